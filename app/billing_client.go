@@ -1,12 +1,15 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"io"
+	"iter"
 	"time"
 
 	pb "go.viam.com/api/app/v1"
+	"go.viam.com/utils"
 	"go.viam.com/utils/rpc"
 )
 
@@ -75,6 +78,37 @@ type GetCurrentMonthUsageResponse struct {
 	Subtotal                   float64
 }
 
+// UsageSample is the usage of an organization for the current month as of a point in time.
+type UsageSample struct {
+	Time  time.Time
+	Usage *GetCurrentMonthUsageResponse
+}
+
+// CostsByType returns the cost of each type of usage, without discounts, summed across sources.
+func (r *GetCurrentMonthUsageResponse) CostsByType() map[UsageCostType]float64 {
+	costs := map[UsageCostType]float64{}
+	for _, source := range r.ResourceUsageCostsBySource {
+		if source.ResourceUsageCosts == nil {
+			continue
+		}
+		for _, cost := range source.ResourceUsageCosts.UsageCosts {
+			costs[cost.ResourceType] += cost.Cost
+		}
+	}
+	return costs
+}
+
+// CostsByTier returns the cost of usage, with discounts, billed under each billing tier.
+func (r *GetCurrentMonthUsageResponse) CostsByTier() map[string]float64 {
+	costs := map[string]float64{}
+	for _, source := range r.ResourceUsageCostsBySource {
+		if source.ResourceUsageCosts != nil {
+			costs[source.TierName] += source.ResourceUsageCosts.TotalWithDiscount
+		}
+	}
+	return costs
+}
+
 // PaymentMethodType is the type of payment method.
 type PaymentMethodType int
 
@@ -161,27 +195,62 @@ func (c *BillingClient) GetInvoicesSummary(ctx context.Context, orgID string) (f
 
 // GetInvoicePDF returns raw byte slices representing the invoice PDF data.
 func (c *BillingClient) GetInvoicePDF(ctx context.Context, id, orgID string) ([]byte, error) {
+	var data bytes.Buffer
+	_, err := c.WriteInvoicePDF(ctx, id, orgID, &data)
+	return data.Bytes(), err
+}
+
+// WriteInvoicePDF writes the invoice PDF to w as it is received, such as to a file, and returns the
+// number of bytes written.
+func (c *BillingClient) WriteInvoicePDF(ctx context.Context, id, orgID string, w io.Writer) (int64, error) {
 	stream, err := c.client.GetInvoicePdf(ctx, &pb.GetInvoicePdfRequest{
 		Id:    id,
 		OrgId: orgID,
 	})
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	var data []byte
+	var written int64
 	for {
 		resp, err := stream.Recv()
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return data, err
+			return written, err
+		}
+		n, err := w.Write(resp.Chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
 		}
-		data = append(data, resp.Chunk...)
 	}
 
-	return data, nil
+	return written, nil
+}
+
+// StreamCurrentMonthUsage returns an iterator over the usage of an organization for the current
+// month, sampled every interval, so that usage can be charted over time. The first sample is taken
+// immediately. Iteration ends when ctx is done or a sample cannot be taken.
+func (c *BillingClient) StreamCurrentMonthUsage(
+	ctx context.Context, orgID string, interval time.Duration,
+) iter.Seq2[*UsageSample, error] {
+	return func(yield func(*UsageSample, error) bool) {
+		for {
+			usage, err := c.GetCurrentMonthUsage(ctx, orgID)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(&UsageSample{Time: time.Now(), Usage: usage}, nil) {
+				return
+			}
+			if !utils.SelectContextOrWait(ctx, interval) {
+				return
+			}
+		}
+	}
 }
 
 // SendPaymentRequiredEmail sends an email about payment requirement.
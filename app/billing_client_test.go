@@ -176,6 +176,31 @@ func TestBillingClient(t *testing.T) {
 		resp, err := client.GetCurrentMonthUsage(context.Background(), organizationID)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, resp, test.ShouldResemble, &getCurrentMonthUsageResponse)
+		test.That(t, resp.CostsByType(), test.ShouldResemble, map[UsageCostType]float64{usageCostType: cost})
+		test.That(t, resp.CostsByTier(), test.ShouldResemble, map[string]float64{tier: totalWithDiscount})
+
+		t.Run("StreamCurrentMonthUsage", func(t *testing.T) {
+			var samples []*UsageSample
+			for sample, err := range client.StreamCurrentMonthUsage(context.Background(), organizationID, time.Millisecond) {
+				test.That(t, err, test.ShouldBeNil)
+				samples = append(samples, sample)
+				if len(samples) == 3 {
+					break
+				}
+			}
+			test.That(t, samples[0].Usage, test.ShouldResemble, &getCurrentMonthUsageResponse)
+			test.That(t, samples[2].Time.After(samples[0].Time), test.ShouldBeTrue)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var count int
+			for _, err := range client.StreamCurrentMonthUsage(ctx, organizationID, time.Hour) {
+				test.That(t, err, test.ShouldBeNil)
+				count++
+				cancel()
+			}
+			test.That(t, count, test.ShouldEqual, 1)
+		})
 	})
 
 	t.Run("GetOrgBillingInformation", func(t *testing.T) {
@@ -250,6 +275,13 @@ func TestBillingClient(t *testing.T) {
 		data, err := client.GetInvoicePDF(context.Background(), invoiceID, organizationID)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, data, test.ShouldResemble, expectedData)
+
+		count = 0
+		var buf bytes.Buffer
+		written, err := client.WriteInvoicePDF(context.Background(), invoiceID, organizationID, &buf)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, written, test.ShouldEqual, len(expectedData))
+		test.That(t, buf.Bytes(), test.ShouldResemble, expectedData)
 	})
 
 	t.Run("SendPaymentRequiredEmail", func(t *testing.T) {
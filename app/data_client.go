@@ -4,14 +4,17 @@ package app
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"image/png"
 	"io"
+	"iter"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -292,6 +295,26 @@ type DataByFilterOptions struct {
 	IncludeInternalData bool
 }
 
+// ExportBinaryDataOptions contains optional parameters for ExportBinaryData and ExportBinaryDataToDir.
+type ExportBinaryDataOptions struct {
+	// No Filter implies all data.
+	Filter *Filter
+	// PageSize is the number of entries requested at a time. PageSize defaults to 50 if unspecified.
+	PageSize            int
+	SortOrder           Order
+	IncludeInternalData bool
+	// ResumeToken resumes an export from the ResumeToken of an entry exported before.
+	ResumeToken string
+}
+
+// ExportedBinaryData is binary data, with its metadata, exported by ExportBinaryData.
+type ExportedBinaryData struct {
+	*BinaryData
+	// ResumeToken resumes the export, through ExportBinaryDataOptions.ResumeToken, without missing
+	// any entry after this one. Entries requested in the same page as this one may be exported again.
+	ResumeToken string
+}
+
 // TabularDataSourceType specifies the data source type for TabularDataByMQL queries.
 type TabularDataSourceType int32
 
@@ -688,38 +711,102 @@ func (d *DataClient) GetLatestTabularData(
 func (d *DataClient) ExportTabularData(
 	ctx context.Context, partID, resourceName, resourceSubtype, method string, interval CaptureInterval, opts *TabularDataOptions,
 ) ([]*ExportTabularDataResponse, error) {
-	additionalParameters, err := additionalParametersToProto(opts)
-	if err != nil {
-		return nil, err
+	var responses []*ExportTabularDataResponse
+	for response, err := range d.ExportTabularDataStream(ctx, partID, resourceName, resourceSubtype, method, interval, opts) {
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
 	}
+	return responses, nil
+}
 
-	stream, err := d.dataClient.ExportTabularData(ctx, &pb.ExportTabularDataRequest{
-		PartId:               partID,
-		ResourceName:         resourceName,
-		ResourceSubtype:      resourceSubtype,
-		MethodName:           method,
-		Interval:             captureIntervalToProto(interval),
-		AdditionalParameters: additionalParameters,
-	})
-	if err != nil {
-		return nil, err
+// ExportTabularDataStream is like ExportTabularData but returns an iterator over the responses,
+// which are received as it is consumed instead of all at once, so exports of any size can be
+// processed. An error ends the iteration. A failed export can be resumed by starting the interval
+// at the TimeCaptured of the last response received; responses captured at that same time are
+// then received again.
+func (d *DataClient) ExportTabularDataStream(
+	ctx context.Context, partID, resourceName, resourceSubtype, method string, interval CaptureInterval, opts *TabularDataOptions,
+) iter.Seq2[*ExportTabularDataResponse, error] {
+	return func(yield func(*ExportTabularDataResponse, error) bool) {
+		additionalParameters, err := additionalParametersToProto(opts)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// the stream is canceled if iteration stops early.
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream, err := d.dataClient.ExportTabularData(ctx, &pb.ExportTabularDataRequest{
+			PartId:               partID,
+			ResourceName:         resourceName,
+			ResourceSubtype:      resourceSubtype,
+			MethodName:           method,
+			Interval:             captureIntervalToProto(interval),
+			AdditionalParameters: additionalParameters,
+		})
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		for {
+			response, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(exportTabularDataResponseFromProto(response), nil) {
+				return
+			}
+		}
 	}
+}
 
-	var responses []*ExportTabularDataResponse
+// exportedTabularRow is the JSON format of the rows written by ExportTabularDataToWriter.
+type exportedTabularRow struct {
+	OrganizationID   string         `json:"organization_id"`
+	LocationID       string         `json:"location_id"`
+	RobotID          string         `json:"robot_id"`
+	RobotName        string         `json:"robot_name"`
+	PartID           string         `json:"part_id"`
+	PartName         string         `json:"part_name"`
+	ResourceName     string         `json:"resource_name"`
+	ResourceSubtype  string         `json:"resource_subtype"`
+	MethodName       string         `json:"method_name"`
+	TimeCaptured     time.Time      `json:"time_captured"`
+	MethodParameters map[string]any `json:"method_parameters,omitempty"`
+	Tags             []string       `json:"tags,omitempty"`
+	Payload          map[string]any `json:"payload"`
+}
 
-	for {
-		response, err := stream.Recv()
-		if errors.Is(err, io.EOF) {
-			break
-		}
+// ExportTabularDataToWriter exports tabular data to w as it is received, as one JSON object per
+// line, and returns the number of rows written. See ExportTabularDataStream for how to resume a
+// failed export.
+func (d *DataClient) ExportTabularDataToWriter(
+	ctx context.Context,
+	w io.Writer,
+	partID, resourceName, resourceSubtype, method string,
+	interval CaptureInterval,
+	opts *TabularDataOptions,
+) (int, error) {
+	encoder := json.NewEncoder(w)
+	var written int
+	for response, err := range d.ExportTabularDataStream(ctx, partID, resourceName, resourceSubtype, method, interval, opts) {
 		if err != nil {
-			return nil, err
+			return written, err
 		}
-
-		responses = append(responses, exportTabularDataResponseFromProto(response))
+		if err := encoder.Encode(exportedTabularRow(*response)); err != nil {
+			return written, err
+		}
+		written++
 	}
-
-	return responses, nil
+	return written, nil
 }
 
 // BinaryDataByFilter queries binary data and metadata based on given filters.
@@ -764,6 +851,84 @@ func (d *DataClient) BinaryDataByFilter(
 	}, nil
 }
 
+// ExportBinaryData returns an iterator over the binary data, with its metadata, that matches the
+// filter of opts, requesting a page at a time as it is consumed so that datasets of any size can be
+// exported without paging through them by hand. An error ends the iteration; the export can be
+// resumed from the ResumeToken of the last entry exported.
+func (d *DataClient) ExportBinaryData(ctx context.Context, opts *ExportBinaryDataOptions) iter.Seq2[*ExportedBinaryData, error] {
+	var exportOpts ExportBinaryDataOptions
+	if opts != nil {
+		exportOpts = *opts
+	}
+	return func(yield func(*ExportedBinaryData, error) bool) {
+		token := exportOpts.ResumeToken
+		for {
+			resp, err := d.BinaryDataByFilter(ctx, true, &DataByFilterOptions{
+				Filter:              exportOpts.Filter,
+				Limit:               exportOpts.PageSize,
+				Last:                token,
+				SortOrder:           exportOpts.SortOrder,
+				IncludeInternalData: exportOpts.IncludeInternalData,
+			})
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, data := range resp.BinaryData {
+				if !yield(&ExportedBinaryData{BinaryData: data, ResumeToken: token}, nil) {
+					return
+				}
+			}
+			// an empty page, or one without a last entry, is the end of the data.
+			if len(resp.BinaryData) == 0 || resp.Last == "" {
+				return
+			}
+			token = resp.Last
+		}
+	}
+}
+
+// ExportBinaryDataToDir exports the binary data that matches the filter of opts into dir, creating
+// it if needed. Each entry is written to a file named after its binary data ID and file extension.
+// Files that already exist are not written again, so that an export interrupted without a resume
+// token can also be restarted cheaply. If the export fails, the token from which it can be resumed
+// is returned with the error.
+func (d *DataClient) ExportBinaryDataToDir(ctx context.Context, dir string, opts *ExportBinaryDataOptions) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	var resumeToken string
+	if opts != nil {
+		resumeToken = opts.ResumeToken
+	}
+	for data, err := range d.ExportBinaryData(ctx, opts) {
+		if err != nil {
+			return resumeToken, err
+		}
+		resumeToken = data.ResumeToken
+		if data.Metadata == nil {
+			continue
+		}
+		path := filepath.Join(dir, exportFileName(data.Metadata))
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			continue
+		}
+		if err := os.WriteFile(path, data.Binary, 0o600); err != nil {
+			return resumeToken, err
+		}
+	}
+	return "", nil
+}
+
+// exportFileName returns the name of the file binary data is exported to, which is unique to it.
+func exportFileName(metadata *BinaryMetadata) string {
+	name := strings.ReplaceAll(metadata.BinaryDataID, "/", "_")
+	if metadata.FileExt != "" && filepath.Ext(name) != metadata.FileExt {
+		name += metadata.FileExt
+	}
+	return name
+}
+
 // BinaryDataByIDs queries binary data and metadata based on given IDs.
 // opts is optional; if not provided, IncludeBinary defaults to true for backward compatibility.
 func (d *DataClient) BinaryDataByIDs(ctx context.Context, binaryDataIDs []string, opts ...*BinaryDataByIDsOptions) ([]*BinaryData, error) {
@@ -1,9 +1,14 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -514,6 +519,36 @@ func TestDataClient(t *testing.T) {
 		test.That(t, responses[0], test.ShouldResemble, exportTabularDataResponseFromProto(exportTabularResponse))
 	})
 
+	t.Run("ExportTabularDataToWriter", func(t *testing.T) {
+		var sent int
+		mockStream := &inject.DataServiceExportTabularDataClient{
+			RecvFunc: func() (*pb.ExportTabularDataResponse, error) {
+				if sent == 2 {
+					return nil, io.EOF
+				}
+				sent++
+				return exportTabularResponse, nil
+			},
+		}
+		grpcClient.ExportTabularDataFunc = func(ctx context.Context, in *pb.ExportTabularDataRequest,
+			opts ...grpc.CallOption,
+		) (pb.DataService_ExportTabularDataClient, error) {
+			return mockStream, nil
+		}
+
+		var buf bytes.Buffer
+		written, err := client.ExportTabularDataToWriter(
+			context.Background(), &buf, partID, componentName, componentType, method, captureInterval, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, written, test.ShouldEqual, 2)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		test.That(t, lines, test.ShouldHaveLength, 2)
+		var row map[string]any
+		test.That(t, json.Unmarshal([]byte(lines[0]), &row), test.ShouldBeNil)
+		test.That(t, row["part_id"], test.ShouldEqual, exportTabularResponse.PartId)
+		test.That(t, row["method_name"], test.ShouldEqual, exportTabularResponse.MethodName)
+	})
+
 	t.Run("BinaryDataByFilter", func(t *testing.T) {
 		includeBinary := true
 		grpcClient.BinaryDataByFilterFunc = func(ctx context.Context, in *pb.BinaryDataByFilterRequest,
@@ -540,6 +575,89 @@ func TestDataClient(t *testing.T) {
 		test.That(t, resp.Last, test.ShouldEqual, last)
 	})
 
+	t.Run("ExportBinaryData", func(t *testing.T) {
+		// three pages of one entry each, the last of which has no last entry.
+		pages := map[string]string{"": "page2", "page2": "page3", "page3": ""}
+		var requested []string
+		grpcClient.BinaryDataByFilterFunc = func(ctx context.Context, in *pb.BinaryDataByFilterRequest,
+			opts ...grpc.CallOption,
+		) (*pb.BinaryDataByFilterResponse, error) {
+			test.That(t, in.IncludeBinary, test.ShouldBeTrue)
+			test.That(t, in.DataRequest.Limit, test.ShouldEqual, limit)
+			requested = append(requested, in.DataRequest.Last)
+			data := binaryData
+			metadata := *data.Metadata
+			metadata.BinaryDataID = binaryDataID + "/" + in.DataRequest.Last
+			data.Metadata = &metadata
+			return &pb.BinaryDataByFilterResponse{
+				Data: []*pb.BinaryData{binaryDataToProto(data)},
+				Last: pages[in.DataRequest.Last],
+			}, nil
+		}
+
+		var exported []*ExportedBinaryData
+		for data, err := range client.ExportBinaryData(context.Background(), &ExportBinaryDataOptions{Filter: &filter, PageSize: limit}) {
+			test.That(t, err, test.ShouldBeNil)
+			exported = append(exported, data)
+		}
+		test.That(t, requested, test.ShouldResemble, []string{"", "page2", "page3"})
+		test.That(t, exported, test.ShouldHaveLength, 3)
+		test.That(t, exported[1].Metadata.BinaryDataID, test.ShouldEqual, binaryDataID+"/page2")
+		test.That(t, exported[1].ResumeToken, test.ShouldEqual, "page2")
+
+		t.Run("resume", func(t *testing.T) {
+			requested = nil
+			var count int
+			for _, err := range client.ExportBinaryData(context.Background(), &ExportBinaryDataOptions{
+				PageSize:    limit,
+				ResumeToken: exported[2].ResumeToken,
+			}) {
+				test.That(t, err, test.ShouldBeNil)
+				count++
+			}
+			test.That(t, requested, test.ShouldResemble, []string{"page3"})
+			test.That(t, count, test.ShouldEqual, 1)
+		})
+
+		t.Run("stopping early", func(t *testing.T) {
+			requested = nil
+			for range client.ExportBinaryData(context.Background(), &ExportBinaryDataOptions{PageSize: limit}) {
+				break
+			}
+			test.That(t, requested, test.ShouldHaveLength, 1)
+		})
+
+		t.Run("to a directory", func(t *testing.T) {
+			dir := t.TempDir()
+			token, err := client.ExportBinaryDataToDir(context.Background(), dir, &ExportBinaryDataOptions{PageSize: limit})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, token, test.ShouldBeEmpty)
+			entries, err := os.ReadDir(dir)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, entries, test.ShouldHaveLength, 3)
+			contents, err := os.ReadFile(filepath.Join(dir, binaryDataID+"_page2"+fileExt))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, contents, test.ShouldResemble, binaryDataByte)
+		})
+
+		t.Run("errors", func(t *testing.T) {
+			grpcClient.BinaryDataByFilterFunc = func(ctx context.Context, in *pb.BinaryDataByFilterRequest,
+				opts ...grpc.CallOption,
+			) (*pb.BinaryDataByFilterResponse, error) {
+				if in.DataRequest.Last == "page3" {
+					return nil, errors.New("unavailable")
+				}
+				return &pb.BinaryDataByFilterResponse{
+					Data: []*pb.BinaryData{binaryDataToProto(binaryData)},
+					Last: pages[in.DataRequest.Last],
+				}, nil
+			}
+			token, err := client.ExportBinaryDataToDir(context.Background(), t.TempDir(), &ExportBinaryDataOptions{PageSize: limit})
+			test.That(t, err, test.ShouldBeError, errors.New("unavailable"))
+			test.That(t, token, test.ShouldEqual, "page2")
+		})
+	})
+
 	t.Run("BinaryDataByIDs", func(t *testing.T) {
 		t.Run("default behavior (backward compatible)", func(t *testing.T) {
 			grpcClient.BinaryDataByIDsFunc = func(ctx context.Context, in *pb.BinaryDataByIDsRequest,
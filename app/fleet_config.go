@@ -0,0 +1,327 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"go.viam.com/utils"
+)
+
+// ErrFleetRolloutStopped is the error of the robots a fleet config rollout did not update because
+// it stopped after a batch in which an update failed.
+var ErrFleetRolloutStopped = errors.New("rollout stopped after an update in an earlier batch failed")
+
+// FleetFilter selects the robots a fleet config operation applies to. The config of a robot is the
+// config of its main part.
+type FleetFilter struct {
+	// OrganizationID selects the robots of every location of the organization. It is required if
+	// LocationIDs and RobotIDs are empty.
+	OrganizationID string
+	// LocationIDs, if set, restricts the robots to those of these locations.
+	LocationIDs []string
+	// RobotIDs, if set, restricts the robots to these. Without an organization or locations, these
+	// robots are selected directly.
+	RobotIDs []string
+	// FragmentID, if set, restricts the robots to those whose config uses the fragment. Since robots
+	// have no tags of their own, fragments are the usual way to tag a group of robots.
+	FragmentID string
+	// Match, if set, restricts the robots to those for which it returns true.
+	Match func(robot *Robot, mainPart *RobotPart) bool
+}
+
+// FleetRolloutOptions contains optional parameters for fleet config operations.
+type FleetRolloutOptions struct {
+	// DryRun computes the config every robot would get without updating any.
+	DryRun bool
+	// BatchSize is the number of robots updated at once. BatchSize defaults to 1 if unspecified.
+	BatchSize int
+	// BatchDelay is the time waited between batches, such as to watch the robots updated in a batch
+	// before updating more.
+	BatchDelay time.Duration
+	// StopOnError stops the rollout after a batch in which an update failed.
+	StopOnError bool
+}
+
+// FleetConfigResult is the outcome of a fleet config operation for one robot.
+type FleetConfigResult struct {
+	Robot    *Robot
+	MainPart *RobotPart
+	// OldConfig and NewConfig are the config of the main part before and after the operation.
+	OldConfig map[string]interface{}
+	NewConfig map[string]interface{}
+	// Changed is whether the operation changes the config of the robot.
+	Changed bool
+	// Applied is whether the config of the robot was updated.
+	Applied bool
+	Err     error
+}
+
+// UpdateFleetConfig applies update to the config of every robot selected by filter, updating the
+// robots whose config it changes in batches, and returns the result for each robot. update is given
+// a copy of the config of a robot it may modify and return. Failures for a single robot are
+// reported in its result; an error is only returned if the robots could not be selected.
+//
+// UpdateFleetConfig example:
+//
+//	results, err := cloud.UpdateFleetConfig(
+//		context.Background(),
+//		app.FleetFilter{OrganizationID: "a1b2c345-abcd-1a2b-abc1-a1b23cd4561e2"},
+//		func(config map[string]interface{}) (map[string]interface{}, error) {
+//			config["debug"] = true
+//			return config, nil
+//		},
+//		&app.FleetRolloutOptions{BatchSize: 10, BatchDelay: time.Minute, StopOnError: true},
+//	)
+func (c *AppClient) UpdateFleetConfig(
+	ctx context.Context,
+	filter FleetFilter,
+	update func(config map[string]interface{}) (map[string]interface{}, error),
+	opts *FleetRolloutOptions,
+) ([]*FleetConfigResult, error) {
+	var rollout FleetRolloutOptions
+	if opts != nil {
+		rollout = *opts
+	}
+	results, err := c.selectFleet(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*FleetConfigResult
+	for _, result := range results {
+		config, err := copyConfig(result.OldConfig)
+		if err == nil {
+			config, err = update(config)
+		}
+		if err != nil {
+			result.Err = fmt.Errorf("updating config of robot %q: %w", result.Robot.Name, err)
+			continue
+		}
+		result.NewConfig = config
+		result.Changed = !reflect.DeepEqual(result.OldConfig, config)
+		if result.Changed {
+			pending = append(pending, result)
+		}
+	}
+	if rollout.DryRun {
+		return results, nil
+	}
+
+	var delay time.Duration
+	var failed bool
+	for batch := range slices.Chunk(pending, max(rollout.BatchSize, 1)) {
+		var skipErr error
+		switch {
+		case rollout.StopOnError && failed:
+			skipErr = ErrFleetRolloutStopped
+		case !utils.SelectContextOrWait(ctx, delay):
+			skipErr = ctx.Err()
+		}
+		delay = rollout.BatchDelay
+		if skipErr != nil {
+			for _, result := range batch {
+				result.Err = skipErr
+			}
+			continue
+		}
+
+		var wg sync.WaitGroup
+		for _, result := range batch {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := c.UpdateRobotPart(ctx, result.MainPart.ID, result.MainPart.Name, result.NewConfig); err != nil {
+					result.Err = fmt.Errorf("updating config of robot %q: %w", result.Robot.Name, err)
+					return
+				}
+				result.Applied = true
+			}()
+		}
+		wg.Wait()
+		failed = failed || slices.ContainsFunc(batch, func(result *FleetConfigResult) bool { return result.Err != nil })
+	}
+	return results, nil
+}
+
+// AddFragmentToFleet adds the fragment to the config of every robot selected by filter that does
+// not use it yet. See UpdateFleetConfig for how the robots are updated.
+//
+// AddFragmentToFleet example:
+//
+//	results, err := cloud.AddFragmentToFleet(
+//		context.Background(),
+//		app.FleetFilter{LocationIDs: []string{"ab1c2d3e45"}},
+//		"12a12ab1-1234-5678-abcd-abcd01234567",
+//		&app.FleetRolloutOptions{DryRun: true},
+//	)
+func (c *AppClient) AddFragmentToFleet(
+	ctx context.Context, filter FleetFilter, fragmentID string, opts *FleetRolloutOptions,
+) ([]*FleetConfigResult, error) {
+	return c.UpdateFleetConfig(ctx, filter, func(config map[string]interface{}) (map[string]interface{}, error) {
+		if configUsesFragment(config, fragmentID) {
+			return config, nil
+		}
+		fragments, _ := config["fragments"].([]interface{})
+		config["fragments"] = append(fragments, map[string]interface{}{"id": fragmentID})
+		return config, nil
+	}, opts)
+}
+
+// PatchFleetResourceAttributes merges patch into the attributes of the component or service named
+// resourceName in the config of every robot selected by filter. Fields of patch that are maps are
+// merged into the attributes recursively and fields that are nil remove attributes, as in a JSON
+// merge patch. Robots without the resource are left unchanged. See UpdateFleetConfig for how the
+// robots are updated.
+//
+// PatchFleetResourceAttributes example:
+//
+//	results, err := cloud.PatchFleetResourceAttributes(
+//		context.Background(),
+//		app.FleetFilter{OrganizationID: "a1b2c345-abcd-1a2b-abc1-a1b23cd4561e2"},
+//		"my-camera",
+//		map[string]interface{}{"width_px": 640, "height_px": 480},
+//		&app.FleetRolloutOptions{BatchSize: 5},
+//	)
+func (c *AppClient) PatchFleetResourceAttributes(
+	ctx context.Context, filter FleetFilter, resourceName string, patch map[string]interface{}, opts *FleetRolloutOptions,
+) ([]*FleetConfigResult, error) {
+	// the patch is normalized to the types configs are made of, so that unchanged values compare equal.
+	patch, err := copyConfig(patch)
+	if err != nil {
+		return nil, err
+	}
+	return c.UpdateFleetConfig(ctx, filter, func(config map[string]interface{}) (map[string]interface{}, error) {
+		for _, kind := range []string{"components", "services"} {
+			resources, _ := config[kind].([]interface{})
+			for _, res := range resources {
+				res, ok := res.(map[string]interface{})
+				if !ok || res["name"] != resourceName {
+					continue
+				}
+				attributes, _ := res["attributes"].(map[string]interface{})
+				res["attributes"] = mergePatch(attributes, patch)
+			}
+		}
+		return config, nil
+	}, opts)
+}
+
+// selectFleet returns a result, yet to be completed, for every robot selected by filter.
+func (c *AppClient) selectFleet(ctx context.Context, filter FleetFilter) ([]*FleetConfigResult, error) {
+	var robots []*Robot
+	switch {
+	case filter.OrganizationID != "" || len(filter.LocationIDs) > 0:
+		locationIDs := filter.LocationIDs
+		if len(locationIDs) == 0 {
+			locations, err := c.ListLocations(ctx, filter.OrganizationID)
+			if err != nil {
+				return nil, err
+			}
+			for _, location := range locations {
+				locationIDs = append(locationIDs, location.ID)
+			}
+		}
+		for _, locationID := range locationIDs {
+			locationRobots, err := c.ListRobots(ctx, locationID)
+			if err != nil {
+				return nil, err
+			}
+			for _, robot := range locationRobots {
+				if len(filter.RobotIDs) == 0 || slices.Contains(filter.RobotIDs, robot.ID) {
+					robots = append(robots, robot)
+				}
+			}
+		}
+	case len(filter.RobotIDs) > 0:
+		for _, robotID := range filter.RobotIDs {
+			robot, err := c.GetRobot(ctx, robotID)
+			if err != nil {
+				return nil, err
+			}
+			robots = append(robots, robot)
+		}
+	default:
+		return nil, errors.New("an organization, locations, or robots must be given to select robots")
+	}
+
+	var results []*FleetConfigResult
+	for _, robot := range robots {
+		parts, err := c.GetRobotParts(ctx, robot.ID)
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(parts, func(part *RobotPart) bool { return part.MainPart })
+		if i < 0 {
+			continue
+		}
+		mainPart := parts[i]
+		if filter.FragmentID != "" && !configUsesFragment(mainPart.RobotConfig, filter.FragmentID) {
+			continue
+		}
+		if filter.Match != nil && !filter.Match(robot, mainPart) {
+			continue
+		}
+		config := mainPart.RobotConfig
+		if config == nil {
+			config = map[string]interface{}{}
+		}
+		results = append(results, &FleetConfigResult{Robot: robot, MainPart: mainPart, OldConfig: config})
+	}
+	return results, nil
+}
+
+// configUsesFragment returns whether the robot config uses the fragment directly.
+func configUsesFragment(config map[string]interface{}, fragmentID string) bool {
+	fragments, _ := config["fragments"].([]interface{})
+	return slices.ContainsFunc(fragments, func(fragment interface{}) bool {
+		switch fragment := fragment.(type) {
+		case string:
+			return fragment == fragmentID
+		case map[string]interface{}:
+			return fragment["id"] == fragmentID
+		default:
+			return false
+		}
+	})
+}
+
+// copyConfig returns a deep copy of config, with the types configs are made of when received from app.
+func copyConfig(config map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var copied map[string]interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	if copied == nil {
+		copied = map[string]interface{}{}
+	}
+	return copied, nil
+}
+
+// mergePatch merges patch into target as a JSON merge patch does and returns the result.
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = map[string]interface{}{}
+	}
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(target, key)
+		case map[string]interface{}:
+			existing, _ := target[key].(map[string]interface{})
+			target[key] = mergePatch(existing, value)
+		default:
+			target[key] = value
+		}
+	}
+	return target
+}
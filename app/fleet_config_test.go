@@ -0,0 +1,167 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	pb "go.viam.com/api/app/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestFleetConfig(t *testing.T) {
+	const fragment = "fragment_id"
+	// fleetConfigs are the configs of the main parts of the robots of the fleet, by robot ID.
+	fleetConfigs := map[string]map[string]interface{}{
+		"robot1": {
+			"components": []interface{}{
+				map[string]interface{}{"name": "camera", "attributes": map[string]interface{}{"width_px": 320.0, "debug": true}},
+			},
+		},
+		"robot2": {"fragments": []interface{}{map[string]interface{}{"id": fragment}}},
+		"robot3": {
+			"fragments": []interface{}{map[string]interface{}{"id": fragment}},
+			"components": []interface{}{
+				map[string]interface{}{"name": "camera", "attributes": map[string]interface{}{"width_px": 640.0}},
+			},
+		},
+	}
+
+	newClient := func(t *testing.T) (*AppClient, *[]string) {
+		t.Helper()
+		var mu sync.Mutex
+		var updated []string
+		grpcClient := &inject.AppServiceClient{
+			ListLocationsFunc: func(ctx context.Context, in *pb.ListLocationsRequest, opts ...grpc.CallOption) (*pb.ListLocationsResponse, error) {
+				test.That(t, in.OrganizationId, test.ShouldEqual, organizationID)
+				return &pb.ListLocationsResponse{Locations: []*pb.Location{{Id: "location1"}, {Id: "location2"}}}, nil
+			},
+			ListRobotsFunc: func(ctx context.Context, in *pb.ListRobotsRequest, opts ...grpc.CallOption) (*pb.ListRobotsResponse, error) {
+				if in.LocationId == "location1" {
+					return &pb.ListRobotsResponse{Robots: []*pb.Robot{{Id: "robot1", Name: "robot1"}, {Id: "robot2", Name: "robot2"}}}, nil
+				}
+				return &pb.ListRobotsResponse{Robots: []*pb.Robot{{Id: "robot3", Name: "robot3"}}}, nil
+			},
+			GetRobotPartsFunc: func(
+				ctx context.Context, in *pb.GetRobotPartsRequest, opts ...grpc.CallOption,
+			) (*pb.GetRobotPartsResponse, error) {
+				config, err := structpb.NewStruct(fleetConfigs[in.RobotId])
+				test.That(t, err, test.ShouldBeNil)
+				return &pb.GetRobotPartsResponse{Parts: []*pb.RobotPart{
+					{Id: in.RobotId + "-remote", MainPart: false},
+					{Id: in.RobotId + "-main", Name: "main", MainPart: true, RobotConfig: config},
+				}}, nil
+			},
+			UpdateRobotPartFunc: func(
+				ctx context.Context, in *pb.UpdateRobotPartRequest, opts ...grpc.CallOption,
+			) (*pb.UpdateRobotPartResponse, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if in.Id == "robot3-main" {
+					return nil, errors.New("robot3 is unreachable")
+				}
+				updated = append(updated, in.Id)
+				return &pb.UpdateRobotPartResponse{Part: &pb.RobotPart{Id: in.Id, RobotConfig: in.RobotConfig}}, nil
+			},
+		}
+		return &AppClient{client: grpcClient}, &updated
+	}
+
+	t.Run("dry run", func(t *testing.T) {
+		client, updated := newClient(t)
+		results, err := client.AddFragmentToFleet(context.Background(), FleetFilter{OrganizationID: organizationID}, fragment,
+			&FleetRolloutOptions{DryRun: true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, results, test.ShouldHaveLength, 3)
+		test.That(t, results[0].Robot.ID, test.ShouldEqual, "robot1")
+		test.That(t, results[0].MainPart.ID, test.ShouldEqual, "robot1-main")
+		test.That(t, results[0].Changed, test.ShouldBeTrue)
+		test.That(t, results[0].NewConfig["fragments"], test.ShouldResemble, []interface{}{map[string]interface{}{"id": fragment}})
+		test.That(t, results[0].OldConfig["fragments"], test.ShouldBeNil)
+		test.That(t, results[1].Changed, test.ShouldBeFalse)
+		test.That(t, results[2].Changed, test.ShouldBeFalse)
+		for _, result := range results {
+			test.That(t, result.Applied, test.ShouldBeFalse)
+		}
+		test.That(t, *updated, test.ShouldBeEmpty)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		client, _ := newClient(t)
+		results, err := client.UpdateFleetConfig(context.Background(), FleetFilter{
+			LocationIDs: []string{"location1", "location2"},
+			FragmentID:  fragment,
+			Match:       func(robot *Robot, mainPart *RobotPart) bool { return robot.Name != "robot2" },
+		}, func(config map[string]interface{}) (map[string]interface{}, error) { return config, nil }, &FleetRolloutOptions{DryRun: true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, results, test.ShouldHaveLength, 1)
+		test.That(t, results[0].Robot.ID, test.ShouldEqual, "robot3")
+
+		_, err = client.UpdateFleetConfig(context.Background(), FleetFilter{},
+			func(config map[string]interface{}) (map[string]interface{}, error) { return config, nil }, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("patch attributes", func(t *testing.T) {
+		client, updated := newClient(t)
+		results, err := client.PatchFleetResourceAttributes(context.Background(), FleetFilter{OrganizationID: organizationID},
+			"camera", map[string]interface{}{"width_px": 640, "debug": nil}, &FleetRolloutOptions{BatchSize: 2})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, results, test.ShouldHaveLength, 3)
+
+		// robot2 has no camera and robot3 already has its width.
+		test.That(t, results[0].Applied, test.ShouldBeTrue)
+		test.That(t, results[0].NewConfig["components"], test.ShouldResemble, []interface{}{
+			map[string]interface{}{"name": "camera", "attributes": map[string]interface{}{"width_px": 640.0}},
+		})
+		test.That(t, results[1].Changed, test.ShouldBeFalse)
+		test.That(t, results[2].Changed, test.ShouldBeFalse)
+		test.That(t, *updated, test.ShouldResemble, []string{"robot1-main"})
+	})
+
+	t.Run("rollout", func(t *testing.T) {
+		client, updated := newClient(t)
+		var n int
+		results, err := client.UpdateFleetConfig(context.Background(), FleetFilter{OrganizationID: organizationID},
+			func(config map[string]interface{}) (map[string]interface{}, error) {
+				n++
+				if n == 2 {
+					return nil, errors.New("bad config")
+				}
+				config["debug"] = true
+				return config, nil
+			}, &FleetRolloutOptions{StopOnError: true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, results, test.ShouldHaveLength, 3)
+		test.That(t, results[0].Applied, test.ShouldBeTrue)
+		// failures of the update function do not stop the rollout.
+		test.That(t, results[1].Err.Error(), test.ShouldContainSubstring, "bad config")
+		test.That(t, results[2].Err.Error(), test.ShouldContainSubstring, "robot3 is unreachable")
+		test.That(t, *updated, test.ShouldResemble, []string{"robot1-main"})
+	})
+
+	t.Run("rollout stops on error", func(t *testing.T) {
+		client, updated := newClient(t)
+		client.client.(*inject.AppServiceClient).GetRobotFunc = func(
+			ctx context.Context, in *pb.GetRobotRequest, opts ...grpc.CallOption,
+		) (*pb.GetRobotResponse, error) {
+			return &pb.GetRobotResponse{Robot: &pb.Robot{Id: in.Id, Name: in.Id}}, nil
+		}
+		results, err := client.UpdateFleetConfig(context.Background(), FleetFilter{RobotIDs: []string{"robot3", "robot1"}},
+			func(config map[string]interface{}) (map[string]interface{}, error) {
+				config["debug"] = false
+				return config, nil
+			}, &FleetRolloutOptions{StopOnError: true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, results, test.ShouldHaveLength, 2)
+		test.That(t, results[0].Err.Error(), test.ShouldContainSubstring, "robot3 is unreachable")
+		test.That(t, results[1].Applied, test.ShouldBeFalse)
+		test.That(t, results[1].Err, test.ShouldBeError, ErrFleetRolloutStopped)
+		test.That(t, *updated, test.ShouldBeEmpty)
+	})
+}
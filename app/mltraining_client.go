@@ -2,10 +2,25 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	pb "go.viam.com/api/app/mltraining/v1"
+	packages "go.viam.com/api/app/packages/v1"
+	apppb "go.viam.com/api/app/v1"
+	"go.viam.com/utils"
+	"go.viam.com/utils/protoutils"
 	"go.viam.com/utils/rpc"
 	status "google.golang.org/genproto/googleapis/rpc/status"
 )
@@ -72,13 +87,50 @@ type SubmitTrainingJobArgs struct {
 	ModelVersion   string
 }
 
+// ModelVersion is a version of an ML model in the registry, such as one synced by a training job.
+type ModelVersion struct {
+	// PackageID is the ID of the package of the model, "{organization_id}/{model_name}".
+	PackageID string
+	Version   string
+	CreatedOn *time.Time
+	// TrainingJob is the training job that produced the version, if any. Its DatasetID is the
+	// dataset the version was trained on.
+	TrainingJob *TrainingJobMetadata
+}
+
+// DownloadedModel is a version of an ML model downloaded by DownloadModelVersion.
+type DownloadedModel struct {
+	// Path is the path of the archive of the model.
+	Path string
+	// Checksum is the sha256 checksum of the archive, e.g. "sha256:ab12...".
+	Checksum string
+}
+
+// DownloadModelVersionOptions contains optional parameters for DownloadModelVersion.
+type DownloadModelVersionOptions struct {
+	// Checksum, if set, is the sha256 checksum, e.g. "sha256:ab12...", the archive must have, such
+	// as one recorded when the version was first deployed.
+	Checksum string
+}
+
 // MLTrainingClient is a gRPC client for method calls to the ML Training API.
 type MLTrainingClient struct {
-	client pb.MLTrainingServiceClient
+	client         pb.MLTrainingServiceClient
+	packagesClient packages.PackageServiceClient
+	appClient      apppb.AppServiceClient
+	// downloadHeaders authenticate downloads of model archives.
+	downloadHeaders http.Header
+	httpClient      *http.Client
 }
 
-func newMLTrainingClient(conn rpc.ClientConn) *MLTrainingClient {
-	return &MLTrainingClient{client: pb.NewMLTrainingServiceClient(conn)}
+func newMLTrainingClient(conn rpc.ClientConn, downloadHeaders http.Header) *MLTrainingClient {
+	return &MLTrainingClient{
+		client:          pb.NewMLTrainingServiceClient(conn),
+		packagesClient:  packages.NewPackageServiceClient(conn),
+		appClient:       apppb.NewAppServiceClient(conn),
+		downloadHeaders: downloadHeaders,
+		httpClient:      http.DefaultClient,
+	}
 }
 
 // SubmitTrainingJob submits a training job request and returns its ID.
@@ -194,6 +246,188 @@ func (c *MLTrainingClient) GetTrainingJobLogs(
 	return logs, resp.NextPageToken, nil
 }
 
+// ListModelVersions lists the versions of the ML model of the organization in the registry, along
+// with the training jobs that produced them.
+func (c *MLTrainingClient) ListModelVersions(ctx context.Context, organizationID, modelName string) ([]*ModelVersion, error) {
+	packageType := packages.PackageType_PACKAGE_TYPE_ML_MODEL
+	resp, err := c.packagesClient.ListPackages(ctx, &packages.ListPackagesRequest{
+		OrganizationId: organizationID,
+		Name:           &modelName,
+		Type:           &packageType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := c.ListTrainingJobs(ctx, organizationID, TrainingStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []*ModelVersion
+	for _, pkg := range resp.Packages {
+		if pkg.Info == nil {
+			continue
+		}
+		version := &ModelVersion{
+			PackageID: organizationID + "/" + pkg.Info.Name,
+			Version:   pkg.Info.Version,
+		}
+		if pkg.CreatedOn != nil {
+			createdOn := pkg.CreatedOn.AsTime()
+			version.CreatedOn = &createdOn
+		}
+		if i := slices.IndexFunc(jobs, func(job *TrainingJobMetadata) bool {
+			return job.ModelName == pkg.Info.Name && job.ModelVersion == pkg.Info.Version
+		}); i >= 0 {
+			version.TrainingJob = jobs[i]
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// DownloadModelVersion downloads the archive of the version of the ML model of the organization
+// into dir, creating it if needed, and verifies it against the crc32c checksum reported by the
+// download server and the checksum of opts, if set. An archive that does not verify is removed.
+func (c *MLTrainingClient) DownloadModelVersion(
+	ctx context.Context, organizationID, modelName, version, dir string, opts *DownloadModelVersionOptions,
+) (*DownloadedModel, error) {
+	includeURL := true
+	packageType := packages.PackageType_PACKAGE_TYPE_ML_MODEL
+	resp, err := c.packagesClient.GetPackage(ctx, &packages.GetPackageRequest{
+		Id:         organizationID + "/" + modelName,
+		Version:    version,
+		IncludeUrl: &includeURL,
+		Type:       &packageType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Package == nil || resp.Package.Url == "" {
+		return nil, fmt.Errorf("no download URL for version %q of model %q", version, modelName)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	// the archive is downloaded to a temporary file first so that a failed download does not leave
+	// an archive behind.
+	tmp, err := os.CreateTemp(dir, "."+modelName+"-*.download")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		utils.UncheckedError(tmp.Close())
+		utils.UncheckedError(os.Remove(tmp.Name()))
+	}()
+	checksum, err := c.downloadModelArchive(ctx, resp.Package.Url, tmp)
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.Checksum != "" && opts.Checksum != checksum {
+		return nil, fmt.Errorf("model archive has checksum %s, expected %s", checksum, opts.Checksum)
+	}
+
+	path := filepath.Join(dir, modelName+"-"+version+".tar.gz")
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return &DownloadedModel{Path: path, Checksum: checksum}, nil
+}
+
+// downloadModelArchive downloads the archive at url to w, verifying it against the crc32c checksum
+// the download server reports, and returns its sha256 checksum.
+func (c *MLTrainingClient) downloadModelArchive(ctx context.Context, url string, w io.Writer) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header = c.downloadHeaders.Clone()
+	//nolint:bodyclose // closed in UncheckedErrorFunc
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("downloading model archive: invalid status code %d", resp.StatusCode)
+	}
+
+	sha256Hash := sha256.New()
+	crc32cHash := crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	if _, err := io.Copy(io.MultiWriter(w, sha256Hash, crc32cHash), resp.Body); err != nil {
+		return "", err
+	}
+	if expected := googleHash(resp.Header, "crc32c"); expected != "" {
+		if actual := base64.StdEncoding.EncodeToString(crc32cHash.Sum(nil)); actual != expected {
+			return "", fmt.Errorf("model archive has crc32c checksum %s, expected %s", actual, expected)
+		}
+	}
+	return "sha256:" + hex.EncodeToString(sha256Hash.Sum(nil)), nil
+}
+
+// googleHash returns the checksum of the given type from the x-goog-hash headers of a download.
+func googleHash(header http.Header, hashType string) string {
+	for _, value := range header.Values("x-goog-hash") {
+		if checksum, ok := strings.CutPrefix(value, hashType+"="); ok {
+			return checksum
+		}
+	}
+	return ""
+}
+
+// RegisterModelPackage adds the version of the ML model to the packages of the robot part under
+// name, replacing the package of that name if there is one, so that the robot downloads it and its
+// ML model services can use it through "${packages.ml_model.<name>}". If checksum is set, such as
+// to the Checksum of a DownloadedModel, the robot only uses the package if it has this checksum,
+// pinning the exact artifact that was downloaded and tested.
+func (c *MLTrainingClient) RegisterModelPackage(ctx context.Context, partID, name string, model *ModelVersion, checksum string) error {
+	if name == "" || model == nil || model.PackageID == "" || model.Version == "" {
+		return errors.New("a package name and a model version are required to register a model package")
+	}
+	resp, err := c.appClient.GetRobotPart(ctx, &apppb.GetRobotPartRequest{Id: partID})
+	if err != nil {
+		return err
+	}
+	if resp.Part == nil {
+		return fmt.Errorf("robot part %q not found", partID)
+	}
+	config := map[string]interface{}{}
+	if resp.Part.RobotConfig != nil {
+		config = resp.Part.RobotConfig.AsMap()
+	}
+
+	pkg := map[string]interface{}{
+		"name":    name,
+		"package": model.PackageID,
+		"version": model.Version,
+		"type":    "ml_model",
+	}
+	if checksum != "" {
+		pkg["verification"] = map[string]interface{}{"checksum": checksum}
+	}
+	pkgs, _ := config["packages"].([]interface{})
+	pkgs = slices.DeleteFunc(pkgs, func(existing interface{}) bool {
+		existingMap, ok := existing.(map[string]interface{})
+		return ok && existingMap["name"] == name
+	})
+	config["packages"] = append(pkgs, pkg)
+
+	robotConfig, err := protoutils.StructToStructPb(config)
+	if err != nil {
+		return err
+	}
+	_, err = c.appClient.UpdateRobotPart(ctx, &apppb.UpdateRobotPartRequest{
+		Id:          partID,
+		Name:        resp.Part.Name,
+		RobotConfig: robotConfig,
+	})
+	return err
+}
+
 func (s *SubmitTrainingJobArgs) isValid() error {
 	if s.DatasetID == "" {
 		return errors.New("DatasetID should not be empty")
@@ -2,14 +2,25 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 
 	pb "go.viam.com/api/app/mltraining/v1"
+	packagespb "go.viam.com/api/app/packages/v1"
+	apppb "go.viam.com/api/app/v1"
 	"go.viam.com/test"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"go.viam.com/rdk/testutils/inject"
@@ -211,3 +222,138 @@ func TestMLTrainingClient(t *testing.T) {
 		test.That(t, token, test.ShouldEqual, pageToken)
 	})
 }
+
+func TestMLTrainingModelVersions(t *testing.T) {
+	archive := []byte("model archive")
+	sha256Sum := sha256.Sum256(archive)
+	checksum := "sha256:" + hex.EncodeToString(sha256Sum[:])
+	crc32c := base64.StdEncoding.EncodeToString(
+		binary.BigEndian.AppendUint32(nil, crc32.Checksum(archive, crc32.MakeTable(crc32.Castagnoli))))
+
+	var served []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		test.That(t, r.Header.Get("key"), test.ShouldEqual, "api_key")
+		w.Header().Add("x-goog-hash", "md5=unused")
+		w.Header().Add("x-goog-hash", "crc32c="+crc32c)
+		_, err := w.Write(served)
+		test.That(t, err, test.ShouldBeNil)
+	}))
+	defer server.Close()
+
+	grpcClient := createMLTrainingGrpcClient()
+	packagesClient := &inject.PackageServiceClient{}
+	appClient := &inject.AppServiceClient{}
+	client := MLTrainingClient{
+		client:          grpcClient,
+		packagesClient:  packagesClient,
+		appClient:       appClient,
+		downloadHeaders: http.Header{"Key": []string{"api_key"}},
+		httpClient:      server.Client(),
+	}
+
+	t.Run("ListModelVersions", func(t *testing.T) {
+		packagesClient.ListPackagesFunc = func(
+			ctx context.Context, in *packagespb.ListPackagesRequest, opts ...grpc.CallOption,
+		) (*packagespb.ListPackagesResponse, error) {
+			test.That(t, in.OrganizationId, test.ShouldEqual, organizationID)
+			test.That(t, *in.Name, test.ShouldEqual, name)
+			test.That(t, *in.Type, test.ShouldEqual, packagespb.PackageType_PACKAGE_TYPE_ML_MODEL)
+			return &packagespb.ListPackagesResponse{Packages: []*packagespb.Package{
+				{Info: &packagespb.PackageInfo{Name: name, Version: version}, CreatedOn: timestamppb.New(start)},
+				{Info: &packagespb.PackageInfo{Name: name, Version: "uploaded"}},
+			}}, nil
+		}
+		grpcClient.ListTrainingJobsFunc = func(
+			ctx context.Context, in *pb.ListTrainingJobsRequest, opts ...grpc.CallOption,
+		) (*pb.ListTrainingJobsResponse, error) {
+			return &pb.ListTrainingJobsResponse{Jobs: []*pb.TrainingJobMetadata{
+				{Id: jobID, DatasetId: datasetID, ModelName: name, ModelVersion: version},
+			}}, nil
+		}
+		versions, err := client.ListModelVersions(context.Background(), organizationID, name)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, versions, test.ShouldHaveLength, 2)
+		test.That(t, versions[0].PackageID, test.ShouldEqual, organizationID+"/"+name)
+		test.That(t, versions[0].Version, test.ShouldEqual, version)
+		test.That(t, *versions[0].CreatedOn, test.ShouldEqual, start.UTC())
+		test.That(t, versions[0].TrainingJob.DatasetID, test.ShouldEqual, datasetID)
+		test.That(t, versions[1].TrainingJob, test.ShouldBeNil)
+	})
+
+	t.Run("DownloadModelVersion", func(t *testing.T) {
+		packagesClient.GetPackageFunc = func(
+			ctx context.Context, in *packagespb.GetPackageRequest, opts ...grpc.CallOption,
+		) (*packagespb.GetPackageResponse, error) {
+			test.That(t, in.Id, test.ShouldEqual, organizationID+"/"+name)
+			test.That(t, in.Version, test.ShouldEqual, version)
+			test.That(t, *in.IncludeUrl, test.ShouldBeTrue)
+			return &packagespb.GetPackageResponse{Package: &packagespb.Package{Url: server.URL}}, nil
+		}
+		dir := t.TempDir()
+		served = archive
+		model, err := client.DownloadModelVersion(context.Background(), organizationID, name, version, dir,
+			&DownloadModelVersionOptions{Checksum: checksum})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, model.Checksum, test.ShouldEqual, checksum)
+		contents, err := os.ReadFile(model.Path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, contents, test.ShouldResemble, archive)
+
+		_, err = client.DownloadModelVersion(context.Background(), organizationID, name, version, dir,
+			&DownloadModelVersionOptions{Checksum: "sha256:1234"})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "expected sha256:1234")
+
+		// corrupted downloads are removed.
+		served = []byte("corrupted archive")
+		otherDir := t.TempDir()
+		_, err = client.DownloadModelVersion(context.Background(), organizationID, name, version, otherDir, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "crc32c")
+		entries, err := os.ReadDir(otherDir)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, entries, test.ShouldBeEmpty)
+	})
+
+	t.Run("RegisterModelPackage", func(t *testing.T) {
+		config, err := structpb.NewStruct(map[string]interface{}{
+			"packages": []interface{}{
+				map[string]interface{}{"name": "detector", "package": "org/old", "type": "ml_model", "version": "1"},
+				map[string]interface{}{"name": "other", "package": "org/other", "type": "module", "version": "2"},
+			},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		appClient.GetRobotPartFunc = func(
+			ctx context.Context, in *apppb.GetRobotPartRequest, opts ...grpc.CallOption,
+		) (*apppb.GetRobotPartResponse, error) {
+			test.That(t, in.Id, test.ShouldEqual, partID)
+			return &apppb.GetRobotPartResponse{Part: &apppb.RobotPart{Id: partID, Name: "main", RobotConfig: config}}, nil
+		}
+		var updated *apppb.UpdateRobotPartRequest
+		appClient.UpdateRobotPartFunc = func(
+			ctx context.Context, in *apppb.UpdateRobotPartRequest, opts ...grpc.CallOption,
+		) (*apppb.UpdateRobotPartResponse, error) {
+			updated = in
+			return &apppb.UpdateRobotPartResponse{}, nil
+		}
+
+		model := &ModelVersion{PackageID: organizationID + "/" + name, Version: version}
+		err = client.RegisterModelPackage(context.Background(), partID, "detector", model, checksum)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, updated.Id, test.ShouldEqual, partID)
+		test.That(t, updated.Name, test.ShouldEqual, "main")
+		test.That(t, updated.RobotConfig.AsMap()["packages"], test.ShouldResemble, []interface{}{
+			map[string]interface{}{"name": "other", "package": "org/other", "type": "module", "version": "2"},
+			map[string]interface{}{
+				"name":         "detector",
+				"package":      organizationID + "/" + name,
+				"type":         "ml_model",
+				"version":      version,
+				"verification": map[string]interface{}{"checksum": checksum},
+			},
+		})
+
+		err = client.RegisterModelPackage(context.Background(), partID, "", model, "")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+)
+
+// RetryPolicy determines how calls to app that fail transiently, such as when app is unavailable
+// or rate limits the client, are retried. The wait between attempts starts at InitialBackoff and
+// doubles after every failed attempt up to MaxBackoff, with random jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the most times a call is made, including the first. Calls are not retried if
+	// it is 1 or less.
+	MaxAttempts int
+	// InitialBackoff defaults to 100ms if unspecified.
+	InitialBackoff time.Duration
+	// MaxBackoff defaults to 5s if unspecified.
+	MaxBackoff time.Duration
+	// Timeout, if positive, bounds each attempt. An attempt that times out is retried as long as the
+	// context of the call is not done.
+	Timeout time.Duration
+}
+
+// DefaultRetryPolicy returns a policy suitable for scripts that should ride out brief outages of app.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: defaultRetryInitialBackoff,
+		MaxBackoff:     defaultRetryMaxBackoff,
+		Timeout:        30 * time.Second,
+	}
+}
+
+// backoff returns how long to wait before the attempt after the given failed one.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	initial := p.InitialBackoff
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+	wait := initial
+	for i := 1; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxBackoff)
+	// half of the wait is jitter, so that clients failing together do not retry together.
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// idempotentMethodPrefixes and idempotentMethodSuffixes match the names of the methods of app that
+// only read, which can be retried without repeating their effects.
+var (
+	idempotentMethodPrefixes = []string{"Get", "List", "Search"}
+	idempotentMethodSuffixes = []string{"ByFilter", "ByIDs", "ByMQL", "BySQL"}
+)
+
+// isIdempotentMethod returns whether the full gRPC method, such as
+// "/viam.app.v1.AppService/GetRobotPart", only reads.
+func isIdempotentMethod(fullMethod string) bool {
+	name := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for _, prefix := range idempotentMethodPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	for _, suffix := range idempotentMethodSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isRetryableError returns whether a call, made with ctx, that failed with err may succeed if made
+// again. Deadlines are only exceeded while ctx is not done when an attempt times out.
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDialOption returns the option that applies the retry policies of options to unary calls,
+// or nil if it has none. Streaming calls are not retried since they may have been partially
+// consumed.
+func retryDialOption(options Options, logger logging.Logger) rpc.DialOption {
+	if options.RetryPolicy == nil && len(options.MethodRetryPolicies) == 0 {
+		return nil
+	}
+	return rpc.WithUnaryClientInterceptor(retryInterceptor(options, logger))
+}
+
+// retryInterceptor retries and times out unary calls according to the retry policies of options.
+func retryInterceptor(options Options, logger logging.Logger) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		policy, ok := options.MethodRetryPolicies[method]
+		if !ok && isIdempotentMethod(method) {
+			policy = options.RetryPolicy
+		}
+		if policy == nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		for attempt := 1; ; attempt++ {
+			err := invokeAttempt(ctx, policy.Timeout, func(ctx context.Context) error {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			})
+			if err == nil || attempt >= policy.MaxAttempts || !isRetryableError(ctx, err) {
+				return err
+			}
+			wait := policy.backoff(attempt)
+			logger.CDebugw(ctx, "retrying call to app", "method", method, "attempt", attempt, "wait", wait, "error", err)
+			if !utils.SelectContextOrWait(ctx, wait) {
+				return err
+			}
+		}
+	}
+}
+
+// invokeAttempt makes an attempt at a call, bounded by timeout if it is positive.
+func invokeAttempt(ctx context.Context, timeout time.Duration, invoke func(context.Context) error) error {
+	if timeout <= 0 {
+		return invoke(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return invoke(ctx)
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryInterceptor(t *testing.T) {
+	const (
+		getMethod    = "/viam.app.v1.AppService/GetRobotPart"
+		createMethod = "/viam.app.v1.AppService/CreateRobotPart"
+	)
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	interceptor := retryInterceptor(Options{
+		RetryPolicy:         policy,
+		MethodRetryPolicies: map[string]*RetryPolicy{"/viam.app.v1.AppService/ListRobots": nil},
+	}, logger)
+
+	// failing returns an invoker that fails with code the given number of times before succeeding.
+	failing := func(failures int, code codes.Code, calls *int) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			*calls++
+			if *calls <= failures {
+				return status.Error(code, "failed")
+			}
+			return nil
+		}
+	}
+
+	t.Run("retries idempotent calls", func(t *testing.T) {
+		var calls int
+		err := interceptor(context.Background(), getMethod, nil, nil, nil, failing(2, codes.Unavailable, &calls))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, calls, test.ShouldEqual, 3)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var calls int
+		err := interceptor(context.Background(), getMethod, nil, nil, nil, failing(5, codes.ResourceExhausted, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.ResourceExhausted)
+		test.That(t, calls, test.ShouldEqual, 3)
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		var calls int
+		err := interceptor(context.Background(), getMethod, nil, nil, nil, failing(1, codes.NotFound, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.NotFound)
+		test.That(t, calls, test.ShouldEqual, 1)
+	})
+
+	t.Run("does not retry calls that change state", func(t *testing.T) {
+		var calls int
+		err := interceptor(context.Background(), createMethod, nil, nil, nil, failing(1, codes.Unavailable, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unavailable)
+		test.That(t, calls, test.ShouldEqual, 1)
+	})
+
+	t.Run("per-method overrides", func(t *testing.T) {
+		var calls int
+		err := interceptor(context.Background(), "/viam.app.v1.AppService/ListRobots", nil, nil, nil,
+			failing(1, codes.Unavailable, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unavailable)
+		test.That(t, calls, test.ShouldEqual, 1)
+
+		override := retryInterceptor(Options{MethodRetryPolicies: map[string]*RetryPolicy{createMethod: policy}}, logger)
+		calls = 0
+		err = override(context.Background(), createMethod, nil, nil, nil, failing(1, codes.Unavailable, &calls))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, calls, test.ShouldEqual, 2)
+	})
+
+	t.Run("timeouts", func(t *testing.T) {
+		timeout := retryInterceptor(Options{RetryPolicy: &RetryPolicy{
+			MaxAttempts:    2,
+			InitialBackoff: time.Millisecond,
+			Timeout:        10 * time.Millisecond,
+		}}, logger)
+		var calls int
+		err := timeout(context.Background(), getMethod, nil, nil, nil,
+			func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				calls++
+				if calls == 1 {
+					<-ctx.Done()
+					return status.FromContextError(ctx.Err()).Err()
+				}
+				return nil
+			})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, calls, test.ShouldEqual, 2)
+	})
+
+	t.Run("canceled calls are not retried", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int
+		err := interceptor(ctx, getMethod, nil, nil, nil,
+			func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				calls++
+				cancel()
+				return status.Error(codes.Unavailable, "failed")
+			})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unavailable)
+		test.That(t, calls, test.ShouldEqual, 1)
+	})
+}
+
+func TestIsIdempotentMethod(t *testing.T) {
+	test.That(t, isIdempotentMethod("/viam.app.v1.AppService/GetOrganization"), test.ShouldBeTrue)
+	test.That(t, isIdempotentMethod("/viam.app.v1.AppService/ListOrganizations"), test.ShouldBeTrue)
+	test.That(t, isIdempotentMethod("/viam.app.data.v1.DataService/TabularDataByFilter"), test.ShouldBeTrue)
+	test.That(t, isIdempotentMethod("/viam.app.v1.AppService/DeleteRobot"), test.ShouldBeFalse)
+	test.That(t, isIdempotentMethod("/viam.app.v1.AppService/UpdateRobotPart"), test.ShouldBeFalse)
+}
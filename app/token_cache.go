@@ -0,0 +1,281 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	goutils "go.viam.com/utils"
+	rpcpb "go.viam.com/utils/proto/rpc/v1"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+// tokenRefreshMargin is how long before it expires an access token is replaced, so that calls
+// made just before it expires do not fail.
+const tokenRefreshMargin = time.Minute
+
+// A TokenCache persists the access tokens a ViamClient authenticates with, so that a process can
+// reuse the token of an earlier one instead of authenticating again when it starts. Tokens are
+// cached by key, which identifies the app and the entity they authenticate.
+type TokenCache interface {
+	// Load returns the token cached under key, or an empty string if there is none.
+	Load(key string) (string, error)
+	// Store caches token under key.
+	Store(key, token string) error
+}
+
+// FileTokenCache is a TokenCache backed by a JSON file that only its owner can read and write.
+type FileTokenCache struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileTokenCache returns a FileTokenCache backed by the file at path, which is created when a
+// token is first stored.
+func NewFileTokenCache(path string) *FileTokenCache {
+	return &FileTokenCache{path: path}
+}
+
+// DefaultTokenCachePath returns the path of the token cache file in the viam directory of the user.
+func DefaultTokenCachePath() string {
+	return filepath.Join(utils.ViamDotDir, "app_tokens.json")
+}
+
+// Load returns the token cached under key. Tokens are not loaded from a file that other users can
+// access, since they may have been tampered with or leaked.
+func (c *FileTokenCache) Load(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tokens, err := c.readLocked()
+	if err != nil {
+		return "", err
+	}
+	return tokens[key], nil
+}
+
+// Store caches token under key, replacing the file atomically so that processes sharing it never
+// read a partial file.
+func (c *FileTokenCache) Store(key, token string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tokens, err := c.readLocked()
+	if err != nil {
+		// a cache that cannot be read is replaced.
+		tokens = map[string]string{}
+	}
+	tokens[key] = token
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), "."+filepath.Base(c.path)+"-*")
+	if err != nil {
+		return err
+	}
+	// temporary files are created readable and writable only by their owner.
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		goutils.UncheckedError(os.Remove(tmp.Name()))
+	}
+	return err
+}
+
+func (c *FileTokenCache) readLocked() (map[string]string, error) {
+	info, err := os.Stat(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// windows does not report unix permissions.
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("token cache %q can be accessed by other users; its permissions must be 0600", c.path)
+	}
+	//nolint:gosec
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	tokens := map[string]string{}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// tokenSource provides the access token calls to app are made with, loading it from a TokenCache
+// and authenticating again when it is about to expire or is rejected.
+type tokenSource struct {
+	entity string
+	creds  rpc.Credentials
+	cache  TokenCache
+	key    string
+	logger logging.Logger
+	// authClient returns the client to authenticate with over a connection.
+	authClient func(grpc.ClientConnInterface) rpcpb.AuthServiceClient
+
+	mu     sync.Mutex
+	loaded bool
+	token  string
+	expiry time.Time
+}
+
+func newTokenSource(host string, options Options, logger logging.Logger) *tokenSource {
+	return &tokenSource{
+		entity:     options.Entity,
+		creds:      options.Credentials,
+		cache:      options.TokenCache,
+		key:        host + "/" + options.Entity,
+		logger:     logger,
+		authClient: rpcpb.NewAuthServiceClient,
+	}
+}
+
+// accessToken returns a token that is not about to expire, authenticating through cc if needed.
+func (s *tokenSource) accessToken(ctx context.Context, cc grpc.ClientConnInterface) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.loaded {
+		s.loaded = true
+		token, err := s.cache.Load(s.key)
+		if err != nil {
+			s.logger.CWarnw(ctx, "failed to load cached access token, authenticating instead", "error", err)
+		}
+		s.setLocked(token)
+	}
+	if s.token != "" && time.Until(s.expiry) > tokenRefreshMargin {
+		return s.token, nil
+	}
+
+	resp, err := s.authClient(cc).Authenticate(ctx, &rpcpb.AuthenticateRequest{
+		Entity: s.entity,
+		Credentials: &rpcpb.Credentials{
+			Type:    string(s.creds.Type),
+			Payload: s.creds.Payload,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	s.setLocked(resp.AccessToken)
+	if err := s.cache.Store(s.key, resp.AccessToken); err != nil {
+		s.logger.CWarnw(ctx, "failed to cache access token", "error", err)
+	}
+	return s.token, nil
+}
+
+// setLocked sets the token, which is treated as expired if its expiry cannot be read.
+func (s *tokenSource) setLocked(token string) {
+	s.token = token
+	s.expiry = time.Time{}
+	var claims jwt.RegisteredClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err == nil && claims.ExpiresAt != nil {
+		s.expiry = claims.ExpiresAt.Time
+	}
+}
+
+// invalidate drops token if it is still the token in use, such as after app rejected it.
+func (s *tokenSource) invalidate(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == token {
+		s.token = ""
+	}
+}
+
+// authContext returns ctx with the access token as the authorization of outgoing calls.
+func (s *tokenSource) authContext(ctx context.Context, cc grpc.ClientConnInterface) (context.Context, string, error) {
+	token, err := s.accessToken(ctx, cc)
+	if err != nil {
+		return nil, "", err
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), token, nil
+}
+
+// isAuthMethod returns whether method authenticates, and so must not be authenticated itself.
+func isAuthMethod(method string) bool {
+	return method == rpcpb.AuthService_Authenticate_FullMethodName
+}
+
+// tokenDialOptions returns the options that authenticate calls with the tokens of s.
+func (s *tokenSource) tokenDialOptions() []rpc.DialOption {
+	return []rpc.DialOption{
+		rpc.WithUnaryClientInterceptor(s.unaryInterceptor),
+		rpc.WithStreamClientInterceptor(s.streamInterceptor),
+	}
+}
+
+// unaryInterceptor authenticates unary calls. A call that app rejects as unauthenticated is made
+// again once with a new token.
+func (s *tokenSource) unaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if isAuthMethod(method) {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		authCtx, token, authErr := s.authContext(ctx, cc)
+		if authErr != nil {
+			return authErr
+		}
+		err = invoker(authCtx, method, req, reply, cc, opts...)
+		if status.Code(err) != codes.Unauthenticated {
+			return err
+		}
+		s.invalidate(token)
+	}
+	return err
+}
+
+// streamInterceptor authenticates streams. A stream that app rejects as unauthenticated is not
+// opened again, but the next call is made with a new token.
+func (s *tokenSource) streamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	authCtx, token, err := s.authContext(ctx, cc)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := streamer(authCtx, desc, cc, method, opts...)
+	if status.Code(err) == codes.Unauthenticated {
+		s.invalidate(token)
+	}
+	return stream, err
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.viam.com/test"
+	rpcpb "go.viam.com/utils/proto/rpc/v1"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestToken(t *testing.T, expiresIn time.Duration) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		ID:        time.Now().String(),
+	}).SignedString([]byte("secret"))
+	test.That(t, err, test.ShouldBeNil)
+	return token
+}
+
+// authConn is a connection that only serves authentication, with the token in its token field.
+type authConn struct {
+	grpc.ClientConnInterface
+	token          string
+	authentication int
+}
+
+func (c *authConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	req := args.(*rpcpb.AuthenticateRequest)
+	if req.Entity != testAPIKeyID || req.Credentials.Payload != testAPIKey {
+		return status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+	c.authentication++
+	reply.(*rpcpb.AuthenticateResponse).AccessToken = c.token
+	return nil
+}
+
+func TestFileTokenCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "viam", "tokens.json")
+	cache := NewFileTokenCache(path)
+	token, err := cache.Load("key")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, token, test.ShouldBeEmpty)
+
+	test.That(t, cache.Store("key", "token"), test.ShouldBeNil)
+	test.That(t, cache.Store("other", "other token"), test.ShouldBeNil)
+	token, err = NewFileTokenCache(path).Load("key")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, token, test.ShouldEqual, "token")
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+	info, err := os.Stat(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, info.Mode().Perm(), test.ShouldEqual, os.FileMode(0o600))
+
+	test.That(t, os.Chmod(path, 0o644), test.ShouldBeNil)
+	_, err = cache.Load("key")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "other users")
+
+	// storing replaces the file with one only its owner can access.
+	test.That(t, cache.Store("key", "new token"), test.ShouldBeNil)
+	token, err = cache.Load("key")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, token, test.ShouldEqual, "new token")
+	token, err = cache.Load("other")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, token, test.ShouldBeEmpty)
+}
+
+func TestTokenSource(t *testing.T) {
+	ctx := context.Background()
+	options := Options{
+		Entity:      testAPIKeyID,
+		Credentials: rpc.Credentials{Type: rpc.CredentialsTypeAPIKey, Payload: testAPIKey},
+		TokenCache:  NewFileTokenCache(filepath.Join(t.TempDir(), "tokens.json")),
+	}
+	conn := &authConn{token: newTestToken(t, time.Hour)}
+	newSource := func(host string, options Options) *tokenSource {
+		source := newTokenSource(host, options, logger)
+		source.authClient = func(grpc.ClientConnInterface) rpcpb.AuthServiceClient { return rpcpb.NewAuthServiceClient(conn) }
+		return source
+	}
+
+	source := newSource("app.viam.com:443", options)
+	token, err := source.accessToken(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, token, test.ShouldEqual, conn.token)
+	test.That(t, conn.authentication, test.ShouldEqual, 1)
+
+	t.Run("cached across clients", func(t *testing.T) {
+		token, err := newSource("app.viam.com:443", options).accessToken(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, token, test.ShouldEqual, conn.token)
+		test.That(t, conn.authentication, test.ShouldEqual, 1)
+
+		// tokens are cached per app.
+		_, err = newSource("app.viam.dev:443", options).accessToken(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, conn.authentication, test.ShouldEqual, 2)
+	})
+
+	t.Run("refreshed before expiring", func(t *testing.T) {
+		expiring := newTestToken(t, tokenRefreshMargin/2)
+		test.That(t, options.TokenCache.Store("app.viam.com:443/"+testAPIKeyID, expiring), test.ShouldBeNil)
+		conn.authentication = 0
+		token, err := newSource("app.viam.com:443", options).accessToken(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, token, test.ShouldEqual, conn.token)
+		test.That(t, conn.authentication, test.ShouldEqual, 1)
+	})
+
+	t.Run("rejected tokens are replaced", func(t *testing.T) {
+		revoked := conn.token
+		conn.token = newTestToken(t, time.Hour)
+		conn.authentication = 0
+		var authorizations []string
+		invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			authorizations = append(authorizations, md.Get("authorization")...)
+			if md.Get("authorization")[0] == "Bearer "+revoked {
+				return status.Error(codes.Unauthenticated, "token revoked")
+			}
+			return nil
+		}
+		err := source.unaryInterceptor(ctx, "/viam.app.v1.AppService/GetUserIDByEmail", nil, nil, nil, invoker)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, authorizations, test.ShouldResemble, []string{"Bearer " + revoked, "Bearer " + conn.token})
+		test.That(t, conn.authentication, test.ShouldEqual, 1)
+
+		token, err := newSource("app.viam.com:443", options).accessToken(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, token, test.ShouldEqual, conn.token)
+		test.That(t, conn.authentication, test.ShouldEqual, 1)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		badOptions := options
+		badOptions.Credentials.Payload = "wrong"
+		badOptions.TokenCache = NewFileTokenCache(filepath.Join(t.TempDir(), "tokens.json"))
+		err := newSource("app.viam.com:443", badOptions).unaryInterceptor(ctx, "/viam.app.v1.AppService/GetUserIDByEmail",
+			nil, nil, nil, func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				t.Fatal("unauthenticated call made")
+				return nil
+			})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unauthenticated)
+	})
+}
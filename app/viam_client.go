@@ -5,8 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"slices"
 	"strings"
 
 	"go.viam.com/utils/rpc"
@@ -23,6 +25,8 @@ type ViamClient struct {
 	dataClient         *DataClient
 	mlTrainingClient   *MLTrainingClient
 	provisioningClient *ProvisioningClient
+	// downloadHeaders authenticate downloads from app outside of gRPC.
+	downloadHeaders http.Header
 }
 
 // Options has the options necessary to connect through gRPC.
@@ -31,6 +35,17 @@ type Options struct {
 	Entity      string
 	Credentials rpc.Credentials
 	DialOptions []rpc.DialOption
+	// RetryPolicy, if set, retries calls that only read, such as Get and List calls, when they fail
+	// transiently. Calls that change state are not retried unless given a policy in
+	// MethodRetryPolicies.
+	RetryPolicy *RetryPolicy
+	// MethodRetryPolicies overrides RetryPolicy for calls to the given full gRPC methods, such as
+	// "/viam.app.v1.AppService/CreateRobotPart". A nil policy disables retries of a method.
+	MethodRetryPolicies map[string]*RetryPolicy
+	// TokenCache, if set, persists the access token the client authenticates with using Entity and
+	// Credentials, so that it is reused across processes until it is about to expire, when it is
+	// refreshed. See NewFileTokenCache.
+	TokenCache TokenCache
 }
 
 var dialDirectGRPC = rpc.DialDirectGRPC
@@ -50,9 +65,15 @@ func CreateViamClientWithOptions(ctx context.Context, options Options, logger lo
 		return nil, err
 	}
 
+	var retryOpts []rpc.DialOption
+	if opt := retryDialOption(options, logger); opt != nil {
+		retryOpts = append(retryOpts, opt)
+	}
+
 	var conn rpc.ClientConn
+	downloadHeaders := http.Header{}
 	if len(options.DialOptions) > 0 {
-		conn, err = dialDirectGRPC(ctx, serviceHost.Host, logger, options.DialOptions...)
+		conn, err = dialDirectGRPC(ctx, serviceHost.Host, logger, append(slices.Clone(options.DialOptions), retryOpts...)...)
 		if err != nil {
 			return nil, err
 		}
@@ -60,13 +81,20 @@ func CreateViamClientWithOptions(ctx context.Context, options Options, logger lo
 		if options.Credentials.Payload == "" || options.Entity == "" {
 			return nil, errors.New("entity and payload cannot be empty")
 		}
-		opts := rpc.WithEntityCredentials(options.Entity, options.Credentials)
-		conn, err = dialDirectGRPC(ctx, serviceHost.Host, logger, opts)
+		authOpts := []rpc.DialOption{rpc.WithEntityCredentials(options.Entity, options.Credentials)}
+		if options.TokenCache != nil {
+			authOpts = newTokenSource(serviceHost.Host, options, logger).tokenDialOptions()
+		}
+		conn, err = dialDirectGRPC(ctx, serviceHost.Host, logger, append(authOpts, retryOpts...)...)
 		if err != nil {
 			return nil, err
 		}
+		if options.Credentials.Type == rpc.CredentialsTypeAPIKey {
+			downloadHeaders.Add("key_id", options.Entity)
+			downloadHeaders.Add("key", options.Credentials.Payload)
+		}
 	}
-	return &ViamClient{conn: conn}, nil
+	return &ViamClient{conn: conn, downloadHeaders: downloadHeaders}, nil
 }
 
 // WithDialOptions creates a new Options struct with the given dial options.
@@ -157,7 +185,7 @@ func (c *ViamClient) MLTrainingClient() *MLTrainingClient {
 	if c.mlTrainingClient != nil {
 		return c.mlTrainingClient
 	}
-	c.mlTrainingClient = newMLTrainingClient(c.conn)
+	c.mlTrainingClient = newMLTrainingClient(c.conn, c.downloadHeaders)
 	return c.mlTrainingClient
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/robot/packages"
 )
 
 // CLI flags.
@@ -159,6 +160,8 @@ const (
 
 	packageFlagFramework = "model-framework"
 	packageFlagModelType = "model-type"
+	packageFlagMirror    = "mirror"
+	packageFlagBlockSize = "block-size"
 
 	oauthAppFlagClientID             = "client-id"
 	oauthAppFlagClientName           = "client-name"
@@ -174,10 +177,22 @@ const (
 
 	cpFlagRecursive = "recursive"
 	cpFlagPreserve  = "preserve"
+	cpFlagResume    = "resume"
+	cpFlagSync      = "sync"
+	cpFlagInclude   = "include"
+	cpFlagExclude   = "exclude"
+	cpFlagBwLimit   = "bwlimit"
+
+	execFlagEnv     = "env"
+	execFlagDir     = "dir"
+	execFlagTimeout = "timeout"
 
 	tunnelFlagLocalPort       = "local-port"
 	tunnelFlagDestinationPort = "destination-port"
 
+	supportBundleFlagNetworkChecks = "network-checks"
+	supportBundleFlagUpload        = "upload"
+
 	organizationFlagSupportEmail = "support-email"
 	organizationFlagLogoPath     = "logo-path"
 
@@ -3125,6 +3140,38 @@ Organization and location are required flags if the machine/part name are not un
 							Flags:     commonPartFlags,
 							Action:    createActionCommandWithT[robotsPartShellArgs](RobotsPartShellAction),
 						},
+						{
+							Name:  "exec",
+							Usage: "run a command on a machine part without a terminal",
+							Description: `
+In order to use the exec command, the machine must have a valid shell type service. The command is run directly rather
+than by a shell, its stdout and stderr are printed separately once it finishes, and the exec command fails with its exit
+code if it does not succeed. Relative directories are relative to the home directory of the user running the process.
+Organization and location are required flags if the machine/part name are not unique across your account.
+
+List a directory on the machine, giving up after 10 seconds:
+'viam machines part exec --part "m1-main" --timeout 10s -- ls -la /var/log'
+
+Run a script with an environment variable set:
+'viam machines part exec --part "m1-main" --env LEVEL=debug --dir scripts -- ./check.sh'
+`,
+							UsageText: createUsageText("machines part exec", []string{generalFlagPart}, true, false, "-- <command> [args...]"),
+							Flags: append(commonPartFlags, []cli.Flag{
+								&cli.StringSliceFlag{
+									Name:  execFlagEnv,
+									Usage: "environment variables to set for the command, as KEY=VALUE",
+								},
+								&cli.StringFlag{
+									Name:  execFlagDir,
+									Usage: "directory to run the command in",
+								},
+								&cli.DurationFlag{
+									Name:  execFlagTimeout,
+									Usage: "kill the command if it runs longer than this",
+								},
+							}...),
+							Action: createActionCommandWithT[machinesPartExecArgs](MachinesPartExecAction),
+						},
 						{
 							Name:      "list",
 							Usage:     "list parts on a machine",
@@ -3176,6 +3223,10 @@ Recursively copy a directory from the machine to a local destination with the sa
 
 Copy multiple files from the machine to a local destination with recursion and keep original permissions and metadata:
 'viam machine part cp --part "m1-main" -r -p machine:my_dir machine:my_file ~/some/existing/dir/'
+
+Sync the logs in a directory on the machine into a local directory over a slow link, copying only new or changed
+files, resuming files whose copy was interrupted, and using at most 256 KiB/s:
+'viam machine part cp --part "m1-main" -r --sync --resume --include "*.log" --bwlimit 256 machine:logs ~/some/existing/dir/'
 `,
 							UsageText: createUsageText(
 								"machines part cp",
@@ -3199,6 +3250,26 @@ Copy multiple files from the machine to a local destination with recursion and k
 									Aliases: []string{"n"},
 									Usage:   "hide progress of the file transfer",
 								},
+								&cli.BoolFlag{
+									Name:  cpFlagResume,
+									Usage: "keep partially copied files when a copy is interrupted and continue them on the next copy",
+								},
+								&cli.BoolFlag{
+									Name:  cpFlagSync,
+									Usage: "skip files the destination already has with the same size and modification time (implies --preserve)",
+								},
+								&cli.StringSliceFlag{
+									Name:  cpFlagInclude,
+									Usage: "only copy files whose relative path or name matches one of these glob patterns",
+								},
+								&cli.StringSliceFlag{
+									Name:  cpFlagExclude,
+									Usage: "skip files and directories whose relative path or name matches one of these glob patterns",
+								},
+								&cli.IntFlag{
+									Name:  cpFlagBwLimit,
+									Usage: "limit the bandwidth used to send file data, in KiB/s",
+								},
 							}...),
 							Action: createActionCommandWithT[machinesPartCopyFilesArgs](MachinesPartCopyFilesAction),
 						},
@@ -3219,6 +3290,32 @@ Note: There is no progress meter while copying is in progress.
 							Flags:  commonPartFlags,
 							Action: createActionCommandWithT(MachinesPartGetFTDCAction),
 						},
+						{
+							Name:  "support-bundle",
+							Usage: "collect a support bundle from a machine part",
+							Description: `
+Collect a support bundle from a machine part: a single tar.gz archive of its recent logs, its
+config with secrets masked, the states of its resources and modules, and the versions of its
+modules. Organization and location are required flags if using name (rather than ID) for the part.
+If [target] is not specified then the bundle will be saved to the current working directory.
+`,
+							UsageText: createUsageText(
+								"machines part support-bundle",
+								[]string{generalFlagPart},
+								true, false,
+								"[target]"),
+							Flags: append(commonPartFlags, []cli.Flag{
+								&cli.BoolFlag{
+									Name:  supportBundleFlagNetworkChecks,
+									Usage: "run DNS and packet loss checks from the machine and include their results",
+								},
+								&cli.BoolFlag{
+									Name:  supportBundleFlagUpload,
+									Usage: "also upload the bundle to the cloud via the data manager of the machine",
+								},
+							}...),
+							Action: createActionCommandWithT[machinesPartSupportBundleArgs](MachinesPartSupportBundleAction),
+						},
 						{
 							Name:  "tunnel",
 							Usage: "tunnel connections to the specified port on a machine part",
@@ -4323,6 +4420,69 @@ This won't work unless you have an existing installation of our GitHub app on yo
 					},
 					Action: createActionCommandWithT[packageUploadArgs](PackageUploadAction),
 				},
+				{
+					Name: "preseed",
+					Usage: "download the packages of a machine config into a packages directory, " +
+						"so that the machine starts without downloading them",
+					UsageText: createUsageText("packages preseed", []string{generalFlagConfig}, true, false),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:      generalFlagConfig,
+							Required:  true,
+							Usage:     "path to the machine config whose packages are downloaded",
+							TakesFile: true,
+						},
+						&cli.StringFlag{
+							Name:        generalFlagDestination,
+							Usage:       "packages directory of the machine, or the root of the mirror with --mirror",
+							DefaultText: "~/.viam/packages",
+							TakesFile:   true,
+						},
+						&cli.BoolFlag{
+							Name:  packageFlagMirror,
+							Usage: "write the package archives in the layout of a package mirror instead of installing them",
+						},
+					},
+					Action: createActionCommandWithT[packagePreseedArgs](PackagePreseedAction),
+				},
+				{
+					Name: "block-index",
+					Usage: "write the block index of a package archive, which lets machines upgrade a package " +
+						"pulled from an OCI registry by downloading only the blocks that changed",
+					UsageText: createUsageText("packages block-index", []string{generalFlagPath}, true, false),
+					Description: `
+Delta updates only apply to packages pulled from an OCI registry. Compress the archive with
+"gzip --rsyncable" so that a change only affects the blocks around it, then push the index as a layer
+of the package next to the archive, for example:
+
+viam packages block-index --path=package.tar.gz
+oras push ghcr.io/org/package:1.2.3 \
+  package.tar.gz:application/vnd.oci.image.layer.v1.tar+gzip \
+  package.tar.gz.blockindex.json:application/vnd.viam.package.block-index.v1+json
+
+Packages from the package service are always downloaded in full.
+`,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:      generalFlagPath,
+							Required:  true,
+							Usage:     "path to the package archive",
+							TakesFile: true,
+						},
+						&cli.StringFlag{
+							Name:        generalFlagDestination,
+							Usage:       "path the block index is written to",
+							DefaultText: "<path>.blockindex.json",
+							TakesFile:   true,
+						},
+						&cli.IntFlag{
+							Name:  packageFlagBlockSize,
+							Usage: "size of the blocks in bytes",
+							Value: packages.DefaultBlockSize,
+						},
+					},
+					Action: createActionCommandWithT[packageBlockIndexArgs](PackageBlockIndexAction),
+				},
 			},
 		},
 		{
@@ -3694,6 +3694,74 @@ func RobotsPartShellAction(ctx context.Context, cmd *cli.Command, args robotsPar
 	)
 }
 
+type machinesPartExecArgs struct {
+	Organization string
+	Location     string
+	Machine      string
+	Part         string
+	Env          []string
+	Dir          string
+	Timeout      time.Duration
+}
+
+// MachinesPartExecAction is the corresponding Action for 'machines part exec'.
+func MachinesPartExecAction(ctx context.Context, cmd *cli.Command, args machinesPartExecArgs) error {
+	req := shell.ExecRequest{
+		Args:      cmd.Args().Slice(),
+		Dir:       args.Dir,
+		TimeoutMs: args.Timeout.Milliseconds(),
+	}
+	for _, kv := range args.Env {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return errors.Errorf("expected --%s to be KEY=VALUE but got %q", execFlagEnv, kv)
+		}
+		if req.Env == nil {
+			req.Env = map[string]string{}
+		}
+		req.Env[k] = v
+	}
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	client, err := newViamClient(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	globalArgs, err := getGlobalArgs(cmd)
+	if err != nil {
+		return err
+	}
+	logger := globalArgs.createLogger()
+
+	shellSvc, closeClient, err := client.connectToShellService(
+		ctx, args.Organization, args.Location, args.Machine, args.Part, globalArgs.Debug, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(closeClient(ctx))
+	}()
+
+	result, err := shell.Exec(ctx, shellSvc, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.Root().Writer, result.Stdout)    //nolint:errcheck
+	fmt.Fprint(cmd.Root().ErrWriter, result.Stderr) //nolint:errcheck
+	if result.Truncated {
+		warningf(cmd.Root().ErrWriter, "output was truncated to the first %d bytes", shell.MaxExecOutput)
+	}
+	switch {
+	case result.TimedOut:
+		return errors.Errorf("command timed out after %s", args.Timeout)
+	case result.ExitCode != 0:
+		return errors.Errorf("command exited with code %d", result.ExitCode)
+	}
+	return nil
+}
+
 var (
 	errNoFiles                         = errors.New("must provide files to copy")
 	errLastArgOfFromMissing            = errors.New("expected last argument to be <copy to path>")
@@ -3717,6 +3785,11 @@ type machinesPartCopyFilesArgs struct {
 	Recursive    bool
 	Preserve     bool
 	NoProgress   bool
+	Resume       bool
+	Sync         bool
+	Include      []string
+	Exclude      []string
+	BwLimit      int
 }
 
 type wrongNumArgsError struct {
@@ -3831,6 +3904,18 @@ func (c *viamClient) machinesPartCopyFilesAction(
 	if err != nil {
 		return err
 	}
+	copyOpts := shell.CopyOptions{
+		Include:     flagArgs.Include,
+		Exclude:     flagArgs.Exclude,
+		Sync:        flagArgs.Sync,
+		Resume:      flagArgs.Resume,
+		BytesPerSec: int64(flagArgs.BwLimit) * 1024,
+	}
+	if err := copyOpts.Validate(); err != nil {
+		return err
+	}
+	// syncing compares modification times, so they have to be preserved.
+	preserve := flagArgs.Preserve || flagArgs.Sync
 
 	globalArgs, err := getGlobalArgs(cmd)
 	if err != nil {
@@ -3851,9 +3936,10 @@ func (c *viamClient) machinesPartCopyFilesAction(
 					flagArgs.Part,
 					globalArgs.Debug,
 					flagArgs.Recursive,
-					flagArgs.Preserve,
+					preserve,
 					paths,
 					destination,
+					copyOpts,
 					logger,
 				)
 			}
@@ -3867,9 +3953,10 @@ func (c *viamClient) machinesPartCopyFilesAction(
 					flagArgs.Part,
 					globalArgs.Debug,
 					flagArgs.Recursive,
-					flagArgs.Preserve,
+					preserve,
 					paths,
 					destination,
+					copyOpts,
 					logger,
 					flagArgs.NoProgress,
 				)
@@ -3898,7 +3985,7 @@ func (c *viamClient) machinesPartCopyFilesAction(
 				statusErr.Message() == shell.ErrMsgDirectoryCopyRequestNoRecursion {
 				return errDirectoryCopyRequestNoRecursion
 			}
-			if statusErr.Code() == codes.NotFound {
+			if statusErr.Code() == codes.NotFound || statusErr.Code() == codes.FailedPrecondition {
 				return errors.WithMessage(err, "copy aborted")
 			}
 		}
@@ -3958,6 +4045,7 @@ func (c *viamClient) machinesPartGetFTDCAction(
 		false,
 		[]string{src},
 		targetPath,
+		shell.CopyOptions{},
 		logger,
 	); err != nil {
 		if statusErr := status.Convert(err); statusErr != nil &&
@@ -5544,11 +5632,7 @@ func (c *viamClient) startRobotPartShell(
 
 	getWinChMsg := func() map[string]interface{} {
 		cols, rows := consolesize.GetConsoleSize()
-		return map[string]interface{}{
-			"message": "window-change",
-			"cols":    cols,
-			"rows":    rows,
-		}
+		return shell.NewWindowChange(cols, rows)
 	}
 
 	input, inputOOB, output, err := shellSvc.Shell(ctx, map[string]interface{}{
@@ -5717,6 +5801,10 @@ func (c *viamClient) retryableCopy(
 				warningf(cmd.Root().ErrWriter, "Copy failed because the destination path does not exist: %s", s.Message())
 				_ = pm.Fail(attemptStepID, copyErr) //nolint:errcheck
 				return attempt, copyErr
+			} else if s.Code() == codes.FailedPrecondition {
+				warningf(cmd.Root().ErrWriter, "Copy could not resume: %s", s.Message())
+				_ = pm.Fail(attemptStepID, copyErr) //nolint:errcheck
+				return attempt, copyErr
 			}
 		}
 
@@ -5754,6 +5842,7 @@ func (c *viamClient) copyFilesToMachine(
 	preserve bool,
 	paths []string,
 	destination string,
+	opts shell.CopyOptions,
 	logger logging.Logger,
 	noProgress bool,
 ) error {
@@ -5761,7 +5850,7 @@ func (c *viamClient) copyFilesToMachine(
 	if err != nil {
 		return err
 	}
-	return c.copyFilesToMachineInner(ctx, shellSvc, closeClient, allowRecursion, preserve, paths, destination, noProgress)
+	return c.copyFilesToMachineInner(ctx, shellSvc, closeClient, allowRecursion, preserve, paths, destination, opts, noProgress)
 }
 
 // copyFilesToFqdn is a copyFilesToMachine variant that makes use of pre-fetched part FQDN.
@@ -5780,7 +5869,8 @@ func (c *viamClient) copyFilesToFqdn(
 	if err != nil {
 		return err
 	}
-	return c.copyFilesToMachineInner(ctx, shellSvc, closeClient, allowRecursion, preserve, paths, destination, noProgress)
+	return c.copyFilesToMachineInner(
+		ctx, shellSvc, closeClient, allowRecursion, preserve, paths, destination, shell.CopyOptions{}, noProgress)
 }
 
 // copyFilesToMachineInner is the common logic for both copyFiles variants.
@@ -5792,18 +5882,31 @@ func (c *viamClient) copyFilesToMachineInner(
 	preserve bool,
 	paths []string,
 	destination string,
+	opts shell.CopyOptions,
 	noProgress bool,
 ) error {
 	defer func() {
 		utils.UncheckedError(closeClient(ctx))
 	}()
 
+	if opts.NeedsManifest() {
+		// what the machine already has decides which files are skipped or resumed.
+		manifest, err := shell.FetchCopyManifest(ctx, shellSvc, destination, shell.CopyManifestNames(paths))
+		if err != nil {
+			return err
+		}
+		opts.Manifest = manifest
+	}
+	// prepare a factory that understands the file copying service (RPC or not).
+	copyFactory, err := shell.NewCopyFileToMachineFactoryWithOptions(destination, preserve, shellSvc, opts)
+	if err != nil {
+		return err
+	}
+
 	if noProgress {
-		// prepare a factory that understands the file copying service (RPC or not).
-		copyFactory := shell.NewCopyFileToMachineFactory(destination, preserve, shellSvc)
 		// make a reader copier that just does the traversal and copy work for us. Think of
 		// this as a tee reader.
-		readCopier, err := shell.NewLocalFileReadCopier(paths, allowRecursion, false, copyFactory)
+		readCopier, err := shell.NewLocalFileReadCopierWithOptions(paths, allowRecursion, false, copyFactory, opts)
 		if err != nil {
 			return err
 		}
@@ -5861,12 +5964,12 @@ func (c *viamClient) copyFilesToMachineInner(
 
 	// Wrap the copy factory to track progress
 	progressFactory := &progressTrackingFactory{
-		factory:    shell.NewCopyFileToMachineFactory(destination, preserve, shellSvc),
+		factory:    copyFactory,
 		onProgress: progressFunc,
 	}
 
 	// Create a new read copier with the progress tracking factory
-	readCopier, err := shell.NewLocalFileReadCopier(paths, allowRecursion, false, progressFactory)
+	readCopier, err := shell.NewLocalFileReadCopierWithOptions(paths, allowRecursion, false, progressFactory, opts)
 	if err != nil {
 		return err
 	}
@@ -5969,6 +6072,7 @@ func (c *viamClient) copyFilesFromMachine(
 	preserve bool,
 	paths []string,
 	destination string,
+	opts shell.CopyOptions,
 	logger logging.Logger,
 ) error {
 	shellSvc, closeClient, err := c.connectToShellService(ctx, orgStr, locStr, robotStr, partStr, debug, logger)
@@ -5979,14 +6083,33 @@ func (c *viamClient) copyFilesFromMachine(
 		utils.UncheckedError(closeClient(ctx))
 	}()
 
+	if opts.Resume {
+		// a machine that cannot describe its files predates resumable copies and would resend
+		// files in full, which must not be appended to what was received before.
+		if _, err := shell.FetchCopyManifest(ctx, shellSvc, "", nil); err != nil {
+			return errors.Wrap(err, "machine does not support resuming copies")
+		}
+	}
+	if opts.NeedsManifest() {
+		// what we already have decides which files the machine skips or resumes.
+		opts.Manifest, err = shell.BuildCopyManifest(destination, shell.CopyManifestNames(paths), false)
+		if err != nil {
+			return err
+		}
+	}
+	extra, err := opts.ToExtra(nil)
+	if err != nil {
+		return err
+	}
+
 	// prepare a factory that understands how to work with our local filesystem.
-	factory, err := shell.NewLocalFileCopyFactory(destination, preserve, false)
+	factory, err := shell.NewLocalFileCopyFactoryWithOptions(destination, preserve, false, opts)
 	if err != nil {
 		return err
 	}
 
 	// let the shell service figure out how to grab the files for and pass them to our copier.
-	return shellSvc.CopyFilesFromMachine(ctx, paths, allowRecursion, preserve, factory, nil)
+	return shellSvc.CopyFilesFromMachine(ctx, paths, allowRecursion, preserve, factory, extra)
 }
 
 func logEntryFieldsToString(fields []*structpb.Struct) (string, error) {
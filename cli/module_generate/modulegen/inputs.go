@@ -79,6 +79,11 @@ var ExcludedResources = []string{
 	"discovery service",
 	"video service",
 	"base_remote_control service",
+	"status_light component",
+	"force_torque_sensor component",
+	"lidar component",
+	"ranging_array component",
+	"orchestration service",
 }
 
 // GoModuleTmpl contains necessary information to fill out the go method stubs.
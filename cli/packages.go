@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,6 +20,10 @@ import (
 	"go.viam.com/utils"
 	"go.viam.com/utils/rpc"
 	"google.golang.org/protobuf/types/known/structpb"
+
+	rconfig "go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/robot/packages"
 )
 
 var boolTrue = true
@@ -178,6 +183,109 @@ func downloadPackageFromURL(ctx context.Context, httpClient *http.Client,
 	return packagePath, nil
 }
 
+type packagePreseedArgs struct {
+	Config      string
+	Destination string
+	Mirror      bool
+}
+
+// PackagePreseedAction is the corresponding action for 'packages preseed'.
+func PackagePreseedAction(ctx context.Context, cmd *cli.Command, args packagePreseedArgs) error {
+	client, err := newViamClient(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	return client.packagePreseedAction(ctx, cmd, args)
+}
+
+func (c *viamClient) packagePreseedAction(ctx context.Context, cmd *cli.Command, args packagePreseedArgs) error {
+	logger := logging.NewLogger("cli")
+	cfg, err := rconfig.ReadLocalConfig(args.Config, logger)
+	if err != nil {
+		return err
+	}
+	destination := args.Destination
+	if destination == "" {
+		destination = rconfig.DefaultPackagesDir()
+	}
+	downloadDir, err := os.MkdirTemp("", "viam-packages-")
+	if err != nil {
+		return err
+	}
+	defer utils.UncheckedErrorFunc(func() error { return os.RemoveAll(downloadDir) })
+
+	var preseeded int
+	for _, p := range cfg.Packages {
+		if p.OCI != nil {
+			warningf(cmd.Root().ErrWriter, "skipping package %s, packages from OCI registries are pulled by the machine", p.Name)
+			continue
+		}
+		orgID, name, ok := strings.Cut(p.Package, "/")
+		if !ok {
+			return fmt.Errorf("invalid package %q of %s, expected <organization-ID>/<package-name>", p.Package, p.Name)
+		}
+		packageURL, err := c.getPackageDownloadURL(ctx, orgID, name, p.Version, string(p.Type))
+		if err != nil {
+			return errors.Wrapf(err, "getting package %s", p.Name)
+		}
+		archivePath, err := downloadPackageFromURL(ctx, c.authFlow.httpClient,
+			filepath.Join(downloadDir, p.SanitizedName()), name, p.Version, packageURL, c.conf.Auth)
+		if err != nil {
+			return errors.Wrapf(err, "downloading package %s", p.Name)
+		}
+
+		if args.Mirror {
+			mirrorPath := filepath.Join(destination, filepath.FromSlash(packages.MirrorArchivePath(p)))
+			if err := os.MkdirAll(filepath.Dir(mirrorPath), 0o750); err != nil {
+				return err
+			}
+			if err := os.Rename(archivePath, mirrorPath); err != nil {
+				return err
+			}
+		} else if err := packages.InstallArchive(ctx, destination, p, archivePath, logger); err != nil {
+			return errors.Wrapf(err, "installing package %s", p.Name)
+		}
+		infof(cmd.Root().Writer, "Preseeded package %s (%s:%s)", p.Name, p.Package, p.Version)
+		preseeded++
+	}
+	printf(cmd.Root().Writer, "Preseeded %d packages into %s", preseeded, destination)
+	return nil
+}
+
+type packageBlockIndexArgs struct {
+	Path        string
+	Destination string
+	BlockSize   int
+}
+
+// PackageBlockIndexAction is the corresponding action for 'packages block-index'.
+func PackageBlockIndexAction(ctx context.Context, cmd *cli.Command, args packageBlockIndexArgs) error {
+	//nolint:gosec
+	archive, err := os.Open(args.Path)
+	if err != nil {
+		return err
+	}
+	defer utils.UncheckedErrorFunc(archive.Close)
+	index, err := packages.NewBlockIndex(archive, args.BlockSize)
+	if err != nil {
+		return errors.Wrap(err, "indexing package archive")
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	destination := args.Destination
+	if destination == "" {
+		destination = args.Path + ".blockindex.json"
+	}
+	if err := os.WriteFile(destination, data, 0o600); err != nil {
+		return err
+	}
+	printf(cmd.Root().Writer, "Wrote the block index of %s to %s, push it as a layer of media type %s",
+		args.Path, destination, packages.BlockIndexMediaType)
+	return nil
+}
+
 type packageUploadArgs struct {
 	Path           string
 	OrgID          string
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v3"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/robot/support"
+)
+
+type machinesPartSupportBundleArgs struct {
+	Organization  string
+	Location      string
+	Machine       string
+	Part          string
+	NetworkChecks bool
+	Upload        bool
+}
+
+// MachinesPartSupportBundleAction is the corresponding Action for 'machines part support-bundle'.
+func MachinesPartSupportBundleAction(ctx context.Context, cmd *cli.Command, args machinesPartSupportBundleArgs) error {
+	var targetPath string
+	switch numArgs := cmd.Args().Len(); numArgs {
+	case 0:
+		var err error
+		targetPath, err = os.Getwd()
+		if err != nil {
+			return err
+		}
+	case 1:
+		targetPath = cmd.Args().First()
+	default:
+		return wrongNumArgsError{numArgs, 0, 1}
+	}
+
+	client, err := newViamClient(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	globalArgs, err := getGlobalArgs(cmd)
+	if err != nil {
+		return err
+	}
+
+	dialCtx, fqdn, rpcOpts, err := client.prepareDial(ctx, args.Organization, args.Location, args.Machine, args.Part, globalArgs.Debug)
+	if err != nil {
+		return err
+	}
+
+	logger := globalArgs.createLogger()
+
+	robotClient, err := client.connectToRobot(dialCtx, fqdn, rpcOpts, globalArgs.Debug, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(robotClient.Close(ctx))
+	}()
+
+	if args.NetworkChecks {
+		printf(cmd.Root().Writer, "Running network checks on the machine, which can take up to half a minute ...")
+	}
+	bundle, err := robotClient.SupportBundle(ctx, support.Options{NetworkChecks: args.NetworkChecks, Upload: args.Upload})
+	if err != nil {
+		return errors.Wrap(err, "failed to collect support bundle")
+	}
+
+	path := filepath.Join(targetPath, filepath.Base(bundle.Name))
+	if err := os.WriteFile(path, bundle.Data, 0o600); err != nil {
+		return errors.Wrap(err, "failed to save support bundle")
+	}
+	printf(cmd.Root().Writer, "Saved support bundle to %s", path)
+	if len(bundle.UploadIDs) != 0 {
+		printf(cmd.Root().Writer, "Uploaded support bundle with file id %s", strings.Join(bundle.UploadIDs, ", "))
+	}
+	return nil
+}
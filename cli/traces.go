@@ -135,6 +135,7 @@ func (c *viamClient) tracesGetRemoteAction(
 		false,
 		[]string{src},
 		target,
+		shell.CopyOptions{},
 		logger,
 	); err != nil {
 		if statusErr := status.Convert(err); statusErr != nil &&
@@ -177,6 +177,17 @@ type Arm interface {
 	Get3DModels(ctx context.Context, extra map[string]interface{}) (map[string]*commonpb.Mesh, error)
 }
 
+// A JointServoer is an arm whose joints can be servoed at its control rate, such as for visual
+// servoing, by repeatedly setting joint positions to move toward without planning a move to each.
+type JointServoer interface {
+	Arm
+
+	// ServoJoints sets the joint positions the arm moves toward, as fast as its speed allows, and
+	// returns without waiting for them to be reached. Servoing continues until the arm is stopped or
+	// given another move.
+	ServoJoints(ctx context.Context, positions []referenceframe.Input) error
+}
+
 // TrajectoryPoint is one waypoint in a streamed joint-space trajectory.
 type TrajectoryPoint struct {
 	// Time from the start of the motion at which this waypoint should be reached. Must be zero
@@ -15,6 +15,7 @@ import (
 	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/simulation"
 	"go.viam.com/rdk/spatialmath"
 )
 
@@ -28,6 +29,9 @@ var Model = resource.DefaultModelFamily.WithModel("fake")
 type Config struct {
 	ArmModel      string `json:"arm-model,omitempty"`
 	ModelFilePath string `json:"model-path,omitempty"`
+	// Simulation makes the arm move its joints over time, within their limits and short of obstacles,
+	// rather than teleporting them.
+	Simulation *simulation.Config `json:"simulation,omitempty"`
 }
 
 // Known values that can be provided for the ArmModel field.
@@ -76,6 +80,9 @@ func (conf *Config) Validate(path string) ([]string, []string, error) {
 	case conf.ArmModel == "" && conf.ModelFilePath != "":
 		_, err = referenceframe.KinematicModelFromFile(conf.ModelFilePath, "")
 	}
+	if err == nil && conf.Simulation != nil {
+		err = conf.Simulation.Validate(path)
+	}
 	return nil, nil, err
 }
 
@@ -132,6 +139,8 @@ type Arm struct {
 	joints   []referenceframe.Input
 	model    referenceframe.Model
 	armModel string
+	// sim holds the joints instead of joints when the arm is simulated.
+	sim *simulation.Joints
 }
 
 // reconfigure atomically reconfigures this arm in place based on the new config.
@@ -153,14 +162,32 @@ func (a *Arm) reconfigure(ctx context.Context, deps resource.Dependencies, conf
 			"the arm-model and model-path from attributes")
 	}
 
+	joints := make([]referenceframe.Input, dof)
+	var sim *simulation.Joints
+	if newConf.Simulation != nil {
+		if sim, err = simulation.NewJoints(newConf.Simulation, model, newConf.Simulation.MaxAngularSpeed(), joints); err != nil {
+			return err
+		}
+	}
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
-	a.joints = make([]referenceframe.Input, dof)
+	if a.sim != nil {
+		a.sim.Stop()
+	}
+	a.joints = joints
 	a.model = model
 	a.armModel = newConf.ArmModel
+	a.sim = sim
 	return nil
 }
 
+func (a *Arm) simulation() *simulation.Joints {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.sim
+}
+
 // EndPosition returns the set position.
 func (a *Arm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
 	joints, err := a.CurrentInputs(ctx)
@@ -174,6 +201,23 @@ func (a *Arm) EndPosition(ctx context.Context, extra map[string]interface{}) (sp
 
 // MoveToPosition sets the position.
 func (a *Arm) MoveToPosition(ctx context.Context, pose spatialmath.Pose, extra map[string]interface{}) error {
+	if sim := a.simulation(); sim != nil {
+		model, err := a.Kinematics(ctx)
+		if err != nil {
+			return err
+		}
+		plan, err := motionplan.GetGlobal().PlanFrameMotion(ctx, a.logger, pose, model, sim.Inputs(), nil, nil)
+		if err != nil {
+			return err
+		}
+		for _, step := range plan {
+			if err := sim.MoveTo(ctx, step, 0); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
@@ -195,6 +239,9 @@ func (a *Arm) MoveToJointPositions(ctx context.Context, joints []referenceframe.
 	if err := arm.CheckDesiredJointPositions(ctx, a, joints); err != nil {
 		return err
 	}
+	if sim := a.simulation(); sim != nil {
+		return sim.MoveTo(ctx, joints, 0)
+	}
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	_, err := a.model.Transform(joints)
@@ -220,9 +267,9 @@ func (a *Arm) MoveThroughJointPositions(
 	return nil
 }
 
-// MoveThroughJointPositionsStreamed executes a streamed trajectory by teleporting to each point in
-// order. The fake arm has no time model, so per-point Time and Constraints are ignored; it snaps to
-// each waypoint and acknowledges once per batch.
+// MoveThroughJointPositionsStreamed executes a streamed trajectory by moving to each point in order.
+// Per-point Time and Constraints are ignored; the fake arm snaps to each waypoint, or moves to it at
+// its simulated speed, and acknowledges once per batch.
 func (a *Arm) MoveThroughJointPositionsStreamed(
 	ctx context.Context,
 	batches <-chan []arm.TrajectoryPoint,
@@ -246,6 +293,9 @@ func (a *Arm) MoveThroughJointPositionsStreamed(
 
 // JointPositions returns joints.
 func (a *Arm) JointPositions(ctx context.Context, extra map[string]interface{}) ([]referenceframe.Input, error) {
+	if sim := a.simulation(); sim != nil {
+		return sim.Inputs(), nil
+	}
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
@@ -254,13 +304,19 @@ func (a *Arm) JointPositions(ctx context.Context, extra map[string]interface{})
 	return ret, nil
 }
 
-// Stop doesn't do anything for a fake arm.
+// Stop stops a simulated arm and does nothing otherwise.
 func (a *Arm) Stop(ctx context.Context, extra map[string]interface{}) error {
+	if sim := a.simulation(); sim != nil {
+		sim.Stop()
+	}
 	return nil
 }
 
-// IsMoving is always false for a fake arm.
+// IsMoving is whether a simulated arm is moving, and always false otherwise.
 func (a *Arm) IsMoving(ctx context.Context) (bool, error) {
+	if sim := a.simulation(); sim != nil {
+		return sim.IsMoving(), nil
+	}
 	return false, nil
 }
 
@@ -273,6 +329,9 @@ func (a *Arm) Kinematics(ctx context.Context) (referenceframe.Model, error) {
 
 // CurrentInputs returns the current inputs of the fake arm.
 func (a *Arm) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	if sim := a.simulation(); sim != nil {
+		return sim.Inputs(), nil
+	}
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
@@ -287,10 +346,13 @@ func (a *Arm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Inp
 	return a.MoveThroughJointPositions(ctx, inputSteps, nil, nil)
 }
 
-// Close does nothing.
+// Close stops a simulated arm.
 func (a *Arm) Close(ctx context.Context) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	if a.sim != nil {
+		a.sim.Stop()
+	}
 	a.CloseCount++
 	return nil
 }
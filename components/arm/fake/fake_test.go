@@ -6,11 +6,13 @@ import (
 	"testing"
 
 	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
 
 	models3d "go.viam.com/rdk/components/arm/fake/3d_models"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/simulation"
 )
 
 func TestJointPositions(t *testing.T) {
@@ -45,6 +47,44 @@ func TestJointPositions(t *testing.T) {
 	test.That(t, sampleInputs, test.ShouldResemble, inputs)
 }
 
+func TestSimulation(t *testing.T) {
+	ctx := context.Background()
+	cfg := resource.Config{
+		Name: "testArm",
+		ConvertedAttributes: &Config{
+			ArmModel:   ur5eModel,
+			Simulation: &simulation.Config{TimeScale: 10, Gravity: true},
+		},
+	}
+	_, _, err := cfg.ConvertedAttributes.(*Config).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	a, err := NewArm(ctx, nil, cfg, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer a.Close(ctx)
+
+	// the arm moves its joints over time rather than teleporting them.
+	goal := []referenceframe.Input{math.Pi / 2, 0, 0, 0, 0, 0}
+	done := make(chan error, 1)
+	go func() { done <- a.MoveToJointPositions(ctx, goal, nil) }()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		moving, err := a.IsMoving(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, moving, test.ShouldBeTrue)
+	})
+	test.That(t, <-done, test.ShouldBeNil)
+	positions, err := a.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, positions, test.ShouldResemble, goal)
+	moving, err := a.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+
+	// lowering the shoulder would swing the arm into the floor.
+	err = a.MoveToJointPositions(ctx, []referenceframe.Input{math.Pi / 2, math.Pi / 2, 0, 0, 0, 0}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, simulation.FloorLabel)
+}
+
 func TestGet3DModels(t *testing.T) {
 	ctx := context.Background()
 	confNo3DModels := resource.Config{
@@ -5,4 +5,6 @@ import (
 	// register arms.
 	_ "go.viam.com/rdk/components/arm/fake"
 	_ "go.viam.com/rdk/components/arm/sim"
+	_ "go.viam.com/rdk/components/arm/simbridge"
+	_ "go.viam.com/rdk/components/arm/ur"
 )
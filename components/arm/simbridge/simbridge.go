@@ -0,0 +1,415 @@
+// Package simbridge implements an arm bound to an arm in a running Gazebo or Isaac Sim simulation
+// over ROS 2 topics through rosbridge. It reads the simulated joints from sensor_msgs/JointState
+// messages and commands joint positions, so a config can be exercised against the simulation
+// before touching hardware.
+package simbridge
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/ros/rosbridge"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// Model is the model of arms bound to a simulation.
+var Model = resource.DefaultModelFamily.WithModel("sim-bridge")
+
+// The kinds of messages joint positions can be commanded with.
+const (
+	// CommandJointState is a sensor_msgs/JointState naming the joints, as taken by Isaac Sim's
+	// articulation controller.
+	CommandJointState = "joint_state"
+	// CommandFloat64MultiArray is a std_msgs/Float64MultiArray of positions in joint order, as taken
+	// by the forward position controller of ros2_control in Gazebo.
+	CommandFloat64MultiArray = "float64_multi_array"
+)
+
+const (
+	defaultJointStatesTopic = "/joint_states"
+	defaultCommandTopic     = "/joint_command"
+	pollInterval            = 20 * time.Millisecond
+	// revoluteTolerance and prismaticTolerance are how close, in radians and millimeters, joints must
+	// get to their goal.
+	revoluteTolerance  = 0.01
+	prismaticTolerance = 1.
+)
+
+// stallTimeout is how long joints may make no progress toward their goal before the motion is
+// considered blocked, such as by a collision in the simulation. Tests shorten it.
+var stallTimeout = 2 * time.Second
+
+func init() {
+	resource.RegisterComponent(arm.API, Model, resource.Registration[arm.Arm, *Config]{
+		Constructor: NewArm,
+	})
+}
+
+// Config binds an arm to a simulated one.
+type Config struct {
+	// RosbridgeURL is the websocket URL of the rosbridge server, such as ws://localhost:9090.
+	RosbridgeURL  string `json:"rosbridge_url"`
+	ModelFilePath string `json:"model-path"`
+	// Joints names the simulated joints in the order of the model's inputs. It defaults to the names
+	// of the model's joints, which match the simulation's when both come from the same URDF.
+	Joints []string `json:"joints,omitempty"`
+	// JointStatesTopic defaults to /joint_states.
+	JointStatesTopic string `json:"joint_states_topic,omitempty"`
+	// CommandTopic defaults to /joint_command.
+	CommandTopic string `json:"command_topic,omitempty"`
+	// CommandType is joint_state, the default, or float64_multi_array.
+	CommandType string `json:"command_type,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if err := rosbridge.ValidateURL(path, conf.RosbridgeURL); err != nil {
+		return nil, nil, err
+	}
+	if conf.ModelFilePath == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "model-path")
+	}
+	switch conf.CommandType {
+	case "", CommandJointState, CommandFloat64MultiArray:
+	default:
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.Errorf("command_type must be %s or %s", CommandJointState, CommandFloat64MultiArray))
+	}
+	model, err := referenceframe.KinematicModelFromFile(conf.ModelFilePath, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, _, err := joints(conf, model); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	return nil, nil, nil
+}
+
+// joints returns the names of the simulated joints and how many of the model's input units, radians
+// or millimeters, there are in each of their ROS units, radians or meters.
+func joints(conf *Config, model referenceframe.Model) ([]string, []float64, error) {
+	dof := len(model.DoF())
+	names := conf.Joints
+	scales := make([]float64, dof)
+	for i := range scales {
+		scales[i] = 1
+	}
+	if simple, ok := model.(*referenceframe.SimpleModel); ok {
+		modelNames := simple.MoveableFrameNames()
+		kinds := map[string]string{}
+		if mc := simple.ModelConfig(); mc != nil {
+			for _, j := range mc.Joints {
+				kinds[j.ID] = j.Type
+			}
+		}
+		if len(modelNames) == dof {
+			for i, name := range modelNames {
+				if kinds[name] == referenceframe.PrismaticJoint {
+					scales[i] = 1000
+				}
+			}
+			if len(names) == 0 {
+				names = modelNames
+			}
+		}
+	}
+	if len(names) != dof {
+		return nil, nil, errors.Errorf("%d joints are named but the model has %d", len(names), dof)
+	}
+	return names, scales, nil
+}
+
+type simArm struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	model        referenceframe.Model
+	joints       []string
+	scales       []float64
+	commandTopic string
+	commandType  string
+	statesTopic  string
+	client       *rosbridge.Client
+	opMgr        *operation.SingleOperationManager
+	logger       logging.Logger
+
+	mu        sync.Mutex
+	positions []referenceframe.Input
+}
+
+// NewArm returns an arm bound to a simulated one.
+func NewArm(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (arm.Arm, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	model, err := referenceframe.KinematicModelFromFile(newConf.ModelFilePath, conf.Name)
+	if err != nil {
+		return nil, err
+	}
+	names, scales, err := joints(newConf, model)
+	if err != nil {
+		return nil, err
+	}
+	a := &simArm{
+		Named:        conf.ResourceName().AsNamed(),
+		model:        model,
+		joints:       names,
+		scales:       scales,
+		commandTopic: newConf.CommandTopic,
+		commandType:  newConf.CommandType,
+		statesTopic:  newConf.JointStatesTopic,
+		opMgr:        operation.NewSingleOperationManager(),
+		logger:       logger,
+	}
+	if a.commandTopic == "" {
+		a.commandTopic = defaultCommandTopic
+	}
+	if a.commandType == "" {
+		a.commandType = CommandJointState
+	}
+	if a.statesTopic == "" {
+		a.statesTopic = defaultJointStatesTopic
+	}
+	a.client = rosbridge.NewClient(newConf.RosbridgeURL, logger)
+	a.client.Subscribe(a.statesTopic, rosbridge.TypeJointState, a.handleJointState)
+	return a, nil
+}
+
+// handleJointState records the positions of the arm's joints. Simulations may publish the joints of
+// several articulations, or only some of them, on one topic, so messages missing any joint are
+// ignored.
+func (a *simArm) handleJointState(msg json.RawMessage) {
+	var state rosbridge.JointState
+	if err := json.Unmarshal(msg, &state); err != nil {
+		a.logger.Warnw("invalid joint state", "topic", a.statesTopic, "error", err)
+		return
+	}
+	index := make(map[string]int, len(state.Name))
+	for i, name := range state.Name {
+		if i < len(state.Position) {
+			index[name] = i
+		}
+	}
+	positions := make([]referenceframe.Input, len(a.joints))
+	for i, name := range a.joints {
+		j, ok := index[name]
+		if !ok {
+			return
+		}
+		positions[i] = state.Position[j] * a.scales[i]
+	}
+	a.mu.Lock()
+	a.positions = positions
+	a.mu.Unlock()
+}
+
+// command publishes joint positions for the simulation to move to.
+func (a *simArm) command(ctx context.Context, positions []referenceframe.Input) error {
+	ros := make([]float64, len(positions))
+	for i, p := range positions {
+		ros[i] = p / a.scales[i]
+	}
+	if a.commandType == CommandFloat64MultiArray {
+		return a.client.Publish(ctx, a.commandTopic, rosbridge.TypeFloat64MultiArray, rosbridge.Float64MultiArray{Data: ros})
+	}
+	return a.client.Publish(ctx, a.commandTopic, rosbridge.TypeJointState, rosbridge.JointState{
+		Header:   rosbridge.NewHeader(time.Now(), ""),
+		Name:     a.joints,
+		Position: ros,
+	})
+}
+
+// EndPosition returns the pose of the end of the simulated arm.
+func (a *simArm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+	inputs, err := a.JointPositions(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return a.model.Transform(inputs)
+}
+
+// MoveToPosition plans a motion of the joints to pose and moves through it.
+func (a *simArm) MoveToPosition(ctx context.Context, pose spatialmath.Pose, extra map[string]interface{}) error {
+	inputs, err := a.JointPositions(ctx, extra)
+	if err != nil {
+		return err
+	}
+	plan, err := motionplan.GetGlobal().PlanFrameMotion(ctx, a.logger, pose, a.model, inputs, nil, nil)
+	if err != nil {
+		return err
+	}
+	return a.MoveThroughJointPositions(ctx, plan, nil, extra)
+}
+
+// MoveToJointPositions commands the simulated joints to positions and returns once they are there.
+func (a *simArm) MoveToJointPositions(ctx context.Context, positions []referenceframe.Input, extra map[string]interface{}) error {
+	if err := arm.CheckDesiredJointPositions(ctx, a, positions); err != nil {
+		return err
+	}
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
+	return a.moveTo(ctx, positions)
+}
+
+// moveTo commands the joints to goal and waits until they get there, or stop getting closer.
+func (a *simArm) moveTo(ctx context.Context, goal []referenceframe.Input) error {
+	if err := a.command(ctx, goal); err != nil {
+		return err
+	}
+	closest := math.Inf(1)
+	lastProgress := time.Now()
+	for {
+		current, err := a.JointPositions(ctx, nil)
+		if err != nil {
+			return err
+		}
+		arrived := true
+		var distance float64
+		for i := range goal {
+			off := math.Abs(goal[i] - current[i])
+			tolerance := revoluteTolerance
+			if a.scales[i] != 1 {
+				tolerance = prismaticTolerance
+			}
+			arrived = arrived && off <= tolerance
+			distance += off / tolerance
+		}
+		if arrived {
+			return nil
+		}
+		if distance < closest-1 {
+			closest, lastProgress = distance, time.Now()
+		} else if time.Since(lastProgress) > stallTimeout {
+			return errors.Errorf("%s stopped short of its goal, it may be blocked in the simulation", a.Name().ShortName())
+		}
+		if !utils.SelectContextOrWait(ctx, pollInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// MoveThroughJointPositions moves through each of positions in turn.
+func (a *simArm) MoveThroughJointPositions(
+	ctx context.Context,
+	positions [][]referenceframe.Input,
+	_ *arm.MoveOptions,
+	_ map[string]interface{},
+) error {
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
+	for _, goal := range positions {
+		if err := a.MoveToJointPositions(ctx, goal, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveThroughJointPositionsStreamed moves through each point of the batches in turn, acknowledging
+// each batch once the arm has reached its last point.
+func (a *simArm) MoveThroughJointPositionsStreamed(
+	ctx context.Context,
+	batches <-chan []arm.TrajectoryPoint,
+	responses chan<- arm.Response,
+	_ map[string]interface{},
+) error {
+	ctx, done := a.opMgr.New(ctx)
+	defer done()
+	for batch := range batches {
+		for _, p := range batch {
+			if err := a.MoveToJointPositions(ctx, p.Positions, nil); err != nil {
+				return err
+			}
+		}
+		select {
+		case responses <- arm.Response{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// JointPositions returns the latest positions of the simulated joints.
+func (a *simArm) JointPositions(ctx context.Context, extra map[string]interface{}) ([]referenceframe.Input, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.positions == nil {
+		return nil, errors.Errorf("no joint states for %s received on %s yet", a.Name().ShortName(), a.statesTopic)
+	}
+	return append([]referenceframe.Input(nil), a.positions...), nil
+}
+
+// Stop cancels the current motion and holds the joints where they are.
+func (a *simArm) Stop(ctx context.Context, extra map[string]interface{}) error {
+	a.opMgr.CancelRunning(ctx)
+	current, err := a.JointPositions(ctx, extra)
+	if err != nil {
+		return err
+	}
+	return a.command(ctx, current)
+}
+
+// IsMoving returns whether the arm is moving to a goal.
+func (a *simArm) IsMoving(ctx context.Context) (bool, error) {
+	return a.opMgr.OpRunning(), nil
+}
+
+// Kinematics returns the kinematic model of the arm.
+func (a *simArm) Kinematics(ctx context.Context) (referenceframe.Model, error) {
+	return a.model, nil
+}
+
+// CurrentInputs returns the latest positions of the simulated joints.
+func (a *simArm) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	return a.JointPositions(ctx, nil)
+}
+
+// GoToInputs moves through each of inputSteps in turn.
+func (a *simArm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	return a.MoveThroughJointPositions(ctx, inputSteps, nil, nil)
+}
+
+// Geometries returns the geometries of the arm where the simulated joints are.
+func (a *simArm) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	inputs, err := a.JointPositions(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	gif, err := a.model.Geometries(inputs)
+	if err != nil {
+		return nil, err
+	}
+	return gif.Geometries(), nil
+}
+
+// Get3DModels returns no models, as the simulation renders the arm.
+func (a *simArm) Get3DModels(ctx context.Context, extra map[string]interface{}) (map[string]*commonpb.Mesh, error) {
+	return map[string]*commonpb.Mesh{}, nil
+}
+
+// DoCommand reports whether the arm is connected to the simulation.
+func (a *simArm) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"connected": a.client.Connected()}, nil
+}
+
+// Close cancels the current motion and disconnects from the simulation.
+func (a *simArm) Close(ctx context.Context) error {
+	a.opMgr.CancelRunning(ctx)
+	a.client.Close()
+	return nil
+}
@@ -0,0 +1,149 @@
+package simbridge
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	goutils "go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/ros/rosbridge"
+	"go.viam.com/rdk/utils"
+)
+
+var (
+	ur5ePath   = utils.ResolveFile("components/arm/fake/kinematics/ur5e.json")
+	ur5eJoints = []string{
+		"shoulder_pan_joint", "shoulder_lift_joint", "elbow_joint", "wrist_1_joint", "wrist_2_joint", "wrist_3_joint",
+	}
+)
+
+// fakeController stands in for the simulation's joint controller, publishing the joints where they
+// were last commanded unless they are blocked.
+type fakeController struct {
+	mu        sync.Mutex
+	positions []float64
+	blocked   bool
+}
+
+func (fc *fakeController) run(ctx context.Context, fs *rosbridge.FakeServer) {
+	for goutils.SelectContextOrWait(ctx, 10*time.Millisecond) {
+		fc.mu.Lock()
+		if ops := fs.Ops("publish", defaultCommandTopic); len(ops) > 0 && !fc.blocked {
+			var cmd rosbridge.JointState
+			if err := json.Unmarshal(ops[len(ops)-1].Msg, &cmd); err == nil {
+				fc.positions = cmd.Position
+			}
+		}
+		state := rosbridge.JointState{Name: append([]string{"other_joint"}, ur5eJoints...), Position: append([]float64{7}, fc.positions...)}
+		fc.mu.Unlock()
+		//nolint:errcheck
+		fs.Publish(ctx, defaultJointStatesTopic, state)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{RosbridgeURL: "ws://localhost:9090", ModelFilePath: ur5ePath}
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.Joints = []string{"a", "b"}
+	_, _, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "2 joints")
+	conf.Joints = nil
+
+	conf.CommandType = "trajectory"
+	_, _, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	conf.CommandType = CommandFloat64MultiArray
+
+	conf.ModelFilePath = ""
+	_, _, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "model-path")
+}
+
+func TestArm(t *testing.T) {
+	stallTimeout = 200 * time.Millisecond
+	ctx := context.Background()
+	fs := rosbridge.NewFakeServer()
+	defer fs.Close()
+
+	a, err := NewArm(ctx, nil, resource.Config{
+		Name:                "arm",
+		API:                 arm.API,
+		Model:               Model,
+		ConvertedAttributes: &Config{RosbridgeURL: fs.URL(), ModelFilePath: ur5ePath},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, a.Close(ctx), test.ShouldBeNil)
+	}()
+
+	_, err = a.JointPositions(ctx, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no joint states")
+
+	fc := &fakeController{positions: make([]float64, len(ur5eJoints))}
+	simCtx, stopSim := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fc.run(simCtx, fs)
+	}()
+	defer func() {
+		stopSim()
+		wg.Wait()
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		_, err := a.JointPositions(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+	})
+
+	goal := []referenceframe.Input{0.1, -0.5, 0.5, 0, 0.2, 0}
+	test.That(t, a.MoveToJointPositions(ctx, goal, nil), test.ShouldBeNil)
+	joints, err := a.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, joints, test.ShouldResemble, goal)
+	cmds := fs.Ops("publish", defaultCommandTopic)
+	var cmd rosbridge.JointState
+	test.That(t, json.Unmarshal(cmds[len(cmds)-1].Msg, &cmd), test.ShouldBeNil)
+	test.That(t, cmd.Name, test.ShouldResemble, ur5eJoints)
+	test.That(t, fs.Ops("advertise", defaultCommandTopic)[0].Type, test.ShouldEqual, rosbridge.TypeJointState)
+
+	pose, err := a.EndPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	model, err := a.Kinematics(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	expected, err := model.Transform(goal)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pose, test.ShouldResemble, expected)
+
+	// a joint blocked in the simulation stops the motion.
+	fc.mu.Lock()
+	fc.blocked = true
+	fc.mu.Unlock()
+	err = a.MoveToJointPositions(ctx, []referenceframe.Input{0, 0, 0, 0, 0, 0}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "stopped short")
+	moving, err := a.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+
+	// stopping holds the joints where they are.
+	sent := len(fs.Ops("publish", defaultCommandTopic))
+	test.That(t, a.Stop(ctx, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, len(fs.Ops("publish", defaultCommandTopic)), test.ShouldEqual, sent+1)
+	})
+	cmds = fs.Ops("publish", defaultCommandTopic)
+	test.That(t, json.Unmarshal(cmds[sent].Msg, &cmd), test.ShouldBeNil)
+	test.That(t, cmd.Position, test.ShouldResemble, []float64(goal))
+}
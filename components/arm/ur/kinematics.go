@@ -0,0 +1,32 @@
+package ur
+
+import (
+	_ "embed"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+)
+
+//go:embed kinematics/ur5e.json
+var ur5eJSON []byte
+
+//go:embed kinematics/ur7e.json
+var ur7eJSON []byte
+
+//go:embed kinematics/ur20.json
+var ur20JSON []byte
+
+var modelKinematics = map[string][]byte{
+	"ur5e": ur5eJSON,
+	"ur7e": ur7eJSON,
+	"ur20": ur20JSON,
+}
+
+func kinematicsFromName(modelName, name string) (referenceframe.Model, error) {
+	raw, ok := modelKinematics[modelName]
+	if !ok {
+		return nil, errors.Errorf("no kinematics for UR arm model %q", modelName)
+	}
+	return referenceframe.UnmarshalModelJSON(raw, name)
+}
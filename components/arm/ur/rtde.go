@@ -0,0 +1,316 @@
+package ur
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/logging"
+)
+
+// RTDE message types, as defined by the Real-Time Data Exchange guide of Universal Robots.
+const (
+	rtdeRequestProtocolVersion = byte('V')
+	rtdeTextMessage            = byte('M')
+	rtdeDataPackage            = byte('U')
+	rtdeSetupOutputs           = byte('O')
+	rtdeSetupInputs            = byte('I')
+	rtdeStart                  = byte('S')
+	rtdePause                  = byte('P')
+)
+
+const (
+	rtdeProtocolVersion = 2
+	// rtdeHeaderSize is the size of the header of every message: a uint16 size, which includes the
+	// header, and a uint8 type.
+	rtdeHeaderSize = 3
+	// rtdeReadTimeout is how long the controller may go without sending outputs before the
+	// connection is considered lost.
+	rtdeReadTimeout = time.Second
+)
+
+// rtdeTypeSizes are the sizes in bytes of the RTDE types this client can exchange.
+var rtdeTypeSizes = map[string]int{
+	"BOOL":     1,
+	"UINT8":    1,
+	"UINT32":   4,
+	"INT32":    4,
+	"UINT64":   8,
+	"DOUBLE":   8,
+	"VECTOR3D": 24,
+	"VECTOR6D": 48,
+}
+
+// an rtdeClient exchanges a fixed set of outputs and inputs with a robot controller over RTDE.
+type rtdeClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	logger logging.Logger
+
+	outputRecipe byte
+	outputTypes  []string
+	inputRecipe  byte
+	inputTypes   []string
+
+	writeMu sync.Mutex
+}
+
+// dialRTDE connects to the RTDE interface at address, negotiates the protocol version, sets up
+// outputs to be sent at frequency and inputs to be written, and starts synchronization.
+func dialRTDE(
+	ctx context.Context, address string, frequency float64, outputs, inputs []string, logger logging.Logger,
+) (*rtdeClient, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	c := &rtdeClient{conn: conn, reader: bufio.NewReader(conn), logger: logger}
+	if err := c.setup(ctx, frequency, outputs, inputs); err != nil {
+		return nil, multierr.Combine(errors.Wrapf(err, "failed to set up RTDE with %s", address), conn.Close())
+	}
+	return c, nil
+}
+
+func (c *rtdeClient) setup(ctx context.Context, frequency float64, outputs, inputs []string) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := c.conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	} else if err := c.conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		return err
+	}
+
+	resp, err := c.request(rtdeRequestProtocolVersion, binary.BigEndian.AppendUint16(nil, rtdeProtocolVersion))
+	if err != nil {
+		return err
+	}
+	if len(resp) < 1 || resp[0] != 1 {
+		return errors.Errorf("controller does not support RTDE protocol version %d", rtdeProtocolVersion)
+	}
+
+	payload := binary.BigEndian.AppendUint64(nil, math.Float64bits(frequency))
+	payload = append(payload, strings.Join(outputs, ",")...)
+	if c.outputRecipe, c.outputTypes, err = c.setupRecipe(rtdeSetupOutputs, payload, outputs); err != nil {
+		return err
+	}
+	if c.inputRecipe, c.inputTypes, err = c.setupRecipe(rtdeSetupInputs, []byte(strings.Join(inputs, ",")), inputs); err != nil {
+		return err
+	}
+
+	if resp, err = c.request(rtdeStart, nil); err != nil {
+		return err
+	}
+	if len(resp) < 1 || resp[0] != 1 {
+		return errors.New("controller did not start RTDE synchronization")
+	}
+	return c.conn.SetDeadline(time.Time{})
+}
+
+// setupRecipe sets up the variables of a recipe, returning its id and the types of its variables.
+func (c *rtdeClient) setupRecipe(msgType byte, payload []byte, names []string) (byte, []string, error) {
+	resp, err := c.request(msgType, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(resp) < 1 {
+		return 0, nil, errors.New("empty RTDE recipe setup response")
+	}
+	types := strings.Split(string(resp[1:]), ",")
+	if len(types) != len(names) {
+		return 0, nil, errors.Errorf("expected %d RTDE variable types but got %q", len(names), resp[1:])
+	}
+	for i, t := range types {
+		switch t {
+		case "NOT_FOUND":
+			return 0, nil, errors.Errorf("RTDE variable %q was not found", names[i])
+		case "IN_USE":
+			return 0, nil, errors.Errorf("RTDE input %q is in use by another client", names[i])
+		}
+		if _, ok := rtdeTypeSizes[t]; !ok {
+			return 0, nil, errors.Errorf("RTDE variable %q has unsupported type %q", names[i], t)
+		}
+	}
+	return resp[0], types, nil
+}
+
+// request sends a message and returns the payload of the controller's response to it.
+func (c *rtdeClient) request(msgType byte, payload []byte) ([]byte, error) {
+	if err := c.send(msgType, payload); err != nil {
+		return nil, err
+	}
+	for {
+		respType, resp, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		if respType == msgType {
+			return resp, nil
+		}
+	}
+}
+
+func (c *rtdeClient) send(msgType byte, payload []byte) error {
+	size := rtdeHeaderSize + len(payload)
+	if size > math.MaxUint16 {
+		return errors.New("RTDE message is too large")
+	}
+	msg := make([]byte, rtdeHeaderSize, size)
+	binary.BigEndian.PutUint16(msg, uint16(size))
+	msg[2] = msgType
+	msg = append(msg, payload...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(msg)
+	return err
+}
+
+// receive reads the next message, logging any text messages from the controller along the way.
+func (c *rtdeClient) receive() (byte, []byte, error) {
+	for {
+		var header [rtdeHeaderSize]byte
+		if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+			return 0, nil, err
+		}
+		size := int(binary.BigEndian.Uint16(header[:]))
+		if size < rtdeHeaderSize {
+			return 0, nil, errors.Errorf("invalid RTDE message size %d", size)
+		}
+		payload := make([]byte, size-rtdeHeaderSize)
+		if _, err := io.ReadFull(c.reader, payload); err != nil {
+			return 0, nil, err
+		}
+		if header[2] != rtdeTextMessage {
+			return header[2], payload, nil
+		}
+		c.logger.Infow("robot controller message", "message", textMessage(payload))
+	}
+}
+
+// textMessage returns the message of a text message payload, which is a length-prefixed message
+// followed by a length-prefixed source and a warning level.
+func textMessage(payload []byte) string {
+	if len(payload) < 1 || len(payload) < 1+int(payload[0]) {
+		return string(payload)
+	}
+	return string(payload[1 : 1+payload[0]])
+}
+
+// readOutputs waits for the next outputs from the controller, returned in the order they were set
+// up in.
+func (c *rtdeClient) readOutputs() ([]interface{}, error) {
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(rtdeReadTimeout)); err != nil {
+			return nil, err
+		}
+		msgType, payload, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		if msgType != rtdeDataPackage || len(payload) < 1 || payload[0] != c.outputRecipe {
+			continue
+		}
+		return decodeRTDEValues(c.outputTypes, payload[1:])
+	}
+}
+
+// writeInputs writes values of the inputs, given in the order they were set up in.
+func (c *rtdeClient) writeInputs(values []interface{}) error {
+	if len(values) != len(c.inputTypes) {
+		return errors.Errorf("expected %d RTDE inputs but got %d", len(c.inputTypes), len(values))
+	}
+	payload := []byte{c.inputRecipe}
+	for i, v := range values {
+		var err error
+		if payload, err = appendRTDEValue(payload, c.inputTypes[i], v); err != nil {
+			return err
+		}
+	}
+	return c.send(rtdeDataPackage, payload)
+}
+
+// Close pauses synchronization and disconnects.
+func (c *rtdeClient) Close() error {
+	// the controller is told to pause without waiting for its response, as the connection may be
+	// why it is being closed.
+	return multierr.Combine(c.send(rtdePause, nil), c.conn.Close())
+}
+
+func decodeRTDEValues(types []string, data []byte) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(types))
+	r := bytes.NewReader(data)
+	for _, t := range types {
+		raw := make([]byte, rtdeTypeSizes[t])
+		if _, err := io.ReadFull(r, raw); err != nil {
+			return nil, errors.Errorf("RTDE data package is too short for its recipe")
+		}
+		switch t {
+		case "BOOL":
+			values = append(values, raw[0] != 0)
+		case "UINT8":
+			values = append(values, int64(raw[0]))
+		case "UINT32":
+			values = append(values, int64(binary.BigEndian.Uint32(raw)))
+		case "INT32":
+			values = append(values, int64(int32(binary.BigEndian.Uint32(raw))))
+		case "UINT64":
+			values = append(values, int64(binary.BigEndian.Uint64(raw)))
+		case "DOUBLE":
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(raw)))
+		default:
+			vec := make([]float64, len(raw)/8)
+			for i := range vec {
+				vec[i] = math.Float64frombits(binary.BigEndian.Uint64(raw[i*8:]))
+			}
+			values = append(values, vec)
+		}
+	}
+	return values, nil
+}
+
+func appendRTDEValue(data []byte, t string, v interface{}) ([]byte, error) {
+	switch t {
+	case "BOOL":
+		if b, ok := v.(bool); ok {
+			if b {
+				return append(data, 1), nil
+			}
+			return append(data, 0), nil
+		}
+	case "UINT8":
+		if i, ok := v.(int64); ok {
+			return append(data, byte(i)), nil
+		}
+	case "UINT32", "INT32":
+		if i, ok := v.(int64); ok {
+			return binary.BigEndian.AppendUint32(data, uint32(i)), nil
+		}
+	case "UINT64":
+		if i, ok := v.(int64); ok {
+			return binary.BigEndian.AppendUint64(data, uint64(i)), nil
+		}
+	case "DOUBLE":
+		if f, ok := v.(float64); ok {
+			return binary.BigEndian.AppendUint64(data, math.Float64bits(f)), nil
+		}
+	default:
+		if vec, ok := v.([]float64); ok && len(vec)*8 == rtdeTypeSizes[t] {
+			for _, f := range vec {
+				data = binary.BigEndian.AppendUint64(data, math.Float64bits(f))
+			}
+			return data, nil
+		}
+	}
+	return nil, errors.Errorf("cannot write %T as RTDE type %s", v, t)
+}
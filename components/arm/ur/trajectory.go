@@ -0,0 +1,81 @@
+package ur
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go.viam.com/rdk/components/arm"
+)
+
+// minJerkPeakVelocityRatio is the ratio of the peak to the average velocity of a minimum jerk move.
+const minJerkPeakVelocityRatio = 1.875
+
+// minJerkDuration returns how long a minimum jerk move between from and to takes for no joint to
+// exceed speed.
+func minJerkDuration(from, to []float64, speed float64) time.Duration {
+	var maxDelta float64
+	for i := range from {
+		maxDelta = math.Max(maxDelta, math.Abs(to[i]-from[i]))
+	}
+	return time.Duration(maxDelta / speed * minJerkPeakVelocityRatio * float64(time.Second))
+}
+
+// minJerk returns a sampler of a minimum jerk move from from to to over duration, which starts and
+// ends at rest.
+func minJerk(from, to []float64, duration time.Duration) func(time.Duration) ([]float64, bool) {
+	return func(elapsed time.Duration) ([]float64, bool) {
+		if elapsed >= duration {
+			return to, true
+		}
+		tau := elapsed.Seconds() / duration.Seconds()
+		s := tau * tau * tau * (10 - 15*tau + 6*tau*tau)
+		q := make([]float64, len(from))
+		for i := range q {
+			q[i] = from[i] + s*(to[i]-from[i])
+		}
+		return q, false
+	}
+}
+
+// a streamedTrajectory is a trajectory whose points arrive while it is executed. Once time passes
+// the last point that has arrived, the arm holds it until more points arrive or the stream ends.
+type streamedTrajectory struct {
+	points []arm.TrajectoryPoint
+	ended  bool
+	// underrun is whether the trajectory has run out of points before the stream ended.
+	underrun bool
+}
+
+func (st *streamedTrajectory) sample(elapsed time.Duration) ([]float64, bool) {
+	last := st.points[len(st.points)-1]
+	if elapsed >= last.Time {
+		st.underrun = !st.ended
+		return last.Positions, st.ended
+	}
+	// i is the index of the first point after elapsed, which is at least 1 as the first point is at 0.
+	i := sort.Search(len(st.points), func(i int) bool { return st.points[i].Time > elapsed })
+	return interpolate(st.points[i-1], st.points[i], elapsed), false
+}
+
+// interpolate returns the joint positions at elapsed between points p0 and p1, following a cubic
+// Hermite spline if both points have target velocities and a straight line otherwise.
+func interpolate(p0, p1 arm.TrajectoryPoint, elapsed time.Duration) []float64 {
+	h := (p1.Time - p0.Time).Seconds()
+	s := (elapsed - p0.Time).Seconds() / h
+	q := make([]float64, len(p0.Positions))
+	hermite := p0.Constraints != nil && p1.Constraints != nil &&
+		len(p0.Constraints.Velocities) == len(q) && len(p1.Constraints.Velocities) == len(q)
+	for i := range q {
+		if !hermite {
+			q[i] = p0.Positions[i] + s*(p1.Positions[i]-p0.Positions[i])
+			continue
+		}
+		s2, s3 := s*s, s*s*s
+		q[i] = (2*s3-3*s2+1)*p0.Positions[i] +
+			(s3-2*s2+s)*h*p0.Constraints.Velocities[i] +
+			(-2*s3+3*s2)*p1.Positions[i] +
+			(s3-s2)*h*p1.Constraints.Velocities[i]
+	}
+	return q
+}
@@ -0,0 +1,725 @@
+// Package ur implements arms of Universal Robots, servoed in real time over the Real-Time Data
+// Exchange (RTDE) interface of their controllers.
+//
+// On connecting, the arm sends its controller a URScript program that servos the joints to the
+// positions written to RTDE input registers on every control cycle, so that moves, streamed
+// trajectories and servoing are all followed at the controller's rate rather than by polling.
+// Input integer register 24 and input double registers 24 to 29 are used for this, and must not be
+// written by other RTDE clients.
+package ur
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	models3d "go.viam.com/rdk/components/arm/fake/3d_models"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan/armplanning"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// The ports of the controller's interfaces. They are variables so that tests can serve them locally.
+var (
+	rtdePort   = 30004
+	scriptPort = 30002
+)
+
+const (
+	defaultSpeedDegsPerSec = 60
+	defaultFrequencyHz     = 500
+	defaultLookahead       = 0.1
+	defaultGain            = 300
+
+	// settleTolerance is how close, in radians, every joint must be to the end of a move for the move
+	// to be done, and settleTimeout is how long the arm may take to get there once commanded to.
+	settleTolerance = 1e-3
+	settleTimeout   = 500 * time.Millisecond
+
+	// reconnectInterval is how often a lost connection to the controller is retried, and how often
+	// the servo program is sent while it is not running.
+	reconnectInterval = time.Second
+)
+
+// The values of the input integer register that selects what the servo program does.
+const (
+	servoModeIdle = iota
+	servoModeServo
+	servoModeStop
+)
+
+// The states of the controller reported over RTDE that the arm can move in.
+const (
+	runtimeStatePlaying = 2
+	robotModeRunning    = 7
+	safetyModeNormal    = 1
+	safetyModeReduced   = 2
+)
+
+var (
+	rtdeOutputs = []string{"timestamp", "actual_q", "actual_qd", "runtime_state", "robot_mode", "safety_mode"}
+	rtdeInputs  = []string{
+		"input_int_register_24",
+		"input_double_register_24",
+		"input_double_register_25",
+		"input_double_register_26",
+		"input_double_register_27",
+		"input_double_register_28",
+		"input_double_register_29",
+	}
+)
+
+// servoScript is the URScript program that follows the registers written over RTDE. It is
+// formatted with the servo time, lookahead and gain.
+const servoScript = `def rdk_servo():
+  while True:
+    mode = read_input_integer_register(24)
+    if mode == 1:
+      q = [read_input_float_register(24), read_input_float_register(25), read_input_float_register(26),
+           read_input_float_register(27), read_input_float_register(28), read_input_float_register(29)]
+      servoj(q, 0, 0, %g, %g, %g)
+    elif mode == 2:
+      stopj(2.0)
+    else:
+      sync()
+    end
+  end
+end
+`
+
+var errStopped = errors.New("arm was stopped")
+
+func init() {
+	for modelName := range modelKinematics {
+		resource.RegisterComponent(arm.API, resource.DefaultModelFamily.WithModel(modelName),
+			resource.Registration[arm.Arm, *Config]{
+				Constructor: func(
+					ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+				) (arm.Arm, error) {
+					return NewArm(ctx, modelName, conf, logger)
+				},
+			})
+	}
+}
+
+// Config is used for converting config attributes.
+type Config struct {
+	// Host is the address of the arm's controller.
+	Host string `json:"host"`
+	// SpeedDegsPerSec is the speed of the fastest joint during moves to joint positions and servoing.
+	// SpeedDegsPerSec defaults to 60 if unspecified.
+	SpeedDegsPerSec float64 `json:"speed_degs_per_sec,omitempty"`
+	// FrequencyHz is the rate of the control cycle, which defaults to 500 if unspecified, the rate of
+	// e-Series controllers.
+	FrequencyHz float64 `json:"frequency_hz,omitempty"`
+	// Lookahead is the time in seconds that servoing looks ahead to smooth the trajectory, from 0.03
+	// to 0.2. Lookahead defaults to 0.1 if unspecified.
+	Lookahead float64 `json:"lookahead,omitempty"`
+	// Gain is the proportional gain of servoing, from 100 to 2000. Gain defaults to 300 if unspecified.
+	Gain float64 `json:"gain,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.Host == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "host")
+	}
+	if conf.SpeedDegsPerSec < 0 || conf.SpeedDegsPerSec > 180 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("speed_degs_per_sec must be between 0 and 180"))
+	}
+	if conf.FrequencyHz < 0 || conf.FrequencyHz > 500 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("frequency_hz must be between 0 and 500"))
+	}
+	if conf.Lookahead != 0 && (conf.Lookahead < 0.03 || conf.Lookahead > 0.2) {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("lookahead must be between 0.03 and 0.2"))
+	}
+	if conf.Gain != 0 && (conf.Gain < 100 || conf.Gain > 2000) {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("gain must be between 100 and 2000"))
+	}
+	return nil, nil, nil
+}
+
+// a motion is a move of the arm, sampled on every control cycle for the joint positions to servo to.
+type motion struct {
+	// sample returns the joint positions at elapsed time into the motion, and whether they are its
+	// last.
+	sample func(elapsed time.Duration) ([]float64, bool)
+	// servoTarget is the joint positions being servoed to, if the motion is servoing.
+	servoTarget []float64
+
+	start      time.Time
+	finishedAt time.Time
+	done       chan struct{}
+	err        error
+}
+
+func newMotion(sample func(time.Duration) ([]float64, bool)) *motion {
+	return &motion{sample: sample, done: make(chan struct{})}
+}
+
+// robotState is the state of the controller received on every control cycle.
+type robotState struct {
+	q, qd        []float64
+	runtimeState int64
+	robotMode    int64
+	safetyMode   int64
+}
+
+type urArm struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger logging.Logger
+
+	modelName string
+	model     referenceframe.Model
+	host      string
+	speed     float64
+	frequency float64
+	script    string
+	workers   *utils.StoppableWorkers
+
+	mu sync.Mutex
+	// state is nil until it is received from the controller, and whenever the connection is lost.
+	state *robotState
+	// motion is the move being made, if any.
+	motion *motion
+	// commanded is the joint positions last servoed to.
+	commanded  []float64
+	stopping   bool
+	lastScript time.Time
+}
+
+// NewArm connects to the controller of a UR arm of the given model, which must be a key of the
+// embedded kinematics.
+func NewArm(ctx context.Context, modelName string, conf resource.Config, logger logging.Logger) (arm.Arm, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	model, err := kinematicsFromName(modelName, conf.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &urArm{
+		Named:     conf.ResourceName().AsNamed(),
+		logger:    logger,
+		modelName: modelName,
+		model:     model,
+		host:      newConf.Host,
+		speed:     newConf.SpeedDegsPerSec,
+		frequency: newConf.FrequencyHz,
+	}
+	if a.speed == 0 {
+		a.speed = defaultSpeedDegsPerSec
+	}
+	a.speed *= math.Pi / 180
+	if a.frequency == 0 {
+		a.frequency = defaultFrequencyHz
+	}
+	lookahead, gain := newConf.Lookahead, newConf.Gain
+	if lookahead == 0 {
+		lookahead = defaultLookahead
+	}
+	if gain == 0 {
+		gain = defaultGain
+	}
+	a.script = fmt.Sprintf(servoScript, 1/a.frequency, lookahead, gain)
+
+	rtde, err := a.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.workers = utils.NewBackgroundStoppableWorkers(func(ctx context.Context) {
+		a.control(ctx, rtde)
+	})
+	return a, nil
+}
+
+func (a *urArm) connect(ctx context.Context) (*rtdeClient, error) {
+	return dialRTDE(ctx, net.JoinHostPort(a.host, strconv.Itoa(rtdePort)), a.frequency, rtdeOutputs, rtdeInputs, a.logger)
+}
+
+// control runs the control cycle on every state received from the controller until ctx is done,
+// reconnecting whenever the connection is lost.
+func (a *urArm) control(ctx context.Context, rtde *rtdeClient) {
+	defer func() {
+		if rtde != nil {
+			if err := rtde.Close(); err != nil {
+				a.logger.Debugw("failed to close RTDE connection", "error", err)
+			}
+		}
+	}()
+	for ctx.Err() == nil {
+		if rtde == nil {
+			var err error
+			if rtde, err = a.connect(ctx); err != nil {
+				a.logger.CDebugw(ctx, "failed to reconnect to arm controller", "error", err)
+				utils.SelectContextOrWait(ctx, reconnectInterval)
+				continue
+			}
+			a.logger.CInfo(ctx, "reconnected to arm controller")
+		}
+
+		outputs, err := rtde.readOutputs()
+		if err == nil {
+			err = a.cycle(ctx, rtde, outputs)
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				a.logger.CWarnw(ctx, "lost connection to arm controller", "error", err)
+			}
+			a.mu.Lock()
+			a.state = nil
+			a.finishLocked(errors.Wrap(err, "lost connection to arm controller"))
+			a.mu.Unlock()
+			if closeErr := rtde.Close(); closeErr != nil {
+				a.logger.CDebugw(ctx, "failed to close RTDE connection", "error", closeErr)
+			}
+			rtde = nil
+		}
+	}
+}
+
+// cycle updates the state of the arm from outputs and writes the inputs that command it.
+func (a *urArm) cycle(ctx context.Context, rtde *rtdeClient, outputs []interface{}) error {
+	st, err := parseState(outputs)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.state = st
+	if err := st.ready(); err != nil {
+		a.finishLocked(err)
+	}
+	mode, target := a.commandLocked(time.Now())
+	sendScript := st.runtimeState != runtimeStatePlaying && st.robotMode == robotModeRunning &&
+		time.Since(a.lastScript) >= reconnectInterval
+	if sendScript {
+		a.lastScript = time.Now()
+	}
+	a.mu.Unlock()
+
+	inputs := make([]interface{}, 0, len(rtdeInputs))
+	inputs = append(inputs, int64(mode))
+	for _, q := range target {
+		inputs = append(inputs, q)
+	}
+	if err := rtde.writeInputs(inputs); err != nil {
+		return err
+	}
+
+	if sendScript {
+		if err := a.sendScript(ctx); err != nil {
+			a.logger.CWarnw(ctx, "failed to send servo program to arm controller", "error", err)
+		}
+	}
+	return nil
+}
+
+// commandLocked advances the motion being made and returns the servo mode and joint positions to
+// write to the controller.
+func (a *urArm) commandLocked(now time.Time) (int, []float64) {
+	m := a.motion
+	if m == nil {
+		if a.stopping {
+			return servoModeStop, a.state.q
+		}
+		return servoModeIdle, a.state.q
+	}
+
+	if m.start.IsZero() {
+		m.start = now
+	}
+	q, last := m.sample(now.Sub(m.start))
+	a.commanded = q
+	if last {
+		if m.finishedAt.IsZero() {
+			m.finishedAt = now
+		}
+		if maxAbsDiff(q, a.state.q) <= settleTolerance || now.Sub(m.finishedAt) >= settleTimeout {
+			a.finishLocked(nil)
+		}
+	}
+	return servoModeServo, q
+}
+
+func (a *urArm) sendScript(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, reconnectInterval)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(a.host, strconv.Itoa(scriptPort)))
+	if err != nil {
+		return err
+	}
+	defer utils.UncheckedErrorFunc(conn.Close)
+	_, err = conn.Write([]byte(a.script))
+	return err
+}
+
+func parseState(outputs []interface{}) (*robotState, error) {
+	if len(outputs) != len(rtdeOutputs) {
+		return nil, errors.Errorf("expected %d RTDE outputs but got %d", len(rtdeOutputs), len(outputs))
+	}
+	q, ok1 := outputs[1].([]float64)
+	qd, ok2 := outputs[2].([]float64)
+	runtimeState, ok3 := outputs[3].(int64)
+	robotMode, ok4 := outputs[4].(int64)
+	safetyMode, ok5 := outputs[5].(int64)
+	if !ok1 || !ok2 || !ok3 || !ok4 || !ok5 || len(q) != 6 {
+		return nil, errors.New("unexpected types of RTDE outputs")
+	}
+	return &robotState{q: q, qd: qd, runtimeState: runtimeState, robotMode: robotMode, safetyMode: safetyMode}, nil
+}
+
+// ready returns why the arm cannot move in this state, if it cannot.
+func (st *robotState) ready() error {
+	switch {
+	case st.safetyMode != safetyModeNormal && st.safetyMode != safetyModeReduced:
+		return errors.Errorf("arm is in safety mode %d, such as a protective or emergency stop", st.safetyMode)
+	case st.robotMode != robotModeRunning:
+		return errors.Errorf("arm is not running, it is in robot mode %d", st.robotMode)
+	case st.runtimeState != runtimeStatePlaying:
+		return errors.New("servo program is not running on the arm controller")
+	default:
+		return nil
+	}
+}
+
+func (a *urArm) readyLocked() error {
+	if a.state == nil {
+		return errors.New("not connected to arm controller")
+	}
+	return a.state.ready()
+}
+
+// startLocked makes m the motion being made, in place of any other. The first position of m is
+// servoed to from the last commanded positions, if a motion is being made, or the current ones.
+func (a *urArm) startLocked(m *motion) error {
+	if err := a.readyLocked(); err != nil {
+		return err
+	}
+	if a.motion != nil {
+		a.finishLocked(errors.New("arm was given another move"))
+	}
+	a.stopping = false
+	a.motion = m
+	return nil
+}
+
+// fromLocked returns the positions a new motion starts from: those last commanded if a motion is
+// being made, so that it continues smoothly, and the current ones otherwise.
+func (a *urArm) fromLocked() []float64 {
+	if a.motion != nil && a.commanded != nil {
+		return a.commanded
+	}
+	return a.state.q
+}
+
+// finishLocked ends the motion being made, if any, with err.
+func (a *urArm) finishLocked(err error) {
+	if a.motion == nil {
+		return
+	}
+	a.motion.err = err
+	close(a.motion.done)
+	a.motion = nil
+}
+
+// wait waits for m to finish, stopping the arm if ctx is done first.
+func (a *urArm) wait(ctx context.Context, m *motion) error {
+	select {
+	case <-m.done:
+		return m.err
+	case <-ctx.Done():
+		a.mu.Lock()
+		if a.motion == m {
+			a.stopLocked()
+		}
+		a.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// stopLocked ends the motion being made, if any, and decelerates the arm to a stop.
+func (a *urArm) stopLocked() {
+	a.finishLocked(errStopped)
+	a.stopping = true
+}
+
+func (a *urArm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+	inputs, err := a.JointPositions(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return a.model.Transform(inputs)
+}
+
+func (a *urArm) MoveToPosition(ctx context.Context, pose spatialmath.Pose, extra map[string]interface{}) error {
+	return armplanning.MoveArm(ctx, a.logger, a, pose)
+}
+
+func (a *urArm) MoveToJointPositions(ctx context.Context, positions []referenceframe.Input, extra map[string]interface{}) error {
+	return a.moveTo(ctx, positions, a.speed)
+}
+
+// moveTo makes a minimum jerk move to positions in which no joint exceeds speed.
+func (a *urArm) moveTo(ctx context.Context, positions []referenceframe.Input, speed float64) error {
+	if err := a.checkPositions(ctx, positions); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	if err := a.readyLocked(); err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	from := append([]float64(nil), a.fromLocked()...)
+	m := newMotion(minJerk(from, positions, minJerkDuration(from, positions, speed)))
+	err := a.startLocked(m)
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return a.wait(ctx, m)
+}
+
+func (a *urArm) checkPositions(ctx context.Context, positions []referenceframe.Input) error {
+	if len(positions) != len(a.model.DoF()) {
+		return errors.Errorf("expected %d joint positions but got %d", len(a.model.DoF()), len(positions))
+	}
+	return arm.CheckDesiredJointPositions(ctx, a, positions)
+}
+
+func (a *urArm) MoveThroughJointPositions(
+	ctx context.Context, positions [][]referenceframe.Input, options *arm.MoveOptions, extra map[string]any,
+) error {
+	speed := a.speed
+	if options != nil && options.MaxVelRads > 0 {
+		speed = math.Min(speed, options.MaxVelRads)
+	}
+	for _, p := range positions {
+		if err := a.moveTo(ctx, p, speed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveThroughJointPositionsStreamed follows the streamed trajectory at the controller's rate,
+// interpolating between points along a cubic Hermite spline when they have target velocities, and
+// linearly otherwise. The arm first moves to the first point, and holds the last point it has if
+// the stream falls behind. Each batch is acknowledged once it is queued, so that the client can keep
+// ahead of the arm.
+func (a *urArm) MoveThroughJointPositionsStreamed(
+	ctx context.Context,
+	batches <-chan []arm.TrajectoryPoint,
+	responses chan<- arm.Response,
+	extra map[string]interface{},
+) error {
+	traj := &streamedTrajectory{}
+	var m *motion
+	for {
+		var done <-chan struct{}
+		if m != nil {
+			done = m.done
+		}
+		select {
+		case <-ctx.Done():
+			if m != nil {
+				return a.wait(ctx, m)
+			}
+			return ctx.Err()
+		case <-done:
+			if m.err == nil {
+				return errors.New("streamed trajectory ended before its stream")
+			}
+			return m.err
+		case batch, ok := <-batches:
+			if !ok {
+				if m == nil {
+					return nil
+				}
+				a.mu.Lock()
+				traj.ended = true
+				a.mu.Unlock()
+				return a.wait(ctx, m)
+			}
+			var err error
+			if m, err = a.queue(ctx, traj, m, batch); err != nil {
+				if m != nil {
+					a.mu.Lock()
+					if a.motion == m {
+						a.stopLocked()
+					}
+					a.mu.Unlock()
+				}
+				return err
+			}
+			select {
+			case responses <- arm.Response{}:
+			case <-ctx.Done():
+				return a.wait(ctx, m)
+			}
+		}
+	}
+}
+
+// queue adds batch to traj, starting the motion that follows traj on its first batch.
+func (a *urArm) queue(
+	ctx context.Context, traj *streamedTrajectory, m *motion, batch []arm.TrajectoryPoint,
+) (*motion, error) {
+	a.mu.Lock()
+	prev := traj.points
+	a.mu.Unlock()
+	for _, p := range batch {
+		if err := a.checkPositions(ctx, p.Positions); err != nil {
+			return m, err
+		}
+		switch {
+		case len(prev) == 0 && p.Time != 0:
+			return m, errors.New("the first point of a streamed trajectory must be at time 0")
+		case len(prev) > 0 && p.Time <= prev[len(prev)-1].Time:
+			return m, errors.New("the points of a streamed trajectory must be in increasing order of time")
+		}
+		prev = append(prev, p)
+	}
+	if len(batch) == 0 {
+		return m, nil
+	}
+
+	if m == nil {
+		if err := a.moveTo(ctx, batch[0].Positions, a.speed); err != nil {
+			return nil, err
+		}
+		m = newMotion(traj.sample)
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		traj.points = prev
+		return m, a.startLocked(m)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if traj.underrun {
+		a.logger.CWarn(ctx, "streamed trajectory fell behind the arm, which held its last point")
+		traj.underrun = false
+	}
+	traj.points = prev
+	return m, nil
+}
+
+// ServoJoints servos the arm toward positions at its configured speed until it is stopped or given
+// another move, so that the target can be updated on every control cycle, such as by visual servoing.
+func (a *urArm) ServoJoints(ctx context.Context, positions []referenceframe.Input) error {
+	if err := a.checkPositions(ctx, positions); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.motion != nil && a.motion.servoTarget != nil {
+		copy(a.motion.servoTarget, positions)
+		return nil
+	}
+	if err := a.readyLocked(); err != nil {
+		return err
+	}
+
+	maxStep := a.speed / a.frequency
+	q := append([]float64(nil), a.fromLocked()...)
+	m := newMotion(nil)
+	m.servoTarget = append([]float64(nil), positions...)
+	m.sample = func(time.Duration) ([]float64, bool) {
+		next := make([]float64, len(q))
+		for i := range q {
+			next[i] = q[i] + math.Max(-maxStep, math.Min(maxStep, m.servoTarget[i]-q[i]))
+		}
+		q = next
+		return q, false
+	}
+	return a.startLocked(m)
+}
+
+func (a *urArm) JointPositions(ctx context.Context, extra map[string]interface{}) ([]referenceframe.Input, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.state == nil {
+		return nil, errors.New("not connected to arm controller")
+	}
+	return append([]referenceframe.Input(nil), a.state.q...), nil
+}
+
+func (a *urArm) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	return a.JointPositions(ctx, nil)
+}
+
+func (a *urArm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	return a.MoveThroughJointPositions(ctx, inputSteps, nil, nil)
+}
+
+func (a *urArm) Kinematics(ctx context.Context) (referenceframe.Model, error) {
+	return a.model, nil
+}
+
+func (a *urArm) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	inputs, err := a.JointPositions(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	gif, err := a.model.Geometries(inputs)
+	if err != nil {
+		return nil, err
+	}
+	return gif.Geometries(), nil
+}
+
+func (a *urArm) Get3DModels(ctx context.Context, extra map[string]interface{}) (map[string]*commonpb.Mesh, error) {
+	models := make(map[string]*commonpb.Mesh)
+	for _, part := range models3d.ArmTo3DModelParts[a.modelName] {
+		mesh := models3d.ThreeDMeshFromName(a.modelName, part)
+		if len(mesh.Mesh) > 0 {
+			models[part] = &mesh
+		}
+	}
+	return models, nil
+}
+
+func (a *urArm) IsMoving(ctx context.Context) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.motion != nil, nil
+}
+
+func (a *urArm) Stop(ctx context.Context, extra map[string]interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopLocked()
+	return nil
+}
+
+func (a *urArm) Close(ctx context.Context) error {
+	a.workers.Stop()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.finishLocked(errors.New("arm was closed"))
+	return nil
+}
+
+func maxAbsDiff(a, b []float64) float64 {
+	var m float64
+	for i := range a {
+		m = math.Max(m, math.Abs(a[i]-b[i]))
+	}
+	return m
+}
@@ -0,0 +1,374 @@
+package ur
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeController serves the RTDE and script interfaces of a UR controller whose joints reach the
+// positions they are servoed to immediately.
+type fakeController struct {
+	t            *testing.T
+	rtde, script net.Listener
+	wg           sync.WaitGroup
+
+	mu         sync.Mutex
+	q          []float64
+	playing    bool
+	safetyMode int64
+	scripts    int
+	modes      map[int64]bool
+}
+
+func newFakeController(t *testing.T) *fakeController {
+	t.Helper()
+	fc := &fakeController{t: t, q: make([]float64, 6), safetyMode: safetyModeNormal, modes: map[int64]bool{}}
+	var err error
+	fc.rtde, err = net.Listen("tcp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	fc.script, err = net.Listen("tcp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+
+	prevRTDE, prevScript := rtdePort, scriptPort
+	rtdePort = fc.rtde.Addr().(*net.TCPAddr).Port
+	scriptPort = fc.script.Addr().(*net.TCPAddr).Port
+	t.Cleanup(func() {
+		rtdePort, scriptPort = prevRTDE, prevScript
+		test.That(t, fc.rtde.Close(), test.ShouldBeNil)
+		test.That(t, fc.script.Close(), test.ShouldBeNil)
+		fc.wg.Wait()
+	})
+
+	fc.wg.Add(2)
+	go fc.serveScripts()
+	go fc.serveRTDE()
+	return fc
+}
+
+func (fc *fakeController) serveScripts() {
+	defer fc.wg.Done()
+	for {
+		conn, err := fc.script.Accept()
+		if err != nil {
+			return
+		}
+		script, _ := io.ReadAll(conn)
+		conn.Close()
+		fc.mu.Lock()
+		if strings.HasPrefix(string(script), "def rdk_servo():") {
+			fc.playing = true
+			fc.scripts++
+		}
+		fc.mu.Unlock()
+	}
+}
+
+func (fc *fakeController) serveRTDE() {
+	defer fc.wg.Done()
+	for {
+		conn, err := fc.rtde.Accept()
+		if err != nil {
+			return
+		}
+		fc.wg.Add(1)
+		go fc.serveRTDEConn(conn)
+	}
+}
+
+func writeMessage(w io.Writer, msgType byte, payload []byte) error {
+	msg := binary.BigEndian.AppendUint16(nil, uint16(rtdeHeaderSize+len(payload)))
+	msg = append(msg, msgType)
+	_, err := w.Write(append(msg, payload...))
+	return err
+}
+
+func (fc *fakeController) serveRTDEConn(conn net.Conn) {
+	defer fc.wg.Done()
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	var writeMu sync.Mutex
+	send := func(msgType byte, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return writeMessage(conn, msgType, payload)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for {
+		var header [rtdeHeaderSize]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return
+		}
+		payload := make([]byte, int(binary.BigEndian.Uint16(header[:]))-rtdeHeaderSize)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return
+		}
+		var err error
+		switch header[2] {
+		case rtdeRequestProtocolVersion:
+			err = send(rtdeRequestProtocolVersion, []byte{1})
+		case rtdeSetupOutputs:
+			test.That(fc.t, string(payload[8:]), test.ShouldEqual, strings.Join(rtdeOutputs, ","))
+			err = send(rtdeSetupOutputs, []byte("\x01DOUBLE,VECTOR6D,VECTOR6D,UINT32,INT32,INT32"))
+		case rtdeSetupInputs:
+			test.That(fc.t, string(payload), test.ShouldEqual, strings.Join(rtdeInputs, ","))
+			err = send(rtdeSetupInputs, []byte("\x02INT32,DOUBLE,DOUBLE,DOUBLE,DOUBLE,DOUBLE,DOUBLE"))
+		case rtdeStart:
+			if err = send(rtdeStart, []byte{1}); err == nil {
+				fc.wg.Add(1)
+				go fc.sendOutputs(send, stop)
+			}
+		case rtdeDataPackage:
+			fc.receiveInputs(payload)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// sendOutputs sends the state of the controller every 2ms, as an e-Series controller does.
+func (fc *fakeController) sendOutputs(send func(byte, []byte) error, stop <-chan struct{}) {
+	defer fc.wg.Done()
+	ticker := time.NewTicker(2 * time.Millisecond)
+	defer ticker.Stop()
+	start := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		fc.mu.Lock()
+		runtimeState := int64(1)
+		if fc.playing {
+			runtimeState = runtimeStatePlaying
+		}
+		payload := binary.BigEndian.AppendUint64([]byte{1}, math.Float64bits(time.Since(start).Seconds()))
+		for _, v := range append(append([]float64(nil), fc.q...), make([]float64, 6)...) {
+			payload = binary.BigEndian.AppendUint64(payload, math.Float64bits(v))
+		}
+		payload = binary.BigEndian.AppendUint32(payload, uint32(runtimeState))
+		payload = binary.BigEndian.AppendUint32(payload, robotModeRunning)
+		payload = binary.BigEndian.AppendUint32(payload, uint32(fc.safetyMode))
+		fc.mu.Unlock()
+		if err := send(rtdeDataPackage, payload); err != nil {
+			return
+		}
+	}
+}
+
+func (fc *fakeController) receiveInputs(payload []byte) {
+	test.That(fc.t, payload[0], test.ShouldEqual, 2)
+	values, err := decodeRTDEValues(strings.Split("INT32,DOUBLE,DOUBLE,DOUBLE,DOUBLE,DOUBLE,DOUBLE", ","), payload[1:])
+	test.That(fc.t, err, test.ShouldBeNil)
+	mode := values[0].(int64)
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.modes[mode] = true
+	if mode == servoModeServo && fc.playing {
+		for i := range fc.q {
+			fc.q[i] = values[i+1].(float64)
+		}
+	}
+}
+
+func (fc *fakeController) setSafetyMode(mode int64) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.safetyMode = mode
+}
+
+func newTestArm(t *testing.T) (*urArm, *fakeController) {
+	t.Helper()
+	fc := newFakeController(t)
+	conf := resource.Config{
+		Name:                "arm",
+		API:                 arm.API,
+		Model:               resource.DefaultModelFamily.WithModel("ur5e"),
+		ConvertedAttributes: &Config{Host: "127.0.0.1", SpeedDegsPerSec: 180},
+	}
+	a, err := NewArm(context.Background(), "ur5e", conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { test.That(t, a.Close(context.Background()), test.ShouldBeNil) })
+
+	// the arm is ready once the servo program it sent is running.
+	test.That(t, waitFor(func() bool {
+		a.(*urArm).mu.Lock()
+		defer a.(*urArm).mu.Unlock()
+		return a.(*urArm).readyLocked() == nil
+	}), test.ShouldBeTrue)
+	return a.(*urArm), fc
+}
+
+func waitFor(cond func() bool) bool {
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if cond() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidate(t *testing.T) {
+	_, _, err := (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "host")
+
+	_, _, err = (&Config{Host: "10.0.0.2", Gain: 50}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, _, err = (&Config{Host: "10.0.0.2"}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestMoveToJointPositions(t *testing.T) {
+	ctx := context.Background()
+	a, fc := newTestArm(t)
+
+	target := []float64{0.2, -0.3, 0.1, 0, 0.05, 0}
+	test.That(t, a.MoveToJointPositions(ctx, target, nil), test.ShouldBeNil)
+	positions, err := a.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, maxAbsDiff(positions, target), test.ShouldBeLessThanOrEqualTo, settleTolerance)
+
+	moving, err := a.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+
+	fc.mu.Lock()
+	test.That(t, fc.scripts, test.ShouldEqual, 1)
+	fc.mu.Unlock()
+
+	t.Run("out of limits", func(t *testing.T) {
+		err := a.MoveToJointPositions(ctx, []float64{100, 0, 0, 0, 0, 0}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- a.MoveToJointPositions(ctx, []float64{2, 0, 0, 0, 0, 0}, nil)
+		}()
+		test.That(t, waitFor(func() bool {
+			moving, err := a.IsMoving(ctx)
+			return err == nil && moving
+		}), test.ShouldBeTrue)
+		test.That(t, a.Stop(ctx, nil), test.ShouldBeNil)
+		test.That(t, <-errCh, test.ShouldBeError, errStopped)
+		test.That(t, waitFor(func() bool {
+			fc.mu.Lock()
+			defer fc.mu.Unlock()
+			return fc.modes[servoModeStop]
+		}), test.ShouldBeTrue)
+	})
+
+	t.Run("protective stop", func(t *testing.T) {
+		fc.setSafetyMode(3)
+		test.That(t, waitFor(func() bool {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			return a.readyLocked() != nil
+		}), test.ShouldBeTrue)
+		err := a.MoveToJointPositions(ctx, target, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "safety mode 3")
+		fc.setSafetyMode(safetyModeNormal)
+	})
+}
+
+func TestMoveThroughJointPositionsStreamed(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestArm(t)
+
+	batches := make(chan []arm.TrajectoryPoint)
+	responses := make(chan arm.Response, 2)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- a.MoveThroughJointPositionsStreamed(ctx, batches, responses, nil)
+	}()
+
+	final := []float64{0.3, 0, 0, 0, 0, 0}
+	batches <- []arm.TrajectoryPoint{
+		{Time: 0, Positions: []float64{0.1, 0, 0, 0, 0, 0}},
+		{
+			Time:        50 * time.Millisecond,
+			Positions:   []float64{0.2, 0, 0, 0, 0, 0},
+			Constraints: &arm.KinematicConstraints{Velocities: []float64{2, 0, 0, 0, 0, 0}},
+		},
+	}
+	batches <- []arm.TrajectoryPoint{{Time: 100 * time.Millisecond, Positions: final}}
+	close(batches)
+	test.That(t, <-errCh, test.ShouldBeNil)
+	test.That(t, len(responses), test.ShouldEqual, 2)
+
+	positions, err := a.JointPositions(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, maxAbsDiff(positions, final), test.ShouldBeLessThanOrEqualTo, settleTolerance)
+
+	t.Run("out of order", func(t *testing.T) {
+		batches := make(chan []arm.TrajectoryPoint, 1)
+		batches <- []arm.TrajectoryPoint{
+			{Time: 0, Positions: final},
+			{Time: 0, Positions: final},
+		}
+		close(batches)
+		err := a.MoveThroughJointPositionsStreamed(ctx, batches, make(chan arm.Response, 1), nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "increasing order")
+	})
+}
+
+func TestServoJoints(t *testing.T) {
+	ctx := context.Background()
+	a, _ := newTestArm(t)
+
+	var servoer arm.JointServoer = a
+	test.That(t, servoer.ServoJoints(ctx, []float64{0.5, 0, 0, 0, 0, 0}), test.ShouldBeNil)
+	test.That(t, servoer.ServoJoints(ctx, []float64{0.1, 0.1, 0, 0, 0, 0}), test.ShouldBeNil)
+	test.That(t, waitFor(func() bool {
+		positions, err := a.JointPositions(ctx, nil)
+		return err == nil && maxAbsDiff(positions, []float64{0.1, 0.1, 0, 0, 0, 0}) < 1e-9
+	}), test.ShouldBeTrue)
+
+	moving, err := a.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeTrue)
+	test.That(t, a.Stop(ctx, nil), test.ShouldBeNil)
+	moving, err = a.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+}
+
+func TestInterpolate(t *testing.T) {
+	p0 := arm.TrajectoryPoint{
+		Positions:   []float64{0},
+		Constraints: &arm.KinematicConstraints{Velocities: []float64{0}},
+	}
+	p1 := arm.TrajectoryPoint{
+		Time:        time.Second,
+		Positions:   []float64{1},
+		Constraints: &arm.KinematicConstraints{Velocities: []float64{0}},
+	}
+	test.That(t, interpolate(p0, p1, 500*time.Millisecond)[0], test.ShouldAlmostEqual, 0.5)
+	test.That(t, interpolate(p0, p1, 250*time.Millisecond)[0], test.ShouldAlmostEqual, 0.15625)
+
+	p1.Constraints = nil
+	test.That(t, interpolate(p0, p1, 250*time.Millisecond)[0], test.ShouldAlmostEqual, 0.25)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/pkg/errors"
 	commonpb "go.viam.com/api/common/v1"
 
 	"go.viam.com/rdk/components/arm"
@@ -18,6 +19,10 @@ import (
 
 // Config is used for converting config attributes.
 type Config struct {
+	// ModelFilePath is the .json or .urdf kinematics file the wrapped arm is moved by. Referencing a
+	// file in a package, as in "${packages.archive.my-arm-kinematics}/arm.urdf", lets corrected
+	// kinematics be rolled out to many machines by releasing a new version of the package, which
+	// rebuilds the wrapper with the new model once the machine has downloaded it.
 	ModelFilePath string `json:"model-path"`
 	ArmName       string `json:"arm-name"`
 }
@@ -85,6 +90,13 @@ func (wrapper *Arm) reconfigure(ctx context.Context, deps resource.Dependencies,
 	if err != nil {
 		return err
 	}
+	// a kinematics file meant for another arm fails to configure rather than moving this one
+	// unexpectedly.
+	if actual, err := newArm.Kinematics(ctx); err == nil && actual != nil && len(actual.DoF()) > 0 &&
+		len(actual.DoF()) != len(model.DoF()) {
+		return errors.Errorf("kinematics file %q has %d degrees of freedom but arm %q has %d",
+			newConf.ModelFilePath, len(model.DoF()), newConf.ArmName, len(actual.DoF()))
+	}
 
 	wrapper.mu.Lock()
 	wrapper.model = model
@@ -0,0 +1,78 @@
+package wrapper
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+// writeKinematics writes the ur5e kinematics, with its base baseZ millimeters high, into the
+// directory of a version of a package and returns the path of the file.
+func writeKinematics(t *testing.T, version, baseZ string) string {
+	t.Helper()
+	data, err := os.ReadFile(utils.ResolveFile("components/arm/fake/kinematics/ur5e.json"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(data), test.ShouldContainSubstring, `"z": 162.5`)
+	corrected := strings.Replace(string(data), `"z": 162.5`, `"z": `+baseZ, 1)
+
+	dir := filepath.Join(t.TempDir(), "orgid-ur5e-kinematics-"+version)
+	test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+	path := filepath.Join(dir, "ur5e.json")
+	test.That(t, os.WriteFile(path, []byte(corrected), 0o600), test.ShouldBeNil)
+	return path
+}
+
+func TestWrapperKinematics(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	actual := inject.NewArm("actual")
+	actual.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) ([]referenceframe.Input, error) {
+		return make([]referenceframe.Input, 6), nil
+	}
+	deps := resource.Dependencies{arm.Named("actual"): actual}
+	newWrapper := func(modelPath string) (arm.Arm, error) {
+		conf := &Config{ModelFilePath: modelPath, ArmName: "actual"}
+		if _, _, err := conf.Validate("path"); err != nil {
+			return nil, err
+		}
+		return NewWrapperArm(ctx, deps, resource.Config{Name: "wrapper", ConvertedAttributes: conf}, logger)
+	}
+
+	// a new version of the kinematics package moves the arm by the corrected model.
+	v1, err := newWrapper(writeKinematics(t, "1_0_0", "162.5"))
+	test.That(t, err, test.ShouldBeNil)
+	pose1, err := v1.EndPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	v2, err := newWrapper(writeKinematics(t, "1_0_1", "165.5"))
+	test.That(t, err, test.ShouldBeNil)
+	pose2, err := v2.EndPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pose2.Point().Z-pose1.Point().Z, test.ShouldAlmostEqual, 3)
+
+	model, err := v2.Kinematics(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, model.DoF(), test.ShouldHaveLength, 6)
+
+	// kinematics of an arm with other joints are rejected.
+	actual.KinematicsFunc = func(ctx context.Context) (referenceframe.Model, error) {
+		return referenceframe.KinematicModelFromFile(utils.ResolveFile("components/arm/fake/kinematics/xarm7.json"), "")
+	}
+	_, err = newWrapper(writeKinematics(t, "1_0_2", "162.5"))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "degrees of freedom")
+
+	_, err = newWrapper(filepath.Join(t.TempDir(), "missing.json"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,197 @@
+// Package alsa implements an audio in that records from an ALSA device with arecord, from
+// alsa-utils.
+package alsa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/audioin"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	rutils "go.viam.com/rdk/utils"
+)
+
+const (
+	defaultDevice     = "default"
+	defaultSampleRate = 16000
+	defaultChannels   = 1
+	chunkDuration     = 100 * time.Millisecond
+)
+
+// arecordCommand is a variable so that tests can replace it.
+var arecordCommand = "arecord"
+
+var model = resource.DefaultModelFamily.WithModel("alsa")
+
+func init() {
+	resource.RegisterComponent(
+		audioin.API,
+		model,
+		resource.Registration[audioin.AudioIn, *Config]{Constructor: NewAudioIn})
+}
+
+// A Config describes the ALSA device to record from.
+type Config struct {
+	// Device is the ALSA device to record from, such as "default" or "plughw:1,0". Device defaults to
+	// "default" if unspecified.
+	Device string `json:"device,omitempty"`
+	// SampleRate defaults to 16000 if unspecified.
+	SampleRate int `json:"sample_rate,omitempty"`
+	// NumChannels defaults to 1 if unspecified.
+	NumChannels int `json:"num_channels,omitempty"`
+}
+
+// Validate validates the config.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.SampleRate < 0 {
+		return nil, nil, fmt.Errorf("sample_rate must be greater than 0 if provided, got %d", conf.SampleRate)
+	}
+	if conf.NumChannels < 0 {
+		return nil, nil, fmt.Errorf("num_channels must be greater than 0 if provided, got %d", conf.NumChannels)
+	}
+	return nil, nil, nil
+}
+
+// AudioIn records audio from an ALSA device. Each call to GetAudio records with its own arecord
+// process, so devices that cannot be opened more than once, such as hw devices, support one
+// stream at a time.
+type AudioIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger      logging.Logger
+	device      string
+	sampleRate  int
+	numChannels int
+	workers     *goutils.StoppableWorkers
+}
+
+// NewAudioIn instantiates a new AudioIn of the alsa model type.
+func NewAudioIn(_ context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger) (audioin.AudioIn, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath(arecordCommand); err != nil {
+		return nil, errors.Wrap(err, "arecord is required to record from ALSA; install alsa-utils")
+	}
+
+	a := &AudioIn{
+		Named:       conf.ResourceName().AsNamed(),
+		logger:      logger,
+		device:      newConf.Device,
+		sampleRate:  newConf.SampleRate,
+		numChannels: newConf.NumChannels,
+		workers:     goutils.NewBackgroundStoppableWorkers(),
+	}
+	if a.device == "" {
+		a.device = defaultDevice
+	}
+	if a.sampleRate == 0 {
+		a.sampleRate = defaultSampleRate
+	}
+	if a.numChannels == 0 {
+		a.numChannels = defaultChannels
+	}
+	return a, nil
+}
+
+// GetAudio records audio in 100ms chunks of pcm16 for durationSeconds, or until ctx is done if
+// durationSeconds is 0. Since the device is live, previousTimestampNs is ignored and recording
+// starts at the time of the call.
+func (a *AudioIn) GetAudio(ctx context.Context,
+	codec string, durationSeconds float32,
+	previousTimestampNs int64,
+	extra map[string]interface{}) (
+	chan *audioin.AudioChunk, error,
+) {
+	if codec != "" && codec != rutils.CodecPCM16 {
+		return nil, fmt.Errorf("codec %s not supported, only %s is supported", codec, rutils.CodecPCM16)
+	}
+	if durationSeconds < 0 {
+		return nil, errors.New("duration must not be negative")
+	}
+
+	// the recording outlives the call, so it is stopped by the context of the call or by Close.
+	recordCtx, cancel := context.WithCancel(ctx)
+	//nolint:gosec
+	cmd := exec.CommandContext(recordCtx, arecordCommand, "-q", "-t", "raw", "-f", "S16_LE",
+		"-r", strconv.Itoa(a.sampleRate), "-c", strconv.Itoa(a.numChannels), "-D", a.device)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, errors.Wrap(err, "starting arecord")
+	}
+
+	info := &rutils.AudioInfo{Codec: rutils.CodecPCM16, SampleRateHz: int32(a.sampleRate), NumChannels: int32(a.numChannels)}
+	bytesPerSecond := a.sampleRate * a.numChannels * 2
+	chunkSize := bytesPerSecond * int(chunkDuration/time.Millisecond) / 1000
+	totalChunks := -1
+	if durationSeconds > 0 {
+		totalChunks = int(math.Ceil(float64(durationSeconds) * float64(time.Second/chunkDuration)))
+	}
+
+	chunkChan := make(chan *audioin.AudioChunk)
+	a.workers.Add(func(workerCtx context.Context) {
+		defer close(chunkChan)
+		defer func() {
+			cancel()
+			goutils.UncheckedError(cmd.Wait())
+		}()
+		stop := context.AfterFunc(workerCtx, cancel)
+		defer stop()
+
+		startNs := time.Now().UnixNano()
+		for sequence := int32(0); totalChunks < 0 || int(sequence) < totalChunks; sequence++ {
+			data := make([]byte, chunkSize)
+			if _, err := io.ReadFull(stdout, data); err != nil {
+				if recordCtx.Err() == nil {
+					a.logger.CWarnw(ctx, "stopped recording from ALSA", "device", a.device, "error", err)
+				}
+				return
+			}
+			endNs := startNs + int64(chunkDuration)
+			chunk := &audioin.AudioChunk{
+				AudioData:                 data,
+				AudioInfo:                 info,
+				Sequence:                  sequence,
+				StartTimestampNanoseconds: startNs,
+				EndTimestampNanoseconds:   endNs,
+			}
+			select {
+			case chunkChan <- chunk:
+			case <-recordCtx.Done():
+				return
+			}
+			startNs = endNs
+		}
+	})
+	return chunkChan, nil
+}
+
+// Properties returns the audio input's properties.
+func (a *AudioIn) Properties(ctx context.Context, extra map[string]interface{}) (rutils.Properties, error) {
+	return rutils.Properties{
+		SupportedCodecs: []string{rutils.CodecPCM16},
+		SampleRateHz:    int32(a.sampleRate),
+		NumChannels:     int32(a.numChannels),
+	}, nil
+}
+
+// Close stops all recordings.
+func (a *AudioIn) Close(ctx context.Context) error {
+	a.workers.Stop()
+	return nil
+}
@@ -0,0 +1,87 @@
+package alsa
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/audioin"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// fakeArecord replaces arecord with a script that records silence endlessly.
+func fakeArecord(t *testing.T) {
+	t.Helper()
+	arecord := filepath.Join(t.TempDir(), "arecord")
+	test.That(t, os.WriteFile(arecord, []byte("#!/bin/sh\nexec cat /dev/zero\n"), 0o700), test.ShouldBeNil)
+	old := arecordCommand
+	arecordCommand = arecord
+	t.Cleanup(func() { arecordCommand = old })
+}
+
+func newAudioIn(t *testing.T) audioin.AudioIn {
+	t.Helper()
+	in, err := NewAudioIn(context.Background(), nil, resource.Config{
+		Name:                "mic",
+		API:                 audioin.API,
+		Model:               model,
+		ConvertedAttributes: &Config{SampleRate: 8000},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return in
+}
+
+func TestGetAudio(t *testing.T) {
+	fakeArecord(t)
+	in := newAudioIn(t)
+	defer in.Close(context.Background())
+
+	chunks, err := in.GetAudio(context.Background(), rutils.CodecPCM16, 0.25, 0, nil)
+	test.That(t, err, test.ShouldBeNil)
+	var received []*audioin.AudioChunk
+	for chunk := range chunks {
+		received = append(received, chunk)
+	}
+	// 0.25s is recorded in three chunks of 100ms.
+	test.That(t, received, test.ShouldHaveLength, 3)
+	for i, chunk := range received {
+		test.That(t, chunk.Sequence, test.ShouldEqual, i)
+		// 100ms of mono pcm16 at 8000Hz.
+		test.That(t, chunk.AudioData, test.ShouldHaveLength, 1600)
+		test.That(t, chunk.AudioInfo, test.ShouldResemble, &rutils.AudioInfo{Codec: rutils.CodecPCM16, SampleRateHz: 8000, NumChannels: 1})
+		test.That(t, chunk.EndTimestampNanoseconds-chunk.StartTimestampNanoseconds, test.ShouldEqual, int64(100*time.Millisecond))
+		if i > 0 {
+			test.That(t, chunk.StartTimestampNanoseconds, test.ShouldEqual, received[i-1].EndTimestampNanoseconds)
+		}
+	}
+
+	t.Run("endless recordings stop with the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		chunks, err := in.GetAudio(ctx, "", 0, 0, nil)
+		test.That(t, err, test.ShouldBeNil)
+		<-chunks
+		cancel()
+		for range chunks {
+		}
+	})
+
+	t.Run("endless recordings stop on close", func(t *testing.T) {
+		chunks, err := in.GetAudio(context.Background(), "", 0, 0, nil)
+		test.That(t, err, test.ShouldBeNil)
+		<-chunks
+		test.That(t, in.Close(context.Background()), test.ShouldBeNil)
+		for range chunks {
+		}
+	})
+
+	t.Run("unsupported codec", func(t *testing.T) {
+		_, err := in.GetAudio(context.Background(), rutils.CodecOpus, 1, 0, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -3,5 +3,6 @@ package register
 
 import (
 	// audio in import
+	_ "go.viam.com/rdk/components/audioin/alsa"
 	_ "go.viam.com/rdk/components/audioin/fake"
 )
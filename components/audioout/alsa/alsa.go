@@ -0,0 +1,256 @@
+// Package alsa implements an audio out that plays through an ALSA device with aplay, and sets its
+// volume with amixer, both from alsa-utils.
+package alsa
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/audioout"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	defaultDevice       = "default"
+	defaultMixerControl = "Master"
+	defaultSampleRate   = 48000
+	defaultChannels     = 2
+)
+
+// The commands run are variables so that tests can replace them.
+var (
+	aplayCommand  = "aplay"
+	amixerCommand = "amixer"
+)
+
+var model = resource.DefaultModelFamily.WithModel("alsa")
+
+func init() {
+	resource.RegisterComponent(
+		audioout.API,
+		model,
+		resource.Registration[audioout.AudioOut, *Config]{Constructor: NewAudioOut})
+}
+
+// A Config describes the ALSA device to play through.
+type Config struct {
+	// Device is the ALSA device to play through, such as "default" or "plughw:1,0". Device defaults
+	// to "default" if unspecified.
+	Device string `json:"device,omitempty"`
+	// MixerCard is the card whose mixer sets the volume, such as "1". MixerCard defaults to the
+	// default card if unspecified.
+	MixerCard string `json:"mixer_card,omitempty"`
+	// MixerControl is the mixer control that sets the volume. MixerControl defaults to "Master" if
+	// unspecified.
+	MixerControl string `json:"mixer_control,omitempty"`
+	// SampleRate and NumChannels are the properties reported for the device. Audio of any sample rate
+	// and number of channels is played, converted by ALSA if needed.
+	SampleRate  int `json:"sample_rate,omitempty"`
+	NumChannels int `json:"num_channels,omitempty"`
+}
+
+// Validate validates the config.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.SampleRate < 0 {
+		return nil, nil, fmt.Errorf("sample_rate must be greater than 0 if provided, got %d", conf.SampleRate)
+	}
+	if conf.NumChannels < 0 {
+		return nil, nil, fmt.Errorf("num_channels must be greater than 0 if provided, got %d", conf.NumChannels)
+	}
+	return nil, nil, nil
+}
+
+// AudioOut plays audio through an ALSA device. Only one stream is played at a time; a call to play
+// waits for the stream before it to finish.
+type AudioOut struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger       logging.Logger
+	device       string
+	mixerCard    string
+	mixerControl string
+	sampleRate   int
+	numChannels  int
+
+	playMu sync.Mutex
+}
+
+// NewAudioOut instantiates a new AudioOut of the alsa model type.
+func NewAudioOut(_ context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger) (audioout.AudioOut, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath(aplayCommand); err != nil {
+		return nil, errors.Wrap(err, "aplay is required to play through ALSA; install alsa-utils")
+	}
+
+	a := &AudioOut{
+		Named:        conf.ResourceName().AsNamed(),
+		logger:       logger,
+		device:       newConf.Device,
+		mixerCard:    newConf.MixerCard,
+		mixerControl: newConf.MixerControl,
+		sampleRate:   newConf.SampleRate,
+		numChannels:  newConf.NumChannels,
+	}
+	if a.device == "" {
+		a.device = defaultDevice
+	}
+	if a.mixerControl == "" {
+		a.mixerControl = defaultMixerControl
+	}
+	if a.sampleRate == 0 {
+		a.sampleRate = defaultSampleRate
+	}
+	if a.numChannels == 0 {
+		a.numChannels = defaultChannels
+	}
+	return a, nil
+}
+
+// Play plays data and returns once it has been played.
+func (a *AudioOut) Play(ctx context.Context, data []byte, info *utils.AudioInfo, extra map[string]interface{}) error {
+	if len(data) == 0 {
+		return errors.New("no audio data provided")
+	}
+	chunks := make(chan []byte, 1)
+	chunks <- data
+	close(chunks)
+	return a.PlayStream(ctx, info, chunks, extra)
+}
+
+// PlayStream plays the chunks received until chunks is closed, and returns once they have been
+// played.
+func (a *AudioOut) PlayStream(ctx context.Context, info *utils.AudioInfo, chunks <-chan []byte, _ map[string]interface{}) error {
+	if info == nil {
+		return errors.New("audio info is required")
+	}
+	if info.Codec != utils.CodecPCM16 {
+		return fmt.Errorf("codec %s not supported, only %s is supported", info.Codec, utils.CodecPCM16)
+	}
+	if info.NumChannels <= 0 || info.SampleRateHz <= 0 {
+		return errors.New("invalid audio info, sample rate and num channels must be above zero")
+	}
+
+	a.playMu.Lock()
+	defer a.playMu.Unlock()
+
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, aplayCommand, "-q", "-t", "raw", "-f", "S16_LE",
+		"-r", strconv.Itoa(int(info.SampleRateHz)), "-c", strconv.Itoa(int(info.NumChannels)), "-D", a.device)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "starting aplay")
+	}
+
+	writeErr := writeChunks(ctx, stdin, chunks)
+	if err := stdin.Close(); writeErr == nil {
+		writeErr = err
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return errors.Wrap(err, "playing through aplay")
+	}
+	return writeErr
+}
+
+// writeChunks writes the chunks received to w until chunks is closed.
+func writeChunks(ctx context.Context, w io.Writer, chunks <-chan []byte) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case chunk, ok := <-chunks:
+			if !ok {
+				return nil
+			}
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Properties returns the audio output's properties.
+func (a *AudioOut) Properties(ctx context.Context, extra map[string]interface{}) (utils.Properties, error) {
+	return utils.Properties{
+		SupportedCodecs: []string{utils.CodecPCM16},
+		SampleRateHz:    int32(a.sampleRate),
+		NumChannels:     int32(a.numChannels),
+	}, nil
+}
+
+// DoCommand gets and sets the volume of the mixer control with audioout.GetVolumeCommand and
+// audioout.SetVolumeCommand.
+func (a *AudioOut) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if value, ok := cmd[audioout.SetVolumeCommand]; ok {
+		volume, ok := value.(float64)
+		if !ok || volume < 0 || volume > 1 {
+			return nil, fmt.Errorf("%s must be a number from 0.0 to 1.0", audioout.SetVolumeCommand)
+		}
+		if _, err := a.amixer(ctx, "sset", a.mixerControl, fmt.Sprintf("%d%%", int(math.Round(volume*100)))); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{}, nil
+	}
+	if _, ok := cmd[audioout.GetVolumeCommand]; ok {
+		out, err := a.amixer(ctx, "sget", a.mixerControl)
+		if err != nil {
+			return nil, err
+		}
+		volume, err := parseVolume(out)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{audioout.VolumeKey: volume}, nil
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+func (a *AudioOut) amixer(ctx context.Context, args ...string) (string, error) {
+	if a.mixerCard != "" {
+		args = append([]string{"-c", a.mixerCard}, args...)
+	}
+	//nolint:gosec
+	out, err := exec.CommandContext(ctx, amixerCommand, args...).CombinedOutput()
+	if err != nil {
+		return "", errors.Wrapf(err, "running amixer: %s", out)
+	}
+	return string(out), nil
+}
+
+var volumePattern = regexp.MustCompile(`\[(\d+)%\]`)
+
+// parseVolume returns the volume of the first channel amixer reports, from 0.0 to 1.0.
+func parseVolume(amixerOutput string) (float64, error) {
+	match := volumePattern.FindStringSubmatch(amixerOutput)
+	if match == nil {
+		return 0, errors.New("amixer did not report a volume for the mixer control")
+	}
+	percent, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, err
+	}
+	return float64(percent) / 100, nil
+}
+
+// Close does nothing, since playback stops with the context of the call playing.
+func (a *AudioOut) Close(ctx context.Context) error {
+	return nil
+}
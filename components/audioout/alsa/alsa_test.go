@@ -0,0 +1,109 @@
+package alsa
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/audioout"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+// fakeCommands replaces aplay and amixer with scripts that record their arguments and input in
+// dir, with amixer reporting the volume last set.
+func fakeCommands(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	aplay := filepath.Join(dir, "aplay")
+	test.That(t, os.WriteFile(aplay, []byte("#!/bin/sh\necho \"$@\" > "+dir+"/aplay_args\ncat > "+dir+"/played\n"), 0o700),
+		test.ShouldBeNil)
+	amixer := filepath.Join(dir, "amixer")
+	test.That(t, os.WriteFile(amixer, []byte(`#!/bin/sh
+echo "$@" > `+dir+`/amixer_args
+if [ "$3" = sset ] || [ "$1" = sset ]; then
+	for arg; do volume=$arg; done
+	echo "$volume" > `+dir+`/volume
+fi
+echo "  Front Left: Playback 42 [$(cat `+dir+`/volume 2>/dev/null || echo 50%)] [on]"
+`), 0o700), test.ShouldBeNil)
+
+	oldAplay, oldAmixer := aplayCommand, amixerCommand
+	aplayCommand, amixerCommand = aplay, amixer
+	t.Cleanup(func() {
+		aplayCommand, amixerCommand = oldAplay, oldAmixer
+	})
+	return dir
+}
+
+func newAudioOut(t *testing.T, conf *Config) audioout.AudioOut {
+	t.Helper()
+	out, err := NewAudioOut(context.Background(), nil, resource.Config{
+		Name:                "speaker",
+		API:                 audioout.API,
+		Model:               model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return out
+}
+
+func TestPlay(t *testing.T) {
+	dir := fakeCommands(t)
+	out := newAudioOut(t, &Config{Device: "plughw:1,0"})
+	ctx := context.Background()
+
+	info := &utils.AudioInfo{Codec: utils.CodecPCM16, SampleRateHz: 16000, NumChannels: 1}
+	test.That(t, out.Play(ctx, []byte{1, 2, 3, 4}, info, nil), test.ShouldBeNil)
+	played, err := os.ReadFile(filepath.Join(dir, "played"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, played, test.ShouldResemble, []byte{1, 2, 3, 4})
+	args, err := os.ReadFile(filepath.Join(dir, "aplay_args"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, strings.TrimSpace(string(args)), test.ShouldEqual, "-q -t raw -f S16_LE -r 16000 -c 1 -D plughw:1,0")
+
+	t.Run("stream", func(t *testing.T) {
+		chunks := make(chan []byte, 2)
+		chunks <- []byte{5, 6}
+		chunks <- []byte{7, 8}
+		close(chunks)
+		test.That(t, out.PlayStream(ctx, info, chunks, nil), test.ShouldBeNil)
+		played, err := os.ReadFile(filepath.Join(dir, "played"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, played, test.ShouldResemble, []byte{5, 6, 7, 8})
+	})
+
+	t.Run("unsupported codec", func(t *testing.T) {
+		err := out.Play(ctx, []byte{1, 2}, &utils.AudioInfo{Codec: utils.CodecMP3, SampleRateHz: 16000, NumChannels: 1}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "not supported")
+	})
+}
+
+func TestVolume(t *testing.T) {
+	dir := fakeCommands(t)
+	out := newAudioOut(t, &Config{MixerCard: "1", MixerControl: "PCM"})
+	ctx := context.Background()
+
+	volume, err := audioout.Volume(ctx, out)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, volume, test.ShouldEqual, 0.5)
+
+	test.That(t, audioout.SetVolume(ctx, out, 0.25), test.ShouldBeNil)
+	args, err := os.ReadFile(filepath.Join(dir, "amixer_args"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, strings.TrimSpace(string(args)), test.ShouldEqual, "-c 1 sset PCM 25%")
+
+	volume, err = audioout.Volume(ctx, out)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, volume, test.ShouldEqual, 0.25)
+
+	test.That(t, audioout.SetVolume(ctx, out, 2), test.ShouldNotBeNil)
+	_, err = out.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+}
@@ -4,6 +4,7 @@ package fake
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.viam.com/rdk/components/audioout"
@@ -29,6 +30,9 @@ type AudioOut struct {
 	sampleRate      int
 	numChannels     int
 	supportedCodecs []string
+
+	volumeMu sync.Mutex
+	volume   float64
 }
 
 // NewAudioOut instantiates a new AudioOut of the fake model type.
@@ -40,6 +44,7 @@ func NewAudioOut(_ context.Context, _ resource.Dependencies, conf resource.Confi
 		sampleRate:      44100,
 		numChannels:     1,
 		supportedCodecs: []string{"pcm16"},
+		volume:          1,
 	}
 
 	return a, nil
@@ -132,6 +137,25 @@ func (a *AudioOut) Properties(ctx context.Context, extra map[string]interface{})
 	}, nil
 }
 
+// DoCommand gets and sets the simulated volume with audioout.GetVolumeCommand and
+// audioout.SetVolumeCommand.
+func (a *AudioOut) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	a.volumeMu.Lock()
+	defer a.volumeMu.Unlock()
+	if value, ok := cmd[audioout.SetVolumeCommand]; ok {
+		volume, ok := value.(float64)
+		if !ok || volume < 0 || volume > 1 {
+			return nil, fmt.Errorf("%s must be a number from 0.0 to 1.0", audioout.SetVolumeCommand)
+		}
+		a.volume = volume
+		return map[string]interface{}{}, nil
+	}
+	if _, ok := cmd[audioout.GetVolumeCommand]; ok {
+		return map[string]interface{}{audioout.VolumeKey: a.volume}, nil
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
 // Geometries returns the geometries associated with the fake audio output.
 func (a *AudioOut) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
 	return a.Geometry, nil
@@ -3,5 +3,6 @@ package register
 
 import (
 	// audio out import
+	_ "go.viam.com/rdk/components/audioout/alsa"
 	_ "go.viam.com/rdk/components/audioout/fake"
 )
@@ -0,0 +1,39 @@
+package audioout
+
+import (
+	"context"
+	"fmt"
+)
+
+// The AudioOut API has no volume methods, so audio outs that can set their volume do so through
+// DoCommand with these commands. Volumes range from 0.0 (muted) to 1.0 (full volume).
+const (
+	// GetVolumeCommand is the DoCommand key that requests the volume, which is returned under VolumeKey.
+	GetVolumeCommand = "get_volume"
+	// SetVolumeCommand is the DoCommand key whose value is the volume to set.
+	SetVolumeCommand = "set_volume"
+	// VolumeKey is the key of the volume in the response to GetVolumeCommand.
+	VolumeKey = "volume"
+)
+
+// Volume returns the volume of out, from 0.0 to 1.0, if it supports GetVolumeCommand.
+func Volume(ctx context.Context, out AudioOut) (float64, error) {
+	resp, err := out.DoCommand(ctx, map[string]interface{}{GetVolumeCommand: true})
+	if err != nil {
+		return 0, err
+	}
+	volume, ok := resp[VolumeKey].(float64)
+	if !ok {
+		return 0, fmt.Errorf("audio out %q did not return its volume", out.Name())
+	}
+	return volume, nil
+}
+
+// SetVolume sets the volume of out, from 0.0 to 1.0, if it supports SetVolumeCommand.
+func SetVolume(ctx context.Context, out AudioOut, volume float64) error {
+	if volume < 0 || volume > 1 {
+		return fmt.Errorf("volume must be from 0.0 to 1.0, got %v", volume)
+	}
+	_, err := out.DoCommand(ctx, map[string]interface{}{SetVolumeCommand: volume})
+	return err
+}
@@ -9,6 +9,7 @@ import (
 	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/simulation"
 	"go.viam.com/rdk/spatialmath"
 )
 
@@ -16,26 +17,45 @@ func init() {
 	resource.RegisterComponent(
 		base.API,
 		resource.DefaultModelFamily.WithModel("fake"),
-		resource.Registration[base.Base, resource.NoNativeConfig]{Constructor: NewBase},
+		resource.Registration[base.Base, *Config]{Constructor: NewBase},
 	)
 }
 
+// Config is used for converting config attributes.
+type Config struct {
+	// Simulation makes the base drive over time, short of obstacles, rather than doing nothing when
+	// told to move.
+	Simulation *simulation.Config `json:"simulation,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.Simulation != nil {
+		if err := conf.Simulation.Validate(path); err != nil {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, nil
+}
+
 const (
 	defaultWidthMm               = 600
 	defaultMinimumTurningRadiusM = 0
 	defaultWheelCircumferenceM   = 3
 )
 
-// Base is a fake base that returns what it was provided in each method.
+// Base is a fake base that returns what it was provided in each method, or drives a simulation of
+// itself if it has one.
 type Base struct {
 	resource.Named
-	resource.TriviallyReconfigurable
+	resource.AlwaysRebuild
 	CloseCount               int
 	WidthMeters              float64
 	TurningRadius            float64
 	WheelCircumferenceMeters float64
 	Geometry                 []spatialmath.Geometry
 	logger                   logging.Logger
+	sim                      *simulation.Planar
 }
 
 // NewBase instantiates a new base of the fake model type.
@@ -54,41 +74,83 @@ func NewBase(_ context.Context, _ resource.Dependencies, conf resource.Config, l
 	}
 	b.WidthMeters = defaultWidthMm * 0.001
 	b.TurningRadius = defaultMinimumTurningRadiusM
+	if conf.ConvertedAttributes != nil {
+		newConf, err := resource.NativeConfig[*Config](conf)
+		if err != nil {
+			return nil, err
+		}
+		if newConf.Simulation != nil {
+			if b.sim, err = simulation.NewPlanar(conf.Name, newConf.Simulation, b.Geometry); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return b, nil
 }
 
-// MoveStraight does nothing.
+// SimulatedPose returns where a simulated base has driven to in the frame it started in, or nil if
+// the base is not simulated.
+func (b *Base) SimulatedPose() spatialmath.Pose {
+	if b.sim == nil {
+		return nil
+	}
+	return b.sim.Pose()
+}
+
+// MoveStraight drives a simulated base and does nothing otherwise.
 func (b *Base) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	if b.sim != nil {
+		return b.sim.MoveStraight(ctx, float64(distanceMm), mmPerSec)
+	}
 	return nil
 }
 
-// Spin does nothing.
+// Spin spins a simulated base and does nothing otherwise.
 func (b *Base) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	if b.sim != nil {
+		return b.sim.Spin(ctx, angleDeg, degsPerSec)
+	}
 	return nil
 }
 
-// SetPower does nothing.
+// SetPower drives a simulated base forward and spins it at fractions of its maximum speeds, and
+// does nothing otherwise.
 func (b *Base) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	if b.sim != nil {
+		b.sim.SetPower(linear.Y, angular.Z)
+	}
 	return nil
 }
 
-// SetVelocity does nothing.
+// SetVelocity drives a simulated base forward and spins it and does nothing otherwise.
 func (b *Base) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	if b.sim != nil {
+		b.sim.SetVelocity(linear.Y, angular.Z)
+	}
 	return nil
 }
 
-// Stop does nothing.
+// Stop stops a simulated base and does nothing otherwise.
 func (b *Base) Stop(ctx context.Context, extra map[string]interface{}) error {
+	if b.sim != nil {
+		b.sim.Stop()
+	}
 	return nil
 }
 
-// IsMoving always returns false.
+// IsMoving returns whether a simulated base is moving, and always false otherwise.
 func (b *Base) IsMoving(ctx context.Context) (bool, error) {
+	if b.sim != nil {
+		return b.sim.IsMoving(), nil
+	}
 	return false, nil
 }
 
-// Close does nothing.
+// Close stops a simulated base.
 func (b *Base) Close(ctx context.Context) error {
+	if b.sim != nil {
+		b.sim.Stop()
+	}
 	b.CloseCount++
 	return nil
 }
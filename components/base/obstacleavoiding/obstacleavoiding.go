@@ -0,0 +1,285 @@
+// Package obstacleavoiding implements a base that drives another base, refusing to drive toward the
+// obstacles its ranging arrays detect too close ahead or behind and stopping it when they come too
+// close while it drives. The arrays are taken to be mounted with their fronts facing the front of
+// the base, so that azimuth zero of their frames is where the base drives forward.
+package obstacleavoiding
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/rangingarray"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// Model is the model of bases that avoid the obstacles their ranging arrays detect.
+var Model = resource.DefaultModelFamily.WithModel("obstacle-avoiding")
+
+const (
+	defaultStopDistanceMM = 300.
+	defaultSectorDegrees  = 60.
+	defaultPollHz         = 20.
+)
+
+// ErrObstacle is returned when an obstacle is too close in the direction the base would drive.
+var ErrObstacle = errors.New("obstacle in the way of the base")
+
+func init() {
+	resource.RegisterComponent(base.API, Model, resource.Registration[base.Base, *Config]{
+		Constructor: NewBase,
+	})
+}
+
+// Config configures a base that avoids obstacles.
+type Config struct {
+	Base          string   `json:"base"`
+	RangingArrays []string `json:"ranging_arrays"`
+	// StopDistanceMM is how close an obstacle may come in the direction of travel, 300 by default.
+	StopDistanceMM float64 `json:"stop_distance_mm,omitempty"`
+	// SectorDegrees is the full width of the direction of travel watched for obstacles, 60 by default,
+	// which should cover the width of the base at the stop distance.
+	SectorDegrees float64 `json:"sector_degrees,omitempty"`
+	// PollHz is how often the arrays are read while the base drives, 20 by default.
+	PollHz float64 `json:"poll_hz,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.Base == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if len(conf.RangingArrays) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "ranging_arrays")
+	}
+	if conf.StopDistanceMM < 0 || conf.PollHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("stop_distance_mm and poll_hz cannot be negative"))
+	}
+	if conf.SectorDegrees < 0 || conf.SectorDegrees > 360 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("sector_degrees must be between 0 and 360"))
+	}
+	deps := append([]string{conf.Base}, conf.RangingArrays...)
+	return deps, nil, nil
+}
+
+type avoidingBase struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	base         base.Base
+	arrays       []rangingarray.RangingArray
+	stopDistance float64
+	halfSector   float64
+	pollInterval time.Duration
+	logger       logging.Logger
+
+	mu sync.Mutex
+	// watcher stops the base if an obstacle comes too close while it drives at a velocity or power.
+	watcher *utils.StoppableWorkers
+}
+
+// NewBase returns a base that drives the configured base clear of obstacles.
+func NewBase(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (base.Base, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b := &avoidingBase{
+		Named:        conf.ResourceName().AsNamed(),
+		stopDistance: newConf.StopDistanceMM,
+		halfSector:   newConf.SectorDegrees * math.Pi / 360,
+		logger:       logger,
+	}
+	if b.stopDistance == 0 {
+		b.stopDistance = defaultStopDistanceMM
+	}
+	if b.halfSector == 0 {
+		b.halfSector = defaultSectorDegrees * math.Pi / 360
+	}
+	pollHz := newConf.PollHz
+	if pollHz == 0 {
+		pollHz = defaultPollHz
+	}
+	b.pollInterval = time.Duration(float64(time.Second) / pollHz)
+	if b.base, err = base.FromProvider(deps, newConf.Base); err != nil {
+		return nil, err
+	}
+	for _, name := range newConf.RangingArrays {
+		a, err := rangingarray.FromProvider(deps, name)
+		if err != nil {
+			return nil, err
+		}
+		b.arrays = append(b.arrays, a)
+	}
+	return b, nil
+}
+
+// checkClear returns ErrObstacle if an obstacle is within the stop distance in the direction of the
+// azimuth. An array that cannot be read keeps the base from driving, as an obstacle would.
+func (b *avoidingBase) checkClear(ctx context.Context, azimuth float64) error {
+	for _, a := range b.arrays {
+		props, err := a.Properties(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the properties of %s", a.Name())
+		}
+		ranges, err := a.Ranges(ctx, nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", a.Name())
+		}
+		if d, ok := rangingarray.NearestInDirection(props, ranges, azimuth, b.halfSector); ok && d <= b.stopDistance {
+			return errors.Wrapf(ErrObstacle, "%s detects an obstacle %.0fmm away", a.Name(), d)
+		}
+	}
+	return nil
+}
+
+// watch checks the direction of the azimuth until ctx is done, returning the first obstacle found.
+func (b *avoidingBase) watch(ctx context.Context, azimuth float64) error {
+	for utils.SelectContextOrWait(ctx, b.pollInterval) {
+		if err := b.checkClear(ctx, azimuth); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// stopWatching stops the watcher of the last velocity or power command, returning whether there was one.
+func (b *avoidingBase) stopWatching() bool {
+	b.mu.Lock()
+	watcher := b.watcher
+	b.watcher = nil
+	b.mu.Unlock()
+	if watcher == nil {
+		return false
+	}
+	watcher.Stop()
+	return true
+}
+
+// travelAzimuth returns the azimuth in the frame of the arrays of driving forward, or backward if
+// forward is negative.
+func travelAzimuth(forward float64) float64 {
+	if forward < 0 {
+		return math.Pi
+	}
+	return 0
+}
+
+// MoveStraight moves the base once the way is clear, stopping it if an obstacle comes too close.
+func (b *avoidingBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	b.stopWatching()
+	if distanceMm == 0 || mmPerSec == 0 {
+		return b.base.MoveStraight(ctx, distanceMm, mmPerSec, extra)
+	}
+	azimuth := travelAzimuth(float64(distanceMm) * mmPerSec)
+	if err := b.checkClear(ctx, azimuth); err != nil {
+		return err
+	}
+
+	moveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var obstacleErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	utils.ManagedGo(func() {
+		if obstacleErr = b.watch(moveCtx, azimuth); obstacleErr != nil {
+			cancel()
+		}
+	}, wg.Done)
+	err := b.base.MoveStraight(moveCtx, distanceMm, mmPerSec, extra)
+	cancel()
+	wg.Wait()
+	if obstacleErr != nil {
+		return multierr.Combine(obstacleErr, b.base.Stop(ctx, nil))
+	}
+	return err
+}
+
+// Spin turns the base in place, which does not drive it toward obstacles.
+func (b *avoidingBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	b.stopWatching()
+	return b.base.Spin(ctx, angleDeg, degsPerSec, extra)
+}
+
+// drive sends a velocity or power command once the way is clear, then watches the way until the next
+// command, stopping the base if an obstacle comes too close.
+func (b *avoidingBase) drive(ctx context.Context, forward float64, command func() error) error {
+	b.stopWatching()
+	if forward == 0 {
+		return command()
+	}
+	azimuth := travelAzimuth(forward)
+	if err := b.checkClear(ctx, azimuth); err != nil {
+		return multierr.Combine(err, b.base.Stop(ctx, nil))
+	}
+	if err := command(); err != nil {
+		return err
+	}
+	watcher := utils.NewBackgroundStoppableWorkers(func(ctx context.Context) {
+		if err := b.watch(ctx, azimuth); err != nil {
+			b.logger.CWarnw(ctx, "stopping base short of an obstacle", "error", err)
+			if err := b.base.Stop(ctx, nil); err != nil {
+				b.logger.CErrorw(ctx, "failed to stop base", "error", err)
+			}
+		}
+	})
+	b.mu.Lock()
+	b.watcher = watcher
+	b.mu.Unlock()
+	return nil
+}
+
+// SetPower sets the power of the base once the way is clear.
+func (b *avoidingBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	return b.drive(ctx, linear.Y, func() error {
+		return b.base.SetPower(ctx, linear, angular, extra)
+	})
+}
+
+// SetVelocity sets the velocity of the base once the way is clear.
+func (b *avoidingBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	return b.drive(ctx, linear.Y, func() error {
+		return b.base.SetVelocity(ctx, linear, angular, extra)
+	})
+}
+
+func (b *avoidingBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	b.stopWatching()
+	return b.base.Stop(ctx, extra)
+}
+
+func (b *avoidingBase) IsMoving(ctx context.Context) (bool, error) {
+	return b.base.IsMoving(ctx)
+}
+
+func (b *avoidingBase) Properties(ctx context.Context, extra map[string]interface{}) (base.Properties, error) {
+	return b.base.Properties(ctx, extra)
+}
+
+func (b *avoidingBase) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return b.base.Geometries(ctx, extra)
+}
+
+func (b *avoidingBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return b.base.DoCommand(ctx, cmd)
+}
+
+// Close stops the base if it is driving at a velocity or power, since it would no longer be watched.
+func (b *avoidingBase) Close(ctx context.Context) error {
+	if b.stopWatching() {
+		return b.base.Stop(ctx, nil)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package obstacleavoiding
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/rangingarray"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// testArray has a beam facing the front and one facing the back, which detect obstacles at the
+// distances set, or nothing at zero.
+type testArray struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	mu          sync.Mutex
+	front, back float64
+	readErr     error
+}
+
+func (a *testArray) set(front, back float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.front, a.back = front, back
+}
+
+func (a *testArray) Ranges(ctx context.Context, extra map[string]interface{}) (*rangingarray.Ranges, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r := &rangingarray.Ranges{}
+	for i, d := range []float64{a.front, a.back} {
+		if d > 0 {
+			r.Detections = append(r.Detections, rangingarray.Detection{Beam: i, DistanceMM: d})
+		}
+	}
+	return r, a.readErr
+}
+
+func (a *testArray) Properties(ctx context.Context) (rangingarray.Properties, error) {
+	return rangingarray.Properties{Beams: []rangingarray.Beam{
+		{Azimuth: 0, HorizontalFOV: math.Pi / 6},
+		{Azimuth: math.Pi, HorizontalFOV: math.Pi / 6},
+	}}, nil
+}
+
+func (a *testArray) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	r, err := a.Ranges(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return rangingarray.RangesToReadings(r)
+}
+
+type testBase struct {
+	*inject.Base
+	mu       sync.Mutex
+	stops    int
+	velocity r3.Vector
+	// moving receives when a move starts.
+	moving chan struct{}
+}
+
+func newTestBase() *testBase {
+	b := &testBase{Base: inject.NewBase("wheels"), moving: make(chan struct{}, 1)}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.stops++
+		b.velocity = r3.Vector{}
+		return nil
+	}
+	b.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.velocity = linear
+		return nil
+	}
+	// moves take until they are canceled.
+	b.MoveStraightFunc = func(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+		b.moving <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return b
+}
+
+func (b *testBase) state() (int, r3.Vector) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stops, b.velocity
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := (&Config{Base: "wheels", RangingArrays: []string{"ring"}}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"wheels", "ring"})
+
+	for _, conf := range []*Config{
+		{RangingArrays: []string{"ring"}},
+		{Base: "wheels"},
+		{Base: "wheels", RangingArrays: []string{"ring"}, StopDistanceMM: -1},
+		{Base: "wheels", RangingArrays: []string{"ring"}, SectorDegrees: 400},
+	} {
+		_, _, err := conf.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestAvoidingBase(t *testing.T) {
+	ctx := context.Background()
+	wheels := newTestBase()
+	ring := &testArray{Named: rangingarray.Named("ring").AsNamed()}
+	res, err := NewBase(ctx, resource.Dependencies{
+		base.Named("wheels"):       wheels,
+		rangingarray.Named("ring"): ring,
+	}, resource.Config{
+		Name:                "base",
+		API:                 base.API,
+		ConvertedAttributes: &Config{Base: "wheels", RangingArrays: []string{"ring"}, PollHz: 200},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	// an obstacle ahead keeps the base from driving forward, but not backward.
+	ring.set(200, 0)
+	err = res.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil)
+	test.That(t, errors.Is(err, ErrObstacle), test.ShouldBeTrue)
+	stops, velocity := wheels.state()
+	test.That(t, stops, test.ShouldEqual, 1)
+	test.That(t, velocity, test.ShouldResemble, r3.Vector{})
+	test.That(t, res.MoveStraight(ctx, 100, 100, nil), test.ShouldWrap, ErrObstacle)
+	test.That(t, res.SetVelocity(ctx, r3.Vector{Y: -100}, r3.Vector{}, nil), test.ShouldBeNil)
+	_, velocity = wheels.state()
+	test.That(t, velocity.Y, test.ShouldEqual, -100)
+	// spinning in place is always allowed.
+	test.That(t, res.SetVelocity(ctx, r3.Vector{}, r3.Vector{Z: 30}, nil), test.ShouldBeNil)
+
+	// the base is stopped when an obstacle comes too close while it drives.
+	ring.set(1000, 0)
+	test.That(t, res.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+	ring.set(250, 0)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		stops, velocity := wheels.state()
+		test.That(tb, stops, test.ShouldEqual, 2)
+		test.That(tb, velocity, test.ShouldResemble, r3.Vector{})
+	})
+
+	// a move is cut short by an obstacle behind the base as it reverses.
+	ring.set(0, 1000)
+	moved := make(chan error)
+	go func() {
+		moved <- res.MoveStraight(ctx, 1000, -100, nil)
+	}()
+	<-wheels.moving
+	ring.set(0, 100)
+	test.That(t, <-moved, test.ShouldWrap, ErrObstacle)
+	stops, _ = wheels.state()
+	test.That(t, stops, test.ShouldEqual, 3)
+
+	// an array that cannot be read keeps the base from driving.
+	ring.mu.Lock()
+	ring.readErr = errors.New("disconnected")
+	ring.mu.Unlock()
+	err = res.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "disconnected")
+
+	test.That(t, res.Close(ctx), test.ShouldBeNil)
+}
@@ -4,6 +4,8 @@ package register
 import (
 	// register bases.
 	_ "go.viam.com/rdk/components/base/fake"
+	_ "go.viam.com/rdk/components/base/obstacleavoiding"
 	_ "go.viam.com/rdk/components/base/sensorcontrolled"
+	_ "go.viam.com/rdk/components/base/simbridge"
 	_ "go.viam.com/rdk/components/base/wheeled"
 )
@@ -0,0 +1,320 @@
+// Package simbridge implements a base bound to a robot driving in a running Gazebo or Isaac Sim
+// simulation over ROS 2 topics through rosbridge. It drives the simulated robot with
+// geometry_msgs/Twist messages on cmd_vel, as the differential drive controllers of both simulators
+// take, and measures its motions with nav_msgs/Odometry when the simulation publishes it.
+package simbridge
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/ros/rosbridge"
+	"go.viam.com/rdk/spatialmath"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// Model is the model of bases bound to a simulation.
+var Model = resource.DefaultModelFamily.WithModel("sim-bridge")
+
+const (
+	defaultCmdVelTopic               = "/cmd_vel"
+	defaultMaxLinearSpeedMMPerSec    = 500.
+	defaultMaxAngularSpeedDegsPerSec = 90.
+	// pollInterval is how often motions check their progress and command the simulation again, so
+	// controllers that stop without fresh commands keep driving.
+	pollInterval = 20 * time.Millisecond
+)
+
+// stallTimeout is how long a motion measured by odometry may make no progress before it is
+// considered blocked, such as by a collision in the simulation. Tests shorten it.
+var stallTimeout = 2 * time.Second
+
+func init() {
+	resource.RegisterComponent(base.API, Model, resource.Registration[base.Base, *Config]{
+		Constructor: NewBase,
+	})
+}
+
+// Config binds a base to a simulated robot.
+type Config struct {
+	// RosbridgeURL is the websocket URL of the rosbridge server, such as ws://localhost:9090.
+	RosbridgeURL string `json:"rosbridge_url"`
+	// CmdVelTopic defaults to /cmd_vel.
+	CmdVelTopic string `json:"cmd_vel_topic,omitempty"`
+	// OdometryTopic, such as /odom, measures how far MoveStraight and Spin have gone. Without it they
+	// drive for as long as the motion should take.
+	OdometryTopic             string  `json:"odometry_topic,omitempty"`
+	WidthMM                   int     `json:"width_mm,omitempty"`
+	WheelCircumferenceMM      int     `json:"wheel_circumference_mm,omitempty"`
+	MaxLinearSpeedMMPerSec    float64 `json:"max_linear_speed_mm_per_sec,omitempty"`
+	MaxAngularSpeedDegsPerSec float64 `json:"max_angular_speed_degs_per_sec,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if err := rosbridge.ValidateURL(path, conf.RosbridgeURL); err != nil {
+		return nil, nil, err
+	}
+	if conf.WidthMM < 0 || conf.WheelCircumferenceMM < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("dimensions cannot be negative"))
+	}
+	if conf.MaxLinearSpeedMMPerSec < 0 || conf.MaxAngularSpeedDegsPerSec < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("speeds cannot be negative"))
+	}
+	return nil, nil, nil
+}
+
+type simBase struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf       Config
+	geometries []spatialmath.Geometry
+	client     *rosbridge.Client
+	opMgr      *operation.SingleOperationManager
+	logger     logging.Logger
+
+	mu sync.Mutex
+	// odometry is the latest odometry, or nil if none has been received.
+	odometry *rosbridge.Odometry
+	// driving is whether the last velocity set was not zero.
+	driving bool
+}
+
+// NewBase returns a base bound to a simulated robot.
+func NewBase(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (base.Base, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b := &simBase{
+		Named:      conf.ResourceName().AsNamed(),
+		conf:       *newConf,
+		geometries: []spatialmath.Geometry{},
+		opMgr:      operation.NewSingleOperationManager(),
+		logger:     logger,
+	}
+	if conf.Frame != nil && conf.Frame.Geometry != nil {
+		geometry, err := conf.Frame.Geometry.ParseConfig()
+		if err != nil {
+			return nil, err
+		}
+		b.geometries = []spatialmath.Geometry{geometry}
+	}
+	if b.conf.CmdVelTopic == "" {
+		b.conf.CmdVelTopic = defaultCmdVelTopic
+	}
+	if b.conf.MaxLinearSpeedMMPerSec == 0 {
+		b.conf.MaxLinearSpeedMMPerSec = defaultMaxLinearSpeedMMPerSec
+	}
+	if b.conf.MaxAngularSpeedDegsPerSec == 0 {
+		b.conf.MaxAngularSpeedDegsPerSec = defaultMaxAngularSpeedDegsPerSec
+	}
+	b.client = rosbridge.NewClient(newConf.RosbridgeURL, logger)
+	if b.conf.OdometryTopic != "" {
+		b.client.Subscribe(b.conf.OdometryTopic, rosbridge.TypeOdometry, b.handleOdometry)
+	}
+	return b, nil
+}
+
+func (b *simBase) handleOdometry(msg json.RawMessage) {
+	var odom rosbridge.Odometry
+	if err := json.Unmarshal(msg, &odom); err != nil {
+		b.logger.Warnw("invalid odometry", "topic", b.conf.OdometryTopic, "error", err)
+		return
+	}
+	b.mu.Lock()
+	b.odometry = &odom
+	b.mu.Unlock()
+}
+
+// travel returns where the odometry has the base, in millimeters, and its heading in degrees
+// counterclockwise.
+func (b *simBase) travel() (r3.Vector, float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.odometry == nil {
+		return r3.Vector{}, 0, errors.Errorf("no odometry for %s received on %s yet", b.Name().ShortName(), b.conf.OdometryTopic)
+	}
+	q := b.odometry.Pose.Pose.Orientation
+	yaw := math.Atan2(2*(q.W*q.Z+q.X*q.Y), 1-2*(q.Y*q.Y+q.Z*q.Z))
+	return b.odometry.Pose.Pose.Position.R3(1000), rutils.RadToDeg(yaw), nil
+}
+
+// command publishes a velocity, forward in millimeters per second and counterclockwise in degrees
+// per second, for the simulated robot to drive at.
+func (b *simBase) command(ctx context.Context, forward, turn float64) error {
+	b.mu.Lock()
+	b.driving = forward != 0 || turn != 0
+	b.mu.Unlock()
+	return b.client.Publish(ctx, b.conf.CmdVelTopic, rosbridge.TypeTwist, rosbridge.Twist{
+		Linear:  rosbridge.Vector3{X: forward / 1000},
+		Angular: rosbridge.Vector3{Z: rutils.DegToRad(turn)},
+	})
+}
+
+// drive drives at a velocity until progress, which returns how much of the motion is left, reaches
+// zero, then stops.
+func (b *simBase) drive(ctx context.Context, forward, turn float64, progress func() (float64, error)) (err error) {
+	ctx, done := b.opMgr.New(ctx)
+	defer done()
+	defer func() {
+		// stop even when the motion is canceled, which has already canceled ctx.
+		if stopErr := b.command(context.Background(), 0, 0); err == nil {
+			err = stopErr
+		}
+	}()
+	closest := math.Inf(1)
+	lastProgress := time.Now()
+	for {
+		left, err := progress()
+		if err != nil {
+			return err
+		}
+		if left <= 0 {
+			return nil
+		}
+		if left < closest-1 {
+			closest, lastProgress = left, time.Now()
+		} else if time.Since(lastProgress) > stallTimeout {
+			return errors.Errorf("%s stopped short of its goal, it may be blocked in the simulation", b.Name().ShortName())
+		}
+		if err := b.command(ctx, forward, turn); err != nil {
+			return err
+		}
+		if !utils.SelectContextOrWait(ctx, pollInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// timed returns a progress of a motion that takes dur.
+func timed(dur time.Duration) func() (float64, error) {
+	start := time.Now()
+	return func() (float64, error) {
+		return float64(dur - time.Since(start)), nil
+	}
+}
+
+// MoveStraight drives distanceMm forward, or backward if it or mmPerSec is negative.
+func (b *simBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	if distanceMm == 0 {
+		return nil
+	}
+	if mmPerSec == 0 {
+		return errors.New("cannot move straight at a speed of 0")
+	}
+	distance := math.Abs(float64(distanceMm))
+	speed := math.Copysign(mmPerSec, float64(distanceMm)*mmPerSec)
+	progress := timed(time.Duration(distance / math.Abs(speed) * float64(time.Second)))
+	if b.conf.OdometryTopic != "" {
+		start, _, err := b.travel()
+		if err != nil {
+			return err
+		}
+		progress = func() (float64, error) {
+			at, _, err := b.travel()
+			return distance - at.Sub(start).Norm(), err
+		}
+	}
+	return b.drive(ctx, speed, 0, progress)
+}
+
+// Spin turns angleDeg counterclockwise, or clockwise if it or degsPerSec is negative.
+func (b *simBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	if angleDeg == 0 {
+		return nil
+	}
+	if degsPerSec == 0 {
+		return errors.New("cannot spin at a speed of 0")
+	}
+	angle := math.Abs(angleDeg)
+	speed := math.Copysign(degsPerSec, angleDeg*degsPerSec)
+	progress := timed(time.Duration(angle / math.Abs(speed) * float64(time.Second)))
+	if b.conf.OdometryTopic != "" {
+		_, last, err := b.travel()
+		if err != nil {
+			return err
+		}
+		var turned float64
+		progress = func() (float64, error) {
+			_, heading, err := b.travel()
+			// accumulate the change in heading, which wraps around at 180 degrees.
+			turned += math.Abs(math.Remainder(heading-last, 360))
+			last = heading
+			return angle - turned, err
+		}
+	}
+	return b.drive(ctx, 0, speed, progress)
+}
+
+// SetPower drives at fractions, from -1 to 1, of the maximum speeds.
+func (b *simBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	return b.SetVelocity(ctx,
+		r3.Vector{Y: linear.Y * b.conf.MaxLinearSpeedMMPerSec},
+		r3.Vector{Z: angular.Z * b.conf.MaxAngularSpeedDegsPerSec},
+		extra)
+}
+
+// SetVelocity drives forward at linear.Y millimeters per second and turns counterclockwise at
+// angular.Z degrees per second until told otherwise.
+func (b *simBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	b.opMgr.CancelRunning(ctx)
+	return b.command(ctx, linear.Y, angular.Z)
+}
+
+// Stop stops the simulated robot.
+func (b *simBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	b.opMgr.CancelRunning(ctx)
+	return b.command(ctx, 0, 0)
+}
+
+// IsMoving returns whether the base is in a motion or driving at a velocity.
+func (b *simBase) IsMoving(ctx context.Context) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.driving || b.opMgr.OpRunning(), nil
+}
+
+// Properties returns the configured dimensions of the base.
+func (b *simBase) Properties(ctx context.Context, extra map[string]interface{}) (base.Properties, error) {
+	return base.Properties{
+		WidthMeters:              float64(b.conf.WidthMM) / 1000,
+		WheelCircumferenceMeters: float64(b.conf.WheelCircumferenceMM) / 1000,
+	}, nil
+}
+
+// Geometries returns the geometry of the base's frame.
+func (b *simBase) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return b.geometries, nil
+}
+
+// DoCommand reports whether the base is connected to the simulation.
+func (b *simBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"connected": b.client.Connected()}, nil
+}
+
+// Close stops the simulated robot and disconnects from the simulation.
+func (b *simBase) Close(ctx context.Context) error {
+	b.opMgr.CancelRunning(ctx)
+	var err error
+	b.mu.Lock()
+	driving := b.driving
+	b.mu.Unlock()
+	if driving {
+		err = b.command(ctx, 0, 0)
+	}
+	b.client.Close()
+	return err
+}
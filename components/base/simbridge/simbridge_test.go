@@ -0,0 +1,171 @@
+package simbridge
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	goutils "go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/ros/rosbridge"
+)
+
+// fakeDrive stands in for the simulation's drive controller, driving at the velocity last sent on
+// cmd_vel and publishing odometry, unless it is blocked.
+type fakeDrive struct {
+	mu          sync.Mutex
+	x, y, theta float64
+	blocked     bool
+}
+
+func (fd *fakeDrive) run(ctx context.Context, fs *rosbridge.FakeServer) {
+	const dt = 0.01
+	for goutils.SelectContextOrWait(ctx, 10*time.Millisecond) {
+		fd.mu.Lock()
+		if ops := fs.Ops("publish", defaultCmdVelTopic); len(ops) > 0 && !fd.blocked {
+			var cmd rosbridge.Twist
+			if err := json.Unmarshal(ops[len(ops)-1].Msg, &cmd); err == nil {
+				fd.theta += cmd.Angular.Z * dt
+				fd.x += cmd.Linear.X * dt * math.Cos(fd.theta)
+				fd.y += cmd.Linear.X * dt * math.Sin(fd.theta)
+			}
+		}
+		odom := rosbridge.Odometry{}
+		odom.Pose.Pose.Position = rosbridge.Vector3{X: fd.x, Y: fd.y}
+		odom.Pose.Pose.Orientation = rosbridge.Quaternion{Z: math.Sin(fd.theta / 2), W: math.Cos(fd.theta / 2)}
+		fd.mu.Unlock()
+		//nolint:errcheck
+		fs.Publish(ctx, "/odom", odom)
+	}
+}
+
+func (fd *fakeDrive) pose() (float64, float64, float64) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	return fd.x, fd.y, fd.theta
+}
+
+func newBase(t *testing.T, conf *Config) base.Base {
+	t.Helper()
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	b, err := NewBase(context.Background(), nil, resource.Config{
+		Name:                "base",
+		API:                 base.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() {
+		test.That(t, b.Close(context.Background()), test.ShouldBeNil)
+	})
+	return b
+}
+
+func lastTwist(t *testing.T, fs *rosbridge.FakeServer) rosbridge.Twist {
+	t.Helper()
+	ops := fs.Ops("publish", defaultCmdVelTopic)
+	test.That(t, ops, test.ShouldNotBeEmpty)
+	var twist rosbridge.Twist
+	test.That(t, json.Unmarshal(ops[len(ops)-1].Msg, &twist), test.ShouldBeNil)
+	return twist
+}
+
+func TestValidate(t *testing.T) {
+	_, _, err := (&Config{}).Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "rosbridge_url")
+	_, _, err = (&Config{RosbridgeURL: "ws://localhost:9090", WidthMM: -1}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestTimedMotions(t *testing.T) {
+	ctx := context.Background()
+	fs := rosbridge.NewFakeServer()
+	defer fs.Close()
+	b := newBase(t, &Config{RosbridgeURL: fs.URL(), WidthMM: 400})
+
+	props, err := b.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.WidthMeters, test.ShouldEqual, .4)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, b.SetVelocity(ctx, r3.Vector{Y: 250}, r3.Vector{Z: 90}, nil), test.ShouldBeNil)
+	})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, fs.Ops("publish", defaultCmdVelTopic), test.ShouldHaveLength, 1)
+	})
+	twist := lastTwist(t, fs)
+	test.That(t, twist.Linear.X, test.ShouldEqual, .25)
+	test.That(t, twist.Angular.Z, test.ShouldAlmostEqual, math.Pi/2)
+	moving, err := b.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeTrue)
+
+	// without odometry, motions drive for as long as they should take, then stop.
+	start := time.Now()
+	test.That(t, b.MoveStraight(ctx, -100, 1000, nil), test.ShouldBeNil)
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 100*time.Millisecond)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, lastTwist(t, fs), test.ShouldResemble, rosbridge.Twist{})
+	})
+	var backward rosbridge.Twist
+	test.That(t, json.Unmarshal(fs.Ops("publish", defaultCmdVelTopic)[1].Msg, &backward), test.ShouldBeNil)
+	test.That(t, backward.Linear.X, test.ShouldEqual, -1)
+	moving, err = b.IsMoving(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, moving, test.ShouldBeFalse)
+}
+
+func TestOdometryMotions(t *testing.T) {
+	stallTimeout = 200 * time.Millisecond
+	ctx := context.Background()
+	fs := rosbridge.NewFakeServer()
+	defer fs.Close()
+	b := newBase(t, &Config{RosbridgeURL: fs.URL(), OdometryTopic: "/odom"})
+
+	test.That(t, b.MoveStraight(ctx, 100, 100, nil), test.ShouldNotBeNil)
+
+	fd := &fakeDrive{}
+	simCtx, stopSim := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fd.run(simCtx, fs)
+	}()
+	defer func() {
+		stopSim()
+		wg.Wait()
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, b.Spin(ctx, 90, 360, nil), test.ShouldBeNil)
+	})
+	_, _, theta := fd.pose()
+	test.That(t, theta, test.ShouldAlmostEqual, math.Pi/2, .2)
+
+	// a quarter turn counterclockwise then forward drives along y in ROS, which is left.
+	test.That(t, b.MoveStraight(ctx, 200, 1000, nil), test.ShouldBeNil)
+	x, y, _ := fd.pose()
+	test.That(t, math.Abs(x), test.ShouldBeLessThan, .05)
+	test.That(t, y, test.ShouldAlmostEqual, .2, .03)
+
+	// a robot blocked in the simulation stops the motion.
+	fd.mu.Lock()
+	fd.blocked = true
+	fd.mu.Unlock()
+	err := b.MoveStraight(ctx, 200, 1000, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "stopped short")
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, lastTwist(t, fs), test.ShouldResemble, rosbridge.Twist{})
+	})
+}
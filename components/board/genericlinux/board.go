@@ -1,8 +1,9 @@
 //go:build linux
 
 // Package genericlinux implements a Linux-based board making heavy use of sysfs
-// (https://en.wikipedia.org/wiki/Sysfs). This does not provide a board model itself but provides
-// the underlying logic for any Linux/sysfs based board.
+// (https://en.wikipedia.org/wiki/Sysfs). It provides the underlying logic for any Linux/sysfs based
+// board, and the genericlinux model, whose pin definitions are detected from the devicetree of the
+// machine among those registered by the packages of specific boards.
 package genericlinux
 
 import (
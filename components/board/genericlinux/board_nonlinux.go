@@ -14,6 +14,10 @@ import (
 	"go.viam.com/rdk/resource"
 )
 
+func init() {
+	RegisterBoard(detectModelName, nil)
+}
+
 // RegisterBoard would register a sysfs based board of the given model. However, this one never
 // creates a board, and instead returns errors about making a Linux board on a non-Linux OS.
 func RegisterBoard(modelName string, gpioMappings map[string]GPIOBoardMapping) {
@@ -33,6 +37,8 @@ func RegisterBoard(modelName string, gpioMappings map[string]GPIOBoardMapping) {
 }
 
 // GetGPIOBoardMappings attempts to find a compatible GPIOBoardMapping for the given board.
-func GetGPIOBoardMappings(modelName string, boardInfoMappings map[string]BoardInformation) (map[string]GPIOBoardMapping, error) {
+func GetGPIOBoardMappings(modelName string, boardInfoMappings map[string]BoardInformation, logger logging.Logger) (
+	map[string]GPIOBoardMapping, error,
+) {
 	return nil, errors.New("linux boards are not supported on non-linux OSes")
 }
@@ -8,9 +8,12 @@ package genericlinux
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"go.viam.com/test"
+	"go.viam.com/utils"
 
 	"go.viam.com/rdk/components/board"
 	"go.viam.com/rdk/components/board/mcp3008helper"
@@ -136,3 +139,64 @@ func TestNewBoard(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestDetectPinDefs(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	pinDefs := []PinDefinition{{Name: "3", DeviceName: "gpiochip0", LineNumber: 2, PwmID: -1}}
+	RegisterBoardInformation("test-board", BoardInformation{
+		PinDefinitions: pinDefs,
+		Compats:        []string{"test,board", "test,soc"},
+	})
+
+	t.Run("boards are selected by devicetree compatible strings", func(t *testing.T) {
+		infos := map[string]BoardInformation{
+			"a": {PinDefinitions: []PinDefinition{{Name: "a"}}, Compats: []string{"test,soc"}},
+			"b": {PinDefinitions: []PinDefinition{{Name: "b"}}, Compats: []string{"test,board"}},
+		}
+		found, err := compatiblePinDefs("test", utils.NewStringSet("test,board", "test,soc"), infos)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, found, test.ShouldResemble, []PinDefinition{{Name: "a"}})
+
+		_, err = compatiblePinDefs("test", utils.NewStringSet("other,board"), infos)
+		test.That(t, err, test.ShouldResemble, NoBoardFoundError{modelName: "test"})
+	})
+
+	t.Run("board_name selects registered pin definitions", func(t *testing.T) {
+		conf := resource.Config{Name: "board1", ConvertedAttributes: &DetectConfig{BoardName: "test-board"}}
+		linuxConf, err := DetectPinDefs(conf, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, linuxConf.GpioMappings, test.ShouldResemble, map[string]GPIOBoardMapping{
+			"3": {GPIOChipDev: "gpiochip0", GPIO: 2, GPIOName: "3", PWMID: -1},
+		})
+
+		conf.ConvertedAttributes = &DetectConfig{BoardName: "missing"}
+		_, err = DetectPinDefs(conf, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "missing")
+	})
+
+	t.Run("board_defs_file_path overrides registered pin definitions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pins.json")
+		err := os.WriteFile(path, []byte(`{"pins": [{"name": "7", "device_name": "gpiochip1", "line_number": 4}]}`), 0o600)
+		test.That(t, err, test.ShouldBeNil)
+		conf := resource.Config{Name: "board1", ConvertedAttributes: &DetectConfig{BoardDefsFilePath: path}}
+		linuxConf, err := DetectPinDefs(conf, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, linuxConf.GpioMappings, test.ShouldResemble, map[string]GPIOBoardMapping{
+			"7": {GPIOChipDev: "gpiochip1", GPIO: 4, GPIOName: "7", PWMID: -1},
+		})
+
+		err = os.WriteFile(path, []byte(`{"pins": [{"name": "7"}]}`), 0o600)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = DetectPinDefs(conf, logger)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "device_name")
+	})
+
+	t.Run("only one override may be configured", func(t *testing.T) {
+		_, _, err := (&DetectConfig{BoardName: "test-board", BoardDefsFilePath: "pins.json"}).Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		_, _, err = (&DetectConfig{BoardName: "test-board"}).Validate("path")
+		test.That(t, err, test.ShouldBeNil)
+	})
+}
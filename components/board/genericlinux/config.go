@@ -1,6 +1,7 @@
 package genericlinux
 
 import (
+	"errors"
 	"fmt"
 
 	"go.viam.com/rdk/components/board"
@@ -30,6 +31,32 @@ func (conf *Config) Validate(path string) ([]string, []string, error) {
 	return nil, nil, nil
 }
 
+// detectModelName is the model of boards whose pin definitions are detected from the devicetree
+// of the machine, among the boards registered with RegisterBoardInformation.
+const detectModelName = "genericlinux"
+
+// A DetectConfig describes the configuration of a board of the genericlinux model, whose pin
+// definitions are detected from the devicetree of the machine unless they are chosen in the config.
+type DetectConfig struct {
+	AnalogReaders     []mcp3008helper.MCP3008AnalogConfig `json:"analogs,omitempty"`
+	DigitalInterrupts []board.DigitalInterruptConfig      `json:"digital_interrupts,omitempty"`
+	// BoardName, if set, selects the pin definitions registered under this name instead of
+	// detecting them.
+	BoardName string `json:"board_name,omitempty"`
+	// BoardDefsFilePath, if set, is the path to a JSON file of pin definitions to use instead of
+	// any that are registered.
+	BoardDefsFilePath string `json:"board_defs_file_path,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *DetectConfig) Validate(path string) ([]string, []string, error) {
+	if conf.BoardName != "" && conf.BoardDefsFilePath != "" {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("only one of board_name and board_defs_file_path may be set"))
+	}
+	return (&Config{AnalogReaders: conf.AnalogReaders, DigitalInterrupts: conf.DigitalInterrupts}).Validate(path)
+}
+
 // LinuxBoardConfig is a struct containing absolutely everything a genericlinux board might need
 // configured. It is a union of the configs for the customlinux boards and the genericlinux boards
 // with static pin definitions, because those components all use the same underlying code but have
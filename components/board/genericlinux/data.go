@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
+	"go.viam.com/utils"
+
 	"go.viam.com/rdk/logging"
 	rdkutils "go.viam.com/rdk/utils"
 )
@@ -16,7 +19,7 @@ import (
 // adapted from https://github.com/NVIDIA/jetson-gpio (MIT License)
 
 func noBoardError(modelName string) error {
-	return fmt.Errorf("could not determine %q model", modelName)
+	return NoBoardFoundError{modelName: modelName}
 }
 
 // pwmChipData is a struct used solely within GetGPIOBoardMappings and its sub-pieces. It
@@ -26,10 +29,15 @@ type pwmChipData struct {
 	Npwm int    // Taken from the /npwm pseudofile in sysfs: number of lines on the chip
 }
 
-// GetGPIOBoardMappings attempts to find a compatible GPIOBoardMapping for the given board.
+// GetGPIOBoardMappings attempts to find a compatible GPIOBoardMapping for the given board. The
+// board is detected from the devicetree compatible strings of the machine, among
+// boardInfoMappings or, if that is nil, among the boards registered with RegisterBoardInformation.
 func GetGPIOBoardMappings(modelName string, boardInfoMappings map[string]BoardInformation, logger logging.Logger) (
 	map[string]GPIOBoardMapping, error,
 ) {
+	if boardInfoMappings == nil {
+		boardInfoMappings = RegisteredBoardInformation()
+	}
 	pinDefs, err := getCompatiblePinDefs(modelName, boardInfoMappings)
 	if err != nil {
 		return nil, err
@@ -52,27 +60,35 @@ func GetGPIOBoardMappingFromPinDefs(pinDefs []PinDefinition, logger logging.Logg
 }
 
 // getCompatiblePinDefs returns a list of pin definitions, from the first BoardInformation struct
-// that appears compatible with the machine we're running on.
+// that appears compatible with the machine we're running on. Boards are considered in order of
+// their names, so that the same board is chosen every time.
 func getCompatiblePinDefs(modelName string, boardInfoMappings map[string]BoardInformation) ([]PinDefinition, error) {
 	compatibles, err := rdkutils.GetDeviceInfo(modelName)
 	if err != nil {
 		return nil, fmt.Errorf("error while getting hardware info %w", err)
 	}
+	return compatiblePinDefs(modelName, compatibles, boardInfoMappings)
+}
 
-	var pinDefs []PinDefinition
-	for _, info := range boardInfoMappings {
+// compatiblePinDefs returns the pin definitions of the first board in boardInfoMappings with one
+// of the given devicetree compatible strings.
+func compatiblePinDefs(
+	modelName string, compatibles utils.StringSet, boardInfoMappings map[string]BoardInformation,
+) ([]PinDefinition, error) {
+	names := make([]string, 0, len(boardInfoMappings))
+	for name := range boardInfoMappings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		info := boardInfoMappings[name]
 		for _, v := range info.Compats {
 			if _, ok := compatibles[v]; ok {
-				pinDefs = info.PinDefinitions
-				break
+				return info.PinDefinitions, nil
 			}
 		}
 	}
-
-	if pinDefs == nil {
-		return nil, noBoardError(modelName)
-	}
-	return pinDefs, nil
+	return nil, noBoardError(modelName)
 }
 
 // A helper function: we read the contents of filePath and return its integer value.
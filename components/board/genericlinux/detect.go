@@ -0,0 +1,86 @@
+//go:build linux
+
+package genericlinux
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func init() {
+	resource.RegisterComponent(
+		board.API,
+		resource.DefaultModelFamily.WithModel(detectModelName),
+		resource.Registration[board.Board, *DetectConfig]{
+			Constructor: func(
+				ctx context.Context,
+				_ resource.Dependencies,
+				conf resource.Config,
+				logger logging.Logger,
+			) (board.Board, error) {
+				return NewBoard(ctx, conf, DetectPinDefs, logger)
+			},
+		})
+}
+
+// DetectPinDefs is a ConfigConverter for boards configured with a DetectConfig. It uses the pin
+// definitions of the config's board definitions file or named board if either is set, and
+// otherwise those of the registered board compatible with the machine.
+func DetectPinDefs(conf resource.Config, logger logging.Logger) (*LinuxBoardConfig, error) {
+	newConf, err := resource.NativeConfig[*DetectConfig](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var pinDefs []PinDefinition
+	switch {
+	case newConf.BoardDefsFilePath != "":
+		pinDefs, err = readPinDefsFile(newConf.BoardDefsFilePath)
+	case newConf.BoardName != "":
+		info, ok := RegisteredBoardInformation()[newConf.BoardName]
+		if !ok {
+			return nil, fmt.Errorf("no pin definitions are registered for board %q", newConf.BoardName)
+		}
+		pinDefs = info.PinDefinitions
+	default:
+		pinDefs, err = getCompatiblePinDefs(detectModelName, RegisteredBoardInformation())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gpioMappings, err := GetGPIOBoardMappingFromPinDefs(pinDefs, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &LinuxBoardConfig{
+		AnalogReaders:     newConf.AnalogReaders,
+		DigitalInterrupts: newConf.DigitalInterrupts,
+		GpioMappings:      gpioMappings,
+	}, nil
+}
+
+// readPinDefsFile reads and validates the pin definitions in a JSON file.
+func readPinDefsFile(path string) ([]PinDefinition, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pinDefs PinDefinitions
+	if err := json.Unmarshal(data, &pinDefs); err != nil {
+		return nil, fmt.Errorf("reading pin definitions from %q: %w", path, err)
+	}
+	for i, pinDef := range pinDefs.Pins {
+		if err := pinDef.Validate(fmt.Sprintf("%s.pins.%d", path, i)); err != nil {
+			return nil, err
+		}
+	}
+	return pinDefs.Pins, nil
+}
@@ -3,6 +3,7 @@ package genericlinux
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"github.com/pkg/errors"
 
@@ -78,6 +79,32 @@ type BoardInformation struct {
 	Compats        []string
 }
 
+var (
+	boardInfoMu         sync.Mutex
+	registeredBoardInfo = map[string]BoardInformation{}
+)
+
+// RegisterBoardInformation registers the pin definitions of a board under name, so that boards of
+// the detecting model can find them from the devicetree compatible strings of the machine they run
+// on, or select them by name.
+func RegisterBoardInformation(name string, info BoardInformation) {
+	boardInfoMu.Lock()
+	defer boardInfoMu.Unlock()
+	registeredBoardInfo[name] = info
+}
+
+// RegisteredBoardInformation returns the board information registered with
+// RegisterBoardInformation, by name.
+func RegisteredBoardInformation() map[string]BoardInformation {
+	boardInfoMu.Lock()
+	defer boardInfoMu.Unlock()
+	infos := make(map[string]BoardInformation, len(registeredBoardInfo))
+	for name, info := range registeredBoardInfo {
+		infos[name] = info
+	}
+	return infos
+}
+
 // A NoBoardFoundError is returned when no compatible mapping is found for a board during GPIO board mapping.
 type NoBoardFoundError struct {
 	modelName string
@@ -0,0 +1,554 @@
+// Package mcuproxy implements a board that proxies its GPIO, PWM, analog, digital interrupt, and
+// I2C calls over serial or TCP to an attached microcontroller, such as an ESP32 or an Arduino, so
+// that hosts without usable GPIO pins of their own can still control hardware.
+//
+// The microcontroller runs a small firmware shim that speaks a line-based text protocol. Each
+// request is a line of space-separated fields: an ID chosen by the board, a command, and its
+// arguments. The shim answers each request with a line starting with the same ID followed by "ok"
+// and the results of the command, or by "err" and a message:
+//
+//	7 gpio_get 13
+//	7 ok 1
+//
+// Pins and buses are named however the shim names them. The commands and their results are:
+//
+//	gpio_set <pin> <0|1>
+//	gpio_get <pin>                            -> <0|1>
+//	pwm_get <pin>                             -> <duty cycle from 0 to 1>
+//	pwm_set <pin> <duty cycle from 0 to 1>
+//	pwm_freq_get <pin>                        -> <frequency in Hz>
+//	pwm_freq_set <pin> <frequency in Hz>
+//	analog_read <pin>                         -> <value> <min> <max> <step size>
+//	analog_write <pin> <value>
+//	interrupt_start <pin>
+//	i2c_tx <bus> <address> <bytes to write> <number of bytes to read>  -> <bytes read>
+//
+// Bytes are hex encoded, or "-" if there are none. Once interrupt_start has been sent for a pin,
+// the shim sends "tick <pin> <0|1> <timestamp in microseconds>" without being asked whenever the
+// pin changes. Lines that are neither answers nor ticks, such as boot messages, are ignored.
+package mcuproxy
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	pb "go.viam.com/api/component/board/v1"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/genericlinux/buses"
+	"go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+const defaultBaudRate = 115200
+
+var model = resource.DefaultModelFamily.WithModel("mcu-proxy")
+
+// I2CConfig describes an I2C bus of the microcontroller.
+type I2CConfig struct {
+	Name string `json:"name"`
+	Bus  string `json:"bus"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *I2CConfig) Validate(path string) error {
+	if conf.Name == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	if conf.Bus == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "bus")
+	}
+	return nil
+}
+
+// A Config describes how to reach the microcontroller and the peripherals attached to it.
+type Config struct {
+	// Address is the host and port of a shim reachable over TCP, such as an ESP32 on WiFi.
+	Address string `json:"address,omitempty"`
+	// SerialPath is the path of the serial device of a shim attached over serial, such as
+	// /dev/ttyUSB0.
+	SerialPath string `json:"serial_path,omitempty"`
+	// SerialBaudRate defaults to 115200 if unspecified.
+	SerialBaudRate    int                            `json:"serial_baud_rate,omitempty"`
+	AnalogReaders     []board.AnalogReaderConfig     `json:"analogs,omitempty"`
+	DigitalInterrupts []board.DigitalInterruptConfig `json:"digital_interrupts,omitempty"`
+	I2Cs              []I2CConfig                    `json:"i2cs,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if (conf.Address == "") == (conf.SerialPath == "") {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("exactly one of address and serial_path must be set"))
+	}
+	for idx, c := range conf.AnalogReaders {
+		if err := c.Validate(fmt.Sprintf("%s.%s.%d", path, "analogs", idx)); err != nil {
+			return nil, nil, err
+		}
+	}
+	for idx, c := range conf.DigitalInterrupts {
+		if err := c.Validate(fmt.Sprintf("%s.%s.%d", path, "digital_interrupts", idx)); err != nil {
+			return nil, nil, err
+		}
+	}
+	for idx, c := range conf.I2Cs {
+		if err := c.Validate(fmt.Sprintf("%s.%s.%d", path, "i2cs", idx)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, nil
+}
+
+func (conf *Config) baudRate() int {
+	if conf.SerialBaudRate == 0 {
+		return defaultBaudRate
+	}
+	return conf.SerialBaudRate
+}
+
+func init() {
+	resource.RegisterComponent(
+		board.API,
+		model,
+		resource.Registration[board.Board, *Config]{
+			Constructor: func(
+				ctx context.Context,
+				_ resource.Dependencies,
+				conf resource.Config,
+				logger logging.Logger,
+			) (board.Board, error) {
+				return NewBoard(ctx, conf, logger)
+			},
+		})
+}
+
+// Board is a board whose peripherals are those of a microcontroller running the firmware shim.
+type Board struct {
+	resource.Named
+
+	mu         sync.Mutex
+	conf       *Config
+	conn       *shimConn
+	analogs    map[string]*analog
+	interrupts map[string]*digitalInterrupt
+	i2cs       map[string]*i2cBus
+	logger     logging.Logger
+
+	// interruptsByPin is guarded by its own mutex so that ticks can be dispatched while the board
+	// waits for the microcontroller.
+	interruptsMu    sync.Mutex
+	interruptsByPin map[string]*digitalInterrupt
+
+	workers *utils.StoppableWorkers
+}
+
+// NewBoard connects to the microcontroller and returns a board for its peripherals.
+func NewBoard(ctx context.Context, conf resource.Config, logger logging.Logger) (*Board, error) {
+	b := &Board{
+		Named:      conf.ResourceName().AsNamed(),
+		analogs:    map[string]*analog{},
+		interrupts: map[string]*digitalInterrupt{},
+		i2cs:       map[string]*i2cBus{},
+		logger:     logger,
+		workers:    utils.NewBackgroundStoppableWorkers(),
+	}
+	if err := b.Reconfigure(ctx, nil, conf); err != nil {
+		return nil, multierr.Combine(err, b.Close(ctx))
+	}
+	return b, nil
+}
+
+// Reconfigure reconnects to the microcontroller if how to reach it changed, and reconfigures its
+// peripherals.
+func (b *Board) Reconfigure(ctx context.Context, _ resource.Dependencies, conf resource.Config) error {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.conn == nil || b.conf.Address != newConf.Address || b.conf.SerialPath != newConf.SerialPath ||
+		b.conf.baudRate() != newConf.baudRate() {
+		if b.conn != nil {
+			if err := b.conn.Close(); err != nil {
+				b.logger.CDebugw(ctx, "error closing connection to the microcontroller", "error", err)
+			}
+			b.conn = nil
+		}
+		rwc, err := dial(ctx, newConf)
+		if err != nil {
+			return err
+		}
+		b.conn = newShimConn(rwc, b.tick, b.logger)
+	}
+	b.conf = newConf
+
+	b.analogs = map[string]*analog{}
+	for _, c := range newConf.AnalogReaders {
+		b.analogs[c.Name] = &analog{conn: b.conn, pin: c.Pin}
+	}
+	b.i2cs = map[string]*i2cBus{}
+	for _, c := range newConf.I2Cs {
+		b.i2cs[c.Name] = &i2cBus{conn: b.conn, bus: c.Bus}
+	}
+
+	// interrupts keep the channels ticks are streamed to across reconfiguration.
+	interrupts := map[string]*digitalInterrupt{}
+	interruptsByPin := map[string]*digitalInterrupt{}
+	for _, c := range newConf.DigitalInterrupts {
+		interrupt, ok := b.interrupts[c.Name]
+		if !ok {
+			interrupt = &digitalInterrupt{name: c.Name}
+		}
+		interrupts[c.Name] = interrupt
+		interruptsByPin[c.Pin] = interrupt
+	}
+	b.interrupts = interrupts
+	b.interruptsMu.Lock()
+	b.interruptsByPin = interruptsByPin
+	b.interruptsMu.Unlock()
+
+	// reporting is started again, since the microcontroller may have been reset or replaced.
+	for _, c := range newConf.DigitalInterrupts {
+		if _, err := b.conn.call(ctx, "interrupt_start", c.Pin); err != nil {
+			return errors.Wrapf(err, "starting digital interrupt %q", c.Name)
+		}
+	}
+	return nil
+}
+
+// dial connects to the shim as conf describes.
+func dial(ctx context.Context, conf *Config) (io.ReadWriteCloser, error) {
+	if conf.Address != "" {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", conf.Address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "connecting to the microcontroller at %s", conf.Address)
+		}
+		return conn, nil
+	}
+	rwc, err := openSerial(conf.SerialPath, conf.baudRate())
+	if err != nil {
+		return nil, errors.Wrapf(err, "connecting to the microcontroller at %s", conf.SerialPath)
+	}
+	return rwc, nil
+}
+
+// tick dispatches a tick the shim sent to the interrupt of its pin.
+func (b *Board) tick(ctx context.Context, pin string, high bool, timestampMicros uint64) {
+	b.interruptsMu.Lock()
+	interrupt, ok := b.interruptsByPin[pin]
+	b.interruptsMu.Unlock()
+	if ok {
+		interrupt.tick(ctx, high, timestampMicros*uint64(time.Microsecond))
+	}
+}
+
+// AnalogByName returns the analog pin by the given name if it exists.
+func (b *Board) AnalogByName(name string) (board.Analog, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	a, ok := b.analogs[name]
+	if !ok {
+		return nil, errors.Errorf("can't find AnalogReader (%s)", name)
+	}
+	return a, nil
+}
+
+// DigitalInterruptByName returns the interrupt by the given name if it exists.
+func (b *Board) DigitalInterruptByName(name string) (board.DigitalInterrupt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	interrupt, ok := b.interrupts[name]
+	if !ok {
+		return nil, errors.Errorf("can't find DigitalInterrupt (%s)", name)
+	}
+	return interrupt, nil
+}
+
+// GPIOPinByName returns the GPIO pin the shim names name.
+func (b *Board) GPIOPinByName(name string) (board.GPIOPin, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &gpioPin{conn: b.conn, pin: name}, nil
+}
+
+// I2CByName returns the I2C bus by the given name if it exists.
+func (b *Board) I2CByName(name string) (buses.I2C, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bus, ok := b.i2cs[name]
+	return bus, ok
+}
+
+// SetPowerMode sets the board to the given power mode. If provided,
+// the board will exit the given power mode after the specified
+// duration.
+func (b *Board) SetPowerMode(ctx context.Context, mode pb.PowerMode, duration *time.Duration, extra map[string]interface{}) error {
+	return grpc.UnimplementedError
+}
+
+// StreamTicks starts a stream of digital interrupt ticks.
+func (b *Board) StreamTicks(ctx context.Context, interrupts []board.DigitalInterrupt, ch chan board.Tick,
+	extra map[string]interface{},
+) error {
+	var rawInterrupts []*digitalInterrupt
+	for _, i := range interrupts {
+		raw, ok := i.(*digitalInterrupt)
+		if !ok {
+			return errors.New("cannot stream ticks to an interrupt not associated with this board")
+		}
+		rawInterrupts = append(rawInterrupts, raw)
+	}
+
+	for _, i := range rawInterrupts {
+		i.addChannel(ch)
+	}
+
+	b.workers.Add(func(cancelCtx context.Context) {
+		// Wait until it's time to shut down then remove the channels.
+		select {
+		case <-ctx.Done():
+		case <-cancelCtx.Done():
+		}
+		for _, i := range rawInterrupts {
+			i.removeChannel(ch)
+		}
+	})
+	return nil
+}
+
+// Close closes the connection to the microcontroller.
+func (b *Board) Close(ctx context.Context) error {
+	b.workers.Stop()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == nil {
+		return nil
+	}
+	return b.conn.Close()
+}
+
+type gpioPin struct {
+	conn *shimConn
+	pin  string
+}
+
+// Set sets the pin to either low or high.
+func (pin *gpioPin) Set(ctx context.Context, high bool, extra map[string]interface{}) error {
+	value := "0"
+	if high {
+		value = "1"
+	}
+	_, err := pin.conn.call(ctx, "gpio_set", pin.pin, value)
+	return err
+}
+
+// Get gets the high/low state of the pin.
+func (pin *gpioPin) Get(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	results, err := pin.conn.call(ctx, "gpio_get", pin.pin)
+	if err != nil {
+		return false, err
+	}
+	var high bool
+	return high, parseResults("gpio_get", results, &high)
+}
+
+// PWM gets the pin's given duty cycle.
+func (pin *gpioPin) PWM(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	results, err := pin.conn.call(ctx, "pwm_get", pin.pin)
+	if err != nil {
+		return 0, err
+	}
+	var dutyCyclePct float64
+	return dutyCyclePct, parseResults("pwm_get", results, &dutyCyclePct)
+}
+
+// SetPWM sets the pin to the given duty cycle.
+func (pin *gpioPin) SetPWM(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+	dutyCyclePct, err := board.ValidatePWMDutyCycle(dutyCyclePct)
+	if err != nil {
+		return err
+	}
+	_, err = pin.conn.call(ctx, "pwm_set", pin.pin, strconv.FormatFloat(dutyCyclePct, 'f', -1, 64))
+	return err
+}
+
+// PWMFreq gets the PWM frequency of the pin.
+func (pin *gpioPin) PWMFreq(ctx context.Context, extra map[string]interface{}) (uint, error) {
+	results, err := pin.conn.call(ctx, "pwm_freq_get", pin.pin)
+	if err != nil {
+		return 0, err
+	}
+	var freqHz uint
+	return freqHz, parseResults("pwm_freq_get", results, &freqHz)
+}
+
+// SetPWMFreq sets the given pin to the given PWM frequency.
+func (pin *gpioPin) SetPWMFreq(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+	_, err := pin.conn.call(ctx, "pwm_freq_set", pin.pin, strconv.FormatUint(uint64(freqHz), 10))
+	return err
+}
+
+type analog struct {
+	conn *shimConn
+	pin  string
+}
+
+// Read reads off the current value.
+func (a *analog) Read(ctx context.Context, extra map[string]interface{}) (board.AnalogValue, error) {
+	results, err := a.conn.call(ctx, "analog_read", a.pin)
+	if err != nil {
+		return board.AnalogValue{}, err
+	}
+	var value board.AnalogValue
+	err = parseResults("analog_read", results, &value.Value, &value.Min, &value.Max, &value.StepSize)
+	return value, err
+}
+
+// Write writes a value to the analog pin.
+func (a *analog) Write(ctx context.Context, value int, extra map[string]interface{}) error {
+	_, err := a.conn.call(ctx, "analog_write", a.pin, strconv.Itoa(value))
+	return err
+}
+
+type digitalInterrupt struct {
+	name string
+
+	mu       sync.Mutex
+	count    int64
+	channels []chan board.Tick
+}
+
+// Name returns the name of the interrupt.
+func (di *digitalInterrupt) Name() string {
+	return di.name
+}
+
+// Value returns the number of rising edges of the pin.
+func (di *digitalInterrupt) Value(ctx context.Context, extra map[string]interface{}) (int64, error) {
+	di.mu.Lock()
+	defer di.mu.Unlock()
+	return di.count, nil
+}
+
+func (di *digitalInterrupt) tick(ctx context.Context, high bool, timestampNanos uint64) {
+	di.mu.Lock()
+	defer di.mu.Unlock()
+	if high {
+		di.count++
+	}
+	tick := board.Tick{Name: di.name, High: high, TimestampNanosec: timestampNanos}
+	for _, ch := range di.channels {
+		select {
+		case <-ctx.Done():
+			return
+		case ch <- tick:
+		}
+	}
+}
+
+func (di *digitalInterrupt) addChannel(ch chan board.Tick) {
+	di.mu.Lock()
+	defer di.mu.Unlock()
+	di.channels = append(di.channels, ch)
+}
+
+func (di *digitalInterrupt) removeChannel(ch chan board.Tick) {
+	di.mu.Lock()
+	defer di.mu.Unlock()
+	for i, oldCh := range di.channels {
+		if oldCh == ch {
+			di.channels = append(di.channels[:i], di.channels[i+1:]...)
+			return
+		}
+	}
+}
+
+// i2cBus is an I2C bus of the microcontroller. Opening a handle locks the bus until the handle is
+// closed.
+type i2cBus struct {
+	conn *shimConn
+	bus  string
+	mu   sync.Mutex
+}
+
+// OpenHandle locks the bus and returns a handle for the device at addr.
+func (bus *i2cBus) OpenHandle(addr byte) (buses.I2CHandle, error) {
+	bus.mu.Lock()
+	return &i2cHandle{bus: bus, addr: addr}, nil
+}
+
+type i2cHandle struct {
+	bus  *i2cBus
+	addr byte
+}
+
+// tx writes write to the device, then reads readCount bytes from it.
+func (h *i2cHandle) tx(ctx context.Context, write []byte, readCount int) ([]byte, error) {
+	results, err := h.bus.conn.call(ctx, "i2c_tx", h.bus.bus, strconv.Itoa(int(h.addr)), encodeBytes(write), strconv.Itoa(readCount))
+	if err != nil {
+		return nil, err
+	}
+	var read []byte
+	if err := parseResults("i2c_tx", results, &read); err != nil {
+		return nil, err
+	}
+	if len(read) != readCount {
+		return nil, fmt.Errorf("expected to read %d bytes from I2C device %d but read %d", readCount, h.addr, len(read))
+	}
+	return read, nil
+}
+
+// Write writes the given bytes to the handle.
+func (h *i2cHandle) Write(ctx context.Context, tx []byte) error {
+	_, err := h.tx(ctx, tx, 0)
+	return err
+}
+
+// Read reads the given number of bytes from the handle.
+func (h *i2cHandle) Read(ctx context.Context, count int) ([]byte, error) {
+	return h.tx(ctx, nil, count)
+}
+
+// ReadByteData reads a byte from the given register.
+func (h *i2cHandle) ReadByteData(ctx context.Context, register byte) (byte, error) {
+	data, err := h.tx(ctx, []byte{register}, 1)
+	if err != nil {
+		return 0, err
+	}
+	return data[0], nil
+}
+
+// WriteByteData writes a byte to the given register.
+func (h *i2cHandle) WriteByteData(ctx context.Context, register, data byte) error {
+	_, err := h.tx(ctx, []byte{register, data}, 0)
+	return err
+}
+
+// ReadBlockData reads the given number of bytes from the given register.
+func (h *i2cHandle) ReadBlockData(ctx context.Context, register byte, numBytes uint8) ([]byte, error) {
+	return h.tx(ctx, []byte{register}, int(numBytes))
+}
+
+// WriteBlockData writes the given bytes to the given register.
+func (h *i2cHandle) WriteBlockData(ctx context.Context, register byte, data []byte) error {
+	_, err := h.tx(ctx, append([]byte{register}, data...), 0)
+	return err
+}
+
+// Close releases the lock on the bus.
+func (h *i2cHandle) Close() error {
+	h.bus.mu.Unlock()
+	return nil
+}
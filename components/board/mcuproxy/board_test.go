@@ -0,0 +1,221 @@
+package mcuproxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// fakeShim is a firmware shim with pins that keep the values they are set to.
+type fakeShim struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	pins     map[string]string
+	requests []string
+	conn     net.Conn
+}
+
+func newFakeShim(t *testing.T) *fakeShim {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	shim := &fakeShim{listener: listener, pins: map[string]string{}}
+	go shim.serve()
+	t.Cleanup(func() { test.That(t, listener.Close(), test.ShouldBeNil) })
+	return shim
+}
+
+func (s *fakeShim) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+	fmt.Fprintln(conn, "shim booted")
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		s.mu.Lock()
+		s.requests = append(s.requests, strings.Join(fields[1:], " "))
+		reply := s.handle(fields[1], fields[2:])
+		s.mu.Unlock()
+		fmt.Fprintf(conn, "%s %s\n", fields[0], reply)
+	}
+}
+
+func (s *fakeShim) handle(command string, args []string) string {
+	switch command {
+	case "gpio_set", "pwm_set", "pwm_freq_set", "analog_write":
+		s.pins[command+args[0]] = args[1]
+		return "ok"
+	case "gpio_get", "pwm_get", "pwm_freq_get":
+		return "ok " + s.pins[strings.TrimSuffix(command, "_get")+"_set"+args[0]]
+	case "analog_read":
+		return "ok 512 0 3.3 0.0032"
+	case "interrupt_start":
+		return "ok"
+	case "i2c_tx":
+		count, err := strconv.Atoi(args[3])
+		if err != nil {
+			return "err invalid count"
+		}
+		return "ok " + encodeBytes(bytes.Repeat([]byte{0xab}, count))
+	default:
+		return "err unknown command " + command
+	}
+}
+
+func (s *fakeShim) send(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintln(s.conn, line)
+}
+
+func TestBoard(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	shim := newFakeShim(t)
+
+	conf := resource.Config{
+		Name: "mcu",
+		ConvertedAttributes: &Config{
+			Address:           shim.listener.Addr().String(),
+			AnalogReaders:     []board.AnalogReaderConfig{{Name: "a1", Pin: "A0"}},
+			DigitalInterrupts: []board.DigitalInterruptConfig{{Name: "encoder", Pin: "4"}},
+			I2Cs:              []I2CConfig{{Name: "i2c0", Bus: "0"}},
+		},
+	}
+	b, err := NewBoard(ctx, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, b.Close(ctx), test.ShouldBeNil)
+	}()
+
+	t.Run("gpio and pwm", func(t *testing.T) {
+		pin, err := b.GPIOPinByName("13")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pin.Set(ctx, true, nil), test.ShouldBeNil)
+		high, err := pin.Get(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, high, test.ShouldBeTrue)
+
+		test.That(t, pin.SetPWM(ctx, 0.25, nil), test.ShouldBeNil)
+		duty, err := pin.PWM(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, duty, test.ShouldEqual, 0.25)
+		test.That(t, pin.SetPWM(ctx, 2, nil), test.ShouldNotBeNil)
+
+		test.That(t, pin.SetPWMFreq(ctx, 1000, nil), test.ShouldBeNil)
+		freq, err := pin.PWMFreq(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, freq, test.ShouldEqual, 1000)
+	})
+
+	t.Run("analogs", func(t *testing.T) {
+		a, err := b.AnalogByName("a1")
+		test.That(t, err, test.ShouldBeNil)
+		value, err := a.Read(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, value, test.ShouldResemble, board.AnalogValue{Value: 512, Min: 0, Max: 3.3, StepSize: 0.0032})
+		test.That(t, a.Write(ctx, 7, nil), test.ShouldBeNil)
+
+		_, err = b.AnalogByName("missing")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("i2c", func(t *testing.T) {
+		bus, ok := b.I2CByName("i2c0")
+		test.That(t, ok, test.ShouldBeTrue)
+		handle, err := bus.OpenHandle(0x40)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, handle.WriteByteData(ctx, 0x01, 0xff), test.ShouldBeNil)
+		data, err := handle.ReadBlockData(ctx, 0x02, 2)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, data, test.ShouldResemble, []byte{0xab, 0xab})
+		test.That(t, handle.Close(), test.ShouldBeNil)
+	})
+
+	t.Run("errors of the shim", func(t *testing.T) {
+		conn := b.conn
+		_, err := conn.call(ctx, "reboot")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "unknown command reboot")
+	})
+
+	t.Run("digital interrupts", func(t *testing.T) {
+		interrupt, err := b.DigitalInterruptByName("encoder")
+		test.That(t, err, test.ShouldBeNil)
+		ticks := make(chan board.Tick)
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		test.That(t, b.StreamTicks(streamCtx, []board.DigitalInterrupt{interrupt}, ticks, nil), test.ShouldBeNil)
+
+		shim.send("tick 4 1 1500")
+		shim.send("tick 5 1 1600")
+		shim.send("tick 4 0 1700")
+		test.That(t, <-ticks, test.ShouldResemble, board.Tick{Name: "encoder", High: true, TimestampNanosec: 1500000})
+		test.That(t, <-ticks, test.ShouldResemble, board.Tick{Name: "encoder", High: false, TimestampNanosec: 1700000})
+		count, err := interrupt.Value(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, count, test.ShouldEqual, 1)
+	})
+
+	shim.mu.Lock()
+	test.That(t, shim.requests[0], test.ShouldEqual, "interrupt_start 4")
+	test.That(t, shim.requests, test.ShouldContain, "i2c_tx 0 64 01ff 0")
+	test.That(t, shim.requests, test.ShouldContain, "i2c_tx 0 64 02 2")
+	shim.mu.Unlock()
+}
+
+func TestBoardTimeouts(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	defer listener.Close()
+	go func() {
+		// the shim accepts the connection but never answers.
+		conn, err := listener.Accept()
+		if err == nil {
+			time.Sleep(2 * responseTimeout)
+			conn.Close()
+		}
+	}()
+
+	conf := resource.Config{Name: "mcu", ConvertedAttributes: &Config{Address: listener.Addr().String()}}
+	b, err := NewBoard(context.Background(), conf, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer b.Close(context.Background())
+
+	pin, err := b.GPIOPinByName("2")
+	test.That(t, err, test.ShouldBeNil)
+	_, err = pin.Get(context.Background(), nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "waiting for the microcontroller")
+}
+
+func TestConfigValidate(t *testing.T) {
+	_, _, err := (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{Address: "mcu.local:4000", SerialPath: "/dev/ttyUSB0"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{SerialPath: "/dev/ttyUSB0", I2Cs: []I2CConfig{{Name: "i2c0"}}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "bus")
+	_, _, err = (&Config{SerialPath: "/dev/ttyUSB0", I2Cs: []I2CConfig{{Name: "i2c0", Bus: "0"}}}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
@@ -0,0 +1,230 @@
+package mcuproxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+)
+
+// responseTimeout is how long the shim has to answer a request. Microcontrollers answer quickly, so
+// a request that takes longer was most likely lost, such as when the microcontroller reset.
+const responseTimeout = time.Second
+
+// errConnClosed is the error of requests made after the connection to the shim is closed.
+var errConnClosed = errors.New("connection to the microcontroller is closed")
+
+// response is the answer of the shim to a request: its results, or the error it reported.
+type response struct {
+	results []string
+	err     error
+}
+
+// shimConn makes requests to the firmware shim over a connection to it and dispatches the ticks
+// the shim sends of its own accord.
+type shimConn struct {
+	rwc    io.ReadWriteCloser
+	onTick func(ctx context.Context, pin string, high bool, timestampMicros uint64)
+	logger logging.Logger
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending map[uint64]chan response
+	err     error
+
+	workers *utils.StoppableWorkers
+}
+
+// newShimConn starts reading from rwc, calling onTick for each tick the shim sends.
+func newShimConn(
+	rwc io.ReadWriteCloser,
+	onTick func(ctx context.Context, pin string, high bool, timestampMicros uint64),
+	logger logging.Logger,
+) *shimConn {
+	c := &shimConn{
+		rwc:     rwc,
+		onTick:  onTick,
+		logger:  logger,
+		pending: map[uint64]chan response{},
+	}
+	c.workers = utils.NewBackgroundStoppableWorkers(c.readLoop)
+	return c
+}
+
+// call sends a request to the shim and returns the results of its response.
+func (c *shimConn) call(ctx context.Context, command string, args ...string) ([]string, error) {
+	ch := make(chan response, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	line := strings.Join(append([]string{strconv.FormatUint(id, 10), command}, args...), " ") + "\n"
+	c.writeMu.Lock()
+	_, err := io.WriteString(c.rwc, line)
+	c.writeMu.Unlock()
+	if err != nil {
+		return nil, errors.Wrapf(err, "sending %s to the microcontroller", command)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, responseTimeout)
+	defer cancel()
+	select {
+	case resp := <-ch:
+		if resp.err != nil {
+			return nil, errors.Wrapf(resp.err, "%s failed on the microcontroller", command)
+		}
+		return resp.results, nil
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "waiting for the microcontroller to answer %s", command)
+	}
+}
+
+// readLoop reads the lines the shim sends until the connection is closed, then fails the requests
+// still waiting for an answer.
+func (c *shimConn) readLoop(ctx context.Context) {
+	scanner := bufio.NewScanner(c.rwc)
+	for scanner.Scan() {
+		c.handleLine(ctx, scanner.Text())
+	}
+	err := scanner.Err()
+	if err == nil || ctx.Err() != nil {
+		err = errConnClosed
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+	for id, ch := range c.pending {
+		ch <- response{err: err}
+		delete(c.pending, id)
+	}
+}
+
+func (c *shimConn) handleLine(ctx context.Context, line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+	if fields[0] == "tick" {
+		if len(fields) != 4 {
+			c.logger.Debugw("ignoring malformed tick from the microcontroller", "line", line)
+			return
+		}
+		timestamp, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			c.logger.Debugw("ignoring malformed tick from the microcontroller", "line", line)
+			return
+		}
+		c.onTick(ctx, fields[1], fields[2] == "1", timestamp)
+		return
+	}
+
+	// microcontrollers commonly print other output, such as when they boot, which is ignored.
+	id, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil || len(fields) < 2 {
+		c.logger.Debugw("ignoring output of the microcontroller", "line", line)
+		return
+	}
+	var resp response
+	switch fields[1] {
+	case "ok":
+		resp.results = fields[2:]
+	case "err":
+		resp.err = errors.New(strings.Join(fields[2:], " "))
+	default:
+		c.logger.Debugw("ignoring output of the microcontroller", "line", line)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ch, ok := c.pending[id]; ok {
+		ch <- resp
+		delete(c.pending, id)
+	}
+}
+
+// Close closes the connection to the shim.
+func (c *shimConn) Close() error {
+	err := c.rwc.Close()
+	c.workers.Stop()
+	return err
+}
+
+// parseResults parses the results of a response into the values vs point to, which may be
+// *string, *bool, *int, *uint, *uint64, *float32, *float64, or *[]byte for hex encoded bytes.
+func parseResults(command string, results []string, vs ...interface{}) error {
+	if len(results) != len(vs) {
+		return fmt.Errorf("expected %d results from %s but the microcontroller answered %q", len(vs), command, results)
+	}
+	for i, result := range results {
+		var err error
+		switch v := vs[i].(type) {
+		case *string:
+			*v = result
+		case *bool:
+			*v = result == "1"
+		case *int:
+			*v, err = strconv.Atoi(result)
+		case *uint:
+			var u uint64
+			u, err = strconv.ParseUint(result, 10, 0)
+			*v = uint(u)
+		case *uint64:
+			*v, err = strconv.ParseUint(result, 10, 64)
+		case *float32:
+			var f float64
+			f, err = strconv.ParseFloat(result, 32)
+			*v = float32(f)
+		case *float64:
+			*v, err = strconv.ParseFloat(result, 64)
+		case *[]byte:
+			*v, err = decodeBytes(result)
+		default:
+			return fmt.Errorf("cannot parse results into %T", v)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "invalid result %q from %s", result, command)
+		}
+	}
+	return nil
+}
+
+// encodeBytes encodes bytes as a field of a request, as hex or "-" if there are none.
+func encodeBytes(data []byte) string {
+	if len(data) == 0 {
+		return "-"
+	}
+	return hex.EncodeToString(data)
+}
+
+// decodeBytes decodes bytes encoded by encodeBytes.
+func decodeBytes(field string) ([]byte, error) {
+	if field == "-" {
+		return nil, nil
+	}
+	return hex.DecodeString(field)
+}
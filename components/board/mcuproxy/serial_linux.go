@@ -0,0 +1,71 @@
+//go:build linux
+
+package mcuproxy
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/multierr"
+	"golang.org/x/sys/unix"
+)
+
+var baudRates = map[int]uint32{
+	9600:   unix.B9600,
+	19200:  unix.B19200,
+	38400:  unix.B38400,
+	57600:  unix.B57600,
+	115200: unix.B115200,
+	230400: unix.B230400,
+	460800: unix.B460800,
+	921600: unix.B921600,
+}
+
+// openSerial opens the serial device at path in raw mode at the given baud rate.
+func openSerial(path string, baudRate int) (io.ReadWriteCloser, error) {
+	speed, ok := baudRates[baudRate]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate %d", baudRate)
+	}
+	//nolint:gosec
+	f, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// the file descriptor is used through SyscallConn rather than Fd, which would make it blocking
+	// and so keep Close from interrupting reads.
+	rawConn, err := f.SyscallConn()
+	if err != nil {
+		return nil, multierr.Combine(err, f.Close())
+	}
+	var termiosErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		termiosErr = makeRaw(int(fd), speed)
+	}); err != nil {
+		return nil, multierr.Combine(err, f.Close())
+	}
+	if termiosErr != nil {
+		return nil, multierr.Combine(fmt.Errorf("configuring serial device %q: %w", path, termiosErr), f.Close())
+	}
+	return f, nil
+}
+
+// makeRaw configures the terminal fd for 8N1 raw binary transfers at speed.
+func makeRaw(fd int, speed uint32) error {
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return err
+	}
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB | unix.CBAUD
+	termios.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL | speed
+	termios.Ispeed = speed
+	termios.Ospeed = speed
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	return unix.IoctlSetTermios(fd, unix.TCSETS, termios)
+}
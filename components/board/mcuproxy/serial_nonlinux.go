@@ -0,0 +1,13 @@
+//go:build !linux
+
+package mcuproxy
+
+import (
+	"github.com/pkg/errors"
+	"io"
+)
+
+// openSerial would open a serial device, but serial devices are only supported on Linux.
+func openSerial(path string, baudRate int) (io.ReadWriteCloser, error) {
+	return nil, errors.New("serial connections to a microcontroller are only supported on linux; connect over TCP instead")
+}
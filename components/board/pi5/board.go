@@ -0,0 +1,67 @@
+//go:build linux
+
+package pi5
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mkch/gpio"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/board/genericlinux"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func init() {
+	// the pins of the Pi 5 can only be registered for the genericlinux model once its RP1 chip is
+	// found, so they are only registered on a Pi 5.
+	if chipDevice, err := findRP1Chip(); err == nil {
+		genericlinux.RegisterBoardInformation(modelName, boardInfoMappings(chipDevice)[modelName])
+	}
+	resource.RegisterComponent(
+		board.API,
+		resource.DefaultModelFamily.WithModel(modelName),
+		resource.Registration[board.Board, *genericlinux.Config]{
+			Constructor: func(
+				ctx context.Context,
+				_ resource.Dependencies,
+				conf resource.Config,
+				logger logging.Logger,
+			) (board.Board, error) {
+				return genericlinux.NewBoard(ctx, conf, convertConfig, logger)
+			},
+		})
+}
+
+// convertConfig finds the pins of the board whenever it is configured, because the RP1 chips are
+// not numbered the same way by every kernel and the PWM chip may only be enabled after a reboot.
+func convertConfig(conf resource.Config, logger logging.Logger) (*genericlinux.LinuxBoardConfig, error) {
+	chipDevice, err := findRP1Chip()
+	if err != nil {
+		return nil, err
+	}
+	gpioMappings, err := genericlinux.GetGPIOBoardMappings(modelName, boardInfoMappings(chipDevice), logger)
+	if err != nil {
+		return nil, err
+	}
+	return genericlinux.ConstPinDefs(gpioMappings)(conf, logger)
+}
+
+// findRP1Chip returns the name of the GPIO chip device, within /dev, of the RP1.
+func findRP1Chip() (string, error) {
+	for _, device := range gpio.ChipDevices() {
+		chip, err := gpio.OpenChip(device)
+		if err != nil {
+			continue
+		}
+		info, err := chip.Info()
+		utils.UncheckedError(chip.Close())
+		if err == nil && info.Label == rp1ChipLabel {
+			return device, nil
+		}
+	}
+	return "", fmt.Errorf("could not find the RP1 GPIO chip (labeled %q); is this a Raspberry Pi 5?", rp1ChipLabel)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package pi5
+
+import "go.viam.com/rdk/components/board/genericlinux"
+
+func init() {
+	genericlinux.RegisterBoard(modelName, nil)
+}
@@ -0,0 +1,82 @@
+package pi5
+
+import "go.viam.com/rdk/components/board/genericlinux"
+
+// rp1ChipLabel is the label of the GPIO chip of the RP1. Its device number varies between kernels,
+// so the chip is found by its label.
+const rp1ChipLabel = "pinctrl-rp1"
+
+// rp1PwmChip is the device tree name of the PWM controller of the RP1 whose channels are routed to
+// the header. It is only available once enabled, such as with dtoverlay=pwm-2chan.
+const rp1PwmChip = "1f00098000.pwm"
+
+// compats are the device tree compatibles of the boards built around the BCM2712 and the RP1,
+// which include the Compute Module 5 and the Pi 500 as well as the Pi 5.
+var compats = []string{"raspberrypi,5-model-b", "brcm,bcm2712"}
+
+// headerPins maps the physical pins of the 40-pin header to the RP1 lines they are wired to. RP1
+// line numbers match the BCM GPIO numbers of earlier Raspberry Pis.
+var headerPins = []struct {
+	name  string
+	line  int
+	pwmID int
+}{
+	{"3", 2, -1},
+	{"5", 3, -1},
+	{"7", 4, -1},
+	{"8", 14, -1},
+	{"10", 15, -1},
+	{"11", 17, -1},
+	{"12", 18, 2},
+	{"13", 27, -1},
+	{"15", 22, -1},
+	{"16", 23, -1},
+	{"18", 24, -1},
+	{"19", 10, -1},
+	{"21", 9, -1},
+	{"22", 25, -1},
+	{"23", 11, -1},
+	{"24", 8, -1},
+	{"26", 7, -1},
+	{"27", 0, -1},
+	{"28", 1, -1},
+	{"29", 5, -1},
+	{"31", 6, -1},
+	{"32", 12, 0},
+	{"33", 13, 1},
+	{"35", 19, 3},
+	{"36", 16, -1},
+	{"37", 26, -1},
+	{"38", 20, -1},
+	{"40", 21, -1},
+}
+
+// pinDefinitions returns the definitions of the header pins, whose lines are on the GPIO chip
+// device chipDevice.
+func pinDefinitions(chipDevice string) []genericlinux.PinDefinition {
+	pinDefs := make([]genericlinux.PinDefinition, 0, len(headerPins))
+	for _, pin := range headerPins {
+		pinDef := genericlinux.PinDefinition{
+			Name:       pin.name,
+			DeviceName: chipDevice,
+			LineNumber: pin.line,
+			PwmID:      pin.pwmID,
+		}
+		if pin.pwmID != -1 {
+			pinDef.PwmChipSysfsDir = rp1PwmChip
+		}
+		pinDefs = append(pinDefs, pinDef)
+	}
+	return pinDefs
+}
+
+// boardInfoMappings returns the board information of the Pi 5, whose lines are on the GPIO chip
+// device chipDevice.
+func boardInfoMappings(chipDevice string) map[string]genericlinux.BoardInformation {
+	return map[string]genericlinux.BoardInformation{
+		modelName: {
+			PinDefinitions: pinDefinitions(chipDevice),
+			Compats:        compats,
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package pi5
+
+import (
+	"fmt"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestPinDefinitions(t *testing.T) {
+	pinDefs := pinDefinitions("gpiochip0")
+	test.That(t, pinDefs, test.ShouldHaveLength, 28)
+
+	lines := map[int]string{}
+	pwmIDs := map[int]string{}
+	for i, pinDef := range pinDefs {
+		test.That(t, pinDef.Validate(fmt.Sprintf("pins.%d", i)), test.ShouldBeNil)
+		test.That(t, pinDef.DeviceName, test.ShouldEqual, "gpiochip0")
+		test.That(t, lines, test.ShouldNotContainKey, pinDef.LineNumber)
+		lines[pinDef.LineNumber] = pinDef.Name
+		if pinDef.PwmID != -1 {
+			test.That(t, pinDef.PwmChipSysfsDir, test.ShouldEqual, rp1PwmChip)
+			test.That(t, pwmIDs, test.ShouldNotContainKey, pinDef.PwmID)
+			pwmIDs[pinDef.PwmID] = pinDef.Name
+		}
+	}
+	// every line of the first bank of the RP1 is on the header.
+	for line := 0; line < 28; line++ {
+		test.That(t, lines, test.ShouldContainKey, line)
+	}
+	test.That(t, pwmIDs, test.ShouldResemble, map[int]string{0: "32", 1: "33", 2: "12", 3: "35"})
+}
@@ -0,0 +1,6 @@
+// Package pi5 implements a board for the Raspberry Pi 5, whose GPIO and PWM pins are provided by
+// the RP1 I/O controller rather than by the SoC as on earlier Raspberry Pis. The board itself is
+// a genericlinux board with the pin definitions of the RP1.
+package pi5
+
+const modelName = "pi5"
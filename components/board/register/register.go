@@ -5,4 +5,6 @@ import (
 	// for boards.
 	_ "go.viam.com/rdk/components/board/esp32"
 	_ "go.viam.com/rdk/components/board/fake"
+	_ "go.viam.com/rdk/components/board/mcuproxy"
+	_ "go.viam.com/rdk/components/board/pi5"
 )
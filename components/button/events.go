@@ -0,0 +1,124 @@
+package button
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// An Event is a button being pressed or released.
+type Event struct {
+	Time    time.Time
+	Pressed bool
+}
+
+// An EventStreamer is a button that senses being pressed and released, such as a momentary button
+// or switch wired to a board. Pushing it simulates a press and release.
+// Its resource Status reports its State in the form produced by StateToMap.
+type EventStreamer interface {
+	Button
+	// StreamEvents sends the events of the button until ctx is done, when the channel is closed.
+	StreamEvents(ctx context.Context, extra map[string]interface{}) (<-chan Event, error)
+}
+
+// State is the state of a button that senses being pressed.
+type State struct {
+	Pressed bool
+	// Presses is the number of times the button has been pressed.
+	Presses int
+	// LastPress is when the button was last pressed, if it has been.
+	LastPress time.Time
+}
+
+// GetState returns the state of a button that senses being pressed from its resource Status.
+func GetState(ctx context.Context, b Button) (State, error) {
+	m, err := b.Status(ctx)
+	if err != nil {
+		return State{}, err
+	}
+	return StateFromMap(m)
+}
+
+// StateToMap converts a State into the map reported by a button's resource Status.
+func StateToMap(st State) map[string]interface{} {
+	m := map[string]interface{}{"pressed": st.Pressed, "presses": float64(st.Presses)}
+	if !st.LastPress.IsZero() {
+		m["last_press"] = st.LastPress.UTC().Format(time.RFC3339Nano)
+	}
+	return m
+}
+
+// StateFromMap converts a map produced by StateToMap back into a State.
+func StateFromMap(m map[string]interface{}) (State, error) {
+	pressed, ok := m["pressed"].(bool)
+	if !ok {
+		return State{}, errors.Errorf("expected button status pressed to be a bool but got %T", m["pressed"])
+	}
+	presses, _ := m["presses"].(float64)
+	st := State{Pressed: pressed, Presses: int(presses)}
+	if raw, ok := m["last_press"].(string); ok {
+		lastPress, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return State{}, err
+		}
+		st.LastPress = lastPress
+	}
+	return st, nil
+}
+
+// StreamEvents sends the events of b until ctx is done, when the channel is closed. Events come
+// directly from b if it is an EventStreamer, and are otherwise derived from the State b reports,
+// polled every interval, such as for buttons of remote machines. Presses shorter than interval are
+// then reported as a press and release at the time they were polled.
+func StreamEvents(ctx context.Context, b Button, interval time.Duration) (<-chan Event, error) {
+	if streamer, ok := b.(EventStreamer); ok {
+		return streamer.StreamEvents(ctx, nil)
+	}
+	if interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	last, err := GetState(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event)
+	utils.PanicCapturingGo(func() {
+		defer close(events)
+		send := func(event Event) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for utils.SelectContextOrWait(ctx, interval) {
+			st, err := GetState(ctx, b)
+			if err != nil {
+				// the button may be briefly unreachable, so polling continues.
+				continue
+			}
+			now := time.Now()
+			if presses := st.Presses - last.Presses; presses > 0 {
+				// presses missed between polls are reported as presses and releases.
+				for i := 0; i < presses; i++ {
+					if last.Pressed && !send(Event{Time: now, Pressed: false}) {
+						return
+					}
+					if !send(Event{Time: now, Pressed: true}) {
+						return
+					}
+					last.Pressed = true
+				}
+			}
+			if last.Pressed && !st.Pressed && !send(Event{Time: now, Pressed: false}) {
+				return
+			}
+			last = st
+		}
+	})
+	return events, nil
+}
@@ -0,0 +1,65 @@
+package button_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/button"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestStateMap(t *testing.T) {
+	st := button.State{Pressed: true, Presses: 3, LastPress: time.Date(2026, 1, 2, 3, 4, 5, 6, time.UTC)}
+	roundTripped, err := button.StateFromMap(button.StateToMap(st))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, roundTripped, test.ShouldResemble, st)
+
+	_, err = button.StateFromMap(map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestStreamEventsByPolling(t *testing.T) {
+	var mu sync.Mutex
+	st := button.State{}
+	b := inject.NewButton("remote")
+	b.StatusFunc = func(ctx context.Context) (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return button.StateToMap(st), nil
+	}
+	setState := func(pressed bool, presses int) {
+		mu.Lock()
+		defer mu.Unlock()
+		st = button.State{Pressed: pressed, Presses: presses}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := button.StreamEvents(ctx, b, time.Millisecond)
+	test.That(t, err, test.ShouldBeNil)
+	next := func() bool {
+		select {
+		case event := <-events:
+			return event.Pressed
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a button event")
+			return false
+		}
+	}
+
+	setState(true, 1)
+	test.That(t, next(), test.ShouldBeTrue)
+	setState(false, 1)
+	test.That(t, next(), test.ShouldBeFalse)
+	// presses between polls are reported as presses and releases.
+	setState(false, 3)
+	test.That(t, []bool{next(), next(), next(), next()}, test.ShouldResemble, []bool{true, false, true, false})
+
+	cancel()
+	for range events {
+	}
+}
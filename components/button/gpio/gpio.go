@@ -0,0 +1,238 @@
+// Package gpio implements a momentary button or switch wired to a GPIO pin of a board, which
+// streams its presses and releases.
+package gpio
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/button"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	defaultDebounceMS     = 20
+	defaultPollIntervalMS = 10
+	// eventBufferSize is the number of events buffered for each stream, beyond which the events of a
+	// stream that is not being read are dropped.
+	eventBufferSize = 64
+)
+
+var model = resource.DefaultModelFamily.WithModel("gpio")
+
+func init() {
+	resource.RegisterComponent(button.API, model, resource.Registration[button.Button, *Config]{Constructor: NewButton})
+}
+
+// Config describes the pin a button is wired to.
+type Config struct {
+	Board string `json:"board"`
+	Pin   string `json:"pin"`
+	// ActiveLow is whether the pin is low while the button is pressed, as it is for buttons that
+	// connect the pin to ground with a pull-up resistor.
+	ActiveLow bool `json:"active_low,omitempty"`
+	// DebounceMS is how long the pin must hold its state before a press or release is reported.
+	// DebounceMS defaults to 20 if unspecified; set it to -1 to disable debouncing.
+	DebounceMS int `json:"debounce_ms,omitempty"`
+	// PollIntervalMS is how often the pin is read. PollIntervalMS defaults to 10 if unspecified.
+	PollIntervalMS int `json:"poll_interval_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.Board == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	if conf.Pin == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "pin")
+	}
+	if conf.DebounceMS < -1 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("debounce_ms must be -1 or more"))
+	}
+	if conf.PollIntervalMS < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("poll_interval_ms cannot be negative"))
+	}
+	return []string{conf.Board}, nil, nil
+}
+
+// Button is a button wired to a GPIO pin.
+type Button struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger    logging.Logger
+	pin       board.GPIOPin
+	activeLow bool
+	debounce  time.Duration
+	interval  time.Duration
+	workers   *utils.StoppableWorkers
+
+	mu      sync.Mutex
+	state   button.State
+	streams map[chan button.Event]struct{}
+}
+
+// NewButton instantiates a new button of the gpio model type.
+func NewButton(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (button.Button, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b, err := board.FromProvider(deps, newConf.Board)
+	if err != nil {
+		return nil, err
+	}
+	pin, err := b.GPIOPinByName(newConf.Pin)
+	if err != nil {
+		return nil, err
+	}
+
+	btn := &Button{
+		Named:     conf.ResourceName().AsNamed(),
+		logger:    logger,
+		pin:       pin,
+		activeLow: newConf.ActiveLow,
+		debounce:  time.Duration(newConf.DebounceMS) * time.Millisecond,
+		interval:  time.Duration(newConf.PollIntervalMS) * time.Millisecond,
+		streams:   map[chan button.Event]struct{}{},
+	}
+	switch newConf.DebounceMS {
+	case 0:
+		btn.debounce = defaultDebounceMS * time.Millisecond
+	case -1:
+		btn.debounce = 0
+	}
+	if btn.interval == 0 {
+		btn.interval = defaultPollIntervalMS * time.Millisecond
+	}
+
+	// the button starts in the state of the pin, without an event.
+	pressed, err := btn.read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	btn.state.Pressed = pressed
+	btn.workers = utils.NewBackgroundStoppableWorkers(btn.poll)
+	return btn, nil
+}
+
+func (b *Button) read(ctx context.Context) (bool, error) {
+	high, err := b.pin.Get(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	return high != b.activeLow, nil
+}
+
+// poll reads the pin until ctx is done, reporting a press or release once the pin has held a new
+// state for the debounce duration.
+func (b *Button) poll(ctx context.Context) {
+	b.mu.Lock()
+	stable := b.state.Pressed
+	b.mu.Unlock()
+	candidate, since := stable, time.Now()
+	var lastErr error
+
+	for utils.SelectContextOrWait(ctx, b.interval) {
+		pressed, err := b.read(ctx)
+		if err != nil {
+			if ctx.Err() == nil && (lastErr == nil || err.Error() != lastErr.Error()) {
+				b.logger.CWarnw(ctx, "failed to read button pin", "error", err)
+			}
+			lastErr = err
+			continue
+		}
+		lastErr = nil
+
+		now := time.Now()
+		if pressed != candidate {
+			candidate, since = pressed, now
+		}
+		if candidate != stable && now.Sub(since) >= b.debounce {
+			stable = candidate
+			b.emit(button.Event{Time: now, Pressed: stable})
+		}
+	}
+}
+
+// emit updates the state of the button with event and sends it to all streams.
+func (b *Button) emit(event button.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.Pressed = event.Pressed
+	if event.Pressed {
+		b.state.Presses++
+		b.state.LastPress = event.Time
+	}
+	for stream := range b.streams {
+		select {
+		case stream <- event:
+		default:
+			b.logger.Debug("dropped button event of a stream that is not being read")
+		}
+	}
+}
+
+// Push simulates the button being pressed and released.
+func (b *Button) Push(ctx context.Context, extra map[string]interface{}) error {
+	now := time.Now()
+	b.emit(button.Event{Time: now, Pressed: true})
+	b.emit(button.Event{Time: now, Pressed: false})
+	return nil
+}
+
+// StreamEvents sends the presses and releases of the button until ctx is done or the button is
+// closed. Events are dropped if more than 64 are waiting to be read.
+func (b *Button) StreamEvents(ctx context.Context, extra map[string]interface{}) (<-chan button.Event, error) {
+	stream := make(chan button.Event, eventBufferSize)
+	b.mu.Lock()
+	b.streams[stream] = struct{}{}
+	b.mu.Unlock()
+
+	events := make(chan button.Event)
+	b.workers.Add(func(workersCtx context.Context) {
+		defer close(events)
+		defer func() {
+			b.mu.Lock()
+			delete(b.streams, stream)
+			b.mu.Unlock()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-workersCtx.Done():
+				return
+			case event := <-stream:
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				case <-workersCtx.Done():
+					return
+				}
+			}
+		}
+	})
+	return events, nil
+}
+
+// Status returns the state of the button in the form produced by button.StateToMap.
+func (b *Button) Status(ctx context.Context) (map[string]interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return button.StateToMap(b.state), nil
+}
+
+// Close stops reading the pin and ends all streams.
+func (b *Button) Close(ctx context.Context) error {
+	b.workers.Stop()
+	return nil
+}
@@ -0,0 +1,110 @@
+package gpio
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/button"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func newButton(t *testing.T, conf *Config, high *atomic.Bool) button.Button {
+	t.Helper()
+	pin := &inject.GPIOPin{}
+	pin.GetFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		return high.Load(), nil
+	}
+	b := inject.NewBoard("board")
+	b.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) {
+		test.That(t, name, test.ShouldEqual, conf.Pin)
+		return pin, nil
+	}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"board"})
+
+	btn, err := NewButton(context.Background(), resource.Dependencies{board.Named("board"): b}, resource.Config{
+		Name:                "button",
+		API:                 button.API,
+		Model:               model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { test.That(t, btn.Close(context.Background()), test.ShouldBeNil) })
+	return btn
+}
+
+func nextEvent(t *testing.T, events <-chan button.Event) button.Event {
+	t.Helper()
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a button event")
+		return button.Event{}
+	}
+}
+
+func TestButton(t *testing.T) {
+	ctx := context.Background()
+	// the button pulls the pin low when pressed.
+	var high atomic.Bool
+	high.Store(true)
+	btn := newButton(t, &Config{Board: "board", Pin: "11", ActiveLow: true, DebounceMS: 5, PollIntervalMS: 1}, &high)
+
+	st, err := button.GetState(ctx, btn)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st, test.ShouldResemble, button.State{})
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	events, err := button.StreamEvents(streamCtx, btn, time.Second)
+	test.That(t, err, test.ShouldBeNil)
+
+	high.Store(false)
+	event := nextEvent(t, events)
+	test.That(t, event.Pressed, test.ShouldBeTrue)
+	high.Store(true)
+	test.That(t, nextEvent(t, events).Pressed, test.ShouldBeFalse)
+
+	st, err = button.GetState(ctx, btn)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Pressed, test.ShouldBeFalse)
+	test.That(t, st.Presses, test.ShouldEqual, 1)
+	test.That(t, st.LastPress.Equal(event.Time), test.ShouldBeTrue)
+
+	// pushing simulates a press and release.
+	test.That(t, btn.Push(ctx, nil), test.ShouldBeNil)
+	test.That(t, nextEvent(t, events).Pressed, test.ShouldBeTrue)
+	test.That(t, nextEvent(t, events).Pressed, test.ShouldBeFalse)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	var high atomic.Bool
+	btn := newButton(t, &Config{Board: "board", Pin: "11", DebounceMS: 200, PollIntervalMS: 1}, &high)
+	events, err := button.StreamEvents(context.Background(), btn, time.Second)
+	test.That(t, err, test.ShouldBeNil)
+
+	// bounces shorter than the debounce duration are not reported.
+	for i := 0; i < 5; i++ {
+		high.Store(true)
+		time.Sleep(10 * time.Millisecond)
+		high.Store(false)
+		time.Sleep(10 * time.Millisecond)
+	}
+	high.Store(true)
+	test.That(t, nextEvent(t, events).Pressed, test.ShouldBeTrue)
+	st, err := button.GetState(context.Background(), btn)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Presses, test.ShouldEqual, 1)
+}
@@ -4,4 +4,5 @@ package register
 import (
 	// for buttons.
 	_ "go.viam.com/rdk/components/button/fake"
+	_ "go.viam.com/rdk/components/button/gpio"
 )
@@ -4,4 +4,6 @@ package register
 import (
 	// for cameras.
 	_ "go.viam.com/rdk/components/camera/fake"
+	_ "go.viam.com/rdk/components/camera/simbridge"
+	_ "go.viam.com/rdk/components/camera/stereo"
 )
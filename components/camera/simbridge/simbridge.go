@@ -0,0 +1,367 @@
+// Package simbridge implements a camera bound to a camera in a running Gazebo or Isaac Sim
+// simulation over ROS 2 topics through rosbridge. It serves the latest color and depth images the
+// simulation publishes, and point clouds from them given the camera's intrinsics.
+package simbridge
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/depthadapter"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/ros/rosbridge"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// Model is the model of cameras bound to a simulation.
+var Model = resource.DefaultModelFamily.WithModel("sim-bridge")
+
+const (
+	colorSource = "color"
+	depthSource = "depth"
+)
+
+func init() {
+	resource.RegisterComponent(camera.API, Model, resource.Registration[camera.Camera, *Config]{
+		Constructor: NewCamera,
+	})
+}
+
+// Config binds a camera to a simulated one.
+type Config struct {
+	// RosbridgeURL is the websocket URL of the rosbridge server, such as ws://localhost:9090.
+	RosbridgeURL string `json:"rosbridge_url"`
+	// ColorTopic publishes sensor_msgs/Image, in rgb8, rgba8, bgr8, bgra8 or mono8, or
+	// sensor_msgs/CompressedImage if CompressedColor is set.
+	ColorTopic      string `json:"color_topic,omitempty"`
+	CompressedColor bool   `json:"compressed_color,omitempty"`
+	// DepthTopic publishes sensor_msgs/Image in 16UC1 millimeters or 32FC1 meters.
+	DepthTopic           string                             `json:"depth_topic,omitempty"`
+	CameraParameters     *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
+	DistortionParameters *transform.BrownConrady            `json:"distortion_parameters,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if err := rosbridge.ValidateURL(path, conf.RosbridgeURL); err != nil {
+		return nil, nil, err
+	}
+	if conf.ColorTopic == "" && conf.DepthTopic == "" {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("at least one of color_topic and depth_topic is required"))
+	}
+	if conf.CameraParameters != nil {
+		if err := conf.CameraParameters.CheckValid(); err != nil {
+			return nil, nil, resource.NewConfigValidationError(path, err)
+		}
+	}
+	return nil, nil, nil
+}
+
+type simCamera struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf       *Config
+	geometries []spatialmath.Geometry
+	client     *rosbridge.Client
+	logger     logging.Logger
+
+	mu    sync.Mutex
+	color *rosbridge.CompressedImage
+	raw   *rosbridge.Image
+	depth *rosbridge.Image
+}
+
+// NewCamera returns a camera bound to a simulated one.
+func NewCamera(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (camera.Camera, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	c := &simCamera{
+		Named:      conf.ResourceName().AsNamed(),
+		conf:       newConf,
+		geometries: []spatialmath.Geometry{},
+		client:     rosbridge.NewClient(newConf.RosbridgeURL, logger),
+		logger:     logger,
+	}
+	if conf.Frame != nil && conf.Frame.Geometry != nil {
+		geometry, err := conf.Frame.Geometry.ParseConfig()
+		if err != nil {
+			return nil, err
+		}
+		c.geometries = []spatialmath.Geometry{geometry}
+	}
+	switch {
+	case newConf.ColorTopic != "" && newConf.CompressedColor:
+		subscribe(c, newConf.ColorTopic, rosbridge.TypeCompressedImage, &c.color)
+	case newConf.ColorTopic != "":
+		subscribe(c, newConf.ColorTopic, rosbridge.TypeImage, &c.raw)
+	}
+	if newConf.DepthTopic != "" {
+		subscribe(c, newConf.DepthTopic, rosbridge.TypeImage, &c.depth)
+	}
+	return c, nil
+}
+
+// subscribe stores each message of msgType received on topic in latest.
+func subscribe[T any](c *simCamera, topic, msgType string, latest **T) {
+	c.client.Subscribe(topic, msgType, func(data json.RawMessage) {
+		msg := new(T)
+		if err := json.Unmarshal(data, msg); err != nil {
+			c.logger.Warnw("invalid image", "topic", topic, "error", err)
+			return
+		}
+		c.mu.Lock()
+		*latest = msg
+		c.mu.Unlock()
+	})
+}
+
+// latest returns the latest message on topic, or an error if none has been received.
+func latest[T any](c *simCamera, topic string, msg **T) (*T, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if *msg == nil {
+		return nil, errors.Errorf("no image received on %s yet", topic)
+	}
+	return *msg, nil
+}
+
+func stampTime(h rosbridge.Header) time.Time {
+	return time.Unix(int64(h.Stamp.Sec), int64(h.Stamp.Nanosec))
+}
+
+// colorImage returns the latest color image, and when it was captured.
+func (c *simCamera) colorImage() (camera.NamedImage, time.Time, error) {
+	if c.conf.CompressedColor {
+		msg, err := latest(c, c.conf.ColorTopic, &c.color)
+		if err != nil {
+			return camera.NamedImage{}, time.Time{}, err
+		}
+		mimeType := utils.MimeTypeJPEG
+		if strings.Contains(msg.Format, "png") {
+			mimeType = utils.MimeTypePNG
+		}
+		img, err := camera.NamedImageFromBytes(msg.Data, colorSource, mimeType, data.Annotations{})
+		return img, stampTime(msg.Header), err
+	}
+	msg, err := latest(c, c.conf.ColorTopic, &c.raw)
+	if err != nil {
+		return camera.NamedImage{}, time.Time{}, err
+	}
+	decoded, err := decodeColor(msg)
+	if err != nil {
+		return camera.NamedImage{}, time.Time{}, err
+	}
+	img, err := camera.NamedImageFromImage(decoded, colorSource, utils.MimeTypeJPEG, data.Annotations{})
+	return img, stampTime(msg.Header), err
+}
+
+// depthMap returns the latest depth map, and when it was captured.
+func (c *simCamera) depthMap() (*rimage.DepthMap, time.Time, error) {
+	msg, err := latest(c, c.conf.DepthTopic, &c.depth)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	dm, err := decodeDepth(msg)
+	return dm, stampTime(msg.Header), err
+}
+
+// Images returns the latest color and depth images.
+func (c *simCamera) Images(
+	ctx context.Context,
+	filterSourceNames []string,
+	extra map[string]interface{},
+) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	for _, name := range filterSourceNames {
+		if name != colorSource && name != depthSource {
+			return nil, resource.ResponseMetadata{}, errors.Errorf("invalid source name: %s", name)
+		}
+	}
+	wanted := func(source, topic string) bool {
+		return topic != "" && (len(filterSourceNames) == 0 || slices.Contains(filterSourceNames, source))
+	}
+	var images []camera.NamedImage
+	var captured time.Time
+	if wanted(colorSource, c.conf.ColorTopic) {
+		img, at, err := c.colorImage()
+		if err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+		images, captured = append(images, img), at
+	}
+	if wanted(depthSource, c.conf.DepthTopic) {
+		dm, at, err := c.depthMap()
+		if err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+		img, err := camera.NamedImageFromImage(dm, depthSource, utils.MimeTypeRawDepth, data.Annotations{})
+		if err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+		images = append(images, img)
+		if at.After(captured) {
+			captured = at
+		}
+	}
+	if len(images) == 0 {
+		return nil, resource.ResponseMetadata{}, errors.New("the camera has none of the requested sources")
+	}
+	return images, resource.ResponseMetadata{CapturedAt: captured}, nil
+}
+
+// NextPointCloud projects the latest depth map, colored by the latest color image if there is one,
+// using the camera's intrinsics.
+func (c *simCamera) NextPointCloud(ctx context.Context, extra map[string]interface{}) (pointcloud.PointCloud, error) {
+	if c.conf.CameraParameters == nil {
+		return nil, transform.NewNoIntrinsicsError("camera intrinsics not found in config")
+	}
+	if c.conf.DepthTopic == "" {
+		return nil, errors.New("no depth_topic to project to a point cloud")
+	}
+	dm, _, err := c.depthMap()
+	if err != nil {
+		return nil, err
+	}
+	if c.conf.ColorTopic == "" {
+		return depthadapter.ToPointCloud(dm, c.conf.CameraParameters), nil
+	}
+	named, _, err := c.colorImage()
+	if err != nil {
+		return nil, err
+	}
+	img, err := named.Image(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.conf.CameraParameters.RGBDToPointCloud(rimage.ConvertImage(img), dm)
+}
+
+// Properties returns the configured intrinsics of the camera.
+func (c *simCamera) Properties(ctx context.Context) (camera.Properties, error) {
+	props := camera.Properties{
+		SupportsPCD:     c.conf.CameraParameters != nil && c.conf.DepthTopic != "",
+		ImageType:       camera.ColorStream,
+		IntrinsicParams: c.conf.CameraParameters,
+	}
+	if c.conf.DistortionParameters != nil {
+		props.DistortionParams = c.conf.DistortionParameters
+	}
+	if c.conf.ColorTopic == "" {
+		props.ImageType = camera.DepthStream
+	}
+	return props, nil
+}
+
+// Geometries returns the geometry of the camera's frame.
+func (c *simCamera) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return c.geometries, nil
+}
+
+// DoCommand reports whether the camera is connected to the simulation.
+func (c *simCamera) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"connected": c.client.Connected()}, nil
+}
+
+// Close disconnects from the simulation.
+func (c *simCamera) Close(ctx context.Context) error {
+	c.client.Close()
+	return nil
+}
+
+// checkSize returns an error unless msg holds a whole image of pixels of bytesPerPixel.
+func checkSize(msg *rosbridge.Image, bytesPerPixel int) error {
+	if msg.Width <= 0 || msg.Height <= 0 || msg.Step < msg.Width*bytesPerPixel || len(msg.Data) < msg.Step*msg.Height {
+		return errors.Errorf("%dx%d %s image with a step of %d has %d bytes", msg.Width, msg.Height, msg.Encoding, msg.Step, len(msg.Data))
+	}
+	return nil
+}
+
+// decodeColor decodes a raw color image.
+func decodeColor(msg *rosbridge.Image) (image.Image, error) {
+	channels := map[string]int{"rgb8": 3, "bgr8": 3, "rgba8": 4, "bgra8": 4, "mono8": 1}[msg.Encoding]
+	if channels == 0 {
+		return nil, errors.Errorf("unsupported color image encoding %q", msg.Encoding)
+	}
+	if err := checkSize(msg, channels); err != nil {
+		return nil, err
+	}
+	if channels == 1 {
+		img := image.NewGray(image.Rect(0, 0, msg.Width, msg.Height))
+		for y := 0; y < msg.Height; y++ {
+			copy(img.Pix[y*img.Stride:], msg.Data[y*msg.Step:y*msg.Step+msg.Width])
+		}
+		return img, nil
+	}
+	bgr := strings.HasPrefix(msg.Encoding, "bgr")
+	img := image.NewNRGBA(image.Rect(0, 0, msg.Width, msg.Height))
+	for y := 0; y < msg.Height; y++ {
+		for x := 0; x < msg.Width; x++ {
+			px := msg.Data[y*msg.Step+x*channels:]
+			c := color.NRGBA{R: px[0], G: px[1], B: px[2], A: 255}
+			if bgr {
+				c.R, c.B = c.B, c.R
+			}
+			if channels == 4 {
+				c.A = px[3]
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img, nil
+}
+
+// decodeDepth decodes a raw depth image in millimeters or meters.
+func decodeDepth(msg *rosbridge.Image) (*rimage.DepthMap, error) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if msg.IsBigendian != 0 {
+		order = binary.BigEndian
+	}
+	var size int
+	var depthAt func(px []byte) rimage.Depth
+	switch msg.Encoding {
+	case "16UC1", "mono16":
+		size = 2
+		depthAt = func(px []byte) rimage.Depth { return rimage.Depth(order.Uint16(px)) }
+	case "32FC1":
+		size = 4
+		depthAt = func(px []byte) rimage.Depth {
+			meters := float64(math.Float32frombits(order.Uint32(px)))
+			// simulations report no return, beyond their clipping range, as infinite or NaN.
+			if math.IsNaN(meters) || meters <= 0 || meters*1000 > float64(rimage.MaxDepth) {
+				return 0
+			}
+			return rimage.Depth(math.Round(meters * 1000))
+		}
+	default:
+		return nil, errors.Errorf("unsupported depth image encoding %q", msg.Encoding)
+	}
+	if err := checkSize(msg, size); err != nil {
+		return nil, err
+	}
+	dm := rimage.NewEmptyDepthMap(msg.Width, msg.Height)
+	for y := 0; y < msg.Height; y++ {
+		for x := 0; x < msg.Width; x++ {
+			dm.Set(x, y, depthAt(msg.Data[y*msg.Step+x*size:]))
+		}
+	}
+	return dm, nil
+}
@@ -0,0 +1,122 @@
+package simbridge
+
+import (
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/ros/rosbridge"
+	"go.viam.com/rdk/utils"
+)
+
+func TestValidate(t *testing.T) {
+	_, _, err := (&Config{RosbridgeURL: "ws://localhost:9090"}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least one")
+	_, _, err = (&Config{
+		RosbridgeURL:     "ws://localhost:9090",
+		DepthTopic:       "/depth",
+		CameraParameters: &transform.PinholeCameraIntrinsics{Width: -1},
+	}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestDecode(t *testing.T) {
+	// a 2x1 bgr8 image whose rows are padded to 8 bytes.
+	img, err := decodeColor(&rosbridge.Image{Width: 2, Height: 1, Step: 8, Encoding: "bgr8", Data: []byte{1, 2, 3, 4, 5, 6, 0, 0}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.At(0, 0), test.ShouldResemble, color.NRGBA{R: 3, G: 2, B: 1, A: 255})
+	test.That(t, img.At(1, 0), test.ShouldResemble, color.NRGBA{R: 6, G: 5, B: 4, A: 255})
+
+	_, err = decodeColor(&rosbridge.Image{Width: 2, Height: 1, Step: 6, Encoding: "rgb8", Data: []byte{1, 2, 3}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = decodeColor(&rosbridge.Image{Width: 1, Height: 1, Step: 1, Encoding: "yuv422", Data: []byte{1}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, math.Float32bits(1.5))
+	binary.LittleEndian.PutUint32(data[4:], math.Float32bits(float32(math.Inf(1))))
+	binary.LittleEndian.PutUint32(data[8:], math.Float32bits(float32(math.NaN())))
+	dm, err := decodeDepth(&rosbridge.Image{Width: 3, Height: 1, Step: 12, Encoding: "32FC1", Data: data})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dm.GetDepth(0, 0), test.ShouldEqual, rimage.Depth(1500))
+	test.That(t, dm.GetDepth(1, 0), test.ShouldEqual, rimage.Depth(0))
+	test.That(t, dm.GetDepth(2, 0), test.ShouldEqual, rimage.Depth(0))
+
+	dm, err = decodeDepth(&rosbridge.Image{Width: 1, Height: 1, Step: 2, Encoding: "16UC1", IsBigendian: 1, Data: []byte{1, 2}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, dm.GetDepth(0, 0), test.ShouldEqual, rimage.Depth(258))
+}
+
+func TestCamera(t *testing.T) {
+	ctx := context.Background()
+	fs := rosbridge.NewFakeServer()
+	defer fs.Close()
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 2, Height: 2, Fx: 1, Fy: 1, Ppx: 1, Ppy: 1}
+	conf := &Config{RosbridgeURL: fs.URL(), ColorTopic: "/rgb", DepthTopic: "/depth", CameraParameters: intrinsics}
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	cam, err := NewCamera(ctx, nil, resource.Config{
+		Name:                "cam",
+		API:                 camera.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, cam.Close(ctx), test.ShouldBeNil)
+	}()
+
+	props, err := cam.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.SupportsPCD, test.ShouldBeTrue)
+	_, _, err = cam.Images(ctx, nil, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no image received on /rgb")
+
+	colorMsg := rosbridge.Image{
+		Header: rosbridge.Header{Stamp: rosbridge.Time{Sec: 100}},
+		Width:  2, Height: 2, Step: 6, Encoding: "rgb8",
+		Data: []byte{255, 0, 0, 255, 0, 0, 255, 0, 0, 255, 0, 0},
+	}
+	depthMsg := rosbridge.Image{
+		Header: rosbridge.Header{Stamp: rosbridge.Time{Sec: 101}},
+		Width:  2, Height: 2, Step: 4, Encoding: "16UC1",
+		Data: []byte{0xe8, 3, 0xe8, 3, 0xe8, 3, 0xe8, 3},
+	}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, fs.Publish(ctx, "/rgb", colorMsg), test.ShouldBeNil)
+		test.That(tb, fs.Publish(ctx, "/depth", depthMsg), test.ShouldBeNil)
+		_, _, err := cam.Images(ctx, nil, nil)
+		test.That(tb, err, test.ShouldBeNil)
+	})
+
+	images, meta, err := cam.Images(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, images, test.ShouldHaveLength, 2)
+	test.That(t, meta.CapturedAt.Unix(), test.ShouldEqual, 101)
+	test.That(t, images[0].SourceName, test.ShouldEqual, "color")
+	test.That(t, images[1].MimeType(), test.ShouldEqual, utils.MimeTypeRawDepth)
+	bounds, err := images[0].Bounds()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bounds, test.ShouldResemble, image.Rect(0, 0, 2, 2))
+
+	images, _, err = cam.Images(ctx, []string{"depth"}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, images, test.ShouldHaveLength, 1)
+	_, _, err = cam.Images(ctx, []string{"infrared"}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	pc, err := cam.NextPointCloud(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 4)
+}
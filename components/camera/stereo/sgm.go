@@ -0,0 +1,202 @@
+package stereo
+
+import (
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// MatcherParams configure the matching of a rectified pair of images.
+type MatcherParams struct {
+	// NumDisparities is how many disparities, from 0, are searched for each pixel.
+	NumDisparities int
+	// BlockSize is the width of the odd-sized square block whose differences are the cost of matching
+	// its center pixel.
+	BlockSize int
+	// P1 and P2 penalize disparities changing by one and by more than one between neighboring pixels
+	// when aggregating costs semi-globally.
+	P1, P2 int
+	// UniquenessRatio is the percent by which the best disparity of a pixel must beat all others but
+	// its neighbors for the pixel to be matched.
+	UniquenessRatio int
+	// SemiGlobal aggregates the block costs along paths through the image before choosing the best
+	// disparities, rather than choosing them from the block costs alone.
+	SemiGlobal bool
+}
+
+// A Matcher returns the disparities of the pixels of the left of a rectified pair of grayscale images
+// of the same size, row by row, or 0 for pixels it could not match.
+type Matcher func(left, right *image.Gray, params MatcherParams) ([]float32, error)
+
+// CUDAMatcher is a Matcher on the GPU, used by cameras configured to use CUDA. It is nil unless the
+// build links one in, in which case those cameras match on the CPU instead.
+var CUDAMatcher Matcher
+
+// maxBlockSize keeps the sum of the differences of a block within a uint16.
+const maxBlockSize = 15
+
+// toGray returns img in grayscale with its bounds at the origin.
+func toGray(img image.Image) *image.Gray {
+	b := img.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(gray, gray.Bounds(), img, b.Min, draw.Src)
+	return gray
+}
+
+// MatchCPU is the Matcher on the CPU. It matches blocks by the sum of their absolute differences,
+// aggregating the costs along four paths through the image as in semi-global block matching if
+// params say to, and refines the best disparities to subpixels.
+func MatchCPU(left, right *image.Gray, params MatcherParams) ([]float32, error) {
+	if left.Bounds().Size() != right.Bounds().Size() {
+		return nil, errors.Errorf("left image is %v but right image is %v", left.Bounds().Size(), right.Bounds().Size())
+	}
+	left, right = toGray(left), toGray(right)
+	w, h := left.Bounds().Dx(), left.Bounds().Dy()
+	numDisp := params.NumDisparities
+	if numDisp <= 0 || numDisp > w {
+		return nil, errors.Errorf("cannot search %d disparities in images %d pixels wide", numDisp, w)
+	}
+	if params.BlockSize <= 0 || params.BlockSize%2 == 0 || params.BlockSize > maxBlockSize {
+		return nil, errors.Errorf("block size must be odd and at most %d but is %d", maxBlockSize, params.BlockSize)
+	}
+
+	costs := blockCosts(left, right, numDisp, params.BlockSize)
+	totals := make([]uint32, len(costs))
+	if params.SemiGlobal {
+		aggregate(costs, totals, w, h, numDisp, int32(params.P1), int32(params.P2))
+	} else {
+		for i, c := range costs {
+			totals[i] = uint32(c)
+		}
+	}
+	return selectDisparities(totals, w, h, numDisp, params.UniquenessRatio), nil
+}
+
+// blockCosts returns the sum of the absolute differences of the block around each left pixel and
+// the block of the right pixel at each disparity, indexed by pixel then disparity. Blocks are
+// clipped to the image and their sums scaled up to the full block, and right pixels off the image
+// differ as much as can be.
+func blockCosts(left, right *image.Gray, numDisp, blockSize int) []uint16 {
+	w, h := left.Bounds().Dx(), left.Bounds().Dy()
+	radius := blockSize / 2
+	area := blockSize * blockSize
+	costs := make([]uint16, w*h*numDisp)
+	// integral holds the sums of the differences above and left of each pixel, with a row and column
+	// of zeros first.
+	integral := make([]int32, (w+1)*(h+1))
+	for d := 0; d < numDisp; d++ {
+		for y := 0; y < h; y++ {
+			var rowSum int32
+			for x := 0; x < w; x++ {
+				diff := int32(math.MaxUint8)
+				if x >= d {
+					diff = int32(left.Pix[y*left.Stride+x]) - int32(right.Pix[y*right.Stride+x-d])
+					if diff < 0 {
+						diff = -diff
+					}
+				}
+				rowSum += diff
+				integral[(y+1)*(w+1)+x+1] = integral[y*(w+1)+x+1] + rowSum
+			}
+		}
+		for y := 0; y < h; y++ {
+			y0, y1 := max(y-radius, 0), min(y+radius+1, h)
+			for x := 0; x < w; x++ {
+				x0, x1 := max(x-radius, 0), min(x+radius+1, w)
+				sum := integral[y1*(w+1)+x1] - integral[y0*(w+1)+x1] - integral[y1*(w+1)+x0] + integral[y0*(w+1)+x0]
+				costs[(y*w+x)*numDisp+d] = uint16(int(sum) * area / ((y1 - y0) * (x1 - x0)))
+			}
+		}
+	}
+	return costs
+}
+
+// aggregate adds to totals the costs aggregated along each row, both ways, and each column, both ways.
+func aggregate(costs []uint16, totals []uint32, w, h, numDisp int, p1, p2 int32) {
+	prev, cur := make([]int32, numDisp), make([]int32, numDisp)
+	for y := 0; y < h; y++ {
+		aggregatePath(costs, totals, y*w, 1, w, numDisp, p1, p2, prev, cur)
+		aggregatePath(costs, totals, y*w+w-1, -1, w, numDisp, p1, p2, prev, cur)
+	}
+	for x := 0; x < w; x++ {
+		aggregatePath(costs, totals, x, w, h, numDisp, p1, p2, prev, cur)
+		aggregatePath(costs, totals, (h-1)*w+x, -w, h, numDisp, p1, p2, prev, cur)
+	}
+}
+
+// aggregatePath adds to totals the costs aggregated along the path of n pixels from start in steps
+// of step. The cost of a disparity at a pixel is its own cost plus the least of the costs of the
+// pixel before it at the same disparity, at a neighboring disparity plus p1, and at any disparity
+// plus p2.
+func aggregatePath(costs []uint16, totals []uint32, start, step, n, numDisp int, p1, p2 int32, prev, cur []int32) {
+	for k := 0; k < n; k++ {
+		p := (start + k*step) * numDisp
+		if k == 0 {
+			for d := 0; d < numDisp; d++ {
+				cur[d] = int32(costs[p+d])
+			}
+		} else {
+			minPrev := prev[0]
+			for _, v := range prev[1:] {
+				minPrev = min(minPrev, v)
+			}
+			for d := 0; d < numDisp; d++ {
+				v := min(prev[d], minPrev+p2)
+				if d > 0 {
+					v = min(v, prev[d-1]+p1)
+				}
+				if d < numDisp-1 {
+					v = min(v, prev[d+1]+p1)
+				}
+				// subtracting the least previous cost keeps the costs from growing along the path.
+				cur[d] = int32(costs[p+d]) + v - minPrev
+			}
+		}
+		for d := 0; d < numDisp; d++ {
+			totals[p+d] += uint32(cur[d])
+		}
+		prev, cur = cur, prev
+	}
+}
+
+// selectDisparities returns the disparity of least total cost of each pixel, refined to a subpixel
+// by fitting a parabola through it and its neighbors. Pixels too near the left edge to search every
+// disparity, and those whose best disparity is not unique, are left unmatched.
+func selectDisparities(totals []uint32, w, h, numDisp, uniquenessRatio int) []float32 {
+	disparities := make([]float32, w*h)
+	for i := range disparities {
+		if i%w < numDisp-1 {
+			continue
+		}
+		s := totals[i*numDisp : (i+1)*numDisp]
+		best := 0
+		for d, v := range s {
+			if v < s[best] {
+				best = d
+			}
+		}
+		unique := true
+		for d, v := range s {
+			if (d < best-1 || d > best+1) && uint64(v)*uint64(100-uniquenessRatio) < uint64(s[best])*100 {
+				unique = false
+				break
+			}
+		}
+		if !unique {
+			continue
+		}
+		disparity := float64(best)
+		if best > 0 && best < numDisp-1 {
+			before, at, after := float64(s[best-1]), float64(s[best]), float64(s[best+1])
+			if denom := before + after - 2*at; denom > 0 {
+				disparity += (before - after) / (2 * denom)
+			}
+		}
+		if disparity > 0 {
+			disparities[i] = float32(disparity)
+		}
+	}
+	return disparities
+}
@@ -0,0 +1,369 @@
+// Package stereo implements a depth camera from a pair of calibrated cameras. It matches the images
+// of the left and right cameras, rectified by their calibration, by semi-global block matching and
+// serves the disparity and depth of the left camera's pixels, and point clouds from them.
+package stereo
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// Model is the model of stereo cameras.
+var Model = resource.DefaultModelFamily.WithModel("stereo")
+
+const (
+	leftSource      = "left"
+	disparitySource = "disparity"
+	depthSource     = "depth"
+
+	matcherSGBM  = "sgbm"
+	matcherBlock = "block"
+
+	defaultNumDisparities  = 64
+	defaultBlockSize       = 5
+	defaultUniquenessRatio = 10
+)
+
+func init() {
+	resource.RegisterComponent(camera.API, Model, resource.Registration[camera.Camera, *Config]{
+		Constructor: NewCamera,
+	})
+}
+
+// Config makes a depth camera of a pair of calibrated cameras.
+type Config struct {
+	LeftCamera  string `json:"left_camera"`
+	RightCamera string `json:"right_camera"`
+	// CameraParameters are the intrinsics of both cameras once rectified, which depth is projected with.
+	CameraParameters *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters"`
+	// LeftDistortion and RightDistortion are removed from the images of each camera to rectify them.
+	LeftDistortion  *transform.BrownConrady `json:"left_distortion_parameters,omitempty"`
+	RightDistortion *transform.BrownConrady `json:"right_distortion_parameters,omitempty"`
+	// BaselineMM is the distance between the optical centers of the cameras.
+	BaselineMM float64 `json:"baseline_mm"`
+	// NumDisparities is how many disparities are searched, 64 by default. Nearer objects have larger
+	// disparities, and pixels closer to the left edge than this are not matched.
+	NumDisparities int `json:"num_disparities,omitempty"`
+	// BlockSize is the odd width of the blocks matched, 5 by default.
+	BlockSize int `json:"block_size,omitempty"`
+	// Matcher is "sgbm", the default, for semi-global block matching or "block" for block matching alone.
+	Matcher string `json:"matcher,omitempty"`
+	// P1 and P2 are the smoothness penalties of semi-global block matching, 8 and 32 times the block
+	// area by default.
+	P1 int `json:"p1,omitempty"`
+	P2 int `json:"p2,omitempty"`
+	// UniquenessRatio is the percent by which a pixel's best disparity must beat the others, 10 by default.
+	UniquenessRatio int `json:"uniqueness_ratio,omitempty"`
+	// UseCUDA matches on the GPU if this build supports it.
+	UseCUDA bool `json:"use_cuda,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid and returns the cameras it depends on.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.LeftCamera == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "left_camera")
+	}
+	if conf.RightCamera == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "right_camera")
+	}
+	if conf.LeftCamera == conf.RightCamera {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("left_camera and right_camera must differ"))
+	}
+	if conf.CameraParameters == nil {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "intrinsic_parameters")
+	}
+	if err := conf.CameraParameters.CheckValid(); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	if conf.BaselineMM <= 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("baseline_mm must be positive"))
+	}
+	params := conf.matcherParams()
+	if params.NumDisparities <= 0 || params.NumDisparities > conf.CameraParameters.Width {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.Errorf("num_disparities must be positive and at most the width of %d", conf.CameraParameters.Width))
+	}
+	if params.BlockSize < 0 || params.BlockSize%2 == 0 || params.BlockSize > maxBlockSize {
+		return nil, nil, resource.NewConfigValidationError(path, errors.Errorf("block_size must be odd and at most %d", maxBlockSize))
+	}
+	if conf.Matcher != "" && conf.Matcher != matcherSGBM && conf.Matcher != matcherBlock {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.Errorf("matcher must be %q or %q but is %q", matcherSGBM, matcherBlock, conf.Matcher))
+	}
+	if params.P1 < 0 || params.P2 <= params.P1 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("p2 must be greater than p1, which must not be negative"))
+	}
+	if params.UniquenessRatio < 0 || params.UniquenessRatio >= 100 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("uniqueness_ratio must be a percent below 100"))
+	}
+	return []string{conf.LeftCamera, conf.RightCamera}, nil, nil
+}
+
+// matcherParams returns the matching configured, with defaults for what is not.
+func (conf *Config) matcherParams() MatcherParams {
+	params := MatcherParams{
+		NumDisparities:  conf.NumDisparities,
+		BlockSize:       conf.BlockSize,
+		P1:              conf.P1,
+		P2:              conf.P2,
+		UniquenessRatio: conf.UniquenessRatio,
+		SemiGlobal:      conf.Matcher != matcherBlock,
+	}
+	if params.NumDisparities == 0 {
+		params.NumDisparities = defaultNumDisparities
+	}
+	if params.BlockSize == 0 {
+		params.BlockSize = defaultBlockSize
+	}
+	area := params.BlockSize * params.BlockSize
+	if params.P1 == 0 {
+		params.P1 = 8 * area
+	}
+	if params.P2 == 0 {
+		params.P2 = 32 * area
+	}
+	if params.UniquenessRatio == 0 {
+		params.UniquenessRatio = defaultUniquenessRatio
+	}
+	return params
+}
+
+type stereoCamera struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	left, right camera.Camera
+	conf        *Config
+	params      MatcherParams
+	matcher     Matcher
+	geometries  []spatialmath.Geometry
+	logger      logging.Logger
+}
+
+// NewCamera returns a depth camera of a pair of calibrated cameras.
+func NewCamera(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (camera.Camera, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	left, err := camera.FromDependencies(deps, newConf.LeftCamera)
+	if err != nil {
+		return nil, err
+	}
+	right, err := camera.FromDependencies(deps, newConf.RightCamera)
+	if err != nil {
+		return nil, err
+	}
+	c := &stereoCamera{
+		Named:      conf.ResourceName().AsNamed(),
+		left:       left,
+		right:      right,
+		conf:       newConf,
+		params:     newConf.matcherParams(),
+		matcher:    MatchCPU,
+		geometries: []spatialmath.Geometry{},
+		logger:     logger,
+	}
+	if newConf.UseCUDA {
+		if CUDAMatcher != nil {
+			c.matcher = CUDAMatcher
+		} else {
+			logger.Warn("use_cuda is set but this build cannot match on the GPU, so matching on the CPU")
+		}
+	}
+	if conf.Frame != nil && conf.Frame.Geometry != nil {
+		geometry, err := conf.Frame.Geometry.ParseConfig()
+		if err != nil {
+			return nil, err
+		}
+		c.geometries = []spatialmath.Geometry{geometry}
+	}
+	return c, nil
+}
+
+// A frame is a rectified pair of images, and the disparities and depths matched from them if asked.
+type frame struct {
+	left, right *rimage.Image
+	disparities []float32
+	depth       *rimage.DepthMap
+	capturedAt  time.Time
+}
+
+// rectify returns img undistorted by distortion, if any, after checking it is the size the
+// intrinsics expect.
+func (c *stereoCamera) rectify(img image.Image, distortion *transform.BrownConrady) (*rimage.Image, error) {
+	intrinsics := c.conf.CameraParameters
+	converted := rimage.ConvertImage(img)
+	if converted.Width() != intrinsics.Width || converted.Height() != intrinsics.Height {
+		return nil, errors.Errorf("image is %dx%d but the intrinsics are %dx%d",
+			converted.Width(), converted.Height(), intrinsics.Width, intrinsics.Height)
+	}
+	if distortion == nil {
+		return converted, nil
+	}
+	model := &transform.PinholeCameraModel{PinholeCameraIntrinsics: intrinsics, Distortion: distortion}
+	return model.UndistortImage(converted)
+}
+
+// capture captures from both cameras at once and rectifies their images, matching them if match is set.
+func (c *stereoCamera) capture(ctx context.Context, match bool, extra map[string]interface{}) (*frame, error) {
+	var leftImg, rightImg image.Image
+	var leftErr, rightErr error
+	start := time.Now()
+	done := make(chan struct{})
+	utils.PanicCapturingGo(func() {
+		defer close(done)
+		rightImg, rightErr = camera.DecodeImageFromCamera(ctx, c.right, nil, extra)
+	})
+	leftImg, leftErr = camera.DecodeImageFromCamera(ctx, c.left, nil, extra)
+	<-done
+	if leftErr != nil {
+		return nil, errors.Wrapf(leftErr, "failed to capture from left camera %s", c.conf.LeftCamera)
+	}
+	if rightErr != nil {
+		return nil, errors.Wrapf(rightErr, "failed to capture from right camera %s", c.conf.RightCamera)
+	}
+	f := &frame{capturedAt: start.Add(time.Since(start) / 2)}
+	var err error
+	if f.left, err = c.rectify(leftImg, c.conf.LeftDistortion); err != nil {
+		return nil, errors.Wrapf(err, "left camera %s", c.conf.LeftCamera)
+	}
+	if !match {
+		return f, nil
+	}
+	if f.right, err = c.rectify(rightImg, c.conf.RightDistortion); err != nil {
+		return nil, errors.Wrapf(err, "right camera %s", c.conf.RightCamera)
+	}
+	if f.disparities, err = c.matcher(toGray(f.left), toGray(f.right), c.params); err != nil {
+		return nil, err
+	}
+	f.depth = depthFromDisparities(f.disparities, f.left.Width(), f.left.Height(), c.conf.CameraParameters.Fx, c.conf.BaselineMM)
+	return f, nil
+}
+
+// depthFromDisparities returns the depth of each pixel of the given disparity, focal length and
+// baseline, leaving unmatched pixels and those too far to be held by a depth map without a depth.
+func depthFromDisparities(disparities []float32, w, h int, fx, baselineMM float64) *rimage.DepthMap {
+	dm := rimage.NewEmptyDepthMap(w, h)
+	for i, disparity := range disparities {
+		if disparity <= 0 {
+			continue
+		}
+		depth := math.Round(fx * baselineMM / float64(disparity))
+		if depth > float64(rimage.MaxDepth) {
+			continue
+		}
+		dm.Set(i%w, i/w, rimage.Depth(depth))
+	}
+	return dm
+}
+
+// disparityImage returns the disparities scaled from none to the most searched as black to white.
+func (c *stereoCamera) disparityImage(f *frame) *image.Gray {
+	w, h := f.left.Width(), f.left.Height()
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	scale := float64(math.MaxUint8) / float64(c.params.NumDisparities-1)
+	if c.params.NumDisparities == 1 {
+		scale = 0
+	}
+	for i, disparity := range f.disparities {
+		img.SetGray(i%w, i/w, color.Gray{Y: uint8(math.Min(math.Round(float64(disparity)*scale), math.MaxUint8))})
+	}
+	return img
+}
+
+// Images returns the rectified left image, the disparity of its pixels and their depth.
+func (c *stereoCamera) Images(
+	ctx context.Context,
+	filterSourceNames []string,
+	extra map[string]interface{},
+) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+	for _, name := range filterSourceNames {
+		if name != leftSource && name != disparitySource && name != depthSource {
+			return nil, resource.ResponseMetadata{}, errors.Errorf("invalid source name: %s", name)
+		}
+	}
+	wanted := func(source string) bool {
+		return len(filterSourceNames) == 0 || slices.Contains(filterSourceNames, source)
+	}
+	f, err := c.capture(ctx, wanted(disparitySource) || wanted(depthSource), extra)
+	if err != nil {
+		return nil, resource.ResponseMetadata{}, err
+	}
+	var images []camera.NamedImage
+	add := func(img image.Image, source, mimeType string) error {
+		named, err := camera.NamedImageFromImage(img, source, mimeType, data.Annotations{})
+		if err != nil {
+			return err
+		}
+		images = append(images, named)
+		return nil
+	}
+	if wanted(leftSource) {
+		if err := add(f.left, leftSource, rutils.MimeTypeJPEG); err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+	}
+	if wanted(disparitySource) {
+		if err := add(c.disparityImage(f), disparitySource, rutils.MimeTypePNG); err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+	}
+	if wanted(depthSource) {
+		if err := add(f.depth, depthSource, rutils.MimeTypeRawDepth); err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+	}
+	return images, resource.ResponseMetadata{CapturedAt: f.capturedAt}, nil
+}
+
+// NextPointCloud projects the depth of the left camera's pixels, colored by its rectified image.
+func (c *stereoCamera) NextPointCloud(ctx context.Context, extra map[string]interface{}) (pointcloud.PointCloud, error) {
+	f, err := c.capture(ctx, true, extra)
+	if err != nil {
+		return nil, err
+	}
+	return c.conf.CameraParameters.RGBDToPointCloud(f.left, f.depth)
+}
+
+// Properties returns the intrinsics of the rectified cameras. Its images are undistorted.
+func (c *stereoCamera) Properties(ctx context.Context) (camera.Properties, error) {
+	return camera.Properties{
+		SupportsPCD:     true,
+		ImageType:       camera.ColorStream,
+		IntrinsicParams: c.conf.CameraParameters,
+		MimeTypes:       []string{rutils.MimeTypeJPEG, rutils.MimeTypePNG, rutils.MimeTypeRawDepth},
+	}, nil
+}
+
+// Geometries returns the geometry of the camera's frame.
+func (c *stereoCamera) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
+	return c.geometries, nil
+}
+
+// DoCommand is unimplemented.
+func (c *stereoCamera) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return nil, resource.ErrDoUnimplemented
+}
+
+// Close does nothing, as the cameras matched are closed by their owner.
+func (c *stereoCamera) Close(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,193 @@
+package stereo
+
+import (
+	"context"
+	"image"
+	"math/rand"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	testWidth     = 64
+	testHeight    = 24
+	testDisparity = 5
+)
+
+// testPair returns a textured right image and the left image of the same plane at the test disparity.
+func testPair() (*image.Gray, *image.Gray) {
+	rng := rand.New(rand.NewSource(1))
+	left := image.NewGray(image.Rect(0, 0, testWidth, testHeight))
+	right := image.NewGray(image.Rect(0, 0, testWidth, testHeight))
+	for i := range right.Pix {
+		right.Pix[i] = uint8(rng.Intn(256))
+		left.Pix[i] = uint8(rng.Intn(256))
+	}
+	for y := 0; y < testHeight; y++ {
+		for x := testDisparity; x < testWidth; x++ {
+			left.Pix[y*left.Stride+x] = right.Pix[y*right.Stride+x-testDisparity]
+		}
+	}
+	return left, right
+}
+
+// interior reports whether the pixel at i is matched from whole blocks of the test images.
+func interior(i, numDisp, blockSize int) bool {
+	x, y := i%testWidth, i/testWidth
+	radius := blockSize / 2
+	return x >= numDisp-1+radius && x < testWidth-radius && y >= radius && y < testHeight-radius
+}
+
+func TestMatchCPU(t *testing.T) {
+	left, right := testPair()
+	for _, semiGlobal := range []bool{true, false} {
+		params := (&Config{NumDisparities: 16}).matcherParams()
+		params.SemiGlobal = semiGlobal
+		disparities, err := MatchCPU(left, right, params)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, disparities, test.ShouldHaveLength, testWidth*testHeight)
+		for i, disparity := range disparities {
+			if i%testWidth < params.NumDisparities-1 {
+				test.That(t, disparity, test.ShouldEqual, 0)
+			} else if interior(i, params.NumDisparities, params.BlockSize) {
+				test.That(t, disparity, test.ShouldAlmostEqual, testDisparity, 0.25)
+			}
+		}
+	}
+
+	_, err := MatchCPU(left, image.NewGray(image.Rect(0, 0, 2, 2)), MatcherParams{NumDisparities: 1, BlockSize: 1})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = MatchCPU(left, right, MatcherParams{NumDisparities: 16, BlockSize: 4})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = MatchCPU(left, right, MatcherParams{NumDisparities: testWidth + 1, BlockSize: 5})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestValidate(t *testing.T) {
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: testWidth, Height: testHeight, Fx: 100, Fy: 100, Ppx: 32, Ppy: 12}
+	valid := func() *Config {
+		return &Config{LeftCamera: "left", RightCamera: "right", CameraParameters: intrinsics, BaselineMM: 60}
+	}
+	deps, _, err := valid().Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"left", "right"})
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.LeftCamera = "" },
+		func(c *Config) { c.RightCamera = "left" },
+		func(c *Config) { c.CameraParameters = nil },
+		func(c *Config) { c.CameraParameters = &transform.PinholeCameraIntrinsics{Width: -1} },
+		func(c *Config) { c.BaselineMM = 0 },
+		func(c *Config) { c.NumDisparities = testWidth + 1 },
+		func(c *Config) { c.BlockSize = 4 },
+		func(c *Config) { c.BlockSize = 17 },
+		func(c *Config) { c.Matcher = "census" },
+		func(c *Config) { c.P1, c.P2 = 100, 50 },
+		func(c *Config) { c.UniquenessRatio = 100 },
+	} {
+		conf := valid()
+		mutate(conf)
+		_, _, err := conf.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func newTestCamera(name string, img image.Image) *inject.Camera {
+	cam := inject.NewCamera(name)
+	cam.ImagesFunc = func(
+		ctx context.Context,
+		filterSourceNames []string,
+		extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		named, err := camera.NamedImageFromImage(img, "color", utils.MimeTypePNG, data.Annotations{})
+		return []camera.NamedImage{named}, resource.ResponseMetadata{}, err
+	}
+	return cam
+}
+
+func TestCamera(t *testing.T) {
+	ctx := context.Background()
+	left, right := testPair()
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: testWidth, Height: testHeight, Fx: 100, Fy: 100, Ppx: 32, Ppy: 12}
+	conf := &Config{
+		LeftCamera:       "left",
+		RightCamera:      "right",
+		CameraParameters: intrinsics,
+		BaselineMM:       60,
+		NumDisparities:   16,
+		UseCUDA:          true,
+	}
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	deps := resource.Dependencies{
+		camera.Named("left"):  newTestCamera("left", left),
+		camera.Named("right"): newTestCamera("right", right),
+	}
+	cam, err := NewCamera(ctx, deps, resource.Config{
+		Name:                "stereo",
+		API:                 camera.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, cam.Close(ctx), test.ShouldBeNil)
+	}()
+
+	props, err := cam.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.SupportsPCD, test.ShouldBeTrue)
+	test.That(t, props.IntrinsicParams, test.ShouldResemble, intrinsics)
+
+	images, meta, err := cam.Images(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, meta.CapturedAt.IsZero(), test.ShouldBeFalse)
+	test.That(t, images, test.ShouldHaveLength, 3)
+	test.That(t, images[0].SourceName, test.ShouldEqual, "left")
+	test.That(t, images[1].SourceName, test.ShouldEqual, "disparity")
+	test.That(t, images[1].MimeType(), test.ShouldEqual, utils.MimeTypePNG)
+	test.That(t, images[2].SourceName, test.ShouldEqual, "depth")
+	test.That(t, images[2].MimeType(), test.ShouldEqual, utils.MimeTypeRawDepth)
+
+	img, err := images[2].Image(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	dm, err := rimage.ConvertImageToDepthMap(ctx, img)
+	test.That(t, err, test.ShouldBeNil)
+	wantDepth := intrinsics.Fx * conf.BaselineMM / testDisparity
+	matched := 0
+	for i := 0; i < testWidth*testHeight; i++ {
+		if !interior(i, conf.NumDisparities, defaultBlockSize) {
+			continue
+		}
+		test.That(t, float64(dm.GetDepth(i%testWidth, i/testWidth)), test.ShouldAlmostEqual, wantDepth, 0.05*wantDepth)
+		matched++
+	}
+	test.That(t, matched, test.ShouldBeGreaterThan, 0)
+
+	images, _, err = cam.Images(ctx, []string{"left"}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, images, test.ShouldHaveLength, 1)
+	_, _, err = cam.Images(ctx, []string{"infrared"}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	pc, err := cam.NextPointCloud(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldBeGreaterThanOrEqualTo, matched)
+}
+
+func TestDepthFromDisparities(t *testing.T) {
+	dm := depthFromDisparities([]float32{0, 2, 0.001}, 3, 1, 100, 50)
+	test.That(t, dm.GetDepth(0, 0), test.ShouldEqual, rimage.Depth(0))
+	test.That(t, dm.GetDepth(1, 0), test.ShouldEqual, rimage.Depth(2500))
+	test.That(t, dm.GetDepth(2, 0), test.ShouldEqual, rimage.Depth(0))
+}
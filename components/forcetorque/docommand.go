@@ -0,0 +1,123 @@
+package forcetorque
+
+import (
+	"context"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/resource"
+)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoGetWrench = "get_wrench"
+	DoTare      = "tare"
+)
+
+type vectorMessage struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// wrenchMessage is the form a wrench takes in DoCommand responses.
+type wrenchMessage struct {
+	Force  vectorMessage `json:"force"`
+	Torque vectorMessage `json:"torque"`
+	Frame  string        `json:"frame,omitempty"`
+}
+
+type commandMessage struct {
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type getWrenchCommand struct {
+	commandMessage
+	GetWrench bool `json:"get_wrench"`
+}
+
+type tareCommand struct {
+	commandMessage
+	Tare bool `json:"tare"`
+}
+
+func wrenchToMessage(w Wrench) wrenchMessage {
+	return wrenchMessage{
+		Force:  vectorMessage(w.Force),
+		Torque: vectorMessage(w.Torque),
+		Frame:  w.Frame,
+	}
+}
+
+func (m wrenchMessage) wrench() Wrench {
+	return Wrench{Force: r3.Vector(m.Force), Torque: r3.Vector(m.Torque), Frame: m.Frame}
+}
+
+// HandleForceTorqueCommand services the force torque sensor DoCommand keys using the given sensor,
+// so that force torque sensors can be used through DoCommand by callers that only have a generic
+// resource handle, such as the client of a sensor provided by a module. It returns false if cmd does
+// not contain any of them so that it can be chained from a DoCommand implementation.
+func HandleForceTorqueCommand(
+	ctx context.Context,
+	sensor ForceTorqueSensor,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	switch {
+	case cmd[DoTare] != nil:
+		req, err := resource.DecodeDoCommand[tareCommand](cmd)
+		if err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, sensor.Tare(ctx, req.Extra)
+	case cmd[DoGetWrench] != nil:
+		req, err := resource.DecodeDoCommand[getWrenchCommand](cmd)
+		if err != nil {
+			return nil, true, err
+		}
+		w, err := sensor.Wrench(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(wrenchToMessage(w))
+		return resp, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// FromResource returns a ForceTorqueSensor that is used through the DoCommand of res, which must
+// handle the force torque sensor DoCommand keys as HandleForceTorqueCommand does. It lets force
+// torque sensors be provided by modules as generic components or sensors.
+func FromResource(res resource.Resource) ForceTorqueSensor {
+	if sensor, ok := res.(ForceTorqueSensor); ok {
+		return sensor
+	}
+	return &doCommandSensor{Resource: res}
+}
+
+type doCommandSensor struct {
+	resource.Resource
+}
+
+func (s *doCommandSensor) Wrench(ctx context.Context, extra map[string]interface{}) (Wrench, error) {
+	resp, err := resource.DoCommandAs[getWrenchCommand, wrenchMessage](
+		ctx, s, getWrenchCommand{commandMessage: commandMessage{Extra: extra}, GetWrench: true})
+	if err != nil {
+		return Wrench{}, err
+	}
+	return resp.wrench(), nil
+}
+
+func (s *doCommandSensor) Tare(ctx context.Context, extra map[string]interface{}) error {
+	_, err := resource.DoCommandAs[tareCommand, map[string]interface{}](
+		ctx, s, tareCommand{commandMessage: commandMessage{Extra: extra}, Tare: true})
+	return err
+}
+
+func (s *doCommandSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	w, err := s.Wrench(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return WrenchToReadings(w), nil
+}
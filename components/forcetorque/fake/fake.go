@@ -0,0 +1,159 @@
+// Package fake implements a fake force torque sensor.
+package fake
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/forcetorque"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("fake")
+
+// DoSetWrench is the DoCommand key that sets the raw wrench the fake sensor reads, to simulate
+// contact, as a map of "force" and "torque" to arrays of three numbers.
+const DoSetWrench = "set_wrench"
+
+func init() {
+	resource.RegisterComponent(forcetorque.API, model, resource.Registration[forcetorque.ForceTorqueSensor, *Config]{
+		Constructor: NewForceTorqueSensor,
+	})
+}
+
+// Config is the config for a fake force torque sensor.
+type Config struct {
+	// Force and Torque are the raw wrench the sensor reads until another is set, such as the weight
+	// of a tool.
+	Force  []float64 `json:"force,omitempty"`
+	Torque []float64 `json:"torque,omitempty"`
+	// Noise is the standard deviation of the noise added to every component of readings.
+	Noise float64 `json:"noise,omitempty"`
+	// FilterCutoffHz is the cutoff frequency of the low-pass filter of readings, which is disabled
+	// if unspecified.
+	FilterCutoffHz float64 `json:"filter_cutoff_hz,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if len(conf.Force) != 0 && len(conf.Force) != 3 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("force must have 3 components"))
+	}
+	if len(conf.Torque) != 0 && len(conf.Torque) != 3 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("torque must have 3 components"))
+	}
+	if conf.Noise < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("noise cannot be negative"))
+	}
+	if conf.FilterCutoffHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("filter_cutoff_hz cannot be negative"))
+	}
+	return nil, nil, nil
+}
+
+// ForceTorqueSensor is a fake force torque sensor whose raw wrench can be set to simulate contact.
+type ForceTorqueSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	conditioner *forcetorque.Conditioner
+	noise       float64
+
+	mu  sync.Mutex
+	raw forcetorque.Wrench
+	rnd *rand.Rand
+}
+
+// NewForceTorqueSensor instantiates a new force torque sensor of the fake model type.
+func NewForceTorqueSensor(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (forcetorque.ForceTorqueSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	return &ForceTorqueSensor{
+		Named:       conf.ResourceName().AsNamed(),
+		conditioner: forcetorque.NewConditioner(newConf.FilterCutoffHz),
+		noise:       newConf.Noise,
+		raw:         forcetorque.Wrench{Force: vectorFromSlice(newConf.Force), Torque: vectorFromSlice(newConf.Torque)},
+		//nolint:gosec
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+func vectorFromSlice(v []float64) r3.Vector {
+	if len(v) != 3 {
+		return r3.Vector{}
+	}
+	return r3.Vector{X: v[0], Y: v[1], Z: v[2]}
+}
+
+// SetWrench sets the raw wrench the sensor reads.
+func (s *ForceTorqueSensor) SetWrench(raw forcetorque.Wrench) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.raw = raw
+}
+
+// Wrench returns the raw wrench, with noise, filtered and less the tare.
+func (s *ForceTorqueSensor) Wrench(ctx context.Context, extra map[string]interface{}) (forcetorque.Wrench, error) {
+	s.mu.Lock()
+	raw := s.raw
+	if s.noise > 0 {
+		noise := func() r3.Vector {
+			return r3.Vector{X: s.rnd.NormFloat64(), Y: s.rnd.NormFloat64(), Z: s.rnd.NormFloat64()}.Mul(s.noise)
+		}
+		raw.Force = raw.Force.Add(noise())
+		raw.Torque = raw.Torque.Add(noise())
+	}
+	s.mu.Unlock()
+	raw.Frame = s.Name().ShortName()
+	return s.conditioner.Condition(raw, time.Now()), nil
+}
+
+// Tare takes the current wrench as the bias of readings.
+func (s *ForceTorqueSensor) Tare(ctx context.Context, extra map[string]interface{}) error {
+	if _, err := s.Wrench(ctx, extra); err != nil {
+		return err
+	}
+	return s.conditioner.Tare()
+}
+
+// Readings returns the wrench in the form produced by forcetorque.WrenchToReadings.
+func (s *ForceTorqueSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	w, err := s.Wrench(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return forcetorque.WrenchToReadings(w), nil
+}
+
+type setWrenchCommand struct {
+	SetWrench struct {
+		Force  []float64 `json:"force"`
+		Torque []float64 `json:"torque"`
+	} `json:"set_wrench"`
+}
+
+// DoCommand handles the force torque sensor DoCommand keys and DoSetWrench.
+func (s *ForceTorqueSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd[DoSetWrench] != nil {
+		req, err := resource.DecodeDoCommand[setWrenchCommand](cmd)
+		if err != nil {
+			return nil, err
+		}
+		s.SetWrench(forcetorque.Wrench{Force: vectorFromSlice(req.SetWrench.Force), Torque: vectorFromSlice(req.SetWrench.Torque)})
+		return map[string]interface{}{}, nil
+	}
+	if resp, ok, err := forcetorque.HandleForceTorqueCommand(ctx, s, cmd); ok {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
@@ -0,0 +1,217 @@
+// Package forcetorque defines force torque sensors, which measure the forces and torques applied to
+// them, such as those mounted between an arm and its gripper to sense contact.
+package forcetorque
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[ForceTorqueSensor]{})
+}
+
+// SubtypeName is a constant that identifies the component resource API string.
+const SubtypeName = "force_torque_sensor"
+
+// API is a variable that identifies the component resource API.
+var API = resource.APINamespaceRDK.WithComponentType(SubtypeName)
+
+// Named is a helper for getting the named force torque sensor's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// A ForceTorqueSensor measures the forces and torques applied to it.
+type ForceTorqueSensor interface {
+	resource.Sensor
+	resource.Resource
+
+	// Wrench returns the forces and torques applied to the sensor, less its tare and smoothed by its
+	// filter, in the sensor's frame.
+	Wrench(ctx context.Context, extra map[string]interface{}) (Wrench, error)
+
+	// Tare takes the forces and torques applied to the sensor now, such as the weight of a tool, as
+	// its bias, so that they read as zero from then on.
+	Tare(ctx context.Context, extra map[string]interface{}) error
+}
+
+// Deprecated: FromRobot is a helper for getting the named ForceTorqueSensor from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (ForceTorqueSensor, error) {
+	return robot.ResourceFromRobot[ForceTorqueSensor](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named ForceTorqueSensor from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (ForceTorqueSensor, error) {
+	return resource.FromProvider[ForceTorqueSensor](provider, Named(name))
+}
+
+// NamesFromRobot is a helper for getting all force torque sensor names from the given Robot.
+func NamesFromRobot(r robot.Robot) []string {
+	return robot.NamesByAPI(r, API)
+}
+
+// A Wrench is a force and a torque, expressed in a reference frame.
+type Wrench struct {
+	// Force is in newtons.
+	Force r3.Vector
+	// Torque is in newton meters.
+	Torque r3.Vector
+	// Frame is the name of the reference frame the wrench is expressed in, which for readings of a
+	// sensor is the sensor's frame.
+	Frame string
+}
+
+// Add returns the sum of the wrenches, in the frame of w.
+func (w Wrench) Add(other Wrench) Wrench {
+	return Wrench{Force: w.Force.Add(other.Force), Torque: w.Torque.Add(other.Torque), Frame: w.Frame}
+}
+
+// Sub returns the difference of the wrenches, in the frame of w.
+func (w Wrench) Sub(other Wrench) Wrench {
+	return Wrench{Force: w.Force.Sub(other.Force), Torque: w.Torque.Sub(other.Torque), Frame: w.Frame}
+}
+
+// Magnitudes returns the magnitudes of the force and the torque.
+func (w Wrench) Magnitudes() (float64, float64) {
+	return w.Force.Norm(), w.Torque.Norm()
+}
+
+// Transform returns the wrench expressed in the frame named frame, given the pose of w's frame in
+// it, with its point in millimeters as is usual for poses. The torque gains the moment of the force
+// about the origin of the new frame.
+func (w Wrench) Transform(pose spatialmath.Pose, frame string) Wrench {
+	rotation := pose.Orientation().RotationMatrix()
+	force := rotation.Mul(w.Force)
+	// lever is the point in meters, so that the torque stays in newton meters.
+	lever := pose.Point().Mul(0.001)
+	return Wrench{
+		Force:  force,
+		Torque: rotation.Mul(w.Torque).Add(lever.Cross(force)),
+		Frame:  frame,
+	}
+}
+
+// The keys of the readings of force torque sensors.
+const (
+	forceReadingKey  = "force"
+	torqueReadingKey = "torque"
+	frameReadingKey  = "frame"
+)
+
+// WrenchToReadings converts a Wrench into the readings of a force torque sensor.
+func WrenchToReadings(w Wrench) map[string]interface{} {
+	readings := map[string]interface{}{forceReadingKey: w.Force, torqueReadingKey: w.Torque}
+	if w.Frame != "" {
+		readings[frameReadingKey] = w.Frame
+	}
+	return readings
+}
+
+// WrenchFromReadings converts the readings of a force torque sensor back into a Wrench.
+func WrenchFromReadings(readings map[string]interface{}) (Wrench, error) {
+	force, ok := readings[forceReadingKey].(r3.Vector)
+	if !ok {
+		return Wrench{}, errors.Errorf("expected reading %s to be a vector but got %T", forceReadingKey, readings[forceReadingKey])
+	}
+	torque, ok := readings[torqueReadingKey].(r3.Vector)
+	if !ok {
+		return Wrench{}, errors.Errorf("expected reading %s to be a vector but got %T", torqueReadingKey, readings[torqueReadingKey])
+	}
+	frame, _ := readings[frameReadingKey].(string)
+	return Wrench{Force: force, Torque: torque, Frame: frame}, nil
+}
+
+// A LowPassFilter smooths wrenches with a first order low-pass filter, whose smoothing adapts to the
+// time between readings.
+type LowPassFilter struct {
+	cutoffHz float64
+	last     Wrench
+	lastTime time.Time
+}
+
+// NewLowPassFilter returns a filter that passes changes slower than cutoffHz. A cutoffHz of zero
+// disables filtering.
+func NewLowPassFilter(cutoffHz float64) *LowPassFilter {
+	return &LowPassFilter{cutoffHz: cutoffHz}
+}
+
+// Update filters w, read at time t, and returns the filtered wrench.
+func (f *LowPassFilter) Update(w Wrench, t time.Time) Wrench {
+	if f.cutoffHz <= 0 || f.lastTime.IsZero() {
+		f.last, f.lastTime = w, t
+		return w
+	}
+	dt := t.Sub(f.lastTime).Seconds()
+	if dt <= 0 {
+		return f.last
+	}
+	rc := 1 / (2 * math.Pi * f.cutoffHz)
+	alpha := dt / (rc + dt)
+	f.last = Wrench{
+		Force:  f.last.Force.Add(w.Force.Sub(f.last.Force).Mul(alpha)),
+		Torque: f.last.Torque.Add(w.Torque.Sub(f.last.Torque).Mul(alpha)),
+		Frame:  w.Frame,
+	}
+	f.lastTime = t
+	return f.last
+}
+
+// Reset forgets the wrenches filtered so far, so that the next one passes unfiltered.
+func (f *LowPassFilter) Reset() {
+	f.lastTime = time.Time{}
+}
+
+// A Conditioner tares and filters the raw readings of a force torque sensor, so that sensors only
+// need to implement reading raw wrenches.
+type Conditioner struct {
+	mu       sync.Mutex
+	filter   *LowPassFilter
+	filtered *Wrench
+	bias     Wrench
+}
+
+// NewConditioner returns a Conditioner that filters readings with a low-pass filter of cutoffHz,
+// which is disabled if zero.
+func NewConditioner(cutoffHz float64) *Conditioner {
+	return &Conditioner{filter: NewLowPassFilter(cutoffHz)}
+}
+
+// Condition filters raw, read at time t, and subtracts the tare from it.
+func (c *Conditioner) Condition(raw Wrench, t time.Time) Wrench {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	filtered := c.filter.Update(raw, t)
+	c.filtered = &filtered
+	return filtered.Sub(c.bias)
+}
+
+// Tare takes the last filtered reading as the bias subtracted from readings from then on.
+func (c *Conditioner) Tare() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.filtered == nil {
+		return errors.New("cannot tare before the sensor has been read")
+	}
+	c.bias = *c.filtered
+	return nil
+}
+
+// Bias returns the bias subtracted from readings, which is zero until the sensor is tared.
+func (c *Conditioner) Bias() Wrench {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.bias
+}
@@ -0,0 +1,131 @@
+package forcetorque_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/forcetorque"
+	"go.viam.com/rdk/components/forcetorque/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/protoutils"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func newFake(t *testing.T, conf *fake.Config) *fake.ForceTorqueSensor {
+	t.Helper()
+	sensor, err := fake.NewForceTorqueSensor(context.Background(), nil, resource.Config{
+		Name:                "ft",
+		API:                 forcetorque.API,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return sensor.(*fake.ForceTorqueSensor)
+}
+
+func TestWrenchTransform(t *testing.T) {
+	w := forcetorque.Wrench{Force: r3.Vector{X: 1}, Frame: "ft"}
+
+	// a sensor 100mm along y from the origin, rotated 90 degrees about z.
+	pose := spatialmath.NewPose(r3.Vector{Y: 100}, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: 90})
+	moved := w.Transform(pose, "world")
+	test.That(t, moved.Frame, test.ShouldEqual, "world")
+	test.That(t, moved.Force.Sub(r3.Vector{Y: 1}).Norm(), test.ShouldBeLessThan, 1e-9)
+	test.That(t, moved.Torque.Norm(), test.ShouldBeLessThan, 1e-9)
+
+	// a force along x at 100mm along y has a moment about -z.
+	moved = w.Transform(spatialmath.NewPoseFromPoint(r3.Vector{Y: 100}), "world")
+	test.That(t, moved.Torque.Sub(r3.Vector{Z: -0.1}).Norm(), test.ShouldBeLessThan, 1e-9)
+}
+
+func TestReadings(t *testing.T) {
+	w := forcetorque.Wrench{Force: r3.Vector{X: 1, Y: 2, Z: 3}, Torque: r3.Vector{Z: 0.5}, Frame: "ft"}
+	readings := forcetorque.WrenchToReadings(w)
+
+	// readings survive conversion to and from their wire form.
+	pbReadings, err := protoutils.ReadingGoToProto(readings)
+	test.That(t, err, test.ShouldBeNil)
+	readings, err = protoutils.ReadingProtoToGo(pbReadings)
+	test.That(t, err, test.ShouldBeNil)
+	got, err := forcetorque.WrenchFromReadings(readings)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, got, test.ShouldResemble, w)
+
+	_, err = forcetorque.WrenchFromReadings(map[string]interface{}{"force": 1.0})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestLowPassFilter(t *testing.T) {
+	start := time.Now()
+	step := forcetorque.Wrench{Force: r3.Vector{Z: 10}}
+
+	cutoffHz := 1.0
+	f := forcetorque.NewLowPassFilter(cutoffHz)
+	test.That(t, f.Update(forcetorque.Wrench{}, start), test.ShouldResemble, forcetorque.Wrench{})
+	// after one time constant a step has risen by 1-1/e.
+	rc := time.Duration(float64(time.Second) / (2 * math.Pi * cutoffHz))
+	var w forcetorque.Wrench
+	for i := 1; i <= 1000; i++ {
+		w = f.Update(step, start.Add(time.Duration(i)*rc/1000))
+	}
+	test.That(t, w.Force.Z, test.ShouldAlmostEqual, 6.32, 0.01)
+
+	f.Reset()
+	test.That(t, f.Update(step, start), test.ShouldResemble, step)
+
+	unfiltered := forcetorque.NewLowPassFilter(0)
+	unfiltered.Update(forcetorque.Wrench{}, start)
+	test.That(t, unfiltered.Update(step, start.Add(time.Millisecond)), test.ShouldResemble, step)
+}
+
+func TestFakeTare(t *testing.T) {
+	ctx := context.Background()
+	sensor := newFake(t, &fake.Config{Force: []float64{0, 0, -5}})
+
+	w, err := sensor.Wrench(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, w.Force, test.ShouldResemble, r3.Vector{Z: -5})
+	test.That(t, w.Frame, test.ShouldEqual, "ft")
+
+	// taring zeroes the weight of the tool, leaving only contact.
+	test.That(t, sensor.Tare(ctx, nil), test.ShouldBeNil)
+	sensor.SetWrench(forcetorque.Wrench{Force: r3.Vector{X: 2, Z: -5}})
+	w, err = sensor.Wrench(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, w.Force, test.ShouldResemble, r3.Vector{X: 2})
+
+	readings, err := sensor.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["force"], test.ShouldResemble, r3.Vector{X: 2})
+}
+
+func TestFromResource(t *testing.T) {
+	ctx := context.Background()
+	sensor := newFake(t, &fake.Config{Torque: []float64{0, 0, 1}})
+
+	_, err := sensor.DoCommand(ctx, map[string]interface{}{
+		fake.DoSetWrench: map[string]interface{}{"force": []interface{}{1, 0, 0}, "torque": []interface{}{0, 0, 1}},
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	// a module provides the sensor as a generic component that handles the force torque commands.
+	res := inject.NewGenericComponent("ft")
+	res.DoFunc = sensor.DoCommand
+	remote := forcetorque.FromResource(res)
+	w, err := remote.Wrench(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, w, test.ShouldResemble, forcetorque.Wrench{Force: r3.Vector{X: 1}, Torque: r3.Vector{Z: 1}, Frame: "ft"})
+
+	test.That(t, remote.Tare(ctx, nil), test.ShouldBeNil)
+	w, err = remote.Wrench(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, w.Force, test.ShouldResemble, r3.Vector{})
+
+	test.That(t, forcetorque.FromResource(sensor), test.ShouldEqual, sensor)
+}
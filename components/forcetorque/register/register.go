@@ -0,0 +1,7 @@
+// Package register registers all relevant force torque sensors and also API specific functions
+package register
+
+import (
+	// for force torque sensors.
+	_ "go.viam.com/rdk/components/forcetorque/fake"
+)
@@ -10,6 +10,7 @@ import (
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/simulation"
 	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/testutils"
 )
@@ -20,6 +21,9 @@ var gantryModelJSON []byte
 // Config is used for converting config attributes.
 type Config struct {
 	ModelFilePath string `json:"model_path,omitempty"`
+	// Simulation makes the gantry slide over time, within its length and short of obstacles, rather
+	// than teleporting.
+	Simulation *simulation.Config `json:"simulation,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -28,6 +32,9 @@ func (conf *Config) Validate(path string) ([]string, []string, error) {
 	if conf.ModelFilePath != "" {
 		_, err = referenceframe.KinematicModelFromFile(conf.ModelFilePath, "")
 	}
+	if err == nil && conf.Simulation != nil {
+		err = conf.Simulation.Validate(path)
+	}
 	return nil, nil, err
 }
 
@@ -81,30 +88,39 @@ func NewGantry(conf resource.Config, logger logging.Logger) (gantry.Gantry, erro
 		return nil, err
 	}
 
-	return &Gantry{
-		testutils.NewUnimplementedResource(conf.ResourceName()),
-		resource.TriviallyCloseable{},
-		[]float64{m.DoF()[0].Max / 2},
-		[]float64{50},
-		[]float64{m.DoF()[0].Max},
-		m,
-		logger,
-	}, nil
+	g := &Gantry{
+		Named:          testutils.NewUnimplementedResource(conf.ResourceName()),
+		positionsMm:    []float64{m.DoF()[0].Max / 2},
+		speedsMmPerSec: []float64{50},
+		lengthsMm:      []float64{m.DoF()[0].Max},
+		model:          m,
+		logger:         logger,
+	}
+	if newConf.Simulation != nil {
+		if g.sim, err = simulation.NewJoints(newConf.Simulation, m, newConf.Simulation.MaxLinearSpeed(), g.positionsMm); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
 }
 
 // Gantry is a fake gantry that can simply read and set properties.
 type Gantry struct {
 	resource.Named
-	resource.TriviallyCloseable
 	positionsMm    []float64
 	speedsMmPerSec []float64
 	lengthsMm      []float64
 	model          referenceframe.Model
 	logger         logging.Logger
+	// sim holds the positions instead of positionsMm when the gantry is simulated.
+	sim *simulation.Joints
 }
 
 // Position returns the position in meters.
 func (g *Gantry) Position(ctx context.Context, extra map[string]interface{}) ([]float64, error) {
+	if g.sim != nil {
+		return g.sim.Inputs(), nil
+	}
 	return g.positionsMm, nil
 }
 
@@ -126,22 +142,40 @@ func (g *Gantry) MoveToPosition(ctx context.Context, positionsMm, speedsMmPerSec
 			return fmt.Errorf("position %v out of range [0, %v]", position, g.lengthsMm[i])
 		}
 	}
+	if g.sim != nil {
+		var speed float64
+		if len(speedsMmPerSec) > 0 {
+			speed = speedsMmPerSec[0]
+		}
+		return g.sim.MoveTo(ctx, positionsMm, speed)
+	}
 
 	g.positionsMm = positionsMm
 	g.speedsMmPerSec = speedsMmPerSec
 	return nil
 }
 
-// Stop doesn't do anything for a fake gantry.
+// Stop stops a simulated gantry and does nothing otherwise.
 func (g *Gantry) Stop(ctx context.Context, extra map[string]interface{}) error {
+	if g.sim != nil {
+		g.sim.Stop()
+	}
 	return nil
 }
 
-// IsMoving is always false for a fake gantry.
+// IsMoving is whether a simulated gantry is moving, and always false otherwise.
 func (g *Gantry) IsMoving(ctx context.Context) (bool, error) {
+	if g.sim != nil {
+		return g.sim.IsMoving(), nil
+	}
 	return false, nil
 }
 
+// Close stops a simulated gantry.
+func (g *Gantry) Close(ctx context.Context) error {
+	return g.Stop(ctx, nil)
+}
+
 // Geometries returns the geometries of the gantry.
 func (g *Gantry) Geometries(ctx context.Context, extra map[string]interface{}) ([]spatialmath.Geometry, error) {
 	inputs, err := g.CurrentInputs(ctx)
@@ -0,0 +1,124 @@
+// Package hid implements input controllers for HID and MIDI devices, such as jog wheels, button
+// boxes, and MIDI control surfaces, whose buttons, axes, and encoders are mapped to controls in
+// their config.
+package hid
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// controller is an input.Controller whose controls are those of a mapping. The models of this
+// package read their devices and dispatch the events of the mapped controls to it.
+type controller struct {
+	resource.Named
+	resource.AlwaysRebuild
+	mapping input.ControlMapping
+	logger  logging.Logger
+
+	mu         sync.RWMutex
+	lastEvents map[input.Control]input.Event
+	callbacks  map[input.Control]map[input.EventType]input.ControlFunction
+	workers    *utils.StoppableWorkers
+}
+
+func newController(name resource.Name, mapping input.ControlMapping, logger logging.Logger) *controller {
+	return &controller{
+		Named:      name.AsNamed(),
+		mapping:    mapping,
+		logger:     logger,
+		lastEvents: map[input.Control]input.Event{},
+		callbacks:  map[input.Control]map[input.EventType]input.ControlFunction{},
+		workers:    utils.NewBackgroundStoppableWorkers(),
+	}
+}
+
+// Controls lists the mapped controls.
+func (c *controller) Controls(ctx context.Context, extra map[string]interface{}) ([]input.Control, error) {
+	return c.mapping.Controls(), nil
+}
+
+// Events returns the last input.Event (the current state) of each control.
+func (c *controller) Events(ctx context.Context, extra map[string]interface{}) (map[input.Control]input.Event, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[input.Control]input.Event)
+	for key, value := range c.lastEvents {
+		out[key] = value
+	}
+	return out, nil
+}
+
+// RegisterControlCallback registers a callback function to be executed on the specified trigger Event.
+func (c *controller) RegisterControlCallback(
+	ctx context.Context,
+	control input.Control,
+	triggers []input.EventType,
+	ctrlFunc input.ControlFunction,
+	extra map[string]interface{},
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.callbacks[control] == nil {
+		c.callbacks[control] = make(map[input.EventType]input.ControlFunction)
+	}
+
+	for _, trigger := range triggers {
+		if trigger == input.ButtonChange {
+			c.callbacks[control][input.ButtonRelease] = ctrlFunc
+			c.callbacks[control][input.ButtonPress] = ctrlFunc
+		} else {
+			c.callbacks[control][trigger] = ctrlFunc
+		}
+	}
+	return nil
+}
+
+// Close terminates background worker threads.
+func (c *controller) Close(ctx context.Context) error {
+	c.workers.Stop()
+	return nil
+}
+
+func (c *controller) makeCallbacks(eventOut input.Event) {
+	c.mu.Lock()
+	c.lastEvents[eventOut.Control] = eventOut
+	c.mu.Unlock()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if ctrlFunc := c.callbacks[eventOut.Control][eventOut.Event]; ctrlFunc != nil {
+		c.workers.Add(func(ctx context.Context) {
+			ctrlFunc(ctx, eventOut)
+		})
+	}
+	if ctrlFuncAll := c.callbacks[eventOut.Control][input.AllEvents]; ctrlFuncAll != nil {
+		c.workers.Add(func(ctx context.Context) {
+			ctrlFuncAll(ctx, eventOut)
+		})
+	}
+}
+
+func (c *controller) sendConnectionStatus(connected bool) {
+	evType := input.Disconnect
+	now := time.Now()
+	if connected {
+		evType = input.Connect
+	}
+
+	for _, control := range c.mapping.Controls() {
+		c.mu.RLock()
+		lastEvent := c.lastEvents[control].Event
+		c.mu.RUnlock()
+		if lastEvent != evType {
+			c.makeCallbacks(input.Event{Time: now, Event: evType, Control: control})
+		}
+	}
+}
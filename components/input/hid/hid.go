@@ -0,0 +1,34 @@
+package hid
+
+import (
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/resource"
+)
+
+var hidModel = resource.DefaultModelFamily.WithModel("hid")
+
+// HIDConfig is the config of an input controller for a HID device, such as a jog wheel or button
+// box, read through evdev. Buttons are mapped by key code, axes by absolute axis code, and encoders
+// by relative axis code, as evtest reports them.
+type HIDConfig struct {
+	// DevFile is the device to read, such as /dev/input/event3. Devices are numbered in the order
+	// they are connected, so DeviceName is more reliable for devices that are unplugged.
+	DevFile string `json:"dev_file,omitempty"`
+	// DeviceName selects the first device with this name if DevFile is unspecified.
+	DeviceName    string               `json:"device_name,omitempty"`
+	AutoReconnect bool                 `json:"auto_reconnect,omitempty"`
+	Mapping       input.ControlMapping `json:"mapping"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *HIDConfig) Validate(path string) ([]string, []string, error) {
+	if conf.DevFile == "" && conf.DeviceName == "" {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("one of dev_file and device_name must be set"))
+	}
+	if err := conf.Mapping.Validate(path + ".mapping"); err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,132 @@
+//go:build linux
+
+package hid
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/viamrobotics/evdev"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func init() {
+	resource.RegisterComponent(input.API, hidModel, resource.Registration[input.Controller, *HIDConfig]{
+		Constructor: NewHIDController,
+	})
+}
+
+// NewHIDController creates a new HID input controller.
+func NewHIDController(
+	ctx context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (input.Controller, error) {
+	newConf, err := resource.NativeConfig[*HIDConfig](conf)
+	if err != nil {
+		return nil, err
+	}
+	c := newController(conf.ResourceName(), newConf.Mapping, logger)
+	c.workers.Add(func(ctx context.Context) {
+		for {
+			dev, err := openHIDDevice(newConf)
+			if err == nil {
+				logger.CInfof(ctx, "found HID device '%s' at %s", strings.TrimSpace(dev.Name()), dev.Path())
+				c.sendConnectionStatus(true)
+				c.dispatchHID(ctx, dev)
+				utils.UncheckedError(dev.Close())
+				c.sendConnectionStatus(false)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if !newConf.AutoReconnect {
+				if err != nil {
+					logger.CError(ctx, err)
+				}
+				return
+			}
+			if !utils.SelectContextOrWait(ctx, 250*time.Millisecond) {
+				return
+			}
+		}
+	})
+	return c, nil
+}
+
+// openHIDDevice opens the device file of conf, or the first device with its device name.
+func openHIDDevice(conf *HIDConfig) (*evdev.Evdev, error) {
+	if conf.DevFile != "" {
+		return evdev.OpenFile(conf.DevFile)
+	}
+	devs, err := filepath.Glob("/dev/input/event*")
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range devs {
+		dev, err := evdev.OpenFile(n)
+		if err != nil {
+			continue
+		}
+		if strings.TrimSpace(dev.Name()) == conf.DeviceName {
+			return dev, nil
+		}
+		utils.UncheckedError(dev.Close())
+	}
+	return nil, errors.Errorf("no HID device named %q found (check /dev/input/eventXX permissions)", conf.DeviceName)
+}
+
+// dispatchHID dispatches the events of the mapped controls of dev until it disconnects or ctx is done.
+func (c *controller) dispatchHID(ctx context.Context, dev *evdev.Evdev) {
+	axes := dev.AbsoluteTypes()
+	evChan := dev.Poll(ctx)
+	for {
+		var eventIn *evdev.EventEnvelope
+		select {
+		case <-ctx.Done():
+			return
+		case eventIn = <-evChan:
+		}
+		if eventIn == nil {
+			continue
+		}
+
+		t := timevalToTime(eventIn.Event.Time)
+		code := int(eventIn.Event.Code)
+		value := eventIn.Event.Value
+		var eventOut input.Event
+		var ok bool
+		//nolint:exhaustive
+		switch eventIn.Event.Type {
+		case evdev.EventSync:
+			if evdev.SyncType(eventIn.Event.Code) == 4 {
+				// the device was removed.
+				return
+			}
+		case evdev.EventKey:
+			// repeats of held keys are not buttons changing.
+			if value != 2 {
+				eventOut, ok = c.mapping.ButtonEvent(code, value != 0, t)
+			}
+		case evdev.EventAbsolute:
+			info := axes[evdev.AbsoluteType(eventIn.Event.Code)]
+			eventOut, ok = c.mapping.AxisEvent(code, float64(value), float64(info.Min), float64(info.Max), t)
+		case evdev.EventRelative:
+			eventOut, ok = c.mapping.EncoderEvent(code, float64(value), t)
+		}
+		if ok {
+			c.makeCallbacks(eventOut)
+		}
+	}
+}
+
+func timevalToTime(timeVal syscall.Timeval) time.Time {
+	//nolint:unconvert
+	return time.Unix(int64(timeVal.Sec), int64(timeVal.Usec*1000))
+}
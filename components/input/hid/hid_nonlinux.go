@@ -0,0 +1,21 @@
+//go:build !linux
+
+package hid
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func init() {
+	resource.RegisterComponent(input.API, hidModel, resource.Registration[input.Controller, *HIDConfig]{
+		Constructor: func(ctx context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger) (input.Controller, error) {
+			return nil, errors.New("HID input is currently only supported on linux")
+		},
+	})
+}
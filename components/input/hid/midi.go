@@ -0,0 +1,190 @@
+package hid
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// pitchBendCode is the code pitch bend is mapped to an axis by, since it is not a control change.
+const pitchBendCode = 128
+
+var midiModel = resource.DefaultModelFamily.WithModel("midi")
+
+// MIDIConfig is the config of a MIDI input controller. Buttons are mapped by note number, and axes
+// and encoders by control change number. Pitch bend is mapped to an axis by code 128. Encoders
+// report relative steps as two's complement control change values, as most jog wheels do: 1 to 63
+// turn forward and 127 down to 65 turn backward.
+type MIDIConfig struct {
+	// DevFile is the raw MIDI device to read, such as /dev/snd/midiC1D0.
+	DevFile string `json:"dev_file"`
+	// Channel, if set, restricts the messages read to those of this channel, from 1 to 16.
+	Channel       int                  `json:"channel,omitempty"`
+	AutoReconnect bool                 `json:"auto_reconnect,omitempty"`
+	Mapping       input.ControlMapping `json:"mapping"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *MIDIConfig) Validate(path string) ([]string, []string, error) {
+	if conf.DevFile == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "dev_file")
+	}
+	if conf.Channel < 0 || conf.Channel > 16 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("channel must be from 1 to 16"))
+	}
+	if err := conf.Mapping.Validate(path + ".mapping"); err != nil {
+		return nil, nil, err
+	}
+	return nil, nil, nil
+}
+
+func init() {
+	resource.RegisterComponent(input.API, midiModel, resource.Registration[input.Controller, *MIDIConfig]{
+		Constructor: NewMIDIController,
+	})
+}
+
+// NewMIDIController creates a new MIDI input controller.
+func NewMIDIController(
+	ctx context.Context, _ resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (input.Controller, error) {
+	newConf, err := resource.NativeConfig[*MIDIConfig](conf)
+	if err != nil {
+		return nil, err
+	}
+	c := newController(conf.ResourceName(), newConf.Mapping, logger)
+	open := func() (io.ReadCloser, error) {
+		//nolint:gosec
+		return os.Open(newConf.DevFile)
+	}
+	c.workers.Add(func(ctx context.Context) {
+		c.readMIDI(ctx, open, newConf.Channel, newConf.AutoReconnect)
+	})
+	return c, nil
+}
+
+// readMIDI reads the device open opens until ctx is done, reopening it if it disconnects and
+// reconnect is set.
+func (c *controller) readMIDI(ctx context.Context, open func() (io.ReadCloser, error), channel int, reconnect bool) {
+	for {
+		dev, err := open()
+		if err == nil {
+			c.sendConnectionStatus(true)
+			// the device is closed when ctx is done, to interrupt reads.
+			stop := context.AfterFunc(ctx, func() { utils.UncheckedError(dev.Close()) })
+			err = c.dispatchMIDI(dev, channel)
+			stop()
+			utils.UncheckedError(dev.Close())
+			c.sendConnectionStatus(false)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if !reconnect {
+			c.logger.CError(ctx, err)
+			return
+		}
+		c.logger.CDebugw(ctx, "MIDI device disconnected, reconnecting", "error", err)
+		if !utils.SelectContextOrWait(ctx, 250*time.Millisecond) {
+			return
+		}
+	}
+}
+
+// dispatchMIDI reads MIDI messages from r until it fails, dispatching the events of the mapped
+// controls. Messages of other channels than channel, if it is set, are ignored.
+func (c *controller) dispatchMIDI(r io.Reader, channel int) error {
+	parser := midiParser{r: bufio.NewReader(r)}
+	for {
+		msg, err := parser.next()
+		if err != nil {
+			return err
+		}
+		if channel != 0 && int(msg.status&0x0f)+1 != channel {
+			continue
+		}
+		now := time.Now()
+		var event input.Event
+		var ok bool
+		switch msg.status & 0xf0 {
+		case 0x80:
+			event, ok = c.mapping.ButtonEvent(int(msg.data[0]), false, now)
+		case 0x90:
+			// note on with no velocity is how many devices release notes.
+			event, ok = c.mapping.ButtonEvent(int(msg.data[0]), msg.data[1] > 0, now)
+		case 0xb0:
+			code, value := int(msg.data[0]), int(msg.data[1])
+			event, ok = c.mapping.AxisEvent(code, float64(value), 0, 127, now)
+			if !ok {
+				if value >= 64 {
+					value -= 128
+				}
+				event, ok = c.mapping.EncoderEvent(code, float64(value), now)
+			}
+		case 0xe0:
+			value := int(msg.data[0]) | int(msg.data[1])<<7
+			event, ok = c.mapping.AxisEvent(pitchBendCode, float64(value), 0, 16383, now)
+		}
+		if ok {
+			c.makeCallbacks(event)
+		}
+	}
+}
+
+// midiMessage is a MIDI channel message.
+type midiMessage struct {
+	status byte
+	data   [2]byte
+}
+
+// midiParser parses the channel messages of a raw MIDI stream, skipping system messages.
+type midiParser struct {
+	r             io.ByteReader
+	runningStatus byte
+}
+
+// midiDataLengths are the number of data bytes of the channel messages, by their high nibble.
+var midiDataLengths = map[byte]int{0x80: 2, 0x90: 2, 0xa0: 2, 0xb0: 2, 0xc0: 1, 0xd0: 1, 0xe0: 2}
+
+func (p *midiParser) next() (midiMessage, error) {
+	var msg midiMessage
+	count := 0
+	for {
+		b, err := p.r.ReadByte()
+		if err != nil {
+			return msg, err
+		}
+		switch {
+		case b >= 0xf8:
+			// real-time messages may appear anywhere, even within other messages.
+			continue
+		case b >= 0xf0:
+			// system messages cancel running status; their data is skipped below.
+			p.runningStatus = 0
+			count = 0
+			continue
+		case b >= 0x80:
+			p.runningStatus = b
+			count = 0
+			continue
+		}
+		if p.runningStatus == 0 {
+			continue
+		}
+		msg.status = p.runningStatus
+		msg.data[count] = b
+		count++
+		if count == midiDataLengths[p.runningStatus&0xf0] {
+			return msg, nil
+		}
+	}
+}
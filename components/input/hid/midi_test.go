@@ -0,0 +1,131 @@
+package hid
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+func TestMIDIParser(t *testing.T) {
+	parser := midiParser{r: &byteReader{data: []byte{
+		0x90, 60, 100, // note on
+		61, 0, // note on through running status
+		0xf8,              // clock, in the middle of nothing
+		0xb1, 7, 0xfe, 64, // control change interrupted by active sensing
+		0xf0, 0x7e, 0x01, 0xf7, // sysex, skipped
+		0xe0, 0x00, 0x40, // pitch bend
+	}}}
+	var msgs []midiMessage
+	for {
+		msg, err := parser.next()
+		if err != nil {
+			test.That(t, err, test.ShouldEqual, io.EOF)
+			break
+		}
+		msgs = append(msgs, msg)
+	}
+	test.That(t, msgs, test.ShouldResemble, []midiMessage{
+		{status: 0x90, data: [2]byte{60, 100}},
+		{status: 0x90, data: [2]byte{61, 0}},
+		{status: 0xb1, data: [2]byte{7, 64}},
+		{status: 0xe0, data: [2]byte{0x00, 0x40}},
+	})
+}
+
+type byteReader struct {
+	data []byte
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	b := r.data[0]
+	r.data = r.data[1:]
+	return b, nil
+}
+
+func TestMIDIController(t *testing.T) {
+	ctx := context.Background()
+	mapping := input.ControlMapping{
+		Buttons:  []input.ButtonMapping{{Code: 36, Control: "ButtonCycleStart"}},
+		Axes:     []input.AxisMapping{{Code: 1, Control: "Feedrate", Unidirectional: true}},
+		Encoders: []input.EncoderMapping{{Code: 16, Control: "JogWheel"}},
+	}
+	c := newController(resource.NewName(input.API, "midi"), mapping, logging.NewTestLogger(t))
+	defer c.Close(ctx)
+
+	events := make(chan input.Event, 10)
+	for _, control := range mapping.Controls() {
+		err := c.RegisterControlCallback(ctx, control, []input.EventType{input.AllEvents}, func(ctx context.Context, ev input.Event) {
+			events <- ev
+		}, nil)
+		test.That(t, err, test.ShouldBeNil)
+	}
+
+	r, w := io.Pipe()
+	c.workers.Add(func(ctx context.Context) {
+		c.readMIDI(ctx, func() (io.ReadCloser, error) { return r, nil }, 1, false)
+	})
+
+	receive := func() input.Event {
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+			return input.Event{}
+		}
+	}
+	eventsOf := func(n int) map[input.Control]input.Event {
+		out := map[input.Control]input.Event{}
+		for i := 0; i < n; i++ {
+			ev := receive()
+			out[ev.Control] = ev
+		}
+		return out
+	}
+	connected := eventsOf(3)
+	test.That(t, connected["JogWheel"].Event, test.ShouldEqual, input.Connect)
+
+	_, err := w.Write([]byte{
+		0x90, 36, 127, // press on channel 1
+		0x91, 36, 0, // release on channel 2, ignored
+		0xb0, 1, 127, // feedrate to max
+		0xb0, 16, 126, // jog wheel back two steps
+	})
+	test.That(t, err, test.ShouldBeNil)
+	// callbacks run concurrently, so their events may arrive in any order.
+	changed := eventsOf(3)
+	test.That(t, changed["ButtonCycleStart"].Event, test.ShouldEqual, input.ButtonPress)
+	test.That(t, changed["Feedrate"].Value, test.ShouldEqual, 1)
+	test.That(t, changed["JogWheel"].Event, test.ShouldEqual, input.PositionChangeRel)
+	test.That(t, changed["JogWheel"].Value, test.ShouldEqual, -2)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		lastEvents, err := c.Events(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, lastEvents["JogWheel"].Value, test.ShouldEqual, -2)
+	})
+
+	test.That(t, w.Close(), test.ShouldBeNil)
+	disconnected := eventsOf(3)
+	test.That(t, disconnected["ButtonCycleStart"].Event, test.ShouldEqual, input.Disconnect)
+}
+
+func TestMIDIConfigValidate(t *testing.T) {
+	_, _, err := (&MIDIConfig{}).Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "dev_file")
+	_, _, err = (&MIDIConfig{DevFile: "/dev/snd/midiC1D0", Channel: 17}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&MIDIConfig{DevFile: "/dev/snd/midiC1D0", Channel: 1}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
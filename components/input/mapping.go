@@ -0,0 +1,193 @@
+package input
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// A ControlMapping maps the codes a device reports its buttons, axes, and encoders by to controls,
+// for devices with no built-in mapping such as jog wheels, button boxes, and MIDI controllers.
+// Controls may be given any name, such as "JogWheel" or "ButtonCycleStart", in addition to those
+// defined by this package.
+type ControlMapping struct {
+	Buttons  []ButtonMapping  `json:"buttons,omitempty"`
+	Axes     []AxisMapping    `json:"axes,omitempty"`
+	Encoders []EncoderMapping `json:"encoders,omitempty"`
+}
+
+// A ButtonMapping maps a code to a button, whose events are ButtonPress and ButtonRelease.
+type ButtonMapping struct {
+	Code    int     `json:"code"`
+	Control Control `json:"control"`
+	// Invert swaps presses and releases, such as for normally closed switches.
+	Invert bool `json:"invert,omitempty"`
+}
+
+// An AxisMapping maps a code to an absolute axis, whose events are PositionChangeAbs with values
+// from -1.0 to 1.0, or from 0.0 to 1.0 if the axis is unidirectional.
+type AxisMapping struct {
+	Code    int     `json:"code"`
+	Control Control `json:"control"`
+	// Min and Max are the range of the values of the axis. If both are unspecified, the range the
+	// device reports is used.
+	Min float64 `json:"min,omitempty"`
+	Max float64 `json:"max,omitempty"`
+	// Unidirectional scales values from 0.0 to 1.0, such as for faders and pedals.
+	Unidirectional bool `json:"unidirectional,omitempty"`
+	Invert         bool `json:"invert,omitempty"`
+	// Deadzone is the fraction of the range around the rest position, the center or the minimum if
+	// the axis is unidirectional, in which the value is 0.
+	Deadzone float64 `json:"deadzone,omitempty"`
+}
+
+// An EncoderMapping maps a code to a relative encoder, such as a jog wheel, whose events are
+// PositionChangeRel with the number of steps it turned since the previous event.
+type EncoderMapping struct {
+	Code    int     `json:"code"`
+	Control Control `json:"control"`
+	// Scale multiplies the steps the device reports. Scale defaults to 1 if unspecified; a negative
+	// scale reverses the encoder.
+	Scale float64 `json:"scale,omitempty"`
+}
+
+// Validate ensures all parts of the mapping are valid.
+func (m *ControlMapping) Validate(path string) error {
+	controls := map[Control]bool{}
+	checkControl := func(path string, control Control) error {
+		if control == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "control")
+		}
+		if controls[control] {
+			return resource.NewConfigValidationError(path, fmt.Errorf("control %q is mapped more than once", control))
+		}
+		controls[control] = true
+		return nil
+	}
+
+	codes := map[int]bool{}
+	for idx, button := range m.Buttons {
+		buttonPath := fmt.Sprintf("%s.buttons.%d", path, idx)
+		if err := checkControl(buttonPath, button.Control); err != nil {
+			return err
+		}
+		if codes[button.Code] {
+			return resource.NewConfigValidationError(buttonPath, fmt.Errorf("button code %d is mapped more than once", button.Code))
+		}
+		codes[button.Code] = true
+	}
+
+	codes = map[int]bool{}
+	for idx, axis := range m.Axes {
+		axisPath := fmt.Sprintf("%s.axes.%d", path, idx)
+		if err := checkControl(axisPath, axis.Control); err != nil {
+			return err
+		}
+		if codes[axis.Code] {
+			return resource.NewConfigValidationError(axisPath, fmt.Errorf("axis code %d is mapped more than once", axis.Code))
+		}
+		codes[axis.Code] = true
+		if (axis.Min != 0 || axis.Max != 0) && axis.Min >= axis.Max {
+			return resource.NewConfigValidationError(axisPath, fmt.Errorf("min (%v) must be less than max (%v)", axis.Min, axis.Max))
+		}
+		if axis.Deadzone < 0 || axis.Deadzone >= 1 {
+			return resource.NewConfigValidationError(axisPath, errors.New("deadzone must be at least 0 and less than 1"))
+		}
+	}
+
+	codes = map[int]bool{}
+	for idx, encoder := range m.Encoders {
+		encoderPath := fmt.Sprintf("%s.encoders.%d", path, idx)
+		if err := checkControl(encoderPath, encoder.Control); err != nil {
+			return err
+		}
+		if codes[encoder.Code] {
+			return resource.NewConfigValidationError(encoderPath, fmt.Errorf("encoder code %d is mapped more than once", encoder.Code))
+		}
+		codes[encoder.Code] = true
+	}
+	return nil
+}
+
+// Controls returns the controls of the mapping.
+func (m *ControlMapping) Controls() []Control {
+	controls := make([]Control, 0, len(m.Buttons)+len(m.Axes)+len(m.Encoders))
+	for _, button := range m.Buttons {
+		controls = append(controls, button.Control)
+	}
+	for _, axis := range m.Axes {
+		controls = append(controls, axis.Control)
+	}
+	for _, encoder := range m.Encoders {
+		controls = append(controls, encoder.Control)
+	}
+	return controls
+}
+
+// ButtonEvent returns the event of the button mapped to code being pressed or released, or false if
+// no button is mapped to code.
+func (m *ControlMapping) ButtonEvent(code int, pressed bool, t time.Time) (Event, bool) {
+	for _, button := range m.Buttons {
+		if button.Code != code {
+			continue
+		}
+		if button.Invert {
+			pressed = !pressed
+		}
+		if pressed {
+			return Event{Time: t, Event: ButtonPress, Control: button.Control, Value: 1}, true
+		}
+		return Event{Time: t, Event: ButtonRelease, Control: button.Control, Value: 0}, true
+	}
+	return Event{}, false
+}
+
+// AxisEvent returns the event of the axis mapped to code moving to value, or false if no axis is
+// mapped to code. deviceMin and deviceMax are the range of the axis the device reports, used if
+// the mapping has none.
+func (m *ControlMapping) AxisEvent(code int, value, deviceMin, deviceMax float64, t time.Time) (Event, bool) {
+	for _, axis := range m.Axes {
+		if axis.Code != code {
+			continue
+		}
+		minValue, maxValue := axis.Min, axis.Max
+		if minValue == 0 && maxValue == 0 {
+			minValue, maxValue = deviceMin, deviceMax
+		}
+		scaled := 0.0
+		if maxValue > minValue {
+			scaled = (math.Max(minValue, math.Min(maxValue, value)) - minValue) / (maxValue - minValue)
+		}
+		if axis.Invert {
+			scaled = 1 - scaled
+		}
+		if !axis.Unidirectional {
+			scaled = 2*scaled - 1
+		}
+		if math.Abs(scaled) <= axis.Deadzone {
+			scaled = 0
+		}
+		return Event{Time: t, Event: PositionChangeAbs, Control: axis.Control, Value: scaled}, true
+	}
+	return Event{}, false
+}
+
+// EncoderEvent returns the event of the encoder mapped to code turning by steps, or false if no
+// encoder is mapped to code.
+func (m *ControlMapping) EncoderEvent(code int, steps float64, t time.Time) (Event, bool) {
+	for _, encoder := range m.Encoders {
+		if encoder.Code != code {
+			continue
+		}
+		scale := encoder.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		return Event{Time: t, Event: PositionChangeRel, Control: encoder.Control, Value: steps * scale}, true
+	}
+	return Event{}, false
+}
@@ -0,0 +1,60 @@
+package input_test
+
+import (
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/input"
+)
+
+func TestControlMapping(t *testing.T) {
+	now := time.Now()
+	mapping := input.ControlMapping{
+		Buttons:  []input.ButtonMapping{{Code: 256, Control: "ButtonCycleStart"}, {Code: 257, Control: "ButtonHold", Invert: true}},
+		Axes:     []input.AxisMapping{{Code: 0, Control: input.AbsoluteX, Deadzone: 0.1}, {Code: 7, Control: "Feedrate", Min: 0, Max: 100, Unidirectional: true}},
+		Encoders: []input.EncoderMapping{{Code: 7, Control: "JogWheel", Scale: -0.5}},
+	}
+	test.That(t, mapping.Validate("path"), test.ShouldBeNil)
+	test.That(t, mapping.Controls(), test.ShouldResemble,
+		[]input.Control{"ButtonCycleStart", "ButtonHold", input.AbsoluteX, "Feedrate", "JogWheel"})
+
+	event, ok := mapping.ButtonEvent(256, true, now)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, event, test.ShouldResemble, input.Event{Time: now, Event: input.ButtonPress, Control: "ButtonCycleStart", Value: 1})
+	event, ok = mapping.ButtonEvent(257, true, now)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, event.Event, test.ShouldEqual, input.ButtonRelease)
+	_, ok = mapping.ButtonEvent(258, true, now)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	// axes without a range of their own use the range of the device.
+	event, ok = mapping.AxisEvent(0, 255, 0, 255, now)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, event, test.ShouldResemble, input.Event{Time: now, Event: input.PositionChangeAbs, Control: input.AbsoluteX, Value: 1})
+	event, _ = mapping.AxisEvent(0, 130, 0, 255, now)
+	test.That(t, event.Value, test.ShouldEqual, 0)
+	event, _ = mapping.AxisEvent(7, 25, 0, 255, now)
+	test.That(t, event.Value, test.ShouldEqual, 0.25)
+	event, _ = mapping.AxisEvent(7, 150, 0, 255, now)
+	test.That(t, event.Value, test.ShouldEqual, 1)
+
+	event, ok = mapping.EncoderEvent(7, 4, now)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, event, test.ShouldResemble, input.Event{Time: now, Event: input.PositionChangeRel, Control: "JogWheel", Value: -2})
+
+	t.Run("invalid mappings", func(t *testing.T) {
+		invalid := input.ControlMapping{Buttons: []input.ButtonMapping{{Code: 1, Control: "A"}, {Code: 1, Control: "B"}}}
+		test.That(t, invalid.Validate("path"), test.ShouldNotBeNil)
+
+		invalid = input.ControlMapping{Buttons: []input.ButtonMapping{{Code: 1, Control: "A"}}, Axes: []input.AxisMapping{{Code: 1, Control: "A"}}}
+		test.That(t, invalid.Validate("path"), test.ShouldNotBeNil)
+
+		invalid = input.ControlMapping{Encoders: []input.EncoderMapping{{Code: 1}}}
+		test.That(t, invalid.Validate("path"), test.ShouldNotBeNil)
+
+		invalid = input.ControlMapping{Axes: []input.AxisMapping{{Code: 1, Control: "A", Min: 10, Max: 5}}}
+		test.That(t, invalid.Validate("path"), test.ShouldNotBeNil)
+	})
+}
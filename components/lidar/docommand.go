@@ -0,0 +1,159 @@
+package lidar
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoGetScan       = "get_scan"
+	DoGetProperties = "get_lidar_properties"
+)
+
+// scanMessage is the form a scan takes in DoCommand responses and readings, with its measurements
+// in columns to keep large scans compact.
+type scanMessage struct {
+	Azimuths    []float64 `json:"azimuths"`
+	Elevations  []float64 `json:"elevations"`
+	RangesMM    []float64 `json:"ranges_mm"`
+	Intensities []float64 `json:"intensities"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at"`
+}
+
+// Validate ensures every column has a value for each measurement.
+func (m scanMessage) Validate() error {
+	n := len(m.RangesMM)
+	if len(m.Azimuths) != n || len(m.Elevations) != n || len(m.Intensities) != n {
+		return errors.Errorf("scan has %d azimuths, %d elevations, %d ranges and %d intensities",
+			len(m.Azimuths), len(m.Elevations), n, len(m.Intensities))
+	}
+	return nil
+}
+
+func scanToMessage(s *Scan) scanMessage {
+	n := len(s.Measurements)
+	msg := scanMessage{
+		Azimuths:    make([]float64, n),
+		Elevations:  make([]float64, n),
+		RangesMM:    make([]float64, n),
+		Intensities: make([]float64, n),
+		StartedAt:   s.StartedAt,
+		EndedAt:     s.EndedAt,
+	}
+	for i, m := range s.Measurements {
+		msg.Azimuths[i], msg.Elevations[i], msg.RangesMM[i], msg.Intensities[i] = m.Azimuth, m.Elevation, m.RangeMM, m.Intensity
+	}
+	return msg
+}
+
+func (m scanMessage) scan() (*Scan, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	s := &Scan{Measurements: make([]Measurement, len(m.RangesMM)), StartedAt: m.StartedAt, EndedAt: m.EndedAt}
+	for i := range s.Measurements {
+		s.Measurements[i] = Measurement{Azimuth: m.Azimuths[i], Elevation: m.Elevations[i], RangeMM: m.RangesMM[i], Intensity: m.Intensities[i]}
+	}
+	return s, nil
+}
+
+// propertiesMessage is the form properties take in DoCommand responses.
+type propertiesMessage struct {
+	Kind              Kind      `json:"kind,omitempty"`
+	Channels          int       `json:"channels,omitempty"`
+	ElevationAngles   []float64 `json:"elevation_angles,omitempty"`
+	MinAzimuth        float64   `json:"min_azimuth"`
+	MaxAzimuth        float64   `json:"max_azimuth"`
+	AngularResolution float64   `json:"angular_resolution,omitempty"`
+	MinRangeMM        float64   `json:"min_range_mm,omitempty"`
+	MaxRangeMM        float64   `json:"max_range_mm,omitempty"`
+	ScanRateHz        float64   `json:"scan_rate_hz,omitempty"`
+	HasIntensity      bool      `json:"has_intensity"`
+}
+
+type commandMessage struct {
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type getScanCommand struct {
+	commandMessage
+	GetScan bool `json:"get_scan"`
+}
+
+type getPropertiesCommand struct {
+	GetProperties bool `json:"get_lidar_properties"`
+}
+
+// HandleLidarCommand services the lidar DoCommand keys using the given lidar, so that lidars can be
+// used through DoCommand by callers that only have a generic resource handle, such as the client of
+// a lidar provided by a module. It returns false if cmd does not contain any of them so that it can
+// be chained from a DoCommand implementation.
+func HandleLidarCommand(ctx context.Context, l Lidar, cmd map[string]interface{}) (map[string]interface{}, bool, error) {
+	switch {
+	case cmd[DoGetScan] != nil:
+		req, err := resource.DecodeDoCommand[getScanCommand](cmd)
+		if err != nil {
+			return nil, true, err
+		}
+		s, err := l.Scan(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(scanToMessage(s))
+		return resp, true, err
+	case cmd[DoGetProperties] != nil:
+		props, err := l.Properties(ctx)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(propertiesMessage(props))
+		return resp, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// FromResource returns a Lidar that is used through the DoCommand of res, which must handle the
+// lidar DoCommand keys as HandleLidarCommand does. It lets lidars be provided by modules as generic
+// components or sensors.
+func FromResource(res resource.Resource) Lidar {
+	if l, ok := res.(Lidar); ok {
+		return l
+	}
+	return &doCommandLidar{Resource: res}
+}
+
+type doCommandLidar struct {
+	resource.Resource
+}
+
+func (l *doCommandLidar) Scan(ctx context.Context, extra map[string]interface{}) (*Scan, error) {
+	resp, err := resource.DoCommandAs[getScanCommand, scanMessage](
+		ctx, l, getScanCommand{commandMessage: commandMessage{Extra: extra}, GetScan: true})
+	if err != nil {
+		return nil, err
+	}
+	return resp.scan()
+}
+
+func (l *doCommandLidar) Properties(ctx context.Context) (Properties, error) {
+	resp, err := resource.DoCommandAs[getPropertiesCommand, propertiesMessage](ctx, l, getPropertiesCommand{GetProperties: true})
+	if err != nil {
+		return Properties{}, err
+	}
+	return Properties(resp), nil
+}
+
+func (l *doCommandLidar) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	s, err := l.Scan(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return ScanToReadings(s)
+}
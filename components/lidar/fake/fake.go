@@ -0,0 +1,196 @@
+// Package fake implements a fake lidar spinning at the center of an empty room.
+package fake
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/lidar"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("fake")
+
+const (
+	defaultRoomLengthMM      = 6000.
+	defaultRoomWidthMM       = 4000.
+	defaultRoomHeightMM      = 3000.
+	defaultAngularResolution = 1.
+	defaultScanRateHz        = 10.
+	maxRangeMM               = 12000.
+)
+
+func init() {
+	resource.RegisterComponent(lidar.API, model, resource.Registration[lidar.Lidar, *Config]{
+		Constructor: NewLidar,
+	})
+}
+
+// Config is the config for a fake lidar.
+type Config struct {
+	// RoomLengthMM, RoomWidthMM and RoomHeightMM are the size of the room along the X, Y and Z of the
+	// lidar, 6000 by 4000 by 3000 by default.
+	RoomLengthMM float64 `json:"room_length_mm,omitempty"`
+	RoomWidthMM  float64 `json:"room_width_mm,omitempty"`
+	RoomHeightMM float64 `json:"room_height_mm,omitempty"`
+	// ElevationAnglesDegrees are the elevations of the beams of the lidar, which is a 2D lidar of one
+	// level beam if unspecified.
+	ElevationAnglesDegrees []float64 `json:"elevation_angles_degrees,omitempty"`
+	// AngularResolutionDegrees is the azimuth between measurements, 1 degree by default.
+	AngularResolutionDegrees float64 `json:"angular_resolution_degrees,omitempty"`
+	ScanRateHz               float64 `json:"scan_rate_hz,omitempty"`
+	// NoiseMM is the standard deviation of the noise added to ranges.
+	NoiseMM float64 `json:"noise_mm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.RoomLengthMM < 0 || conf.RoomWidthMM < 0 || conf.RoomHeightMM < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("room dimensions cannot be negative"))
+	}
+	if conf.AngularResolutionDegrees < 0 || conf.AngularResolutionDegrees > 90 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("angular_resolution_degrees must be between 0 and 90"))
+	}
+	for _, elevation := range conf.ElevationAnglesDegrees {
+		if math.Abs(elevation) >= 90 {
+			return nil, nil, resource.NewConfigValidationError(path, errors.New("elevation_angles_degrees must be between -90 and 90"))
+		}
+	}
+	if conf.ScanRateHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("scan_rate_hz cannot be negative"))
+	}
+	if conf.NoiseMM < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("noise_mm cannot be negative"))
+	}
+	return nil, nil, nil
+}
+
+// Lidar is a fake lidar spinning at the center of an empty room.
+type Lidar struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	halfLength, halfWidth, halfHeight float64
+	elevations                        []float64
+	resolution                        float64
+	scanRateHz                        float64
+	noise                             float64
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewLidar instantiates a new lidar of the fake model type.
+func NewLidar(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (lidar.Lidar, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	orDefault := func(v, def float64) float64 {
+		if v == 0 {
+			return def
+		}
+		return v
+	}
+	l := &Lidar{
+		Named:      conf.ResourceName().AsNamed(),
+		halfLength: orDefault(newConf.RoomLengthMM, defaultRoomLengthMM) / 2,
+		halfWidth:  orDefault(newConf.RoomWidthMM, defaultRoomWidthMM) / 2,
+		halfHeight: orDefault(newConf.RoomHeightMM, defaultRoomHeightMM) / 2,
+		elevations: []float64{0},
+		resolution: orDefault(newConf.AngularResolutionDegrees, defaultAngularResolution) * math.Pi / 180,
+		scanRateHz: orDefault(newConf.ScanRateHz, defaultScanRateHz),
+		noise:      newConf.NoiseMM,
+		//nolint:gosec
+		rnd: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if len(newConf.ElevationAnglesDegrees) != 0 {
+		l.elevations = make([]float64, len(newConf.ElevationAnglesDegrees))
+		for i, elevation := range newConf.ElevationAnglesDegrees {
+			l.elevations[i] = elevation * math.Pi / 180
+		}
+	}
+	return l, nil
+}
+
+// rangeTo returns the distance to the walls, floor or ceiling of the room along the beam, or zero
+// if they are out of range.
+func (l *Lidar) rangeTo(azimuth, elevation float64) float64 {
+	horizontal := math.Inf(1)
+	if c := math.Abs(math.Cos(azimuth)); c > 1e-9 {
+		horizontal = l.halfLength / c
+	}
+	if s := math.Abs(math.Sin(azimuth)); s > 1e-9 {
+		horizontal = math.Min(horizontal, l.halfWidth/s)
+	}
+	r := horizontal / math.Cos(elevation)
+	if s := math.Abs(math.Sin(elevation)); s > 1e-9 {
+		r = math.Min(r, l.halfHeight/s)
+	}
+	if r > maxRangeMM {
+		return 0
+	}
+	return r
+}
+
+// Scan returns a scan of the room ending now.
+func (l *Lidar) Scan(ctx context.Context, extra map[string]interface{}) (*lidar.Scan, error) {
+	ended := time.Now()
+	steps := int(math.Round(2 * math.Pi / l.resolution))
+	scan := &lidar.Scan{
+		Measurements: make([]lidar.Measurement, 0, steps*len(l.elevations)),
+		StartedAt:    ended.Add(-time.Duration(float64(time.Second) / l.scanRateHz)),
+		EndedAt:      ended,
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := 0; i < steps; i++ {
+		azimuth := float64(i) * l.resolution
+		for _, elevation := range l.elevations {
+			r := l.rangeTo(azimuth, elevation)
+			if r > 0 && l.noise > 0 {
+				r = math.Max(r+l.rnd.NormFloat64()*l.noise, 0)
+			}
+			scan.Measurements = append(scan.Measurements, lidar.Measurement{Azimuth: azimuth, Elevation: elevation, RangeMM: r})
+		}
+	}
+	return scan, nil
+}
+
+// Properties returns the properties of a spinning lidar.
+func (l *Lidar) Properties(ctx context.Context) (lidar.Properties, error) {
+	return lidar.Properties{
+		Kind:              lidar.KindSpinning,
+		Channels:          len(l.elevations),
+		ElevationAngles:   l.elevations,
+		MinAzimuth:        0,
+		MaxAzimuth:        2 * math.Pi,
+		AngularResolution: l.resolution,
+		MaxRangeMM:        maxRangeMM,
+		ScanRateHz:        l.scanRateHz,
+	}, nil
+}
+
+// Readings returns the scan in the form produced by lidar.ScanToReadings.
+func (l *Lidar) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	scan, err := l.Scan(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return lidar.ScanToReadings(scan)
+}
+
+// DoCommand handles the lidar DoCommand keys.
+func (l *Lidar) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, ok, err := lidar.HandleLidarCommand(ctx, l, cmd); ok {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
@@ -0,0 +1,151 @@
+// Package lidar defines lidars, which measure the ranges of their surroundings along beams swept
+// around them. Unlike depth cameras, whose point clouds are projected from images, a lidar reports
+// each measurement with the direction of its beam, its intensity and the time of its scan, as SLAM
+// and obstacle avoidance need of 2D lidars and multi-beam spinning or solid state units alike.
+package lidar
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Lidar]{})
+}
+
+// SubtypeName is a constant that identifies the component resource API string.
+const SubtypeName = "lidar"
+
+// API is a variable that identifies the component resource API.
+var API = resource.APINamespaceRDK.WithComponentType(SubtypeName)
+
+// Named is a helper for getting the named lidar's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// A Lidar measures the ranges of its surroundings in scans.
+type Lidar interface {
+	resource.Sensor
+	resource.Resource
+
+	// Scan returns the latest complete scan, waiting for the first if there has not been one.
+	Scan(ctx context.Context, extra map[string]interface{}) (*Scan, error)
+
+	// Properties returns what the lidar measures and how.
+	Properties(ctx context.Context) (Properties, error)
+}
+
+// Deprecated: FromRobot is a helper for getting the named Lidar from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Lidar, error) {
+	return robot.ResourceFromRobot[Lidar](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named Lidar from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Lidar, error) {
+	return resource.FromProvider[Lidar](provider, Named(name))
+}
+
+// NamesFromRobot is a helper for getting all lidar names from the given Robot.
+func NamesFromRobot(r robot.Robot) []string {
+	return robot.NamesByAPI(r, API)
+}
+
+// A Kind is how a lidar sweeps its beams.
+type Kind string
+
+const (
+	// KindSpinning lidars sweep their beams by rotating, covering all around them each revolution.
+	KindSpinning = Kind("spinning")
+	// KindSolidState lidars steer their beams without moving parts, over a limited field of view.
+	KindSolidState = Kind("solid_state")
+)
+
+// Properties describe what a lidar measures and how. Angles are in radians, with azimuths
+// counterclockwise about +Z from +X, which is the front of the lidar, and elevations above the XY
+// plane. Zero values are unknown.
+type Properties struct {
+	Kind Kind
+	// Channels is how many beams the lidar sweeps at once, which is 1 for 2D lidars.
+	Channels int
+	// ElevationAngles are the elevations of the beams, if they are fixed.
+	ElevationAngles []float64
+	// MinAzimuth and MaxAzimuth bound the azimuths of the field of view.
+	MinAzimuth float64
+	MaxAzimuth float64
+	// AngularResolution is the azimuth between consecutive measurements of a beam.
+	AngularResolution float64
+	MinRangeMM        float64
+	MaxRangeMM        float64
+	// ScanRateHz is how many scans the lidar makes each second.
+	ScanRateHz float64
+	// HasIntensity is whether measurements report the intensity of their returns.
+	HasIntensity bool
+}
+
+// A Measurement is the range along one beam of a scan.
+type Measurement struct {
+	Azimuth   float64
+	Elevation float64
+	// RangeMM is the distance to the return, or zero if there was none.
+	RangeMM float64
+	// Intensity is the strength of the return, in units of the lidar, or zero if it has none.
+	Intensity float64
+}
+
+// Point returns the point of the return in the lidar's frame, in millimeters.
+func (m Measurement) Point() r3.Vector {
+	horizontal := m.RangeMM * math.Cos(m.Elevation)
+	return r3.Vector{
+		X: horizontal * math.Cos(m.Azimuth),
+		Y: horizontal * math.Sin(m.Azimuth),
+		Z: m.RangeMM * math.Sin(m.Elevation),
+	}
+}
+
+// A Scan is one sweep of a lidar's beams.
+type Scan struct {
+	Measurements []Measurement
+	// StartedAt and EndedAt are when the first and last measurements of the scan were made.
+	StartedAt time.Time
+	EndedAt   time.Time
+}
+
+// PointCloud returns the points of the returns of the scan in the lidar's frame, in millimeters,
+// with their intensities as their values.
+func (s *Scan) PointCloud() (pointcloud.PointCloud, error) {
+	pc := pointcloud.NewBasicEmpty()
+	for _, m := range s.Measurements {
+		if m.RangeMM <= 0 {
+			continue
+		}
+		if err := pc.Set(m.Point(), pointcloud.NewValueData(int(math.Round(m.Intensity)))); err != nil {
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+// ScanToReadings converts a Scan into the readings of a lidar.
+func ScanToReadings(s *Scan) (map[string]interface{}, error) {
+	return resource.EncodeDoCommand(scanToMessage(s))
+}
+
+// ScanFromReadings converts the readings of a lidar back into a Scan.
+func ScanFromReadings(readings map[string]interface{}) (*Scan, error) {
+	msg, err := resource.DecodeDoCommand[scanMessage](readings)
+	if err != nil {
+		return nil, err
+	}
+	return msg.scan()
+}
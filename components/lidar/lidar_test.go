@@ -0,0 +1,109 @@
+package lidar_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/lidar"
+	"go.viam.com/rdk/components/lidar/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/protoutils"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func newFake(t *testing.T, conf *fake.Config) lidar.Lidar {
+	t.Helper()
+	l, err := fake.NewLidar(context.Background(), nil, resource.Config{
+		Name:                "lidar",
+		API:                 lidar.API,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return l
+}
+
+func TestMeasurementPoint(t *testing.T) {
+	p := lidar.Measurement{Azimuth: math.Pi / 2, RangeMM: 1000}.Point()
+	test.That(t, p.Sub(r3.Vector{Y: 1000}).Norm(), test.ShouldBeLessThan, 1e-9)
+	p = lidar.Measurement{Azimuth: math.Pi, Elevation: math.Pi / 6, RangeMM: 1000}.Point()
+	test.That(t, p.Sub(r3.Vector{X: -1000 * math.Sqrt(3) / 2, Z: 500}).Norm(), test.ShouldBeLessThan, 1e-9)
+
+	scan := &lidar.Scan{Measurements: []lidar.Measurement{
+		{RangeMM: 1000, Intensity: 12},
+		// measurements without a return are not points.
+		{Azimuth: 1},
+	}}
+	pc, err := scan.PointCloud()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 1)
+	d, ok := pc.At(1000, 0, 0)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Value(), test.ShouldEqual, 12)
+}
+
+func TestFakeScan(t *testing.T) {
+	ctx := context.Background()
+	l := newFake(t, &fake.Config{RoomLengthMM: 4000, RoomWidthMM: 2000, AngularResolutionDegrees: 90, ElevationAnglesDegrees: []float64{0, 45}})
+	scan, err := l.Scan(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, scan.EndedAt.Sub(scan.StartedAt), test.ShouldEqual, 100*time.Millisecond)
+	test.That(t, scan.Measurements, test.ShouldHaveLength, 8)
+	// the walls are 2000mm to the front and back and 1000mm to the sides, and the ceiling 1500mm up.
+	for i, want := range []float64{2000, 1500 * math.Sqrt2, 1000, 1000 * math.Sqrt2, 2000, 1500 * math.Sqrt2, 1000, 1000 * math.Sqrt2} {
+		test.That(t, scan.Measurements[i].RangeMM, test.ShouldAlmostEqual, want)
+	}
+
+	props, err := l.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.Channels, test.ShouldEqual, 2)
+	test.That(t, props.Kind, test.ShouldEqual, lidar.KindSpinning)
+}
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	l := newFake(t, &fake.Config{AngularResolutionDegrees: 10})
+	readings, err := l.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// readings survive conversion to and from their wire form.
+	pbReadings, err := protoutils.ReadingGoToProto(readings)
+	test.That(t, err, test.ShouldBeNil)
+	readings, err = protoutils.ReadingProtoToGo(pbReadings)
+	test.That(t, err, test.ShouldBeNil)
+	scan, err := lidar.ScanFromReadings(readings)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, scan.Measurements, test.ShouldHaveLength, 36)
+	test.That(t, scan.Measurements[0].RangeMM, test.ShouldAlmostEqual, 3000)
+	test.That(t, scan.StartedAt.IsZero(), test.ShouldBeFalse)
+
+	_, err = lidar.ScanFromReadings(map[string]interface{}{"ranges_mm": []interface{}{1.0}})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestFromResource(t *testing.T) {
+	ctx := context.Background()
+	l := newFake(t, &fake.Config{AngularResolutionDegrees: 90})
+
+	// a module provides the lidar as a generic component that handles the lidar commands.
+	res := inject.NewGenericComponent("lidar")
+	res.DoFunc = l.DoCommand
+	remote := lidar.FromResource(res)
+	scan, err := remote.Scan(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, scan.Measurements, test.ShouldHaveLength, 4)
+	test.That(t, scan.Measurements[1].Azimuth, test.ShouldAlmostEqual, math.Pi/2)
+	test.That(t, scan.Measurements[1].RangeMM, test.ShouldAlmostEqual, 2000)
+
+	props, err := remote.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.Channels, test.ShouldEqual, 1)
+	test.That(t, props.MaxAzimuth, test.ShouldAlmostEqual, 2*math.Pi)
+
+	test.That(t, lidar.FromResource(l), test.ShouldEqual, l)
+}
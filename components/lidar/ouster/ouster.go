@@ -0,0 +1,326 @@
+// Package ouster implements Ouster OS series lidars, which are multi-beam spinning lidars that
+// stream their measurements as UDP lidar packets and are configured over HTTP.
+package ouster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/lidar"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// Model is the model of Ouster lidars.
+var Model = resource.DefaultModelFamily.WithModel("ouster")
+
+const (
+	defaultLidarPort = 7502
+	httpTimeout      = 10 * time.Second
+	// maxPacketSize is more than the largest lidar packet, of a 128 beam sensor.
+	maxPacketSize = 65536
+)
+
+func init() {
+	resource.RegisterComponent(lidar.API, Model, resource.Registration[lidar.Lidar, *Config]{
+		Constructor: NewLidar,
+	})
+}
+
+// Config is the config of an Ouster lidar.
+type Config struct {
+	// Host is the hostname or IP address of the sensor, such as os-122201000999.local.
+	Host string `json:"host"`
+	// LidarPort is the UDP port lidar packets are received on, 7502 by default.
+	LidarPort int `json:"lidar_port,omitempty"`
+	// UDPDest, if set, configures the sensor to send its lidar packets to this address, which should
+	// be one of this machine. Otherwise the sensor must already be configured to.
+	UDPDest string `json:"udp_dest,omitempty"`
+	// LidarMode, if set, configures the columns and rate of the sensor's scans, such as 1024x10.
+	LidarMode string `json:"lidar_mode,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.Host == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "host")
+	}
+	if conf.LidarPort < 0 || conf.LidarPort > math.MaxUint16 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("lidar_port must be a valid port"))
+	}
+	return nil, nil, nil
+}
+
+type ouster struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	md      *metadata
+	conn    net.PacketConn
+	logger  logging.Logger
+	workers *utils.StoppableWorkers
+
+	mu      sync.Mutex
+	latest  *lidar.Scan
+	readErr error
+	// scanned is closed and replaced when a scan completes or reading fails.
+	scanned chan struct{}
+}
+
+// NewLidar configures the sensor if asked, reads its metadata and starts receiving its scans.
+func NewLidar(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (lidar.Lidar, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	port := newConf.LidarPort
+	if port == 0 {
+		port = defaultLidarPort
+	}
+	return newLidar(ctx, conf.ResourceName(), newConf, port, logger)
+}
+
+// newLidar receives the sensor's scans on port, which may be 0 to receive them on any.
+func newLidar(ctx context.Context, name resource.Name, conf *Config, port int, logger logging.Logger) (*ouster, error) {
+	conn, err := net.ListenPacket("udp", ":"+strconv.Itoa(port))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to listen for lidar packets")
+	}
+	port = conn.LocalAddr().(*net.UDPAddr).Port
+	client := &http.Client{Timeout: httpTimeout}
+	if conf.UDPDest != "" || conf.LidarMode != "" {
+		if err := configure(ctx, client, conf, port); err != nil {
+			return nil, multierr.Combine(err, conn.Close())
+		}
+	}
+	md, err := getMetadata(ctx, client, conf.Host)
+	if err != nil {
+		return nil, multierr.Combine(err, conn.Close())
+	}
+	if md.ConfigParams.UDPPortLidar != 0 && md.ConfigParams.UDPPortLidar != port {
+		logger.Warnw("sensor sends lidar packets to another port", "sensor_port", md.ConfigParams.UDPPortLidar, "port", port)
+	}
+	logger.Infow("connected to lidar", "product", md.SensorInfo.ProdLine, "serial", md.SensorInfo.ProdSN,
+		"firmware", md.SensorInfo.BuildRev, "mode", md.ConfigParams.LidarMode, "profile", md.LidarDataFormat.UDPProfileLidar)
+	l := &ouster{
+		Named:   name.AsNamed(),
+		md:      md,
+		conn:    conn,
+		logger:  logger,
+		scanned: make(chan struct{}),
+	}
+	l.workers = utils.NewBackgroundStoppableWorkers(l.receive)
+	return l, nil
+}
+
+// configure sets the destination and mode of the sensor's lidar packets.
+func configure(ctx context.Context, client *http.Client, conf *Config, port int) error {
+	params := map[string]interface{}{}
+	if conf.UDPDest != "" {
+		params["udp_dest"] = conf.UDPDest
+		params["udp_port_lidar"] = port
+	}
+	if conf.LidarMode != "" {
+		params["lidar_mode"] = conf.LidarMode
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sensorURL(conf.Host, "config"), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to configure the lidar")
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("failed to configure the lidar: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// getMetadata returns the validated metadata of the sensor.
+func getMetadata(ctx context.Context, client *http.Client, host string) (*metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sensorURL(host, "metadata"), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the metadata of the lidar")
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to get the metadata of the lidar: %s", resp.Status)
+	}
+	md := &metadata{}
+	if err := json.NewDecoder(resp.Body).Decode(md); err != nil {
+		return nil, errors.Wrap(err, "invalid lidar metadata")
+	}
+	if err := md.validate(); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+func sensorURL(host, endpoint string) string {
+	return fmt.Sprintf("http://%s/api/v1/sensor/%s", host, endpoint)
+}
+
+// receive receives lidar packets until the lidar is closed, keeping each frame as the latest scan.
+// A frame is complete once its last column is received, or when a packet of the next arrives.
+func (l *ouster) receive(ctx context.Context) {
+	window := l.md.LidarDataFormat.ColumnWindow
+	var current *lidar.Scan
+	var currentFrame, lastFrame uint16
+	published := false
+	packet := make([]byte, maxPacketSize)
+	for ctx.Err() == nil {
+		n, _, err := l.conn.ReadFrom(packet)
+		if err != nil {
+			if ctx.Err() != nil {
+				err = errors.New("lidar is closed")
+			}
+			l.publish(nil, errors.Wrap(err, "failed to receive lidar packets"))
+			return
+		}
+		now := time.Now()
+		columns, err := l.md.parsePacket(packet[:n])
+		if err != nil {
+			l.logger.Debugw("ignoring invalid lidar packet", "error", err)
+			continue
+		}
+		for _, c := range columns {
+			if published && c.frameID == lastFrame {
+				continue
+			}
+			if current != nil && c.frameID != currentFrame {
+				l.publish(current, nil)
+				current = nil
+			}
+			if current == nil {
+				current, currentFrame, published = &lidar.Scan{StartedAt: now}, c.frameID, false
+			}
+			l.addColumn(current, c)
+			current.EndedAt = now
+			if c.measurementID == window[1] {
+				l.publish(current, nil)
+				current, lastFrame, published = nil, c.frameID, true
+			}
+		}
+	}
+}
+
+// addColumn adds the measurements of c to scan, unless the sensor marked it invalid.
+func (l *ouster) addColumn(scan *lidar.Scan, c column) {
+	if !c.valid {
+		return
+	}
+	encoder := l.md.columnAzimuth(c.measurementID)
+	intrinsics := l.md.BeamIntrinsics
+	for p, r := range c.ranges {
+		azimuth := math.Mod(encoder-intrinsics.BeamAzimuthAngles[p]*math.Pi/180+4*math.Pi, 2*math.Pi)
+		scan.Measurements = append(scan.Measurements, lidar.Measurement{
+			Azimuth:   azimuth,
+			Elevation: intrinsics.BeamAltitudeAngles[p] * math.Pi / 180,
+			RangeMM:   float64(r),
+			Intensity: float64(c.signals[p]),
+		})
+	}
+}
+
+// publish keeps scan as the latest, or err as the reason there will be no more, and wakes those
+// waiting for it.
+func (l *ouster) publish(scan *lidar.Scan, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if scan != nil {
+		l.latest = scan
+	}
+	if err != nil {
+		l.readErr = err
+	}
+	close(l.scanned)
+	l.scanned = make(chan struct{})
+}
+
+// Scan returns the latest complete frame, waiting for the first. Its times are when its first and
+// last packets were received.
+func (l *ouster) Scan(ctx context.Context, extra map[string]interface{}) (*lidar.Scan, error) {
+	for {
+		l.mu.Lock()
+		latest, readErr, scanned := l.latest, l.readErr, l.scanned
+		l.mu.Unlock()
+		if readErr != nil {
+			return nil, readErr
+		}
+		if latest != nil {
+			return latest, nil
+		}
+		select {
+		case <-scanned:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Properties returns the beams and scan rate of the sensor from its metadata.
+func (l *ouster) Properties(ctx context.Context) (lidar.Properties, error) {
+	elevations := make([]float64, len(l.md.BeamIntrinsics.BeamAltitudeAngles))
+	for i, altitude := range l.md.BeamIntrinsics.BeamAltitudeAngles {
+		elevations[i] = altitude * math.Pi / 180
+	}
+	return lidar.Properties{
+		Kind:              lidar.KindSpinning,
+		Channels:          l.md.LidarDataFormat.PixelsPerColumn,
+		ElevationAngles:   elevations,
+		MinAzimuth:        0,
+		MaxAzimuth:        2 * math.Pi,
+		AngularResolution: 2 * math.Pi / float64(l.md.LidarDataFormat.ColumnsPerFrame),
+		ScanRateHz:        l.md.scanRateHz(),
+		HasIntensity:      true,
+	}, nil
+}
+
+// Readings returns the latest scan in the form produced by lidar.ScanToReadings.
+func (l *ouster) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	scan, err := l.Scan(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return lidar.ScanToReadings(scan)
+}
+
+// DoCommand handles the lidar DoCommand keys.
+func (l *ouster) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, ok, err := lidar.HandleLidarCommand(ctx, l, cmd); ok {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+// Close stops receiving lidar packets.
+func (l *ouster) Close(ctx context.Context) error {
+	err := l.conn.Close()
+	l.workers.Stop()
+	return err
+}
@@ -0,0 +1,164 @@
+package ouster
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/lidar"
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	testColumns  = 32
+	testPerPack  = 16
+	testChannels = 4
+)
+
+func testMetadata(profile string) *metadata {
+	md := &metadata{}
+	md.SensorInfo.ProdLine = "OS-1-4"
+	md.LidarDataFormat.ColumnsPerFrame = testColumns
+	md.LidarDataFormat.ColumnsPerPacket = testPerPack
+	md.LidarDataFormat.PixelsPerColumn = testChannels
+	md.LidarDataFormat.UDPProfileLidar = profile
+	md.BeamIntrinsics.BeamAltitudeAngles = []float64{15, 5, -5, -15}
+	md.BeamIntrinsics.BeamAzimuthAngles = []float64{0, 0, 0, 0}
+	md.ConfigParams.LidarMode = "32x10"
+	return md
+}
+
+// encodePacket returns a packet of the columns from first, whose ranges are the column's
+// measurement id plus 1000 times the pixel plus 1000, and whose signals are the pixel.
+func encodePacket(md *metadata, frameID uint16, first int) []byte {
+	packet := make([]byte, md.packetSize())
+	legacy := md.LidarDataFormat.UDPProfileLidar == profileLegacy
+	offset := 0
+	if !legacy {
+		binary.LittleEndian.PutUint16(packet[2:], frameID)
+		offset = rng19PacketHeaderSize
+	}
+	for c := 0; c < md.LidarDataFormat.ColumnsPerPacket; c++ {
+		binary.LittleEndian.PutUint16(packet[offset+8:], uint16(first+c))
+		if legacy {
+			binary.LittleEndian.PutUint16(packet[offset+10:], frameID)
+			offset += legacyColumnHeaderSize
+		} else {
+			binary.LittleEndian.PutUint16(packet[offset+10:], 1)
+			offset += rng19ColumnHeaderSize
+		}
+		for p := 0; p < md.LidarDataFormat.PixelsPerColumn; p++ {
+			binary.LittleEndian.PutUint32(packet[offset:], uint32(first+c+1000*(p+1)))
+			binary.LittleEndian.PutUint16(packet[offset+6:], uint16(p))
+			offset += pixelSize
+		}
+		if legacy {
+			binary.LittleEndian.PutUint32(packet[offset:], legacyValidStatus)
+			offset += legacyColumnFooterSize
+		}
+	}
+	return packet
+}
+
+func TestParsePacket(t *testing.T) {
+	for _, profile := range []string{profileLegacy, profileRNG19} {
+		md := testMetadata(profile)
+		test.That(t, md.validate(), test.ShouldBeNil)
+		columns, err := md.parsePacket(encodePacket(md, 7, 16))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, columns, test.ShouldHaveLength, testPerPack)
+		for i, c := range columns {
+			test.That(t, c.frameID, test.ShouldEqual, 7)
+			test.That(t, c.measurementID, test.ShouldEqual, 16+i)
+			test.That(t, c.valid, test.ShouldBeTrue)
+			test.That(t, c.ranges, test.ShouldResemble, []uint32{uint32(1016 + i), uint32(2016 + i), uint32(3016 + i), uint32(4016 + i)})
+			test.That(t, c.signals, test.ShouldResemble, []uint16{0, 1, 2, 3})
+		}
+		_, err = md.parsePacket(make([]byte, 10))
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+
+	md := testMetadata("FUSA_RNG15_RFL8_NIR8_DUAL")
+	test.That(t, md.validate(), test.ShouldNotBeNil)
+	md = testMetadata("")
+	md.BeamIntrinsics.BeamAzimuthAngles = nil
+	test.That(t, md.validate(), test.ShouldNotBeNil)
+	md = testMetadata("")
+	test.That(t, md.validate(), test.ShouldBeNil)
+	test.That(t, md.LidarDataFormat.UDPProfileLidar, test.ShouldEqual, profileLegacy)
+	test.That(t, md.LidarDataFormat.ColumnWindow, test.ShouldEqual, [2]int{0, testColumns - 1})
+	test.That(t, md.scanRateHz(), test.ShouldEqual, 10)
+}
+
+func TestLidar(t *testing.T) {
+	ctx := context.Background()
+	md := testMetadata(profileRNG19)
+	var mu sync.Mutex
+	var configured map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/sensor/metadata":
+			test.That(t, json.NewEncoder(w).Encode(md), test.ShouldBeNil)
+		case "/api/v1/sensor/config":
+			mu.Lock()
+			defer mu.Unlock()
+			test.That(t, json.NewDecoder(r.Body).Decode(&configured), test.ShouldBeNil)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	conf := &Config{Host: host, UDPDest: "127.0.0.1", LidarMode: "32x10"}
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	l, err := newLidar(ctx, lidar.Named("lidar"), conf, 0, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	port := l.conn.LocalAddr().(*net.UDPAddr).Port
+	mu.Lock()
+	test.That(t, configured, test.ShouldResemble, map[string]interface{}{
+		"udp_dest": "127.0.0.1", "udp_port_lidar": float64(port), "lidar_mode": "32x10",
+	})
+	mu.Unlock()
+
+	sender, err := net.Dial("udp", l.conn.LocalAddr().String())
+	test.That(t, err, test.ShouldBeNil)
+	defer sender.Close()
+	for first := 0; first < testColumns; first += testPerPack {
+		_, err := sender.Write(encodePacket(md, 1, first))
+		test.That(t, err, test.ShouldBeNil)
+	}
+
+	scan, err := l.Scan(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, scan.Measurements, test.ShouldHaveLength, testColumns*testChannels)
+	first := scan.Measurements[0]
+	test.That(t, first.Azimuth, test.ShouldAlmostEqual, 0)
+	test.That(t, first.Elevation, test.ShouldAlmostEqual, 15*math.Pi/180)
+	test.That(t, first.RangeMM, test.ShouldEqual, 1000)
+	// the encoder turns clockwise, so the next column is at a smaller azimuth.
+	test.That(t, scan.Measurements[testChannels].Azimuth, test.ShouldAlmostEqual, 2*math.Pi*(1-1./testColumns))
+	test.That(t, scan.Measurements[testChannels+3].Intensity, test.ShouldEqual, 3)
+
+	props, err := l.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.Channels, test.ShouldEqual, testChannels)
+	test.That(t, props.ScanRateHz, test.ShouldEqual, 10)
+	test.That(t, props.AngularResolution, test.ShouldAlmostEqual, 2*math.Pi/testColumns)
+
+	test.That(t, l.Close(ctx), test.ShouldBeNil)
+
+	_, err = newLidar(ctx, lidar.Named("lidar"), &Config{Host: "127.0.0.1:1"}, 0, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+}
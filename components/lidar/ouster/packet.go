@@ -0,0 +1,168 @@
+package ouster
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The profiles of lidar packets this package parses.
+const (
+	profileLegacy = "LEGACY"
+	profileRNG19  = "RNG19_RFL8_SIG16_NIR16"
+)
+
+const (
+	pixelSize = 12
+	// legacy columns have a header of their timestamp, measurement id, frame id and encoder count,
+	// and a footer of their status.
+	legacyColumnHeaderSize = 16
+	legacyColumnFooterSize = 4
+	legacyRangeMask        = 0xFFFFF
+	legacyValidStatus      = 0xFFFFFFFF
+	// RNG19 packets have a header, including their frame id, and a footer, and their columns a
+	// header of their timestamp, measurement id and status.
+	rng19PacketHeaderSize = 32
+	rng19PacketFooterSize = 32
+	rng19ColumnHeaderSize = 12
+	rng19RangeMask        = 0x7FFFF
+)
+
+// metadata is the part of the metadata of a sensor, as served at /api/v1/sensor/metadata, needed
+// to make scans of its packets.
+type metadata struct {
+	SensorInfo struct {
+		ProdLine string `json:"prod_line"`
+		ProdSN   string `json:"prod_sn"`
+		BuildRev string `json:"build_rev"`
+	} `json:"sensor_info"`
+	LidarDataFormat struct {
+		ColumnsPerFrame  int    `json:"columns_per_frame"`
+		ColumnsPerPacket int    `json:"columns_per_packet"`
+		PixelsPerColumn  int    `json:"pixels_per_column"`
+		ColumnWindow     [2]int `json:"column_window"`
+		UDPProfileLidar  string `json:"udp_profile_lidar"`
+	} `json:"lidar_data_format"`
+	BeamIntrinsics struct {
+		BeamAltitudeAngles []float64 `json:"beam_altitude_angles"`
+		BeamAzimuthAngles  []float64 `json:"beam_azimuth_angles"`
+	} `json:"beam_intrinsics"`
+	ConfigParams struct {
+		LidarMode    string `json:"lidar_mode"`
+		UDPPortLidar int    `json:"udp_port_lidar"`
+	} `json:"config_params"`
+}
+
+// validate ensures the packets the metadata describes can be parsed, defaulting the profile of
+// sensors whose firmware predates profiles to the legacy one.
+func (md *metadata) validate() error {
+	format := &md.LidarDataFormat
+	if format.ColumnsPerFrame <= 0 || format.ColumnsPerPacket <= 0 || format.PixelsPerColumn <= 0 {
+		return errors.Errorf("invalid lidar data format of %d columns per frame, %d per packet and %d pixels per column",
+			format.ColumnsPerFrame, format.ColumnsPerPacket, format.PixelsPerColumn)
+	}
+	if format.ColumnWindow == [2]int{} {
+		format.ColumnWindow = [2]int{0, format.ColumnsPerFrame - 1}
+	}
+	if len(md.BeamIntrinsics.BeamAltitudeAngles) != format.PixelsPerColumn ||
+		len(md.BeamIntrinsics.BeamAzimuthAngles) != format.PixelsPerColumn {
+		return errors.Errorf("expected beam angles for %d pixels but got %d altitudes and %d azimuths", format.PixelsPerColumn,
+			len(md.BeamIntrinsics.BeamAltitudeAngles), len(md.BeamIntrinsics.BeamAzimuthAngles))
+	}
+	switch format.UDPProfileLidar {
+	case "":
+		format.UDPProfileLidar = profileLegacy
+	case profileLegacy, profileRNG19:
+	default:
+		return errors.Errorf("unsupported lidar packet profile %q; configure the sensor for %s or %s",
+			format.UDPProfileLidar, profileRNG19, profileLegacy)
+	}
+	return nil
+}
+
+// packetSize returns the size of the lidar packets of the sensor.
+func (md *metadata) packetSize() int {
+	format := md.LidarDataFormat
+	pixels := format.PixelsPerColumn * pixelSize
+	if format.UDPProfileLidar == profileLegacy {
+		return format.ColumnsPerPacket * (legacyColumnHeaderSize + pixels + legacyColumnFooterSize)
+	}
+	return rng19PacketHeaderSize + format.ColumnsPerPacket*(rng19ColumnHeaderSize+pixels) + rng19PacketFooterSize
+}
+
+// scanRateHz returns the scan rate of the lidar mode, such as 10 for 1024x10.
+func (md *metadata) scanRateHz() float64 {
+	_, rate, ok := strings.Cut(md.ConfigParams.LidarMode, "x")
+	if !ok {
+		return 0
+	}
+	hz, err := strconv.ParseFloat(rate, 64)
+	if err != nil {
+		return 0
+	}
+	return hz
+}
+
+// A column is the measurements of every beam at one azimuth.
+type column struct {
+	frameID       uint16
+	measurementID int
+	valid         bool
+	// ranges are in millimeters, and zero where there was no return.
+	ranges  []uint32
+	signals []uint16
+}
+
+// parsePacket returns the columns of a lidar packet.
+func (md *metadata) parsePacket(packet []byte) ([]column, error) {
+	if len(packet) != md.packetSize() {
+		return nil, errors.Errorf("expected a lidar packet of %d bytes but got %d", md.packetSize(), len(packet))
+	}
+	format := md.LidarDataFormat
+	columns := make([]column, format.ColumnsPerPacket)
+	legacy := format.UDPProfileLidar == profileLegacy
+	offset := 0
+	var packetFrameID uint16
+	if !legacy {
+		packetFrameID = binary.LittleEndian.Uint16(packet[2:4])
+		offset = rng19PacketHeaderSize
+	}
+	for i := range columns {
+		c := column{
+			measurementID: int(binary.LittleEndian.Uint16(packet[offset+8:])),
+			ranges:        make([]uint32, format.PixelsPerColumn),
+			signals:       make([]uint16, format.PixelsPerColumn),
+		}
+		rangeMask := uint32(rng19RangeMask)
+		if legacy {
+			c.frameID = binary.LittleEndian.Uint16(packet[offset+10:])
+			offset += legacyColumnHeaderSize
+			rangeMask = legacyRangeMask
+		} else {
+			c.frameID = packetFrameID
+			c.valid = binary.LittleEndian.Uint16(packet[offset+10:])&1 == 1
+			offset += rng19ColumnHeaderSize
+		}
+		for p := 0; p < format.PixelsPerColumn; p++ {
+			c.ranges[p] = binary.LittleEndian.Uint32(packet[offset:]) & rangeMask
+			c.signals[p] = binary.LittleEndian.Uint16(packet[offset+6:])
+			offset += pixelSize
+		}
+		if legacy {
+			c.valid = binary.LittleEndian.Uint32(packet[offset:]) == legacyValidStatus
+			offset += legacyColumnFooterSize
+		}
+		columns[i] = c
+	}
+	return columns, nil
+}
+
+// columnAzimuth returns the azimuth of the encoder at a measurement, counterclockwise from the
+// connector of the sensor, which is +X of its lidar coordinate frame, as the encoder turns
+// clockwise from it.
+func (md *metadata) columnAzimuth(measurementID int) float64 {
+	return 2 * math.Pi * (1 - float64(measurementID)/float64(md.LidarDataFormat.ColumnsPerFrame))
+}
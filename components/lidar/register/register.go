@@ -0,0 +1,9 @@
+// Package register registers all relevant lidars and also API specific functions
+package register
+
+import (
+	// for lidars.
+	_ "go.viam.com/rdk/components/lidar/fake"
+	_ "go.viam.com/rdk/components/lidar/ouster"
+	_ "go.viam.com/rdk/components/lidar/rplidar"
+)
@@ -0,0 +1,157 @@
+package rplidar
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The Slamtec serial protocol: requests are a start flag, a command and, for some commands, a
+// payload with its size and checksum. Responses are a descriptor, of a start flag, the length and
+// send mode of the response and its data type, followed by the response data, which for scans is a
+// stream of 5-byte measurement nodes until the scan is stopped.
+const (
+	startFlag1 = 0xA5
+	startFlag2 = 0x5A
+
+	cmdStop        = 0x25
+	cmdScan        = 0x20
+	cmdGetInfo     = 0x50
+	cmdGetHealth   = 0x52
+	cmdSetMotorPWM = 0xF0
+
+	typeInfo   = 0x04
+	typeHealth = 0x06
+	typeScan   = 0x81
+
+	infoSize   = 20
+	healthSize = 3
+	nodeSize   = 5
+
+	healthWarning = 1
+	healthError   = 2
+)
+
+// encodeRequest returns the request of cmd, with its payload if it has one.
+func encodeRequest(cmd byte, payload []byte) []byte {
+	req := []byte{startFlag1, cmd}
+	if payload == nil {
+		return req
+	}
+	req = append(req, byte(len(payload)))
+	req = append(req, payload...)
+	var checksum byte
+	for _, b := range req {
+		checksum ^= b
+	}
+	return append(req, checksum)
+}
+
+// motorPWMRequest returns the request setting the duty cycle of the motor, from 0 to 1023.
+func motorPWMRequest(pwm int) []byte {
+	payload := make([]byte, 2)
+	binary.LittleEndian.PutUint16(payload, uint16(pwm))
+	return encodeRequest(cmdSetMotorPWM, payload)
+}
+
+type descriptor struct {
+	length   uint32
+	dataType byte
+}
+
+// readDescriptor reads the next response descriptor, skipping anything before it, such as the
+// rest of a scan that was stopped.
+func readDescriptor(r *bufio.Reader) (descriptor, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return descriptor{}, err
+		}
+		if b != startFlag1 {
+			continue
+		}
+		next, err := r.Peek(1)
+		if err != nil {
+			return descriptor{}, err
+		}
+		if next[0] != startFlag2 {
+			continue
+		}
+		buf := make([]byte, 6)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return descriptor{}, err
+		}
+		// the top two bits of the length are the send mode, which is implied by the data type.
+		return descriptor{length: binary.LittleEndian.Uint32(buf[1:5]) & 0x3FFFFFFF, dataType: buf[5]}, nil
+	}
+}
+
+// readResponse reads the descriptor of a response of dataType and size, and the response if it is
+// a single one rather than a stream.
+func readResponse(r *bufio.Reader, dataType byte, size int, stream bool) ([]byte, error) {
+	d, err := readDescriptor(r)
+	if err != nil {
+		return nil, err
+	}
+	if d.dataType != dataType || d.length != uint32(size) {
+		return nil, errors.Errorf("expected a response of type %#x and length %d but got type %#x and length %d",
+			dataType, size, d.dataType, d.length)
+	}
+	if stream {
+		return nil, nil
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(r, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// deviceInfo identifies a lidar.
+type deviceInfo struct {
+	Model           byte
+	FirmwareVersion string
+	Hardware        byte
+	SerialNumber    string
+}
+
+func parseInfo(resp []byte) deviceInfo {
+	return deviceInfo{
+		Model:           resp[0],
+		FirmwareVersion: fmt.Sprintf("%d.%02d", resp[2], resp[1]),
+		Hardware:        resp[3],
+		SerialNumber:    strings.ToUpper(hex.EncodeToString(resp[4:20])),
+	}
+}
+
+// A node is one measurement of a scan.
+type node struct {
+	// start marks the first node of a revolution.
+	start   bool
+	quality byte
+	// angle is clockwise from the front of the lidar, in degrees.
+	angle float64
+	// distance is in millimeters, or zero if the measurement failed.
+	distance float64
+}
+
+// parseNode parses a scan node, returning false if it is not a valid one, as when the stream is not
+// aligned to the start of a node.
+func parseNode(b []byte) (node, bool) {
+	start, notStart := b[0]&1, (b[0]>>1)&1
+	if start == notStart || b[1]&1 != 1 {
+		return node{}, false
+	}
+	angleQ6 := uint16(b[1])>>1 | uint16(b[2])<<7
+	return node{
+		start:    start == 1,
+		quality:  b[0] >> 2,
+		angle:    float64(angleQ6) / 64,
+		distance: float64(binary.LittleEndian.Uint16(b[3:5])) / 4,
+	}, true
+}
@@ -0,0 +1,332 @@
+// Package rplidar implements lidars of the Slamtec RPLIDAR A and S series, which are 2D spinning
+// lidars connected over serial, such as through their USB adapters.
+package rplidar
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/lidar"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// Model is the model of Slamtec RPLIDAR lidars.
+var Model = resource.DefaultModelFamily.WithModel("rplidar")
+
+const (
+	defaultBaudRate = 115200
+	defaultMotorPWM = 660
+	maxMotorPWM     = 1023
+	// handshakeTimeout bounds how long the lidar has to answer each request before scanning.
+	handshakeTimeout = 2 * time.Second
+	// minScanSize keeps the partial revolution before the first start node from being a scan.
+	minScanSize = 8
+)
+
+// DoGetDeviceInfo is the DoCommand key that returns the model, firmware version, hardware version
+// and serial number of the lidar.
+const DoGetDeviceInfo = "get_device_info"
+
+func init() {
+	resource.RegisterComponent(lidar.API, Model, resource.Registration[lidar.Lidar, *Config]{
+		Constructor: NewLidar,
+	})
+}
+
+// Config is the config of a Slamtec RPLIDAR.
+type Config struct {
+	SerialPath string `json:"serial_path"`
+	// BaudRate is 115200 by default, as for the A1 and A2. The A3 and S1 use 256000 and the S2 1000000.
+	BaudRate int `json:"baud_rate,omitempty"`
+	// MotorPWM is the duty cycle, from 1 to 1023, of the motors of lidars whose speed is set over serial,
+	// such as the A2 and A3. It is 660 by default.
+	MotorPWM int `json:"motor_pwm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.SerialPath == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "serial_path")
+	}
+	if conf.BaudRate < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("baud_rate cannot be negative"))
+	}
+	if conf.MotorPWM < 0 || conf.MotorPWM > maxMotorPWM {
+		return nil, nil, resource.NewConfigValidationError(path, errors.Errorf("motor_pwm must be between 0 and %d", maxMotorPWM))
+	}
+	return nil, nil, nil
+}
+
+// dtrSetter is a serial port whose DTR line can be set.
+type dtrSetter interface {
+	SetDTR(on bool) error
+}
+
+// readDeadliner is a connection whose reads can time out.
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+type rplidar struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	port   io.ReadWriteCloser
+	reader *bufio.Reader
+	info   deviceInfo
+	logger logging.Logger
+
+	workers *utils.StoppableWorkers
+
+	mu      sync.Mutex
+	latest  *lidar.Scan
+	readErr error
+	// scanned is closed and replaced when a scan completes or reading fails.
+	scanned chan struct{}
+}
+
+// NewLidar opens the lidar on its serial port and starts it scanning.
+func NewLidar(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger) (lidar.Lidar, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	baudRate := newConf.BaudRate
+	if baudRate == 0 {
+		baudRate = defaultBaudRate
+	}
+	port, err := openSerial(newConf.SerialPath, baudRate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open lidar on %s", newConf.SerialPath)
+	}
+	motorPWM := newConf.MotorPWM
+	if motorPWM == 0 {
+		motorPWM = defaultMotorPWM
+	}
+	return newLidar(conf.ResourceName(), port, motorPWM, logger)
+}
+
+// newLidar starts the lidar on port scanning.
+func newLidar(name resource.Name, port io.ReadWriteCloser, motorPWM int, logger logging.Logger) (*rplidar, error) {
+	l := &rplidar{
+		Named:   name.AsNamed(),
+		port:    port,
+		reader:  bufio.NewReader(port),
+		logger:  logger,
+		scanned: make(chan struct{}),
+	}
+	if err := l.start(motorPWM); err != nil {
+		return nil, multierr.Combine(err, l.stop(), port.Close())
+	}
+	l.workers = utils.NewBackgroundStoppableWorkers(l.readScans)
+	return l, nil
+}
+
+func (l *rplidar) request(cmd byte, payload []byte) error {
+	_, err := l.port.Write(encodeRequest(cmd, payload))
+	return err
+}
+
+// start checks the health of the lidar, starts its motor and starts it scanning.
+func (l *rplidar) start(motorPWM int) error {
+	if deadliner, ok := l.port.(readDeadliner); ok {
+		if err := deadliner.SetReadDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+			return err
+		}
+		//nolint:errcheck
+		defer deadliner.SetReadDeadline(time.Time{})
+	}
+	// a lidar left scanning is stopped, and the rest of its scan skipped when reading the responses.
+	if err := l.request(cmdStop, nil); err != nil {
+		return err
+	}
+
+	if err := l.request(cmdGetHealth, nil); err != nil {
+		return err
+	}
+	health, err := readResponse(l.reader, typeHealth, healthSize, false)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the health of the lidar")
+	}
+	code := int(health[1]) | int(health[2])<<8
+	switch health[0] {
+	case healthWarning:
+		l.logger.Warnw("lidar reports a warning", "code", code)
+	case healthError:
+		return errors.Errorf("lidar reports error %d and must be reset or power cycled", code)
+	}
+
+	if err := l.request(cmdGetInfo, nil); err != nil {
+		return err
+	}
+	info, err := readResponse(l.reader, typeInfo, infoSize, false)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the info of the lidar")
+	}
+	l.info = parseInfo(info)
+	l.logger.Infow("connected to lidar", "model", l.info.Model, "firmware", l.info.FirmwareVersion, "serial", l.info.SerialNumber)
+
+	if err := l.setMotor(motorPWM); err != nil {
+		return err
+	}
+	if err := l.request(cmdScan, nil); err != nil {
+		return err
+	}
+	_, err = readResponse(l.reader, typeScan, nodeSize, true)
+	return errors.Wrap(err, "failed to start the lidar scanning")
+}
+
+// setMotor runs the motor at pwm, or stops it if zero. Motors switched by DTR, such as that of the
+// A1, run while it is low, and others at the duty cycle set over serial.
+func (l *rplidar) setMotor(pwm int) error {
+	if dtr, ok := l.port.(dtrSetter); ok {
+		if err := dtr.SetDTR(pwm == 0); err != nil {
+			return err
+		}
+	}
+	_, err := l.port.Write(motorPWMRequest(pwm))
+	return err
+}
+
+// stop stops the lidar scanning and its motor.
+func (l *rplidar) stop() error {
+	return multierr.Combine(l.request(cmdStop, nil), l.setMotor(0))
+}
+
+// readScans reads the nodes of the scan until the lidar is closed, keeping each revolution as the
+// latest scan.
+func (l *rplidar) readScans(ctx context.Context) {
+	var current *lidar.Scan
+	buf := make([]byte, nodeSize)
+	_, err := io.ReadFull(l.reader, buf)
+	for err == nil && ctx.Err() == nil {
+		n, ok := parseNode(buf)
+		if !ok {
+			// the stream is realigned a byte at a time until it holds valid nodes.
+			copy(buf, buf[1:])
+			buf[nodeSize-1], err = l.reader.ReadByte()
+			continue
+		}
+		now := time.Now()
+		if n.start {
+			if current != nil && len(current.Measurements) >= minScanSize {
+				l.publish(current, nil)
+			}
+			current = &lidar.Scan{StartedAt: now}
+		}
+		if current != nil {
+			current.Measurements = append(current.Measurements, lidar.Measurement{
+				// the lidar measures clockwise angles, which are negated to be counterclockwise.
+				Azimuth:   math.Mod(2*math.Pi-n.angle*math.Pi/180, 2*math.Pi),
+				RangeMM:   n.distance,
+				Intensity: float64(n.quality),
+			})
+			current.EndedAt = now
+		}
+		_, err = io.ReadFull(l.reader, buf)
+	}
+	if ctx.Err() != nil {
+		err = errors.New("lidar is closed")
+	}
+	l.publish(nil, errors.Wrap(err, "failed to read from the lidar"))
+}
+
+// publish keeps scan as the latest, or err as the reason there will be no more, and wakes those
+// waiting for it.
+func (l *rplidar) publish(scan *lidar.Scan, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if scan != nil {
+		l.latest = scan
+	}
+	if err != nil {
+		l.readErr = err
+	}
+	close(l.scanned)
+	l.scanned = make(chan struct{})
+}
+
+// Scan returns the latest complete revolution, waiting for the first.
+func (l *rplidar) Scan(ctx context.Context, extra map[string]interface{}) (*lidar.Scan, error) {
+	for {
+		l.mu.Lock()
+		latest, readErr, scanned := l.latest, l.readErr, l.scanned
+		l.mu.Unlock()
+		if readErr != nil {
+			return nil, readErr
+		}
+		if latest != nil {
+			return latest, nil
+		}
+		select {
+		case <-scanned:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Properties returns the properties of a 2D spinning lidar, with the angular resolution of its
+// latest scan.
+func (l *rplidar) Properties(ctx context.Context) (lidar.Properties, error) {
+	props := lidar.Properties{
+		Kind:            lidar.KindSpinning,
+		Channels:        1,
+		ElevationAngles: []float64{0},
+		MinAzimuth:      0,
+		MaxAzimuth:      2 * math.Pi,
+		HasIntensity:    true,
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.latest != nil {
+		props.AngularResolution = 2 * math.Pi / float64(len(l.latest.Measurements))
+		if d := l.latest.EndedAt.Sub(l.latest.StartedAt); d > 0 {
+			props.ScanRateHz = float64(time.Second) / float64(d)
+		}
+	}
+	return props, nil
+}
+
+// Readings returns the latest scan in the form produced by lidar.ScanToReadings.
+func (l *rplidar) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	scan, err := l.Scan(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return lidar.ScanToReadings(scan)
+}
+
+// DoCommand handles the lidar DoCommand keys and DoGetDeviceInfo.
+func (l *rplidar) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if cmd[DoGetDeviceInfo] != nil {
+		return map[string]interface{}{
+			"model":            int(l.info.Model),
+			"firmware_version": l.info.FirmwareVersion,
+			"hardware_version": int(l.info.Hardware),
+			"serial_number":    l.info.SerialNumber,
+		}, nil
+	}
+	if resp, ok, err := lidar.HandleLidarCommand(ctx, l, cmd); ok {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+// Close stops the lidar and closes its serial port.
+func (l *rplidar) Close(ctx context.Context) error {
+	err := l.stop()
+	// closing the port ends the reads of the worker.
+	err = multierr.Combine(err, l.port.Close())
+	l.workers.Stop()
+	return err
+}
@@ -0,0 +1,184 @@
+package rplidar
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/lidar"
+	"go.viam.com/rdk/logging"
+)
+
+const nodesPerRevolution = 360
+
+func encodeDescriptor(length uint32, mode, dataType byte) []byte {
+	b := []byte{startFlag1, startFlag2, 0, 0, 0, 0, dataType}
+	binary.LittleEndian.PutUint32(b[2:6], length|uint32(mode)<<30)
+	return b
+}
+
+func encodeNode(n node) []byte {
+	b := make([]byte, nodeSize)
+	b[0] = n.quality<<2 | 0b10
+	if n.start {
+		b[0] = n.quality<<2 | 0b01
+	}
+	angleQ6 := uint16(n.angle * 64)
+	b[1] = byte(angleQ6<<1) | 1
+	b[2] = byte(angleQ6 >> 7)
+	binary.LittleEndian.PutUint16(b[3:], uint16(n.distance*4))
+	return b
+}
+
+// fakeDevice answers the requests of a lidar whose scans measure 1000mm all around, at the angle
+// in degrees times 10mm at the front, and whose health is given.
+type fakeDevice struct {
+	conn   net.Conn
+	health byte
+
+	mu       sync.Mutex
+	commands []byte
+	pwms     []int
+}
+
+func (d *fakeDevice) serve() {
+	r := bufio.NewReader(d.conn)
+	for {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return
+		}
+		cmd := header[1]
+		d.mu.Lock()
+		d.commands = append(d.commands, cmd)
+		d.mu.Unlock()
+		switch cmd {
+		case cmdSetMotorPWM:
+			payload := make([]byte, 4)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			d.mu.Lock()
+			d.pwms = append(d.pwms, int(binary.LittleEndian.Uint16(payload[1:3])))
+			d.mu.Unlock()
+		case cmdGetHealth:
+			// a leftover node of an earlier scan comes before the response.
+			resp := append(encodeNode(node{angle: 1, distance: 100}), encodeDescriptor(healthSize, 0, typeHealth)...)
+			utils.UncheckedError(d.write(append(resp, d.health, 7, 0)))
+		case cmdGetInfo:
+			resp := append(encodeDescriptor(infoSize, 0, typeInfo), 0x18, 29, 1, 7)
+			utils.UncheckedError(d.write(append(resp, make([]byte, 16)...)))
+		case cmdScan:
+			utils.UncheckedError(d.write(encodeDescriptor(nodeSize, 1, typeScan)))
+			go d.stream()
+		}
+	}
+}
+
+func (d *fakeDevice) write(b []byte) error {
+	_, err := d.conn.Write(b)
+	return err
+}
+
+func (d *fakeDevice) stream() {
+	// a misaligned byte comes first.
+	if d.write([]byte{0}) != nil {
+		return
+	}
+	for {
+		for i := 0; i < nodesPerRevolution; i++ {
+			n := node{start: i == 0, quality: 47, angle: float64(i), distance: 1000}
+			if i < 10 {
+				n.distance = float64(i) * 10
+			}
+			if d.write(encodeNode(n)) != nil {
+				return
+			}
+		}
+	}
+}
+
+func newTestLidar(t *testing.T, health byte) (*rplidar, *fakeDevice, error) {
+	t.Helper()
+	port, conn := net.Pipe()
+	device := &fakeDevice{conn: conn, health: health}
+	go device.serve()
+	l, err := newLidar(lidar.Named("lidar"), port, defaultMotorPWM, logging.NewTestLogger(t))
+	return l, device, err
+}
+
+func TestProtocol(t *testing.T) {
+	test.That(t, encodeRequest(cmdScan, nil), test.ShouldResemble, []byte{0xA5, 0x20})
+	// the checksum is the XOR of every byte before it.
+	test.That(t, motorPWMRequest(660), test.ShouldResemble, []byte{0xA5, 0xF0, 2, 0x94, 0x02, 0xA5 ^ 0xF0 ^ 2 ^ 0x94 ^ 0x02})
+
+	n, ok := parseNode(encodeNode(node{start: true, quality: 15, angle: 90.5, distance: 1234.25}))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, n, test.ShouldResemble, node{start: true, quality: 15, angle: 90.5, distance: 1234.25})
+	_, ok = parseNode([]byte{0b11, 1, 0, 0, 0})
+	test.That(t, ok, test.ShouldBeFalse)
+	_, ok = parseNode([]byte{0b01, 0, 0, 0, 0})
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestLidar(t *testing.T) {
+	ctx := context.Background()
+	l, device, err := newTestLidar(t, healthWarning)
+	test.That(t, err, test.ShouldBeNil)
+
+	scan, err := l.Scan(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, scan.Measurements, test.ShouldHaveLength, nodesPerRevolution)
+	test.That(t, scan.EndedAt.Before(scan.StartedAt), test.ShouldBeFalse)
+	for i, m := range scan.Measurements {
+		test.That(t, m.Intensity, test.ShouldEqual, 47)
+		test.That(t, m.Elevation, test.ShouldEqual, 0)
+		if i >= 10 {
+			test.That(t, m.RangeMM, test.ShouldEqual, 1000)
+		}
+	}
+	// the lidar's clockwise angles are counterclockwise azimuths.
+	test.That(t, scan.Measurements[0].Azimuth, test.ShouldEqual, 0)
+	test.That(t, scan.Measurements[90].Azimuth, test.ShouldAlmostEqual, 3*math.Pi/2)
+	test.That(t, scan.Measurements[5].RangeMM, test.ShouldEqual, 50)
+
+	props, err := l.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.Kind, test.ShouldEqual, lidar.KindSpinning)
+	test.That(t, props.Channels, test.ShouldEqual, 1)
+	test.That(t, props.AngularResolution, test.ShouldAlmostEqual, 2*math.Pi/nodesPerRevolution)
+
+	info, err := l.DoCommand(ctx, map[string]interface{}{DoGetDeviceInfo: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, info["model"], test.ShouldEqual, 0x18)
+	test.That(t, info["firmware_version"], test.ShouldEqual, "1.29")
+
+	resp, err := l.DoCommand(ctx, map[string]interface{}{lidar.DoGetScan: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["ranges_mm"], test.ShouldHaveLength, nodesPerRevolution)
+
+	test.That(t, l.Close(ctx), test.ShouldBeNil)
+	_, err = l.Scan(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	device.conn.Close()
+
+	device.mu.Lock()
+	defer device.mu.Unlock()
+	test.That(t, device.commands[:4], test.ShouldResemble, []byte{cmdStop, cmdGetHealth, cmdGetInfo, cmdSetMotorPWM})
+	test.That(t, device.pwms, test.ShouldResemble, []int{defaultMotorPWM, 0})
+}
+
+func TestUnhealthy(t *testing.T) {
+	_, device, err := newTestLidar(t, healthError)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "error 7")
+	device.conn.Close()
+}
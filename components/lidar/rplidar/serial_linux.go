@@ -0,0 +1,83 @@
+//go:build linux && !ppc && !ppc64 && !ppc64le
+
+package rplidar
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"go.uber.org/multierr"
+	"golang.org/x/sys/unix"
+)
+
+// serialPort is a serial device whose DTR line can be set, which switches the motor of lidars such
+// as the A1 on and off.
+type serialPort struct {
+	*os.File
+}
+
+// openSerial opens the serial device at path in raw mode at the given baud rate, which may be a
+// nonstandard rate such as the 256000 of the A3 and S1.
+func openSerial(path string, baudRate int) (io.ReadWriteCloser, error) {
+	//nolint:gosec
+	f, err := os.OpenFile(path, os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// the file descriptor is used through SyscallConn rather than Fd, which would make it blocking
+	// and so keep Close from interrupting reads.
+	rawConn, err := f.SyscallConn()
+	if err != nil {
+		return nil, multierr.Combine(err, f.Close())
+	}
+	var termiosErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		termiosErr = makeRaw(int(fd), uint32(baudRate))
+	}); err != nil {
+		return nil, multierr.Combine(err, f.Close())
+	}
+	if termiosErr != nil {
+		return nil, multierr.Combine(fmt.Errorf("configuring serial device %q: %w", path, termiosErr), f.Close())
+	}
+	return &serialPort{File: f}, nil
+}
+
+// makeRaw configures the terminal fd for 8N1 raw binary transfers at baudRate, set through termios2
+// so that rates without a Bnnn constant can be used.
+func makeRaw(fd int, baudRate uint32) error {
+	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS2)
+	if err != nil {
+		return err
+	}
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	termios.Cflag &^= unix.CSIZE | unix.PARENB | unix.CBAUD
+	termios.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL | unix.BOTHER
+	termios.Ispeed = baudRate
+	termios.Ospeed = baudRate
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	return unix.IoctlSetTermios(fd, unix.TCSETS2, termios)
+}
+
+// SetDTR raises or lowers the DTR line.
+func (p *serialPort) SetDTR(on bool) error {
+	rawConn, err := p.SyscallConn()
+	if err != nil {
+		return err
+	}
+	req := uint(unix.TIOCMBIC)
+	if on {
+		req = unix.TIOCMBIS
+	}
+	var ioctlErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		ioctlErr = unix.IoctlSetPointerInt(int(fd), req, unix.TIOCM_DTR)
+	}); err != nil {
+		return err
+	}
+	return ioctlErr
+}
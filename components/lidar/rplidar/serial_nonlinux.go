@@ -0,0 +1,15 @@
+//go:build !linux || ppc || ppc64 || ppc64le
+
+package rplidar
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// openSerial would open a serial device, but serial devices at the baud rates of lidars are only
+// supported on Linux.
+func openSerial(path string, baudRate int) (io.ReadWriteCloser, error) {
+	return nil, errors.New("serial connections to a lidar are only supported on linux")
+}
@@ -0,0 +1,177 @@
+package fusion
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// DefaultAlpha is how much complementary filters trust what they predict over what is measured.
+const DefaultAlpha = 0.98
+
+// complementaryFilter is a Filter that dead reckons position from velocity and blends it with
+// measured positions, weighting the prediction by alpha. Velocities and accelerations are the mean
+// of what was measured since the last prediction. Measurement noise is not used.
+type complementaryFilter struct {
+	alpha     float64
+	estimates map[Quantity][]float64
+	// counts holds how many measurements of each rate were averaged since the last prediction.
+	counts map[Quantity]int
+}
+
+// NewComplementaryFilter returns a complementary filter that weights predictions by alpha, which
+// is in [0, 1).
+func NewComplementaryFilter(alpha float64) Filter {
+	return &complementaryFilter{alpha: alpha, estimates: map[Quantity][]float64{}, counts: map[Quantity]int{}}
+}
+
+func (cf *complementaryFilter) Predict(dt float64) {
+	clear(cf.counts)
+	pos, ok := cf.estimates[QuantityPosition]
+	vel, velOK := cf.estimates[QuantityLinearVelocity]
+	if !ok || !velOK || dt <= 0 {
+		return
+	}
+	for i := range pos {
+		pos[i] += vel[i] * dt
+	}
+}
+
+func (cf *complementaryFilter) Update(q Quantity, z []float64, stddev float64) error {
+	if err := checkValues(q, z); err != nil {
+		return err
+	}
+	est, ok := cf.estimates[q]
+	switch {
+	case !ok:
+		cf.estimates[q] = append([]float64(nil), z...)
+		cf.counts[q] = 1
+	case q == QuantityPosition:
+		for i := range est {
+			est[i] = cf.alpha*est[i] + (1-cf.alpha)*z[i]
+		}
+	default:
+		// a running mean of the rates measured since the last prediction.
+		n := float64(cf.counts[q])
+		for i := range est {
+			est[i] = (est[i]*n + z[i]) / (n + 1)
+		}
+		cf.counts[q]++
+	}
+	return nil
+}
+
+func (cf *complementaryFilter) Estimate(q Quantity) ([]float64, bool) {
+	est, ok := cf.estimates[q]
+	return append([]float64(nil), est...), ok
+}
+
+func (cf *complementaryFilter) Uncertainty(q Quantity) ([]float64, bool) {
+	return nil, false
+}
+
+// checkValues ensures z holds as many finite values as q has.
+func checkValues(q Quantity, z []float64) error {
+	if dims, ok := quantityDims[q]; !ok || len(z) != dims {
+		return errors.Errorf("expected %d values of %s but got %d", quantityDims[q], q, len(z))
+	}
+	for _, v := range z {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return errors.Errorf("cannot fuse %s of %v", q, z)
+		}
+	}
+	return nil
+}
+
+// attitudeFilter is a complementary filter of orientation. It integrates angular velocity and
+// blends the result toward measured orientations and compass headings, weighting the integrated
+// orientation by alpha. Compass headings only correct the rotation about the up axis.
+type attitudeFilter struct {
+	alpha       float64
+	orientation *quat.Number
+	angularVel  []float64
+	// angularVelCount is how many angular velocities were averaged since the last prediction.
+	angularVelCount int
+}
+
+func newAttitudeFilter(alpha float64) *attitudeFilter {
+	return &attitudeFilter{alpha: alpha}
+}
+
+// predict rotates the orientation by the angular velocity, which is in the frame of the machine,
+// over dt seconds.
+func (af *attitudeFilter) predict(dt float64) {
+	af.angularVelCount = 0
+	if af.orientation == nil || af.angularVel == nil || dt <= 0 {
+		return
+	}
+	rotation := r3.Vector{
+		X: utils.DegToRad(af.angularVel[0]),
+		Y: utils.DegToRad(af.angularVel[1]),
+		Z: utils.DegToRad(af.angularVel[2]),
+	}.Mul(dt)
+	if rotation.Norm() == 0 {
+		return
+	}
+	q := spatialmath.Normalize(quat.Mul(*af.orientation, spatialmath.R3ToR4(rotation).Quaternion()))
+	af.orientation = &q
+}
+
+func (af *attitudeFilter) update(q Quantity, z []float64) error {
+	if err := checkValues(q, z); err != nil {
+		return err
+	}
+	switch q {
+	case QuantityAngularVelocity:
+		if af.angularVel == nil {
+			af.angularVel = make([]float64, 3)
+		}
+		n := float64(af.angularVelCount)
+		for i := range af.angularVel {
+			af.angularVel[i] = (af.angularVel[i]*n + z[i]) / (n + 1)
+		}
+		af.angularVelCount++
+	case QuantityOrientation:
+		measured := spatialmath.Normalize(quat.Number{Real: z[0], Imag: z[1], Jmag: z[2], Kmag: z[3]})
+		if af.orientation == nil {
+			af.orientation = &measured
+			return nil
+		}
+		blended := spatialmath.Interpolate(
+			spatialmath.NewPoseFromOrientation((*spatialmath.Quaternion)(af.orientation)),
+			spatialmath.NewPoseFromOrientation((*spatialmath.Quaternion)(&measured)),
+			1-af.alpha,
+		).Orientation().Quaternion()
+		af.orientation = &blended
+	case QuantityCompassHeading:
+		if af.orientation == nil {
+			initial := headingToQuat(z[0])
+			af.orientation = &initial
+			return nil
+		}
+		// rotate about the up axis of the world by part of the difference in heading.
+		diff := wrapDegrees(z[0]-quatToHeading(*af.orientation)) * (1 - af.alpha)
+		rotated := spatialmath.Normalize(quat.Mul(headingToQuat(diff), *af.orientation))
+		af.orientation = &rotated
+	default:
+		return errors.Errorf("cannot fuse %s into orientation", q)
+	}
+	return nil
+}
+
+// headingToQuat returns the rotation about the up axis by the given compass heading, which is
+// clockwise rather than counterclockwise.
+func headingToQuat(heading float64) quat.Number {
+	return (&spatialmath.EulerAngles{Yaw: -utils.DegToRad(heading)}).Quaternion()
+}
+
+// quatToHeading returns the compass heading of an orientation, in [0, 360).
+func quatToHeading(q quat.Number) float64 {
+	yaw := spatialmath.QuatToEulerAngles(q).Yaw
+	return math.Mod(math.Mod(-utils.RadToDeg(yaw), 360)+360, 360)
+}
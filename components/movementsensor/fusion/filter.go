@@ -0,0 +1,544 @@
+package fusion
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+// A Quantity is something a movement sensor measures.
+type Quantity string
+
+// The quantities the fusion model fuses, with the units and frames filters see them in.
+const (
+	// QuantityPosition is east, north, and up in meters from the first position fused.
+	QuantityPosition = Quantity("position")
+	// QuantityLinearVelocity is in meters per second.
+	QuantityLinearVelocity = Quantity("linear_velocity")
+	// QuantityLinearAcceleration is in meters per second per second.
+	QuantityLinearAcceleration = Quantity("linear_acceleration")
+	// QuantityAngularVelocity is in degrees per second.
+	QuantityAngularVelocity = Quantity("angular_velocity")
+	// QuantityOrientation is a quaternion, as real, i, j, and k parts.
+	QuantityOrientation = Quantity("orientation")
+	// QuantityCompassHeading is in degrees clockwise from north.
+	QuantityCompassHeading = Quantity("compass_heading")
+)
+
+// quantityDims is how many values each quantity has.
+var quantityDims = map[Quantity]int{
+	QuantityPosition:           3,
+	QuantityLinearVelocity:     3,
+	QuantityLinearAcceleration: 3,
+	QuantityAngularVelocity:    3,
+	QuantityOrientation:        4,
+	QuantityCompassHeading:     1,
+}
+
+// A Filter estimates the translational state of a machine from noisy measurements.
+type Filter interface {
+	// Predict advances the estimate by dt seconds.
+	Predict(dt float64)
+	// Update corrects the estimate with a measurement z of q whose noise has the given standard
+	// deviation.
+	Update(q Quantity, z []float64, stddev float64) error
+	// Estimate returns the estimated value of q, or false if there is none.
+	Estimate(q Quantity) ([]float64, bool)
+	// Uncertainty returns the standard deviation of each value of the estimate of q, or false if the
+	// filter does not track it.
+	Uncertainty(q Quantity) ([]float64, bool)
+}
+
+// A StateModel describes how the state estimated by a Kalman filter evolves and how it is measured.
+// Models may be nonlinear, since the filters linearize them numerically or through sigma points.
+type StateModel interface {
+	// Size returns the number of values in the state.
+	Size() int
+	// Predict returns the state dt seconds after x.
+	Predict(x []float64, dt float64) []float64
+	// ProcessNoise returns the covariance of the noise the state accumulates over dt seconds.
+	ProcessNoise(dt float64) *mat.SymDense
+	// Observe returns what a measurement of q reads in state x, or false if q is not observed.
+	Observe(q Quantity, x []float64) ([]float64, bool)
+}
+
+// A ModelConstructor builds a StateModel from the model_params of a config.
+type ModelConstructor func(params map[string]float64) (StateModel, error)
+
+var (
+	modelsMu sync.Mutex
+	models   = map[string]ModelConstructor{
+		"constant_velocity":     newConstantVelocityModel,
+		"constant_acceleration": newConstantAccelerationModel,
+	}
+)
+
+// DefaultModel is the model Kalman filters use when none is configured.
+const DefaultModel = "constant_velocity"
+
+// RegisterModel makes a StateModel available to Kalman filters under the given name, so that modules
+// can fuse sensors with their own models.
+func RegisterModel(name string, constructor ModelConstructor) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	if _, ok := models[name]; ok {
+		panic(fmt.Errorf("fusion model %q already registered", name))
+	}
+	models[name] = constructor
+}
+
+// NewModel returns the registered StateModel with the given name.
+func NewModel(name string, params map[string]float64) (StateModel, error) {
+	modelsMu.Lock()
+	constructor, ok := models[name]
+	modelsMu.Unlock()
+	if !ok {
+		return nil, errors.Errorf("unknown fusion model %q, expected one of %v", name, registeredModels())
+	}
+	return constructor(params)
+}
+
+func registeredModels() []string {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// kinematicModel is a model of position and its derivatives along three axes, whose highest
+// derivative changes by white noise. Its state holds all positions, then all velocities, and so on.
+type kinematicModel struct {
+	// order is 2 for constant velocity and 3 for constant acceleration.
+	order int
+	// noise is the spectral density of the white noise.
+	noise float64
+}
+
+func processNoiseParam(params map[string]float64) (float64, error) {
+	noise, ok := params["process_noise"]
+	if !ok {
+		return 1, nil
+	}
+	if noise <= 0 {
+		return 0, errors.New("process_noise must be positive")
+	}
+	return noise, nil
+}
+
+func newConstantVelocityModel(params map[string]float64) (StateModel, error) {
+	noise, err := processNoiseParam(params)
+	if err != nil {
+		return nil, err
+	}
+	return &kinematicModel{order: 2, noise: noise}, nil
+}
+
+func newConstantAccelerationModel(params map[string]float64) (StateModel, error) {
+	noise, err := processNoiseParam(params)
+	if err != nil {
+		return nil, err
+	}
+	return &kinematicModel{order: 3, noise: noise}, nil
+}
+
+func (m *kinematicModel) Size() int {
+	return 3 * m.order
+}
+
+func (m *kinematicModel) Predict(x []float64, dt float64) []float64 {
+	next := append([]float64(nil), x...)
+	for axis := 0; axis < 3; axis++ {
+		next[axis] += x[3+axis] * dt
+		if m.order == 3 {
+			next[axis] += x[6+axis] * dt * dt / 2
+			next[3+axis] += x[6+axis] * dt
+		}
+	}
+	return next
+}
+
+func (m *kinematicModel) ProcessNoise(dt float64) *mat.SymDense {
+	// the covariance of each derivative integrated from white noise in the highest one.
+	var perAxis [][]float64
+	if m.order == 2 {
+		perAxis = [][]float64{
+			{math.Pow(dt, 3) / 3, dt * dt / 2},
+			{dt * dt / 2, dt},
+		}
+	} else {
+		perAxis = [][]float64{
+			{math.Pow(dt, 5) / 20, math.Pow(dt, 4) / 8, math.Pow(dt, 3) / 6},
+			{math.Pow(dt, 4) / 8, math.Pow(dt, 3) / 3, dt * dt / 2},
+			{math.Pow(dt, 3) / 6, dt * dt / 2, dt},
+		}
+	}
+	q := mat.NewSymDense(m.Size(), nil)
+	for axis := 0; axis < 3; axis++ {
+		for i := 0; i < m.order; i++ {
+			for j := i; j < m.order; j++ {
+				q.SetSym(3*i+axis, 3*j+axis, m.noise*perAxis[i][j])
+			}
+		}
+	}
+	return q
+}
+
+func (m *kinematicModel) Observe(q Quantity, x []float64) ([]float64, bool) {
+	switch {
+	case q == QuantityPosition:
+		return x[0:3], true
+	case q == QuantityLinearVelocity:
+		return x[3:6], true
+	case q == QuantityLinearAcceleration && m.order == 3:
+		return x[6:9], true
+	default:
+		return nil, false
+	}
+}
+
+// initialVariance is the variance of each value of the state before anything is measured, which is
+// large so that the first measurements are trusted.
+const initialVariance = 1e4
+
+// kalmanFilter holds what the extended and unscented Kalman filters share.
+type kalmanFilter struct {
+	model StateModel
+	x     []float64
+	p     *mat.SymDense
+	// measured is whether anything was measured, before which nothing is estimated.
+	measured bool
+}
+
+func newKalmanFilter(model StateModel) kalmanFilter {
+	n := model.Size()
+	p := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		p.SetSym(i, i, initialVariance)
+	}
+	return kalmanFilter{model: model, x: make([]float64, n), p: p}
+}
+
+func (kf *kalmanFilter) Estimate(q Quantity) ([]float64, bool) {
+	if !kf.measured {
+		return nil, false
+	}
+	z, ok := kf.model.Observe(q, kf.x)
+	return append([]float64(nil), z...), ok
+}
+
+func (kf *kalmanFilter) Uncertainty(q Quantity) ([]float64, bool) {
+	if !kf.measured {
+		return nil, false
+	}
+	h, ok := jacobian(func(x []float64) []float64 {
+		z, _ := kf.model.Observe(q, x)
+		return z
+	}, kf.x)
+	if !ok {
+		return nil, false
+	}
+	var cov mat.Dense
+	cov.Product(h, kf.p, h.T())
+	rows, _ := cov.Dims()
+	stddevs := make([]float64, rows)
+	for i := range stddevs {
+		stddevs[i] = math.Sqrt(math.Max(cov.At(i, i), 0))
+	}
+	return stddevs, true
+}
+
+// checkMeasurement ensures z can be fused as a measurement of q, returning its noise covariance.
+func (kf *kalmanFilter) checkMeasurement(q Quantity, z []float64, stddev float64) (*mat.SymDense, error) {
+	expected, ok := kf.model.Observe(q, kf.x)
+	if !ok {
+		return nil, errors.Errorf("the fusion model does not observe %s", q)
+	}
+	if len(z) != len(expected) {
+		return nil, errors.Errorf("expected %d values of %s but got %d", len(expected), q, len(z))
+	}
+	for _, v := range z {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, errors.Errorf("cannot fuse %s of %v", q, z)
+		}
+	}
+	if stddev <= 0 {
+		return nil, errors.New("measurement noise must be positive")
+	}
+	r := mat.NewSymDense(len(z), nil)
+	for i := range z {
+		r.SetSym(i, i, stddev*stddev)
+	}
+	return r, nil
+}
+
+// correct applies the gain k and innovation y to the state and sets its covariance to p.
+func (kf *kalmanFilter) correct(k *mat.Dense, y []float64, p mat.Matrix) {
+	var dx mat.VecDense
+	dx.MulVec(k, mat.NewVecDense(len(y), y))
+	for i := range kf.x {
+		kf.x[i] += dx.AtVec(i)
+	}
+	kf.p = symmetrize(p)
+}
+
+// EKF is an extended Kalman filter, which linearizes its model around the current estimate.
+type EKF struct {
+	kalmanFilter
+}
+
+// NewEKF returns an extended Kalman filter of the given model.
+func NewEKF(model StateModel) *EKF {
+	return &EKF{kalmanFilter: newKalmanFilter(model)}
+}
+
+// Predict advances the estimate by dt seconds.
+func (ekf *EKF) Predict(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	f, ok := jacobian(func(x []float64) []float64 { return ekf.model.Predict(x, dt) }, ekf.x)
+	if !ok {
+		return
+	}
+	ekf.x = ekf.model.Predict(ekf.x, dt)
+	var p mat.Dense
+	p.Product(f, ekf.p, f.T())
+	p.Add(&p, ekf.model.ProcessNoise(dt))
+	ekf.p = symmetrize(&p)
+}
+
+// Update corrects the estimate with a measurement z of q.
+func (ekf *EKF) Update(q Quantity, z []float64, stddev float64) error {
+	r, err := ekf.checkMeasurement(q, z, stddev)
+	if err != nil {
+		return err
+	}
+	observe := func(x []float64) []float64 {
+		obs, _ := ekf.model.Observe(q, x)
+		return obs
+	}
+	h, ok := jacobian(observe, ekf.x)
+	if !ok {
+		return errors.Errorf("cannot linearize the fusion model for %s", q)
+	}
+	y := residual(q, z, observe(ekf.x))
+
+	var s mat.Dense
+	s.Product(h, ekf.p, h.T())
+	s.Add(&s, r)
+	var sInv mat.Dense
+	if err := sInv.Inverse(&s); err != nil {
+		return errors.Wrapf(err, "cannot fuse %s", q)
+	}
+	var k mat.Dense
+	k.Product(ekf.p, h.T(), &sInv)
+
+	// the Joseph form keeps the covariance positive definite.
+	n := ekf.model.Size()
+	var ikh mat.Dense
+	ikh.Mul(&k, h)
+	ikh.Sub(eye(n), &ikh)
+	var p, krk mat.Dense
+	p.Product(&ikh, ekf.p, ikh.T())
+	krk.Product(&k, r, k.T())
+	p.Add(&p, &krk)
+	ekf.correct(&k, y, &p)
+	ekf.measured = true
+	return nil
+}
+
+// UKF is an unscented Kalman filter, which propagates sigma points through its model rather than
+// linearizing it, so it handles strongly nonlinear models better than an EKF.
+type UKF struct {
+	kalmanFilter
+	weightsMean []float64
+	weightsCov  []float64
+	lambda      float64
+}
+
+// NewUKF returns an unscented Kalman filter of the given model.
+func NewUKF(model StateModel) *UKF {
+	const alpha, beta, kappa = 1, 2, 0
+	n := float64(model.Size())
+	lambda := alpha*alpha*(n+kappa) - n
+	count := 2*model.Size() + 1
+	ukf := &UKF{
+		kalmanFilter: newKalmanFilter(model),
+		weightsMean:  make([]float64, count),
+		weightsCov:   make([]float64, count),
+		lambda:       lambda,
+	}
+	for i := range ukf.weightsMean {
+		ukf.weightsMean[i] = 1 / (2 * (n + lambda))
+		ukf.weightsCov[i] = ukf.weightsMean[i]
+	}
+	ukf.weightsMean[0] = lambda / (n + lambda)
+	ukf.weightsCov[0] = ukf.weightsMean[0] + (1 - alpha*alpha + beta)
+	return ukf
+}
+
+// sigmaPoints returns the sigma points of the current estimate.
+func (ukf *UKF) sigmaPoints() ([][]float64, bool) {
+	n := ukf.model.Size()
+	scaled := mat.NewSymDense(n, nil)
+	scaled.ScaleSym(float64(n)+ukf.lambda, ukf.p)
+	var chol mat.Cholesky
+	if !chol.Factorize(scaled) {
+		return nil, false
+	}
+	var l mat.TriDense
+	chol.LTo(&l)
+	points := make([][]float64, 2*n+1)
+	points[0] = append([]float64(nil), ukf.x...)
+	for i := 0; i < n; i++ {
+		plus := append([]float64(nil), ukf.x...)
+		minus := append([]float64(nil), ukf.x...)
+		for j := 0; j < n; j++ {
+			plus[j] += l.At(j, i)
+			minus[j] -= l.At(j, i)
+		}
+		points[1+i] = plus
+		points[1+n+i] = minus
+	}
+	return points, true
+}
+
+// Predict advances the estimate by dt seconds.
+func (ukf *UKF) Predict(dt float64) {
+	if dt <= 0 {
+		return
+	}
+	points, ok := ukf.sigmaPoints()
+	if !ok {
+		return
+	}
+	for i, point := range points {
+		points[i] = ukf.model.Predict(point, dt)
+	}
+	x := ukf.mean(points)
+	p := ukf.covariance(points, x, points, x)
+	p.Add(p, ukf.model.ProcessNoise(dt))
+	ukf.x = x
+	ukf.p = symmetrize(p)
+}
+
+// Update corrects the estimate with a measurement z of q.
+func (ukf *UKF) Update(q Quantity, z []float64, stddev float64) error {
+	r, err := ukf.checkMeasurement(q, z, stddev)
+	if err != nil {
+		return err
+	}
+	points, ok := ukf.sigmaPoints()
+	if !ok {
+		return errors.Errorf("cannot fuse %s since the covariance is not positive definite", q)
+	}
+	observed := make([][]float64, len(points))
+	for i, point := range points {
+		observed[i], _ = ukf.model.Observe(q, point)
+	}
+	zMean := ukf.mean(observed)
+	s := ukf.covariance(observed, zMean, observed, zMean)
+	s.Add(s, r)
+	pxz := ukf.covariance(points, ukf.x, observed, zMean)
+
+	var sInv mat.Dense
+	if err := sInv.Inverse(s); err != nil {
+		return errors.Wrapf(err, "cannot fuse %s", q)
+	}
+	var k mat.Dense
+	k.Mul(pxz, &sInv)
+	var ksk, p mat.Dense
+	ksk.Product(&k, s, k.T())
+	p.Sub(ukf.p, &ksk)
+	ukf.correct(&k, residual(q, z, zMean), &p)
+	ukf.measured = true
+	return nil
+}
+
+func (ukf *UKF) mean(points [][]float64) []float64 {
+	mean := make([]float64, len(points[0]))
+	for i, point := range points {
+		for j, v := range point {
+			mean[j] += ukf.weightsMean[i] * v
+		}
+	}
+	return mean
+}
+
+func (ukf *UKF) covariance(a [][]float64, aMean []float64, b [][]float64, bMean []float64) *mat.Dense {
+	cov := mat.NewDense(len(aMean), len(bMean), nil)
+	for i := range a {
+		for j := range aMean {
+			for k := range bMean {
+				cov.Set(j, k, cov.At(j, k)+ukf.weightsCov[i]*(a[i][j]-aMean[j])*(b[i][k]-bMean[k]))
+			}
+		}
+	}
+	return cov
+}
+
+// jacobian numerically differentiates f at x.
+func jacobian(f func([]float64) []float64, x []float64) (*mat.Dense, bool) {
+	const eps = 1e-6
+	// models may return slices of the state they are given, so results are copied.
+	y := append([]float64(nil), f(x)...)
+	if len(y) == 0 {
+		return nil, false
+	}
+	jac := mat.NewDense(len(y), len(x), nil)
+	shifted := append([]float64(nil), x...)
+	for j := range x {
+		step := eps * math.Max(1, math.Abs(x[j]))
+		shifted[j] = x[j] + step
+		yShifted := append([]float64(nil), f(shifted)...)
+		shifted[j] = x[j]
+		for i := range y {
+			jac.Set(i, j, (yShifted[i]-y[i])/step)
+		}
+	}
+	return jac, true
+}
+
+// residual returns z minus expected, wrapping compass headings so the shortest way around is taken.
+func residual(q Quantity, z, expected []float64) []float64 {
+	y := make([]float64, len(z))
+	for i := range z {
+		y[i] = z[i] - expected[i]
+		if q == QuantityCompassHeading {
+			y[i] = wrapDegrees(y[i])
+		}
+	}
+	return y
+}
+
+// wrapDegrees returns the angle equal to deg in [-180, 180).
+func wrapDegrees(deg float64) float64 {
+	return math.Mod(math.Mod(deg+180, 360)+360, 360) - 180
+}
+
+func symmetrize(m mat.Matrix) *mat.SymDense {
+	n, _ := m.Dims()
+	sym := mat.NewSymDense(n, nil)
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			sym.SetSym(i, j, (m.At(i, j)+m.At(j, i))/2)
+		}
+	}
+	return sym
+}
+
+func eye(n int) *mat.Dense {
+	id := mat.NewDense(n, n, nil)
+	for i := 0; i < n; i++ {
+		id.Set(i, i, 1)
+	}
+	return id
+}
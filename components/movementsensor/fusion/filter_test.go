@@ -0,0 +1,173 @@
+package fusion
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"go.viam.com/test"
+	"gonum.org/v1/gonum/mat"
+)
+
+// trackConstantVelocity fuses noisy positions of something moving at a constant velocity into
+// filter and returns its estimated position and velocity after 10 seconds.
+func trackConstantVelocity(t *testing.T, filter Filter) ([]float64, []float64) {
+	t.Helper()
+	//nolint:gosec
+	rng := rand.New(rand.NewSource(1))
+	velocity := []float64{1, -2, 0.5}
+	const dt = 0.1
+	for i := 1; i <= 100; i++ {
+		filter.Predict(dt)
+		tm := float64(i) * dt
+		z := make([]float64, 3)
+		for axis := range z {
+			z[axis] = velocity[axis]*tm + rng.NormFloat64()*0.5
+		}
+		test.That(t, filter.Update(QuantityPosition, z, 0.5), test.ShouldBeNil)
+	}
+	pos, ok := filter.Estimate(QuantityPosition)
+	test.That(t, ok, test.ShouldBeTrue)
+	vel, ok := filter.Estimate(QuantityLinearVelocity)
+	test.That(t, ok, test.ShouldBeTrue)
+	return pos, vel
+}
+
+func TestKalmanFilters(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		newFilter func(StateModel) Filter
+	}{
+		{"ekf", func(m StateModel) Filter { return NewEKF(m) }},
+		{"ukf", func(m StateModel) Filter { return NewUKF(m) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			model, err := NewModel(DefaultModel, map[string]float64{"process_noise": 0.01})
+			test.That(t, err, test.ShouldBeNil)
+			filter := tc.newFilter(model)
+			_, ok := filter.Estimate(QuantityLinearVelocity)
+			test.That(t, ok, test.ShouldBeFalse)
+
+			pos, vel := trackConstantVelocity(t, filter)
+			test.That(t, pos[0], test.ShouldAlmostEqual, 10, 0.5)
+			test.That(t, pos[1], test.ShouldAlmostEqual, -20, 0.5)
+			test.That(t, pos[2], test.ShouldAlmostEqual, 5, 0.5)
+			test.That(t, vel[0], test.ShouldAlmostEqual, 1, 0.2)
+			test.That(t, vel[1], test.ShouldAlmostEqual, -2, 0.2)
+			test.That(t, vel[2], test.ShouldAlmostEqual, 0.5, 0.2)
+
+			stddevs, ok := filter.Uncertainty(QuantityPosition)
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, stddevs, test.ShouldHaveLength, 3)
+			test.That(t, stddevs[0], test.ShouldBeLessThan, 0.5)
+
+			// a constant velocity model does not observe acceleration.
+			test.That(t, filter.Update(QuantityLinearAcceleration, []float64{0, 0, 0}, 1), test.ShouldNotBeNil)
+			test.That(t, filter.Update(QuantityPosition, []float64{0, 0}, 1), test.ShouldNotBeNil)
+			test.That(t, filter.Update(QuantityPosition, []float64{math.NaN(), 0, 0}, 1), test.ShouldNotBeNil)
+
+			model, err = NewModel("constant_acceleration", nil)
+			test.That(t, err, test.ShouldBeNil)
+			filter = tc.newFilter(model)
+			for i := 0; i < 20; i++ {
+				filter.Predict(0.1)
+				test.That(t, filter.Update(QuantityLinearAcceleration, []float64{1, 0, 0}, 0.1), test.ShouldBeNil)
+			}
+			acc, ok := filter.Estimate(QuantityLinearAcceleration)
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, acc[0], test.ShouldAlmostEqual, 1, 0.05)
+		})
+	}
+}
+
+func TestComplementaryFilter(t *testing.T) {
+	filter := NewComplementaryFilter(0.9)
+	_, ok := filter.Uncertainty(QuantityPosition)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	test.That(t, filter.Update(QuantityPosition, []float64{0, 0, 0}, 1), test.ShouldBeNil)
+	test.That(t, filter.Update(QuantityLinearVelocity, []float64{1, 0, 0}, 1), test.ShouldBeNil)
+	test.That(t, filter.Update(QuantityLinearVelocity, []float64{3, 0, 0}, 1), test.ShouldBeNil)
+	vel, ok := filter.Estimate(QuantityLinearVelocity)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, vel, test.ShouldResemble, []float64{2, 0, 0})
+
+	// position is dead reckoned, then pulled toward what is measured.
+	filter.Predict(1)
+	pos, _ := filter.Estimate(QuantityPosition)
+	test.That(t, pos, test.ShouldResemble, []float64{2, 0, 0})
+	test.That(t, filter.Update(QuantityPosition, []float64{0, 10, 0}, 1), test.ShouldBeNil)
+	pos, _ = filter.Estimate(QuantityPosition)
+	test.That(t, pos[0], test.ShouldAlmostEqual, 1.8)
+	test.That(t, pos[1], test.ShouldAlmostEqual, 1)
+
+	// velocities measured after a prediction replace those before it.
+	test.That(t, filter.Update(QuantityLinearVelocity, []float64{0, 1, 0}, 1), test.ShouldBeNil)
+	vel, _ = filter.Estimate(QuantityLinearVelocity)
+	test.That(t, vel, test.ShouldResemble, []float64{0, 1, 0})
+	test.That(t, filter.Update(QuantityLinearVelocity, []float64{0, 1}, 1), test.ShouldNotBeNil)
+}
+
+func TestAttitudeFilter(t *testing.T) {
+	for _, heading := range []float64{0, 45, 180, 300} {
+		test.That(t, quatToHeading(headingToQuat(heading)), test.ShouldAlmostEqual, heading)
+	}
+	test.That(t, wrapDegrees(350), test.ShouldAlmostEqual, -10)
+	test.That(t, wrapDegrees(-190), test.ShouldAlmostEqual, 170)
+
+	af := newAttitudeFilter(0.5)
+	test.That(t, af.update(QuantityCompassHeading, []float64{350}), test.ShouldBeNil)
+	test.That(t, quatToHeading(*af.orientation), test.ShouldAlmostEqual, 350)
+	// half of the way from 350 to 10 is across north.
+	test.That(t, af.update(QuantityCompassHeading, []float64{10}), test.ShouldBeNil)
+	test.That(t, quatToHeading(*af.orientation), test.ShouldAlmostEqual, 0, 1e-6)
+
+	// turning counterclockwise about up at 90 degrees per second for a second.
+	test.That(t, af.update(QuantityAngularVelocity, []float64{0, 0, 90}), test.ShouldBeNil)
+	for i := 0; i < 10; i++ {
+		af.predict(0.1)
+		test.That(t, af.update(QuantityAngularVelocity, []float64{0, 0, 90}), test.ShouldBeNil)
+	}
+	test.That(t, quatToHeading(*af.orientation), test.ShouldAlmostEqual, 270, 1e-6)
+
+	measured := headingToQuat(240)
+	test.That(t, af.update(QuantityOrientation, []float64{measured.Real, measured.Imag, measured.Jmag, measured.Kmag}),
+		test.ShouldBeNil)
+	test.That(t, quatToHeading(*af.orientation), test.ShouldAlmostEqual, 255, 1e-6)
+	test.That(t, af.update(QuantityPosition, []float64{0, 0, 0}), test.ShouldNotBeNil)
+}
+
+type stillModel struct{}
+
+func (stillModel) Size() int { return 3 }
+
+func (stillModel) Predict(x []float64, dt float64) []float64 { return x }
+
+func (stillModel) ProcessNoise(dt float64) *mat.SymDense {
+	return mat.NewSymDense(3, []float64{1e-6, 0, 0, 0, 1e-6, 0, 0, 0, 1e-6})
+}
+
+func (stillModel) Observe(q Quantity, x []float64) ([]float64, bool) {
+	return x, q == QuantityPosition
+}
+
+func TestRegisterModel(t *testing.T) {
+	_, err := NewModel("still", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	RegisterModel("still", func(params map[string]float64) (StateModel, error) {
+		return stillModel{}, nil
+	})
+	test.That(t, func() { RegisterModel("still", nil) }, test.ShouldPanic)
+	model, err := NewModel("still", nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	filter := NewUKF(model)
+	test.That(t, filter.Update(QuantityPosition, []float64{1, 2, 3}, 0.1), test.ShouldBeNil)
+	pos, ok := filter.Estimate(QuantityPosition)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, pos[2], test.ShouldAlmostEqual, 3, 1e-3)
+	test.That(t, filter.Update(QuantityLinearVelocity, []float64{1, 2, 3}, 0.1), test.ShouldNotBeNil)
+
+	_, err = NewModel(DefaultModel, map[string]float64{"process_noise": -1})
+	test.That(t, err, test.ShouldNotBeNil)
+}
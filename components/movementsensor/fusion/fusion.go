@@ -0,0 +1,692 @@
+// Package fusion implements a movement sensor that fuses the measurements of other movement
+// sensors and sensors into a single estimate.
+//
+// Position, linear velocity, and linear acceleration are fused by a configurable filter: a
+// complementary filter, or an extended or unscented Kalman filter of a registered StateModel, which
+// modules can add to with RegisterModel. Orientation, compass heading, and angular velocity are
+// always fused by a complementary filter that integrates angular velocity and corrects it with
+// measured orientations and compass headings.
+package fusion
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// Model is the name of the fusion model of a movementsensor component.
+var Model = resource.DefaultModelFamily.WithModel("fusion")
+
+// The filters that position, linear velocity, and linear acceleration can be fused with.
+const (
+	FilterComplementary = "complementary"
+	FilterEKF           = "ekf"
+	FilterUKF           = "ukf"
+)
+
+const (
+	defaultUpdateRateHz = 20
+	// earthRadiusM is the mean radius of the earth, used to convert positions to and from meters.
+	earthRadiusM = 6371000
+)
+
+// defaultNoise is the standard deviation Kalman filters assume measurements of each quantity have
+// unless one is configured.
+var defaultNoise = map[Quantity]float64{
+	QuantityPosition:           1,
+	QuantityLinearVelocity:     0.1,
+	QuantityLinearAcceleration: 0.5,
+}
+
+// translational holds the quantities fused by the configured filter rather than the attitude filter.
+var translational = []Quantity{QuantityPosition, QuantityLinearVelocity, QuantityLinearAcceleration}
+
+// Config is the config for a fusion MovementSensor.
+type Config struct {
+	Inputs []InputConfig `json:"inputs"`
+	// Filter fuses position, linear velocity, and linear acceleration, and is one of
+	// "complementary", the default, "ekf", or "ukf".
+	Filter string `json:"filter,omitempty"`
+	// Model is the StateModel of Kalman filters, which defaults to "constant_velocity".
+	Model       string             `json:"model,omitempty"`
+	ModelParams map[string]float64 `json:"model_params,omitempty"`
+	// Alpha is how much complementary filters trust what they predict over what is measured.
+	Alpha        float64 `json:"alpha,omitempty"`
+	UpdateRateHz float64 `json:"update_rate_hz,omitempty"`
+}
+
+// InputConfig describes a movement sensor or sensor whose measurements are fused.
+type InputConfig struct {
+	Name string `json:"name"`
+	// Quantities lists what is fused from a movement sensor, which defaults to everything its
+	// properties say it supports.
+	Quantities []Quantity `json:"quantities,omitempty"`
+	// Readings makes the input a sensor whose readings hold the quantities fused from it, by the
+	// keys of their values: latitude, longitude, and optionally altitude in meters for position; x,
+	// y, and z for velocities and accelerations; roll, pitch, and yaw in degrees for orientation;
+	// and a single key for compass heading.
+	Readings map[Quantity][]string `json:"readings,omitempty"`
+	// Noise is the standard deviation of the measurements of each quantity, which weights them
+	// against those of other inputs in Kalman filters.
+	Noise map[Quantity]float64 `json:"noise,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, []string, error) {
+	if len(cfg.Inputs) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "inputs")
+	}
+	var deps []string
+	for i, input := range cfg.Inputs {
+		inputPath := fmt.Sprintf("%s.inputs.%d", path, i)
+		if input.Name == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(inputPath, "name")
+		}
+		if err := input.validate(inputPath); err != nil {
+			return nil, nil, err
+		}
+		deps = append(deps, input.Name)
+	}
+	switch cfg.Filter {
+	case "", FilterComplementary:
+	case FilterEKF, FilterUKF:
+		if _, err := NewModel(cfg.modelName(), cfg.ModelParams); err != nil {
+			return nil, nil, resource.NewConfigValidationError(path, err)
+		}
+	default:
+		return nil, nil, resource.NewConfigValidationError(path, errors.Errorf(
+			"unknown filter %q, expected one of %v", cfg.Filter, []string{FilterComplementary, FilterEKF, FilterUKF}))
+	}
+	if cfg.Alpha < 0 || cfg.Alpha >= 1 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("alpha must be in [0, 1)"))
+	}
+	if cfg.UpdateRateHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("update_rate_hz cannot be negative"))
+	}
+	return deps, nil, nil
+}
+
+func (input *InputConfig) validate(path string) error {
+	for _, q := range input.Quantities {
+		if _, ok := quantityDims[q]; !ok {
+			return resource.NewConfigValidationError(path, errors.Errorf("unknown quantity %q", q))
+		}
+	}
+	if len(input.Readings) != 0 && len(input.Quantities) != 0 {
+		return resource.NewConfigValidationError(path, errors.New("only one of quantities and readings can be set"))
+	}
+	for q, keys := range input.Readings {
+		var want []int
+		switch q {
+		case QuantityPosition:
+			want = []int{2, 3}
+		case QuantityLinearVelocity, QuantityLinearAcceleration, QuantityAngularVelocity, QuantityOrientation:
+			want = []int{3}
+		case QuantityCompassHeading:
+			want = []int{1}
+		default:
+			return resource.NewConfigValidationError(path, errors.Errorf("unknown quantity %q", q))
+		}
+		if !slices.Contains(want, len(keys)) {
+			return resource.NewConfigValidationError(path, errors.Errorf("expected %v readings keys for %s but got %d", want, q, len(keys)))
+		}
+	}
+	for q, noise := range input.Noise {
+		if _, ok := quantityDims[q]; !ok {
+			return resource.NewConfigValidationError(path, errors.Errorf("unknown quantity %q", q))
+		}
+		if noise <= 0 {
+			return resource.NewConfigValidationError(path, errors.Errorf("noise of %s must be positive", q))
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) modelName() string {
+	if cfg.Model == "" {
+		return DefaultModel
+	}
+	return cfg.Model
+}
+
+// input is a resolved InputConfig.
+type input struct {
+	name       string
+	ms         movementsensor.MovementSensor
+	sensor     sensor.Sensor
+	readings   map[Quantity][]string
+	quantities []Quantity
+	noise      map[Quantity]float64
+}
+
+type fused struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	inputs     []input
+	newFilter  func() Filter
+	alpha      float64
+	updateRate float64
+	supported  map[Quantity]bool
+
+	mu       sync.Mutex
+	filter   Filter
+	attitude *attitudeFilter
+	// origin is the first position fused, from which fused positions are measured.
+	origin    *geo.Point
+	originAlt float64
+	lastStep  time.Time
+	lastErrs  map[string]error
+
+	workers *goutils.StoppableWorkers
+	logger  logging.Logger
+}
+
+func init() {
+	resource.RegisterComponent(
+		movementsensor.API,
+		Model,
+		resource.Registration[movementsensor.MovementSensor, *Config]{Constructor: newFused})
+}
+
+func newFused(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	f := &fused{
+		Named:      conf.ResourceName().AsNamed(),
+		alpha:      newConf.Alpha,
+		updateRate: newConf.UpdateRateHz,
+		supported:  map[Quantity]bool{},
+		lastErrs:   map[string]error{},
+		logger:     logger,
+	}
+	if f.alpha == 0 {
+		f.alpha = DefaultAlpha
+	}
+	if f.updateRate == 0 {
+		f.updateRate = defaultUpdateRateHz
+	}
+
+	var model StateModel
+	switch newConf.Filter {
+	case FilterEKF, FilterUKF:
+		if model, err = NewModel(newConf.modelName(), newConf.ModelParams); err != nil {
+			return nil, err
+		}
+		if newConf.Filter == FilterEKF {
+			f.newFilter = func() Filter { return NewEKF(model) }
+		} else {
+			f.newFilter = func() Filter { return NewUKF(model) }
+		}
+	default:
+		f.newFilter = func() Filter { return NewComplementaryFilter(f.alpha) }
+	}
+
+	for _, inputConf := range newConf.Inputs {
+		in, err := resolveInput(ctx, deps, inputConf)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range in.quantities {
+			if model != nil && slices.Contains(translational, q) {
+				if _, ok := model.Observe(q, make([]float64, model.Size())); !ok {
+					return nil, errors.Errorf("the %s fusion model cannot fuse %s from %s", newConf.modelName(), q, in.name)
+				}
+			}
+			f.supported[q] = true
+		}
+		f.inputs = append(f.inputs, in)
+	}
+	// Kalman filters estimate everything their model observes from whatever is measured, such as
+	// velocity from positions alone.
+	if model != nil && slices.ContainsFunc(translational, func(q Quantity) bool { return f.supported[q] }) {
+		for _, q := range translational {
+			if _, ok := model.Observe(q, make([]float64, model.Size())); ok {
+				f.supported[q] = true
+			}
+		}
+	}
+	f.reset()
+
+	f.workers = goutils.NewBackgroundStoppableWorkers(f.run)
+	return f, nil
+}
+
+// resolveInput finds the dependency of an input and what is fused from it.
+func resolveInput(ctx context.Context, deps resource.Dependencies, conf InputConfig) (input, error) {
+	in := input{name: conf.Name, readings: conf.Readings, noise: map[Quantity]float64{}}
+	for q, noise := range defaultNoise {
+		in.noise[q] = noise
+	}
+	for q, noise := range conf.Noise {
+		in.noise[q] = noise
+	}
+
+	if len(conf.Readings) != 0 {
+		s, err := sensor.FromProvider(deps, conf.Name)
+		if err != nil {
+			return input{}, err
+		}
+		in.sensor = s
+		for q := range conf.Readings {
+			in.quantities = append(in.quantities, q)
+		}
+		slices.Sort(in.quantities)
+		return in, nil
+	}
+
+	ms, err := movementsensor.FromProvider(deps, conf.Name)
+	if err != nil {
+		return input{}, err
+	}
+	in.ms = ms
+	if len(conf.Quantities) != 0 {
+		in.quantities = conf.Quantities
+		return in, nil
+	}
+	props, err := ms.Properties(ctx, nil)
+	if err != nil {
+		return input{}, errors.Wrapf(err, "cannot get the properties of %s", conf.Name)
+	}
+	for q, supported := range map[Quantity]bool{
+		QuantityPosition:           props.PositionSupported,
+		QuantityLinearVelocity:     props.LinearVelocitySupported,
+		QuantityLinearAcceleration: props.LinearAccelerationSupported,
+		QuantityAngularVelocity:    props.AngularVelocitySupported,
+		QuantityOrientation:        props.OrientationSupported,
+		QuantityCompassHeading:     props.CompassHeadingSupported,
+	} {
+		if supported {
+			in.quantities = append(in.quantities, q)
+		}
+	}
+	if len(in.quantities) == 0 {
+		return input{}, errors.Errorf("movement sensor %s supports nothing to fuse", conf.Name)
+	}
+	slices.Sort(in.quantities)
+	return in, nil
+}
+
+// reset starts fusing over, from a new origin.
+func (f *fused) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.filter = f.newFilter()
+	f.attitude = newAttitudeFilter(f.alpha)
+	f.origin = nil
+	f.lastStep = time.Time{}
+}
+
+func (f *fused) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / f.updateRate))
+	defer ticker.Stop()
+	for {
+		f.step(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// step predicts the state up to now, then fuses the latest measurements of every input.
+func (f *fused) step(ctx context.Context) {
+	f.mu.Lock()
+	now := time.Now()
+	if !f.lastStep.IsZero() {
+		dt := now.Sub(f.lastStep).Seconds()
+		f.filter.Predict(dt)
+		f.attitude.predict(dt)
+	}
+	f.lastStep = now
+	f.mu.Unlock()
+
+	for _, in := range f.inputs {
+		measurements, err := in.measure(ctx)
+		f.mu.Lock()
+		for _, m := range measurements {
+			if updateErr := f.updateLocked(in, m.quantity, m.values); updateErr != nil {
+				err = errors.Wrapf(updateErr, "cannot fuse %s", m.quantity)
+			}
+		}
+		if err != nil && ctx.Err() == nil {
+			if f.lastErrs[in.name] == nil {
+				f.logger.CDebugw(ctx, "error fusing input", "input", in.name, "error", err)
+			}
+			f.lastErrs[in.name] = err
+		} else {
+			delete(f.lastErrs, in.name)
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *fused) updateLocked(in input, q Quantity, values []float64) error {
+	if !slices.Contains(translational, q) {
+		return f.attitude.update(q, values)
+	}
+	if q == QuantityPosition {
+		if f.origin == nil {
+			f.origin = geo.NewPoint(values[0], values[1])
+			f.originAlt = values[2]
+		}
+		values = f.toLocal(values)
+	}
+	return f.filter.Update(q, values, in.noise[q])
+}
+
+// toLocal converts latitude, longitude, and altitude to east, north, and up in meters from the
+// origin. The conversion is accurate near the origin.
+func (f *fused) toLocal(position []float64) []float64 {
+	lat0 := utils.DegToRad(f.origin.Lat())
+	return []float64{
+		utils.DegToRad(position[1]-f.origin.Lng()) * math.Cos(lat0) * earthRadiusM,
+		utils.DegToRad(position[0]-f.origin.Lat()) * earthRadiusM,
+		position[2] - f.originAlt,
+	}
+}
+
+// fromLocal is the inverse of toLocal.
+func (f *fused) fromLocal(local []float64) (*geo.Point, float64) {
+	lat0 := utils.DegToRad(f.origin.Lat())
+	return geo.NewPoint(
+		f.origin.Lat()+utils.RadToDeg(local[1]/earthRadiusM),
+		f.origin.Lng()+utils.RadToDeg(local[0]/(math.Cos(lat0)*earthRadiusM)),
+	), f.originAlt + local[2]
+}
+
+type measurement struct {
+	quantity Quantity
+	values   []float64
+}
+
+// measure returns the latest measurement of every quantity fused from the input, and the last error
+// measuring any of them.
+func (in input) measure(ctx context.Context) ([]measurement, error) {
+	if in.sensor != nil {
+		return in.measureReadings(ctx)
+	}
+	var measurements []measurement
+	var lastErr error
+	for _, q := range in.quantities {
+		var values []float64
+		var err error
+		switch q {
+		case QuantityPosition:
+			var pos *geo.Point
+			var alt float64
+			if pos, alt, err = in.ms.Position(ctx, nil); err == nil {
+				values = []float64{pos.Lat(), pos.Lng(), alt}
+			}
+		case QuantityLinearVelocity:
+			var v r3.Vector
+			if v, err = in.ms.LinearVelocity(ctx, nil); err == nil {
+				values = []float64{v.X, v.Y, v.Z}
+			}
+		case QuantityLinearAcceleration:
+			var a r3.Vector
+			if a, err = in.ms.LinearAcceleration(ctx, nil); err == nil {
+				values = []float64{a.X, a.Y, a.Z}
+			}
+		case QuantityAngularVelocity:
+			var w spatialmath.AngularVelocity
+			if w, err = in.ms.AngularVelocity(ctx, nil); err == nil {
+				values = []float64{w.X, w.Y, w.Z}
+			}
+		case QuantityOrientation:
+			var o spatialmath.Orientation
+			if o, err = in.ms.Orientation(ctx, nil); err == nil {
+				oq := o.Quaternion()
+				values = []float64{oq.Real, oq.Imag, oq.Jmag, oq.Kmag}
+			}
+		case QuantityCompassHeading:
+			var heading float64
+			if heading, err = in.ms.CompassHeading(ctx, nil); err == nil {
+				values = []float64{heading}
+			}
+		}
+		if err != nil {
+			lastErr = errors.Wrapf(err, "cannot measure %s", q)
+			continue
+		}
+		measurements = append(measurements, measurement{quantity: q, values: values})
+	}
+	return measurements, lastErr
+}
+
+func (in input) measureReadings(ctx context.Context) ([]measurement, error) {
+	readings, err := in.sensor.Readings(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var measurements []measurement
+	var lastErr error
+	for _, q := range in.quantities {
+		keys := in.readings[q]
+		values := make([]float64, 0, len(keys)+1)
+		for _, key := range keys {
+			v, ok := toFloat(readings[key])
+			if !ok {
+				lastErr = errors.Errorf("expected reading %q to be a number but got %v", key, readings[key])
+				break
+			}
+			values = append(values, v)
+		}
+		if len(values) != len(keys) {
+			continue
+		}
+		switch {
+		case q == QuantityPosition && len(values) == 2:
+			values = append(values, 0)
+		case q == QuantityOrientation:
+			oq := (&spatialmath.EulerAngles{
+				Roll:  utils.DegToRad(values[0]),
+				Pitch: utils.DegToRad(values[1]),
+				Yaw:   utils.DegToRad(values[2]),
+			}).Quaternion()
+			values = []float64{oq.Real, oq.Imag, oq.Jmag, oq.Kmag}
+		}
+		measurements = append(measurements, measurement{quantity: q, values: values})
+	}
+	return measurements, lastErr
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// estimate returns the fused estimate of a translational quantity.
+func (f *fused) estimate(q Quantity, unimplemented error) ([]float64, error) {
+	if !f.supported[q] {
+		return nil, unimplemented
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	values, ok := f.filter.Estimate(q)
+	if !ok {
+		return nil, errors.Errorf("no %s has been fused yet", q)
+	}
+	return values, nil
+}
+
+func (f *fused) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	local, err := f.estimate(QuantityPosition, movementsensor.ErrMethodUnimplementedPosition)
+	if err != nil {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pos, alt := f.fromLocal(local)
+	return pos, alt, nil
+}
+
+func (f *fused) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	v, err := f.estimate(QuantityLinearVelocity, movementsensor.ErrMethodUnimplementedLinearVelocity)
+	if err != nil {
+		return r3.Vector{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}, err
+	}
+	return r3.Vector{X: v[0], Y: v[1], Z: v[2]}, nil
+}
+
+func (f *fused) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	a, err := f.estimate(QuantityLinearAcceleration, movementsensor.ErrMethodUnimplementedLinearAcceleration)
+	if err != nil {
+		return r3.Vector{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}, err
+	}
+	return r3.Vector{X: a[0], Y: a[1], Z: a[2]}, nil
+}
+
+func (f *fused) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	nan := spatialmath.AngularVelocity{X: math.NaN(), Y: math.NaN(), Z: math.NaN()}
+	if !f.supported[QuantityAngularVelocity] {
+		return nan, movementsensor.ErrMethodUnimplementedAngularVelocity
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.attitude.angularVel == nil {
+		return nan, errors.Errorf("no %s has been fused yet", QuantityAngularVelocity)
+	}
+	w := f.attitude.angularVel
+	return spatialmath.AngularVelocity{X: w[0], Y: w[1], Z: w[2]}, nil
+}
+
+// orientation returns the fused orientation, which orientations and compass headings are fused into.
+func (f *fused) orientation() (quat.Number, error) {
+	if !f.supported[QuantityOrientation] && !f.supported[QuantityCompassHeading] {
+		return quat.Number{}, movementsensor.ErrMethodUnimplementedOrientation
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.attitude.orientation == nil {
+		return quat.Number{}, errors.Errorf("no %s has been fused yet", QuantityOrientation)
+	}
+	return *f.attitude.orientation, nil
+}
+
+func (f *fused) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	q, err := f.orientation()
+	if err != nil {
+		return &spatialmath.OrientationVector{OX: math.NaN(), OY: math.NaN(), OZ: math.NaN(), Theta: math.NaN()}, err
+	}
+	return (*spatialmath.Quaternion)(&q), nil
+}
+
+func (f *fused) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	q, err := f.orientation()
+	if err != nil {
+		if errors.Is(err, movementsensor.ErrMethodUnimplementedOrientation) {
+			err = movementsensor.ErrMethodUnimplementedCompassHeading
+		}
+		return math.NaN(), err
+	}
+	return quatToHeading(q), nil
+}
+
+// Accuracy reports the standard deviation of each fused value that Kalman filters track, such as
+// "position_east_stddev_m".
+func (f *fused) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	accMap := map[string]float32{}
+	suffixes := map[Quantity][]string{
+		QuantityPosition:           {"east_stddev_m", "north_stddev_m", "up_stddev_m"},
+		QuantityLinearVelocity:     {"x_stddev_mps", "y_stddev_mps", "z_stddev_mps"},
+		QuantityLinearAcceleration: {"x_stddev_mps2", "y_stddev_mps2", "z_stddev_mps2"},
+	}
+	for _, q := range translational {
+		stddevs, ok := f.filter.Uncertainty(q)
+		if !ok {
+			continue
+		}
+		for i, stddev := range stddevs {
+			if i < len(suffixes[q]) {
+				accMap[string(q)+"_"+suffixes[q][i]] = float32(stddev)
+			}
+		}
+	}
+	return &movementsensor.Accuracy{
+		AccuracyMap:        accMap,
+		Hdop:               float32(math.NaN()),
+		Vdop:               float32(math.NaN()),
+		NmeaFix:            -1,
+		CompassDegreeError: float32(math.NaN()),
+	}, nil
+}
+
+func (f *fused) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	attitude := f.supported[QuantityOrientation] || f.supported[QuantityCompassHeading]
+	return &movementsensor.Properties{
+		PositionSupported:           f.supported[QuantityPosition],
+		LinearVelocitySupported:     f.supported[QuantityLinearVelocity],
+		LinearAccelerationSupported: f.supported[QuantityLinearAcceleration],
+		AngularVelocitySupported:    f.supported[QuantityAngularVelocity],
+		OrientationSupported:        attitude,
+		CompassHeadingSupported:     attitude,
+	}, nil
+}
+
+func (f *fused) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.DefaultAPIReadings(ctx, f, extra)
+}
+
+// DoCommand resets the fused estimate under "reset", and reports the inputs that failed to be
+// fused in the last update under "errors".
+func (f *fused) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := cmd["reset"]; ok {
+		f.reset()
+		return map[string]interface{}{"reset": true}, nil
+	}
+	if _, ok := cmd["errors"]; ok {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		errs := map[string]interface{}{}
+		for name, err := range f.lastErrs {
+			errs[name] = err.Error()
+		}
+		return map[string]interface{}{"errors": errs}, nil
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+func (f *fused) Close(ctx context.Context) error {
+	// we do not close the sensors fused, which their own drivers and modules close.
+	f.workers.Stop()
+	return nil
+}
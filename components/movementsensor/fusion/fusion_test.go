@@ -0,0 +1,185 @@
+package fusion
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	cfg := Config{}
+	_, _, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg = Config{Inputs: []InputConfig{
+		{Name: "gps"},
+		{Name: "compass", Readings: map[Quantity][]string{QuantityCompassHeading: {"heading"}}},
+	}}
+	deps, _, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"gps", "compass"})
+
+	for _, bad := range []Config{
+		{Inputs: []InputConfig{{}}},
+		{Inputs: []InputConfig{{Name: "gps", Quantities: []Quantity{"altitude"}}}},
+		{Inputs: []InputConfig{{Name: "gps", Readings: map[Quantity][]string{QuantityPosition: {"lat"}}}}},
+		{Inputs: []InputConfig{{Name: "gps", Noise: map[Quantity]float64{QuantityPosition: 0}}}},
+		{Inputs: []InputConfig{{Name: "gps"}}, Filter: "particle"},
+		{Inputs: []InputConfig{{Name: "gps"}}, Filter: FilterEKF, Model: "unknown"},
+		{Inputs: []InputConfig{{Name: "gps"}}, Alpha: 1},
+	} {
+		_, _, err := bad.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func newTestFused(t *testing.T, cfg *Config, deps resource.Dependencies) movementsensor.MovementSensor {
+	t.Helper()
+	_, _, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	ms, err := newFused(context.Background(), deps, resource.Config{
+		Name:                "fused",
+		API:                 movementsensor.API,
+		Model:               Model,
+		ConvertedAttributes: cfg,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() {
+		test.That(t, ms.Close(context.Background()), test.ShouldBeNil)
+	})
+	return ms
+}
+
+func TestFusion(t *testing.T) {
+	ctx := context.Background()
+	gps := inject.NewMovementSensor("gps")
+	gps.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{PositionSupported: true}, nil
+	}
+	gps.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return geo.NewPoint(40.7, -74), 10, nil
+	}
+	imu := inject.NewMovementSensor("imu")
+	imu.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{AngularVelocitySupported: true, LinearAccelerationSupported: true}, nil
+	}
+	imu.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{}, nil
+	}
+	compass := inject.NewSensor("compass")
+	compass.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"heading": 90.0}, nil
+	}
+	deps := resource.Dependencies{
+		movementsensor.Named("gps"): gps,
+		movementsensor.Named("imu"): imu,
+		sensor.Named("compass"):     compass,
+	}
+
+	ms := newTestFused(t, &Config{
+		Inputs: []InputConfig{
+			{Name: "gps"},
+			{Name: "imu", Quantities: []Quantity{QuantityAngularVelocity}},
+			{Name: "compass", Readings: map[Quantity][]string{QuantityCompassHeading: {"heading"}}},
+		},
+		Filter:       FilterEKF,
+		UpdateRateHz: 100,
+	}, deps)
+
+	props, err := ms.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	// the Kalman filter estimates velocity from positions.
+	test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+		PositionSupported:        true,
+		LinearVelocitySupported:  true,
+		AngularVelocitySupported: true,
+		OrientationSupported:     true,
+		CompassHeadingSupported:  true,
+	})
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		pos, alt, err := ms.Position(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, pos.Lat(), test.ShouldAlmostEqual, 40.7, 1e-6)
+		test.That(tb, pos.Lng(), test.ShouldAlmostEqual, -74, 1e-6)
+		test.That(tb, alt, test.ShouldAlmostEqual, 10, 1e-3)
+		heading, err := ms.CompassHeading(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, heading, test.ShouldAlmostEqual, 90, 1e-6)
+	})
+	vel, err := ms.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, vel.Norm(), test.ShouldBeLessThan, 0.1)
+	_, err = ms.LinearAcceleration(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedLinearAcceleration)
+
+	acc, err := ms.Accuracy(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, acc.AccuracyMap["position_east_stddev_m"], test.ShouldBeLessThan, 1)
+
+	readings, err := ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings["compass"], test.ShouldAlmostEqual, 90, 1e-6)
+
+	// failing inputs are reported and the rest are still fused.
+	gps.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return nil, 0, errors.New("no fix")
+	}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		resp, err := ms.DoCommand(ctx, map[string]interface{}{"errors": true})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, resp["errors"], test.ShouldContainKey, "gps")
+	})
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"reset": true})
+	test.That(t, err, test.ShouldBeNil)
+	_, _, err = ms.Position(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+}
+
+func TestFusionInputs(t *testing.T) {
+	ctx := context.Background()
+	nothing := inject.NewMovementSensor("nothing")
+	nothing.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+		return &movementsensor.Properties{}, nil
+	}
+	imu := inject.NewMovementSensor("imu")
+	deps := resource.Dependencies{
+		movementsensor.Named("nothing"): nothing,
+		movementsensor.Named("imu"):     imu,
+	}
+	cfg := &Config{Inputs: []InputConfig{{Name: "nothing"}}}
+	_, err := newFused(ctx, deps, resource.Config{Name: "fused", ConvertedAttributes: cfg}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a constant velocity model cannot fuse acceleration.
+	cfg = &Config{
+		Inputs: []InputConfig{{Name: "imu", Quantities: []Quantity{QuantityLinearAcceleration}}},
+		Filter: FilterUKF,
+	}
+	_, err = newFused(ctx, deps, resource.Config{Name: "fused", ConvertedAttributes: cfg}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	cfg.Model = "constant_acceleration"
+	ms := newTestFused(t, cfg, deps)
+	_, _, err = ms.Position(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = ms.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedOrientation)
+	_, err = ms.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedCompassHeading)
+	_, err = ms.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeError, movementsensor.ErrMethodUnimplementedAngularVelocity)
+}
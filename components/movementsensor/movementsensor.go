@@ -76,6 +76,7 @@ func Named(name string) resource.Name {
 }
 
 // A MovementSensor reports information about the robot's direction, position and speed.
+// Its readings can be subscribed to with resource.StreamReadings.
 // For more information, see the [movement sensor component docs].
 //
 // Position example:
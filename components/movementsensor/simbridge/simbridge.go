@@ -0,0 +1,263 @@
+// Package simbridge implements a movement sensor bound to the sensors of a robot in a running
+// Gazebo or Isaac Sim simulation over ROS 2 topics through rosbridge. It reads nav_msgs/Odometry,
+// sensor_msgs/Imu and sensor_msgs/NavSatFix messages, whichever are configured.
+//
+// Velocities and accelerations are converted from the ROS body frame, x forward and y left, to the
+// RDK's, y forward and x right. Orientations are as the simulation reports them, and compass headings
+// follow from them taking the simulation's x axis to point east, as ROS does.
+package simbridge
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/ros/rosbridge"
+	"go.viam.com/rdk/spatialmath"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// Model is the model of movement sensors bound to a simulation.
+var Model = resource.DefaultModelFamily.WithModel("sim-bridge")
+
+func init() {
+	resource.RegisterComponent(movementsensor.API, Model, resource.Registration[movementsensor.MovementSensor, *Config]{
+		Constructor: NewMovementSensor,
+	})
+}
+
+// Config binds a movement sensor to simulated sensors.
+type Config struct {
+	// RosbridgeURL is the websocket URL of the rosbridge server, such as ws://localhost:9090.
+	RosbridgeURL string `json:"rosbridge_url"`
+	// OdometryTopic, such as /odom, provides linear and angular velocity and orientation.
+	OdometryTopic string `json:"odometry_topic,omitempty"`
+	// IMUTopic, such as /imu, provides angular velocity, linear acceleration and orientation, which
+	// are preferred to the odometry's.
+	IMUTopic string `json:"imu_topic,omitempty"`
+	// GPSTopic, such as /gps/fix, provides position.
+	GPSTopic string `json:"gps_topic,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if err := rosbridge.ValidateURL(path, conf.RosbridgeURL); err != nil {
+		return nil, nil, err
+	}
+	if conf.OdometryTopic == "" && conf.IMUTopic == "" && conf.GPSTopic == "" {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("at least one of odometry_topic, imu_topic and gps_topic is required"))
+	}
+	return nil, nil, nil
+}
+
+type simSensor struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf   *Config
+	client *rosbridge.Client
+	logger logging.Logger
+
+	mu       sync.Mutex
+	odometry *rosbridge.Odometry
+	imu      *rosbridge.Imu
+	fix      *rosbridge.NavSatFix
+}
+
+// NewMovementSensor returns a movement sensor bound to simulated sensors.
+func NewMovementSensor(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (movementsensor.MovementSensor, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	s := &simSensor{
+		Named:  conf.ResourceName().AsNamed(),
+		conf:   newConf,
+		client: rosbridge.NewClient(newConf.RosbridgeURL, logger),
+		logger: logger,
+	}
+	if newConf.OdometryTopic != "" {
+		subscribe(s, newConf.OdometryTopic, rosbridge.TypeOdometry, &s.odometry)
+	}
+	if newConf.IMUTopic != "" {
+		subscribe(s, newConf.IMUTopic, rosbridge.TypeImu, &s.imu)
+	}
+	if newConf.GPSTopic != "" {
+		subscribe(s, newConf.GPSTopic, rosbridge.TypeNavSatFix, &s.fix)
+	}
+	return s, nil
+}
+
+// subscribe stores each message of msgType received on topic in latest.
+func subscribe[T any](s *simSensor, topic, msgType string, latest **T) {
+	s.client.Subscribe(topic, msgType, func(data json.RawMessage) {
+		msg := new(T)
+		if err := json.Unmarshal(data, msg); err != nil {
+			s.logger.Warnw("invalid message", "topic", topic, "error", err)
+			return
+		}
+		s.mu.Lock()
+		*latest = msg
+		s.mu.Unlock()
+	})
+}
+
+// latest returns a copy of the latest message on topic, or an error if none has been received.
+func latest[T any](s *simSensor, topic string, msg **T) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if *msg == nil {
+		var zero T
+		return zero, errors.Errorf("no message received on %s yet", topic)
+	}
+	return **msg, nil
+}
+
+// bodyVector converts a vector in the ROS body frame, scaled by scale, to the RDK's.
+func bodyVector(v rosbridge.Vector3, scale float64) r3.Vector {
+	return r3.Vector{X: -v.Y * scale, Y: v.X * scale, Z: v.Z * scale}
+}
+
+// quaternion returns the orientation reported by the IMU, or the odometry without one.
+func (s *simSensor) quaternion() (rosbridge.Quaternion, error) {
+	switch {
+	case s.conf.IMUTopic != "":
+		msg, err := latest(s, s.conf.IMUTopic, &s.imu)
+		return msg.Orientation, err
+	case s.conf.OdometryTopic != "":
+		msg, err := latest(s, s.conf.OdometryTopic, &s.odometry)
+		return msg.Pose.Pose.Orientation, err
+	default:
+		return rosbridge.Quaternion{}, movementsensor.ErrMethodUnimplementedOrientation
+	}
+}
+
+// Position returns the position of the simulated GPS.
+func (s *simSensor) Position(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+	if s.conf.GPSTopic == "" {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), movementsensor.ErrMethodUnimplementedPosition
+	}
+	msg, err := latest(s, s.conf.GPSTopic, &s.fix)
+	if err != nil {
+		return geo.NewPoint(math.NaN(), math.NaN()), math.NaN(), err
+	}
+	return geo.NewPoint(msg.Latitude, msg.Longitude), msg.Altitude, nil
+}
+
+// LinearVelocity returns the velocity measured by the simulated odometry, in millimeters per second.
+func (s *simSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if s.conf.OdometryTopic == "" {
+		return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearVelocity
+	}
+	msg, err := latest(s, s.conf.OdometryTopic, &s.odometry)
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return bodyVector(msg.Twist.Twist.Linear, 1000), nil
+}
+
+// AngularVelocity returns the angular velocity measured by the simulated IMU, or odometry without
+// one, in degrees per second.
+func (s *simSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	var velocity rosbridge.Vector3
+	switch {
+	case s.conf.IMUTopic != "":
+		msg, err := latest(s, s.conf.IMUTopic, &s.imu)
+		if err != nil {
+			return spatialmath.AngularVelocity{}, err
+		}
+		velocity = msg.AngularVelocity
+	case s.conf.OdometryTopic != "":
+		msg, err := latest(s, s.conf.OdometryTopic, &s.odometry)
+		if err != nil {
+			return spatialmath.AngularVelocity{}, err
+		}
+		velocity = msg.Twist.Twist.Angular
+	default:
+		return spatialmath.AngularVelocity{}, movementsensor.ErrMethodUnimplementedAngularVelocity
+	}
+	return spatialmath.AngularVelocity(bodyVector(velocity, rutils.RadToDeg(1))), nil
+}
+
+// LinearAcceleration returns the acceleration measured by the simulated IMU, in millimeters per
+// second squared.
+func (s *simSensor) LinearAcceleration(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	if s.conf.IMUTopic == "" {
+		return r3.Vector{}, movementsensor.ErrMethodUnimplementedLinearAcceleration
+	}
+	msg, err := latest(s, s.conf.IMUTopic, &s.imu)
+	if err != nil {
+		return r3.Vector{}, err
+	}
+	return bodyVector(msg.LinearAcceleration, 1000), nil
+}
+
+// CompassHeading returns the heading of the simulated robot in degrees clockwise from north.
+func (s *simSensor) CompassHeading(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	q, err := s.quaternion()
+	if errors.Is(err, movementsensor.ErrMethodUnimplementedOrientation) {
+		return 0, movementsensor.ErrMethodUnimplementedCompassHeading
+	}
+	if err != nil {
+		return 0, err
+	}
+	yaw := rutils.RadToDeg(math.Atan2(2*(q.W*q.Z+q.X*q.Y), 1-2*(q.Y*q.Y+q.Z*q.Z)))
+	return math.Mod(450-yaw, 360), nil
+}
+
+// Orientation returns the orientation of the simulated robot.
+func (s *simSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	q, err := s.quaternion()
+	if err != nil {
+		return nil, err
+	}
+	return q.Orientation(), nil
+}
+
+// Properties returns which measurements the configured topics provide.
+func (s *simSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	odometry, imu := s.conf.OdometryTopic != "", s.conf.IMUTopic != ""
+	return &movementsensor.Properties{
+		PositionSupported:           s.conf.GPSTopic != "",
+		OrientationSupported:        odometry || imu,
+		CompassHeadingSupported:     odometry || imu,
+		LinearVelocitySupported:     odometry,
+		AngularVelocitySupported:    odometry || imu,
+		LinearAccelerationSupported: imu,
+	}, nil
+}
+
+// Accuracy is not reported by the simulation.
+func (s *simSensor) Accuracy(ctx context.Context, extra map[string]interface{}) (*movementsensor.Accuracy, error) {
+	return movementsensor.UnimplementedOptionalAccuracies(), nil
+}
+
+// Readings returns the measurements the configured topics provide.
+func (s *simSensor) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return movementsensor.DefaultAPIReadings(ctx, s, extra)
+}
+
+// DoCommand reports whether the movement sensor is connected to the simulation.
+func (s *simSensor) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"connected": s.client.Connected()}, nil
+}
+
+// Close disconnects from the simulation.
+func (s *simSensor) Close(ctx context.Context) error {
+	s.client.Close()
+	return nil
+}
@@ -0,0 +1,118 @@
+package simbridge
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/ros/rosbridge"
+)
+
+func newMovementSensor(t *testing.T, conf *Config) movementsensor.MovementSensor {
+	t.Helper()
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	ms, err := NewMovementSensor(context.Background(), nil, resource.Config{
+		Name:                "ms",
+		API:                 movementsensor.API,
+		Model:               Model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() {
+		test.That(t, ms.Close(context.Background()), test.ShouldBeNil)
+	})
+	return ms
+}
+
+func TestValidate(t *testing.T) {
+	_, _, err := (&Config{RosbridgeURL: "ws://localhost:9090"}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least one")
+	_, _, err = (&Config{RosbridgeURL: "http://localhost:9090", IMUTopic: "/imu"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestMovementSensor(t *testing.T) {
+	ctx := context.Background()
+	fs := rosbridge.NewFakeServer()
+	defer fs.Close()
+	ms := newMovementSensor(t, &Config{RosbridgeURL: fs.URL(), OdometryTopic: "/odom", IMUTopic: "/imu"})
+
+	props, err := ms.Properties(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props, test.ShouldResemble, &movementsensor.Properties{
+		OrientationSupported:        true,
+		CompassHeadingSupported:     true,
+		LinearVelocitySupported:     true,
+		AngularVelocitySupported:    true,
+		LinearAccelerationSupported: true,
+	})
+	_, _, err = ms.Position(ctx, nil)
+	test.That(t, errors.Is(err, movementsensor.ErrMethodUnimplementedPosition), test.ShouldBeTrue)
+	_, err = ms.LinearVelocity(ctx, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no message received on /odom")
+
+	// the robot faces north, a quarter turn counterclockwise from east, driving forward and turning left.
+	odom := rosbridge.Odometry{}
+	odom.Twist.Twist = rosbridge.Twist{Linear: rosbridge.Vector3{X: .5, Y: .1}, Angular: rosbridge.Vector3{Z: 1}}
+	imu := rosbridge.Imu{
+		Orientation:        rosbridge.Quaternion{Z: math.Sqrt2 / 2, W: math.Sqrt2 / 2},
+		AngularVelocity:    rosbridge.Vector3{Z: math.Pi},
+		LinearAcceleration: rosbridge.Vector3{X: 1, Z: 9.8},
+	}
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, fs.Publish(ctx, "/odom", odom), test.ShouldBeNil)
+		test.That(tb, fs.Publish(ctx, "/imu", imu), test.ShouldBeNil)
+		_, err := ms.LinearVelocity(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		_, err = ms.LinearAcceleration(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+	})
+
+	velocity, err := ms.LinearVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, velocity, test.ShouldResemble, r3.Vector{X: -100, Y: 500})
+	angular, err := ms.AngularVelocity(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, angular.Z, test.ShouldAlmostEqual, 180)
+	accel, err := ms.LinearAcceleration(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, accel, test.ShouldResemble, r3.Vector{Y: 1000, Z: 9800})
+	heading, err := ms.CompassHeading(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, heading, test.ShouldAlmostEqual, 0)
+	orientation, err := ms.Orientation(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, orientation.OrientationVectorDegrees().Theta, test.ShouldAlmostEqual, 90)
+
+	readings, err := ms.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readings, test.ShouldContainKey, "linear_velocity")
+	test.That(t, readings, test.ShouldNotContainKey, "position")
+}
+
+func TestGPS(t *testing.T) {
+	ctx := context.Background()
+	fs := rosbridge.NewFakeServer()
+	defer fs.Close()
+	ms := newMovementSensor(t, &Config{RosbridgeURL: fs.URL(), GPSTopic: "/gps/fix"})
+
+	_, err := ms.CompassHeading(ctx, nil)
+	test.That(t, errors.Is(err, movementsensor.ErrMethodUnimplementedCompassHeading), test.ShouldBeTrue)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, fs.Publish(ctx, "/gps/fix", rosbridge.NavSatFix{Latitude: 40.7, Longitude: -74, Altitude: 10}), test.ShouldBeNil)
+		point, alt, err := ms.Position(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, point.Lat(), test.ShouldEqual, 40.7)
+		test.That(tb, point.Lng(), test.ShouldEqual, -74)
+		test.That(tb, alt, test.ShouldEqual, 10)
+	})
+}
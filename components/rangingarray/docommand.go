@@ -0,0 +1,200 @@
+package rangingarray
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoGetRanges     = "get_ranges"
+	DoGetProperties = "get_ranging_array_properties"
+)
+
+// distanceReading is the reading holding the distance to the nearest detection in meters.
+const distanceReading = "distance"
+
+// rangesMessage is the form ranges take in DoCommand responses and readings, with their detections
+// in columns as lidar scans are.
+type rangesMessage struct {
+	Beams                    []int     `json:"beams"`
+	DistancesMM              []float64 `json:"distances_mm"`
+	Azimuths                 []float64 `json:"azimuths"`
+	Elevations               []float64 `json:"elevations"`
+	RadialVelocitiesMMPerSec []float64 `json:"radial_velocities_mm_per_sec"`
+	Time                     time.Time `json:"time"`
+}
+
+// Validate ensures every column has a value for each detection.
+func (m rangesMessage) Validate() error {
+	n := len(m.DistancesMM)
+	if len(m.Beams) != n || len(m.Azimuths) != n || len(m.Elevations) != n || len(m.RadialVelocitiesMMPerSec) != n {
+		return errors.Errorf("ranges have %d beams, %d distances, %d azimuths, %d elevations and %d radial velocities",
+			len(m.Beams), n, len(m.Azimuths), len(m.Elevations), len(m.RadialVelocitiesMMPerSec))
+	}
+	return nil
+}
+
+func rangesToMessage(r *Ranges) rangesMessage {
+	n := len(r.Detections)
+	msg := rangesMessage{
+		Beams:                    make([]int, n),
+		DistancesMM:              make([]float64, n),
+		Azimuths:                 make([]float64, n),
+		Elevations:               make([]float64, n),
+		RadialVelocitiesMMPerSec: make([]float64, n),
+		Time:                     r.Time,
+	}
+	for i, d := range r.Detections {
+		msg.Beams[i], msg.DistancesMM[i] = d.Beam, d.DistanceMM
+		msg.Azimuths[i], msg.Elevations[i] = d.Azimuth, d.Elevation
+		msg.RadialVelocitiesMMPerSec[i] = d.RadialVelocityMMPerSec
+	}
+	return msg
+}
+
+func (m rangesMessage) ranges() (*Ranges, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	r := &Ranges{Detections: make([]Detection, len(m.DistancesMM)), Time: m.Time}
+	for i := range r.Detections {
+		r.Detections[i] = Detection{
+			Beam:                   m.Beams[i],
+			DistanceMM:             m.DistancesMM[i],
+			Azimuth:                m.Azimuths[i],
+			Elevation:              m.Elevations[i],
+			RadialVelocityMMPerSec: m.RadialVelocitiesMMPerSec[i],
+		}
+	}
+	return r, nil
+}
+
+// beamMessage is the form a beam takes in DoCommand responses.
+type beamMessage struct {
+	Name          string    `json:"name,omitempty"`
+	PositionMM    r3.Vector `json:"position_mm"`
+	Azimuth       float64   `json:"azimuth"`
+	Elevation     float64   `json:"elevation"`
+	HorizontalFOV float64   `json:"horizontal_fov,omitempty"`
+	VerticalFOV   float64   `json:"vertical_fov,omitempty"`
+	MinRangeMM    float64   `json:"min_range_mm,omitempty"`
+	MaxRangeMM    float64   `json:"max_range_mm,omitempty"`
+}
+
+// propertiesMessage is the form properties take in DoCommand responses.
+type propertiesMessage struct {
+	Kind        Kind          `json:"kind,omitempty"`
+	Beams       []beamMessage `json:"beams"`
+	RateHz      float64       `json:"rate_hz,omitempty"`
+	HasVelocity bool          `json:"has_velocity"`
+}
+
+func propertiesToMessage(props Properties) propertiesMessage {
+	msg := propertiesMessage{
+		Kind:        props.Kind,
+		Beams:       make([]beamMessage, len(props.Beams)),
+		RateHz:      props.RateHz,
+		HasVelocity: props.HasVelocity,
+	}
+	for i, b := range props.Beams {
+		msg.Beams[i] = beamMessage(b)
+	}
+	return msg
+}
+
+func (m propertiesMessage) properties() Properties {
+	props := Properties{Kind: m.Kind, Beams: make([]Beam, len(m.Beams)), RateHz: m.RateHz, HasVelocity: m.HasVelocity}
+	for i, b := range m.Beams {
+		props.Beams[i] = Beam(b)
+	}
+	return props
+}
+
+type commandMessage struct {
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type getRangesCommand struct {
+	commandMessage
+	GetRanges bool `json:"get_ranges"`
+}
+
+type getPropertiesCommand struct {
+	GetProperties bool `json:"get_ranging_array_properties"`
+}
+
+// HandleRangingArrayCommand services the ranging array DoCommand keys using the given array, so that
+// ranging arrays can be used through DoCommand by callers that only have a generic resource handle,
+// such as the client of an array provided by a module. It returns false if cmd does not contain any
+// of them so that it can be chained from a DoCommand implementation.
+func HandleRangingArrayCommand(
+	ctx context.Context, a RangingArray, cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	switch {
+	case cmd[DoGetRanges] != nil:
+		req, err := resource.DecodeDoCommand[getRangesCommand](cmd)
+		if err != nil {
+			return nil, true, err
+		}
+		r, err := a.Ranges(ctx, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(rangesToMessage(r))
+		return resp, true, err
+	case cmd[DoGetProperties] != nil:
+		props, err := a.Properties(ctx)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(propertiesToMessage(props))
+		return resp, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// FromResource returns a RangingArray that is used through the DoCommand of res, which must handle
+// the ranging array DoCommand keys as HandleRangingArrayCommand does. It lets ranging arrays be
+// provided by modules as generic components or sensors.
+func FromResource(res resource.Resource) RangingArray {
+	if a, ok := res.(RangingArray); ok {
+		return a
+	}
+	return &doCommandRangingArray{Resource: res}
+}
+
+type doCommandRangingArray struct {
+	resource.Resource
+}
+
+func (a *doCommandRangingArray) Ranges(ctx context.Context, extra map[string]interface{}) (*Ranges, error) {
+	resp, err := resource.DoCommandAs[getRangesCommand, rangesMessage](
+		ctx, a, getRangesCommand{commandMessage: commandMessage{Extra: extra}, GetRanges: true})
+	if err != nil {
+		return nil, err
+	}
+	return resp.ranges()
+}
+
+func (a *doCommandRangingArray) Properties(ctx context.Context) (Properties, error) {
+	resp, err := resource.DoCommandAs[getPropertiesCommand, propertiesMessage](ctx, a, getPropertiesCommand{GetProperties: true})
+	if err != nil {
+		return Properties{}, err
+	}
+	return resp.properties(), nil
+}
+
+func (a *doCommandRangingArray) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	r, err := a.Ranges(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return RangesToReadings(r)
+}
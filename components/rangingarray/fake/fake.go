@@ -0,0 +1,160 @@
+// Package fake implements a fake ring of ultrasonic beams at the center of an empty room.
+package fake
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/rangingarray"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("fake")
+
+const (
+	defaultRoomLengthMM         = 6000.
+	defaultRoomWidthMM          = 4000.
+	defaultNumBeams             = 8
+	defaultRingRadiusMM         = 150.
+	defaultHorizontalFOVDegrees = 30.
+	defaultMaxRangeMM           = 4000.
+	minRangeMM                  = 20.
+	rateHz                      = 10.
+)
+
+func init() {
+	resource.RegisterComponent(rangingarray.API, model, resource.Registration[rangingarray.RangingArray, *Config]{
+		Constructor: NewRangingArray,
+	})
+}
+
+// Config is the config for a fake ranging array.
+type Config struct {
+	// RoomLengthMM and RoomWidthMM are the size of the room along the X and Y of the array, 6000 by
+	// 4000 by default.
+	RoomLengthMM float64 `json:"room_length_mm,omitempty"`
+	RoomWidthMM  float64 `json:"room_width_mm,omitempty"`
+	// NumBeams is how many beams are evenly spaced around the ring, the first facing +X, 8 by default.
+	NumBeams     int     `json:"num_beams,omitempty"`
+	RingRadiusMM float64 `json:"ring_radius_mm,omitempty"`
+	// HorizontalFOVDegrees is the field of view of each beam, 30 degrees by default.
+	HorizontalFOVDegrees float64 `json:"horizontal_fov_degrees,omitempty"`
+	MaxRangeMM           float64 `json:"max_range_mm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.RoomLengthMM < 0 || conf.RoomWidthMM < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("room dimensions cannot be negative"))
+	}
+	if conf.NumBeams < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("num_beams cannot be negative"))
+	}
+	if conf.RingRadiusMM < 0 || conf.MaxRangeMM < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("ring_radius_mm and max_range_mm cannot be negative"))
+	}
+	if conf.HorizontalFOVDegrees < 0 || conf.HorizontalFOVDegrees > 180 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("horizontal_fov_degrees must be between 0 and 180"))
+	}
+	return nil, nil, nil
+}
+
+// RangingArray is a fake ring of ultrasonic beams at the center of an empty room.
+type RangingArray struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	halfLength, halfWidth float64
+	beams                 []rangingarray.Beam
+}
+
+// NewRangingArray instantiates a new ranging array of the fake model type.
+func NewRangingArray(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (rangingarray.RangingArray, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	orDefault := func(v, def float64) float64 {
+		if v == 0 {
+			return def
+		}
+		return v
+	}
+	numBeams := newConf.NumBeams
+	if numBeams == 0 {
+		numBeams = defaultNumBeams
+	}
+	radius := orDefault(newConf.RingRadiusMM, defaultRingRadiusMM)
+	a := &RangingArray{
+		Named:      conf.ResourceName().AsNamed(),
+		halfLength: orDefault(newConf.RoomLengthMM, defaultRoomLengthMM) / 2,
+		halfWidth:  orDefault(newConf.RoomWidthMM, defaultRoomWidthMM) / 2,
+		beams:      make([]rangingarray.Beam, numBeams),
+	}
+	fov := orDefault(newConf.HorizontalFOVDegrees, defaultHorizontalFOVDegrees) * math.Pi / 180
+	for i := range a.beams {
+		azimuth := 2 * math.Pi * float64(i) / float64(numBeams)
+		a.beams[i] = rangingarray.Beam{
+			PositionMM:    r3.Vector{X: radius * math.Cos(azimuth), Y: radius * math.Sin(azimuth)},
+			Azimuth:       azimuth,
+			HorizontalFOV: fov,
+			VerticalFOV:   fov,
+			MinRangeMM:    minRangeMM,
+			MaxRangeMM:    orDefault(newConf.MaxRangeMM, defaultMaxRangeMM),
+		}
+	}
+	return a, nil
+}
+
+// rangeTo returns the distance from the beam to the walls of the room along its center.
+func (a *RangingArray) rangeTo(beam rangingarray.Beam) float64 {
+	r := math.Inf(1)
+	if c := math.Cos(beam.Azimuth); math.Abs(c) > 1e-9 {
+		r = (math.Copysign(a.halfLength, c) - beam.PositionMM.X) / c
+	}
+	if s := math.Sin(beam.Azimuth); math.Abs(s) > 1e-9 {
+		r = math.Min(r, (math.Copysign(a.halfWidth, s)-beam.PositionMM.Y)/s)
+	}
+	return r
+}
+
+// Ranges returns the distances to the walls the beams reach.
+func (a *RangingArray) Ranges(ctx context.Context, extra map[string]interface{}) (*rangingarray.Ranges, error) {
+	r := &rangingarray.Ranges{Time: time.Now()}
+	for i, beam := range a.beams {
+		if d := a.rangeTo(beam); d >= beam.MinRangeMM && d <= beam.MaxRangeMM {
+			r.Detections = append(r.Detections, rangingarray.Detection{Beam: i, DistanceMM: d})
+		}
+	}
+	return r, nil
+}
+
+// Properties returns the beams of the ring.
+func (a *RangingArray) Properties(ctx context.Context) (rangingarray.Properties, error) {
+	return rangingarray.Properties{Kind: rangingarray.KindUltrasonic, Beams: a.beams, RateHz: rateHz}, nil
+}
+
+// Readings returns the ranges in the form produced by rangingarray.RangesToReadings.
+func (a *RangingArray) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	r, err := a.Ranges(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return rangingarray.RangesToReadings(r)
+}
+
+// DoCommand handles the ranging array DoCommand keys.
+func (a *RangingArray) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, ok, err := rangingarray.HandleRangingArrayCommand(ctx, a, cmd); ok {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
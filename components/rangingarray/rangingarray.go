@@ -0,0 +1,195 @@
+// Package rangingarray defines ranging arrays, which measure the distances to obstacles along
+// several fixed beams, each with its own field of view, such as the ultrasonic rings of mobile bases
+// and automotive radars. Unlike cameras and lidars, whose beams are narrow enough for their returns
+// to be points, a return of a ranging array may be anywhere within the field of view of its beam, so
+// the beams are reported alongside their detections for consumers to reason about coverage.
+package rangingarray
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[RangingArray]{})
+}
+
+// SubtypeName is a constant that identifies the component resource API string.
+const SubtypeName = "ranging_array"
+
+// API is a variable that identifies the component resource API.
+var API = resource.APINamespaceRDK.WithComponentType(SubtypeName)
+
+// Named is a helper for getting the named ranging array's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// A RangingArray measures the distances to obstacles along its beams.
+type RangingArray interface {
+	resource.Sensor
+	resource.Resource
+
+	// Ranges returns the latest detections of every beam.
+	Ranges(ctx context.Context, extra map[string]interface{}) (*Ranges, error)
+
+	// Properties returns the beams of the array and what they measure.
+	Properties(ctx context.Context) (Properties, error)
+}
+
+// Deprecated: FromRobot is a helper for getting the named RangingArray from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (RangingArray, error) {
+	return robot.ResourceFromRobot[RangingArray](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named RangingArray from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (RangingArray, error) {
+	return resource.FromProvider[RangingArray](provider, Named(name))
+}
+
+// NamesFromRobot is a helper for getting all ranging array names from the given Robot.
+func NamesFromRobot(r robot.Robot) []string {
+	return robot.NamesByAPI(r, API)
+}
+
+// A Kind is how a ranging array measures distances.
+type Kind string
+
+const (
+	// KindUltrasonic arrays time echoes of sound, over wide fields of view and short ranges.
+	KindUltrasonic = Kind("ultrasonic")
+	// KindRadar arrays measure radio returns, which resolve their direction within the beam and their
+	// radial velocity.
+	KindRadar = Kind("radar")
+	// KindTimeOfFlight arrays time pulses of infrared light, over narrow fields of view.
+	KindTimeOfFlight = Kind("time_of_flight")
+)
+
+// A Beam is one of the fields of view a ranging array measures. Positions are in millimeters and
+// angles in radians, in the frame of the array, with azimuths counterclockwise about +Z from +X, which
+// is the front of the array, and elevations above the XY plane.
+type Beam struct {
+	Name string
+	// PositionMM is where the beam originates.
+	PositionMM r3.Vector
+	// Azimuth and Elevation are the direction of the center of the beam.
+	Azimuth   float64
+	Elevation float64
+	// HorizontalFOV and VerticalFOV are the full widths of the field of view of the beam.
+	HorizontalFOV float64
+	VerticalFOV   float64
+	MinRangeMM    float64
+	MaxRangeMM    float64
+}
+
+// Properties describe what a ranging array measures and how. Zero values are unknown.
+type Properties struct {
+	Kind  Kind
+	Beams []Beam
+	// RateHz is how many times the array measures every beam each second.
+	RateHz float64
+	// HasVelocity is whether detections report the radial velocities of their returns.
+	HasVelocity bool
+}
+
+// A Detection is a return measured by one beam.
+type Detection struct {
+	// Beam is the index of the beam in the Beams of the array's Properties.
+	Beam       int
+	DistanceMM float64
+	// Azimuth and Elevation are the direction of the return from the center of its beam, for arrays
+	// such as radars that resolve it, and zero otherwise.
+	Azimuth   float64
+	Elevation float64
+	// RadialVelocityMMPerSec is how fast the return moves away from the beam, which is negative as it
+	// approaches, or zero if the array does not measure it.
+	RadialVelocityMMPerSec float64
+}
+
+// Point returns where the return is in the array's frame, in millimeters, taking it to be along the
+// center of its beam unless its direction is resolved.
+func (d Detection) Point(beam Beam) r3.Vector {
+	azimuth, elevation := beam.Azimuth+d.Azimuth, beam.Elevation+d.Elevation
+	horizontal := d.DistanceMM * math.Cos(elevation)
+	return beam.PositionMM.Add(r3.Vector{
+		X: horizontal * math.Cos(azimuth),
+		Y: horizontal * math.Sin(azimuth),
+		Z: d.DistanceMM * math.Sin(elevation),
+	})
+}
+
+// Ranges are what a ranging array measured at once. A beam may have several detections, or none if
+// nothing was in range.
+type Ranges struct {
+	Detections []Detection
+	Time       time.Time
+}
+
+// Nearest returns the detection closest to its beam, and false if there are none.
+func (r *Ranges) Nearest() (Detection, bool) {
+	var nearest Detection
+	found := false
+	for _, d := range r.Detections {
+		if !found || d.DistanceMM < nearest.DistanceMM {
+			nearest, found = d, true
+		}
+	}
+	return nearest, found
+}
+
+// NearestInDirection returns the distance to the nearest detection of a beam whose horizontal field
+// of view overlaps the sector of half angle halfWidth about azimuth, and false if there are none.
+// It lets a consumer find what is in its way without knowing the layout of the beams.
+func NearestInDirection(props Properties, r *Ranges, azimuth, halfWidth float64) (float64, bool) {
+	nearest, found := math.Inf(1), false
+	for _, d := range r.Detections {
+		if d.Beam < 0 || d.Beam >= len(props.Beams) {
+			continue
+		}
+		beam := props.Beams[d.Beam]
+		if angularDistance(beam.Azimuth, azimuth) > halfWidth+beam.HorizontalFOV/2 {
+			continue
+		}
+		if d.DistanceMM < nearest {
+			nearest, found = d.DistanceMM, true
+		}
+	}
+	return nearest, found
+}
+
+// angularDistance returns the smaller angle between two azimuths.
+func angularDistance(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 2*math.Pi)
+	return math.Min(d, 2*math.Pi-d)
+}
+
+// RangesToReadings converts Ranges into the readings of a ranging array. The readings also hold the
+// distance to the nearest detection in meters under "distance", as proximity sensors report it.
+func RangesToReadings(r *Ranges) (map[string]interface{}, error) {
+	readings, err := resource.EncodeDoCommand(rangesToMessage(r))
+	if err != nil {
+		return nil, err
+	}
+	if nearest, ok := r.Nearest(); ok {
+		readings[distanceReading] = nearest.DistanceMM / 1000
+	}
+	return readings, nil
+}
+
+// RangesFromReadings converts the readings of a ranging array back into Ranges.
+func RangesFromReadings(readings map[string]interface{}) (*Ranges, error) {
+	msg, err := resource.DecodeDoCommand[rangesMessage](readings)
+	if err != nil {
+		return nil, err
+	}
+	return msg.ranges()
+}
@@ -0,0 +1,115 @@
+package rangingarray_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/rangingarray"
+	"go.viam.com/rdk/components/rangingarray/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/protoutils"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// newFake returns a ring of 4 beams 100mm from the center of a room 2000mm long and 1000mm wide,
+// which detect the walls 900mm to the front and back and 400mm to the sides.
+func newFake(t *testing.T) rangingarray.RangingArray {
+	t.Helper()
+	a, err := fake.NewRangingArray(context.Background(), nil, resource.Config{
+		Name:                "ring",
+		API:                 rangingarray.API,
+		ConvertedAttributes: &fake.Config{RoomLengthMM: 2000, RoomWidthMM: 1000, NumBeams: 4, RingRadiusMM: 100},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return a
+}
+
+func TestDetectionPoint(t *testing.T) {
+	beam := rangingarray.Beam{PositionMM: r3.Vector{X: 100}, Azimuth: math.Pi / 2}
+	p := rangingarray.Detection{DistanceMM: 500}.Point(beam)
+	test.That(t, p.Sub(r3.Vector{X: 100, Y: 500}).Norm(), test.ShouldBeLessThan, 1e-9)
+	// a radar resolves the direction of its returns within the beam.
+	p = rangingarray.Detection{DistanceMM: 1000, Azimuth: -math.Pi / 2, Elevation: math.Pi / 6}.Point(beam)
+	test.That(t, p.Sub(r3.Vector{X: 100 + 1000*math.Sqrt(3)/2, Z: 500}).Norm(), test.ShouldBeLessThan, 1e-9)
+}
+
+func TestNearestInDirection(t *testing.T) {
+	ctx := context.Background()
+	a := newFake(t)
+	props, err := a.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.Beams, test.ShouldHaveLength, 4)
+	test.That(t, props.Kind, test.ShouldEqual, rangingarray.KindUltrasonic)
+	ranges, err := a.Ranges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ranges.Detections, test.ShouldHaveLength, 4)
+
+	nearest, ok := ranges.Nearest()
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, nearest.Beam, test.ShouldEqual, 1)
+	test.That(t, nearest.DistanceMM, test.ShouldAlmostEqual, 400)
+
+	// the front beam alone covers a narrow sector ahead.
+	d, ok := rangingarray.NearestInDirection(props, ranges, 0, math.Pi/12)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d, test.ShouldAlmostEqual, 900)
+	// a wide sector behind overlaps the side beams, whose fields of view are 30 degrees.
+	d, ok = rangingarray.NearestInDirection(props, ranges, math.Pi, math.Pi/2-math.Pi/24)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d, test.ShouldAlmostEqual, 400)
+	// no beam looks between the front and the side.
+	_, ok = rangingarray.NearestInDirection(props, ranges, math.Pi/4, math.Pi/24)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestReadings(t *testing.T) {
+	ctx := context.Background()
+	a := newFake(t)
+	readings, err := a.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	// the nearest detection is reported as proximity sensors report it.
+	test.That(t, readings["distance"], test.ShouldAlmostEqual, 0.4)
+
+	// readings survive conversion to and from their wire form.
+	pbReadings, err := protoutils.ReadingGoToProto(readings)
+	test.That(t, err, test.ShouldBeNil)
+	readings, err = protoutils.ReadingProtoToGo(pbReadings)
+	test.That(t, err, test.ShouldBeNil)
+	ranges, err := rangingarray.RangesFromReadings(readings)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ranges.Detections, test.ShouldHaveLength, 4)
+	test.That(t, ranges.Detections[2].Beam, test.ShouldEqual, 2)
+	test.That(t, ranges.Detections[2].DistanceMM, test.ShouldAlmostEqual, 900)
+	test.That(t, ranges.Time.IsZero(), test.ShouldBeFalse)
+
+	_, err = rangingarray.RangesFromReadings(map[string]interface{}{"distances_mm": []interface{}{1.0}})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestFromResource(t *testing.T) {
+	ctx := context.Background()
+	a := newFake(t)
+
+	// a module provides the array as a generic component that handles the ranging array commands.
+	res := inject.NewGenericComponent("ring")
+	res.DoFunc = a.DoCommand
+	remote := rangingarray.FromResource(res)
+	ranges, err := remote.Ranges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ranges.Detections, test.ShouldHaveLength, 4)
+	test.That(t, ranges.Detections[1].DistanceMM, test.ShouldAlmostEqual, 400)
+
+	props, err := remote.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.Beams, test.ShouldHaveLength, 4)
+	test.That(t, props.Beams[1].Azimuth, test.ShouldAlmostEqual, math.Pi/2)
+	test.That(t, props.Beams[1].PositionMM.Y, test.ShouldAlmostEqual, 100)
+	test.That(t, props.Beams[1].HorizontalFOV, test.ShouldAlmostEqual, math.Pi/6)
+
+	test.That(t, rangingarray.FromResource(a), test.ShouldEqual, a)
+}
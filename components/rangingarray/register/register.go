@@ -0,0 +1,8 @@
+// Package register registers all relevant ranging arrays and also API specific functions
+package register
+
+import (
+	// for ranging arrays.
+	_ "go.viam.com/rdk/components/rangingarray/fake"
+	_ "go.viam.com/rdk/components/rangingarray/sensors"
+)
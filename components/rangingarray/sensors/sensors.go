@@ -0,0 +1,163 @@
+// Package sensors implements a ranging array of proximity sensors, such as a ring of ultrasonic
+// rangefinders, each of which reports the distance to the nearest obstacle in front of it.
+package sensors
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/rangingarray"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// Model is the model of ranging arrays of proximity sensors.
+var Model = resource.DefaultModelFamily.WithModel("sensors")
+
+const defaultDistanceKey = "distance"
+
+func init() {
+	resource.RegisterComponent(rangingarray.API, Model, resource.Registration[rangingarray.RangingArray, *Config]{
+		Constructor: NewRangingArray,
+	})
+}
+
+// BeamConfig places a proximity sensor in the array. Positions are in millimeters and angles in
+// degrees, in the frame of the array.
+type BeamConfig struct {
+	Sensor               string    `json:"sensor"`
+	PositionMM           r3.Vector `json:"position_mm"`
+	AzimuthDegrees       float64   `json:"azimuth_degrees"`
+	ElevationDegrees     float64   `json:"elevation_degrees,omitempty"`
+	HorizontalFOVDegrees float64   `json:"horizontal_fov_degrees"`
+	VerticalFOVDegrees   float64   `json:"vertical_fov_degrees,omitempty"`
+	MinRangeMM           float64   `json:"min_range_mm,omitempty"`
+	MaxRangeMM           float64   `json:"max_range_mm"`
+}
+
+// Config is the config of a ranging array of proximity sensors.
+type Config struct {
+	Beams []BeamConfig `json:"beams"`
+	// Kind is how the sensors measure, ultrasonic by default.
+	Kind rangingarray.Kind `json:"kind,omitempty"`
+	// DistanceKey is the reading of each sensor holding its distance in meters, "distance" by default.
+	DistanceKey string `json:"distance_key,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if len(conf.Beams) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "beams")
+	}
+	deps := make([]string, 0, len(conf.Beams))
+	for i, b := range conf.Beams {
+		if b.Sensor == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "beams.sensor")
+		}
+		if b.HorizontalFOVDegrees <= 0 || b.HorizontalFOVDegrees > 180 || b.VerticalFOVDegrees < 0 || b.VerticalFOVDegrees > 180 {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("beam %d fields of view must be between 0 and 180 degrees", i))
+		}
+		if b.MaxRangeMM <= 0 || b.MinRangeMM < 0 || b.MinRangeMM >= b.MaxRangeMM {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("beam %d max_range_mm must be positive and greater than min_range_mm", i))
+		}
+		deps = append(deps, b.Sensor)
+	}
+	return deps, nil, nil
+}
+
+type sensorsArray struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	sensors     []sensor.Sensor
+	props       rangingarray.Properties
+	distanceKey string
+}
+
+// NewRangingArray returns a ranging array of the proximity sensors configured.
+func NewRangingArray(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (rangingarray.RangingArray, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	a := &sensorsArray{
+		Named:       conf.ResourceName().AsNamed(),
+		sensors:     make([]sensor.Sensor, len(newConf.Beams)),
+		props:       rangingarray.Properties{Kind: newConf.Kind, Beams: make([]rangingarray.Beam, len(newConf.Beams))},
+		distanceKey: newConf.DistanceKey,
+	}
+	if a.props.Kind == "" {
+		a.props.Kind = rangingarray.KindUltrasonic
+	}
+	if a.distanceKey == "" {
+		a.distanceKey = defaultDistanceKey
+	}
+	for i, b := range newConf.Beams {
+		if a.sensors[i], err = sensor.FromProvider(deps, b.Sensor); err != nil {
+			return nil, err
+		}
+		a.props.Beams[i] = rangingarray.Beam{
+			Name:          b.Sensor,
+			PositionMM:    b.PositionMM,
+			Azimuth:       b.AzimuthDegrees * math.Pi / 180,
+			Elevation:     b.ElevationDegrees * math.Pi / 180,
+			HorizontalFOV: b.HorizontalFOVDegrees * math.Pi / 180,
+			VerticalFOV:   b.VerticalFOVDegrees * math.Pi / 180,
+			MinRangeMM:    b.MinRangeMM,
+			MaxRangeMM:    b.MaxRangeMM,
+		}
+	}
+	return a, nil
+}
+
+// Ranges reads the sensors one at a time, since ultrasonic sensors pinging at once hear each other's
+// echoes. A sensor measuring outside the range of its beam has no detection.
+func (a *sensorsArray) Ranges(ctx context.Context, extra map[string]interface{}) (*rangingarray.Ranges, error) {
+	r := &rangingarray.Ranges{}
+	for i, s := range a.sensors {
+		readings, err := s.Readings(ctx, extra)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read sensor %s", a.props.Beams[i].Name)
+		}
+		meters, ok := readings[a.distanceKey].(float64)
+		if !ok {
+			return nil, errors.Errorf("expected sensor %s reading %q to be a float64 but got %T",
+				a.props.Beams[i].Name, a.distanceKey, readings[a.distanceKey])
+		}
+		beam := a.props.Beams[i]
+		if mm := meters * 1000; mm >= beam.MinRangeMM && mm <= beam.MaxRangeMM {
+			r.Detections = append(r.Detections, rangingarray.Detection{Beam: i, DistanceMM: mm})
+		}
+	}
+	r.Time = time.Now()
+	return r, nil
+}
+
+func (a *sensorsArray) Properties(ctx context.Context) (rangingarray.Properties, error) {
+	return a.props, nil
+}
+
+func (a *sensorsArray) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	r, err := a.Ranges(ctx, extra)
+	if err != nil {
+		return nil, err
+	}
+	return rangingarray.RangesToReadings(r)
+}
+
+func (a *sensorsArray) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, ok, err := rangingarray.HandleRangingArrayCommand(ctx, a, cmd); ok {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
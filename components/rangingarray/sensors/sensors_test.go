@@ -0,0 +1,82 @@
+package sensors
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/rangingarray"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{Beams: []BeamConfig{
+		{Sensor: "front", HorizontalFOVDegrees: 30, MaxRangeMM: 4000},
+		{Sensor: "left", AzimuthDegrees: 90, HorizontalFOVDegrees: 30, MaxRangeMM: 4000},
+	}}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"front", "left"})
+
+	for _, b := range []BeamConfig{
+		{HorizontalFOVDegrees: 30, MaxRangeMM: 4000},
+		{Sensor: "s", MaxRangeMM: 4000},
+		{Sensor: "s", HorizontalFOVDegrees: 30},
+		{Sensor: "s", HorizontalFOVDegrees: 30, MinRangeMM: 500, MaxRangeMM: 400},
+	} {
+		_, _, err := (&Config{Beams: []BeamConfig{b}}).Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+	_, _, err = (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestRanges(t *testing.T) {
+	ctx := context.Background()
+	distances := map[string]interface{}{"front": 0.5, "left": 6.0}
+	var readErr error
+	deps := resource.Dependencies{}
+	for _, name := range []string{"front", "left"} {
+		s := inject.NewSensor(name)
+		s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"range": distances[name]}, readErr
+		}
+		deps[sensor.Named(name)] = s
+	}
+	a, err := NewRangingArray(ctx, deps, resource.Config{
+		Name: "ring",
+		API:  rangingarray.API,
+		ConvertedAttributes: &Config{
+			Beams: []BeamConfig{
+				{Sensor: "front", HorizontalFOVDegrees: 30, MaxRangeMM: 4000},
+				{Sensor: "left", AzimuthDegrees: 90, HorizontalFOVDegrees: 30, MaxRangeMM: 4000},
+			},
+			DistanceKey: "range",
+		},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	props, err := a.Properties(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.Kind, test.ShouldEqual, rangingarray.KindUltrasonic)
+	test.That(t, props.Beams[1].Name, test.ShouldEqual, "left")
+	test.That(t, props.Beams[1].Azimuth, test.ShouldAlmostEqual, math.Pi/2)
+
+	// the left sensor measures beyond the range of its beam, so has no detection.
+	ranges, err := a.Ranges(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ranges.Detections, test.ShouldResemble, []rangingarray.Detection{{Beam: 0, DistanceMM: 500}})
+
+	distances["left"] = "far"
+	_, err = a.Ranges(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	readErr = errors.New("disconnected")
+	_, err = a.Readings(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -10,16 +10,20 @@ import (
 	_ "go.viam.com/rdk/components/button/register"
 	_ "go.viam.com/rdk/components/camera/register"
 	_ "go.viam.com/rdk/components/encoder/register"
+	_ "go.viam.com/rdk/components/forcetorque/register"
 	_ "go.viam.com/rdk/components/gantry/register"
 	_ "go.viam.com/rdk/components/generic/register"
 	_ "go.viam.com/rdk/components/gripper/register"
 	_ "go.viam.com/rdk/components/input/register"
+	_ "go.viam.com/rdk/components/lidar/register"
 	_ "go.viam.com/rdk/components/motor/register"
 	_ "go.viam.com/rdk/components/movementsensor/register"
 	// register APIs without implementations directly.
 	_ "go.viam.com/rdk/components/posetracker"
 	_ "go.viam.com/rdk/components/powersensor/register"
+	_ "go.viam.com/rdk/components/rangingarray/register"
 	_ "go.viam.com/rdk/components/sensor/register"
 	_ "go.viam.com/rdk/components/servo/register"
+	_ "go.viam.com/rdk/components/statuslight/register"
 	_ "go.viam.com/rdk/components/switch/register"
 )
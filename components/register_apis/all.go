@@ -11,15 +11,19 @@ import (
 	_ "go.viam.com/rdk/components/board"
 	_ "go.viam.com/rdk/components/button"
 	_ "go.viam.com/rdk/components/encoder"
+	_ "go.viam.com/rdk/components/forcetorque"
 	_ "go.viam.com/rdk/components/gantry"
 	_ "go.viam.com/rdk/components/generic"
 	_ "go.viam.com/rdk/components/gripper"
 	_ "go.viam.com/rdk/components/input"
+	_ "go.viam.com/rdk/components/lidar"
 	_ "go.viam.com/rdk/components/motor"
 	_ "go.viam.com/rdk/components/movementsensor"
 	_ "go.viam.com/rdk/components/posetracker"
 	_ "go.viam.com/rdk/components/powersensor"
+	_ "go.viam.com/rdk/components/rangingarray"
 	_ "go.viam.com/rdk/components/sensor"
 	_ "go.viam.com/rdk/components/servo"
+	_ "go.viam.com/rdk/components/statuslight"
 	_ "go.viam.com/rdk/components/switch"
 )
@@ -45,7 +45,9 @@ func Named(name string) resource.Name {
 }
 
 // A Sensor represents a general purpose sensors that can give arbitrary readings
-// of some thing that it is sensing.
+// of some thing that it is sensing. Its readings can be subscribed to with
+// resource.StreamReadings, which sensors that push readings support by implementing
+// resource.ReadingsStreamer.
 type Sensor interface {
 	resource.Resource
 	resource.Sensor
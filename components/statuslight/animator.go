@@ -0,0 +1,151 @@
+package statuslight
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+)
+
+// DefaultFrameInterval is the time between the frames of animated patterns, 50 frames a second.
+const DefaultFrameInterval = 20 * time.Millisecond
+
+// An Animator sets the colors of LEDs through a write function, animating patterns in the
+// background, so that status lights only need to implement writing colors to their LEDs.
+type Animator struct {
+	numLEDs       int
+	frameInterval time.Duration
+	write         func(ctx context.Context, colors []Color) error
+	logger        logging.Logger
+
+	// setMu serializes changes of what is shown, so that only one animation runs at a time.
+	setMu     sync.Mutex
+	mu        sync.Mutex
+	colors    []Color
+	pattern   *Pattern
+	animation *utils.StoppableWorkers
+}
+
+// NewAnimator returns an Animator of numLEDs LEDs whose colors are set with write, which is given
+// the color of every LED. Animated patterns are written every frameInterval.
+func NewAnimator(
+	numLEDs int, frameInterval time.Duration, write func(ctx context.Context, colors []Color) error, logger logging.Logger,
+) *Animator {
+	if frameInterval <= 0 {
+		frameInterval = DefaultFrameInterval
+	}
+	return &Animator{
+		numLEDs:       numLEDs,
+		frameInterval: frameInterval,
+		write:         write,
+		logger:        logger,
+		colors:        make([]Color, numLEDs),
+	}
+}
+
+// SetColors stops any pattern and writes colors, one for each LED or a single color for every LED.
+func (a *Animator) SetColors(ctx context.Context, colors []Color) error {
+	switch len(colors) {
+	case 1:
+		colors = slices.Repeat(colors, a.numLEDs)
+	case a.numLEDs:
+		colors = slices.Clone(colors)
+	default:
+		return errors.Errorf("expected 1 or %d colors but got %d", a.numLEDs, len(colors))
+	}
+
+	a.setMu.Lock()
+	defer a.setMu.Unlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopAnimationLocked()
+	a.pattern = nil
+	return a.writeLocked(ctx, colors)
+}
+
+// SetPattern writes pattern, animating it in the background until colors or another pattern are set.
+func (a *Animator) SetPattern(ctx context.Context, pattern Pattern) error {
+	if err := pattern.Validate(); err != nil {
+		return err
+	}
+
+	a.setMu.Lock()
+	defer a.setMu.Unlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopAnimationLocked()
+	a.pattern = &pattern
+	if err := a.writeLocked(ctx, pattern.Render(0, a.numLEDs)); err != nil {
+		return err
+	}
+	if !pattern.Animated() {
+		return nil
+	}
+
+	start := time.Now()
+	a.animation = utils.NewBackgroundStoppableWorkers(func(ctx context.Context) {
+		var lastErr error
+		for utils.SelectContextOrWait(ctx, a.frameInterval) {
+			a.mu.Lock()
+			err := a.writeLocked(ctx, pattern.Render(time.Since(start), a.numLEDs))
+			a.mu.Unlock()
+			if err != nil && ctx.Err() == nil && (lastErr == nil || err.Error() != lastErr.Error()) {
+				a.logger.CWarnw(ctx, "failed to animate status light", "error", err)
+			}
+			lastErr = err
+		}
+	})
+	return nil
+}
+
+// Colors returns the colors last written to the LEDs.
+func (a *Animator) Colors() []Color {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.colors)
+}
+
+// Pattern returns the pattern being shown, if one is.
+func (a *Animator) Pattern() (Pattern, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pattern == nil {
+		return Pattern{}, false
+	}
+	return *a.pattern, true
+}
+
+// Close stops any pattern.
+func (a *Animator) Close() {
+	a.setMu.Lock()
+	defer a.setMu.Unlock()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopAnimationLocked()
+}
+
+func (a *Animator) writeLocked(ctx context.Context, colors []Color) error {
+	if err := a.write(ctx, colors); err != nil {
+		return err
+	}
+	a.colors = colors
+	return nil
+}
+
+// stopAnimationLocked stops the animation, which takes the lock to write frames, so the lock is
+// released while waiting for it.
+func (a *Animator) stopAnimationLocked() {
+	if a.animation == nil {
+		return
+	}
+	animation := a.animation
+	a.animation = nil
+	a.mu.Unlock()
+	animation.Stop()
+	a.mu.Lock()
+}
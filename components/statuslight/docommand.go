@@ -0,0 +1,145 @@
+package statuslight
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoNumLEDs    = "num_leds"
+	DoSetColors  = "set_colors"
+	DoSetPattern = "set_pattern"
+)
+
+// patternMessage is the form a pattern takes in DoCommand commands.
+type patternMessage struct {
+	Kind     PatternKind `json:"kind"`
+	Color    string      `json:"color"`
+	PeriodMS float64     `json:"period_ms,omitempty"`
+}
+
+type setColorsCommand struct {
+	Colors []string               `json:"set_colors"`
+	Extra  map[string]interface{} `json:"extra,omitempty"`
+}
+
+type setPatternCommand struct {
+	Pattern patternMessage         `json:"set_pattern"`
+	Extra   map[string]interface{} `json:"extra,omitempty"`
+}
+
+type numLEDsResponse struct {
+	NumLEDs int `json:"num_leds"`
+}
+
+// HandleStatusLightCommand services the status light DoCommand keys using the given StatusLight, so
+// that status lights can be driven through DoCommand by callers that only have a generic resource
+// handle, such as the client of a status light provided by a module. It returns false if cmd does
+// not contain any of them so that it can be chained from a DoCommand implementation.
+func HandleStatusLightCommand(
+	ctx context.Context,
+	light StatusLight,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	_, setColors := cmd[DoSetColors]
+	_, setPattern := cmd[DoSetPattern]
+	_, numLEDs := cmd[DoNumLEDs]
+	switch {
+	case setColors && setPattern:
+		return nil, true, errors.Errorf("cannot %s and %s in the same command", DoSetColors, DoSetPattern)
+	case setColors:
+		req, err := resource.DecodeDoCommand[setColorsCommand](cmd)
+		if err != nil {
+			return nil, true, errors.Wrapf(err, "invalid %s command", DoSetColors)
+		}
+		colors := make([]Color, 0, len(req.Colors))
+		for _, raw := range req.Colors {
+			c, err := ParseColor(raw)
+			if err != nil {
+				return nil, true, err
+			}
+			colors = append(colors, c)
+		}
+		if err := light.SetColors(ctx, colors, req.Extra); err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, nil
+	case setPattern:
+		req, err := resource.DecodeDoCommand[setPatternCommand](cmd)
+		if err != nil {
+			return nil, true, errors.Wrapf(err, "invalid %s command", DoSetPattern)
+		}
+		c, err := ParseColor(req.Pattern.Color)
+		if err != nil {
+			return nil, true, err
+		}
+		pattern := Pattern{
+			Kind:   req.Pattern.Kind,
+			Color:  c,
+			Period: time.Duration(req.Pattern.PeriodMS * float64(time.Millisecond)),
+		}
+		if err := light.SetPattern(ctx, pattern, req.Extra); err != nil {
+			return nil, true, err
+		}
+		return map[string]interface{}{}, true, nil
+	case numLEDs:
+		n, err := light.NumLEDs(ctx, nil)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(numLEDsResponse{NumLEDs: n})
+		return resp, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// FromResource returns a StatusLight that is driven through the DoCommand of res, which must handle
+// the status light DoCommand keys as HandleStatusLightCommand does. It lets status lights be
+// provided by modules as generic components.
+func FromResource(res resource.Resource) StatusLight {
+	if light, ok := res.(StatusLight); ok {
+		return light
+	}
+	return &doCommandLight{Resource: res}
+}
+
+type doCommandLight struct {
+	resource.Resource
+}
+
+func (l *doCommandLight) NumLEDs(ctx context.Context, extra map[string]interface{}) (int, error) {
+	resp, err := resource.DoCommandAs[map[string]interface{}, numLEDsResponse](
+		ctx, l, map[string]interface{}{DoNumLEDs: true})
+	if err != nil {
+		return 0, err
+	}
+	return resp.NumLEDs, nil
+}
+
+func (l *doCommandLight) SetColors(ctx context.Context, colors []Color, extra map[string]interface{}) error {
+	req := setColorsCommand{Colors: make([]string, 0, len(colors)), Extra: extra}
+	for _, c := range colors {
+		req.Colors = append(req.Colors, c.String())
+	}
+	_, err := resource.DoCommandAs[setColorsCommand, map[string]interface{}](ctx, l, req)
+	return err
+}
+
+func (l *doCommandLight) SetPattern(ctx context.Context, pattern Pattern, extra map[string]interface{}) error {
+	req := setPatternCommand{
+		Pattern: patternMessage{
+			Kind:     pattern.Kind,
+			Color:    pattern.Color.String(),
+			PeriodMS: float64(pattern.Period) / float64(time.Millisecond),
+		},
+		Extra: extra,
+	}
+	_, err := resource.DoCommandAs[setPatternCommand, map[string]interface{}](ctx, l, req)
+	return err
+}
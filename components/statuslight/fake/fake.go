@@ -0,0 +1,105 @@
+// Package fake implements a fake status light.
+package fake
+
+import (
+	"context"
+	"fmt"
+
+	"go.viam.com/rdk/components/statuslight"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+var model = resource.DefaultModelFamily.WithModel("fake")
+
+func init() {
+	resource.RegisterComponent(statuslight.API, model, resource.Registration[statuslight.StatusLight, *Config]{
+		Constructor: NewStatusLight,
+	})
+}
+
+// Config is the config for a fake status light.
+type Config struct {
+	// NumLEDs is the number of LEDs of the light. NumLEDs defaults to 1 if unspecified.
+	NumLEDs int `json:"num_leds,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.NumLEDs < 0 {
+		return nil, nil, fmt.Errorf("num_leds must be greater than 0 if provided, got %d", conf.NumLEDs)
+	}
+	return nil, nil, nil
+}
+
+// StatusLight is a fake status light that keeps the colors of its LEDs, which its resource Status
+// reports.
+type StatusLight struct {
+	resource.Named
+	resource.AlwaysRebuild
+	numLEDs  int
+	animator *statuslight.Animator
+}
+
+// NewStatusLight instantiates a new status light of the fake model type.
+func NewStatusLight(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (statuslight.StatusLight, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	numLEDs := newConf.NumLEDs
+	if numLEDs == 0 {
+		numLEDs = 1
+	}
+	write := func(ctx context.Context, colors []statuslight.Color) error { return nil }
+	return &StatusLight{
+		Named:    conf.ResourceName().AsNamed(),
+		numLEDs:  numLEDs,
+		animator: statuslight.NewAnimator(numLEDs, statuslight.DefaultFrameInterval, write, logger),
+	}, nil
+}
+
+// NumLEDs returns the number of LEDs of the light.
+func (l *StatusLight) NumLEDs(ctx context.Context, extra map[string]interface{}) (int, error) {
+	return l.numLEDs, nil
+}
+
+// SetColors sets the colors of the LEDs.
+func (l *StatusLight) SetColors(ctx context.Context, colors []statuslight.Color, extra map[string]interface{}) error {
+	return l.animator.SetColors(ctx, colors)
+}
+
+// SetPattern animates the LEDs with pattern.
+func (l *StatusLight) SetPattern(ctx context.Context, pattern statuslight.Pattern, extra map[string]interface{}) error {
+	return l.animator.SetPattern(ctx, pattern)
+}
+
+// DoCommand handles the status light DoCommand keys.
+func (l *StatusLight) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, ok, err := statuslight.HandleStatusLightCommand(ctx, l, cmd); ok {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+// Status reports the colors of the LEDs and the pattern being shown, if any.
+func (l *StatusLight) Status(ctx context.Context) (map[string]interface{}, error) {
+	colors := l.animator.Colors()
+	hexColors := make([]interface{}, 0, len(colors))
+	for _, c := range colors {
+		hexColors = append(hexColors, c.String())
+	}
+	status := map[string]interface{}{"colors": hexColors}
+	if pattern, ok := l.animator.Pattern(); ok {
+		status["pattern"] = string(pattern.Kind)
+	}
+	return status, nil
+}
+
+// Close stops any pattern.
+func (l *StatusLight) Close(ctx context.Context) error {
+	l.animator.Close()
+	return nil
+}
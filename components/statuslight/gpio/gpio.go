@@ -0,0 +1,138 @@
+// Package gpio implements a status light of an RGB LED whose red, green, and blue legs are driven
+// with PWM by GPIO pins of a board.
+package gpio
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/statuslight"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+const defaultPWMFreqHz = 1000
+
+var model = resource.DefaultModelFamily.WithModel("gpio")
+
+func init() {
+	resource.RegisterComponent(statuslight.API, model, resource.Registration[statuslight.StatusLight, *Config]{
+		Constructor: NewStatusLight,
+	})
+}
+
+// Config describes the pins the legs of the LED are wired to.
+type Config struct {
+	Board    string `json:"board"`
+	RedPin   string `json:"red_pin"`
+	GreenPin string `json:"green_pin"`
+	BluePin  string `json:"blue_pin"`
+	// CommonAnode is whether the LED shares its anode, so that its legs light when driven low.
+	CommonAnode bool `json:"common_anode,omitempty"`
+	// PWMFreqHz defaults to 1000 if unspecified.
+	PWMFreqHz uint `json:"pwm_freq_hz,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.Board == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	for field, pin := range map[string]string{"red_pin": conf.RedPin, "green_pin": conf.GreenPin, "blue_pin": conf.BluePin} {
+		if pin == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(path, field)
+		}
+	}
+	return []string{conf.Board}, nil, nil
+}
+
+// StatusLight is an RGB LED driven by GPIO pins.
+type StatusLight struct {
+	resource.Named
+	resource.AlwaysRebuild
+	pins        [3]board.GPIOPin
+	commonAnode bool
+	animator    *statuslight.Animator
+}
+
+// NewStatusLight instantiates a new status light of the gpio model type, which starts off.
+func NewStatusLight(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (statuslight.StatusLight, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b, err := board.FromProvider(deps, newConf.Board)
+	if err != nil {
+		return nil, err
+	}
+	freq := newConf.PWMFreqHz
+	if freq == 0 {
+		freq = defaultPWMFreqHz
+	}
+
+	l := &StatusLight{
+		Named:       conf.ResourceName().AsNamed(),
+		commonAnode: newConf.CommonAnode,
+	}
+	for i, name := range []string{newConf.RedPin, newConf.GreenPin, newConf.BluePin} {
+		pin, err := b.GPIOPinByName(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := pin.SetPWMFreq(ctx, freq, nil); err != nil {
+			return nil, errors.Wrapf(err, "setting PWM frequency of pin %q", name)
+		}
+		l.pins[i] = pin
+	}
+	l.animator = statuslight.NewAnimator(1, statuslight.DefaultFrameInterval, l.write, logger)
+	if err := l.animator.SetColors(ctx, []statuslight.Color{statuslight.Off}); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *StatusLight) write(ctx context.Context, colors []statuslight.Color) error {
+	c := colors[0]
+	var err error
+	for i, level := range []uint8{c.R, c.G, c.B} {
+		dutyCycle := float64(level) / 255
+		if l.commonAnode {
+			dutyCycle = 1 - dutyCycle
+		}
+		err = multierr.Combine(err, l.pins[i].SetPWM(ctx, dutyCycle, nil))
+	}
+	return err
+}
+
+// NumLEDs returns 1, since the LED shows one color.
+func (l *StatusLight) NumLEDs(ctx context.Context, extra map[string]interface{}) (int, error) {
+	return 1, nil
+}
+
+// SetColors sets the color of the LED.
+func (l *StatusLight) SetColors(ctx context.Context, colors []statuslight.Color, extra map[string]interface{}) error {
+	return l.animator.SetColors(ctx, colors)
+}
+
+// SetPattern animates the LED with pattern.
+func (l *StatusLight) SetPattern(ctx context.Context, pattern statuslight.Pattern, extra map[string]interface{}) error {
+	return l.animator.SetPattern(ctx, pattern)
+}
+
+// DoCommand handles the status light DoCommand keys.
+func (l *StatusLight) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, ok, err := statuslight.HandleStatusLightCommand(ctx, l, cmd); ok {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+// Close stops any pattern and turns the LED off.
+func (l *StatusLight) Close(ctx context.Context) error {
+	return l.animator.SetColors(ctx, []statuslight.Color{statuslight.Off})
+}
@@ -0,0 +1,70 @@
+package gpio
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/statuslight"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestStatusLight(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	dutyCycles := map[string]float64{}
+	freqs := map[string]uint{}
+	b := inject.NewBoard("board")
+	b.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) {
+		pin := &inject.GPIOPin{}
+		pin.SetPWMFunc = func(ctx context.Context, dutyCyclePct float64, extra map[string]interface{}) error {
+			mu.Lock()
+			defer mu.Unlock()
+			dutyCycles[name] = dutyCyclePct
+			return nil
+		}
+		pin.SetPWMFreqFunc = func(ctx context.Context, freqHz uint, extra map[string]interface{}) error {
+			freqs[name] = freqHz
+			return nil
+		}
+		return pin, nil
+	}
+	getDutyCycles := func() map[string]float64 {
+		mu.Lock()
+		defer mu.Unlock()
+		return map[string]float64{"r": dutyCycles["r"], "g": dutyCycles["g"], "b": dutyCycles["b"]}
+	}
+
+	conf := &Config{Board: "board", RedPin: "r", GreenPin: "g", BluePin: "b", CommonAnode: true}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"board"})
+	_, _, err = (&Config{Board: "board", RedPin: "r", GreenPin: "g"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	light, err := NewStatusLight(ctx, resource.Dependencies{board.Named("board"): b}, resource.Config{
+		Name:                "light",
+		API:                 statuslight.API,
+		Model:               model,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, freqs, test.ShouldResemble, map[string]uint{"r": 1000, "g": 1000, "b": 1000})
+	// common anode LEDs are off while their legs are high.
+	test.That(t, getDutyCycles(), test.ShouldResemble, map[string]float64{"r": 1, "g": 1, "b": 1})
+
+	test.That(t, light.SetColors(ctx, []statuslight.Color{{R: 255, G: 51}}, nil), test.ShouldBeNil)
+	test.That(t, getDutyCycles(), test.ShouldResemble, map[string]float64{"r": 0, "g": 0.8, "b": 1})
+
+	test.That(t, light.SetPattern(ctx, statuslight.Pattern{Kind: statuslight.PatternBlink, Color: statuslight.Color{B: 255}}, nil),
+		test.ShouldBeNil)
+	test.That(t, getDutyCycles(), test.ShouldResemble, map[string]float64{"r": 1, "g": 1, "b": 0})
+
+	test.That(t, light.Close(ctx), test.ShouldBeNil)
+	test.That(t, getDutyCycles(), test.ShouldResemble, map[string]float64{"r": 1, "g": 1, "b": 1})
+}
@@ -0,0 +1,8 @@
+// Package register registers all relevant status lights and also API specific functions
+package register
+
+import (
+	// for status lights.
+	_ "go.viam.com/rdk/components/statuslight/fake"
+	_ "go.viam.com/rdk/components/statuslight/gpio"
+)
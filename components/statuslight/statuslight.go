@@ -0,0 +1,170 @@
+// Package statuslight defines status lights, from single RGB LEDs to addressable light strips, which
+// show solid colors or animated patterns.
+package statuslight
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[StatusLight]{})
+}
+
+// SubtypeName is a constant that identifies the component resource API string.
+const SubtypeName = "status_light"
+
+// API is a variable that identifies the component resource API.
+var API = resource.APINamespaceRDK.WithComponentType(SubtypeName)
+
+// Named is a helper for getting the named status light's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// A StatusLight is one or more LEDs whose colors can be set.
+type StatusLight interface {
+	resource.Resource
+
+	// NumLEDs returns the number of LEDs whose colors can be set individually, which is 1 for a
+	// single LED or a strip that is not addressable.
+	NumLEDs(ctx context.Context, extra map[string]interface{}) (int, error)
+
+	// SetColors sets the color of each LED, stopping any pattern. A single color sets every LED.
+	SetColors(ctx context.Context, colors []Color, extra map[string]interface{}) error
+
+	// SetPattern animates the LEDs with pattern until colors or another pattern are set.
+	SetPattern(ctx context.Context, pattern Pattern, extra map[string]interface{}) error
+}
+
+// Deprecated: FromRobot is a helper for getting the named StatusLight from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (StatusLight, error) {
+	return robot.ResourceFromRobot[StatusLight](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named StatusLight from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (StatusLight, error) {
+	return resource.FromProvider[StatusLight](provider, Named(name))
+}
+
+// NamesFromRobot is a helper for getting all status light names from the given Robot.
+func NamesFromRobot(r robot.Robot) []string {
+	return robot.NamesByAPI(r, API)
+}
+
+// A Color is the color of an LED.
+type Color struct {
+	R, G, B uint8
+}
+
+// Off is the color of an LED that is off.
+var Off = Color{}
+
+// ParseColor parses a color in hex form, such as "#ff8800" or "ff8800".
+func ParseColor(s string) (Color, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(s, "#"))
+	if err != nil || len(raw) != 3 {
+		return Color{}, errors.Errorf("expected color %q to be in the form #rrggbb", s)
+	}
+	return Color{R: raw[0], G: raw[1], B: raw[2]}, nil
+}
+
+// String returns the color in the form #rrggbb.
+func (c Color) String() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Scale returns the color with its brightness scaled by factor, from 0 to 1.
+func (c Color) Scale(factor float64) Color {
+	factor = math.Max(0, math.Min(1, factor))
+	scale := func(v uint8) uint8 { return uint8(math.Round(float64(v) * factor)) }
+	return Color{R: scale(c.R), G: scale(c.G), B: scale(c.B)}
+}
+
+// A PatternKind is a way of animating LEDs.
+type PatternKind string
+
+// The set of known patterns.
+const (
+	// PatternSolid shows the color steadily.
+	PatternSolid = PatternKind("solid")
+	// PatternBlink turns the color on and off, each for half of the period.
+	PatternBlink = PatternKind("blink")
+	// PatternBreathe fades the color in and out over the period.
+	PatternBreathe = PatternKind("breathe")
+	// PatternChase moves a lit LED along the strip, once over the period.
+	PatternChase = PatternKind("chase")
+)
+
+const defaultPatternPeriod = time.Second
+
+// A Pattern is an animation of LEDs in a color.
+type Pattern struct {
+	Kind  PatternKind
+	Color Color
+	// Period is the time the animation takes to repeat. Period defaults to 1s if unspecified.
+	Period time.Duration
+}
+
+// Validate ensures the pattern is known and its period is not negative.
+func (p Pattern) Validate() error {
+	switch p.Kind {
+	case PatternSolid, PatternBlink, PatternBreathe, PatternChase:
+	default:
+		return errors.Errorf("unknown pattern %q", p.Kind)
+	}
+	if p.Period < 0 {
+		return errors.New("pattern period cannot be negative")
+	}
+	return nil
+}
+
+// Animated returns whether the colors of the pattern change over time.
+func (p Pattern) Animated() bool {
+	return p.Kind != PatternSolid
+}
+
+// Render returns the colors of numLEDs LEDs at elapsed time into the pattern.
+func (p Pattern) Render(elapsed time.Duration, numLEDs int) []Color {
+	period := p.Period
+	if period <= 0 {
+		period = defaultPatternPeriod
+	}
+	// phase is how far through the period the animation is, from 0 to 1.
+	phase := float64(elapsed%period) / float64(period)
+
+	colors := make([]Color, numLEDs)
+	switch p.Kind {
+	case PatternBlink:
+		if phase < 0.5 {
+			fillColors(colors, p.Color)
+		}
+	case PatternBreathe:
+		fillColors(colors, p.Color.Scale((1-math.Cos(2*math.Pi*phase))/2))
+	case PatternChase:
+		if numLEDs > 0 {
+			colors[int(phase*float64(numLEDs))%numLEDs] = p.Color
+		}
+	default:
+		fillColors(colors, p.Color)
+	}
+	return colors
+}
+
+func fillColors(colors []Color, c Color) {
+	for i := range colors {
+		colors[i] = c
+	}
+}
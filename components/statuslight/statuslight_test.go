@@ -0,0 +1,130 @@
+package statuslight_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/statuslight"
+	"go.viam.com/rdk/components/statuslight/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+var (
+	red   = statuslight.Color{R: 255}
+	green = statuslight.Color{G: 255}
+)
+
+func TestColor(t *testing.T) {
+	c, err := statuslight.ParseColor("#ff8800")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, c, test.ShouldResemble, statuslight.Color{R: 255, G: 136})
+	test.That(t, c.String(), test.ShouldEqual, "#ff8800")
+	c, err = statuslight.ParseColor("00ff00")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, c, test.ShouldResemble, green)
+	test.That(t, c.Scale(0.5), test.ShouldResemble, statuslight.Color{G: 128})
+
+	for _, s := range []string{"", "#fff", "#gg0000", "#ff000000"} {
+		_, err := statuslight.ParseColor(s)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestRender(t *testing.T) {
+	off := statuslight.Off
+	blink := statuslight.Pattern{Kind: statuslight.PatternBlink, Color: red, Period: time.Second}
+	test.That(t, blink.Render(100*time.Millisecond, 2), test.ShouldResemble, []statuslight.Color{red, red})
+	test.That(t, blink.Render(600*time.Millisecond, 2), test.ShouldResemble, []statuslight.Color{off, off})
+	test.That(t, blink.Render(1100*time.Millisecond, 2), test.ShouldResemble, []statuslight.Color{red, red})
+
+	breathe := statuslight.Pattern{Kind: statuslight.PatternBreathe, Color: red}
+	test.That(t, breathe.Render(0, 1), test.ShouldResemble, []statuslight.Color{off})
+	test.That(t, breathe.Render(500*time.Millisecond, 1), test.ShouldResemble, []statuslight.Color{red})
+
+	chase := statuslight.Pattern{Kind: statuslight.PatternChase, Color: green, Period: 3 * time.Second}
+	test.That(t, chase.Render(1500*time.Millisecond, 3), test.ShouldResemble, []statuslight.Color{off, green, off})
+
+	test.That(t, statuslight.Pattern{Kind: "sparkle"}.Validate(), test.ShouldNotBeNil)
+	test.That(t, statuslight.Pattern{Kind: statuslight.PatternSolid, Period: -1}.Validate(), test.ShouldNotBeNil)
+}
+
+func TestAnimator(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	var frames [][]statuslight.Color
+	write := func(ctx context.Context, colors []statuslight.Color) error {
+		mu.Lock()
+		defer mu.Unlock()
+		frames = append(frames, colors)
+		return nil
+	}
+	numFrames := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(frames)
+	}
+	animator := statuslight.NewAnimator(3, time.Millisecond, write, logging.NewTestLogger(t))
+	defer animator.Close()
+
+	test.That(t, animator.SetColors(ctx, []statuslight.Color{red}), test.ShouldBeNil)
+	test.That(t, animator.Colors(), test.ShouldResemble, []statuslight.Color{red, red, red})
+	test.That(t, animator.SetColors(ctx, []statuslight.Color{red, green}), test.ShouldNotBeNil)
+
+	test.That(t, animator.SetPattern(ctx, statuslight.Pattern{Kind: statuslight.PatternChase, Color: green}), test.ShouldBeNil)
+	pattern, ok := animator.Pattern()
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, pattern.Kind, test.ShouldEqual, statuslight.PatternChase)
+	for numFrames() < 10 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// setting colors stops the animation.
+	test.That(t, animator.SetColors(ctx, []statuslight.Color{red, green, red}), test.ShouldBeNil)
+	_, ok = animator.Pattern()
+	test.That(t, ok, test.ShouldBeFalse)
+	written := numFrames()
+	time.Sleep(20 * time.Millisecond)
+	test.That(t, numFrames(), test.ShouldEqual, written)
+	test.That(t, animator.Colors(), test.ShouldResemble, []statuslight.Color{red, green, red})
+}
+
+func TestFromResource(t *testing.T) {
+	ctx := context.Background()
+	light, err := fake.NewStatusLight(ctx, nil, resource.Config{
+		Name:                "light",
+		API:                 statuslight.API,
+		ConvertedAttributes: &fake.Config{NumLEDs: 2},
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer light.Close(ctx)
+	test.That(t, statuslight.FromResource(light), test.ShouldEqual, light)
+
+	// a module provides the light as a generic component that handles the status light commands.
+	res := inject.NewGenericComponent("strip")
+	res.DoFunc = light.DoCommand
+	remote := statuslight.FromResource(res)
+
+	numLEDs, err := remote.NumLEDs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, numLEDs, test.ShouldEqual, 2)
+
+	test.That(t, remote.SetColors(ctx, []statuslight.Color{red, green}, nil), test.ShouldBeNil)
+	status, err := light.Status(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status, test.ShouldResemble, map[string]interface{}{"colors": []interface{}{"#ff0000", "#00ff00"}})
+
+	pattern := statuslight.Pattern{Kind: statuslight.PatternBreathe, Color: red, Period: 2 * time.Second}
+	test.That(t, remote.SetPattern(ctx, pattern, nil), test.ShouldBeNil)
+	status, err = light.Status(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["pattern"], test.ShouldEqual, "breathe")
+
+	err = remote.SetPattern(ctx, statuslight.Pattern{Kind: "sparkle"}, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
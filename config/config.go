@@ -3,10 +3,13 @@ package config
 
 import (
 	"bytes"
+	"cmp"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -36,13 +39,18 @@ type Config struct {
 	Processes         []pexec.ProcessConfig
 	Services          []resource.Config
 	Packages          []PackageConfig
+	PackageRegistries []PackageRegistry
+	PackageMirrors    []string
 	Network           NetworkConfig
 	Auth              AuthConfig
 	Debug             bool
 	LogConfig         []logging.LoggerPatternConfig
 	MaintenanceConfig *MaintenanceConfig
+	EmergencyStop     *EmergencyStopConfig
+	Shutdown          *ShutdownConfig
 	Jobs              []JobConfig
 	Tracing           TracingConfig
+	NetAppender       *logging.NetAppenderConfig
 
 	ConfigFilePath string
 
@@ -77,6 +85,12 @@ type Config struct {
 	// should be turned off. Defaults to false.
 	DisableLogDeduplication bool
 
+	// ResourceConfigurationConcurrency is the number of resources that may be
+	// (re)configured at the same time. If zero, the value of the
+	// VIAM_RESOURCE_CONFIGURATION_CONCURRENCY environment variable or its default is used. It is only
+	// read from local configs as it has no cloud representation yet.
+	ResourceConfigurationConcurrency int
+
 	// toCache stores the JSON marshalled version of the config to be cached. It should be a copy of
 	// the config pulled from cloud with minor changes.
 	// This version is kept because the config is changed as it moves through the system.
@@ -110,31 +124,128 @@ func (cfg TracingConfig) IsEnabled() bool {
 }
 
 // MaintenanceConfig specifies a sensor that the machine will check to determine if the machine should reconfigure.
-// This Config is not validated during config processing but it will be validated during reconfiguration.
+// The sensor is not validated during config processing but it will be validated during reconfiguration.
+//
+// If Windows are given, config changes and module upgrades are only applied while one of them is open or
+// the machine is in maintenance mode; changes received at other times are deferred until then.
 type MaintenanceConfig struct {
-	SensorName            string `json:"sensor_name"`
-	MaintenanceAllowedKey string `json:"maintenance_allowed_key"`
+	SensorName            string              `json:"sensor_name"`
+	MaintenanceAllowedKey string              `json:"maintenance_allowed_key"`
+	Windows               []MaintenanceWindow `json:"windows,omitempty"`
+}
+
+// EmergencyStopConfig specifies a GPIO pin that engages the machine's emergency stop when it becomes active.
+// Like MaintenanceConfig, the board is looked up when the pin is polled rather than during config processing.
+// It is only read from local configs as it has no cloud representation yet.
+type EmergencyStopConfig struct {
+	BoardName string `json:"board"`
+	Pin       string `json:"pin"`
+	// ActiveLow engages the emergency stop when the pin reads low rather than high.
+	ActiveLow      bool `json:"active_low,omitempty"`
+	PollIntervalMS int  `json:"poll_interval_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (c *EmergencyStopConfig) Validate(path string) error {
+	if c.BoardName == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "board")
+	}
+	if c.Pin == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "pin")
+	}
+	if c.PollIntervalMS < 0 {
+		return resource.NewConfigValidationError(path, errors.New("poll_interval_ms cannot be negative"))
+	}
+	return nil
+}
+
+// Safe states a resource can be brought to before it is closed during shutdown.
+const (
+	// SafeStateStop stops an actuator, such as braking a base.
+	SafeStateStop = "stop"
+	// SafeStateHome homes a gantry or moves an arm to its zero joint positions.
+	SafeStateHome = "home"
+	// SafeStateCommand sends the resource a DoCommand.
+	SafeStateCommand = "command"
+)
+
+// ShutdownConfig specifies how the machine's resources are brought to a safe state and closed when
+// it shuts down. Resources are always closed in reverse dependency order.
+type ShutdownConfig struct {
+	// ResourceTimeoutMS bounds how long each resource is given to reach its safe state, and then to
+	// close. TimeoutMS overrides it for a single resource.
+	ResourceTimeoutMS int                      `json:"resource_timeout_ms,omitempty"`
+	Resources         []ShutdownResourceConfig `json:"resources,omitempty"`
+}
+
+// ShutdownResourceConfig specifies how a single resource is shut down.
+type ShutdownResourceConfig struct {
+	// Name is the name of the resource, either short ("arm1") or fully qualified
+	// ("rdk:component:arm/arm1").
+	Name      string `json:"name"`
+	TimeoutMS int    `json:"timeout_ms,omitempty"`
+	// SafeState is one of SafeStateStop, SafeStateHome, or SafeStateCommand. The resource is closed
+	// without being brought to a safe state if it is empty.
+	SafeState string                 `json:"safe_state,omitempty"`
+	Command   map[string]interface{} `json:"command,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (c *ShutdownConfig) Validate(path string) error {
+	if c.ResourceTimeoutMS < 0 {
+		return resource.NewConfigValidationError(path, errors.New("resource_timeout_ms cannot be negative"))
+	}
+	seen := make(map[string]struct{}, len(c.Resources))
+	for i, res := range c.Resources {
+		resPath := fmt.Sprintf("%s.resources.%d", path, i)
+		if res.Name == "" {
+			return resource.NewConfigValidationFieldRequiredError(resPath, "name")
+		}
+		if _, ok := seen[res.Name]; ok {
+			return resource.NewConfigValidationError(resPath, errors.Errorf("resource %q is listed more than once", res.Name))
+		}
+		seen[res.Name] = struct{}{}
+		if res.TimeoutMS < 0 {
+			return resource.NewConfigValidationError(resPath, errors.New("timeout_ms cannot be negative"))
+		}
+		switch res.SafeState {
+		case "", SafeStateStop, SafeStateHome:
+		case SafeStateCommand:
+			if len(res.Command) == 0 {
+				return resource.NewConfigValidationFieldRequiredError(resPath, "command")
+			}
+		default:
+			return resource.NewConfigValidationError(resPath, errors.Errorf("unknown safe_state %q", res.SafeState))
+		}
+	}
+	return nil
 }
 
 // NOTE: This data must be maintained with what is in [Config].
 type configData struct {
-	Cloud                   *Cloud                        `json:"cloud,omitempty"`
-	Modules                 []Module                      `json:"modules,omitempty"`
-	Remotes                 []Remote                      `json:"remotes,omitempty"`
-	Components              []resource.Config             `json:"components,omitempty"`
-	Processes               []pexec.ProcessConfig         `json:"processes,omitempty"`
-	Services                []resource.Config             `json:"services,omitempty"`
-	Packages                []PackageConfig               `json:"packages,omitempty"`
-	Network                 NetworkConfig                 `json:"network"`
-	Auth                    AuthConfig                    `json:"auth"`
-	Debug                   bool                          `json:"debug,omitempty"`
-	EnableWebProfile        bool                          `json:"enable_web_profile"`
-	LogConfig               []logging.LoggerPatternConfig `json:"log,omitempty"`
-	Revision                string                        `json:"revision,omitempty"`
-	MaintenanceConfig       *MaintenanceConfig            `json:"maintenance,omitempty"`
-	DisableLogDeduplication bool                          `json:"disable_log_deduplication"`
-	Jobs                    []JobConfig                   `json:"jobs,omitempty"`
-	Tracing                 TracingConfig                 `json:"tracing,omitempty"`
+	Cloud                            *Cloud                        `json:"cloud,omitempty"`
+	Modules                          []Module                      `json:"modules,omitempty"`
+	Remotes                          []Remote                      `json:"remotes,omitempty"`
+	Components                       []resource.Config             `json:"components,omitempty"`
+	Processes                        []pexec.ProcessConfig         `json:"processes,omitempty"`
+	Services                         []resource.Config             `json:"services,omitempty"`
+	Packages                         []PackageConfig               `json:"packages,omitempty"`
+	PackageRegistries                []PackageRegistry             `json:"package_registries,omitempty"`
+	PackageMirrors                   []string                      `json:"package_mirrors,omitempty"`
+	Network                          NetworkConfig                 `json:"network"`
+	Auth                             AuthConfig                    `json:"auth"`
+	Debug                            bool                          `json:"debug,omitempty"`
+	EnableWebProfile                 bool                          `json:"enable_web_profile"`
+	LogConfig                        []logging.LoggerPatternConfig `json:"log,omitempty"`
+	Revision                         string                        `json:"revision,omitempty"`
+	MaintenanceConfig                *MaintenanceConfig            `json:"maintenance,omitempty"`
+	EmergencyStop                    *EmergencyStopConfig          `json:"emergency_stop,omitempty"`
+	Shutdown                         *ShutdownConfig               `json:"shutdown,omitempty"`
+	DisableLogDeduplication          bool                          `json:"disable_log_deduplication"`
+	ResourceConfigurationConcurrency int                           `json:"resource_configuration_concurrency,omitempty"`
+	Jobs                             []JobConfig                   `json:"jobs,omitempty"`
+	Tracing                          TracingConfig                 `json:"tracing,omitempty"`
+	NetAppender                      *logging.NetAppenderConfig    `json:"net_appender,omitempty"`
 }
 
 // AppValidationStatus refers to the.
@@ -160,12 +271,64 @@ func (c *Config) Ensure(fromCloud bool, logger logging.Logger) error {
 	if err := c.Network.Validate("network"); err != nil {
 		return err
 	}
+	if c.Network.Tracing != nil {
+		if c.Tracing != (TracingConfig{}) && c.Tracing != *c.Network.Tracing {
+			return resource.NewConfigValidationError("network.tracing", errors.New("may only set one of tracing or network.tracing"))
+		}
+		c.Tracing = *c.Network.Tracing
+	}
 
 	// Updates ValidatedKeySet once validated.
 	if err := c.Auth.Validate("auth"); err != nil {
 		return err
 	}
 
+	if c.MaintenanceConfig != nil {
+		if err := c.MaintenanceConfig.Validate("maintenance"); err != nil {
+			return err
+		}
+	}
+
+	if c.EmergencyStop != nil {
+		if err := c.EmergencyStop.Validate("emergency_stop"); err != nil {
+			return err
+		}
+	}
+
+	if c.Shutdown != nil {
+		if err := c.Shutdown.Validate("shutdown"); err != nil {
+			return err
+		}
+	}
+
+	if c.NetAppender != nil {
+		if err := c.NetAppender.Validate(); err != nil {
+			return resource.NewConfigValidationError("net_appender", err)
+		}
+	}
+
+	seenRegistries := make(map[string]struct{})
+	for idx := range c.PackageRegistries {
+		path := fmt.Sprintf("%s.%d", "package_registries", idx)
+		if err := c.PackageRegistries[idx].Validate(path); err != nil {
+			return err
+		}
+		if _, exists := seenRegistries[c.PackageRegistries[idx].Host]; exists {
+			return resource.NewConfigValidationError(path, errors.Errorf("duplicate registry %q", c.PackageRegistries[idx].Host))
+		}
+		seenRegistries[c.PackageRegistries[idx].Host] = struct{}{}
+	}
+
+	for idx, mirror := range c.PackageMirrors {
+		if err := ValidatePackageMirror(mirror); err != nil {
+			return resource.NewConfigValidationError(fmt.Sprintf("%s.%d", "package_mirrors", idx), err)
+		}
+	}
+
+	if c.ResourceConfigurationConcurrency < 0 {
+		return resource.NewConfigValidationError("resource_configuration_concurrency", errors.New("must not be negative"))
+	}
+
 	// Check jobs, modules, remotes, packages, and processes, and log errors for lack of
 	// uniqueness within each category. Managers of each resource handle duplicates
 	// differently, and behavior is undefined.
@@ -287,6 +450,8 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.Processes = conf.Processes
 	c.Services = conf.Services
 	c.Packages = conf.Packages
+	c.PackageRegistries = conf.PackageRegistries
+	c.PackageMirrors = conf.PackageMirrors
 	c.Network = conf.Network
 	c.Auth = conf.Auth
 	c.Debug = conf.Debug
@@ -294,9 +459,13 @@ func (c *Config) UnmarshalJSON(data []byte) error {
 	c.LogConfig = conf.LogConfig
 	c.Revision = conf.Revision
 	c.MaintenanceConfig = conf.MaintenanceConfig
+	c.EmergencyStop = conf.EmergencyStop
+	c.Shutdown = conf.Shutdown
 	c.DisableLogDeduplication = conf.DisableLogDeduplication
+	c.ResourceConfigurationConcurrency = conf.ResourceConfigurationConcurrency
 	c.Jobs = conf.Jobs
 	c.Tracing = conf.Tracing
+	c.NetAppender = conf.NetAppender
 
 	return nil
 }
@@ -311,23 +480,29 @@ func (c Config) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(configData{
-		Cloud:                   c.Cloud,
-		Modules:                 c.Modules,
-		Remotes:                 c.Remotes,
-		Components:              c.Components,
-		Processes:               c.Processes,
-		Services:                c.Services,
-		Packages:                c.Packages,
-		Network:                 c.Network,
-		Auth:                    c.Auth,
-		Debug:                   c.Debug,
-		EnableWebProfile:        c.EnableWebProfile,
-		LogConfig:               c.LogConfig,
-		Revision:                c.Revision,
-		MaintenanceConfig:       c.MaintenanceConfig,
-		DisableLogDeduplication: c.DisableLogDeduplication,
-		Jobs:                    c.Jobs,
-		Tracing:                 c.Tracing,
+		Cloud:                            c.Cloud,
+		Modules:                          c.Modules,
+		Remotes:                          c.Remotes,
+		Components:                       c.Components,
+		Processes:                        c.Processes,
+		Services:                         c.Services,
+		Packages:                         c.Packages,
+		PackageRegistries:                c.PackageRegistries,
+		PackageMirrors:                   c.PackageMirrors,
+		Network:                          c.Network,
+		Auth:                             c.Auth,
+		Debug:                            c.Debug,
+		EnableWebProfile:                 c.EnableWebProfile,
+		LogConfig:                        c.LogConfig,
+		Revision:                         c.Revision,
+		MaintenanceConfig:                c.MaintenanceConfig,
+		EmergencyStop:                    c.EmergencyStop,
+		Shutdown:                         c.Shutdown,
+		DisableLogDeduplication:          c.DisableLogDeduplication,
+		ResourceConfigurationConcurrency: c.ResourceConfigurationConcurrency,
+		Jobs:                             c.Jobs,
+		Tracing:                          c.Tracing,
+		NetAppender:                      c.NetAppender,
 	})
 }
 
@@ -363,6 +538,23 @@ type Remote struct {
 	ReconnectInterval         time.Duration
 	AssociatedResourceConfigs []resource.AssociatedResourceConfig
 
+	// Addresses are other addresses the remote is reachable at, such as a LAN address and a cloud address, which
+	// are connected to when Address cannot be. See FailoverAddresses for the order they are tried in.
+	Addresses []RemoteAddress
+	// FailoverThreshold is the number of consecutive failed connection checks after which the connection to the
+	// remote is considered lost and the remote is connected to again, failing over to the next address that can
+	// be connected to. Defaults to 1.
+	FailoverThreshold int
+	// FailbackInterval is how often to try connecting to a more preferred address while connected to a failover
+	// address. Defaults to a minute.
+	FailbackInterval time.Duration
+	// Resources selects which resources of the remote are added to the resource graph. All of them are if nil.
+	Resources *RemoteResources
+	// ClockSyncInterval is how often to estimate the offset between the clock of the remote and the clock of this
+	// machine, by which the capture times of the remote's data are translated into the time of this machine.
+	// Clocks are not synchronized if 0.
+	ClockSyncInterval time.Duration
+
 	// Secret is a helper for a robot location secret.
 	Secret string
 	Prefix string
@@ -382,6 +574,11 @@ type remoteData struct {
 	ConnectionCheckInterval   string                              `json:"connection_check_interval,omitempty"`
 	ReconnectInterval         string                              `json:"reconnect_interval,omitempty"`
 	AssociatedResourceConfigs []resource.AssociatedResourceConfig `json:"service_configs"`
+	Addresses                 []RemoteAddress                     `json:"addresses,omitempty"`
+	FailoverThreshold         int                                 `json:"failover_threshold,omitempty"`
+	FailbackInterval          string                              `json:"failback_interval,omitempty"`
+	Resources                 *RemoteResources                    `json:"resources,omitempty"`
+	ClockSyncInterval         string                              `json:"clock_sync_interval,omitempty"`
 
 	// Secret is a helper for a robot location secret.
 	Secret string `json:"secret"`
@@ -412,6 +609,9 @@ func (conf *Remote) UnmarshalJSON(data []byte) error {
 		ManagedBy:                 temp.ManagedBy,
 		Insecure:                  temp.Insecure,
 		AssociatedResourceConfigs: temp.AssociatedResourceConfigs,
+		Addresses:                 temp.Addresses,
+		FailoverThreshold:         temp.FailoverThreshold,
+		Resources:                 temp.Resources,
 		Secret:                    temp.Secret,
 		Prefix:                    temp.Prefix,
 	}
@@ -429,6 +629,20 @@ func (conf *Remote) UnmarshalJSON(data []byte) error {
 		}
 		conf.ReconnectInterval = dur
 	}
+	if temp.FailbackInterval != "" {
+		dur, err := time.ParseDuration(temp.FailbackInterval)
+		if err != nil {
+			return err
+		}
+		conf.FailbackInterval = dur
+	}
+	if temp.ClockSyncInterval != "" {
+		dur, err := time.ParseDuration(temp.ClockSyncInterval)
+		if err != nil {
+			return err
+		}
+		conf.ClockSyncInterval = dur
+	}
 	return nil
 }
 
@@ -443,6 +657,9 @@ func (conf Remote) MarshalJSON() ([]byte, error) {
 		ManagedBy:                 conf.ManagedBy,
 		Insecure:                  conf.Insecure,
 		AssociatedResourceConfigs: conf.AssociatedResourceConfigs,
+		Addresses:                 conf.Addresses,
+		FailoverThreshold:         conf.FailoverThreshold,
+		Resources:                 conf.Resources,
 		Secret:                    conf.Secret,
 	}
 	if conf.Prefix != "" {
@@ -454,9 +671,44 @@ func (conf Remote) MarshalJSON() ([]byte, error) {
 	if conf.ReconnectInterval != 0 {
 		temp.ReconnectInterval = conf.ReconnectInterval.String()
 	}
+	if conf.FailbackInterval != 0 {
+		temp.FailbackInterval = conf.FailbackInterval.String()
+	}
+	if conf.ClockSyncInterval != 0 {
+		temp.ClockSyncInterval = conf.ClockSyncInterval.String()
+	}
 	return json.Marshal(temp)
 }
 
+// validateRemoteAddress checks the parts of a remote address that would otherwise only fail when dialed.
+func validateRemoteAddress(address string) error {
+	if rutils.IsUnixSocketAddress(address) && strings.TrimPrefix(address, rutils.UnixSocketAddressPrefix) == "" {
+		return errors.Errorf("address %q is missing the path of the socket", address)
+	}
+	return nil
+}
+
+// A RemoteAddress is a failover address of a remote.
+type RemoteAddress struct {
+	Address string `json:"address"`
+	// Priority orders failover addresses, lower first. Addresses of the same priority are tried in the order
+	// they are listed.
+	Priority int `json:"priority,omitempty"`
+}
+
+// FailoverAddresses returns the failover addresses of the remote in the order they are tried, after Address.
+func (conf *Remote) FailoverAddresses() []string {
+	addresses := slices.Clone(conf.Addresses)
+	slices.SortStableFunc(addresses, func(a, b RemoteAddress) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+	failover := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		failover = append(failover, address.Address)
+	}
+	return failover
+}
+
 // RemoteAuth specifies how to authenticate against a remote. If no credentials are
 // specified, authentication does not happen. If an entity is specified, the
 // authentication request will specify it.
@@ -494,6 +746,40 @@ func (conf *Remote) validate(path string) error {
 	if conf.Address == "" {
 		return resource.NewConfigValidationFieldRequiredError(path, "address")
 	}
+	if err := validateRemoteAddress(conf.Address); err != nil {
+		return resource.NewConfigValidationError(path, err)
+	}
+	seenAddresses := map[string]bool{conf.Address: true}
+	for idx, address := range conf.Addresses {
+		addressPath := fmt.Sprintf("%s.addresses.%d", path, idx)
+		if address.Address == "" {
+			return resource.NewConfigValidationFieldRequiredError(addressPath, "address")
+		}
+		if err := validateRemoteAddress(address.Address); err != nil {
+			return resource.NewConfigValidationError(addressPath, err)
+		}
+		if address.Priority < 0 {
+			return resource.NewConfigValidationError(addressPath, errors.New("priority cannot be negative"))
+		}
+		if seenAddresses[address.Address] {
+			return resource.NewConfigValidationError(addressPath, errors.Errorf("duplicate address %q", address.Address))
+		}
+		seenAddresses[address.Address] = true
+	}
+	if conf.FailoverThreshold < 0 {
+		return resource.NewConfigValidationError(path, errors.New("failover_threshold cannot be negative"))
+	}
+	if conf.FailbackInterval < 0 {
+		return resource.NewConfigValidationError(path, errors.New("failback_interval cannot be negative"))
+	}
+	if conf.ClockSyncInterval < 0 {
+		return resource.NewConfigValidationError(path, errors.New("clock_sync_interval cannot be negative"))
+	}
+	if conf.Resources != nil {
+		if err := conf.Resources.validate(path + ".resources"); err != nil {
+			return err
+		}
+	}
 	if conf.Frame != nil {
 		if conf.Frame.Parent == "" {
 			return resource.NewConfigValidationFieldRequiredError(path, "frame.parent")
@@ -529,12 +815,112 @@ type Cloud struct {
 	SignalingInsecure bool
 	AppAddress        string
 	RefreshInterval   time.Duration
+	// ConfigRollback is read from the local config only.
+	ConfigRollback *ConfigRollbackConfig
+	// StagedRollout is read from the local config only.
+	StagedRollout *StagedRolloutConfig
 
 	// cached by us and fetched from a non-config endpoint.
 	TLSCertificate string
 	TLSPrivateKey  string
 }
 
+// Defaults for a ConfigRollbackConfig.
+const (
+	DefaultConfigHistory     = 3
+	DefaultConfigGracePeriod = time.Minute
+)
+
+// ConfigRollbackConfig specifies when a new cloud config is rolled back to the last known-good one.
+// A config becomes known-good once the machine has run it for the grace period and met the health
+// criteria: no more than MaxUnhealthyResources resources unhealthy and every one of
+// CriticalResources ready. A config that misses them is rolled back and never applied again.
+type ConfigRollbackConfig struct {
+	// History is how many known-good configs are kept on disk.
+	History               int `json:"history,omitempty"`
+	GracePeriodSec        int `json:"grace_period_sec,omitempty"`
+	MaxUnhealthyResources int `json:"max_unhealthy_resources,omitempty"`
+	// CriticalResources are resource names, either short ("arm1") or fully qualified
+	// ("rdk:component:arm/arm1").
+	CriticalResources []string `json:"critical_resources,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (c *ConfigRollbackConfig) Validate(path string) error {
+	if c.History < 0 {
+		return resource.NewConfigValidationError(path, errors.New("history cannot be negative"))
+	}
+	if c.GracePeriodSec < 0 {
+		return resource.NewConfigValidationError(path, errors.New("grace_period_sec cannot be negative"))
+	}
+	if c.MaxUnhealthyResources < 0 {
+		return resource.NewConfigValidationError(path, errors.New("max_unhealthy_resources cannot be negative"))
+	}
+	return nil
+}
+
+// HistorySize returns how many known-good configs are kept on disk.
+func (c *ConfigRollbackConfig) HistorySize() int {
+	if c.History == 0 {
+		return DefaultConfigHistory
+	}
+	return c.History
+}
+
+// GracePeriod returns how long a new config runs before it is checked against the health criteria.
+func (c *ConfigRollbackConfig) GracePeriod() time.Duration {
+	if c.GracePeriodSec == 0 {
+		return DefaultConfigGracePeriod
+	}
+	return time.Duration(c.GracePeriodSec) * time.Second
+}
+
+// DefaultStagedTag is the tag that marks a config revision as staged unless configured otherwise.
+const DefaultStagedTag = "staged"
+
+// StagedRolloutConfig specifies which cloud config revisions wait for acknowledgment on the machine
+// before they are applied. A revision is tagged by appending "+" and the tag to it, such as
+// "3f2a9c+staged". Until it is acknowledged, the machine keeps running its current config.
+type StagedRolloutConfig struct {
+	// RequireAck is whether revisions tagged as staged wait for acknowledgment.
+	RequireAck bool `json:"require_ack,omitempty"`
+	// Tag is the tag that marks a revision as staged, DefaultStagedTag if unset.
+	Tag string `json:"tag,omitempty"`
+	// PinnedRevision pins the machine to a revision, so that every other revision waits for
+	// acknowledgment whether it is staged or not.
+	PinnedRevision string `json:"pinned_revision,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (c *StagedRolloutConfig) Validate(path string) error {
+	if strings.Contains(c.Tag, "+") {
+		return resource.NewConfigValidationError(path, errors.New(`tag cannot contain "+"`))
+	}
+	return nil
+}
+
+// IsStaged returns whether the given revision is tagged as staged.
+func (c *StagedRolloutConfig) IsStaged(revision string) bool {
+	tag := c.Tag
+	if tag == "" {
+		tag = DefaultStagedTag
+	}
+	parts := strings.Split(revision, "+")
+	return slices.Contains(parts[1:], tag)
+}
+
+// RequiresAck returns whether the given revision waits for acknowledgment before it is applied.
+// A nil StagedRolloutConfig never requires acknowledgment.
+func (c *StagedRolloutConfig) RequiresAck(revision string) bool {
+	if c == nil {
+		return false
+	}
+	if c.PinnedRevision != "" {
+		return revision != c.PinnedRevision
+	}
+	return c.RequireAck && c.IsStaged(revision)
+}
+
 // Note: keep this in sync with Cloud.
 type cloudData struct {
 	// For a working cloud managed robot, these three fields have to be set
@@ -558,6 +944,9 @@ type cloudData struct {
 	SignalingInsecure bool             `json:"signaling_insecure,omitempty"`
 	RefreshInterval   string           `json:"refresh_interval,omitempty"`
 
+	ConfigRollback *ConfigRollbackConfig `json:"config_rollback,omitempty"`
+	StagedRollout  *StagedRolloutConfig  `json:"staged_rollout,omitempty"`
+
 	// cached by us and fetched from a non-config endpoint.
 	TLSCertificate string `json:"tls_certificate"`
 	TLSPrivateKey  string `json:"tls_private_key"`
@@ -611,6 +1000,8 @@ func (config *Cloud) UnmarshalJSON(data []byte) error {
 		SignalingAddress:  temp.SignalingAddress,
 		SignalingInsecure: temp.SignalingInsecure,
 		AppAddress:        temp.AppAddress,
+		ConfigRollback:    temp.ConfigRollback,
+		StagedRollout:     temp.StagedRollout,
 		TLSCertificate:    temp.TLSCertificate,
 		TLSPrivateKey:     temp.TLSPrivateKey,
 	}
@@ -641,6 +1032,8 @@ func (config Cloud) MarshalJSON() ([]byte, error) {
 		SignalingAddress:  config.SignalingAddress,
 		SignalingInsecure: config.SignalingInsecure,
 		AppAddress:        config.AppAddress,
+		ConfigRollback:    config.ConfigRollback,
+		StagedRollout:     config.StagedRollout,
 		TLSCertificate:    config.TLSCertificate,
 		TLSPrivateKey:     config.TLSPrivateKey,
 	}
@@ -670,6 +1063,16 @@ func (config *Cloud) Validate(path string, fromCloud bool) error {
 	if config.RefreshInterval == 0 {
 		config.RefreshInterval = 10 * time.Second
 	}
+	if config.ConfigRollback != nil {
+		if err := config.ConfigRollback.Validate(path + ".config_rollback"); err != nil {
+			return err
+		}
+	}
+	if config.StagedRollout != nil {
+		if err := config.StagedRollout.Validate(path + ".staged_rollout"); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -712,6 +1115,11 @@ type NetworkConfigData struct {
 
 	BindAddressDefaultSet bool `json:"-"`
 
+	// UnixSocket, if set, is the path of a Unix domain socket the web server also listens on, without
+	// TLS, so that processes on the same host can connect to it as "unix:<path>" without the overhead
+	// of TCP and TLS.
+	UnixSocket string `json:"unix_socket,omitempty"`
+
 	// TLSCertFile is used to enable secure communications on the hosted HTTP server.
 	// This is mutually exclusive with TLSCertPEM and TLSKeyPEM.
 	TLSCertFile string `json:"tls_cert_file,omitempty"`
@@ -732,6 +1140,18 @@ type NetworkConfigData struct {
 
 	// TrafficTunnelEndpoints are the allowed ports and options for tunneling.
 	TrafficTunnelEndpoints []TrafficTunnelEndpoint `json:"traffic_tunnel_endpoints"`
+
+	// DisableHTTPAPI turns off the HTTP/JSON interface to the robot's APIs served under /api.
+	DisableHTTPAPI bool `json:"disable_http_api,omitempty"`
+
+	// Tracing configures where the spans of requests served and made by this server, its modules
+	// and its remotes are exported to. It may be set here or at the top level of the config, but
+	// not both.
+	Tracing *TracingConfig `json:"tracing,omitempty"`
+
+	// MediaServer, if set, re-publishes camera streams over RTSP, RTMP and HLS for clients that
+	// have no WebRTC support, such as NVRs and streaming servers.
+	MediaServer *MediaServerConfig `json:"media_server,omitempty"`
 }
 
 // MarshalJSON marshals out this config.
@@ -759,10 +1179,24 @@ func (nc *NetworkConfig) Validate(path string) error {
 	if _, _, err := net.SplitHostPort(nc.BindAddress); err != nil {
 		return resource.NewConfigValidationError(path, errors.Wrap(err, "error validating bind_address"))
 	}
+	if nc.UnixSocket != "" && !filepath.IsAbs(nc.UnixSocket) {
+		return resource.NewConfigValidationError(path, errors.New("unix_socket must be an absolute path"))
+	}
 	if (nc.TLSCertFile == "") != (nc.TLSKeyFile == "") {
 		return resource.NewConfigValidationError(path, errors.New("must provide both tls_cert_file and tls_key_file"))
 	}
 
+	for idx, tte := range nc.TrafficTunnelEndpoints {
+		if err := tte.Validate(fmt.Sprintf("%s.traffic_tunnel_endpoints.%d", path, idx)); err != nil {
+			return err
+		}
+	}
+	if nc.MediaServer != nil {
+		if err := nc.MediaServer.Validate(path + ".media_server"); err != nil {
+			return err
+		}
+	}
+
 	return nc.Sessions.Validate(path + ".sessions")
 }
 
@@ -819,6 +1253,12 @@ func (sc *SessionsConfig) Validate(path string) error {
 	return nil
 }
 
+// The protocols traffic can be tunneled over.
+const (
+	TunnelProtocolTCP = "tcp"
+	TunnelProtocolUDP = "udp"
+)
+
 // TrafficTunnelEndpoint is an endpoint for tunneling traffic.
 type TrafficTunnelEndpoint struct {
 	// Port is the port which can be tunneled to/from.
@@ -826,12 +1266,48 @@ type TrafficTunnelEndpoint struct {
 	// ConnectionTimeout is the timeout with which we will attempt to connect to the port.
 	// If set to 0 or not specified, a default connection timeout of 10 seconds will be used.
 	ConnectionTimeout time.Duration
+	// Protocol is the protocol of the port, TunnelProtocolTCP or TunnelProtocolUDP. Defaults to
+	// TCP. Over UDP, each tunneled message is a datagram. Only local configs may set it, as it has
+	// no cloud representation yet.
+	Protocol string
+	// AllowedEntities, if not empty, limits the tunnel to the authenticated entities listed.
+	AllowedEntities []string
 }
 
 // Note: keep this in sync with TrafficTunnelEndpoint.
 type trafficTunnelEndpointData struct {
-	Port              int    `json:"port"`
-	ConnectionTimeout string `json:"connection_timeout,omitempty"`
+	Port              int      `json:"port"`
+	ConnectionTimeout string   `json:"connection_timeout,omitempty"`
+	Protocol          string   `json:"protocol,omitempty"`
+	AllowedEntities   []string `json:"allowed_entities,omitempty"`
+}
+
+// Network returns the network the port is dialed over, which is the protocol of the endpoint.
+func (tte TrafficTunnelEndpoint) Network() string {
+	if tte.Protocol == "" {
+		return TunnelProtocolTCP
+	}
+	return tte.Protocol
+}
+
+// Allows returns whether the authenticated entity may tunnel to the endpoint.
+func (tte TrafficTunnelEndpoint) Allows(entity string) bool {
+	return len(tte.AllowedEntities) == 0 || slices.Contains(tte.AllowedEntities, entity)
+}
+
+// Validate ensures the endpoint is valid.
+func (tte TrafficTunnelEndpoint) Validate(path string) error {
+	if tte.Port <= 0 || tte.Port > 65535 {
+		return resource.NewConfigValidationError(path, errors.Errorf("invalid port %d", tte.Port))
+	}
+	if tte.ConnectionTimeout < 0 {
+		return resource.NewConfigValidationError(path, errors.New("connection_timeout cannot be negative"))
+	}
+	if network := tte.Network(); network != TunnelProtocolTCP && network != TunnelProtocolUDP {
+		return resource.NewConfigValidationError(path, errors.Errorf(
+			"protocol must be %q or %q, not %q", TunnelProtocolTCP, TunnelProtocolUDP, tte.Protocol))
+	}
+	return nil
 }
 
 // UnmarshalJSON unmarshals JSON data into this traffic tunnel endpoint.
@@ -842,6 +1318,8 @@ func (tte *TrafficTunnelEndpoint) UnmarshalJSON(data []byte) error {
 	}
 
 	tte.Port = temp.Port
+	tte.Protocol = temp.Protocol
+	tte.AllowedEntities = temp.AllowedEntities
 
 	if temp.ConnectionTimeout != "" {
 		dur, err := time.ParseDuration(temp.ConnectionTimeout)
@@ -859,6 +1337,8 @@ func (tte *TrafficTunnelEndpoint) MarshalJSON() ([]byte, error) {
 	var temp trafficTunnelEndpointData
 
 	temp.Port = tte.Port
+	temp.Protocol = tte.Protocol
+	temp.AllowedEntities = tte.AllowedEntities
 
 	if tte.ConnectionTimeout != 0 {
 		temp.ConnectionTimeout = tte.ConnectionTimeout.String()
@@ -866,11 +1346,71 @@ func (tte *TrafficTunnelEndpoint) MarshalJSON() ([]byte, error) {
 	return json.Marshal(temp)
 }
 
+// MediaServerConfig configures the media server, which re-publishes the streams of cameras at
+// rtsp://<rtsp_address>/<camera>, rtmp://<rtmp_address>/live/<camera> and
+// http://<hls_address>/<camera>/index.m3u8. Each protocol is served only if its address is set.
+type MediaServerConfig struct {
+	RTSPAddress string `json:"rtsp_address,omitempty"`
+	RTMPAddress string `json:"rtmp_address,omitempty"`
+	HLSAddress  string `json:"hls_address,omitempty"`
+
+	// Cameras are the names of the cameras re-published. All cameras are if it is empty.
+	Cameras []string `json:"cameras,omitempty"`
+
+	// Username and Password are required of clients: by RTSP and HLS clients through the
+	// authentication of their protocol, and by RTMP clients as the user and pass query parameters
+	// of the stream name. They must be set unless AllowUnauthenticated is.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	// AllowUnauthenticated lets any client that can reach the media server view the cameras when
+	// no username and password are set.
+	AllowUnauthenticated bool `json:"allow_unauthenticated,omitempty"`
+
+	// FrameRate is the rate at which the images of cameras that do not provide H264 themselves are
+	// encoded, 10 by default.
+	FrameRate int `json:"frame_rate,omitempty"`
+}
+
+// Validate ensures the media server config is valid.
+func (msc *MediaServerConfig) Validate(path string) error {
+	if msc.RTSPAddress == "" && msc.RTMPAddress == "" && msc.HLSAddress == "" {
+		return resource.NewConfigValidationError(path, errors.New("must set at least one of rtsp_address, rtmp_address or hls_address"))
+	}
+	for _, address := range []string{msc.RTSPAddress, msc.RTMPAddress, msc.HLSAddress} {
+		if address == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return resource.NewConfigValidationError(path, errors.Wrapf(err, "invalid address %q", address))
+		}
+	}
+	if (msc.Username == "") != (msc.Password == "") {
+		return resource.NewConfigValidationError(path, errors.New("must provide both username and password"))
+	}
+	if msc.Username == "" && !msc.AllowUnauthenticated {
+		return resource.NewConfigValidationError(path,
+			errors.New("must provide username and password, or set allow_unauthenticated to serve cameras without authentication"))
+	}
+	if msc.FrameRate < 0 {
+		return resource.NewConfigValidationError(path, errors.New("frame_rate cannot be negative"))
+	}
+	return nil
+}
+
 // AuthConfig describes authentication and authorization settings for the web server.
 type AuthConfig struct {
 	Handlers           []AuthHandlerConfig `json:"handlers,omitempty"`
 	TLSAuthEntities    []string            `json:"tls_auth_entities,omitempty"`
 	ExternalAuthConfig *ExternalAuthConfig `json:"external_auth_config,omitempty"`
+	// Roles restrict what authenticated entities may access. Entities without a role have full access,
+	// but calls over WebRTC connections signaled elsewhere, such as through the cloud, get the viewer
+	// role as the entity that signaled them is not known. Roles are only read from local configs as
+	// they have no cloud representation yet.
+	Roles []AuthRoleConfig `json:"roles,omitempty"`
+	// RateLimits limit how quickly authenticated entities may call the robot's API. Like roles, they
+	// are only read from local configs.
+	RateLimits []RateLimitConfig `json:"rate_limits,omitempty"`
 }
 
 // ExternalAuthConfig contains information needed to verify externally authenticated tokens.
@@ -956,10 +1496,93 @@ type AuthHandlerConfig struct {
 	Config rutils.AttributeMap `json:"config"`
 }
 
+// AuthRole is a level of access to the robot's API.
+type AuthRole string
+
+const (
+	// AuthRoleViewer may only call methods that read state, such as getting sensor readings and camera images.
+	AuthRoleViewer AuthRole = "viewer"
+	// AuthRoleOperator may additionally move and command resources, but not administer the robot.
+	AuthRoleOperator AuthRole = "operator"
+	// AuthRoleAdmin may call every method, including shell access, restarting modules and shutting down.
+	AuthRoleAdmin AuthRole = "admin"
+)
+
+// AuthRoleConfig assigns a role to an authenticated entity.
+type AuthRoleConfig struct {
+	// Entity is the entity a credential authenticates as, such as an API key ID.
+	Entity string   `json:"entity"`
+	Role   AuthRole `json:"role"`
+	// Resources, if set, limits the entity to the resources with these names.
+	Resources []string `json:"resources,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *AuthRoleConfig) Validate(path string) error {
+	if config.Entity == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "entity")
+	}
+	switch config.Role {
+	case AuthRoleViewer, AuthRoleOperator, AuthRoleAdmin:
+	case "":
+		return resource.NewConfigValidationFieldRequiredError(path, "role")
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf(
+			"unknown role %q; must be one of %q, %q or %q", config.Role, AuthRoleViewer, AuthRoleOperator, AuthRoleAdmin))
+	}
+	return nil
+}
+
+// APIMethodClass groups the robot's API methods for rate limiting.
+type APIMethodClass string
+
+const (
+	// APIMethodClassRead are the methods the viewer role may call.
+	APIMethodClassRead APIMethodClass = "read"
+	// APIMethodClassWrite are the methods that move and command resources.
+	APIMethodClassWrite APIMethodClass = "write"
+	// APIMethodClassAdmin are the methods only the admin role may call.
+	APIMethodClassAdmin APIMethodClass = "admin"
+)
+
+// RateLimitConfig limits the calls an authenticated entity makes to a class of methods. An empty
+// entity applies the limit to every entity and an empty class applies it to every method; when
+// several limits match a call, only the most specific one is applied.
+type RateLimitConfig struct {
+	Entity string         `json:"entity,omitempty"`
+	Class  APIMethodClass `json:"class,omitempty"`
+	// RequestsPerSec is the sustained rate of calls allowed, with Burst calls allowed at once. Burst
+	// defaults to RequestsPerSec rounded up.
+	RequestsPerSec float64 `json:"requests_per_sec,omitempty"`
+	Burst          int     `json:"burst,omitempty"`
+	// MaxConcurrentStreams is the number of streaming calls that may be open at once.
+	MaxConcurrentStreams int `json:"max_concurrent_streams,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (config *RateLimitConfig) Validate(path string) error {
+	switch config.Class {
+	case "", APIMethodClassRead, APIMethodClassWrite, APIMethodClassAdmin:
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf(
+			"unknown class %q; must be one of %q, %q or %q", config.Class, APIMethodClassRead, APIMethodClassWrite, APIMethodClassAdmin))
+	}
+	if config.RequestsPerSec < 0 || config.Burst < 0 || config.MaxConcurrentStreams < 0 {
+		return resource.NewConfigValidationError(path, errors.New("requests_per_sec, burst and max_concurrent_streams must not be negative"))
+	}
+	if config.Burst != 0 && config.RequestsPerSec == 0 {
+		return resource.NewConfigValidationError(path, errors.New("burst requires requests_per_sec"))
+	}
+	if config.RequestsPerSec == 0 && config.MaxConcurrentStreams == 0 {
+		return resource.NewConfigValidationError(path, errors.New("requests_per_sec or max_concurrent_streams is required"))
+	}
+	return nil
+}
+
 // Validate ensures all parts of the config are valid. If it exists, updates ExternalAuthConfig's ValidatedKeySet once validated.
-// A sample AuthConfig in JSON form is shown below, where "handlers" contains a list of auth handlers. The only accepted credential
-// type for the RDK in the config is "api-key" currently. An auth handler for utils.CredentialsTypeRobotLocationSecret may be added
-// later by the RDK during processing.
+// A sample AuthConfig in JSON form is shown below, where "handlers" contains a list of auth handlers. The accepted credential
+// types for the RDK in the config are "api-key", "mtls" (see ParseMTLSAuthConfig) and "oidc" (see ParseOIDCAuthConfig).
+// An auth handler for utils.CredentialsTypeRobotLocationSecret may be added later by the RDK during processing.
 //
 //	"auth": {
 //			"handlers": [
@@ -972,7 +1595,21 @@ type AuthHandlerConfig struct {
 //					}
 //				}
 //			],
-//		    "external_auth_config": {}
+//		    "external_auth_config": {},
+//			"roles": [
+//				{
+//					"entity": "API_KEY_ID_2",
+//					"role": "viewer",
+//					"resources": ["camera1"]
+//				}
+//			],
+//			"rate_limits": [
+//				{
+//					"class": "write",
+//					"requests_per_sec": 20,
+//					"max_concurrent_streams": 4
+//				}
+//			]
 //	}
 func (config *AuthConfig) Validate(path string) error {
 	seenTypes := make(map[string]struct{}, len(config.Handlers))
@@ -991,6 +1628,30 @@ func (config *AuthConfig) Validate(path string) error {
 			return err
 		}
 	}
+	seenEntities := make(map[string]struct{}, len(config.Roles))
+	for idx, role := range config.Roles {
+		rolePath := fmt.Sprintf("%s.%s.%d", path, "roles", idx)
+		if err := role.Validate(rolePath); err != nil {
+			return err
+		}
+		if _, ok := seenEntities[role.Entity]; ok {
+			return resource.NewConfigValidationError(rolePath, errors.Errorf("entity %q already has a role", role.Entity))
+		}
+		seenEntities[role.Entity] = struct{}{}
+	}
+	seenLimits := make(map[RateLimitConfig]struct{}, len(config.RateLimits))
+	for idx, limit := range config.RateLimits {
+		limitPath := fmt.Sprintf("%s.%s.%d", path, "rate_limits", idx)
+		if err := limit.Validate(limitPath); err != nil {
+			return err
+		}
+		key := RateLimitConfig{Entity: limit.Entity, Class: limit.Class}
+		if _, ok := seenLimits[key]; ok {
+			return resource.NewConfigValidationError(limitPath, errors.Errorf(
+				"entity %q already has a rate limit for class %q", limit.Entity, limit.Class))
+		}
+		seenLimits[key] = struct{}{}
+	}
 	return nil
 }
 
@@ -1004,6 +1665,14 @@ func (config *AuthHandlerConfig) Validate(path string) error {
 		if len(config.Config.StringSlice("keys")) == 0 {
 			return resource.NewConfigValidationError(fmt.Sprintf("%s.config", path), errors.New("keys is required"))
 		}
+	case rutils.CredentialsTypeMTLS:
+		if _, err := ParseMTLSAuthConfig(*config); err != nil {
+			return resource.NewConfigValidationError(fmt.Sprintf("%s.config", path), err)
+		}
+	case rutils.CredentialsTypeOIDC:
+		if _, err := ParseOIDCAuthConfig(*config); err != nil {
+			return resource.NewConfigValidationError(fmt.Sprintf("%s.config", path), err)
+		}
 	case rpc.CredentialsTypeExternal:
 		return errors.New("robot cannot issue external auth tokens")
 	default:
@@ -1028,6 +1697,124 @@ func ParseAPIKeys(handler AuthHandlerConfig) map[string]string {
 	return apiKeys
 }
 
+// MTLSAuthConfig is the parsed config of an mTLS auth handler.
+type MTLSAuthConfig struct {
+	// ClientCAs verify the certificates presented by clients.
+	ClientCAs *x509.CertPool
+	// Entities maps the DNS subject alternative names of client certificates to the entities they
+	// authenticate as, which can be given roles. Certificates without a listed name are rejected.
+	Entities map[string]string
+}
+
+// ParseMTLSAuthConfig parses the config of an mTLS auth handler, loading its CA bundle. A sample
+// handler in JSON form is shown below, where "ca_file" may be replaced with the PEM encoded bundle
+// in "ca_pem".
+//
+//	{
+//		"type": "mtls",
+//		"config": {
+//			"ca_file": "/etc/viam/client-ca.pem",
+//			"entities": {
+//				"dashboard.example.com": "dashboard"
+//			}
+//		}
+//	}
+func ParseMTLSAuthConfig(handler AuthHandlerConfig) (*MTLSAuthConfig, error) {
+	caPEM := []byte(handler.Config.String("ca_pem"))
+	if caFile := handler.Config.String("ca_file"); caFile != "" {
+		if len(caPEM) != 0 {
+			return nil, errors.New("may only set one of ca_file or ca_pem")
+		}
+		var err error
+		if caPEM, err = os.ReadFile(caFile); err != nil {
+			return nil, errors.Wrap(err, "failed to read ca_file")
+		}
+	}
+	if len(caPEM) == 0 {
+		return nil, errors.New("ca_file or ca_pem is required")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("CA bundle contains no PEM encoded certificates")
+	}
+
+	rawEntities, ok := handler.Config["entities"].(map[string]interface{})
+	if !ok || len(rawEntities) == 0 {
+		return nil, errors.New("entities must map at least one certificate DNS name to an entity")
+	}
+	entities := make(map[string]string, len(rawEntities))
+	for name, rawEntity := range rawEntities {
+		entity, ok := rawEntity.(string)
+		if !ok || entity == "" {
+			return nil, errors.Errorf("entity for %q must be a non-empty string", name)
+		}
+		entities[name] = entity
+	}
+	return &MTLSAuthConfig{ClientCAs: pool, Entities: entities}, nil
+}
+
+// OIDCAuthConfig is the parsed config of an OIDC auth handler.
+type OIDCAuthConfig struct {
+	// Issuer is the URL of the OpenID Connect provider, which must support discovery.
+	Issuer string
+	// Audience must be one of the audiences of accepted access tokens.
+	Audience string
+	// EntityClaim is the claim of access tokens holding the entity they authenticate, which clients
+	// must authenticate as. It defaults to "sub".
+	EntityClaim string
+	// KeyRefreshInterval is the minimum time between refreshes of the provider's signing keys.
+	KeyRefreshInterval time.Duration
+}
+
+const (
+	defaultOIDCEntityClaim        = "sub"
+	defaultOIDCKeyRefreshInterval = 15 * time.Minute
+)
+
+// ParseOIDCAuthConfig parses the config of an OIDC auth handler. A sample handler in JSON form is
+// shown below. Clients authenticate as the value of the entity claim of their access token, using the
+// token as the credential payload.
+//
+//	{
+//		"type": "oidc",
+//		"config": {
+//			"issuer": "https://sso.example.com",
+//			"audience": "robot-access",
+//			"entity_claim": "email",
+//			"key_refresh_interval": "1h"
+//		}
+//	}
+func ParseOIDCAuthConfig(handler AuthHandlerConfig) (*OIDCAuthConfig, error) {
+	conf := &OIDCAuthConfig{
+		Issuer:             handler.Config.String("issuer"),
+		Audience:           handler.Config.String("audience"),
+		EntityClaim:        handler.Config.String("entity_claim"),
+		KeyRefreshInterval: defaultOIDCKeyRefreshInterval,
+	}
+	if conf.Issuer == "" {
+		return nil, errors.New("issuer is required")
+	}
+	if issuer, err := url.Parse(conf.Issuer); err != nil || (issuer.Scheme != "https" && issuer.Scheme != "http") {
+		return nil, errors.Errorf("issuer %q must be an http(s) URL", conf.Issuer)
+	}
+	if conf.Audience == "" {
+		return nil, errors.New("audience is required so that tokens issued for other applications are rejected")
+	}
+	if conf.EntityClaim == "" {
+		conf.EntityClaim = defaultOIDCEntityClaim
+	}
+	if interval := handler.Config.String("key_refresh_interval"); interval != "" {
+		var err error
+		if conf.KeyRefreshInterval, err = time.ParseDuration(interval); err != nil {
+			return nil, errors.Wrap(err, "invalid key_refresh_interval")
+		}
+		if conf.KeyRefreshInterval <= 0 {
+			return nil, errors.New("key_refresh_interval must be positive")
+		}
+	}
+	return conf, nil
+}
+
 // CreateTLSWithCert creates a tls.Config with the TLS certificate to be returned.
 func CreateTLSWithCert(cfg *Config) (*tls.Config, error) {
 	cert, err := tls.X509KeyPair([]byte(cfg.Cloud.TLSCertificate), []byte(cfg.Cloud.TLSPrivateKey))
@@ -1095,6 +1882,12 @@ type PackageConfig struct {
 	Version string `json:"version,omitempty"`
 	// Types of the Package.
 	Type PackageType `json:"type"`
+	// OCI pulls the package from an OCI registry instead of the package service. Credentials for the
+	// registry are looked up in the package_registries of the robot config.
+	OCI *PackageOCISource `json:"oci,omitempty"`
+	// Verification is checked against the package archive before it is unpacked. A package that fails
+	// verification is not installed, so modules and ML models that use it are not loaded.
+	Verification *PackageVerification `json:"verification,omitempty"`
 
 	Status *AppValidationStatus `json:"status,omitempty"`
 
@@ -1137,6 +1930,16 @@ func (p *PackageConfig) validate(path string) error {
 		return resource.NewConfigValidationError(path, err)
 	}
 
+	if p.OCI != nil {
+		if err := p.OCI.validate(path + ".oci"); err != nil {
+			return err
+		}
+	}
+
+	if p.Verification != nil {
+		return p.Verification.validate(path + ".verification")
+	}
+
 	return nil
 }
 
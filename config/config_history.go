@@ -0,0 +1,210 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/artifact"
+
+	"go.viam.com/rdk/logging"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// States of a config in a ConfigHistory.
+const (
+	// ConfigStatePending is a config that has not yet been checked against the health criteria.
+	ConfigStatePending = "pending"
+	// ConfigStateGood is a config the machine ran while meeting the health criteria.
+	ConfigStateGood = "good"
+	// ConfigStateBad is a config the machine was rolled back from.
+	ConfigStateBad = "bad"
+)
+
+// ErrNoGoodConfig is returned when there is no known-good config to roll back to.
+var ErrNoGoodConfig = errors.New("no known-good config to roll back to")
+
+// A ConfigHistoryEntry is a cloud config kept in a ConfigHistory.
+type ConfigHistoryEntry struct {
+	Revision string    `json:"revision"`
+	State    string    `json:"state"`
+	Recorded time.Time `json:"recorded"`
+	// Config is the unprocessed config, as it is cached.
+	Config json.RawMessage `json:"config"`
+}
+
+// A ConfigHistory keeps the cloud configs most recently applied to a machine on disk along with
+// whether each proved to be good, so that the machine can roll back to a known-good config. A config
+// is recorded before it is applied, so a config that leaves the machine unable to run is still known
+// after a restart.
+type ConfigHistory struct {
+	path string
+	size int
+
+	mu      sync.Mutex
+	entries []ConfigHistoryEntry
+}
+
+func getConfigHistoryFilePath(id string) string {
+	return filepath.Join(rutils.ViamDotDir, fmt.Sprintf("cloud_config_history_%s.json", id))
+}
+
+// OpenConfigHistory returns the config history of the machine with the given cloud ID, which keeps up
+// to size known-good configs. A history that cannot be read is discarded.
+func OpenConfigHistory(id string, size int, logger logging.Logger) *ConfigHistory {
+	h := &ConfigHistory{path: getConfigHistoryFilePath(id), size: max(size, 1)}
+	data, err := os.ReadFile(h.path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		logger.Warnw("cannot read the config history, starting a new one", "error", err)
+	default:
+		if err := json.Unmarshal(data, &h.entries); err != nil {
+			logger.Warnw("cannot parse the config history, starting a new one", "error", err)
+			h.entries = nil
+		}
+	}
+	return h
+}
+
+// Record records cfg, which must have been read from the cloud, as pending unless its revision is
+// already known. It is a no-op for configs not read from the cloud.
+func (h *ConfigHistory) Record(cfg *Config) error {
+	if cfg.toCache == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i := h.find(cfg.Revision); i >= 0 {
+		h.entries[i].Config = cfg.toCache
+		return h.store()
+	}
+	h.entries = append(h.entries, ConfigHistoryEntry{
+		Revision: cfg.Revision,
+		State:    ConfigStatePending,
+		Recorded: time.Now(),
+		Config:   cfg.toCache,
+	})
+	h.prune()
+	return h.store()
+}
+
+// State returns the state of the config with the given revision, or the empty string if it is not
+// in the history.
+func (h *ConfigHistory) State(revision string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i := h.find(revision); i >= 0 {
+		return h.entries[i].State
+	}
+	return ""
+}
+
+// MarkGood records that the config with the given revision is good.
+func (h *ConfigHistory) MarkGood(revision string) error {
+	return h.mark(revision, ConfigStateGood)
+}
+
+// MarkBad records that the config with the given revision is bad.
+func (h *ConfigHistory) MarkBad(revision string) error {
+	return h.mark(revision, ConfigStateBad)
+}
+
+func (h *ConfigHistory) mark(revision, state string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := h.find(revision)
+	if i < 0 {
+		return errors.Errorf("config revision %q is not in the history", revision)
+	}
+	h.entries[i].State = state
+	h.prune()
+	return h.store()
+}
+
+// Entries returns the configs in the history from oldest to newest.
+func (h *ConfigHistory) Entries() []ConfigHistoryEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]ConfigHistoryEntry(nil), h.entries...)
+}
+
+// LastGood returns the newest known-good config, processed and ready to apply, with its cloud section
+// replaced by cloud so that it keeps the machine's current credentials. It returns ErrNoGoodConfig if
+// there is none.
+func (h *ConfigHistory) LastGood(cloud *Cloud, logger logging.Logger) (*Config, error) {
+	h.mu.Lock()
+	var entry ConfigHistoryEntry
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].State == ConfigStateGood {
+			entry = h.entries[i]
+			break
+		}
+	}
+	h.mu.Unlock()
+	if entry.State != ConfigStateGood {
+		return nil, ErrNoGoodConfig
+	}
+
+	unprocessedConfig := &Config{}
+	if err := json.Unmarshal(entry.Config, unprocessedConfig); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse config revision %q from the history", entry.Revision)
+	}
+	cfg, err := processConfigFromCloud(unprocessedConfig, logger)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot process config revision %q from the history", entry.Revision)
+	}
+	if cloud != nil && cfg.Cloud != nil {
+		*cfg.Cloud = *cloud
+	}
+	// the cache is restored to the known-good config once it is applied.
+	cfg.toCache = entry.Config
+	return cfg, nil
+}
+
+// find must be called with mu held.
+func (h *ConfigHistory) find(revision string) int {
+	for i := range h.entries {
+		if h.entries[i].Revision == revision {
+			return i
+		}
+	}
+	return -1
+}
+
+// prune keeps the newest size known-good configs and the newest size other configs. It must be
+// called with mu held.
+func (h *ConfigHistory) prune() {
+	var good, other int
+	kept := make([]ConfigHistoryEntry, 0, len(h.entries))
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		count := &other
+		if h.entries[i].State == ConfigStateGood {
+			count = &good
+		}
+		if *count < h.size {
+			*count++
+			kept = append(kept, h.entries[i])
+		}
+	}
+	slices.Reverse(kept)
+	h.entries = kept
+}
+
+// store must be called with mu held.
+func (h *ConfigHistory) store() error {
+	data, err := json.Marshal(h.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	return artifact.AtomicStore(h.path, bytes.NewReader(data), filepath.Base(h.path))
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestConfigHistory(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	// NOTE: not parallel — this test redirects the global utils.ViamDotDir.
+	prevDotDir := rutils.ViamDotDir
+	rutils.ViamDotDir = t.TempDir()
+	defer func() {
+		rutils.ViamDotDir = prevDotDir
+	}()
+
+	cloudConfig := func(revision string) *Config {
+		unprocessed := &Config{
+			Revision: revision,
+			Cloud:    &Cloud{ID: "history", FQDN: "fqdn", LocalFQDN: "local-fqdn"},
+			Components: []resource.Config{{
+				Name:  "arm-" + revision,
+				API:   resource.APINamespaceRDK.WithComponentType("arm"),
+				Model: resource.DefaultModelFamily.WithModel("fake"),
+			}},
+		}
+		cfg := &Config{Revision: revision}
+		test.That(t, cfg.SetToCache(unprocessed), test.ShouldBeNil)
+		return cfg
+	}
+
+	h := OpenConfigHistory("history", 2, logger)
+	_, err := h.LastGood(nil, logger)
+	test.That(t, err, test.ShouldBeError, ErrNoGoodConfig)
+	// configs not read from the cloud are not recorded.
+	test.That(t, h.Record(&Config{Revision: "local"}), test.ShouldBeNil)
+	test.That(t, h.State("local"), test.ShouldBeEmpty)
+	test.That(t, h.MarkGood("local"), test.ShouldNotBeNil)
+
+	test.That(t, h.Record(cloudConfig("1")), test.ShouldBeNil)
+	test.That(t, h.State("1"), test.ShouldEqual, ConfigStatePending)
+	test.That(t, h.MarkGood("1"), test.ShouldBeNil)
+	test.That(t, h.Record(cloudConfig("2")), test.ShouldBeNil)
+	test.That(t, h.MarkBad("2"), test.ShouldBeNil)
+	// recording a known config again keeps its state.
+	test.That(t, h.Record(cloudConfig("2")), test.ShouldBeNil)
+	test.That(t, h.State("2"), test.ShouldEqual, ConfigStateBad)
+
+	current := &Cloud{ID: "history", FQDN: "fqdn", LocalFQDN: "local-fqdn", Secret: "current"}
+	good, err := h.LastGood(current, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, good.Revision, test.ShouldEqual, "1")
+	test.That(t, good.Components, test.ShouldHaveLength, 1)
+	test.That(t, good.Components[0].Name, test.ShouldEqual, "arm-1")
+	test.That(t, good.Cloud.Secret, test.ShouldEqual, "current")
+	test.That(t, good.toCache, test.ShouldNotBeNil)
+
+	// the history survives a restart.
+	h = OpenConfigHistory("history", 2, logger)
+	test.That(t, h.State("1"), test.ShouldEqual, ConfigStateGood)
+	test.That(t, h.State("2"), test.ShouldEqual, ConfigStateBad)
+
+	// only the newest known-good configs and the newest other configs are kept.
+	for _, revision := range []string{"3", "4"} {
+		test.That(t, h.Record(cloudConfig(revision)), test.ShouldBeNil)
+		test.That(t, h.MarkGood(revision), test.ShouldBeNil)
+	}
+	test.That(t, h.Record(cloudConfig("5")), test.ShouldBeNil)
+	revisions := []string{}
+	for _, entry := range h.Entries() {
+		revisions = append(revisions, entry.Revision)
+	}
+	test.That(t, revisions, test.ShouldResemble, []string{"2", "3", "4", "5"})
+	good, err = h.LastGood(nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, good.Revision, test.ShouldEqual, "4")
+
+	// an unreadable history is discarded.
+	test.That(t, os.WriteFile(getConfigHistoryFilePath("history"), []byte("{"), 0o600), test.ShouldBeNil)
+	h = OpenConfigHistory("history", 2, logger)
+	test.That(t, h.Entries(), test.ShouldBeEmpty)
+}
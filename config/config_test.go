@@ -460,6 +460,125 @@ func TestConfigEnsure(t *testing.T) {
 	}
 
 	test.That(t, invalidAuthConfig.Ensure(false, logger), test.ShouldBeNil)
+
+	invalidEmergencyStop := config.Config{EmergencyStop: &config.EmergencyStopConfig{Pin: "37"}}
+	err = invalidEmergencyStop.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "board")
+	invalidEmergencyStop.EmergencyStop = &config.EmergencyStopConfig{BoardName: "board1"}
+	err = invalidEmergencyStop.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "pin")
+	invalidEmergencyStop.EmergencyStop.Pin = "37"
+	test.That(t, invalidEmergencyStop.Ensure(false, logger), test.ShouldBeNil)
+
+	invalidMaintenance := config.Config{MaintenanceConfig: &config.MaintenanceConfig{
+		Windows: []config.MaintenanceWindow{{Start: "01:00", End: "03:00"}, {Days: []string{"funday"}}},
+	}}
+	err = invalidMaintenance.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "maintenance.windows.1")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "funday")
+	invalidMaintenance.MaintenanceConfig.Windows[1] = config.MaintenanceWindow{Days: []string{"Sun"}, Start: "22:00"}
+	err = invalidMaintenance.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "end")
+	invalidMaintenance.MaintenanceConfig.Windows[1].End = "25:00"
+	test.That(t, invalidMaintenance.Ensure(false, logger), test.ShouldNotBeNil)
+	invalidMaintenance.MaintenanceConfig.Windows[1].End = "02:00"
+	invalidMaintenance.MaintenanceConfig.Windows[1].Timezone = "Mars/Olympus_Mons"
+	test.That(t, invalidMaintenance.Ensure(false, logger), test.ShouldNotBeNil)
+	invalidMaintenance.MaintenanceConfig.Windows[1].Timezone = "America/New_York"
+	test.That(t, invalidMaintenance.Ensure(false, logger), test.ShouldBeNil)
+
+	invalidShutdown := config.Config{Shutdown: &config.ShutdownConfig{
+		Resources: []config.ShutdownResourceConfig{{Name: "arm1", SafeState: config.SafeStateHome}, {SafeState: "park"}},
+	}}
+	err = invalidShutdown.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "name")
+	invalidShutdown.Shutdown.Resources[1].Name = "arm1"
+	err = invalidShutdown.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "more than once")
+	invalidShutdown.Shutdown.Resources[1].Name = "base1"
+	err = invalidShutdown.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "shutdown.resources.1")
+	invalidShutdown.Shutdown.Resources[1].SafeState = config.SafeStateCommand
+	err = invalidShutdown.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "command")
+	invalidShutdown.Shutdown.Resources[1].Command = map[string]interface{}{"brake": true}
+	invalidShutdown.Shutdown.ResourceTimeoutMS = -1
+	test.That(t, invalidShutdown.Ensure(false, logger), test.ShouldNotBeNil)
+	invalidShutdown.Shutdown.ResourceTimeoutMS = 5000
+	test.That(t, invalidShutdown.Ensure(false, logger), test.ShouldBeNil)
+
+	invalidConcurrency := config.Config{ResourceConfigurationConcurrency: -1}
+	err = invalidConcurrency.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "resource_configuration_concurrency")
+	invalidConcurrency.ResourceConfigurationConcurrency = 4
+	test.That(t, invalidConcurrency.Ensure(false, logger), test.ShouldBeNil)
+
+	invalidNetAppender := config.Config{NetAppender: &logging.NetAppenderConfig{OverflowPolicy: "drop_newest"}}
+	err = invalidNetAppender.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "net_appender")
+	invalidNetAppender.NetAppender.OverflowPolicy = logging.NetAppenderBlock
+	test.That(t, invalidNetAppender.Ensure(false, logger), test.ShouldBeNil)
+
+	networkTracing := config.TracingConfig{Enabled: true, OTLPEndpoint: "localhost:4317"}
+	tracingInNetwork := config.Config{}
+	tracingInNetwork.Network.Tracing = &networkTracing
+	test.That(t, tracingInNetwork.Ensure(false, logger), test.ShouldBeNil)
+	test.That(t, tracingInNetwork.Tracing, test.ShouldResemble, networkTracing)
+	test.That(t, tracingInNetwork.Ensure(false, logger), test.ShouldBeNil)
+	tracingInNetwork.Tracing = config.TracingConfig{Enabled: true, Console: true}
+	err = tracingInNetwork.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "network.tracing")
+
+	invalidTunnel := config.Config{}
+	invalidTunnel.Network.TrafficTunnelEndpoints = []config.TrafficTunnelEndpoint{
+		{Port: 9090},
+		{Port: 8554, Protocol: "sctp"},
+	}
+	err = invalidTunnel.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "network.traffic_tunnel_endpoints.1")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "protocol")
+	invalidTunnel.Network.TrafficTunnelEndpoints[1].Protocol = config.TunnelProtocolUDP
+	test.That(t, invalidTunnel.Ensure(false, logger), test.ShouldBeNil)
+	invalidTunnel.Network.TrafficTunnelEndpoints[0].Port = 0
+	err = invalidTunnel.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid port")
+
+	invalidMediaServer := config.Config{}
+	invalidMediaServer.Network.MediaServer = &config.MediaServerConfig{}
+	err = invalidMediaServer.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "network.media_server")
+	invalidMediaServer.Network.MediaServer.RTSPAddress = "8554"
+	err = invalidMediaServer.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid address")
+	invalidMediaServer.Network.MediaServer.RTSPAddress = ":8554"
+	invalidMediaServer.Network.MediaServer.Username = "viewer"
+	err = invalidMediaServer.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "password")
+	invalidMediaServer.Network.MediaServer.Password = "secret"
+	test.That(t, invalidMediaServer.Ensure(false, logger), test.ShouldBeNil)
+	invalidMediaServer.Network.MediaServer.Username = ""
+	invalidMediaServer.Network.MediaServer.Password = ""
+	err = invalidMediaServer.Ensure(false, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "allow_unauthenticated")
+	invalidMediaServer.Network.MediaServer.AllowUnauthenticated = true
+	test.That(t, invalidMediaServer.Ensure(false, logger), test.ShouldBeNil)
 }
 
 func TestRemoteValidate(t *testing.T) {
@@ -490,6 +609,103 @@ func TestRemoteValidate(t *testing.T) {
 			"must start with a letter or number and must only contain letters, numbers, dashes, and underscores",
 		)
 	})
+
+	t.Run("failover addresses", func(t *testing.T) {
+		var remote config.Remote
+		test.That(t, json.Unmarshal([]byte(`{
+			"name": "foo",
+			"address": "foo.local:8080",
+			"addresses": [
+				{"address": "foo-main.viam.cloud", "priority": 2},
+				{"address": "10.0.0.2:8080", "priority": 1},
+				{"address": "foo-backup.viam.cloud", "priority": 2}
+			],
+			"failover_threshold": 3,
+			"failback_interval": "30s",
+			"clock_sync_interval": "1m"
+		}`), &remote), test.ShouldBeNil)
+		_, _, err := remote.Validate("path")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, remote.FailoverThreshold, test.ShouldEqual, 3)
+		test.That(t, remote.FailbackInterval, test.ShouldEqual, 30*time.Second)
+		test.That(t, remote.ClockSyncInterval, test.ShouldEqual, time.Minute)
+		test.That(t, remote.FailoverAddresses(), test.ShouldResemble,
+			[]string{"10.0.0.2:8080", "foo-main.viam.cloud", "foo-backup.viam.cloud"})
+
+		marshaled, err := json.Marshal(remote)
+		test.That(t, err, test.ShouldBeNil)
+		var roundTripped config.Remote
+		test.That(t, json.Unmarshal(marshaled, &roundTripped), test.ShouldBeNil)
+		test.That(t, roundTripped.Equals(remote), test.ShouldBeTrue)
+
+		duplicate := config.Remote{Name: "foo", Address: "foo.local:8080", Addresses: []config.RemoteAddress{{Address: "foo.local:8080"}}}
+		_, _, err = duplicate.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "duplicate address")
+
+		negative := config.Remote{Name: "foo", Address: "foo.local:8080", Addresses: []config.RemoteAddress{{Address: "bar", Priority: -1}}}
+		_, _, err = negative.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "priority cannot be negative")
+	})
+
+	t.Run("unix socket address", func(t *testing.T) {
+		remote := config.Remote{Name: "foo", Address: "unix:/run/viam/foo.sock"}
+		_, _, err := remote.Validate("path")
+		test.That(t, err, test.ShouldBeNil)
+
+		noPath := config.Remote{Name: "foo", Address: "foo.local:8080", Addresses: []config.RemoteAddress{{Address: "unix:"}}}
+		_, _, err = noPath.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "missing the path of the socket")
+	})
+
+	t.Run("resources", func(t *testing.T) {
+		var remote config.Remote
+		test.That(t, json.Unmarshal([]byte(`{
+			"name": "foo",
+			"address": "foo.local:8080",
+			"resources": {
+				"include": [{"api": "rdk:component:camera"}, {"name": "arm*"}],
+				"exclude": [{"api": "rdk:component:camera", "name": "*_debug"}],
+				"proxy_streams": false
+			}
+		}`), &remote), test.ShouldBeNil)
+		_, _, err := remote.Validate("path")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, remote.Resources.ProxiesStreams(), test.ShouldBeFalse)
+
+		test.That(t, remote.Resources.Imports(camera.Named("front")), test.ShouldBeTrue)
+		test.That(t, remote.Resources.Imports(camera.Named("front_debug")), test.ShouldBeFalse)
+		test.That(t, remote.Resources.Imports(arm.Named("arm1")), test.ShouldBeTrue)
+		test.That(t, remote.Resources.Imports(base.Named("base1")), test.ShouldBeFalse)
+
+		var unfiltered *config.RemoteResources
+		test.That(t, unfiltered.Imports(base.Named("base1")), test.ShouldBeTrue)
+		test.That(t, unfiltered.ProxiesStreams(), test.ShouldBeTrue)
+
+		marshaled, err := json.Marshal(remote)
+		test.That(t, err, test.ShouldBeNil)
+		var roundTripped config.Remote
+		test.That(t, json.Unmarshal(marshaled, &roundTripped), test.ShouldBeNil)
+		test.That(t, roundTripped.Resources, test.ShouldResemble, remote.Resources)
+
+		empty := config.Remote{
+			Name: "foo", Address: "foo.local:8080",
+			Resources: &config.RemoteResources{Include: []config.RemoteResourceMatcher{{}}},
+		}
+		_, _, err = empty.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "must specify an api, a name, or both")
+
+		invalid := config.Remote{
+			Name: "foo", Address: "foo.local:8080",
+			Resources: &config.RemoteResources{Exclude: []config.RemoteResourceMatcher{{Name: "[cam"}}},
+		}
+		_, _, err = invalid.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "invalid name pattern")
+	})
 }
 
 func TestCopyOnlyPublicFields(t *testing.T) {
@@ -810,6 +1026,107 @@ func TestAuthConfigEnsure(t *testing.T) {
 		test.That(t, err, test.ShouldNotBeNil)
 		test.That(t, err.Error(), test.ShouldContainSubstring, "must contain at least 1 key")
 	})
+
+	t.Run("mtls handler", func(t *testing.T) {
+		logger := logging.NewTestLogger(t)
+		handler := config.AuthHandlerConfig{Type: rutils.CredentialsTypeMTLS, Config: rutils.AttributeMap{}}
+		config := config.Config{Auth: config.AuthConfig{Handlers: []config.AuthHandlerConfig{handler}}}
+
+		err := config.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "ca_file or ca_pem is required")
+
+		handler.Config["ca_file"] = filepath.Join(t.TempDir(), "missing.pem")
+		err = config.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "failed to read ca_file")
+
+		delete(handler.Config, "ca_file")
+		handler.Config["ca_pem"] = "not a certificate"
+		err = config.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no PEM encoded certificates")
+	})
+
+	t.Run("oidc handler", func(t *testing.T) {
+		logger := logging.NewTestLogger(t)
+		handler := config.AuthHandlerConfig{
+			Type:   rutils.CredentialsTypeOIDC,
+			Config: rutils.AttributeMap{"issuer": "sso.example.com"},
+		}
+		cfg := config.Config{Auth: config.AuthConfig{Handlers: []config.AuthHandlerConfig{handler}}}
+
+		err := cfg.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "must be an http(s) URL")
+
+		handler.Config["issuer"] = "https://sso.example.com"
+		err = cfg.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "audience is required")
+
+		handler.Config["audience"] = "robots"
+		handler.Config["key_refresh_interval"] = "-1m"
+		err = cfg.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "key_refresh_interval must be positive")
+
+		handler.Config["key_refresh_interval"] = "1h"
+		test.That(t, cfg.Ensure(true, logger), test.ShouldBeNil)
+		oidcConf, err := config.ParseOIDCAuthConfig(handler)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, oidcConf.EntityClaim, test.ShouldEqual, "sub")
+		test.That(t, oidcConf.KeyRefreshInterval, test.ShouldEqual, time.Hour)
+	})
+
+	t.Run("roles", func(t *testing.T) {
+		logger := logging.NewTestLogger(t)
+		config := config.Config{
+			Auth: config.AuthConfig{
+				Roles: []config.AuthRoleConfig{
+					{Entity: "abc123", Role: config.AuthRoleViewer, Resources: []string{"camera1"}},
+					{Entity: "def456", Role: config.AuthRoleOperator},
+				},
+			},
+		}
+		test.That(t, config.Ensure(true, logger), test.ShouldBeNil)
+
+		config.Auth.Roles[1].Entity = "abc123"
+		err := config.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `entity "abc123" already has a role`)
+
+		config.Auth.Roles[1].Role = "superuser"
+		err = config.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `unknown role "superuser"`)
+
+		config.Auth.Roles[1].Entity = ""
+		err = config.Ensure(true, logger)
+		test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "entity")
+	})
+
+	t.Run("rate limits", func(t *testing.T) {
+		logger := logging.NewTestLogger(t)
+		cfg := config.Config{
+			Auth: config.AuthConfig{
+				RateLimits: []config.RateLimitConfig{
+					{RequestsPerSec: 50},
+					{Entity: "abc123", Class: config.APIMethodClassWrite, RequestsPerSec: 5, Burst: 10},
+					{Entity: "abc123", Class: config.APIMethodClassRead, MaxConcurrentStreams: 2},
+				},
+			},
+		}
+		test.That(t, cfg.Ensure(true, logger), test.ShouldBeNil)
+
+		cfg.Auth.RateLimits[2].Class = config.APIMethodClassWrite
+		err := cfg.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `entity "abc123" already has a rate limit for class "write"`)
+
+		cfg.Auth.RateLimits[2].Class = "everything"
+		err = cfg.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `unknown class "everything"`)
+
+		cfg.Auth.RateLimits[2] = config.RateLimitConfig{Entity: "abc123", Burst: 3}
+		err = cfg.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "burst requires requests_per_sec")
+
+		cfg.Auth.RateLimits[2] = config.RateLimitConfig{Entity: "abc123"}
+		err = cfg.Ensure(true, logger)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "requests_per_sec or max_concurrent_streams is required")
+	})
 }
 
 func TestValidateUniqueNames(t *testing.T) {
@@ -1042,6 +1359,74 @@ func TestJobsConfig(t *testing.T) {
 	}
 }
 
+func TestMaintenanceWindows(t *testing.T) {
+	// a Wednesday.
+	wed := time.Date(2024, time.January, 3, 12, 0, 0, 0, time.UTC)
+
+	var noWindows *config.MaintenanceConfig
+	test.That(t, noWindows.WindowOpen(wed), test.ShouldBeTrue)
+	test.That(t, noWindows.NextWindowOpen(wed), test.ShouldEqual, wed)
+
+	daily := config.MaintenanceWindow{Start: "02:00", End: "04:00", Timezone: "UTC"}
+	test.That(t, daily.Contains(wed), test.ShouldBeFalse)
+	test.That(t, daily.Contains(wed.Add(-9*time.Hour)), test.ShouldBeTrue)
+	test.That(t, daily.Contains(wed.Add(-8*time.Hour)), test.ShouldBeFalse)
+	test.That(t, daily.NextOpen(wed), test.ShouldEqual, wed.Add(14*time.Hour))
+	test.That(t, daily.NextOpen(wed.Add(-11*time.Hour)), test.ShouldEqual, wed.Add(-10*time.Hour))
+
+	// opens Friday and Saturday nights, closing the following mornings.
+	weekend := config.MaintenanceWindow{Days: []string{"friday", "SAT"}, Start: "22:00", End: "06:00", Timezone: "UTC"}
+	fri := wed.AddDate(0, 0, 2)
+	test.That(t, weekend.Contains(fri), test.ShouldBeFalse)
+	test.That(t, weekend.Contains(fri.Add(11*time.Hour)), test.ShouldBeTrue)
+	test.That(t, weekend.Contains(fri.Add(17*time.Hour)), test.ShouldBeTrue)
+	test.That(t, weekend.Contains(fri.Add(41*time.Hour)), test.ShouldBeTrue)
+	test.That(t, weekend.Contains(fri.Add(65*time.Hour)), test.ShouldBeFalse)
+	test.That(t, weekend.NextOpen(wed), test.ShouldEqual, fri.Add(10*time.Hour))
+	test.That(t, weekend.NextOpen(fri.Add(20*time.Hour)), test.ShouldEqual, fri.Add(34*time.Hour))
+
+	// times of day are in the window's timezone.
+	ny, err := time.LoadLocation("America/New_York")
+	test.That(t, err, test.ShouldBeNil)
+	local := config.MaintenanceWindow{Start: "07:00", End: "07:00", Timezone: ny.String()}
+	test.That(t, local.Contains(wed), test.ShouldBeTrue)
+	morning := config.MaintenanceWindow{Start: "07:00", End: "08:00", Timezone: ny.String()}
+	test.That(t, morning.Contains(wed), test.ShouldBeTrue)
+	test.That(t, morning.Contains(wed.Add(-time.Hour)), test.ShouldBeFalse)
+
+	mc := &config.MaintenanceConfig{Windows: []config.MaintenanceWindow{weekend, daily}}
+	test.That(t, mc.WindowOpen(wed), test.ShouldBeFalse)
+	test.That(t, mc.WindowOpen(wed.Add(15*time.Hour)), test.ShouldBeTrue)
+	test.That(t, mc.NextWindowOpen(wed), test.ShouldEqual, wed.Add(14*time.Hour))
+}
+
+func TestStagedRollout(t *testing.T) {
+	var none *config.StagedRolloutConfig
+	test.That(t, none.RequiresAck("rev1+staged"), test.ShouldBeFalse)
+
+	staged := &config.StagedRolloutConfig{RequireAck: true}
+	test.That(t, staged.Validate("cloud.staged_rollout"), test.ShouldBeNil)
+	test.That(t, staged.IsStaged("rev1"), test.ShouldBeFalse)
+	test.That(t, staged.IsStaged("staged"), test.ShouldBeFalse)
+	test.That(t, staged.IsStaged("rev1+canary+staged"), test.ShouldBeTrue)
+	test.That(t, staged.RequiresAck("rev1"), test.ShouldBeFalse)
+	test.That(t, staged.RequiresAck("rev1+staged"), test.ShouldBeTrue)
+
+	staged.Tag = "canary"
+	test.That(t, staged.RequiresAck("rev1+staged"), test.ShouldBeFalse)
+	test.That(t, staged.RequiresAck("rev1+canary"), test.ShouldBeTrue)
+	test.That(t, (&config.StagedRolloutConfig{RequireAck: false}).RequiresAck("rev1+staged"), test.ShouldBeFalse)
+
+	pinned := &config.StagedRolloutConfig{PinnedRevision: "rev1+staged"}
+	test.That(t, pinned.RequiresAck("rev1+staged"), test.ShouldBeFalse)
+	test.That(t, pinned.RequiresAck("rev2"), test.ShouldBeTrue)
+
+	invalid := &config.Cloud{ID: "machine", Secret: "secret", StagedRollout: &config.StagedRolloutConfig{Tag: "a+b"}}
+	err := invalid.Validate("cloud", false)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cloud.staged_rollout")
+}
+
 func TestConfigRobotWebProfile(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 	cfg, err := config.Read(context.Background(), "data/config_with_web_profile.json", logger, nil)
@@ -1141,6 +1526,11 @@ func TestConfigJSONMarshalRoundtrip(t *testing.T) {
 							{
 								Port: 23654,
 							},
+							{
+								Port:            8554,
+								Protocol:        config.TunnelProtocolUDP,
+								AllowedEntities: []string{"some-entity"},
+							},
 						},
 					},
 				},
@@ -1160,6 +1550,11 @@ func TestConfigJSONMarshalRoundtrip(t *testing.T) {
 							{
 								Port: 23654,
 							},
+							{
+								Port:            8554,
+								Protocol:        config.TunnelProtocolUDP,
+								AllowedEntities: []string{"some-entity"},
+							},
 						},
 					},
 				},
@@ -4,7 +4,9 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"reflect"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"go.viam.com/utils/pexec"
@@ -128,52 +130,6 @@ func prettyDiff(left, right Config) (string, error) {
 	left = leftClone
 	right = rightClone
 
-	mask := "******"
-	sanitizeConfig := func(conf *Config) {
-		// Note(erd): keep in mind this will destroy the actual pretty diffing of these which
-		// is fine because we aren't considering pretty diff changes to these fields at this level
-		// of the stack.
-		if conf.Cloud != nil {
-			if conf.Cloud.Secret != "" {
-				conf.Cloud.Secret = mask
-			}
-			if conf.Cloud.LocationSecret != "" {
-				conf.Cloud.LocationSecret = mask
-			}
-			for i := range conf.Cloud.LocationSecrets {
-				if conf.Cloud.LocationSecrets[i].Secret != "" {
-					conf.Cloud.LocationSecrets[i].Secret = mask
-				}
-			}
-			if conf.Cloud.APIKey.Key != "" {
-				conf.Cloud.APIKey.Key = mask
-			}
-			// Not really a secret but annoying to diff
-			if conf.Cloud.TLSCertificate != "" {
-				conf.Cloud.TLSCertificate = mask
-			}
-			if conf.Cloud.TLSPrivateKey != "" {
-				conf.Cloud.TLSPrivateKey = mask
-			}
-		}
-		for _, hdlr := range conf.Auth.Handlers {
-			for key := range hdlr.Config {
-				hdlr.Config[key] = mask
-			}
-		}
-		for i := range conf.Remotes {
-			rem := &conf.Remotes[i]
-			if rem.Secret != "" {
-				rem.Secret = mask
-			}
-			if rem.Auth.Credentials != nil {
-				rem.Auth.Credentials.Payload = mask
-			}
-			if rem.Auth.SignalingCreds != nil {
-				rem.Auth.SignalingCreds.Payload = mask
-			}
-		}
-	}
 	sanitizeConfig(&left)
 	sanitizeConfig(&right)
 
@@ -198,6 +154,121 @@ func prettyDiff(left, right Config) (string, error) {
 	return dmp.DiffPrettyText(filteredDiffs), nil
 }
 
+// sanitizedMask replaces the secrets of sanitized configs.
+const sanitizedMask = "******"
+
+// sanitizeConfig masks the secrets of conf in place.
+func sanitizeConfig(conf *Config) {
+	// Note(erd): keep in mind this will destroy the actual pretty diffing of these which
+	// is fine because we aren't considering pretty diff changes to these fields at this level
+	// of the stack.
+	if conf.Cloud != nil {
+		if conf.Cloud.Secret != "" {
+			conf.Cloud.Secret = sanitizedMask
+		}
+		if conf.Cloud.LocationSecret != "" {
+			conf.Cloud.LocationSecret = sanitizedMask
+		}
+		for i := range conf.Cloud.LocationSecrets {
+			if conf.Cloud.LocationSecrets[i].Secret != "" {
+				conf.Cloud.LocationSecrets[i].Secret = sanitizedMask
+			}
+		}
+		if conf.Cloud.APIKey.Key != "" {
+			conf.Cloud.APIKey.Key = sanitizedMask
+		}
+		// Not really a secret but annoying to diff
+		if conf.Cloud.TLSCertificate != "" {
+			conf.Cloud.TLSCertificate = sanitizedMask
+		}
+		if conf.Cloud.TLSPrivateKey != "" {
+			conf.Cloud.TLSPrivateKey = sanitizedMask
+		}
+	}
+	if conf.Network.MediaServer != nil && conf.Network.MediaServer.Password != "" {
+		conf.Network.MediaServer.Password = sanitizedMask
+	}
+	for _, hdlr := range conf.Auth.Handlers {
+		for key := range hdlr.Config {
+			hdlr.Config[key] = sanitizedMask
+		}
+	}
+	for i := range conf.Remotes {
+		rem := &conf.Remotes[i]
+		if rem.Secret != "" {
+			rem.Secret = sanitizedMask
+		}
+		if rem.Auth.Credentials != nil {
+			rem.Auth.Credentials.Payload = sanitizedMask
+		}
+		if rem.Auth.SignalingCreds != nil {
+			rem.Auth.SignalingCreds.Payload = sanitizedMask
+		}
+	}
+}
+
+// sensitiveAttributeNameParts are the parts of the names of resource attributes whose values
+// SanitizedCopy masks, since resources such as the MQTT bridge take credentials as attributes.
+var sensitiveAttributeNameParts = []string{"password", "secret", "token", "key", "credential"}
+
+// sanitizeAttributes masks the values of attrs whose names look like they hold secrets, including
+// those of nested attributes.
+func sanitizeAttributes(attrs map[string]interface{}) {
+	for name, value := range attrs {
+		lowerName := strings.ToLower(name)
+		if slices.ContainsFunc(sensitiveAttributeNameParts, func(part string) bool {
+			return strings.Contains(lowerName, part)
+		}) {
+			attrs[name] = sanitizedMask
+			continue
+		}
+		sanitizeAttributeValue(value)
+	}
+}
+
+func sanitizeAttributeValue(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		sanitizeAttributes(v)
+	case []interface{}:
+		for _, elem := range v {
+			sanitizeAttributeValue(elem)
+		}
+	}
+}
+
+// SanitizedCopy returns a copy of conf with its secrets masked, including the passwords of its
+// package registries, the values of the environment variables of its modules, and resource
+// attributes named like secrets, which often hold credentials, so that it can be shared.
+func SanitizedCopy(conf *Config) (*Config, error) {
+	md, err := json.Marshal(conf)
+	if err != nil {
+		return nil, err
+	}
+	var sanitized Config
+	if err := json.Unmarshal(md, &sanitized); err != nil {
+		return nil, err
+	}
+	sanitizeConfig(&sanitized)
+	for i := range sanitized.PackageRegistries {
+		if sanitized.PackageRegistries[i].Password != "" {
+			sanitized.PackageRegistries[i].Password = sanitizedMask
+		}
+	}
+	for i := range sanitized.Modules {
+		for name := range sanitized.Modules[i].Environment {
+			sanitized.Modules[i].Environment[name] = sanitizedMask
+		}
+	}
+	for _, resConf := range slices.Concat(sanitized.Components, sanitized.Services) {
+		sanitizeAttributes(resConf.Attributes)
+		for _, assocConf := range resConf.AssociatedResourceConfigs {
+			sanitizeAttributes(assocConf.Attributes)
+		}
+	}
+	return &sanitized, nil
+}
+
 // String returns a pretty version of the diff.
 func (diff *Diff) String() string {
 	return diff.PrettyDiff
@@ -670,6 +670,60 @@ func TestDiffSanitize(t *testing.T) {
 	}
 }
 
+func TestSanitizedCopy(t *testing.T) {
+	orig := &config.Config{
+		Cloud: &config.Cloud{ID: "1", Secret: "hello", APIKey: config.APIKey{ID: "api_key_id", Key: "sec"}},
+		Remotes: []config.Remote{
+			{Name: "rem", Secret: "remsecret", Auth: config.RemoteAuth{
+				Credentials: &utils.Credentials{Type: "remauthtype", Payload: "payload"},
+			}},
+		},
+		PackageRegistries: []config.PackageRegistry{{Host: "ghcr.io", Username: "user", Password: "pass"}},
+		Modules: []config.Module{
+			{Name: "mod", ExePath: "/bin/mod", Environment: map[string]string{"TOKEN": "tok"}},
+		},
+		Services: []resource.Config{
+			{Name: "svc", API: resource.APINamespaceRDK.WithServiceType("generic"), Attributes: utils.AttributeMap{
+				"broker":   "mqtt://broker:1883",
+				"Password": "svcpass",
+				"cloud":    map[string]interface{}{"api_key": "nestedkey", "region": "us"},
+				"servers":  []interface{}{map[string]interface{}{"auth_token": "listtok"}},
+			}},
+		},
+	}
+	orig.Network.MediaServer = &config.MediaServerConfig{RTSPAddress: ":8554", Username: "viewer", Password: "msecret"}
+
+	sanitized, err := config.SanitizedCopy(orig)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sanitized.Cloud.ID, test.ShouldEqual, "1")
+	test.That(t, sanitized.Cloud.Secret, test.ShouldNotEqual, "hello")
+	test.That(t, sanitized.Cloud.APIKey.ID, test.ShouldEqual, "api_key_id")
+	test.That(t, sanitized.Cloud.APIKey.Key, test.ShouldNotEqual, "sec")
+	test.That(t, sanitized.Remotes[0].Name, test.ShouldEqual, "rem")
+	test.That(t, sanitized.Remotes[0].Secret, test.ShouldNotEqual, "remsecret")
+	test.That(t, sanitized.Remotes[0].Auth.Credentials.Payload, test.ShouldNotEqual, "payload")
+	test.That(t, sanitized.PackageRegistries[0].Username, test.ShouldEqual, "user")
+	test.That(t, sanitized.PackageRegistries[0].Password, test.ShouldNotEqual, "pass")
+	test.That(t, sanitized.Modules[0].ExePath, test.ShouldEqual, "/bin/mod")
+	test.That(t, sanitized.Modules[0].Environment, test.ShouldContainKey, "TOKEN")
+	test.That(t, sanitized.Modules[0].Environment["TOKEN"], test.ShouldNotEqual, "tok")
+	test.That(t, sanitized.Network.MediaServer.Username, test.ShouldEqual, "viewer")
+	test.That(t, sanitized.Network.MediaServer.Password, test.ShouldNotEqual, "msecret")
+	svcAttrs := sanitized.Services[0].Attributes
+	test.That(t, svcAttrs["broker"], test.ShouldEqual, "mqtt://broker:1883")
+	test.That(t, svcAttrs["Password"], test.ShouldNotEqual, "svcpass")
+	test.That(t, svcAttrs["cloud"].(map[string]interface{})["region"], test.ShouldEqual, "us")
+	test.That(t, svcAttrs["cloud"].(map[string]interface{})["api_key"], test.ShouldNotEqual, "nestedkey")
+	test.That(t, svcAttrs["servers"].([]interface{})[0].(map[string]interface{})["auth_token"], test.ShouldNotEqual, "listtok")
+
+	// the original is untouched
+	test.That(t, orig.Cloud.Secret, test.ShouldEqual, "hello")
+	test.That(t, orig.PackageRegistries[0].Password, test.ShouldEqual, "pass")
+	test.That(t, orig.Modules[0].Environment["TOKEN"], test.ShouldEqual, "tok")
+	test.That(t, orig.Network.MediaServer.Password, test.ShouldEqual, "msecret")
+	test.That(t, orig.Services[0].Attributes["Password"], test.ShouldEqual, "svcpass")
+}
+
 func modifiedConfigDiffValidate(c *config.ModifiedConfigDiff) error {
 	for idx := 0; idx < len(c.Components); idx++ {
 		requiredDeps, optionalDeps, err := c.Components[idx].Validate(fmt.Sprintf("%s.%d", "components", idx), resource.APITypeComponentName)
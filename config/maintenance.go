@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// A MaintenanceWindow is a time of day, on some days of the week, during which the machine applies
+// config changes.
+type MaintenanceWindow struct {
+	// Days are the days of the week the window opens on, as names such as "monday" or "mon". The
+	// window opens every day if none are given.
+	Days []string `json:"days,omitempty"`
+	// Start and End are times of day in the form "15:04". A window that ends at or before it starts
+	// closes on the next day.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Timezone is the IANA name of the timezone of Start and End, which defaults to the machine's.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (mc *MaintenanceConfig) Validate(path string) error {
+	for i := range mc.Windows {
+		if err := mc.Windows[i].Validate(fmt.Sprintf("%s.windows.%d", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WindowOpen returns whether config changes may be applied at t, which is always the case if there are
+// no windows.
+func (mc *MaintenanceConfig) WindowOpen(t time.Time) bool {
+	if mc == nil || len(mc.Windows) == 0 {
+		return true
+	}
+	for _, w := range mc.Windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextWindowOpen returns the first time at or after t at which a window is open. It returns t if
+// there are no windows and the zero time if the windows are invalid.
+func (mc *MaintenanceConfig) NextWindowOpen(t time.Time) time.Time {
+	if mc.WindowOpen(t) {
+		return t
+	}
+	var next time.Time
+	for _, w := range mc.Windows {
+		if opens := w.NextOpen(t); !opens.IsZero() && (next.IsZero() || opens.Before(next)) {
+			next = opens
+		}
+	}
+	return next
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// parseTimeOfDay returns the minute of the day of a time in the form "15:04".
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, errors.Errorf("time of day %q is not in the form 15:04", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate ensures all parts of the config are valid.
+func (w *MaintenanceWindow) Validate(path string) error {
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return resource.NewConfigValidationError(path, errors.Errorf("unknown day %q", day))
+		}
+	}
+	if w.Start == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "start")
+	}
+	if w.End == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "end")
+	}
+	for _, tod := range []string{w.Start, w.End} {
+		if _, err := parseTimeOfDay(tod); err != nil {
+			return resource.NewConfigValidationError(path, err)
+		}
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return resource.NewConfigValidationError(path, errors.Wrapf(err, "unknown timezone %q", w.Timezone))
+	}
+	return nil
+}
+
+// parse returns the minutes of the day the window starts and ends at, and its location. ok is false
+// if the window is invalid.
+func (w *MaintenanceWindow) parse() (start, end int, loc *time.Location, ok bool) {
+	start, startErr := parseTimeOfDay(w.Start)
+	end, endErr := parseTimeOfDay(w.End)
+	loc, locErr := time.LoadLocation(w.Timezone)
+	return start, end, loc, startErr == nil && endErr == nil && locErr == nil
+}
+
+func (w *MaintenanceWindow) onDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains returns whether the window is open at t.
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	start, end, loc, ok := w.parse()
+	if !ok {
+		return false
+	}
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return w.onDay(t.Weekday()) && minute >= start && minute < end
+	}
+	// the window spans midnight, so it may have opened the day before.
+	if minute >= start {
+		return w.onDay(t.Weekday())
+	}
+	return minute < end && w.onDay(t.AddDate(0, 0, -1).Weekday())
+}
+
+// NextOpen returns the first time at or after t at which the window is open, or the zero time if the
+// window is invalid.
+func (w *MaintenanceWindow) NextOpen(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	start, _, loc, ok := w.parse()
+	if !ok {
+		return time.Time{}
+	}
+	local := t.In(loc)
+	for days := 0; days <= 7; days++ {
+		day := local.AddDate(0, 0, days)
+		opens := time.Date(day.Year(), day.Month(), day.Day(), start/60, start%60, 0, 0, loc)
+		if opens.After(t) && w.onDay(opens.Weekday()) {
+			return opens
+		}
+	}
+	return time.Time{}
+}
@@ -11,6 +11,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -23,8 +25,9 @@ import (
 )
 
 const (
-	reservedModuleName     = "parent"
-	defaultFirstRunTimeout = 1 * time.Hour
+	reservedModuleName        = "parent"
+	defaultFirstRunTimeout    = 1 * time.Hour
+	defaultFirstRunRetryDelay = 10 * time.Second
 )
 
 var errLocalTarballEntrypoint = errors.New("local tarballs must contain a meta.json with the 'entrypoint' field")
@@ -61,6 +64,28 @@ type Module struct {
 	// immediate timeout you should set this field to a very small positive value
 	// such as "1ns".
 	FirstRunTimeout goutils.Duration `json:"first_run_timeout,omitempty"`
+	// FirstRunRetries is the number of times a failed first run script is run again. The first retry
+	// waits FirstRunRetryDelay, or 10 seconds if it is unset, and each later retry waits twice as long
+	// as the one before it.
+	FirstRunRetries    int              `json:"first_run_retries,omitempty"`
+	FirstRunRetryDelay goutils.Duration `json:"first_run_retry_delay,omitempty"`
+
+	// CPULimit is the number of CPU cores the module process may use, such as 0.5 for half a core.
+	// MemoryLimitMB is the memory in megabytes the module process may use before it is killed and
+	// restarted. Both are only enforced on Linux systems with cgroups v2 and are unlimited if unset.
+	CPULimit      float64 `json:"cpu_limit,omitempty"`
+	MemoryLimitMB int     `json:"memory_limit_mb,omitempty"`
+
+	// Sandbox, if set, runs the module with bubblewrap (bwrap) so that it can only access the paths and
+	// hosts it allows. It is only supported on Linux.
+	Sandbox *ModuleSandbox `json:"sandbox,omitempty"`
+
+	// InProcess loads the executable of the module, which must be a Go plugin built with
+	// -buildmode=plugin against the same version of the RDK as viam-server, into the viam-server
+	// process instead of starting it as a subprocess. Its resources are then called directly rather
+	// than over gRPC, so it should only be set for trusted modules. A loaded plugin stays loaded until
+	// viam-server exits.
+	InProcess bool `json:"in_process,omitempty"`
 
 	// Status refers to the validations done in the APP to make sure a module is configured correctly
 	Status           *AppValidationStatus `json:"status"`
@@ -71,6 +96,49 @@ type Module struct {
 	LocalVersion string
 }
 
+// HasResourceLimits returns true if the module process should be limited in the CPU or memory it uses.
+func (m Module) HasResourceLimits() bool {
+	return m.CPULimit > 0 || m.MemoryLimitMB > 0
+}
+
+// ModuleSandbox describes what a sandboxed module may access. A sandboxed module has no network.
+// Besides the paths below, it can read the system paths needed to run programs, such as /usr, the
+// dynamic linker cache and the certificates in /etc, and can read and write its own directory, its
+// data directory, and the directory of its socket.
+type ModuleSandbox struct {
+	// ReadOnlyPaths are the absolute paths the module may read.
+	ReadOnlyPaths []string `json:"read_only_paths,omitempty"`
+	// ReadWritePaths are the absolute paths the module may read and write. Devices under them cannot be
+	// used; those the module needs must be listed in Devices.
+	ReadWritePaths []string `json:"read_write_paths,omitempty"`
+	// Devices are the absolute paths of the devices, such as /dev/video0, the module may use.
+	Devices []string `json:"devices,omitempty"`
+	// ResolvableHosts is not supported, as the sandbox cannot limit which hosts a module connects to,
+	// so sandboxed modules have no network. Configs setting it fail validation rather than having the
+	// module start without the network it expects.
+	ResolvableHosts []string `json:"resolvable_hosts,omitempty"`
+}
+
+// Validate checks that the sandbox paths are absolute.
+func (s ModuleSandbox) Validate(path string) error {
+	for _, paths := range [][]string{s.ReadOnlyPaths, s.ReadWritePaths, s.Devices} {
+		for _, p := range paths {
+			expanded, err := utils.ExpandHomeDir(p)
+			if err != nil {
+				return err
+			}
+			if !filepath.IsAbs(expanded) {
+				return resource.NewConfigValidationError(path, fmt.Errorf("sandbox path %q must be absolute", p))
+			}
+		}
+	}
+	if len(s.ResolvableHosts) != 0 {
+		return resource.NewConfigValidationError(path, errors.New(
+			"sandbox resolvable_hosts is not supported as sandboxed modules have no network"))
+	}
+	return nil
+}
+
 // ParentSockAddrs stores addresses for both TCP and UDS-based connection.
 type ParentSockAddrs struct {
 	TCPAddr  string
@@ -81,6 +149,9 @@ type ParentSockAddrs struct {
 type JSONManifest struct {
 	Entrypoint string `json:"entrypoint"`
 	FirstRun   string `json:"first_run"`
+	// RequiredSystemPackages are the system packages, such as those installed with apt, that the module
+	// needs. First run fails with the command to install them if any are missing after it runs.
+	RequiredSystemPackages []string `json:"required_system_packages,omitempty"`
 }
 
 // ModuleType indicates where a module comes from.
@@ -130,6 +201,39 @@ func (m *Module) validate(path string) error {
 		return fmt.Errorf("module %s cannot use the reserved name of %s", path, reservedModuleName)
 	}
 
+	if m.FirstRunRetries < 0 {
+		return resource.NewConfigValidationError(path, errors.New("first_run_retries cannot be negative"))
+	}
+
+	if m.CPULimit < 0 {
+		return resource.NewConfigValidationError(path, errors.New("cpu_limit cannot be negative"))
+	}
+	if m.MemoryLimitMB < 0 {
+		return resource.NewConfigValidationError(path, errors.New("memory_limit_mb cannot be negative"))
+	}
+
+	if m.InProcess {
+		// none of these apply to a module without a process of its own.
+		switch {
+		case m.Sandbox != nil:
+			return resource.NewConfigValidationError(path, errors.New("in_process modules cannot be sandboxed"))
+		case m.HasResourceLimits():
+			return resource.NewConfigValidationError(path, errors.New("in_process modules cannot have cpu_limit or memory_limit_mb"))
+		case m.TCPMode:
+			return resource.NewConfigValidationError(path, errors.New("in_process modules cannot use tcp_mode"))
+		}
+	}
+
+	if m.Sandbox != nil {
+		if err := m.Sandbox.Validate(path); err != nil {
+			return err
+		}
+		// a sandbox has its own network, which cannot reach the server.
+		if m.TCPMode {
+			return resource.NewConfigValidationError(path, errors.New("sandboxed modules cannot use tcp_mode"))
+		}
+	}
+
 	return nil
 }
 
@@ -241,14 +345,30 @@ func (m Module) EvaluateExePath(packagesDir string) (string, error) {
 			if err != nil {
 				return "", err
 			}
-			return filepath.Abs(entrypoint)
+			return filepath.Abs(windowsExecutable(entrypoint))
 		}
 		if m.NeedsSyntheticPackage() {
 			// registry modules can use configured ExePath, but for local tarballs it is wrong, throw an error.
 			return "", errLocalTarballEntrypoint
 		}
 	}
-	return path, nil
+	return windowsExecutable(path), nil
+}
+
+// windowsExecutable returns path with ".exe" appended if it is on Windows, has no extension, and only
+// exists with the extension. Windows runs "foo" as "foo.exe", so entrypoints are often written without
+// it, but the path is also checked for changes and must name the file.
+func windowsExecutable(path string) string {
+	if runtime.GOOS != "windows" || filepath.Ext(path) != "" {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if _, err := os.Stat(path + ".exe"); err == nil {
+		return path + ".exe"
+	}
+	return path
 }
 
 // FirstRunSuccessSuffix is the suffix of the file whose existence
@@ -260,12 +380,15 @@ func (m Module) EvaluateExePath(packagesDir string) (string, error) {
 // could break or uncoordinate package sync.
 const FirstRunSuccessSuffix = ".first_run_succeeded"
 
-// FirstRun executes a module-specific setup script.
+// FirstRun executes a module-specific setup script, retrying it as configured if it fails, and then checks
+// that the system packages required by the meta.json of the module are installed. Progress reported by
+// the script is passed to onProgress, which may be nil.
 func (m *Module) FirstRun(
 	ctx context.Context,
 	localPackagesDir,
 	dataDir string,
 	env map[string]string,
+	onProgress func(FirstRunProgress),
 	logger logging.Logger,
 ) error {
 	logger = logger.Sublogger("first_run").WithFields("module", m.Name)
@@ -317,7 +440,7 @@ func (m *Module) FirstRun(
 
 	if meta.FirstRun == "" {
 		logger.Debug("no first run script specified, skipping first run")
-		return nil
+		return m.checkSystemPackages(ctx, meta.RequiredSystemPackages, nil, logger)
 	}
 	relFirstRunPath, err := utils.SafeJoinDir(moduleWorkingDirectory, meta.FirstRun)
 	if err != nil {
@@ -331,12 +454,62 @@ func (m *Module) FirstRun(
 	}
 
 	logger = logger.WithFields("module", m.Name, "path", firstRunPath)
-	logger.Infow("executing first run script")
 
 	timeout := defaultFirstRunTimeout
 	if m.FirstRunTimeout > 0 {
 		timeout = m.FirstRunTimeout.Unwrap()
 	}
+	retryDelay := defaultFirstRunRetryDelay
+	if m.FirstRunRetryDelay > 0 {
+		retryDelay = m.FirstRunRetryDelay.Unwrap()
+	}
+	for attempt := 1; ; attempt++ {
+		err = runFirstRunScript(ctx, firstRunPath, timeout, env, onProgress, logger)
+		err = m.checkSystemPackages(ctx, meta.RequiredSystemPackages, err, logger)
+		if err == nil {
+			break
+		}
+		if attempt > m.FirstRunRetries {
+			return err
+		}
+		logger.Warnw("first run failed, retrying", "attempt", attempt, "retries", m.FirstRunRetries, "delay", retryDelay, "error", err)
+		if onProgress != nil {
+			onProgress(FirstRunProgress{Message: fmt.Sprintf("retrying after failed attempt %d of %d", attempt, m.FirstRunRetries+1)})
+		}
+		if !goutils.SelectContextOrWait(ctx, retryDelay) {
+			return err
+		}
+		retryDelay *= 2
+	}
+
+	// Mark success by writing a marker file to disk. This is a best
+	// effort; if writing to disk fails the first run script will run again
+	// for this module and version and we are okay with that.
+	//nolint:gosec // safe
+	markerFile, err := os.Create(firstRunSuccessPath)
+	if err != nil {
+		logger.Errorw("failed to mark success", "error", err)
+		return nil
+	}
+	if err = markerFile.Close(); err != nil {
+		logger.Errorw("failed to close marker file", "error", err)
+		return nil
+	}
+	return nil
+}
+
+// runFirstRunScript runs a first run script once, logging its output and reporting the progress it
+// writes to stdout to onProgress, which may be nil.
+func runFirstRunScript(
+	ctx context.Context,
+	firstRunPath string,
+	timeout time.Duration,
+	env map[string]string,
+	onProgress func(FirstRunProgress),
+	logger logging.Logger,
+) error {
+	logger.Infow("executing first run script")
+
 	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
@@ -365,6 +538,13 @@ func (m *Module) FirstRun(
 		defer wg.Done()
 
 		for scanOut.Scan() {
+			if progress, ok := parseFirstRunProgress(scanOut.Text()); ok {
+				logger.Infow("first run progress", "percent", progress.Percent, "message", progress.Message)
+				if onProgress != nil {
+					onProgress(progress)
+				}
+				continue
+			}
 			logger.Infow("got stdio", "output", scanOut.Text())
 		}
 		// This scanner keeps trying to read stdio until the command terminates,
@@ -392,27 +572,60 @@ func (m *Module) FirstRun(
 		logger.Errorw("failed to start first run script", "error", err)
 		return err
 	}
+	// Wait closes the pipes, so all output, and the progress reported in it, must be read first.
+	wg.Wait()
 	if err := cmd.Wait(); err != nil {
 		logger.Errorw("first run script failed", "error", err)
 		return err
 	}
-	wg.Wait()
 	logger.Info("first run script succeeded")
+	return nil
+}
 
-	// Mark success by writing a marker file to disk. This is a best
-	// effort; if writing to disk fails the first run script will run again
-	// for this module and version and we are okay with that.
-	//nolint:gosec // safe
-	markerFile, err := os.Create(firstRunSuccessPath)
-	if err != nil {
-		logger.Errorw("failed to mark success", "error", err)
-		return nil
+// checkSystemPackages returns a MissingSystemPackagesError wrapping scriptErr if any of the required
+// system packages are not installed, and scriptErr otherwise.
+func (m *Module) checkSystemPackages(ctx context.Context, required []string, scriptErr error, logger logging.Logger) error {
+	if len(required) == 0 {
+		return scriptErr
 	}
-	if err = markerFile.Close(); err != nil {
-		logger.Errorw("failed to close marker file", "error", err)
-		return nil
+	missing, installCommand, ok := missingSystemPackages(ctx, required)
+	if !ok {
+		logger.Warnw("no supported package manager found, not checking required system packages", "packages", required)
+		return scriptErr
 	}
-	return nil
+	if len(missing) == 0 {
+		return scriptErr
+	}
+	logger.Errorw("required system packages are not installed", "packages", missing, "install_command", installCommand)
+	return &MissingSystemPackagesError{Module: m.Name, Packages: missing, InstallCommand: installCommand, Err: scriptErr}
+}
+
+// FirstRunProgress is the progress a first run script reports by writing a line that starts with
+// FirstRunProgressPrefix to stdout.
+type FirstRunProgress struct {
+	// Percent is between 0 and 100.
+	Percent float64
+	Message string
+}
+
+// FirstRunProgressPrefix starts the lines a first run script writes to stdout to report its progress. It
+// is followed by a percentage and an optional message, such as
+// "VIAM_FIRST_RUN_PROGRESS: 40 installing dependencies".
+const FirstRunProgressPrefix = "VIAM_FIRST_RUN_PROGRESS:"
+
+// parseFirstRunProgress returns the progress reported by a line of first run script output, or false if
+// the line does not report progress.
+func parseFirstRunProgress(line string) (FirstRunProgress, bool) {
+	rest, ok := strings.CutPrefix(line, FirstRunProgressPrefix)
+	if !ok {
+		return FirstRunProgress{}, false
+	}
+	percentStr, message, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(percentStr, "%"), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return FirstRunProgress{}, false
+	}
+	return FirstRunProgress{Percent: percent, Message: strings.TrimSpace(message)}, true
 }
 
 // getJSONManifest returns a loaded meta.json from one of three sources (in order of precedence):
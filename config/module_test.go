@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.uber.org/zap/zaptest/observer"
 	"go.viam.com/test"
+	goutils "go.viam.com/utils"
 
 	"go.viam.com/rdk/logging"
 )
@@ -121,7 +124,7 @@ func TestRegistryModuleFirstRun(t *testing.T) {
 	t.Run("MetaFileNotFound", func(t *testing.T) {
 		module, _, env, logger, observedLogs := testSetUpRegistryModule(t)
 
-		err := module.FirstRun(ctx, localPackagesDir, dataDir, env, logger)
+		err := module.FirstRun(ctx, localPackagesDir, dataDir, env, nil, logger)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, observedLogs.FilterMessage("meta.json does not exist, skipping first run").Len(), test.ShouldEqual, 1)
 	})
@@ -133,7 +136,7 @@ func TestRegistryModuleFirstRun(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 		defer metaJSONFile.Close()
 
-		err = module.FirstRun(ctx, localPackagesDir, dataDir, env, logger)
+		err = module.FirstRun(ctx, localPackagesDir, dataDir, env, nil, logger)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, observedLogs.FilterMessage("failed to parse meta.json, skipping first run").Len(), test.ShouldEqual, 1)
 	})
@@ -143,7 +146,7 @@ func TestRegistryModuleFirstRun(t *testing.T) {
 
 		testWriteJSON(t, metaJSONFilepath, JSONManifest{})
 
-		err := module.FirstRun(ctx, localPackagesDir, dataDir, env, logger)
+		err := module.FirstRun(ctx, localPackagesDir, dataDir, env, nil, logger)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, observedLogs.FilterMessage("no first run script specified, skipping first run").Len(), test.ShouldEqual, 1)
 	})
@@ -153,11 +156,70 @@ func TestRegistryModuleFirstRun(t *testing.T) {
 
 		testWriteJSON(t, metaJSONFilepath, JSONManifest{FirstRun: "../firstrun.sh"})
 
-		err := module.FirstRun(ctx, localPackagesDir, dataDir, env, logger)
+		err := module.FirstRun(ctx, localPackagesDir, dataDir, env, nil, logger)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, observedLogs.FilterMessage("failed to build path to first run script, skipping first run").Len(), test.ShouldEqual, 1)
 	})
 
+	t.Run("RetriesAndReportsProgress", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("first run script is a shell script")
+		}
+		module, metaJSONFilepath, env, logger, observedLogs := testSetUpRegistryModule(t)
+		module.FirstRunRetries = 1
+		module.FirstRunRetryDelay = goutils.Duration(time.Millisecond)
+
+		// the script fails the first time it runs and succeeds the second time.
+		dir := filepath.Dir(metaJSONFilepath)
+		script := "#!/bin/sh\ncd \"$(dirname \"$0\")\"\n" +
+			"echo '" + FirstRunProgressPrefix + " 50 installing dependencies'\n" +
+			"if [ ! -f attempted ]; then touch attempted; exit 1; fi\n" +
+			"echo '" + FirstRunProgressPrefix + " 100'\n"
+		test.That(t, os.WriteFile(filepath.Join(dir, "first_run.sh"), []byte(script), 0o700), test.ShouldBeNil)
+		testWriteJSON(t, metaJSONFilepath, JSONManifest{FirstRun: "first_run.sh"})
+
+		var progress []FirstRunProgress
+		err := module.FirstRun(ctx, localPackagesDir, dataDir, env, func(p FirstRunProgress) {
+			progress = append(progress, p)
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, observedLogs.FilterMessage("first run failed, retrying").Len(), test.ShouldEqual, 1)
+		test.That(t, observedLogs.FilterMessage("first run script succeeded").Len(), test.ShouldEqual, 1)
+		test.That(t, progress, test.ShouldResemble, []FirstRunProgress{
+			{Percent: 50, Message: "installing dependencies"},
+			{Message: "retrying after failed attempt 1 of 2"},
+			{Percent: 50, Message: "installing dependencies"},
+			{Percent: 100},
+		})
+	})
+
+	t.Run("MissingSystemPackages", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("package manager is faked with sh")
+		}
+		prevManagers := systemPackageManagers
+		t.Cleanup(func() { systemPackageManagers = prevManagers })
+		// a package manager that only has packages named "installed".
+		systemPackageManagers = []systemPackageManager{{
+			query:   func(pkg string) []string { return []string{"sh", "-c", `test "$0" = installed`, pkg} },
+			install: "pkg install",
+		}}
+
+		module, metaJSONFilepath, env, logger, _ := testSetUpRegistryModule(t)
+		module.Name = "needs-packages"
+		testWriteJSON(t, metaJSONFilepath, JSONManifest{RequiredSystemPackages: []string{"installed", "libfoo", "bar"}})
+
+		err := module.FirstRun(ctx, localPackagesDir, dataDir, env, nil, logger)
+		var missingErr *MissingSystemPackagesError
+		test.That(t, errors.As(err, &missingErr), test.ShouldBeTrue)
+		test.That(t, missingErr.Packages, test.ShouldResemble, []string{"libfoo", "bar"})
+		test.That(t, missingErr.InstallCommand, test.ShouldEqual, "pkg install libfoo bar")
+		test.That(t, err.Error(), test.ShouldContainSubstring, "module needs-packages requires system packages that are not installed")
+
+		testWriteJSON(t, metaJSONFilepath, JSONManifest{RequiredSystemPackages: []string{"installed"}})
+		test.That(t, module.FirstRun(ctx, localPackagesDir, dataDir, env, nil, logger), test.ShouldBeNil)
+	})
+
 	// the executable is one level deep, and the meta.json file is in the same directory
 	t.Run("NoFirstRunScriptOneLevelExe", func(t *testing.T) {
 		module := Module{Type: ModuleTypeRegistry}
@@ -177,7 +239,7 @@ func TestRegistryModuleFirstRun(t *testing.T) {
 
 		testWriteJSON(t, exeMetaJSONFilepath, JSONManifest{})
 
-		err = module.FirstRun(ctx, localPackagesDir, dataDir, env, logger)
+		err = module.FirstRun(ctx, localPackagesDir, dataDir, env, nil, logger)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, observedLogs.FilterMessage("no first run script specified, skipping first run").Len(), test.ShouldEqual, 1)
 	})
@@ -202,12 +264,27 @@ func TestRegistryModuleFirstRun(t *testing.T) {
 
 		testWriteJSON(t, exeMetaJSONFilepath, JSONManifest{})
 
-		err = module.FirstRun(ctx, localPackagesDir, dataDir, env, logger)
+		err = module.FirstRun(ctx, localPackagesDir, dataDir, env, nil, logger)
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, observedLogs.FilterMessage("no first run script specified, skipping first run").Len(), test.ShouldEqual, 1)
 	})
 }
 
+func TestParseFirstRunProgress(t *testing.T) {
+	progress, ok := parseFirstRunProgress(FirstRunProgressPrefix + " 42.5 downloading  models ")
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, progress, test.ShouldResemble, FirstRunProgress{Percent: 42.5, Message: "downloading  models"})
+
+	progress, ok = parseFirstRunProgress(FirstRunProgressPrefix + "100%")
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, progress, test.ShouldResemble, FirstRunProgress{Percent: 100})
+
+	for _, line := range []string{"installing 40", FirstRunProgressPrefix + " soon", FirstRunProgressPrefix + " 101"} {
+		_, ok = parseFirstRunProgress(line)
+		test.That(t, ok, test.ShouldBeFalse)
+	}
+}
+
 func TestGetJSONManifest(t *testing.T) {
 	validJSONManifest := JSONManifest{Entrypoint: "entry"}
 
@@ -439,3 +516,69 @@ func testSetUpRegistryModule(t *testing.T) (module Module, metaJSONFilepath stri
 	logger, observedLogs = logging.NewObservedTestLogger(t)
 	return
 }
+
+func TestModuleResourceLimits(t *testing.T) {
+	mod := Module{Name: "limited", Type: ModuleTypeRegistry, CPULimit: 0.5, MemoryLimitMB: 256}
+	test.That(t, mod.Validate("modules.0"), test.ShouldBeNil)
+	test.That(t, mod.HasResourceLimits(), test.ShouldBeTrue)
+	test.That(t, Module{Name: "unlimited"}.HasResourceLimits(), test.ShouldBeFalse)
+
+	mod = Module{Name: "limited", Type: ModuleTypeRegistry, CPULimit: -1}
+	err := mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cpu_limit cannot be negative")
+
+	mod = Module{Name: "limited", Type: ModuleTypeRegistry, MemoryLimitMB: -1}
+	err = mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "memory_limit_mb cannot be negative")
+}
+
+func TestModuleSandbox(t *testing.T) {
+	mod := Module{Name: "sandboxed", Type: ModuleTypeRegistry, Sandbox: &ModuleSandbox{
+		ReadOnlyPaths:  []string{"/opt/models", "~/models"},
+		ReadWritePaths: []string{"/data/models"},
+		Devices:        []string{"/dev/video0"},
+	}}
+	test.That(t, mod.Validate("modules.0"), test.ShouldBeNil)
+
+	mod = Module{Name: "sandboxed", Type: ModuleTypeRegistry, Sandbox: &ModuleSandbox{Devices: []string{"video0"}}}
+	err := mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `sandbox path "video0" must be absolute`)
+
+	mod = Module{Name: "sandboxed", Type: ModuleTypeRegistry, Sandbox: &ModuleSandbox{ReadOnlyPaths: []string{"models"}}}
+	err = mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `sandbox path "models" must be absolute`)
+
+	mod = Module{Name: "sandboxed", Type: ModuleTypeRegistry, TCPMode: true, Sandbox: &ModuleSandbox{}}
+	err = mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot use tcp_mode")
+
+	mod = Module{Name: "sandboxed", Type: ModuleTypeRegistry, Sandbox: &ModuleSandbox{ResolvableHosts: []string{"localhost"}}}
+	err = mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "resolvable_hosts is not supported")
+}
+
+func TestModuleInProcess(t *testing.T) {
+	mod := Module{Name: "plugin", Type: ModuleTypeRegistry, InProcess: true}
+	test.That(t, mod.Validate("modules.0"), test.ShouldBeNil)
+
+	mod = Module{Name: "plugin", Type: ModuleTypeRegistry, InProcess: true, Sandbox: &ModuleSandbox{}}
+	err := mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "in_process modules cannot be sandboxed")
+
+	mod = Module{Name: "plugin", Type: ModuleTypeRegistry, InProcess: true, MemoryLimitMB: 256}
+	err = mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "in_process modules cannot have cpu_limit or memory_limit_mb")
+
+	mod = Module{Name: "plugin", Type: ModuleTypeRegistry, InProcess: true, TCPMode: true}
+	err = mod.Validate("modules.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "in_process modules cannot use tcp_mode")
+}
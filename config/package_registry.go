@@ -0,0 +1,160 @@
+package config
+
+import (
+	"encoding/hex"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// A PackageRegistry describes how to authenticate with an OCI registry that packages are pulled from.
+type PackageRegistry struct {
+	// Host is the host, and optionally port, of the registry, e.g. "ghcr.io" or "registry.local:5000".
+	Host string `json:"host"`
+	// Username and Password are exchanged for a token, or sent as basic auth, when the registry asks for
+	// credentials. Most registries accept an access token as the password.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Insecure talks to the registry over plain HTTP rather than HTTPS.
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (r *PackageRegistry) Validate(path string) error {
+	if r.Host == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "host")
+	}
+	if strings.Contains(r.Host, "/") {
+		return resource.NewConfigValidationError(path, errors.Errorf("host %q must not contain a scheme or path", r.Host))
+	}
+	if r.Password != "" && r.Username == "" {
+		return resource.NewConfigValidationError(path, errors.New("password requires a username"))
+	}
+	return nil
+}
+
+// PackageOCISource describes a package that is pulled from an OCI registry rather than from the package service.
+// The image manifest of the package must have exactly one gzipped tarball layer, which holds the package contents.
+// If the manifest also has a block index layer (see packages.BlockIndex), upgrades of the package only download the
+// blocks of the tarball that changed since a version the robot already has. The layer is written with
+// "viam packages block-index". Packages from the package service do not support delta updates and are always
+// downloaded in full.
+type PackageOCISource struct {
+	// Reference is the image reference of the package, e.g. "ghcr.io/org/package:1.2.3". If neither a tag nor a
+	// digest is given, "latest" is assumed.
+	Reference string `json:"reference"`
+	// Digest pins the manifest of the package, e.g. "sha256:ab12...". If set, the package fails to sync when the
+	// registry serves a different manifest for Reference.
+	Digest string `json:"digest,omitempty"`
+}
+
+// An OCIReference is a parsed PackageOCISource.Reference.
+type OCIReference struct {
+	Registry   string
+	Repository string
+	// Tag is empty if the reference is by digest.
+	Tag    string
+	Digest string
+}
+
+// Manifest parses the reference and returns the tag or digest to request the manifest of the package by, along with
+// the digest that the manifest must match, if any.
+func (s *PackageOCISource) Manifest() (ref OCIReference, manifestRef, digest string, err error) {
+	ref, err = ParseOCIReference(s.Reference)
+	if err != nil {
+		return OCIReference{}, "", "", err
+	}
+	digest = s.Digest
+	if digest == "" {
+		digest = ref.Digest
+	}
+	if ref.Digest != "" {
+		return ref, ref.Digest, digest, nil
+	}
+	return ref, ref.Tag, digest, nil
+}
+
+func (s *PackageOCISource) validate(path string) error {
+	ref, err := ParseOCIReference(s.Reference)
+	if err != nil {
+		return resource.NewConfigValidationError(path, err)
+	}
+	if s.Digest == "" {
+		return nil
+	}
+	if err := ValidateOCIDigest(s.Digest); err != nil {
+		return resource.NewConfigValidationError(path, err)
+	}
+	if ref.Digest != "" && ref.Digest != s.Digest {
+		return resource.NewConfigValidationError(path, errors.Errorf("digest %q does not match the digest of reference %q",
+			s.Digest, s.Reference))
+	}
+	return nil
+}
+
+// ParseOCIReference parses an image reference of the form "registry/repository[:tag][@digest]". The registry
+// must be given explicitly.
+func ParseOCIReference(reference string) (OCIReference, error) {
+	var ref OCIReference
+	rest := reference
+	if idx := strings.Index(rest, "@"); idx >= 0 {
+		ref.Digest = rest[idx+1:]
+		rest = rest[:idx]
+		if err := ValidateOCIDigest(ref.Digest); err != nil {
+			return OCIReference{}, errors.Wrapf(err, "invalid reference %q", reference)
+		}
+	}
+
+	registry, repository, ok := strings.Cut(rest, "/")
+	if !ok || registry == "" || repository == "" {
+		return OCIReference{}, errors.Errorf("invalid reference %q, must be of the form registry/repository[:tag][@digest]", reference)
+	}
+	// a colon after the last slash separates the tag, any other colon is the port of the registry.
+	if idx := strings.LastIndex(repository, ":"); idx >= 0 {
+		ref.Tag = repository[idx+1:]
+		repository = repository[:idx]
+		if ref.Tag == "" {
+			return OCIReference{}, errors.Errorf("invalid reference %q, empty tag", reference)
+		}
+	}
+	if repository != strings.ToLower(repository) {
+		return OCIReference{}, errors.Errorf("invalid reference %q, repository must be lowercase", reference)
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	ref.Registry = registry
+	ref.Repository = repository
+	return ref, nil
+}
+
+// ValidateOCIDigest returns an error if digest is not a sha256 digest, which is the only algorithm packages
+// are verified with.
+func ValidateOCIDigest(digest string) error {
+	hexDigest, ok := strings.CutPrefix(digest, "sha256:")
+	if !ok {
+		return errors.Errorf("unsupported digest %q, must start with sha256:", digest)
+	}
+	if _, err := hex.DecodeString(hexDigest); err != nil || len(hexDigest) != 64 {
+		return errors.Errorf("invalid digest %q, must be 64 hex characters", digest)
+	}
+	return nil
+}
+
+// ValidatePackageMirror returns an error if mirror is neither an absolute directory nor an http(s) URL. A mirror holds
+// the archives of packages at "<mirror>/<type>/<PackageConfig.SanitizedName()>.tar.gz", which is the layout that
+// "viam packages preseed --mirror" writes.
+func ValidatePackageMirror(mirror string) error {
+	if filepath.IsAbs(mirror) {
+		return nil
+	}
+	parsed, err := url.Parse(mirror)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.Errorf("invalid package mirror %q, must be an absolute directory or an http(s) URL", mirror)
+	}
+	return nil
+}
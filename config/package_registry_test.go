@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestParseOCIReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+
+	ref, err := ParseOCIReference("registry.local:5000/org/pkg:1.2.3")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ref, test.ShouldResemble, OCIReference{Registry: "registry.local:5000", Repository: "org/pkg", Tag: "1.2.3"})
+
+	ref, err = ParseOCIReference("ghcr.io/org/pkg")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ref.Tag, test.ShouldEqual, "latest")
+
+	ref, err = ParseOCIReference("ghcr.io/org/pkg@" + digest)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ref, test.ShouldResemble, OCIReference{Registry: "ghcr.io", Repository: "org/pkg", Digest: digest})
+
+	for _, invalid := range []string{"pkg", "ghcr.io/", "ghcr.io/org/pkg:", "ghcr.io/Org/pkg", "ghcr.io/org/pkg@md5:ab"} {
+		_, err = ParseOCIReference(invalid)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestPackageOCISourceValidate(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("ab", 32)
+	pkg := PackageConfig{
+		Name:    "my-module",
+		Package: "org/pkg",
+		Type:    PackageTypeModule,
+		OCI:     &PackageOCISource{Reference: "ghcr.io/org/pkg:1.2.3", Digest: digest},
+	}
+	test.That(t, pkg.Validate("packages.0"), test.ShouldBeNil)
+
+	_, manifestRef, pinnedDigest, err := pkg.OCI.Manifest()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, manifestRef, test.ShouldEqual, "1.2.3")
+	test.That(t, pinnedDigest, test.ShouldEqual, digest)
+
+	pkg = PackageConfig{
+		Name:    "my-module",
+		Package: "org/pkg",
+		Type:    PackageTypeModule,
+		OCI:     &PackageOCISource{Reference: "ghcr.io/org/pkg:1.2.3", Digest: "sha256:abc"},
+	}
+	err = pkg.Validate("packages.0")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must be 64 hex characters")
+
+	registry := PackageRegistry{Host: "https://ghcr.io"}
+	test.That(t, registry.Validate("package_registries.0"), test.ShouldNotBeNil)
+	registry = PackageRegistry{Host: "ghcr.io", Password: "token"}
+	test.That(t, registry.Validate("package_registries.0"), test.ShouldNotBeNil)
+}
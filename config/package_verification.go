@@ -0,0 +1,48 @@
+package config
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// PackageVerification describes how the archive of a package is verified before it is installed. Any
+// combination of a checksum and a signature may be given, and all of them must verify.
+type PackageVerification struct {
+	// Checksum is the sha256 checksum of the package archive, e.g. "sha256:ab12...".
+	Checksum string `json:"checksum,omitempty"`
+	// PublicKey is either a minisign public key, e.g. "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3",
+	// or a PEM encoded ECDSA public key as generated by "cosign generate-key-pair".
+	PublicKey string `json:"public_key,omitempty"`
+	// Signature is the signature of the package archive by PublicKey. For minisign keys it is the contents of
+	// the .minisig file, and for cosign keys it is the base64 encoded signature output by "cosign sign-blob".
+	Signature string `json:"signature,omitempty"`
+}
+
+// IsMinisign returns true if PublicKey is a minisign key rather than a cosign key.
+func (v *PackageVerification) IsMinisign() bool {
+	return !strings.HasPrefix(strings.TrimSpace(v.PublicKey), "-----BEGIN")
+}
+
+func (v *PackageVerification) validate(path string) error {
+	if v.Checksum == "" && v.PublicKey == "" {
+		return resource.NewConfigValidationError(path, errors.New("must specify a checksum, a public_key, or both"))
+	}
+	if v.Checksum != "" {
+		hexChecksum, ok := strings.CutPrefix(v.Checksum, "sha256:")
+		if _, err := hex.DecodeString(hexChecksum); !ok || err != nil || len(hexChecksum) != 64 {
+			return resource.NewConfigValidationError(path, errors.Errorf("invalid checksum %q, must be sha256: followed by 64 hex characters",
+				v.Checksum))
+		}
+	}
+	if v.PublicKey != "" && v.Signature == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "signature")
+	}
+	if v.Signature != "" && v.PublicKey == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "public_key")
+	}
+	return nil
+}
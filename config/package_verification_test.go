@@ -0,0 +1,32 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestPackageVerificationValidate(t *testing.T) {
+	checksum := "sha256:" + strings.Repeat("ab", 32)
+	for _, tc := range []struct {
+		verification PackageVerification
+		err          string
+	}{
+		{PackageVerification{Checksum: checksum}, ""},
+		{PackageVerification{PublicKey: "RWQ", Signature: "sig"}, ""},
+		{PackageVerification{}, "must specify a checksum"},
+		{PackageVerification{Checksum: "md5:abc"}, "invalid checksum"},
+		{PackageVerification{PublicKey: "RWQ"}, "signature"},
+		{PackageVerification{Checksum: checksum, Signature: "sig"}, "public_key"},
+	} {
+		pkg := PackageConfig{Name: "my-module", Package: "org/pkg", Type: PackageTypeModule, Verification: &tc.verification}
+		err := pkg.Validate("packages.0")
+		if tc.err == "" {
+			test.That(t, err, test.ShouldBeNil)
+		} else {
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		}
+	}
+}
@@ -414,12 +414,41 @@ func fromReader(
 					cfgFromDisk.Network.BindAddress)
 			}
 		}
+		if err == nil {
+			warnLocalOnlySettings(ctx, cfgFromDisk, logger)
+		}
 		return cfg, err
 	}
 
 	return cfgFromDisk, err
 }
 
+// warnLocalOnlySettings warns about the settings of a local config that have no cloud representation
+// and so are dropped when the robot uses its cloud config instead.
+func warnLocalOnlySettings(ctx context.Context, cfgFromDisk *Config, logger logging.Logger) {
+	var ignored []string
+	if cfgFromDisk.EmergencyStop != nil {
+		ignored = append(ignored, "emergency_stop")
+	}
+	if cfgFromDisk.ResourceConfigurationConcurrency != 0 {
+		ignored = append(ignored, "resource_configuration_concurrency")
+	}
+	for _, confs := range [][]resource.Config{cfgFromDisk.Components, cfgFromDisk.Services} {
+		for _, conf := range confs {
+			if conf.HealthCheck != nil {
+				ignored = append(ignored, conf.Name+".health_check")
+			}
+			if len(conf.OptionalDependsOn) != 0 {
+				ignored = append(ignored, conf.Name+".optional_depends_on")
+			}
+		}
+	}
+	if len(ignored) != 0 {
+		logger.CWarnw(ctx, "Using cloud config, but these settings are only read from local configs and will be ignored",
+			"settings", ignored)
+	}
+}
+
 // ProcessLocal validates the current config assuming it came from a local file and
 // updates it with all derived fields. Returns an error if the unprocessedConfig is
 // non-valid.
@@ -21,6 +21,7 @@ import (
 	"go.viam.com/rdk/config/testutils"
 	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/utils"
 )
 
@@ -187,6 +188,28 @@ func TestFromReader(t *testing.T) {
 	})
 }
 
+func TestWarnLocalOnlySettings(t *testing.T) {
+	logger, logs := logging.NewObservedTestLogger(t)
+	warnLocalOnlySettings(context.Background(), &Config{}, logger)
+	test.That(t, logs.Len(), test.ShouldEqual, 0)
+
+	warnLocalOnlySettings(context.Background(), &Config{
+		EmergencyStop:                    &EmergencyStopConfig{BoardName: "board1", Pin: "37"},
+		ResourceConfigurationConcurrency: 4,
+	}, logger)
+	test.That(t, logs.Len(), test.ShouldEqual, 1)
+	test.That(t, logs.All()[0].ContextMap()["settings"], test.ShouldResemble,
+		[]interface{}{"emergency_stop", "resource_configuration_concurrency"})
+
+	warnLocalOnlySettings(context.Background(), &Config{
+		Components: []resource.Config{{Name: "sensor1"}, {Name: "sensor2", HealthCheck: &resource.HealthCheckConfig{}}},
+		Services:   []resource.Config{{Name: "nav1", OptionalDependsOn: []string{"sensor1"}}},
+	}, logger)
+	test.That(t, logs.Len(), test.ShouldEqual, 2)
+	test.That(t, logs.All()[1].ContextMap()["settings"], test.ShouldResemble,
+		[]interface{}{"sensor2.health_check", "nav1.optional_depends_on"})
+}
+
 // TestGetFromCloudOrCacheErrorClassification verifies that when the cloud config endpoint fails
 // and we fall back to a cached config, a connectivity error is logged quietly (Warn) while a
 // malformed config from the cloud is surfaced loudly (Error).
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+)
+
+// RemoteResources selects which resources of a remote are added to the resource graph, and whether the
+// streams of its cameras are proxied, so that connecting to a large remote does not pull in resources
+// that are not used.
+type RemoteResources struct {
+	// Include, if not empty, limits the resources added to those matching any of the matchers.
+	Include []RemoteResourceMatcher `json:"include,omitempty"`
+	// Exclude skips the resources matching any of the matchers, even if they are included.
+	Exclude []RemoteResourceMatcher `json:"exclude,omitempty"`
+	// ProxyStreams is whether the cameras of the remote are streamed through this machine. Defaults to true.
+	ProxyStreams *bool `json:"proxy_streams,omitempty"`
+}
+
+// A RemoteResourceMatcher matches resources of a remote by API, by name, or by both. Both are glob
+// patterns as accepted by path.Match, e.g. {"api": "rdk:component:camera", "name": "front_*"}.
+type RemoteResourceMatcher struct {
+	API  string `json:"api,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// Imports returns whether the resource of the remote with the given name, as named by the remote, is
+// added to the resource graph. All resources are imported if r is nil.
+func (r *RemoteResources) Imports(name resource.Name) bool {
+	if r == nil {
+		return true
+	}
+	matchesAny := func(matchers []RemoteResourceMatcher) bool {
+		for _, m := range matchers {
+			if m.matches(name) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(r.Include) > 0 && !matchesAny(r.Include) {
+		return false
+	}
+	return !matchesAny(r.Exclude)
+}
+
+// ProxiesStreams returns whether the cameras of the remote are streamed through this machine.
+func (r *RemoteResources) ProxiesStreams() bool {
+	return r == nil || r.ProxyStreams == nil || *r.ProxyStreams
+}
+
+func (r *RemoteResources) validate(path string) error {
+	for idx, m := range r.Include {
+		if err := m.validate(fmt.Sprintf("%s.include.%d", path, idx)); err != nil {
+			return err
+		}
+	}
+	for idx, m := range r.Exclude {
+		if err := m.validate(fmt.Sprintf("%s.exclude.%d", path, idx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m RemoteResourceMatcher) matches(name resource.Name) bool {
+	// patterns are validated, so matching cannot fail.
+	if m.API != "" {
+		if ok, _ := path.Match(m.API, name.API.String()); !ok {
+			return false
+		}
+	}
+	if m.Name != "" {
+		if ok, _ := path.Match(m.Name, name.ShortName()); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (m RemoteResourceMatcher) validate(matcherPath string) error {
+	if m.API == "" && m.Name == "" {
+		return resource.NewConfigValidationError(matcherPath, errors.New("must specify an api, a name, or both"))
+	}
+	if _, err := path.Match(m.API, ""); err != nil {
+		return resource.NewConfigValidationError(matcherPath, errors.Wrapf(err, "invalid api pattern %q", m.API))
+	}
+	if _, err := path.Match(m.Name, ""); err != nil {
+		return resource.NewConfigValidationError(matcherPath, errors.Wrapf(err, "invalid name pattern %q", m.Name))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// systemPackageManager checks whether system packages are installed with the package manager of the
+// host and suggests how to install those that are not.
+type systemPackageManager struct {
+	// query is the command whose output contains installed, and which succeeds, if pkg is installed.
+	query     func(pkg string) []string
+	installed string
+	install   string
+}
+
+// systemPackageManagers are checked in order, and the first one present on the host is used.
+var systemPackageManagers = []systemPackageManager{
+	{
+		query:     func(pkg string) []string { return []string{"dpkg-query", "-W", "-f=${Status}", pkg} },
+		installed: "install ok installed",
+		install:   "sudo apt-get install -y",
+	},
+	{
+		query:   func(pkg string) []string { return []string{"rpm", "-q", pkg} },
+		install: "sudo dnf install -y",
+	},
+	{
+		query:   func(pkg string) []string { return []string{"apk", "info", "-e", pkg} },
+		install: "sudo apk add",
+	},
+	{
+		query:   func(pkg string) []string { return []string{"brew", "list", "--versions", pkg} },
+		install: "brew install",
+	},
+}
+
+// MissingSystemPackagesError is returned by FirstRun when system packages that the meta.json of a module
+// requires are not installed after its first run script ran.
+type MissingSystemPackagesError struct {
+	Module   string
+	Packages []string
+	// InstallCommand installs the missing packages with the package manager of the host.
+	InstallCommand string
+	// Err is the error of the first run script, if it failed.
+	Err error
+}
+
+func (e *MissingSystemPackagesError) Error() string {
+	msg := fmt.Sprintf("module %s requires system packages that are not installed: %s; install them with %q",
+		e.Module, strings.Join(e.Packages, ", "), e.InstallCommand)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *MissingSystemPackagesError) Unwrap() error {
+	return e.Err
+}
+
+// missingSystemPackages returns the packages that are not installed and the command that installs them.
+// ok is false if the host has no package manager that packages can be checked with.
+func missingSystemPackages(ctx context.Context, packages []string) (missing []string, installCommand string, ok bool) {
+	for _, manager := range systemPackageManagers {
+		if _, err := exec.LookPath(manager.query("")[0]); err != nil {
+			continue
+		}
+		for _, pkg := range packages {
+			args := manager.query(pkg)
+			//nolint:gosec // the packages come from the meta.json of the module, which is run regardless.
+			out, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+			if err != nil || !strings.Contains(string(out), manager.installed) {
+				missing = append(missing, pkg)
+			}
+		}
+		if len(missing) == 0 {
+			return nil, "", true
+		}
+		return missing, manager.install + " " + strings.Join(missing, " "), true
+	}
+	return nil, "", false
+}
@@ -0,0 +1,112 @@
+package ftdc
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PrometheusWriter writes metrics in the Prometheus text exposition format. Every sample of a
+// metric family must be written right after the family is declared, as the format requires the
+// samples of a family to be grouped together.
+//
+// Format reference: https://prometheus.io/docs/instrumenting/exposition_formats/
+type PrometheusWriter struct {
+	w   io.Writer
+	err error
+}
+
+// NewPrometheusWriter returns a PrometheusWriter that writes to w.
+func NewPrometheusWriter(w io.Writer) *PrometheusWriter {
+	return &PrometheusWriter{w: w}
+}
+
+// Family declares a metric family with its type, such as "counter" or "gauge", and help text.
+func (pw *PrometheusWriter) Family(name, metricType, help string) {
+	pw.printf("# HELP %s %s\n# TYPE %s %s\n", name, strings.ReplaceAll(help, "\n", " "), name, metricType)
+}
+
+// Sample writes a single sample of the named family. labels are alternating label names and
+// values.
+func (pw *PrometheusWriter) Sample(name string, value float64, labels ...string) {
+	var sb strings.Builder
+	sb.WriteString(name)
+	if len(labels) > 0 {
+		sb.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			sb.WriteString(labels[i])
+			sb.WriteString(`="`)
+			sb.WriteString(escapeLabelValue(labels[i+1]))
+			sb.WriteByte('"')
+		}
+		sb.WriteByte('}')
+	}
+	pw.printf("%s %s\n", sb.String(), strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// Err returns the first error encountered while writing, if any.
+func (pw *PrometheusWriter) Err() error {
+	return pw.err
+}
+
+func (pw *PrometheusWriter) printf(format string, args ...any) {
+	if pw.err != nil {
+		return
+	}
+	_, pw.err = fmt.Fprintf(pw.w, format, args...)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// WritePrometheus writes the current reading of every registered statser as a sample of the
+// `viam_ftdc` gauge, labeled with the statser's section name and the reading's metric name.
+func (ftdc *FTDC) WritePrometheus(pw *PrometheusWriter) {
+	datum := ftdc.constructDatum()
+	sections := make([]string, 0, len(datum.Data))
+	for section := range datum.Data {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	pw.Family("viam_ftdc", "gauge", "A reading collected by FTDC, labeled with its section and metric name.")
+	for _, section := range sections {
+		fields, values, err := flatten(reflect.ValueOf(datum.Data[section]))
+		if err != nil {
+			ftdc.logger.Debugw("Cannot export ftdc section as prometheus metrics", "section", section, "err", err)
+			continue
+		}
+		for idx, field := range fields {
+			pw.Sample("viam_ftdc", float64(values[idx]), "section", section, "metric", field)
+		}
+	}
+}
+
+// WriteGoRuntimePrometheus writes statistics about the Go runtime of the current process.
+func WriteGoRuntimePrometheus(pw *PrometheusWriter) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	pw.Family("go_goroutines", "gauge", "Number of goroutines that currently exist.")
+	pw.Sample("go_goroutines", float64(runtime.NumGoroutine()))
+	pw.Family("go_memstats_heap_alloc_bytes", "gauge", "Number of heap bytes allocated and still in use.")
+	pw.Sample("go_memstats_heap_alloc_bytes", float64(mem.HeapAlloc))
+	pw.Family("go_memstats_heap_inuse_bytes", "gauge", "Number of heap bytes that are in use.")
+	pw.Sample("go_memstats_heap_inuse_bytes", float64(mem.HeapInuse))
+	pw.Family("go_memstats_sys_bytes", "gauge", "Number of bytes obtained from the system.")
+	pw.Sample("go_memstats_sys_bytes", float64(mem.Sys))
+	pw.Family("go_gc_cycles_total", "counter", "Number of completed GC cycles.")
+	pw.Sample("go_gc_cycles_total", float64(mem.NumGC))
+	pw.Family("go_gc_pause_seconds_total", "counter", "Total time spent in GC stop-the-world pauses.")
+	pw.Sample("go_gc_pause_seconds_total", float64(mem.PauseTotalNs)/1e9)
+}
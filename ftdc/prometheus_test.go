@@ -0,0 +1,50 @@
+package ftdc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestPrometheusWriter(t *testing.T) {
+	var buf bytes.Buffer
+	pw := NewPrometheusWriter(&buf)
+	pw.Family("requests_total", "counter", "Number of\nrequests.")
+	pw.Sample("requests_total", 3, "method", `a"b\c`)
+	pw.Sample("requests_total", 0.5)
+	test.That(t, pw.Err(), test.ShouldBeNil)
+	test.That(t, buf.String(), test.ShouldEqual, strings.Join([]string{
+		"# HELP requests_total Number of requests.",
+		"# TYPE requests_total counter",
+		`requests_total{method="a\"b\\c"} 3`,
+		"requests_total 0.5",
+		"",
+	}, "\n"))
+}
+
+func TestWritePrometheus(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ftdc := NewWithWriter(bytes.NewBuffer(nil), logger.Sublogger("ftdc"))
+	ftdc.Add("nested", &nestedStatser{x: 1, z: 2})
+	ftdc.Add("foo", &foo{x: 3, y: 4})
+
+	var buf bytes.Buffer
+	pw := NewPrometheusWriter(&buf)
+	ftdc.WritePrometheus(pw)
+	test.That(t, pw.Err(), test.ShouldBeNil)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	test.That(t, lines[2:], test.ShouldResemble, []string{
+		`viam_ftdc{section="foo",metric="X"} 3`,
+		`viam_ftdc{section="foo",metric="Y"} 4`,
+		`viam_ftdc{section="nested",metric="X"} 1`,
+		`viam_ftdc{section="nested",metric="Y.Z"} 2`,
+	})
+
+	buf.Reset()
+	WriteGoRuntimePrometheus(pw)
+	test.That(t, buf.String(), test.ShouldContainSubstring, "# TYPE go_goroutines gauge\ngo_goroutines ")
+}
@@ -179,6 +179,17 @@ func (imp *impl) WithOptions(opts ...zap.Option) *zap.SugaredLogger {
 }
 
 func (imp *impl) WithFields(args ...interface{}) Logger {
+	// The child shares the parent's `impl`, so appenders are not copied and level changes made
+	// through the registry apply to both.
+	return &implWith{
+		imp,
+		fieldsFromArgs(args),
+	}
+}
+
+// fieldsFromArgs turns alternating keys and values into fields. Keys that are neither strings nor
+// `fmt.Stringer`s are dropped along with their values.
+func fieldsFromArgs(args []interface{}) []zapcore.Field {
 	// fields will always get added as the last key-value pair(s) to a log line
 	fields := make([]zapcore.Field, 0, len(args)/2+1)
 
@@ -204,10 +215,7 @@ func (imp *impl) WithFields(args ...interface{}) Logger {
 		}
 	}
 
-	return &implWith{
-		imp,
-		fields,
-	}
+	return fields
 }
 
 func (imp *impl) AsZap() *zap.SugaredLogger {
@@ -676,6 +684,48 @@ func getCaller() zapcore.EntryCaller {
 	return entryCaller
 }
 
+// WithFields returns a child logger that logs the fields of imp followed by those of args.
+func (imp *implWith) WithFields(args ...interface{}) Logger {
+	// Copy rather than append so that siblings made from the same parent do not share a backing
+	// array.
+	fields := make([]zapcore.Field, 0, len(imp.logFields)+len(args)/2+1)
+	fields = append(fields, imp.logFields...)
+	return &implWith{
+		imp.impl,
+		append(fields, fieldsFromArgs(args)...),
+	}
+}
+
+// Sublogger returns a sublogger that keeps the fields of imp. The sublogger is registered, and
+// found again, by name in the registry as any other, such that its level may be configured.
+func (imp *implWith) Sublogger(subname string) Logger {
+	sublogger := imp.impl.Sublogger(subname)
+	subImpl, ok := sublogger.(*impl)
+	if !ok {
+		return sublogger
+	}
+	return &implWith{
+		subImpl,
+		imp.logFields,
+	}
+}
+
+func (imp *implWith) AsZap() *zap.SugaredLogger {
+	return imp.impl.AsZap().Desugar().With(imp.logFields...).Sugar()
+}
+
+func (imp *implWith) Desugar() *zap.Logger {
+	return imp.AsZap().Desugar()
+}
+
+func (imp *implWith) Named(name string) *zap.SugaredLogger {
+	return imp.AsZap().Named(name)
+}
+
+func (imp *implWith) WithOptions(opts ...zap.Option) *zap.SugaredLogger {
+	return imp.AsZap().WithOptions(opts...)
+}
+
 func (imp *implWith) Debug(args ...interface{}) {
 	imp.testHelper()
 	if imp.shouldLog(DEBUG) {
@@ -432,6 +432,41 @@ func TestLoggingWithFields(t *testing.T) {
 		`2023-10-30T09:12:09.459Z	DEBUG	impl	logging/impl_test.go:200	Debugw log	{"traceKey":"foobar","k":"v","key":"value"}`)
 }
 
+func TestWithFieldsChildLoggers(t *testing.T) {
+	logger, observedLogs, registry := NewObservedTestLoggerWithRegistry(t, "parent")
+	fields := func() map[string]interface{} {
+		t.Helper()
+		all := observedLogs.TakeAll()
+		test.That(t, all, test.ShouldHaveLength, 1)
+		return all[0].ContextMap()
+	}
+
+	withFields := logger.WithFields("resource", "arm1")
+	child := withFields.WithFields("attempt", 2)
+	child.Infow("moving", "speed", 10)
+	test.That(t, fields(), test.ShouldResemble, map[string]interface{}{"speed": int64(10), "resource": "arm1", "attempt": int64(2)})
+
+	// Children do not change the fields of their parent.
+	withFields.Info("moving")
+	test.That(t, fields(), test.ShouldResemble, map[string]interface{}{"resource": "arm1"})
+
+	// Subloggers keep their parent's fields, and are registered by name so that their level follows the
+	// registry's config.
+	sub := withFields.Sublogger("sub")
+	sub.Debug("planning")
+	test.That(t, fields(), test.ShouldResemble, map[string]interface{}{"resource": "arm1"})
+
+	registry.Update([]LoggerPatternConfig{{Pattern: "parent.sub", Level: "WARN"}}, logger)
+	test.That(t, sub.GetLevel(), test.ShouldEqual, WARN)
+	sub.Info("planning")
+	test.That(t, observedLogs.TakeAll(), test.ShouldBeEmpty)
+	test.That(t, logger.Sublogger("sub").GetLevel(), test.ShouldEqual, WARN)
+
+	// Loggers converted to zap keep the fields too.
+	withFields.AsZap().Info("moving")
+	test.That(t, fields(), test.ShouldResemble, map[string]interface{}{"resource": "arm1"})
+}
+
 func TestLogEntryHashKey(t *testing.T) {
 	testCases := []struct {
 		name            string
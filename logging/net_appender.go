@@ -27,6 +27,7 @@ import (
 var (
 	defaultMaxQueueSize        = 20000
 	writeBatchSize             = 100
+	defaultFlushInterval       = 100 * time.Millisecond
 	errUninitializedConnection = errors.New("sharedConn is true and connection is not initialized")
 	logWriteTimeout            = 4 * time.Second
 	logWriteTimeoutBehindProxy = time.Minute
@@ -39,6 +40,59 @@ type CloudConfig struct {
 	CloudCred  rpc.DialOption
 }
 
+// NetAppenderOverflowPolicy is what a NetAppender does with entries logged while its queue is full.
+type NetAppenderOverflowPolicy string
+
+const (
+	// NetAppenderDropOldest discards the oldest queued entry to make room for the new one.
+	NetAppenderDropOldest NetAppenderOverflowPolicy = "drop_oldest"
+	// NetAppenderBlock makes the caller logging the entry wait until the queue has room. The
+	// NetAppender's own entries, and entries logged while it closes, are never blocked on.
+	NetAppenderBlock NetAppenderOverflowPolicy = "block"
+)
+
+// NetAppenderConfig configures how a NetAppender queues and batches entries before sending them.
+// Zero values take their defaults.
+type NetAppenderConfig struct {
+	// MaxQueueSize is the most entries held while they wait to be sent, which defaults to 20000.
+	MaxQueueSize int `json:"max_queue_size,omitempty"`
+	// BatchSize is the most entries sent at once, which defaults to 100.
+	BatchSize int `json:"batch_size,omitempty"`
+	// FlushIntervalMS is how often queued entries are sent while online, which defaults to 100ms.
+	FlushIntervalMS int `json:"flush_interval_ms,omitempty"`
+	// OverflowPolicy is what is done with entries logged while the queue is full, which defaults
+	// to NetAppenderDropOldest.
+	OverflowPolicy NetAppenderOverflowPolicy `json:"overflow_policy,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *NetAppenderConfig) Validate() error {
+	switch {
+	case cfg.MaxQueueSize < 0:
+		return errors.New("max_queue_size cannot be negative")
+	case cfg.BatchSize < 0:
+		return errors.New("batch_size cannot be negative")
+	case cfg.FlushIntervalMS < 0:
+		return errors.New("flush_interval_ms cannot be negative")
+	}
+	switch cfg.OverflowPolicy {
+	case "", NetAppenderDropOldest, NetAppenderBlock:
+	default:
+		return fmt.Errorf("overflow_policy must be %q or %q, not %q", NetAppenderDropOldest, NetAppenderBlock, cfg.OverflowPolicy)
+	}
+	return nil
+}
+
+// NetAppenderStats are counts of the entries a NetAppender has handled since it was created.
+type NetAppenderStats struct {
+	// Queued is the number of entries currently waiting to be sent.
+	Queued int
+	// Sent is the number of entries sent.
+	Sent int64
+	// Dropped is the number of entries discarded because the queue was full.
+	Dropped int64
+}
+
 // NewNetAppender creates a NetAppender to send log events to the app backend. NetAppenders ought to
 // be `Close`d prior to shutdown to flush remaining logs.
 // Pass `nil` for `conn` if you want this to create its own connection.
@@ -86,8 +140,12 @@ func newNetAppender(
 		cancel:           cancel,
 		remoteWriter:     logWriter,
 		maxQueueSize:     defaultMaxQueueSize,
+		batchSize:        writeBatchSize,
+		flushInterval:    defaultFlushInterval,
+		overflowPolicy:   NetAppenderDropOldest,
 		loggerWithoutNet: loggerWithoutNet,
 	}
+	nl.toLogSpace = sync.NewCond(&nl.toLogMutex)
 
 	nl.SetConn(conn, sharedConn)
 
@@ -103,12 +161,21 @@ type NetAppender struct {
 	hostname     string
 	remoteWriter *remoteLogWriterGRPC
 
-	// toLogMutex guards toLog and toLogOverflowsSinceLastSync.
+	// toLogMutex guards toLog, toLogOverflowsSinceLastSync, the counts of sent and dropped entries,
+	// and the queue's config.
 	toLogMutex                  sync.Mutex
 	toLog                       []*commonpb.LogEntry
 	toLogOverflowsSinceLastSync int
+	// toLogSpace is signalled when entries leave the queue, for callers blocked on it being full.
+	// It is nil for NetAppenders which were not made by newNetAppender, which never block.
+	toLogSpace *sync.Cond
+	sent       int64
+	dropped    int64
 
-	maxQueueSize int
+	maxQueueSize   int
+	batchSize      int
+	flushInterval  time.Duration
+	overflowPolicy NetAppenderOverflowPolicy
 
 	cancelCtx               context.Context
 	cancel                  func()
@@ -145,6 +212,45 @@ func (nl *NetAppender) SetConn(conn rpc.ClientConn, sharedConn bool) {
 	nl.remoteWriter.setConn(nl.cancelCtx, nl.loggerWithoutNet, conn, sharedConn)
 }
 
+// SetConfig changes how the NetAppender queues and batches entries. A nil cfg restores the
+// defaults. Shrinking the queue below the number of entries in it drops the oldest of them.
+func (nl *NetAppender) SetConfig(cfg *NetAppenderConfig) {
+	if cfg == nil {
+		cfg = &NetAppenderConfig{}
+	}
+	nl.toLogMutex.Lock()
+	defer nl.toLogMutex.Unlock()
+
+	nl.maxQueueSize = cfg.MaxQueueSize
+	if nl.maxQueueSize == 0 {
+		nl.maxQueueSize = defaultMaxQueueSize
+	}
+	nl.batchSize = cfg.BatchSize
+	if nl.batchSize == 0 {
+		nl.batchSize = writeBatchSize
+	}
+	nl.flushInterval = time.Duration(cfg.FlushIntervalMS) * time.Millisecond
+	if nl.flushInterval == 0 {
+		nl.flushInterval = defaultFlushInterval
+	}
+	nl.overflowPolicy = cfg.OverflowPolicy
+	if nl.overflowPolicy == "" {
+		nl.overflowPolicy = NetAppenderDropOldest
+	}
+
+	if overflow := len(nl.toLog) - nl.maxQueueSize; overflow > 0 {
+		nl.dropOldest(overflow)
+	}
+	nl.signalSpace()
+}
+
+// Stats returns counts of the entries the NetAppender has handled, for FTDC.
+func (nl *NetAppender) Stats() any {
+	nl.toLogMutex.Lock()
+	defer nl.toLogMutex.Unlock()
+	return NetAppenderStats{Queued: len(nl.toLog), Sent: nl.sent, Dropped: nl.dropped}
+}
+
 func (nl *NetAppender) queueSize() int {
 	nl.toLogMutex.Lock()
 	defer nl.toLogMutex.Unlock()
@@ -158,6 +264,10 @@ func (nl *NetAppender) cancelBackgroundWorkers() {
 		nl.cancel()
 		nl.cancel = nil
 	}
+	// wake callers blocked on a full queue, which stop blocking once the NetAppender is cancelled
+	nl.toLogMutex.Lock()
+	nl.signalSpace()
+	nl.toLogMutex.Unlock()
 	nl.activeBackgroundWorkers.Wait()
 }
 
@@ -236,6 +346,16 @@ func newInternalLogEntry(level zapcore.Level, message string) zapcore.Entry {
 }
 
 func (nl *NetAppender) Write(e zapcore.Entry, f []zapcore.Field) error {
+	return nl.write(e, f, true)
+}
+
+// writeInternal writes the NetAppender's own entries, which never block on a full queue as the
+// background worker that empties it writes them.
+func (nl *NetAppender) writeInternal(e zapcore.Entry) error {
+	return nl.write(e, nil, false)
+}
+
+func (nl *NetAppender) write(e zapcore.Entry, f []zapcore.Field, mayBlock bool) error {
 	log := &commonpb.LogEntry{
 		Host:       nl.hostname,
 		Level:      e.Level.String(),
@@ -261,7 +381,7 @@ func (nl *NetAppender) Write(e zapcore.Entry, f []zapcore.Field) error {
 	}
 	log.Fields = fields
 
-	nl.addToQueue(log)
+	nl.addToQueue(log, mayBlock)
 
 	if e.Level == zapcore.FatalLevel || e.Level == zapcore.DPanicLevel || e.Level == zapcore.PanicLevel {
 		// program is going to go away, let's try and sync all our messages before then
@@ -271,18 +391,23 @@ func (nl *NetAppender) Write(e zapcore.Entry, f []zapcore.Field) error {
 	return nil
 }
 
-// addToQueue adds a LogEntry to the net appender's queue, discarding the
-// oldest entry in the queue if the size of the queue has overflowed.
-func (nl *NetAppender) addToQueue(logEntry *commonpb.LogEntry) {
+// addToQueue adds a LogEntry to the net appender's queue. If the queue is full, either the oldest
+// entry in the queue is discarded, or if mayBlock is set and the overflow policy is to block, the
+// caller waits until the queue has room or the net appender is cancelled.
+func (nl *NetAppender) addToQueue(logEntry *commonpb.LogEntry, mayBlock bool) {
 	nl.toLogMutex.Lock()
 	defer nl.toLogMutex.Unlock()
 
+	if mayBlock && nl.overflowPolicy == NetAppenderBlock && nl.toLogSpace != nil {
+		for len(nl.toLog) >= nl.maxQueueSize && nl.cancelCtx.Err() == nil {
+			nl.toLogSpace.Wait()
+		}
+	}
 	if len(nl.toLog) >= nl.maxQueueSize {
 		// TODO(RSDK-7000): Selectively kick logs out of the queue based on log
 		// content (i.e. don't maintain 20000 trivial logs of "Foo" when other,
 		// potentially important information is being logged).
-		nl.toLog = nl.toLog[1:]
-		nl.toLogOverflowsSinceLastSync++
+		nl.dropOldest(len(nl.toLog) - nl.maxQueueSize + 1)
 	}
 	nl.toLog = append(nl.toLog, logEntry)
 }
@@ -299,6 +424,7 @@ func (nl *NetAppender) addBatchToQueue(batch []*commonpb.LogEntry) {
 	defer nl.toLogMutex.Unlock()
 
 	if len(batch) > nl.maxQueueSize {
+		nl.dropped += int64(len(batch) - nl.maxQueueSize)
 		batch = batch[len(batch)-nl.maxQueueSize:]
 	}
 
@@ -306,18 +432,35 @@ func (nl *NetAppender) addBatchToQueue(batch []*commonpb.LogEntry) {
 		// TODO(RSDK-7000): Selectively kick logs out of the queue based on log
 		// content (i.e. don't maintain 20000 trivial logs of "Foo" when other,
 		// potentially important information is being logged).
-		overflow := len(nl.toLog) + len(batch) - nl.maxQueueSize
-		nl.toLog = nl.toLog[overflow:]
-		nl.toLogOverflowsSinceLastSync += overflow
+		nl.dropOldest(len(nl.toLog) + len(batch) - nl.maxQueueSize)
 	}
 
 	nl.toLog = append(nl.toLog, batch...)
 }
 
+// dropOldest discards the oldest n entries in the queue. toLogMutex must be held.
+func (nl *NetAppender) dropOldest(n int) {
+	// The dropped entries are not cleared, as a batch of them may be being sent.
+	nl.toLog = nl.toLog[n:]
+	nl.toLogOverflowsSinceLastSync += n
+	nl.dropped += int64(n)
+}
+
+// signalSpace wakes callers blocked on a full queue. toLogMutex must be held.
+func (nl *NetAppender) signalSpace() {
+	if nl.toLogSpace != nil {
+		nl.toLogSpace.Broadcast()
+	}
+}
+
 func (nl *NetAppender) backgroundWorker() {
-	normalInterval := 100 * time.Millisecond
+	normalInterval := func() time.Duration {
+		nl.toLogMutex.Lock()
+		defer nl.toLogMutex.Unlock()
+		return nl.flushInterval
+	}
 	abnormalInterval := 5 * time.Second
-	interval := normalInterval
+	interval := normalInterval()
 
 	// used as a set. could be changed to store Timestamp or count if needed
 	errsSinceLastOnline := make(map[string]struct{})
@@ -347,13 +490,13 @@ func (nl *NetAppender) backgroundWorker() {
 				nl.loggerWithoutNet.Info(errMsg)
 
 				entry := newInternalLogEntry(zapcore.InfoLevel, errMsg)
-				err := nl.Write(entry, nil)
+				err := nl.writeInternal(entry)
 				if err != nil {
 					nl.loggerWithoutNet.Warnw("Unable to add to net log queue", "entry", entry, "err", err)
 				}
 			}
 		} else {
-			interval = normalInterval
+			interval = normalInterval()
 			clear(errsSinceLastOnline)
 		}
 	}
@@ -377,10 +520,7 @@ func (nl *NetAppender) syncOnce() (bool, error) {
 		return false, nil
 	}
 
-	batchSize := writeBatchSize
-	if len(nl.toLog) < writeBatchSize {
-		batchSize = len(nl.toLog)
-	}
+	batchSize := min(nl.batchSize, len(nl.toLog))
 
 	// Read a batch from the queue, unlock mutex, and return an error if write
 	// fails. Lock mutex again to remove batch from queue only if write succeeded
@@ -410,6 +550,8 @@ func (nl *NetAppender) syncOnce() (bool, error) {
 		idx := min(batchSize-nl.toLogOverflowsSinceLastSync, len(nl.toLog))
 		nl.toLog = nl.toLog[idx:]
 	}
+	nl.sent += int64(batchSize)
+	nl.signalSpace()
 
 	toLogOverflowsSinceLastSync := nl.toLogOverflowsSinceLastSync
 	nl.toLogOverflowsSinceLastSync = 0
@@ -429,7 +571,7 @@ func (nl *NetAppender) syncOnce() (bool, error) {
 
 		// Manually create new log entry & add to cloud queue
 		entry := newInternalLogEntry(zapcore.WarnLevel, overflowMsg)
-		err := nl.Write(entry, nil)
+		err := nl.writeInternal(entry)
 		if err != nil {
 			nl.loggerWithoutNet.Warnw("Unable to add to net log queue", "entry", entry, "err", err)
 		}
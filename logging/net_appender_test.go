@@ -40,17 +40,112 @@ func TestNetLoggerQueueOperations(t *testing.T) {
 			maxQueueSize: queueSize,
 		}
 
-		nl.addToQueue(&commonpb.LogEntry{})
+		nl.addToQueue(&commonpb.LogEntry{}, false)
 		test.That(t, nl.queueSize(), test.ShouldEqual, 1)
 
-		nl.addToQueue(&commonpb.LogEntry{})
+		nl.addToQueue(&commonpb.LogEntry{}, false)
 		test.That(t, nl.queueSize(), test.ShouldEqual, queueSize)
 
-		nl.addToQueue(&commonpb.LogEntry{})
+		nl.addToQueue(&commonpb.LogEntry{}, false)
 		test.That(t, nl.queueSize(), test.ShouldEqual, queueSize)
 	})
 }
 
+func TestNetLoggerConfig(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		test.That(t, (&NetAppenderConfig{}).Validate(), test.ShouldBeNil)
+		test.That(t, (&NetAppenderConfig{
+			MaxQueueSize: 10, BatchSize: 5, FlushIntervalMS: 50, OverflowPolicy: NetAppenderBlock,
+		}).Validate(), test.ShouldBeNil)
+
+		err := (&NetAppenderConfig{BatchSize: -1}).Validate()
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "batch_size")
+		err = (&NetAppenderConfig{OverflowPolicy: "drop_newest"}).Validate()
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "overflow_policy")
+	})
+
+	server := makeServerForRobotLogger(t)
+	defer server.stop()
+	setup := func(t *testing.T, cfg *NetAppenderConfig) (*NetAppender, Logger) {
+		t.Helper()
+		// The background worker is not started, so that entries are only sent when synced.
+		netAppender, err := newNetAppender(server.cloudConfig, nil, false, false, 0, NewTestLogger(t))
+		test.That(t, err, test.ShouldBeNil)
+		t.Cleanup(netAppender.Close)
+		netAppender.SetConfig(cfg)
+		logger := NewDebugLogger("test logger")
+		logger.AddAppender(netAppender)
+		return netAppender, logger
+	}
+
+	t.Run("batch size", func(t *testing.T) {
+		netAppender, logger := setup(t, &NetAppenderConfig{BatchSize: 3})
+		for i := 0; i < 7; i++ {
+			logger.Infof("Some-info %d", i)
+		}
+		test.That(t, netAppender.sync(), test.ShouldBeNil)
+		test.That(t, netAppender.Stats(), test.ShouldResemble, NetAppenderStats{Sent: 7})
+
+		server.service.logsMu.Lock()
+		defer server.service.logsMu.Unlock()
+		batches := server.service.logBatches[len(server.service.logBatches)-3:]
+		test.That(t, batches[0], test.ShouldHaveLength, 3)
+		test.That(t, batches[1], test.ShouldHaveLength, 3)
+		test.That(t, batches[2], test.ShouldHaveLength, 1)
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		netAppender, logger := setup(t, &NetAppenderConfig{MaxQueueSize: 3})
+		for i := 0; i < 4; i++ {
+			logger.Infof("Some-info %d", i)
+		}
+		test.That(t, netAppender.Stats(), test.ShouldResemble, NetAppenderStats{Queued: 3, Dropped: 1})
+		test.That(t, netAppender.toLog[0].GetMessage(), test.ShouldEqual, "Some-info 1")
+
+		// Shrinking the queue drops the entries that no longer fit.
+		netAppender.SetConfig(&NetAppenderConfig{MaxQueueSize: 1})
+		test.That(t, netAppender.Stats(), test.ShouldResemble, NetAppenderStats{Queued: 1, Dropped: 3})
+		test.That(t, netAppender.toLog[0].GetMessage(), test.ShouldEqual, "Some-info 3")
+
+		// The defaults are restored with a nil config.
+		netAppender.SetConfig(nil)
+		test.That(t, netAppender.maxQueueSize, test.ShouldEqual, defaultMaxQueueSize)
+		test.That(t, netAppender.batchSize, test.ShouldEqual, writeBatchSize)
+	})
+
+	t.Run("block", func(t *testing.T) {
+		netAppender, logger := setup(t, &NetAppenderConfig{MaxQueueSize: 1, OverflowPolicy: NetAppenderBlock})
+		logger.Info("first")
+
+		logged := make(chan struct{})
+		go func() {
+			logger.Info("second")
+			close(logged)
+		}()
+		select {
+		case <-logged:
+			t.Fatal("logging to a full queue should block")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		test.That(t, netAppender.sync(), test.ShouldBeNil)
+		<-logged
+		test.That(t, netAppender.Stats(), test.ShouldResemble, NetAppenderStats{Queued: 1, Sent: 1})
+		test.That(t, netAppender.toLog[0].GetMessage(), test.ShouldEqual, "second")
+
+		// Closing unblocks callers waiting on a full queue.
+		unblocked := make(chan struct{})
+		go func() {
+			logger.Info("third")
+			close(unblocked)
+		}()
+		netAppender.cancelBackgroundWorkers()
+		<-unblocked
+	})
+}
+
 type mockRobotService struct {
 	apppb.UnimplementedRobotServiceServer
 	expectedID string
@@ -326,7 +421,7 @@ func TestNetLoggerOverflowDuringWrite(t *testing.T) {
 	// receiving a non-nil err.
 	server.service.logsMu.Lock()
 	for i := 0; i < defaultMaxQueueSize; i++ {
-		netAppender.addToQueue(&commonpb.LogEntry{Message: fmt.Sprint(i)})
+		netAppender.addToQueue(&commonpb.LogEntry{Message: fmt.Sprint(i)}, false)
 	}
 
 	// Sleep to ensure syncOnce happens (normally every 100ms) and hangs in
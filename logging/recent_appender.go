@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultRecentLogsCapacity is the number of log lines a RecentLogsAppender keeps by default.
+const DefaultRecentLogsCapacity = 2000
+
+// RecentLogsAppender keeps the most recent log lines written to it in memory, formatted as the
+// ConsoleAppender formats them, such as for including in support bundles.
+type RecentLogsAppender struct {
+	capacity int
+
+	mu    sync.Mutex
+	lines []string
+}
+
+// NewRecentLogsAppender returns an appender that keeps the most recent capacity log lines, or
+// DefaultRecentLogsCapacity if capacity is not positive.
+func NewRecentLogsAppender(capacity int) *RecentLogsAppender {
+	if capacity <= 0 {
+		capacity = DefaultRecentLogsCapacity
+	}
+	return &RecentLogsAppender{capacity: capacity}
+}
+
+// Write formats the log entry and keeps it, dropping the oldest line if the appender is full.
+func (a *RecentLogsAppender) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var sb strings.Builder
+	if err := NewWriterAppender(&sb).Write(entry, fields); err != nil {
+		return err
+	}
+	line := strings.TrimSuffix(sb.String(), "\n")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.lines) == a.capacity {
+		a.lines = a.lines[1:]
+	}
+	a.lines = append(a.lines, line)
+	return nil
+}
+
+// Sync is a no-op, as lines are kept as soon as they are written.
+func (a *RecentLogsAppender) Sync() error {
+	return nil
+}
+
+// Lines returns the kept log lines, from oldest to newest.
+func (a *RecentLogsAppender) Lines() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	lines := make([]string, len(a.lines))
+	copy(lines, a.lines)
+	return lines
+}
@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"fmt"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestRecentLogsAppender(t *testing.T) {
+	appender := NewRecentLogsAppender(3)
+	logger := NewBlankLogger("recent")
+	logger.AddAppender(appender)
+
+	for i := 0; i < 5; i++ {
+		logger.Infow(fmt.Sprintf("message %d", i), "i", i)
+	}
+	lines := appender.Lines()
+	test.That(t, lines, test.ShouldHaveLength, 3)
+	for i, line := range lines {
+		test.That(t, line, test.ShouldContainSubstring, "INFO")
+		test.That(t, line, test.ShouldContainSubstring, "recent")
+		test.That(t, line, test.ShouldContainSubstring, fmt.Sprintf("message %d", i+2))
+		test.That(t, line, test.ShouldContainSubstring, fmt.Sprintf(`{"i":%d}`, i+2))
+		test.That(t, line, test.ShouldNotEndWith, "\n")
+	}
+
+	// the lines returned are a copy
+	lines[0] = "changed"
+	test.That(t, appender.Lines()[0], test.ShouldContainSubstring, "message 2")
+
+	test.That(t, NewRecentLogsAppender(0).capacity, test.ShouldEqual, DefaultRecentLogsCapacity)
+}
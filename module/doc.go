@@ -73,5 +73,18 @@ grpc services.
 
 In other languages, and for small modules not part of a larger code ecosystem, the registry concept may not make as much sense, and
 foregoing the registry step in favor of some more direct AddModel() call (which takes the creation handler func directly) may be better.
+
+# In-Process Modules
+
+For latency-critical drivers, such as high-rate motor controllers, the subprocess and gRPC hop can be skipped for trusted Golang modules.
+Since a module registers its models during init(), a custom viam-server binary that imports the package of a module serves its models
+as built-in resources. Alternatively, a module can be built as a Go plugin with "go build -buildmode=plugin" against the same version of
+the RDK as viam-server and configured with "in_process": true. Instead of starting the module, the modmanager then opens the plugin, which
+registers its models, and checks that the plugin exports the same models its main() would pass to ModularMain:
+
+	var Models = []resource.APIModel{{API: motor.API, Model: myModel}}
+
+Resources of in-process modules are constructed and called directly by the resource manager, like built-in resources. A Go plugin
+cannot be unloaded, so its models stay registered until viam-server exits.
 */
 package module
@@ -0,0 +1,35 @@
+package module
+
+import (
+	"fmt"
+	"plugin"
+
+	"go.viam.com/rdk/resource"
+)
+
+// InProcessModelsSymbol is the name of the variable that a module built as a Go plugin exports to list
+// the models it provides, the same models its main function would pass to ModularMain.
+const InProcessModelsSymbol = "Models"
+
+// LoadInProcess opens the Go plugin at path, which registers its models when it is first opened, and
+// returns the models it provides. Opening a plugin that is already open returns its models again.
+func LoadInProcess(path string) ([]resource.APIModel, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load in-process module %q: %w", path, err)
+	}
+	sym, err := p.Lookup(InProcessModelsSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("in-process module %q does not export %s: %w", path, InProcessModelsSymbol, err)
+	}
+	models, ok := sym.(*[]resource.APIModel)
+	if !ok {
+		return nil, fmt.Errorf("in-process module %q exports %s as %T instead of []resource.APIModel", path, InProcessModelsSymbol, sym)
+	}
+	for _, apiModel := range *models {
+		if _, ok := resource.LookupRegistration(apiModel.API, apiModel.Model); !ok {
+			return nil, fmt.Errorf("in-process module %q does not register API %q and model %q", path, apiModel.API, apiModel.Model)
+		}
+	}
+	return *models, nil
+}
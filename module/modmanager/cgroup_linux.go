@@ -0,0 +1,183 @@
+//go:build linux
+
+package modmanager
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/config"
+)
+
+const (
+	cgroupRoot = "/sys/fs/cgroup"
+	// cpuMaxPeriod is the period, in microseconds, over which the CPU quota of a module is enforced.
+	cpuMaxPeriod = 100000
+	// serverCgroupName is the leaf cgroup the server moves itself into when its own cgroup must hold
+	// the cgroups of modules instead.
+	serverCgroupName = "viam-server"
+)
+
+var (
+	modulesCgroupOnce sync.Once
+	modulesCgroupDir  string
+	errModulesCgroup  error
+)
+
+// moduleCgroup is a cgroups v2 control group limiting the CPU and memory of a module process.
+type moduleCgroup struct {
+	dir string
+	// oomKills is the number of OOM kills in the cgroup that have been counted.
+	oomKills uint64
+}
+
+// newModuleCgroup creates a cgroup for the module with the limits in its config. The cgroups of
+// modules are created in the cgroup of the server so that they stay within its own limits.
+func newModuleCgroup(cfg config.Module) (*moduleCgroup, error) {
+	modulesCgroupOnce.Do(func() {
+		modulesCgroupDir, errModulesCgroup = initModulesCgroup()
+	})
+	if errModulesCgroup != nil {
+		return nil, errModulesCgroup
+	}
+
+	dir := filepath.Join(modulesCgroupDir, fmt.Sprintf("module-%s-%s", cfg.Name, utils.RandomAlphaString(5)))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to create module cgroup")
+	}
+	cg := &moduleCgroup{dir: dir}
+	if err := cg.setLimits(cfg); err != nil {
+		utils.UncheckedError(cg.remove())
+		return nil, err
+	}
+	return cg, nil
+}
+
+func (cg *moduleCgroup) setLimits(cfg config.Module) error {
+	if cfg.CPULimit > 0 {
+		quota := max(int(cfg.CPULimit*cpuMaxPeriod), 1000)
+		if err := cg.write("cpu.max", fmt.Sprintf("%d %d", quota, cpuMaxPeriod)); err != nil {
+			return err
+		}
+	}
+	if cfg.MemoryLimitMB > 0 {
+		if err := cg.write("memory.max", strconv.Itoa(cfg.MemoryLimitMB*1024*1024)); err != nil {
+			return err
+		}
+		// kill every process of the module together so that it is restarted as a whole rather than
+		// left running without the process that was killed.
+		if err := cg.write("memory.oom.group", "1"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addProcess moves the process with the given PID, along with its threads, into the cgroup.
+func (cg *moduleCgroup) addProcess(pid int) error {
+	return cg.write("cgroup.procs", strconv.Itoa(pid))
+}
+
+// newOOMKills returns the number of processes of the module killed for exceeding its memory limit
+// since it was last called.
+func (cg *moduleCgroup) newOOMKills() (uint64, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(filepath.Join(cg.dir, "memory.events"))
+	if err != nil {
+		return 0, err
+	}
+	total, err := parseOOMKills(data)
+	if err != nil {
+		return 0, err
+	}
+	if total < cg.oomKills {
+		return 0, nil
+	}
+	newKills := total - cg.oomKills
+	cg.oomKills = total
+	return newKills, nil
+}
+
+// remove removes the cgroup, which only succeeds once every process in it has exited.
+func (cg *moduleCgroup) remove() error {
+	return os.Remove(cg.dir)
+}
+
+func (cg *moduleCgroup) write(file, value string) error {
+	if err := os.WriteFile(filepath.Join(cg.dir, file), []byte(value), 0o644); err != nil {
+		return errors.Wrapf(err, "failed to write %q to %s of module cgroup", value, file)
+	}
+	return nil
+}
+
+// initModulesCgroup enables the cpu and memory controllers for the children of the cgroup of the
+// server and returns it. cgroups v2 does not allow a cgroup that holds processes to enable controllers
+// for its children, so if the server's cgroup is not empty the server first moves itself into a leaf.
+func initModulesCgroup() (string, error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to find the cgroup of the server")
+	}
+	cgroupPath, err := parseCgroupPath(data)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cgroupRoot, cgroupPath)
+	if filepath.Base(dir) == serverCgroupName {
+		// the server is already in its leaf, such as when it was started in place of a previous server.
+		dir = filepath.Dir(dir)
+	}
+
+	subtreeControl := filepath.Join(dir, "cgroup.subtree_control")
+	enableControllers := func() error {
+		return os.WriteFile(subtreeControl, []byte("+cpu +memory"), 0o644)
+	}
+	err = enableControllers()
+	if errors.Is(err, syscall.EBUSY) {
+		leaf := filepath.Join(dir, serverCgroupName)
+		if err := os.Mkdir(leaf, 0o755); err != nil && !os.IsExist(err) {
+			return "", errors.Wrap(err, "failed to create cgroup for the server")
+		}
+		if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+			return "", errors.Wrap(err, "failed to move the server into its own cgroup")
+		}
+		err = enableControllers()
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to enable cpu and memory controllers in %s", dir)
+	}
+	return dir, nil
+}
+
+// parseCgroupPath returns the cgroups v2 path in the contents of a /proc/<pid>/cgroup file.
+func parseCgroupPath(data []byte) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		// the cgroups v2 hierarchy has ID 0 and no controllers, as in "0::/system.slice/viam.service".
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", errors.New("cgroups v2 is not available")
+}
+
+// parseOOMKills returns the oom_kill count in the contents of a memory.events file.
+func parseOOMKills(data []byte) (uint64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if count, ok := strings.CutPrefix(scanner.Text(), "oom_kill "); ok {
+			return strconv.ParseUint(count, 10, 64)
+		}
+	}
+	return 0, errors.New("memory.events has no oom_kill count")
+}
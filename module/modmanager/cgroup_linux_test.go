@@ -0,0 +1,32 @@
+//go:build linux
+
+package modmanager
+
+import (
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestParseCgroupPath(t *testing.T) {
+	path, err := parseCgroupPath([]byte("0::/system.slice/viam-agent.service\n"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, path, test.ShouldEqual, "/system.slice/viam-agent.service")
+
+	// hybrid hierarchies list cgroups v1 controllers before the cgroups v2 path.
+	path, err = parseCgroupPath([]byte("12:memory:/user.slice\n1:name=systemd:/user.slice\n0::/user.slice/session-2.scope\n"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, path, test.ShouldEqual, "/user.slice/session-2.scope")
+
+	_, err = parseCgroupPath([]byte("12:memory:/user.slice\n1:name=systemd:/user.slice\n"))
+	test.That(t, err, test.ShouldBeError, "cgroups v2 is not available")
+}
+
+func TestParseOOMKills(t *testing.T) {
+	kills, err := parseOOMKills([]byte("low 0\nhigh 0\nmax 12\noom 3\noom_kill 2\noom_group_kill 1\n"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, kills, test.ShouldEqual, uint64(2))
+
+	_, err = parseOOMKills([]byte("low 0\nhigh 0\n"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package modmanager
+
+import (
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/config"
+)
+
+// moduleCgroup limits the CPU and memory of a module process, which is only supported on Linux.
+type moduleCgroup struct{}
+
+func newModuleCgroup(cfg config.Module) (*moduleCgroup, error) {
+	return nil, errors.New("module resource limits are only supported on Linux")
+}
+
+func (cg *moduleCgroup) addProcess(pid int) error {
+	return nil
+}
+
+func (cg *moduleCgroup) newOOMKills() (uint64, error) {
+	return 0, nil
+}
+
+func (cg *moduleCgroup) remove() error {
+	return nil
+}
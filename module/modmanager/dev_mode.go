@@ -0,0 +1,138 @@
+package modmanager
+
+import (
+	"context"
+	"os"
+	"time"
+
+	pb "go.viam.com/api/module/v1"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/packages"
+)
+
+// devModePollInterval is how often the executables of local modules are checked for changes in dev
+// mode. A module is only restarted once its executable has stopped changing for an interval, so that
+// it is not started while it is still being written.
+var devModePollInterval = time.Second
+
+// executableState tracks the modification time of a module executable.
+type executableState struct {
+	// running is the modification time of the executable the module is running.
+	running time.Time
+	// last is the modification time seen at the last check.
+	last time.Time
+}
+
+// watchModuleExecutables restarts local modules whose executables change, so that module authors
+// only need to rebuild a module to reload it. Modules distributed as tarballs are not watched.
+func (mgr *Manager) watchModuleExecutables(ctx context.Context) {
+	ticker := time.NewTicker(devModePollInterval)
+	defer ticker.Stop()
+
+	// keyed by executable path rather than module name so that reconfiguring a module to another
+	// executable does not count as a change.
+	states := map[string]*executableState{}
+	for {
+		if !utils.SelectContextOrWaitChan(ctx, ticker.C) {
+			return
+		}
+
+		var changed []*module
+		seen := map[string]struct{}{}
+		mgr.modules.Range(func(_ string, mod *module) bool {
+			// in-process modules cannot be reloaded, since their plugins cannot be unloaded.
+			if mod.cfg.Type != config.ModuleTypeLocal || mod.cfg.NeedsSyntheticPackage() || mod.cfg.InProcess {
+				return true
+			}
+			exePath, err := mod.cfg.EvaluateExePath(packages.LocalPackagesDir(mgr.packagesDir))
+			if err != nil {
+				return true
+			}
+			info, err := os.Stat(exePath)
+			if err != nil {
+				// the executable may be missing while it is being rebuilt.
+				return true
+			}
+			seen[exePath] = struct{}{}
+			modTime := info.ModTime()
+			state, ok := states[exePath]
+			if !ok {
+				states[exePath] = &executableState{running: modTime, last: modTime}
+				return true
+			}
+			if !modTime.Equal(state.running) && modTime.Equal(state.last) {
+				state.running = modTime
+				changed = append(changed, mod)
+			}
+			state.last = modTime
+			return true
+		})
+		for exePath := range states {
+			if _, ok := seen[exePath]; !ok {
+				delete(states, exePath)
+			}
+		}
+
+		for _, mod := range changed {
+			mgr.reloadModule(ctx, mod)
+		}
+	}
+}
+
+// reloadModule restarts a module whose executable changed and hands its resources to
+// handleOrphanedResources to be re-added. If the new executable fails to start, the module is left
+// stopped until its executable changes again.
+func (mgr *Manager) reloadModule(ctx context.Context, mod *module) {
+	orphanedResourceNames, ok := mgr.restartChangedModule(ctx, mod)
+	if !ok {
+		return
+	}
+	mod.logger.CInfow(ctx, "Module restarted after executable change. Its resources will be re-added",
+		"module", mod.cfg.Name, "resources", orphanedResourceNames)
+	mgr.handleOrphanedResources(mgr.restartCtx, orphanedResourceNames)
+}
+
+// restartChangedModule restarts the module process and returns the resources that need to be re-added.
+func (mgr *Manager) restartChangedModule(ctx context.Context, mod *module) ([]resource.Name, bool) {
+	mgr.mu.Lock()
+	defer mgr.mu.Unlock()
+	if current, ok := mgr.modules.Load(mod.cfg.Name); !ok || current != mod {
+		// removed or replaced while the executable was being checked.
+		return nil, false
+	}
+	mod.logger.CInfow(ctx, "Module executable changed. Restarting module", "module", mod.cfg.Name)
+
+	// remove resources from the old process so that they can release what they hold before the new
+	// process adds them again.
+	removeCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	for name := range mod.resources {
+		if _, err := mod.client.RemoveResource(removeCtx, &pb.RemoveResourceRequest{Name: name.String()}); err != nil {
+			mod.logger.CDebugw(ctx, "Error removing resource before restart", "resource", name, "error", err)
+		}
+	}
+	cancel()
+	if err := mod.stopProcess(); err != nil {
+		mod.logger.CWarnw(ctx, "Error stopping module process before restart", "module", mod.cfg.Name, "error", err)
+	}
+	mod.cleanupAfterCrash(mgr)
+
+	// the new process gets its own restart context, as startModule gives it, so that removing or
+	// reconfiguring the module stops it from being restarted after a crash.
+	mod.restartCancel()
+	var moduleRestartCtx context.Context
+	moduleRestartCtx, mod.restartCancel = context.WithCancel(mgr.restartCtx)
+	if err := mgr.attemptRestart(moduleRestartCtx, mod); err != nil {
+		mgr.SetModuleStatusUnhealthy(mod.cfg.Name, err)
+		return nil, false
+	}
+	orphanedResourceNames := make([]resource.Name, 0, len(mod.resources))
+	for name := range mod.resources {
+		orphanedResourceNames = append(orphanedResourceNames, name)
+		mgr.rMap.Delete(name)
+		delete(mod.resources, name)
+	}
+	return orphanedResourceNames, true
+}
@@ -0,0 +1,193 @@
+package modmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+)
+
+const (
+	// name of the folder under the viamHomeDir that holds the diagnostics bundles of crashed modules
+	// ex: /home/walle/.viam/diagnostics/modules/<module-name>/<time>
+	diagnosticsFolderName = "diagnostics"
+	// diagnosticsTimeFormat names bundles so that they sort by the time the module exited.
+	diagnosticsTimeFormat = "20060102T150405.000Z"
+	redactedValue         = "<redacted>"
+)
+
+var (
+	// diagnosticsOutputLines is the number of the most recent lines of module output kept for its
+	// diagnostics bundle.
+	diagnosticsOutputLines = 200
+	// maxDiagnosticsBundles is the number of diagnostics bundles kept for each module. Older bundles are
+	// removed.
+	maxDiagnosticsBundles = 5
+)
+
+// outputLines keeps the most recent lines a module process wrote to stdout and stderr.
+type outputLines struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (o *outputLines) add(stream, line string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.lines) == diagnosticsOutputLines {
+		o.lines = o.lines[1:]
+	}
+	o.lines = append(o.lines, fmt.Sprintf("%s [%s] %s", time.Now().UTC().Format(logging.DefaultTimeFormatStr), stream, line))
+}
+
+func (o *outputLines) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.lines) == 0 {
+		return ""
+	}
+	return strings.Join(o.lines, "\n") + "\n"
+}
+
+// outputRecorder records the lines of output that pexec logs through it, which it logs at info level
+// for stdout and error level for stderr.
+type outputRecorder struct {
+	logging.Logger
+	stream string
+	output *outputLines
+}
+
+func (r *outputRecorder) Info(args ...interface{}) {
+	r.record(args)
+	r.Logger.Info(args...)
+}
+
+func (r *outputRecorder) Error(args ...interface{}) {
+	r.record(args)
+	r.Logger.Error(args...)
+}
+
+func (r *outputRecorder) record(args []interface{}) {
+	r.output.add(r.stream, strings.TrimPrefix(fmt.Sprint(args...), "\n\\_ "))
+}
+
+// crashReport describes an unexpected exit of a module in its diagnostics bundle.
+type crashReport struct {
+	Module    string    `json:"module"`
+	ExitCode  int       `json:"exit_code"`
+	PID       int       `json:"pid,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+	ExitedAt  time.Time `json:"exited_at"`
+	OOMKilled bool      `json:"oom_killed,omitempty"`
+	CoreDump  string    `json:"core_dump,omitempty"`
+}
+
+// writeDiagnosticsBundle collects the recent output, exit status, core dump if one was written, and
+// config of a module that exited unexpectedly into a directory under the viam home directory, and
+// returns its path.
+func (mgr *Manager) writeDiagnosticsBundle(mod *module, exitCode int, oomKilled bool) (string, error) {
+	modDir := filepath.Join(mgr.viamHomeDir, diagnosticsFolderName, "modules", mod.cfg.Name)
+	exitedAt := time.Now().UTC()
+	dir := filepath.Join(modDir, exitedAt.Format(diagnosticsTimeFormat))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", errors.Wrap(err, "failed to create diagnostics bundle")
+	}
+
+	report := crashReport{
+		Module:    mod.cfg.Name,
+		ExitCode:  exitCode,
+		StartedAt: mod.startedAt,
+		ExitedAt:  exitedAt,
+		OOMKilled: oomKilled,
+	}
+	if mod.process != nil {
+		report.PID, _ = mod.process.UnixPid()
+	}
+	if core := findCoreDump(mod.workingDir, report.PID, mod.startedAt); core != "" {
+		report.CoreDump = filepath.Join(dir, filepath.Base(core))
+		if err := os.Rename(core, report.CoreDump); err != nil {
+			// core dumps can be large, so one that can't be moved is left where it is.
+			report.CoreDump = core
+		}
+	}
+
+	// env vars of modules often hold credentials, so only their names are kept.
+	cfg := mod.cfg
+	if len(cfg.Environment) != 0 {
+		cfg.Environment = make(map[string]string, len(mod.cfg.Environment))
+		for name := range mod.cfg.Environment {
+			cfg.Environment[name] = redactedValue
+		}
+	}
+	if err := writeJSONFile(filepath.Join(dir, "exit.json"), report); err != nil {
+		return "", err
+	}
+	if err := writeJSONFile(filepath.Join(dir, "config.json"), cfg); err != nil {
+		return "", err
+	}
+	var output string
+	if mod.output != nil {
+		output = mod.output.String()
+	}
+	if err := os.WriteFile(filepath.Join(dir, "output.log"), []byte(output), 0o640); err != nil {
+		return "", errors.Wrap(err, "failed to write module output to diagnostics bundle")
+	}
+
+	pruneDiagnosticsBundles(modDir, mod.logger)
+	return dir, nil
+}
+
+// findCoreDump returns the path of a core dump written to the working directory of the module process
+// since it started, or the empty string if there is none, such as when core dumps are not enabled.
+func findCoreDump(workingDir string, pid int, startedAt time.Time) string {
+	if workingDir == "" {
+		return ""
+	}
+	for _, name := range []string{fmt.Sprintf("core.%d", pid), "core"} {
+		path := filepath.Join(workingDir, name)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && !info.ModTime().Before(startedAt) {
+			return path
+		}
+	}
+	return ""
+}
+
+func pruneDiagnosticsBundles(modDir string, logger logging.Logger) {
+	entries, err := os.ReadDir(modDir)
+	if err != nil {
+		logger.Debugw("Error reading diagnostics bundles", "dir", modDir, "error", err)
+		return
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+	for len(names) > maxDiagnosticsBundles {
+		if err := os.RemoveAll(filepath.Join(modDir, names[0])); err != nil {
+			logger.Debugw("Error removing diagnostics bundle", "bundle", names[0], "error", err)
+		}
+		names = names[1:]
+	}
+}
+
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o640); err != nil {
+		return errors.Wrapf(err, "failed to write %s to diagnostics bundle", filepath.Base(path))
+	}
+	return nil
+}
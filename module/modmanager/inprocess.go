@@ -0,0 +1,38 @@
+package modmanager
+
+import (
+	modlib "go.viam.com/rdk/module"
+	"go.viam.com/rdk/robot/packages"
+)
+
+// startInProcessModule loads a module into the viam-server process instead of starting its process. The
+// plugin of the module registers its models with their own constructors, so the resource manager
+// constructs and calls its resources directly, as it does built-in resources, rather than through
+// AddResource.
+func (mgr *Manager) startInProcessModule(mod *module) error {
+	mgr.setModuleStatusStarting(mod.cfg.Name)
+	exePath, err := mod.cfg.EvaluateExePath(packages.LocalPackagesDir(mgr.packagesDir))
+	if err != nil {
+		return err
+	}
+	models, err := modlib.LoadInProcess(exePath)
+	if err != nil {
+		return err
+	}
+	for _, apiModel := range models {
+		mod.logger.Infow("Using API and model from in-process module", "module", mod.cfg.Name,
+			"API", apiModel.API, "model", apiModel.Model)
+	}
+	mod.inProcessModels = models
+	return nil
+}
+
+// closeInProcessModule removes an in-process module. Its plugin cannot be unloaded, so its models stay
+// registered and resources using them are left to the resource manager.
+func (mgr *Manager) closeInProcessModule(mod *module) {
+	mod.restartCancel()
+	mod.inProcessModels = nil
+	mgr.modules.Delete(mod.cfg.Name)
+	mgr.removeModuleStatus(mod.cfg.Name)
+	mod.logger.Infow("In-process module closed. Its models stay registered until viam-server exits", "module", mod.cfg.Name)
+}
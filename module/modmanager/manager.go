@@ -66,6 +66,9 @@ func NewManager(
 		modPeerConnTracker:      options.ModPeerConnTracker,
 		moduleStatusMap:         make(map[string]modulestatus.Status),
 	}
+	if options.DevMode {
+		ret.devModeWorkers = utils.NewBackgroundStoppableWorkers(ret.watchModuleExecutables)
+	}
 	return ret, nil
 }
 
@@ -154,10 +157,18 @@ type Manager struct {
 
 	moduleStatusMu  sync.RWMutex
 	moduleStatusMap map[string]modulestatus.Status
+
+	// devModeWorkers restarts local modules when their executables change. It is nil unless dev mode
+	// is enabled.
+	devModeWorkers *utils.StoppableWorkers
 }
 
 // Close terminates module connections and processes.
 func (mgr *Manager) Close(ctx context.Context) error {
+	// stopped before locking as restarting a module takes the lock.
+	if mgr.devModeWorkers != nil {
+		mgr.devModeWorkers.Stop()
+	}
 	mgr.mu.Lock()
 	defer mgr.mu.Unlock()
 
@@ -378,6 +389,16 @@ func (mgr *Manager) startModule(ctx context.Context, mod *module) error {
 
 	var moduleRestartCtx context.Context
 	moduleRestartCtx, mod.restartCancel = context.WithCancel(mgr.restartCtx)
+	if mod.cfg.InProcess {
+		if err := mgr.startInProcessModule(mod); err != nil {
+			return errors.WithMessage(err, "error while loading in-process module "+mod.cfg.Name)
+		}
+		mgr.modules.Store(mod.cfg.Name, mod)
+		mod.logger.Infow("In-process module successfully added", "module", mod.cfg.Name)
+		mgr.setModuleStatusReady(mod.cfg.Name)
+		success = true
+		return nil
+	}
 	if err := mgr.startModuleProcess(mod, mgr.newOnUnexpectedExitHandler(moduleRestartCtx, mod)); err != nil {
 		return errors.WithMessage(err, "error while starting module "+mod.cfg.Name)
 	}
@@ -494,6 +515,10 @@ func (mgr *Manager) Remove(modName string) ([]resource.Name, error) {
 // as they are running outside code and may have unexpected behavior.
 func (mgr *Manager) closeModule(mod *module, reconfigure bool) error {
 	mgr.setModuleStatusClosing(mod.cfg.Name)
+	if mod.cfg.InProcess {
+		mgr.closeInProcessModule(mod)
+		return nil
+	}
 	// resource manager should've removed these cleanly if this isn't a reconfigure
 	if !reconfigure && len(mod.resources) != 0 {
 		mod.logger.Warnw("Forcing removal of module with active resources", "module", mod.cfg.Name)
@@ -614,6 +639,15 @@ func (mgr *Manager) AllModels() []resource.ModuleModel {
 			}
 		}
 	}
+	mgr.modules.Range(func(moduleName string, mod *module) bool {
+		for _, apiModel := range mod.inProcessModels {
+			models = append(models, resource.ModuleModel{
+				ModuleName: moduleName, Model: apiModel.Model, API: apiModel.API,
+				FromLocalModule: mod.cfg.Type == config.ModuleTypeLocal,
+			})
+		}
+		return true
+	})
 	return models
 }
 
@@ -701,6 +735,9 @@ func (mgr *Manager) ValidateConfig(ctx context.Context, conf resource.Config) ([
 			requiredImplicitDeps = append(requiredImplicitDeps, dep)
 		}
 	}
+	if err := conf.ValidateOptionalDependsOn(conf.ResourceName().String(), requiredImplicitDeps); err != nil {
+		return nil, nil, err
+	}
 	for _, optionalDep := range resp.OptionalDependencies {
 		switch optionalDep {
 		case "framesystem", "$framesystem", framesystem.PublicServiceName.String():
@@ -933,14 +970,30 @@ func (mgr *Manager) newOnUnexpectedExitHandler(ctx context.Context, mod *module)
 			if !cleanupPerformed {
 				// only record the failure inside the lock, and after mgr.Remove or mgr.Reconfigure have potentially touched it
 				fullErr := fmt.Errorf("module has unexpectedly exited; module: %s, exit_code: %d", mod.cfg.Name, exitCode)
+				oomKilled := mgr.countModuleOOMKills(mod)
+				if oomKilled {
+					fullErr = fmt.Errorf("module was killed for exceeding its memory limit of %dMB; module: %s, exit_code: %d",
+						mod.cfg.MemoryLimitMB, mod.cfg.Name, exitCode)
+				}
+				if mgr.viamHomeDir != "" {
+					if bundle, err := mgr.writeDiagnosticsBundle(mod, exitCode, oomKilled); err != nil {
+						mod.logger.Warnw("Error collecting diagnostics of crashed module", "module", mod.cfg.Name, "error", err)
+					} else {
+						mod.logger.Infow("Collected diagnostics of crashed module", "module", mod.cfg.Name, "bundle", bundle)
+						mgr.setModuleDiagnosticsBundle(mod.cfg.Name, bundle)
+						fullErr = fmt.Errorf("%w; diagnostics: %s", fullErr, bundle)
+					}
+				}
 				mgr.SetModuleStatusUnhealthy(mod.cfg.Name, fullErr)
 
 				mod.cleanupAfterCrash(mgr)
 				cleanupPerformed = true
 			}
 
+			mgr.logger.CInfow(ctx, "Attempting to restart crashed module", "module", mod.cfg.Name)
 			err := mgr.attemptRestart(ctx, mod)
 			if err == nil {
+				mgr.countModuleRestart(mod.cfg.Name)
 				break
 			}
 			mgr.SetModuleStatusUnhealthy(mod.cfg.Name, err)
@@ -968,10 +1021,10 @@ func (mgr *Manager) newOnUnexpectedExitHandler(ctx context.Context, mod *module)
 	}
 }
 
-// attemptRestart will attempt to restart the module process. It returns nil
-// on success and an error in case of failure. In the failure case it ensures
-// that the failed process is killed and will not be restarted by pexec or an
-// OUE handler.
+// attemptRestart will attempt to restart the module process after it crashed or,
+// in dev mode, after its executable changed. It returns nil on success and an
+// error in case of failure. In the failure case it ensures that the failed
+// process is killed and will not be restarted by pexec or an OUE handler.
 func (mgr *Manager) attemptRestart(ctx context.Context, mod *module) error {
 	var success, processRestarted bool
 	defer func() {
@@ -986,8 +1039,6 @@ func (mgr *Manager) attemptRestart(ctx context.Context, mod *module) error {
 		}
 	}()
 
-	mgr.logger.CInfow(ctx, "Attempting to restart crashed module", "module", mod.cfg.Name)
-
 	// No need to check mgr.untrustedEnv, as we're restarting the same
 	// executable we were given for initial module addition.
 
@@ -1056,8 +1107,9 @@ func (mgr *Manager) attemptRestart(ctx context.Context, mod *module) error {
 	return nil
 }
 
-// FirstRun is runs a module-specific setup script.
-func (mgr *Manager) FirstRun(ctx context.Context, conf config.Module) error {
+// FirstRun is runs a module-specific setup script. Progress reported by the script is passed to
+// onProgress, which may be nil.
+func (mgr *Manager) FirstRun(ctx context.Context, conf config.Module, onProgress func(config.FirstRunProgress)) error {
 	pkgsDir := packages.LocalPackagesDir(mgr.packagesDir)
 
 	// This value is normally set on a field on the [module] struct but it seems like we can safely get it on demand.
@@ -1072,7 +1124,7 @@ func (mgr *Manager) FirstRun(ctx context.Context, conf config.Module) error {
 	}
 	env := getFullEnvironment(conf, pkgsDir, dataDir, mgr.viamHomeDir)
 
-	return conf.FirstRun(ctx, pkgsDir, dataDir, env, mgr.logger)
+	return conf.FirstRun(ctx, pkgsDir, dataDir, env, onProgress, mgr.logger)
 }
 
 func getFullEnvironment(
@@ -1194,6 +1246,47 @@ func (mgr *Manager) setModuleState(moduleName string, state modulestatus.State,
 	mgr.moduleStatusMu.Unlock()
 }
 
+func (mgr *Manager) countModuleRestart(moduleName string) {
+	mgr.moduleStatusMu.Lock()
+	defer mgr.moduleStatusMu.Unlock()
+	if status, ok := mgr.moduleStatusMap[moduleName]; ok {
+		status.Restarts++
+		mgr.moduleStatusMap[moduleName] = status
+	}
+}
+
+func (mgr *Manager) setModuleDiagnosticsBundle(moduleName, bundle string) {
+	mgr.moduleStatusMu.Lock()
+	defer mgr.moduleStatusMu.Unlock()
+	if status, ok := mgr.moduleStatusMap[moduleName]; ok {
+		status.DiagnosticsBundle = bundle
+		mgr.moduleStatusMap[moduleName] = status
+	}
+}
+
+// countModuleOOMKills counts the processes of the module killed for exceeding its memory limit since it
+// was last checked, and returns true if there were any.
+func (mgr *Manager) countModuleOOMKills(mod *module) bool {
+	if mod.cgroup == nil {
+		return false
+	}
+	kills, err := mod.cgroup.newOOMKills()
+	if err != nil {
+		mod.logger.Debugw("Error reading OOM kills of module", "module", mod.cfg.Name, "error", err)
+		return false
+	}
+	if kills == 0 {
+		return false
+	}
+	mgr.moduleStatusMu.Lock()
+	defer mgr.moduleStatusMu.Unlock()
+	if status, ok := mgr.moduleStatusMap[mod.cfg.Name]; ok {
+		status.OOMKills += uint(kills)
+		mgr.moduleStatusMap[mod.cfg.Name] = status
+	}
+	return true
+}
+
 func (mgr *Manager) removeModuleStatus(moduleName string) {
 	mgr.moduleStatusMu.Lock()
 	delete(mgr.moduleStatusMap, moduleName)
@@ -3,6 +3,7 @@ package modmanager
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -35,6 +36,7 @@ import (
 	"go.viam.com/rdk/logging"
 	modlib "go.viam.com/rdk/module"
 	modmanageroptions "go.viam.com/rdk/module/modmanager/options"
+	modulestatus "go.viam.com/rdk/module/status"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot/web"
 	rtestutils "go.viam.com/rdk/testutils"
@@ -961,6 +963,141 @@ func TestModuleReloading(t *testing.T) {
 	})
 }
 
+func TestModuleDevMode(t *testing.T) {
+	ctx := context.Background()
+	logger, logs := logging.NewObservedTestLogger(t)
+
+	originalInterval := devModePollInterval
+	t.Cleanup(func() {
+		devModePollInterval = originalInterval
+	})
+	devModePollInterval = 10 * time.Millisecond
+
+	cfgMyHelper := resource.Config{
+		Name:  "myhelper",
+		API:   generic.API,
+		Model: resource.NewModel("rdk", "test", "helper"),
+	}
+	_, _, err := cfgMyHelper.Validate("test", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldBeNil)
+
+	parentAddr := setupSocketWithRobot(t)
+	modCfg := config.Module{
+		Name:    "test-module",
+		Type:    config.ModuleTypeLocal,
+		ExePath: rtestutils.BuildTempModule(t, "module/testmodule"),
+	}
+
+	orphaned := make(chan []resource.Name, 1)
+	mgr := setupModManager(t, ctx, parentAddr, logger, modmanageroptions.Options{
+		HandleOrphanedResources: func(_ context.Context, names []resource.Name) {
+			orphaned <- names
+		},
+		DevMode: true,
+	})
+	test.That(t, mgr.Add(ctx, modCfg), test.ShouldBeNil)
+	_, err = mgr.AddResource(ctx, cfgMyHelper, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	// let the watcher see the original executable before "rebuilding" it.
+	time.Sleep(10 * devModePollInterval)
+	rebuilt := time.Now().Add(time.Minute)
+	test.That(t, os.Chtimes(modCfg.ExePath, rebuilt, rebuilt), test.ShouldBeNil)
+
+	select {
+	case names := <-orphaned:
+		test.That(t, names, test.ShouldResemble, []resource.Name{generic.Named("myhelper")})
+	case <-time.After(time.Minute):
+		t.Fatal("module was not restarted after its executable changed")
+	}
+	test.That(t, logs.FilterMessageSnippet("Module executable changed").Len(), test.ShouldEqual, 1)
+
+	// with a real handleOrphanedResources, the resource manager would re-add the resource.
+	h, err := mgr.AddResource(ctx, cfgMyHelper, nil)
+	test.That(t, err, test.ShouldBeNil)
+	resp, err := h.DoCommand(ctx, map[string]any{"command": "echo"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["command"], test.ShouldEqual, "echo")
+}
+
+func TestModuleCrashDiagnostics(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	originalMaxBundles := maxDiagnosticsBundles
+	t.Cleanup(func() {
+		maxDiagnosticsBundles = originalMaxBundles
+	})
+	maxDiagnosticsBundles = 1
+
+	cfgMyHelper := resource.Config{
+		Name:  "myhelper",
+		API:   generic.API,
+		Model: resource.NewModel("rdk", "test", "helper"),
+	}
+	_, _, err := cfgMyHelper.Validate("test", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldBeNil)
+
+	parentAddr := setupSocketWithRobot(t)
+	viamHomeDir := t.TempDir()
+	modCfg := config.Module{
+		Name:        "test-module",
+		ExePath:     rtestutils.BuildTempModule(t, "module/testmodule"),
+		Environment: map[string]string{"API_KEY": "secret"},
+	}
+	mgr := setupModManager(t, ctx, parentAddr, logger, modmanageroptions.Options{
+		ViamHomeDir:             viamHomeDir,
+		HandleOrphanedResources: func(context.Context, []resource.Name) {},
+	})
+	test.That(t, mgr.Add(ctx, modCfg), test.ShouldBeNil)
+
+	crash := func(prevBundle string) string {
+		h, err := mgr.AddResource(ctx, cfgMyHelper, nil)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = h.DoCommand(ctx, map[string]any{"command": "kill_module"})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		var status modulestatus.Status
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			statuses := mgr.Status()
+			test.That(tb, statuses, test.ShouldHaveLength, 1)
+			status = statuses[0]
+			test.That(tb, status.DiagnosticsBundle, test.ShouldNotEqual, prevBundle)
+			test.That(tb, status.State, test.ShouldEqual, modulestatus.ModuleStateReady)
+		})
+		test.That(t, status.DiagnosticsBundle, test.ShouldStartWith,
+			filepath.Join(viamHomeDir, diagnosticsFolderName, "modules", "test-module"))
+		return status.DiagnosticsBundle
+	}
+	bundle := crash("")
+
+	exitJSON, err := os.ReadFile(filepath.Join(bundle, "exit.json"))
+	test.That(t, err, test.ShouldBeNil)
+	var report crashReport
+	test.That(t, json.Unmarshal(exitJSON, &report), test.ShouldBeNil)
+	test.That(t, report.Module, test.ShouldEqual, "test-module")
+	test.That(t, report.ExitCode, test.ShouldEqual, 1)
+	test.That(t, report.PID, test.ShouldNotEqual, 0)
+
+	output, err := os.ReadFile(filepath.Join(bundle, "output.log"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(output), test.ShouldContainSubstring, "[stderr] kill_module called")
+
+	cfgJSON, err := os.ReadFile(filepath.Join(bundle, "config.json"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(cfgJSON), test.ShouldContainSubstring, "API_KEY")
+	test.That(t, string(cfgJSON), test.ShouldNotContainSubstring, "secret")
+
+	// only the most recent bundles are kept.
+	nextBundle := crash(bundle)
+	test.That(t, nextBundle, test.ShouldNotEqual, bundle)
+	_, err = os.Stat(bundle)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	_, err = os.Stat(nextBundle)
+	test.That(t, err, test.ShouldBeNil)
+}
+
 func TestDebugModule(t *testing.T) {
 	ctx := context.Background()
 
@@ -1645,6 +1782,33 @@ func TestBadModuleFailsFast(t *testing.T) {
 	test.That(t, err.Error(), test.ShouldContainSubstring, "module test-module exited too quickly after attempted startup")
 }
 
+func TestInProcessModuleNotAPlugin(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	exePath := filepath.Join(t.TempDir(), "module.so")
+	test.That(t, os.WriteFile(exePath, []byte("not a plugin"), 0o700), test.ShouldBeNil)
+	modCfgs := []config.Module{
+		{
+			Name:      "test-module",
+			ExePath:   exePath,
+			Type:      config.ModuleTypeLocal,
+			InProcess: true,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	parentAddr := setupSocketWithRobot(t)
+	opts := modmanageroptions.Options{UntrustedEnv: false}
+	mgr := setupModManager(t, ctx, parentAddr, logger, opts)
+
+	err := mgr.Add(ctx, modCfgs...)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "failed to load in-process module")
+	test.That(t, mgr.Configs(), test.ShouldBeEmpty)
+	test.That(t, mgr.UnhealthyModules(), test.ShouldResemble, []string{"test-module"})
+}
+
 // TestFTDCAfterModuleCrash is to give confidence that the FTDC sections devoted to tracking module
 // process information (e.g: CPU usage) is in sync with the Process IDs (PIDs) that are actually
 // running.
@@ -1753,7 +1917,7 @@ func TestFirstRun(t *testing.T) {
 
 		t.Setenv("VIAM_TEST_FAIL_RUN_FIRST", "1")
 
-		err := mgr.FirstRun(ctx, modCfg)
+		err := mgr.FirstRun(ctx, modCfg, nil)
 		test.That(t, err, test.ShouldNotBeNil)
 
 		test.That(t, logs.FilterMessage("executing first run script").Len(), test.ShouldEqual, 1)
@@ -1801,7 +1965,7 @@ func TestFirstRun(t *testing.T) {
 
 		t.Log("=== FIRST RUN SUCCEEDS ===")
 
-		err := mgr.FirstRun(ctx, modCfg)
+		err := mgr.FirstRun(ctx, modCfg, nil)
 		test.That(t, err, test.ShouldBeNil)
 
 		test.That(t, logs.FilterMessage("executing first run script").Len(), test.ShouldEqual, 1)
@@ -1838,7 +2002,7 @@ func TestFirstRun(t *testing.T) {
 
 		logs.TakeAll() // remove logs observed up to this point
 
-		err = mgr.FirstRun(ctx, modCfg)
+		err = mgr.FirstRun(ctx, modCfg, nil)
 		test.That(t, err, test.ShouldBeNil)
 
 		test.That(t, logs.FilterMessage("first run already ran").Len(), test.ShouldEqual, 1)
@@ -1855,7 +2019,7 @@ func TestFirstRun(t *testing.T) {
 		}
 		mgr = setupModManager(t, ctx, parentAddr, logger, opts)
 
-		err = mgr.FirstRun(ctx, modCfg)
+		err = mgr.FirstRun(ctx, modCfg, nil)
 		test.That(t, err, test.ShouldBeNil)
 
 		test.That(t, logs.FilterMessage("first run already ran").Len(), test.ShouldEqual, 1)
@@ -1879,7 +2043,7 @@ func TestFirstRun(t *testing.T) {
 			ExePath:         exePath,
 			FirstRunTimeout: utils.Duration(100 * time.Millisecond),
 		}
-		err := mgr.FirstRun(ctx, modCfg)
+		err := mgr.FirstRun(ctx, modCfg, nil)
 		test.That(t, err, test.ShouldNotBeNil)
 
 		var errExit *exec.ExitError
@@ -1892,7 +2056,7 @@ func TestFirstRun(t *testing.T) {
 		// set a timeout that expires before the process can even start.
 		// this should result in a [context.DeadlineExceeded] error.
 		modCfg.FirstRunTimeout = utils.Duration(1 * time.Nanosecond)
-		err = mgr.FirstRun(ctx, modCfg)
+		err = mgr.FirstRun(ctx, modCfg, nil)
 		test.That(t, err, test.ShouldResemble, context.DeadlineExceeded)
 	})
 }
@@ -64,6 +64,17 @@ type module struct {
 	// pendingRemoval allows delaying module close until after resources within it are closed
 	pendingRemoval bool
 	restartCancel  context.CancelFunc
+	// cgroup limits the CPU and memory of the module process if its config has limits.
+	cgroup *moduleCgroup
+	// output, startedAt, and workingDir describe the current process of the module for its diagnostics
+	// bundle if it crashes.
+	output     *outputLines
+	startedAt  time.Time
+	workingDir string
+
+	// inProcessModels are the models of a module that is loaded into the viam-server process, which
+	// has no process, connection, or handles.
+	inProcessModels []resource.APIModel
 
 	logger logging.Logger
 	ftdc   *ftdc.FTDC
@@ -274,6 +285,9 @@ func (m *module) startProcess(
 	stdoutLogger := m.logger.Sublogger("StdOut")
 	stderrLogger := m.logger.Sublogger("StdErr")
 	stderrLogger.NeverDeduplicate()
+	// Keep the recent output of the module for its diagnostics bundle if it crashes.
+	m.output = &outputLines{}
+	m.workingDir = moduleWorkingDirectory
 
 	moduleEnvironment[rutils.ViamModuleAddress] = m.addr
 
@@ -298,8 +312,8 @@ func (m *module) startProcess(
 		Environment:      moduleEnvironment,
 		Log:              true,
 		OnUnexpectedExit: oue,
-		StdOutLogger:     stdoutLogger,
-		StdErrLogger:     stderrLogger,
+		StdOutLogger:     &outputRecorder{Logger: stdoutLogger, stream: "stdout", output: m.output},
+		StdErrLogger:     &outputRecorder{Logger: stderrLogger, stream: "stderr", output: m.output},
 	}
 	// Start module process with supplied log level or "debug" if none is
 	// supplied and module manager has a DebugLevel logger.
@@ -313,8 +327,15 @@ func (m *module) startProcess(
 		pconf.Args = append(pconf.Args, "--tcp-mode")
 	}
 
+	if m.cfg.Sandbox != nil {
+		if err := m.sandboxProcess(ctx, &pconf); err != nil {
+			return errors.WithMessage(err, "module sandbox setup failed")
+		}
+	}
+
 	m.prevProcess = m.process
 	m.process = pexec.NewManagedProcess(pconf, m.logger)
+	m.startedAt = time.Now()
 
 	if err := m.process.Start(context.Background()); err != nil {
 		return errors.WithMessage(err, "module startup failed")
@@ -323,6 +344,8 @@ func (m *module) startProcess(
 	// Turn on process cpu/memory diagnostics for the module process. If there's an error, we
 	// continue normally, just without FTDC.
 	m.registerProcessWithFTDC()
+	// Likewise, if the resource limits of the module can't be enforced, it runs without them.
+	m.limitResources(ctx)
 
 	checkTicker := time.NewTicker(100 * time.Millisecond)
 	defer checkTicker.Stop()
@@ -386,6 +409,13 @@ func (m *module) stopProcess() error {
 		if m.ftdc != nil {
 			m.ftdc.Remove(m.getFTDCName())
 		}
+
+		if m.cgroup != nil {
+			if err := m.cgroup.remove(); err != nil {
+				m.logger.Debugw("Error removing module cgroup", "module", m.cfg.Name, "error", err)
+			}
+			m.cgroup = nil
+		}
 	}()
 
 	// TODO(RSDK-2551): stop ignoring exit status 143 once Python modules handle
@@ -495,6 +525,34 @@ func (m *module) cleanupAfterCrash(mgr *Manager) {
 	}
 }
 
+// limitResources moves the module process into a cgroup limiting the CPU and memory it uses. The cgroup is
+// kept when the module crashes so that the OOM kills in it can be counted and the restarted process reuses it.
+func (m *module) limitResources(ctx context.Context) {
+	if !m.cfg.HasResourceLimits() {
+		return
+	}
+	if m.cgroup == nil {
+		cgroup, err := newModuleCgroup(m.cfg)
+		if err != nil {
+			m.logger.CWarnw(ctx, "Unable to limit module resources. Module will run without limits",
+				"module", m.cfg.Name, "error", err)
+			return
+		}
+		m.cgroup = cgroup
+	}
+	pid, err := m.process.UnixPid()
+	if err == nil {
+		err = m.cgroup.addProcess(pid)
+	}
+	if err != nil {
+		m.logger.CWarnw(ctx, "Unable to limit module resources. Module will run without limits",
+			"module", m.cfg.Name, "error", err)
+		return
+	}
+	m.logger.CDebugw(ctx, "Limited module resources", "module", m.cfg.Name,
+		"cpu_limit", m.cfg.CPULimit, "memory_limit_mb", m.cfg.MemoryLimitMB)
+}
+
 func (m *module) getFullEnvironment(viamHomeDir, packagesDir string) map[string]string {
 	return getFullEnvironment(m.cfg, packagesDir, m.dataDir, viamHomeDir)
 }
@@ -28,4 +28,7 @@ type Options struct {
 	// gRPC API calls can choose to respond with data over the PeerConnection. Such is the case with
 	// video streams.
 	ModPeerConnTracker *grpc.ModPeerConnTracker
+	// DevMode restarts local modules when their executables change and re-adds their resources, so
+	// that module authors do not need to edit the config or restart the server after every build.
+	DevMode bool
 }
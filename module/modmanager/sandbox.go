@@ -0,0 +1,91 @@
+package modmanager
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/pexec"
+
+	"go.viam.com/rdk/config"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// sandboxSystemPaths are the paths a sandboxed module may read so that it can run programs. Those
+// missing on the system are skipped. Only the files of /etc that programs need are included, as the
+// rest of it holds secrets such as the cloud credentials of the machine and /etc/shadow.
+var sandboxSystemPaths = []string{
+	"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64",
+	"/etc/ld.so.cache", "/etc/ld.so.conf", "/etc/ld.so.conf.d", "/etc/alternatives",
+	"/etc/ssl/certs", "/etc/pki/tls/certs", "/etc/pki/ca-trust/extracted",
+	"/etc/passwd", "/etc/group", "/etc/nsswitch.conf", "/etc/localtime",
+}
+
+// sandboxProcess changes the process config of the module so that its executable runs in a bubblewrap
+// sandbox which only allows what the sandbox config of the module does.
+func (m *module) sandboxProcess(ctx context.Context, pconf *pexec.ProcessConfig) error {
+	if runtime.GOOS != "linux" {
+		return errors.New("module sandboxes are only supported on Linux")
+	}
+	bwrap, err := exec.LookPath("bwrap")
+	if err != nil {
+		return errors.Wrap(err, "module sandboxes require bubblewrap (bwrap) to be installed")
+	}
+	if m.tcpMode() {
+		return errors.New("sandboxed modules have no network, so they cannot run in TCP mode")
+	}
+
+	sandbox := *m.cfg.Sandbox
+	for _, paths := range []*[]string{&sandbox.ReadOnlyPaths, &sandbox.ReadWritePaths, &sandbox.Devices} {
+		expanded := make([]string, 0, len(*paths))
+		for _, path := range *paths {
+			path, err := rutils.ExpandHomeDir(path)
+			if err != nil {
+				return err
+			}
+			expanded = append(expanded, path)
+		}
+		*paths = expanded
+	}
+	// the module creates its socket next to the socket of the server, which it connects to.
+	readWritePaths := []string{pconf.CWD, m.dataDir, filepath.Dir(m.addr)}
+	sandbox.ReadWritePaths = append(readWritePaths, sandbox.ReadWritePaths...)
+
+	args := sandboxArgs(sandbox, filepath.Dir(pconf.Name), pconf.CWD)
+
+	pconf.Args = append(append(args, "--", pconf.Name), pconf.Args...)
+	pconf.Name = bwrap
+	m.logger.CInfow(ctx, "Starting module in sandbox", "module", m.cfg.Name,
+		"read_only_paths", sandbox.ReadOnlyPaths, "read_write_paths", sandbox.ReadWritePaths, "devices", sandbox.Devices)
+	return nil
+}
+
+// sandboxArgs returns the bwrap arguments for the sandbox, which has no network.
+func sandboxArgs(sandbox config.ModuleSandbox, exeDir, workingDir string) []string {
+	args := []string{
+		"--unshare-all",
+		"--die-with-parent",
+		"--proc", "/proc",
+		"--dev", "/dev",
+		"--tmpfs", "/tmp",
+	}
+	for _, path := range sandboxSystemPaths {
+		args = append(args, "--ro-bind-try", path, path)
+	}
+	// later mounts take precedence, so paths that are read-write are mounted last.
+	for _, path := range append([]string{exeDir}, sandbox.ReadOnlyPaths...) {
+		args = append(args, "--ro-bind", path, path)
+	}
+	for _, path := range sandbox.ReadWritePaths {
+		if path != "" {
+			args = append(args, "--bind", path, path)
+		}
+	}
+	// device nodes are only usable when bound with --dev-bind, which is limited to the declared devices.
+	for _, path := range sandbox.Devices {
+		args = append(args, "--dev-bind", path, path)
+	}
+	return append(args, "--chdir", workingDir)
+}
@@ -0,0 +1,30 @@
+package modmanager
+
+import (
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+)
+
+func TestSandboxArgs(t *testing.T) {
+	sandbox := config.ModuleSandbox{
+		ReadOnlyPaths:  []string{"/opt/models"},
+		ReadWritePaths: []string{"/modules/my-module", "/data/my-module", ""},
+		Devices:        []string{"/dev/video0"},
+	}
+
+	args := strings.Join(sandboxArgs(sandbox, "/modules/my-module/bin", "/modules/my-module"), " ")
+	test.That(t, args, test.ShouldStartWith, "--unshare-all --die-with-parent")
+	test.That(t, args, test.ShouldContainSubstring, "--ro-bind-try /usr /usr")
+	test.That(t, args, test.ShouldContainSubstring, "--ro-bind-try /etc/ssl/certs /etc/ssl/certs")
+	test.That(t, args, test.ShouldNotContainSubstring, "/etc /etc")
+	test.That(t, args, test.ShouldNotContainSubstring, "--share-net")
+	test.That(t, args, test.ShouldContainSubstring,
+		"--ro-bind /modules/my-module/bin /modules/my-module/bin --ro-bind /opt/models /opt/models "+
+			"--bind /modules/my-module /modules/my-module --bind /data/my-module /data/my-module "+
+			"--dev-bind /dev/video0 /dev/video0 --chdir /modules/my-module")
+	test.That(t, args, test.ShouldEndWith, "--chdir /modules/my-module")
+}
@@ -25,4 +25,13 @@ type Status struct {
 	LastUpdated         time.Time
 	Error               error
 	ConsecutiveFailures uint
+	// Restarts counts the times the module was restarted after crashing. It is kept for as long as
+	// the module is tracked.
+	Restarts uint
+	// OOMKills counts the times the module was killed for exceeding its memory limit, which are also
+	// counted as crashes in Restarts once it is restarted.
+	OOMKills uint
+	// DiagnosticsBundle is the path of the diagnostics bundle collected when the module last exited
+	// unexpectedly, if any.
+	DiagnosticsBundle string
 }
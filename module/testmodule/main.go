@@ -228,6 +228,7 @@ func (h *helper) DoCommand(ctx context.Context, req map[string]interface{}) (map
 		return req, nil
 	case "kill_module":
 		// For testing module reloading & unexpected exists
+		fmt.Fprintln(os.Stderr, "kill_module called")
 		os.Exit(1)
 		// unreachable return statement needed for compilation
 		return nil, errors.New("unreachable error")
@@ -5,8 +5,11 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/pkg/errors"
@@ -46,6 +49,9 @@ type PlanRequest struct {
 	ObstaclesInWorldFrame *referenceframe.GeometriesInFrame `json:"obstacles_in_world_frame"`
 	// Additional parameters constraining the motion of the robot.
 	Constraints *motionplan.Constraints `json:"constraints"`
+	// If non-empty, SegmentConstraints has one entry per goal, constraining the motion to that goal
+	// from the previous one in place of Constraints. A nil entry leaves its segment unconstrained.
+	SegmentConstraints []*motionplan.Constraints `json:"segment_constraints,omitempty"`
 	// Other more granular parameters for the plan used to move the robot.
 	PlannerOptions *PlannerOptions `json:"planner_options"`
 
@@ -133,15 +139,29 @@ func (req *PlanRequest) validatePlanRequest() error {
 	}
 
 	// Validate the goals. Each goal with a pose must not also have a configuration specified. The parent frame of the pose must exist.
+	// A goal with alternatives must have neither, and each of its alternatives is validated as a goal.
 	for _, goalState := range req.Goals {
-		for fName, pif := range goalState.poses {
-			if len(goalState.structuredConfiguration) > 0 {
-				return errors.New("individual goals cannot have both configuration and poses populated")
+		if goalState.alternatives == nil {
+			if err := req.validateGoal(goalState); err != nil {
+				return err
 			}
-
-			goalParentFrame := pif.Parent()
-			if req.FrameSystem.Frame(goalParentFrame) == nil {
-				return referenceframe.NewParentFrameMissingError(fName, goalParentFrame)
+			continue
+		}
+		if len(goalState.poses) > 0 || len(goalState.structuredConfiguration) > 0 {
+			return errors.New("goals with alternatives cannot also have configuration or poses populated")
+		}
+		if len(goalState.alternatives) == 0 {
+			return errors.New("goals with alternatives must have at least one alternative")
+		}
+		for _, alternative := range goalState.alternatives {
+			if alternative == nil || alternative.alternatives != nil {
+				return errors.New("alternatives must be non-nil and cannot have alternatives of their own")
+			}
+			if len(alternative.poses) == 0 && len(alternative.structuredConfiguration) == 0 {
+				return errors.New("alternatives must have configuration or poses populated")
+			}
+			if err := req.validateGoal(alternative); err != nil {
+				return err
 			}
 		}
 	}
@@ -149,6 +169,39 @@ func (req *PlanRequest) validatePlanRequest() error {
 	if req.Constraints == nil {
 		req.Constraints = &motionplan.Constraints{}
 	}
+	if len(req.SegmentConstraints) > 0 {
+		if len(req.SegmentConstraints) != len(req.Goals) {
+			return errors.Errorf("PlanRequest must have one SegmentConstraints entry per goal, got %d for %d goals",
+				len(req.SegmentConstraints), len(req.Goals))
+		}
+		for i, constraints := range req.SegmentConstraints {
+			if constraints == nil {
+				req.SegmentConstraints[i] = &motionplan.Constraints{}
+			}
+		}
+	}
+	return nil
+}
+
+// constraintsForGoal returns the constraints on the motion to the goal with index goalIdx.
+func (req *PlanRequest) constraintsForGoal(goalIdx int) *motionplan.Constraints {
+	if len(req.SegmentConstraints) > 0 {
+		return req.SegmentConstraints[goalIdx]
+	}
+	return req.Constraints
+}
+
+func (req *PlanRequest) validateGoal(goalState *PlanState) error {
+	for fName, pif := range goalState.poses {
+		if len(goalState.structuredConfiguration) > 0 {
+			return errors.New("individual goals cannot have both configuration and poses populated")
+		}
+
+		goalParentFrame := pif.Parent()
+		if req.FrameSystem.Frame(goalParentFrame) == nil {
+			return referenceframe.NewParentFrameMissingError(fName, goalParentFrame)
+		}
+	}
 	return nil
 }
 
@@ -186,6 +239,160 @@ func PlanFrameMotion(ctx context.Context,
 	return plan.Trajectory().GetFrameInputs(f.Name())
 }
 
+// Waypoint is one goal of a multi-segment plan, with the constraints on the motion to it from the
+// previous waypoint, or from the start for the first. Nil constraints leave the segment unconstrained.
+type Waypoint struct {
+	Goal        *PlanState
+	Constraints *motionplan.Constraints
+}
+
+// PlanWaypoints plans one continuous motion from start through each of the waypoints in order,
+// holding each segment to its own constraints. For example, a linear constraint may hold only
+// between the second and third waypoints, and an orientation constraint on every segment after the
+// third. Each segment is planned from the configuration the last one ended at and smoothed, so
+// clients need not stitch together independent plans.
+func PlanWaypoints(ctx context.Context,
+	logger logging.Logger,
+	fs *referenceframe.FrameSystem,
+	start *PlanState,
+	waypoints []Waypoint,
+	obstacles *referenceframe.GeometriesInFrame,
+	planOpts *PlannerOptions,
+) (motionplan.Plan, *PlanMeta, error) {
+	goals := make([]*PlanState, 0, len(waypoints))
+	segmentConstraints := make([]*motionplan.Constraints, 0, len(waypoints))
+	for _, waypoint := range waypoints {
+		goals = append(goals, waypoint.Goal)
+		segmentConstraints = append(segmentConstraints, waypoint.Constraints)
+	}
+	return PlanMotion(ctx, logger, &PlanRequest{
+		FrameSystem:           fs,
+		Goals:                 goals,
+		StartState:            start,
+		ObstaclesInWorldFrame: obstacles,
+		SegmentConstraints:    segmentConstraints,
+		PlannerOptions:        planOpts,
+	})
+}
+
+// planStartTolerance is how far each input of the first step of a plan may be from the configuration
+// CheckPlan is given, in radians or mm.
+const planStartTolerance = 1e-3
+
+// planPathToleranceMm is how far the poses of a plan's path may be from the poses CheckPlan finds
+// its trajectory reaches.
+const planPathToleranceMm = 0.1
+
+// CheckPlan returns an error if a plan, which may have been computed in another process and against
+// an older world, cannot be executed from the configuration start in the given frame system and
+// among the given obstacles. The plan must begin at start, keep every frame it moves within its
+// limits, reach the poses of its path if it has one, and not collide.
+func CheckPlan(ctx context.Context,
+	logger logging.Logger,
+	fs *referenceframe.FrameSystem,
+	start referenceframe.FrameSystemInputs,
+	plan motionplan.Plan,
+	obstacles *referenceframe.GeometriesInFrame,
+	planOpts *PlannerOptions,
+) error {
+	if plan == nil {
+		return errors.New("cannot check nil plan")
+	}
+	traj := plan.Trajectory()
+	path := plan.Path()
+	if len(traj) == 0 {
+		return errors.New("plan has no trajectory")
+	}
+	if len(path) > 0 && len(path) != len(traj) {
+		return errors.Errorf("plan has %d path steps but %d trajectory steps", len(path), len(traj))
+	}
+
+	current := referenceframe.FrameSystemInputs{}
+	maps.Copy(current, start)
+	moved := map[string]bool{}
+	configurations := make([]*referenceframe.LinearInputs, 0, len(traj))
+	for i, step := range traj {
+		for frameName, inputs := range step {
+			f := fs.Frame(frameName)
+			if f == nil {
+				return referenceframe.NewFrameMissingError(frameName)
+			}
+			if len(inputs) != len(f.DoF()) || len(inputs) != len(start[frameName]) {
+				return errors.Errorf("plan step %d has %d inputs for frame %q, which has %d degrees of freedom and %d start inputs",
+					i, len(inputs), frameName, len(f.DoF()), len(start[frameName]))
+			}
+			for j, limit := range f.DoF() {
+				if inputs[j] < limit.Min || inputs[j] > limit.Max {
+					return errors.Errorf("plan step %d: %s, frame %q input %d is %.5f, limits are [%.5f, %.5f]",
+						i, referenceframe.OOBErrString, frameName, j, inputs[j], limit.Min, limit.Max)
+				}
+				if i == 0 && math.Abs(inputs[j]-start[frameName][j]) > planStartTolerance {
+					return errors.Errorf("plan does not begin at the start configuration, frame %q is at %v rather than %v",
+						frameName, inputs, start[frameName])
+				}
+			}
+			if !slices.Equal(inputs, start[frameName]) {
+				moved[frameName] = true
+			}
+			current[frameName] = inputs
+		}
+		configuration := current.ToLinearInputs()
+		configurations = append(configurations, configuration)
+
+		if len(path) == 0 {
+			continue
+		}
+		for frameName, expected := range path[i] {
+			tf, err := fs.Transform(configuration, referenceframe.NewZeroPoseInFrame(frameName), expected.Parent())
+			if err != nil {
+				return errors.Wrapf(err, "plan step %d", i)
+			}
+			if !spatialmath.PoseAlmostCoincidentEps(tf.(*referenceframe.PoseInFrame).Pose(), expected.Pose(), planPathToleranceMm) {
+				return errors.Errorf("plan step %d reaches %v for frame %q, but its path has %v",
+					i, tf.(*referenceframe.PoseInFrame).Pose(), frameName, expected.Pose())
+			}
+		}
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+
+	// The frames the plan moves are checked for collisions as they would be planning to their final
+	// configuration, so the same collisions as in planning are allowed.
+	goal := referenceframe.FrameSystemInputs{}
+	for frameName := range moved {
+		goal[frameName] = current[frameName]
+	}
+	req := &PlanRequest{
+		FrameSystem:           fs,
+		Goals:                 []*PlanState{NewPlanState(nil, goal)},
+		StartState:            NewPlanState(nil, start),
+		ObstaclesInWorldFrame: obstacles,
+		PlannerOptions:        planOpts,
+	}
+	if err := req.validatePlanRequest(); err != nil {
+		return err
+	}
+	pc, err := NewPlanContext(ctx, logger, req, &PlanMeta{})
+	if err != nil {
+		return err
+	}
+	goalPoses, err := req.Goals[0].ComputePoses(ctx, fs)
+	if err != nil {
+		return err
+	}
+	psc, err := NewPlanSegmentContext(ctx, pc, req.StartState.LinearConfiguration(), goalPoses)
+	if err != nil {
+		return err
+	}
+	for i := 1; i < len(configurations); i++ {
+		if err := psc.CheckPath(ctx, configurations[i-1], configurations[i], true, nil); err != nil {
+			return errors.Wrapf(err, "plan step %d", i)
+		}
+	}
+	return nil
+}
+
 // SolutionNodeInfo captures per-node data from getSolutions for visualization and debugging.
 type SolutionNodeInfo struct {
 	// Score is the cost of moving from the start configuration to this node.
@@ -246,6 +453,10 @@ type PlanMeta struct {
 	// waypoints created + 1 (for the final user goal).
 	SubgoalsPerGoal []int
 
+	// AlternativeReached has the same size as SubgoalsPerGoal. For each goal with alternatives, it
+	// is the index of the alternative the plan reaches, and it is -1 for every other goal.
+	AlternativeReached []int
+
 	// SubgoalsProcessed may be non-zero when a plan request has linear/orientation
 	// constraints. Satisfying those constraints internally creates additional goals.
 	SubgoalsProcessed int
@@ -282,6 +493,9 @@ func PlanMotion(ctx context.Context, parentLogger logging.Logger, request *PlanR
 		return nil, meta, err
 	}
 	logger.CDebugf(ctx, "constraint specs for this step: %v", request.Constraints)
+	if len(request.SegmentConstraints) > 0 {
+		logger.CDebugf(ctx, "segment constraint specs for this step: %v", request.SegmentConstraints)
+	}
 	logger.CDebugf(ctx, "motion config for this step: %v", request.PlannerOptions)
 	logger.CDebugf(ctx, "start position: %v", request.StartState.structuredConfiguration)
 
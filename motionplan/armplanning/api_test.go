@@ -1,12 +1,14 @@
 package armplanning_test
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 
 	"github.com/golang/geo/r3"
 	"go.viam.com/test"
 
+	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/motionplan/armplanning"
 	"go.viam.com/rdk/referenceframe"
@@ -79,3 +81,62 @@ func TestWriteAndReadRequestAndResponse(t *testing.T) {
 		test.That(t, gotPlan, test.ShouldBeNil)
 	})
 }
+
+func TestCheckTransferredPlan(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	model, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "xarm6")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("")
+	test.That(t, fs.AddFrame(model, fs.World()), test.ShouldBeNil)
+
+	start := referenceframe.FrameSystemInputs{"xarm6": make([]referenceframe.Input, 6)}
+	goalPose := spatialmath.NewPose(r3.Vector{X: 300, Y: 100, Z: 300}, &spatialmath.OrientationVector{OZ: -1})
+	plan, _, err := armplanning.PlanMotion(context.Background(), logger, &armplanning.PlanRequest{
+		FrameSystem: fs,
+		StartState:  armplanning.NewPlanState(nil, start),
+		Goals: []*armplanning.PlanState{armplanning.NewPlanState(referenceframe.FrameSystemPoses{
+			"xarm6": referenceframe.NewPoseInFrame(referenceframe.World, goalPose),
+		}, nil)},
+	})
+	test.That(t, err, test.ShouldBeNil)
+
+	// The plan is computed in one process and executed in another.
+	data, err := motionplan.MarshalPlan(plan)
+	test.That(t, err, test.ShouldBeNil)
+	transferred, err := motionplan.UnmarshalPlan(data)
+	test.That(t, err, test.ShouldBeNil)
+
+	check := func(start referenceframe.FrameSystemInputs, obstacles *referenceframe.GeometriesInFrame) error {
+		return armplanning.CheckPlan(context.Background(), logger, fs, start, transferred, obstacles,
+			armplanning.NewBasicPlannerOptions())
+	}
+
+	t.Run("in the world it was planned for", func(t *testing.T) {
+		test.That(t, check(start, nil), test.ShouldBeNil)
+	})
+
+	t.Run("from another start", func(t *testing.T) {
+		err := check(referenceframe.FrameSystemInputs{"xarm6": {0.5, 0, 0, 0, 0, 0}}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "does not begin at the start configuration")
+	})
+
+	t.Run("with an obstacle in the way", func(t *testing.T) {
+		box, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(goalPose.Point()), r3.Vector{X: 50, Y: 50, Z: 50}, "box")
+		test.That(t, err, test.ShouldBeNil)
+		err = check(start, referenceframe.NewGeometriesInFrame(referenceframe.World, []spatialmath.Geometry{box}))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, motionplan.ObstacleConstraintDescription)
+	})
+
+	t.Run("with different kinematics", func(t *testing.T) {
+		other := referenceframe.NewEmptyFrameSystem("")
+		offset, err := referenceframe.NewStaticFrame("offset", spatialmath.NewPoseFromPoint(r3.Vector{Z: 100}))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, other.AddFrame(offset, other.World()), test.ShouldBeNil)
+		test.That(t, other.AddFrame(model, offset), test.ShouldBeNil)
+		err = armplanning.CheckPlan(context.Background(), logger, other, start, transferred, nil, armplanning.NewBasicPlannerOptions())
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "but its path has")
+	})
+}
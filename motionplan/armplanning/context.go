@@ -29,6 +29,10 @@ type PlanContext struct {
 	planOpts                  *PlannerOptions
 	request                   *PlanRequest
 
+	// constraints hold on the segment being planned. They are the request's constraints, unless it
+	// has constraints per segment.
+	constraints *motionplan.Constraints
+
 	randseed *rand.Rand
 
 	planMeta *PlanMeta
@@ -52,13 +56,18 @@ func NewPlanContext(ctx context.Context, logger logging.Logger, request *PlanReq
 		ConfigurationDistanceFunc: motionplan.GetConfigurationDistanceFunc(request.PlannerOptions.ConfigurationDistanceMetric),
 		planOpts:                  request.PlannerOptions,
 		request:                   request,
+		constraints:               request.Constraints,
 		randseed:                  rand.New(rand.NewSource(int64(request.PlannerOptions.RandomSeed))), //nolint:gosec
 		planMeta:                  meta,
 		logger:                    logger,
-		collisionCache:            motionplan.NewCollisionCache(),
 	}
 
 	var err error
+	pc.collisionCache, err = motionplan.NewCollisionCacheWithBackend(request.PlannerOptions.CollisionBackend, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	pc.lis, err = request.StartState.LinearConfiguration().GetSchema(pc.fs)
 	if err != nil {
 		return nil, err
@@ -74,6 +83,16 @@ func NewPlanContext(ctx context.Context, logger logging.Logger, request *PlanReq
 	return pc, nil
 }
 
+// forAlternative returns a copy of pc for solving toward one of a goal's alternatives concurrently
+// with the others. The copy draws from its own random source, seeded from pc's so that
+// deterministic plans stay deterministic, and records diagnostics in its own PlanMeta.
+func (pc *PlanContext) forAlternative() *PlanContext {
+	alternative := *pc
+	alternative.randseed = rand.New(rand.NewSource(pc.randseed.Int63())) //nolint:gosec
+	alternative.planMeta = &PlanMeta{CollectSolutionDiagnostics: pc.planMeta.CollectSolutionDiagnostics}
+	return &alternative
+}
+
 // GetLinearInputsSchema gets the LinearInputsSchema.
 func (pc *PlanContext) GetLinearInputsSchema() *referenceframe.LinearInputsSchema {
 	return pc.lis
@@ -156,7 +175,7 @@ func NewPlanSegmentContext(ctx context.Context, pc *PlanContext, start *referenc
 
 	psc.Checker, err = motionplan.NewConstraintChecker(
 		pc.planOpts.CollisionBufferMM,
-		pc.request.Constraints,
+		pc.constraints,
 		psc.startPoses,
 		goal,
 		pc.fs,
@@ -469,6 +469,151 @@ func TestArmOOBSolve(t *testing.T) {
 	}
 }
 
+func TestAlternativeGoals(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	fs := frame.NewEmptyFrameSystem("")
+	xarm, err := frame.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "xarm6")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(xarm, fs.World()), test.ShouldBeNil)
+	gripper, err := frame.NewStaticFrame("gripper", spatialmath.NewPoseFromPoint(r3.Vector{Z: 100}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(gripper, xarm), test.ShouldBeNil)
+	start := frame.NewNeutralFrameSystemInputs(fs)
+
+	unreachable := NewPlanState(frame.FrameSystemPoses{
+		"xarm6": frame.NewPoseInFrame(frame.World, spatialmath.NewPoseFromPoint(r3.Vector{X: 5000})),
+	}, nil)
+	reachablePose := spatialmath.NewPose(r3.Vector{X: 200, Y: 100, Z: 300}, &spatialmath.OrientationVector{OZ: -1})
+	reachable := NewPlanState(frame.FrameSystemPoses{"xarm6": frame.NewPoseInFrame(frame.World, reachablePose)}, nil)
+	configuration := NewPlanState(nil, frame.FrameSystemInputs{"xarm6": {0.3, 0.2, -0.4, 0.1, 0.5, 0}})
+
+	plan := func(t *testing.T, alternatives ...*PlanState) (motionplan.Plan, *PlanMeta) {
+		t.Helper()
+		p, meta, err := PlanMotion(context.Background(), logger, &PlanRequest{
+			FrameSystem:    fs,
+			Goals:          []*PlanState{NewAlternativesPlanState(alternatives...)},
+			StartState:     NewPlanState(nil, start),
+			PlannerOptions: NewBasicPlannerOptions(),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		return p, meta
+	}
+	finalPose := func(t *testing.T, p motionplan.Plan, frameName string) spatialmath.Pose {
+		t.Helper()
+		traj := p.Trajectory()
+		pif, err := fs.Transform(traj[len(traj)-1].ToLinearInputs(),
+			frame.NewPoseInFrame(frameName, spatialmath.NewZeroPose()), frame.World)
+		test.That(t, err, test.ShouldBeNil)
+		return pif.(*frame.PoseInFrame).Pose()
+	}
+
+	t.Run("reaches the pose which can be reached", func(t *testing.T) {
+		p, meta := plan(t, unreachable, reachable)
+		test.That(t, meta.AlternativeReached, test.ShouldResemble, []int{1})
+		test.That(t, spatialmath.PoseAlmostCoincidentEps(finalPose(t, p, "xarm6"), reachablePose, 0.1), test.ShouldBeTrue)
+	})
+
+	t.Run("reaches a configuration", func(t *testing.T) {
+		p, meta := plan(t, unreachable, configuration)
+		test.That(t, meta.AlternativeReached, test.ShouldResemble, []int{1})
+		traj := p.Trajectory()
+		test.That(t, traj[len(traj)-1]["xarm6"], test.ShouldResemble, configuration.Configuration()["xarm6"])
+	})
+
+	t.Run("grows around obstacles toward the alternatives", func(t *testing.T) {
+		// Swinging the arm about its base to the configuration hits a box halfway.
+		swing := NewPlanState(nil, frame.FrameSystemInputs{"xarm6": {1.5, 0, 0, 0, 0, 0}})
+		halfway, err := fs.Transform(frame.FrameSystemInputs{"xarm6": {0.75, 0, 0, 0, 0, 0}}.ToLinearInputs(),
+			frame.NewPoseInFrame("xarm6", spatialmath.NewZeroPose()), frame.World)
+		test.That(t, err, test.ShouldBeNil)
+		box, err := spatialmath.NewBox(halfway.(*frame.PoseInFrame).Pose(), r3.Vector{X: 50, Y: 50, Z: 50}, "box")
+		test.That(t, err, test.ShouldBeNil)
+
+		p, meta, err := PlanMotion(context.Background(), logger, &PlanRequest{
+			FrameSystem:           fs,
+			Goals:                 []*PlanState{NewAlternativesPlanState(unreachable, swing)},
+			StartState:            NewPlanState(nil, start),
+			ObstaclesInWorldFrame: frame.NewGeometriesInFrame(frame.World, []spatialmath.Geometry{box}),
+			PlannerOptions:        NewBasicPlannerOptions(),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, meta.GoalsCBIRRTSolved, test.ShouldEqual, 1)
+		test.That(t, meta.AlternativeReached, test.ShouldResemble, []int{1})
+		traj := p.Trajectory()
+		test.That(t, traj[len(traj)-1]["xarm6"], test.ShouldResemble, swing.Configuration()["xarm6"])
+	})
+
+	t.Run("alternatives for different frames are tried in order", func(t *testing.T) {
+		gripperAlternative := NewPlanState(frame.FrameSystemPoses{
+			"gripper": frame.NewPoseInFrame(frame.World, reachablePose),
+		}, nil)
+		p, meta := plan(t, unreachable, gripperAlternative)
+		test.That(t, meta.AlternativeReached, test.ShouldResemble, []int{1})
+		test.That(t, spatialmath.PoseAlmostCoincidentEps(finalPose(t, p, "gripper"), reachablePose, 0.1), test.ShouldBeTrue)
+	})
+
+	t.Run("fails if no alternative can be reached", func(t *testing.T) {
+		_, _, err := PlanMotion(context.Background(), logger, &PlanRequest{
+			FrameSystem:    fs,
+			Goals:          []*PlanState{NewAlternativesPlanState(unreachable, unreachable)},
+			StartState:     NewPlanState(nil, start),
+			PlannerOptions: NewBasicPlannerOptions(),
+		})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "alternative 0")
+		test.That(t, err.Error(), test.ShouldContainSubstring, "alternative 1")
+	})
+}
+
+func TestPlanWaypoints(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	fs := frame.NewEmptyFrameSystem("")
+	xarm, err := frame.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "xarm6")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(xarm, fs.World()), test.ShouldBeNil)
+
+	poses := []spatialmath.Pose{
+		spatialmath.NewPose(r3.Vector{X: 300, Y: 100, Z: 300}, &spatialmath.OrientationVector{OZ: -1}),
+		spatialmath.NewPose(r3.Vector{X: 300, Y: -100, Z: 300}, &spatialmath.OrientationVector{OZ: -1}),
+		spatialmath.NewPose(r3.Vector{X: 200, Y: -100, Z: 400}, &spatialmath.OrientationVector{OZ: -1}),
+	}
+	waypoints := make([]Waypoint, 0, len(poses))
+	for _, pose := range poses {
+		waypoints = append(waypoints, Waypoint{
+			Goal: NewPlanState(frame.FrameSystemPoses{"xarm6": frame.NewPoseInFrame(frame.World, pose)}, nil),
+		})
+	}
+	// Only the motion between the first and second waypoints must be linear.
+	lineToleranceMm := 1.
+	waypoints[1].Constraints = &motionplan.Constraints{
+		LinearConstraint: []motionplan.LinearConstraint{{LineToleranceMm: lineToleranceMm, OrientationToleranceDegs: 2}},
+	}
+
+	plan, meta, err := PlanWaypoints(context.Background(), logger, fs,
+		NewPlanState(nil, frame.NewNeutralFrameSystemInputs(fs)), waypoints, nil, NewBasicPlannerOptions())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, meta.GoalsProcessed, test.ShouldEqual, len(waypoints))
+	test.That(t, meta.SubgoalsPerGoal[0], test.ShouldEqual, 1)
+	test.That(t, meta.SubgoalsPerGoal[1], test.ShouldBeGreaterThan, 1)
+	test.That(t, meta.SubgoalsPerGoal[2], test.ShouldEqual, 1)
+
+	// The plan is one trajectory through every waypoint, straight between the first two.
+	reached := 0
+	for _, inputs := range plan.Trajectory() {
+		pif, err := fs.Transform(inputs.ToLinearInputs(), frame.NewPoseInFrame("xarm6", spatialmath.NewZeroPose()), frame.World)
+		test.That(t, err, test.ShouldBeNil)
+		pose := pif.(*frame.PoseInFrame).Pose()
+		if reached == 1 {
+			dist := spatialmath.DistToLineSegment(poses[0].Point(), poses[1].Point(), pose.Point())
+			test.That(t, dist, test.ShouldBeLessThan, lineToleranceMm+0.1)
+		}
+		if reached < len(poses) && spatialmath.PoseAlmostCoincidentEps(pose, poses[reached], 0.1) {
+			reached++
+		}
+	}
+	test.That(t, reached, test.ShouldEqual, len(poses))
+}
+
 func TestArmObstacleSolve(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 
@@ -840,6 +985,75 @@ func TestValidatePlanRequest(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "goal with both alternatives and poses - fail",
+			request: &PlanRequest{
+				FrameSystem: fs,
+				Goals:       []*PlanState{{poses: validGoal[0].poses, alternatives: validGoal}},
+				StartState: &PlanState{structuredConfiguration: map[string][]frame.Input{
+					"frame1": {}, "frame2": {0},
+				}},
+			},
+			expectedErr: errors.New("goals with alternatives cannot also have configuration or poses populated"),
+		},
+		{
+			name: "nested alternatives - fail",
+			request: &PlanRequest{
+				FrameSystem: fs,
+				Goals:       []*PlanState{NewAlternativesPlanState(NewAlternativesPlanState(validGoal...))},
+				StartState: &PlanState{structuredConfiguration: map[string][]frame.Input{
+					"frame1": {}, "frame2": {0},
+				}},
+			},
+			expectedErr: errors.New("alternatives must be non-nil and cannot have alternatives of their own"),
+		},
+		{
+			name: "alternative's parent not in frame system - fail",
+			request: &PlanRequest{
+				FrameSystem: fs,
+				Goals:       []*PlanState{NewAlternativesPlanState(validGoal[0], badGoal[0])},
+				StartState: &PlanState{structuredConfiguration: map[string][]frame.Input{
+					"frame1": {}, "frame2": {0},
+				}},
+			},
+			expectedErr: errors.New("part with name frame1 references non-existent parent non-existent"),
+		},
+		{
+			name: "well formed PlanRequest with alternatives",
+			request: &PlanRequest{
+				FrameSystem: fs,
+				Goals: []*PlanState{NewAlternativesPlanState(
+					validGoal[0],
+					NewPlanState(nil, frame.FrameSystemInputs{"frame2": {0.5}}),
+				)},
+				StartState: &PlanState{structuredConfiguration: map[string][]frame.Input{
+					"frame1": {}, "frame2": {0},
+				}},
+			},
+		},
+		{
+			name: "segment constraints not one per goal - fail",
+			request: &PlanRequest{
+				FrameSystem:        fs,
+				Goals:              validGoal,
+				SegmentConstraints: []*motionplan.Constraints{nil, nil},
+				StartState: &PlanState{structuredConfiguration: map[string][]frame.Input{
+					"frame1": {}, "frame2": {0},
+				}},
+			},
+			expectedErr: errors.New("PlanRequest must have one SegmentConstraints entry per goal, got 2 for 1 goals"),
+		},
+		{
+			name: "well formed PlanRequest with segment constraints",
+			request: &PlanRequest{
+				FrameSystem:        fs,
+				Goals:              validGoal,
+				SegmentConstraints: []*motionplan.Constraints{nil},
+				StartState: &PlanState{structuredConfiguration: map[string][]frame.Input{
+					"frame1": {}, "frame2": {0},
+				}},
+			},
+		},
 		{
 			name:        "nil framesystem errors correctly",
 			request:     &PlanRequest{},
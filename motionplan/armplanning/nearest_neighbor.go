@@ -2,6 +2,7 @@ package armplanning
 
 import (
 	"math"
+	"slices"
 
 	"go.viam.com/rdk/motionplan"
 )
@@ -16,12 +17,15 @@ func nodeConfigurationDistanceFunc(node1, node2 *node) float64 {
 	})
 }
 
+// nearestNeighbor returns the node in tree closest to seed. Ties are broken by comparing inputs, as
+// the order in which the tree's nodes are visited differs between runs.
 func nearestNeighbor(seed *node, tree rrtMap, nodeDistanceFunc NodeDistanceMetric) *node {
 	bestDist := math.Inf(1)
 	var best *node
 	for k := range tree {
 		dist := nodeDistanceFunc(seed, k)
-		if dist < bestDist {
+		if dist < bestDist || (dist == bestDist && best != nil &&
+			slices.Compare(k.inputs.GetLinearizedInputs(), best.inputs.GetLinearizedInputs()) < 0) {
 			bestDist = dist
 			best = k
 		}
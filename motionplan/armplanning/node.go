@@ -492,6 +492,17 @@ func (sss *SolutionSolvingState) shouldStopEarly() bool {
 		minMillis = 500 * speedMultiplier
 		minAttempts = 1000
 	}
+
+	if sss.psc.pc.planOpts.Deterministic {
+		// How long the search has taken varies from run to run, so count the solutions seen instead.
+		if sss.processCalls > int(minAttempts) {
+			sss.logger.Debugf("stopping early bestScore %0.2f / %0.2f after %d solutions",
+				sss.bestScoreNoProblem, sss.bestScoreWithProblem, sss.processCalls)
+			return true
+		}
+		return false
+	}
+
 	timeToSearch := max(sss.firstSolutionTime*time.Duration(multiple), time.Duration(minMillis)*time.Millisecond)
 
 	if sss.psc.pc.planOpts.Timeout > 0 && len(sss.solutions) > 0 {
@@ -605,7 +616,8 @@ func getSolutions(ctx context.Context, psc *PlanSegmentContext, logger logging.L
 	ctxWithCancel, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	solutionGen := make(chan *ik.Solution, defaultNumThreads)
+	numThreads := psc.pc.planOpts.numThreads()
+	solutionGen := make(chan *ik.Solution, numThreads)
 	defer func() {
 		// In lieu of creating a separate WaitGroup to wait on before returning, we simply wait to
 		// see the `solutionGen` channel get closed to know that the goroutine we spawned has
@@ -627,8 +639,12 @@ func getSolutions(ctx context.Context, psc *PlanSegmentContext, logger logging.L
 			ikTime = 100 * time.Millisecond
 		}
 	}
+	if psc.pc.planOpts.Deterministic {
+		// Without time to extend into, the solver stops after a fixed number of iterations.
+		ikTime = 0
+	}
 
-	solver, err := ik.CreateCombinedIKSolver(logger.Sublogger("ik"), defaultNumThreads, psc.pc.planOpts.GoalThreshold, ikTime)
+	solver, err := ik.CreateCombinedIKSolver(logger.Sublogger("ik"), numThreads, psc.pc.planOpts.GoalThreshold, ikTime)
 	if err != nil {
 		close(solutionGen)
 		return nil, err
@@ -657,6 +673,7 @@ func getSolutions(ctx context.Context, psc *PlanSegmentContext, logger logging.L
 		solveErrorLock.Unlock()
 	})
 
+	stopped := false
 solutionLoop:
 	for {
 		select {
@@ -672,9 +689,15 @@ solutionLoop:
 				cancel()
 				break solutionLoop
 			}
+			if stopped && psc.pc.planOpts.Deterministic {
+				// How many solutions the solver produced before seeing the cancellation is up to
+				// the scheduler, so they can't be used.
+				continue
+			}
 			solvingState.process(ctx, stepSolution)
 			if solvingState.shouldStopEarly() {
 				cancel()
+				stopped = true
 				// we don't exit the loop to get the last solutions so we don't waste them
 			}
 		}
@@ -14,15 +14,20 @@ import (
 // PlanState is a struct which holds both a referenceframe.FrameSystemPoses and a configuration.
 // This is intended to be used as start or goal states for plans. Either field may be nil. Except
 // that start states must not use `FrameSystemPoses`, only `FrameSystemInputs`.
+//
+// A goal may instead list alternative PlanStates, any one of which satisfies it. See
+// NewAlternativesPlanState.
 type PlanState struct {
 	poses                   referenceframe.FrameSystemPoses
 	structuredConfiguration referenceframe.FrameSystemInputs
 	linearizedConfiguration *referenceframe.LinearInputs
+	alternatives            []*PlanState
 }
 
 type planStateJSON struct {
 	Poses         referenceframe.FrameSystemPoses  `json:"poses"`
 	Configuration referenceframe.FrameSystemInputs `json:"configuration"`
+	Alternatives  []*PlanState                     `json:"alternatives,omitempty"`
 }
 
 // MarshalJSON serializes a PlanState to JSON.
@@ -30,6 +35,7 @@ func (p *PlanState) MarshalJSON() ([]byte, error) {
 	stateJSON := planStateJSON{
 		Poses:         p.poses,
 		Configuration: p.structuredConfiguration,
+		Alternatives:  p.alternatives,
 	}
 	return json.Marshal(stateJSON)
 }
@@ -42,6 +48,7 @@ func (p *PlanState) UnmarshalJSON(data []byte) error {
 	}
 	p.poses = stateJSON.Poses
 	p.structuredConfiguration = stateJSON.Configuration
+	p.alternatives = stateJSON.Alternatives
 	return nil
 }
 
@@ -50,6 +57,14 @@ func NewPlanState(poses referenceframe.FrameSystemPoses, configuration reference
 	return &PlanState{poses: poses, structuredConfiguration: configuration}
 }
 
+// NewAlternativesPlanState creates a goal PlanState which is reached by reaching any one of the
+// given PlanStates, such as each of the places on a shelf an object may be put. Each alternative
+// has either poses or a configuration. The planner seeds and grows toward all of them at once, and
+// returns a plan to whichever it reaches first.
+func NewAlternativesPlanState(alternatives ...*PlanState) *PlanState {
+	return &PlanState{alternatives: alternatives}
+}
+
 // Poses returns the poses of the PlanState.
 func (p *PlanState) Poses() referenceframe.FrameSystemPoses {
 	return p.poses
@@ -60,6 +75,11 @@ func (p *PlanState) Configuration() referenceframe.FrameSystemInputs {
 	return p.structuredConfiguration
 }
 
+// Alternatives returns the alternatives of the PlanState, if it is reached by reaching any one of them.
+func (p *PlanState) Alternatives() []*PlanState {
+	return p.alternatives
+}
+
 // LinearConfiguration returns a `LinearInputs` version of the `Configuration`.
 func (p *PlanState) LinearConfiguration() *referenceframe.LinearInputs {
 	if p.linearizedConfiguration != nil {
@@ -105,6 +125,13 @@ func (p PlanState) Serialize() map[string]interface{} {
 	if p.structuredConfiguration != nil {
 		m["configuration"] = confMap
 	}
+	if p.alternatives != nil {
+		alternatives := make([]interface{}, 0, len(p.alternatives))
+		for _, alternative := range p.alternatives {
+			alternatives = append(alternatives, alternative.Serialize())
+		}
+		m["alternatives"] = alternatives
+	}
 	return m
 }
 
@@ -158,5 +185,22 @@ func DeserializePlanState(iface map[string]interface{}) (*PlanState, error) {
 	} else {
 		ps.structuredConfiguration = nil
 	}
+	if alternativesIface, ok := iface["alternatives"]; ok {
+		alternatives, ok := alternativesIface.([]interface{})
+		if !ok {
+			return nil, errors.New("could not decode contents of alternatives")
+		}
+		for _, alternativeIface := range alternatives {
+			alternativeMap, ok := alternativeIface.(map[string]interface{})
+			if !ok {
+				return nil, errors.New("alternative could not be interpreted as map[string]interface{}")
+			}
+			alternative, err := DeserializePlanState(alternativeMap)
+			if err != nil {
+				return nil, err
+			}
+			ps.alternatives = append(ps.alternatives, alternative)
+		}
+	}
 	return ps, nil
 }
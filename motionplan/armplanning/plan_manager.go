@@ -3,8 +3,14 @@ package armplanning
 import (
 	"context"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
 	"time"
 
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
 	"go.viam.com/utils/trace"
 
 	"go.viam.com/rdk/logging"
@@ -32,8 +38,8 @@ func newPlanManager(ctx context.Context, logger logging.Logger, request *PlanReq
 	}, nil
 }
 
-// planMultiWaypoint plans a motion through multiple waypoints, using identical constraints for each
-// Any constraints, etc, will be held for the entire motion.
+// planMultiWaypoint plans a motion through multiple waypoints. The request's constraints are held for
+// the entire motion, unless it has constraints per segment.
 // return trajector (always, even with error), which goal we got to, error.
 func (pm *planManager) planMultiWaypoint(ctx context.Context) ([]*referenceframe.LinearInputs, int, error) {
 	ctx, span := trace.StartSpan(ctx, "planMultiWaypoint")
@@ -55,57 +61,33 @@ func (pm *planManager) planMultiWaypoint(ctx context.Context) ([]*referenceframe
 	}
 
 	pm.pc.planMeta.SubgoalsPerGoal = make([]int, len(pm.request.Goals))
+	pm.pc.planMeta.AlternativeReached = make([]int, len(pm.request.Goals))
 	for i, g := range pm.request.Goals {
 		if ctx.Err() != nil {
 			return linearTraj, i, err // note: here and below, we return traj because of ReturnPartialPlan
 		}
 
-		to, err := g.ComputePoses(ctx, pm.request.FrameSystem)
-		if err != nil {
-			return linearTraj, i, err
-		}
-
 		if i > 0 {
 			pm.logger.Infof("planning step %d of %d, current linearTraj size: %d",
 				i, len(pm.request.Goals), len(linearTraj))
 		}
 
-		for k, v := range to {
-			pm.logger.Debug(k, v)
-		}
-
-		if len(g.Configuration()) > 0 {
-			newTraj, err := pm.planToDirectJoints(ctx, linearTraj[len(linearTraj)-1], g)
-			if err != nil {
-				return linearTraj, i, err
+		var newTraj []*referenceframe.LinearInputs
+		var to referenceframe.FrameSystemPoses
+		pm.pc.constraints = pm.request.constraintsForGoal(i)
+		pm.pc.planMeta.AlternativeReached[i] = -1
+		if len(g.Alternatives()) > 0 {
+			var reached int
+			newTraj, to, reached, err = pm.planToAlternatives(ctx, linearTraj[len(linearTraj)-1], start, g.Alternatives(), i)
+			if err == nil {
+				pm.pc.planMeta.AlternativeReached[i] = reached
 			}
-			linearTraj = append(linearTraj, newTraj...)
 		} else {
-			subGoals, cbirrtAllowed, err := pm.generateWaypoints(ctx, start, to)
-			if err != nil {
-				return linearTraj, i, err
-			}
-			pm.pc.planMeta.SubgoalsPerGoal[i] = len(subGoals)
-
-			if len(subGoals) > 1 {
-				pm.logger.Debugf("\t generateWaypoint turned into %d subGoals cbirrtAllowed: %v", len(subGoals), cbirrtAllowed)
-				pm.logger.Debugf("\t start: %v\n", start)
-				pm.logger.Debugf("\t to   : %v\n", to)
-				for _, sg := range subGoals {
-					pm.logger.Debugf("\t\t sg: %v", sg)
-				}
-			}
-
-			for subGoalIdx, sg := range subGoals {
-				singleGoalStart := time.Now()
-				newTraj, err := pm.planSingleGoal(ctx, linearTraj[len(linearTraj)-1], sg, cbirrtAllowed)
-				if err != nil {
-					pm.logger.Infof("\t subgoal %d failed after %v with: %v", subGoalIdx, time.Since(singleGoalStart), err)
-					return linearTraj, i, err
-				}
-				pm.logger.Debugf("\t subgoal %d took %v", subGoalIdx, time.Since(singleGoalStart))
-				linearTraj = append(linearTraj, newTraj...)
-			}
+			newTraj, to, err = pm.planToGoal(ctx, linearTraj[len(linearTraj)-1], start, g, i)
+		}
+		linearTraj = append(linearTraj, newTraj...)
+		if err != nil {
+			return linearTraj, i, err
 		}
 		start = to
 	}
@@ -113,13 +95,193 @@ func (pm *planManager) planMultiWaypoint(ctx context.Context) ([]*referenceframe
 	return linearTraj, len(pm.request.Goals), nil
 }
 
-func (pm *planManager) planToDirectJoints(
+// planToGoal plans from the configuration `from`, at poses `start`, to the goal with index goalIdx
+// in the request, or one of its alternatives. It returns the trajectory, which is partial if there
+// is an error, and the goal poses.
+func (pm *planManager) planToGoal(
 	ctx context.Context,
-	start *referenceframe.LinearInputs,
-	goal *PlanState,
-) ([]*referenceframe.LinearInputs, error) {
-	ctx, span := trace.StartSpan(ctx, "planToDirectJoints")
+	from *referenceframe.LinearInputs,
+	start referenceframe.FrameSystemPoses,
+	g *PlanState,
+	goalIdx int,
+) ([]*referenceframe.LinearInputs, referenceframe.FrameSystemPoses, error) {
+	to, err := g.ComputePoses(ctx, pm.request.FrameSystem)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for k, v := range to {
+		pm.logger.Debug(k, v)
+	}
+
+	if len(g.Configuration()) > 0 {
+		newTraj, err := pm.planToDirectJoints(ctx, from, g)
+		return newTraj, to, err
+	}
+
+	subGoals, cbirrtAllowed, err := pm.generateWaypoints(ctx, start, to)
+	if err != nil {
+		return nil, to, err
+	}
+	pm.pc.planMeta.SubgoalsPerGoal[goalIdx] = len(subGoals)
+
+	if len(subGoals) > 1 {
+		pm.logger.Debugf("\t generateWaypoint turned into %d subGoals cbirrtAllowed: %v", len(subGoals), cbirrtAllowed)
+		pm.logger.Debugf("\t start: %v\n", start)
+		pm.logger.Debugf("\t to   : %v\n", to)
+		for _, sg := range subGoals {
+			pm.logger.Debugf("\t\t sg: %v", sg)
+		}
+	}
+
+	var traj []*referenceframe.LinearInputs
+	for subGoalIdx, sg := range subGoals {
+		singleGoalStart := time.Now()
+		newTraj, err := pm.planSingleGoal(ctx, from, sg, cbirrtAllowed)
+		if err != nil {
+			pm.logger.Infof("\t subgoal %d failed after %v with: %v", subGoalIdx, time.Since(singleGoalStart), err)
+			return traj, to, err
+		}
+		pm.logger.Debugf("\t subgoal %d took %v", subGoalIdx, time.Since(singleGoalStart))
+		traj = append(traj, newTraj...)
+		if len(newTraj) > 0 {
+			from = newTraj[len(newTraj)-1]
+		}
+	}
+	return traj, to, nil
+}
+
+// planToAlternatives plans from the configuration `from`, at poses `start`, to whichever of a
+// goal's alternatives it can reach. It returns the trajectory, the poses and the index of the
+// alternative reached.
+//
+// IK is solved for every alternative at once, and CBiRRT grows toward the solutions of all of them
+// together. That needs the alternatives to move the same frames, and to share the constraints on
+// the path to them. Linear, pseudolinear and orientation constraints depend on the goal, so with
+// those, or with alternatives for different frames, the alternatives are instead planned to one at
+// a time in order, and the first reached is returned.
+func (pm *planManager) planToAlternatives(
+	ctx context.Context,
+	from *referenceframe.LinearInputs,
+	start referenceframe.FrameSystemPoses,
+	alternatives []*PlanState,
+	goalIdx int,
+) ([]*referenceframe.LinearInputs, referenceframe.FrameSystemPoses, int, error) {
+	ctx, span := trace.StartSpan(ctx, "planToAlternatives")
 	defer span.End()
+
+	if !pm.canPlanAlternativesTogether(alternatives) {
+		var errs error
+		for j, alternative := range alternatives {
+			traj, to, err := pm.planToGoal(ctx, from, start, alternative, goalIdx)
+			if err == nil {
+				return traj, to, j, nil
+			}
+			pm.logger.Debugf("alternative %d failed: %v", j, err)
+			errs = multierr.Append(errs, fmt.Errorf("alternative %d: %w", j, err))
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, nil, -1, errs
+	}
+
+	pm.pc.planMeta.SubgoalsPerGoal[goalIdx] = 1
+	goals := make([]referenceframe.FrameSystemPoses, len(alternatives))
+	configurations := make([]*referenceframe.LinearInputs, len(alternatives))
+	pscs := make([]*PlanSegmentContext, len(alternatives))
+	for j, alternative := range alternatives {
+		var err error
+		goals[j], err = alternative.ComputePoses(ctx, pm.request.FrameSystem)
+		if err != nil {
+			return nil, nil, -1, err
+		}
+		if len(alternative.Configuration()) > 0 {
+			configurations[j] = fullConfiguration(from, alternative)
+		}
+		// Each alternative is solved in its own goroutine, with its own random source and metadata,
+		// and its own copy of the start, as computing the schema of LinearInputs mutates them.
+		pscs[j], err = NewPlanSegmentContext(ctx, pm.pc.forAlternative(), independentCopy(from), goals[j])
+		if err != nil {
+			return nil, nil, -1, err
+		}
+	}
+
+	planSeed, alternativeOf, err := initRRTSolutionsAlternatives(ctx, pscs, configurations, pm.logger.Sublogger("solve"))
+	if pm.pc.planMeta.CollectSolutionDiagnostics {
+		for _, psc := range pscs {
+			pm.pc.planMeta.PerGoal = append(pm.pc.planMeta.PerGoal, psc.pc.planMeta.PerGoal...)
+		}
+	}
+	if err != nil {
+		return nil, nil, -1, err
+	}
+
+	if planSeed.steps != nil {
+		reached := alternativeOf[planSeed.steps[0]]
+		pm.logger.Debugf("found an ideal ik solution for alternative %d", reached)
+		return planSeed.steps, goals[reached], reached, nil
+	}
+
+	pm.logger.Debugf("initRRTSolutionsAlternatives goalMap size: %d", len(planSeed.maps.goalMap))
+	psc := pscs[alternativeOf[planSeed.maps.optNode.inputs]]
+	pathPlanner, err := newCBiRRTMotionPlanner(ctx, pm.pc, psc, pm.logger.Sublogger("cbirrt"))
+	if err != nil {
+		return nil, nil, -1, err
+	}
+
+	finalSteps, err := pathPlanner.rrtRunner(ctx, planSeed.maps)
+	if err != nil {
+		return nil, nil, -1, err
+	}
+	reached := alternativeOf[finalSteps.steps[len(finalSteps.steps)-1]]
+	pm.logger.Debugf("cbirrt reached alternative %d", reached)
+
+	finalSteps.steps, err = smoothPath(ctx, pscs[reached], finalSteps.steps)
+	if err != nil {
+		return nil, nil, -1, err
+	}
+
+	pm.pc.planMeta.GoalsCBIRRTSolved++
+	return finalSteps.steps, goals[reached], reached, nil
+}
+
+// canPlanAlternativesTogether returns whether planToAlternatives can grow toward all of the
+// alternatives at once.
+func (pm *planManager) canPlanAlternativesTogether(alternatives []*PlanState) bool {
+	constraints := pm.pc.constraints
+	if len(constraints.LinearConstraint) > 0 || len(constraints.PseudolinearConstraint) > 0 ||
+		len(constraints.OrientationConstraint) > 0 {
+		return false
+	}
+
+	frames := func(alternative *PlanState) []string {
+		if len(alternative.Configuration()) > 0 {
+			return slices.Sorted(maps.Keys(alternative.Configuration()))
+		}
+		return slices.Sorted(maps.Keys(alternative.Poses()))
+	}
+	first := frames(alternatives[0])
+	for _, alternative := range alternatives[1:] {
+		if !slices.Equal(first, frames(alternative)) {
+			return false
+		}
+	}
+	return true
+}
+
+// independentCopy returns a copy of li, in the same order, which shares no state with it.
+func independentCopy(li *referenceframe.LinearInputs) *referenceframe.LinearInputs {
+	c := referenceframe.NewLinearInputs()
+	for frameName, inputs := range li.Items() {
+		c.Put(frameName, inputs)
+	}
+	return c
+}
+
+// fullConfiguration returns the goal's configuration, with the configuration of every frame the
+// goal does not mention taken from start.
+func fullConfiguration(start *referenceframe.LinearInputs, goal *PlanState) *referenceframe.LinearInputs {
 	fullConfig := referenceframe.NewLinearInputs()
 	for k, v := range goal.Configuration() {
 		fullConfig.Put(k, v)
@@ -130,6 +292,17 @@ func (pm *planManager) planToDirectJoints(
 			fullConfig.Put(k, v)
 		}
 	}
+	return fullConfig
+}
+
+func (pm *planManager) planToDirectJoints(
+	ctx context.Context,
+	start *referenceframe.LinearInputs,
+	goal *PlanState,
+) ([]*referenceframe.LinearInputs, error) {
+	ctx, span := trace.StartSpan(ctx, "planToDirectJoints")
+	defer span.End()
+	fullConfig := fullConfiguration(start, goal)
 
 	goalPoses, err := goal.ComputePoses(ctx, pm.pc.fs)
 	if err != nil {
@@ -237,13 +410,13 @@ func (pm *planManager) generateWaypoints(ctx context.Context, start, goal refere
 ) ([]referenceframe.FrameSystemPoses, bool, error) {
 	_, span := trace.StartSpan(ctx, "generateWaypoints")
 	defer span.End()
-	if len(pm.request.Constraints.LinearConstraint) == 0 {
+	if len(pm.pc.constraints.LinearConstraint) == 0 {
 		return []referenceframe.FrameSystemPoses{goal}, true, nil
 	}
 
 	tighestConstraint := 10.0
 
-	for _, lc := range pm.request.Constraints.LinearConstraint {
+	for _, lc := range pm.pc.constraints.LinearConstraint {
 		tighestConstraint = min(tighestConstraint, lc.LineToleranceMm)
 		tighestConstraint = min(tighestConstraint, lc.OrientationToleranceDegs)
 	}
@@ -318,19 +491,46 @@ func initRRTSolutions(ctx context.Context, psc *PlanSegmentContext, logger loggi
 		},
 	}
 
+	seed := newConfigurationNode(psc.start)
+	// goalNodes are sorted from lowest cost to highest.
+	goalNodes, reasonableCost, err := solveGoalNodes(ctx, psc, logger)
+	if err != nil {
+		return rrt, err
+	}
+
+	rrt.maps.optNode = goalNodes[0]
+	for _, solution := range goalNodes {
+		if solution.cost > reasonableCost {
+			// if it's this bad, we don't want for cbirrt or going straight
+			continue
+		}
+
+		if solution.checkPath {
+			// If we've already checked the path of a solution that is "reasonable", we can just
+			// return now. Otherwise, continue to initialize goal map with keys.
+			rrt.steps = []*referenceframe.LinearInputs{solution.inputs}
+			return rrt, nil
+		}
+		rrt.maps.goalMap[&node{inputs: solution.inputs}] = nil
+	}
+	rrt.maps.startMap[&node{inputs: seed.inputs}] = nil
+
+	return rrt, nil
+}
+
+// solveGoalNodes solves IK for the goal of psc. It returns the solutions sorted from lowest cost
+// to highest, and the cost above which solutions aren't worth planning to.
+func solveGoalNodes(ctx context.Context, psc *PlanSegmentContext, logger logging.Logger) ([]*node, float64, error) {
 	if psc.pc.planMeta.CollectSolutionDiagnostics {
 		psc.pc.planMeta.PerGoal = append(psc.pc.planMeta.PerGoal, PerGoalMeta{})
 	}
 
-	seed := newConfigurationNode(psc.start)
-	// goalNodes are sorted from lowest cost to highest.
 	goalNodes, err := getSolutions(ctx, psc, logger)
 	if err != nil {
-		return rrt, err
+		return nil, 0, err
 	}
 
-	rrt.maps.optNode = goalNodes[0]
-	logger.Debugf("optNode cost: %v", rrt.maps.optNode.cost)
+	logger.Debugf("optNode cost: %v", goalNodes[0].cost)
 	// `defaultOptimalityMultiple` is > 1.0
 	reasonableCost := max(.01, goalNodes[0].cost) * defaultOptimalityMultiple
 
@@ -350,21 +550,107 @@ func initRRTSolutions(ctx context.Context, psc *PlanSegmentContext, logger loggi
 		}
 	}
 
-	for _, solution := range goalNodes {
-		if solution.cost > reasonableCost {
-			// if it's this bad, we don't want for cbirrt or going straight
+	return goalNodes, reasonableCost, nil
+}
+
+// initRRTSolutionsAlternatives is initRRTSolutions for a goal with alternatives, one
+// PlanSegmentContext each, all starting from the same configuration. Alternatives given as
+// configurations have a non-nil entry in configurations, and are used as goal nodes directly;
+// IK is solved for the rest concurrently. The goal map is seeded with the reasonable solutions
+// of every alternative that has any, and if some can be directly interpolated to, the cheapest
+// of them is returned as the steps. The returned map gives the alternative of each goal node.
+func initRRTSolutionsAlternatives(
+	ctx context.Context,
+	pscs []*PlanSegmentContext,
+	configurations []*referenceframe.LinearInputs,
+	logger logging.Logger,
+) (*rrtSolution, map[*referenceframe.LinearInputs]int, error) {
+	ctx, span := trace.StartSpan(ctx, "initRRTSolutionsAlternatives")
+	defer span.End()
+	rrt := &rrtSolution{
+		maps: &rrtMaps{
+			startMap: rrtMap{},
+			goalMap:  rrtMap{},
+		},
+	}
+
+	type alternativeSolutions struct {
+		goalNodes      []*node
+		reasonableCost float64
+		err            error
+	}
+	solutions := make([]alternativeSolutions, len(pscs))
+	var wg sync.WaitGroup
+	for i, psc := range pscs {
+		wg.Add(1)
+		utils.PanicCapturingGo(func() {
+			defer wg.Done()
+			if configurations[i] != nil {
+				solutions[i].goalNodes, solutions[i].err = configurationGoalNodes(ctx, psc, configurations[i])
+				solutions[i].reasonableCost = math.Inf(1)
+				return
+			}
+			solutions[i].goalNodes, solutions[i].reasonableCost, solutions[i].err = solveGoalNodes(
+				ctx, psc, logger.Sublogger(fmt.Sprintf("alternative%d", i)))
+		})
+	}
+	wg.Wait()
+
+	alternativeOf := map[*referenceframe.LinearInputs]int{}
+	var errs error
+	var direct *node
+	for i, solution := range solutions {
+		if solution.err != nil {
+			logger.Debugf("no solutions for alternative %d: %v", i, solution.err)
+			errs = multierr.Append(errs, fmt.Errorf("alternative %d: %w", i, solution.err))
 			continue
 		}
 
-		if solution.checkPath {
-			// If we've already checked the path of a solution that is "reasonable", we can just
-			// return now. Otherwise, continue to initialize goal map with keys.
-			rrt.steps = []*referenceframe.LinearInputs{solution.inputs}
-			return rrt, nil
+		if rrt.maps.optNode == nil || solution.goalNodes[0].cost < rrt.maps.optNode.cost {
+			rrt.maps.optNode = solution.goalNodes[0]
+		}
+		for _, goalNode := range solution.goalNodes {
+			if goalNode.cost > solution.reasonableCost {
+				continue
+			}
+			alternativeOf[goalNode.inputs] = i
+			if goalNode.checkPath && (direct == nil || goalNode.cost < direct.cost) {
+				direct = goalNode
+			}
+			rrt.maps.goalMap[&node{inputs: goalNode.inputs}] = nil
 		}
-		rrt.maps.goalMap[&node{inputs: solution.inputs}] = nil
 	}
-	rrt.maps.startMap[&node{inputs: seed.inputs}] = nil
+	if rrt.maps.optNode == nil {
+		return rrt, nil, errs
+	}
 
-	return rrt, nil
+	if direct != nil {
+		rrt.steps = []*referenceframe.LinearInputs{direct.inputs}
+		return rrt, alternativeOf, nil
+	}
+	rrt.maps.startMap[&node{inputs: pscs[0].start}] = nil
+
+	return rrt, alternativeOf, nil
+}
+
+// configurationGoalNodes returns the goal node for an alternative given as a configuration,
+// which must itself meet the constraints.
+func configurationGoalNodes(
+	ctx context.Context, psc *PlanSegmentContext, configuration *referenceframe.LinearInputs,
+) ([]*node, error) {
+	if _, err := psc.Checker.CheckStateFSConstraints(ctx, &motionplan.StateFS{
+		Configuration: configuration,
+		FS:            psc.pc.fs,
+	}); err != nil {
+		return nil, fmt.Errorf("want to go to specific joint config but it is invalid: %w", err)
+	}
+
+	goalNode := newConfigurationNode(configuration)
+	goalNode.cost = psc.pc.ConfigurationDistanceFunc(&motionplan.SegmentFS{
+		StartConfiguration: psc.start,
+		EndConfiguration:   configuration,
+		FS:                 psc.pc.fs,
+	})
+	goalNode.checkPath = psc.CheckPath(ctx, psc.start, configuration, false, nil) == nil
+	return []*node{goalNode}, nil
 }
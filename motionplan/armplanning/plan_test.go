@@ -1,6 +1,7 @@
 package armplanning
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"testing"
@@ -339,3 +340,42 @@ func BenchmarkLinearizeFSMetric(b *testing.B) {
 		minFunc(ctx, inps)
 	}
 }
+
+func TestPlanStateAlternatives(t *testing.T) {
+	pose := spatialmath.NewPoseFromPoint(r3.Vector{X: 100})
+	goal := NewAlternativesPlanState(
+		NewPlanState(referenceframe.FrameSystemPoses{"arm": referenceframe.NewPoseInFrame(referenceframe.World, pose)}, nil),
+		NewPlanState(nil, referenceframe.FrameSystemInputs{"arm": {1, 2, 3}}),
+	)
+
+	check := func(t *testing.T, got *PlanState) {
+		t.Helper()
+		test.That(t, got.Poses(), test.ShouldBeEmpty)
+		test.That(t, got.Configuration(), test.ShouldBeEmpty)
+		test.That(t, len(got.Alternatives()), test.ShouldEqual, 2)
+		test.That(t, spatialmath.PoseAlmostEqual(got.Alternatives()[0].Poses()["arm"].Pose(), pose), test.ShouldBeTrue)
+		test.That(t, got.Alternatives()[1].Configuration(), test.ShouldResemble, goal.Alternatives()[1].Configuration())
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(goal)
+		test.That(t, err, test.ShouldBeNil)
+		got := &PlanState{}
+		test.That(t, json.Unmarshal(data, got), test.ShouldBeNil)
+		check(t, got)
+	})
+
+	t.Run("Serialize", func(t *testing.T) {
+		// Serialized states travel in the extra of a motion request, which is JSON encoded.
+		data, err := json.Marshal(goal.Serialize())
+		test.That(t, err, test.ShouldBeNil)
+		var serialized map[string]interface{}
+		test.That(t, json.Unmarshal(data, &serialized), test.ShouldBeNil)
+		got, err := DeserializePlanState(serialized)
+		test.That(t, err, test.ShouldBeNil)
+		check(t, got)
+
+		_, err = DeserializePlanState(map[string]interface{}{"alternatives": "shelf"})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -46,10 +46,16 @@ const (
 	defaultOptimalityMultiple = 3.0
 )
 
-var defaultNumThreads = utils.MinInt(runtime.NumCPU()/2, 10)
+// At least one thread, as single CPU machines would otherwise get none.
+var defaultNumThreads = max(1, utils.MinInt(runtime.NumCPU()/2, 10))
+
+// defaultDeterministic turns on deterministic planning for every request, such that a CI failure
+// can be reproduced by setting MP_DETERMINISTIC=true without changing the requests that failed.
+var defaultDeterministic = false
 
 func init() {
 	defaultNumThreads = utils.GetenvInt("MP_NUM_THREADS", defaultNumThreads)
+	defaultDeterministic = utils.GetenvBool("MP_DETERMINISTIC", defaultDeterministic)
 }
 
 // NewBasicPlannerOptions specifies a set of basic options for the planner.
@@ -72,6 +78,7 @@ func NewBasicPlannerOptions() *PlannerOptions {
 
 	opt.CollisionBufferMM = defaultCollisionBufferMM
 	opt.RandomSeed = defaultRandomSeed
+	opt.Deterministic = defaultDeterministic
 
 	return opt
 }
@@ -126,9 +133,19 @@ type PlannerOptions struct {
 	// outputs for a given set of identical inputs
 	RandomSeed int `json:"rseed"`
 
+	// Deterministic makes identical requests with the same RandomSeed produce identical plans. IK is
+	// solved on a single thread for a fixed number of iterations, and no decision depends on how long
+	// anything took. Plans are slower to find, so this is meant for reproducing failures in tests.
+	Deterministic bool `json:"deterministic"`
+
 	// Setting indicating that all mesh geometries should be converted into octrees.
 	MeshesAsOctrees bool `json:"meshes_as_octrees"`
 
+	// CollisionBackend selects where collision checks are computed. With "gpu", obstacles that cannot
+	// be within CollisionBufferMM of the robot are culled on the GPU in scenes with many obstacles,
+	// falling back to "cpu", the default, if no GPU is available.
+	CollisionBackend motionplan.CollisionBackend `json:"collision_backend"`
+
 	// CollectSolutionDiagnostics enables collection of per-node IK solution data into PlanMeta.
 	// This includes SolutionNodes and ConstraintFailuresByType. Disabled by default because
 	// accumulating this data can be expensive for large solution sets.
@@ -154,6 +171,12 @@ func NewPlannerOptionsFromExtra(extra map[string]interface{}) (*PlannerOptions,
 		return nil, errors.New("collision_buffer_mm can't be negative")
 	}
 
+	switch opt.CollisionBackend {
+	case "", motionplan.CPUCollisionBackend, motionplan.GPUCollisionBackend:
+	default:
+		return nil, fmt.Errorf("unknown collision_backend %q", opt.CollisionBackend)
+	}
+
 	return opt, nil
 }
 
@@ -195,6 +218,14 @@ func (p *PlannerOptions) SetMinScore(minScore float64) {
 	p.MinScore = minScore
 }
 
+// numThreads returns how many goroutines to solve IK with.
+func (p *PlannerOptions) numThreads() int {
+	if p.Deterministic {
+		return 1
+	}
+	return defaultNumThreads
+}
+
 func (p *PlannerOptions) timeoutDuration() time.Duration {
 	return time.Duration(p.Timeout * float64(time.Second))
 }
@@ -522,6 +522,28 @@ func TestTeleOpTwoMove(t *testing.T) {
 	}
 }
 
+func TestDeterministicPlanning(t *testing.T) {
+	t.Parallel()
+	req, err := ReadRequestFromFile("data/plan-2026-02-12-left-arm-collision-avoidance.json")
+	test.That(t, err, test.ShouldBeNil)
+
+	opts, err := NewPlannerOptionsFromExtra(map[string]interface{}{"deterministic": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, opts.Deterministic, test.ShouldBeTrue)
+	test.That(t, opts.numThreads(), test.ShouldEqual, 1)
+
+	req.PlannerOptions.Deterministic = true
+	req.PlannerOptions.RandomSeed = 3
+
+	var trajectories []motionplan.Trajectory
+	for i := 0; i < 2; i++ {
+		plan, _, err := PlanMotion(context.Background(), logging.NewTestLogger(t), req)
+		test.That(t, err, test.ShouldBeNil)
+		trajectories = append(trajectories, plan.Trajectory())
+	}
+	test.That(t, trajectories[1], test.ShouldResemble, trajectories[0])
+}
+
 func TestWineBadBottleMoveGoodCost(t *testing.T) {
 	if IsTooSmallForCache() {
 		t.Skip()
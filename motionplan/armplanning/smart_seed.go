@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 	"strconv"
 	"sync"
@@ -80,6 +81,7 @@ func newCacheForFrame(f referenceframe.Frame, logger logging.Logger) (*cacheForF
 	start := time.Now()
 
 	ccf.entriesForCacheBuilding = make([][]smartSeedCacheEntry, defaultNumThreads)
+	ccf.pendingInputs = make([][]referenceframe.Input, defaultNumThreads)
 	perSize := totalCacheSizeEstimate(len(f.DoF())) / defaultNumThreads
 	for x := range ccf.entriesForCacheBuilding {
 		ccf.entriesForCacheBuilding[x] = make([]smartSeedCacheEntry, 0, perSize+1)
@@ -96,6 +98,9 @@ func newCacheForFrame(f referenceframe.Frame, logger logging.Logger) (*cacheForF
 			defer wg.Done()
 			values := make([]float64, len(f.DoF()))
 			err := ccf.buildCacheHelper(f, values, 0, x)
+			if err == nil {
+				err = ccf.flushCache(f, x)
+			}
 			if err != nil {
 				errLock.Lock()
 				mainErr = multierr.Combine(mainErr, err)
@@ -126,7 +131,10 @@ func newCacheForFrame(f referenceframe.Frame, logger logging.Logger) (*cacheForF
 
 type cacheForFrame struct {
 	entriesForCacheBuilding [][]smartSeedCacheEntry
-	totalSize               int
+	// pendingInputs are the configurations each thread has yet to transform, back to back, so
+	// that they are transformed in batches.
+	pendingInputs [][]referenceframe.Input
+	totalSize     int
 
 	maxNorm                    float64
 	minCartesian, maxCartesian r3.Vector
@@ -202,15 +210,38 @@ func (cff *cacheForFrame) buildCacheHelper(f referenceframe.Frame, values []floa
 	return nil
 }
 
+// smartSeedBatchSize is how many configurations each thread transforms at once while building the
+// cache.
+const smartSeedBatchSize = 1024
+
 func (cff *cacheForFrame) addToCache(frame referenceframe.Frame, inputsNotMine []float64, t int) error {
-	inputs := append([]float64{}, inputsNotMine...)
-	p, err := frame.Transform(inputs)
-	if err != nil {
-		return err
+	if cff.pendingInputs[t] == nil {
+		cff.pendingInputs[t] = make([]referenceframe.Input, 0, smartSeedBatchSize*len(inputsNotMine))
 	}
+	cff.pendingInputs[t] = append(cff.pendingInputs[t], inputsNotMine...)
+	if len(cff.pendingInputs[t]) < smartSeedBatchSize*len(inputsNotMine) {
+		return nil
+	}
+	return cff.flushCache(frame, t)
+}
 
-	cff.entriesForCacheBuilding[t] = append(cff.entriesForCacheBuilding[t], smartSeedCacheEntry{inputs, p.Point()})
-
+// flushCache transforms the configurations thread t has pending and adds them to the cache.
+func (cff *cacheForFrame) flushCache(frame referenceframe.Frame, t int) error {
+	inputs := cff.pendingInputs[t]
+	dof := len(frame.DoF())
+	if len(inputs) == 0 || dof == 0 {
+		return nil
+	}
+	poses := make([]spatialmath.DualQuaternion, len(inputs)/dof)
+	if err := referenceframe.TransformBatch(frame, inputs, poses); err != nil {
+		return err
+	}
+	for i := range poses {
+		// The entries keep slices of this batch's inputs, so the next batch needs a new array.
+		entryInputs := inputs[i*dof : (i+1)*dof : (i+1)*dof]
+		cff.entriesForCacheBuilding[t] = append(cff.entriesForCacheBuilding[t], smartSeedCacheEntry{entryInputs, poses[i].Point()})
+	}
+	cff.pendingInputs[t] = nil
 	return nil
 }
 
@@ -245,13 +276,19 @@ func (cff *cacheForFrame) buildInverseCache() {
 			}
 			box.entries = append(box.entries, e)
 
-			box.center = box.center.Add(e.pt)
-
 			cff.maxNorm = max(cff.maxNorm, e.pt.Norm())
 		}
 	}
 
+	// Entries arrive grouped by the thread that computed them, so sort them to keep the cache the
+	// same however many threads built it.
 	for _, box := range cff.boxes {
+		slices.SortFunc(box.entries, func(a, b smartSeedCacheEntry) int {
+			return slices.Compare(a.inputs, b.inputs)
+		})
+		for _, e := range box.entries {
+			box.center = box.center.Add(e.pt)
+		}
 		box.center = box.center.Mul(1.0 / float64(len(box.entries)))
 	}
 
@@ -269,17 +306,25 @@ func (cff *cacheForFrame) findBoxes(goalPose spatialmath.Pose) []*goalCacheBox {
 	best := []e{}
 	bestScore := cff.minCartesian.Distance(cff.maxCartesian) / 20
 
+	for _, b := range cff.boxes {
+		bestScore = min(goalPoint.Distance(b.center), bestScore)
+	}
+	// Boxes are visited in a different order every time, so the cutoff is applied only once the
+	// closest box is known and ties are broken by position.
 	for _, b := range cff.boxes {
 		d := goalPoint.Distance(b.center)
 		if d > bestScore*10 {
 			continue
 		}
-		bestScore = min(d, bestScore)
 		best = append(best, e{b, d})
 	}
 
 	sort.Slice(best, func(a, b int) bool {
-		return best[a].d < best[b].d
+		if best[a].d != best[b].d {
+			return best[a].d < best[b].d
+		}
+		ca, cb := best[a].b.center, best[b].b.center
+		return slices.Compare([]float64{ca.X, ca.Y, ca.Z}, []float64{cb.X, cb.Y, cb.Z}) < 0
 	})
 
 	boxes := []*goalCacheBox{}
@@ -0,0 +1,203 @@
+// Package benchmark measures how well the arm planner solves a set of standard scenes, such as
+// reaching into shelves, moving between bins and passing through a narrow corridor.
+//
+// Each scene is planned a number of times with consecutive random seeds, recording whether planning
+// succeeded, how long it took and how long the resulting path is. Reports from two commits, or from
+// two sets of planner options, can be compared to find regressions; cmd-benchmark does so from the
+// command line.
+package benchmark
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"time"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/motionplan/armplanning"
+)
+
+// Options are how to run a benchmark.
+type Options struct {
+	// Runs is how many times to plan each scene. Defaults to 10.
+	Runs int
+	// Timeout limits each plan, overriding any timeout in Extra. Defaults to 30 seconds.
+	Timeout time.Duration
+	// Extra overrides the default planner options, as the extra of a MoveRequest does. The random
+	// seed of each run is the "rseed" given here, or 0, plus the run's index.
+	Extra map[string]interface{}
+}
+
+// Result is how well the planner solved one scene.
+type Result struct {
+	Scene     string `json:"scene"`
+	Runs      int    `json:"runs"`
+	Successes int    `json:"successes"`
+	// PlanTimes are how long each successful plan took.
+	PlanTimes []time.Duration `json:"plan_times"`
+	// JointDistances are the total joint movement of each successful plan, in radians.
+	JointDistances []float64 `json:"joint_distances"`
+	// PathLengths are how far the arm's end moved in each successful plan, in millimeters.
+	PathLengths []float64 `json:"path_lengths_mm"`
+	// Errors are the errors of the failed plans.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// SuccessRate is the fraction of runs that produced a plan.
+func (r *Result) SuccessRate() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return float64(r.Successes) / float64(r.Runs)
+}
+
+// MedianPlanTime is the median time taken by successful runs.
+func (r *Result) MedianPlanTime() time.Duration {
+	return median(r.PlanTimes)
+}
+
+// MedianJointDistance is the median joint movement of successful runs, in radians.
+func (r *Result) MedianJointDistance() float64 {
+	return median(r.JointDistances)
+}
+
+// MedianPathLength is the median distance the arm's end moved in successful runs, in millimeters.
+func (r *Result) MedianPathLength() float64 {
+	return median(r.PathLengths)
+}
+
+func median[T time.Duration | float64](values []T) T {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	if len(sorted)%2 == 1 {
+		return sorted[len(sorted)/2]
+	}
+	return (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+}
+
+// Report is the results of benchmarking a set of scenes with the same options.
+type Report struct {
+	// Label identifies what was benchmarked, such as a commit.
+	Label   string                 `json:"label"`
+	Extra   map[string]interface{} `json:"extra,omitempty"`
+	Results []*Result              `json:"results"`
+}
+
+// Result returns the result for the named scene, or nil if it wasn't benchmarked.
+func (r *Report) Result(scene string) *Result {
+	for _, res := range r.Results {
+		if res.Scene == scene {
+			return res
+		}
+	}
+	return nil
+}
+
+// WriteToFile writes the report to fileName as JSON.
+func (r *Report) WriteToFile(fileName string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	//nolint:gosec
+	return os.WriteFile(fileName, data, 0o644)
+}
+
+// ReadReportFromFile reads a report written by WriteToFile.
+func ReadReportFromFile(fileName string) (*Report, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Run benchmarks the scenes, returning a report labelled label.
+func Run(ctx context.Context, logger logging.Logger, label string, scenes []Scene, opts Options) (*Report, error) {
+	report := &Report{Label: label, Extra: opts.Extra}
+	for _, scene := range scenes {
+		result, err := RunScene(ctx, logger, scene, opts)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// RunScene plans a scene opts.Runs times. Failing to plan is recorded in the result, while failing
+// to set up the scene is returned.
+func RunScene(ctx context.Context, logger logging.Logger, scene Scene, opts Options) (*Result, error) {
+	if opts.Runs <= 0 {
+		opts.Runs = 10
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	planOpts, err := armplanning.NewPlannerOptionsFromExtra(opts.Extra)
+	if err != nil {
+		return nil, err
+	}
+	planOpts.Timeout = opts.Timeout.Seconds()
+	seed := planOpts.RandomSeed
+
+	req, err := scene.Request()
+	if err != nil {
+		return nil, err
+	}
+	// Build the smart seed cache up front so that the first run's time doesn't include it.
+	if err := armplanning.PrepSmartSeed(req.FrameSystem, logger); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Scene: scene.Name, Runs: opts.Runs}
+	for i := 0; i < opts.Runs; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		runOpts := *planOpts
+		runOpts.RandomSeed = seed + i
+		req.PlannerOptions = &runOpts
+
+		plan, meta, err := armplanning.PlanMotion(ctx, logger, req)
+		if err != nil {
+			logger.Debugw("plan failed", "scene", scene.Name, "seed", runOpts.RandomSeed, "error", err)
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		length, err := pathLength(plan)
+		if err != nil {
+			return nil, err
+		}
+		result.Successes++
+		result.PlanTimes = append(result.PlanTimes, meta.Duration)
+		result.JointDistances = append(result.JointDistances, plan.Trajectory().EvaluateCost(motionplan.FSConfigurationL2Distance))
+		result.PathLengths = append(result.PathLengths, length)
+	}
+	logger.Infow("benchmarked scene", "scene", scene.Name, "success_rate", result.SuccessRate(),
+		"median_plan_time", result.MedianPlanTime(), "median_path_length_mm", result.MedianPathLength())
+	return result, nil
+}
+
+// pathLength returns how far the arm's end moves along the plan, in millimeters.
+func pathLength(plan motionplan.Plan) (float64, error) {
+	poses, err := plan.Path().GetFramePoses(ArmName)
+	if err != nil {
+		return 0, err
+	}
+	length := 0.
+	for i := 1; i < len(poses); i++ {
+		length += poses[i].Point().Distance(poses[i-1].Point())
+	}
+	return length, nil
+}
@@ -0,0 +1,72 @@
+package benchmark
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestScenes(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	report, err := Run(context.Background(), logger, "test", Scenes(), Options{Runs: 2})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, report.Results, test.ShouldHaveLength, len(Scenes()))
+	t.Log("\n" + report.Summary())
+
+	for _, res := range report.Results {
+		test.That(t, res.Errors, test.ShouldBeEmpty)
+		test.That(t, res.SuccessRate(), test.ShouldEqual, 1)
+		test.That(t, res.MedianPlanTime(), test.ShouldBeGreaterThan, 0)
+		test.That(t, res.MedianJointDistance(), test.ShouldBeGreaterThan, 0)
+		test.That(t, res.MedianPathLength(), test.ShouldBeGreaterThan, 0)
+	}
+
+	fileName := filepath.Join(t.TempDir(), "report.json")
+	test.That(t, report.WriteToFile(fileName), test.ShouldBeNil)
+	read, err := ReadReportFromFile(fileName)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, read, test.ShouldResemble, report)
+
+	_, err = SceneByName("moon")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &Report{Results: []*Result{
+		{
+			Scene: "a", Runs: 4, Successes: 4,
+			PlanTimes:      []time.Duration{time.Second, time.Second, 2 * time.Second, 2 * time.Second},
+			JointDistances: []float64{1, 1, 1, 1},
+			PathLengths:    []float64{100, 100, 100, 100},
+		},
+		{Scene: "only-baseline", Runs: 1},
+	}}
+	test.That(t, baseline.Results[0].MedianPlanTime(), test.ShouldEqual, 1500*time.Millisecond)
+	test.That(t, Compare(baseline, baseline, DefaultTolerances), test.ShouldBeEmpty)
+
+	current := &Report{Results: []*Result{
+		{
+			Scene: "a", Runs: 4, Successes: 3,
+			PlanTimes:      []time.Duration{3 * time.Second, 3 * time.Second, 3 * time.Second},
+			JointDistances: []float64{1.2, 1.2, 1.2},
+			PathLengths:    []float64{200, 200, 200},
+		},
+		{Scene: "only-current", Runs: 1},
+	}}
+	regressions := Compare(baseline, current, DefaultTolerances)
+	test.That(t, regressions, test.ShouldHaveLength, 3)
+	test.That(t, regressions[0], test.ShouldResemble, Regression{"a", "success rate", 1, 0.75})
+	test.That(t, regressions[1].Metric, test.ShouldEqual, "median plan time (s)")
+	test.That(t, regressions[2].Metric, test.ShouldEqual, "median path length (mm)")
+
+	// nothing succeeding is only a drop in success rate.
+	current.Results[0] = &Result{Scene: "a", Runs: 4}
+	regressions = Compare(baseline, current, DefaultTolerances)
+	test.That(t, regressions, test.ShouldHaveLength, 1)
+	test.That(t, regressions[0].String(), test.ShouldEqual, "a: success rate went from 1.000 to 0.000")
+}
@@ -0,0 +1,130 @@
+// package main benchmarks the arm planner on the standard scenes, and compares the results with an
+// earlier run to catch regressions. For example, to compare a branch with main:
+//
+//	git checkout main && go run ./motionplan/benchmark/cmd-benchmark -label main -out main.json
+//	git checkout my-branch && go run ./motionplan/benchmark/cmd-benchmark -label my-branch -compare main.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan/benchmark"
+)
+
+func main() {
+	if err := realMain(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func realMain() error {
+	ctx := context.Background()
+	logger := logging.NewLogger("cmd-benchmark")
+
+	sceneNames := flag.String("scenes", "", "comma separated scenes to benchmark, all of them by default")
+	list := flag.Bool("list", false, "list the scenes and exit")
+	runs := flag.Int("runs", 10, "how many times to plan each scene")
+	timeout := flag.Duration("timeout", 0, "limit on each plan, 30s by default")
+	options := flag.String("options", "", `planner options as JSON, such as {"deterministic": true}`)
+	label := flag.String("label", "", "label for the report, such as the commit")
+	out := flag.String("out", "", "json file to write the report to")
+	compare := flag.String("compare", "", "json report to compare with, failing on any regression")
+	successDrop := flag.Float64("success-drop", benchmark.DefaultTolerances.SuccessRateDrop,
+		"regression if the success rate drops by more than this")
+	timeRatio := flag.Float64("time-ratio", benchmark.DefaultTolerances.PlanTimeRatio,
+		"regression if the median plan time grows by more than this factor")
+	lengthRatio := flag.Float64("length-ratio", benchmark.DefaultTolerances.PathLengthRatio,
+		"regression if the median path length grows by more than this factor")
+	requests := flag.String("write-requests", "", "directory to write each scene's plan request to, for cmd-plan")
+	verbose := flag.Bool("v", false, "verbose")
+
+	flag.Parse()
+
+	if !*verbose {
+		logger.SetLevel(logging.WARN)
+	}
+
+	scenes := benchmark.Scenes()
+	if *list {
+		for _, s := range scenes {
+			fmt.Printf("%-16s %s\n", s.Name, s.Description)
+		}
+		return nil
+	}
+	if *sceneNames != "" {
+		scenes = nil
+		for _, name := range strings.Split(*sceneNames, ",") {
+			s, err := benchmark.SceneByName(strings.TrimSpace(name))
+			if err != nil {
+				return err
+			}
+			scenes = append(scenes, s)
+		}
+	}
+
+	if *requests != "" {
+		if err := os.MkdirAll(*requests, 0o750); err != nil {
+			return err
+		}
+		for _, s := range scenes {
+			req, err := s.Request()
+			if err != nil {
+				return err
+			}
+			if err := req.WriteToFile(filepath.Join(*requests, s.Name+".json")); err != nil {
+				return err
+			}
+		}
+	}
+
+	opts := benchmark.Options{Runs: *runs, Timeout: *timeout}
+	if *options != "" {
+		if err := json.Unmarshal([]byte(*options), &opts.Extra); err != nil {
+			return fmt.Errorf("invalid options: %w", err)
+		}
+	}
+
+	report, err := benchmark.Run(ctx, logger, *label, scenes, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report.Summary())
+
+	if *out != "" {
+		if err := report.WriteToFile(*out); err != nil {
+			return err
+		}
+	}
+
+	if *compare == "" {
+		return nil
+	}
+	baseline, err := benchmark.ReadReportFromFile(*compare)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nbaseline %s:\n%s", baseline.Label, baseline.Summary())
+	regressions := benchmark.Compare(baseline, report, benchmark.Tolerances{
+		SuccessRateDrop: *successDrop,
+		PlanTimeRatio:   *timeRatio,
+		PathLengthRatio: *lengthRatio,
+	})
+	if len(regressions) == 0 {
+		fmt.Println("\nno regressions")
+		return nil
+	}
+	fmt.Println("\nregressions:")
+	for _, r := range regressions {
+		fmt.Println("\t" + r.String())
+	}
+	return errors.New("found regressions")
+}
@@ -0,0 +1,80 @@
+package benchmark
+
+import (
+	"fmt"
+	"time"
+)
+
+// Tolerances are how much worse a result may be than its baseline before it is a regression.
+type Tolerances struct {
+	// SuccessRateDrop is how much lower the success rate may be, from 0 to 1.
+	SuccessRateDrop float64
+	// PlanTimeRatio is how many times the baseline's median plan time the median may be.
+	PlanTimeRatio float64
+	// PathLengthRatio is how many times the baseline's median path length and joint distance the
+	// medians may be.
+	PathLengthRatio float64
+}
+
+// DefaultTolerances allow for the noise between runs of a few dozen plans on the same machine.
+var DefaultTolerances = Tolerances{
+	SuccessRateDrop: 0.1,
+	PlanTimeRatio:   1.5,
+	PathLengthRatio: 1.25,
+}
+
+// Regression is a metric of a scene that is worse than in the baseline.
+type Regression struct {
+	Scene    string
+	Metric   string
+	Baseline float64
+	Current  float64
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s went from %.3f to %.3f", r.Scene, r.Metric, r.Baseline, r.Current)
+}
+
+// Compare returns the regressions of current from baseline. Scenes in only one of the reports are
+// ignored.
+func Compare(baseline, current *Report, tol Tolerances) []Regression {
+	var regressions []Regression
+	for _, cur := range current.Results {
+		base := baseline.Result(cur.Scene)
+		if base == nil {
+			continue
+		}
+		if cur.SuccessRate() < base.SuccessRate()-tol.SuccessRateDrop {
+			regressions = append(regressions, Regression{cur.Scene, "success rate", base.SuccessRate(), cur.SuccessRate()})
+		}
+		// Times and lengths mean nothing without successes to measure them by.
+		if base.Successes == 0 || cur.Successes == 0 {
+			continue
+		}
+		baseTime, curTime := base.MedianPlanTime().Seconds(), cur.MedianPlanTime().Seconds()
+		if curTime > baseTime*tol.PlanTimeRatio {
+			regressions = append(regressions, Regression{cur.Scene, "median plan time (s)", baseTime, curTime})
+		}
+		if cur.MedianJointDistance() > base.MedianJointDistance()*tol.PathLengthRatio {
+			regressions = append(regressions, Regression{
+				cur.Scene, "median joint distance (rad)", base.MedianJointDistance(), cur.MedianJointDistance(),
+			})
+		}
+		if cur.MedianPathLength() > base.MedianPathLength()*tol.PathLengthRatio {
+			regressions = append(regressions, Regression{
+				cur.Scene, "median path length (mm)", base.MedianPathLength(), cur.MedianPathLength(),
+			})
+		}
+	}
+	return regressions
+}
+
+// Summary returns a table of the report's results, one line per scene.
+func (r *Report) Summary() string {
+	s := fmt.Sprintf("%-16s %8s %12s %12s %14s\n", "scene", "success", "plan time", "joints (rad)", "path (mm)")
+	for _, res := range r.Results {
+		s += fmt.Sprintf("%-16s %7.0f%% %12v %12.3f %14.1f\n", res.Scene, 100*res.SuccessRate(),
+			res.MedianPlanTime().Round(time.Millisecond), res.MedianJointDistance(), res.MedianPathLength())
+	}
+	return s
+}
@@ -0,0 +1,245 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/arm/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan/armplanning"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// ArmName is the name of the UR5e every standard scene plans for. Its base is at the world origin.
+const ArmName = "ur5e"
+
+// Scene is a planning problem: an arm at a start configuration, a goal pose and obstacles.
+type Scene struct {
+	Name        string
+	Description string
+
+	build func(model referenceframe.Model) (*sceneSpec, error)
+}
+
+// sceneSpec is what a scene plans: from start to the pose of the arm at goal, around obstacles.
+type sceneSpec struct {
+	start, goal []referenceframe.Input
+	obstacles   []spatialmath.Geometry
+}
+
+// Request returns a new request to plan the scene with the default planner options.
+func (s Scene) Request() (*armplanning.PlanRequest, error) {
+	model, err := ur5e()
+	if err != nil {
+		return nil, err
+	}
+	spec, err := s.build(model)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := referenceframe.NewEmptyFrameSystem("benchmark")
+	if err := fs.AddFrame(model, fs.World()); err != nil {
+		return nil, err
+	}
+	goal, err := model.Transform(spec.goal)
+	if err != nil {
+		return nil, err
+	}
+	return &armplanning.PlanRequest{
+		FrameSystem: fs,
+		Goals: []*armplanning.PlanState{armplanning.NewPlanState(
+			referenceframe.FrameSystemPoses{ArmName: referenceframe.NewPoseInFrame(referenceframe.World, goal)}, nil,
+		)},
+		StartState:            armplanning.NewPlanState(nil, referenceframe.FrameSystemInputs{ArmName: spec.start}),
+		ObstaclesInWorldFrame: referenceframe.NewGeometriesInFrame(referenceframe.World, spec.obstacles),
+		PlannerOptions:        armplanning.NewBasicPlannerOptions(),
+	}, nil
+}
+
+// Scenes returns the standard scenes.
+func Scenes() []Scene {
+	return []Scene{
+		{
+			Name:        "open",
+			Description: "a half turn over a table with nothing in the way",
+			build:       openScene,
+		},
+		{
+			Name:        "shelves",
+			Description: "reaching into a shelf from beside it",
+			build:       shelvesScene,
+		},
+		{
+			Name:        "bins",
+			Description: "moving from inside one bin to inside another",
+			build:       binsScene,
+		},
+		{
+			Name:        "narrow-corridor",
+			Description: "passing through a gap between a wall and a ceiling",
+			build:       corridorScene,
+		},
+	}
+}
+
+// SceneByName returns the standard scene named name.
+func SceneByName(name string) (Scene, error) {
+	for _, s := range Scenes() {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return Scene{}, fmt.Errorf("no scene named %q", name)
+}
+
+// ur5e returns the kinematics of the fake arm's UR5e.
+func ur5e() (referenceframe.Model, error) {
+	ctx := context.Background()
+	a, err := fake.NewArm(ctx, nil, resource.Config{
+		Name:                ArmName,
+		API:                 arm.API,
+		Model:               fake.Model,
+		ConvertedAttributes: &fake.Config{ArmModel: ArmName},
+	}, logging.NewBlankLogger(ArmName))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		//nolint:errcheck
+		a.Close(ctx)
+	}()
+	return a.Kinematics(ctx)
+}
+
+// pointingDown returns the configuration turned theta radians about the base that reaches out with
+// the tool pointing down. A larger reach, from 0 to 1, is further from the base and lower.
+func pointingDown(theta, reach float64) []referenceframe.Input {
+	shoulder := -1.9 + 1.1*reach
+	elbow := 2.2 - 1.2*reach
+	return []referenceframe.Input{theta, shoulder, elbow, -math.Pi/2 - shoulder - elbow, -math.Pi / 2, 0}
+}
+
+// pointingOut returns the configuration turned theta radians about the base that reaches out with
+// the tool pointing away from the base.
+func pointingOut(theta, shoulder, elbow float64) []referenceframe.Input {
+	return []referenceframe.Input{theta, shoulder, elbow, -shoulder - elbow, math.Pi / 2, 0}
+}
+
+// box returns an axis aligned box between the corners lo and hi.
+func box(label string, lo, hi r3.Vector) (spatialmath.Geometry, error) {
+	return spatialmath.NewBox(spatialmath.NewPoseFromPoint(lo.Add(hi).Mul(0.5)), hi.Sub(lo), label)
+}
+
+// boxes returns the boxes between each pair of corners, labelled by their order.
+func boxes(label string, corners ...r3.Vector) ([]spatialmath.Geometry, error) {
+	geometries := make([]spatialmath.Geometry, 0, len(corners)/2)
+	for i := 0; i+1 < len(corners); i += 2 {
+		b, err := box(fmt.Sprintf("%s-%d", label, i/2), corners[i], corners[i+1])
+		if err != nil {
+			return nil, err
+		}
+		geometries = append(geometries, b)
+	}
+	return geometries, nil
+}
+
+// table is the surface the arm is mounted on.
+func table() (spatialmath.Geometry, error) {
+	return box("table", r3.Vector{X: -1200, Y: -1200, Z: -60}, r3.Vector{X: 1200, Y: 1200, Z: -10})
+}
+
+func openScene(model referenceframe.Model) (*sceneSpec, error) {
+	t, err := table()
+	if err != nil {
+		return nil, err
+	}
+	return &sceneSpec{
+		start:     pointingDown(-math.Pi/2, 0.5),
+		goal:      pointingDown(math.Pi/2, 0.5),
+		obstacles: []spatialmath.Geometry{t},
+	}, nil
+}
+
+func shelvesScene(model referenceframe.Model) (*sceneSpec, error) {
+	t, err := table()
+	if err != nil {
+		return nil, err
+	}
+	// Two open bays, one above the other, facing the arm along the -x axis. The goal is 80mm into
+	// the lower bay.
+	shelves, err := boxes("shelf",
+		r3.Vector{X: -1000, Y: -450, Z: -10}, r3.Vector{X: -700, Y: 200, Z: 120},
+		r3.Vector{X: -1000, Y: -450, Z: 520}, r3.Vector{X: -700, Y: 200, Z: 540},
+		r3.Vector{X: -1000, Y: -450, Z: 920}, r3.Vector{X: -700, Y: 200, Z: 940},
+		r3.Vector{X: -1020, Y: -450, Z: -10}, r3.Vector{X: -1000, Y: 200, Z: 940},
+		r3.Vector{X: -1000, Y: -470, Z: -10}, r3.Vector{X: -700, Y: -450, Z: 940},
+		r3.Vector{X: -1000, Y: 200, Z: -10}, r3.Vector{X: -700, Y: 220, Z: 940},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sceneSpec{
+		start:     pointingOut(-1.4, -1.2, 1.6),
+		goal:      pointingOut(0, -0.8, 1.0),
+		obstacles: append(shelves, t),
+	}, nil
+}
+
+func binsScene(model referenceframe.Model) (*sceneSpec, error) {
+	start, goal := pointingDown(-0.9, 0.6), pointingDown(0.9, 0.6)
+	obstacles := []spatialmath.Geometry{}
+	t, err := table()
+	if err != nil {
+		return nil, err
+	}
+	obstacles = append(obstacles, t)
+	// A bin is centered below each end of the motion, with the tool 40mm below its rim.
+	for i, inputs := range [][]referenceframe.Input{start, goal} {
+		tool, err := model.Transform(inputs)
+		if err != nil {
+			return nil, err
+		}
+		c := tool.Point()
+		lo := r3.Vector{X: c.X - 200, Y: c.Y - 200, Z: c.Z - 200}
+		hi := r3.Vector{X: c.X + 200, Y: c.Y + 200, Z: c.Z + 40}
+		walls, err := boxes(fmt.Sprintf("bin-%d", i),
+			lo, r3.Vector{X: hi.X, Y: hi.Y, Z: lo.Z + 10},
+			lo, r3.Vector{X: lo.X + 10, Y: hi.Y, Z: hi.Z},
+			r3.Vector{X: hi.X - 10, Y: lo.Y, Z: lo.Z}, hi,
+			lo, r3.Vector{X: hi.X, Y: lo.Y + 10, Z: hi.Z},
+			r3.Vector{X: lo.X, Y: hi.Y - 10, Z: lo.Z}, hi,
+		)
+		if err != nil {
+			return nil, err
+		}
+		obstacles = append(obstacles, walls...)
+	}
+	return &sceneSpec{start: start, goal: goal, obstacles: obstacles}, nil
+}
+
+func corridorScene(model referenceframe.Model) (*sceneSpec, error) {
+	t, err := table()
+	if err != nil {
+		return nil, err
+	}
+	// A wall between the start and the goal leaves a 300mm high gap below a ceiling.
+	obstacles, err := boxes("corridor",
+		r3.Vector{X: -1000, Y: -20, Z: -10}, r3.Vector{X: -250, Y: 20, Z: 450},
+		r3.Vector{X: -1000, Y: -600, Z: 750}, r3.Vector{X: -250, Y: 600, Z: 790},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sceneSpec{
+		start:     pointingDown(-0.6, 0.5),
+		goal:      pointingDown(0.6, 0.5),
+		obstacles: append(obstacles, t),
+	}, nil
+}
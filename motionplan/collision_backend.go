@@ -0,0 +1,131 @@
+package motionplan
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// CollisionBackend is a string enum indicating where collision checks are computed.
+type CollisionBackend string
+
+const (
+	// CPUCollisionBackend checks every pair of moving and obstacle geometries on the CPU. It is the default.
+	CPUCollisionBackend CollisionBackend = "cpu"
+	// GPUCollisionBackend culls obstacles which cannot be within the collision buffer of any moving
+	// geometry on the GPU before the remaining pairs are checked on the CPU. It needs a build with the
+	// opencl tag and an OpenCL device, and falls back to the CPU backend otherwise.
+	GPUCollisionBackend CollisionBackend = "gpu"
+)
+
+// broadPhaseMinObstacles is the number of obstacles below which a broad phase is skipped, as
+// copying the scene to the device costs more than checking the few pairs it would cull.
+var broadPhaseMinObstacles = 256
+
+// boundingSphere encloses a geometry, centered on its pose.
+type boundingSphere struct {
+	center r3.Vector
+	radius float64
+}
+
+// broadPhase cheaply bounds the distances between moving geometries and obstacles, so that only
+// the obstacles that may be close are handed to the exact checks.
+type broadPhase interface {
+	// lowerBounds sets out[i] to a lower bound on the distance between obstacles[i] and the nearest
+	// of the moving spheres. out has the same length as obstacles.
+	lowerBounds(moving, obstacles []boundingSphere, out []float64) error
+}
+
+// NewCollisionCacheWithBackend constructs an empty cache whose collision constraints compute on
+// the given backend. An empty backend is the CPU backend. If the GPU backend is requested but no
+// device is available, a warning is logged and the CPU backend is used.
+func NewCollisionCacheWithBackend(backend CollisionBackend, logger logging.Logger) (*CollisionCache, error) {
+	cache := NewCollisionCache()
+	switch backend {
+	case "", CPUCollisionBackend:
+	case GPUCollisionBackend:
+		bp, err := newGPUBroadPhase()
+		if err != nil {
+			logger.Warnf("GPU collision backend unavailable, falling back to CPU: %v", err)
+			break
+		}
+		cache.broadPhase = bp
+	default:
+		return nil, fmt.Errorf("unknown collision backend %q", backend)
+	}
+	return cache, nil
+}
+
+// boundingSpheres returns a bounding sphere for each geometry, and false if any geometry has no
+// bounding radius.
+func boundingSpheres(geometries []spatialmath.Geometry) ([]boundingSphere, bool) {
+	spheres := make([]boundingSphere, 0, len(geometries))
+	for _, g := range geometries {
+		r, err := spatialmath.BoundingRadius(g)
+		if err != nil {
+			return nil, false
+		}
+		spheres = append(spheres, boundingSphere{center: g.Pose().Point(), radius: r})
+	}
+	return spheres, true
+}
+
+// obstacleBroadPhase culls obstacles for one collision constraint. The obstacles are fixed when the
+// constraint is built, so their bounding spheres are computed once.
+type obstacleBroadPhase struct {
+	bp        broadPhase
+	obstacles []spatialmath.Geometry
+	spheres   []boundingSphere
+	// sphereOf maps each obstacle to its index in spheres, or -1 if it has no bounding radius and
+	// must always be checked.
+	sphereOf []int
+}
+
+func newObstacleBroadPhase(bp broadPhase, obstacles []spatialmath.Geometry) *obstacleBroadPhase {
+	if bp == nil || len(obstacles) < broadPhaseMinObstacles {
+		return nil
+	}
+	obp := &obstacleBroadPhase{bp: bp, obstacles: obstacles, sphereOf: make([]int, len(obstacles))}
+	for i, g := range obstacles {
+		r, err := spatialmath.BoundingRadius(g)
+		if err != nil {
+			obp.sphereOf[i] = -1
+			continue
+		}
+		obp.sphereOf[i] = len(obp.spheres)
+		obp.spheres = append(obp.spheres, boundingSphere{center: g.Pose().Point(), radius: r})
+	}
+	return obp
+}
+
+// cull returns the obstacles which may be within collisionBufferMM of a moving geometry, and a
+// lower bound on the distance to those which are not. It returns every obstacle if a moving
+// geometry has no bounding radius.
+func (obp *obstacleBroadPhase) cull(
+	moving []spatialmath.Geometry,
+	collisionBufferMM float64,
+) ([]spatialmath.Geometry, float64, error) {
+	movingSpheres, ok := boundingSpheres(moving)
+	if !ok || len(movingSpheres) == 0 || len(obp.spheres) == 0 {
+		return obp.obstacles, math.Inf(1), nil
+	}
+
+	bounds := make([]float64, len(obp.spheres))
+	if err := obp.bp.lowerBounds(movingSpheres, obp.spheres, bounds); err != nil {
+		return nil, math.Inf(-1), err
+	}
+	kept := make([]spatialmath.Geometry, 0, len(obp.obstacles))
+	culledMin := math.Inf(1)
+	for i, g := range obp.obstacles {
+		if j := obp.sphereOf[i]; j >= 0 && bounds[j] > collisionBufferMM {
+			culledMin = math.Min(culledMin, bounds[j])
+			continue
+		}
+		kept = append(kept, g)
+	}
+	return kept, culledMin, nil
+}
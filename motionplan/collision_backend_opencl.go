@@ -0,0 +1,223 @@
+//go:build opencl && !no_cgo
+
+package motionplan
+
+/*
+#cgo CFLAGS: -DCL_TARGET_OPENCL_VERSION=120
+#cgo linux LDFLAGS: -lOpenCL
+#cgo darwin LDFLAGS: -framework OpenCL
+
+#include <stdlib.h>
+#ifdef __APPLE__
+#include <OpenCL/opencl.h>
+#else
+#include <CL/cl.h>
+#endif
+
+typedef struct {
+	cl_context context;
+	cl_command_queue queue;
+	cl_program program;
+	cl_kernel kernel;
+} rdk_cl_state;
+
+// rdk_cl_init builds the kernel in source on the first GPU of any platform. Resources of a failed
+// initialization are not released, as it is only attempted once per process.
+static cl_int rdk_cl_init(const char *source, rdk_cl_state *state) {
+	cl_platform_id platforms[8];
+	cl_uint numPlatforms = 0;
+	cl_int err = clGetPlatformIDs(8, platforms, &numPlatforms);
+	if (err != CL_SUCCESS) {
+		return err;
+	}
+	if (numPlatforms > 8) {
+		numPlatforms = 8;
+	}
+
+	cl_device_id device = NULL;
+	for (cl_uint i = 0; i < numPlatforms && device == NULL; i++) {
+		if (clGetDeviceIDs(platforms[i], CL_DEVICE_TYPE_GPU, 1, &device, NULL) != CL_SUCCESS) {
+			device = NULL;
+		}
+	}
+	if (device == NULL) {
+		return CL_DEVICE_NOT_FOUND;
+	}
+
+	state->context = clCreateContext(NULL, 1, &device, NULL, NULL, &err);
+	if (err != CL_SUCCESS) {
+		return err;
+	}
+	state->queue = clCreateCommandQueue(state->context, device, 0, &err);
+	if (err != CL_SUCCESS) {
+		return err;
+	}
+	state->program = clCreateProgramWithSource(state->context, 1, &source, NULL, &err);
+	if (err != CL_SUCCESS) {
+		return err;
+	}
+	err = clBuildProgram(state->program, 1, &device, NULL, NULL, NULL);
+	if (err != CL_SUCCESS) {
+		return err;
+	}
+	state->kernel = clCreateKernel(state->program, "lower_bounds", &err);
+	return err;
+}
+
+// rdk_cl_lower_bounds runs the kernel over numObstacles obstacles, reading back one float each
+// into out. Spheres are packed as x, y, z, radius.
+static cl_int rdk_cl_lower_bounds(
+	rdk_cl_state *state,
+	const void *moving, cl_uint numMoving,
+	const void *obstacles, cl_uint numObstacles,
+	void *out
+) {
+	cl_int err = CL_SUCCESS;
+	cl_mem movingBuf = NULL, obstaclesBuf = NULL, outBuf = NULL;
+	size_t global = numObstacles;
+
+	movingBuf = clCreateBuffer(state->context, CL_MEM_READ_ONLY | CL_MEM_COPY_HOST_PTR,
+		sizeof(cl_float4) * numMoving, (void *)moving, &err);
+	if (err == CL_SUCCESS) {
+		obstaclesBuf = clCreateBuffer(state->context, CL_MEM_READ_ONLY | CL_MEM_COPY_HOST_PTR,
+			sizeof(cl_float4) * numObstacles, (void *)obstacles, &err);
+	}
+	if (err == CL_SUCCESS) {
+		outBuf = clCreateBuffer(state->context, CL_MEM_WRITE_ONLY, sizeof(cl_float) * numObstacles, NULL, &err);
+	}
+	if (err == CL_SUCCESS) {
+		err = clSetKernelArg(state->kernel, 0, sizeof(cl_mem), &movingBuf);
+	}
+	if (err == CL_SUCCESS) {
+		err = clSetKernelArg(state->kernel, 1, sizeof(cl_uint), &numMoving);
+	}
+	if (err == CL_SUCCESS) {
+		err = clSetKernelArg(state->kernel, 2, sizeof(cl_mem), &obstaclesBuf);
+	}
+	if (err == CL_SUCCESS) {
+		err = clSetKernelArg(state->kernel, 3, sizeof(cl_mem), &outBuf);
+	}
+	if (err == CL_SUCCESS) {
+		err = clEnqueueNDRangeKernel(state->queue, state->kernel, 1, NULL, &global, NULL, 0, NULL, NULL);
+	}
+	if (err == CL_SUCCESS) {
+		err = clEnqueueReadBuffer(state->queue, outBuf, CL_TRUE, 0, sizeof(cl_float) * numObstacles, out, 0, NULL, NULL);
+	}
+
+	if (outBuf != NULL) {
+		clReleaseMemObject(outBuf);
+	}
+	if (obstaclesBuf != NULL) {
+		clReleaseMemObject(obstaclesBuf);
+	}
+	if (movingBuf != NULL) {
+		clReleaseMemObject(movingBuf);
+	}
+	return err;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"unsafe"
+)
+
+// lowerBoundsKernel runs one work-item per obstacle, each finding the smallest gap between its
+// obstacle's sphere and any of the moving spheres.
+const lowerBoundsKernel = `
+__kernel void lower_bounds(
+	__global const float4 *moving,
+	const uint numMoving,
+	__global const float4 *obstacles,
+	__global float *out
+) {
+	size_t i = get_global_id(0);
+	float4 obstacle = obstacles[i];
+	float best = INFINITY;
+	for (uint j = 0; j < numMoving; j++) {
+		float4 m = moving[j];
+		best = fmin(best, distance(obstacle.xyz, m.xyz) - obstacle.w - m.w);
+	}
+	out[i] = best;
+}
+`
+
+// float32Slack, times the largest coordinate in a scene, is subtracted from the bounds computed in
+// single precision on the device so that they remain lower bounds on the exact distances.
+const float32Slack = 1e-5
+
+// gpu holds the one OpenCL context shared by every plan in the process.
+var gpu struct {
+	once  sync.Once
+	state C.rdk_cl_state
+	err   error
+
+	// mu serializes kernel runs, as the kernel's arguments are set on the shared kernel object.
+	mu sync.Mutex
+}
+
+// gpuBroadPhase computes obstacle lower bounds with an OpenCL kernel.
+type gpuBroadPhase struct{}
+
+func newGPUBroadPhase() (broadPhase, error) {
+	gpu.once.Do(func() {
+		source := C.CString(lowerBoundsKernel)
+		defer C.free(unsafe.Pointer(source))
+		if code := C.rdk_cl_init(source, &gpu.state); code != C.CL_SUCCESS {
+			gpu.err = fmt.Errorf("initializing OpenCL failed with error %d", int(code))
+		}
+	})
+	if gpu.err != nil {
+		return nil, gpu.err
+	}
+	return gpuBroadPhase{}, nil
+}
+
+func (gpuBroadPhase) lowerBounds(moving, obstacles []boundingSphere, out []float64) error {
+	if len(obstacles) == 0 {
+		return nil
+	}
+	if len(moving) == 0 {
+		for i := range out {
+			out[i] = math.Inf(1)
+		}
+		return nil
+	}
+
+	movingPacked, movingScale := packSpheres(moving)
+	obstaclesPacked, obstaclesScale := packSpheres(obstacles)
+	bounds := make([]float32, len(obstacles))
+
+	gpu.mu.Lock()
+	code := C.rdk_cl_lower_bounds(
+		&gpu.state,
+		unsafe.Pointer(&movingPacked[0]), C.cl_uint(len(moving)),
+		unsafe.Pointer(&obstaclesPacked[0]), C.cl_uint(len(obstacles)),
+		unsafe.Pointer(&bounds[0]),
+	)
+	gpu.mu.Unlock()
+	if code != C.CL_SUCCESS {
+		return fmt.Errorf("running OpenCL collision kernel failed with error %d", int(code))
+	}
+
+	slack := float32Slack * math.Max(movingScale, obstaclesScale)
+	for i, b := range bounds {
+		out[i] = float64(b) - slack
+	}
+	return nil
+}
+
+// packSpheres converts spheres to the kernel's float4 layout, returning the largest magnitude of
+// any coordinate or radius among them.
+func packSpheres(spheres []boundingSphere) ([]float32, float64) {
+	packed := make([]float32, 0, 4*len(spheres))
+	scale := 0.
+	for _, s := range spheres {
+		packed = append(packed, float32(s.center.X), float32(s.center.Y), float32(s.center.Z), float32(s.radius))
+		scale = math.Max(scale, math.Max(s.radius, math.Max(math.Abs(s.center.X), math.Max(math.Abs(s.center.Y), math.Abs(s.center.Z)))))
+	}
+	return packed, scale
+}
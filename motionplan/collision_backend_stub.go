@@ -0,0 +1,11 @@
+//go:build !opencl || no_cgo
+
+package motionplan
+
+import "errors"
+
+// newGPUBroadPhase always fails, as this build has no GPU support. Build with the opencl tag and
+// cgo to enable it.
+func newGPUBroadPhase() (broadPhase, error) {
+	return nil, errors.New("built without the opencl tag")
+}
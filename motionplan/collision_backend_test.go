@@ -0,0 +1,159 @@
+package motionplan
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// cpuBroadPhase computes the same bounds as the GPU kernel, in double precision.
+type cpuBroadPhase struct{}
+
+func (cpuBroadPhase) lowerBounds(moving, obstacles []boundingSphere, out []float64) error {
+	for i, o := range obstacles {
+		out[i] = math.Inf(1)
+		for _, m := range moving {
+			out[i] = math.Min(out[i], o.center.Sub(m.center).Norm()-o.radius-m.radius)
+		}
+	}
+	return nil
+}
+
+func TestNewCollisionCacheWithBackend(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	for _, backend := range []CollisionBackend{"", CPUCollisionBackend} {
+		cache, err := NewCollisionCacheWithBackend(backend, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cache.broadPhase, test.ShouldBeNil)
+	}
+
+	// Without a device, the GPU backend falls back to the CPU rather than failing the plan.
+	cache, err := NewCollisionCacheWithBackend(GPUCollisionBackend, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cache, test.ShouldNotBeNil)
+
+	_, err = NewCollisionCacheWithBackend("tpu", logger)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestObstacleBroadPhase(t *testing.T) {
+	model, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("test")
+	test.That(t, fs.AddFrame(model, fs.Frame(referenceframe.World)), test.ShouldBeNil)
+	seedMap := referenceframe.NewNeutralFrameSystemInputs(fs)
+	moving, err := model.Geometries(seedMap[model.Name()])
+	test.That(t, err, test.ShouldBeNil)
+
+	// A grid of small boxes around the arm, some within its reach, plus a mesh which has no
+	// bounding radius and so is never culled.
+	box, err := spatial.NewBox(spatial.NewZeroPose(), r3.Vector{X: 20, Y: 20, Z: 20}, "")
+	test.That(t, err, test.ShouldBeNil)
+	var obstacles []spatial.Geometry
+	for x := -1000.; x <= 1000; x += 100 {
+		for y := -1000.; y <= 1000; y += 100 {
+			obstacle := box.Transform(spatial.NewPoseFromPoint(r3.Vector{X: x, Y: y, Z: 400}))
+			obstacle.SetLabel(fmt.Sprintf("box_%v_%v", x, y))
+			obstacles = append(obstacles, obstacle)
+		}
+	}
+	mesh := spatial.NewMesh(spatial.NewPoseFromPoint(r3.Vector{X: 5000}), []*spatial.Triangle{
+		spatial.NewTriangle(r3.Vector{}, r3.Vector{X: 10}, r3.Vector{Y: 10}),
+	}, "mesh")
+	obstacles = append(obstacles, mesh)
+	test.That(t, len(obstacles), test.ShouldBeGreaterThanOrEqualTo, broadPhaseMinObstacles)
+
+	t.Run("culls distant obstacles", func(t *testing.T) {
+		obp := newObstacleBroadPhase(cpuBroadPhase{}, obstacles)
+		test.That(t, obp, test.ShouldNotBeNil)
+		kept, culledMin, err := obp.cull(moving.Geometries(), defaultCollisionBufferMM)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(kept), test.ShouldBeLessThan, len(obstacles))
+		test.That(t, kept, test.ShouldContain, mesh)
+		test.That(t, culledMin, test.ShouldBeGreaterThan, defaultCollisionBufferMM)
+
+		test.That(t, newObstacleBroadPhase(nil, obstacles), test.ShouldBeNil)
+		test.That(t, newObstacleBroadPhase(cpuBroadPhase{}, obstacles[:10]), test.ShouldBeNil)
+	})
+
+	t.Run("agrees with checking every pair", func(t *testing.T) {
+		logger := logging.NewTestLogger(t)
+		cache := NewCollisionCache()
+		cache.broadPhase = cpuBroadPhase{}
+		movingFrames := map[string]bool{model.Name(): true}
+		culled, err := NewCollisionConstraintFS(
+			fs, moving.Geometries(), movingFrames, obstacles, nil, defaultCollisionBufferMM, false, nil, cache, logger)
+		test.That(t, err, test.ShouldBeNil)
+		exhaustive, err := NewCollisionConstraintFS(
+			fs, moving.Geometries(), movingFrames, obstacles, nil, defaultCollisionBufferMM, false, nil, nil, logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		rseed := rand.New(rand.NewSource(1))
+		collided := 0
+		for i := 0; i < 100; i++ {
+			configuration := referenceframe.FrameSystemInputs{
+				model.Name(): referenceframe.RandomFrameInputs(model, rseed),
+			}.ToLinearInputs()
+			culledDist, culledErr := culled(&StateFS{Configuration: configuration, FS: fs})
+			exhaustiveDist, exhaustiveErr := exhaustive(&StateFS{Configuration: configuration, FS: fs})
+			test.That(t, culledErr == nil, test.ShouldEqual, exhaustiveErr == nil)
+			if exhaustiveErr != nil {
+				collided++
+				continue
+			}
+			test.That(t, culledDist, test.ShouldBeLessThanOrEqualTo, exhaustiveDist)
+		}
+		test.That(t, collided, test.ShouldBeGreaterThan, 0)
+	})
+}
+
+func BenchmarkObstacleBroadPhase(b *testing.B) {
+	model, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "")
+	test.That(b, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("test")
+	test.That(b, fs.AddFrame(model, fs.Frame(referenceframe.World)), test.ShouldBeNil)
+	moving, err := model.Geometries(referenceframe.NewNeutralFrameSystemInputs(fs)[model.Name()])
+	test.That(b, err, test.ShouldBeNil)
+
+	box, err := spatial.NewBox(spatial.NewZeroPose(), r3.Vector{X: 20, Y: 20, Z: 20}, "")
+	test.That(b, err, test.ShouldBeNil)
+	var obstacles []spatial.Geometry
+	for x := -2000.; x <= 2000; x += 50 {
+		for y := -2000.; y <= 2000; y += 50 {
+			obstacle := box.Transform(spatial.NewPoseFromPoint(r3.Vector{X: x, Y: y, Z: -500}))
+			obstacle.SetLabel(fmt.Sprintf("box_%v_%v", x, y))
+			obstacles = append(obstacles, obstacle)
+		}
+	}
+
+	for _, tc := range []struct {
+		name  string
+		cache *CollisionCache
+	}{
+		{"exhaustive", nil},
+		{"culled", &CollisionCache{broadPhase: cpuBroadPhase{}}},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			constraint, err := NewCollisionConstraintFS(fs, moving.Geometries(), map[string]bool{model.Name(): true},
+				obstacles, nil, defaultCollisionBufferMM, false, nil, tc.cache, logging.NewTestLogger(b))
+			test.That(b, err, test.ShouldBeNil)
+			state := &StateFS{Configuration: referenceframe.NewNeutralFrameSystemInputs(fs).ToLinearInputs(), FS: fs}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				state.movingGeometries = nil
+				if _, err := constraint(state); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -32,6 +32,10 @@ type CollisionCache struct {
 	// for an interpolated edge. Key is the canonical {hashA, hashB} pair —
 	// uint64 fits inside sync.Map's interface{} slot without allocation.
 	edgeResults sync.Map // edgeResultKey -> edgeResultValue
+
+	// broadPhase, when set, culls distant obstacles before the obstacle and
+	// robot-vs-robot constraints check pairs. See NewCollisionCacheWithBackend.
+	broadPhase broadPhase
 }
 
 // NewCollisionCache constructs an empty cache. Safe for concurrent use.
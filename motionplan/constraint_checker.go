@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"slices"
 	"sync/atomic"
 
 	"github.com/pkg/errors"
@@ -480,7 +481,9 @@ func CreateAllCollisionConstraints(
 // outside of the collisions present for the observationInput. Collisions specified as collisionSpecifications will also be ignored.
 //
 // When pairHint is non-nil, the closure uses it to remember the most-recently-
-// violated (geomA, geomB) pair and try that pair first on the next call.
+// violated (geomA, geomB) pair and try that pair first on the next call. When cache
+// has a broad phase and there are enough obstacles, obstacles that cannot be within
+// collisionBufferMM of a moving geometry are culled before pairs are checked.
 func NewCollisionConstraintFS(
 	fs *referenceframe.FrameSystem,
 	moving []spatialmath.Geometry,
@@ -493,7 +496,6 @@ func NewCollisionConstraintFS(
 	cache *CollisionCache,
 	logger logging.Logger,
 ) (CollisionConstraintFunc, error) {
-	_ = cache // reserved for future planner-level caches (edge memoization lives elsewhere on the same struct)
 	ignoreCollisions, err := computeInitialCollisionsToIgnore(fs, moving, static,
 		collisionSpecifications, collisionBufferMM, logger)
 	if err != nil {
@@ -502,6 +504,11 @@ func NewCollisionConstraintFS(
 
 	allowed := makeAllowedCollisionsLookup(ignoreCollisions)
 
+	var obstacles *obstacleBroadPhase
+	if cache != nil && !isSelfCollision {
+		obstacles = newObstacleBroadPhase(cache.broadPhase, static)
+	}
+
 	// create constraint from reference collision graph
 	constraint := func(state *StateFS) (float64, error) {
 		// state.movingGeometries is shared across the three collision constraints
@@ -526,9 +533,17 @@ func NewCollisionConstraintFS(
 		if isSelfCollision {
 			staticToCheck = internalGeoms
 		}
+		culledMin := math.Inf(1)
+		if obstacles != nil {
+			var err error
+			if staticToCheck, culledMin, err = obstacles.cull(internalGeoms, collisionBufferMM); err != nil {
+				return math.Inf(-1), err
+			}
+		}
 
 		collisions, minDist, err := checkCollisionsHinted(
 			internalGeoms, staticToCheck, allowed, collisionBufferMM, false, pairHint, logger)
+		minDist = math.Min(minDist, culledMin)
 		if err != nil {
 			return minDist, err
 		}
@@ -551,12 +566,20 @@ func computeInitialCollisionsToIgnore(
 	collisionBufferMM float64,
 	logger logging.Logger,
 ) ([]Collision, error) {
-	// Geometries in collision at move start should thereafter be ignored
-	initialCollisions, _, err := checkCollisionsHinted(
+	// Geometries in collision at move start should thereafter be ignored, unless a model's self-collision
+	// config enables checking them
+	disabled, enabled := selfCollisionConfigPairs(fs, group1, group2)
+	collisions, _, err := checkCollisionsHinted(
 		group1, group2, makeAllowedCollisionsLookup(collisionSpecifications), collisionBufferMM, true, nil, logger)
 	if err != nil {
 		return nil, err
 	}
+	initialCollisions := []Collision{}
+	for _, c := range collisions {
+		if !enabled[canonicalPair(c.name1, c.name2)] {
+			initialCollisions = append(initialCollisions, c)
+		}
+	}
 
 	// Add collision specifications
 	initialCollisions = append(initialCollisions, collisionSpecifications...)
@@ -564,9 +587,73 @@ func computeInitialCollisionsToIgnore(
 	// Add coparented static frames that could never be brought into collision
 	initialCollisions = append(initialCollisions, findCoparentedStaticFrames(fs, group1, group2)...)
 
+	// Add pairs models' self-collision configs disable
+	initialCollisions = append(initialCollisions, disabled...)
+
 	return initialCollisions, nil
 }
 
+// selfCollisionConfigPairs returns the pairs of geometries, from group1 and group2, whose checking the
+// self-collision configs of the models in fs disable and enable. The geometries of a model's root links are
+// not checked against those of the frame it is mounted on, the nearest static frame with geometries above it.
+func selfCollisionConfigPairs(fs *referenceframe.FrameSystem, group1, group2 []spatialmath.Geometry) (
+	[]Collision, map[[2]string]bool,
+) {
+	labels := map[string]bool{}
+	for _, g := range append(slices.Clip(group1), group2...) {
+		labels[g.Label()] = true
+	}
+
+	disabled := []Collision{}
+	enabled := map[[2]string]bool{}
+	addDisabled := func(name1, name2 string) {
+		if labels[name1] && labels[name2] {
+			disabled = append(disabled, Collision{name1: name1, name2: name2})
+		}
+	}
+	for _, name := range fs.FrameNames() {
+		model, ok := fs.Frame(name).(*referenceframe.SimpleModel)
+		if !ok {
+			continue
+		}
+		pairs := model.SelfCollision()
+		for _, pair := range pairs.Disabled {
+			addDisabled(pair[0], pair[1])
+		}
+		for _, pair := range pairs.Enabled {
+			enabled[canonicalPair(pair[0], pair[1])] = true
+		}
+		if len(pairs.Mounted) == 0 {
+			continue
+		}
+		for _, mountLabel := range mountGeometryLabels(fs, model) {
+			for _, label := range pairs.Mounted {
+				addDisabled(label, mountLabel)
+			}
+		}
+	}
+	return disabled, enabled
+}
+
+// mountGeometryLabels returns the labels of the geometries of the nearest static frame with geometries
+// above frame, if there is one before a moving frame or the world frame.
+func mountGeometryLabels(fs *referenceframe.FrameSystem, frame referenceframe.Frame) []string {
+	for {
+		parent, err := fs.Parent(frame)
+		if err != nil || parent == nil || parent == fs.World() || len(parent.DoF()) != 0 {
+			return nil
+		}
+		if gif, err := parent.Geometries(nil); err == nil && gif != nil && len(gif.Geometries()) > 0 {
+			labels := []string{}
+			for _, g := range gif.Geometries() {
+				labels = append(labels, g.Label())
+			}
+			return labels
+		}
+		frame = parent
+	}
+}
+
 func findCoparentedStaticFrames(fs *referenceframe.FrameSystem, group1, group2 []spatialmath.Geometry) []Collision {
 	skipList := []Collision{}
 
@@ -642,19 +729,15 @@ func segmentStepCount(ci *SegmentFS, resolution float64) (int, error) {
 		}
 
 		// Calculate positions for this frame's start and end configs
-		startPos, err := frame.Transform(startConfig)
-		if err != nil {
-			return -1, err
-		}
-		endPos, err := frame.Transform(endConfig)
-		if err != nil {
+		var poses [2]spatialmath.DualQuaternion
+		if err := referenceframe.TransformBatch(frame, append(slices.Clip(startConfig), endConfig...), poses[:]); err != nil {
 			return -1, err
 		}
 
 		// Compute joint step size from the largest limit range, divided by 1000
 		jointStepSize := jointStepSizeFromLimits(frame.DoF())
 
-		maxSteps = max(maxSteps, CalculateStepCount(startPos, endPos, resolution))
+		maxSteps = max(maxSteps, CalculateStepCount(&poses[0], &poses[1], resolution))
 		maxSteps = max(maxSteps, calculateJointStepCount(startConfig, endConfig, jointStepSize))
 	}
 	return maxSteps, nil
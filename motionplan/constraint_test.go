@@ -488,6 +488,40 @@ func TestComputeInitialCollisionsToIgnore(t *testing.T) {
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, len(ignoreList), test.ShouldEqual, 0)
 	})
+
+	t.Run("honors self-collision configs of models", func(t *testing.T) {
+		// The arm's base sits on the mount, and each of its links touches the next.
+		model, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("referenceframe/testfiles/self_collision_arm.json"), "arm")
+		test.That(t, err, test.ShouldBeNil)
+		mountBox, err := spatial.NewBox(spatial.NewPoseFromPoint(r3.Vector{Z: -30}), r3.Vector{100, 100, 20}, "mount")
+		test.That(t, err, test.ShouldBeNil)
+		mount, err := referenceframe.NewStaticFrameWithGeometry("mount", spatial.NewZeroPose(), mountBox)
+		test.That(t, err, test.ShouldBeNil)
+		fs := referenceframe.NewEmptyFrameSystem("")
+		test.That(t, fs.AddFrame(mount, fs.World()), test.ShouldBeNil)
+		test.That(t, fs.AddFrame(model, mount), test.ShouldBeNil)
+
+		geoms, err := referenceframe.FrameSystemGeometries(fs, referenceframe.NewNeutralFrameSystemInputs(fs))
+		test.That(t, err, test.ShouldBeNil)
+		moving := geoms["arm"].Geometries()
+		static := geoms["mount"].Geometries()
+		logger := logging.NewTestLogger(t)
+
+		// upper is adjacent to and touching base, but enabled, so it is still checked.
+		ignoreList, err := computeInitialCollisionsToIgnore(fs, moving, moving, nil, defaultCollisionBufferMM, logger)
+		test.That(t, err, test.ShouldBeNil)
+		ignored := makeAllowedCollisionsLookup(ignoreList)
+		test.That(t, ignored[canonicalPair("arm:base", "arm:upper")], test.ShouldBeFalse)
+		test.That(t, ignored[canonicalPair("arm:upper", "arm:fore")], test.ShouldBeTrue)
+		test.That(t, ignored[canonicalPair("arm:base", "arm:fore")], test.ShouldBeTrue)
+
+		// Only the root link is adjacent to the mount.
+		ignoreList, err = computeInitialCollisionsToIgnore(fs, moving, static, nil, defaultCollisionBufferMM, logger)
+		test.That(t, err, test.ShouldBeNil)
+		ignored = makeAllowedCollisionsLookup(ignoreList)
+		test.That(t, ignored[canonicalPair("arm:base", "mount")], test.ShouldBeTrue)
+		test.That(t, ignored[canonicalPair("arm:upper", "mount")], test.ShouldBeFalse)
+	})
 }
 
 func TestCollisionDistance(t *testing.T) {
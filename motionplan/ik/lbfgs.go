@@ -0,0 +1,225 @@
+package ik
+
+import (
+	"context"
+	"math"
+)
+
+const (
+	// lbfgsMemory is the number of past steps the optimizer approximates the curvature of the cost from.
+	lbfgsMemory = 6
+	// lbfgsSufficientDecrease is the fraction of the decrease predicted by the gradient a step must achieve.
+	lbfgsSufficientDecrease = 1e-4
+	// lbfgsInitialMove is how far the first step moves the input with the steepest gradient, before any
+	// curvature is known to scale it.
+	lbfgsInitialMove = 0.1
+)
+
+// lbfgsOptimizer minimizes a function within bounds with a projected limited-memory BFGS method. It is
+// the pure-Go stand-in for nlopt and stops under the same conditions as the nlopt optimizer does.
+type lbfgsOptimizer struct {
+	ctx                    context.Context
+	objective              objectiveFunc
+	lowerBound, upperBound []float64
+	ftolRel, xtolRel       float64
+}
+
+func newLBFGSOptimizer(
+	ctx context.Context,
+	lowerBound, upperBound []float64,
+	objective objectiveFunc,
+	useRelTol bool,
+) *lbfgsOptimizer {
+	o := &lbfgsOptimizer{ctx: ctx, objective: objective, lowerBound: lowerBound, upperBound: upperBound}
+	if useRelTol {
+		o.ftolRel = defaultGoalThreshold
+		o.xtolRel = defaultGoalThreshold
+	}
+	return o
+}
+
+// lbfgsStep is a past step s and the change in gradient y over it.
+type lbfgsStep struct {
+	s, y []float64
+	rho  float64
+}
+
+func (o *lbfgsOptimizer) optimize(start []float64) ([]float64, float64, error) {
+	n := len(start)
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = o.project(i, start[i])
+	}
+	g := make([]float64, n)
+	f := o.objective(x, g)
+	evals := 1
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return x, f, errOptimizerFailure
+	}
+
+	var history []lbfgsStep
+	free := make([]bool, n)
+	p := make([]float64, n)
+	next := make([]float64, n)
+	nextG := make([]float64, n)
+	for evals < nloptStepsPerIter && f >= defaultGoalThreshold && o.ctx.Err() == nil {
+		// inputs held at a bound by the gradient are left there.
+		for i := range free {
+			free[i] = !(x[i] <= o.lowerBound[i] && g[i] > 0) && !(x[i] >= o.upperBound[i] && g[i] < 0)
+		}
+		slope := o.direction(g, free, history, p)
+		if slope >= 0 {
+			// the approximated curvature no longer describes the cost, so start over from the gradient.
+			history = history[:0]
+			slope = o.direction(g, free, history, p)
+		}
+		if slope == 0 {
+			// no descent direction within the bounds.
+			break
+		}
+
+		// backtrack along the projected direction until the cost decreases enough.
+		alpha := 1.0
+		var nextCost float64
+		for {
+			var predicted float64
+			for i := range next {
+				next[i] = o.project(i, x[i]+alpha*p[i])
+				predicted += g[i] * (next[i] - x[i])
+			}
+			nextCost = o.objective(next, nil)
+			evals++
+			if nextCost <= f+lbfgsSufficientDecrease*predicted {
+				break
+			}
+			if evals >= nloptStepsPerIter {
+				return x, f, nil
+			}
+			if alpha*infNorm(p) < defaultGoalThreshold {
+				if len(history) == 0 {
+					return x, f, nil
+				}
+				// the approximated curvature led nowhere, so search along the gradient instead.
+				history = history[:0]
+				slope = o.direction(g, free, history, p)
+				alpha = 1
+				continue
+			}
+			// minimize the quadratic interpolation of the cost along the direction, within safeguards.
+			candidate := -0.5 * slope * alpha * alpha / (nextCost - f - slope*alpha)
+			if math.IsNaN(candidate) || candidate < 0.1*alpha || candidate > 0.5*alpha {
+				candidate = alpha / 2
+			}
+			alpha = candidate
+		}
+		nextCost = o.objective(next, nextG)
+		evals++
+
+		step := lbfgsStep{s: make([]float64, n), y: make([]float64, n)}
+		var sy, yy float64
+		xConverged := true
+		for i := range next {
+			step.s[i] = next[i] - x[i]
+			step.y[i] = nextG[i] - g[i]
+			sy += step.s[i] * step.y[i]
+			yy += step.y[i] * step.y[i]
+			if math.Abs(step.s[i]) >= defaultGoalThreshold+o.xtolRel*math.Abs(next[i]) {
+				xConverged = false
+			}
+		}
+		change := math.Abs(f - nextCost)
+		fConverged := change < defaultGoalThreshold || change < o.ftolRel*math.Abs(nextCost)
+
+		copy(x, next)
+		copy(g, nextG)
+		f = nextCost
+		if xConverged || fConverged {
+			break
+		}
+		// only steps along which the cost curves upward describe a minimum.
+		if sy > 1e-10*yy {
+			step.rho = 1 / sy
+			if len(history) == lbfgsMemory {
+				history = history[1:]
+			}
+			history = append(history, step)
+		}
+	}
+	return x, f, nil
+}
+
+// direction fills p with the quasi-Newton direction of the free inputs, which is the negative gradient
+// scaled by the inverse curvature approximated from history, and returns the slope of the cost along it.
+func (o *lbfgsOptimizer) direction(g []float64, free []bool, history []lbfgsStep, p []float64) float64 {
+	for i := range p {
+		if free[i] {
+			p[i] = -g[i]
+		} else {
+			p[i] = 0
+		}
+	}
+	alphas := make([]float64, len(history))
+	for k := len(history) - 1; k >= 0; k-- {
+		alphas[k] = history[k].rho * maskedDot(history[k].s, p, free)
+		for i := range p {
+			if free[i] {
+				p[i] -= alphas[k] * history[k].y[i]
+			}
+		}
+	}
+	var curvature float64
+	if len(history) != 0 {
+		last := history[len(history)-1]
+		curvature = last.rho * maskedDot(last.y, last.y, free)
+	}
+	if curvature > 0 {
+		scale(p, 1/curvature)
+	} else if norm := infNorm(p); norm > 0 {
+		// without curvature, the step is scaled to move the input with the steepest gradient a fixed amount.
+		scale(p, lbfgsInitialMove/norm)
+	}
+	for k := range history {
+		beta := history[k].rho * maskedDot(history[k].y, p, free)
+		for i := range p {
+			if free[i] {
+				p[i] += (alphas[k] - beta) * history[k].s[i]
+			}
+		}
+	}
+
+	var slope float64
+	for i := range p {
+		slope += g[i] * p[i]
+	}
+	return slope
+}
+
+func (o *lbfgsOptimizer) project(i int, v float64) float64 {
+	return math.Min(math.Max(v, o.lowerBound[i]), o.upperBound[i])
+}
+
+func (o *lbfgsOptimizer) destroy() {}
+
+func maskedDot(a, b []float64, mask []bool) float64 {
+	var dot float64
+	for i := range a {
+		if mask[i] {
+			dot += a[i] * b[i]
+		}
+	}
+	return dot
+}
+
+func scale(v []float64, factor float64) {
+	for i := range v {
+		v[i] *= factor
+	}
+}
+
+func infNorm(v []float64) float64 {
+	var norm float64
+	for _, x := range v {
+		norm = math.Max(norm, math.Abs(x))
+	}
+	return norm
+}
@@ -0,0 +1,45 @@
+package ik
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestLBFGSOptimizer(t *testing.T) {
+	// a quadratic whose unconstrained minimum is outside the upper bound of its second input.
+	center := []float64{0.5, 3, -1}
+	objective := func(x, gradient []float64) float64 {
+		var cost float64
+		for i := range x {
+			d := x[i] - center[i]
+			cost += d * d
+			if len(gradient) != 0 {
+				gradient[i] = 2 * d
+			}
+		}
+		return cost
+	}
+	lower := []float64{-2, -2, -2}
+	upper := []float64{2, 2, 2}
+
+	t.Run("within bounds", func(t *testing.T) {
+		o := newLBFGSOptimizer(context.Background(), lower, upper, objective, false)
+		x, cost, err := o.optimize([]float64{-1.5, 0, 1.5})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, x[0], test.ShouldAlmostEqual, 0.5, 1e-3)
+		test.That(t, x[1], test.ShouldAlmostEqual, 2, 1e-3)
+		test.That(t, x[2], test.ShouldAlmostEqual, -1, 1e-3)
+		test.That(t, cost, test.ShouldAlmostEqual, 1, 1e-3)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		o := newLBFGSOptimizer(ctx, lower, upper, objective, false)
+		x, _, err := o.optimize([]float64{-1.5, 0, 1.5})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, x, test.ShouldResemble, []float64{-1.5, 0, 1.5})
+	})
+}
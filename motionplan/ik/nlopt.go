@@ -1,5 +1,3 @@
-//go:build !windows && !no_cgo
-
 package ik
 
 import (
@@ -9,18 +7,18 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/go-nlopt/nlopt"
 	"github.com/pkg/errors"
-	"go.uber.org/multierr"
 
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/referenceframe"
 )
 
-var errBadBounds = errors.New("cannot set upper or lower bounds for nlopt, slice is empty. Are you trying to move a static frame?")
-
-// NloptAlg is what algorith to use - nlopt.LD_SLSQP is the original one we used
-var NloptAlg = nlopt.LD_SLSQP
+var (
+	errBadBounds = errors.New("cannot set upper or lower bounds for nlopt, slice is empty. Are you trying to move a static frame?")
+	// errOptimizerFailure is returned by a seedOptimizer for a generic failure, which happens sometimes
+	// on nonlinear randomized problems and is not fatal to solving.
+	errOptimizerFailure = errors.New("nlopt: FAILURE")
+)
 
 const (
 	nloptStepsPerIter = 4001
@@ -28,7 +26,8 @@ const (
 	defaultJump       = 1e-8
 )
 
-// NloptIK can solve IK problems with nlopt.
+// NloptIK can solve IK problems with nlopt, or with a pure-Go optimizer on builds without it, which
+// are those for Windows and those without cgo.
 type NloptIK struct {
 	maxIterations int
 	logger        logging.Logger
@@ -80,10 +79,20 @@ type nloptSeedState struct {
 
 	meta string
 
-	opt    *nlopt.NLopt
+	opt    seedOptimizer
 	logger logging.Logger
 }
 
+// objectiveFunc returns the cost of x and, if gradient is not empty, fills it with the gradient at x.
+type objectiveFunc func(x, gradient []float64) float64
+
+// seedOptimizer minimizes the cost function of a seed within its bounds.
+type seedOptimizer interface {
+	// optimize returns the best configuration found starting from x and its cost.
+	optimize(x []float64) ([]float64, float64, error)
+	destroy()
+}
+
 func (ik *NloptIK) newSeedState(ctx context.Context, seedNumber int, minFunc CostFunc,
 	s []float64, limits []referenceframe.Limit, iterations *int,
 ) (*nloptSeedState, error) {
@@ -112,39 +121,17 @@ func (ik *NloptIK) newSeedState(ctx context.Context, seedNumber int, minFunc Cos
 	for i := range ss.jump {
 		ss.jump[i] = defaultJump
 	}
-	ss.opt, err = nlopt.NewNLopt(NloptAlg, uint(len(ss.lowerBound)))
-	if err != nil {
-		return nil, errors.Wrap(err, "nlopt creation error")
-	}
-
-	err = multierr.Combine(
-		ss.opt.SetFtolAbs(defaultGoalThreshold),
-		ss.opt.SetLowerBounds(ss.lowerBound),
-		ss.opt.SetStopVal(defaultGoalThreshold),
-		ss.opt.SetUpperBounds(ss.upperBound),
-		ss.opt.SetXtolAbs1(defaultGoalThreshold),
-		ss.opt.SetMinObjective(ss.getMinFunc(ctx, minFunc, iterations)),
-		ss.opt.SetMaxEval(nloptStepsPerIter),
-	)
+	ss.opt, err = newSeedOptimizer(ctx, ss, ss.getMinFunc(ctx, minFunc, iterations), ik.useRelTol)
 	if err != nil {
 		return nil, err
 	}
-	if ik.useRelTol {
-		err = multierr.Combine(
-			ss.opt.SetFtolRel(defaultGoalThreshold),
-			ss.opt.SetXtolRel(defaultGoalThreshold),
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
 
 	return ss, nil
 }
 
-func (nss *nloptSeedState) getMinFunc(ctx context.Context, minFunc CostFunc, iteration *int) nlopt.Func {
+func (nss *nloptSeedState) getMinFunc(ctx context.Context, minFunc CostFunc, iteration *int) objectiveFunc {
 	// checkVals is our set of inputs that we evaluate for distance
-	// Gradient is, under the hood, a unsafe C structure that we are meant to mutate in place.
+	// With nlopt, gradient is, under the hood, a unsafe C structure that we are meant to mutate in place.
 	return func(checkVals, gradient []float64) float64 {
 		*iteration++
 		dist := minFunc(ctx, checkVals)
@@ -153,21 +140,15 @@ func (nss *nloptSeedState) getMinFunc(ctx context.Context, minFunc CostFunc, ite
 			// if statement is faster.
 			for i := range gradient {
 				jumpVal := nss.jump[i]
-				flip := false
-				checkVals[i] += jumpVal
-				ub := nss.upperBound[i]
-				for checkVals[i] >= ub {
-					flip = true
-					checkVals[i] -= 10 * jumpVal
+				if checkVals[i]+jumpVal >= nss.upperBound[i] {
+					// step down from the upper bound instead so that the input stays within its limits.
+					jumpVal = -jumpVal
 				}
+				val := checkVals[i]
+				checkVals[i] += jumpVal
 				dist2 := minFunc(ctx, checkVals)
 				gradient[i] = (dist2 - dist) / jumpVal
-				if flip {
-					checkVals[i] += jumpVal
-					gradient[i] *= -1
-				} else {
-					checkVals[i] -= jumpVal
-				}
+				checkVals[i] = val
 			}
 		}
 		if nss.logger.GetLevel() <= logging.DEBUG {
@@ -201,7 +182,7 @@ func (ik *NloptIK) Solve(ctx context.Context,
 	defer func() {
 		for _, ss := range seedStates {
 			if ss.opt != nil {
-				ss.opt.Destroy()
+				ss.opt.destroy()
 			}
 		}
 	}()
@@ -237,7 +218,7 @@ func (ik *NloptIK) Solve(ctx context.Context,
 			totalAttempts.Add(1)
 		}
 
-		solutionRaw, result, nloptErr := ss.opt.Optimize(ss.seed)
+		solutionRaw, result, nloptErr := ss.opt.optimize(ss.seed)
 		if ik.logger.GetLevel() <= logging.DEBUG {
 			ik.logger.Debugf("seed (%d) %v\n\t result: %0.2f  err: %v res: %v",
 				seedNumberRanged, logging.FloatArrayFormat{"", ss.seed},
@@ -251,7 +232,7 @@ func (ik *NloptIK) Solve(ctx context.Context,
 			// Above was previous comment.
 			// I (Eliot) think this is caused by a bug in how we compute the gradient
 			// When the absolute value of the gradient is too high, it blows up
-			if nloptErr.Error() != "nlopt: FAILURE" {
+			if !errors.Is(nloptErr, errOptimizerFailure) {
 				return solutionsFound, nil, nloptErr
 			}
 		} else if solutionRaw == nil {
@@ -0,0 +1,59 @@
+//go:build !windows && !no_cgo
+
+package ik
+
+import (
+	"context"
+
+	"github.com/go-nlopt/nlopt"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// NloptAlg is what algorith to use - nlopt.LD_SLSQP is the original one we used
+var NloptAlg = nlopt.LD_SLSQP
+
+// nloptOptimizer optimizes seeds with nlopt.
+type nloptOptimizer struct {
+	opt *nlopt.NLopt
+}
+
+func newSeedOptimizer(_ context.Context, ss *nloptSeedState, objective objectiveFunc, useRelTol bool) (seedOptimizer, error) {
+	opt, err := nlopt.NewNLopt(NloptAlg, uint(len(ss.lowerBound)))
+	if err != nil {
+		return nil, errors.Wrap(err, "nlopt creation error")
+	}
+
+	err = multierr.Combine(
+		opt.SetFtolAbs(defaultGoalThreshold),
+		opt.SetLowerBounds(ss.lowerBound),
+		opt.SetStopVal(defaultGoalThreshold),
+		opt.SetUpperBounds(ss.upperBound),
+		opt.SetXtolAbs1(defaultGoalThreshold),
+		opt.SetMinObjective(nlopt.Func(objective)),
+		opt.SetMaxEval(nloptStepsPerIter),
+	)
+	if err == nil && useRelTol {
+		err = multierr.Combine(
+			opt.SetFtolRel(defaultGoalThreshold),
+			opt.SetXtolRel(defaultGoalThreshold),
+		)
+	}
+	if err != nil {
+		opt.Destroy()
+		return nil, err
+	}
+	return &nloptOptimizer{opt: opt}, nil
+}
+
+func (o *nloptOptimizer) optimize(x []float64) ([]float64, float64, error) {
+	solution, result, err := o.opt.Optimize(x)
+	if err != nil && err.Error() == errOptimizerFailure.Error() {
+		err = errOptimizerFailure
+	}
+	return solution, result, err
+}
+
+func (o *nloptOptimizer) destroy() {
+	o.opt.Destroy()
+}
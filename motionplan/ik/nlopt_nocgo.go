@@ -0,0 +1,10 @@
+//go:build windows || no_cgo
+
+package ik
+
+import "context"
+
+// newSeedOptimizer returns the pure-Go optimizer, since nlopt needs cgo and is not built for Windows.
+func newSeedOptimizer(ctx context.Context, ss *nloptSeedState, objective objectiveFunc, useRelTol bool) (seedOptimizer, error) {
+	return newLBFGSOptimizer(ctx, ss.lowerBound, ss.upperBound, objective, useRelTol), nil
+}
@@ -0,0 +1,71 @@
+package motionplan
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// PlanFormatVersion is the version of the format MarshalPlan and PlanToProto write. It is increased
+// whenever a change to the format would make older readers misread it, and plans of a newer version
+// than the reader's are rejected rather than executed.
+const PlanFormatVersion = 1
+
+type serializedPlan struct {
+	Version    int        `json:"version"`
+	Path       Path       `json:"path"`
+	Trajectory Trajectory `json:"trajectory"`
+}
+
+// MarshalPlan serializes a plan to versioned JSON, so that it may be computed in one process and
+// executed in another. UnmarshalPlan reads it back.
+func MarshalPlan(plan Plan) ([]byte, error) {
+	if plan == nil {
+		return nil, fmt.Errorf("cannot serialize nil plan")
+	}
+	return json.Marshal(serializedPlan{Version: PlanFormatVersion, Path: plan.Path(), Trajectory: plan.Trajectory()})
+}
+
+// UnmarshalPlan parses a plan serialized by MarshalPlan. The plan should be checked against the
+// current frame system and world before it is executed, as they may have changed since it was made.
+func UnmarshalPlan(data []byte) (*SimplePlan, error) {
+	var sp serializedPlan
+	if err := json.Unmarshal(data, &sp); err != nil {
+		return nil, err
+	}
+	if sp.Version < 1 || sp.Version > PlanFormatVersion {
+		return nil, fmt.Errorf("unsupported plan format version %d, must be between 1 and %d", sp.Version, PlanFormatVersion)
+	}
+	if len(sp.Path) > 0 && len(sp.Path) != len(sp.Trajectory) {
+		return nil, fmt.Errorf("plan has %d path steps but %d trajectory steps", len(sp.Path), len(sp.Trajectory))
+	}
+	return NewSimplePlan(sp.Path, sp.Trajectory), nil
+}
+
+// PlanToProto converts a plan to the protobuf form of the JSON MarshalPlan writes, for transfer in
+// the extra or DoCommand fields of API calls.
+func PlanToProto(plan Plan) (*structpb.Struct, error) {
+	data, err := MarshalPlan(plan)
+	if err != nil {
+		return nil, err
+	}
+	pb := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, pb); err != nil {
+		return nil, err
+	}
+	return pb, nil
+}
+
+// PlanFromProto parses a plan converted to protobuf by PlanToProto.
+func PlanFromProto(pb *structpb.Struct) (*SimplePlan, error) {
+	if pb == nil {
+		return nil, fmt.Errorf("cannot parse nil plan")
+	}
+	data, err := protojson.Marshal(pb)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalPlan(data)
+}
@@ -0,0 +1,67 @@
+package motionplan
+
+import (
+	"fmt"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestPlanSerialization(t *testing.T) {
+	model, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("test")
+	test.That(t, fs.AddFrame(model, fs.World()), test.ShouldBeNil)
+	plan, err := NewSimplePlanFromTrajectory([]*referenceframe.LinearInputs{
+		referenceframe.FrameSystemInputs{model.Name(): {0, 0, 0, 0, 0, 0}}.ToLinearInputs(),
+		referenceframe.FrameSystemInputs{model.Name(): {0.1, 0.2, -0.3, 0.4, 0.5, 0.6}}.ToLinearInputs(),
+	}, fs)
+	test.That(t, err, test.ShouldBeNil)
+
+	checkEqual := func(t *testing.T, got Plan) {
+		t.Helper()
+		test.That(t, got.Trajectory(), test.ShouldResemble, plan.Trajectory())
+		test.That(t, len(got.Path()), test.ShouldEqual, len(plan.Path()))
+		for i, step := range plan.Path() {
+			gotPose := got.Path()[i][model.Name()]
+			test.That(t, gotPose.Parent(), test.ShouldEqual, step[model.Name()].Parent())
+			test.That(t, spatial.PoseAlmostEqual(gotPose.Pose(), step[model.Name()].Pose()), test.ShouldBeTrue)
+		}
+	}
+
+	t.Run("json", func(t *testing.T) {
+		data, err := MarshalPlan(plan)
+		test.That(t, err, test.ShouldBeNil)
+		got, err := UnmarshalPlan(data)
+		test.That(t, err, test.ShouldBeNil)
+		checkEqual(t, got)
+	})
+
+	t.Run("proto", func(t *testing.T) {
+		pb, err := PlanToProto(plan)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pb.Fields["version"].GetNumberValue(), test.ShouldEqual, PlanFormatVersion)
+		got, err := PlanFromProto(pb)
+		test.That(t, err, test.ShouldBeNil)
+		checkEqual(t, got)
+	})
+
+	t.Run("rejects unsupported versions", func(t *testing.T) {
+		for _, version := range []int{0, PlanFormatVersion + 1} {
+			_, err := UnmarshalPlan([]byte(fmt.Sprintf(`{"version": %d, "trajectory": []}`, version)))
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, "unsupported plan format version")
+		}
+	})
+
+	t.Run("rejects a path which does not match the trajectory", func(t *testing.T) {
+		data, err := MarshalPlan(NewSimplePlan(plan.Path(), plan.Trajectory()[:1]))
+		test.That(t, err, test.ShouldBeNil)
+		_, err = UnmarshalPlan(data)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,370 @@
+package motionplan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/golang/geo/r3"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	defaultReachabilityVoxelMM = 50.
+	defaultReachabilitySamples = 100000
+	// reachabilityDirections is the number of bins the approach directions a voxel is reached from are
+	// sorted into, spread evenly over the sphere.
+	reachabilityDirections = 32
+	// manipulabilityStep is the change in each input the Jacobian is estimated over.
+	manipulabilityStep = 1e-4
+)
+
+// reachabilityDirectionBins are the centers of the approach direction bins.
+var reachabilityDirectionBins = func() []r3.Vector {
+	// a Fibonacci sphere
+	bins := make([]r3.Vector, reachabilityDirections)
+	golden := math.Pi * (3 - math.Sqrt(5))
+	for i := range bins {
+		z := 1 - 2*(float64(i)+0.5)/reachabilityDirections
+		r := math.Sqrt(1 - z*z)
+		bins[i] = r3.Vector{X: r * math.Cos(golden*float64(i)), Y: r * math.Sin(golden*float64(i)), Z: z}
+	}
+	return bins
+}()
+
+// ReachabilityOptions configure the generation of a ReachabilityMap.
+type ReachabilityOptions struct {
+	// VoxelSizeMM is the edge length of the voxels the workspace is divided into, which defaults to 50mm.
+	VoxelSizeMM float64 `json:"voxel_size_mm"`
+	// Samples is the number of random configurations of the frame sampled, which defaults to 100000.
+	// Sparse sampling leaves voxels at the edge of the workspace, and approach directions which can only
+	// be reached in few configurations, unmapped.
+	Samples int `json:"samples"`
+	// Seed seeds the random sampling, so that maps generated with the same options are the same.
+	Seed int64 `json:"seed"`
+}
+
+func (opts *ReachabilityOptions) withDefaults() ReachabilityOptions {
+	filled := ReachabilityOptions{}
+	if opts != nil {
+		filled = *opts
+	}
+	if filled.VoxelSizeMM == 0 {
+		filled.VoxelSizeMM = defaultReachabilityVoxelMM
+	}
+	if filled.Samples == 0 {
+		filled.Samples = defaultReachabilitySamples
+	}
+	return filled
+}
+
+// ReachabilityVoxel holds what is known of how a frame reaches one voxel of its workspace.
+type ReachabilityVoxel struct {
+	Index [3]int `json:"index"`
+	// Directions holds the best manipulability the voxel was reached with from each approach direction it
+	// was reached from, by approach direction bin.
+	Directions map[int]float64 `json:"directions"`
+	// Seed is the configuration the voxel was reached with the best manipulability in, a good seed for IK.
+	Seed []referenceframe.Input `json:"seed"`
+
+	manipulability float64
+}
+
+// ReachabilityScore describes how well a frame can reach a pose.
+type ReachabilityScore struct {
+	// Reached is whether the voxel of the pose was reached from the approach direction of the pose.
+	Reached bool
+	// Reachability is the fraction of approach directions the voxel of the pose was reached from, or 0
+	// if it was not reached.
+	Reachability float64
+	// Manipulability is the best manipulability the voxel was reached with from the approach direction of
+	// the pose, or 0 if it was not reached from that direction. It is the product of the singular values
+	// of the frame's Jacobian, with positions in meters and orientations in radians, and falls to 0 at
+	// singularities.
+	Manipulability float64
+	// Seed is the configuration the voxel was reached with the best manipulability in.
+	Seed []referenceframe.Input
+}
+
+// ReachabilityMap is a voxelized map of the workspace of a frame, recording which voxels the frame can
+// reach, from which approach directions, and how far from singularities. It is expressed in the frame's
+// parent frame, so that it is independent of where the frame is mounted, and may be used to quickly
+// reject goals the frame cannot reach and to choose where to mount it.
+type ReachabilityMap struct {
+	Frame     string               `json:"frame"`
+	Reference string               `json:"reference"`
+	FrameHash int                  `json:"frame_hash"`
+	Options   ReachabilityOptions  `json:"options"`
+	Voxels    []*ReachabilityVoxel `json:"voxels"`
+
+	voxels map[[3]int]*ReachabilityVoxel
+}
+
+// NewReachabilityMap generates the reachability map of the frame named frameName in fs, by sampling
+// random configurations of the frame. Only the inputs of the frame itself are sampled.
+func NewReachabilityMap(
+	ctx context.Context,
+	fs *referenceframe.FrameSystem,
+	frameName string,
+	opts *ReachabilityOptions,
+) (*ReachabilityMap, error) {
+	frame := fs.Frame(frameName)
+	if frame == nil {
+		return nil, referenceframe.NewFrameMissingError(frameName)
+	}
+	if len(frame.DoF()) == 0 {
+		return nil, fmt.Errorf("cannot map the reachability of frame %s, which has no degrees of freedom", frameName)
+	}
+	parent, err := fs.Parent(frame)
+	if err != nil {
+		return nil, err
+	}
+	filled := opts.withDefaults()
+	if filled.VoxelSizeMM < 0 || filled.Samples < 0 {
+		return nil, errors.New("reachability voxel size and samples must be positive")
+	}
+
+	rm := &ReachabilityMap{
+		Frame:     frameName,
+		Reference: parent.Name(),
+		FrameHash: frame.Hash(),
+		Options:   filled,
+		voxels:    map[[3]int]*ReachabilityVoxel{},
+	}
+	rseed := rand.New(rand.NewSource(filled.Seed)) //nolint:gosec
+	for i := 0; i < filled.Samples; i++ {
+		if i%1000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		inputs := referenceframe.RandomFrameInputs(frame, rseed)
+		pose, err := frame.Transform(inputs)
+		if err != nil {
+			return nil, err
+		}
+		manipulability, err := frameManipulability(frame, inputs, pose)
+		if err != nil {
+			return nil, err
+		}
+
+		key := rm.voxelIndex(pose.Point())
+		voxel, ok := rm.voxels[key]
+		if !ok {
+			voxel = &ReachabilityVoxel{Index: key, Directions: map[int]float64{}, manipulability: -1}
+			rm.voxels[key] = voxel
+			rm.Voxels = append(rm.Voxels, voxel)
+		}
+		bin := approachDirectionBin(pose.Orientation())
+		if best, ok := voxel.Directions[bin]; !ok || manipulability > best {
+			voxel.Directions[bin] = manipulability
+		}
+		if manipulability > voxel.manipulability {
+			voxel.manipulability = manipulability
+			voxel.Seed = inputs
+		}
+	}
+	sort.Slice(rm.Voxels, func(i, j int) bool {
+		a, b := rm.Voxels[i].Index, rm.Voxels[j].Index
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	return rm, nil
+}
+
+// LoadOrNewReachabilityMap reads the reachability map cached at path if it was generated for the frame
+// as it is in fs and with opts, and otherwise generates it and caches it at path.
+func LoadOrNewReachabilityMap(
+	ctx context.Context,
+	fs *referenceframe.FrameSystem,
+	frameName string,
+	opts *ReachabilityOptions,
+	path string,
+) (*ReachabilityMap, error) {
+	frame := fs.Frame(frameName)
+	if frame == nil {
+		return nil, referenceframe.NewFrameMissingError(frameName)
+	}
+	//nolint:gosec
+	if data, err := os.ReadFile(path); err == nil {
+		cached := &ReachabilityMap{}
+		if err := json.Unmarshal(data, cached); err == nil &&
+			cached.Frame == frameName && cached.FrameHash == frame.Hash() && cached.Options == opts.withDefaults() {
+			return cached, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	rm, err := NewReachabilityMap(ctx, fs, frameName, opts)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(rm)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, err
+	}
+	return rm, nil
+}
+
+// UnmarshalJSON parses a reachability map and indexes its voxels.
+func (rm *ReachabilityMap) UnmarshalJSON(data []byte) error {
+	type plainMap ReachabilityMap
+	if err := json.Unmarshal(data, (*plainMap)(rm)); err != nil {
+		return err
+	}
+	if rm.Options.VoxelSizeMM <= 0 {
+		return fmt.Errorf("invalid reachability voxel size %v", rm.Options.VoxelSizeMM)
+	}
+	rm.voxels = make(map[[3]int]*ReachabilityVoxel, len(rm.Voxels))
+	for _, voxel := range rm.Voxels {
+		voxel.manipulability = -1
+		for _, manipulability := range voxel.Directions {
+			voxel.manipulability = math.Max(voxel.manipulability, manipulability)
+		}
+		rm.voxels[voxel.Index] = voxel
+	}
+	return nil
+}
+
+// Score returns how well the frame can reach pose, which is in the map's reference frame.
+func (rm *ReachabilityMap) Score(pose spatialmath.Pose) ReachabilityScore {
+	voxel, ok := rm.voxels[rm.voxelIndex(pose.Point())]
+	if !ok {
+		return ReachabilityScore{}
+	}
+	manipulability, reached := voxel.Directions[approachDirectionBin(pose.Orientation())]
+	return ReachabilityScore{
+		Reached:        reached,
+		Reachability:   float64(len(voxel.Directions)) / reachabilityDirections,
+		Manipulability: manipulability,
+		Seed:           voxel.Seed,
+	}
+}
+
+// MayReach returns false if the frame cannot reach the goal, given the inputs of fs, with no regard to
+// orientation. As the map is sampled, the voxels next to those the frame was seen to reach are assumed to
+// be reachable too, so MayReach may return true for goals just out of reach but should not reject any
+// reachable ones.
+func (rm *ReachabilityMap) MayReach(
+	fs *referenceframe.FrameSystem,
+	inputs referenceframe.FrameSystemInputs,
+	goal *referenceframe.PoseInFrame,
+) (bool, error) {
+	tf, err := fs.Transform(inputs.ToLinearInputs(), goal, rm.Reference)
+	if err != nil {
+		return false, err
+	}
+	key := rm.voxelIndex(tf.(*referenceframe.PoseInFrame).Pose().Point())
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			for dz := -1; dz <= 1; dz++ {
+				if _, ok := rm.voxels[[3]int{key[0] + dx, key[1] + dy, key[2] + dz}]; ok {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// BestBasePlacement returns the index of the candidate pose of the map's reference frame from which the
+// frame can reach the most targets, and the fraction of them it can reach from there. Ties are broken by
+// the reachability of the targets. Candidates and targets must be in the same frame.
+func (rm *ReachabilityMap) BestBasePlacement(targets, candidates []spatialmath.Pose) (int, float64, error) {
+	if len(candidates) == 0 {
+		return -1, 0, errors.New("no candidate base placements")
+	}
+	if len(targets) == 0 {
+		return -1, 0, errors.New("no targets to place the base for")
+	}
+	best, bestReached, bestReachability := -1, -1, -1.
+	for i, candidate := range candidates {
+		inverse := spatialmath.PoseInverse(candidate)
+		reached, reachability := 0, 0.
+		for _, target := range targets {
+			score := rm.Score(spatialmath.Compose(inverse, target))
+			if score.Reached {
+				reached++
+			}
+			reachability += score.Reachability
+		}
+		if reached > bestReached || (reached == bestReached && reachability > bestReachability) {
+			best, bestReached, bestReachability = i, reached, reachability
+		}
+	}
+	return best, float64(bestReached) / float64(len(targets)), nil
+}
+
+func (rm *ReachabilityMap) voxelIndex(pt r3.Vector) [3]int {
+	size := rm.Options.VoxelSizeMM
+	return [3]int{int(math.Floor(pt.X / size)), int(math.Floor(pt.Y / size)), int(math.Floor(pt.Z / size))}
+}
+
+// approachDirectionBin returns the bin of the direction a frame with orientation o approaches along, which
+// is its z axis.
+func approachDirectionBin(o spatialmath.Orientation) int {
+	ov := o.OrientationVectorRadians()
+	direction := r3.Vector{X: ov.OX, Y: ov.OY, Z: ov.OZ}.Normalize()
+	best, bestDot := 0, math.Inf(-1)
+	for i, bin := range reachabilityDirectionBins {
+		if dot := direction.Dot(bin); dot > bestDot {
+			best, bestDot = i, dot
+		}
+	}
+	return best
+}
+
+// frameManipulability returns the manipulability of frame at inputs, where it is at pose, from a numerical
+// estimate of its Jacobian.
+func frameManipulability(frame referenceframe.Frame, inputs []referenceframe.Input, pose spatialmath.Pose) (float64, error) {
+	jacobian := mat.NewDense(6, len(inputs), nil)
+	stepped := append([]referenceframe.Input{}, inputs...)
+	dof := frame.DoF()
+	for i := range inputs {
+		// inputs at their upper limit are stepped down instead, which only flips the sign of the column
+		step := manipulabilityStep
+		if inputs[i]+step > dof[i].Max {
+			step = -step
+		}
+		stepped[i] = inputs[i] + step
+		steppedPose, err := frame.Transform(stepped)
+		if err != nil {
+			return 0, err
+		}
+		stepped[i] = inputs[i]
+
+		delta := spatialmath.PoseDelta(pose, steppedPose)
+		translation := delta.Point().Mul(1. / (1000 * step))
+		rotation := delta.Orientation().AxisAngles().ToR3().Mul(1. / step)
+		for row, v := range []float64{translation.X, translation.Y, translation.Z, rotation.X, rotation.Y, rotation.Z} {
+			jacobian.Set(row, i, v)
+		}
+	}
+	var svd mat.SVD
+	if !svd.Factorize(jacobian, mat.SVDNone) {
+		return 0, nil
+	}
+	manipulability := 1.
+	for _, v := range svd.Values(nil) {
+		manipulability *= v
+	}
+	return manipulability, nil
+}
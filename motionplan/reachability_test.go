@@ -0,0 +1,104 @@
+package motionplan
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestReachabilityMap(t *testing.T) {
+	ctx := context.Background()
+	model, err := referenceframe.ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/xarm6.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+	mountPose := spatial.NewPoseFromPoint(r3.Vector{X: 5000})
+	mount, err := referenceframe.NewStaticFrame("mount", mountPose)
+	test.That(t, err, test.ShouldBeNil)
+	fs := referenceframe.NewEmptyFrameSystem("test")
+	test.That(t, fs.AddFrame(mount, fs.World()), test.ShouldBeNil)
+	test.That(t, fs.AddFrame(model, mount), test.ShouldBeNil)
+	inputs := referenceframe.NewZeroInputs(fs)
+
+	opts := &ReachabilityOptions{VoxelSizeMM: 100, Samples: 2000}
+	rm, err := NewReachabilityMap(ctx, fs, model.Name(), opts)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rm.Reference, test.ShouldEqual, "mount")
+	test.That(t, len(rm.Voxels), test.ShouldBeGreaterThan, 0)
+
+	// The configurations voxels were reached in are known to reach them.
+	var reached []spatial.Pose
+	for _, voxel := range rm.Voxels[:10] {
+		pose, err := model.Transform(voxel.Seed)
+		test.That(t, err, test.ShouldBeNil)
+		reached = append(reached, pose)
+	}
+	far := spatial.NewPoseFromPoint(r3.Vector{X: 10000})
+
+	t.Run("score", func(t *testing.T) {
+		for _, pose := range reached {
+			score := rm.Score(pose)
+			test.That(t, score.Reached, test.ShouldBeTrue)
+			test.That(t, score.Reachability, test.ShouldBeGreaterThan, 0)
+			test.That(t, score.Manipulability, test.ShouldBeGreaterThan, 0)
+			test.That(t, score.Seed, test.ShouldHaveLength, len(model.DoF()))
+		}
+		test.That(t, rm.Score(far), test.ShouldResemble, ReachabilityScore{})
+	})
+
+	t.Run("reject goals out of reach", func(t *testing.T) {
+		// Goals are transformed into the frame the arm is mounted on.
+		goal := referenceframe.NewPoseInFrame(referenceframe.World, spatial.Compose(mountPose, reached[0]))
+		mayReach, err := rm.MayReach(fs, inputs, goal)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mayReach, test.ShouldBeTrue)
+
+		mayReach, err = rm.MayReach(fs, inputs, referenceframe.NewPoseInFrame("mount", far))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mayReach, test.ShouldBeFalse)
+	})
+
+	t.Run("base placement", func(t *testing.T) {
+		best, fraction, err := rm.BestBasePlacement(reached, []spatial.Pose{far, spatial.NewZeroPose()})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, best, test.ShouldEqual, 1)
+		test.That(t, fraction, test.ShouldEqual, 1)
+
+		_, _, err = rm.BestBasePlacement(reached, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "maps", "xarm6.json")
+		generated, err := LoadOrNewReachabilityMap(ctx, fs, model.Name(), opts, path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, generated.Voxels, test.ShouldResemble, rm.Voxels)
+
+		cached, err := LoadOrNewReachabilityMap(ctx, fs, model.Name(), opts, path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cached.Voxels, test.ShouldResemble, rm.Voxels)
+		test.That(t, cached.Score(reached[0]), test.ShouldResemble, rm.Score(reached[0]))
+
+		// Maps generated with other options are not reused.
+		regenerated, err := LoadOrNewReachabilityMap(ctx, fs, model.Name(), &ReachabilityOptions{VoxelSizeMM: 100, Samples: 500}, path)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, regenerated.Options.Samples, test.ShouldEqual, 500)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := NewReachabilityMap(ctx, fs, "mount", opts)
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = NewReachabilityMap(ctx, fs, "missing", opts)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = NewReachabilityMap(cancelled, fs, model.Name(), opts)
+		test.That(t, err, test.ShouldEqual, context.Canceled)
+	})
+}
@@ -0,0 +1,95 @@
+package referenceframe
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/multierr"
+	"gonum.org/v1/gonum/num/dualquat"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// TransformBatch computes the pose of frame at each of many configurations. inputs holds the
+// configurations back to back, len(frame.DoF()) inputs each, and the pose at the i-th configuration
+// is written to out[i].
+//
+// Keeping a batch in two flat slices keeps it contiguous in memory, and writing poses in place
+// rather than returning a Pose for each avoids an allocation per configuration. Loops that transform
+// thousands of configurations, such as building the smart seed cache, should prefer it to calling
+// Transform on each.
+//
+// TransformBatch stops at the first configuration that fails to transform, such as one out of
+// bounds, and returns its error.
+func TransformBatch(frame Frame, inputs []Input, out []spatialmath.DualQuaternion) error {
+	dof := len(frame.DoF())
+	if err := checkBatchLength(frame, inputs, out); err != nil {
+		return err
+	}
+
+	for i := range out {
+		configuration := inputs[i*dof : (i+1)*dof]
+		var err error
+		switch f := frame.(type) {
+		case *SimpleModel:
+			out[i], err = f.transformDQ(configuration)
+		case *rotationalFrame:
+			if err = f.validInputs(configuration); err == nil {
+				orientation := f.InputToOrientation(configuration[0])
+				out[i] = spatialmath.DualQuaternion{Number: dualquat.Number{Real: orientation.Quaternion()}}
+			}
+		default:
+			var pose spatialmath.Pose
+			if pose, err = frame.Transform(configuration); err == nil {
+				out[i] = *spatialmath.DualQuaternionFromPose(pose)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("configuration %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// TransformBatchParallel is TransformBatch split evenly across up to workers goroutines. It is
+// worth it for batches of hundreds of configurations or more, when the planner isn't already
+// keeping every core busy. Each goroutine stops at its first failing configuration, and the errors
+// of all of them are combined.
+func TransformBatchParallel(frame Frame, inputs []Input, out []spatialmath.DualQuaternion, workers int) error {
+	dof := len(frame.DoF())
+	if err := checkBatchLength(frame, inputs, out); err != nil {
+		return err
+	}
+	workers = max(1, min(workers, len(out)))
+	if workers == 1 {
+		return TransformBatch(frame, inputs, out)
+	}
+
+	chunk := (len(out) + workers - 1) / workers
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*chunk, min((w+1)*chunk, len(out))
+		if start >= end {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[w] = TransformBatch(frame, inputs[start*dof:end*dof], out[start:end])
+			if errs[w] != nil {
+				errs[w] = fmt.Errorf("configurations %d to %d: %w", start, end-1, errs[w])
+			}
+		}()
+	}
+	wg.Wait()
+	return multierr.Combine(errs...)
+}
+
+func checkBatchLength(frame Frame, inputs []Input, out []spatialmath.DualQuaternion) error {
+	if dof := len(frame.DoF()); len(inputs) != dof*len(out) {
+		return fmt.Errorf("%d inputs are not %d configurations of %d DoF for frame %q",
+			len(inputs), len(out), dof, frame.Name())
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package referenceframe
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// randomBatch returns n random in-bounds configurations of frame, back to back.
+func randomBatch(frame Frame, n int) []Input {
+	rseed := rand.New(rand.NewSource(1))
+	inputs := make([]Input, 0, n*len(frame.DoF()))
+	for i := 0; i < n; i++ {
+		inputs = append(inputs, RandomFrameInputs(frame, rseed)...)
+	}
+	return inputs
+}
+
+func TestTransformBatch(t *testing.T) {
+	ur5e, err := ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/ur5e.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+	rotational, err := NewRotationalFrame("rotational", spatial.R4AA{RX: 1}, Limit{-3, 3})
+	test.That(t, err, test.ShouldBeNil)
+	translational, err := NewTranslationalFrame("translational", r3.Vector{Y: 1}, Limit{-100, 100})
+	test.That(t, err, test.ShouldBeNil)
+
+	for _, frame := range []Frame{ur5e, rotational, translational} {
+		t.Run(frame.Name(), func(t *testing.T) {
+			const n = 50
+			dof := len(frame.DoF())
+			inputs := randomBatch(frame, n)
+
+			for _, workers := range []int{1, 4} {
+				out := make([]spatial.DualQuaternion, n)
+				test.That(t, TransformBatchParallel(frame, inputs, out, workers), test.ShouldBeNil)
+				for i := range out {
+					expected, err := frame.Transform(inputs[i*dof : (i+1)*dof])
+					test.That(t, err, test.ShouldBeNil)
+					test.That(t, spatial.PoseAlmostEqual(&out[i], expected), test.ShouldBeTrue)
+				}
+			}
+
+			out := make([]spatial.DualQuaternion, n)
+			test.That(t, TransformBatch(frame, inputs[1:], out), test.ShouldNotBeNil)
+
+			// An out of bounds configuration fails the batch, naming the configuration.
+			inputs[3*dof] = frame.DoF()[0].Max + 1
+			err := TransformBatch(frame, inputs, out)
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, "configuration 3")
+			test.That(t, err.Error(), test.ShouldContainSubstring, OOBErrString)
+			test.That(t, TransformBatchParallel(frame, inputs, out, 4), test.ShouldNotBeNil)
+		})
+	}
+
+	t.Run("does not allocate for models", func(t *testing.T) {
+		inputs := randomBatch(ur5e, 100)
+		out := make([]spatial.DualQuaternion, 100)
+		allocs := testing.AllocsPerRun(10, func() {
+			//nolint:errcheck
+			TransformBatch(ur5e, inputs, out)
+		})
+		test.That(t, allocs, test.ShouldEqual, 0)
+	})
+}
+
+func BenchmarkTransformBatch(b *testing.B) {
+	ur5e, err := ParseModelJSONFile(utils.ResolveFile("components/arm/fake/kinematics/ur5e.json"), "")
+	test.That(b, err, test.ShouldBeNil)
+	const n = 1000
+	inputs := randomBatch(ur5e, n)
+	dof := len(ur5e.DoF())
+
+	b.Run("transform", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < n; j++ {
+				if _, err := ur5e.Transform(inputs[j*dof : (j+1)*dof]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		out := make([]spatial.DualQuaternion, n)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := TransformBatch(ur5e, inputs, out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"math"
 	"math/rand"
 	"reflect"
+	"slices"
 
 	"github.com/golang/geo/r3"
 	"github.com/pkg/errors"
@@ -898,8 +899,8 @@ func framesAlmostEqual(frame1, frame2 Frame, epsilon float64) (bool, error) {
 		}
 	case *SimpleModel:
 		f2 := frame2.(*SimpleModel)
-		frames1 := f1.framesInOrder()
-		frames2 := f2.framesInOrder()
+		frames1 := slices.Collect(f1.framesInOrder())
+		frames2 := slices.Collect(f2.framesInOrder())
 		if len(frames1) != len(frames2) {
 			return false, nil
 		}
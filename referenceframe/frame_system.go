@@ -710,6 +710,18 @@ func (sfs *FrameSystem) composeTransforms(frame Frame, linearInputs *LinearInput
 				return ret, NewIncorrectDoFError(len(frameInputs), len(frame.DoF()))
 			}
 
+			if rf, ok := frame.(*rotationalFrame); ok {
+				// Most moveable frames are revolute joints. Compose their rotation directly rather than
+				// allocating a Pose for it, as collision checking transforms every joint of every state.
+				if err := rf.validInputs(frameInputs); err != nil {
+					return ret, err
+				}
+				orientation := rf.InputToOrientation(frameInputs[0])
+				rot := spatial.DualQuaternion{Number: dualquat.Number{Real: orientation.Quaternion()}}
+				ret = rot.Transformation(ret)
+				frame = sfs.lookupFrame(sfs.parents[frame.Name()])
+				continue
+			}
 			pose, err = frame.Transform(frameInputs)
 			if err != nil {
 				return ret, err
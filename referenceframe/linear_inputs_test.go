@@ -2,8 +2,10 @@ package referenceframe
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
+	"github.com/golang/geo/r3"
 	"go.viam.com/test"
 
 	spatial "go.viam.com/rdk/spatialmath"
@@ -116,3 +118,35 @@ func TestLinearInputsLimits(t *testing.T) {
 		}
 	}
 }
+
+func TestTransformToDQRevoluteChainDoesNotAllocate(t *testing.T) {
+	// Collision checking transforms every joint of every state it checks, so walking a chain of
+	// revolute joints must not allocate.
+	fs := NewEmptyFrameSystem("fs")
+	parent := fs.World()
+	for _, name := range []string{"j0", "j1", "j2"} {
+		link, err := NewStaticFrame(name+"_link", spatial.NewPoseFromPoint(r3.Vector{X: 100}))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fs.AddFrame(link, parent), test.ShouldBeNil)
+		joint, err := NewRotationalFrame(name, spatial.R4AA{RZ: 1}, Limit{-10, 10})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fs.AddFrame(joint, link), test.ShouldBeNil)
+		parent = joint
+	}
+
+	li := NewLinearInputs()
+	li.Put("j0", []Input{0.5})
+	li.Put("j1", []Input{-0.5})
+	li.Put("j2", []Input{1})
+
+	dq, err := fs.TransformToDQ(li, "j2", World)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatial.R3VectorAlmostEqual(dq.Point(), r3.Vector{X: 100 + 100*math.Cos(0.5) + 100, Y: 100 * math.Sin(0.5)}, 1e-9),
+		test.ShouldBeTrue)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		//nolint:errcheck
+		fs.TransformToDQ(li, "j2", World)
+	})
+	test.That(t, allocs, test.ShouldEqual, 0)
+}
@@ -3,6 +3,7 @@ package referenceframe
 import (
 	"encoding/json"
 	"fmt"
+	"iter"
 	"math"
 	"math/rand"
 	"strings"
@@ -331,20 +332,20 @@ func (m *SimpleModel) MoveableFrameNames() []string {
 	return names
 }
 
-// framesInOrder returns the Frame objects in schema order.
-func (m *SimpleModel) framesInOrder() []Frame {
-	if m.internalFS == nil || m.inputSchema == nil {
-		return nil
-	}
-	names := m.inputSchema.FrameNamesInOrder()
-	frames := make([]Frame, 0, len(names))
-	for _, name := range names {
-		f := m.internalFS.Frame(name)
-		if f != nil {
-			frames = append(frames, f)
+// framesInOrder yields the Frame objects in schema order. It is called for every interpolation, so
+// it walks the schema rather than building a slice of names and frames each time.
+func (m *SimpleModel) framesInOrder() iter.Seq[Frame] {
+	return func(yield func(Frame) bool) {
+		if m.internalFS == nil || m.inputSchema == nil {
+			return
+		}
+		for _, meta := range m.inputSchema.metas {
+			f := m.internalFS.Frame(meta.frameName)
+			if f != nil && !yield(f) {
+				return
+			}
 		}
 	}
-	return frames
 }
 
 // toLinearInputs converts flat []Input to a *LinearInputs via the model's schema.
@@ -379,7 +380,7 @@ func (m *SimpleModel) ModelConfig() *ModelConfigJSON {
 func (m *SimpleModel) Hash() int {
 	h := m.hash()
 	h += hashString(m.name)
-	for _, f := range m.framesInOrder() {
+	for f := range m.framesInOrder() {
 		h += f.Hash()
 	}
 	h += hashString(m.primaryOutputFrame)
@@ -458,7 +459,13 @@ func (m *SimpleModel) Transform(inputs []Input) (spatialmath.Pose, error) {
 	if len(m.DoF()) != len(inputs) {
 		return nil, NewIncorrectDoFError(len(inputs), len(m.DoF()))
 	}
+	dq, err := m.transformDQ(inputs)
+	return &dq, err
+}
 
+// transformDQ is Transform returning the pose as a value, for callers such as TransformBatch that
+// write many poses in place. inputs must be the model's DoF long.
+func (m *SimpleModel) transformDQ(inputs []Input) (spatialmath.DualQuaternion, error) {
 	composedTransformation := spatialmath.DualQuaternion{
 		Number: dualquat.Number{
 			Real: quat.Number{Real: 1},
@@ -489,7 +496,7 @@ func (m *SimpleModel) Transform(inputs []Input) (spatialmath.Pose, error) {
 			} else {
 				frameInputs = inputs[offset : offset+dof]
 				if err := frame.validInputs(frameInputs); err != nil {
-					return &composedTransformation, fmt.Errorf("Frame: %v.%v (joint %d): %w",
+					return composedTransformation, fmt.Errorf("Frame: %v.%v (joint %d): %w",
 						m.Name(), frame.Name(), offset, err)
 				}
 			}
@@ -514,7 +521,7 @@ func (m *SimpleModel) Transform(inputs []Input) (spatialmath.Pose, error) {
 				pose, err = chainFrame.Transform(inputs[offset : offset+dof])
 			}
 			if err != nil {
-				return &composedTransformation, fmt.Errorf("joint %d: %w", offset, err)
+				return composedTransformation, fmt.Errorf("joint %d: %w", offset, err)
 			}
 			composedTransformation = spatialmath.DualQuaternion{
 				Number: composedTransformation.Transformation(pose.(*spatialmath.DualQuaternion).Number),
@@ -522,14 +529,14 @@ func (m *SimpleModel) Transform(inputs []Input) (spatialmath.Pose, error) {
 		}
 	}
 
-	return &composedTransformation, nil
+	return composedTransformation, nil
 }
 
 // Interpolate interpolates the given amount between the two sets of inputs.
 func (m *SimpleModel) Interpolate(from, to []Input, by float64) ([]Input, error) {
 	interp := make([]Input, 0, len(from))
 	posIdx := 0
-	for _, transform := range m.framesInOrder() {
+	for transform := range m.framesInOrder() {
 		dof := len(transform.DoF()) + posIdx
 		fromSubset := from[posIdx:dof]
 		toSubset := to[posIdx:dof]
@@ -548,7 +555,7 @@ func (m *SimpleModel) Interpolate(from, to []Input, by float64) ([]Input, error)
 func (m *SimpleModel) InputFromProtobuf(jp *pb.JointPositions) []Input {
 	inputs := make([]Input, 0, len(jp.Values))
 	posIdx := 0
-	for _, transform := range m.framesInOrder() {
+	for transform := range m.framesInOrder() {
 		dof := len(transform.DoF()) + posIdx
 		jPos := jp.Values[posIdx:dof]
 		posIdx = dof
@@ -561,7 +568,7 @@ func (m *SimpleModel) InputFromProtobuf(jp *pb.JointPositions) []Input {
 func (m *SimpleModel) ProtobufFromInput(input []Input) *pb.JointPositions {
 	jPos := &pb.JointPositions{}
 	posIdx := 0
-	for _, transform := range m.framesInOrder() {
+	for transform := range m.framesInOrder() {
 		dof := len(transform.DoF()) + posIdx
 		jPos.Values = append(jPos.Values, transform.ProtobufFromInput(input[posIdx:dof]).Values...)
 		posIdx = dof
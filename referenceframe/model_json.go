@@ -28,7 +28,9 @@ type ModelConfigJSON struct {
 	Joints       []JointConfig   `json:"joints,omitempty"`
 	DHParams     []DHParamConfig `json:"dhParams,omitempty"`
 	OutputFrames []string        `json:"output_frames,omitempty"`
-	OriginalFile *ModelFile      `json:"original_file,omitempty"`
+	// SelfCollision changes which pairs of links are checked for collision with each other.
+	SelfCollision *SelfCollisionConfig `json:"self_collision,omitempty"`
+	OriginalFile  *ModelFile           `json:"original_file,omitempty"`
 }
 
 // ModelFile is a struct that stores the raw bytes of the file used to create the model as well as its extension,
@@ -132,6 +134,12 @@ func (cfg *ModelConfigJSON) ParseConfig(modelName string) (Model, error) {
 		return nil, err
 	}
 
+	if cfg.SelfCollision != nil {
+		if err := cfg.SelfCollision.validate(cfg.links()); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(cfg.OutputFrames) > 1 {
 		return nil, fmt.Errorf("multiple output frames are not yet supported, got %v", cfg.OutputFrames)
 	}
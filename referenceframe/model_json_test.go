@@ -180,3 +180,53 @@ func TestMimicJSONRoundTrip(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, spatial.R3VectorAlmostEqual(pose.Point(), r3.Vector{X: 0, Y: 0, Z: 30}, defaultFloatPrecision), test.ShouldBeTrue)
 }
+
+func TestSelfCollisionConfig(t *testing.T) {
+	model, err := ParseModelJSONFile(utils.ResolveFile("referenceframe/testfiles/self_collision_arm.json"), "arm")
+	test.That(t, err, test.ShouldBeNil)
+	smodel, ok := model.(*SimpleModel)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	// The pairs are labelled as the model's geometries are.
+	geoms, err := model.Geometries(make([]Input, len(model.DoF())))
+	test.That(t, err, test.ShouldBeNil)
+	for _, label := range []string{"arm:base", "arm:upper", "arm:fore"} {
+		test.That(t, geoms.GeometryByName(label), test.ShouldNotBeNil)
+	}
+
+	// upper is adjacent to base but enabled, so only fore's adjacency to upper is disabled.
+	pairs := smodel.SelfCollision()
+	test.That(t, pairs.Disabled, test.ShouldHaveLength, 2)
+	test.That(t, pairs.Disabled, test.ShouldContain, [2]string{"arm:base", "arm:fore"})
+	test.That(t, pairs.Disabled, test.ShouldContain, [2]string{"arm:upper", "arm:fore"})
+	test.That(t, pairs.Enabled, test.ShouldResemble, [][2]string{{"arm:base", "arm:upper"}})
+	test.That(t, pairs.Mounted, test.ShouldResemble, []string{"arm:base"})
+
+	data, err := smodel.MarshalJSON()
+	test.That(t, err, test.ShouldBeNil)
+	restored := new(SimpleModel)
+	test.That(t, restored.UnmarshalJSON(data), test.ShouldBeNil)
+	test.That(t, restored.SelfCollision(), test.ShouldResemble, pairs)
+
+	for _, tc := range []struct {
+		name   string
+		config SelfCollisionConfig
+		err    string
+	}{
+		{"unknown link", SelfCollisionConfig{Disable: [][2]string{{"base", "shoulder"}}}, "not a link"},
+		{"same link", SelfCollisionConfig{Enable: [][2]string{{"fore", "fore"}}}, "two different links"},
+		{
+			"enabled and disabled",
+			SelfCollisionConfig{Enable: [][2]string{{"base", "fore"}}, Disable: [][2]string{{"fore", "base"}}},
+			"both enabled and disabled",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := *smodel.ModelConfig()
+			cfg.SelfCollision = &tc.config
+			_, err := cfg.ParseConfig("arm")
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.err)
+		})
+	}
+}
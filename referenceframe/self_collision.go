@@ -0,0 +1,154 @@
+package referenceframe
+
+import (
+	"fmt"
+)
+
+// SelfCollisionConfig specifies which pairs of a model's links are checked for collision with each other
+// when planning. By default every pair is checked, except those already in collision where a motion starts.
+type SelfCollisionConfig struct {
+	// IgnoreAdjacentLinks skips pairs of links joined by a single joint, whose geometries often overlap about
+	// the joint, and pairs of the model's root links and the frame it is mounted on.
+	IgnoreAdjacentLinks bool `json:"ignore_adjacent_links,omitempty"`
+	// Disable lists pairs of link IDs which are never checked.
+	Disable [][2]string `json:"disable,omitempty"`
+	// Enable lists pairs of link IDs which are always checked, even if they are adjacent or in collision
+	// where a motion starts.
+	Enable [][2]string `json:"enable,omitempty"`
+}
+
+func (cfg *SelfCollisionConfig) validate(links map[string]string) error {
+	enabled := map[[2]string]bool{}
+	for _, pair := range cfg.Enable {
+		enabled[canonicalLinkPair(pair)] = true
+	}
+	for _, pairs := range [][][2]string{cfg.Disable, cfg.Enable} {
+		for _, pair := range pairs {
+			for _, link := range pair {
+				if _, ok := links[link]; !ok {
+					return fmt.Errorf("self_collision pair %v names %q, which is not a link of the model", pair, link)
+				}
+			}
+			if pair[0] == pair[1] {
+				return fmt.Errorf("self_collision pair %v must name two different links", pair)
+			}
+		}
+	}
+	for _, pair := range cfg.Disable {
+		if enabled[canonicalLinkPair(pair)] {
+			return fmt.Errorf("self_collision pair %v cannot be both enabled and disabled", pair)
+		}
+	}
+	return nil
+}
+
+func canonicalLinkPair(pair [2]string) [2]string {
+	if pair[1] < pair[0] {
+		return [2]string{pair[1], pair[0]}
+	}
+	return pair
+}
+
+// links returns the parents of the links of the model, by link ID. The parent of a link is the nearest
+// link it is attached to, through any number of joints, or "" for the model's root links.
+func (cfg *ModelConfigJSON) links() map[string]string {
+	parents := map[string]string{}
+	links := map[string]bool{}
+	switch cfg.KinParamType {
+	case "SVA", "":
+		for _, link := range cfg.Links {
+			parents[link.ID] = link.Parent
+			links[link.ID] = true
+		}
+		for _, joint := range cfg.Joints {
+			parents[joint.ID] = joint.Parent
+		}
+	case "DH":
+		for _, dh := range cfg.DHParams {
+			parents[dh.ID] = dh.ID + "_j"
+			parents[dh.ID+"_j"] = dh.Parent
+			links[dh.ID] = true
+		}
+	}
+
+	linkParents := map[string]string{}
+	for link := range links {
+		parent := parents[link]
+		// the walk is bounded in case of cycles, which parsing the model rejects
+		for i := 0; i < len(parents) && parent != "" && !links[parent]; i++ {
+			parent = parents[parent]
+		}
+		if !links[parent] {
+			parent = ""
+		}
+		linkParents[link] = parent
+	}
+	return linkParents
+}
+
+// SelfCollisionPairs are pairs of a model's geometries, labelled as Geometries labels them, whose checking
+// for collision its SelfCollisionConfig changes.
+type SelfCollisionPairs struct {
+	Disabled [][2]string
+	Enabled  [][2]string
+	// Mounted are the geometries of the model's root links if adjacent links are ignored, which are not
+	// checked against the frame the model is mounted on.
+	Mounted []string
+}
+
+// SelfCollision returns the pairs of the model's geometries whose checking for collision its kinematics
+// config changes, which are none if it has no self-collision config.
+func (m *SimpleModel) SelfCollision() SelfCollisionPairs {
+	pairs := SelfCollisionPairs{}
+	if m.modelConfig == nil || m.modelConfig.SelfCollision == nil {
+		return pairs
+	}
+	cfg := m.modelConfig.SelfCollision
+
+	labels := func(link string) []string {
+		f := m.internalFS.Frame(link)
+		if f == nil {
+			return nil
+		}
+		gif, err := f.Geometries(nil)
+		if err != nil || gif == nil {
+			return nil
+		}
+		names := []string{}
+		for _, g := range gif.Geometries() {
+			names = append(names, m.name+":"+g.Label())
+		}
+		return names
+	}
+	expand := func(linkPairs [][2]string) [][2]string {
+		expanded := [][2]string{}
+		for _, pair := range linkPairs {
+			for _, label1 := range labels(pair[0]) {
+				for _, label2 := range labels(pair[1]) {
+					expanded = append(expanded, [2]string{label1, label2})
+				}
+			}
+		}
+		return expanded
+	}
+
+	disabled := append([][2]string{}, cfg.Disable...)
+	if cfg.IgnoreAdjacentLinks {
+		enabled := map[[2]string]bool{}
+		for _, pair := range cfg.Enable {
+			enabled[canonicalLinkPair(pair)] = true
+		}
+		for link, parent := range m.modelConfig.links() {
+			if parent == "" {
+				pairs.Mounted = append(pairs.Mounted, labels(link)...)
+				continue
+			}
+			if pair := [2]string{parent, link}; !enabled[canonicalLinkPair(pair)] {
+				disabled = append(disabled, pair)
+			}
+		}
+	}
+	pairs.Disabled = expand(disabled)
+	pairs.Enabled = expand(cfg.Enable)
+	return pairs
+}
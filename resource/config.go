@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/go-viper/mapstructure/v2"
@@ -16,13 +17,18 @@ import (
 
 // A Config describes the configuration of a resource.
 type Config struct {
-	Name             string
-	API              API
-	Model            Model
-	Frame            *referenceframe.LinkConfig
-	DependsOn        []string
-	LogConfiguration *LogConfig
-	Attributes       utils.AttributeMap
+	Name      string
+	API       API
+	Model     Model
+	Frame     *referenceframe.LinkConfig
+	DependsOn []string
+	// OptionalDependsOn lists dependencies that the resource can be built without. They are
+	// passed in when available and the resource is reconfigured as they appear or disappear. They
+	// are only read from local configs as they have no cloud representation yet.
+	OptionalDependsOn []string
+	LogConfiguration  *LogConfig
+	HealthCheck       *HealthCheckConfig
+	Attributes        utils.AttributeMap
 
 	AssociatedResourceConfigs []AssociatedResourceConfig
 	AssociatedAttributes      map[Name]AssociatedConfig
@@ -49,7 +55,9 @@ type typeSpecificConfigData struct {
 	Model                     Model                      `json:"model"`
 	Frame                     *referenceframe.LinkConfig `json:"frame,omitempty"`
 	DependsOn                 []string                   `json:"depends_on,omitempty"`
+	OptionalDependsOn         []string                   `json:"optional_depends_on,omitempty"`
 	LogConfiguration          *LogConfig                 `json:"log_configuration,omitempty"`
+	HealthCheck               *HealthCheckConfig         `json:"health_check,omitempty"`
 	AssociatedResourceConfigs []AssociatedResourceConfig `json:"service_configs,omitempty"`
 	Attributes                utils.AttributeMap         `json:"attributes,omitempty"`
 }
@@ -61,7 +69,9 @@ type configData struct {
 	Model                     Model                      `json:"model"`
 	Frame                     *referenceframe.LinkConfig `json:"frame,omitempty"`
 	DependsOn                 []string                   `json:"depends_on,omitempty"`
+	OptionalDependsOn         []string                   `json:"optional_depends_on,omitempty"`
 	LogConfiguration          *LogConfig                 `json:"log_configuration,omitempty"`
+	HealthCheck               *HealthCheckConfig         `json:"health_check,omitempty"`
 	AssociatedResourceConfigs []AssociatedResourceConfig `json:"service_configs,omitempty"`
 	Attributes                utils.AttributeMap         `json:"attributes,omitempty"`
 }
@@ -82,7 +92,9 @@ func (conf *Config) UnmarshalJSON(data []byte) error {
 		conf.Model = confData.Model
 		conf.Frame = confData.Frame
 		conf.DependsOn = confData.DependsOn
+		conf.OptionalDependsOn = confData.OptionalDependsOn
 		conf.LogConfiguration = confData.LogConfiguration
+		conf.HealthCheck = confData.HealthCheck
 		conf.AssociatedResourceConfigs = confData.AssociatedResourceConfigs
 		conf.Attributes = confData.Attributes
 		return nil
@@ -98,7 +110,9 @@ func (conf *Config) UnmarshalJSON(data []byte) error {
 	conf.Model = typeSpecificConf.Model
 	conf.Frame = typeSpecificConf.Frame
 	conf.DependsOn = typeSpecificConf.DependsOn
+	conf.OptionalDependsOn = typeSpecificConf.OptionalDependsOn
 	conf.LogConfiguration = typeSpecificConf.LogConfiguration
+	conf.HealthCheck = typeSpecificConf.HealthCheck
 	conf.AssociatedResourceConfigs = typeSpecificConf.AssociatedResourceConfigs
 	conf.Attributes = typeSpecificConf.Attributes
 	return nil
@@ -112,7 +126,9 @@ func (conf Config) MarshalJSON() ([]byte, error) {
 		Model:                     conf.Model,
 		Frame:                     conf.Frame,
 		DependsOn:                 conf.DependsOn,
+		OptionalDependsOn:         conf.OptionalDependsOn,
 		LogConfiguration:          conf.LogConfiguration,
+		HealthCheck:               conf.HealthCheck,
 		AssociatedResourceConfigs: conf.AssociatedResourceConfigs,
 		Attributes:                conf.Attributes,
 	})
@@ -241,6 +257,29 @@ func (conf *Config) Dependencies() []string {
 	return result
 }
 
+// OptionalDependencies returns the deduplicated union of user-defined and implicit optional
+// dependencies, leaving out any that are also required.
+func (conf *Config) OptionalDependencies() []string {
+	seen := make(map[string]struct{})
+	for _, dep := range conf.Dependencies() {
+		seen[dep] = struct{}{}
+	}
+	result := make([]string, 0, len(conf.OptionalDependsOn)+len(conf.ImplicitOptionalDependsOn))
+	appendUniq := func(dep string) {
+		if _, ok := seen[dep]; !ok {
+			seen[dep] = struct{}{}
+			result = append(result, dep)
+		}
+	}
+	for _, dep := range conf.OptionalDependsOn {
+		appendUniq(dep)
+	}
+	for _, dep := range conf.ImplicitOptionalDependsOn {
+		appendUniq(dep)
+	}
+	return result
+}
+
 // String returns a verbose representation of the config.
 func (conf *Config) String() string {
 	return fmt.Sprintf("%#v", conf)
@@ -323,16 +362,43 @@ func (conf *Config) validate(path, defaultAPIType string) ([]string, []string, e
 	if err := conf.API.Validate(); err != nil {
 		return nil, nil, err
 	}
+	if err := conf.ValidateOptionalDependsOn(path, nil); err != nil {
+		return nil, nil, err
+	}
+	if conf.HealthCheck != nil {
+		if err := conf.HealthCheck.Validate(path); err != nil {
+			return nil, nil, err
+		}
+	}
 	if conf.ConvertedAttributes != nil {
 		var err error
 		requiredDeps, optionalDeps, err = conf.ConvertedAttributes.Validate(path)
 		if err != nil {
 			return nil, nil, err
 		}
+		if err := conf.ValidateOptionalDependsOn(path, requiredDeps); err != nil {
+			return nil, nil, err
+		}
 	}
 	return requiredDeps, optionalDeps, nil
 }
 
+// ValidateOptionalDependsOn returns an error if a dependency in optional_depends_on is also required,
+// either in depends_on or by the given implicit dependencies the model requires.
+func (conf *Config) ValidateOptionalDependsOn(path string, implicitDeps []string) error {
+	for _, dep := range conf.OptionalDependsOn {
+		if slices.Contains(conf.DependsOn, dep) {
+			return NewConfigValidationError(path,
+				errors.Errorf("dependency %q cannot be both in depends_on and optional_depends_on", dep))
+		}
+		if slices.Contains(implicitDeps, dep) {
+			return NewConfigValidationError(path,
+				errors.Errorf("dependency %q in optional_depends_on is required by model %s", dep, conf.Model))
+		}
+	}
+	return nil
+}
+
 // A ConfigValidator validates a configuration and also returns both required and optional
 // dependencies that were implicitly discovered.
 type ConfigValidator interface {
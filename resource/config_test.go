@@ -663,3 +663,37 @@ func TestEqual(t *testing.T) {
 		})
 	})
 }
+
+func TestOptionalDependencies(t *testing.T) {
+	conf := resource.Config{
+		Name:                      "foo",
+		API:                       arm.API,
+		Model:                     fakeModel,
+		DependsOn:                 []string{"a"},
+		ImplicitDependsOn:         []string{"b"},
+		OptionalDependsOn:         []string{"b", "c", "d"},
+		ImplicitOptionalDependsOn: []string{"d", "e"},
+	}
+	_, _, err := conf.Validate("path", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldBeNil)
+	// required dependencies win over optional ones.
+	test.That(t, conf.OptionalDependencies(), test.ShouldResemble, []string{"c", "d", "e"})
+
+	conf = resource.Config{
+		Name:              "foo",
+		API:               arm.API,
+		Model:             fakeModel,
+		DependsOn:         []string{"a"},
+		OptionalDependsOn: []string{"a"},
+	}
+	_, _, err = conf.Validate("path", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "optional_depends_on")
+
+	// as are dependencies the model implicitly requires.
+	conf.DependsOn = nil
+	test.That(t, conf.ValidateOptionalDependsOn("path", nil), test.ShouldBeNil)
+	err = conf.ValidateOptionalDependsOn("path", []string{"a"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "required by model")
+}
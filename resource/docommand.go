@@ -0,0 +1,118 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// A DoCommandValidator is a DoCommand request or response that checks itself. Requests are
+// checked before being sent and after being received, and responses after being received.
+type DoCommandValidator interface {
+	Validate() error
+}
+
+// DoCommandAs sends req to the resource with DoCommand and returns the response as a TResp. Both
+// are converted to and from the maps DoCommand carries through their json tags, so a module and its
+// clients can share typed requests and responses instead of building and picking apart maps, e.g.
+//
+//	type moveRequest struct {
+//		Command string  `json:"command"`
+//		Speed   float64 `json:"speed"`
+//	}
+//	type moveResponse struct {
+//		Moved bool `json:"moved"`
+//	}
+//
+//	resp, err := resource.DoCommandAs[moveRequest, moveResponse](ctx, res, moveRequest{Command: "move", Speed: 10})
+func DoCommandAs[TReq, TResp any](ctx context.Context, res Resource, req TReq) (TResp, error) {
+	var resp TResp
+	cmd, err := EncodeDoCommand(req)
+	if err != nil {
+		return resp, errors.Wrap(err, "invalid DoCommand request")
+	}
+	respMap, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return resp, err
+	}
+	resp, err = DecodeDoCommand[TResp](respMap)
+	if err != nil {
+		return resp, errors.Wrap(err, "invalid DoCommand response")
+	}
+	return resp, nil
+}
+
+// DoCommandFunc adapts a function taking a TReq and returning a TResp into a DoCommand method,
+// decoding each command into a TReq and encoding the TResp returned, e.g.
+//
+//	func (b *myBase) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+//		return resource.DoCommandFunc(b.move)(ctx, cmd)
+//	}
+//
+// Commands that cannot be decoded or that fail validation are rejected without calling handle.
+func DoCommandFunc[TReq, TResp any](
+	handle func(context.Context, TReq) (TResp, error),
+) func(context.Context, map[string]interface{}) (map[string]interface{}, error) {
+	return func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		req, err := DecodeDoCommand[TReq](cmd)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid DoCommand request")
+		}
+		resp, err := handle(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return EncodeDoCommand(resp)
+	}
+}
+
+// EncodeDoCommand converts v into a DoCommand command or response through its json tags and
+// validates it if it is a DoCommandValidator. v must encode to a JSON object. Numbers become
+// float64s, as they do when sent over the network.
+func EncodeDoCommand[T any](v T) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil || m == nil {
+		return nil, errors.Errorf("expected %T to encode to a JSON object", v)
+	}
+	if err := validateDoCommand(&v); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DecodeDoCommand converts a DoCommand command or response into a T through its json tags, then
+// validates it if it is a DoCommandValidator. Fields of m that T does not have are ignored.
+func DecodeDoCommand[T any](m map[string]interface{}) (T, error) {
+	var out T
+	data, err := json.Marshal(m)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, err
+	}
+	if err := validateDoCommand(&out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// validateDoCommand validates the value v points to if it, or v, is a DoCommandValidator.
+func validateDoCommand[T any](v *T) error {
+	if rv := reflect.ValueOf(*v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+	if validator, ok := any(*v).(DoCommandValidator); ok {
+		return validator.Validate()
+	}
+	if validator, ok := any(v).(DoCommandValidator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
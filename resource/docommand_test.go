@@ -0,0 +1,114 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+type moveRequest struct {
+	Command string   `json:"command"`
+	Speed   float64  `json:"speed"`
+	Axes    []string `json:"axes,omitempty"`
+}
+
+func (req moveRequest) Validate() error {
+	if req.Speed < 0 {
+		return errors.New("speed cannot be negative")
+	}
+	return nil
+}
+
+type moveResponse struct {
+	Moved    bool   `json:"moved"`
+	Distance int    `json:"distance"`
+	Note     string `json:"note,omitempty"`
+}
+
+func TestDoCommandAs(t *testing.T) {
+	ctx := context.Background()
+
+	var moves int
+	move := func(ctx context.Context, req moveRequest) (moveResponse, error) {
+		if req.Command != "move" {
+			return moveResponse{}, errors.New("unknown command")
+		}
+		moves++
+		return moveResponse{Moved: true, Distance: int(req.Speed) * len(req.Axes)}, nil
+	}
+	res := inject.NewGenericComponent("thing")
+	var cmds []map[string]interface{}
+	res.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		cmds = append(cmds, cmd)
+		return resource.DoCommandFunc(move)(ctx, cmd)
+	}
+
+	resp, err := resource.DoCommandAs[moveRequest, moveResponse](ctx, res, moveRequest{
+		Command: "move",
+		Speed:   10,
+		Axes:    []string{"x", "y"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, moveResponse{Moved: true, Distance: 20})
+	// the command is built through json tags, with the types DoCommand carries over the network.
+	test.That(t, cmds, test.ShouldResemble, []map[string]interface{}{
+		{"command": "move", "speed": 10.0, "axes": []interface{}{"x", "y"}},
+	})
+
+	t.Run("errors of the handler", func(t *testing.T) {
+		_, err := resource.DoCommandAs[moveRequest, moveResponse](ctx, res, moveRequest{Command: "jump"})
+		test.That(t, err, test.ShouldBeError, errors.New("unknown command"))
+	})
+
+	t.Run("invalid requests are not sent", func(t *testing.T) {
+		sent := len(cmds)
+		_, err := resource.DoCommandAs[moveRequest, moveResponse](ctx, res, moveRequest{Command: "move", Speed: -1})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "speed cannot be negative")
+		test.That(t, cmds, test.ShouldHaveLength, sent)
+	})
+
+	t.Run("invalid requests are not handled", func(t *testing.T) {
+		handled := moves
+		_, err := resource.DoCommandFunc(move)(ctx, map[string]interface{}{"command": "move", "speed": -1.0})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "speed cannot be negative")
+
+		_, err = resource.DoCommandFunc(move)(ctx, map[string]interface{}{"command": "move", "speed": "fast"})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "invalid DoCommand request")
+		test.That(t, moves, test.ShouldEqual, handled)
+	})
+
+	t.Run("unexpected responses", func(t *testing.T) {
+		other := inject.NewGenericComponent("other")
+		other.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{"moved": "yes"}, nil
+		}
+		_, err := resource.DoCommandAs[moveRequest, moveResponse](ctx, other, moveRequest{Command: "move"})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "invalid DoCommand response")
+	})
+}
+
+func TestEncodeDoCommand(t *testing.T) {
+	cmd, err := resource.EncodeDoCommand(&moveRequest{Command: "stop"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cmd, test.ShouldResemble, map[string]interface{}{"command": "stop", "speed": 0.0})
+
+	_, err = resource.EncodeDoCommand([]string{"stop"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "JSON object")
+
+	_, err = resource.EncodeDoCommand[*moveRequest](nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	req, err := resource.DecodeDoCommand[*moveRequest](map[string]interface{}{"command": "stop", "unknown": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, req, test.ShouldResemble, &moveRequest{Command: "stop"})
+}
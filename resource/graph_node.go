@@ -99,6 +99,10 @@ type GraphNode struct {
 	// "not yet recorded"; an empty non-nil map means "no weak/optional
 	// dependencies were resolvable at the time."
 	lastWeakOptionalDepsClocks map[Name]int64
+
+	// onTransition is called with the node's status every time it transitions to a new
+	// lifecycle state. It is set by the graph the node belongs to if that graph is observed.
+	onTransition func(NodeStatus)
 }
 
 var (
@@ -148,6 +152,29 @@ func (w *GraphNode) UpdatedAt() int64 {
 	return w.updatedAt
 }
 
+func (w *GraphNode) setTransitionObserver(onTransition func(NodeStatus)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onTransition = onTransition
+}
+
+// notifyTransition reports the current status to the transition observer, if any. This method
+// must be called while holding a lock on `mu`.
+func (w *GraphNode) notifyTransition() {
+	if w.onTransition == nil {
+		return
+	}
+	st := NodeStatus{
+		State:       w.state,
+		LastUpdated: w.transitionedAt,
+		Revision:    w.revision,
+	}
+	if w.state == NodeStateUnhealthy {
+		st.Error = w.lastErr
+	}
+	w.onTransition(st)
+}
+
 func (w *GraphNode) setGraphLogicalClock(clock *atomic.Int64) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
@@ -621,6 +648,10 @@ func (w *GraphNode) canTransitionTo(state NodeState) bool {
 func (w *GraphNode) transitionTo(state NodeState) {
 	if w.state == state && w.logger != nil {
 		w.logger.Debugw("resource state self-transition", "state", w.state.String())
+		if state == NodeStateUnhealthy {
+			// the error may have changed.
+			w.notifyTransition()
+		}
 		return
 	}
 
@@ -638,6 +669,7 @@ func (w *GraphNode) transitionTo(state NodeState) {
 
 	w.state = state
 	w.transitionedAt = time.Now()
+	w.notifyTransition()
 }
 
 // Status returns the current [NodeStatus].
@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultHealthCheckInterval     = 10 * time.Second
+	defaultHealthCheckTimeout      = 5 * time.Second
+	defaultHealthFailureThreshold  = 3
+	defaultMaxHealthRestarts       = 5
+	defaultHealthRestartBackoff    = time.Second
+	defaultHealthMaxRestartBackoff = time.Minute
+	defaultHealthRestartReset      = 10 * time.Minute
+)
+
+// A HealthCheckConfig describes how a resource's liveness is checked and how it is rebuilt when it
+// stops responding. Zero values select defaults. Health checks are only read from local configs as
+// they have no cloud representation yet.
+type HealthCheckConfig struct {
+	IntervalMS int `json:"interval_ms,omitempty"`
+	// TimeoutMS bounds each check so that a wedged resource counts as a failure rather than blocking.
+	TimeoutMS int `json:"timeout_ms,omitempty"`
+	// FailureThreshold is the number of consecutive failed checks that trigger a restart.
+	FailureThreshold int `json:"failure_threshold,omitempty"`
+	// MaxRestarts is the number of restarts attempted before giving up. A negative value never gives up.
+	MaxRestarts int `json:"max_restarts,omitempty"`
+	// RestartBackoffMS is the delay before a second restart, which doubles for every further restart
+	// up to MaxRestartBackoffMS.
+	RestartBackoffMS    int `json:"restart_backoff_ms,omitempty"`
+	MaxRestartBackoffMS int `json:"max_restart_backoff_ms,omitempty"`
+	// RestartResetMS is how long a resource must stay healthy after a restart for its restart count to reset.
+	RestartResetMS int `json:"restart_reset_ms,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (hc *HealthCheckConfig) Validate(path string) error {
+	if hc.IntervalMS < 0 || hc.TimeoutMS < 0 || hc.FailureThreshold < 0 ||
+		hc.RestartBackoffMS < 0 || hc.MaxRestartBackoffMS < 0 || hc.RestartResetMS < 0 {
+		return NewConfigValidationError(path, errors.New("health_check values other than max_restarts cannot be negative"))
+	}
+	if hc.MaxRestartBackoffMS != 0 && hc.MaxRestartBackoffMS < hc.RestartBackoffMS {
+		return NewConfigValidationError(path, errors.New("health_check max_restart_backoff_ms must be at least restart_backoff_ms"))
+	}
+	return nil
+}
+
+func msOrDefault(ms int, def time.Duration) time.Duration {
+	if ms == 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// Interval returns how often the resource is checked.
+func (hc *HealthCheckConfig) Interval() time.Duration {
+	return msOrDefault(hc.IntervalMS, defaultHealthCheckInterval)
+}
+
+// Timeout returns how long a single check may take.
+func (hc *HealthCheckConfig) Timeout() time.Duration {
+	return msOrDefault(hc.TimeoutMS, defaultHealthCheckTimeout)
+}
+
+// Threshold returns the number of consecutive failed checks that trigger a restart.
+func (hc *HealthCheckConfig) Threshold() int {
+	if hc.FailureThreshold == 0 {
+		return defaultHealthFailureThreshold
+	}
+	return hc.FailureThreshold
+}
+
+// RestartLimit returns the number of restarts attempted before giving up, or a negative number if unlimited.
+func (hc *HealthCheckConfig) RestartLimit() int {
+	if hc.MaxRestarts == 0 {
+		return defaultMaxHealthRestarts
+	}
+	return hc.MaxRestarts
+}
+
+// RestartBackoff returns how long to wait after the given number of restarts before restarting again.
+func (hc *HealthCheckConfig) RestartBackoff(restarts int) time.Duration {
+	if restarts == 0 {
+		return 0
+	}
+	backoff := msOrDefault(hc.RestartBackoffMS, defaultHealthRestartBackoff)
+	maxBackoff := msOrDefault(hc.MaxRestartBackoffMS, defaultHealthMaxRestartBackoff)
+	for i := 1; i < restarts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// RestartReset returns how long a resource must stay healthy for its restart count to reset.
+func (hc *HealthCheckConfig) RestartReset() time.Duration {
+	return msOrDefault(hc.RestartResetMS, defaultHealthRestartReset)
+}
+
+// A HealthChecker is a resource with a cheaper or more precise liveness check than its Status method.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// CheckHealth performs a lightweight liveness check of the resource, using CheckHealth if the resource
+// is a HealthChecker and Status otherwise. For modular resources this is a round trip to the module,
+// so it also detects a module that has stopped responding.
+func CheckHealth(ctx context.Context, res Resource) error {
+	if hc, ok := res.(HealthChecker); ok {
+		return hc.CheckHealth(ctx)
+	}
+	_, err := res.Status(ctx)
+	return err
+}
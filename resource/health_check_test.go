@@ -0,0 +1,73 @@
+package resource_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestHealthCheckConfig(t *testing.T) {
+	var conf resource.Config
+	test.That(t, json.Unmarshal([]byte(`{
+		"name": "foo",
+		"type": "arm",
+		"model": "fake",
+		"health_check": {"interval_ms": 100, "max_restarts": -1, "restart_backoff_ms": 10, "max_restart_backoff_ms": 35}
+	}`), &conf), test.ShouldBeNil)
+	test.That(t, conf.HealthCheck, test.ShouldNotBeNil)
+	_, _, err := conf.Validate("path", resource.APITypeComponentName)
+	test.That(t, err, test.ShouldBeNil)
+
+	hc := conf.HealthCheck
+	test.That(t, hc.Interval(), test.ShouldEqual, 100*time.Millisecond)
+	test.That(t, hc.Timeout(), test.ShouldEqual, 5*time.Second)
+	test.That(t, hc.Threshold(), test.ShouldEqual, 3)
+	test.That(t, hc.RestartLimit(), test.ShouldBeLessThan, 0)
+	test.That(t, hc.RestartBackoff(0), test.ShouldEqual, 0)
+	test.That(t, hc.RestartBackoff(1), test.ShouldEqual, 10*time.Millisecond)
+	test.That(t, hc.RestartBackoff(2), test.ShouldEqual, 20*time.Millisecond)
+	test.That(t, hc.RestartBackoff(3), test.ShouldEqual, 35*time.Millisecond)
+	test.That(t, hc.RestartBackoff(100), test.ShouldEqual, 35*time.Millisecond)
+
+	// round trips through JSON
+	data, err := json.Marshal(conf)
+	test.That(t, err, test.ShouldBeNil)
+	var other resource.Config
+	test.That(t, json.Unmarshal(data, &other), test.ShouldBeNil)
+	test.That(t, other.HealthCheck, test.ShouldResemble, hc)
+
+	test.That(t, (&resource.HealthCheckConfig{}).RestartLimit(), test.ShouldEqual, 5)
+	test.That(t, (&resource.HealthCheckConfig{TimeoutMS: -1}).Validate("path"), test.ShouldNotBeNil)
+	test.That(t, (&resource.HealthCheckConfig{RestartBackoffMS: 10, MaxRestartBackoffMS: 5}).Validate("path"), test.ShouldNotBeNil)
+}
+
+func TestCheckHealth(t *testing.T) {
+	statusErr := errors.New("wedged")
+	sensor := inject.NewSensor("foo")
+	sensor.StatusFunc = func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, statusErr
+	}
+	test.That(t, resource.CheckHealth(context.Background(), sensor), test.ShouldBeError, statusErr)
+
+	sensor.StatusFunc = func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{}, nil
+	}
+	test.That(t, resource.CheckHealth(context.Background(), sensor), test.ShouldBeNil)
+	test.That(t, resource.CheckHealth(context.Background(), healthChecker{sensor, statusErr}), test.ShouldBeError, statusErr)
+}
+
+type healthChecker struct {
+	resource.Resource
+	err error
+}
+
+func (hc healthChecker) CheckHealth(ctx context.Context) error {
+	return hc.err
+}
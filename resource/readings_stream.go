@@ -0,0 +1,100 @@
+package resource
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+// ReadingsStreamOptions describe which readings of a sensor are streamed.
+type ReadingsStreamOptions struct {
+	// RateHz is the most readings sent per second. It must be positive.
+	RateHz float64
+	// ChangesOnly skips readings, and errors, equal to the last ones sent.
+	ChangesOnly bool
+	// Extra is passed to each call to Readings.
+	Extra map[string]interface{}
+}
+
+// Validate ensures the options can be streamed.
+func (opts ReadingsStreamOptions) Validate() error {
+	if opts.RateHz <= 0 {
+		return errors.Errorf("readings rate must be positive but got %v", opts.RateHz)
+	}
+	return nil
+}
+
+// Interval returns the time between readings sent at RateHz.
+func (opts ReadingsStreamOptions) Interval() time.Duration {
+	return time.Duration(float64(time.Second) / opts.RateHz)
+}
+
+// TimedReadings are the readings of a sensor, or why it could not take them, at some time.
+type TimedReadings struct {
+	Time     time.Time
+	Readings map[string]interface{}
+	Err      error
+}
+
+// A ReadingsStreamer is a Sensor that pushes its readings as it takes them rather than having
+// them polled, such as one that reads a device producing samples at its own rate.
+type ReadingsStreamer interface {
+	Sensor
+	// StreamReadings sends readings as described by opts until ctx is done, when the channel is
+	// closed.
+	StreamReadings(ctx context.Context, opts ReadingsStreamOptions) (<-chan TimedReadings, error)
+}
+
+// StreamReadings sends the readings of s as described by opts until ctx is done, when the
+// channel is closed. Readings come directly from s if it is a ReadingsStreamer, and are otherwise
+// polled every interval of opts, such as for sensors of remote machines. Errors are sent rather
+// than ending the stream, as the sensor may only briefly be unable to take readings.
+func StreamReadings(ctx context.Context, s Sensor, opts ReadingsStreamOptions) (<-chan TimedReadings, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if streamer, ok := s.(ReadingsStreamer); ok {
+		return streamer.StreamReadings(ctx, opts)
+	}
+
+	readings := make(chan TimedReadings)
+	utils.PanicCapturingGo(func() {
+		defer close(readings)
+		var last *TimedReadings
+		ticker := time.NewTicker(opts.Interval())
+		defer ticker.Stop()
+		for {
+			r, err := s.Readings(ctx, opts.Extra)
+			if ctx.Err() != nil {
+				return
+			}
+			next := TimedReadings{Time: time.Now(), Readings: r, Err: err}
+			if !opts.ChangesOnly || last == nil || !sameReadings(*last, next) {
+				select {
+				case readings <- next:
+					last = &next
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	return readings, nil
+}
+
+// sameReadings returns whether a and b hold the same readings or the same error, ignoring when
+// they were taken.
+func sameReadings(a, b TimedReadings) bool {
+	if a.Err != nil || b.Err != nil {
+		return a.Err != nil && b.Err != nil && a.Err.Error() == b.Err.Error()
+	}
+	return reflect.DeepEqual(a.Readings, b.Readings)
+}
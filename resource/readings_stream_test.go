@@ -0,0 +1,95 @@
+package resource_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+type pushingSensor struct {
+	*inject.Sensor
+	readings chan resource.TimedReadings
+}
+
+func (s *pushingSensor) StreamReadings(
+	ctx context.Context,
+	opts resource.ReadingsStreamOptions,
+) (<-chan resource.TimedReadings, error) {
+	return s.readings, nil
+}
+
+func TestStreamReadings(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := inject.NewSensor("s")
+	var calls atomic.Int64
+	s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		test.That(t, extra, test.ShouldResemble, map[string]interface{}{"foo": "bar"})
+		switch n := calls.Add(1); {
+		case n <= 2:
+			return map[string]interface{}{"a": 1}, nil
+		case n <= 4:
+			return nil, errors.New("unplugged")
+		default:
+			return map[string]interface{}{"a": 2}, nil
+		}
+	}
+
+	_, err := resource.StreamReadings(ctx, s, resource.ReadingsStreamOptions{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// every reading is sent when all are requested.
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	readings, err := resource.StreamReadings(streamCtx, s, resource.ReadingsStreamOptions{
+		RateHz: 1000,
+		Extra:  map[string]interface{}{"foo": "bar"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	for _, expected := range []int{1, 1} {
+		r := <-readings
+		test.That(t, r.Err, test.ShouldBeNil)
+		test.That(t, r.Readings["a"], test.ShouldEqual, expected)
+	}
+	r := <-readings
+	test.That(t, r.Err, test.ShouldBeError, errors.New("unplugged"))
+	streamCancel()
+	for range readings {
+	}
+
+	// only changes are sent when requested.
+	calls.Store(0)
+	streamCtx, streamCancel = context.WithCancel(ctx)
+	readings, err = resource.StreamReadings(streamCtx, s, resource.ReadingsStreamOptions{
+		RateHz:      1000,
+		ChangesOnly: true,
+		Extra:       map[string]interface{}{"foo": "bar"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	r = <-readings
+	test.That(t, r.Readings["a"], test.ShouldEqual, 1)
+	r = <-readings
+	test.That(t, r.Err, test.ShouldBeError, errors.New("unplugged"))
+	r = <-readings
+	test.That(t, r.Readings["a"], test.ShouldEqual, 2)
+	test.That(t, calls.Load(), test.ShouldEqual, 5)
+	streamCancel()
+	for range readings {
+	}
+
+	// sensors that push readings stream them directly.
+	pushed := make(chan resource.TimedReadings, 1)
+	pushed <- resource.TimedReadings{Time: time.Now(), Readings: map[string]interface{}{"a": 3}}
+	pusher := &pushingSensor{Sensor: s, readings: pushed}
+	readings, err = resource.StreamReadings(ctx, pusher, resource.ReadingsStreamOptions{RateHz: 1})
+	test.That(t, err, test.ShouldBeNil)
+	r = <-readings
+	test.That(t, r.Readings["a"], test.ShouldEqual, 3)
+}
@@ -153,6 +153,25 @@ func (d Dependencies) Lookup(name Name) (Resource, error) {
 	return res, nil
 }
 
+// LookupByShortName searches for a dependency by its short name alone, for configs that name
+// resources without saying what API they have.
+func (d Dependencies) LookupByShortName(name string) (Resource, error) {
+	var found Resource
+	for depName, res := range d {
+		if depName.ShortName() != name {
+			continue
+		}
+		if found != nil {
+			return nil, errors.Errorf("more than one dependency is named %q", name)
+		}
+		found = res
+	}
+	if found == nil {
+		return nil, errors.Errorf("dependency %q not found", name)
+	}
+	return found, nil
+}
+
 // An RPCAPI provides RPC information about a particular API.
 type RPCAPI struct {
 	API          API
@@ -210,6 +210,18 @@ type Graph struct {
 	logicalClock *atomic.Int64
 	logger       logging.Logger
 	ftdc         *ftdc.FTDC
+	// observer is notified of lifecycle changes of the graph's nodes. Clones of the graph are
+	// not observed.
+	observer GraphObserver
+}
+
+// A GraphObserver is notified of lifecycle changes of the nodes in a Graph. Its methods are
+// called while the graph or node is locked, so they must not block or call back into the graph.
+type GraphObserver interface {
+	// NodeTransitioned is called when a node enters a new lifecycle state.
+	NodeTransitioned(status NodeStatus)
+	// NodeRemoved is called when a node is removed from the graph.
+	NodeRemoved(name Name)
 }
 
 // NewGraph creates a new resource graph.
@@ -234,6 +246,39 @@ func NewGraphWithFTDC(logger logging.Logger, ftdc *ftdc.FTDC) *Graph {
 	return ret
 }
 
+// SetObserver sets the observer notified of lifecycle changes of all current and future
+// nodes of the graph.
+func (g *Graph) SetObserver(observer GraphObserver) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.observer = observer
+	for name, node := range g.nodes.All() {
+		node.setTransitionObserver(g.transitionObserver(name))
+	}
+}
+
+func (g *Graph) transitionObserver(name Name) func(NodeStatus) {
+	if g.observer == nil {
+		return nil
+	}
+	observer := g.observer
+	return func(status NodeStatus) {
+		status.Name = name
+		observer.NodeTransitioned(status)
+	}
+}
+
+// announceNode reports the current state of a node that was added to or replaced in the graph,
+// since it did not reach that state through a transition the observer saw.
+func (g *Graph) announceNode(node *GraphNode) {
+	if g.observer == nil {
+		return
+	}
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.notifyTransition()
+}
+
 // CurrLogicalClockValue returns current the logical clock value.
 func (g *Graph) CurrLogicalClockValue() int64 {
 	return g.logicalClock.Load()
@@ -541,9 +586,14 @@ func (g *Graph) addNode(name Name, node *GraphNode) error {
 			return err
 		}
 		g.nodes.UpdateSimpleName(name, prevPrefix, val)
+		g.announceNode(val)
 		return nil
 	}
 	node.setGraphLogicalClock(g.logicalClock)
+	if g.observer != nil {
+		node.setTransitionObserver(g.transitionObserver(name))
+		g.announceNode(node)
+	}
 	if g.ftdc != nil {
 		g.ftdc.Add(name.String(), node)
 	}
@@ -646,6 +696,9 @@ func (g *Graph) remove(node Name) {
 	if g.ftdc != nil {
 		g.ftdc.Remove(node.String())
 	}
+	if g.observer != nil {
+		g.observer.NodeRemoved(node)
+	}
 }
 
 // MarkForRemoval marks the given graph for removal at a later point
@@ -1306,3 +1306,91 @@ func TestResolveDependenciesSkipsDependencyMarkedForRemoval(t *testing.T) {
 	test.That(t, g.ResolveDependencies(logger), test.ShouldBeNil)
 	test.That(t, g.GetAllParentsOf(nameB), test.ShouldResemble, []Name{nameA})
 }
+
+func TestGraphExport(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	g := NewGraph(logger)
+	model := DefaultModelFamily.WithModel("fake")
+	nameA := NewName(apiA, "A")
+	nameB := NewName(apiA, "B")
+	nameC := NewName(apiA, "C").PrependRemote("remote1")
+
+	test.That(t, g.AddNode(nameA, NewUnconfiguredGraphNode(Config{Name: "A", API: apiA, Model: model}, nil)), test.ShouldBeNil)
+	nodeB := NewUnconfiguredGraphNode(Config{Name: "B", API: apiA, Model: model}, []string{"A", "missing"})
+	test.That(t, g.AddNode(nameB, nodeB), test.ShouldBeNil)
+	test.That(t, g.AddNode(nameC, NewUninitializedNode()), test.ShouldBeNil)
+	test.That(t, g.AddChild(nameB, nameA), test.ShouldBeNil)
+	nodeB.LogAndSetLastError(errors.New("failed to build"))
+
+	export := g.Export()
+	test.That(t, export.Edges, test.ShouldResemble, []GraphExportEdge{{From: nameB.String(), To: nameA.String()}})
+	test.That(t, export.Nodes, test.ShouldHaveLength, 3)
+
+	exportedA := export.Nodes[0]
+	test.That(t, exportedA.Name, test.ShouldEqual, nameA.String())
+	test.That(t, exportedA.API, test.ShouldEqual, apiA.String())
+	test.That(t, exportedA.Model, test.ShouldEqual, model.String())
+	test.That(t, exportedA.State, test.ShouldEqual, NodeStateConfiguring.String())
+	test.That(t, exportedA.Error, test.ShouldBeEmpty)
+
+	exportedB := export.Nodes[1]
+	test.That(t, exportedB.Name, test.ShouldEqual, nameB.String())
+	test.That(t, exportedB.State, test.ShouldEqual, NodeStateUnhealthy.String())
+	test.That(t, exportedB.Error, test.ShouldEqual, "failed to build")
+
+	exportedC := export.Nodes[2]
+	test.That(t, exportedC.Remote, test.ShouldEqual, "remote1")
+	test.That(t, exportedC.Model, test.ShouldBeEmpty)
+}
+
+type recordingObserver struct {
+	transitions []NodeStatus
+	removed     []Name
+}
+
+func (o *recordingObserver) NodeTransitioned(status NodeStatus) {
+	o.transitions = append(o.transitions, status)
+}
+
+func (o *recordingObserver) NodeRemoved(name Name) {
+	o.removed = append(o.removed, name)
+}
+
+func TestGraphObserver(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	g := NewGraph(logger)
+	model := DefaultModelFamily.WithModel("fake")
+	nameA := NewName(apiA, "A")
+	nameB := NewName(apiA, "B")
+
+	// nodes added before the observer is set are observed too
+	nodeA := NewUnconfiguredGraphNode(Config{Name: "A", API: apiA, Model: model}, nil)
+	test.That(t, g.AddNode(nameA, nodeA), test.ShouldBeNil)
+	observer := &recordingObserver{}
+	g.SetObserver(observer)
+	nodeB := NewUnconfiguredGraphNode(Config{Name: "B", API: apiA, Model: model}, nil)
+	test.That(t, g.AddNode(nameB, nodeB), test.ShouldBeNil)
+
+	// a node added to an observed graph announces its initial state
+	test.That(t, observer.transitions, test.ShouldHaveLength, 1)
+	test.That(t, observer.transitions[0].Name, test.ShouldResemble, nameB)
+	test.That(t, observer.transitions[0].State, test.ShouldEqual, NodeStateConfiguring)
+
+	nodeA.SwapResource(&someResource{Named: nameA.AsNamed()}, model, nil, true)
+	buildErr := errors.New("failed to build")
+	nodeB.LogAndSetLastError(buildErr)
+
+	test.That(t, observer.transitions, test.ShouldHaveLength, 3)
+	test.That(t, observer.transitions[1].Name, test.ShouldResemble, nameA)
+	test.That(t, observer.transitions[1].State, test.ShouldEqual, NodeStateReady)
+	test.That(t, observer.transitions[1].Error, test.ShouldBeNil)
+	test.That(t, observer.transitions[2].Name, test.ShouldResemble, nameB)
+	test.That(t, observer.transitions[2].State, test.ShouldEqual, NodeStateUnhealthy)
+	test.That(t, observer.transitions[2].Error, test.ShouldBeError, buildErr)
+
+	nodeB.MarkForRemoval()
+	g.RemoveMarked()
+	test.That(t, observer.transitions, test.ShouldHaveLength, 4)
+	test.That(t, observer.transitions[3].State, test.ShouldEqual, NodeStateRemoving)
+	test.That(t, observer.removed, test.ShouldResemble, []Name{nameB})
+}
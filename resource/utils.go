@@ -1,5 +1,7 @@
 package resource
 
+import "context"
+
 type resourceNamer struct {
 	nameField       string
 	nameFromMessage func(any) string
@@ -60,3 +62,23 @@ func GetResourceNameOverride(service, method string) string {
 func GetResourceNameFromRequest(service, method string, req any) string {
 	return getResourceNamer(service, method).nameFromMessage(req)
 }
+
+type accessCheckKey struct{}
+
+// ContextWithAccessCheck returns ctx with check, which returns an error if the caller of an RPC
+// may not access the resource with the given short name. The name a request is for is checked
+// before it is served; servers of requests naming several resources in their bodies check each
+// of them with CheckAccess.
+func ContextWithAccessCheck(ctx context.Context, check func(name string) error) context.Context {
+	return context.WithValue(ctx, accessCheckKey{}, check)
+}
+
+// CheckAccess returns an error if the caller of the RPC being served with ctx may not access the
+// resource named name.
+func CheckAccess(ctx context.Context, name Name) error {
+	check, ok := ctx.Value(accessCheckKey{}).(func(name string) error)
+	if !ok {
+		return nil
+	}
+	return check(name.ShortName())
+}
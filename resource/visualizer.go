@@ -191,6 +191,7 @@ func exportNode(bw *blockWriter, name Name, node *GraphNode) {
 	_, err := node.Resource()
 	node.mu.RLock()
 	model := node.currentModel
+	state := node.state
 	updatedAt := node.updatedAt
 	needsDepRes := node.needsDependencyResolution
 	unresolvedDepsStr := strings.Join(node.unresolvedDependencies, ", ")
@@ -204,8 +205,9 @@ func exportNode(bw *blockWriter, name Name, node *GraphNode) {
 	const newline = "&#10;"
 	// Every tooltip contains this information. Tooltips for nodes in an error state will also
 	// include error information.
-	tooltipNoError := fmt.Sprintf("Model: %s%vLogicalClock: %d%vNeedsDependencyResolution: %v%vUnresolvedDeps: [%s]",
-		model.String(), newline, updatedAt, newline, needsDepRes, newline, unresolvedDepsStr)
+	tooltipNoError := fmt.Sprintf(
+		"Model: %s%vLifecycle: %v%vLogicalClock: %d%vNeedsDependencyResolution: %v%vUnresolvedDeps: [%s]",
+		model.String(), newline, state, newline, updatedAt, newline, needsDepRes, newline, unresolvedDepsStr)
 
 	// Color nodes based on error state.
 	nodeName := genNodeName(name)
@@ -361,3 +363,77 @@ func (g *Graph) ExportDot() (string, error) {
 
 	return writer.String(), nil
 }
+
+// A GraphExport is a structured export of the resource graph meant for debugging why resources
+// are not configured. Like ExportDot, it is best-effort as nodes may change while it is taken.
+type GraphExport struct {
+	Nodes []GraphExportNode `json:"nodes"`
+	// Edges point from a resource to a resource it depends on.
+	Edges []GraphExportEdge `json:"edges"`
+}
+
+// A GraphExportNode describes a single resource in a GraphExport.
+type GraphExportNode struct {
+	Name     string `json:"name"`
+	API      string `json:"api"`
+	Model    string `json:"model,omitempty"`
+	Remote   string `json:"remote,omitempty"`
+	Internal bool   `json:"internal,omitempty"`
+	// Modular is set by the robot for resources that are served by a module.
+	Modular                bool      `json:"modular,omitempty"`
+	State                  string    `json:"state"`
+	Error                  string    `json:"error,omitempty"`
+	UnresolvedDependencies []string  `json:"unresolved_dependencies,omitempty"`
+	LastUpdated            time.Time `json:"last_updated"`
+	LogicalClock           int64     `json:"logical_clock"`
+}
+
+// A GraphExportEdge is a dependency of the From resource on the To resource.
+type GraphExportEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Export exports every node of the resource graph along with its lifecycle state and last error,
+// sorted the same way as ExportDot.
+func (g *Graph) Export() GraphExport {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	export := GraphExport{Nodes: []GraphExportNode{}, Edges: []GraphExportEdge{}}
+	for _, nameNode := range nodesSortedByName(g.nodes) {
+		export.Nodes = append(export.Nodes, exportNodeInfo(nameNode.Name, nameNode.Node))
+	}
+	for _, edge := range edgesSortedByName(g.children) {
+		export.Edges = append(export.Edges, GraphExportEdge{From: edge.source.String(), To: edge.dest.String()})
+	}
+	return export
+}
+
+func exportNodeInfo(name Name, node *GraphNode) GraphExportNode {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+
+	model := node.currentModel
+	if model == (Model{}) {
+		// never built, so fall back to what it should be built as.
+		model = node.config.Model
+	}
+	info := GraphExportNode{
+		Name:                   name.String(),
+		API:                    name.API.String(),
+		Remote:                 name.Remote,
+		Internal:               isInternalService(name),
+		State:                  node.state.String(),
+		UnresolvedDependencies: slices.Clone(node.unresolvedDependencies),
+		LastUpdated:            node.transitionedAt,
+		LogicalClock:           node.updatedAt,
+	}
+	if model != (Model{}) {
+		info.Model = model.String()
+	}
+	if node.lastErr != nil {
+		info.Error = node.lastErr.Error()
+	}
+	return info
+}
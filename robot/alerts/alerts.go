@@ -0,0 +1,372 @@
+// Package alerts implements the robot's alert registry, in which resources and modules raise and
+// clear named alerts, such as "imu_saturated" or "low_disk", so that faults can be detected without
+// scraping logs. The active alerts are reported in the robot's MachineStatus.
+//
+// Resources running in the robot depend on InternalServiceName and raise alerts through the Service
+// returned by FromProvider. Alerts are also raised, cleared, listed, and watched through the
+// DoCommand of the resource named PublicServiceName on every local robot, which works against both
+// local robots and robot clients, such as the connection of a module to its parent. Raise, Clear,
+// List, and Watch wrap that contract.
+package alerts
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+)
+
+// SubtypeName is a constant that identifies the internal alerts resource subtype string.
+const SubtypeName = "alerts"
+
+// API is the fully qualified API for the internal alerts service.
+var API = resource.APINamespaceRDKInternal.WithServiceType(SubtypeName)
+
+// InternalServiceName is used to refer to/depend on this service internally.
+var InternalServiceName = resource.NewName(API, "builtin")
+
+// PublicServiceName is the generic service through which the alerts of a robot are raised and read.
+var PublicServiceName = resource.NewName(generic.API, "$alerts")
+
+// export keys to be used with DoCommand on PublicServiceName so they can be referenced by clients.
+//
+//   - DoRaise raises an alert, given as a robot.Alert of which only the source, name, severity,
+//     message, and metadata are used
+//     required key: DoRaise
+//   - DoClear clears an alert, given its source and name
+//     required key: DoClear
+//   - DoList lists the active alerts
+//     required key: DoList
+//   - DoWatch waits for the alerts to change from the given sequence number, or until
+//     DoTimeoutSec seconds pass, before responding
+//     required key: DoWatch
+//     optional key: DoTimeoutSec, which defaults to 30 seconds
+//
+// Every command responds with the active alerts under DoAlerts and their sequence number, which
+// changes every time they do, under DoSequence.
+const (
+	DoRaise      = "raise"
+	DoClear      = "clear"
+	DoList       = "list"
+	DoWatch      = "watch"
+	DoTimeoutSec = "timeout_sec"
+	DoAlerts     = "alerts"
+	DoSequence   = "sequence"
+)
+
+const (
+	defaultWatchTimeout = 30 * time.Second
+	maxWatchTimeout     = 5 * time.Minute
+)
+
+// A Service holds the active alerts of a robot.
+type Service interface {
+	resource.Resource
+	// Raise raises an alert, or updates it if it is already active. Only the source, name,
+	// severity, message, and metadata of the alert are used.
+	Raise(alert robot.Alert) error
+	// Clear clears an alert. It returns false if the alert was not active.
+	Clear(source, name string) bool
+	// ClearSource clears every alert raised by source.
+	ClearSource(source string)
+	// Alerts returns the active alerts in order of source and name.
+	Alerts() []robot.Alert
+	// Changed returns the sequence number of the active alerts and a channel that is closed once
+	// they change.
+	Changed() (uint64, <-chan struct{})
+}
+
+// FromProvider is a helper for getting the alerts service from a resource Provider (collection of
+// Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name resource.Name) (Service, error) {
+	return resource.FromProvider[Service](provider, name)
+}
+
+type key struct {
+	source, name string
+}
+
+type registry struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+
+	mu       sync.Mutex
+	alerts   map[key]robot.Alert
+	sequence uint64
+	changed  chan struct{}
+}
+
+// New returns an alerts service without any alerts.
+func New() Service {
+	return &registry{
+		Named:   InternalServiceName.AsNamed(),
+		alerts:  map[key]robot.Alert{},
+		changed: make(chan struct{}),
+	}
+}
+
+// notify must be called with mu held after the alerts change.
+func (reg *registry) notify() {
+	reg.sequence++
+	close(reg.changed)
+	reg.changed = make(chan struct{})
+}
+
+func validate(alert robot.Alert) error {
+	if alert.Source == "" {
+		return errors.New("an alert needs a source")
+	}
+	if alert.Name == "" {
+		return errors.New("an alert needs a name")
+	}
+	switch alert.Severity {
+	case robot.AlertInfo, robot.AlertWarning, robot.AlertError, robot.AlertCritical:
+		return nil
+	default:
+		return errors.Errorf("unknown alert severity %q", alert.Severity)
+	}
+}
+
+func (reg *registry) Raise(alert robot.Alert) error {
+	if err := validate(alert); err != nil {
+		return err
+	}
+	now := time.Now()
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	k := key{alert.Source, alert.Name}
+	if active, ok := reg.alerts[k]; ok {
+		alert.Raised, alert.Count = active.Raised, active.Count
+	} else {
+		alert.Raised, alert.Count = now, 0
+	}
+	alert.Updated = now
+	alert.Count++
+	reg.alerts[k] = alert
+	reg.notify()
+	return nil
+}
+
+func (reg *registry) Clear(source, name string) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	k := key{source, name}
+	if _, ok := reg.alerts[k]; !ok {
+		return false
+	}
+	delete(reg.alerts, k)
+	reg.notify()
+	return true
+}
+
+func (reg *registry) ClearSource(source string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	cleared := false
+	for k := range reg.alerts {
+		if k.source == source {
+			delete(reg.alerts, k)
+			cleared = true
+		}
+	}
+	if cleared {
+		reg.notify()
+	}
+}
+
+func (reg *registry) Alerts() []robot.Alert {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.sortedAlerts()
+}
+
+func (reg *registry) sortedAlerts() []robot.Alert {
+	alerts := make([]robot.Alert, 0, len(reg.alerts))
+	for _, alert := range reg.alerts {
+		alerts = append(alerts, alert)
+	}
+	slices.SortFunc(alerts, func(a, b robot.Alert) int {
+		if c := strings.Compare(a.Source, b.Source); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return alerts
+}
+
+func (reg *registry) Changed() (uint64, <-chan struct{}) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.sequence, reg.changed
+}
+
+// Raise raises an alert on the given robot.
+func Raise(ctx context.Context, r robot.Robot, alert robot.Alert) error {
+	m, err := resource.EncodeDoCommand(alert)
+	if err != nil {
+		return err
+	}
+	_, _, err = doCommand(ctx, r, map[string]interface{}{DoRaise: m})
+	return err
+}
+
+// Clear clears an alert of the given robot.
+func Clear(ctx context.Context, r robot.Robot, source, name string) error {
+	_, _, err := doCommand(ctx, r, map[string]interface{}{
+		DoClear: map[string]interface{}{"source": source, "name": name},
+	})
+	return err
+}
+
+// List returns the active alerts of the given robot.
+func List(ctx context.Context, r robot.Robot) ([]robot.Alert, error) {
+	alerts, _, err := doCommand(ctx, r, map[string]interface{}{DoList: true})
+	return alerts, err
+}
+
+// Watch calls onChange with the active alerts of the given robot, and then again every time they
+// change, until the context is canceled or onChange returns an error. Changes that happen in quick
+// succession may only be seen together.
+func Watch(ctx context.Context, r robot.Robot, onChange func([]robot.Alert) error) error {
+	alerts, sequence, err := doCommand(ctx, r, map[string]interface{}{DoList: true})
+	if err != nil {
+		return err
+	}
+	if err := onChange(alerts); err != nil {
+		return err
+	}
+	for {
+		next, nextSequence, err := doCommand(ctx, r, map[string]interface{}{
+			DoWatch:      float64(sequence),
+			DoTimeoutSec: defaultWatchTimeout.Seconds(),
+		})
+		if err != nil {
+			return err
+		}
+		if nextSequence == sequence {
+			continue
+		}
+		sequence = nextSequence
+		if err := onChange(next); err != nil {
+			return err
+		}
+	}
+}
+
+func doCommand(ctx context.Context, r robot.Robot, cmd map[string]interface{}) ([]robot.Alert, uint64, error) {
+	res, err := r.ResourceByName(PublicServiceName)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return nil, 0, err
+	}
+	return fromResponse(resp)
+}
+
+func fromResponse(resp map[string]interface{}) ([]robot.Alert, uint64, error) {
+	sequence, ok := resp[DoSequence].(float64)
+	if !ok {
+		return nil, 0, errors.Errorf("expected %s to be a number but got %T", DoSequence, resp[DoSequence])
+	}
+	list, ok := resp[DoAlerts].([]interface{})
+	if !ok {
+		return nil, 0, errors.Errorf("expected %s to be a list but got %T", DoAlerts, resp[DoAlerts])
+	}
+	alerts := make([]robot.Alert, 0, len(list))
+	for _, v := range list {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, 0, errors.Errorf("expected an alert to be a map but got %T", v)
+		}
+		alert, err := resource.DecodeDoCommand[robot.Alert](m)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, "invalid alert")
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, uint64(sequence), nil
+}
+
+type service struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	alerts Service
+}
+
+// NewService returns the resource through which the given alerts are raised and read, which a
+// robot serves as PublicServiceName.
+func NewService(alerts Service) resource.Resource {
+	return &service{Named: PublicServiceName.AsNamed(), alerts: alerts}
+}
+
+func (svc *service) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch {
+	case cmd[DoRaise] != nil:
+		m, ok := cmd[DoRaise].(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected %s to be a map but got %T", DoRaise, cmd[DoRaise])
+		}
+		alert, err := resource.DecodeDoCommand[robot.Alert](m)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s command", DoRaise)
+		}
+		if err := svc.alerts.Raise(alert); err != nil {
+			return nil, err
+		}
+	case cmd[DoClear] != nil:
+		m, ok := cmd[DoClear].(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected %s to be a map but got %T", DoClear, cmd[DoClear])
+		}
+		source, _ := m["source"].(string)
+		name, _ := m["name"].(string)
+		svc.alerts.Clear(source, name)
+	case cmd[DoList] != nil:
+	case cmd[DoWatch] != nil:
+		since, ok := cmd[DoWatch].(float64)
+		if !ok {
+			return nil, errors.Errorf("expected %s to be a number but got %T", DoWatch, cmd[DoWatch])
+		}
+		timeout := defaultWatchTimeout
+		if sec, ok := cmd[DoTimeoutSec].(float64); ok && sec > 0 {
+			timeout = min(time.Duration(sec*float64(time.Second)), maxWatchTimeout)
+		}
+		if sequence, changed := svc.alerts.Changed(); sequence == uint64(since) {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-timer.C:
+			case <-changed:
+			}
+		}
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+
+	// the sequence is read first so that watchers never miss a change made while the alerts are
+	// read.
+	sequence, _ := svc.alerts.Changed()
+	alerts := svc.alerts.Alerts()
+	list := make([]interface{}, 0, len(alerts))
+	for _, alert := range alerts {
+		m, err := resource.EncodeDoCommand(alert)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return map[string]interface{}{DoAlerts: list, DoSequence: float64(sequence)}, nil
+}
@@ -0,0 +1,128 @@
+package alerts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/alerts"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestRegistry(t *testing.T) {
+	reg := alerts.New()
+	test.That(t, reg.Name(), test.ShouldResemble, alerts.InternalServiceName)
+	test.That(t, reg.Alerts(), test.ShouldBeEmpty)
+	sequence, changed := reg.Changed()
+
+	imu := "rdk:component:movement_sensor/imu"
+	test.That(t, reg.Raise(robot.Alert{Source: imu, Name: "imu_saturated", Severity: "bad"}), test.ShouldNotBeNil)
+	test.That(t, reg.Raise(robot.Alert{Source: imu, Severity: robot.AlertWarning}), test.ShouldNotBeNil)
+	test.That(t, reg.Raise(robot.Alert{Name: "imu_saturated", Severity: robot.AlertWarning}), test.ShouldNotBeNil)
+	next, _ := reg.Changed()
+	test.That(t, next, test.ShouldEqual, sequence)
+
+	test.That(t, reg.Raise(robot.Alert{Source: imu, Name: "imu_saturated", Severity: robot.AlertWarning}), test.ShouldBeNil)
+	<-changed
+	first := reg.Alerts()[0]
+	test.That(t, first.Count, test.ShouldEqual, 1)
+	test.That(t, first.Raised, test.ShouldEqual, first.Updated)
+
+	test.That(t, reg.Raise(robot.Alert{
+		Source:   imu,
+		Name:     "imu_saturated",
+		Severity: robot.AlertError,
+		Message:  "acceleration out of range",
+		Metadata: map[string]interface{}{"axis": "z"},
+	}), test.ShouldBeNil)
+	test.That(t, reg.Raise(robot.Alert{Source: "system", Name: "low_disk", Severity: robot.AlertCritical}), test.ShouldBeNil)
+	active := reg.Alerts()
+	test.That(t, active, test.ShouldHaveLength, 2)
+	test.That(t, active[0].Source, test.ShouldEqual, imu)
+	test.That(t, active[0].Severity, test.ShouldEqual, robot.AlertError)
+	test.That(t, active[0].Count, test.ShouldEqual, 2)
+	test.That(t, active[0].Raised, test.ShouldEqual, first.Raised)
+	test.That(t, active[0].Metadata, test.ShouldResemble, map[string]interface{}{"axis": "z"})
+	test.That(t, active[1].Name, test.ShouldEqual, "low_disk")
+
+	test.That(t, reg.Clear(imu, "missing"), test.ShouldBeFalse)
+	test.That(t, reg.Clear("system", "low_disk"), test.ShouldBeTrue)
+	reg.ClearSource(imu)
+	test.That(t, reg.Alerts(), test.ShouldBeEmpty)
+	next, _ = reg.Changed()
+	test.That(t, next, test.ShouldEqual, sequence+5)
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	reg := alerts.New()
+	svc := alerts.NewService(reg)
+	test.That(t, svc.Name(), test.ShouldResemble, alerts.PublicServiceName)
+
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		test.That(t, name, test.ShouldResemble, alerts.PublicServiceName)
+		return svc, nil
+	}
+
+	active, err := alerts.List(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, active, test.ShouldBeEmpty)
+
+	alert := robot.Alert{
+		Source:   "module-a",
+		Name:     "low_disk",
+		Severity: robot.AlertWarning,
+		Metadata: map[string]interface{}{"free_bytes": 1024.},
+	}
+	test.That(t, alerts.Raise(ctx, r, alert), test.ShouldBeNil)
+	test.That(t, alerts.Raise(ctx, r, robot.Alert{Source: "module-a", Name: "low_disk"}), test.ShouldNotBeNil)
+	active, err = alerts.List(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, active, test.ShouldHaveLength, 1)
+	test.That(t, active[0].Metadata, test.ShouldResemble, alert.Metadata)
+	test.That(t, active[0].Raised.Equal(reg.Alerts()[0].Raised), test.ShouldBeTrue)
+
+	t.Run("watch", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		errStop := errors.New("stop")
+		seen := make(chan []robot.Alert, 3)
+		done := make(chan error, 1)
+		go func() {
+			done <- alerts.Watch(ctx, r, func(active []robot.Alert) error {
+				seen <- active
+				if len(active) == 0 {
+					return errStop
+				}
+				return nil
+			})
+		}()
+		test.That(t, <-seen, test.ShouldHaveLength, 1)
+		test.That(t, alerts.Clear(ctx, r, "module-a", "low_disk"), test.ShouldBeNil)
+		test.That(t, <-seen, test.ShouldBeEmpty)
+		test.That(t, <-done, test.ShouldEqual, errStop)
+	})
+
+	t.Run("watch times out", func(t *testing.T) {
+		sequence, _ := reg.Changed()
+		start := time.Now()
+		resp, err := svc.DoCommand(ctx, map[string]interface{}{
+			alerts.DoWatch:      float64(sequence),
+			alerts.DoTimeoutSec: 0.05,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+		test.That(t, resp[alerts.DoSequence], test.ShouldEqual, float64(sequence))
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		_, err := svc.DoCommand(ctx, map[string]interface{}{"unknown": true})
+		test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+	})
+}
@@ -0,0 +1,192 @@
+// Package arbiter arbitrates control of a robot's actuating components between the sessions of the
+// clients connected to it. A session may acquire exclusive control of resources, after which the
+// safety monitored methods of those resources, which are the ones that move them, are rejected
+// unless they are called within that session. Another session may take control over, which stops
+// the resources so that they do not keep following the last command of the previous session.
+// Control is released explicitly or when the session expires.
+//
+// The arbiter is a resource named PublicServiceName on every local robot, and it is used through its
+// DoCommand with the keys below, which works against both local robots and robot clients. Acquire,
+// Release, and Controllers wrap that contract.
+package arbiter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+)
+
+// PublicServiceName is the generic service through which control of a robot's resources is arbitrated.
+var PublicServiceName = resource.NewName(generic.API, "$arbiter")
+
+// export keys to be used with DoCommand on PublicServiceName so they can be referenced by clients.
+//
+//   - DoAcquire acquires exclusive control of a list of resource names
+//     required keys: DoAcquire, DoSessionID
+//     optional key: DoTakeover, a bool to take control from other sessions
+//   - DoRelease releases control of a list of resource names, or of every resource if it is empty
+//     required keys: DoRelease, DoSessionID
+//   - DoStatus returns which sessions control which resources
+//     required key: DoStatus
+//
+// Every command responds with the sessions in control of resources under the DoControllers key, as
+// a map of resource names to session IDs.
+const (
+	DoAcquire     = "acquire"
+	DoRelease     = "release"
+	DoTakeover    = "takeover"
+	DoSessionID   = "session_id"
+	DoStatus      = "status"
+	DoControllers = "controllers"
+)
+
+// A Controller arbitrates control of resources between sessions, which robot.SessionManager does.
+type Controller interface {
+	AcquireControl(ctx context.Context, id uuid.UUID, ownerID string, names []resource.Name, takeover bool) error
+	ReleaseControl(ctx context.Context, id uuid.UUID, ownerID string, names []resource.Name) error
+	Controllers() map[resource.Name]uuid.UUID
+}
+
+// Acquire acquires exclusive control of the named resources of the given robot for the session with
+// the given ID, taking control from other sessions if takeover is set.
+func Acquire(ctx context.Context, r robot.Robot, sessionID string, names []resource.Name, takeover bool) error {
+	_, err := doCommand(ctx, r, map[string]interface{}{
+		DoAcquire:   namesToStrings(names),
+		DoSessionID: sessionID,
+		DoTakeover:  takeover,
+	})
+	return err
+}
+
+// Release releases control of the named resources of the given robot by the session with the given
+// ID, or of every resource it controls if no names are given.
+func Release(ctx context.Context, r robot.Robot, sessionID string, names ...resource.Name) error {
+	_, err := doCommand(ctx, r, map[string]interface{}{
+		DoRelease:   namesToStrings(names),
+		DoSessionID: sessionID,
+	})
+	return err
+}
+
+// Controllers returns the IDs of the sessions in exclusive control of resources of the given robot.
+func Controllers(ctx context.Context, r robot.Robot) (map[resource.Name]string, error) {
+	return doCommand(ctx, r, map[string]interface{}{DoStatus: true})
+}
+
+func doCommand(ctx context.Context, r robot.Robot, cmd map[string]interface{}) (map[resource.Name]string, error) {
+	res, err := r.ResourceByName(PublicServiceName)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	controllers, ok := resp[DoControllers].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected %s to be a map but got %T", DoControllers, resp[DoControllers])
+	}
+	result := make(map[resource.Name]string, len(controllers))
+	for name, id := range controllers {
+		resName, err := resource.NewFromString(name)
+		if err != nil {
+			return nil, err
+		}
+		idStr, ok := id.(string)
+		if !ok {
+			return nil, errors.Errorf("expected session ID of %q to be a string but got %T", name, id)
+		}
+		result[resName] = idStr
+	}
+	return result, nil
+}
+
+func namesToStrings(names []resource.Name) []interface{} {
+	strs := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		strs = append(strs, name.String())
+	}
+	return strs
+}
+
+func namesFromCommand(cmd map[string]interface{}, key string) ([]resource.Name, error) {
+	values, ok := cmd[key].([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected %s to be a list of resource names but got %T", key, cmd[key])
+	}
+	names := make([]resource.Name, 0, len(values))
+	for _, v := range values {
+		str, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("expected %s to be a list of resource names but found %T", key, v)
+		}
+		name, err := resource.NewFromString(str)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+type service struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	controller Controller
+}
+
+// NewService returns the resource through which control of the resources arbitrated by controller
+// is acquired and released, which the robot serves as PublicServiceName.
+func NewService(controller Controller) resource.Resource {
+	return &service{Named: PublicServiceName.AsNamed(), controller: controller}
+}
+
+func (svc *service) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	_, acquire := cmd[DoAcquire]
+	_, release := cmd[DoRelease]
+	_, status := cmd[DoStatus]
+	switch {
+	case acquire && release:
+		return nil, fmt.Errorf("cannot both %s and %s control", DoAcquire, DoRelease)
+	case acquire || release:
+		sessionID, err := uuid.Parse(fmt.Sprint(cmd[DoSessionID]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s must be the ID of a session", DoSessionID)
+		}
+		key := DoRelease
+		if acquire {
+			key = DoAcquire
+		}
+		names, err := namesFromCommand(cmd, key)
+		if err != nil {
+			return nil, err
+		}
+		// sessions are owned by the entity that started them.
+		owner, _ := rpc.ContextAuthEntity(ctx)
+		if acquire {
+			takeover, _ := cmd[DoTakeover].(bool)
+			err = svc.controller.AcquireControl(ctx, sessionID, owner.Entity, names, takeover)
+		} else {
+			err = svc.controller.ReleaseControl(ctx, sessionID, owner.Entity, names)
+		}
+		if err != nil {
+			return nil, err
+		}
+	case !status:
+		return nil, resource.ErrDoUnimplemented
+	}
+
+	controllers := map[string]interface{}{}
+	for name, id := range svc.controller.Controllers() {
+		controllers[name.String()] = id.String()
+	}
+	return map[string]interface{}{DoControllers: controllers}, nil
+}
@@ -0,0 +1,116 @@
+package arbiter_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+	"go.viam.com/utils/testutils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/arbiter"
+	"go.viam.com/rdk/robot/client"
+	robotimpl "go.viam.com/rdk/robot/impl"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/robottestutils"
+)
+
+func TestArbiter(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	var stops atomic.Int64
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+	resource.RegisterComponent(
+		motor.API,
+		model,
+		resource.Registration[motor.Motor, resource.NoNativeConfig]{
+			Constructor: func(
+				ctx context.Context,
+				deps resource.Dependencies,
+				conf resource.Config,
+				logger logging.Logger,
+			) (motor.Motor, error) {
+				m := inject.NewMotor(conf.Name)
+				m.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+					return nil
+				}
+				m.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+					stops.Add(1)
+					return nil
+				}
+				return m, nil
+			},
+		})
+
+	motor1 := motor.Named("motor1")
+	r, err := robotimpl.New(ctx, &config.Config{Components: []resource.Config{
+		{Name: "motor1", API: motor.API, Model: model},
+	}}, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, r.Close(ctx), test.ShouldBeNil)
+	}()
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	newClient := func() (*client.RobotClient, motor.Motor, string) {
+		rc, err := client.New(ctx, addr, logger, client.WithDialOptions(rpc.WithWebRTCOptions(rpc.DialWebRTCOptions{
+			Disable: true,
+		})))
+		test.That(t, err, test.ShouldBeNil)
+		m, err := motor.FromProvider(rc, "motor1")
+		test.That(t, err, test.ShouldBeNil)
+		sessionID, err := rc.SessionID(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		return rc, m, sessionID
+	}
+	client1, motorClient1, session1 := newClient()
+	defer func() {
+		test.That(t, client1.Close(ctx), test.ShouldBeNil)
+	}()
+	client2, motorClient2, session2 := newClient()
+
+	test.That(t, arbiter.Acquire(ctx, client1, session1, []resource.Name{motor1}, false), test.ShouldBeNil)
+	test.That(t, motorClient1.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+	err = motorClient2.SetPower(ctx, 0.5, nil)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.FailedPrecondition)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "exclusively controlled by another session")
+
+	err = arbiter.Acquire(ctx, client2, session2, []resource.Name{motor1}, false)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "already controlled by another session")
+	controllers, err := arbiter.Controllers(ctx, client2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, controllers, test.ShouldResemble, map[resource.Name]string{motor1: session1})
+
+	// taking over stops the motor and locks out the previous controller.
+	stopsBefore := stops.Load()
+	test.That(t, arbiter.Acquire(ctx, client2, session2, []resource.Name{motor1}, true), test.ShouldBeNil)
+	test.That(t, stops.Load(), test.ShouldBeGreaterThan, stopsBefore)
+	test.That(t, motorClient2.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+	err = motorClient1.SetPower(ctx, 0.5, nil)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.FailedPrecondition)
+
+	test.That(t, arbiter.Release(ctx, client2, session2), test.ShouldBeNil)
+	test.That(t, motorClient1.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+
+	// control is released when the session expires.
+	test.That(t, arbiter.Acquire(ctx, client2, session2, []resource.Name{motor1}, false), test.ShouldBeNil)
+	test.That(t, client2.Close(ctx), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		controllers, err := arbiter.Controllers(ctx, r)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, controllers, test.ShouldBeEmpty)
+	})
+	test.That(t, motorClient1.SetPower(ctx, 0.5, nil), test.ShouldBeNil)
+}
@@ -0,0 +1,758 @@
+// Package automation edits the jobs of a robot and the programs of its orchestration services at
+// runtime, so that they can be managed without pushing a whole new config. Definitions created or
+// updated at runtime are applied on top of the robot's config, replacing configured definitions of
+// the same name, and any definition can be disabled. Runtime changes are stored in a file so that
+// they are kept across restarts.
+//
+// The editor is a resource named PublicServiceName on every local robot, and it is used through its
+// DoCommand with the keys below, which works against both local robots and robot clients. List,
+// Validate, Create, Update, Delete, SetEnabled, and RunNow wrap that contract. Since jobs run as the
+// robot, only the admin role may use it.
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/services/orchestration"
+	obuiltin "go.viam.com/rdk/services/orchestration/builtin"
+)
+
+// PublicServiceName is the generic service through which a robot's automation is edited.
+var PublicServiceName = resource.NewName(generic.API, "$automation")
+
+// export keys to be used with DoCommand on PublicServiceName so they can be referenced by clients.
+//
+//   - DoList lists the definitions of the robot
+//     required key: DoList
+//   - DoValidate checks a Definition without changing anything
+//     required key: DoValidate
+//   - DoCreate creates a Definition, which must not exist yet
+//     required key: DoCreate
+//   - DoUpdate replaces an existing Definition, which may be one of the config
+//     required key: DoUpdate
+//   - DoDelete deletes a Definition created at runtime, given its Ref
+//     required key: DoDelete
+//   - DoEnable and DoDisable enable and disable a Definition, given its Ref
+//     required key: DoEnable or DoDisable
+//   - DoRunNow runs an enabled job, or starts an enabled program, once now, given its Ref
+//     required key: DoRunNow
+//
+// Every command responds with the Entries of the robot under the DoDefinitions key.
+const (
+	DoList        = "list"
+	DoValidate    = "validate"
+	DoCreate      = "create"
+	DoUpdate      = "update"
+	DoDelete      = "delete"
+	DoEnable      = "enable"
+	DoDisable     = "disable"
+	DoRunNow      = "run_now"
+	DoDefinitions = "definitions"
+)
+
+// A Kind is a kind of definition.
+type Kind string
+
+// The set of known kinds.
+const (
+	// KindJob definitions are jobs of the robot's config.
+	KindJob = Kind("job")
+	// KindTree and KindStateMachine definitions are programs of an orchestration service.
+	KindTree         = Kind("tree")
+	KindStateMachine = Kind("state_machine")
+)
+
+// A Ref identifies a definition.
+type Ref struct {
+	Kind Kind `json:"kind"`
+	// Service is the orchestration service of a program.
+	Service string `json:"service,omitempty"`
+	Name    string `json:"name"`
+}
+
+// Validate ensures the ref is complete.
+func (ref *Ref) Validate() error {
+	switch ref.Kind {
+	case KindJob:
+	case KindTree, KindStateMachine:
+		if ref.Service == "" {
+			return errors.Errorf("a %s needs the orchestration service it belongs to", ref.Kind)
+		}
+	default:
+		return errors.Errorf("unknown kind %q", ref.Kind)
+	}
+	if ref.Name == "" {
+		return errors.New("a name is required")
+	}
+	return nil
+}
+
+func (ref Ref) String() string {
+	if ref.Service == "" {
+		return fmt.Sprintf("%s %q", ref.Kind, ref.Name)
+	}
+	return fmt.Sprintf("%s %q of %q", ref.Kind, ref.Name, ref.Service)
+}
+
+// A Definition is a job, or a program of an orchestration service, in the format of the config.
+type Definition struct {
+	Kind Kind `json:"kind"`
+	// Service is the orchestration service of a program.
+	Service      string                       `json:"service,omitempty"`
+	Job          *config.JobConfig            `json:"job,omitempty"`
+	Tree         *obuiltin.TreeConfig         `json:"tree,omitempty"`
+	StateMachine *obuiltin.StateMachineConfig `json:"state_machine,omitempty"`
+}
+
+// Ref returns the ref of the definition.
+func (def *Definition) Ref() Ref {
+	ref := Ref{Kind: def.Kind, Service: def.Service}
+	switch {
+	case def.Kind == KindJob && def.Job != nil:
+		ref.Name = def.Job.Name
+	case def.Kind == KindTree && def.Tree != nil:
+		ref.Name = def.Tree.Name
+	case def.Kind == KindStateMachine && def.StateMachine != nil:
+		ref.Name = def.StateMachine.Name
+	}
+	return ref
+}
+
+// Validate ensures the definition is complete and valid in itself. Whether it fits the robot is
+// checked by Manager.Validate.
+func (def *Definition) Validate() error {
+	set := 0
+	for _, isSet := range []bool{def.Job != nil, def.Tree != nil, def.StateMachine != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("a definition needs exactly one of job, tree, or state_machine")
+	}
+	if (def.Kind == KindJob && def.Job == nil) ||
+		(def.Kind == KindTree && def.Tree == nil) ||
+		(def.Kind == KindStateMachine && def.StateMachine == nil) {
+		return errors.Errorf("a definition of kind %s needs a %s", def.Kind, def.Kind)
+	}
+	ref := def.Ref()
+	if err := ref.Validate(); err != nil {
+		return err
+	}
+	switch def.Kind {
+	case KindJob:
+		return def.Job.Validate(string(KindJob))
+	case KindTree:
+		_, _, err := (&obuiltin.Config{Trees: []obuiltin.TreeConfig{*def.Tree}}).Validate(string(KindTree))
+		return err
+	default:
+		_, _, err := (&obuiltin.Config{StateMachines: []obuiltin.StateMachineConfig{*def.StateMachine}}).Validate(string(KindStateMachine))
+		return err
+	}
+}
+
+// A Source is where a definition comes from.
+type Source string
+
+// The set of known sources.
+const (
+	SourceConfig  = Source("config")
+	SourceRuntime = Source("runtime")
+)
+
+// An Entry is a definition of the robot.
+type Entry struct {
+	Definition
+	Source Source `json:"source"`
+	// Overrides is whether a definition created at runtime replaces one of the config.
+	Overrides bool `json:"overrides,omitempty"`
+	Enabled   bool `json:"enabled"`
+}
+
+// List lists the definitions of the given robot.
+func List(ctx context.Context, r robot.Robot) ([]Entry, error) {
+	return doCommand(ctx, r, map[string]interface{}{DoList: true})
+}
+
+// Validate checks a definition against the given robot without changing anything.
+func Validate(ctx context.Context, r robot.Robot, def Definition) error {
+	return doDefinitionCommand(ctx, r, DoValidate, def)
+}
+
+// Create creates a definition on the given robot.
+func Create(ctx context.Context, r robot.Robot, def Definition) error {
+	return doDefinitionCommand(ctx, r, DoCreate, def)
+}
+
+// Update replaces a definition of the given robot.
+func Update(ctx context.Context, r robot.Robot, def Definition) error {
+	return doDefinitionCommand(ctx, r, DoUpdate, def)
+}
+
+// Delete deletes a definition created at runtime on the given robot.
+func Delete(ctx context.Context, r robot.Robot, ref Ref) error {
+	return doRefCommand(ctx, r, DoDelete, ref)
+}
+
+// SetEnabled enables or disables a definition of the given robot.
+func SetEnabled(ctx context.Context, r robot.Robot, ref Ref, enabled bool) error {
+	key := DoDisable
+	if enabled {
+		key = DoEnable
+	}
+	return doRefCommand(ctx, r, key, ref)
+}
+
+// RunNow runs a job, or starts a program, of the given robot once now.
+func RunNow(ctx context.Context, r robot.Robot, ref Ref) error {
+	return doRefCommand(ctx, r, DoRunNow, ref)
+}
+
+func doDefinitionCommand(ctx context.Context, r robot.Robot, key string, def Definition) error {
+	m, err := resource.EncodeDoCommand(&def)
+	if err != nil {
+		return err
+	}
+	_, err = doCommand(ctx, r, map[string]interface{}{key: m})
+	return err
+}
+
+func doRefCommand(ctx context.Context, r robot.Robot, key string, ref Ref) error {
+	m, err := resource.EncodeDoCommand(&ref)
+	if err != nil {
+		return err
+	}
+	_, err = doCommand(ctx, r, map[string]interface{}{key: m})
+	return err
+}
+
+func doCommand(ctx context.Context, r robot.Robot, cmd map[string]interface{}) ([]Entry, error) {
+	res, err := r.ResourceByName(PublicServiceName)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	cmdEntries, ok := resp[DoDefinitions].([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected %s to be a list but got %T", DoDefinitions, resp[DoDefinitions])
+	}
+	entries := make([]Entry, 0, len(cmdEntries))
+	for _, cmdEntry := range cmdEntries {
+		m, ok := cmdEntry.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected a definition to be a map but got %T", cmdEntry)
+		}
+		entry, err := resource.DecodeDoCommand[Entry](m)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid definition")
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// storedState is what a Manager stores in its file.
+type storedState struct {
+	Definitions []Definition `json:"definitions,omitempty"`
+	Disabled    []Ref        `json:"disabled,omitempty"`
+}
+
+// A Manager holds the definitions created at runtime and the refs of disabled definitions, and
+// applies them to the config of a robot.
+type Manager struct {
+	path string
+
+	mu    sync.Mutex
+	base  *config.Config
+	state storedState
+}
+
+// NewManager returns a Manager that stores its changes in the file at path, loading those already
+// stored there. Changes are only kept in memory if path is empty.
+func NewManager(path string) (*Manager, error) {
+	m := &Manager{path: path, base: &config.Config{}}
+	if path == "" {
+		return m, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &m.state); err != nil {
+		return nil, errors.Wrapf(err, "invalid automation file %s", path)
+	}
+	return m, nil
+}
+
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0o750); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
+
+// SetConfig sets the config of the robot, which the definitions of the Manager are applied to.
+func (m *Manager) SetConfig(cfg *config.Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.base = cfg
+}
+
+// configured returns the definitions of the config.
+func (m *Manager) configured() []Definition {
+	var defs []Definition
+	for i := range m.base.Jobs {
+		job := m.base.Jobs[i]
+		defs = append(defs, Definition{Kind: KindJob, Job: &job})
+	}
+	for _, svc := range m.base.Services {
+		if svc.API != orchestration.API {
+			continue
+		}
+		var programs obuiltin.Config
+		attrs, err := attributesOf(svc)
+		if err == nil {
+			err = convert(attrs, &programs)
+		}
+		if err != nil {
+			// the service reports its own invalid config.
+			continue
+		}
+		for i := range programs.Trees {
+			defs = append(defs, Definition{Kind: KindTree, Service: svc.Name, Tree: &programs.Trees[i]})
+		}
+		for i := range programs.StateMachines {
+			defs = append(defs, Definition{Kind: KindStateMachine, Service: svc.Name, StateMachine: &programs.StateMachines[i]})
+		}
+	}
+	return defs
+}
+
+func (m *Manager) runtimeIndex(ref Ref) int {
+	return slices.IndexFunc(m.state.Definitions, func(def Definition) bool { return def.Ref() == ref })
+}
+
+func (m *Manager) isConfigured(ref Ref) bool {
+	return slices.ContainsFunc(m.configured(), func(def Definition) bool { return def.Ref() == ref })
+}
+
+// List returns the definitions of the config and those created at runtime, in order of kind,
+// service, and name.
+func (m *Manager) List() []Entry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var entries []Entry
+	seen := map[Ref]bool{}
+	add := func(def Definition, source Source, overrides bool) {
+		ref := def.Ref()
+		seen[ref] = true
+		entries = append(entries, Entry{
+			Definition: def,
+			Source:     source,
+			Overrides:  overrides,
+			Enabled:    !slices.Contains(m.state.Disabled, ref),
+		})
+	}
+	for _, def := range m.configured() {
+		if i := m.runtimeIndex(def.Ref()); i >= 0 {
+			add(m.state.Definitions[i], SourceRuntime, true)
+			continue
+		}
+		add(def, SourceConfig, false)
+	}
+	for _, def := range m.state.Definitions {
+		if !seen[def.Ref()] {
+			add(def, SourceRuntime, false)
+		}
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		aRef, bRef := a.Ref(), b.Ref()
+		if c := strings.Compare(string(aRef.Kind), string(bRef.Kind)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(aRef.Service, bRef.Service); c != 0 {
+			return c
+		}
+		return strings.Compare(aRef.Name, bRef.Name)
+	})
+	return entries
+}
+
+// Validate checks a definition, and that the orchestration service of a program is in the config.
+func (m *Manager) Validate(def Definition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.validate(&def)
+}
+
+func (m *Manager) validate(def *Definition) error {
+	if err := def.Validate(); err != nil {
+		return err
+	}
+	if def.Kind == KindJob {
+		return nil
+	}
+	if !slices.ContainsFunc(m.base.Services, func(svc resource.Config) bool {
+		return svc.API == orchestration.API && svc.Name == def.Service
+	}) {
+		return errors.Errorf("no orchestration service named %q is configured", def.Service)
+	}
+	return nil
+}
+
+// Create adds a definition that is neither in the config nor created already.
+func (m *Manager) Create(def Definition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.validate(&def); err != nil {
+		return err
+	}
+	ref := def.Ref()
+	if m.runtimeIndex(ref) >= 0 || m.isConfigured(ref) {
+		return errors.Errorf("%s already exists", ref)
+	}
+	m.state.Definitions = append(m.state.Definitions, def)
+	return m.save()
+}
+
+// Update replaces a definition, overriding it if it is in the config.
+func (m *Manager) Update(def Definition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.validate(&def); err != nil {
+		return err
+	}
+	ref := def.Ref()
+	if i := m.runtimeIndex(ref); i >= 0 {
+		m.state.Definitions[i] = def
+	} else if m.isConfigured(ref) {
+		m.state.Definitions = append(m.state.Definitions, def)
+	} else {
+		return errors.Errorf("%s does not exist", ref)
+	}
+	return m.save()
+}
+
+// Delete deletes a definition created at runtime. Deleting a definition that overrides one of the
+// config reverts to the one of the config.
+func (m *Manager) Delete(ref Ref) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := m.runtimeIndex(ref)
+	if i < 0 {
+		if m.isConfigured(ref) {
+			return errors.Errorf("%s is in the config and can only be disabled", ref)
+		}
+		return errors.Errorf("%s does not exist", ref)
+	}
+	m.state.Definitions = slices.Delete(m.state.Definitions, i, i+1)
+	if !m.isConfigured(ref) {
+		m.state.Disabled = slices.DeleteFunc(m.state.Disabled, func(disabled Ref) bool { return disabled == ref })
+	}
+	return m.save()
+}
+
+// SetEnabled enables or disables a definition.
+func (m *Manager) SetEnabled(ref Ref, enabled bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.runtimeIndex(ref) < 0 && !m.isConfigured(ref) {
+		return errors.Errorf("%s does not exist", ref)
+	}
+	m.state.Disabled = slices.DeleteFunc(m.state.Disabled, func(disabled Ref) bool { return disabled == ref })
+	if !enabled {
+		m.state.Disabled = append(m.state.Disabled, ref)
+	}
+	return m.save()
+}
+
+// Enabled returns an error unless a definition exists and is enabled.
+func (m *Manager) Enabled(ref Ref) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.runtimeIndex(ref) < 0 && !m.isConfigured(ref) {
+		return errors.Errorf("%s does not exist", ref)
+	}
+	if slices.Contains(m.state.Disabled, ref) {
+		return errors.Errorf("%s is disabled", ref)
+	}
+	return nil
+}
+
+// Config returns the config of the robot with the definitions of the Manager applied. The config
+// set by SetConfig is not modified. Definitions that cannot be applied are left out and reported in
+// the returned error.
+func (m *Manager) Config() (*config.Config, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cfg := *m.base
+	if len(m.state.Definitions) == 0 && len(m.state.Disabled) == 0 {
+		return &cfg, nil
+	}
+	cfg.Jobs = slices.Clone(cfg.Jobs)
+	cfg.Services = slices.Clone(cfg.Services)
+
+	var errs error
+	services := map[string][]Definition{}
+	for _, def := range m.state.Definitions {
+		if def.Kind != KindJob {
+			services[def.Service] = append(services[def.Service], def)
+			continue
+		}
+		if i := slices.IndexFunc(cfg.Jobs, func(job config.JobConfig) bool { return job.Name == def.Job.Name }); i >= 0 {
+			cfg.Jobs[i] = *def.Job
+		} else {
+			cfg.Jobs = append(cfg.Jobs, *def.Job)
+		}
+	}
+	cfg.Jobs = slices.DeleteFunc(cfg.Jobs, func(job config.JobConfig) bool {
+		return slices.Contains(m.state.Disabled, Ref{Kind: KindJob, Name: job.Name})
+	})
+	for _, ref := range m.state.Disabled {
+		if ref.Kind != KindJob {
+			services[ref.Service] = append(services[ref.Service], Definition{})
+		}
+	}
+
+	for name, defs := range services {
+		i := slices.IndexFunc(cfg.Services, func(svc resource.Config) bool {
+			return svc.API == orchestration.API && svc.Name == name
+		})
+		if i < 0 {
+			for _, def := range defs {
+				if def.Kind != "" {
+					errs = multierr.Combine(errs, errors.Errorf("cannot apply %s as there is no such orchestration service", def.Ref()))
+				}
+			}
+			continue
+		}
+		svc, err := m.applyPrograms(cfg.Services[i], defs)
+		if err != nil {
+			errs = multierr.Combine(errs, errors.Wrapf(err, "cannot apply the programs of orchestration service %q", name))
+			continue
+		}
+		cfg.Services[i] = svc
+	}
+	return &cfg, errs
+}
+
+// applyPrograms returns the config of an orchestration service with the programs of defs added or
+// replaced, and its disabled programs removed, as the config had been written that way.
+func (m *Manager) applyPrograms(svc resource.Config, defs []Definition) (resource.Config, error) {
+	attrs, err := attributesOf(svc)
+	if err != nil {
+		return svc, err
+	}
+	attrs = maps.Clone(attrs)
+	if attrs == nil {
+		attrs = map[string]interface{}{}
+	}
+	for _, field := range []struct {
+		key  string
+		kind Kind
+	}{{"trees", KindTree}, {"state_machines", KindStateMachine}} {
+		programs, _ := attrs[field.key].([]interface{})
+		programs = slices.Clone(programs)
+		for _, def := range defs {
+			if def.Kind != field.kind {
+				continue
+			}
+			var program interface{} = def.Tree
+			if def.Kind == KindStateMachine {
+				program = def.StateMachine
+			}
+			var encoded map[string]interface{}
+			if err := convert(program, &encoded); err != nil {
+				return svc, err
+			}
+			if i := slices.IndexFunc(programs, func(p interface{}) bool { return programName(p) == def.Ref().Name }); i >= 0 {
+				programs[i] = encoded
+			} else {
+				programs = append(programs, encoded)
+			}
+		}
+		programs = slices.DeleteFunc(programs, func(p interface{}) bool {
+			return slices.Contains(m.state.Disabled, Ref{Kind: field.kind, Service: svc.Name, Name: programName(p)})
+		})
+		if len(programs) > 0 || attrs[field.key] != nil {
+			attrs[field.key] = programs
+		}
+	}
+
+	svc.Attributes = attrs
+	if reg, ok := resource.LookupRegistration(svc.API, svc.Model); ok && reg.AttributeMapConverter != nil {
+		converted, err := reg.AttributeMapConverter(attrs)
+		if err != nil {
+			return svc, err
+		}
+		svc.ConvertedAttributes = converted
+		// the programs may call resources the service did not depend on before.
+		required, optional, err := svc.Validate("", resource.APITypeServiceName)
+		if err != nil {
+			return svc, err
+		}
+		svc.ImplicitDependsOn = required
+		svc.ImplicitOptionalDependsOn = optional
+	}
+	return svc, nil
+}
+
+func programName(p interface{}) string {
+	m, _ := p.(map[string]interface{})
+	name, _ := m["name"].(string)
+	return name
+}
+
+// attributesOf returns the attributes of a service, which a service configured in code may only have
+// converted.
+func attributesOf(svc resource.Config) (map[string]interface{}, error) {
+	if svc.Attributes != nil || svc.ConvertedAttributes == nil {
+		return svc.Attributes, nil
+	}
+	var attrs map[string]interface{}
+	if err := convert(svc.ConvertedAttributes, &attrs); err != nil {
+		return nil, err
+	}
+	return attrs, nil
+}
+
+// convert converts between equivalent forms of a config through JSON.
+func convert(from, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+// A Controller applies the definitions of a Manager to a robot and runs them.
+type Controller interface {
+	Automations() *Manager
+	// ApplyAutomations reconfigures the robot with the definitions of its Manager applied.
+	ApplyAutomations(ctx context.Context) error
+	// RunAutomation runs a job, or starts a program, once now.
+	RunAutomation(ctx context.Context, ref Ref) error
+}
+
+type service struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	controller Controller
+}
+
+// NewService returns the resource through which the automation of the robot behind controller is
+// edited, which the robot serves as PublicServiceName.
+func NewService(controller Controller) resource.Resource {
+	return &service{Named: PublicServiceName.AsNamed(), controller: controller}
+}
+
+func decodeCommand[T any](key string, v interface{}) (T, error) {
+	var zero T
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return zero, errors.Errorf("expected %s to be a map but got %T", key, v)
+	}
+	decoded, err := resource.DecodeDoCommand[T](m)
+	if err != nil {
+		return zero, errors.Wrapf(err, "invalid %s command", key)
+	}
+	return decoded, nil
+}
+
+func (svc *service) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	manager := svc.controller.Automations()
+	var err error
+	switch {
+	case cmd[DoList] != nil:
+	case cmd[DoValidate] != nil:
+		var def Definition
+		if def, err = decodeCommand[Definition](DoValidate, cmd[DoValidate]); err == nil {
+			err = manager.Validate(def)
+		}
+	case cmd[DoCreate] != nil || cmd[DoUpdate] != nil:
+		key, change := DoCreate, manager.Create
+		if cmd[DoCreate] == nil {
+			key, change = DoUpdate, manager.Update
+		}
+		var def Definition
+		if def, err = decodeCommand[Definition](key, cmd[key]); err == nil {
+			if err = change(def); err == nil {
+				err = svc.controller.ApplyAutomations(ctx)
+			}
+		}
+	case cmd[DoDelete] != nil:
+		var ref Ref
+		if ref, err = decodeCommand[Ref](DoDelete, cmd[DoDelete]); err == nil {
+			if err = manager.Delete(ref); err == nil {
+				err = svc.controller.ApplyAutomations(ctx)
+			}
+		}
+	case cmd[DoEnable] != nil || cmd[DoDisable] != nil:
+		key, enabled := DoEnable, true
+		if cmd[DoEnable] == nil {
+			key, enabled = DoDisable, false
+		}
+		var ref Ref
+		if ref, err = decodeCommand[Ref](key, cmd[key]); err == nil {
+			if err = manager.SetEnabled(ref, enabled); err == nil {
+				err = svc.controller.ApplyAutomations(ctx)
+			}
+		}
+	case cmd[DoRunNow] != nil:
+		var ref Ref
+		if ref, err = decodeCommand[Ref](DoRunNow, cmd[DoRunNow]); err == nil {
+			if err = manager.Enabled(ref); err == nil {
+				err = svc.controller.RunAutomation(ctx, ref)
+			}
+		}
+	default:
+		return nil, resource.ErrDoUnimplemented
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := manager.List()
+	list := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		m, err := resource.EncodeDoCommand(&entry)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return map[string]interface{}{DoDefinitions: list}, nil
+}
@@ -0,0 +1,246 @@
+package automation
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/orchestration"
+	obuiltin "go.viam.com/rdk/services/orchestration/builtin"
+)
+
+func job(name, resourceName string) Definition {
+	return Definition{Kind: KindJob, Job: &config.JobConfig{JobConfigData: config.JobConfigData{
+		Name: name, Schedule: "5s", Resource: resourceName, Method: "DoCommand",
+	}}}
+}
+
+func tree(name, resourceName string) Definition {
+	return Definition{Kind: KindTree, Service: "orchestrator", Tree: &obuiltin.TreeConfig{
+		Name: name, Root: obuiltin.NodeConfig{
+			Type: "action", Resource: resourceName, Command: map[string]interface{}{"name": name},
+		},
+	}}
+}
+
+func baseConfig(t *testing.T) *config.Config {
+	t.Helper()
+	svc := resource.Config{
+		Name:  "orchestrator",
+		API:   orchestration.API,
+		Model: resource.DefaultServiceModel,
+		Attributes: map[string]interface{}{
+			"trees": []interface{}{map[string]interface{}{
+				"name": "patrol", "root": map[string]interface{}{
+					"type": "action", "resource": "base", "command": map[string]interface{}{"name": "patrol"},
+				},
+			}},
+		},
+	}
+	reg, ok := resource.LookupRegistration(svc.API, svc.Model)
+	test.That(t, ok, test.ShouldBeTrue)
+	converted, err := reg.AttributeMapConverter(svc.Attributes)
+	test.That(t, err, test.ShouldBeNil)
+	svc.ConvertedAttributes = converted
+	return &config.Config{
+		Jobs:     []config.JobConfig{*job("nightly", "arm").Job},
+		Services: []resource.Config{svc},
+	}
+}
+
+func refs(entries []Entry) []Ref {
+	out := make([]Ref, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry.Ref())
+	}
+	return out
+}
+
+func TestDefinitionValidate(t *testing.T) {
+	for _, def := range []Definition{job("a", "arm"), tree("a", "base")} {
+		test.That(t, def.Validate(), test.ShouldBeNil)
+	}
+	noService := tree("a", "base")
+	noService.Service = ""
+	wrongKind := job("a", "arm")
+	wrongKind.Kind = KindTree
+	both := job("a", "arm")
+	both.Tree = tree("a", "base").Tree
+	for _, def := range []Definition{
+		{Kind: KindJob},
+		job("", "arm"),
+		job("a", ""),
+		tree("a", ""),
+		noService,
+		wrongKind,
+		both,
+	} {
+		test.That(t, def.Validate(), test.ShouldNotBeNil)
+	}
+}
+
+func TestManager(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "automation", "part.json")
+	m, err := NewManager(path)
+	test.That(t, err, test.ShouldBeNil)
+	base := baseConfig(t)
+	m.SetConfig(base)
+
+	entries := m.List()
+	test.That(t, refs(entries), test.ShouldResemble, []Ref{
+		{Kind: KindJob, Name: "nightly"},
+		{Kind: KindTree, Service: "orchestrator", Name: "patrol"},
+	})
+	test.That(t, entries[0].Source, test.ShouldEqual, SourceConfig)
+	test.That(t, entries[0].Enabled, test.ShouldBeTrue)
+
+	test.That(t, m.Create(job("nightly", "gripper")), test.ShouldNotBeNil)
+	test.That(t, m.Update(job("hourly", "gripper")), test.ShouldNotBeNil)
+	unknownService := tree("inspect", "camera")
+	unknownService.Service = "other"
+	test.That(t, m.Create(unknownService), test.ShouldNotBeNil)
+
+	test.That(t, m.Create(job("hourly", "gripper")), test.ShouldBeNil)
+	test.That(t, m.Update(job("nightly", "gripper")), test.ShouldBeNil)
+	test.That(t, m.Create(tree("inspect", "camera")), test.ShouldBeNil)
+	test.That(t, m.SetEnabled(Ref{Kind: KindTree, Service: "orchestrator", Name: "patrol"}, false), test.ShouldBeNil)
+	test.That(t, m.SetEnabled(Ref{Kind: KindJob, Name: "missing"}, false), test.ShouldNotBeNil)
+
+	entries = m.List()
+	test.That(t, refs(entries), test.ShouldResemble, []Ref{
+		{Kind: KindJob, Name: "hourly"},
+		{Kind: KindJob, Name: "nightly"},
+		{Kind: KindTree, Service: "orchestrator", Name: "inspect"},
+		{Kind: KindTree, Service: "orchestrator", Name: "patrol"},
+	})
+	test.That(t, entries[1].Source, test.ShouldEqual, SourceRuntime)
+	test.That(t, entries[1].Overrides, test.ShouldBeTrue)
+	test.That(t, entries[1].Job.Resource, test.ShouldEqual, "gripper")
+	test.That(t, entries[3].Enabled, test.ShouldBeFalse)
+	test.That(t, m.Enabled(entries[3].Ref()), test.ShouldNotBeNil)
+	test.That(t, m.Enabled(entries[2].Ref()), test.ShouldBeNil)
+
+	cfg, err := m.Config()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cfg.Jobs, test.ShouldHaveLength, 2)
+	test.That(t, cfg.Jobs[0].Resource, test.ShouldEqual, "gripper")
+	test.That(t, cfg.Jobs[1].Name, test.ShouldEqual, "hourly")
+	programs, ok := cfg.Services[0].ConvertedAttributes.(*obuiltin.Config)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, programs.Trees, test.ShouldHaveLength, 1)
+	test.That(t, programs.Trees[0].Name, test.ShouldEqual, "inspect")
+	test.That(t, cfg.Services[0].ImplicitDependsOn, test.ShouldResemble, []string{"camera"})
+
+	// the config the changes are applied to is left alone.
+	test.That(t, base.Jobs[0].Resource, test.ShouldEqual, "arm")
+	test.That(t, base.Services[0].Attributes["trees"], test.ShouldHaveLength, 1)
+
+	// changes are reloaded from the file.
+	reloaded, err := NewManager(path)
+	test.That(t, err, test.ShouldBeNil)
+	reloaded.SetConfig(base)
+	test.That(t, reloaded.List(), test.ShouldResemble, entries)
+
+	test.That(t, m.Delete(Ref{Kind: KindTree, Service: "orchestrator", Name: "patrol"}), test.ShouldNotBeNil)
+	test.That(t, m.Delete(Ref{Kind: KindJob, Name: "nightly"}), test.ShouldBeNil)
+	test.That(t, m.Delete(Ref{Kind: KindJob, Name: "hourly"}), test.ShouldBeNil)
+	test.That(t, m.SetEnabled(Ref{Kind: KindTree, Service: "orchestrator", Name: "patrol"}, true), test.ShouldBeNil)
+	cfg, err = m.Config()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cfg.Jobs, test.ShouldResemble, base.Jobs)
+	programs = cfg.Services[0].ConvertedAttributes.(*obuiltin.Config)
+	test.That(t, programs.Trees, test.ShouldHaveLength, 2)
+}
+
+type fakeController struct {
+	manager *Manager
+	applied int
+	ran     []Ref
+}
+
+func (c *fakeController) Automations() *Manager {
+	return c.manager
+}
+
+func (c *fakeController) ApplyAutomations(ctx context.Context) error {
+	c.applied++
+	return nil
+}
+
+func (c *fakeController) RunAutomation(ctx context.Context, ref Ref) error {
+	c.ran = append(c.ran, ref)
+	return nil
+}
+
+func TestDoCommand(t *testing.T) {
+	ctx := context.Background()
+	m, err := NewManager("")
+	test.That(t, err, test.ShouldBeNil)
+	m.SetConfig(baseConfig(t))
+	controller := &fakeController{manager: m}
+	svc := NewService(controller)
+	test.That(t, svc.Name(), test.ShouldResemble, PublicServiceName)
+
+	do := func(cmd map[string]interface{}) ([]Entry, error) {
+		resp, err := svc.DoCommand(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		list, ok := resp[DoDefinitions].([]interface{})
+		test.That(t, ok, test.ShouldBeTrue)
+		entries := make([]Entry, 0, len(list))
+		for _, v := range list {
+			entry, err := resource.DecodeDoCommand[Entry](v.(map[string]interface{}))
+			test.That(t, err, test.ShouldBeNil)
+			entries = append(entries, entry)
+		}
+		return entries, nil
+	}
+	encode := func(v interface{}) map[string]interface{} {
+		m, err := resource.EncodeDoCommand(v)
+		test.That(t, err, test.ShouldBeNil)
+		return m
+	}
+
+	entries, err := do(map[string]interface{}{DoList: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 2)
+
+	_, err = do(map[string]interface{}{DoValidate: encode(job("", "arm"))})
+	test.That(t, err, test.ShouldNotBeNil)
+	entries, err = do(map[string]interface{}{DoValidate: encode(job("hourly", "arm"))})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 2)
+	test.That(t, controller.applied, test.ShouldEqual, 0)
+
+	entries, err = do(map[string]interface{}{DoCreate: encode(job("hourly", "arm"))})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 3)
+	test.That(t, controller.applied, test.ShouldEqual, 1)
+
+	ref := Ref{Kind: KindJob, Name: "hourly"}
+	entries, err = do(map[string]interface{}{DoDisable: encode(ref)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries[0].Enabled, test.ShouldBeFalse)
+	_, err = do(map[string]interface{}{DoRunNow: encode(ref)})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = do(map[string]interface{}{DoEnable: encode(ref)})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = do(map[string]interface{}{DoRunNow: encode(ref)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, controller.ran, test.ShouldResemble, []Ref{ref})
+
+	entries, err = do(map[string]interface{}{DoDelete: encode(ref)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 2)
+	test.That(t, controller.applied, test.ShouldEqual, 4)
+
+	_, err = do(map[string]interface{}{DoDelete: map[string]interface{}{"kind": "job"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+}
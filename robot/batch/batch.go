@@ -0,0 +1,195 @@
+// Package batch runs batches of read-only calls to a robot's resources concurrently, so that
+// clients such as dashboards can read many resources in one round trip rather than one per
+// resource, which matters over high-latency connections.
+//
+// The robot proto has no batch RPC, so the robot serves batches over a BatchService of its own
+// whose requests and responses are google.protobuf.Structs.
+package batch
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/gantry"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// The read-only methods that can be batched.
+const (
+	// MethodReadings calls Readings of a sensor.
+	MethodReadings = "readings"
+	// MethodPosition gets the position of a movement sensor as "position" and "altitude" like its
+	// readings, of a motor in revolutions as "position", of a servo in degrees as "position_deg",
+	// of a gantry in millimeters as "positions_mm", or of an arm's end as "position" in
+	// millimeters and "orientation".
+	MethodPosition = "position"
+	// MethodImages gets the images of a camera as "images", each with its "source_name",
+	// "mime_type" and base64-encoded "data".
+	MethodImages = "images"
+)
+
+// MaxCalls is the most calls a batch may hold.
+const MaxCalls = 256
+
+// maxConcurrentCalls is the most calls of a batch run at once.
+const maxConcurrentCalls = 16
+
+// A Call is a read-only call to a resource.
+type Call struct {
+	Name   resource.Name
+	Method string
+	Extra  map[string]interface{}
+}
+
+// Validate ensures the call can be made.
+func (c Call) Validate() error {
+	if c.Name.Name == "" {
+		return errors.New("batch calls must name a resource")
+	}
+	switch c.Method {
+	case MethodReadings, MethodPosition, MethodImages:
+		return nil
+	default:
+		return errors.Errorf("unknown batch method %q", c.Method)
+	}
+}
+
+// A Result is what a Call returned, or why it failed.
+type Result struct {
+	Value map[string]interface{}
+	Err   error
+}
+
+// Execute makes the calls to the resources of r concurrently and returns their results in the
+// same order. A call failing does not fail the others; only a batch that cannot be made at all
+// returns an error.
+func Execute(ctx context.Context, r robot.Robot, calls []Call) ([]Result, error) {
+	if len(calls) > MaxCalls {
+		return nil, errors.Errorf("batches may hold at most %d calls but got %d", MaxCalls, len(calls))
+	}
+	for i, c := range calls {
+		if err := c.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "call %d", i)
+		}
+	}
+
+	results := make([]Result, len(calls))
+	sem := make(chan struct{}, maxConcurrentCalls)
+	var wg sync.WaitGroup
+	for i, c := range calls {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		utils.PanicCapturingGo(func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			value, err := call(ctx, r, c)
+			results[i] = Result{Value: value, Err: err}
+		})
+	}
+	wg.Wait()
+	return results, nil
+}
+
+func call(ctx context.Context, r robot.Robot, c Call) (map[string]interface{}, error) {
+	res, err := r.ResourceByName(c.Name)
+	if err != nil {
+		return nil, err
+	}
+	switch c.Method {
+	case MethodReadings:
+		s, ok := res.(resource.Sensor)
+		if !ok {
+			return nil, errors.Errorf("%s does not have readings", c.Name)
+		}
+		return s.Readings(ctx, c.Extra)
+	case MethodPosition:
+		return position(ctx, res, c.Extra)
+	case MethodImages:
+		cam, ok := res.(camera.Camera)
+		if !ok {
+			return nil, errors.Errorf("%s is not a camera", c.Name)
+		}
+		return images(ctx, cam, c.Extra)
+	default:
+		return nil, errors.Errorf("unknown batch method %q", c.Method)
+	}
+}
+
+func position(ctx context.Context, res resource.Resource, extra map[string]interface{}) (map[string]interface{}, error) {
+	switch res := res.(type) {
+	case movementsensor.MovementSensor:
+		point, alt, err := res.Position(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"position": point, "altitude": alt}, nil
+	case motor.Motor:
+		revs, err := res.Position(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"position": revs}, nil
+	case servo.Servo:
+		deg, err := res.Position(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"position_deg": float64(deg)}, nil
+	case gantry.Gantry:
+		mm, err := res.Position(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		positions := make([]interface{}, 0, len(mm))
+		for _, p := range mm {
+			positions = append(positions, p)
+		}
+		return map[string]interface{}{"positions_mm": positions}, nil
+	case arm.Arm:
+		pose, err := res.EndPosition(ctx, extra)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"position": pose.Point(), "orientation": pose.Orientation()}, nil
+	default:
+		return nil, fmt.Errorf("%s does not have a position", res.Name())
+	}
+}
+
+func images(ctx context.Context, cam camera.Camera, extra map[string]interface{}) (map[string]interface{}, error) {
+	namedImages, _, err := cam.Images(ctx, nil, extra)
+	if err != nil {
+		return nil, err
+	}
+	imgs := make([]interface{}, 0, len(namedImages))
+	for _, img := range namedImages {
+		data, err := img.Bytes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		imgs = append(imgs, map[string]interface{}{
+			"source_name": img.SourceName,
+			"mime_type":   img.MimeType(),
+			"data":        base64.StdEncoding.EncodeToString(data),
+		})
+	}
+	return map[string]interface{}{"images": imgs}, nil
+}
@@ -0,0 +1,181 @@
+package batch
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func newTestRobot() *inject.Robot {
+	s := inject.NewSensor("s")
+	s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"temp": 21.5, "extra": extra["unit"]}, nil
+	}
+	gps := inject.NewMovementSensor("gps")
+	gps.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return geo.NewPoint(40, -74), 10, nil
+	}
+	m := inject.NewMotor("m")
+	m.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (float64, error) {
+		return 0, errors.New("no encoder")
+	}
+	a := inject.NewArm("a")
+	a.EndPositionFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+		return spatialmath.NewPoseFromPoint(r3.Vector{X: 1, Y: 2, Z: 3}), nil
+	}
+	cam := inject.NewCamera("cam")
+	cam.ImagesFunc = func(
+		ctx context.Context,
+		filterSourceNames []string,
+		extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		img, err := camera.NamedImageFromBytes([]byte("jpeg"), "color", "image/jpeg", data.Annotations{})
+		return []camera.NamedImage{img}, resource.ResponseMetadata{}, err
+	}
+	resources := map[resource.Name]resource.Resource{
+		sensor.Named("s"):           s,
+		movementsensor.Named("gps"): gps,
+		motor.Named("m"):            m,
+		arm.Named("a"):              a,
+		camera.Named("cam"):         cam,
+	}
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		res, ok := resources[name]
+		if !ok {
+			return nil, resource.NewNotFoundError(name)
+		}
+		return res, nil
+	}
+	return r
+}
+
+var testCalls = []Call{
+	{Name: sensor.Named("s"), Method: MethodReadings, Extra: map[string]interface{}{"unit": "c"}},
+	{Name: movementsensor.Named("gps"), Method: MethodPosition},
+	{Name: motor.Named("m"), Method: MethodPosition},
+	{Name: arm.Named("a"), Method: MethodPosition},
+	{Name: camera.Named("cam"), Method: MethodImages},
+	{Name: camera.Named("cam"), Method: MethodPosition},
+	{Name: sensor.Named("missing"), Method: MethodReadings},
+}
+
+func checkResults(t *testing.T, results []Result) {
+	t.Helper()
+	test.That(t, results, test.ShouldHaveLength, len(testCalls))
+	test.That(t, results[0].Err, test.ShouldBeNil)
+	test.That(t, results[0].Value, test.ShouldResemble, map[string]interface{}{"temp": 21.5, "extra": "c"})
+
+	test.That(t, results[1].Err, test.ShouldBeNil)
+	point, ok := results[1].Value["position"].(*geo.Point)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, point.Lat(), test.ShouldEqual, 40)
+	test.That(t, results[1].Value["altitude"], test.ShouldEqual, 10.)
+
+	test.That(t, results[2].Err, test.ShouldBeError, errors.New("no encoder"))
+
+	test.That(t, results[3].Err, test.ShouldBeNil)
+	test.That(t, results[3].Value["position"], test.ShouldResemble, r3.Vector{X: 1, Y: 2, Z: 3})
+
+	test.That(t, results[4].Err, test.ShouldBeNil)
+	imgs, ok := results[4].Value["images"].([]interface{})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, imgs, test.ShouldHaveLength, 1)
+	img := imgs[0].(map[string]interface{})
+	test.That(t, img["source_name"], test.ShouldEqual, "color")
+	test.That(t, img["mime_type"], test.ShouldEqual, "image/jpeg")
+	test.That(t, img["data"], test.ShouldEqual, base64.StdEncoding.EncodeToString([]byte("jpeg")))
+
+	test.That(t, results[5].Err, test.ShouldNotBeNil)
+	test.That(t, results[6].Err, test.ShouldNotBeNil)
+}
+
+func TestExecute(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRobot()
+
+	_, err := Execute(ctx, r, []Call{{Name: sensor.Named("s"), Method: "move"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = Execute(ctx, r, make([]Call, MaxCalls+1))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	results, err := Execute(ctx, r, testCalls)
+	test.That(t, err, test.ShouldBeNil)
+	checkResults(t, results)
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	gServer := grpc.NewServer()
+	gServer.RegisterService(&ServiceDesc, NewServer(newTestRobot()))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+
+	results, err := Query(ctx, conn, testCalls)
+	test.That(t, err, test.ShouldBeNil)
+	checkResults(t, results)
+
+	results, err = Query(ctx, conn, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, results, test.ShouldBeEmpty)
+	_, err = Query(ctx, conn, []Call{{Method: MethodReadings}})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestQueryAccessCheck(t *testing.T) {
+	ctx := context.Background()
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	// callers may only access the sensor s.
+	gServer := grpc.NewServer(grpc.UnaryInterceptor(func(
+		ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (any, error) {
+		return handler(resource.ContextWithAccessCheck(ctx, func(name string) error {
+			if name != "s" {
+				return status.Errorf(codes.PermissionDenied, "not allowed to access resource %q", name)
+			}
+			return nil
+		}), req)
+	}))
+	gServer.RegisterService(&ServiceDesc, NewServer(newTestRobot()))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+
+	results, err := Query(ctx, conn, testCalls[:1])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, results[0].Err, test.ShouldBeNil)
+
+	_, err = Query(ctx, conn, testCalls[:2])
+	test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"gps"`)
+}
@@ -0,0 +1,189 @@
+package batch
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	vprotoutils "go.viam.com/utils/protoutils"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/protoutils"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+const (
+	serviceName = "rdk.robot.batch.v1.BatchService"
+	queryMethod = "/" + serviceName + "/Query"
+)
+
+// A Server serves batches of calls. Requests hold "calls", each with the "name" of a resource,
+// its "method" and optional "extra". Responses hold "results" with one per call, each with its "value"
+// or its "error".
+type Server interface {
+	Query(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// ServiceDesc describes the BatchService for registering a Server with an rpc.Server.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Server)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Query", Handler: queryHandler},
+	},
+	Metadata: "rdk/robot/batch",
+}
+
+//nolint:revive // the signature of gRPC method handlers puts the server before the context.
+func queryHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Server).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: queryMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Server).Query(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+type server struct {
+	r robot.Robot
+}
+
+// NewServer returns a Server of the resources of r.
+func NewServer(r robot.Robot) Server {
+	return &server{r: r}
+}
+
+func (s *server) Query(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	calls, err := callsFromProto(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range calls {
+		if err := resource.CheckAccess(ctx, c.Name); err != nil {
+			return nil, err
+		}
+	}
+	results, err := Execute(ctx, s.r, calls)
+	if err != nil {
+		return nil, err
+	}
+	return resultsToProto(results), nil
+}
+
+// Query makes the calls to the resources of the robot at the other end of conn in one round trip,
+// returning their results in the same order. Values are converted as readings are, so geo points,
+// vectors and orientations keep their types.
+func Query(ctx context.Context, conn grpc.ClientConnInterface, calls []Call) ([]Result, error) {
+	if len(calls) > MaxCalls {
+		return nil, errors.Errorf("batches may hold at most %d calls but got %d", MaxCalls, len(calls))
+	}
+	req, err := callsToProto(calls)
+	if err != nil {
+		return nil, err
+	}
+	resp := new(structpb.Struct)
+	if err := conn.Invoke(ctx, queryMethod, req, resp); err != nil {
+		return nil, err
+	}
+	results, err := resultsFromProto(resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(calls) {
+		return nil, errors.Errorf("expected %d batch results but got %d", len(calls), len(results))
+	}
+	return results, nil
+}
+
+func callsToProto(calls []Call) (*structpb.Struct, error) {
+	values := make([]*structpb.Value, 0, len(calls))
+	for i, c := range calls {
+		if err := c.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "call %d", i)
+		}
+		fields := map[string]*structpb.Value{
+			"name":   structpb.NewStringValue(c.Name.String()),
+			"method": structpb.NewStringValue(c.Method),
+		}
+		if c.Extra != nil {
+			extra, err := vprotoutils.StructToStructPb(c.Extra)
+			if err != nil {
+				return nil, err
+			}
+			fields["extra"] = structpb.NewStructValue(extra)
+		}
+		values = append(values, structpb.NewStructValue(&structpb.Struct{Fields: fields}))
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"calls": structpb.NewListValue(&structpb.ListValue{Values: values}),
+	}}, nil
+}
+
+func callsFromProto(req *structpb.Struct) ([]Call, error) {
+	values := req.GetFields()["calls"].GetListValue().GetValues()
+	calls := make([]Call, 0, len(values))
+	for i, v := range values {
+		fields := v.GetStructValue().GetFields()
+		name, err := resource.NewFromString(fields["name"].GetStringValue())
+		if err != nil {
+			return nil, errors.Wrapf(err, "call %d", i)
+		}
+		c := Call{Name: name, Method: fields["method"].GetStringValue()}
+		if extra := fields["extra"].GetStructValue(); extra != nil {
+			c.Extra = extra.AsMap()
+		}
+		calls = append(calls, c)
+	}
+	return calls, nil
+}
+
+func resultsToProto(results []Result) *structpb.Struct {
+	values := make([]*structpb.Value, 0, len(results))
+	for _, res := range results {
+		fields := map[string]*structpb.Value{}
+		if res.Err != nil {
+			fields["error"] = structpb.NewStringValue(res.Err.Error())
+		} else {
+			value, err := protoutils.ReadingGoToProto(res.Value)
+			if err != nil {
+				fields["error"] = structpb.NewStringValue(err.Error())
+			} else {
+				fields["value"] = structpb.NewStructValue(&structpb.Struct{Fields: value})
+			}
+		}
+		values = append(values, structpb.NewStructValue(&structpb.Struct{Fields: fields}))
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"results": structpb.NewListValue(&structpb.ListValue{Values: values}),
+	}}
+}
+
+func resultsFromProto(resp *structpb.Struct) ([]Result, error) {
+	values := resp.GetFields()["results"].GetListValue().GetValues()
+	results := make([]Result, 0, len(values))
+	for _, v := range values {
+		fields := v.GetStructValue().GetFields()
+		if msg, ok := fields["error"]; ok {
+			results = append(results, Result{Err: errors.New(msg.GetStringValue())})
+			continue
+		}
+		value, err := protoutils.ReadingProtoToGo(fields["value"].GetStructValue().GetFields())
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, Result{Value: value})
+	}
+	return results, nil
+}
@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// keys of the metadata held by a metadataCache.
+const (
+	cacheKeyFrameSystemConfig = "frame_system_config"
+	cacheKeyModels            = "models_from_modules"
+	cacheKeyCloudMetadata     = "cloud_metadata"
+)
+
+// metadataCache holds metadata of the robot that only changes when its config does, so that it is
+// not asked for again until the config revision reported by MachineStatus changes.
+type metadataCache struct {
+	revalidateEvery time.Duration
+
+	mu          sync.Mutex
+	revision    string
+	validatedAt time.Time
+	// generation is incremented whenever entries are dropped, so that a value fetched before then is
+	// not cached after.
+	generation uint64
+	entries    map[string]interface{}
+}
+
+func newMetadataCache(revalidateEvery time.Duration) *metadataCache {
+	return &metadataCache{revalidateEvery: revalidateEvery, entries: map[string]interface{}{}}
+}
+
+func (c *metadataCache) get(key string) (interface{}, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.entries[key]
+	return value, c.generation, ok
+}
+
+func (c *metadataCache) set(key string, value interface{}, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation == c.generation {
+		c.entries[key] = value
+	}
+}
+
+// invalidate drops every entry, such as when the client connects to what may be a restarted robot.
+func (c *metadataCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropLocked()
+	c.revision = ""
+	c.validatedAt = time.Time{}
+}
+
+func (c *metadataCache) dropLocked() {
+	c.generation++
+	clear(c.entries)
+}
+
+// observeRevision records the config revision the robot reported, dropping every entry if it
+// changed. It returns whether it changed from a revision observed before.
+func (c *metadataCache) observeRevision(revision string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.validatedAt = time.Now()
+	if revision == c.revision {
+		return false
+	}
+	changed := c.revision != ""
+	c.revision = revision
+	c.dropLocked()
+	return changed
+}
+
+// needsRevalidation returns whether the revision is due to be checked again.
+func (c *metadataCache) needsRevalidation() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.revalidateEvery > 0 && time.Since(c.validatedAt) >= c.revalidateEvery
+}
+
+// cachedMetadata returns the metadata held under key if the client caches metadata and the config
+// revision of the robot has not changed since it was fetched, and otherwise fetches it. The
+// revision is checked, through MachineStatus, at most every revalidateEvery; should that fail, the
+// metadata held is returned as is.
+func cachedMetadata[T any](ctx context.Context, rc *RobotClient, key string, fetch func(context.Context) (T, error)) (T, error) {
+	cache := rc.metadataCache
+	if cache == nil {
+		return fetch(ctx)
+	}
+	if cache.needsRevalidation() {
+		if _, err := rc.MachineStatus(ctx); err != nil {
+			rc.Logger().CDebugw(ctx, "failed to check the config revision of remote, using cached metadata",
+				"error", err, "address", rc.address())
+		}
+	}
+	value, generation, ok := cache.get(key)
+	if ok {
+		return value.(T), nil
+	}
+	fetched, err := fetch(ctx)
+	if err != nil {
+		return fetched, err
+	}
+	cache.set(key, fetched, generation)
+	return fetched, nil
+}
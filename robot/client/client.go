@@ -3,9 +3,11 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -36,6 +38,7 @@ import (
 	"google.golang.org/grpc/metadata"
 	reflectpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -50,11 +53,22 @@ import (
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/alerts"
+	"go.viam.com/rdk/robot/arbiter"
+	"go.viam.com/rdk/robot/automation"
+	"go.viam.com/rdk/robot/batch"
+	"go.viam.com/rdk/robot/estop"
 	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/robot/maintenance"
 	"go.viam.com/rdk/robot/packages"
+	"go.viam.com/rdk/robot/rollout"
+	"go.viam.com/rdk/robot/support"
+	"go.viam.com/rdk/robot/synccapture"
+	"go.viam.com/rdk/robot/tunnels"
 	"go.viam.com/rdk/session"
 	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/tunnel"
+	rutils "go.viam.com/rdk/utils"
 	"go.viam.com/rdk/utils/contextutils"
 	viammetadata "go.viam.com/rdk/utils/contextutils/metadata"
 	nc "go.viam.com/rdk/web/networkcheck"
@@ -91,9 +105,32 @@ var (
 type RobotClient struct {
 	resource.Named
 	remoteName  string
-	address     string
 	dialOptions []rpc.DialOption
 
+	// addresses are the addresses the robot is reachable at, most preferred first. activeAddress
+	// indexes the one the client is connected to, or last connected to.
+	addresses           []string
+	activeAddress       atomic.Int32
+	failoverThreshold   int
+	failbackEvery       time.Duration
+	failoverDialTimeout time.Duration
+
+	// clockOffset is the latest estimate of the clock offset of the robot, made every clockSyncEvery,
+	// or nil if it has not been estimated.
+	clockSyncEvery time.Duration
+	clockOffset    atomic.Pointer[clockOffset]
+
+	// reconnectEvery is how often the client tries to reconnect after losing its connection, or 0
+	// if it does not. onDegraded is told when the connection is lost and when it is restored, and
+	// degraded is whether it is lost.
+	reconnectEvery time.Duration
+	onDegraded     func(degraded bool, err error)
+	degraded       atomic.Bool
+
+	// metadataCache holds metadata of the robot until its config changes, or is nil if metadata
+	// is not cached.
+	metadataCache *metadataCache
+
 	// resourceRPCAPIs is guarded behind an atomic pointer instead of mu. This is because
 	// safety-monitoring logic in viam-server needs to access this field for remote clients
 	// on every incoming gRPC request. We do not want to wait for background work like
@@ -108,6 +145,7 @@ type RobotClient struct {
 	remoteNameMap            map[resource.Name]resource.Name
 	changeChan               chan bool
 	notifyParent             func()
+	reconnected              chan struct{} // closed on reconnecting; nil while connected
 	conn                     grpc.ReconfigurableClientConn
 	client                   pb.RobotServiceClient
 	refClient                *grpcreflect.Client
@@ -131,11 +169,11 @@ type RobotClient struct {
 	heartbeatCtx       context.Context
 	heartbeatCtxCancel func()
 
-	// If we ever connect to a server using webrtc, we want all subsequent connections to force
-	// webrtc. Some operations such as video streaming are much more performant when using
-	// webrtc. We don't want a network disconnect to result in reconnecting over tcp such that
-	// performance would be impacted.
-	serverIsWebrtcEnabled bool
+	// If we ever connect to an address using webrtc, we want all subsequent connections to that
+	// address to force webrtc. Some operations such as video streaming are much more performant
+	// when using webrtc. We don't want a network disconnect to result in reconnecting over tcp
+	// such that performance would be impacted. Guarded by mu.
+	webrtcEnabledAddresses map[string]bool
 
 	pc         *webrtc.PeerConnection
 	sharedConn *grpc.SharedConn
@@ -199,7 +237,12 @@ func isDisconnectedError(err error) bool {
 }
 
 func (rc *RobotClient) notConnectedToRemoteError() error {
-	return fmt.Errorf("not connected to remote robot at %s", rc.address)
+	return fmt.Errorf("not connected to remote robot at %s", rc.address())
+}
+
+// address returns the address the client is connected to, or last connected to.
+func (rc *RobotClient) address() string {
+	return rc.addresses[rc.activeAddress.Load()]
 }
 
 func isResourceExhaustedError(err error) bool {
@@ -306,7 +349,12 @@ func New(ctx context.Context, address string, clientLogger logging.ZapCompatible
 	rc := &RobotClient{
 		Named:               resource.NewName(RemoteAPI, rOpts.remoteName).AsNamed(),
 		remoteName:          rOpts.remoteName,
-		address:             address,
+		addresses:           append([]string{address}, rOpts.failoverAddresses...),
+		failoverThreshold:   max(rOpts.failoverThreshold, 1),
+		failbackEvery:       time.Minute,
+		failoverDialTimeout: 10 * time.Second,
+		clockSyncEvery:      rOpts.clockSyncEvery,
+		onDegraded:          rOpts.onDegraded,
 		backgroundCtx:       backgroundCtx,
 		backgroundCtxCancel: backgroundCtxCancel,
 		logger:              logger,
@@ -319,6 +367,15 @@ func New(ctx context.Context, address string, clientLogger logging.ZapCompatible
 		heartbeatCtx:        heartbeatCtx,
 		heartbeatCtxCancel:  heartbeatCtxCancel,
 	}
+	if rOpts.failbackEvery != nil {
+		rc.failbackEvery = *rOpts.failbackEvery
+	}
+	if rOpts.failoverDialTimeout > 0 {
+		rc.failoverDialTimeout = rOpts.failoverDialTimeout
+	}
+	if rOpts.cacheMetadata {
+		rc.metadataCache = newMetadataCache(rOpts.metadataRevalidateEvery)
+	}
 
 	otelStatsHandler := otelgrpc.NewClientHandler(
 		otelgrpc.WithTracerProvider(trace.GetProvider()),
@@ -329,6 +386,8 @@ func New(ctx context.Context, address string, clientLogger logging.ZapCompatible
 	rc.dialOptions = append(
 		rc.dialOptions,
 		rpc.WithUnaryClientInterceptor(contextutils.ContextWithMetadataUnaryClientInterceptor),
+		// clock synchronization
+		rpc.WithUnaryClientInterceptor(rc.translateTimesUnaryClientInterceptor),
 		// error handling
 		rpc.WithUnaryClientInterceptor(rc.handleUnaryDisconnect),
 		rpc.WithStreamClientInterceptor(rc.handleStreamDisconnect),
@@ -445,6 +504,17 @@ func New(ctx context.Context, address string, clientLogger logging.ZapCompatible
 		return nil, multierr.Combine(err, rc.conn.Close())
 	}
 
+	if rc.clockSyncEvery > 0 {
+		if err := rc.estimateClockOffset(ctx); err != nil {
+			rc.Logger().CWarnw(ctx, "failed to estimate clock offset of remote, its times will not be translated until it is",
+				"error", err, "address", rc.address())
+		}
+		rc.activeBackgroundWorkers.Add(1)
+		utils.ManagedGo(func() {
+			rc.syncClockEvery(backgroundCtx, rc.clockSyncEvery)
+		}, rc.activeBackgroundWorkers.Done)
+	}
+
 	var refreshTime time.Duration
 	if rOpts.refreshEvery == nil {
 		refreshTime = 10 * time.Second
@@ -465,6 +535,7 @@ func New(ctx context.Context, address string, clientLogger logging.ZapCompatible
 	}
 
 	if checkConnectedTime > 0 && reconnectTime > 0 {
+		rc.reconnectEvery = reconnectTime
 		refresh := checkConnectedTime == refreshTime
 		rc.activeBackgroundWorkers.Add(1)
 		utils.ManagedGo(func() {
@@ -525,11 +596,12 @@ func (rc *RobotClient) Connect(ctx context.Context) error {
 	if err := rc.connectWithLock(ctx); err != nil {
 		return err
 	}
-	rc.Logger().CInfow(ctx, "successfully (re)connected to remote at address", "address", rc.address)
+	rc.Logger().CInfow(ctx, "successfully (re)connected to remote at address", "address", rc.address())
 	if rc.notifyParent != nil {
 		rc.notifyParent()
-		rc.Logger().CDebugw(ctx, "successfully notified parent after (re)connection", "address", rc.address)
+		rc.Logger().CDebugw(ctx, "successfully notified parent after (re)connection", "address", rc.address())
 	}
+	rc.markRestored(ctx)
 	return nil
 }
 
@@ -541,6 +613,97 @@ func (rc *RobotClient) connectWithLock(ctx context.Context) error {
 		return err
 	}
 
+	// Try the addresses in order of preference, failing over to the next when one cannot be
+	// connected to.
+	var errs error
+	for idx, address := range rc.addresses {
+		conn, isWebRTC, err := rc.dial(ctx, address, rc.webrtcEnabledAddresses[address])
+		if err == nil {
+			rc.recordWebRTCLocked(address, isWebRTC)
+			if idx > 0 {
+				rc.logger.CInfow(ctx, "failed over to remote address", "address", address, "preferred_address", rc.addresses[0])
+			}
+			rc.activeAddress.Store(int32(idx))
+			return rc.useConnLocked(ctx, conn)
+		}
+		errs = multierr.Append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+		if idx < len(rc.addresses)-1 {
+			rc.logger.CWarnw(ctx, "failed to connect to remote address, trying the next address",
+				"address", address, "error", err)
+		}
+	}
+	return errs
+}
+
+// recordWebRTCLocked forces all future connections to address to use webrtc once a webrtc
+// connection was made to it.
+func (rc *RobotClient) recordWebRTCLocked(address string, isWebRTC bool) {
+	if !isWebRTC || rc.webrtcEnabledAddresses[address] {
+		return
+	}
+	rc.logger.Info("A WebRTC connection was made to the robot.",
+		"Reconnects will disallow direct gRPC connections.")
+	if rc.webrtcEnabledAddresses == nil {
+		rc.webrtcEnabledAddresses = map[string]bool{}
+	}
+	rc.webrtcEnabledAddresses[address] = true
+}
+
+// failback connects to the most preferred address that is more preferred than the one the client
+// is connected to, and switches the client over to it. The current connection is kept if none of
+// them can be connected to.
+func (rc *RobotClient) failback(ctx context.Context) {
+	for idx, address := range rc.addresses[:rc.activeAddress.Load()] {
+		rc.mu.RLock()
+		webrtcOnly := rc.webrtcEnabledAddresses[address]
+		rc.mu.RUnlock()
+		// dial without holding mu so that the client stays usable over the current connection.
+		conn, isWebRTC, err := rc.dial(ctx, address, webrtcOnly)
+		if err != nil {
+			rc.Logger().CDebugw(ctx, "preferred remote address is still unreachable", "address", address, "error", err)
+			continue
+		}
+
+		rc.mu.Lock()
+		rc.recordWebRTCLocked(address, isWebRTC)
+		utils.UncheckedError(rc.conn.Close())
+		rc.activeAddress.Store(int32(idx))
+		err = rc.useConnLocked(ctx, conn)
+		notifyParentFn := rc.notifyParent
+		rc.mu.Unlock()
+		if err != nil {
+			rc.Logger().CErrorw(ctx, "failed to update resources after failing back to preferred remote address",
+				"error", err, "address", address)
+			return
+		}
+		rc.Logger().CInfow(ctx, "failed back to preferred remote address", "address", address)
+		if notifyParentFn != nil {
+			notifyParentFn()
+		}
+		return
+	}
+}
+
+// dial connects to an address of the robot over webrtc, falling back to a direct grpc connection
+// unless webrtcOnly is set. It returns whether the connection is over webrtc.
+func (rc *RobotClient) dial(ctx context.Context, address string, webrtcOnly bool) (rpc.ClientConn, bool, error) {
+	if len(rc.addresses) > 1 {
+		// do not wait long on an address that is down when there are others to fail over to.
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, rc.failoverDialTimeout)
+		defer cancel()
+	}
+
+	dialLogger := rc.logger.Sublogger("networking")
+	if rutils.IsUnixSocketAddress(address) {
+		// there is no WebRTC over a Unix domain socket, only gRPC.
+		conn, err := grpc.Dial(ctx, address, dialLogger, rc.dialOptions...)
+		return conn, false, err
+	}
+
 	// Try forcing a webrtc connection.
 	dialOptionsWebRTCOnly := make([]rpc.DialOption, len(rc.dialOptions)+1)
 	// Put our "disable GRPC" option in front and the user input values at the end. This ensures
@@ -548,17 +711,11 @@ func (rc *RobotClient) connectWithLock(ctx context.Context) error {
 	copy(dialOptionsWebRTCOnly[1:], rc.dialOptions)
 	dialOptionsWebRTCOnly[0] = rpc.WithDisableDirectGRPC()
 
-	dialLogger := rc.logger.Sublogger("networking")
-	conn, err := grpc.Dial(ctx, rc.address, dialLogger, dialOptionsWebRTCOnly...)
+	conn, err := grpc.Dial(ctx, address, dialLogger, dialOptionsWebRTCOnly...)
 	if err == nil {
-		// If we succeed with a webrtc connection, flip the `serverIsWebrtcEnabled` to force all future
-		// connections to use webrtc.
-		if !rc.serverIsWebrtcEnabled {
-			rc.logger.Info("A WebRTC connection was made to the robot.",
-				"Reconnects will disallow direct gRPC connections.")
-			rc.serverIsWebrtcEnabled = true
-		}
-	} else if !rc.serverIsWebrtcEnabled {
+		return conn, true, nil
+	}
+	if !webrtcOnly {
 		// If we failed to connect via webrtc and* we've never previously connected over webrtc, try
 		// to connect with a grpc over a tcp connection.
 		//
@@ -573,7 +730,7 @@ func (rc *RobotClient) connectWithLock(ctx context.Context) error {
 		// we add this flag to partially override the above override.
 		dialOptionsGRPCOnly[1] = rpc.WithDialMulticastDNSOptions(rpc.DialMulticastDNSOptions{Disable: false})
 
-		grpcConn, grpcErr := grpc.Dial(ctx, rc.address, dialLogger, dialOptionsGRPCOnly...)
+		grpcConn, grpcErr := grpc.Dial(ctx, address, dialLogger, dialOptionsGRPCOnly...)
 		if grpcErr == nil {
 			conn = grpcConn
 			err = nil
@@ -587,7 +744,7 @@ func (rc *RobotClient) connectWithLock(ctx context.Context) error {
 				statusErr.Code() == codes.NotFound &&
 				errors.Is(grpcErr, rpc.ErrMDNSNoCandidatesFound) &&
 				errors.Is(grpcErr, context.DeadlineExceeded) {
-				return err
+				return nil, false, err
 			}
 			// A context.DeadlineExceeded from the WebRTC dial implies the client is unable to reach
 			// the signaling server, which likely means that the client is offline. In that case, if the errors returned from
@@ -598,16 +755,20 @@ func (rc *RobotClient) connectWithLock(ctx context.Context) error {
 			if errors.Is(err, context.DeadlineExceeded) &&
 				errors.Is(grpcErr, context.DeadlineExceeded) &&
 				errors.Is(grpcErr, rpc.ErrMDNSNoCandidatesFound) {
-				return fmt.Errorf("failed to connect to machine within time limit. check network connection, whether the viam-server is running, " +
-					"and try again. see " + connTimeoutURL + " for troubleshooting steps")
+				return nil, false, fmt.Errorf("failed to connect to machine within time limit. check network connection, " +
+					"whether the viam-server is running, and try again. see " + connTimeoutURL + " for troubleshooting steps")
 			}
 			err = multierr.Combine(err, grpcErr)
 		}
 	}
 	if err != nil {
-		return err
+		return nil, false, err
 	}
+	return conn, false, nil
+}
 
+// useConnLocked makes conn the connection of the client.
+func (rc *RobotClient) useConnLocked(ctx context.Context, conn rpc.ClientConn) error {
 	client := pb.NewRobotServiceClient(conn)
 
 	refClient := grpcreflect.NewClientV1Alpha(rc.backgroundCtx, reflectpb.NewServerReflectionClient(conn))
@@ -616,6 +777,14 @@ func (rc *RobotClient) connectWithLock(ctx context.Context) error {
 	rc.client = client
 	rc.refClient = refClient
 	rc.connected.Store(true)
+	if rc.metadataCache != nil {
+		// the robot may have restarted with a different config.
+		rc.metadataCache.invalidate()
+	}
+	if rc.reconnected != nil {
+		close(rc.reconnected)
+		rc.reconnected = nil
+	}
 	if len(rc.resourceClients) != 0 {
 		if err := rc.updateResources(ctx); err != nil {
 			return err
@@ -652,6 +821,8 @@ func (rc *RobotClient) updateResourceClients(ctx context.Context) error {
 
 // checkConnection either checks if the client is still connected, or attempts to reconnect to the remote.
 func (rc *RobotClient) checkConnection(ctx context.Context, checkEvery, reconnectEvery time.Duration, refresh bool) {
+	var failedChecks int
+	lastFailback := time.Now()
 	for {
 		var waitTime time.Duration
 		if rc.connected.Load() {
@@ -668,11 +839,12 @@ func (rc *RobotClient) checkConnection(ctx context.Context, checkEvery, reconnec
 			return
 		}
 		if !rc.connected.Load() {
-			rc.Logger().CInfow(ctx, "trying to reconnect to remote at address", "address", rc.address)
+			rc.Logger().CInfow(ctx, "trying to reconnect to remote at address", "address", rc.address())
 			if err := rc.Connect(ctx); err != nil {
-				rc.Logger().CErrorw(ctx, "failed to reconnect remote", "error", err, "address", rc.address)
+				rc.Logger().CErrorw(ctx, "failed to reconnect remote", "error", err, "address", rc.address())
 				continue
 			}
+			lastFailback = time.Now()
 		} else {
 			check := func() error {
 				if refresh {
@@ -702,7 +874,15 @@ func (rc *RobotClient) checkConnection(ctx context.Context, checkEvery, reconnec
 				outerError = nil
 				break
 			}
-			if outerError != nil {
+			if outerError == nil {
+				failedChecks = 0
+				// while connected to a failover address, periodically try to fail back to a more
+				// preferred one.
+				if rc.activeAddress.Load() > 0 && rc.failbackEvery > 0 && time.Since(lastFailback) >= rc.failbackEvery {
+					lastFailback = time.Now()
+					rc.failback(ctx)
+				}
+			} else {
 				if isResourceExhaustedError(outerError) {
 					// The remote is reachable but is rate-limiting our health-check requests.
 					// The connection itself is healthy, so do not mark the client disconnected.
@@ -713,25 +893,39 @@ func (rc *RobotClient) checkConnection(ctx context.Context, checkEvery, reconnec
 						"connection health checks to remote are failing due to a request rate limit, "+
 							"likely caused by a different client or module; leaving connection up",
 						"error", outerError,
-						"address", rc.address,
+						"address", rc.address(),
 					)
 					continue
 				}
+				failedChecks++
+				if failedChecks < rc.failoverThreshold {
+					rc.Logger().CWarnw(ctx, "connection check to remote failed",
+						"error", outerError,
+						"address", rc.address(),
+						"failed_checks", failedChecks,
+						"failover_threshold", rc.failoverThreshold,
+					)
+					continue
+				}
+				failedChecks = 0
 				rc.Logger().CErrorw(ctx,
 					"lost connection to remote",
 					"error", outerError,
-					"address", rc.address,
+					"address", rc.address(),
 					"reconnect_interval", reconnectEvery.Seconds(),
 				)
 				rc.mu.Lock()
 				rc.connected.Store(false)
+				if rc.reconnected == nil {
+					rc.reconnected = make(chan struct{})
+				}
 				if rc.changeChan != nil {
 					rc.changeChan <- true
 				}
 
 				var notifyParentFn func()
 				if rc.notifyParent != nil {
-					rc.Logger().CDebugf(ctx, "connection was lost for remote %q", rc.address)
+					rc.Logger().CDebugf(ctx, "connection was lost for remote %q", rc.address())
 					// RSDK-3670: This callback may ultimately acquire the `robotClient.mu`
 					// mutex. Execute the function after releasing the mutex.
 					notifyParentFn = rc.notifyParent
@@ -740,6 +934,7 @@ func (rc *RobotClient) checkConnection(ctx context.Context, checkEvery, reconnec
 				if notifyParentFn != nil {
 					notifyParentFn()
 				}
+				rc.markDegraded(ctx, outerError)
 			}
 		}
 	}
@@ -802,6 +997,13 @@ func (rc *RobotClient) ResourceByName(name resource.Name) (resource.Resource, er
 	if name == framesystem.PublicServiceName {
 		return rc, nil
 	}
+	// the emergency stop, arbiter, tunnel manager, automation editor, alerts, maintenance mode, and
+	// staged rollout are served by every robot without being listed among its resources
+	if name == estop.PublicServiceName || name == arbiter.PublicServiceName || name == tunnels.PublicServiceName ||
+		name == automation.PublicServiceName || name == alerts.PublicServiceName || name == maintenance.PublicServiceName ||
+		name == rollout.PublicServiceName {
+		return rc.createClient(name)
+	}
 
 	rc.mu.RLock()
 
@@ -1021,6 +1223,14 @@ func (rc *RobotClient) Logger() logging.Logger {
 
 // GetModelsFromModules  returns the available models from the configured modules on a given machine.
 func (rc *RobotClient) GetModelsFromModules(ctx context.Context) ([]resource.ModuleModel, error) {
+	models, err := cachedMetadata(ctx, rc, cacheKeyModels, rc.getModelsFromModules)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(models), nil
+}
+
+func (rc *RobotClient) getModelsFromModules(ctx context.Context) ([]resource.ModuleModel, error) {
 	resp, err := rc.client.GetModelsFromModules(ctx, &pb.GetModelsFromModulesRequest{})
 	if err != nil {
 		return nil, err
@@ -1049,7 +1259,12 @@ func (rc *RobotClient) GetModelsFromModules(ctx context.Context) ([]resource.Mod
 //
 //	frameSystem, err := machine.FrameSystemConfig(context.Background(), nil)
 func (rc *RobotClient) FrameSystemConfig(ctx context.Context) (*framesystem.Config, error) {
-	resp, err := rc.client.FrameSystemConfig(ctx, &pb.FrameSystemConfigRequest{})
+	// the response is cached rather than the config so that every caller is given parts of its own,
+	// which it may modify.
+	resp, err := cachedMetadata(ctx, rc, cacheKeyFrameSystemConfig,
+		func(ctx context.Context) (*pb.FrameSystemConfigResponse, error) {
+			return rc.client.FrameSystemConfig(ctx, &pb.FrameSystemConfigRequest{})
+		})
 	if err != nil {
 		return nil, err
 	}
@@ -1240,12 +1455,14 @@ func (rc *RobotClient) Log(ctx context.Context, log zapcore.Entry, fields []zap.
 //
 //	metadata, err := machine.CloudMetadata(ctx.Background())
 func (rc *RobotClient) CloudMetadata(ctx context.Context) (cloud.Metadata, error) {
-	req := &pb.GetCloudMetadataRequest{}
-	resp, err := rc.client.GetCloudMetadata(ctx, req)
-	if err != nil {
-		return cloud.Metadata{}, err
-	}
-	return rprotoutils.MetadataFromProto(resp), nil
+	return cachedMetadata(ctx, rc, cacheKeyCloudMetadata, func(ctx context.Context) (cloud.Metadata, error) {
+		req := &pb.GetCloudMetadataRequest{}
+		resp, err := rc.client.GetCloudMetadata(ctx, req)
+		if err != nil {
+			return cloud.Metadata{}, err
+		}
+		return rprotoutils.MetadataFromProto(resp), nil
+	})
 }
 
 // RestartModule restarts a running module by name or ID.
@@ -1291,12 +1508,111 @@ func (rc *RobotClient) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+// StreamResourceStates calls onEvent with the lifecycle state of the robot's resources, limited
+// to the given resources if any are given, until the context is canceled, the stream ends, or
+// onEvent returns an error. It is first called with a snapshot of every resource's state, marked
+// with Snapshot, and then with each change. If every is positive, changes are batched and only the
+// latest change to each resource in each interval is sent. Should the robot drop changes because
+// they were not read quickly enough, it sends another snapshot. Should the connection to the robot
+// be lost, the stream is resubscribed to once the client reconnects, starting with another snapshot.
+func (rc *RobotClient) StreamResourceStates(
+	ctx context.Context,
+	names []resource.Name,
+	every time.Duration,
+	onEvent func(robot.ResourceStateEvent) error,
+) error {
+	req := &pb.StreamStatusRequest{ResourceNames: make([]*commonpb.ResourceName, 0, len(names))}
+	for _, name := range names {
+		req.ResourceNames = append(req.ResourceNames, rprotoutils.ResourceNameToProto(name))
+	}
+	if every > 0 {
+		req.Every = durationpb.New(every)
+	}
+	for {
+		streamErr, err := rc.streamResourceStates(ctx, req, onEvent)
+		if streamErr == nil || !isConnectionLostError(streamErr) || rc.reconnectEvery <= 0 || ctx.Err() != nil {
+			return err
+		}
+		rc.Logger().CDebugw(ctx, "resource state stream was cut off by a lost connection, resubscribing once reconnected",
+			"error", streamErr, "address", rc.address())
+		if err := rc.waitReconnected(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// streamResourceStates subscribes to the resource states once. Errors of the stream itself, which
+// may be resumed after, are also returned as streamErr, unlike errors of onEvent.
+func (rc *RobotClient) streamResourceStates(
+	ctx context.Context,
+	req *pb.StreamStatusRequest,
+	onEvent func(robot.ResourceStateEvent) error,
+) (streamErr, err error) {
+	stream, err := rc.client.StreamStatus(ctx, req)
+	if err != nil {
+		return err, err
+	}
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return err, err
+		}
+		for _, st := range resp.GetStatus() {
+			event, err := resourceStateEventFromProto(st)
+			if err != nil {
+				return nil, err
+			}
+			if err := onEvent(event); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func resourceStateEventFromProto(st *pb.Status) (robot.ResourceStateEvent, error) {
+	fields := st.GetStatus().AsMap()
+	event := robot.ResourceStateEvent{Name: rprotoutils.ResourceNameFromProto(st.GetName())}
+	event.Removed, _ = fields[robot.ResourceStateKeyRemoved].(bool)
+	event.Snapshot, _ = fields[robot.ResourceStateKeySnapshot].(bool)
+	event.Revision, _ = fields[robot.ResourceStateKeyRevision].(string)
+	if errMsg, ok := fields[robot.ResourceStateKeyError].(string); ok && errMsg != "" {
+		event.Error = errors.New(errMsg)
+	}
+	if ts, ok := fields[robot.ResourceStateKeyTime].(string); ok {
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return robot.ResourceStateEvent{}, err
+		}
+		event.Time = t
+	}
+	state, _ := fields[robot.ResourceStateKeyState].(string)
+	switch pb.ResourceStatus_State(pb.ResourceStatus_State_value[state]) {
+	case pb.ResourceStatus_STATE_UNSPECIFIED:
+		event.State = resource.NodeStateUnknown
+	case pb.ResourceStatus_STATE_UNCONFIGURED:
+		event.State = resource.NodeStateUnconfigured
+	case pb.ResourceStatus_STATE_CONFIGURING:
+		event.State = resource.NodeStateConfiguring
+	case pb.ResourceStatus_STATE_READY:
+		event.State = resource.NodeStateReady
+	case pb.ResourceStatus_STATE_REMOVING:
+		event.State = resource.NodeStateRemoving
+	case pb.ResourceStatus_STATE_UNHEALTHY:
+		event.State = resource.NodeStateUnhealthy
+	}
+	return event, nil
+}
+
 // MachineStatus returns the current status of the robot.
 func (rc *RobotClient) MachineStatus(ctx context.Context) (robot.MachineStatus, error) {
 	mStatus := robot.MachineStatus{}
 
 	req := &pb.GetMachineStatusRequest{}
-	resp, err := rc.client.GetMachineStatus(ctx, req)
+	var header metadata.MD
+	resp, err := rc.client.GetMachineStatus(ctx, req, googlegrpc.Header(&header))
 	if err != nil {
 		return mStatus, err
 	}
@@ -1307,6 +1623,13 @@ func (rc *RobotClient) MachineStatus(ctx context.Context) (robot.MachineStatus,
 			LastUpdated: resp.Config.LastUpdated.AsTime(),
 		}
 	}
+	if rc.metadataCache != nil && rc.metadataCache.observeRevision(mStatus.Config.Revision) {
+		// the resources of the robot likely changed with its config, so do not wait for the next
+		// refresh to learn of them.
+		if err := rc.Refresh(ctx); err != nil {
+			rc.Logger().CDebugw(ctx, "failed to refresh remote after its config changed", "error", err, "address", rc.address())
+		}
+	}
 
 	mStatus.Resources = make([]resource.Status, 0, len(resp.Resources))
 	for _, pbResStatus := range resp.Resources {
@@ -1396,6 +1719,22 @@ func (rc *RobotClient) MachineStatus(ctx context.Context) (robot.MachineStatus,
 		}
 	}
 
+	if values := header.Get(contextutils.RequestStatsMetadataKey); len(values) > 0 {
+		if err := json.Unmarshal([]byte(values[0]), &mStatus.RequestStats); err != nil {
+			rc.logger.CDebugw(ctx, "received invalid request stats", "error", err)
+		}
+	}
+	if values := header.Get(contextutils.AlertsMetadataKey); len(values) > 0 {
+		if err := json.Unmarshal([]byte(values[0]), &mStatus.Alerts); err != nil {
+			rc.logger.CDebugw(ctx, "received invalid alerts", "error", err)
+		}
+	}
+	if values := header.Get(contextutils.ConfigRevisionsMetadataKey); len(values) > 0 {
+		if err := json.Unmarshal([]byte(values[0]), &mStatus.ConfigRevisions); err != nil {
+			rc.logger.CDebugw(ctx, "received invalid config revisions", "error", err)
+		}
+	}
+
 	return mStatus, nil
 }
 
@@ -1415,6 +1754,28 @@ func (rc *RobotClient) Version(ctx context.Context) (robot.VersionResponse, erro
 	return mVersion, nil
 }
 
+// BatchQuery makes read-only calls to many resources of the machine, which runs them concurrently,
+// in one round trip. Results are in the same order as the calls, and a call failing does not fail
+// the others.
+func (rc *RobotClient) BatchQuery(ctx context.Context, calls []batch.Call) ([]batch.Result, error) {
+	return batch.Query(ctx, &rc.conn, calls)
+}
+
+// SupportBundle has the machine collect a support bundle of its recent logs, sanitized config,
+// resource and module statuses, module versions and, if requested, network diagnostics, and
+// returns it. The machine can also upload it via its data manager.
+func (rc *RobotClient) SupportBundle(ctx context.Context, opts support.Options) (support.Bundle, error) {
+	return support.CreateBundle(ctx, &rc.conn, opts)
+}
+
+// SynchronizedCapture has the machine capture a frame from each of many cameras at once, by hardware
+// trigger for the cameras that support it and by software barrier for the others. The frames are
+// returned in the order of the cameras, with their shared timestamp and how far apart they were
+// captured.
+func (rc *RobotClient) SynchronizedCapture(ctx context.Context, req synccapture.Request) (synccapture.Result, error) {
+	return synccapture.Query(ctx, &rc.conn, req)
+}
+
 // SendTraces sends OTLP spans to be recorded by viam server. It should only be
 // called from modules.
 func (rc *RobotClient) SendTraces(ctx context.Context, spans []*otlpv1.ResourceSpans) error {
@@ -1451,8 +1812,20 @@ func (rc *RobotClient) UploadDataFromPath(ctx context.Context, path string, md *
 // Tunnel tunnels data to/from the read writer from/to the destination port on the server. This
 // function will close the connection passed in as part of cleanup.
 func (rc *RobotClient) Tunnel(ctx context.Context, conn io.ReadWriteCloser, dest int) error {
+	return rc.tunnel(ctx, conn, dest, config.TunnelProtocolTCP)
+}
+
+// TunnelUDP tunnels datagrams to/from the read writer from/to the destination UDP port on the
+// server. Each read of conn must return, and each write to it is given, a single datagram, as with
+// a tunnel.PacketConn. This function will close the connection passed in as part of cleanup.
+func (rc *RobotClient) TunnelUDP(ctx context.Context, conn io.ReadWriteCloser, dest int) error {
+	return rc.tunnel(ctx, conn, dest, config.TunnelProtocolUDP)
+}
+
+func (rc *RobotClient) tunnel(ctx context.Context, conn io.ReadWriteCloser, dest int, protocol string) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, tunnel.ProtocolMetadataKey, protocol)
 	client, err := rc.client.Tunnel(ctx)
 	if err != nil {
 		return err
@@ -1463,7 +1836,7 @@ func (rc *RobotClient) Tunnel(ctx context.Context, conn io.ReadWriteCloser, dest
 	}); err != nil {
 		return err
 	}
-	rc.Logger().CInfow(ctx, "creating tunnel to server", "port", dest)
+	rc.Logger().CInfow(ctx, "creating tunnel to server", "port", dest, "protocol", protocol)
 	var (
 		wg              sync.WaitGroup
 		readerSenderErr error
@@ -48,6 +48,37 @@ type robotClientOpts struct {
 	// in production (not in a testing environment) will already allow connecting
 	// to still-initializing machines.
 	doNotWaitForRunning bool
+
+	// failoverAddresses are other addresses of the robot, in order of preference, which
+	// are connected to when the address given to New cannot be.
+	failoverAddresses []string
+
+	// failoverThreshold is the number of consecutive failed connection checks after which
+	// the connection is considered lost. Defaults to 1.
+	failoverThreshold int
+
+	// failbackEvery is how often to try connecting to a more preferred address while
+	// connected to a failover address. If <=0, it will not fail back, if unset, it will
+	// try every minute.
+	failbackEvery *time.Duration
+
+	// failoverDialTimeout is how long to try connecting to an address before failing over
+	// to the next one. Defaults to 10s. Unused without failover addresses.
+	failoverDialTimeout time.Duration
+
+	// clockSyncEvery is how often to estimate the offset between the clock of the robot and
+	// ours, by which the capture times in responses are translated into our time. If <=0,
+	// clocks are not synchronized.
+	clockSyncEvery time.Duration
+
+	// onDegraded is called when the connection to the robot is lost and when it is restored.
+	onDegraded func(degraded bool, err error)
+
+	// cacheMetadata is whether metadata of the robot that only changes with its config is
+	// cached. metadataRevalidateEvery is how often its config revision is checked when
+	// cached metadata is asked for. If <=0, it is only checked by calls to MachineStatus.
+	cacheMetadata           bool
+	metadataRevalidateEvery time.Duration
 }
 
 // RobotClientOption configures how we set up the connection.
@@ -111,6 +142,71 @@ func WithReconnectEvery(reconnectEvery time.Duration) RobotClientOption {
 	})
 }
 
+// WithFailoverAddresses returns a RobotClientOption for other addresses of the robot, in order of
+// preference, which are connected to when the address given to New cannot be connected to.
+func WithFailoverAddresses(addresses ...string) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.failoverAddresses = addresses
+	})
+}
+
+// WithFailoverThreshold returns a RobotClientOption for the number of consecutive failed connection
+// checks after which the connection to the robot is considered lost and the robot is connected to
+// again, failing over to the next address that can be connected to.
+func WithFailoverThreshold(threshold int) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.failoverThreshold = threshold
+	})
+}
+
+// WithFailbackEvery returns a RobotClientOption for how often to try connecting to a more preferred
+// address of the robot while connected to a failover address.
+func WithFailbackEvery(failbackEvery time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.failbackEvery = &failbackEvery
+	})
+}
+
+// WithFailoverDialTimeout returns a RobotClientOption for how long to try connecting to an address
+// of the robot before failing over to the next one.
+func WithFailoverDialTimeout(timeout time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.failoverDialTimeout = timeout
+	})
+}
+
+// WithClockSync returns a RobotClientOption that estimates the offset between the clock of the
+// robot and the clock of this machine every interval, and translates the capture times in
+// responses from the robot into the time of this machine.
+func WithClockSync(every time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.clockSyncEvery = every
+	})
+}
+
+// WithDegradedCallback returns a RobotClientOption that calls f once when the connection to the
+// robot is lost, with the error that revealed the loss, and once more with a nil error when the
+// client has reconnected and resumed its session. In between, requests fail as unavailable and
+// resource state streams wait to be resubscribed to.
+func WithDegradedCallback(f func(degraded bool, err error)) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.onDegraded = f
+	})
+}
+
+// WithMetadataCache returns a RobotClientOption that caches the frame system config, the models of
+// modules, and the cloud metadata of the robot until its config revision, as reported by
+// MachineStatus, changes. The revision is checked at most every revalidateEvery when cached
+// metadata is asked for, and by every call to MachineStatus, which also refreshes the resource
+// names of the robot when it changes. If revalidateEvery <= 0, the revision is only checked by calls
+// to MachineStatus. The cache is dropped whenever the client (re)connects.
+func WithMetadataCache(revalidateEvery time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.cacheMetadata = true
+		o.metadataRevalidateEvery = revalidateEvery
+	})
+}
+
 // WithRemoteName returns a RobotClientOption setting the name of the remote robot.
 func WithRemoteName(remoteName string) RobotClientOption {
 	return newFuncRobotClientOption(func(o *robotClientOpts) {
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
+	"github.com/pkg/errors"
 	pb "go.viam.com/api/robot/v1"
 	"go.viam.com/utils"
 	"google.golang.org/grpc"
@@ -83,7 +84,11 @@ func (rc *RobotClient) sessionMetadata(ctx context.Context, method string) (cont
 	if !rc.useSessionInRequest(ctx, method) {
 		return ctx, nil
 	}
-	ctx = context.WithValue(ctx, ctxKeyInSessionMDReq, true)
+	return rc.ensureSession(context.WithValue(ctx, ctxKeyInSessionMDReq, true))
+}
+
+// ensureSession starts a session if there is none and returns ctx with its metadata attached.
+func (rc *RobotClient) ensureSession(ctx context.Context) (context.Context, error) {
 	rc.sessionMu.RLock()
 	if rc.sessionsSupported != nil {
 		defer rc.sessionMu.RUnlock()
@@ -136,6 +141,23 @@ func (rc *RobotClient) sessionMetadata(ctx context.Context, method string) (cont
 	return rc.sessionMetadataInner(ctx), nil
 }
 
+// SessionID returns the ID of the session the client maintains with the robot, starting one if
+// needed, so that it can be used to acquire exclusive control of resources.
+func (rc *RobotClient) SessionID(ctx context.Context) (string, error) {
+	if rc.sessionsDisabled {
+		return "", errors.New("sessions are disabled for this client")
+	}
+	if _, err := rc.ensureSession(context.WithValue(ctx, ctxKeyInSessionMDReq, true)); err != nil {
+		return "", err
+	}
+	rc.sessionMu.RLock()
+	defer rc.sessionMu.RUnlock()
+	if rc.sessionsSupported == nil || !*rc.sessionsSupported {
+		return "", errors.New("robot does not support sessions")
+	}
+	return rc.currentSessionID, nil
+}
+
 func (rc *RobotClient) safetyMonitorFromHeaders(ctx context.Context, hdr metadata.MD) {
 	for _, name := range hdr.Get(session.SafetyMonitoredResourceMetadataKey) {
 		resName, err := resource.NewFromString(name)
@@ -69,7 +69,9 @@ import (
 	"go.viam.com/rdk/spatialmath"
 	"go.viam.com/rdk/testutils"
 	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/tunnel"
 	rutils "go.viam.com/rdk/utils"
+	"go.viam.com/rdk/utils/contextutils"
 )
 
 var emptyResources = []resource.Name{
@@ -1297,6 +1299,247 @@ func TestClientReconnect(t *testing.T) {
 	test.That(t, atomic.LoadInt64(&called), test.ShouldEqual, 1)
 }
 
+// resourceStateRobot is a robot whose resource states can be streamed.
+type resourceStateRobot struct {
+	*inject.Robot
+}
+
+func (r *resourceStateRobot) SubscribeResourceStateEvents() (<-chan robot.ResourceStateEvent, func()) {
+	return make(chan robot.ResourceStateEvent), func() {}
+}
+
+func TestClientReconnectResumes(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	var listener net.Listener = gotestutils.ReserveRandomListener(t)
+	hold := testutils.HoldPort(t, listener)
+	injectRobot := &inject.Robot{}
+	injectRobot.ResourceRPCAPIsFunc = func() []resource.RPCAPI { return nil }
+	injectRobot.ResourceNamesFunc = func() []resource.Name { return []resource.Name{arm.Named("arm1")} }
+	injectRobot.MachineStatusFunc = func(ctx context.Context) (robot.MachineStatus, error) {
+		return robot.MachineStatus{
+			State: robot.StateRunning,
+			Resources: []resource.Status{
+				{NodeStatus: resource.NodeStatus{Name: arm.Named("arm1"), State: resource.NodeStateReady}},
+			},
+		}, nil
+	}
+	serve := func() *grpc.Server {
+		gServer := grpc.NewServer()
+		pb.RegisterRobotServiceServer(gServer, server.New(&resourceStateRobot{injectRobot}))
+		go gServer.Serve(hold)
+		return gServer
+	}
+	gServer := serve()
+
+	type degradedEvent struct {
+		degraded bool
+		err      error
+	}
+	degradedEvents := make(chan degradedEvent, 10)
+	dur := 100 * time.Millisecond
+	client, err := New(
+		context.Background(),
+		listener.Addr().String(),
+		logger,
+		WithCheckConnectedEvery(dur),
+		WithReconnectEvery(dur),
+		WithDegradedCallback(func(degraded bool, err error) {
+			degradedEvents <- degradedEvent{degraded, err}
+		}),
+	)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan robot.ResourceStateEvent, 10)
+	streamDone := make(chan error, 1)
+	go func() {
+		streamDone <- client.StreamResourceStates(ctx, nil, 0, func(event robot.ResourceStateEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+	event := <-events
+	test.That(t, event.Snapshot, test.ShouldBeTrue)
+	test.That(t, event.Name, test.ShouldResemble, arm.Named("arm1"))
+
+	gServer.Stop()
+	lost := <-degradedEvents
+	test.That(t, lost.degraded, test.ShouldBeTrue)
+	test.That(t, lost.err, test.ShouldNotBeNil)
+
+	hold.Rearm(t)
+	gServer2 := serve()
+	defer gServer2.Stop()
+
+	restored := <-degradedEvents
+	test.That(t, restored.degraded, test.ShouldBeFalse)
+	test.That(t, restored.err, test.ShouldBeNil)
+	test.That(t, client.Connected(), test.ShouldBeTrue)
+
+	// the stream is resubscribed to, starting with another snapshot.
+	event = <-events
+	test.That(t, event.Snapshot, test.ShouldBeTrue)
+	test.That(t, event.Name, test.ShouldResemble, arm.Named("arm1"))
+	test.That(t, degradedEvents, test.ShouldBeEmpty)
+
+	cancel()
+	test.That(t, <-streamDone, test.ShouldNotBeNil)
+}
+
+func TestClientFailover(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	injectRobot := &inject.Robot{}
+	injectRobot.ResourceRPCAPIsFunc = func() []resource.RPCAPI { return nil }
+	injectRobot.ResourceNamesFunc = func() []resource.Name {
+		return []resource.Name{arm.Named("arm1")}
+	}
+	injectRobot.MachineStatusFunc = func(ctx context.Context) (robot.MachineStatus, error) {
+		return robot.MachineStatus{State: robot.StateRunning}, nil
+	}
+	injectRobot.FrameSystemConfigFunc = func(ctx context.Context) (*framesystem.Config, error) {
+		return &framesystem.Config{}, nil
+	}
+	serve := func(lis net.Listener) *grpc.Server {
+		gServer := grpc.NewServer()
+		pb.RegisterRobotServiceServer(gServer, server.New(injectRobot))
+		go gServer.Serve(lis)
+		return gServer
+	}
+
+	preferredListener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	preferredAddress := preferredListener.Addr().String()
+	preferredServer := serve(preferredListener)
+	failoverListener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	failoverServer := serve(failoverListener)
+	defer failoverServer.Stop()
+
+	dur := 100 * time.Millisecond
+	client, err := New(
+		context.Background(),
+		preferredAddress,
+		logger,
+		WithCheckConnectedEvery(dur),
+		WithReconnectEvery(dur),
+		WithFailoverAddresses(failoverListener.Addr().String()),
+		WithFailbackEvery(2*dur),
+		WithFailoverDialTimeout(time.Second),
+	)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+	}()
+	test.That(t, client.address(), test.ShouldEqual, preferredAddress)
+
+	// the client fails over once the preferred address goes down.
+	preferredServer.Stop()
+	test.That(t, <-client.Changed(), test.ShouldBeTrue)
+	test.That(t, <-client.Changed(), test.ShouldBeTrue)
+	test.That(t, client.Connected(), test.ShouldBeTrue)
+	test.That(t, client.address(), test.ShouldEqual, failoverListener.Addr().String())
+	test.That(t, client.ResourceNames(), test.ShouldHaveLength, 1)
+
+	// and fails back once it is up again, without disconnecting.
+	preferredListener, err = net.Listen("tcp", preferredAddress)
+	test.That(t, err, test.ShouldBeNil)
+	preferredServer = serve(preferredListener)
+	defer preferredServer.Stop()
+	test.That(t, <-client.Changed(), test.ShouldBeTrue)
+	test.That(t, client.Connected(), test.ShouldBeTrue)
+	test.That(t, client.address(), test.ShouldEqual, preferredAddress)
+	test.That(t, client.ResourceNames(), test.ShouldHaveLength, 1)
+}
+
+// skewedRobotServer reports a clock that is skew ahead of ours.
+type skewedRobotServer struct {
+	pb.RobotServiceServer
+	skew time.Duration
+}
+
+func (s *skewedRobotServer) GetVersion(ctx context.Context, _ *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
+	serverTime := time.Now().Add(s.skew).Format(time.RFC3339Nano)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(contextutils.ServerTimeMetadataKey, serverTime)); err != nil {
+		return nil, err
+	}
+	return &pb.GetVersionResponse{}, nil
+}
+
+func TestClientClockSync(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	skew := time.Hour
+	capturedAt := time.Now().Add(skew)
+
+	injectRobot := &inject.Robot{}
+	injectRobot.ResourceRPCAPIsFunc = func() []resource.RPCAPI { return nil }
+	injectRobot.ResourceNamesFunc = func() []resource.Name {
+		return []resource.Name{camera.Named("camera1")}
+	}
+	injectRobot.MachineStatusFunc = func(ctx context.Context) (robot.MachineStatus, error) {
+		return robot.MachineStatus{State: robot.StateRunning}, nil
+	}
+	injectCamera := &inject.Camera{}
+	injectCamera.ImagesFunc = func(
+		ctx context.Context,
+		filterSourceNames []string,
+		extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		namedImg, err := camera.NamedImageFromBytes([]byte{0}, "", rutils.MimeTypeJPEG, data.Annotations{})
+		if err != nil {
+			return nil, resource.ResponseMetadata{}, err
+		}
+		return []camera.NamedImage{namedImg}, resource.ResponseMetadata{CapturedAt: capturedAt}, nil
+	}
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	gServer := grpc.NewServer()
+	pb.RegisterRobotServiceServer(gServer, &skewedRobotServer{RobotServiceServer: server.New(injectRobot), skew: skew})
+	cameraSvc, err := resource.NewAPIResourceCollection(camera.API, map[resource.Name]camera.Camera{camera.Named("camera1"): injectCamera})
+	test.That(t, err, test.ShouldBeNil)
+	gServer.RegisterService(&camerapb.CameraService_ServiceDesc, camera.NewRPCServiceServer(cameraSvc, logger))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	t.Run("without clock sync", func(t *testing.T) {
+		client, err := New(context.Background(), listener.Addr().String(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		defer func() {
+			test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+		}()
+		_, _, ok := client.ClockOffset()
+		test.That(t, ok, test.ShouldBeFalse)
+
+		cam, err := camera.FromProvider(client, "camera1")
+		test.That(t, err, test.ShouldBeNil)
+		_, md, err := cam.Images(context.Background(), nil, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, md.CapturedAt.Equal(capturedAt), test.ShouldBeTrue)
+	})
+
+	t.Run("with clock sync", func(t *testing.T) {
+		client, err := New(context.Background(), listener.Addr().String(), logger, WithClockSync(time.Hour))
+		test.That(t, err, test.ShouldBeNil)
+		defer func() {
+			test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+		}()
+		offset, uncertainty, ok := client.ClockOffset()
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, offset, test.ShouldAlmostEqual, skew, uncertainty+10*time.Millisecond)
+
+		cam, err := camera.FromProvider(client, "camera1")
+		test.That(t, err, test.ShouldBeNil)
+		_, md, err := cam.Images(context.Background(), nil, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, md.CapturedAt, test.ShouldHappenWithin, uncertainty+10*time.Millisecond, capturedAt.Add(-skew))
+	})
+}
+
 func TestClientRefreshNoReconfigure(t *testing.T) {
 	someAPI := resource.APINamespace("acme").WithComponentType(uuid.New().String())
 	var called int64
@@ -2133,6 +2376,117 @@ func TestCloudMetadata(t *testing.T) {
 	test.That(t, md, test.ShouldResemble, injectCloudMD)
 }
 
+func TestClientMetadataCache(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+
+	lif, err := (&referenceframe.LinkConfig{ID: "frame1", Parent: referenceframe.World}).ParseConfig()
+	test.That(t, err, test.ShouldBeNil)
+	var revision atomic.Value
+	revision.Store("rev1")
+	var resourceNames atomic.Value
+	resourceNames.Store([]resource.Name{arm.Named("arm1")})
+	var fsCalls, cloudCalls atomic.Int64
+	injectRobot := &inject.Robot{
+		ResourceNamesFunc:   func() []resource.Name { return resourceNames.Load().([]resource.Name) },
+		ResourceRPCAPIsFunc: func() []resource.RPCAPI { return nil },
+		MachineStatusFunc: func(ctx context.Context) (robot.MachineStatus, error) {
+			return robot.MachineStatus{
+				State:  robot.StateRunning,
+				Config: config.Revision{Revision: revision.Load().(string)},
+			}, nil
+		},
+		FrameSystemConfigFunc: func(ctx context.Context) (*framesystem.Config, error) {
+			fsCalls.Add(1)
+			return &framesystem.Config{Parts: []*referenceframe.FrameSystemPart{{FrameConfig: lif}}}, nil
+		},
+		CloudMetadataFunc: func(ctx context.Context) (cloud.Metadata, error) {
+			cloudCalls.Add(1)
+			return cloud.Metadata{MachineID: "the-machine"}, nil
+		},
+	}
+
+	newClient := func(t *testing.T, revalidateEvery time.Duration) *RobotClient {
+		t.Helper()
+		listener, err := net.Listen("tcp", "localhost:0")
+		test.That(t, err, test.ShouldBeNil)
+		gServer := grpc.NewServer()
+		pb.RegisterRobotServiceServer(gServer, server.New(injectRobot))
+		go gServer.Serve(listener)
+		t.Cleanup(gServer.Stop)
+
+		client, err := New(context.Background(), listener.Addr().String(), logger,
+			WithRefreshEvery(time.Hour), WithCheckConnectedEvery(time.Hour), WithMetadataCache(revalidateEvery))
+		test.That(t, err, test.ShouldBeNil)
+		t.Cleanup(func() {
+			test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+		})
+		return client
+	}
+
+	t.Run("invalidated by machine status", func(t *testing.T) {
+		fsCalls.Store(0)
+		cloudCalls.Store(0)
+		revision.Store("rev1")
+		resourceNames.Store([]resource.Name{arm.Named("arm1")})
+		client := newClient(t, 0)
+		ctx := context.Background()
+
+		for range 2 {
+			fsCfg, err := client.FrameSystemConfig(ctx)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, fsCfg.Parts, test.ShouldHaveLength, 1)
+			test.That(t, fsCfg.Parts[0].FrameConfig.Name(), test.ShouldEqual, "frame1")
+			// callers are given parts of their own.
+			framesystem.PrefixRemoteParts(fsCfg.Parts, "remote-", "remote_world")
+
+			md, err := client.CloudMetadata(ctx)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, md.MachineID, test.ShouldEqual, "the-machine")
+		}
+		test.That(t, fsCalls.Load(), test.ShouldEqual, 1)
+		test.That(t, cloudCalls.Load(), test.ShouldEqual, 1)
+
+		// an unchanged revision keeps the cache.
+		_, err := client.MachineStatus(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = client.FrameSystemConfig(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fsCalls.Load(), test.ShouldEqual, 1)
+
+		revision.Store("rev2")
+		resourceNames.Store([]resource.Name{arm.Named("arm1"), arm.Named("arm2")})
+		_, err = client.MachineStatus(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, client.ResourceNames(), test.ShouldHaveLength, 2)
+		_, err = client.FrameSystemConfig(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fsCalls.Load(), test.ShouldEqual, 2)
+		_, err = client.CloudMetadata(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloudCalls.Load(), test.ShouldEqual, 2)
+	})
+
+	t.Run("revalidated when asked for", func(t *testing.T) {
+		fsCalls.Store(0)
+		revision.Store("rev1")
+		client := newClient(t, 10*time.Millisecond)
+		ctx := context.Background()
+
+		_, err := client.FrameSystemConfig(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		time.Sleep(20 * time.Millisecond)
+		_, err = client.FrameSystemConfig(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fsCalls.Load(), test.ShouldEqual, 1)
+
+		revision.Store("rev2")
+		time.Sleep(20 * time.Millisecond)
+		_, err = client.FrameSystemConfig(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fsCalls.Load(), test.ShouldEqual, 2)
+	})
+}
+
 func TestShutDown(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 	listener, err := net.Listen("tcp", "localhost:0")
@@ -2531,6 +2885,85 @@ func TestListTunnels(t *testing.T) {
 	test.That(t, ttes, test.ShouldResemble, expectedTTEs)
 }
 
+func TestTunnelUDP(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	gServer := grpc.NewServer()
+
+	// the destination echoes every datagram back to its sender.
+	echo, err := net.ListenPacket("udp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	defer echo.Close()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := echo.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if _, err := echo.WriteTo(buf[:n], addr); err != nil {
+				return
+			}
+		}
+	}()
+	echoPort := echo.LocalAddr().(*net.UDPAddr).Port
+
+	injectRobot := &inject.Robot{
+		ResourceNamesFunc:   func() []resource.Name { return nil },
+		ResourceRPCAPIsFunc: func() []resource.RPCAPI { return nil },
+		MachineStatusFunc: func(ctx context.Context) (robot.MachineStatus, error) {
+			return robot.MachineStatus{State: robot.StateRunning}, nil
+		},
+		ListTunnelsFunc: func(ctx context.Context) ([]config.TrafficTunnelEndpoint, error) {
+			return []config.TrafficTunnelEndpoint{{Port: echoPort, Protocol: config.TunnelProtocolUDP}}, nil
+		},
+		LoggerFunc: func() logging.Logger { return logger },
+	}
+
+	pb.RegisterRobotServiceServer(gServer, server.New(injectRobot))
+
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	client, err := New(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	t.Run("tcp is not tunneled to a udp endpoint", func(t *testing.T) {
+		local, remote := net.Pipe()
+		defer remote.Close()
+		err := client.Tunnel(context.Background(), local, echoPort)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "tcp tunnel not available")
+	})
+
+	local, err := net.ListenPacket("udp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	ctx, cancel := context.WithCancel(context.Background())
+	tunnelDone := make(chan error, 1)
+	go func() {
+		tunnelDone <- client.TunnelUDP(ctx, tunnel.NewPacketConn(local), echoPort)
+	}()
+	defer func() {
+		cancel()
+		<-tunnelDone
+	}()
+
+	peer, err := net.Dial("udp", local.LocalAddr().String())
+	test.That(t, err, test.ShouldBeNil)
+	defer peer.Close()
+	test.That(t, peer.SetReadDeadline(time.Now().Add(10*time.Second)), test.ShouldBeNil)
+	_, err = peer.Write([]byte("hello"))
+	test.That(t, err, test.ShouldBeNil)
+	buf := make([]byte, 1024)
+	n, err := peer.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(buf[:n]), test.ShouldEqual, "hello")
+}
+
 func TestUploadDataFromPath(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 	listener, err := net.Listen("tcp", "localhost:0")
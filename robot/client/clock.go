@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	commonpb "go.viam.com/api/common/v1"
+	pb "go.viam.com/api/robot/v1"
+	"go.viam.com/utils"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/utils/contextutils"
+)
+
+// clockSyncSamples is how many round trips are made to the robot each time its clock offset is
+// estimated. The sample with the shortest round trip is kept, as it has the smallest uncertainty.
+const clockSyncSamples = 5
+
+var errServerTimeUnavailable = errors.New("robot did not report its time")
+
+// clockOffset is an estimate of how far ahead the clock of the robot is of ours.
+type clockOffset struct {
+	offset      time.Duration
+	uncertainty time.Duration
+}
+
+// ClockOffset returns how far ahead the clock of the robot is of the clock of this machine, and the
+// uncertainty of the estimate. ok is false if the offset has not been estimated, which is the case
+// unless the client was created WithClockSync, or if the robot does not report its time.
+func (rc *RobotClient) ClockOffset() (offset, uncertainty time.Duration, ok bool) {
+	est := rc.clockOffset.Load()
+	if est == nil {
+		return 0, 0, false
+	}
+	return est.offset, est.uncertainty, true
+}
+
+// LocalTime translates a time of the robot's clock into the time of this machine's clock. The time
+// is returned as is if the clock offset of the robot has not been estimated.
+func (rc *RobotClient) LocalTime(t time.Time) time.Time {
+	est := rc.clockOffset.Load()
+	if est == nil {
+		return t
+	}
+	return t.Add(-est.offset)
+}
+
+// estimateClockOffset estimates the clock offset of the robot, NTP-style, from the time the robot
+// reports when asked for its version.
+func (rc *RobotClient) estimateClockOffset(ctx context.Context) error {
+	var best *clockOffset
+	for range clockSyncSamples {
+		var header metadata.MD
+		start := time.Now()
+		if _, err := rc.client.GetVersion(ctx, &pb.GetVersionRequest{}, googlegrpc.Header(&header)); err != nil {
+			return err
+		}
+		roundTrip := time.Since(start)
+		values := header.Get(contextutils.ServerTimeMetadataKey)
+		if len(values) == 0 {
+			return errServerTimeUnavailable
+		}
+		serverTime, err := time.Parse(time.RFC3339Nano, values[0])
+		if err != nil {
+			return err
+		}
+		// assume the robot read its clock halfway through the round trip, which is off by at most half of it.
+		sample := &clockOffset{
+			offset:      serverTime.Sub(start.Add(roundTrip / 2)),
+			uncertainty: roundTrip / 2,
+		}
+		if best == nil || sample.uncertainty < best.uncertainty {
+			best = sample
+		}
+	}
+	rc.clockOffset.Store(best)
+	return nil
+}
+
+// syncClockEvery estimates the clock offset of the robot every interval while connected to it.
+func (rc *RobotClient) syncClockEvery(ctx context.Context, every time.Duration) {
+	for {
+		if !utils.SelectContextOrWait(ctx, every) {
+			return
+		}
+		if !rc.connected.Load() {
+			continue
+		}
+		if err := rc.estimateClockOffset(ctx); err != nil && ctx.Err() == nil {
+			rc.Logger().CDebugw(ctx, "failed to estimate clock offset of remote", "error", err, "address", rc.address())
+		}
+	}
+}
+
+// translateTimesUnaryClientInterceptor translates the capture times in responses from the robot,
+// both in response metadata and in headers, into the time of this machine.
+func (rc *RobotClient) translateTimesUnaryClientInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *googlegrpc.ClientConn,
+	invoker googlegrpc.UnaryInvoker,
+	opts ...googlegrpc.CallOption,
+) error {
+	if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+		return err
+	}
+	if rc.clockOffset.Load() == nil {
+		return nil
+	}
+
+	if resp, ok := reply.(interface {
+		GetResponseMetadata() *commonpb.ResponseMetadata
+	}); ok {
+		if md := resp.GetResponseMetadata(); md.GetCapturedAt() != nil {
+			md.CapturedAt = timestamppb.New(rc.LocalTime(md.GetCapturedAt().AsTime()))
+		}
+	}
+	for _, opt := range opts {
+		headerOpt, ok := opt.(googlegrpc.HeaderCallOption)
+		if !ok || headerOpt.HeaderAddr == nil {
+			continue
+		}
+		for _, key := range []string{contextutils.TimeRequestedMetadataKey, contextutils.TimeReceivedMetadataKey} {
+			values := headerOpt.HeaderAddr.Get(key)
+			translated := make([]string, 0, len(values))
+			for _, value := range values {
+				t, err := time.Parse(time.RFC3339Nano, value)
+				if err != nil {
+					translated = append(translated, value)
+					continue
+				}
+				translated = append(translated, rc.LocalTime(t).Format(time.RFC3339Nano))
+			}
+			if len(translated) > 0 {
+				headerOpt.HeaderAddr.Set(key, translated...)
+			}
+		}
+	}
+	return nil
+}
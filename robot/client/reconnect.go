@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+
+	"go.viam.com/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// markDegraded records that the connection to the robot was lost, telling the degraded callback
+// once per loss.
+func (rc *RobotClient) markDegraded(ctx context.Context, err error) {
+	if rc.degraded.Swap(true) || rc.onDegraded == nil {
+		return
+	}
+	rc.Logger().CDebugw(ctx, "notifying that the connection to remote is degraded", "address", rc.address())
+	rc.onDegraded(true, err)
+}
+
+// markRestored records that the client reconnected to the robot after losing its connection,
+// resuming its session before telling the degraded callback, so that requests made from the
+// callback are made in the session held before.
+func (rc *RobotClient) markRestored(ctx context.Context) {
+	if !rc.degraded.Swap(false) {
+		return
+	}
+	rc.resumeSession(ctx)
+	if rc.onDegraded != nil {
+		rc.onDegraded(false, nil)
+	}
+}
+
+// resumeSession resumes the session the client held before it lost its connection, so that the
+// resources it had exclusive control of stay under its control and are not stopped for lack of
+// heartbeats.
+func (rc *RobotClient) resumeSession(ctx context.Context) {
+	if rc.sessionsDisabled {
+		return
+	}
+	rc.sessionMu.RLock()
+	sessionID := rc.currentSessionID
+	rc.sessionMu.RUnlock()
+	if sessionID == "" {
+		return
+	}
+	rc.sessionReset()
+	if _, err := rc.ensureSession(context.WithValue(ctx, ctxKeyInSessionMDReq, true)); err != nil {
+		rc.Logger().CWarnw(ctx, "failed to resume session after reconnecting to remote; a new one will be started when needed",
+			"error", err, "address", rc.address(), "session_id", sessionID)
+	}
+}
+
+// waitReconnected waits for the client to reconnect to the robot after a stream was cut off by
+// the loss of the connection. It waits at least reconnectEvery, so that a stream cut off before
+// the loss is detected is not resubscribed to over a dead connection in a tight loop.
+func (rc *RobotClient) waitReconnected(ctx context.Context) error {
+	if !utils.SelectContextOrWait(ctx, rc.reconnectEvery) {
+		return ctx.Err()
+	}
+	rc.mu.RLock()
+	reconnected := rc.reconnected
+	rc.mu.RUnlock()
+	if reconnected == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-rc.backgroundCtx.Done():
+		return context.Cause(rc.backgroundCtx)
+	case <-reconnected:
+		return nil
+	}
+}
+
+// isConnectionLostError returns whether err ended a call because the connection to the robot was
+// lost, rather than because of the call itself.
+func isConnectionLostError(err error) bool {
+	return status.Code(err) == codes.Unavailable || isDisconnectedError(err)
+}
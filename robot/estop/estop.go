@@ -0,0 +1,307 @@
+// Package estop implements a robot-wide emergency stop. Once engaged, the emergency stop latches: every
+// actuator is stopped and every request that would move an actuator is rejected until the emergency stop
+// is explicitly reset.
+//
+// The emergency stop is a resource named PublicServiceName on every local robot, and it is engaged,
+// reset, and queried through its DoCommand with the keys below, which works against both local robots
+// and robot clients. Engage, Reset, and GetStatus wrap that contract. While engaged, it is also reported
+// in the robot's MachineStatus as an unhealthy resource named InternalServiceName.
+package estop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+)
+
+// InternalServiceName identifies the emergency stop in MachineStatus.
+var InternalServiceName = resource.NewName(
+	resource.APINamespaceRDKInternal.WithServiceType("emergency_stop"),
+	"builtin",
+)
+
+// PublicServiceName is the generic service through which the emergency stop of a robot is controlled.
+var PublicServiceName = resource.NewName(generic.API, "$emergency_stop")
+
+// export keys to be used with DoCommand on PublicServiceName so they can be referenced by clients.
+//
+//   - DoEngage engages the emergency stop and stops every actuator
+//     required key: DoEngage
+//     optional key: DoReason, a string describing why
+//   - DoReset releases the emergency stop
+//     required key: DoReset
+//   - DoStatus returns the state of the emergency stop
+//     required key: DoStatus
+//
+// Every command responds with the state of the emergency stop in the form produced by StatusToMap.
+const (
+	DoEngage = "engage"
+	DoReason = "reason"
+	DoReset  = "reset"
+	DoStatus = "status"
+)
+
+// Sources of an engaged emergency stop.
+const (
+	SourceAPI  = "api"
+	SourceGPIO = "gpio"
+)
+
+// ErrEngaged is returned for requests rejected because the emergency stop is engaged.
+var ErrEngaged = errors.New("emergency stop engaged")
+
+// Status describes the state of the emergency stop.
+type Status struct {
+	Engaged bool
+	// Source is what engaged the emergency stop, such as SourceAPI or SourceGPIO.
+	Source string
+	Reason string
+	// Since is when the emergency stop was engaged.
+	Since time.Time
+}
+
+// Err returns an error wrapping ErrEngaged that describes the status, or nil if it is not engaged.
+func (st Status) Err() error {
+	if !st.Engaged {
+		return nil
+	}
+	return fmt.Errorf("%w by %s: %s", ErrEngaged, st.Source, st.Reason)
+}
+
+// A Latch holds the emergency stop state of a robot.
+type Latch struct {
+	mu     sync.Mutex
+	status Status
+	reset  chan struct{}
+}
+
+// NewLatch returns a disengaged Latch.
+func NewLatch() *Latch {
+	return &Latch{}
+}
+
+// Engage latches the emergency stop. It returns false if the emergency stop was already engaged, in
+// which case the original source and reason are kept.
+func (l *Latch) Engage(source, reason string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.status.Engaged {
+		return false
+	}
+	l.status = Status{Engaged: true, Source: source, Reason: reason, Since: time.Now()}
+	l.reset = make(chan struct{})
+	return true
+}
+
+// Reset releases the emergency stop. It returns false if the emergency stop was not engaged.
+func (l *Latch) Reset() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.status.Engaged {
+		return false
+	}
+	l.status = Status{}
+	close(l.reset)
+	return true
+}
+
+// Status returns the current emergency stop state.
+func (l *Latch) Status() Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.status
+}
+
+// Done returns a channel that is closed when the current engagement is reset, or nil if the emergency
+// stop is not engaged.
+func (l *Latch) Done() <-chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.status.Engaged {
+		return nil
+	}
+	return l.reset
+}
+
+// A Provider exposes the emergency stop Latch of a robot.
+type Provider interface {
+	EmergencyStop() *Latch
+}
+
+// checkMethod rejects a safety heartbeat monitored method, which is one that moves an actuator, while
+// the emergency stop is engaged.
+func (l *Latch) checkMethod(method string) error {
+	st := l.Status()
+	if !st.Engaged || !robot.IsSafetyHeartbeatMonitored(method) {
+		return nil
+	}
+	return status.Error(codes.FailedPrecondition, st.Err().Error())
+}
+
+// UnaryServerInterceptor rejects unary requests that would move an actuator while the emergency stop is engaged.
+func (l *Latch) UnaryServerInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if err := l.checkMethod(info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamServerInterceptor rejects streaming requests that would move an actuator while the emergency stop is engaged.
+func (l *Latch) StreamServerInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if err := l.checkMethod(info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// Engage engages the emergency stop of the given robot, stopping all of its actuators.
+func Engage(ctx context.Context, r robot.Robot, reason string) error {
+	_, err := doCommand(ctx, r, map[string]interface{}{DoEngage: true, DoReason: reason})
+	return err
+}
+
+// Reset releases the emergency stop of the given robot.
+func Reset(ctx context.Context, r robot.Robot) error {
+	_, err := doCommand(ctx, r, map[string]interface{}{DoReset: true})
+	return err
+}
+
+// GetStatus returns the emergency stop state of the given robot.
+func GetStatus(ctx context.Context, r robot.Robot) (Status, error) {
+	return doCommand(ctx, r, map[string]interface{}{DoStatus: true})
+}
+
+func doCommand(ctx context.Context, r robot.Robot, cmd map[string]interface{}) (Status, error) {
+	res, err := r.ResourceByName(PublicServiceName)
+	if err != nil {
+		return Status{}, err
+	}
+	resp, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return Status{}, err
+	}
+	return StatusFromMap(resp)
+}
+
+// StatusToMap converts a Status into the map returned by the emergency stop's DoCommand.
+func StatusToMap(st Status) map[string]interface{} {
+	m := map[string]interface{}{"engaged": st.Engaged}
+	if st.Engaged {
+		m["source"] = st.Source
+		m["reason"] = st.Reason
+		m["since"] = st.Since.UTC().Format(time.RFC3339Nano)
+	}
+	return m
+}
+
+// StatusFromMap converts a map produced by StatusToMap back into a Status.
+func StatusFromMap(m map[string]interface{}) (Status, error) {
+	engaged, ok := m["engaged"].(bool)
+	if !ok {
+		return Status{}, errors.Errorf("expected emergency stop status engaged to be a bool but got %T", m["engaged"])
+	}
+	st := Status{Engaged: engaged}
+	st.Source, _ = m["source"].(string)
+	st.Reason, _ = m["reason"].(string)
+	if since, ok := m["since"].(string); ok {
+		t, err := time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return Status{}, err
+		}
+		st.Since = t
+	}
+	return st, nil
+}
+
+// A Controller engages and resets the emergency stop of a robot.
+type Controller interface {
+	EngageEmergencyStop(ctx context.Context, source, reason string) error
+	ResetEmergencyStop(ctx context.Context) error
+	EmergencyStop() *Latch
+}
+
+type service struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	controller Controller
+}
+
+// NewService returns the resource through which the emergency stop of the robot behind controller is
+// controlled, which the robot serves as PublicServiceName.
+func NewService(controller Controller) resource.Resource {
+	return &service{Named: PublicServiceName.AsNamed(), controller: controller}
+}
+
+func (svc *service) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	engage, _ := cmd[DoEngage].(bool)
+	reset, _ := cmd[DoReset].(bool)
+	_, status := cmd[DoStatus]
+	switch {
+	case engage && reset:
+		return nil, fmt.Errorf("cannot both %s and %s the emergency stop", DoEngage, DoReset)
+	case engage:
+		reason, _ := cmd[DoReason].(string)
+		if err := svc.controller.EngageEmergencyStop(ctx, SourceAPI, reason); err != nil {
+			return nil, err
+		}
+	case reset:
+		if err := svc.controller.ResetEmergencyStop(ctx); err != nil {
+			return nil, err
+		}
+	case !status:
+		return nil, resource.ErrDoUnimplemented
+	}
+	return StatusToMap(svc.controller.EmergencyStop().Status()), nil
+}
+
+// ResourceStatus returns the entry that represents an engaged emergency stop in a MachineStatus.
+func ResourceStatus(st Status) resource.Status {
+	return resource.Status{NodeStatus: resource.NodeStatus{
+		Name:        InternalServiceName,
+		State:       resource.NodeStateUnhealthy,
+		LastUpdated: st.Since,
+		Error:       st.Err(),
+	}}
+}
+
+// FromMachineStatus returns the emergency stop state reported in a MachineStatus.
+func FromMachineStatus(ms robot.MachineStatus) Status {
+	for _, res := range ms.Resources {
+		if res.Name != InternalServiceName || res.State != resource.NodeStateUnhealthy {
+			continue
+		}
+		st := Status{Engaged: true, Since: res.LastUpdated}
+		if res.Error != nil {
+			msg := strings.TrimPrefix(res.Error.Error(), ErrEngaged.Error()+" by ")
+			if source, reason, ok := strings.Cut(msg, ": "); ok {
+				st.Source, st.Reason = source, reason
+			} else {
+				st.Reason = msg
+			}
+		}
+		return st
+	}
+	return Status{}
+}
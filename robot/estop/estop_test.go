@@ -0,0 +1,150 @@
+package estop
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	// register the base protos so that their safety heartbeat options can be found.
+	_ "go.viam.com/api/component/base/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+func TestLatch(t *testing.T) {
+	l := NewLatch()
+	test.That(t, l.Status().Engaged, test.ShouldBeFalse)
+	test.That(t, l.Status().Err(), test.ShouldBeNil)
+	test.That(t, l.Done(), test.ShouldBeNil)
+	test.That(t, l.Reset(), test.ShouldBeFalse)
+
+	test.That(t, l.Engage(SourceAPI, "operator"), test.ShouldBeTrue)
+	test.That(t, l.Engage(SourceGPIO, "button"), test.ShouldBeFalse)
+	st := l.Status()
+	test.That(t, st.Engaged, test.ShouldBeTrue)
+	test.That(t, st.Source, test.ShouldEqual, SourceAPI)
+	test.That(t, st.Reason, test.ShouldEqual, "operator")
+	test.That(t, errors.Is(st.Err(), ErrEngaged), test.ShouldBeTrue)
+	test.That(t, st.Err().Error(), test.ShouldEqual, "emergency stop engaged by api: operator")
+
+	done := l.Done()
+	select {
+	case <-done:
+		t.Fatal("done closed before reset")
+	default:
+	}
+	test.That(t, l.Reset(), test.ShouldBeTrue)
+	<-done
+	test.That(t, l.Status().Engaged, test.ShouldBeFalse)
+}
+
+func TestInterceptors(t *testing.T) {
+	l := NewLatch()
+	called := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called++
+		return nil, nil
+	}
+	move := &grpc.UnaryServerInfo{FullMethod: "/viam.component.base.v1.BaseService/MoveStraight"}
+	read := &grpc.UnaryServerInfo{FullMethod: "/viam.component.base.v1.BaseService/GetProperties"}
+
+	_, err := l.UnaryServerInterceptor(context.Background(), nil, move, handler)
+	test.That(t, err, test.ShouldBeNil)
+
+	l.Engage(SourceAPI, "test")
+	_, err = l.UnaryServerInterceptor(context.Background(), nil, move, handler)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.FailedPrecondition)
+	_, err = l.UnaryServerInterceptor(context.Background(), nil, read, handler)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, called, test.ShouldEqual, 2)
+
+	streamHandler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+	err = l.StreamServerInterceptor(nil, nil, &grpc.StreamServerInfo{FullMethod: move.FullMethod}, streamHandler)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.FailedPrecondition)
+
+	l.Reset()
+	_, err = l.UnaryServerInterceptor(context.Background(), nil, move, handler)
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestMachineStatus(t *testing.T) {
+	test.That(t, FromMachineStatus(robot.MachineStatus{}), test.ShouldResemble, Status{})
+
+	st := Status{Engaged: true, Source: SourceGPIO, Reason: "pin 37 on board pi", Since: time.Now()}
+	res := ResourceStatus(st)
+	test.That(t, res.Name, test.ShouldResemble, InternalServiceName)
+	test.That(t, res.State, test.ShouldEqual, resource.NodeStateUnhealthy)
+	test.That(t, FromMachineStatus(robot.MachineStatus{Resources: []resource.Status{res}}), test.ShouldResemble, st)
+
+	// the error only survives a round trip through the robot client as text
+	res.Error = errors.New(res.Error.Error())
+	test.That(t, FromMachineStatus(robot.MachineStatus{Resources: []resource.Status{res}}), test.ShouldResemble, st)
+}
+
+func TestStatusMap(t *testing.T) {
+	st, err := StatusFromMap(StatusToMap(Status{}))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st, test.ShouldResemble, Status{})
+
+	engaged := Status{Engaged: true, Source: SourceAPI, Reason: "why", Since: time.Now().UTC()}
+	st, err = StatusFromMap(StatusToMap(engaged))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Engaged, test.ShouldBeTrue)
+	test.That(t, st.Source, test.ShouldEqual, SourceAPI)
+	test.That(t, st.Reason, test.ShouldEqual, "why")
+	test.That(t, st.Since.Equal(engaged.Since), test.ShouldBeTrue)
+
+	_, err = StatusFromMap(map[string]interface{}{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+type fakeController struct {
+	latch *Latch
+}
+
+func (c *fakeController) EngageEmergencyStop(ctx context.Context, source, reason string) error {
+	c.latch.Engage(source, reason)
+	return nil
+}
+
+func (c *fakeController) ResetEmergencyStop(ctx context.Context) error {
+	c.latch.Reset()
+	return nil
+}
+
+func (c *fakeController) EmergencyStop() *Latch {
+	return c.latch
+}
+
+func TestServiceDoCommand(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(&fakeController{latch: NewLatch()})
+	test.That(t, svc.Name(), test.ShouldResemble, PublicServiceName)
+
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{DoStatus: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["engaged"], test.ShouldBeFalse)
+
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{DoEngage: true, DoReason: "why"})
+	test.That(t, err, test.ShouldBeNil)
+	st, err := StatusFromMap(resp)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Engaged, test.ShouldBeTrue)
+	test.That(t, st.Source, test.ShouldEqual, SourceAPI)
+	test.That(t, st.Reason, test.ShouldEqual, "why")
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{DoEngage: true, DoReset: true})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"other": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{DoReset: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["engaged"], test.ShouldBeFalse)
+}
@@ -0,0 +1,110 @@
+package governor
+
+import (
+	"context"
+
+	"go.viam.com/rdk/components/gantry"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/referenceframe"
+)
+
+// The actuators below cannot be slowed, so the governor only rejects their motion while it is halted.
+
+type governedGripper struct {
+	gripper.Gripper
+	g Service
+}
+
+func (gr *governedGripper) Open(ctx context.Context, extra map[string]interface{}) error {
+	if err := gr.g.Halted(); err != nil {
+		return err
+	}
+	return gr.Gripper.Open(ctx, extra)
+}
+
+func (gr *governedGripper) Grab(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	if err := gr.g.Halted(); err != nil {
+		return false, err
+	}
+	return gr.Gripper.Grab(ctx, extra)
+}
+
+func (gr *governedGripper) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	if err := gr.g.Halted(); err != nil {
+		return err
+	}
+	return gr.Gripper.GoToInputs(ctx, inputSteps...)
+}
+
+type governedMotor struct {
+	motor.Motor
+	g Service
+}
+
+func (m *governedMotor) SetPower(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+	if err := m.g.Halted(); err != nil {
+		return err
+	}
+	return m.Motor.SetPower(ctx, powerPct, extra)
+}
+
+func (m *governedMotor) GoFor(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+	if err := m.g.Halted(); err != nil {
+		return err
+	}
+	return m.Motor.GoFor(ctx, rpm, revolutions, extra)
+}
+
+func (m *governedMotor) GoTo(ctx context.Context, rpm, positionRevolutions float64, extra map[string]interface{}) error {
+	if err := m.g.Halted(); err != nil {
+		return err
+	}
+	return m.Motor.GoTo(ctx, rpm, positionRevolutions, extra)
+}
+
+func (m *governedMotor) SetRPM(ctx context.Context, rpm float64, extra map[string]interface{}) error {
+	if err := m.g.Halted(); err != nil {
+		return err
+	}
+	return m.Motor.SetRPM(ctx, rpm, extra)
+}
+
+type governedServo struct {
+	servo.Servo
+	g Service
+}
+
+func (s *governedServo) Move(ctx context.Context, angleDeg uint32, extra map[string]interface{}) error {
+	if err := s.g.Halted(); err != nil {
+		return err
+	}
+	return s.Servo.Move(ctx, angleDeg, extra)
+}
+
+type governedGantry struct {
+	gantry.Gantry
+	g Service
+}
+
+func (gn *governedGantry) MoveToPosition(ctx context.Context, positionsMm, speedsMmPerSec []float64, extra map[string]interface{}) error {
+	if err := gn.g.Halted(); err != nil {
+		return err
+	}
+	return gn.Gantry.MoveToPosition(ctx, positionsMm, speedsMmPerSec, extra)
+}
+
+func (gn *governedGantry) Home(ctx context.Context, extra map[string]interface{}) (bool, error) {
+	if err := gn.g.Halted(); err != nil {
+		return false, err
+	}
+	return gn.Gantry.Home(ctx, extra)
+}
+
+func (gn *governedGantry) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	if err := gn.g.Halted(); err != nil {
+		return err
+	}
+	return gn.Gantry.GoToInputs(ctx, inputSteps...)
+}
@@ -0,0 +1,358 @@
+// Package governor implements the robot's actuator governor, which limits how fast bases and arms may
+// be commanded to move and can halt every actuator outright. Services such as the safety zone service
+// set limits on named components, the emergency stop halts the whole robot, and the robot applies both
+// to every command sent to an actuator, whether it arrives over gRPC or from another resource through
+// its dependencies.
+package governor
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/gantry"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// SubtypeName is a constant that identifies the internal governor resource subtype string.
+const SubtypeName = "governor"
+
+// API is the fully qualified API for the internal governor service.
+var API = resource.APINamespaceRDKInternal.WithServiceType(SubtypeName)
+
+// InternalServiceName is used to refer to/depend on this service internally.
+var InternalServiceName = resource.NewName(API, "builtin")
+
+// ErrBlocked is returned for motion commands rejected because a component's speed scale is zero.
+var ErrBlocked = errors.New("motion blocked by governor")
+
+// Limit restricts the speed of one component.
+type Limit struct {
+	// SpeedScale is the fraction of the commanded speed permitted, in [0, 1]. Zero rejects motion.
+	SpeedScale float64
+	// Reason describes why the limit is in place and is included in errors.
+	Reason string
+	// MaxVelDegsPerSec and MaxAccDegsPerSec2 are the full speed joint limits of an arm. An arm can only
+	// be slowed when they are set or when the command carries its own limits.
+	MaxVelDegsPerSec  float64
+	MaxAccDegsPerSec2 float64
+}
+
+// A Service holds the limits placed on a robot's components by their owners.
+type Service interface {
+	resource.Resource
+	// SetLimits replaces every limit owned by owner with the given ones. A nil map clears them.
+	SetLimits(owner string, limits map[resource.Name]Limit)
+	// Limit returns the combined limit on a component, which is the most restrictive of every owner's.
+	Limit(name resource.Name) (Limit, bool)
+	// SetHalted rejects every motion command to every actuator with err until it is called with nil.
+	// Stop requests are always let through.
+	SetHalted(err error)
+	// Halted returns the error motion commands are rejected with, or nil if they are not.
+	Halted() error
+}
+
+// FromProvider is a helper for getting the governor from a resource Provider (collection of
+// Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name resource.Name) (Service, error) {
+	return resource.FromProvider[Service](provider, name)
+}
+
+type governor struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+
+	mu     sync.Mutex
+	limits map[string]map[resource.Name]Limit
+	halted error
+}
+
+// New returns a governor without any limits.
+func New() Service {
+	return &governor{
+		Named:  InternalServiceName.AsNamed(),
+		limits: map[string]map[resource.Name]Limit{},
+	}
+}
+
+func (g *governor) SetLimits(owner string, limits map[resource.Name]Limit) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(limits) == 0 {
+		delete(g.limits, owner)
+		return
+	}
+	g.limits[owner] = limits
+}
+
+func (g *governor) SetHalted(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.halted = err
+}
+
+func (g *governor) Halted() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.halted
+}
+
+func (g *governor) Limit(name resource.Name) (Limit, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var combined Limit
+	found := false
+	for _, limits := range g.limits {
+		l, ok := limits[name]
+		if !ok {
+			continue
+		}
+		if !found || l.SpeedScale < combined.SpeedScale {
+			combined.SpeedScale, combined.Reason = l.SpeedScale, l.Reason
+		}
+		combined.MaxVelDegsPerSec = minPositive(combined.MaxVelDegsPerSec, l.MaxVelDegsPerSec)
+		combined.MaxAccDegsPerSec2 = minPositive(combined.MaxAccDegsPerSec2, l.MaxAccDegsPerSec2)
+		found = true
+	}
+	return combined, found
+}
+
+func minPositive(a, b float64) float64 {
+	switch {
+	case a <= 0:
+		return b
+	case b <= 0:
+		return a
+	default:
+		return math.Min(a, b)
+	}
+}
+
+// Wrap returns res with the governor applied to its motion commands if it is an actuator. Bases and
+// arms are also slowed by their limits. Any other resource is returned unchanged.
+func Wrap(g Service, name resource.Name, res resource.Resource) resource.Resource {
+	switch name.API {
+	case base.API:
+		if b, ok := res.(base.Base); ok {
+			return &governedBase{Base: b, name: name, g: g}
+		}
+	case arm.API:
+		if a, ok := res.(arm.Arm); ok {
+			governed := &governedArm{Arm: a, name: name, g: g}
+			if servoer, ok := a.(arm.JointServoer); ok {
+				return &governedServoingArm{governedArm: governed, servoer: servoer}
+			}
+			return governed
+		}
+	case gripper.API:
+		if gr, ok := res.(gripper.Gripper); ok {
+			return &governedGripper{Gripper: gr, g: g}
+		}
+	case motor.API:
+		if m, ok := res.(motor.Motor); ok {
+			return &governedMotor{Motor: m, g: g}
+		}
+	case servo.API:
+		if s, ok := res.(servo.Servo); ok {
+			return &governedServo{Servo: s, g: g}
+		}
+	case gantry.API:
+		if gn, ok := res.(gantry.Gantry); ok {
+			return &governedGantry{Gantry: gn, g: g}
+		}
+	}
+	return res
+}
+
+// scale returns the speed scale to apply to a component, or an error if it may not move.
+func scale(g Service, name resource.Name) (Limit, float64, error) {
+	if err := g.Halted(); err != nil {
+		return Limit{}, 0, err
+	}
+	l, ok := g.Limit(name)
+	if !ok || l.SpeedScale >= 1 {
+		return l, 1, nil
+	}
+	if l.SpeedScale <= 0 {
+		return l, 0, errors.Wrapf(ErrBlocked, "%s: %s", name.ShortName(), l.Reason)
+	}
+	return l, l.SpeedScale, nil
+}
+
+type governedBase struct {
+	base.Base
+	name resource.Name
+	g    Service
+}
+
+func (b *governedBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	_, s, err := scale(b.g, b.name)
+	if err != nil {
+		return err
+	}
+	return b.Base.MoveStraight(ctx, distanceMm, mmPerSec*s, extra)
+}
+
+func (b *governedBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	_, s, err := scale(b.g, b.name)
+	if err != nil {
+		return err
+	}
+	return b.Base.Spin(ctx, angleDeg, degsPerSec*s, extra)
+}
+
+func (b *governedBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	_, s, err := scale(b.g, b.name)
+	if err != nil {
+		return err
+	}
+	return b.Base.SetPower(ctx, linear.Mul(s), angular.Mul(s), extra)
+}
+
+func (b *governedBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	_, s, err := scale(b.g, b.name)
+	if err != nil {
+		return err
+	}
+	return b.Base.SetVelocity(ctx, linear.Mul(s), angular.Mul(s), extra)
+}
+
+type governedArm struct {
+	arm.Arm
+	name resource.Name
+	g    Service
+}
+
+// scaledOptions returns options that slow a joint move by s, filling in the governor's limits for any
+// the caller left unset.
+func scaledOptions(name resource.Name, l Limit, s float64, options *arm.MoveOptions) (*arm.MoveOptions, error) {
+	scaled := arm.MoveOptions{}
+	if options != nil {
+		scaled = *options
+	}
+	if scaled.MaxVelRads == 0 && len(scaled.MaxVelRadsJoints) == 0 {
+		scaled.MaxVelRads = utils.DegToRad(l.MaxVelDegsPerSec)
+	}
+	if scaled.MaxAccRads == 0 && len(scaled.MaxAccRadsJoints) == 0 {
+		scaled.MaxAccRads = utils.DegToRad(l.MaxAccDegsPerSec2)
+	}
+	if scaled.MaxVelRads == 0 && len(scaled.MaxVelRadsJoints) == 0 {
+		return nil, errors.Errorf("cannot slow arm %s without joint velocity limits", name.ShortName())
+	}
+	scaled.MaxVelRads *= s
+	scaled.MaxAccRads *= s * s
+	scaled.MaxVelRadsJoints = scaleAll(scaled.MaxVelRadsJoints, s)
+	scaled.MaxAccRadsJoints = scaleAll(scaled.MaxAccRadsJoints, s*s)
+	if scaled.MaxTCPSpeedMPerSec != nil {
+		tcp := *scaled.MaxTCPSpeedMPerSec * s
+		scaled.MaxTCPSpeedMPerSec = &tcp
+	}
+	return &scaled, nil
+}
+
+func scaleAll(values []float64, s float64) []float64 {
+	if values == nil {
+		return nil
+	}
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = v * s
+	}
+	return out
+}
+
+func (a *governedArm) MoveToPosition(ctx context.Context, pose spatialmath.Pose, extra map[string]interface{}) error {
+	_, s, err := scale(a.g, a.name)
+	if err != nil {
+		return err
+	}
+	if s < 1 {
+		return errors.Errorf("cannot slow a cartesian move of arm %s; move it by joint positions instead", a.name.ShortName())
+	}
+	return a.Arm.MoveToPosition(ctx, pose, extra)
+}
+
+func (a *governedArm) MoveToJointPositions(ctx context.Context, positions []referenceframe.Input, extra map[string]interface{}) error {
+	return a.MoveThroughJointPositions(ctx, [][]referenceframe.Input{positions}, nil, extra)
+}
+
+func (a *governedArm) MoveThroughJointPositions(
+	ctx context.Context,
+	positions [][]referenceframe.Input,
+	options *arm.MoveOptions,
+	extra map[string]any,
+) error {
+	l, s, err := scale(a.g, a.name)
+	if err != nil {
+		return err
+	}
+	if s == 1 {
+		if len(positions) == 1 && options == nil {
+			return a.Arm.MoveToJointPositions(ctx, positions[0], extra)
+		}
+		return a.Arm.MoveThroughJointPositions(ctx, positions, options, extra)
+	}
+	scaled, err := scaledOptions(a.name, l, s, options)
+	if err != nil {
+		return err
+	}
+	return a.Arm.MoveThroughJointPositions(ctx, positions, scaled, extra)
+}
+
+func (a *governedArm) MoveThroughJointPositionsStreamed(
+	ctx context.Context,
+	batches <-chan []arm.TrajectoryPoint,
+	responses chan<- arm.Response,
+	extra map[string]interface{},
+) error {
+	_, s, err := scale(a.g, a.name)
+	if err != nil {
+		return err
+	}
+	if s < 1 {
+		return errors.Errorf("cannot slow a streamed trajectory of arm %s", a.name.ShortName())
+	}
+	return a.Arm.MoveThroughJointPositionsStreamed(ctx, batches, responses, extra)
+}
+
+func (a *governedArm) GoToInputs(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+	_, s, err := scale(a.g, a.name)
+	if err != nil {
+		return err
+	}
+	if s == 1 {
+		return a.Arm.GoToInputs(ctx, inputSteps...)
+	}
+	return a.MoveThroughJointPositions(ctx, inputSteps, nil, nil)
+}
+
+// governedServoingArm is a governed arm that can be servoed. Only arms that implement arm.JointServoer
+// are wrapped by it, so that callers checking for the interface see the same arms with or without
+// the governor.
+type governedServoingArm struct {
+	*governedArm
+	servoer arm.JointServoer
+}
+
+func (a *governedServoingArm) ServoJoints(ctx context.Context, positions []referenceframe.Input) error {
+	_, s, err := scale(a.g, a.name)
+	if err != nil {
+		return err
+	}
+	if s < 1 {
+		return errors.Errorf("cannot slow servoing of arm %s", a.name.ShortName())
+	}
+	return a.servoer.ServoJoints(ctx, positions)
+}
@@ -0,0 +1,143 @@
+package governor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func TestLimit(t *testing.T) {
+	g := New()
+	_, ok := g.Limit(base.Named("base"))
+	test.That(t, ok, test.ShouldBeFalse)
+
+	g.SetLimits("a", map[resource.Name]Limit{base.Named("base"): {SpeedScale: 0.5, MaxVelDegsPerSec: 30}})
+	g.SetLimits("b", map[resource.Name]Limit{base.Named("base"): {SpeedScale: 0.25, MaxVelDegsPerSec: 60}})
+	l, ok := g.Limit(base.Named("base"))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, l.SpeedScale, test.ShouldEqual, 0.25)
+	test.That(t, l.MaxVelDegsPerSec, test.ShouldEqual, 30)
+
+	g.SetLimits("b", nil)
+	l, _ = g.Limit(base.Named("base"))
+	test.That(t, l.SpeedScale, test.ShouldEqual, 0.5)
+}
+
+func TestGovernedBase(t *testing.T) {
+	ctx := context.Background()
+	var linear r3.Vector
+	var mmPerSec float64
+	b := inject.NewBase("base")
+	b.SetPowerFunc = func(ctx context.Context, lin, ang r3.Vector, extra map[string]interface{}) error {
+		linear = lin
+		return nil
+	}
+	b.MoveStraightFunc = func(ctx context.Context, distanceMm int, speed float64, extra map[string]interface{}) error {
+		mmPerSec = speed
+		return nil
+	}
+	g := New()
+	governed := Wrap(g, base.Named("base"), b).(base.Base)
+
+	test.That(t, governed.SetPower(ctx, r3.Vector{Y: 1}, r3.Vector{}, nil), test.ShouldBeNil)
+	test.That(t, linear.Y, test.ShouldEqual, 1)
+
+	g.SetLimits("zone", map[resource.Name]Limit{base.Named("base"): {SpeedScale: 0.5}})
+	test.That(t, governed.SetPower(ctx, r3.Vector{Y: 1}, r3.Vector{}, nil), test.ShouldBeNil)
+	test.That(t, linear.Y, test.ShouldEqual, 0.5)
+	test.That(t, governed.MoveStraight(ctx, 100, 200, nil), test.ShouldBeNil)
+	test.That(t, mmPerSec, test.ShouldEqual, 100)
+
+	g.SetLimits("zone", map[resource.Name]Limit{base.Named("base"): {SpeedScale: 0, Reason: "stop"}})
+	test.That(t, governed.SetPower(ctx, r3.Vector{Y: 1}, r3.Vector{}, nil), test.ShouldWrap, ErrBlocked)
+}
+
+func TestGovernedArm(t *testing.T) {
+	ctx := context.Background()
+	var options *arm.MoveOptions
+	a := inject.NewArm("arm")
+	a.MoveToJointPositionsFunc = func(ctx context.Context, positions []referenceframe.Input, extra map[string]interface{}) error {
+		options = nil
+		return nil
+	}
+	a.MoveThroughJointPositionsFunc = func(
+		ctx context.Context,
+		positions [][]referenceframe.Input,
+		opts *arm.MoveOptions,
+		extra map[string]interface{},
+	) error {
+		options = opts
+		return nil
+	}
+	a.MoveToPositionFunc = func(ctx context.Context, pose spatialmath.Pose, extra map[string]interface{}) error {
+		return nil
+	}
+	g := New()
+	governed := Wrap(g, arm.Named("arm"), a).(arm.Arm)
+	goal := []referenceframe.Input{1, 2}
+
+	test.That(t, governed.MoveToJointPositions(ctx, goal, nil), test.ShouldBeNil)
+	test.That(t, options, test.ShouldBeNil)
+
+	// an arm without limits cannot be slowed
+	g.SetLimits("zone", map[resource.Name]Limit{arm.Named("arm"): {SpeedScale: 0.5}})
+	test.That(t, governed.MoveToJointPositions(ctx, goal, nil), test.ShouldNotBeNil)
+	test.That(t, governed.MoveToPosition(ctx, spatialmath.NewZeroPose(), nil), test.ShouldNotBeNil)
+
+	g.SetLimits("zone", map[resource.Name]Limit{
+		arm.Named("arm"): {SpeedScale: 0.5, MaxVelDegsPerSec: 60, MaxAccDegsPerSec2: 120},
+	})
+	test.That(t, governed.MoveToJointPositions(ctx, goal, nil), test.ShouldBeNil)
+	test.That(t, options.MaxVelRads, test.ShouldAlmostEqual, utils.DegToRad(30))
+	test.That(t, options.MaxAccRads, test.ShouldAlmostEqual, utils.DegToRad(30))
+
+	test.That(t, governed.MoveThroughJointPositions(ctx, [][]referenceframe.Input{goal}, &arm.MoveOptions{MaxVelRads: 1}, nil),
+		test.ShouldBeNil)
+	test.That(t, options.MaxVelRads, test.ShouldAlmostEqual, 0.5)
+}
+
+// servoingArm is an arm that can be servoed.
+type servoingArm struct {
+	*inject.Arm
+	servoed [][]referenceframe.Input
+}
+
+func (a *servoingArm) ServoJoints(ctx context.Context, positions []referenceframe.Input) error {
+	a.servoed = append(a.servoed, positions)
+	return nil
+}
+
+func TestGovernedServoingArm(t *testing.T) {
+	ctx := context.Background()
+	g := New()
+	_, ok := Wrap(g, arm.Named("arm"), inject.NewArm("arm")).(arm.JointServoer)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	a := &servoingArm{Arm: inject.NewArm("arm")}
+	governed, ok := Wrap(g, arm.Named("arm"), a).(arm.JointServoer)
+	test.That(t, ok, test.ShouldBeTrue)
+	goal := []referenceframe.Input{1, 2}
+	test.That(t, governed.ServoJoints(ctx, goal), test.ShouldBeNil)
+	test.That(t, a.servoed, test.ShouldResemble, [][]referenceframe.Input{goal})
+
+	// servoing moves as fast as the arm allows, so it cannot be slowed.
+	g.SetLimits("zone", map[resource.Name]Limit{
+		arm.Named("arm"): {SpeedScale: 0.5, MaxVelDegsPerSec: 60, MaxAccDegsPerSec2: 120},
+	})
+	test.That(t, governed.ServoJoints(ctx, goal), test.ShouldNotBeNil)
+	g.SetLimits("zone", nil)
+	g.SetHalted(errors.New("emergency stop"))
+	test.That(t, governed.ServoJoints(ctx, goal), test.ShouldNotBeNil)
+	test.That(t, a.servoed, test.ShouldHaveLength, 1)
+}
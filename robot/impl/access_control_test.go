@@ -0,0 +1,182 @@
+package robotimpl
+
+import (
+	"context"
+	"testing"
+
+	commonpb "go.viam.com/api/common/v1"
+	basepb "go.viam.com/api/component/base/v1"
+	sensorpb "go.viam.com/api/component/sensor/v1"
+	robotpb "go.viam.com/api/robot/v1"
+	genericpb "go.viam.com/api/service/generic/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/protoutils"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	rgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/automation"
+	"go.viam.com/rdk/robot/batch"
+	"go.viam.com/rdk/robot/support"
+	"go.viam.com/rdk/robot/tunnels"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestAccessControl(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	cfg := &config.Config{Components: []resource.Config{
+		{Name: "s1", API: sensor.API, Model: fakeModel},
+		{Name: "s2", API: sensor.API, Model: fakeModel},
+		{Name: "base1", API: base.API, Model: fakeModel},
+	}}
+	r := setupLocalRobot(t, ctx, cfg, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type: rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{
+				"viewer":   "viewerkey",
+				"operator": "operatorkey",
+				"sensors":  "sensorskey",
+				"keys":     []string{"viewer", "operator", "sensors"},
+			},
+		},
+	}
+	options.Auth.Roles = []config.AuthRoleConfig{
+		{Entity: "viewer", Role: config.AuthRoleViewer, Resources: []string{"s1"}},
+		{Entity: "operator", Role: config.AuthRoleOperator},
+		{Entity: "sensors", Role: config.AuthRoleOperator, Resources: []string{"s1"}},
+	}
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	dial := func(t *testing.T, keyID, key string, opts ...rpc.DialOption) rpc.ClientConn {
+		t.Helper()
+		conn, err := rgrpc.Dial(ctx, addr, logger, append([]rpc.DialOption{
+			rpc.WithAllowInsecureWithCredentialsDowngrade(),
+			rpc.WithEntityCredentials(keyID, rpc.Credentials{Type: rpc.CredentialsTypeAPIKey, Payload: key}),
+		}, opts...)...)
+		test.That(t, err, test.ShouldBeNil)
+		t.Cleanup(func() {
+			test.That(t, conn.Close(), test.ShouldBeNil)
+		})
+		return conn
+	}
+	readings := func(conn rpc.ClientConn, name string) error {
+		_, err := sensorpb.NewSensorServiceClient(conn).GetReadings(ctx, &commonpb.GetReadingsRequest{Name: name})
+		return err
+	}
+	doCommand := func(conn rpc.ClientConn, name string, cmd map[string]interface{}) error {
+		cmdPb, err := protoutils.StructToStructPb(cmd)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = genericpb.NewGenericServiceClient(conn).DoCommand(ctx, &commonpb.DoCommandRequest{Name: name, Command: cmdPb})
+		return err
+	}
+	setPower := func(conn rpc.ClientConn) error {
+		_, err := basepb.NewBaseServiceClient(conn).SetPower(ctx, &basepb.SetPowerRequest{
+			Name:   "base1",
+			Linear: &commonpb.Vector3{Y: 1},
+		})
+		return err
+	}
+
+	t.Run("viewer", func(t *testing.T) {
+		conn := dial(t, "viewer", "viewerkey", rpc.WithForceDirectGRPC())
+		_, err := robotpb.NewRobotServiceClient(conn).ResourceNames(ctx, &robotpb.ResourceNamesRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readings(conn, "s1"), test.ShouldBeNil)
+
+		err = readings(conn, "s2")
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `not allowed to access resource "s2"`)
+
+		err = setPower(conn)
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "the viewer role may not call")
+	})
+
+	t.Run("operator", func(t *testing.T) {
+		conn := dial(t, "operator", "operatorkey", rpc.WithForceDirectGRPC())
+		test.That(t, readings(conn, "s2"), test.ShouldBeNil)
+		test.That(t, setPower(conn), test.ShouldBeNil)
+
+		_, err := robotpb.NewRobotServiceClient(conn).Shutdown(ctx, &robotpb.ShutdownRequest{})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		_, err = support.CreateBundle(ctx, conn, support.Options{})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+
+		err = doCommand(conn, tunnels.PublicServiceName.ShortName(), map[string]interface{}{
+			tunnels.DoOpen: map[string]interface{}{"port": 22},
+		})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		ttes, err := tunnels.List(ctx, r)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ttes, test.ShouldBeEmpty)
+
+		// jobs run as the robot, so an operator could otherwise reach the shell service through one
+		shellJob := map[string]interface{}{
+			"kind": string(automation.KindJob),
+			"job": map[string]interface{}{
+				"name":     "shell",
+				"schedule": "continuous",
+				"resource": "shell",
+				"method":   "DoCommand",
+				"command":  map[string]interface{}{"exec": "id"},
+			},
+		}
+		err = doCommand(conn, automation.PublicServiceName.ShortName(), map[string]interface{}{automation.DoCreate: shellJob})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		err = doCommand(conn, automation.PublicServiceName.ShortName(), map[string]interface{}{
+			automation.DoRunNow: map[string]interface{}{"kind": string(automation.KindJob), "name": "shell"},
+		})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		entries, err := automation.List(ctx, r)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, entries, test.ShouldBeEmpty)
+	})
+
+	t.Run("viewer over webrtc", func(t *testing.T) {
+		conn := dial(t, "viewer", "viewerkey", rpc.WithDisableDirectGRPC())
+		test.That(t, readings(conn, "s1"), test.ShouldBeNil)
+
+		err := readings(conn, "s2")
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+
+		err = setPower(conn)
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "the viewer role may not call")
+
+		_, err = robotpb.NewRobotServiceClient(conn).Shutdown(ctx, &robotpb.ShutdownRequest{})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+	})
+
+	t.Run("operator over webrtc", func(t *testing.T) {
+		conn := dial(t, "operator", "operatorkey", rpc.WithDisableDirectGRPC())
+		test.That(t, setPower(conn), test.ShouldBeNil)
+
+		_, err := robotpb.NewRobotServiceClient(conn).Shutdown(ctx, &robotpb.ShutdownRequest{})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+	})
+
+	t.Run("resources named in a batch", func(t *testing.T) {
+		conn := dial(t, "sensors", "sensorskey", rpc.WithForceDirectGRPC())
+		results, err := batch.Query(ctx, conn, []batch.Call{{Name: sensor.Named("s1"), Method: batch.MethodReadings}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, results[0].Err, test.ShouldBeNil)
+
+		_, err = batch.Query(ctx, conn, []batch.Call{
+			{Name: sensor.Named("s1"), Method: batch.MethodReadings},
+			{Name: sensor.Named("s2"), Method: batch.MethodReadings},
+		})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		test.That(t, err.Error(), test.ShouldContainSubstring, `not allowed to access resource "s2"`)
+	})
+}
@@ -0,0 +1,203 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// configRollbackCheckInterval is how often a new config is checked for whether its grace period has passed.
+const configRollbackCheckInterval = time.Second
+
+// The alert raised when a config is rolled back, which is cleared once a new config proves good.
+const (
+	configRollbackAlertSource = "config"
+	configRollbackAlertName   = "config_rollback"
+)
+
+// configRollback tracks the cloud config being checked against the health criteria of its
+// ConfigRollbackConfig.
+type configRollback struct {
+	mu          sync.Mutex
+	history     *config.ConfigHistory
+	historyID   string
+	historySize int
+	// pending is the revision of the config being checked, which happens once deadline passes.
+	pending  string
+	deadline time.Time
+}
+
+// openHistory must be called with mu held.
+func (cr *configRollback) openHistory(cloud *config.Cloud, r *localRobot) *config.ConfigHistory {
+	size := cloud.ConfigRollback.HistorySize()
+	if cr.history == nil || cr.historyID != cloud.ID || cr.historySize != size {
+		cr.history = config.OpenConfigHistory(cloud.ID, size, r.logger)
+		cr.historyID, cr.historySize = cloud.ID, size
+	}
+	return cr.history
+}
+
+// admitConfig records a new cloud config in the config history before it is applied and starts its
+// grace period. It returns the config to apply instead, which is the last known-good config if the
+// new one was already rolled back once.
+func (r *localRobot) admitConfig(ctx context.Context, newConfig *config.Config) *config.Config {
+	if newConfig.Cloud == nil || newConfig.Cloud.ConfigRollback == nil {
+		return newConfig
+	}
+	r.configRollback.mu.Lock()
+	defer r.configRollback.mu.Unlock()
+	history := r.configRollback.openHistory(newConfig.Cloud, r)
+
+	switch history.State(newConfig.Revision) {
+	case config.ConfigStateBad:
+		good, err := history.LastGood(newConfig.Cloud, r.logger)
+		if err != nil {
+			r.logger.CErrorw(ctx, "Applying config revision that was rolled back before since there is no config to roll back to",
+				"revision", newConfig.Revision, "error", err)
+			return newConfig
+		}
+		r.logger.CWarnw(ctx, "Not applying config revision that was rolled back before",
+			"revision", newConfig.Revision, "applying_revision", good.Revision)
+		r.rollout.Set(newConfig.Revision, robot.ConfigRevisionRolledBack, nil)
+		return good
+	case config.ConfigStateGood:
+		r.configRollback.pending = ""
+		return newConfig
+	}
+
+	if err := history.Record(newConfig); err != nil {
+		r.logger.CWarnw(ctx, "Failed to record config in the config history", "revision", newConfig.Revision, "error", err)
+		return newConfig
+	}
+	if r.configRollback.pending != newConfig.Revision && history.State(newConfig.Revision) == config.ConfigStatePending {
+		r.configRollback.pending = newConfig.Revision
+		r.configRollback.deadline = time.Now().Add(newConfig.Cloud.ConfigRollback.GracePeriod())
+	}
+	return newConfig
+}
+
+// watchConfigHealth checks each new cloud config against its health criteria once its grace period
+// passes.
+func (r *localRobot) watchConfigHealth() {
+	for goutils.SelectContextOrWait(r.closeContext, configRollbackCheckInterval) {
+		r.checkConfigHealth(r.closeContext)
+	}
+}
+
+// checkConfigHealth marks the pending config good if the robot meets its health criteria, and
+// otherwise marks it bad and rolls back to the last known-good config.
+func (r *localRobot) checkConfigHealth(ctx context.Context) {
+	cfg := r.mostRecentCfg.Load().(config.Config)
+	r.configRollback.mu.Lock()
+	revision, history := r.configRollback.pending, r.configRollback.history
+	if revision == "" || time.Now().Before(r.configRollback.deadline) {
+		r.configRollback.mu.Unlock()
+		return
+	}
+	if cfg.Cloud == nil || cfg.Cloud.ConfigRollback == nil {
+		r.configRollback.pending = ""
+		r.configRollback.mu.Unlock()
+		return
+	}
+	if cfg.Revision != revision {
+		// the config has not been applied yet, such as when it is deferred until a maintenance
+		// window opens, so its grace period starts over.
+		r.configRollback.deadline = time.Now().Add(cfg.Cloud.ConfigRollback.GracePeriod())
+		r.configRollback.mu.Unlock()
+		return
+	}
+	r.configRollback.pending = ""
+	r.configRollback.mu.Unlock()
+
+	unhealthy, err := r.unhealthyResources(ctx, cfg.Cloud.ConfigRollback)
+	if err != nil {
+		r.logger.CWarnw(ctx, "Failed to check config against its health criteria", "revision", revision, "error", err)
+		return
+	}
+	if len(unhealthy) == 0 {
+		if err := history.MarkGood(revision); err != nil {
+			r.logger.CWarnw(ctx, "Failed to mark config as known-good", "revision", revision, "error", err)
+			return
+		}
+		r.logger.CInfow(ctx, "Config met its health criteria and is known-good", "revision", revision)
+		r.alerts.Clear(configRollbackAlertSource, configRollbackAlertName)
+		return
+	}
+
+	if err := history.MarkBad(revision); err != nil {
+		r.logger.CWarnw(ctx, "Failed to mark config as bad", "revision", revision, "error", err)
+	}
+	good, err := history.LastGood(cfg.Cloud, r.logger)
+	if err != nil {
+		r.logger.CErrorw(ctx, "Config failed its health criteria but cannot be rolled back",
+			"revision", revision, "unhealthy", unhealthy, "error", err)
+		r.raiseConfigRollbackAlert(revision, "", unhealthy)
+		return
+	}
+	r.logger.CErrorw(ctx, "Config failed its health criteria, rolling back",
+		"revision", revision, "rolled_back_to", good.Revision, "unhealthy", unhealthy)
+	r.raiseConfigRollbackAlert(revision, good.Revision, unhealthy)
+	r.rollout.Set(revision, robot.ConfigRevisionRolledBack, unhealthy)
+	r.rollout.Approve(good.Revision)
+	r.Reconfigure(ctx, good)
+}
+
+// unhealthyResources returns the resources that keep the robot from meeting the health criteria of
+// policy, which is none if it meets them.
+func (r *localRobot) unhealthyResources(ctx context.Context, policy *config.ConfigRollbackConfig) ([]string, error) {
+	status, err := r.MachineStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var unhealthy []string
+	ready := map[string]bool{}
+	for _, res := range status.Resources {
+		// internal resources, such as an engaged emergency stop, do not reflect on the config.
+		if res.Name.API.Type.Namespace == resource.APINamespaceRDKInternal {
+			continue
+		}
+		if res.State == resource.NodeStateUnhealthy {
+			unhealthy = append(unhealthy, res.Name.String())
+		}
+		isReady := res.State == resource.NodeStateReady
+		ready[res.Name.String()] = isReady
+		ready[res.Name.ShortName()] = ready[res.Name.ShortName()] || isReady
+	}
+	var missing []string
+	for _, name := range policy.CriticalResources {
+		if !ready[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(unhealthy) <= policy.MaxUnhealthyResources {
+		unhealthy = nil
+	}
+	return append(unhealthy, missing...), nil
+}
+
+func (r *localRobot) raiseConfigRollbackAlert(revision, rolledBackTo string, unhealthy []string) {
+	msg := fmt.Sprintf("config revision %s failed its health criteria (%s)", revision, strings.Join(unhealthy, ", "))
+	if rolledBackTo != "" {
+		msg += "; rolled back to revision " + rolledBackTo
+	} else {
+		msg += "; no known-good config to roll back to"
+	}
+	err := r.alerts.Raise(robot.Alert{
+		Source:   configRollbackAlertSource,
+		Name:     configRollbackAlertName,
+		Severity: robot.AlertError,
+		Message:  msg,
+		Metadata: map[string]interface{}{"revision": revision, "rolled_back_to": rolledBackTo},
+	})
+	if err != nil {
+		r.logger.Warnw("Failed to raise config rollback alert", "error", err)
+	}
+}
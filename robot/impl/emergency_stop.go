@@ -0,0 +1,101 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/robot/estop"
+)
+
+const defaultEmergencyStopPollInterval = 20 * time.Millisecond
+
+// EmergencyStop returns the robot's emergency stop latch.
+func (r *localRobot) EmergencyStop() *estop.Latch {
+	return r.estop
+}
+
+// EngageEmergencyStop latches the emergency stop, has the governor reject motion commands to every
+// actuator until it is reset, and stops every actuator.
+func (r *localRobot) EngageEmergencyStop(ctx context.Context, source, reason string) error {
+	if !r.estop.Engage(source, reason) {
+		return nil
+	}
+	r.logger.CErrorw(ctx, "emergency stop engaged", "source", source, "reason", reason)
+	r.governor.SetHalted(r.estop.Status().Err())
+	return r.StopAll(ctx, nil)
+}
+
+// ResetEmergencyStop releases the emergency stop unless its GPIO pin is still active.
+func (r *localRobot) ResetEmergencyStop(ctx context.Context) error {
+	if cfg := r.mostRecentCfg.Load().(config.Config).EmergencyStop; cfg != nil {
+		active, err := r.emergencyStopPinActive(ctx, cfg)
+		if err != nil {
+			return errors.Wrap(err, "cannot reset emergency stop without reading its pin")
+		}
+		if active {
+			return errors.Errorf("cannot reset emergency stop while pin %s on board %s is active", cfg.Pin, cfg.BoardName)
+		}
+	}
+	if r.estop.Reset() {
+		r.governor.SetHalted(nil)
+		r.logger.CInfo(ctx, "emergency stop reset")
+	}
+	return nil
+}
+
+// watchEmergencyStopPin polls the configured emergency stop pin and engages the emergency stop when it is active.
+func (r *localRobot) watchEmergencyStopPin() {
+	var lastErr string
+	for {
+		cfg := r.mostRecentCfg.Load().(config.Config).EmergencyStop
+		interval := defaultEmergencyStopPollInterval
+		if cfg != nil && cfg.PollIntervalMS > 0 {
+			interval = time.Duration(cfg.PollIntervalMS) * time.Millisecond
+		}
+		if !goutils.SelectContextOrWait(r.closeContext, interval) {
+			return
+		}
+		if cfg == nil || r.estop.Status().Engaged {
+			continue
+		}
+
+		active, err := r.emergencyStopPinActive(r.closeContext, cfg)
+		if err != nil {
+			if err.Error() != lastErr {
+				r.logger.Warnw("failed to read emergency stop pin", "board", cfg.BoardName, "pin", cfg.Pin, "error", err)
+				lastErr = err.Error()
+			}
+			continue
+		}
+		lastErr = ""
+		if !active {
+			continue
+		}
+		reason := fmt.Sprintf("pin %s on board %s", cfg.Pin, cfg.BoardName)
+		if err := r.EngageEmergencyStop(r.closeContext, estop.SourceGPIO, reason); err != nil {
+			r.logger.Errorw("failed to stop all resources for emergency stop", "error", err)
+		}
+	}
+}
+
+func (r *localRobot) emergencyStopPinActive(ctx context.Context, cfg *config.EmergencyStopConfig) (bool, error) {
+	b, err := board.FromProvider(r, cfg.BoardName)
+	if err != nil {
+		return false, err
+	}
+	pin, err := b.GPIOPinByName(cfg.Pin)
+	if err != nil {
+		return false, err
+	}
+	high, err := pin.Get(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	return high != cfg.ActiveLow, nil
+}
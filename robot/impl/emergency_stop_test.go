@@ -0,0 +1,146 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/config"
+	rgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/robot/estop"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/testutils/robottestutils"
+)
+
+func TestEmergencyStop(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+
+	var stops, moves atomic.Int32
+	dummyArm := &inject.Arm{
+		StopFunc: func(ctx context.Context, extra map[string]interface{}) error {
+			stops.Add(1)
+			return nil
+		},
+		JointPositionsFunc: func(ctx context.Context, extra map[string]interface{}) ([]referenceframe.Input, error) {
+			return []referenceframe.Input{}, nil
+		},
+		MoveToJointPositionsFunc: func(ctx context.Context, positions []referenceframe.Input, extra map[string]interface{}) error {
+			moves.Add(1)
+			return nil
+		},
+	}
+	resource.RegisterComponent(
+		arm.API,
+		model,
+		resource.Registration[arm.Arm, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (arm.Arm, error) {
+			return dummyArm, nil
+		}})
+	defer func() {
+		resource.Deregister(arm.API, model)
+	}()
+
+	cfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+		"components": [
+			{"model": "%s", "name": "arm1", "type": "arm"},
+			{"model": "fake", "name": "board1", "type": "board"},
+			{"model": "fake", "name": "gripper1", "type": "gripper", "depends_on": ["arm1"]}
+		],
+		"emergency_stop": {"board": "board1", "pin": "37", "poll_interval_ms": 5}
+	}`, model.String())), logger, nil)
+	test.That(t, err, test.ShouldBeNil)
+	r := setupLocalRobot(t, ctx, cfg, logger)
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+	conn, err := rgrpc.Dial(ctx, addr, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer utils.UncheckedErrorFunc(conn.Close)
+	armClient, err := arm.NewClientFromConn(ctx, conn, "", arm.Named("arm1"), logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, armClient.MoveToJointPositions(ctx, []referenceframe.Input{}, nil), test.ShouldBeNil)
+	test.That(t, moves.Load(), test.ShouldEqual, 1)
+
+	// the emergency stop is controlled the same way through a robot client
+	rc, err := client.New(ctx, addr, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, rc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// engaging stops everything and rejects further motion requests
+	test.That(t, estop.Engage(ctx, rc, "test"), test.ShouldBeNil)
+	test.That(t, stops.Load(), test.ShouldEqual, 1)
+	ms, err := r.MachineStatus(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	st := estop.FromMachineStatus(ms)
+	test.That(t, st.Engaged, test.ShouldBeTrue)
+	test.That(t, st.Source, test.ShouldEqual, estop.SourceAPI)
+	test.That(t, st.Reason, test.ShouldEqual, "test")
+	st, err = estop.GetStatus(ctx, rc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Engaged, test.ShouldBeTrue)
+	test.That(t, st.Reason, test.ShouldEqual, "test")
+
+	err = armClient.MoveToJointPositions(ctx, []referenceframe.Input{}, nil)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.FailedPrecondition)
+	test.That(t, moves.Load(), test.ShouldEqual, 1)
+
+	// motion commanded from within the robot through dependencies is rejected too
+	lr := r.(*localRobot)
+	node, ok := lr.manager.resources.Node(gripper.Named("gripper1"))
+	test.That(t, ok, test.ShouldBeTrue)
+	deps, err := lr.getDependencies(gripper.Named("gripper1"), node)
+	test.That(t, err, test.ShouldBeNil)
+	depArm, err := arm.FromProvider(deps, "arm1")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, depArm.MoveToJointPositions(ctx, []referenceframe.Input{}, nil), test.ShouldWrap, estop.ErrEngaged)
+	test.That(t, moves.Load(), test.ShouldEqual, 1)
+
+	test.That(t, estop.Reset(ctx, rc), test.ShouldBeNil)
+	ms, err = r.MachineStatus(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, estop.FromMachineStatus(ms).Engaged, test.ShouldBeFalse)
+	test.That(t, armClient.MoveToJointPositions(ctx, []referenceframe.Input{}, nil), test.ShouldBeNil)
+	test.That(t, moves.Load(), test.ShouldEqual, 2)
+
+	// the GPIO pin engages the emergency stop, which cannot be reset while the pin stays active
+	b, err := board.FromProvider(r, "board1")
+	test.That(t, err, test.ShouldBeNil)
+	pin, err := b.GPIOPinByName("37")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pin.Set(ctx, true, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		ms, err := r.MachineStatus(ctx)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, estop.FromMachineStatus(ms).Source, test.ShouldEqual, estop.SourceGPIO)
+	})
+	test.That(t, estop.Reset(ctx, r), test.ShouldNotBeNil)
+	test.That(t, pin.Set(ctx, false, nil), test.ShouldBeNil)
+	test.That(t, estop.Reset(ctx, r), test.ShouldBeNil)
+	ms, err = r.MachineStatus(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, estop.FromMachineStatus(ms).Engaged, test.ShouldBeFalse)
+}
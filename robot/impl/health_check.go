@@ -0,0 +1,250 @@
+package robotimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+)
+
+// healthCheckPollInterval is how often the health monitor looks for checks that are due. It bounds
+// how precisely a resource's configured interval is honored.
+const healthCheckPollInterval = 100 * time.Millisecond
+
+// healthTracker holds the health check state of a single resource across restarts.
+type healthTracker struct {
+	conf *resource.HealthCheckConfig
+	// resConf is the config of the resource, which resets the restart limit when it changes.
+	resConf resource.Config
+	// res is the instance that the in-flight check and failure count apply to.
+	res         resource.Resource
+	checking    bool
+	restarting  bool
+	nextCheck   time.Time
+	failures    int
+	restarts    int
+	lastRestart time.Time
+	gaveUp      bool
+}
+
+type healthCheckResult struct {
+	name resource.Name
+	res  resource.Resource
+	err  error
+}
+
+// monitorResourceHealth periodically checks every ready resource that has a health check configured
+// and rebuilds resources that fail too many consecutive checks, following their restart policy.
+func (r *localRobot) monitorResourceHealth() {
+	trackers := map[resource.Name]*healthTracker{}
+	results := make(chan healthCheckResult)
+	restarted := make(chan resource.Name)
+	ticker := time.NewTicker(healthCheckPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.closeContext.Done():
+			return
+		case result := <-results:
+			r.handleHealthCheckResult(trackers, result, restarted, time.Now())
+		case name := <-restarted:
+			if tracker, ok := trackers[name]; ok {
+				tracker.restarting = false
+			}
+		case <-ticker.C:
+			r.startDueHealthChecks(trackers, results, time.Now())
+		}
+	}
+}
+
+func (r *localRobot) startDueHealthChecks(
+	trackers map[resource.Name]*healthTracker,
+	results chan<- healthCheckResult,
+	now time.Time,
+) {
+	seen := map[resource.Name]struct{}{}
+	for _, name := range r.manager.resources.Names() {
+		if name.ContainsRemoteNames() {
+			continue
+		}
+		node, ok := r.manager.resources.Node(name)
+		if !ok {
+			continue
+		}
+		resConf := node.Config()
+		conf := resConf.HealthCheck
+		if conf == nil || node.State() != resource.NodeStateReady {
+			continue
+		}
+		res, err := node.Resource()
+		if err != nil {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		tracker, ok := trackers[name]
+		if !ok {
+			tracker = &healthTracker{resConf: resConf}
+			trackers[name] = tracker
+		}
+		if !tracker.resConf.Equals(resConf) {
+			// restarts by the tracker keep the config, so a change means the resource was reconfigured.
+			tracker.resConf = resConf
+			tracker.restarts = 0
+			tracker.lastRestart = time.Time{}
+			tracker.gaveUp = false
+		}
+		tracker.conf = conf
+		if tracker.restarting {
+			continue
+		}
+		if tracker.res != res {
+			// a new instance starts with a clean slate; any check still running against the old one is ignored.
+			tracker.res = res
+			tracker.checking = false
+			tracker.failures = 0
+			tracker.nextCheck = now.Add(conf.Interval())
+		}
+		if tracker.checking || now.Before(tracker.nextCheck) {
+			continue
+		}
+		tracker.checking = true
+		tracker.nextCheck = now.Add(conf.Interval())
+		r.checkResourceHealth(name, res, conf.Timeout(), results)
+	}
+	for name := range trackers {
+		if _, ok := seen[name]; !ok {
+			delete(trackers, name)
+		}
+	}
+}
+
+// checkResourceHealth checks the resource in the background and reports the result. A check that
+// does not respect its context is abandoned once the timeout passes so it cannot block the robot from closing.
+func (r *localRobot) checkResourceHealth(
+	name resource.Name,
+	res resource.Resource,
+	timeout time.Duration,
+	results chan<- healthCheckResult,
+) {
+	r.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(func() {
+		ctx, cancel := context.WithTimeout(r.closeContext, timeout)
+		defer cancel()
+		checkErr := make(chan error, 1)
+		goutils.PanicCapturingGo(func() {
+			checkErr <- resource.CheckHealth(ctx, res)
+		})
+		var err error
+		select {
+		case err = <-checkErr:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		select {
+		case results <- healthCheckResult{name: name, res: res, err: err}:
+		case <-r.closeContext.Done():
+		}
+	}, r.activeBackgroundWorkers.Done)
+}
+
+func (r *localRobot) handleHealthCheckResult(
+	trackers map[resource.Name]*healthTracker,
+	result healthCheckResult,
+	restarted chan<- resource.Name,
+	now time.Time,
+) {
+	tracker, ok := trackers[result.name]
+	if !ok || tracker.res != result.res {
+		return
+	}
+	tracker.checking = false
+
+	if result.err == nil {
+		if tracker.failures > 0 {
+			r.logger.Infow("resource passed health check again", "resource", result.name)
+		}
+		tracker.failures = 0
+		if tracker.restarts > 0 && now.Sub(tracker.lastRestart) >= tracker.conf.RestartReset() {
+			tracker.restarts = 0
+			tracker.gaveUp = false
+		}
+		return
+	}
+
+	tracker.failures++
+	r.logger.Warnw("resource failed health check",
+		"resource", result.name, "consecutive_failures", tracker.failures, "error", result.err)
+	if tracker.failures < tracker.conf.Threshold() || tracker.gaveUp {
+		return
+	}
+	if limit := tracker.conf.RestartLimit(); limit >= 0 && tracker.restarts >= limit {
+		tracker.gaveUp = true
+		r.logger.Errorw("resource is still failing health checks but has reached its restart limit; "+
+			"it will not be restarted again until it is reconfigured", "resource", result.name, "restarts", tracker.restarts)
+		return
+	}
+	if now.Before(tracker.lastRestart.Add(tracker.conf.RestartBackoff(tracker.restarts))) {
+		return
+	}
+
+	tracker.restarts++
+	tracker.lastRestart = now
+	tracker.failures = 0
+	r.logger.Warnw("restarting resource after failed health checks",
+		"resource", result.name, "restart", tracker.restarts, "error", result.err)
+	tracker.restarting = true
+	r.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(func() {
+		r.restartResource(result.name, result.res)
+		select {
+		case restarted <- result.name:
+		case <-r.closeContext.Done():
+		}
+	}, r.activeBackgroundWorkers.Done)
+}
+
+// restartResource closes the resource and rebuilds it from its current config, unless it has
+// already been replaced. It runs in the background since it waits for any reconfiguration in progress.
+func (r *localRobot) restartResource(name resource.Name, res resource.Resource) {
+	r.reconfigurationLock.Lock()
+	defer r.reconfigurationLock.Unlock()
+	if r.closeContext.Err() != nil {
+		return
+	}
+
+	node, ok := r.manager.resources.Node(name)
+	if !ok {
+		return
+	}
+	if current, err := node.UnsafeResource(); err != nil || current != res {
+		return
+	}
+	if err := r.closeUnhealthyResource(res); err != nil {
+		r.logger.Warnw("error closing unhealthy resource before restart", "resource", name, "error", err)
+	}
+	r.manager.markRebuildResources([]resource.Name{name})
+	r.manager.completeConfig(r.closeContext, r, false)
+	r.updateWeakAndOptionalDependents(r.closeContext)
+}
+
+// closeUnhealthyResource closes a resource that may be wedged. The close is abandoned once
+// resourceCloseTimeout passes, even if the resource ignores its context, so that it cannot hold the
+// reconfiguration lock forever.
+func (r *localRobot) closeUnhealthyResource(res resource.Resource) error {
+	ctx, cancel := context.WithTimeout(r.closeContext, resourceCloseTimeout)
+	defer cancel()
+	closeErr := make(chan error, 1)
+	goutils.PanicCapturingGo(func() {
+		closeErr <- r.manager.closeResource(ctx, res)
+	})
+	select {
+	case err := <-closeErr:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "gave up waiting for resource to close")
+	}
+}
@@ -0,0 +1,110 @@
+package robotimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestResourceHealthCheckRestart(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+
+	// a wedged resource also hangs on close without respecting its context
+	oldCloseTimeout := resourceCloseTimeout
+	resourceCloseTimeout = 10 * time.Millisecond
+	release := make(chan struct{})
+	unwedgeClose := sync.OnceFunc(func() { close(release) })
+	defer func() {
+		unwedgeClose()
+		resourceCloseTimeout = oldCloseTimeout
+	}()
+
+	var constructed atomic.Int32
+	var wedged atomic.Bool
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			constructed.Add(1)
+			s := inject.NewSensor(conf.ResourceName().Name)
+			s.StatusFunc = func(ctx context.Context) (map[string]interface{}, error) {
+				if wedged.Load() {
+					return nil, errors.New("wedged")
+				}
+				return map[string]interface{}{}, nil
+			}
+			s.CloseFunc = func(ctx context.Context) error {
+				if wedged.Load() {
+					<-release
+				}
+				return nil
+			}
+			return s, nil
+		}})
+	defer func() {
+		resource.Deregister(sensor.API, model)
+	}()
+
+	sensorConfig := func(threshold int) *config.Config {
+		cfg, err := config.FromReader(ctx, "", strings.NewReader(fmt.Sprintf(`{
+			"components": [{
+				"model": "%s",
+				"name": "sensor1",
+				"type": "sensor",
+				"health_check": {"interval_ms": 1, "failure_threshold": %d, "max_restarts": 2, "restart_backoff_ms": 1}
+			}]
+		}`, model.String(), threshold)), logger, nil)
+		test.That(t, err, test.ShouldBeNil)
+		return cfg
+	}
+	r := setupLocalRobot(t, ctx, sensorConfig(2), logger)
+	test.That(t, constructed.Load(), test.ShouldEqual, 1)
+
+	// a healthy resource is left alone
+	time.Sleep(3 * healthCheckPollInterval)
+	test.That(t, constructed.Load(), test.ShouldEqual, 1)
+
+	// a wedged resource is rebuilt until it reaches its restart limit
+	wedged.Store(true)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, constructed.Load(), test.ShouldEqual, 3)
+	})
+	time.Sleep(5 * healthCheckPollInterval)
+	test.That(t, constructed.Load(), test.ShouldEqual, 3)
+
+	_, err := sensor.FromProvider(r, "sensor1")
+	test.That(t, err, test.ShouldBeNil)
+
+	// reconfiguring the resource gives it a fresh restart limit
+	unwedgeClose()
+	r.Reconfigure(ctx, sensorConfig(3))
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, constructed.Load(), test.ShouldEqual, 6)
+	})
+	time.Sleep(5 * healthCheckPollInterval)
+	test.That(t, constructed.Load(), test.ShouldEqual, 6)
+}
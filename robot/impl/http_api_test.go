@@ -0,0 +1,66 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestHTTPAPI(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	apiKeyID := "sosecretID"
+	apiKey := "sosecret"
+
+	startWeb := func(t *testing.T, disabled bool) string {
+		t.Helper()
+		r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+		options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+		options.Network.DisableHTTPAPI = disabled
+		options.Auth.Handlers = []config.AuthHandlerConfig{
+			{
+				Type: rpc.CredentialsTypeAPIKey,
+				Config: rutils.AttributeMap{
+					apiKeyID: apiKey,
+					"keys":   []string{apiKeyID},
+				},
+			},
+		}
+		test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+		return fmt.Sprintf("http://%s/api/v1/machine_status", addr)
+	}
+
+	get := func(t *testing.T, url, keyID, key string) int {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		test.That(t, err, test.ShouldBeNil)
+		if keyID != "" {
+			req.SetBasicAuth(keyID, key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.Body.Close(), test.ShouldBeNil)
+		return resp.StatusCode
+	}
+
+	t.Run("api key", func(t *testing.T) {
+		url := startWeb(t, false)
+		test.That(t, get(t, url, "", ""), test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, get(t, url, apiKeyID, "wrong"), test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, get(t, url, apiKeyID, apiKey), test.ShouldEqual, http.StatusOK)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		url := startWeb(t, true)
+		test.That(t, get(t, url, apiKeyID, apiKey), test.ShouldNotEqual, http.StatusOK)
+	})
+}
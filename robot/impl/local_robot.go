@@ -52,13 +52,23 @@ import (
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/alerts"
+	"go.viam.com/rdk/robot/arbiter"
+	"go.viam.com/rdk/robot/automation"
 	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/robot/estop"
 	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/robot/governor"
 	"go.viam.com/rdk/robot/jobmanager"
+	"go.viam.com/rdk/robot/maintenance"
 	"go.viam.com/rdk/robot/packages"
+	"go.viam.com/rdk/robot/rollout"
+	"go.viam.com/rdk/robot/shutdown"
+	"go.viam.com/rdk/robot/tunnels"
 	"go.viam.com/rdk/robot/web"
 	weboptions "go.viam.com/rdk/robot/web/options"
 	"go.viam.com/rdk/services/datamanager"
+	"go.viam.com/rdk/services/orchestration"
 	"go.viam.com/rdk/session"
 	"go.viam.com/rdk/utils"
 )
@@ -115,6 +125,7 @@ type localRobot struct {
 	configTicker               *time.Ticker
 	revealSensitiveConfigDiffs bool
 	shutdownCallback           func()
+	recentLogs                 *logging.RecentLogsAppender
 
 	// lastWeakAndOptionalDependentsRound stores the value of the resource graph's
 	// logical clock when updateWeakAndOptionalDependents was called.
@@ -128,6 +139,7 @@ type localRobot struct {
 	// internal services that are in the graph but we also hold onto
 	webSvc   web.Service
 	frameSvc framesystem.Service
+	governor governor.Service
 
 	// map keyed by Module.Name. This is necessary to get the package manager to use a new folder
 	// when a local tarball is updated.
@@ -137,6 +149,8 @@ type localRobot struct {
 
 	// whether the robot is actively reconfiguring
 	reconfiguring atomic.Bool
+	// counts and times the reconfigurations that were allowed to run, for metrics.
+	reconfigures reconfigureMetrics
 
 	// whether the robot is still initializing. this value controls what state will be
 	// returned by the MachineStatus endpoint (initializing if true, running if false.)
@@ -144,6 +158,30 @@ type localRobot struct {
 	initializing atomic.Bool
 
 	traceClients atomic.Pointer[[]otlptrace.Client]
+
+	estop    *estop.Latch
+	estopSvc resource.Resource
+
+	arbiterSvc resource.Resource
+
+	tunnels    *tunnels.Manager
+	tunnelsSvc resource.Resource
+
+	automations    *automation.Manager
+	automationsSvc resource.Resource
+
+	alerts    alerts.Service
+	alertsSvc resource.Resource
+
+	maintenance    *maintenance.Mode
+	maintenanceSvc resource.Resource
+
+	configRollback configRollback
+
+	rollout    *rollout.Tracker
+	rolloutSvc resource.Resource
+
+	resourceEvents *resourceEventBroadcaster
 }
 
 // ExportResourcesAsDot exports the resource graph as a DOT representation for
@@ -153,6 +191,17 @@ func (r *localRobot) ExportResourcesAsDot(index int) (resource.GetSnapshotInfo,
 	return r.manager.ExportDot(index)
 }
 
+// ExportResourceGraph exports the current resource graph, including remote and modular
+// resources, with the lifecycle state and last error of every resource.
+func (r *localRobot) ExportResourceGraph() resource.GraphExport {
+	return r.manager.ExportGraph()
+}
+
+// ExportResourceGraphDot exports the current resource graph as a DOT representation.
+func (r *localRobot) ExportResourceGraphDot() (string, error) {
+	return r.manager.resources.ExportDot()
+}
+
 // RemoteByName returns a remote robot by name. If it does not exist
 // nil is returned.
 func (r *localRobot) RemoteByName(name string) (robot.Robot, bool) {
@@ -198,11 +247,41 @@ func (r *localRobot) UploadDataFromPath(ctx context.Context, path string, md *da
 	return uploader.UploadDataFromPath(ctx, path, md, extra)
 }
 
+// RecentLogs returns the most recent lines logged by the robot, if it was created with
+// WithRecentLogs.
+func (r *localRobot) RecentLogs() []string {
+	if r.recentLogs == nil {
+		return nil
+	}
+	return r.recentLogs.Lines()
+}
+
 // FindBySimpleNameAndAPI finds a resource by its simple name and API. This is queried
 // through the resourceGetterForAPI for _all_ incoming gRPC requests related to a
 // resource. A nil resource and an error is returned in the case of no resource found, or
 // multiple matching remote resources found.
 func (r *localRobot) FindBySimpleNameAndAPI(name string, api resource.API) (resource.Resource, error) {
+	if name == estop.PublicServiceName.Name && api == estop.PublicServiceName.API {
+		return r.estopSvc, nil
+	}
+	if name == arbiter.PublicServiceName.Name && api == arbiter.PublicServiceName.API {
+		return r.arbiterSvc, nil
+	}
+	if name == tunnels.PublicServiceName.Name && api == tunnels.PublicServiceName.API {
+		return r.tunnelsSvc, nil
+	}
+	if name == automation.PublicServiceName.Name && api == automation.PublicServiceName.API {
+		return r.automationsSvc, nil
+	}
+	if name == alerts.PublicServiceName.Name && api == alerts.PublicServiceName.API {
+		return r.alertsSvc, nil
+	}
+	if name == maintenance.PublicServiceName.Name && api == maintenance.PublicServiceName.API {
+		return r.maintenanceSvc, nil
+	}
+	if name == rollout.PublicServiceName.Name && api == rollout.PublicServiceName.API {
+		return r.rolloutSvc, nil
+	}
 	n, err := r.manager.resources.FindBySimpleNameAndAPI(name, api)
 	if err != nil {
 		return nil, err
@@ -227,6 +306,24 @@ func (r *localRobot) RemoteNames() []string {
 	return r.manager.RemoteNames()
 }
 
+// StreamsCamera returns whether the camera with the given short name is streamed. The cameras of a
+// remote are not streamed if the remote's config disables proxying its streams.
+func (r *localRobot) StreamsCamera(name string) bool {
+	remoteName, _, isRemote := strings.Cut(name, ":")
+	if !isRemote {
+		return true
+	}
+	gNode, ok := r.manager.resources.Node(fromRemoteNameToRemoteNodeName(remoteName))
+	if !ok {
+		return true
+	}
+	remoteConf, ok := gNode.Config().ConvertedAttributes.(*config.Remote)
+	if !ok {
+		return true
+	}
+	return remoteConf.Resources.ProxiesStreams()
+}
+
 // ResourceNames returns the names of all known resources.
 func (r *localRobot) ResourceNames() []resource.Name {
 	return r.manager.ResourceNames()
@@ -278,7 +375,7 @@ func (r *localRobot) Close(ctx context.Context) error {
 	}
 	if r.manager != nil {
 		r.reconfigurationLock.Lock()
-		err = multierr.Combine(err, r.manager.Close(ctx))
+		err = multierr.Combine(err, r.manager.Shutdown(ctx, shutdown.NewManager(r.shutdownConfig(ctx), r.logger)))
 		r.reconfigurationLock.Unlock()
 	}
 	if r.packageManager != nil {
@@ -299,6 +396,29 @@ func (r *localRobot) Close(ctx context.Context) error {
 	return err
 }
 
+// shutdownConfig returns how the robot's resources are shut down. While the emergency stop is
+// engaged, resources are only stopped rather than moved to a safe state.
+func (r *localRobot) shutdownConfig(ctx context.Context) *config.ShutdownConfig {
+	cfg, ok := r.mostRecentCfg.Load().(config.Config)
+	if !ok || cfg.Shutdown == nil {
+		return nil
+	}
+	if !r.estop.Status().Engaged {
+		return cfg.Shutdown
+	}
+	shutdownCfg := *cfg.Shutdown
+	shutdownCfg.Resources = make([]config.ShutdownResourceConfig, 0, len(cfg.Shutdown.Resources))
+	for _, resCfg := range cfg.Shutdown.Resources {
+		if resCfg.SafeState != "" && resCfg.SafeState != config.SafeStateStop {
+			r.logger.CWarnw(ctx, "Emergency stop engaged, stopping resource instead of moving it to its safe state",
+				"resource", resCfg.Name, "safe_state", resCfg.SafeState)
+			resCfg.SafeState = config.SafeStateStop
+		}
+		shutdownCfg.Resources = append(shutdownCfg.Resources, resCfg)
+	}
+	return &shutdownCfg
+}
+
 // Kill will attempt to kill any processes on the system started by the robot as quickly as possible.
 // This operation is not clean and will not wait for completion.
 func (r *localRobot) Kill() {
@@ -503,6 +623,9 @@ func newWithResources(
 		if statser, err := sys.NewNetUsageStatser(); err == nil {
 			ftdcWorker.Add("net", statser)
 		}
+		for name, statser := range rOpts.ftdcStatsers {
+			ftdcWorker.Add(name, statser)
+		}
 	}
 
 	homeDir := utils.ViamDotDir
@@ -524,6 +647,7 @@ func newWithResources(
 				untrustedEnv:       cfg.UntrustedEnv,
 				tlsConfig:          cfg.Network.TLSConfig,
 				ftdc:               ftdcWorker,
+				moduleDevMode:      rOpts.moduleDevMode,
 			},
 			logger,
 		),
@@ -538,9 +662,23 @@ func newWithResources(
 		revealSensitiveConfigDiffs: rOpts.revealSensitiveConfigDiffs,
 		cloudConnSvc:               icloud.NewCloudConnectionService(cfg.Cloud, conn, logger),
 		shutdownCallback:           rOpts.shutdownCallback,
+		recentLogs:                 rOpts.recentLogs,
 		localModuleVersions:        make(map[string]semver.Version),
 		ftdc:                       ftdcWorker,
+		estop:                      estop.NewLatch(),
+		alerts:                     alerts.New(),
+		maintenance:                maintenance.NewMode(),
+		rollout:                    rollout.NewTracker(),
+		resourceEvents:             newResourceEventBroadcaster(logger),
+	}
+	r.alertsSvc = alerts.NewService(r.alerts)
+	r.maintenanceSvc = maintenance.NewService(r)
+	r.rolloutSvc = rollout.NewService(r)
+	// the alerts of a resource no longer apply once it is removed.
+	r.resourceEvents.onRemoved = func(name resource.Name) {
+		r.alerts.ClearSource(name.String())
 	}
+	r.manager.resources.SetObserver(r.resourceEvents)
 
 	r.mostRecentCfg.Store(config.Config{})
 
@@ -550,7 +688,18 @@ func newWithResources(
 	} else {
 		heartbeatWindow = cfg.Network.Sessions.HeartbeatWindow
 	}
-	r.sessionManager = robot.NewSessionManager(r, heartbeatWindow)
+	sessionManager := robot.NewSessionManager(r, heartbeatWindow)
+	r.sessionManager = sessionManager
+	r.arbiterSvc = arbiter.NewService(sessionManager)
+	r.tunnels = tunnels.NewManager()
+	r.tunnelsSvc = tunnels.NewService(r.tunnels)
+	automationsPath := filepath.Join(homeDir, "automation", partID+".json")
+	if r.automations, err = automation.NewManager(automationsPath); err != nil {
+		logger.CErrorw(ctx, "Failed to load the automation changes made at runtime, keeping new changes in memory only",
+			"error", err)
+		r.automations, _ = automation.NewManager("")
+	}
+	r.automationsSvc = automation.NewService(r)
 
 	var successful bool
 	defer func() {
@@ -593,6 +742,8 @@ func newWithResources(
 	if err != nil {
 		return nil, err
 	}
+	r.governor = governor.New()
+	r.estopSvc = estop.NewService(r)
 
 	// now that we're changing the resource graph, take the reconfigurationLock so
 	// that other goroutines can't interleave
@@ -608,6 +759,16 @@ func newWithResources(
 		resource.NewConfiguredGraphNode(resource.Config{}, r.frameSvc, builtinModel)); err != nil {
 		return nil, err
 	}
+	if err := r.manager.resources.AddNode(
+		governor.InternalServiceName,
+		resource.NewConfiguredGraphNode(resource.Config{}, r.governor, builtinModel)); err != nil {
+		return nil, err
+	}
+	if err := r.manager.resources.AddNode(
+		alerts.InternalServiceName,
+		resource.NewConfiguredGraphNode(resource.Config{}, r.alerts, builtinModel)); err != nil {
+		return nil, err
+	}
 	if err := r.manager.resources.AddNode(
 		r.packageManager.Name(),
 		resource.NewConfiguredGraphNode(resource.Config{}, r.packageManager, builtinModel)); err != nil {
@@ -654,6 +815,18 @@ func newWithResources(
 		}, r.activeBackgroundWorkers.Done)
 	}
 
+	r.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(r.watchEmergencyStopPin, r.activeBackgroundWorkers.Done)
+
+	r.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(r.applyDeferredConfig, r.activeBackgroundWorkers.Done)
+
+	r.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(r.watchConfigHealth, r.activeBackgroundWorkers.Done)
+
+	r.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(r.monitorResourceHealth, r.activeBackgroundWorkers.Done)
+
 	// getResource is passed in to the jobmanager to have access to the resource graph.
 	getResource := func(res string) (resource.Resource, error) {
 		var found bool
@@ -680,7 +853,7 @@ func newWithResources(
 	}
 	r.jobManager = jobManager
 
-	r.reconfigure(ctx, cfg, false)
+	r.reconfigure(ctx, r.automationConfig(ctx, r.admitConfig(ctx, cfg)), false)
 
 	for name, res := range resources {
 		node := resource.NewConfiguredGraphNode(resource.Config{}, res, unknownModel)
@@ -828,6 +1001,12 @@ func (r *localRobot) getDependenciesWithWeakOptionalSnapshot(
 	for name, clock := range optSnap {
 		weakAndOptionalDepsSnapshot[name] = clock
 	}
+	// motion commanded by other resources is subject to the governor just like motion commanded over gRPC.
+	if r.governor != nil {
+		for name, res := range allDeps {
+			allDeps[name] = governor.Wrap(r.governor, name, res)
+		}
+	}
 
 	return allDeps, weakAndOptionalDepsSnapshot, nil
 }
@@ -840,7 +1019,7 @@ func (r *localRobot) getWeakDependencyMatchers(api resource.API, model resource.
 	return reg.WeakDependencies
 }
 
-// getOptionalDependenciesAndSnapshot resolves each entry in conf.ImplicitOptionalDependsOn
+// getOptionalDependenciesAndSnapshot resolves each entry in conf.OptionalDependencies()
 // and records its GraphNode.UpdatedAt at the moment of resolution. Resolving and
 // snapshotting in the same pass keeps them consistent.
 func (r *localRobot) getOptionalDependenciesAndSnapshot(
@@ -849,7 +1028,8 @@ func (r *localRobot) getOptionalDependenciesAndSnapshot(
 	optDeps := make(resource.Dependencies)
 	snapshot := make(map[resource.Name]int64)
 	found := make([]resource.Name, 0)
-	for _, optionalDepNameString := range conf.ImplicitOptionalDependsOn {
+	optionalDepNames := conf.OptionalDependencies()
+	for _, optionalDepNameString := range optionalDepNames {
 		// If the name string is a fully qualified resource name, skip trying to match
 		// by simple name.
 		//
@@ -912,7 +1092,7 @@ func (r *localRobot) getOptionalDependenciesAndSnapshot(
 		found = append(found, resolvedOptionalDepName)
 		snapshot[resolvedOptionalDepName] = node.UpdatedAt()
 	}
-	if len(conf.ImplicitOptionalDependsOn) > 0 {
+	if len(optionalDepNames) > 0 {
 		r.logger.Infow(
 			"Found optional dependencies for resource",
 			"resource", conf.ResourceName().String(),
@@ -1066,127 +1246,12 @@ func (r *localRobot) updateWeakAndOptionalDependents(ctx context.Context) {
 	// completeConfig.
 	r.lastWeakAndOptionalDependentsRound.Store(r.manager.resources.CurrLogicalClockValue())
 
-	allResources := map[resource.Name]resource.Resource{}
-	internalResources := map[resource.Name]resource.Resource{}
-	components := map[resource.Name]resource.Resource{}
-	for _, n := range r.manager.AllNonCollidingResourceNames() {
-		if !(n.API.IsComponent() || n.API.IsService()) {
-			continue
-		}
-		res, err := r.ResourceByName(n)
-		if err != nil {
-			if !resource.IsDependencyNotReadyError(err) && !resource.IsNotAvailableError(err) {
-				r.Logger().CDebugw(
-					ctx,
-					"error finding resource during weak/optional dependent update",
-					"resource", n,
-					"error", err,
-				)
-			}
-			continue
-		}
-		allResources[n] = res
-		switch {
-		case n.API.IsComponent():
-			components[n] = res
-		case n.API.Type.Namespace == resource.APINamespaceRDKInternal:
-			internalResources[n] = res
-		}
+	r.updateInternalServices(ctx)
+	if ctx.Err() != nil {
+		return
 	}
 
 	timeout := utils.GetResourceConfigurationTimeout(r.logger)
-	// NOTE(erd): this is intentionally hard coded since these services are treated specially with
-	// how they request dependencies or consume the robot's config. We should make an effort to
-	// formalize these as servcices that while internal, obey the reconfigure lifecycle.
-	// For example, the framesystem should depend on all input enabled components while the web
-	// service depends on all resources.
-	// For now, we pass all resources and empty configs.
-	processInternalResources := func(resName resource.Name, res resource.Resource, resChan chan struct{}) {
-		ctxWithTimeout, timeoutCancel := context.WithTimeout(ctx, timeout)
-		defer timeoutCancel()
-
-		cleanup := utils.SlowLogger(
-			ctx,
-			"Waiting for internal resource to complete reconfiguration during weak/optional dependencies update",
-			"resource", resName.String(),
-			r.logger,
-		)
-		defer cleanup()
-
-		r.reconfigureWorkers.Add(1)
-		goutils.PanicCapturingGo(func() {
-			defer func() {
-				resChan <- struct{}{}
-				r.reconfigureWorkers.Done()
-			}()
-			// NOTE(cheukt): when adding internal services that reconfigure, also add them to
-			// the check in `localRobot.resourceHasWeakDependencies`.
-			switch resName {
-			case web.InternalServiceName:
-				if internalRes, ok := res.(resource.BuiltInResource); ok {
-					if err := internalRes.BuiltInReconfigure(ctxWithTimeout, allResources, resource.Config{}); err != nil {
-						r.Logger().CErrorw(
-							ctx,
-							"failed to reconfigure internal service during weak/optional dependencies update",
-							"service", resName,
-							"error", err,
-						)
-					}
-				}
-			case framesystem.InternalServiceName:
-				if internalRes, ok := res.(resource.BuiltInResource); ok {
-					fsCfg, err := r.FrameSystemConfig(ctxWithTimeout)
-					if err != nil {
-						r.Logger().CErrorw(
-							ctx,
-							"failed to reconfigure internal service during weak/optional dependencies update",
-							"service", resName,
-							"error", err,
-						)
-						break
-					}
-					err = internalRes.BuiltInReconfigure(ctxWithTimeout, components, resource.Config{ConvertedAttributes: fsCfg})
-					if err != nil {
-						r.Logger().CErrorw(
-							ctx,
-							"failed to reconfigure internal service during weak/optional dependencies update",
-							"service", resName,
-							"error", err,
-						)
-					}
-				}
-			case packages.InternalServiceName, packages.DeferredServiceName, icloud.InternalServiceName:
-			default:
-				r.logger.CWarnw(
-					ctx,
-					"do not know how to reconfigure internal service during weak/optional dependencies update",
-					"service", resName,
-				)
-			}
-		})
-
-		select {
-		case <-resChan:
-		case <-ctxWithTimeout.Done():
-			if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
-				r.logger.CWarn(ctx, utils.NewWeakOrOptionalDependenciesUpdateTimeoutError(resName.String(), r.logger))
-			}
-		case <-ctx.Done():
-			return
-		}
-	}
-
-	for resName, res := range internalResources {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-		}
-		resChan := make(chan struct{}, 1)
-		resName := resName
-		res := res
-		processInternalResources(resName, res, resChan)
-	}
 
 	updateResourceWeakAndOptionalDependents := func(ctx context.Context, conf resource.Config) {
 		resName := conf.ResourceName()
@@ -1201,7 +1266,7 @@ func (r *localRobot) updateWeakAndOptionalDependents(ctx context.Context) {
 
 		// Return early if resource has neither weak nor optional dependencies (root of tree)
 		if len(r.getWeakDependencyMatchers(conf.API, conf.Model)) == 0 &&
-			len(conf.ImplicitOptionalDependsOn) == 0 {
+			len(conf.OptionalDependencies()) == 0 {
 			return
 		}
 
@@ -1310,6 +1375,146 @@ func (r *localRobot) updateWeakAndOptionalDependents(ctx context.Context) {
 	}
 }
 
+// resourceConfigurationConcurrency returns the number of resources that may be (re)configured at the
+// same time, which the robot's config overrides.
+func (r *localRobot) resourceConfigurationConcurrency() int {
+	if c := r.mostRecentCfg.Load().(config.Config).ResourceConfigurationConcurrency; c > 0 {
+		return c
+	}
+	return utils.GetResourceConfigurationConcurrency(r.logger)
+}
+
+// updateInternalServices reconfigures the internal services that track the robot's resources, such
+// as the frame system and web service, with the resources currently in the graph. If only is given,
+// just those internal services are reconfigured.
+func (r *localRobot) updateInternalServices(ctx context.Context, only ...resource.Name) {
+	allResources := map[resource.Name]resource.Resource{}
+	internalResources := map[resource.Name]resource.Resource{}
+	components := map[resource.Name]resource.Resource{}
+	for _, n := range r.manager.AllNonCollidingResourceNames() {
+		if !(n.API.IsComponent() || n.API.IsService()) {
+			continue
+		}
+		res, err := r.ResourceByName(n)
+		if err != nil {
+			if !resource.IsDependencyNotReadyError(err) && !resource.IsNotAvailableError(err) {
+				r.Logger().CDebugw(
+					ctx,
+					"error finding resource during weak/optional dependent update",
+					"resource", n,
+					"error", err,
+				)
+			}
+			continue
+		}
+		allResources[n] = res
+		switch {
+		case n.API.IsComponent():
+			components[n] = res
+		case n.API.Type.Namespace == resource.APINamespaceRDKInternal:
+			internalResources[n] = res
+		}
+	}
+
+	timeout := utils.GetResourceConfigurationTimeout(r.logger)
+	// NOTE(erd): this is intentionally hard coded since these services are treated specially with
+	// how they request dependencies or consume the robot's config. We should make an effort to
+	// formalize these as servcices that while internal, obey the reconfigure lifecycle.
+	// For example, the framesystem should depend on all input enabled components while the web
+	// service depends on all resources.
+	// For now, we pass all resources and empty configs.
+	processInternalResources := func(resName resource.Name, res resource.Resource, resChan chan struct{}) {
+		ctxWithTimeout, timeoutCancel := context.WithTimeout(ctx, timeout)
+		defer timeoutCancel()
+
+		cleanup := utils.SlowLogger(
+			ctx,
+			"Waiting for internal resource to complete reconfiguration during weak/optional dependencies update",
+			"resource", resName.String(),
+			r.logger,
+		)
+		defer cleanup()
+
+		r.reconfigureWorkers.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer func() {
+				resChan <- struct{}{}
+				r.reconfigureWorkers.Done()
+			}()
+			// NOTE(cheukt): when adding internal services that reconfigure, also add them to
+			// the check in `localRobot.resourceHasWeakDependencies`.
+			switch resName {
+			case web.InternalServiceName:
+				if internalRes, ok := res.(resource.BuiltInResource); ok {
+					if err := internalRes.BuiltInReconfigure(ctxWithTimeout, allResources, resource.Config{}); err != nil {
+						r.Logger().CErrorw(
+							ctx,
+							"failed to reconfigure internal service during weak/optional dependencies update",
+							"service", resName,
+							"error", err,
+						)
+					}
+				}
+			case framesystem.InternalServiceName:
+				if internalRes, ok := res.(resource.BuiltInResource); ok {
+					fsCfg, err := r.FrameSystemConfig(ctxWithTimeout)
+					if err != nil {
+						r.Logger().CErrorw(
+							ctx,
+							"failed to reconfigure internal service during weak/optional dependencies update",
+							"service", resName,
+							"error", err,
+						)
+						break
+					}
+					err = internalRes.BuiltInReconfigure(ctxWithTimeout, components, resource.Config{ConvertedAttributes: fsCfg})
+					if err != nil {
+						r.Logger().CErrorw(
+							ctx,
+							"failed to reconfigure internal service during weak/optional dependencies update",
+							"service", resName,
+							"error", err,
+						)
+					}
+				}
+			case packages.InternalServiceName, packages.DeferredServiceName, icloud.InternalServiceName, governor.InternalServiceName,
+				alerts.InternalServiceName:
+			default:
+				r.logger.CWarnw(
+					ctx,
+					"do not know how to reconfigure internal service during weak/optional dependencies update",
+					"service", resName,
+				)
+			}
+		})
+
+		select {
+		case <-resChan:
+		case <-ctxWithTimeout.Done():
+			if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
+				r.logger.CWarn(ctx, utils.NewWeakOrOptionalDependenciesUpdateTimeoutError(resName.String(), r.logger))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	for resName, res := range internalResources {
+		if len(only) > 0 && !slices.Contains(only, resName) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		resChan := make(chan struct{}, 1)
+		resName := resName
+		res := res
+		processInternalResources(resName, res, resChan)
+	}
+}
+
 // Config returns the info of each individual part that makes up the frame system
 // The output of this function is to be sent over GRPC to the client, so the client
 // can build its frame system. requests the remote components from the remote's frame system service.
@@ -1635,6 +1840,18 @@ func dialRobotClient(
 	if config.ReconnectInterval != 0 {
 		rOpts = append(rOpts, client.WithReconnectEvery(config.ReconnectInterval))
 	}
+	if failover := config.FailoverAddresses(); len(failover) > 0 {
+		rOpts = append(rOpts, client.WithFailoverAddresses(failover...))
+	}
+	if config.FailoverThreshold != 0 {
+		rOpts = append(rOpts, client.WithFailoverThreshold(config.FailoverThreshold))
+	}
+	if config.FailbackInterval != 0 {
+		rOpts = append(rOpts, client.WithFailbackEvery(config.FailbackInterval))
+	}
+	if config.ClockSyncInterval > 0 {
+		rOpts = append(rOpts, client.WithClockSync(config.ClockSyncInterval))
+	}
 
 	// only dial once per reconfiguration cycle, any failures will be retried on a ticker anyway
 	rOpts = append(rOpts, client.WithInitialDialAttempts(1))
@@ -1657,7 +1874,50 @@ func dialRobotClient(
 func (r *localRobot) Reconfigure(ctx context.Context, newConfig *config.Config) {
 	r.reconfigurationLock.Lock()
 	defer r.reconfigurationLock.Unlock()
-	r.reconfigure(ctx, newConfig, false)
+	if !r.admitRevision(ctx, newConfig) {
+		return
+	}
+	r.reconfigure(ctx, r.automationConfig(ctx, r.admitConfig(ctx, newConfig)), false)
+}
+
+// automationConfig returns the given config with the automation changes made at runtime applied.
+func (r *localRobot) automationConfig(ctx context.Context, cfg *config.Config) *config.Config {
+	r.automations.SetConfig(cfg)
+	effective, err := r.automations.Config()
+	if err != nil {
+		r.logger.CWarnw(ctx, "Some automation changes made at runtime could not be applied", "error", err)
+	}
+	return effective
+}
+
+// Automations returns the automation changes made at runtime.
+func (r *localRobot) Automations() *automation.Manager {
+	return r.automations
+}
+
+// ApplyAutomations reconfigures the robot with the automation changes made at runtime applied to
+// its last config.
+func (r *localRobot) ApplyAutomations(ctx context.Context) error {
+	r.reconfigurationLock.Lock()
+	defer r.reconfigurationLock.Unlock()
+	cfg, err := r.automations.Config()
+	r.reconfigure(ctx, cfg, false)
+	return err
+}
+
+// RunAutomation runs a job, or starts a program of an orchestration service, once now.
+func (r *localRobot) RunAutomation(ctx context.Context, ref automation.Ref) error {
+	if ref.Kind == automation.KindJob {
+		if r.jobManager == nil {
+			return errors.New("the job manager is not running")
+		}
+		return r.jobManager.RunNow(ref.Name)
+	}
+	svc, err := orchestration.FromProvider(r, ref.Service)
+	if err != nil {
+		return err
+	}
+	return svc.Start(ctx, ref.Name, nil)
 }
 
 // set Module.LocalVersion on Type=local modules. Call this before localPackages.Sync and in RestartModule.
@@ -1685,9 +1945,14 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 	// No need to check whether reconfiguring is allowed if this is initialization.
 	var reconfigureAllowedErr error
 	if !r.initializing.Load() {
+		if r.deferReconfigure(ctx, newConfig) {
+			r.rollout.Set(newConfig.Revision, robot.ConfigRevisionDeferred, nil)
+			return
+		}
 		var reconfigureAllowed bool
 		reconfigureAllowed, reconfigureAllowedErr = r.reconfigureAllowed(ctx, newConfig.MaintenanceConfig)
 		if !reconfigureAllowed {
+			r.rollout.Set(newConfig.Revision, robot.ConfigRevisionDeferred, nil)
 			// Diff the configs to guess if we are skipping a "meaningful" reconfigure (network
 			// or resources changed), otherwise silently return.
 			diff, err := config.DiffConfigs(*r.Config(), *newConfig, false)
@@ -1725,7 +1990,9 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 	// If reconfigure is allowed, assume we are reconfiguring until this function
 	// returns.
 	r.reconfiguring.Store(true)
+	reconfigureStart := time.Now()
 	defer func() {
+		r.reconfigures.observe(time.Since(reconfigureStart))
 		r.reconfiguring.Store(false)
 	}()
 
@@ -1767,6 +2034,7 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 	// TODO(RSDK-1849): Make this non-blocking so other resources that do not require packages can run before package sync finishes.
 	// TODO(RSDK-2710) this should really use Reconfigure for the package and should allow itself to check
 	// if anything has changed.
+	r.packageManager.SetPackageSources(packages.NewPackageSources(newConfig))
 	err = r.packageManager.Sync(ctx, newConfig.Packages, newConfig.Modules)
 	if err != nil {
 		// The returned error is rich, detailing each individual packages error. The underlying
@@ -1779,6 +2047,7 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 				"all modules were fully downloaded and unzipped, and completed its first run script. "+
 				"currently running modules will not be shutdown",
 		)
+		r.recordRevisionApplied(newConfig.Revision, errors.Wrap(err, "cloud modules or packages sync failed"))
 		return
 	}
 
@@ -1793,6 +2062,7 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 				"falling back to last config where all modules were fully downloaded and unzipped, "+
 				"and completed its first run script. currently running modules will not be shutdown",
 		)
+		r.recordRevisionApplied(newConfig.Revision, errors.Wrap(err, "local modules or packages sync failed"))
 		return
 	}
 
@@ -1803,7 +2073,10 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 		pkgMgr, pkgName := r.packageManagerForModule(mod)
 		pkgMgr.SetPackageState(pkgName, packages.PackageStateFirstRun, "")
 
-		if err := r.manager.moduleManager.FirstRun(ctx, mod); err != nil {
+		onProgress := func(progress config.FirstRunProgress) {
+			pkgMgr.SetFirstRunProgress(pkgName, progress.Percent, progress.Message)
+		}
+		if err := r.manager.moduleManager.FirstRun(ctx, mod, onProgress); err != nil {
 			pkgMgr.SetPackageState(pkgName, packages.PackageStateFailed, err.Error())
 			r.logger.CErrorw(
 				ctx,
@@ -1917,6 +2190,7 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 	}()
 
 	if diff.ResourcesEqual {
+		r.recordRevisionApplied(newConfig.Revision, nil)
 		return
 	}
 
@@ -1925,7 +2199,7 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 	if !r.initializing.Load() {
 		logVerb = "Reconfigur"
 		logNoun = "reconfiguration"
-		if newConfig.MaintenanceConfig != nil {
+		if newConfig.MaintenanceConfig != nil && newConfig.MaintenanceConfig.SensorName != "" {
 			if reconfigureAllowedErr != nil {
 				r.logger.CInfow(
 					ctx,
@@ -2001,6 +2275,7 @@ func (r *localRobot) reconfigure(ctx context.Context, newConfig *config.Config,
 	} else {
 		r.logger.CInfof(ctx, "Robot %ved", strings.ToLower(logVerb))
 	}
+	r.recordRevisionApplied(newConfig.Revision, allErrs)
 }
 
 func (r *localRobot) reconfigureTracing(ctx context.Context, newConfig *config.Config) {
@@ -2283,6 +2558,9 @@ func (r *localRobot) MachineStatus(ctx context.Context) (robot.MachineStatus, er
 		// cloud metadata blank in that case.
 		result.Resources = append(result.Resources, resource.Status{NodeStatus: resourceStatus, CloudMetadata: cloud.Metadata{}})
 	}
+	if st := r.estop.Status(); st.Engaged {
+		result.Resources = append(result.Resources, estop.ResourceStatus(st))
+	}
 	r.configRevisionMu.RLock()
 	result.Config = r.configRevision
 	r.configRevisionMu.RUnlock()
@@ -2311,6 +2589,12 @@ func (r *localRobot) MachineStatus(ctx context.Context) (robot.MachineStatus, er
 	result.Packages = append(result.Packages, r.packageManager.PackageStatuses()...)
 	result.Packages = append(result.Packages, r.localPackages.PackageStatuses()...)
 
+	if r.webSvc != nil {
+		result.RequestStats = r.webSvc.RequestCounter().ResourceRequestStats()
+	}
+	result.Alerts = r.alerts.Alerts()
+	result.ConfigRevisions = r.rollout.Statuses()
+
 	return result, nil
 }
 
@@ -2322,8 +2606,8 @@ func (r *localRobot) Version(ctx context.Context) (robot.VersionResponse, error)
 // reconfigureAllowed returns whether the local robot can reconfigure and any encountered
 // error.
 func (r *localRobot) reconfigureAllowed(ctx context.Context, mCfg *config.MaintenanceConfig) (bool, error) {
-	// Reconfigure is always allowed in the absence of a MaintenanceConfig.
-	if mCfg == nil {
+	// Reconfigure is always allowed in the absence of a maintenance sensor.
+	if mCfg == nil || mCfg.SensorName == "" {
 		return true, nil
 	}
 
@@ -2372,11 +2656,14 @@ func (r *localRobot) checkMaintenanceSensorReadings(ctx context.Context,
 
 // RestartAllowed returns whether the robot can safely be restarted. The robot
 // can be safely restarted if the robot is not in the middle of a reconfigure,
-// and a reconfigure would be allowed.
+// its maintenance window is open, and a reconfigure would be allowed.
 func (r *localRobot) RestartAllowed() bool {
 	if r.reconfiguring.Load() {
 		return false
 	}
+	if !r.maintenance.WindowOpen(r.Config().MaintenanceConfig, time.Now()) {
+		return false
+	}
 
 	// The error return is insignificant here; any reconfigureAllowed errors will be logged
 	// in the main reconfigure method. We do not want to log them every time viam-agent hits
@@ -2387,13 +2674,13 @@ func (r *localRobot) RestartAllowed() bool {
 	return reconfigureAllowed
 }
 
-// ListTunnels returns information on available traffic tunnels.
+// ListTunnels returns information on available traffic tunnels, both configured and opened at runtime.
 func (r *localRobot) ListTunnels(_ context.Context) ([]config.TrafficTunnelEndpoint, error) {
-	cfg := r.Config()
-	if cfg != nil {
-		return cfg.Network.NetworkConfigData.TrafficTunnelEndpoints, nil
+	var ttes []config.TrafficTunnelEndpoint
+	if cfg := r.Config(); cfg != nil {
+		ttes = append(ttes, cfg.Network.NetworkConfigData.TrafficTunnelEndpoints...)
 	}
-	return nil, nil
+	return append(ttes, r.tunnels.Endpoints()...), nil
 }
 
 // GetResource implements resource.Provider for a localRobot by looking up a resource by name.
@@ -727,7 +727,7 @@ func TestConfigRemoteWithTLSAuth(t *testing.T) {
 
 	statuses, err := r2.MachineStatus(context.Background())
 	test.That(t, err, test.ShouldBeNil)
-	test.That(t, len(statuses.Resources), test.ShouldEqual, 10)
+	test.That(t, len(statuses.Resources), test.ShouldEqual, 11)
 	test.That(t, statuses, test.ShouldNotBeNil)
 }
 
@@ -3934,6 +3934,16 @@ func getExpectedDefaultStatuses(_ string, md cloud.Metadata) []resource.Status {
 			},
 			CloudMetadata: md,
 		},
+		{
+			NodeStatus: resource.NodeStatus{
+				Name: resource.Name{
+					API:  resource.APINamespaceRDKInternal.WithServiceType("governor"),
+					Name: "builtin",
+				},
+				State: resource.NodeStateReady,
+			},
+			CloudMetadata: md,
+		},
 		{
 			NodeStatus: resource.NodeStatus{
 				Name: resource.Name{
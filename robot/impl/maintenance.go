@@ -0,0 +1,80 @@
+package robotimpl
+
+import (
+	"context"
+	"time"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/robot/maintenance"
+)
+
+// maintenanceCheckInterval is how often a deferred config change is checked for an open maintenance window.
+const maintenanceCheckInterval = 10 * time.Second
+
+// EnterMaintenance enters maintenance mode, which applies any deferred config change.
+func (r *localRobot) EnterMaintenance(ctx context.Context, until time.Time) error {
+	r.maintenance.Enter(until)
+	if until.IsZero() {
+		r.logger.CInfo(ctx, "Entered maintenance mode")
+	} else {
+		r.logger.CInfow(ctx, "Entered maintenance mode", "until", until)
+	}
+	return nil
+}
+
+// ExitMaintenance exits maintenance mode.
+func (r *localRobot) ExitMaintenance(ctx context.Context) error {
+	r.maintenance.Exit()
+	r.logger.CInfo(ctx, "Exited maintenance mode")
+	return nil
+}
+
+// MaintenanceStatus returns the maintenance state of the robot.
+func (r *localRobot) MaintenanceStatus() maintenance.Status {
+	return r.maintenance.Status(r.mostRecentCfg.Load().(config.Config).MaintenanceConfig, time.Now())
+}
+
+// deferReconfigure returns whether reconfiguring to newConfig has to wait for a maintenance window to
+// open, in which case the change is queued. Changes that leave resources, modules, and jobs untouched
+// are never deferred.
+func (r *localRobot) deferReconfigure(ctx context.Context, newConfig *config.Config) bool {
+	now := time.Now()
+	if r.maintenance.WindowOpen(newConfig.MaintenanceConfig, now) {
+		r.maintenance.ClearDeferred()
+		return false
+	}
+	diff, err := config.DiffConfigs(*r.Config(), *newConfig, false)
+	if err != nil {
+		r.logger.CErrorw(ctx, "error diffing the configs", "error", err)
+		return false
+	}
+	if diff.ResourcesEqual && diff.JobsEqual {
+		return false
+	}
+	if r.maintenance.Defer(newConfig) {
+		r.logger.CInfow(ctx, "Reconfigure deferred until the maintenance window opens",
+			"revision", newConfig.Revision, "next_window", newConfig.MaintenanceConfig.NextWindowOpen(now))
+	}
+	return true
+}
+
+// applyDeferredConfig reconfigures the robot with its latest config once a maintenance window opens
+// while a config change is deferred.
+func (r *localRobot) applyDeferredConfig() {
+	for {
+		select {
+		case <-r.closeContext.Done():
+			return
+		case <-r.maintenance.Wake():
+		case <-time.After(maintenanceCheckInterval):
+		}
+		mc, deferred := r.maintenance.Deferred()
+		if !deferred || !r.maintenance.WindowOpen(mc, time.Now()) {
+			continue
+		}
+		r.logger.CInfo(r.closeContext, "Maintenance window open, applying deferred config change")
+		if err := r.ApplyAutomations(r.closeContext); err != nil {
+			r.logger.CWarnw(r.closeContext, "Some automation changes made at runtime could not be applied", "error", err)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package robotimpl
+
+import (
+	"cmp"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"go.viam.com/rdk/ftdc"
+	modulestatus "go.viam.com/rdk/module/status"
+)
+
+type reconfigureMetrics struct {
+	count     atomic.Int64
+	totalNano atomic.Int64
+	lastNano  atomic.Int64
+}
+
+func (m *reconfigureMetrics) observe(d time.Duration) {
+	m.count.Add(1)
+	m.totalNano.Add(int64(d))
+	m.lastNano.Store(int64(d))
+}
+
+// WritePrometheusMetrics writes the robot's reconfiguration and module metrics along with every
+// reading collected by FTDC, which includes WebRTC connection, data sync and process statistics.
+func (r *localRobot) WritePrometheusMetrics(pw *ftdc.PrometheusWriter) {
+	pw.Family("viam_reconfigures_total", "counter", "Number of reconfigurations of the robot.")
+	pw.Sample("viam_reconfigures_total", float64(r.reconfigures.count.Load()))
+	pw.Family("viam_reconfigure_duration_seconds_total", "counter", "Total time spent reconfiguring the robot.")
+	pw.Sample("viam_reconfigure_duration_seconds_total", time.Duration(r.reconfigures.totalNano.Load()).Seconds())
+	pw.Family("viam_reconfigure_last_duration_seconds", "gauge", "Duration of the most recent reconfiguration of the robot.")
+	pw.Sample("viam_reconfigure_last_duration_seconds", time.Duration(r.reconfigures.lastNano.Load()).Seconds())
+
+	statuses := r.manager.moduleManager.Status()
+	slices.SortFunc(statuses, func(a, b modulestatus.Status) int { return cmp.Compare(a.Name, b.Name) })
+	pw.Family("viam_module_restarts_total", "counter", "Number of times a module was restarted after crashing.")
+	for _, status := range statuses {
+		pw.Sample("viam_module_restarts_total", float64(status.Restarts), "module", status.Name)
+	}
+	pw.Family("viam_module_oom_kills_total", "counter", "Number of times a module was killed for exceeding its memory limit.")
+	for _, status := range statuses {
+		pw.Sample("viam_module_oom_kills_total", float64(status.OOMKills), "module", status.Name)
+	}
+
+	if r.ftdc != nil {
+		r.ftdc.WritePrometheus(pw)
+	}
+}
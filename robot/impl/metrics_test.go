@@ -0,0 +1,87 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	robotClient, err := client.New(ctx, addr, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, robotClient.Close(ctx), test.ShouldBeNil)
+	}()
+	_, err = robotClient.MachineStatus(ctx)
+	test.That(t, err, test.ShouldBeNil)
+
+	//nolint:noctx
+	resp, err := http.Get(fmt.Sprintf("http://%s/metrics", addr))
+	test.That(t, err, test.ShouldBeNil)
+	defer resp.Body.Close()
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	test.That(t, resp.Header.Get("Content-Type"), test.ShouldStartWith, "text/plain; version=0.0.4")
+	body, err := io.ReadAll(resp.Body)
+	test.That(t, err, test.ShouldBeNil)
+	metrics := string(body)
+	test.That(t, metrics, test.ShouldContainSubstring, `viam_grpc_requests_total{resource="",method="RobotService/GetMachineStatus"}`)
+	test.That(t, metrics, test.ShouldContainSubstring, "# TYPE go_goroutines gauge")
+	test.That(t, metrics, test.ShouldContainSubstring, "viam_reconfigures_total 1")
+	test.That(t, metrics, test.ShouldContainSubstring, "# TYPE viam_module_restarts_total counter")
+	test.That(t, metrics, test.ShouldContainSubstring, "# TYPE viam_module_oom_kills_total counter")
+}
+
+func TestHTTPEndpointsRequireAuth(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	keyID := "key-id"
+	key := "sosecret"
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type: rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{
+				keyID:  key,
+				"keys": []string{keyID},
+			},
+		},
+	}
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	get := func(path, keyID, key string) int {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s%s", addr, path), nil)
+		test.That(t, err, test.ShouldBeNil)
+		if keyID != "" {
+			req.SetBasicAuth(keyID, key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		test.That(t, err, test.ShouldBeNil)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+	for _, path := range []string{"/metrics", "/debug/graph?format=json"} {
+		test.That(t, get(path, "", ""), test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, get(path, keyID, "wrong"), test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, get(path, keyID, key), test.ShouldEqual, http.StatusOK)
+	}
+}
@@ -0,0 +1,154 @@
+package robotimpl
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	robotpb "go.viam.com/api/robot/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+	"go.viam.com/utils/testutils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+	rgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// newClientCA returns a PEM encoded CA certificate and a function issuing client certificates
+// with the given DNS names from it.
+func newClientCA(t *testing.T) ([]byte, func(dnsNames ...string) tls.Certificate) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.That(t, err, test.ShouldBeNil)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	test.That(t, err, test.ShouldBeNil)
+	caCert, err := x509.ParseCertificate(caDER)
+	test.That(t, err, test.ShouldBeNil)
+
+	serial := int64(1)
+	issue := func(dnsNames ...string) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		test.That(t, err, test.ShouldBeNil)
+		serial++
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: dnsNames[0]},
+			DNSNames:     dnsNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		test.That(t, err, test.ShouldBeNil)
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), issue
+}
+
+func TestMTLSAuth(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	serverCert, certFile, keyFile, serverPool, err := testutils.GenerateSelfSignedCertificate("somename")
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() {
+		os.Remove(certFile)
+		os.Remove(keyFile)
+	})
+	caPEM, issue := newClientCA(t)
+
+	r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Network.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type: rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{
+				"keyid": "key",
+				"keys":  []string{"keyid"},
+			},
+		},
+		{
+			Type: rutils.CredentialsTypeMTLS,
+			Config: rutils.AttributeMap{
+				"ca_pem":   string(caPEM),
+				"entities": map[string]interface{}{"dashboard.example.com": "dashboard"},
+			},
+		},
+	}
+	options.Auth.Roles = []config.AuthRoleConfig{{Entity: "dashboard", Role: config.AuthRoleViewer}}
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	dial := func(t *testing.T, cert *tls.Certificate, opts ...rpc.DialOption) (robotpb.RobotServiceClient, error) {
+		t.Helper()
+		tlsConfig := &tls.Config{RootCAs: serverPool, ServerName: "somename", MinVersion: tls.VersionTLS12}
+		if cert != nil {
+			tlsConfig.Certificates = []tls.Certificate{*cert}
+		}
+		opts = append(opts, rpc.WithTLSConfig(tlsConfig), rpc.WithForceDirectGRPC())
+		conn, err := rgrpc.Dial(ctx, addr, logger, opts...)
+		if err != nil {
+			return nil, err
+		}
+		t.Cleanup(func() {
+			test.That(t, conn.Close(), test.ShouldBeNil)
+		})
+		return robotpb.NewRobotServiceClient(conn), nil
+	}
+
+	t.Run("mapped certificate", func(t *testing.T) {
+		cert := issue("dashboard.example.com")
+		client, err := dial(t, &cert)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = client.ResourceNames(ctx, &robotpb.ResourceNamesRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		// the certificate authenticates as the dashboard entity, which is only a viewer.
+		_, err = client.StopAll(ctx, &robotpb.StopAllRequest{})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+	})
+
+	t.Run("unmapped certificate", func(t *testing.T) {
+		cert := issue("other.example.com")
+		client, err := dial(t, &cert)
+		test.That(t, err, test.ShouldBeNil)
+		_, err = client.ResourceNames(ctx, &robotpb.ResourceNamesRequest{})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unauthenticated)
+	})
+
+	t.Run("api key without certificate", func(t *testing.T) {
+		client, err := dial(t, nil, rpc.WithEntityCredentials("keyid", rpc.Credentials{
+			Type:    rpc.CredentialsTypeAPIKey,
+			Payload: "key",
+		}))
+		test.That(t, err, test.ShouldBeNil)
+		_, err = client.StopAll(ctx, &robotpb.StopAllRequest{})
+		test.That(t, err, test.ShouldBeNil)
+	})
+}
@@ -0,0 +1,138 @@
+package robotimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	robotpb "go.viam.com/api/robot/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/config"
+	rgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestOIDCAuth(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	test.That(t, err, test.ShouldBeNil)
+	pubKey, err := jwk.New(&privKey.PublicKey)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pubKey.Set(jwk.KeyIDKey, "key1"), test.ShouldBeNil)
+	test.That(t, pubKey.Set(jwk.AlgorithmKey, "RS256"), test.ShouldBeNil)
+	keySet := jwk.NewSet()
+	keySet.Add(pubKey)
+
+	mux := http.NewServeMux()
+	provider := httptest.NewServer(mux)
+	defer provider.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		test.That(t, json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   provider.URL,
+			"jwks_uri": provider.URL + "/keys",
+		}), test.ShouldBeNil)
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		test.That(t, json.NewEncoder(w).Encode(keySet), test.ShouldBeNil)
+	})
+
+	r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type: rutils.CredentialsTypeOIDC,
+			Config: rutils.AttributeMap{
+				"issuer":   provider.URL,
+				"audience": "robots",
+			},
+		},
+	}
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	accessToken := func(t *testing.T, claims jwt.MapClaims) string {
+		t.Helper()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key1"
+		signed, err := token.SignedString(privKey)
+		test.That(t, err, test.ShouldBeNil)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": provider.URL,
+			"aud": []string{"robots"},
+			"sub": "alice",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	// authentication happens on the first call.
+	call := func(entity, token string) error {
+		conn, err := rgrpc.Dial(ctx, addr, logger,
+			rpc.WithAllowInsecureWithCredentialsDowngrade(),
+			rpc.WithEntityCredentials(entity, rpc.Credentials{Type: rutils.CredentialsTypeOIDC, Payload: token}),
+			rpc.WithForceDirectGRPC(),
+		)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			test.That(t, conn.Close(), test.ShouldBeNil)
+		}()
+		_, err = robotpb.NewRobotServiceClient(conn).ResourceNames(ctx, &robotpb.ResourceNamesRequest{})
+		return err
+	}
+
+	test.That(t, call("alice", accessToken(t, claims())), test.ShouldBeNil)
+
+	err = call("bob", accessToken(t, claims()))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not match the entity")
+
+	wrongAudience := claims()
+	wrongAudience["aud"] = []string{"other-app"}
+	err = call("alice", accessToken(t, wrongAudience))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not for this audience")
+
+	expired := claims()
+	expired["exp"] = time.Now().Add(-time.Minute).Unix()
+	err = call("alice", accessToken(t, expired))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "expired")
+
+	noExpiry := claims()
+	delete(noExpiry, "exp")
+	err = call("alice", accessToken(t, noExpiry))
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no expiry")
+
+	t.Run("metrics", func(t *testing.T) {
+		getMetrics := func(entity, token string) int {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+"/metrics", nil)
+			test.That(t, err, test.ShouldBeNil)
+			if entity != "" {
+				req.SetBasicAuth(entity, token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			test.That(t, err, test.ShouldBeNil)
+			defer resp.Body.Close()
+			return resp.StatusCode
+		}
+		test.That(t, getMetrics("", ""), test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, getMetrics("alice", accessToken(t, expired)), test.ShouldEqual, http.StatusUnauthorized)
+		test.That(t, getMetrics("alice", accessToken(t, claims())), test.ShouldEqual, http.StatusOK)
+	})
+}
@@ -2748,3 +2748,55 @@ func TestModularStalePointerCascadeChain(t *testing.T) {
 	// target instance through the freshly rebuilt mid-holder.
 	assertTopHolderReachesCurrentTarget("after-optdep-change")
 }
+
+func TestExplicitOptionalDependencies(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	lr := setupLocalRobot(t, ctx, &config.Config{}, logger, WithDisableCompleteConfigWorker())
+
+	// Register a component without attributes that records whether its optional motor was
+	// passed in.
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(5))
+	var withMotor []bool
+	resource.Register(
+		generic.API,
+		model,
+		resource.Registration[resource.Resource, resource.NoNativeConfig]{
+			Constructor: func(
+				ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+			) (resource.Resource, error) {
+				_, err := motor.FromProvider(deps, "m1")
+				withMotor = append(withMotor, err == nil)
+				return testutils.NewUnimplementedResource(conf.ResourceName()), nil
+			},
+		})
+	defer resource.Deregister(generic.API, model)
+
+	ocConf := resource.Config{Name: "oc", API: generic.API, Model: model, OptionalDependsOn: []string{"m1"}}
+	motorConf := resource.Config{Name: "m1", API: motor.API, Model: fake.Model, ConvertedAttributes: &fake.Config{}}
+
+	// the component is built without its optional dependency.
+	cfg := config.Config{Components: []resource.Config{ocConf}}
+	test.That(t, cfg.Ensure(false, logger), test.ShouldBeNil)
+	lr.Reconfigure(ctx, &cfg)
+	_, err := lr.ResourceByName(generic.Named("oc"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, withMotor, test.ShouldResemble, []bool{false})
+
+	// the component is rebuilt with its optional dependency once it appears.
+	cfg = config.Config{Components: []resource.Config{ocConf, motorConf}}
+	test.That(t, cfg.Ensure(false, logger), test.ShouldBeNil)
+	lr.Reconfigure(ctx, &cfg)
+	_, err = lr.ResourceByName(generic.Named("oc"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, withMotor[len(withMotor)-1], test.ShouldBeTrue)
+
+	// and rebuilt without it once it is removed.
+	cfg = config.Config{Components: []resource.Config{ocConf}}
+	test.That(t, cfg.Ensure(false, logger), test.ShouldBeNil)
+	lr.Reconfigure(ctx, &cfg)
+	_, err = lr.ResourceByName(generic.Named("oc"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, withMotor[len(withMotor)-1], test.ShouldBeFalse)
+}
@@ -0,0 +1,114 @@
+package robotimpl
+
+import (
+	"context"
+	"testing"
+
+	robotpb "go.viam.com/api/robot/v1"
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+	"go.viam.com/utils/testutils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+	rgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/testutils/robottestutils"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestRateLimits(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type: rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{
+				"limited":   "limitedkey",
+				"unlimited": "unlimitedkey",
+				"keys":      []string{"limited", "unlimited"},
+			},
+		},
+	}
+	options.Auth.RateLimits = []config.RateLimitConfig{
+		{Entity: "limited", Class: config.APIMethodClassWrite, RequestsPerSec: 0.01},
+		{Entity: "limited", Class: config.APIMethodClassRead, MaxConcurrentStreams: 1},
+	}
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	dial := func(t *testing.T, keyID, key string, opts ...rpc.DialOption) robotpb.RobotServiceClient {
+		t.Helper()
+		conn, err := rgrpc.Dial(ctx, addr, logger, append([]rpc.DialOption{
+			rpc.WithAllowInsecureWithCredentialsDowngrade(),
+			rpc.WithEntityCredentials(keyID, rpc.Credentials{Type: rpc.CredentialsTypeAPIKey, Payload: key}),
+		}, opts...)...)
+		test.That(t, err, test.ShouldBeNil)
+		t.Cleanup(func() {
+			test.That(t, conn.Close(), test.ShouldBeNil)
+		})
+		return robotpb.NewRobotServiceClient(conn)
+	}
+
+	t.Run("requests per second", func(t *testing.T) {
+		client := dial(t, "limited", "limitedkey", rpc.WithForceDirectGRPC())
+		_, err := client.StopAll(ctx, &robotpb.StopAllRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = client.StopAll(ctx, &robotpb.StopAllRequest{})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.ResourceExhausted)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "write requests per second")
+
+		// other classes and entities are not limited.
+		_, err = client.ResourceNames(ctx, &robotpb.ResourceNamesRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		other := dial(t, "unlimited", "unlimitedkey", rpc.WithForceDirectGRPC())
+		for i := 0; i < 3; i++ {
+			_, err = other.StopAll(ctx, &robotpb.StopAllRequest{})
+			test.That(t, err, test.ShouldBeNil)
+		}
+	})
+
+	t.Run("concurrent streams", func(t *testing.T) {
+		client := dial(t, "limited", "limitedkey", rpc.WithForceDirectGRPC())
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stream, err := client.StreamStatus(streamCtx, &robotpb.StreamStatusRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		// the first message is only sent once the stream is counted.
+		_, err = stream.Recv()
+		test.That(t, err, test.ShouldBeNil)
+
+		second, err := client.StreamStatus(ctx, &robotpb.StreamStatusRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = second.Recv()
+		test.That(t, status.Code(err), test.ShouldEqual, codes.ResourceExhausted)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "concurrent read streams")
+
+		cancel()
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			tb.Helper()
+			thirdCtx, thirdCancel := context.WithCancel(ctx)
+			defer thirdCancel()
+			third, err := client.StreamStatus(thirdCtx, &robotpb.StreamStatusRequest{})
+			test.That(tb, err, test.ShouldBeNil)
+			_, err = third.Recv()
+			test.That(tb, err, test.ShouldBeNil)
+		})
+	})
+
+	t.Run("over webrtc", func(t *testing.T) {
+		// each entity connected over WebRTC has its own limits rather than sharing the robot's.
+		client := dial(t, "limited", "limitedkey", rpc.WithDisableDirectGRPC())
+		_, err := client.StopAll(ctx, &robotpb.StopAllRequest{})
+		test.That(t, status.Code(err), test.ShouldEqual, codes.ResourceExhausted)
+
+		other := dial(t, "unlimited", "unlimitedkey", rpc.WithDisableDirectGRPC())
+		for i := 0; i < 3; i++ {
+			_, err = other.StopAll(ctx, &robotpb.StopAllRequest{})
+			test.That(t, err, test.ShouldBeNil)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package robotimpl
+
+import (
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// resourceEventBufferSize is how many events a subscriber may fall behind by before further
+// events are dropped for it.
+const resourceEventBufferSize = 256
+
+// resourceEventBroadcaster fans out lifecycle changes of the resource graph's nodes to
+// subscribers. It is installed as the graph's observer, so it is called with graph or node
+// locks held and never blocks on a subscriber. A subscriber that falls behind misses events
+// and is sent a resync event once it has room again.
+type resourceEventBroadcaster struct {
+	logger logging.Logger
+	// onRemoved, if set, is called with the name of every node removed from the graph.
+	onRemoved func(resource.Name)
+
+	mu          sync.Mutex
+	nextID      int
+	subscribers map[int]*resourceEventSubscriber
+}
+
+type resourceEventSubscriber struct {
+	events chan robot.ResourceStateEvent
+	// missed is set once an event could not be delivered and cleared once the resync event is.
+	missed bool
+}
+
+func newResourceEventBroadcaster(logger logging.Logger) *resourceEventBroadcaster {
+	return &resourceEventBroadcaster{
+		logger:      logger,
+		subscribers: map[int]*resourceEventSubscriber{},
+	}
+}
+
+// NodeTransitioned implements resource.GraphObserver.
+func (b *resourceEventBroadcaster) NodeTransitioned(status resource.NodeStatus) {
+	b.publish(robot.ResourceStateEvent{
+		Name:     status.Name,
+		State:    status.State,
+		Revision: status.Revision,
+		Error:    status.Error,
+		Time:     status.LastUpdated,
+	})
+}
+
+// NodeRemoved implements resource.GraphObserver.
+func (b *resourceEventBroadcaster) NodeRemoved(name resource.Name) {
+	if b.onRemoved != nil {
+		b.onRemoved(name)
+	}
+	b.publish(robot.ResourceStateEvent{
+		Name:    name,
+		Removed: true,
+		Time:    time.Now(),
+	})
+}
+
+func (b *resourceEventBroadcaster) publish(event robot.ResourceStateEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range b.subscribers {
+		if sub.missed {
+			select {
+			case sub.events <- robot.ResourceStateEvent{Resync: true, Time: event.Time}:
+				sub.missed = false
+			default:
+				continue
+			}
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.missed = true
+			b.logger.Debugw("dropping resource state events for slow subscriber until it catches up", "resource", event.Name)
+		}
+	}
+}
+
+func (b *resourceEventBroadcaster) subscribe() (<-chan robot.ResourceStateEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	sub := &resourceEventSubscriber{events: make(chan robot.ResourceStateEvent, resourceEventBufferSize)}
+	b.subscribers[id] = sub
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers, id)
+			close(sub.events)
+		})
+	}
+}
+
+// SubscribeResourceStateEvents returns a channel of lifecycle state changes of the robot's
+// resources and a function that ends the subscription.
+func (r *localRobot) SubscribeResourceStateEvents() (<-chan robot.ResourceStateEvent, func()) {
+	return r.resourceEvents.subscribe()
+}
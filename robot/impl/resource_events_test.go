@@ -0,0 +1,153 @@
+package robotimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/arm/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/testutils/robottestutils"
+)
+
+func TestResourceStateEvents(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+	robotClient, err := client.New(ctx, addr, logger.Sublogger("client"))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, robotClient.Close(ctx), test.ShouldBeNil)
+	}()
+
+	arm1, arm2 := arm.Named("arm1"), arm.Named("arm2")
+	subscriber, ok := r.(robot.ResourceStateSubscriber)
+	test.That(t, ok, test.ShouldBeTrue)
+	local, unsubscribe := subscriber.SubscribeResourceStateEvents()
+	defer unsubscribe()
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	streamed := make(chan robot.ResourceStateEvent, 100)
+	streamErr := make(chan error, 2)
+	utils.PanicCapturingGo(func() {
+		streamErr <- robotClient.StreamResourceStates(streamCtx, []resource.Name{arm2}, 0, func(event robot.ResourceStateEvent) error {
+			streamed <- event
+			return nil
+		})
+	})
+	streams := 1
+	defer func() {
+		cancel()
+		for i := 0; i < streams; i++ {
+			<-streamErr
+		}
+	}()
+	// give the stream time to be established before anything changes.
+	time.Sleep(100 * time.Millisecond)
+
+	armConf := resource.Config{
+		Name:                "arm1",
+		API:                 arm.API,
+		Model:               fakeModel,
+		ConvertedAttributes: &fake.Config{ModelFilePath: "../../components/arm/fake/kinematics/fake.json"},
+	}
+	brokenConf := resource.Config{Name: "arm2", API: arm.API, Model: resource.DefaultModelFamily.WithModel("missing")}
+	r.Reconfigure(ctx, &config.Config{Components: []resource.Config{armConf, brokenConf}})
+	r.Reconfigure(ctx, &config.Config{Components: []resource.Config{armConf}})
+
+	next := func(events <-chan robot.ResourceStateEvent, name resource.Name) robot.ResourceStateEvent {
+		t.Helper()
+		for {
+			select {
+			case event := <-events:
+				if event.Name == name {
+					return event
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for event for %v", name)
+			}
+		}
+	}
+
+	// the first event for a new resource is it starting to configure.
+	event := next(local, arm1)
+	test.That(t, event.State, test.ShouldEqual, resource.NodeStateConfiguring)
+	event = next(local, arm1)
+	test.That(t, event.State, test.ShouldEqual, resource.NodeStateReady)
+
+	// only the requested resource is streamed to the client.
+	var sawUnhealthy bool
+	for {
+		event = next(streamed, arm2)
+		test.That(t, event.Name, test.ShouldResemble, arm2)
+		if event.State == resource.NodeStateUnhealthy {
+			test.That(t, event.Error, test.ShouldNotBeNil)
+			sawUnhealthy = true
+		}
+		if event.Removed {
+			break
+		}
+	}
+	test.That(t, sawUnhealthy, test.ShouldBeTrue)
+
+	// a new stream starts with a snapshot of the current state, and batches changes if asked to.
+	batched := make(chan robot.ResourceStateEvent, 100)
+	streams++
+	utils.PanicCapturingGo(func() {
+		streamErr <- robotClient.StreamResourceStates(streamCtx, []resource.Name{arm1}, 10*time.Millisecond,
+			func(event robot.ResourceStateEvent) error {
+				batched <- event
+				return nil
+			})
+	})
+	event = next(batched, arm1)
+	test.That(t, event.Snapshot, test.ShouldBeTrue)
+	test.That(t, event.State, test.ShouldEqual, resource.NodeStateReady)
+	test.That(t, event.Time, test.ShouldNotBeZeroValue)
+
+	r.Reconfigure(ctx, &config.Config{})
+	for {
+		event = next(batched, arm1)
+		test.That(t, event.Snapshot, test.ShouldBeFalse)
+		if event.Removed {
+			break
+		}
+	}
+}
+
+func TestResourceEventBroadcasterResync(t *testing.T) {
+	b := newResourceEventBroadcaster(logging.NewTestLogger(t))
+	events, unsubscribe := b.subscribe()
+	defer unsubscribe()
+
+	name := arm.Named("arm1")
+	for i := 0; i < resourceEventBufferSize+10; i++ {
+		b.NodeRemoved(name)
+	}
+	for i := 0; i < resourceEventBufferSize; i++ {
+		test.That(t, (<-events).Resync, test.ShouldBeFalse)
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected event %v", event)
+	default:
+	}
+
+	// once there is room, the subscriber learns that it missed events before getting new ones.
+	b.NodeRemoved(name)
+	test.That(t, (<-events).Resync, test.ShouldBeTrue)
+	event := <-events
+	test.That(t, event.Resync, test.ShouldBeFalse)
+	test.That(t, event.Name, test.ShouldResemble, name)
+}
@@ -0,0 +1,83 @@
+package robotimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/arm/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/testutils/robottestutils"
+)
+
+func TestExportResourceGraph(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	cfg := &config.Config{Components: []resource.Config{
+		{
+			Name:                "arm1",
+			API:                 arm.API,
+			Model:               fakeModel,
+			ConvertedAttributes: &fake.Config{ModelFilePath: "../../components/arm/fake/kinematics/fake.json"},
+		},
+		{Name: "arm2", API: arm.API, Model: resource.DefaultModelFamily.WithModel("missing"), DependsOn: []string{"arm1"}},
+	}}
+	r := setupLocalRobot(t, ctx, cfg, logger)
+
+	nodes := func(export resource.GraphExport) map[string]resource.GraphExportNode {
+		byName := map[string]resource.GraphExportNode{}
+		for _, node := range export.Nodes {
+			byName[node.Name] = node
+		}
+		return byName
+	}
+
+	exporter, ok := r.(robot.ResourceGraphExporter)
+	test.That(t, ok, test.ShouldBeTrue)
+	export := exporter.ExportResourceGraph()
+	byName := nodes(export)
+	arm1, arm2 := byName[arm.Named("arm1").String()], byName[arm.Named("arm2").String()]
+	test.That(t, arm1.State, test.ShouldEqual, resource.NodeStateReady.String())
+	test.That(t, arm1.Model, test.ShouldEqual, fakeModel.String())
+	test.That(t, arm1.Error, test.ShouldBeEmpty)
+	test.That(t, arm1.Modular, test.ShouldBeFalse)
+	test.That(t, arm2.State, test.ShouldEqual, resource.NodeStateUnhealthy.String())
+	test.That(t, arm2.Error, test.ShouldNotBeEmpty)
+	test.That(t, export.Edges, test.ShouldContain, resource.GraphExportEdge{
+		From: arm.Named("arm2").String(),
+		To:   arm.Named("arm1").String(),
+	})
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	test.That(t, r.StartWeb(ctx, options), test.ShouldBeNil)
+
+	//nolint:noctx
+	resp, err := http.Get(fmt.Sprintf("http://%s/debug/graph?format=json", addr))
+	test.That(t, err, test.ShouldBeNil)
+	defer resp.Body.Close()
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	var served resource.GraphExport
+	test.That(t, json.NewDecoder(resp.Body).Decode(&served), test.ShouldBeNil)
+	test.That(t, nodes(served)[arm.Named("arm2").String()].Error, test.ShouldEqual, arm2.Error)
+
+	//nolint:noctx
+	resp, err = http.Get(fmt.Sprintf("http://%s/debug/graph?format=dot", addr))
+	test.That(t, err, test.ShouldBeNil)
+	defer resp.Body.Close()
+	dot, err := io.ReadAll(resp.Body)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(dot), test.ShouldContainSubstring, "digraph")
+	// the live graph is exported, including the error of the resource that failed to build
+	test.That(t, string(dot), test.ShouldContainSubstring, "arm2")
+	test.That(t, string(dot), test.ShouldContainSubstring, "indianred")
+}
@@ -30,6 +30,7 @@ import (
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/robot/shutdown"
 	"go.viam.com/rdk/robot/web"
 	"go.viam.com/rdk/services/shell"
 	rutils "go.viam.com/rdk/utils"
@@ -55,7 +56,7 @@ type moduleManager interface {
 	CleanModuleDataDirectory() error
 	Close(ctx context.Context) error
 	Configs() []config.Module
-	FirstRun(ctx context.Context, conf config.Module) error
+	FirstRun(ctx context.Context, conf config.Module, onProgress func(config.FirstRunProgress)) error
 	IsModularResource(name resource.Name) bool
 	Kill()
 	Provides(conf resource.Config) bool
@@ -91,6 +92,7 @@ type resourceManagerOptions struct {
 	untrustedEnv       bool
 	tlsConfig          *tls.Config
 	ftdc               *ftdc.FTDC
+	moduleDevMode      bool
 }
 
 // newResourceManager returns a properly initialized set of parts.
@@ -124,6 +126,22 @@ func (manager *resourceManager) ExportDot(index int) (resource.GetSnapshotInfo,
 	return manager.viz.GetSnapshot(index)
 }
 
+// ExportGraph exports the current resource graph, marking the resources served by modules.
+func (manager *resourceManager) ExportGraph() resource.GraphExport {
+	export := manager.resources.Export()
+	if manager.moduleManager == nil {
+		return export
+	}
+	for i, node := range export.Nodes {
+		name, err := resource.NewFromString(node.Name)
+		if err != nil {
+			continue
+		}
+		export.Nodes[i].Modular = manager.moduleManager.IsModularResource(name)
+	}
+	return export
+}
+
 func (manager *resourceManager) startModuleManager(
 	ctx context.Context,
 	parentAddrs config.ParentSockAddrs,
@@ -143,6 +161,7 @@ func (manager *resourceManager) startModuleManager(
 		PackagesDir:             packagesDir,
 		FTDC:                    manager.opts.ftdc,
 		ModPeerConnTracker:      modPeerConnTracker,
+		DevMode:                 manager.opts.moduleDevMode,
 	}
 	modmanager, err := modmanager.NewManager(ctx, parentAddrs, logger, mmOpts)
 	if err != nil {
@@ -173,7 +192,7 @@ func (manager *resourceManager) addRemote(
 	} else {
 		gNode.SwapResource(rr, builtinModel, manager.opts.ftdc, true)
 	}
-	manager.updateRemoteResourceNames(ctx, rName, rr, c.Prefix, true)
+	manager.updateRemoteResourceNames(ctx, rName, rr, c.Prefix, c.Resources, true)
 }
 
 func (manager *resourceManager) remoteResourceNames(remoteName resource.Name) []resource.Name {
@@ -209,8 +228,9 @@ type internalRemoteRobot interface {
 
 // updateRemoteResourceNames is called when the Remote robot has changed (either connection or disconnection).
 // It will pull the current remote resources and update the resource tree adding or removing nodes accordingly.
-// The recreateAllClients flag will re-add all remote resource nodes if true and only new / uninitialized
-// resource names if false. If any local resources are dependent on a remote resource two things can happen
+// Only the resources selected by importedResources are added. The recreateAllClients flag will re-add all
+// remote resource nodes if true and only new / uninitialized resource names if false. If any local resources
+// are dependent on a remote resource two things can happen
 //  1. The remote resource already is in the tree and nothing will happen.
 //  2. A remote resource is being deleted but a local resource depends on it; it will be removed
 //     and its local children will be destroyed.
@@ -219,6 +239,7 @@ func (manager *resourceManager) updateRemoteResourceNames(
 	remoteName resource.Name,
 	rr internalRemoteRobot,
 	prefix string,
+	importedResources *config.RemoteResources,
 	recreateAllClients bool,
 ) bool {
 	logger := manager.logger.WithFields("remote", remoteName)
@@ -258,6 +279,11 @@ func (manager *resourceManager) updateRemoteResourceNames(
 		if resName.Name == resource.DefaultServiceName {
 			continue
 		}
+		// Resources that are not imported are treated as if the remote did not have them, so those that were
+		// imported before are removed.
+		if !importedResources.Imports(resName) {
+			continue
+		}
 
 		remoteResName := resName
 		resLogger := logger.WithFields("resource", remoteResName)
@@ -404,6 +430,7 @@ func (manager *resourceManager) updateRemotesResourceNames(ctx context.Context)
 						name,
 						rr,
 						remoteConfig.Prefix,
+						remoteConfig.Resources,
 						false,
 					) || anythingChanged
 				}
@@ -573,8 +600,6 @@ func (manager *resourceManager) mergeResourceRPCAPIsWithRemote(r robot.Robot, ty
 }
 
 func (manager *resourceManager) closeResource(ctx context.Context, res resource.Resource) error {
-	manager.logger.CInfow(ctx, "Now removing resource", "resource", res.Name())
-
 	// TODO(RSDK-6626): We should be resilient to builtin resource `Close` calls
 	// hanging and not respecting the context created below. We will likely need
 	// a goroutine/timer setup here similar to that in the (re)configuration
@@ -583,6 +608,13 @@ func (manager *resourceManager) closeResource(ctx context.Context, res resource.
 	// Avoid hangs in Close/RemoveResource with resourceCloseTimeout.
 	closeCtx, cancel := context.WithTimeout(ctx, resourceCloseTimeout)
 	defer cancel()
+	return manager.closeResourceWithin(closeCtx, res)
+}
+
+// closeResourceWithin closes a resource, and removes it from its module if it is modular, within the
+// deadline of closeCtx.
+func (manager *resourceManager) closeResourceWithin(closeCtx context.Context, res resource.Resource) error {
+	manager.logger.CInfow(closeCtx, "Now removing resource", "resource", res.Name())
 
 	cleanup := rutils.SlowLogger(
 		closeCtx,
@@ -649,16 +681,41 @@ func (manager *resourceManager) removeMarkedAndClose(
 
 // Close attempts to close/stop all parts.
 func (manager *resourceManager) Close(ctx context.Context) error {
-	manager.resources.MarkForRemoval(manager.resources.Clone())
+	return manager.Shutdown(ctx, shutdown.NewManager(nil, manager.logger))
+}
 
-	var allErrs error
+// Shutdown brings all parts to their safe states and closes them in reverse dependency order, as
+// shutdownManager directs, and then closes the module manager.
+func (manager *resourceManager) Shutdown(ctx context.Context, shutdownManager *shutdown.Manager) error {
+	// the built resources are looked up before they are removed from the graph so that they can be
+	// brought to their safe states.
+	built := map[resource.Name]resource.Resource{}
+	for _, name := range manager.resources.Names() {
+		if node, ok := manager.resources.Node(name); ok {
+			if res, err := node.Resource(); err == nil {
+				built[name] = res
+			}
+		}
+	}
+	manager.resources.MarkForRemoval(manager.resources.Clone())
 
-	// our caller will close web
-	excludeWebFromClose := map[resource.Name]struct{}{
-		web.InternalServiceName: {},
+	var targets []shutdown.Target
+	for _, res := range manager.resources.RemoveMarked() {
+		// our caller will close web
+		if res.Name() == web.InternalServiceName {
+			continue
+		}
+		targets = append(targets, shutdown.Target{
+			Name:     res.Name(),
+			Resource: built[res.Name()],
+			Close: func(ctx context.Context) error {
+				return manager.closeResourceWithin(ctx, res)
+			},
+		})
 	}
-	if err := manager.removeMarkedAndClose(ctx, excludeWebFromClose); err != nil {
-		allErrs = multierr.Combine(allErrs, err)
+	allErrs := shutdownManager.Shutdown(ctx, targets)
+	if err := manager.viz.SaveSnapshot(manager.resources); err != nil {
+		manager.logger.Warnw("failed to save graph snapshot", "error", err)
 	}
 	// take a lock minimally to make a copy of the moduleManager.
 	manager.modManagerLock.Lock()
@@ -712,216 +769,296 @@ func (manager *resourceManager) completeConfig(
 		manager.logger.CDebugw(ctx, "error resolving dependencies", "error", err)
 	}
 
-	// sort resources into topological "levels" based on their dependencies. resources in
-	// any given level only depend on resources in prior levels. this makes it safe to
-	// process resources within a level concurrently as long as levels are processed in
-	// order.
+	// sort resources into topological "levels" based on their dependencies. the levels only
+	// determine the order in which ready resources are started: a resource is processed as
+	// soon as every dependency it has in this pass is done, so independent branches of the
+	// graph are (re)configured in parallel instead of waiting on unrelated resources in
+	// earlier levels.
 	levels := manager.resources.ReverseTopologicalSortInLevels()
 	timeout := rutils.GetResourceConfigurationTimeout(manager.logger)
-	for i, resourceNames := range levels {
-		// At the start of every reconfiguration level, run updateWeakAndOptionalDependents.
-		// value.
-		//
-		// This will make sure that weak and optional dependents are updated before they are
-		// passed into constructors or reconfigure methods.
-		//
-		// Resources that depend on weak or optional dependents should expect that the
-		// weak/optional dependents passed into the constructor or reconfigure method will
-		// only have been reconfigured with all resources constructed before their level.
-		for _, resName := range resourceNames {
-			select {
-			case <-ctx.Done():
-				return
-			default:
+	concurrency := lr.resourceConfigurationConcurrency()
+	if forceSync {
+		concurrency = 1
+	}
+
+	var ordered []resource.Name
+	for _, resourceNames := range levels {
+		ordered = append(ordered, resourceNames...)
+	}
+	inPass := make(map[resource.Name]struct{}, len(ordered))
+	for _, resName := range ordered {
+		inPass[resName] = struct{}{}
+	}
+	remainingDeps := make(map[resource.Name]int, len(ordered))
+	dependents := make(map[resource.Name][]resource.Name, len(ordered))
+	var ready []resource.Name
+	for _, resName := range ordered {
+		for _, dep := range manager.resources.GetAllParentsOf(resName) {
+			if _, ok := inPass[dep]; ok {
+				remainingDeps[resName]++
+				dependents[dep] = append(dependents[dep], resName)
 			}
+		}
+		if remainingDeps[resName] == 0 {
+			ready = append(ready, resName)
+		}
+	}
+
+	// TODO(RSDK-6925): support concurrent processing of resources of APIs with a maximum
+	// instance limit. Currently this limit is validated later in the resource creation flow
+	// and assumes that each resource is created synchronously to have an accurate creation
+	// count, so those resources are never started while another one is being processed.
+	hasMaxInstance := func(resName resource.Name) bool {
+		c, ok := resource.LookupGenericAPIRegistration(resName.API)
+		return ok && c.MaxInstance != 0
+	}
+
+	// processResource is intended to be run concurrently for each resource whose
+	// dependencies are done. if any processResource function returns a non-nil error then
+	// the entire `completeConfig` function will exit early.
+	//
+	// currently only a top-level context cancellation will result in an early
+	// exist - individual resource processing failures will not.
+	processResource := func(resName resource.Name) error {
+		resChan := make(chan struct{}, 1)
+		ctxWithTimeout, timeoutCancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer timeoutCancel()
+
+		stopSlowLogger := rutils.SlowLogger(
+			ctx, "Waiting for resource to complete (re)configuration", "resource", resName.String(), manager.logger)
+
+		lr.reconfigureWorkers.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer func() {
+				stopSlowLogger()
+				resChan <- struct{}{}
+				lr.reconfigureWorkers.Done()
+			}()
 			gNode, ok := manager.resources.Node(resName)
-			if manager.logger != nil && manager.logger.GetLevel() < 0 {
-				manager.logger.Debugw("CompleteConfig", "level", i, "resName", resName,
-					"lastWeakAndOptionalDependentsRound", lr.lastWeakAndOptionalDependentsRound.Load(),
-					"CurrLogicalClockValue", manager.resources.CurrLogicalClockValue(), "NeedsReconfigure", gNode.NeedsReconfigure())
-			}
 			if !ok || !gNode.NeedsReconfigure() {
-				continue
+				return
 			}
 			if !(resName.API.IsComponent() || resName.API.IsService()) {
-				continue
+				return
 			}
 
-			lr.updateWeakAndOptionalDependents(ctx)
-			break
-		}
-		// we use an errgroup here instead of a normal waitgroup to conveniently bubble
-		// up errors in resource processing goroutinues that warrant an early exit.
-		var levelErrG errgroup.Group
-		// Add resources in batches instead of all at once. We've observed this to be more
-		// reliable when there are a large number of resources to add (e.g. hundreds).
-		levelErrG.SetLimit(10)
-		for _, resName := range resourceNames {
-			select {
-			case <-ctx.Done():
+			verb := "construct"
+			conf := gNode.Config()
+			if gNode.IsUninitialized() {
+				gNode.InitializeLogger(
+					manager.logger, resName.String(),
+				)
+			} else {
+				verb = "rebuild"
+			}
+			manager.logger.CInfow(ctx, fmt.Sprintf("Now %ving resource", verb), "resource", resName, "model", conf.Model)
+
+			// The config was already validated, but we must check again before attempting
+			// to add.
+			if _, _, err := conf.Validate("", resName.API.Type.Name); err != nil {
+				gNode.LogAndSetLastError(
+					fmt.Errorf("resource config validation error: %w", err),
+					"resource", conf.ResourceName(),
+					"model", conf.Model)
 				return
-			default:
 			}
-			// processResource is intended to be run concurrently for each resource
-			// within a topological sort level. if any processResource function returns a
-			// non-nil error then the entire `completeConfig` function will exit early.
-			//
-			// currently only a top-level context cancellation will result in an early
-			// exist - individual resource processing failures will not.
-			processResource := func() error {
-				resChan := make(chan struct{}, 1)
-				ctxWithTimeout, timeoutCancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
-				defer timeoutCancel()
-
-				stopSlowLogger := rutils.SlowLogger(
-					ctx, "Waiting for resource to complete (re)configuration", "resource", resName.String(), manager.logger)
-
-				lr.reconfigureWorkers.Add(1)
-				goutils.PanicCapturingGo(func() {
-					defer func() {
-						stopSlowLogger()
-						resChan <- struct{}{}
-						lr.reconfigureWorkers.Done()
-					}()
-					gNode, ok := manager.resources.Node(resName)
-					if !ok || !gNode.NeedsReconfigure() {
-						return
-					}
-					if !(resName.API.IsComponent() || resName.API.IsService()) {
-						return
-					}
+			if manager.moduleManager.Provides(conf) {
+				implicitDeps, implicitOptionalDeps, err := manager.moduleManager.ValidateConfig(ctxWithTimeout, conf)
+				if err != nil {
+					gNode.LogAndSetLastError(
+						fmt.Errorf("modular resource config validation error: %w", err),
+						"resource", conf.ResourceName(),
+						"model", conf.Model)
+					return
+				}
 
-					verb := "construct"
-					conf := gNode.Config()
-					if gNode.IsUninitialized() {
-						gNode.InitializeLogger(
-							manager.logger, resName.String(),
-						)
-					} else {
-						verb = "rebuild"
-					}
-					manager.logger.CInfow(ctx, fmt.Sprintf("Now %ving resource", verb), "resource", resName, "model", conf.Model)
-
-					// The config was already validated, but we must check again before attempting
-					// to add.
-					if _, _, err := conf.Validate("", resName.API.Type.Name); err != nil {
-						gNode.LogAndSetLastError(
-							fmt.Errorf("resource config validation error: %w", err),
-							"resource", conf.ResourceName(),
-							"model", conf.Model)
-						return
-					}
-					if manager.moduleManager.Provides(conf) {
-						implicitDeps, implicitOptionalDeps, err := manager.moduleManager.ValidateConfig(ctxWithTimeout, conf)
-						if err != nil {
-							gNode.LogAndSetLastError(
-								fmt.Errorf("modular resource config validation error: %w", err),
-								"resource", conf.ResourceName(),
-								"model", conf.Model)
-							return
-						}
-
-						// If the freshly-validated implicit dependencies differ from the set the node
-						// was configured with, the node's dependency edges are stale. This happens when
-						// ResolveImplicitDependencies hit a transient Validate error (e.g. a
-						// DeadlineExceeded timeout) and dropped the dependencies, but Validate now
-						// succeeds. Re-apply the dependencies and defer the build by one pass so they
-						// get resolved into graph edges first; building now would construct the resource
-						// with an incomplete dependency set (e.g. a missing camera dependency).
-						if !equalUnordered(implicitDeps, conf.ImplicitDependsOn) ||
-							!equalUnordered(implicitOptionalDeps, conf.ImplicitOptionalDependsOn) {
-							manager.logger.CInfow(ctx,
-								"modular resource implicit dependencies changed since last validation; re-resolving before building",
-								"resource", conf.ResourceName(), "model", conf.Model,
-								"old", conf.ImplicitDependsOn, "new", implicitDeps)
-							conf.ImplicitDependsOn = implicitDeps
-							conf.ImplicitOptionalDependsOn = implicitOptionalDeps
-							gNode.SetNewConfig(conf, conf.Dependencies())
-							lr.sendTriggerConfig("modular dependency re-resolution")
-							return
-						}
-					}
+				// If the freshly-validated implicit dependencies differ from the set the node
+				// was configured with, the node's dependency edges are stale. This happens when
+				// ResolveImplicitDependencies hit a transient Validate error (e.g. a
+				// DeadlineExceeded timeout) and dropped the dependencies, but Validate now
+				// succeeds. Re-apply the dependencies and defer the build by one pass so they
+				// get resolved into graph edges first; building now would construct the resource
+				// with an incomplete dependency set (e.g. a missing camera dependency).
+				if !equalUnordered(implicitDeps, conf.ImplicitDependsOn) ||
+					!equalUnordered(implicitOptionalDeps, conf.ImplicitOptionalDependsOn) {
+					manager.logger.CInfow(ctx,
+						"modular resource implicit dependencies changed since last validation; re-resolving before building",
+						"resource", conf.ResourceName(), "model", conf.Model,
+						"old", conf.ImplicitDependsOn, "new", implicitDeps)
+					conf.ImplicitDependsOn = implicitDeps
+					conf.ImplicitOptionalDependsOn = implicitOptionalDeps
+					gNode.SetNewConfig(conf, conf.Dependencies())
+					lr.sendTriggerConfig("modular dependency re-resolution")
+					return
+				}
+			}
 
-					switch {
-					case resName.API.IsComponent(), resName.API.IsService():
-						newRes, err := manager.processResource(ctxWithTimeout, conf, gNode, lr)
-						if err := manager.markChildrenForUpdate(resName); err != nil {
-							manager.logger.CErrorw(ctx,
-								"failed to mark children of resource for update",
-								"resource", resName,
-								"reason", err)
-						}
-
-						if err != nil {
-							gNode.LogAndSetLastError(
-								fmt.Errorf("resource build error: %v", err.Error()),
-								"resource", conf.ResourceName(),
-								"model", conf.Model)
-							return
-						}
-
-						// if the ctxWithTimeout fails with DeadlineExceeded, then that means that
-						// resource generation is running async, and we don't currently have good
-						// validation around how this might affect the resource graph. So, we avoid
-						// updating the graph to be safe.
-						if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
-							manager.logger.CErrorw(
-								ctx, "error building resource", "resource", conf.ResourceName(), "model", conf.Model, "error", ctxWithTimeout.Err())
-						} else {
-							gNode.SwapResource(newRes, conf.Model, manager.opts.ftdc, true)
-							manager.logger.CInfow(ctx, fmt.Sprintf("Successfully %ved resource", verb), "resource", resName, "model", conf.Model)
-						}
-
-					default:
-						err := errors.New("config is not for a component or service")
-						gNode.LogAndSetLastError(err, "resource", resName)
-					}
-				})
+			switch {
+			case resName.API.IsComponent(), resName.API.IsService():
+				newRes, err := manager.processResource(ctxWithTimeout, conf, gNode, lr)
+				if err := manager.markChildrenForUpdate(resName); err != nil {
+					manager.logger.CErrorw(ctx,
+						"failed to mark children of resource for update",
+						"resource", resName,
+						"reason", err)
+				}
 
-				select {
-				case <-resChan:
-				case <-ctxWithTimeout.Done():
-					// this resource is taking too long to process, so we give up but
-					// continue processing other resources. we do not wait for this
-					// resource to finish processing since it may be running outside code
-					// and have unexpected behavior.
-					if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
-						lr.logger.CWarn(ctx, rutils.NewBuildTimeoutError(resName.String(), lr.logger))
-					}
-				case <-ctx.Done():
-					return ctx.Err()
+				if err != nil {
+					gNode.LogAndSetLastError(
+						fmt.Errorf("resource build error: %v", err.Error()),
+						"resource", conf.ResourceName(),
+						"model", conf.Model)
+					return
 				}
-				return nil
-			}
 
-			syncRes := forceSync
-			if !syncRes {
-				// TODO(RSDK-6925): support concurrent processing of resources of
-				// APIs with a maximum instance limit. Currently this limit is
-				// validated later in the resource creation flow and assumes that
-				// each resource is created synchronously to have an accurate
-				// creation count.
-				if c, ok := resource.LookupGenericAPIRegistration(resName.API); ok && c.MaxInstance != 0 {
-					syncRes = true
+				// if the ctxWithTimeout fails with DeadlineExceeded, then that means that
+				// resource generation is running async, and we don't currently have good
+				// validation around how this might affect the resource graph. So, we avoid
+				// updating the graph to be safe.
+				if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
+					manager.logger.CErrorw(
+						ctx, "error building resource", "resource", conf.ResourceName(), "model", conf.Model, "error", ctxWithTimeout.Err())
+				} else {
+					gNode.SwapResource(newRes, conf.Model, manager.opts.ftdc, true)
+					manager.logger.CInfow(ctx, fmt.Sprintf("Successfully %ved resource", verb), "resource", resName, "model", conf.Model)
 				}
+
+			default:
+				err := errors.New("config is not for a component or service")
+				gNode.LogAndSetLastError(err, "resource", resName)
 			}
+		})
 
-			if syncRes {
-				if err := processResource(); err != nil {
-					return
+		select {
+		case <-resChan:
+		case <-ctxWithTimeout.Done():
+			// this resource is taking too long to process, so we give up but
+			// continue processing other resources. we do not wait for this
+			// resource to finish processing since it may be running outside code
+			// and have unexpected behavior.
+			if errors.Is(ctxWithTimeout.Err(), context.DeadlineExceeded) {
+				lr.logger.CWarn(ctx, rutils.NewBuildTimeoutError(resName.String(), lr.logger))
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	type processedResource struct {
+		name resource.Name
+		err  error
+	}
+	// buffered so that workers never block on sending their result if we exit early.
+	results := make(chan processedResource, len(ordered))
+	running := 0
+	maxInstanceRunning := false
+	// the logical clock value at which each internal service was last updated during this pass.
+	internalUpdated := map[resource.Name]int64{}
+	for len(ready) > 0 || running > 0 {
+		for running < concurrency {
+			idx := slices.IndexFunc(ready, func(resName resource.Name) bool {
+				return !maxInstanceRunning || !hasMaxInstance(resName)
+			})
+			if idx < 0 {
+				break
+			}
+			resName := ready[idx]
+			if manager.needsWeakAndOptionalDependentsUpdate(lr, resName) {
+				// weak and optional dependents must be updated before they are passed into
+				// constructors or reconfigure methods, which is only safe once nothing else
+				// is being (re)configured.
+				if running > 0 {
+					break
 				}
-			} else {
-				lr.reconfigureWorkers.Add(1)
-				levelErrG.Go(func() error {
-					defer lr.reconfigureWorkers.Done()
-					return processResource()
-				})
+				lr.updateWeakAndOptionalDependents(ctx)
 			}
-		} // for-each resource name
-		if err := levelErrG.Wait(); err != nil {
+			// internal services can be updated while other resources are being (re)configured,
+			// so resources that depend on them only wait for the ones they use.
+			var stale []resource.Name
+			clock := manager.resources.CurrLogicalClockValue()
+			for _, dep := range manager.resources.GetAllParentsOf(resName) {
+				if dep.API.Type.Namespace == resource.APINamespaceRDKInternal && internalUpdated[dep] < clock &&
+					lr.lastWeakAndOptionalDependentsRound.Load() < clock {
+					stale = append(stale, dep)
+					internalUpdated[dep] = clock
+				}
+			}
+			if len(stale) > 0 {
+				lr.updateInternalServices(ctx, stale...)
+			}
+			ready = slices.Delete(ready, idx, idx+1)
+			if hasMaxInstance(resName) {
+				maxInstanceRunning = true
+			}
+			running++
+			lr.reconfigureWorkers.Add(1)
+			goutils.PanicCapturingGo(func() {
+				var err error
+				defer func() {
+					results <- processedResource{name: resName, err: err}
+					lr.reconfigureWorkers.Done()
+				}()
+				err = processResource(resName)
+			})
+		}
+
+		var result processedResource
+		select {
+		case <-ctx.Done():
 			return
+		case result = <-results:
+		}
+		running--
+		if hasMaxInstance(result.name) {
+			maxInstanceRunning = false
+		}
+		if result.err != nil {
+			return
+		}
+		for _, dependent := range dependents[result.name] {
+			remainingDeps[dependent]--
+			if remainingDeps[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
 		}
-	} // for-each level
+	}
+}
+
+// needsWeakAndOptionalDependentsUpdate returns whether weak and optional dependents have to be
+// updated before the given resource is (re)configured. That is the case when the graph changed
+// since the last update and the resource, or one of its dependencies, has weak or optional
+// dependencies. Internal services a resource depends on are updated on their own without waiting
+// for other resources to finish.
+func (manager *resourceManager) needsWeakAndOptionalDependentsUpdate(lr *localRobot, resName resource.Name) bool {
+	if !(resName.API.IsComponent() || resName.API.IsService()) {
+		return false
+	}
+	gNode, ok := manager.resources.Node(resName)
+	if !ok || !gNode.NeedsReconfigure() {
+		return false
+	}
+	if lr.lastWeakAndOptionalDependentsRound.Load() >= manager.resources.CurrLogicalClockValue() {
+		return false
+	}
+	if manager.hasWeakOrOptionalDependencies(lr, resName) {
+		return true
+	}
+	for _, dep := range manager.resources.GetAllParentsOf(resName) {
+		if manager.hasWeakOrOptionalDependencies(lr, dep) {
+			return true
+		}
+	}
+	return false
+}
+
+func (manager *resourceManager) hasWeakOrOptionalDependencies(lr *localRobot, resName resource.Name) bool {
+	gNode, ok := manager.resources.Node(resName)
+	if !ok {
+		return false
+	}
+	conf := gNode.Config()
+	return len(lr.getWeakDependencyMatchers(conf.API, conf.Model)) > 0 || len(conf.OptionalDependencies()) > 0
 }
 
 func (manager *resourceManager) completeConfigForRemotes(ctx context.Context, lr *localRobot) {
@@ -484,7 +484,7 @@ func (m *dummyModMan) Close(ctx context.Context) error {
 	return nil
 }
 
-func (m *dummyModMan) FirstRun(ctx context.Context, conf config.Module) error {
+func (m *dummyModMan) FirstRun(ctx context.Context, conf config.Module, onProgress func(config.FirstRunProgress)) error {
 	return nil
 }
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -2248,3 +2249,103 @@ func TestOfflineRemoteResources(t *testing.T) {
 	mainClient.Refresh(ctx)
 	test.That(t, resourceNames, test.ShouldNotContain, mainClient.ResourceNames())
 }
+
+func TestCompleteConfigIndependentBranches(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var constructed []string
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			if conf.Name == "slow" {
+				<-release
+			}
+			mu.Lock()
+			constructed = append(constructed, conf.Name)
+			mu.Unlock()
+			return inject.NewSensor(conf.Name), nil
+		}})
+	defer func() {
+		resource.Deregister(sensor.API, model)
+	}()
+
+	r := setupLocalRobot(t, ctx, &config.Config{}, logger)
+	cfg := &config.Config{Components: []resource.Config{
+		{Name: "slow", API: sensor.API, Model: model},
+		{Name: "fast", API: sensor.API, Model: model},
+		{Name: "fast-dependent", API: sensor.API, Model: model, DependsOn: []string{"fast"}},
+		{Name: "slow-dependent", API: sensor.API, Model: model, DependsOn: []string{"slow"}},
+	}}
+
+	reconfigured := make(chan struct{})
+	go func() {
+		defer close(reconfigured)
+		r.Reconfigure(ctx, cfg)
+	}()
+
+	// the dependent of the fast resource does not wait for the slow resource in the same level.
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, constructed, test.ShouldResemble, []string{"fast", "fast-dependent"})
+	})
+
+	close(release)
+	<-reconfigured
+	mu.Lock()
+	defer mu.Unlock()
+	test.That(t, constructed, test.ShouldResemble, []string{"fast", "fast-dependent", "slow", "slow-dependent"})
+}
+
+func TestCompleteConfigConcurrencyFromConfig(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	model := resource.DefaultModelFamily.WithModel(utils.RandomAlphaString(8))
+
+	var inFlight, maxInFlight atomic.Int32
+	resource.RegisterComponent(
+		sensor.API,
+		model,
+		resource.Registration[sensor.Sensor, resource.NoNativeConfig]{Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger logging.Logger,
+		) (sensor.Sensor, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				highest := maxInFlight.Load()
+				if n <= highest || maxInFlight.CompareAndSwap(highest, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return inject.NewSensor(conf.Name), nil
+		}})
+	defer func() {
+		resource.Deregister(sensor.API, model)
+	}()
+
+	cfg := &config.Config{ResourceConfigurationConcurrency: 1}
+	for i := 0; i < 4; i++ {
+		cfg.Components = append(cfg.Components, resource.Config{Name: fmt.Sprintf("sensor%d", i), API: sensor.API, Model: model})
+	}
+	r := setupLocalRobot(t, ctx, cfg, logger)
+	for _, conf := range cfg.Components {
+		_, err := sensor.FromProvider(r, conf.Name)
+		test.That(t, err, test.ShouldBeNil)
+	}
+	test.That(t, maxInFlight.Load(), test.ShouldEqual, 1)
+}
@@ -1,6 +1,8 @@
 package robotimpl
 
 import (
+	"go.viam.com/rdk/ftdc"
+	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/robot/web"
 )
 
@@ -22,8 +24,17 @@ type options struct {
 	// whether or not to run FTDC
 	enableFTDC bool
 
+	// ftdcStatsers are recorded by FTDC, by name, in addition to the robot's own.
+	ftdcStatsers map[string]ftdc.Statser
+
 	// disableCompleteConfigWorker starts the robot without the complete config worker - should only be used for tests.
 	disableCompleteConfigWorker bool
+
+	// moduleDevMode restarts local modules when their executables change.
+	moduleDevMode bool
+
+	// recentLogs keeps the most recent logs of the machine for support bundles.
+	recentLogs *logging.RecentLogsAppender
 }
 
 // Option configures how we set up the web service.
@@ -55,6 +66,17 @@ func WithFTDC() Option {
 	})
 }
 
+// WithFTDCStatser returns an Option which has FTDC record the stats of statser under name, if FTDC
+// is enabled, for statsers that live outside of the robot.
+func WithFTDCStatser(name string, statser ftdc.Statser) Option {
+	return newFuncOption(func(o *options) {
+		if o.ftdcStatsers == nil {
+			o.ftdcStatsers = map[string]ftdc.Statser{}
+		}
+		o.ftdcStatsers[name] = statser
+	})
+}
+
 // WithWebOptions returns a Option which sets the streamConfig
 // used to enable audio/video streaming over WebRTC.
 func WithWebOptions(opts ...web.Option) Option {
@@ -93,3 +115,19 @@ func WithDisableCompleteConfigWorker() Option {
 		o.disableCompleteConfigWorker = true
 	})
 }
+
+// WithModuleDevMode returns an Option which restarts local modules when their executables change and
+// re-adds their resources, for use while developing modules.
+func WithModuleDevMode() Option {
+	return newFuncOption(func(o *options) {
+		o.moduleDevMode = true
+	})
+}
+
+// WithRecentLogs returns an Option which has the robot report the logs kept by appender as its
+// recent logs, such as for support bundles. The appender should be added to every logger.
+func WithRecentLogs(appender *logging.RecentLogsAppender) Option {
+	return newFuncOption(func(o *options) {
+		o.recentLogs = appender
+	})
+}
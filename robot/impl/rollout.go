@@ -0,0 +1,88 @@
+package robotimpl
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/rollout"
+)
+
+// The alert raised while a config revision is awaiting acknowledgment.
+const (
+	rolloutAlertSource = "config"
+	rolloutAlertName   = "awaiting_ack"
+)
+
+// admitRevision returns whether newConfig may be applied, which it may not if its revision is held
+// back until it is acknowledged.
+func (r *localRobot) admitRevision(ctx context.Context, newConfig *config.Config) bool {
+	if r.rollout.Admit(newConfig) {
+		r.alerts.Clear(rolloutAlertSource, rolloutAlertName)
+		return true
+	}
+	r.logger.CInfow(ctx, "Config revision is awaiting acknowledgment before it is applied", "revision", newConfig.Revision)
+	err := r.alerts.Raise(robot.Alert{
+		Source:   rolloutAlertSource,
+		Name:     rolloutAlertName,
+		Severity: robot.AlertInfo,
+		Message:  fmt.Sprintf("config revision %s is awaiting acknowledgment", newConfig.Revision),
+		Metadata: map[string]interface{}{"revision": newConfig.Revision},
+	})
+	if err != nil {
+		r.logger.CWarnw(ctx, "Failed to raise config acknowledgment alert", "error", err)
+	}
+	return false
+}
+
+// AcknowledgeRevision applies the given config revision, which must be awaiting acknowledgment.
+func (r *localRobot) AcknowledgeRevision(ctx context.Context, revision string) error {
+	cfg, err := r.rollout.Acknowledge(revision)
+	if err != nil {
+		return err
+	}
+	r.logger.CInfow(ctx, "Config revision acknowledged, applying it", "revision", revision)
+	r.Reconfigure(ctx, cfg)
+	return nil
+}
+
+// RolloutStatus returns the staged rollout state of the robot.
+func (r *localRobot) RolloutStatus() rollout.Status {
+	return r.rollout.Status()
+}
+
+// ConfigRevisionStatuses returns how far each recently received config revision got toward being
+// applied.
+func (r *localRobot) ConfigRevisionStatuses() []robot.ConfigRevisionStatus {
+	return r.rollout.Statuses()
+}
+
+// recordRevisionApplied records that the given revision was applied, partially if applying it
+// gathered errors or left resources unhealthy.
+func (r *localRobot) recordRevisionApplied(revision string, allErrs error) {
+	if revision == "" {
+		return
+	}
+	var errs []string
+	for _, err := range multierr.Errors(allErrs) {
+		errs = append(errs, err.Error())
+	}
+	for _, status := range r.manager.resources.Status() {
+		// internal resources, such as an engaged emergency stop, do not reflect on the config.
+		if status.Name.API.Type.Namespace == resource.APINamespaceRDKInternal {
+			continue
+		}
+		if status.State == resource.NodeStateUnhealthy && status.Error != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", status.Name, status.Error))
+		}
+	}
+	if len(errs) > 0 {
+		r.rollout.Set(revision, robot.ConfigRevisionPartiallyApplied, errs)
+		return
+	}
+	r.rollout.Set(revision, robot.ConfigRevisionApplied, nil)
+}
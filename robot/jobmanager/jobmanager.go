@@ -426,6 +426,16 @@ func (jm *JobManager) scheduleJob(jc config.JobConfig, verbose bool) {
 	jm.namesToJobIDs[jc.Name] = jobID
 }
 
+// RunNow runs the named job once now, in addition to its schedule.
+func (jm *JobManager) RunNow(name string) error {
+	for _, j := range jm.scheduler.Jobs() {
+		if j.Name() == name {
+			return j.RunNow()
+		}
+	}
+	return errors.Errorf("no job named %q is scheduled", name)
+}
+
 // UpdateJobs is called when the "jobs" part of the config gets updated. It updates
 // scheduled jobs based on the Removed/Added/Modified parts of the diff.
 func (jm *JobManager) UpdateJobs(diff *config.Diff) {
@@ -0,0 +1,252 @@
+// Package maintenance implements maintenance mode. While the maintenance windows of a robot's
+// MaintenanceConfig are closed, config changes that would reconfigure resources, modules, or jobs are
+// deferred and queued, and the newest of them is applied once a window opens. Entering maintenance
+// mode opens an ad hoc window, which applies any queued config change right away.
+//
+// Maintenance mode is a resource named PublicServiceName on every local robot, and it is entered,
+// exited, and queried through its DoCommand with the keys below, which works against both local
+// robots and robot clients. Enter, Exit, and GetStatus wrap that contract.
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+)
+
+// PublicServiceName is the generic service through which the maintenance mode of a robot is controlled.
+var PublicServiceName = resource.NewName(generic.API, "$maintenance")
+
+// export keys to be used with DoCommand on PublicServiceName so they can be referenced by clients.
+//
+//   - DoEnter enters maintenance mode
+//     required key: DoEnter
+//     optional key: DoDurationSec, after which maintenance mode is exited on its own
+//   - DoExit exits maintenance mode
+//     required key: DoExit
+//   - DoStatus returns the maintenance state of the robot
+//     required key: DoStatus
+//
+// Every command responds with the maintenance state of the robot as a Status.
+const (
+	DoEnter       = "enter"
+	DoDurationSec = "duration_sec"
+	DoExit        = "exit"
+	DoStatus      = "status"
+)
+
+// Status describes the maintenance state of a robot.
+type Status struct {
+	// Active is whether the robot is in maintenance mode.
+	Active bool `json:"active"`
+	// Until is when maintenance mode is exited on its own, if ever.
+	Until *time.Time `json:"until,omitempty"`
+	// WindowOpen is whether config changes are applied, either because the robot is in maintenance
+	// mode or because a maintenance window is open.
+	WindowOpen bool `json:"window_open"`
+	// NextWindow is when the next maintenance window opens, if WindowOpen is false.
+	NextWindow *time.Time `json:"next_window,omitempty"`
+	// Deferred is whether a config change is queued until the window opens, and DeferredRevision is
+	// the revision of the config it came from.
+	Deferred         bool   `json:"deferred"`
+	DeferredRevision string `json:"deferred_revision,omitempty"`
+}
+
+// A Mode holds the maintenance mode of a robot and whether it has deferred a config change.
+type Mode struct {
+	mu               sync.Mutex
+	active           bool
+	until            time.Time
+	deferred         *config.MaintenanceConfig
+	deferredRevision string
+	wake             chan struct{}
+}
+
+// NewMode returns a Mode that is not in maintenance mode.
+func NewMode() *Mode {
+	return &Mode{wake: make(chan struct{}, 1)}
+}
+
+// Enter enters maintenance mode until the given time, or until exited if it is zero.
+func (m *Mode) Enter(until time.Time) {
+	m.mu.Lock()
+	m.active, m.until = true, until
+	m.mu.Unlock()
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Exit exits maintenance mode.
+func (m *Mode) Exit() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.active, m.until = false, time.Time{}
+}
+
+// Wake returns a channel that receives when maintenance mode is entered.
+func (m *Mode) Wake() <-chan struct{} {
+	return m.wake
+}
+
+// activeLocked must be called with mu held.
+func (m *Mode) activeLocked(now time.Time) bool {
+	if m.active && !m.until.IsZero() && !now.Before(m.until) {
+		m.active, m.until = false, time.Time{}
+	}
+	return m.active
+}
+
+// WindowOpen returns whether config changes may be applied at now under the given config, which is
+// the case in maintenance mode or while one of its maintenance windows is open.
+func (m *Mode) WindowOpen(mc *config.MaintenanceConfig, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.activeLocked(now) || mc.WindowOpen(now)
+}
+
+// Defer records that the config change to the given config was deferred. It returns false if a
+// config change was already deferred.
+func (m *Mode) Defer(cfg *config.Config) bool {
+	mc := cfg.MaintenanceConfig
+	if mc == nil {
+		mc = &config.MaintenanceConfig{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	first := m.deferred == nil
+	m.deferred, m.deferredRevision = mc, cfg.Revision
+	return first
+}
+
+// Deferred returns the maintenance config of the deferred config change, if there is one.
+func (m *Mode) Deferred() (*config.MaintenanceConfig, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.deferred, m.deferred != nil
+}
+
+// ClearDeferred records that no config change is deferred.
+func (m *Mode) ClearDeferred() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deferred, m.deferredRevision = nil, ""
+}
+
+// Status returns the maintenance state at now of a robot with the given maintenance config. The
+// maintenance config of a deferred config change is used instead, if there is one.
+func (m *Mode) Status(mc *config.MaintenanceConfig, now time.Time) Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.deferred != nil {
+		mc = m.deferred
+	}
+	st := Status{
+		Active:           m.activeLocked(now),
+		Deferred:         m.deferred != nil,
+		DeferredRevision: m.deferredRevision,
+	}
+	if st.Active && !m.until.IsZero() {
+		until := m.until
+		st.Until = &until
+	}
+	st.WindowOpen = st.Active || mc.WindowOpen(now)
+	if !st.WindowOpen {
+		if next := mc.NextWindowOpen(now); !next.IsZero() {
+			st.NextWindow = &next
+		}
+	}
+	return st
+}
+
+// Enter puts the given robot in maintenance mode, applying any deferred config change. A positive
+// duration exits maintenance mode after it passes.
+func Enter(ctx context.Context, r robot.Robot, duration time.Duration) error {
+	cmd := map[string]interface{}{DoEnter: true}
+	if duration > 0 {
+		cmd[DoDurationSec] = duration.Seconds()
+	}
+	_, err := doCommand(ctx, r, cmd)
+	return err
+}
+
+// Exit takes the given robot out of maintenance mode.
+func Exit(ctx context.Context, r robot.Robot) error {
+	_, err := doCommand(ctx, r, map[string]interface{}{DoExit: true})
+	return err
+}
+
+// GetStatus returns the maintenance state of the given robot.
+func GetStatus(ctx context.Context, r robot.Robot) (Status, error) {
+	return doCommand(ctx, r, map[string]interface{}{DoStatus: true})
+}
+
+func doCommand(ctx context.Context, r robot.Robot, cmd map[string]interface{}) (Status, error) {
+	res, err := r.ResourceByName(PublicServiceName)
+	if err != nil {
+		return Status{}, err
+	}
+	resp, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return Status{}, err
+	}
+	return resource.DecodeDoCommand[Status](resp)
+}
+
+// A Controller enters and exits the maintenance mode of a robot.
+type Controller interface {
+	// EnterMaintenance enters maintenance mode until the given time, or until exited if it is zero.
+	EnterMaintenance(ctx context.Context, until time.Time) error
+	ExitMaintenance(ctx context.Context) error
+	MaintenanceStatus() Status
+}
+
+type service struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	controller Controller
+}
+
+// NewService returns the resource through which the maintenance mode of the robot behind controller
+// is controlled, which the robot serves as PublicServiceName.
+func NewService(controller Controller) resource.Resource {
+	return &service{Named: PublicServiceName.AsNamed(), controller: controller}
+}
+
+func (svc *service) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	enter, _ := cmd[DoEnter].(bool)
+	exit, _ := cmd[DoExit].(bool)
+	_, status := cmd[DoStatus]
+	switch {
+	case enter && exit:
+		return nil, errors.Errorf("cannot both %s and %s maintenance mode", DoEnter, DoExit)
+	case enter:
+		var until time.Time
+		if v, ok := cmd[DoDurationSec]; ok {
+			sec, ok := v.(float64)
+			if !ok || sec <= 0 {
+				return nil, errors.Errorf("expected %s to be a positive number but got %v", DoDurationSec, v)
+			}
+			until = time.Now().Add(time.Duration(sec * float64(time.Second)))
+		}
+		if err := svc.controller.EnterMaintenance(ctx, until); err != nil {
+			return nil, err
+		}
+	case exit:
+		if err := svc.controller.ExitMaintenance(ctx); err != nil {
+			return nil, err
+		}
+	case !status:
+		return nil, resource.ErrDoUnimplemented
+	}
+	return resource.EncodeDoCommand(svc.controller.MaintenanceStatus())
+}
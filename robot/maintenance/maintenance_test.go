@@ -0,0 +1,122 @@
+package maintenance_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/maintenance"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestMode(t *testing.T) {
+	// a Wednesday, with a window that opens at 02:00 every day.
+	now := time.Date(2024, time.January, 3, 12, 0, 0, 0, time.UTC)
+	mc := &config.MaintenanceConfig{Windows: []config.MaintenanceWindow{{Start: "02:00", End: "04:00", Timezone: "UTC"}}}
+	nextWindow := now.Add(14 * time.Hour)
+
+	m := maintenance.NewMode()
+	test.That(t, m.WindowOpen(nil, now), test.ShouldBeTrue)
+	test.That(t, m.WindowOpen(mc, now), test.ShouldBeFalse)
+	test.That(t, m.Status(mc, now), test.ShouldResemble, maintenance.Status{NextWindow: &nextWindow})
+
+	cfg := &config.Config{Revision: "rev1", MaintenanceConfig: mc}
+	test.That(t, m.Defer(cfg), test.ShouldBeTrue)
+	cfg.Revision = "rev2"
+	test.That(t, m.Defer(cfg), test.ShouldBeFalse)
+	deferred, ok := m.Deferred()
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, deferred, test.ShouldEqual, mc)
+	// the windows of the deferred config apply until it is applied.
+	st := m.Status(nil, now)
+	test.That(t, st.Deferred, test.ShouldBeTrue)
+	test.That(t, st.DeferredRevision, test.ShouldEqual, "rev2")
+	test.That(t, st.WindowOpen, test.ShouldBeFalse)
+
+	until := now.Add(time.Hour)
+	m.Enter(until)
+	<-m.Wake()
+	test.That(t, m.WindowOpen(mc, now), test.ShouldBeTrue)
+	st = m.Status(mc, now)
+	test.That(t, st.Active, test.ShouldBeTrue)
+	test.That(t, st.WindowOpen, test.ShouldBeTrue)
+	test.That(t, *st.Until, test.ShouldEqual, until)
+	test.That(t, st.NextWindow, test.ShouldBeNil)
+
+	// maintenance mode expires on its own.
+	test.That(t, m.WindowOpen(mc, until), test.ShouldBeFalse)
+	test.That(t, m.Status(mc, now).Active, test.ShouldBeFalse)
+
+	m.Enter(time.Time{})
+	test.That(t, m.WindowOpen(mc, now.AddDate(1, 0, 0)), test.ShouldBeTrue)
+	m.Exit()
+	test.That(t, m.WindowOpen(mc, now), test.ShouldBeFalse)
+
+	m.ClearDeferred()
+	_, ok = m.Deferred()
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+type fakeController struct {
+	mode *maintenance.Mode
+	mc   *config.MaintenanceConfig
+}
+
+func (fc *fakeController) EnterMaintenance(ctx context.Context, until time.Time) error {
+	fc.mode.Enter(until)
+	return nil
+}
+
+func (fc *fakeController) ExitMaintenance(ctx context.Context) error {
+	fc.mode.Exit()
+	return nil
+}
+
+func (fc *fakeController) MaintenanceStatus() maintenance.Status {
+	return fc.mode.Status(fc.mc, time.Now())
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	// a window that never opens on its own.
+	fc := &fakeController{
+		mode: maintenance.NewMode(),
+		mc:   &config.MaintenanceConfig{Windows: []config.MaintenanceWindow{{Start: "00:00", End: "00:00", Timezone: "Bad/Zone"}}},
+	}
+	svc := maintenance.NewService(fc)
+	test.That(t, svc.Name(), test.ShouldResemble, maintenance.PublicServiceName)
+
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		test.That(t, name, test.ShouldResemble, maintenance.PublicServiceName)
+		return svc, nil
+	}
+
+	st, err := maintenance.GetStatus(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st, test.ShouldResemble, maintenance.Status{})
+
+	test.That(t, maintenance.Enter(ctx, r, time.Hour), test.ShouldBeNil)
+	st, err = maintenance.GetStatus(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Active, test.ShouldBeTrue)
+	test.That(t, st.WindowOpen, test.ShouldBeTrue)
+	test.That(t, st.Until, test.ShouldNotBeNil)
+	test.That(t, time.Until(*st.Until), test.ShouldBeBetween, 59*time.Minute, time.Hour)
+
+	test.That(t, maintenance.Exit(ctx, r), test.ShouldBeNil)
+	st, err = maintenance.GetStatus(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Active, test.ShouldBeFalse)
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{maintenance.DoEnter: true, maintenance.DoDurationSec: -1.})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{maintenance.DoEnter: true, maintenance.DoExit: true})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+}
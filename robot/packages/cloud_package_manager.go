@@ -59,6 +59,9 @@ type cloudManager struct {
 	statusMu        sync.Mutex
 	packageStatuses map[PackageName]*PackageStatus
 
+	// packageSources are where packages are pulled from, other than the package service.
+	packageSources PackageSources
+
 	logger logging.Logger
 }
 
@@ -142,9 +145,31 @@ func (m *cloudManager) SetPackageState(name PackageName, state PackageState, err
 		s.State = state
 		s.Error = errMsg
 		s.LastUpdated = time.Now()
+		if state == PackageStateFirstRun {
+			s.FirstRunPercent = 0
+			s.FirstRunMessage = ""
+		}
+	}
+}
+
+// SetFirstRunProgress records the progress reported by the first run script of the named package.
+func (m *cloudManager) SetFirstRunProgress(name PackageName, percent float64, message string) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	if s, ok := m.packageStatuses[name]; ok {
+		s.FirstRunPercent = percent
+		s.FirstRunMessage = message
+		s.LastUpdated = time.Now()
 	}
 }
 
+// SetPackageSources sets the mirrors and OCI registries that the next Sync pulls packages from.
+func (m *cloudManager) SetPackageSources(sources PackageSources) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.packageSources = sources
+}
+
 // setPackageStatus sets the full status entry for a package. Download progress is preserved
 // when updating an existing entry for the same package version.
 func (m *cloudManager) setPackageStatus(p config.PackageConfig, state PackageState, errMsg string) {
@@ -230,67 +255,14 @@ func (m *cloudManager) Sync(ctx context.Context, packages []config.PackageConfig
 		m.logger.Debugf("Starting package sync [%d/%d] %s:%s", idx+1, len(changedPackages), p.Package, p.Version)
 		m.setPackageStatus(p, PackageStateDownloading, "")
 
-		// Lookup the packages http url
-		includeURL := true
-
-		packageType, err := config.PackageTypeToProto(p.Type)
-		if err != nil {
-			m.logger.Warnw("failed to get package type", "package", p.Name, "error", err)
-		}
-
-		resp, err := m.client.GetPackage(ctx, &pb.GetPackageRequest{
-			Id:         p.Package,
-			Version:    p.Version,
-			Type:       packageType,
-			IncludeUrl: &includeURL,
-		})
-		if err != nil {
-			m.logger.Errorf("Failed fetching package details for package %s:%s. Err: %v", p.Package, p.Version, err)
-			m.setPackageStatus(p, PackageStateFailed, fmt.Sprintf("failed to fetch package details: %v", err.Error()))
-			outErr = multierr.Append(outErr, fmt.Errorf("failed loading package url for %s:%s %w", p.Package, p.Version, err))
-			continue
+		var err error
+		if p.OCI != nil {
+			err = m.installOCIPackage(ctx, p)
+		} else {
+			err = m.installServicePackage(ctx, p)
 		}
-
-		m.logger.Debugf("Downloading from %s", sanitizeURLForLogs(resp.Package.Url))
-
-		// download package from a http endpoint
-		err = installPackage(ctx, m.logger, m.packagesDir, resp.Package.Url, p, true,
-			func(ctx context.Context, url, dstPath string) (string, string, error) {
-				statusFile := packageSyncFile{
-					PackageID:       p.Package,
-					Version:         p.Version,
-					ModifiedTime:    time.Now(),
-					Status:          syncStatusDownloading,
-					TarballChecksum: "",
-				}
-
-				err = writeStatusFile(p, statusFile, m.packagesDir)
-				if err != nil {
-					return "", "", err
-				}
-
-				checksum, contentType, err := m.downloadFileWithChecksum(ctx, url, dstPath, PackageName(p.Name))
-				if err != nil {
-					return checksum, contentType, err
-				}
-
-				// The tarball is fully downloaded; installPackage will now verify and
-				// extract it.
-				m.setPackageStatus(p, PackageStateLoading, "")
-				return checksum, contentType, nil
-			},
-		)
 		if err != nil {
-			m.logger.Errorf(
-				"Failed downloading/unzipping package %s:%s from %s, %s",
-				p.Package,
-				p.Version,
-				sanitizeURLForLogs(resp.Package.Url),
-				err,
-			)
-			m.setPackageStatus(p, PackageStateFailed, fmt.Sprintf("failed downloading/unzipping package: %v", err.Error()))
-			outErr = multierr.Append(outErr, fmt.Errorf("failed downloading/unzipping package %s:%s from %s %w",
-				p.Package, p.Version, sanitizeURLForLogs(resp.Package.Url), err))
+			outErr = multierr.Append(outErr, err)
 			continue
 		}
 
@@ -335,6 +307,82 @@ func (m *cloudManager) Sync(ctx context.Context, packages []config.PackageConfig
 	return outErr
 }
 
+// installServicePackage installs the package from the first package mirror that has it, or else downloads it from
+// the package service. Failures are logged and recorded in the status of the package.
+func (m *cloudManager) installServicePackage(ctx context.Context, p config.PackageConfig) error {
+	if m.installMirroredPackage(ctx, p) {
+		return nil
+	}
+	if m.client == nil {
+		m.logger.Errorw("Package is not in any mirror and the package service is unreachable", "package", p.Name)
+		m.setPackageStatus(p, PackageStateFailed, "package is not in any mirror and the package service is unreachable")
+		return fmt.Errorf("package %s:%s is not in any mirror and the package service is unreachable", p.Package, p.Version)
+	}
+
+	// Lookup the packages http url
+	includeURL := true
+
+	packageType, err := config.PackageTypeToProto(p.Type)
+	if err != nil {
+		m.logger.Warnw("failed to get package type", "package", p.Name, "error", err)
+	}
+
+	resp, err := m.client.GetPackage(ctx, &pb.GetPackageRequest{
+		Id:         p.Package,
+		Version:    p.Version,
+		Type:       packageType,
+		IncludeUrl: &includeURL,
+	})
+	if err != nil {
+		m.logger.Errorf("Failed fetching package details for package %s:%s. Err: %v", p.Package, p.Version, err)
+		m.setPackageStatus(p, PackageStateFailed, fmt.Sprintf("failed to fetch package details: %v", err.Error()))
+		return fmt.Errorf("failed loading package url for %s:%s %w", p.Package, p.Version, err)
+	}
+
+	m.logger.Debugf("Downloading from %s", sanitizeURLForLogs(resp.Package.Url))
+
+	// download package from a http endpoint
+	err = installPackage(ctx, m.logger, m.packagesDir, resp.Package.Url, p, true,
+		func(ctx context.Context, url, dstPath string) (string, string, error) {
+			statusFile := packageSyncFile{
+				PackageID:       p.Package,
+				Version:         p.Version,
+				ModifiedTime:    time.Now(),
+				Status:          syncStatusDownloading,
+				TarballChecksum: "",
+			}
+
+			err = writeStatusFile(p, statusFile, m.packagesDir)
+			if err != nil {
+				return "", "", err
+			}
+
+			checksum, contentType, err := m.downloadFileWithChecksum(ctx, url, dstPath, PackageName(p.Name))
+			if err != nil {
+				return checksum, contentType, err
+			}
+
+			// The tarball is fully downloaded; installPackage will now verify and
+			// extract it.
+			m.setPackageStatus(p, PackageStateLoading, "")
+			return checksum, contentType, nil
+		},
+	)
+	if err != nil {
+		m.logger.Errorf(
+			"Failed downloading/unzipping package %s:%s from %s, %s",
+			p.Package,
+			p.Version,
+			sanitizeURLForLogs(resp.Package.Url),
+			err,
+		)
+		m.setPackageStatus(p, PackageStateFailed, installFailureMessage("failed downloading/unzipping package", err))
+		return fmt.Errorf("failed downloading/unzipping package %s:%s from %s %w",
+			p.Package, p.Version, sanitizeURLForLogs(resp.Package.Url), err)
+	}
+	return nil
+}
+
 func (m *cloudManager) validateAndGetChangedPackages(
 	packages []config.PackageConfig,
 ) ([]config.PackageConfig, []config.PackageConfig) {
@@ -379,6 +427,7 @@ func (m *cloudManager) Cleanup(ctx context.Context) error {
 	}
 
 	allErrors = multierr.Append(allErrors, m.mlModelSymlinkCleanup())
+	allErrors = multierr.Append(allErrors, m.cleanupOCIBlobs())
 	return allErrors
 }
 
@@ -36,6 +36,8 @@ type deferredPackageManager struct {
 	// syncMu serializes Sync operations without blocking readers of lastSyncedManager,
 	// so that package statuses (including download progress) remain readable mid-Sync.
 	syncMu sync.Mutex
+	// packageSources are handed to whichever manager the next Sync uses. Guarded by syncMu.
+	packageSources PackageSources
 
 	lastSyncedManager     ManagerSyncer
 	lastSyncedManagerLock sync.Mutex
@@ -87,6 +89,7 @@ func (m *deferredPackageManager) Sync(ctx context.Context, packages []config.Pac
 	if err != nil {
 		return err
 	}
+	mgr.SetPackageSources(m.packageSources)
 	m.lastSyncedManagerLock.Lock()
 	m.lastSyncedManager = mgr
 	m.lastSyncedManagerLock.Unlock()
@@ -135,6 +138,16 @@ func (m *deferredPackageManager) getManagerForSync(ctx context.Context, packages
 			// err == nil, not != nil
 			m.cloudManager = mgr
 			m.logger.Info("cloud package manager created synchronously")
+		} else if len(m.packageSources.Mirrors) > 0 {
+			// install what the mirrors have without the package service. The manager is not cached so that the
+			// connection is tried again on the next sync.
+			m.logger.Warnw("failed to create cloud package manager, installing packages from mirrors only", "error", err)
+			mgr, err = NewCloudManager(
+				m.cloudManagerArgs.cloudConfig,
+				nil,
+				m.cloudManagerArgs.packagesDir,
+				m.cloudManagerArgs.logger,
+			)
 		}
 		m.cloudManagerLock.Unlock()
 		return mgr, err
@@ -192,3 +205,17 @@ func (m *deferredPackageManager) SetPackageState(name PackageName, state Package
 	defer m.lastSyncedManagerLock.Unlock()
 	m.lastSyncedManager.SetPackageState(name, state, errMsg)
 }
+
+// SetFirstRunProgress delegates to the last synced manager.
+func (m *deferredPackageManager) SetFirstRunProgress(name PackageName, percent float64, message string) {
+	m.lastSyncedManagerLock.Lock()
+	defer m.lastSyncedManagerLock.Unlock()
+	m.lastSyncedManager.SetFirstRunProgress(name, percent, message)
+}
+
+// SetPackageSources sets the sources that the next Sync pulls packages from.
+func (m *deferredPackageManager) SetPackageSources(sources PackageSources) {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+	m.packageSources = sources
+}
@@ -0,0 +1,361 @@
+package packages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	errw "github.com/pkg/errors"
+	"go.viam.com/utils"
+)
+
+const (
+	// BlockIndexMediaType is the media type of the optional manifest layer that holds the BlockIndex of the package
+	// layer. Registries must support range requests of blobs for delta updates to be used. The layer is written by
+	// "viam packages block-index".
+	BlockIndexMediaType = "application/vnd.viam.package.block-index.v1+json"
+
+	// DefaultBlockSize is the block size of block indexes that balances the size of the index against how much of
+	// a package has to be downloaded again when a part of it changes.
+	DefaultBlockSize = 64 << 10
+
+	maxBlockIndexSize = 64 << 20
+	strongChecksumLen = 16
+)
+
+// A BlockIndex describes the fixed size blocks of a package archive, so that a new version of the package can be
+// assembled from the blocks of archives the robot already has, and only the blocks that changed are downloaded.
+// This is the scheme of zsync. Archives should be compressed with "gzip --rsyncable" so that a change only affects
+// the blocks around it.
+//
+// Delta updates only apply to packages pulled from an OCI registry whose manifest has a block index layer. Packages
+// from the package service are always downloaded in full.
+type BlockIndex struct {
+	BlockSize int   `json:"block_size"`
+	Size      int64 `json:"size"`
+	// Blocks holds the checksums of each block, in order. The last block may be shorter than BlockSize.
+	Blocks []BlockChecksum `json:"blocks"`
+}
+
+// A BlockChecksum holds the rolling checksum of a block, which candidate blocks are found with, and the truncated
+// sha256 checksum of the block, which they are confirmed with.
+type BlockChecksum struct {
+	Weak   uint32 `json:"weak"`
+	Strong string `json:"strong"`
+}
+
+// NewBlockIndex returns the BlockIndex of an archive. It is published as a layer of the package with media type
+// BlockIndexMediaType to enable delta updates.
+func NewBlockIndex(archive io.Reader, blockSize int) (*BlockIndex, error) {
+	if blockSize <= 0 {
+		return nil, errors.New("block size must be positive")
+	}
+	index := &BlockIndex{BlockSize: blockSize}
+	block := make([]byte, blockSize)
+	for {
+		n, err := io.ReadFull(archive, block)
+		if n > 0 {
+			index.Size += int64(n)
+			index.Blocks = append(index.Blocks, BlockChecksum{Weak: newRollingChecksum(block[:n]).sum(), Strong: strongChecksum(block[:n])})
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return index, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rollingChecksum is the rolling checksum of rsync, which can be moved along a file one byte at a time.
+type rollingChecksum struct {
+	a, b   uint32
+	length uint32
+}
+
+func newRollingChecksum(block []byte) rollingChecksum {
+	c := rollingChecksum{length: uint32(len(block))}
+	for i, x := range block {
+		c.a += uint32(x)
+		c.b += uint32(len(block)-i) * uint32(x)
+	}
+	return c
+}
+
+func (c rollingChecksum) sum() uint32 {
+	return c.a&0xffff | c.b<<16
+}
+
+// roll moves the checksum one byte along, dropping out and adding in.
+func (c *rollingChecksum) roll(out, in byte) {
+	c.a += uint32(in) - uint32(out)
+	c.b += c.a - c.length*uint32(out)
+}
+
+func strongChecksum(block []byte) string {
+	sum := sha256.Sum256(block)
+	return hex.EncodeToString(sum[:strongChecksumLen])
+}
+
+// reuseBlocks scans basis for blocks of the index that are not filled yet, writes those found to out at their
+// offset, and marks them filled. It returns the number of bytes reused. Only blocks of the full block size are
+// looked for.
+func (index *BlockIndex) reuseBlocks(ctx context.Context, basis io.Reader, out io.WriterAt, filled []bool) (int64, error) {
+	blockSize := index.BlockSize
+	candidates := map[uint32][]int{}
+	for i, block := range index.Blocks {
+		if !filled[i] && (int64(i)+1)*int64(blockSize) <= index.Size {
+			candidates[block.Weak] = append(candidates[block.Weak], i)
+		}
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	window := make([]byte, 0, 4*blockSize)
+	// fill tops up window so it holds at least n bytes after start, and returns false once basis runs out.
+	start := 0
+	fill := func(n int) (bool, error) {
+		if len(window)-start >= n {
+			return true, nil
+		}
+		if start > 0 {
+			window = append(window[:0], window[start:]...)
+			start = 0
+		}
+		for len(window) < n {
+			read, err := basis.Read(window[len(window):cap(window)])
+			window = window[:len(window)+read]
+			if errors.Is(err, io.EOF) {
+				return len(window) >= n, nil
+			}
+			if err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	var reused int64
+	ok, err := fill(blockSize)
+	if !ok || err != nil {
+		return 0, err
+	}
+	checksum := newRollingChecksum(window[start : start+blockSize])
+	for scanned := 0; ; scanned++ {
+		if scanned%(1<<20) == 0 && ctx.Err() != nil {
+			return reused, ctx.Err()
+		}
+
+		matched := false
+		if blocks, ok := candidates[checksum.sum()]; ok {
+			block := window[start : start+blockSize]
+			strong := strongChecksum(block)
+			for _, i := range blocks {
+				if filled[i] || index.Blocks[i].Strong != strong {
+					continue
+				}
+				if _, err := out.WriteAt(block, int64(i)*int64(blockSize)); err != nil {
+					return reused, err
+				}
+				filled[i] = true
+				matched = true
+				reused += int64(blockSize)
+			}
+		}
+
+		if matched {
+			// skip past the matched block, since blocks of the new archive rarely overlap.
+			start += blockSize
+			if ok, err := fill(blockSize); !ok || err != nil {
+				return reused, err
+			}
+			checksum = newRollingChecksum(window[start : start+blockSize])
+			continue
+		}
+		if ok, err := fill(blockSize + 1); !ok || err != nil {
+			return reused, err
+		}
+		checksum.roll(window[start], window[start+blockSize])
+		start++
+	}
+}
+
+// downloadOCIBlobDelta assembles a layer from the blocks of cached layers that its block index matches, downloads
+// the remaining blocks with range requests, and stores the layer at blobPath once it is verified against its
+// digest. It returns false if the layer could not be assembled, in which case it should be downloaded in full.
+func (m *cloudManager) downloadOCIBlobDelta(
+	ctx context.Context,
+	session *ociSession,
+	layer, indexLayer ociDescriptor,
+	blobPath string,
+	name PackageName,
+) bool {
+	blobsDir := filepath.Dir(blobPath)
+	entries, err := os.ReadDir(blobsDir)
+	if err != nil {
+		return false
+	}
+	var basisPaths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && !strings.HasSuffix(entry.Name(), ".part") {
+			basisPaths = append(basisPaths, filepath.Join(blobsDir, entry.Name()))
+		}
+	}
+	if len(basisPaths) == 0 {
+		return false
+	}
+
+	downloaded, reused, err := m.assembleOCIBlob(ctx, session, layer, indexLayer, basisPaths, blobPath, name)
+	if err != nil {
+		m.logger.Infow("Could not update package from the layers it already has, downloading it in full",
+			"package", name, "digest", layer.Digest, "error", err)
+		return false
+	}
+	m.logger.Infow("Updated package from the layers it already has",
+		"package", name, "digest", layer.Digest, "bytes_reused", reused, "bytes_downloaded", downloaded)
+	return true
+}
+
+func (m *cloudManager) assembleOCIBlob(
+	ctx context.Context,
+	session *ociSession,
+	layer, indexLayer ociDescriptor,
+	basisPaths []string,
+	blobPath string,
+	name PackageName,
+) (downloaded, reused int64, err error) {
+	index, err := fetchBlockIndex(ctx, session, indexLayer)
+	if err != nil {
+		return 0, 0, err
+	}
+	if index.Size != layer.Size {
+		return 0, 0, fmt.Errorf("block index is of %d bytes, but the layer has %d", index.Size, layer.Size)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(blobPath), "*.part")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		if err != nil {
+			utils.UncheckedError(tmpFile.Close())
+			utils.UncheckedError(os.Remove(tmpFile.Name()))
+		}
+	}()
+	if err := tmpFile.Truncate(index.Size); err != nil {
+		return 0, 0, err
+	}
+
+	filled := make([]bool, len(index.Blocks))
+	for _, basisPath := range basisPaths {
+		//nolint:gosec
+		basis, err := os.Open(basisPath)
+		if err != nil {
+			return 0, 0, err
+		}
+		n, err := index.reuseBlocks(ctx, basis, tmpFile, filled)
+		utils.UncheckedError(basis.Close())
+		if err != nil {
+			return 0, 0, err
+		}
+		reused += n
+	}
+	if reused == 0 {
+		return 0, 0, errors.New("no blocks of the layer are cached")
+	}
+	m.setDownloadProgress(name, reused, index.Size)
+
+	// download the blocks that are still missing, merging adjacent ones into a single range request.
+	for i := 0; i < len(filled); {
+		if filled[i] {
+			i++
+			continue
+		}
+		first := i
+		for i < len(filled) && !filled[i] {
+			i++
+		}
+		start := int64(first) * int64(index.BlockSize)
+		end := min(int64(i)*int64(index.BlockSize), index.Size)
+		if err := downloadRange(ctx, session, layer.Digest, tmpFile, start, end); err != nil {
+			return 0, 0, err
+		}
+		downloaded += end - start
+		m.setDownloadProgress(name, reused+downloaded, index.Size)
+	}
+
+	if _, err := tmpFile.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, tmpFile); err != nil {
+		return 0, 0, err
+	}
+	if digest := ociDigestPrefix + hex.EncodeToString(hash.Sum(nil)); digest != layer.Digest {
+		return 0, 0, fmt.Errorf("assembled layer has digest %s, expected %s", digest, layer.Digest)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		return 0, 0, err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return 0, 0, err
+	}
+	return downloaded, reused, os.Rename(tmpFile.Name(), blobPath)
+}
+
+// fetchBlockIndex downloads and verifies the block index of a layer.
+func fetchBlockIndex(ctx context.Context, session *ociSession, indexLayer ociDescriptor) (*BlockIndex, error) {
+	//nolint:bodyclose // closed below
+	resp, err := session.get(ctx, "blobs/"+indexLayer.Digest, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBlockIndexSize))
+	if err != nil {
+		return nil, errw.Wrap(err, "reading block index")
+	}
+	sum := sha256.Sum256(body)
+	if digest := ociDigestPrefix + hex.EncodeToString(sum[:]); digest != indexLayer.Digest {
+		return nil, fmt.Errorf("block index has digest %s, expected %s", digest, indexLayer.Digest)
+	}
+	var index BlockIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		return nil, errw.Wrap(err, "parsing block index")
+	}
+	if index.BlockSize <= 0 || int64(len(index.Blocks)) != (index.Size+int64(index.BlockSize)-1)/int64(index.BlockSize) {
+		return nil, errors.New("invalid block index")
+	}
+	return &index, nil
+}
+
+// downloadRange downloads the bytes [start, end) of a blob to the same offset of out.
+func downloadRange(ctx context.Context, session *ociSession, digest string, out io.WriterAt, start, end int64) error {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", start, end-1)}}
+	//nolint:bodyclose // closed below
+	resp, err := session.get(ctx, "blobs/"+digest, header)
+	if err != nil {
+		return err
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	if resp.StatusCode != http.StatusPartialContent {
+		return errors.New("registry does not support range requests")
+	}
+	n, err := io.Copy(io.NewOffsetWriter(out, start), io.LimitReader(resp.Body, end-start))
+	if err != nil {
+		return errw.Wrap(err, "downloading range")
+	}
+	if n != end-start {
+		return fmt.Errorf("downloaded %d bytes of range %d-%d", n, start, end-1)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package packages
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+func TestRollingChecksum(t *testing.T) {
+	data := make([]byte, 256)
+	_, err := rand.Read(data)
+	test.That(t, err, test.ShouldBeNil)
+
+	const blockSize = 32
+	checksum := newRollingChecksum(data[:blockSize])
+	for i := 1; i+blockSize <= len(data); i++ {
+		checksum.roll(data[i-1], data[i+blockSize-1])
+		test.That(t, checksum.sum(), test.ShouldEqual, newRollingChecksum(data[i:i+blockSize]).sum())
+	}
+}
+
+func TestOCIDeltaUpdate(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	const blockSize = 1024
+
+	// the new layer inserts bytes at the front of, and changes a block in the middle of, the old layer.
+	oldLayer := make([]byte, 40*blockSize)
+	_, err := rand.Read(oldLayer)
+	test.That(t, err, test.ShouldBeNil)
+	newLayer := append([]byte("inserted"), oldLayer...)
+	copy(newLayer[20*blockSize:], bytes.Repeat([]byte{1}, blockSize))
+	newLayer = append(newLayer, []byte("appended")...)
+
+	index, err := NewBlockIndex(bytes.NewReader(newLayer), blockSize)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, index.Size, test.ShouldEqual, len(newLayer))
+	indexJSON, err := json.Marshal(index)
+	test.That(t, err, test.ShouldBeNil)
+
+	var rangeBytes atomic.Int64
+	blobs := map[string][]byte{sha256Digest(newLayer): newLayer, sha256Digest(indexJSON): indexJSON}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		blob, ok := blobs[strings.TrimPrefix(req.URL.Path, "/v2/org/pkg/blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.Header.Get("Range") != "" {
+			var start, end int64
+			_, err := fmt.Sscanf(req.Header.Get("Range"), "bytes=%d-%d", &start, &end)
+			test.That(t, err, test.ShouldBeNil)
+			rangeBytes.Add(end - start + 1)
+		}
+		http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(blob))
+	}))
+	defer server.Close()
+
+	packagesDir := t.TempDir()
+	pm, err := NewCloudManager(&config.Cloud{ID: "some-id", Secret: "some-secret"}, nil, packagesDir, logger)
+	test.That(t, err, test.ShouldBeNil)
+	m := pm.(*cloudManager)
+
+	blobsDir := ociBlobsDir(packagesDir)
+	test.That(t, os.MkdirAll(blobsDir, 0o700), test.ShouldBeNil)
+	test.That(t, os.WriteFile(filepath.Join(blobsDir, strings.TrimPrefix(sha256Digest(oldLayer), ociDigestPrefix)), oldLayer, 0o600),
+		test.ShouldBeNil)
+
+	session := &ociSession{
+		httpClient: &m.httpClient,
+		ref:        config.OCIReference{Registry: strings.TrimPrefix(server.URL, "http://"), Repository: "org/pkg"},
+		registry:   config.PackageRegistry{Insecure: true},
+	}
+	layer := ociDescriptor{MediaType: ociLayerGzipMediaType, Digest: sha256Digest(newLayer), Size: int64(len(newLayer))}
+	indexLayer := ociDescriptor{MediaType: BlockIndexMediaType, Digest: sha256Digest(indexJSON), Size: int64(len(indexJSON))}
+	blobPath := filepath.Join(blobsDir, strings.TrimPrefix(layer.Digest, ociDigestPrefix))
+	test.That(t, m.downloadOCIBlobDelta(ctx, session, layer, indexLayer, blobPath, "some-package"), test.ShouldBeTrue)
+
+	assembled, err := os.ReadFile(blobPath)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bytes.Equal(assembled, newLayer), test.ShouldBeTrue)
+	// only the changed block, and the blocks around the ends that the insertions shifted, are downloaded.
+	test.That(t, rangeBytes.Load(), test.ShouldBeGreaterThan, 0)
+	test.That(t, rangeBytes.Load(), test.ShouldBeLessThanOrEqualTo, 4*blockSize)
+
+	// a layer that no cached layer shares blocks with is not assembled.
+	test.That(t, os.Remove(blobPath), test.ShouldBeNil)
+	test.That(t, os.WriteFile(filepath.Join(blobsDir, strings.TrimPrefix(sha256Digest(oldLayer), ociDigestPrefix)),
+		make([]byte, len(oldLayer)), 0o600), test.ShouldBeNil)
+	test.That(t, m.downloadOCIBlobDelta(ctx, session, layer, indexLayer, blobPath, "some-package"), test.ShouldBeFalse)
+	_, err = os.Stat(blobPath)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+}
@@ -285,9 +285,27 @@ func (m *localManager) SetPackageState(name PackageName, state PackageState, err
 		s.State = state
 		s.Error = errMsg
 		s.LastUpdated = time.Now()
+		if state == PackageStateFirstRun {
+			s.FirstRunPercent = 0
+			s.FirstRunMessage = ""
+		}
 	}
 }
 
+// SetFirstRunProgress records the progress reported by the first run script of the named package.
+func (m *localManager) SetFirstRunProgress(name PackageName, percent float64, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.packageStatuses[name]; ok {
+		s.FirstRunPercent = percent
+		s.FirstRunMessage = message
+		s.LastUpdated = time.Now()
+	}
+}
+
+// SetPackageSources is a no-op for this package manager variant.
+func (m *localManager) SetPackageSources(_ PackageSources) {}
+
 // setPackageStatusLocked sets the full status entry for a package. Must be called with
 // m.mu held (write). Tarball byte counts are preserved when updating an existing entry
 // for the same package version.
@@ -0,0 +1,141 @@
+package packages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+var errNotInMirror = errors.New("package is not in the mirror")
+
+// MirrorArchivePath returns the path of the archive of a package relative to the root of a mirror.
+func MirrorArchivePath(p config.PackageConfig) string {
+	return path.Join(string(p.Type), p.SanitizedName()+".tar.gz")
+}
+
+// InstallArchive installs the package archive at archivePath into packagesDir the same way a package manager
+// does, so that a robot using packagesDir considers the package synced and does not download it again.
+func InstallArchive(ctx context.Context, packagesDir string, p config.PackageConfig, archivePath string, logger logging.Logger) error {
+	if err := p.Validate(""); err != nil {
+		return err
+	}
+	return installPackage(ctx, logger, packagesDir, archivePath, p, false,
+		func(ctx context.Context, archivePath, dstPath string) (string, string, error) {
+			checksum, err := copyArchive(ctx, archivePath, dstPath)
+			return checksum, allowedContentType, err
+		},
+	)
+}
+
+// installMirroredPackage installs the package from the first mirror that has it. It returns false if no mirror
+// could install it, in which case it should be downloaded from the package service.
+func (m *cloudManager) installMirroredPackage(ctx context.Context, p config.PackageConfig) bool {
+	for _, mirror := range m.packageSources.Mirrors {
+		err := installPackage(ctx, m.logger, m.packagesDir, mirror, p, false,
+			func(ctx context.Context, mirror, dstPath string) (string, string, error) {
+				checksum, err := m.fetchFromMirror(ctx, mirror, p, dstPath)
+				if err != nil {
+					return "", "", err
+				}
+				m.setPackageStatus(p, PackageStateLoading, "")
+				return checksum, allowedContentType, nil
+			},
+		)
+		switch {
+		case err == nil:
+			m.logger.Infow("Installed package from mirror", "package", p.Name, "mirror", sanitizeURLForLogs(mirror))
+			return true
+		case errors.Is(err, errNotInMirror):
+			m.logger.Debugw("Package is not in mirror", "package", p.Name, "mirror", sanitizeURLForLogs(mirror))
+		default:
+			m.logger.Warnw("Failed installing package from mirror, trying the next source",
+				"package", p.Name, "mirror", sanitizeURLForLogs(mirror), "error", err)
+		}
+	}
+	return false
+}
+
+// fetchFromMirror copies the archive of a package from a mirror directory, or downloads it from a mirror URL, to
+// dstPath. It returns the sha256 checksum of the archive.
+func (m *cloudManager) fetchFromMirror(ctx context.Context, mirror string, p config.PackageConfig, dstPath string) (string, error) {
+	if filepath.IsAbs(mirror) {
+		checksum, err := copyArchive(ctx, filepath.Join(mirror, filepath.FromSlash(MirrorArchivePath(p))), dstPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", errNotInMirror
+		}
+		return checksum, err
+	}
+
+	rawURL := strings.TrimSuffix(mirror, "/") + "/" + MirrorArchivePath(p)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	//nolint:bodyclose // closed in UncheckedErrorFunc
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", errNotInMirror
+	default:
+		return "", fmt.Errorf("invalid status code %d", resp.StatusCode)
+	}
+
+	m.setDownloadProgress(PackageName(p.Name), 0, resp.ContentLength)
+	checksum, written, err := writeArchive(resp.Body, dstPath)
+	if err != nil {
+		return "", err
+	}
+	m.setDownloadProgress(PackageName(p.Name), written, written)
+	return checksum, nil
+}
+
+// copyArchive copies an archive to dstPath and returns its sha256 checksum.
+func copyArchive(ctx context.Context, archivePath, dstPath string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	//nolint:gosec
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer utils.UncheckedErrorFunc(archive.Close)
+	checksum, _, err := writeArchive(archive, dstPath)
+	return checksum, err
+}
+
+func writeArchive(r io.Reader, dstPath string) (string, int64, error) {
+	//nolint:gosec
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return "", 0, err
+	}
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(dst, hash), r)
+	if err != nil {
+		utils.UncheckedError(dst.Close())
+		return "", 0, err
+	}
+	if err := dst.Close(); err != nil {
+		return "", 0, err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), written, nil
+}
@@ -0,0 +1,94 @@
+package packages
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+func TestMirroredPackages(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	archive := newFakeRegistry(t, map[string]string{"bin/module": "#!/bin/sh"}).layer
+	pkg := config.PackageConfig{
+		Name:    "some-module",
+		Package: "org/pkg",
+		Version: "1.0.0",
+		Type:    config.PackageTypeModule,
+	}
+	mirrorDir := t.TempDir()
+	mirrorPath := filepath.Join(mirrorDir, filepath.FromSlash(MirrorArchivePath(pkg)))
+	test.That(t, os.MkdirAll(filepath.Dir(mirrorPath), 0o700), test.ShouldBeNil)
+	test.That(t, os.WriteFile(mirrorPath, archive, 0o600), test.ShouldBeNil)
+	server := httptest.NewServer(http.FileServer(http.Dir(mirrorDir)))
+	defer server.Close()
+
+	// the managers have no package service client, so every package must come from a mirror.
+	newManager := func(t *testing.T, packagesDir string, mirrors ...string) *cloudManager {
+		t.Helper()
+		pm, err := NewCloudManager(&config.Cloud{ID: "some-id", Secret: "some-secret"}, nil, packagesDir, logger)
+		test.That(t, err, test.ShouldBeNil)
+		pm.SetPackageSources(PackageSources{Mirrors: mirrors})
+		return pm.(*cloudManager)
+	}
+	checkInstalled := func(t *testing.T, packagesDir string) {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(pkg.LocalDataDirectory(packagesDir), "bin", "module"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(content), test.ShouldEqual, "#!/bin/sh")
+	}
+
+	for _, tc := range []struct {
+		name   string
+		mirror string
+	}{
+		{"directory", mirrorDir},
+		{"url", server.URL},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			packagesDir := t.TempDir()
+			// the first mirror does not have the package, so the second is used.
+			pm := newManager(t, packagesDir, t.TempDir(), tc.mirror)
+			test.That(t, pm.Sync(ctx, []config.PackageConfig{pkg}, nil), test.ShouldBeNil)
+			checkInstalled(t, packagesDir)
+			statuses := pm.PackageStatuses()
+			test.That(t, statuses, test.ShouldHaveLength, 1)
+			test.That(t, statuses[0].State, test.ShouldEqual, PackageStateReady)
+		})
+	}
+
+	t.Run("not in any mirror", func(t *testing.T) {
+		pm := newManager(t, t.TempDir(), t.TempDir(), server.URL+"/missing")
+		err := pm.Sync(ctx, []config.PackageConfig{pkg}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "not in any mirror")
+		statuses := pm.PackageStatuses()
+		test.That(t, statuses, test.ShouldHaveLength, 1)
+		test.That(t, statuses[0].State, test.ShouldEqual, PackageStateFailed)
+	})
+
+	t.Run("preseeded", func(t *testing.T) {
+		packagesDir := t.TempDir()
+		test.That(t, InstallArchive(ctx, packagesDir, pkg, mirrorPath, logger), test.ShouldBeNil)
+		checkInstalled(t, packagesDir)
+		// the archive is copied rather than moved.
+		_, err := os.Stat(mirrorPath)
+		test.That(t, err, test.ShouldBeNil)
+
+		// a manager without any source considers the preseeded package synced.
+		pm := newManager(t, packagesDir)
+		test.That(t, pm.Sync(ctx, []config.PackageConfig{pkg}, nil), test.ShouldBeNil)
+		path, err := pm.PackagePath(PackageName(pkg.Name))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, path, test.ShouldEqual, pkg.LocalDataDirectory(packagesDir))
+	})
+}
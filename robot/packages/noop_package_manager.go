@@ -56,3 +56,9 @@ func (m *noopManager) PackageStatuses() []PackageStatus {
 
 // SetPackageState is a no-op for this package manager variant.
 func (m *noopManager) SetPackageState(_ PackageName, _ PackageState, _ string) {}
+
+// SetFirstRunProgress is a no-op for this package manager variant.
+func (m *noopManager) SetFirstRunProgress(_ PackageName, _ float64, _ string) {}
+
+// SetPackageSources is a no-op for this package manager variant.
+func (m *noopManager) SetPackageSources(_ PackageSources) {}
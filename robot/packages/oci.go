@@ -0,0 +1,439 @@
+package packages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	errw "github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/config"
+)
+
+const (
+	ociManifestMediaType     = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType  = "application/vnd.docker.distribution.manifest.v2+json"
+	ociLayerGzipMediaType    = "application/vnd.oci.image.layer.v1.tar+gzip"
+	dockerLayerGzipMediaType = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+
+	ociDigestPrefix    = "sha256:"
+	maxOCIManifestSize = 4 << 20
+)
+
+// ociDescriptor and ociManifest are the parts of an OCI image manifest that packages are pulled with.
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ociSession pulls from a single repository of a registry, and holds on to the token the registry hands out so that
+// it is only requested once per package.
+type ociSession struct {
+	httpClient *http.Client
+	ref        config.OCIReference
+	registry   config.PackageRegistry
+	authHeader string
+}
+
+// packageSource identifies where a package was pulled from, beyond its id and version, so that a package is
+// pulled again when its source changes. It is empty for packages from the package service.
+func packageSource(p config.PackageConfig) string {
+	if p.OCI == nil {
+		return ""
+	}
+	if p.OCI.Digest != "" {
+		return p.OCI.Reference + "@" + p.OCI.Digest
+	}
+	return p.OCI.Reference
+}
+
+// ociBlobsDir returns the content-addressed cache that layers pulled from OCI registries are kept in.
+func ociBlobsDir(packagesDir string) string {
+	return filepath.Join(packagesDir, "oci", "blobs", "sha256")
+}
+
+// installOCIPackage pulls the package from its OCI registry and installs it. Failures are logged and recorded in the
+// status of the package.
+func (m *cloudManager) installOCIPackage(ctx context.Context, p config.PackageConfig) error {
+	err := installPackage(ctx, m.logger, m.packagesDir, p.OCI.Reference, p, false,
+		func(ctx context.Context, _, dstPath string) (string, string, error) {
+			statusFile := packageSyncFile{
+				PackageID:    p.Package,
+				Version:      p.Version,
+				Source:       packageSource(p),
+				ModifiedTime: time.Now(),
+				Status:       syncStatusDownloading,
+			}
+			if err := writeStatusFile(p, statusFile, m.packagesDir); err != nil {
+				return "", "", err
+			}
+
+			digest, err := m.pullOCILayer(ctx, p, dstPath)
+			if err != nil {
+				return "", "", err
+			}
+			m.setPackageStatus(p, PackageStateLoading, "")
+			return digest, allowedContentType, nil
+		},
+	)
+	if err != nil {
+		m.logger.Errorw("Failed pulling package from OCI registry", "package", p.Name, "reference", p.OCI.Reference, "error", err)
+		m.setPackageStatus(p, PackageStateFailed, installFailureMessage("failed pulling package from OCI registry", err))
+		return fmt.Errorf("failed pulling package %s:%s from %s %w", p.Package, p.Version, p.OCI.Reference, err)
+	}
+	return nil
+}
+
+// pullOCILayer downloads the layer of the package into the blob cache, unless it is cached already, and links it to
+// dstPath. It returns the digest of the layer.
+func (m *cloudManager) pullOCILayer(ctx context.Context, p config.PackageConfig, dstPath string) (string, error) {
+	ref, manifestRef, pinnedDigest, err := p.OCI.Manifest()
+	if err != nil {
+		return "", err
+	}
+	session := &ociSession{httpClient: &m.httpClient, ref: ref, registry: config.PackageRegistry{Host: ref.Registry}}
+	for _, registry := range m.packageSources.Registries {
+		if registry.Host == ref.Registry {
+			session.registry = registry
+		}
+	}
+
+	layer, index, err := session.packageLayer(ctx, manifestRef, pinnedDigest)
+	if err != nil {
+		return "", err
+	}
+
+	blobsDir := ociBlobsDir(m.packagesDir)
+	if err := os.MkdirAll(blobsDir, 0o700); err != nil {
+		return "", err
+	}
+	blobPath := filepath.Join(blobsDir, strings.TrimPrefix(layer.Digest, ociDigestPrefix))
+	switch _, err := os.Stat(blobPath); {
+	case err == nil:
+		m.logger.Debugw("Using cached OCI layer", "package", p.Name, "digest", layer.Digest)
+		m.setDownloadProgress(PackageName(p.Name), layer.Size, layer.Size)
+	case index != nil && m.downloadOCIBlobDelta(ctx, session, layer, *index, blobPath, PackageName(p.Name)):
+	default:
+		if err := m.downloadOCIBlob(ctx, session, layer, blobPath, PackageName(p.Name)); err != nil {
+			return "", err
+		}
+	}
+
+	// installPackage removes dstPath once it is unpacked, so it must not be the cached blob itself.
+	if err := os.Link(blobPath, dstPath); err != nil {
+		if err := copyBlob(blobPath, dstPath); err != nil {
+			return "", err
+		}
+	}
+	return layer.Digest, nil
+}
+
+// downloadOCIBlob downloads a blob to blobPath, verifying it against its digest first so that the cache only ever
+// holds verified blobs.
+func (m *cloudManager) downloadOCIBlob(
+	ctx context.Context,
+	session *ociSession,
+	layer ociDescriptor,
+	blobPath string,
+	name PackageName,
+) (err error) {
+	//nolint:bodyclose // closed below
+	resp, err := session.get(ctx, "blobs/"+layer.Digest, nil)
+	if err != nil {
+		return err
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(blobPath), "*.part")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			utils.UncheckedError(tmpFile.Close())
+			utils.UncheckedError(os.Remove(tmpFile.Name()))
+		}
+	}()
+
+	m.setDownloadProgress(name, 0, layer.Size)
+	progressCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go utils.PanicCapturingGo(func() {
+		fileSizeProgress(progressCtx, m.logger, tmpFile.Name(), layer.Size, func(curBytes int64) {
+			m.setDownloadProgress(name, curBytes, layer.Size)
+		})
+	})
+
+	hash := sha256.New()
+	downloadedBytes, err := io.Copy(io.MultiWriter(tmpFile, hash), resp.Body)
+	if err != nil {
+		return errw.Wrap(err, "downloading layer")
+	}
+	m.setDownloadProgress(name, downloadedBytes, layer.Size)
+	if digest := ociDigestPrefix + hex.EncodeToString(hash.Sum(nil)); digest != layer.Digest {
+		return fmt.Errorf("downloaded layer has digest %s, expected %s", digest, layer.Digest)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), blobPath)
+}
+
+// packageLayer fetches the manifest of the package and returns its gzipped tarball layer, along with the block index
+// of that layer if the manifest has one. If pinnedDigest is set the manifest must match it.
+func (s *ociSession) packageLayer(ctx context.Context, manifestRef, pinnedDigest string) (ociDescriptor, *ociDescriptor, error) {
+	//nolint:bodyclose // closed below
+	resp, err := s.get(ctx, "manifests/"+manifestRef, http.Header{"Accept": {ociManifestMediaType + ", " + dockerManifestMediaType}})
+	if err != nil {
+		return ociDescriptor{}, nil, err
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxOCIManifestSize))
+	if err != nil {
+		return ociDescriptor{}, nil, errw.Wrap(err, "reading manifest")
+	}
+
+	sum := sha256.Sum256(body)
+	digest := ociDigestPrefix + hex.EncodeToString(sum[:])
+	if pinnedDigest != "" && digest != pinnedDigest {
+		return ociDescriptor{}, nil, fmt.Errorf("manifest of %s has digest %s, expected pinned digest %s", manifestRef, digest, pinnedDigest)
+	}
+
+	var manifest ociManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return ociDescriptor{}, nil, errw.Wrap(err, "parsing manifest")
+	}
+	if manifest.MediaType == "" {
+		manifest.MediaType = resp.Header.Get("Content-Type")
+	}
+	if manifest.MediaType != ociManifestMediaType && manifest.MediaType != dockerManifestMediaType {
+		return ociDescriptor{}, nil, fmt.Errorf("unsupported manifest type %q", manifest.MediaType)
+	}
+
+	var layers []ociDescriptor
+	var index *ociDescriptor
+	for _, layer := range manifest.Layers {
+		switch layer.MediaType {
+		case ociLayerGzipMediaType, dockerLayerGzipMediaType, allowedContentType, "application/gzip":
+			layers = append(layers, layer)
+		case BlockIndexMediaType:
+			if config.ValidateOCIDigest(layer.Digest) == nil {
+				index = &layer
+			}
+		}
+	}
+	if len(layers) != 1 {
+		return ociDescriptor{}, nil, fmt.Errorf("manifest must have exactly one gzipped tarball layer, found %d", len(layers))
+	}
+	if err := config.ValidateOCIDigest(layers[0].Digest); err != nil {
+		return ociDescriptor{}, nil, err
+	}
+	return layers[0], index, nil
+}
+
+// get requests a path under the repository of the session, authenticating with the registry if it asks to. A
+// partial response is only returned if a Range header is set.
+func (s *ociSession) get(ctx context.Context, path string, header http.Header) (*http.Response, error) {
+	scheme := "https"
+	if s.registry.Insecure {
+		scheme = "http"
+	}
+	rawURL := fmt.Sprintf("%s://%s/v2/%s/%s", scheme, s.ref.Registry, s.ref.Repository, path)
+
+	do := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if s.authHeader != "" {
+			req.Header.Set("Authorization", s.authHeader)
+		}
+		return s.httpClient.Do(req)
+	}
+
+	resp, err := do()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && s.authHeader == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		utils.UncheckedError(resp.Body.Close())
+		if err := s.authenticate(ctx, challenge); err != nil {
+			return nil, errw.Wrapf(err, "authenticating with %s", s.ref.Registry)
+		}
+		if resp, err = do(); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK && (resp.StatusCode != http.StatusPartialContent || header.Get("Range") == "") {
+		utils.UncheckedError(resp.Body.Close())
+		return nil, fmt.Errorf("invalid status code %d from %s", resp.StatusCode, sanitizeURLForLogs(rawURL))
+	}
+	return resp, nil
+}
+
+// authenticate answers a WWW-Authenticate challenge of the registry, either with basic auth or by exchanging the
+// credentials of the registry, if any, for a bearer token.
+func (s *ociSession) authenticate(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch strings.ToLower(scheme) {
+	case "basic", "":
+		if s.registry.Username == "" {
+			return fmt.Errorf("registry requires credentials, add %s to package_registries", s.ref.Registry)
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(s.registry.Username + ":" + s.registry.Password))
+		s.authHeader = "Basic " + credentials
+		return nil
+	case "bearer":
+	default:
+		return fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+
+	challengeParams := parseAuthChallenge(params)
+	realm, err := url.Parse(challengeParams["realm"])
+	if err != nil || challengeParams["realm"] == "" {
+		return fmt.Errorf("invalid token realm %q", challengeParams["realm"])
+	}
+	query := realm.Query()
+	if service := challengeParams["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := challengeParams["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", s.ref.Repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if s.registry.Username != "" {
+		req.SetBasicAuth(s.registry.Username, s.registry.Password)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer utils.UncheckedErrorFunc(resp.Body.Close)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("invalid status code %d requesting token", resp.StatusCode)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return errw.Wrap(err, "parsing token")
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return errors.New("registry returned an empty token")
+	}
+	s.authHeader = "Bearer " + token.Token
+	return nil
+}
+
+// parseAuthChallenge parses the comma separated key="value" parameters of a WWW-Authenticate challenge.
+func parseAuthChallenge(params string) map[string]string {
+	parsed := map[string]string{}
+	for params != "" {
+		key, rest, ok := strings.Cut(strings.TrimLeft(params, ", "), "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			value, params = rest[1:end+1], rest[end+2:]
+		} else {
+			value, params, _ = strings.Cut(rest, ",")
+		}
+		parsed[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return parsed
+}
+
+// cleanupOCIBlobs removes cached blobs that no managed package was installed from.
+func (m *cloudManager) cleanupOCIBlobs() error {
+	blobsDir := ociBlobsDir(m.packagesDir)
+	entries, err := os.ReadDir(blobsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	expected := map[string]bool{}
+	for _, p := range m.managedPackages {
+		if p.OCI == nil {
+			continue
+		}
+		statusFile, err := readStatusFile(*p, m.packagesDir)
+		if err != nil {
+			// keep every blob rather than risk pulling a package again.
+			return nil
+		}
+		expected[strings.TrimPrefix(statusFile.TarballChecksum, ociDigestPrefix)] = true
+	}
+
+	var allErrors error
+	for _, entry := range entries {
+		if expected[entry.Name()] {
+			continue
+		}
+		m.logger.Infof("Cleaning up unused OCI layer %s", entry.Name())
+		allErrors = multierr.Append(allErrors, os.Remove(filepath.Join(blobsDir, entry.Name())))
+	}
+	return allErrors
+}
+
+func copyBlob(from, to string) error {
+	//nolint:gosec
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer utils.UncheckedErrorFunc(src.Close)
+	//nolint:gosec
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return multierr.Combine(err, dst.Close())
+	}
+	return dst.Close()
+}
@@ -0,0 +1,210 @@
+package packages
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+// fakeRegistry serves a single package from repository "org/pkg" and requires a bearer token obtained with
+// username "user" and password "pass".
+type fakeRegistry struct {
+	manifest      []byte
+	layer         []byte
+	blobRequests  atomic.Int32
+	tokenRequests atomic.Int32
+}
+
+func newFakeRegistry(t *testing.T, contents map[string]string) *fakeRegistry {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range contents {
+		test.That(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content))}), test.ShouldBeNil)
+		_, err := tw.Write([]byte(content))
+		test.That(t, err, test.ShouldBeNil)
+	}
+	test.That(t, tw.Close(), test.ShouldBeNil)
+	test.That(t, gz.Close(), test.ShouldBeNil)
+
+	r := &fakeRegistry{layer: buf.Bytes()}
+	manifest, err := json.Marshal(ociManifest{
+		MediaType: ociManifestMediaType,
+		Layers: []ociDescriptor{
+			{MediaType: "application/vnd.oci.image.config.v1+json", Digest: sha256Digest([]byte("{}")), Size: 2},
+			{MediaType: ociLayerGzipMediaType, Digest: sha256Digest(r.layer), Size: int64(len(r.layer))},
+		},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	r.manifest = manifest
+	return r
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		r.tokenRequests.Add(1)
+		if user, pass, ok := req.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if req.URL.Query().Get("scope") != "repository:org/pkg:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"token":"secret-token"}`))
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer secret-token" {
+		w.Header().Set("WWW-Authenticate",
+			`Bearer realm="http://`+req.Host+`/token",service="registry",scope="repository:org/pkg:pull"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case req.URL.Path == "/v2/org/pkg/manifests/1.0.0" || req.URL.Path == "/v2/org/pkg/manifests/"+sha256Digest(r.manifest):
+		w.Header().Set("Content-Type", ociManifestMediaType)
+		_, _ = w.Write(r.manifest)
+	case req.URL.Path == "/v2/org/pkg/blobs/"+sha256Digest(r.layer):
+		r.blobRequests.Add(1)
+		_, _ = w.Write(r.layer)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return ociDigestPrefix + hex.EncodeToString(sum[:])
+}
+
+func TestOCIPackages(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	registry := newFakeRegistry(t, map[string]string{"bin/module": "#!/bin/sh"})
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+	registries := []config.PackageRegistry{{Host: host, Username: "user", Password: "pass", Insecure: true}}
+
+	newManager := func(t *testing.T, packagesDir string) *cloudManager {
+		t.Helper()
+		pm, err := NewCloudManager(&config.Cloud{ID: "some-id", Secret: "some-secret"}, nil, packagesDir, logger)
+		test.That(t, err, test.ShouldBeNil)
+		pm.SetPackageSources(PackageSources{Registries: registries})
+		return pm.(*cloudManager)
+	}
+	pkg := config.PackageConfig{
+		Name:    "some-module",
+		Package: "org/pkg",
+		Version: "1.0.0",
+		Type:    config.PackageTypeModule,
+		OCI:     &config.PackageOCISource{Reference: host + "/org/pkg:1.0.0"},
+	}
+
+	t.Run("pulls and caches the layer", func(t *testing.T) {
+		packagesDir := t.TempDir()
+		pm := newManager(t, packagesDir)
+		test.That(t, pm.Sync(ctx, []config.PackageConfig{pkg}, nil), test.ShouldBeNil)
+
+		content, err := os.ReadFile(filepath.Join(pkg.LocalDataDirectory(packagesDir), "bin", "module"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(content), test.ShouldEqual, "#!/bin/sh")
+		statuses := pm.PackageStatuses()
+		test.That(t, statuses, test.ShouldHaveLength, 1)
+		test.That(t, statuses[0].State, test.ShouldEqual, PackageStateReady)
+
+		blobPath := filepath.Join(ociBlobsDir(packagesDir), strings.TrimPrefix(sha256Digest(registry.layer), ociDigestPrefix))
+		_, err = os.Stat(blobPath)
+		test.That(t, err, test.ShouldBeNil)
+
+		// pinning the digest of the same manifest changes the source, so the package is installed again, but from
+		// the cache.
+		blobRequests := registry.blobRequests.Load()
+		pinned := pkg
+		pinned.OCI = &config.PackageOCISource{Reference: pkg.OCI.Reference, Digest: sha256Digest(registry.manifest)}
+		test.That(t, pm.Sync(ctx, []config.PackageConfig{pinned}, nil), test.ShouldBeNil)
+		test.That(t, registry.blobRequests.Load(), test.ShouldEqual, blobRequests)
+
+		test.That(t, pm.Sync(ctx, nil, nil), test.ShouldBeNil)
+		test.That(t, pm.Cleanup(ctx), test.ShouldBeNil)
+		_, err = os.Stat(blobPath)
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	})
+
+	t.Run("pinned digest mismatch", func(t *testing.T) {
+		packagesDir := t.TempDir()
+		pm := newManager(t, packagesDir)
+		pinned := pkg
+		pinned.OCI = &config.PackageOCISource{Reference: pkg.OCI.Reference, Digest: sha256Digest([]byte("other"))}
+		err := pm.Sync(ctx, []config.PackageConfig{pinned}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "expected pinned digest")
+
+		statuses := pm.PackageStatuses()
+		test.That(t, statuses, test.ShouldHaveLength, 1)
+		test.That(t, statuses[0].State, test.ShouldEqual, PackageStateFailed)
+		test.That(t, statuses[0].Error, test.ShouldContainSubstring, "expected pinned digest")
+	})
+
+	t.Run("failed verification", func(t *testing.T) {
+		packagesDir := t.TempDir()
+		pm := newManager(t, packagesDir)
+		verified := pkg
+		verified.Verification = &config.PackageVerification{Checksum: sha256Digest([]byte("other"))}
+		err := pm.Sync(ctx, []config.PackageConfig{verified}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, errors.Is(err, ErrPackageVerification), test.ShouldBeTrue)
+
+		statuses := pm.PackageStatuses()
+		test.That(t, statuses, test.ShouldHaveLength, 1)
+		test.That(t, statuses[0].State, test.ShouldEqual, PackageStateFailed)
+		test.That(t, statuses[0].Error, test.ShouldStartWith, ErrPackageVerification.Error())
+		_, err = pm.PackagePath(PackageName(verified.Name))
+		test.That(t, err, test.ShouldEqual, ErrPackageMissing)
+		_, err = os.Stat(verified.LocalDataDirectory(packagesDir))
+		test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+
+		// the package is installed once the checksum is fixed.
+		verified.Verification = &config.PackageVerification{Checksum: sha256Digest(registry.layer)}
+		test.That(t, pm.Sync(ctx, []config.PackageConfig{verified}, nil), test.ShouldBeNil)
+		_, err = pm.PackagePath(PackageName(verified.Name))
+		test.That(t, err, test.ShouldBeNil)
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		pm := newManager(t, t.TempDir())
+		pm.SetPackageSources(PackageSources{Registries: []config.PackageRegistry{{Host: host, Insecure: true}}})
+		err := pm.Sync(ctx, []config.PackageConfig{pkg}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "invalid status code 401 requesting token")
+		test.That(t, registry.tokenRequests.Load(), test.ShouldBeGreaterThan, 0)
+	})
+}
+
+func TestParseAuthChallenge(t *testing.T) {
+	params := parseAuthChallenge(`realm="https://auth.example.com/token",service="registry.example.com",scope="repository:a/b:pull"`)
+	test.That(t, params, test.ShouldResemble, map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:a/b:pull",
+	})
+}
@@ -21,9 +21,26 @@ type (
 // ErrPackageMissing is an error when a package cannot be found.
 var ErrPackageMissing = errors.New("package missing")
 
+// ErrPackageVerification is an error when a package archive does not match the checksum or signature in its config.
+var ErrPackageVerification = errors.New("package failed verification")
+
 // ErrInvalidPackageRef is an error when a invalid package reference syntax.
 var ErrInvalidPackageRef = errors.New("invalid package reference")
 
+// PackageSources are where packages are pulled from, other than the package service.
+type PackageSources struct {
+	// Registries holds the credentials for the OCI registries of packages with an OCI source.
+	Registries []config.PackageRegistry
+	// Mirrors are directories or URLs that packages from the package service are looked up in, in order,
+	// before the package service. See config.Config.PackageMirrors.
+	Mirrors []string
+}
+
+// NewPackageSources returns the package sources of a robot config.
+func NewPackageSources(cfg *config.Config) PackageSources {
+	return PackageSources{Registries: cfg.PackageRegistries, Mirrors: cfg.PackageMirrors}
+}
+
 // Manager provides a managed interface for looking up package paths. This is separated from ManagerSyncer to avoid passing
 // the full sync interface to all components.
 type Manager interface {
@@ -58,4 +75,10 @@ type ManagerSyncer interface {
 	// SetPackageState updates the in-memory state for a named package. Used by local_robot to
 	// transition a module package through the first-run lifecycle stage.
 	SetPackageState(name PackageName, state PackageState, errMsg string)
+
+	// SetFirstRunProgress records the progress reported by the first run script of a module package.
+	SetFirstRunProgress(name PackageName, percent float64, message string)
+
+	// SetPackageSources sets the mirrors and OCI registries that packages are pulled from on the next Sync.
+	SetPackageSources(sources PackageSources)
 }
@@ -44,4 +44,8 @@ type PackageStatus struct {
 	BytesDownloaded uint64
 	// TotalBytes is the total size of the package tarball in bytes. Zero if unknown.
 	TotalBytes uint64
+	// FirstRunPercent and FirstRunMessage are the progress last reported by the first run script of a
+	// module package. They are reset when the package enters PackageStateFirstRun.
+	FirstRunPercent float64
+	FirstRunMessage string
 }
@@ -104,6 +104,15 @@ func installPackage(
 		return fmt.Errorf("unknown content-type for package %s", contentType)
 	}
 
+	if p.Verification != nil {
+		if err := verifyPackageArchive(p.Verification, dstPath); err != nil {
+			// remove the archive so that it is downloaded again rather than resumed.
+			utils.UncheckedError(os.Remove(dstPath))
+			utils.UncheckedError(cleanup(packagesDir, p))
+			return err
+		}
+	}
+
 	// unpack to temp directory to ensure we do an atomic rename once finished.
 	tmpDataPath, err := os.MkdirTemp(parentDir, "*.tmp")
 	if err != nil {
@@ -126,6 +135,7 @@ func installPackage(
 		statusFile := packageSyncFile{
 			PackageID:       p.Package,
 			Version:         p.Version,
+			Source:          packageSource(p),
 			ModifiedTime:    time.Now(),
 			Status:          syncStatusFailed,
 			TarballChecksum: "",
@@ -153,6 +163,8 @@ func installPackage(
 	statusFile := packageSyncFile{
 		PackageID:       p.Package,
 		Version:         p.Version,
+		Source:          packageSource(p),
+		Verification:    verificationFingerprint(p),
 		ModifiedTime:    time.Now(),
 		Status:          syncStatusDone,
 		TarballChecksum: checksum,
@@ -417,6 +429,10 @@ type packageSyncFile struct {
 	ModifiedTime    time.Time  `json:"modified_time"`
 	Status          syncStatus `json:"sync_status"`
 	TarballChecksum string     `json:"tarball_checksum"`
+	// Source is where the package was pulled from, if not the package service. See packageSource.
+	Source string `json:"source,omitempty"`
+	// Verification is the fingerprint of the verification the package passed. See verificationFingerprint.
+	Verification string `json:"verification,omitempty"`
 }
 
 func packageIsSynced(pkg config.PackageConfig, packagesDir string, logger logging.Logger) bool {
@@ -431,6 +447,12 @@ func packageIsSynced(pkg config.PackageConfig, packagesDir string, logger loggin
 			// filename given the log line context.
 			"packageName", pkg.Name, "packageVersion", pkg.Version, "packageId", pkg.Package, "packagesDir", packagesDir, "err", err)
 		return false
+	case syncFile.Source != packageSource(pkg):
+		logger.Infow("Package source changed", "name", pkg.Name, "previous", syncFile.Source, "source", packageSource(pkg))
+		return false
+	case syncFile.Status == syncStatusDone && syncFile.Verification != verificationFingerprint(pkg):
+		logger.Infow("Package verification changed, verifying it again", "name", pkg.Name)
+		return false
 	case syncFile.PackageID == pkg.Package && syncFile.Version == pkg.Version && syncFile.Status == syncStatusDone:
 		logger.Debugf("Package already downloaded at %s, skipping.", pkg.LocalDataDirectory(packagesDir))
 		return true
@@ -0,0 +1,169 @@
+package packages
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.viam.com/utils"
+	"golang.org/x/crypto/blake2b"
+
+	"go.viam.com/rdk/config"
+	rutils "go.viam.com/rdk/utils"
+)
+
+const (
+	minisignKeyAlgorithm       = "Ed"
+	minisignPrehashedAlgorithm = "ED"
+	minisignKeyIDSize          = 8
+	minisignUntrustedPrefix    = "untrusted comment:"
+	minisignTrustedPrefix      = "trusted comment: "
+)
+
+// verifyPackageArchive checks the archive at archivePath against the checksum and signature of v. The returned
+// error wraps ErrPackageVerification if the archive does not verify.
+func verifyPackageArchive(v *config.PackageVerification, archivePath string) error {
+	//nolint:gosec
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer utils.UncheckedErrorFunc(archive.Close)
+
+	// minisign signs the BLAKE2b-512 hash of the archive and cosign signs its sha256 hash, so both are computed in
+	// a single pass over the archive.
+	sha256Hash := sha256.New()
+	blake2bHash, err := blake2b.New512(nil)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(io.MultiWriter(sha256Hash, blake2bHash), archive); err != nil {
+		return err
+	}
+	sha256Sum := sha256Hash.Sum(nil)
+
+	if v.Checksum != "" {
+		if checksum := "sha256:" + hex.EncodeToString(sha256Sum); checksum != v.Checksum {
+			return fmt.Errorf("%w: archive has checksum %s, expected %s", ErrPackageVerification, checksum, v.Checksum)
+		}
+	}
+	if v.PublicKey == "" {
+		return nil
+	}
+	if v.IsMinisign() {
+		err = verifyMinisign(v.PublicKey, v.Signature, blake2bHash.Sum(nil))
+	} else {
+		err = verifyCosign(v.PublicKey, v.Signature, sha256Sum)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrPackageVerification, err)
+	}
+	return nil
+}
+
+// verificationFingerprint identifies the verification a package was installed with, so that a package is verified,
+// and so installed, again when its verification changes. It is empty for packages without verification.
+func verificationFingerprint(p config.PackageConfig) string {
+	if p.Verification == nil {
+		return ""
+	}
+	return rutils.HashString(p.Verification.Checksum+"\n"+p.Verification.PublicKey+"\n"+p.Verification.Signature, 0)
+}
+
+// installFailureMessage returns the message recorded in the status of a package that failed to install.
+// Verification failures are reported as is, so they are not mistaken for download failures.
+func installFailureMessage(prefix string, err error) string {
+	if errors.Is(err, ErrPackageVerification) {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s: %v", prefix, err.Error())
+}
+
+// verifyMinisign verifies a prehashed minisign signature, and its trusted comment, of a message with the given
+// BLAKE2b-512 hash. See https://jedisct1.github.io/minisign/ for the formats.
+func verifyMinisign(publicKey, signature string, hash []byte) error {
+	keyBytes, err := base64.StdEncoding.DecodeString(lastLine(publicKey))
+	if err != nil || len(keyBytes) != 2+minisignKeyIDSize+ed25519.PublicKeySize ||
+		string(keyBytes[:2]) != minisignKeyAlgorithm {
+		return errors.New("invalid minisign public key")
+	}
+	keyID, key := keyBytes[2:2+minisignKeyIDSize], ed25519.PublicKey(keyBytes[2+minisignKeyIDSize:])
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(signature))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, minisignUntrustedPrefix) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], minisignTrustedPrefix) {
+		return errors.New("invalid minisign signature, expected a signature, a trusted comment, and a global signature")
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(lines[0])
+	if err != nil || len(sigBytes) != 2+minisignKeyIDSize+ed25519.SignatureSize {
+		return errors.New("invalid minisign signature")
+	}
+	if algorithm := string(sigBytes[:2]); algorithm != minisignPrehashedAlgorithm {
+		return fmt.Errorf("unsupported minisign signature algorithm %q, sign with a prehashed signature", algorithm)
+	}
+	if !bytes.Equal(sigBytes[2:2+minisignKeyIDSize], keyID) {
+		return fmt.Errorf("signature was made by key %X, expected key %X", sigBytes[2:2+minisignKeyIDSize], keyID)
+	}
+	sig := sigBytes[2+minisignKeyIDSize:]
+	if !ed25519.Verify(key, hash, sig) {
+		return errors.New("invalid signature")
+	}
+
+	globalSig, err := base64.StdEncoding.DecodeString(lines[2])
+	if err != nil {
+		return errors.New("invalid minisign global signature")
+	}
+	trustedComment := strings.TrimPrefix(lines[1], minisignTrustedPrefix)
+	if !ed25519.Verify(key, append(append([]byte{}, sig...), trustedComment...), globalSig) {
+		return fmt.Errorf("invalid signature of trusted comment %q", trustedComment)
+	}
+	return nil
+}
+
+// verifyCosign verifies a signature made with "cosign sign-blob" and an ECDSA key of a message with the given
+// sha256 hash.
+func verifyCosign(publicKey, signature string, hash []byte) error {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return errors.New("invalid cosign public key, expected PEM")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("invalid cosign public key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported cosign public key type %T, expected ECDSA", parsed)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return fmt.Errorf("invalid cosign signature: %w", err)
+	}
+	if !ecdsa.VerifyASN1(key, hash, sig) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// lastLine returns the last non-empty line of s, so that the contents of a minisign .pub file, which start with
+// an untrusted comment, can be used as a key.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
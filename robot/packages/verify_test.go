@@ -0,0 +1,92 @@
+package packages
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+	"golang.org/x/crypto/blake2b"
+
+	"go.viam.com/rdk/config"
+)
+
+// minisignSign returns a minisign public key and the .minisig contents of a prehashed signature of message.
+func minisignSign(t *testing.T, message []byte, trustedComment string) (string, string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	test.That(t, err, test.ShouldBeNil)
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	hash := blake2b.Sum512(message)
+	sig := ed25519.Sign(priv, hash[:])
+	globalSig := ed25519.Sign(priv, append(append([]byte{}, sig...), trustedComment...))
+
+	publicKey := "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...))
+	signature := "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("ED"), keyID...), sig...)) + "\n" +
+		"trusted comment: " + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(globalSig) + "\n"
+	return publicKey, signature
+}
+
+func TestVerifyPackageArchive(t *testing.T) {
+	archive := []byte("package contents")
+	archivePath := filepath.Join(t.TempDir(), "package.tar.gz")
+	test.That(t, os.WriteFile(archivePath, archive, 0o600), test.ShouldBeNil)
+	sum := sha256.Sum256(archive)
+	checksum := "sha256:" + hex.EncodeToString(sum[:])
+
+	t.Run("checksum", func(t *testing.T) {
+		test.That(t, verifyPackageArchive(&config.PackageVerification{Checksum: checksum}, archivePath), test.ShouldBeNil)
+
+		other := sha256.Sum256([]byte("other"))
+		err := verifyPackageArchive(&config.PackageVerification{Checksum: "sha256:" + hex.EncodeToString(other[:])}, archivePath)
+		test.That(t, errors.Is(err, ErrPackageVerification), test.ShouldBeTrue)
+	})
+
+	t.Run("minisign", func(t *testing.T) {
+		publicKey, signature := minisignSign(t, archive, "timestamp:1700000000\tfile:package.tar.gz")
+		v := &config.PackageVerification{PublicKey: publicKey, Signature: signature}
+		test.That(t, v.IsMinisign(), test.ShouldBeTrue)
+		test.That(t, verifyPackageArchive(v, archivePath), test.ShouldBeNil)
+
+		otherKey, otherSignature := minisignSign(t, []byte("other"), "trusted")
+		err := verifyPackageArchive(&config.PackageVerification{PublicKey: publicKey, Signature: otherSignature}, archivePath)
+		test.That(t, errors.Is(err, ErrPackageVerification), test.ShouldBeTrue)
+		err = verifyPackageArchive(&config.PackageVerification{PublicKey: otherKey, Signature: signature}, archivePath)
+		test.That(t, errors.Is(err, ErrPackageVerification), test.ShouldBeTrue)
+	})
+
+	t.Run("cosign", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		test.That(t, err, test.ShouldBeNil)
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		test.That(t, err, test.ShouldBeNil)
+		publicKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+		test.That(t, err, test.ShouldBeNil)
+
+		v := &config.PackageVerification{Checksum: checksum, PublicKey: publicKey, Signature: base64.StdEncoding.EncodeToString(sig)}
+		test.That(t, v.IsMinisign(), test.ShouldBeFalse)
+		test.That(t, verifyPackageArchive(v, archivePath), test.ShouldBeNil)
+
+		otherSum := sha256.Sum256([]byte("other"))
+		otherSig, err := ecdsa.SignASN1(rand.Reader, key, otherSum[:])
+		test.That(t, err, test.ShouldBeNil)
+		v.Signature = base64.StdEncoding.EncodeToString(otherSig)
+		err = verifyPackageArchive(v, archivePath)
+		test.That(t, errors.Is(err, ErrPackageVerification), test.ShouldBeTrue)
+	})
+}
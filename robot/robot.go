@@ -16,6 +16,7 @@ import (
 
 	"go.viam.com/rdk/cloud"
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/ftdc"
 	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
 	modulestatus "go.viam.com/rdk/module/status"
@@ -213,6 +214,45 @@ type LocalRobot interface {
 		UploadDataFromPathResult, error)
 }
 
+// A ResourceStateSubscriber delivers lifecycle changes of its resources as they happen. A LocalRobot
+// may implement it to serve StreamStatus.
+type ResourceStateSubscriber interface {
+	// SubscribeResourceStateEvents returns a channel of lifecycle state changes of the robot's
+	// resources and a function that ends the subscription. Events are dropped rather than delivered
+	// late if the subscriber does not keep up; once it does, it is sent an event with Resync set and
+	// should take a fresh snapshot of every resource's state, such as from MachineStatus.
+	SubscribeResourceStateEvents() (<-chan ResourceStateEvent, func())
+}
+
+// A ConfigRevisionReporter reports how far its recently received config revisions got toward being
+// applied. A LocalRobot may implement it to report them to the agent managing it.
+type ConfigRevisionReporter interface {
+	ConfigRevisionStatuses() []ConfigRevisionStatus
+}
+
+// A RecentLogsReporter reports the most recent lines logged by the machine, from oldest to newest.
+// A LocalRobot may implement it to include them in support bundles.
+type RecentLogsReporter interface {
+	RecentLogs() []string
+}
+
+// A MetricsExporter writes metrics about itself in the Prometheus text format. A LocalRobot may
+// implement it to add to the metrics served by its web service.
+type MetricsExporter interface {
+	WritePrometheusMetrics(pw *ftdc.PrometheusWriter)
+}
+
+// A ResourceGraphExporter exports its current resource graph for debugging. A LocalRobot may
+// implement it in addition to ExportResourcesAsDot, which only exports snapshots of past graphs.
+type ResourceGraphExporter interface {
+	// ExportResourceGraph exports the current resource graph, including remote and modular
+	// resources, with the lifecycle state and last error of every resource.
+	ExportResourceGraph() resource.GraphExport
+
+	// ExportResourceGraphDot exports the current resource graph as a DOT representation.
+	ExportResourceGraphDot() (string, error)
+}
+
 // A RemoteRobot is a Robot that was created through a connection.
 type RemoteRobot interface {
 	Robot
@@ -369,8 +409,120 @@ type MachineStatus struct {
 	State       MachineState
 	JobStatuses map[string]JobStatus
 	Packages    []packages.PackageStatus
+	// RequestStats holds the rolling stats of the API calls recently made to each resource, by
+	// the name the calls targeted it with.
+	RequestStats map[string]RequestStats
+	// Alerts holds the active alerts of the machine.
+	Alerts []Alert
+	// ConfigRevisions holds how far each recently received config revision got toward being
+	// applied, from oldest to newest.
+	ConfigRevisions []ConfigRevisionStatus
+}
+
+// ConfigRevisionState is how far a config revision got toward being applied.
+type ConfigRevisionState string
+
+// The set of known config revision states.
+const (
+	// ConfigRevisionAwaitingAck is a revision held back until it is acknowledged.
+	ConfigRevisionAwaitingAck = ConfigRevisionState("awaiting_ack")
+	// ConfigRevisionSuperseded is a revision that was held back and then replaced by a newer one.
+	ConfigRevisionSuperseded = ConfigRevisionState("superseded")
+	// ConfigRevisionDeferred is a revision not applied yet, such as until a maintenance window opens.
+	ConfigRevisionDeferred = ConfigRevisionState("deferred")
+	// ConfigRevisionApplied is a revision applied without errors.
+	ConfigRevisionApplied = ConfigRevisionState("applied")
+	// ConfigRevisionPartiallyApplied is a revision applied with errors, such as resources that
+	// failed to build.
+	ConfigRevisionPartiallyApplied = ConfigRevisionState("partially_applied")
+	// ConfigRevisionRolledBack is a revision rolled back to a known-good config.
+	ConfigRevisionRolledBack = ConfigRevisionState("rolled_back")
+)
+
+// ConfigRevisionStatus describes how far a config revision got toward being applied.
+type ConfigRevisionStatus struct {
+	Revision string              `json:"revision"`
+	State    ConfigRevisionState `json:"state"`
+	// Errors holds what went wrong applying a partially applied revision, or why a revision was
+	// rolled back.
+	Errors  []string  `json:"errors,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// AlertSeverity is how severe an alert is.
+type AlertSeverity string
+
+// The set of known alert severities, from least to most severe.
+const (
+	AlertInfo     = AlertSeverity("info")
+	AlertWarning  = AlertSeverity("warning")
+	AlertError    = AlertSeverity("error")
+	AlertCritical = AlertSeverity("critical")
+)
+
+// An Alert is a named problem raised by a resource or module, such as "imu_saturated" or
+// "low_disk", which stays active until it is cleared.
+type Alert struct {
+	// Source is what raised the alert. Resources use the string form of their resource name.
+	Source   string                 `json:"source"`
+	Name     string                 `json:"name"`
+	Severity AlertSeverity          `json:"severity"`
+	Message  string                 `json:"message,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// Raised is when the alert became active, and Updated is when it was last raised.
+	Raised  time.Time `json:"raised"`
+	Updated time.Time `json:"updated"`
+	// Count is how many times the alert has been raised since it became active.
+	Count int `json:"count"`
 }
 
+// RequestStats summarizes the unary API calls made to a resource over a recent window of time.
+type RequestStats struct {
+	// Window is how far back the stats look.
+	Window time.Duration `json:"window"`
+	// Requests and Errors are how many calls were handled, and how many of them failed, within
+	// the window.
+	Requests  int64   `json:"requests"`
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+	// P50Latency and P95Latency are percentiles of how long the calls took to handle.
+	P50Latency time.Duration `json:"p50_latency"`
+	P95Latency time.Duration `json:"p95_latency"`
+}
+
+// ResourceStateEvent describes a resource entering a new lifecycle state or being removed.
+type ResourceStateEvent struct {
+	Name     resource.Name
+	State    resource.NodeState
+	Removed  bool
+	Revision string
+	// Error is the last error of the resource if it is unhealthy.
+	Error error
+	Time  time.Time
+	// Snapshot is set on events that report the current state of a resource rather than a change
+	// to it. Every resource without one in a snapshot no longer exists.
+	Snapshot bool
+	// Resync is set, instead of any other field but Time, when events were dropped because the
+	// subscriber fell behind.
+	Resync bool
+}
+
+// Keys of the struct in each status sent by StreamStatus, which holds a ResourceStateEvent.
+const (
+	// ResourceStateKeyState is the resource's lifecycle state as the name of a ResourceStatus state.
+	ResourceStateKeyState = "state"
+	// ResourceStateKeyRevision is the revision of the config the resource was built from.
+	ResourceStateKeyRevision = "revision"
+	// ResourceStateKeyError is the resource's last error, which is only present if it is unhealthy.
+	ResourceStateKeyError = "error"
+	// ResourceStateKeyRemoved is true once the resource has left the resource graph.
+	ResourceStateKeyRemoved = "removed"
+	// ResourceStateKeyTime is when the resource entered its state, in RFC 3339 format.
+	ResourceStateKeyTime = "time"
+	// ResourceStateKeySnapshot is true on statuses that are part of a snapshot.
+	ResourceStateKeySnapshot = "snapshot"
+)
+
 // JobStatus encapsulates status information about a single JobManager job.
 type JobStatus struct {
 	RecentSuccessfulRuns []time.Time
@@ -0,0 +1,212 @@
+// Package rollout implements staged rollouts of cloud configs. Under the StagedRolloutConfig of a
+// machine's cloud config, revisions tagged as staged, or every revision other than a pinned one, are
+// held back until they are acknowledged on the machine, which keeps running its current config in the
+// meantime. A Tracker also records how far each recently received revision got toward being applied,
+// which the machine reports in its machine status and to the agent managing it.
+//
+// The staged rollout of a robot is a resource named PublicServiceName on every local robot, and
+// revisions are acknowledged and statuses queried through its DoCommand with the keys below, which
+// works against both local robots and robot clients. Acknowledge and GetStatus wrap that contract.
+package rollout
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+)
+
+// PublicServiceName is the generic service through which the staged rollout of a robot is controlled.
+var PublicServiceName = resource.NewName(generic.API, "$rollout")
+
+// export keys to be used with DoCommand on PublicServiceName so they can be referenced by clients.
+//
+//   - DoAcknowledge applies the revision awaiting acknowledgment
+//     required key: DoAcknowledge, whose value is the revision
+//   - DoStatus returns the staged rollout state of the robot
+//     required key: DoStatus
+//
+// Every command responds with the staged rollout state of the robot as a Status.
+const (
+	DoAcknowledge = "acknowledge"
+	DoStatus      = "status"
+)
+
+// MaxRevisions is how many revisions a Tracker keeps the status of.
+const MaxRevisions = 10
+
+// Status describes the staged rollout state of a robot.
+type Status struct {
+	// AwaitingAck is the revision held back until it is acknowledged, if any.
+	AwaitingAck string `json:"awaiting_ack,omitempty"`
+	// Revisions holds the statuses of the recently received revisions, from oldest to newest.
+	Revisions []robot.ConfigRevisionStatus `json:"revisions,omitempty"`
+}
+
+// A Tracker holds back the revisions that wait for acknowledgment and records the status of each
+// recently received revision.
+type Tracker struct {
+	mu       sync.Mutex
+	statuses []robot.ConfigRevisionStatus
+	// approved holds the revisions that were acknowledged or applied, which are not held back again.
+	approved map[string]bool
+	held     *config.Config
+}
+
+// NewTracker returns a Tracker that holds back no revision.
+func NewTracker() *Tracker {
+	return &Tracker{approved: map[string]bool{}}
+}
+
+// Admit returns whether cfg may be applied under the StagedRolloutConfig of its cloud config. A
+// revision that waits for acknowledgment is held back instead, replacing any revision held back
+// before it.
+func (t *Tracker) Admit(cfg *config.Config) bool {
+	var policy *config.StagedRolloutConfig
+	if cfg.Cloud != nil {
+		policy = cfg.Cloud.StagedRollout
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.held != nil && t.held.Revision != cfg.Revision {
+		t.setLocked(t.held.Revision, robot.ConfigRevisionSuperseded, nil)
+		t.held = nil
+	}
+	if !policy.RequiresAck(cfg.Revision) || t.approved[cfg.Revision] {
+		return true
+	}
+	t.held = cfg
+	t.setLocked(cfg.Revision, robot.ConfigRevisionAwaitingAck, nil)
+	return false
+}
+
+// Acknowledge approves the revision held back and returns its config to apply. It fails if the
+// given revision is not the one held back.
+func (t *Tracker) Acknowledge(revision string) (*config.Config, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.held == nil || t.held.Revision != revision {
+		return nil, errors.Errorf("config revision %q is not awaiting acknowledgment", revision)
+	}
+	cfg := t.held
+	t.held = nil
+	t.approved[revision] = true
+	return cfg, nil
+}
+
+// Approve records that the given revision is applied without waiting for acknowledgment, such as a
+// known-good config being rolled back to.
+func (t *Tracker) Approve(revision string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.approved[revision] = true
+}
+
+// Set records the state of the given revision. Applied revisions are never held back again, so
+// that the machine can always roll back to them.
+func (t *Tracker) Set(revision string, state robot.ConfigRevisionState, errs []string) {
+	if revision == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setLocked(revision, state, errs)
+}
+
+// setLocked must be called with mu held.
+func (t *Tracker) setLocked(revision string, state robot.ConfigRevisionState, errs []string) {
+	if state == robot.ConfigRevisionApplied || state == robot.ConfigRevisionPartiallyApplied {
+		t.approved[revision] = true
+	}
+	status := robot.ConfigRevisionStatus{Revision: revision, State: state, Errors: errs, Updated: time.Now()}
+	t.statuses = slices.DeleteFunc(t.statuses, func(st robot.ConfigRevisionStatus) bool {
+		return st.Revision == revision
+	})
+	t.statuses = append(t.statuses, status)
+	if len(t.statuses) > MaxRevisions {
+		t.statuses = slices.Delete(t.statuses, 0, len(t.statuses)-MaxRevisions)
+	}
+}
+
+// Statuses returns the statuses of the recently received revisions, from oldest to newest.
+func (t *Tracker) Statuses() []robot.ConfigRevisionStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.statuses)
+}
+
+// Status returns the staged rollout state.
+func (t *Tracker) Status() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := Status{Revisions: slices.Clone(t.statuses)}
+	if t.held != nil {
+		st.AwaitingAck = t.held.Revision
+	}
+	return st
+}
+
+// Acknowledge applies the revision of the given robot that is awaiting acknowledgment.
+func Acknowledge(ctx context.Context, r robot.Robot, revision string) (Status, error) {
+	return doCommand(ctx, r, map[string]interface{}{DoAcknowledge: revision})
+}
+
+// GetStatus returns the staged rollout state of the given robot.
+func GetStatus(ctx context.Context, r robot.Robot) (Status, error) {
+	return doCommand(ctx, r, map[string]interface{}{DoStatus: true})
+}
+
+func doCommand(ctx context.Context, r robot.Robot, cmd map[string]interface{}) (Status, error) {
+	res, err := r.ResourceByName(PublicServiceName)
+	if err != nil {
+		return Status{}, err
+	}
+	resp, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return Status{}, err
+	}
+	return resource.DecodeDoCommand[Status](resp)
+}
+
+// A Controller acknowledges the revisions held back by a robot.
+type Controller interface {
+	// AcknowledgeRevision applies the given revision, which must be awaiting acknowledgment.
+	AcknowledgeRevision(ctx context.Context, revision string) error
+	RolloutStatus() Status
+}
+
+type service struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	controller Controller
+}
+
+// NewService returns the resource through which the staged rollout of the robot behind controller is
+// controlled, which the robot serves as PublicServiceName.
+func NewService(controller Controller) resource.Resource {
+	return &service{Named: PublicServiceName.AsNamed(), controller: controller}
+}
+
+func (svc *service) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	_, status := cmd[DoStatus]
+	if v, ok := cmd[DoAcknowledge]; ok {
+		revision, ok := v.(string)
+		if !ok || revision == "" {
+			return nil, errors.Errorf("expected %s to be a revision but got %v", DoAcknowledge, v)
+		}
+		if err := svc.controller.AcknowledgeRevision(ctx, revision); err != nil {
+			return nil, err
+		}
+	} else if !status {
+		return nil, resource.ErrDoUnimplemented
+	}
+	return resource.EncodeDoCommand(svc.controller.RolloutStatus())
+}
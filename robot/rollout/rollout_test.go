@@ -0,0 +1,125 @@
+package rollout_test
+
+import (
+	"context"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/rollout"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func cloudConfig(revision string, policy *config.StagedRolloutConfig) *config.Config {
+	return &config.Config{Revision: revision, Cloud: &config.Cloud{ID: "machine", StagedRollout: policy}}
+}
+
+func states(statuses []robot.ConfigRevisionStatus) map[string]robot.ConfigRevisionState {
+	out := map[string]robot.ConfigRevisionState{}
+	for _, st := range statuses {
+		out[st.Revision] = st.State
+	}
+	return out
+}
+
+func TestTracker(t *testing.T) {
+	policy := &config.StagedRolloutConfig{RequireAck: true}
+	tr := rollout.NewTracker()
+	test.That(t, tr.Admit(&config.Config{Revision: "local"}), test.ShouldBeTrue)
+	test.That(t, tr.Admit(cloudConfig("rev1", nil)), test.ShouldBeTrue)
+	test.That(t, tr.Admit(cloudConfig("rev1", policy)), test.ShouldBeTrue)
+	tr.Set("rev1", robot.ConfigRevisionApplied, nil)
+
+	test.That(t, tr.Admit(cloudConfig("rev2+staged", policy)), test.ShouldBeFalse)
+	test.That(t, tr.Status().AwaitingAck, test.ShouldEqual, "rev2+staged")
+	_, err := tr.Acknowledge("rev1")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// a newer revision supersedes the one held back.
+	test.That(t, tr.Admit(cloudConfig("rev3+staged", policy)), test.ShouldBeFalse)
+	test.That(t, states(tr.Statuses()), test.ShouldResemble, map[string]robot.ConfigRevisionState{
+		"rev1":        robot.ConfigRevisionApplied,
+		"rev2+staged": robot.ConfigRevisionSuperseded,
+		"rev3+staged": robot.ConfigRevisionAwaitingAck,
+	})
+	cfg, err := tr.Acknowledge("rev3+staged")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cfg.Revision, test.ShouldEqual, "rev3+staged")
+	test.That(t, tr.Status().AwaitingAck, test.ShouldBeEmpty)
+	test.That(t, tr.Admit(cfg), test.ShouldBeTrue)
+	tr.Set("rev3+staged", robot.ConfigRevisionPartiallyApplied, []string{"arm1: failed"})
+	statuses := tr.Statuses()
+	test.That(t, statuses[len(statuses)-1].Errors, test.ShouldResemble, []string{"arm1: failed"})
+
+	// a pinned machine holds back every other revision, but not revisions it already applied.
+	pinned := &config.StagedRolloutConfig{PinnedRevision: "rev3+staged"}
+	test.That(t, tr.Admit(cloudConfig("rev3+staged", pinned)), test.ShouldBeTrue)
+	test.That(t, tr.Admit(cloudConfig("rev1", pinned)), test.ShouldBeTrue)
+	test.That(t, tr.Admit(cloudConfig("rev4", pinned)), test.ShouldBeFalse)
+	tr.Approve("rev4")
+	test.That(t, tr.Admit(cloudConfig("rev4", pinned)), test.ShouldBeTrue)
+
+	for i := 0; i < rollout.MaxRevisions+5; i++ {
+		tr.Set(string(rune('a'+i)), robot.ConfigRevisionApplied, nil)
+	}
+	test.That(t, tr.Statuses(), test.ShouldHaveLength, rollout.MaxRevisions)
+}
+
+type fakeController struct {
+	tracker  *rollout.Tracker
+	applying []string
+}
+
+func (fc *fakeController) AcknowledgeRevision(ctx context.Context, revision string) error {
+	cfg, err := fc.tracker.Acknowledge(revision)
+	if err != nil {
+		return err
+	}
+	fc.applying = append(fc.applying, cfg.Revision)
+	fc.tracker.Set(cfg.Revision, robot.ConfigRevisionApplied, nil)
+	return nil
+}
+
+func (fc *fakeController) RolloutStatus() rollout.Status {
+	return fc.tracker.Status()
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	fc := &fakeController{tracker: rollout.NewTracker()}
+	svc := rollout.NewService(fc)
+	test.That(t, svc.Name(), test.ShouldResemble, rollout.PublicServiceName)
+
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		test.That(t, name, test.ShouldResemble, rollout.PublicServiceName)
+		return svc, nil
+	}
+
+	st, err := rollout.GetStatus(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st, test.ShouldResemble, rollout.Status{})
+
+	fc.tracker.Admit(cloudConfig("rev1+staged", &config.StagedRolloutConfig{RequireAck: true}))
+	st, err = rollout.GetStatus(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.AwaitingAck, test.ShouldEqual, "rev1+staged")
+	test.That(t, st.Revisions, test.ShouldHaveLength, 1)
+	test.That(t, st.Revisions[0].State, test.ShouldEqual, robot.ConfigRevisionAwaitingAck)
+
+	_, err = rollout.Acknowledge(ctx, r, "rev2")
+	test.That(t, err, test.ShouldNotBeNil)
+	st, err = rollout.Acknowledge(ctx, r, "rev1+staged")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fc.applying, test.ShouldResemble, []string{"rev1+staged"})
+	test.That(t, st.AwaitingAck, test.ShouldBeEmpty)
+	test.That(t, st.Revisions[0].State, test.ShouldEqual, robot.ConfigRevisionApplied)
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{rollout.DoAcknowledge: 1.})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+}
@@ -6,9 +6,11 @@ package server
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -21,6 +23,10 @@ import (
 	"go.viam.com/utils"
 	vprotoutils "go.viam.com/utils/protoutils"
 	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -37,6 +43,7 @@ import (
 	"go.viam.com/rdk/robot/packages"
 	"go.viam.com/rdk/session"
 	"go.viam.com/rdk/tunnel"
+	"go.viam.com/rdk/utils/contextutils"
 )
 
 // logTSKey is the key used in conjunction with the timestamp of logs received
@@ -99,6 +106,15 @@ func (s *Server) Tunnel(srv pb.RobotService_TunnelServer) error {
 
 	dialTimeout := defaultTunnelConnectionTimeout
 
+	// the protocol of the destination port is TCP unless the client says otherwise.
+	network := config.TunnelProtocolTCP
+	if md, ok := metadata.FromIncomingContext(srv.Context()); ok {
+		if protocols := md.Get(tunnel.ProtocolMetadataKey); len(protocols) > 0 {
+			network = protocols[0]
+		}
+	}
+	entity, _ := rpc.ContextAuthEntity(srv.Context())
+
 	// Ensure destination port is available; otherwise error.
 	var destAllowed bool
 	ttes, err := s.robot.ListTunnels(srv.Context())
@@ -106,7 +122,7 @@ func (s *Server) Tunnel(srv pb.RobotService_TunnelServer) error {
 		return err
 	}
 	for _, tte := range ttes {
-		if int(req.DestinationPort) == tte.Port {
+		if int(req.DestinationPort) == tte.Port && tte.Network() == network && tte.Allows(entity.Entity) {
 			destAllowed = true
 			if tte.ConnectionTimeout != 0 {
 				// Honor specified timeout if one exists (0 is use-default.)
@@ -116,13 +132,13 @@ func (s *Server) Tunnel(srv pb.RobotService_TunnelServer) error {
 		}
 	}
 	if !destAllowed {
-		return fmt.Errorf("tunnel not available at port %d", req.DestinationPort)
+		return fmt.Errorf("%s tunnel not available at port %d", network, req.DestinationPort)
 	}
 
 	dest := strconv.Itoa(int(req.DestinationPort))
 
-	s.robot.Logger().CInfow(srv.Context(), "dialing to destination port", "port", dest, "timeout", dialTimeout)
-	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", dest), dialTimeout)
+	s.robot.Logger().CInfow(srv.Context(), "dialing to destination port", "port", dest, "protocol", network, "timeout", dialTimeout)
+	conn, err := net.DialTimeout(network, net.JoinHostPort("127.0.0.1", dest), dialTimeout)
 	if err != nil {
 		return fmt.Errorf("failed to dial to destination port %v: %w", dest, err)
 	}
@@ -556,20 +572,11 @@ func (s *Server) GetMachineStatus(ctx context.Context, _ *pb.GetMachineStatusReq
 			CloudMetadata: protoutils.MetadataToProto(resStatus.CloudMetadata),
 		}
 
-		switch resStatus.State {
+		pbResStatus.State = nodeStateToProto(resStatus.State)
+		switch resStatus.State { //nolint:exhaustive
 		case resource.NodeStateUnknown:
 			s.robot.Logger().CErrorw(ctx, "resource in an unknown state", "resource", resStatus.Name.String())
-			pbResStatus.State = pb.ResourceStatus_STATE_UNSPECIFIED
-		case resource.NodeStateUnconfigured:
-			pbResStatus.State = pb.ResourceStatus_STATE_UNCONFIGURED
-		case resource.NodeStateConfiguring:
-			pbResStatus.State = pb.ResourceStatus_STATE_CONFIGURING
-		case resource.NodeStateReady:
-			pbResStatus.State = pb.ResourceStatus_STATE_READY
-		case resource.NodeStateRemoving:
-			pbResStatus.State = pb.ResourceStatus_STATE_REMOVING
 		case resource.NodeStateUnhealthy:
-			pbResStatus.State = pb.ResourceStatus_STATE_UNHEALTHY
 			if resStatus.Error != nil {
 				pbResStatus.Error = resStatus.Error.Error()
 			}
@@ -633,9 +640,182 @@ func (s *Server) GetMachineStatus(ctx context.Context, _ *pb.GetMachineStatusReq
 		}
 	}
 
+	// the response has no fields for the request stats and alerts, so they are sent in its header.
+	// This is best effort, as setting the header fails once the call is canceled.
+	if len(mStatus.RequestStats) > 0 {
+		if stats, err := json.Marshal(mStatus.RequestStats); err == nil {
+			utils.UncheckedError(grpc.SetHeader(ctx, metadata.Pairs(contextutils.RequestStatsMetadataKey, string(stats))))
+		}
+	}
+	if len(mStatus.Alerts) > 0 {
+		if alerts, err := json.Marshal(mStatus.Alerts); err == nil {
+			utils.UncheckedError(grpc.SetHeader(ctx, metadata.Pairs(contextutils.AlertsMetadataKey, string(alerts))))
+		}
+	}
+	if len(mStatus.ConfigRevisions) > 0 {
+		if revisions, err := json.Marshal(mStatus.ConfigRevisions); err == nil {
+			utils.UncheckedError(grpc.SetHeader(ctx, metadata.Pairs(contextutils.ConfigRevisionsMetadataKey, string(revisions))))
+		}
+	}
+
 	return &result, nil
 }
 
+func nodeStateToProto(state resource.NodeState) pb.ResourceStatus_State {
+	switch state {
+	case resource.NodeStateUnconfigured:
+		return pb.ResourceStatus_STATE_UNCONFIGURED
+	case resource.NodeStateConfiguring:
+		return pb.ResourceStatus_STATE_CONFIGURING
+	case resource.NodeStateReady:
+		return pb.ResourceStatus_STATE_READY
+	case resource.NodeStateRemoving:
+		return pb.ResourceStatus_STATE_REMOVING
+	case resource.NodeStateUnhealthy:
+		return pb.ResourceStatus_STATE_UNHEALTHY
+	case resource.NodeStateUnknown:
+	}
+	return pb.ResourceStatus_STATE_UNSPECIFIED
+}
+
+// StreamStatus streams lifecycle changes of the robot's resources, limited to the requested
+// resources if any are given. The first response is a snapshot of the state of every resource, and
+// each later one holds changes as they happen or, if `every` is set, the latest change to each
+// resource since the previous response. If the stream falls behind and changes are lost, another
+// snapshot is sent. The struct of each status is described by the robot.ResourceStateKey* keys.
+func (s *Server) StreamStatus(req *pb.StreamStatusRequest, stream pb.RobotService_StreamStatusServer) error {
+	subscriber, ok := s.robot.(robot.ResourceStateSubscriber)
+	if !ok {
+		return status.Error(codes.Unimplemented, "robot does not stream resource states")
+	}
+	events, unsubscribe := subscriber.SubscribeResourceStateEvents()
+	defer unsubscribe()
+
+	var requested map[resource.Name]struct{}
+	if len(req.GetResourceNames()) > 0 {
+		requested = make(map[resource.Name]struct{}, len(req.GetResourceNames()))
+		for _, name := range req.GetResourceNames() {
+			requested[protoutils.ResourceNameFromProto(name)] = struct{}{}
+		}
+	}
+	wanted := func(name resource.Name) bool {
+		if requested == nil {
+			return true
+		}
+		_, ok := requested[name]
+		return ok
+	}
+
+	send := func(events []robot.ResourceStateEvent) error {
+		statuses := make([]*pb.Status, 0, len(events))
+		for _, event := range events {
+			st, err := resourceStateEventToProto(event)
+			if err != nil {
+				return err
+			}
+			statuses = append(statuses, st)
+		}
+		return stream.Send(&pb.StreamStatusResponse{Status: statuses})
+	}
+	// the snapshot is taken after subscribing so that no change is missed between the two.
+	sendSnapshot := func() error {
+		ms, err := s.robot.MachineStatus(stream.Context())
+		if err != nil {
+			return err
+		}
+		var snapshot []robot.ResourceStateEvent
+		for _, res := range ms.Resources {
+			if !wanted(res.Name) {
+				continue
+			}
+			snapshot = append(snapshot, robot.ResourceStateEvent{
+				Name:     res.Name,
+				State:    res.State,
+				Revision: res.Revision,
+				Error:    res.Error,
+				Time:     res.LastUpdated,
+				Snapshot: true,
+			})
+		}
+		return send(snapshot)
+	}
+	if err := sendSnapshot(); err != nil {
+		return err
+	}
+
+	var flush <-chan time.Time
+	if every := req.GetEvery().AsDuration(); every > 0 {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+	var pending []robot.ResourceStateEvent
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-flush:
+			if len(pending) == 0 {
+				continue
+			}
+			if err := send(pending); err != nil {
+				return err
+			}
+			pending = nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if event.Resync {
+				pending = nil
+				if err := sendSnapshot(); err != nil {
+					return err
+				}
+				continue
+			}
+			if !wanted(event.Name) {
+				continue
+			}
+			if flush == nil {
+				if err := send([]robot.ResourceStateEvent{event}); err != nil {
+					return err
+				}
+				continue
+			}
+			// only the latest change to each resource is sent.
+			idx := slices.IndexFunc(pending, func(p robot.ResourceStateEvent) bool { return p.Name == event.Name })
+			if idx < 0 {
+				pending = append(pending, event)
+			} else {
+				pending[idx] = event
+			}
+		}
+	}
+}
+
+func resourceStateEventToProto(event robot.ResourceStateEvent) (*pb.Status, error) {
+	fields := map[string]interface{}{
+		robot.ResourceStateKeyState:    nodeStateToProto(event.State).String(),
+		robot.ResourceStateKeyRevision: event.Revision,
+		robot.ResourceStateKeyRemoved:  event.Removed,
+		robot.ResourceStateKeyTime:     event.Time.UTC().Format(time.RFC3339Nano),
+	}
+	if event.Error != nil {
+		fields[robot.ResourceStateKeyError] = event.Error.Error()
+	}
+	if event.Snapshot {
+		fields[robot.ResourceStateKeySnapshot] = true
+	}
+	st, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.Status{
+		Name:   protoutils.ResourceNameToProto(event.Name),
+		Status: st,
+	}, nil
+}
+
 func packageStateToProto(s packages.PackageState) pb.PackageStatus_State {
 	switch s {
 	case packages.PackageStateDownloading:
@@ -657,6 +837,10 @@ func packageStateToProto(s packages.PackageState) pb.PackageStatus_State {
 
 // GetVersion returns version information about the robot.
 func (s *Server) GetVersion(ctx context.Context, _ *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
+	// clients estimate the offset between their clock and ours from the time we handled the request. This
+	// is best effort, as setting the header fails outside of a gRPC call or once the call is canceled.
+	serverTime := time.Now().Format(time.RFC3339Nano)
+	utils.UncheckedError(grpc.SetHeader(ctx, metadata.Pairs(contextutils.ServerTimeMetadataKey, serverTime)))
 	result := robot.Version
 	return &pb.GetVersionResponse{
 		Platform:   result.Platform,
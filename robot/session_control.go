@@ -0,0 +1,121 @@
+package robot
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/resource"
+)
+
+// AcquireControl gives the session with the given id exclusive control of the named resources, so
+// that safety monitored methods, which are the ones that move actuators, are rejected for those
+// resources unless they are called within that session. If another active session is in control of
+// any of them, nothing is acquired unless takeover is set, in which case control is taken from it
+// and the resources it was controlling are stopped.
+func (m *SessionManager) AcquireControl(
+	ctx context.Context,
+	id uuid.UUID,
+	ownerID string,
+	names []resource.Name,
+	takeover bool,
+) error {
+	if _, err := m.FindByID(ctx, id, ownerID); err != nil {
+		return err
+	}
+
+	var takenOver []resource.Name
+	if err := func() error {
+		m.sessionResourceMu.Lock()
+		defer m.sessionResourceMu.Unlock()
+		for _, name := range names {
+			if controller, ok := m.resourceController[name]; ok && controller != id {
+				if !takeover {
+					return status.Errorf(codes.FailedPrecondition, "%q is already controlled by another session", name)
+				}
+				takenOver = append(takenOver, name)
+			}
+		}
+		for _, name := range names {
+			m.resourceController[name] = id
+		}
+		return nil
+	}(); err != nil {
+		return err
+	}
+
+	if len(takenOver) != 0 {
+		m.logger.CInfow(ctx, "session took over control", "session_id", id.String(), "resources", takenOver)
+		m.stopResources(ctx, takenOver)
+	}
+	return nil
+}
+
+// ReleaseControl releases the session's exclusive control of the named resources, or of all the
+// resources it controls if no names are given.
+func (m *SessionManager) ReleaseControl(ctx context.Context, id uuid.UUID, ownerID string, names []resource.Name) error {
+	if _, err := m.FindByID(ctx, id, ownerID); err != nil {
+		return err
+	}
+	m.sessionResourceMu.Lock()
+	defer m.sessionResourceMu.Unlock()
+	if len(names) == 0 {
+		m.releaseAllControlLocked(id)
+		return nil
+	}
+	for _, name := range names {
+		if m.resourceController[name] == id {
+			delete(m.resourceController, name)
+		}
+	}
+	return nil
+}
+
+// Controllers returns the ids of the sessions in exclusive control of resources.
+func (m *SessionManager) Controllers() map[resource.Name]uuid.UUID {
+	m.sessionResourceMu.RLock()
+	defer m.sessionResourceMu.RUnlock()
+	controllers := make(map[resource.Name]uuid.UUID, len(m.resourceController))
+	for name, id := range m.resourceController {
+		controllers[name] = id
+	}
+	return controllers
+}
+
+func (m *SessionManager) releaseAllControlLocked(id uuid.UUID) {
+	for name, controller := range m.resourceController {
+		if controller == id {
+			delete(m.resourceController, name)
+		}
+	}
+}
+
+// checkControl returns an error if a session other than the one with the given id, which may be
+// uuid.Nil for calls outside of a session, is in exclusive control of the named resource.
+func (m *SessionManager) checkControl(id uuid.UUID, name resource.Name) error {
+	m.sessionResourceMu.RLock()
+	controller, ok := m.resourceController[name]
+	m.sessionResourceMu.RUnlock()
+	if !ok || controller == id {
+		return nil
+	}
+	return status.Errorf(codes.FailedPrecondition, "%q is exclusively controlled by another session", name)
+}
+
+// stopResources stops the named resources that are actuators, logging any errors.
+func (m *SessionManager) stopResources(ctx context.Context, names []resource.Name) {
+	for _, name := range names {
+		res, err := m.robot.ResourceByName(name)
+		if err != nil {
+			m.logger.CWarnw(ctx, "failed to find resource to stop", "resource", name, "error", err)
+			continue
+		}
+		if actuator, ok := res.(resource.Actuator); ok {
+			if err := actuator.Stop(ctx, nil); err != nil {
+				m.logger.CWarnw(ctx, "failed to stop resource", "resource", name, "error", err)
+			}
+		}
+	}
+}
@@ -17,11 +17,12 @@ import (
 // NewSessionManager creates a new manager for holding sessions.
 func NewSessionManager(robot Robot, heartbeatWindow time.Duration) *SessionManager {
 	m := &SessionManager{
-		robot:             robot,
-		heartbeatWindow:   heartbeatWindow,
-		logger:            robot.Logger().Sublogger("networking.session_manager"),
-		sessions:          map[uuid.UUID]*session.Session{},
-		resourceToSession: map[resource.Name]uuid.UUID{},
+		robot:              robot,
+		heartbeatWindow:    heartbeatWindow,
+		logger:             robot.Logger().Sublogger("networking.session_manager"),
+		sessions:           map[uuid.UUID]*session.Session{},
+		resourceToSession:  map[resource.Name]uuid.UUID{},
+		resourceController: map[resource.Name]uuid.UUID{},
 	}
 	m.workers = utils.NewBackgroundStoppableWorkers(m.expireLoop)
 	return m
@@ -39,6 +40,9 @@ type SessionManager struct {
 
 	resourceToSession map[resource.Name]uuid.UUID
 
+	// resourceController maps resources to the session in exclusive control of them, if any.
+	resourceController map[resource.Name]uuid.UUID
+
 	workers *utils.StoppableWorkers
 }
 
@@ -87,6 +91,7 @@ func (m *SessionManager) expireLoop(ctx context.Context) {
 			defer m.sessionResourceMu.Unlock()
 			for id := range toDelete {
 				delete(m.sessions, id)
+				m.releaseAllControlLocked(id)
 			}
 
 			if len(toStop) == 0 {
@@ -209,6 +209,9 @@ func associateSession(
 	meta, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		m.logger.CWarnw(ctx, "failed to pull metadata from context", "method", method)
+		if err := m.checkControl(uuid.Nil, safetyMonitoredResourceName); err != nil {
+			return nil, err
+		}
 		return ctx, nil
 	}
 	sessID, err = sessionFromMetadata(meta)
@@ -216,6 +219,9 @@ func associateSession(
 		m.logger.CWarnw(ctx, "failed to get session id from metadata", "error", err)
 		return ctx, err
 	}
+	if err := m.checkControl(sessID, safetyMonitoredResourceName); err != nil {
+		return nil, err
+	}
 	if sessID == uuid.Nil {
 		return ctx, nil
 	}
@@ -0,0 +1,225 @@
+// Package shutdown brings the resources of a robot to a safe state and closes them when the robot
+// shuts down. Resources are shut down one at a time in reverse dependency order, so that nothing is
+// closed while a resource that depends on it is still running. Each resource is given a bounded time
+// to reach its safe state and then to close, after which it is abandoned so that a single wedged
+// resource cannot stall the shutdown of the rest.
+package shutdown
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/gantry"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+)
+
+// DefaultResourceTimeout is how long a resource is given to reach its safe state, and then to close,
+// unless configured otherwise.
+const DefaultResourceTimeout = 30 * time.Second
+
+// Outcomes of shutting down a resource.
+const (
+	OutcomeClosed   = "closed"
+	OutcomeFailed   = "failed"
+	OutcomeTimedOut = "timed_out"
+)
+
+// A Target is a resource to shut down.
+type Target struct {
+	Name resource.Name
+	// Resource is brought to its configured safe state, if any. It is nil if the resource was never
+	// built, in which case it is only closed.
+	Resource resource.Resource
+	Close    func(ctx context.Context) error
+}
+
+// A Result describes how a resource was shut down.
+type Result struct {
+	Name    resource.Name
+	Outcome string
+	// SafeState is the safe state the resource was brought to, if any, and SafeStateErr is why it
+	// could not be, if it could not.
+	SafeState    string
+	SafeStateErr error
+	Err          error
+	Duration     time.Duration
+}
+
+// Progress describes how far a shutdown has gotten.
+type Progress struct {
+	Total int
+	// Current is the resource being shut down, if any.
+	Current *resource.Name
+	// Results describes each resource shut down so far, in order.
+	Results []Result
+}
+
+// A Manager shuts down resources as configured.
+type Manager struct {
+	cfg    *config.ShutdownConfig
+	logger logging.Logger
+
+	mu       sync.Mutex
+	progress Progress
+}
+
+// NewManager returns a Manager that shuts down resources as cfg specifies. cfg may be nil, in which
+// case every resource is closed with DefaultResourceTimeout and none is brought to a safe state.
+func NewManager(cfg *config.ShutdownConfig, logger logging.Logger) *Manager {
+	if cfg == nil {
+		cfg = &config.ShutdownConfig{}
+	}
+	return &Manager{cfg: cfg, logger: logger}
+}
+
+// Progress returns how far the shutdown has gotten.
+func (m *Manager) Progress() Progress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	progress := m.progress
+	progress.Results = slices.Clone(progress.Results)
+	return progress
+}
+
+// resourceConfig returns how the named resource is to be shut down.
+func (m *Manager) resourceConfig(name resource.Name) (config.ShutdownResourceConfig, time.Duration) {
+	timeout := DefaultResourceTimeout
+	if m.cfg.ResourceTimeoutMS > 0 {
+		timeout = time.Duration(m.cfg.ResourceTimeoutMS) * time.Millisecond
+	}
+	for _, resCfg := range m.cfg.Resources {
+		if resCfg.Name != name.ShortName() && resCfg.Name != name.String() {
+			continue
+		}
+		if resCfg.TimeoutMS > 0 {
+			timeout = time.Duration(resCfg.TimeoutMS) * time.Millisecond
+		}
+		return resCfg, timeout
+	}
+	return config.ShutdownResourceConfig{Name: name.ShortName()}, timeout
+}
+
+// Shutdown brings each target to its safe state and closes it, in the given order, which should be
+// reverse dependency order. Every target is closed even if others fail.
+func (m *Manager) Shutdown(ctx context.Context, targets []Target) error {
+	m.mu.Lock()
+	m.progress = Progress{Total: len(targets)}
+	m.mu.Unlock()
+
+	var allErrs error
+	for i, target := range targets {
+		name := target.Name
+		m.mu.Lock()
+		m.progress.Current = &name
+		m.mu.Unlock()
+
+		resCfg, timeout := m.resourceConfig(name)
+		m.logger.CInfow(ctx, "Shutting down resource", "resource", name, "step", i+1, "of", len(targets))
+		result := m.shutdownResource(ctx, target, resCfg, timeout)
+		switch result.Outcome {
+		case OutcomeClosed:
+			if result.SafeStateErr != nil {
+				m.logger.CWarnw(ctx, "Resource closed without reaching its safe state",
+					"resource", name, "safe_state", result.SafeState, "error", result.SafeStateErr)
+			}
+		case OutcomeTimedOut:
+			m.logger.CErrorw(ctx, "Gave up waiting for resource to shut down", "resource", name, "timeout", timeout)
+			allErrs = multierr.Combine(allErrs, errors.Wrapf(result.Err, "resource %s", name))
+		default:
+			allErrs = multierr.Combine(allErrs, result.Err)
+		}
+
+		m.mu.Lock()
+		m.progress.Current = nil
+		m.progress.Results = append(m.progress.Results, result)
+		m.mu.Unlock()
+	}
+	return allErrs
+}
+
+func (m *Manager) shutdownResource(
+	ctx context.Context,
+	target Target,
+	resCfg config.ShutdownResourceConfig,
+	timeout time.Duration,
+) Result {
+	start := time.Now()
+	result := Result{Name: target.Name, Outcome: OutcomeClosed}
+	if resCfg.SafeState != "" && target.Resource != nil {
+		result.SafeState = resCfg.SafeState
+		result.SafeStateErr = runWithin(ctx, timeout, func(ctx context.Context) error {
+			return toSafeState(ctx, target.Resource, resCfg)
+		})
+	}
+	result.Err = runWithin(ctx, timeout, target.Close)
+	if errors.Is(result.Err, context.DeadlineExceeded) {
+		result.Outcome = OutcomeTimedOut
+	} else if result.Err != nil {
+		result.Outcome = OutcomeFailed
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// runWithin runs f, giving up on it once timeout passes even if f ignores its context.
+func runWithin(ctx context.Context, timeout time.Duration, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	goutils.PanicCapturingGo(func() {
+		done <- f(ctx)
+	})
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// toSafeState brings res to the safe state resCfg specifies.
+func toSafeState(ctx context.Context, res resource.Resource, resCfg config.ShutdownResourceConfig) error {
+	switch resCfg.SafeState {
+	case config.SafeStateStop:
+		act, ok := res.(resource.Actuator)
+		if !ok {
+			return errors.Errorf("%s is not an actuator and cannot be stopped", res.Name())
+		}
+		return act.Stop(ctx, nil)
+	case config.SafeStateHome:
+		switch homeable := res.(type) {
+		case gantry.Gantry:
+			homed, err := homeable.Home(ctx, nil)
+			if err != nil {
+				return err
+			}
+			if !homed {
+				return errors.Errorf("%s did not finish homing", res.Name())
+			}
+			return nil
+		case arm.Arm:
+			joints, err := homeable.JointPositions(ctx, nil)
+			if err != nil {
+				return err
+			}
+			return homeable.MoveToJointPositions(ctx, make([]referenceframe.Input, len(joints)), nil)
+		default:
+			return errors.Errorf("%s is neither an arm nor a gantry and cannot be homed", res.Name())
+		}
+	case config.SafeStateCommand:
+		_, err := res.DoCommand(ctx, resCfg.Command)
+		return err
+	default:
+		return errors.Errorf("unknown safe state %q", resCfg.SafeState)
+	}
+}
@@ -0,0 +1,126 @@
+package shutdown_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/gantry"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/shutdown"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestShutdown(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	var events []string
+	closer := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			events = append(events, "close "+name)
+			return nil
+		}
+	}
+
+	injectArm := inject.NewArm("arm1")
+	injectArm.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) ([]referenceframe.Input, error) {
+		return []referenceframe.Input{1, 2, 3}, nil
+	}
+	injectArm.MoveToJointPositionsFunc = func(ctx context.Context, positions []referenceframe.Input, extra map[string]interface{}) error {
+		test.That(t, positions, test.ShouldResemble, []referenceframe.Input{0, 0, 0})
+		events = append(events, "home arm1")
+		return nil
+	}
+	injectBase := inject.NewBase("base1")
+	injectBase.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		events = append(events, "stop base1")
+		return nil
+	}
+	injectGantry := inject.NewGantry("gantry1")
+	injectGantry.HomeFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		return false, nil
+	}
+	stuck := inject.NewGenericComponent("stuck")
+
+	cfg := &config.ShutdownConfig{
+		ResourceTimeoutMS: 1000,
+		Resources: []config.ShutdownResourceConfig{
+			{Name: "arm1", SafeState: config.SafeStateHome},
+			{Name: base.Named("base1").String(), SafeState: config.SafeStateStop},
+			{Name: "gantry1", SafeState: config.SafeStateHome},
+			{Name: "stuck", TimeoutMS: 10},
+		},
+	}
+	m := shutdown.NewManager(cfg, logger)
+	errClose := errors.New("close failed")
+	err := m.Shutdown(context.Background(), []shutdown.Target{
+		{Name: arm.Named("arm1"), Resource: injectArm, Close: closer("arm1")},
+		{Name: base.Named("base1"), Resource: injectBase, Close: closer("base1")},
+		{Name: gantry.Named("gantry1"), Resource: injectGantry, Close: func(context.Context) error { return errClose }},
+		{Name: stuck.Name(), Resource: stuck, Close: func(context.Context) error {
+			// ignores its context.
+			time.Sleep(time.Second)
+			return nil
+		}},
+		// never built, so only closed.
+		{Name: arm.Named("arm2"), Close: closer("arm2")},
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, errors.Is(err, errClose), test.ShouldBeTrue)
+	test.That(t, errors.Is(err, context.DeadlineExceeded), test.ShouldBeTrue)
+	test.That(t, events, test.ShouldResemble, []string{"home arm1", "close arm1", "stop base1", "close base1", "close arm2"})
+
+	progress := m.Progress()
+	test.That(t, progress.Total, test.ShouldEqual, 5)
+	test.That(t, progress.Current, test.ShouldBeNil)
+	test.That(t, progress.Results, test.ShouldHaveLength, 5)
+	outcomes := make(map[resource.Name]shutdown.Result, len(progress.Results))
+	for _, result := range progress.Results {
+		outcomes[result.Name] = result
+	}
+	test.That(t, outcomes[arm.Named("arm1")].Outcome, test.ShouldEqual, shutdown.OutcomeClosed)
+	test.That(t, outcomes[arm.Named("arm1")].SafeState, test.ShouldEqual, config.SafeStateHome)
+	test.That(t, outcomes[arm.Named("arm1")].SafeStateErr, test.ShouldBeNil)
+	test.That(t, outcomes[base.Named("base1")].SafeState, test.ShouldEqual, config.SafeStateStop)
+	test.That(t, outcomes[gantry.Named("gantry1")].Outcome, test.ShouldEqual, shutdown.OutcomeFailed)
+	test.That(t, outcomes[gantry.Named("gantry1")].SafeStateErr.Error(), test.ShouldContainSubstring, "did not finish homing")
+	test.That(t, outcomes[stuck.Name()].Outcome, test.ShouldEqual, shutdown.OutcomeTimedOut)
+	test.That(t, outcomes[stuck.Name()].Duration, test.ShouldBeLessThan, time.Second)
+	test.That(t, outcomes[arm.Named("arm2")].SafeState, test.ShouldBeEmpty)
+}
+
+func TestShutdownSafeStateErrors(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	sensor := inject.NewSensor("sensor1")
+	sensor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		test.That(t, cmd, test.ShouldResemble, map[string]interface{}{"park": true})
+		return nil, nil
+	}
+	cfg := &config.ShutdownConfig{Resources: []config.ShutdownResourceConfig{
+		{Name: "sensor1", SafeState: config.SafeStateCommand, Command: map[string]interface{}{"park": true}},
+		{Name: "sensor2", SafeState: config.SafeStateStop},
+		{Name: "sensor3", SafeState: config.SafeStateHome},
+	}}
+	m := shutdown.NewManager(cfg, logger)
+	noop := func(context.Context) error { return nil }
+	test.That(t, m.Shutdown(context.Background(), []shutdown.Target{
+		{Name: sensor.Name(), Resource: sensor, Close: noop},
+		{Name: resource.NewName(sensor.Name().API, "sensor2"), Resource: inject.NewSensor("sensor2"), Close: noop},
+		{Name: resource.NewName(sensor.Name().API, "sensor3"), Resource: inject.NewSensor("sensor3"), Close: noop},
+	}), test.ShouldBeNil)
+
+	results := m.Progress().Results
+	test.That(t, results[0].SafeStateErr, test.ShouldBeNil)
+	test.That(t, results[1].SafeStateErr.Error(), test.ShouldContainSubstring, "not an actuator")
+	test.That(t, results[2].SafeStateErr.Error(), test.ShouldContainSubstring, "cannot be homed")
+	for _, result := range results {
+		test.That(t, result.Outcome, test.ShouldEqual, shutdown.OutcomeClosed)
+	}
+}
@@ -0,0 +1,349 @@
+// Package support collects support bundles of a machine: a single tar.gz archive of its recent
+// logs, sanitized config, resource and module statuses, module versions and network diagnostics,
+// for attaching to support requests.
+//
+// The robot proto has no support bundle RPC, so the robot serves bundles over a SupportService of
+// its own whose requests and responses are google.protobuf.Structs.
+package support
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	datasyncpb "go.viam.com/api/app/datasync/v1"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/web/networkcheck"
+)
+
+// The files of a support bundle.
+const (
+	// FileManifest describes the bundle and the sections that could not be collected.
+	FileManifest = "manifest.json"
+	// FileLogs holds the most recent logs of the machine, from oldest to newest.
+	FileLogs = "logs.txt"
+	// FileConfig holds the config of the machine with its secrets masked.
+	FileConfig = "config.json"
+	// FileStatus holds the state of the machine and of each of its resources.
+	FileStatus = "status.json"
+	// FileModules holds the version and state of each module of the machine.
+	FileModules = "modules.json"
+	// FileNetwork holds the results of network checks run from the machine.
+	FileNetwork = "network.txt"
+)
+
+// UploadTag tags support bundles uploaded via the data manager.
+const UploadTag = "support_bundle"
+
+// bundleTimeFormat names bundles so that they sort by the time they were collected.
+const bundleTimeFormat = "20060102T150405Z"
+
+// Options configure how a support bundle is collected.
+type Options struct {
+	// NetworkChecks runs DNS and packet loss checks from the machine, which can take up to half a
+	// minute, and includes their results.
+	NetworkChecks bool
+	// Upload uploads the bundle to the cloud via the data manager of the machine, tagged with
+	// UploadTag, as well as returning it.
+	Upload bool
+}
+
+// A Bundle is a support bundle of a machine.
+type Bundle struct {
+	// Name is the file name of the bundle, such as "support-20261017T120000Z.tar.gz".
+	Name string
+	// Data is the tar.gz archive of the bundle.
+	Data []byte
+	// UploadIDs are the ids of the files uploaded if the bundle was uploaded.
+	UploadIDs []string
+}
+
+// manifest describes a support bundle.
+type manifest struct {
+	CreatedAt  time.Time `json:"created_at"`
+	Platform   string    `json:"platform,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	APIVersion string    `json:"api_version,omitempty"`
+	// Errors holds why each file missing from the bundle could not be collected.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// machineStatus is the state of a machine and of each of its resources.
+type machineStatus struct {
+	State          string           `json:"state"`
+	ConfigRevision string           `json:"config_revision,omitempty"`
+	Resources      []resourceStatus `json:"resources"`
+}
+
+type resourceStatus struct {
+	Name        string    `json:"name"`
+	State       string    `json:"state"`
+	Revision    string    `json:"revision,omitempty"`
+	LastUpdated time.Time `json:"last_updated"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type moduleInfo struct {
+	Name     string `json:"name"`
+	ModuleID string `json:"module_id,omitempty"`
+	Type     string `json:"type"`
+	Version  string `json:"version,omitempty"`
+	ExePath  string `json:"executable_path"`
+	State    string `json:"state,omitempty"`
+	Restarts uint   `json:"restarts,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Collect collects a support bundle of r. The config and recent logs are only included if r is a
+// LocalRobot and a RecentLogsReporter. Sections that cannot be collected are left out of the bundle
+// and their errors are listed in its manifest, so that a partly broken machine still gives a bundle.
+func Collect(ctx context.Context, r robot.Robot, opts Options) (Bundle, error) {
+	createdAt := time.Now().UTC()
+	m := manifest{CreatedAt: createdAt, Errors: map[string]string{}}
+	files := map[string][]byte{}
+	addJSON := func(name string, v any) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			m.Errors[name] = err.Error()
+			return
+		}
+		files[name] = data
+	}
+
+	if version, err := r.Version(ctx); err == nil {
+		m.Platform, m.Version, m.APIVersion = version.Platform, version.Version, version.APIVersion
+	}
+
+	if reporter, ok := r.(robot.RecentLogsReporter); ok {
+		files[FileLogs] = []byte(strings.Join(reporter.RecentLogs(), "\n") + "\n")
+	} else {
+		m.Errors[FileLogs] = "the machine does not keep its recent logs"
+	}
+
+	var cfg *config.Config
+	if local, ok := r.(robot.LocalRobot); ok {
+		cfg = local.Config()
+	}
+	if cfg != nil {
+		sanitized, err := config.SanitizedCopy(cfg)
+		if err != nil {
+			m.Errors[FileConfig] = err.Error()
+		} else {
+			addJSON(FileConfig, sanitized)
+		}
+	} else {
+		m.Errors[FileConfig] = "the config of the machine is not available"
+	}
+
+	status, err := r.MachineStatus(ctx)
+	if err != nil {
+		m.Errors[FileStatus] = err.Error()
+		m.Errors[FileModules] = err.Error()
+	} else {
+		addJSON(FileStatus, statusOf(status))
+		addJSON(FileModules, modulesOf(cfg, status))
+	}
+
+	if opts.NetworkChecks {
+		files[FileNetwork] = runNetworkChecks(ctx)
+	}
+
+	addJSON(FileManifest, m)
+	data, err := archive(files, createdAt)
+	if err != nil {
+		return Bundle{}, err
+	}
+	bundle := Bundle{Name: "support-" + createdAt.Format(bundleTimeFormat) + ".tar.gz", Data: data}
+
+	if opts.Upload {
+		bundle.UploadIDs, err = upload(ctx, r, bundle)
+		if err != nil {
+			return Bundle{}, err
+		}
+	}
+	return bundle, nil
+}
+
+func statusOf(status robot.MachineStatus) machineStatus {
+	out := machineStatus{
+		State:          machineStateName(status.State),
+		ConfigRevision: status.Config.Revision,
+		Resources:      make([]resourceStatus, 0, len(status.Resources)),
+	}
+	for _, res := range status.Resources {
+		rs := resourceStatus{
+			Name:        res.Name.String(),
+			State:       res.State.String(),
+			Revision:    res.Revision,
+			LastUpdated: res.LastUpdated,
+		}
+		if res.Error != nil {
+			rs.Error = res.Error.Error()
+		}
+		out.Resources = append(out.Resources, rs)
+	}
+	return out
+}
+
+func machineStateName(state robot.MachineState) string {
+	switch state {
+	case robot.StateInitializing:
+		return "initializing"
+	case robot.StateRunning:
+		return "running"
+	case robot.StateUnknown:
+		fallthrough
+	default:
+		return "unknown"
+	}
+}
+
+// modulesOf returns the version and state of each module configured in cfg, or of each module with
+// a status if there is no config.
+func modulesOf(cfg *config.Config, status robot.MachineStatus) []moduleInfo {
+	modules := []moduleInfo{}
+	indexByName := map[string]int{}
+	if cfg != nil {
+		for _, mod := range cfg.Modules {
+			info := moduleInfo{
+				Name:     mod.Name,
+				ModuleID: mod.ModuleID,
+				Type:     string(mod.Type),
+				Version:  mod.LocalVersion,
+				ExePath:  mod.ExePath,
+			}
+			for _, pkg := range cfg.Packages {
+				if pkg.Type == config.PackageTypeModule && (pkg.Name == mod.Name || pkg.Package == mod.ModuleID) {
+					info.Version = pkg.Version
+					break
+				}
+			}
+			indexByName[mod.Name] = len(modules)
+			modules = append(modules, info)
+		}
+	}
+	for _, s := range status.Modules {
+		idx, ok := indexByName[s.Name]
+		if !ok {
+			idx = len(modules)
+			modules = append(modules, moduleInfo{Name: s.Name})
+		}
+		modules[idx].State = strings.ToLower(strings.TrimPrefix(s.ToProto().State.String(), "STATE_"))
+		modules[idx].Restarts = s.Restarts
+		if s.Error != nil {
+			modules[idx].Error = s.Error.Error()
+		}
+	}
+	return modules
+}
+
+// runNetworkChecks runs DNS and packet loss checks and returns what they logged.
+func runNetworkChecks(ctx context.Context) []byte {
+	var buf bytes.Buffer
+	logger := logging.NewBlankLogger("network-checks")
+	logger.SetLevel(logging.DEBUG)
+	logger.AddAppender(logging.NewWriterAppender(&buf))
+	networkcheck.TestDNS(ctx, logger.Sublogger("dns"), true /* verbose to log successes */)
+	networkcheck.TestPacketLoss(ctx, logger.Sublogger("packet-loss"), true /* verbose to log successes */)
+	return buf.Bytes()
+}
+
+// archive writes files into a tar.gz archive, in the order they are listed in a bundle.
+func archive(files map[string][]byte, modTime time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range []string{FileManifest, FileLogs, FileConfig, FileStatus, FileModules, FileNetwork} {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: modTime,
+		}); err != nil {
+			return nil, errors.Wrap(err, "failed to write support bundle")
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, errors.Wrap(err, "failed to write support bundle")
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to write support bundle")
+	}
+	if err := gz.Close(); err != nil {
+		return nil, errors.Wrap(err, "failed to write support bundle")
+	}
+	return buf.Bytes(), nil
+}
+
+// Extract returns the files of a support bundle by name.
+func Extract(data []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read support bundle")
+	}
+	tr := tar.NewReader(gz)
+	files := map[string][]byte{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read support bundle")
+		}
+		var contents bytes.Buffer
+		//nolint:gosec // bundles are small, and written by the machine itself
+		if _, err := io.Copy(&contents, tr); err != nil {
+			return nil, errors.Wrap(err, "failed to read support bundle")
+		}
+		files[hdr.Name] = contents.Bytes()
+	}
+}
+
+// upload uploads bundle via the data manager of r, which removes the file it uploads.
+func upload(ctx context.Context, r robot.Robot, bundle Bundle) ([]string, error) {
+	local, ok := r.(robot.LocalRobot)
+	if !ok {
+		return nil, errors.New("only local machines can upload support bundles")
+	}
+	dir, err := os.MkdirTemp("", "support-bundle")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to upload support bundle")
+	}
+	defer func() {
+		//nolint:errcheck
+		os.RemoveAll(dir)
+	}()
+	path := filepath.Join(dir, bundle.Name)
+	if err := os.WriteFile(path, bundle.Data, 0o600); err != nil {
+		return nil, errors.Wrap(err, "failed to upload support bundle")
+	}
+	result, err := local.UploadDataFromPath(ctx, path, &datasyncpb.UploadMetadata{
+		Type:          datasyncpb.DataType_DATA_TYPE_FILE,
+		FileName:      bundle.Name,
+		FileExtension: ".tar.gz",
+		Tags:          []string{UploadTag},
+	}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to upload support bundle")
+	}
+	if result.FilesFailed > 0 {
+		return nil, errors.New("failed to upload support bundle")
+	}
+	return result.IDs, nil
+}
@@ -0,0 +1,229 @@
+package support
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"testing"
+
+	datasyncpb "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/config"
+	modulestatus "go.viam.com/rdk/module/status"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// supportRobot is a robot that keeps its recent logs.
+type supportRobot struct {
+	*inject.Robot
+	logs []string
+}
+
+func (r *supportRobot) RecentLogs() []string {
+	return r.logs
+}
+
+func (r *supportRobot) Version(ctx context.Context) (robot.VersionResponse, error) {
+	return robot.VersionResponse{Platform: "rdk", Version: "v0.0.1", APIVersion: "v0.1.0"}, nil
+}
+
+func newTestRobot() *supportRobot {
+	r := &inject.Robot{}
+	r.ConfigFunc = func() *config.Config {
+		return &config.Config{
+			Cloud: &config.Cloud{ID: "part", Secret: "cloud-secret"},
+			Modules: []config.Module{
+				{
+					Name:        "mod",
+					ModuleID:    "acme:mod",
+					Type:        config.ModuleTypeRegistry,
+					ExePath:     "/packages/mod/run.sh",
+					Environment: map[string]string{"TOKEN": "module-secret"},
+				},
+				{Name: "local", Type: config.ModuleTypeLocal, ExePath: "/bin/local"},
+			},
+			Packages: []config.PackageConfig{
+				{Name: "mod", Package: "acme/mod", Version: "1.2.3", Type: config.PackageTypeModule},
+			},
+			Services: []resource.Config{
+				{
+					Name:  "bridge",
+					API:   generic.API,
+					Model: resource.DefaultModelFamily.WithModel("mqtt-bridge"),
+					Attributes: rutils.AttributeMap{
+						"broker":   "mqtts://broker:8883",
+						"username": "bridge-user",
+						"password": "bridge-secret",
+					},
+				},
+			},
+		}
+	}
+	r.MachineStatusFunc = func(ctx context.Context) (robot.MachineStatus, error) {
+		return robot.MachineStatus{
+			State:  robot.StateRunning,
+			Config: config.Revision{Revision: "rev1"},
+			Resources: []resource.Status{
+				{NodeStatus: resource.NodeStatus{Name: arm.Named("a"), State: resource.NodeStateReady, Revision: "rev1"}},
+				{NodeStatus: resource.NodeStatus{
+					Name:  arm.Named("b"),
+					State: resource.NodeStateUnhealthy,
+					Error: errors.New("arm b is unplugged"),
+				}},
+			},
+			Modules: []modulestatus.Status{
+				{Name: "mod", State: modulestatus.ModuleStateReady},
+				{Name: "local", State: modulestatus.ModuleStateUnhealthy, Restarts: 2, Error: errors.New("crashed")},
+			},
+		}, nil
+	}
+	return &supportRobot{Robot: r, logs: []string{"first line", "second line"}}
+}
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("collects every section", func(t *testing.T) {
+		bundle, err := Collect(ctx, newTestRobot(), Options{})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, bundle.Name, test.ShouldStartWith, "support-")
+		test.That(t, bundle.Name, test.ShouldEndWith, ".tar.gz")
+		test.That(t, bundle.UploadIDs, test.ShouldBeEmpty)
+
+		files, err := Extract(bundle.Data)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, files, test.ShouldContainKey, FileManifest)
+		test.That(t, files, test.ShouldNotContainKey, FileNetwork)
+		test.That(t, string(files[FileLogs]), test.ShouldEqual, "first line\nsecond line\n")
+
+		var m manifest
+		test.That(t, json.Unmarshal(files[FileManifest], &m), test.ShouldBeNil)
+		test.That(t, m.Version, test.ShouldEqual, "v0.0.1")
+		test.That(t, m.Errors, test.ShouldBeEmpty)
+
+		test.That(t, string(files[FileConfig]), test.ShouldContainSubstring, `"TOKEN"`)
+		test.That(t, string(files[FileConfig]), test.ShouldNotContainSubstring, "module-secret")
+		test.That(t, string(files[FileConfig]), test.ShouldNotContainSubstring, "cloud-secret")
+		test.That(t, string(files[FileConfig]), test.ShouldContainSubstring, "bridge-user")
+		test.That(t, string(files[FileConfig]), test.ShouldNotContainSubstring, "bridge-secret")
+
+		var status machineStatus
+		test.That(t, json.Unmarshal(files[FileStatus], &status), test.ShouldBeNil)
+		test.That(t, status.State, test.ShouldEqual, "running")
+		test.That(t, status.ConfigRevision, test.ShouldEqual, "rev1")
+		test.That(t, status.Resources, test.ShouldHaveLength, 2)
+		test.That(t, status.Resources[0].Name, test.ShouldEqual, arm.Named("a").String())
+		test.That(t, status.Resources[0].State, test.ShouldEqual, resource.NodeStateReady.String())
+		test.That(t, status.Resources[1].Error, test.ShouldEqual, "arm b is unplugged")
+
+		var modules []moduleInfo
+		test.That(t, json.Unmarshal(files[FileModules], &modules), test.ShouldBeNil)
+		test.That(t, modules, test.ShouldHaveLength, 2)
+		test.That(t, modules[0].Name, test.ShouldEqual, "mod")
+		test.That(t, modules[0].Version, test.ShouldEqual, "1.2.3")
+		test.That(t, modules[0].State, test.ShouldEqual, "ready")
+		test.That(t, modules[1].Name, test.ShouldEqual, "local")
+		test.That(t, modules[1].State, test.ShouldEqual, "unhealthy")
+		test.That(t, modules[1].Restarts, test.ShouldEqual, 2)
+		test.That(t, modules[1].Error, test.ShouldEqual, "crashed")
+	})
+
+	t.Run("lists sections that could not be collected", func(t *testing.T) {
+		r := newTestRobot()
+		r.ConfigFunc = func() *config.Config { return nil }
+		r.MachineStatusFunc = func(ctx context.Context) (robot.MachineStatus, error) {
+			return robot.MachineStatus{}, errors.New("status unavailable")
+		}
+		bundle, err := Collect(ctx, r, Options{})
+		test.That(t, err, test.ShouldBeNil)
+		files, err := Extract(bundle.Data)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, files, test.ShouldContainKey, FileLogs)
+		test.That(t, files, test.ShouldNotContainKey, FileConfig)
+		test.That(t, files, test.ShouldNotContainKey, FileStatus)
+
+		var m manifest
+		test.That(t, json.Unmarshal(files[FileManifest], &m), test.ShouldBeNil)
+		test.That(t, m.Errors, test.ShouldContainKey, FileConfig)
+		test.That(t, m.Errors[FileStatus], test.ShouldEqual, "status unavailable")
+		test.That(t, m.Errors[FileModules], test.ShouldEqual, "status unavailable")
+	})
+
+	t.Run("uploads via the data manager", func(t *testing.T) {
+		r := newTestRobot()
+		var uploaded []byte
+		var md *datasyncpb.UploadMetadata
+		r.UploadDataFromPathFunc = func(
+			ctx context.Context,
+			path string,
+			uploadMetadata *datasyncpb.UploadMetadata,
+			extra map[string]interface{},
+		) (robot.UploadDataFromPathResult, error) {
+			var err error
+			uploaded, err = os.ReadFile(path)
+			md = uploadMetadata
+			return robot.UploadDataFromPathResult{FilesUploaded: 1, IDs: []string{"file-id"}}, err
+		}
+		bundle, err := Collect(ctx, r, Options{Upload: true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, bundle.UploadIDs, test.ShouldResemble, []string{"file-id"})
+		test.That(t, uploaded, test.ShouldResemble, bundle.Data)
+		test.That(t, md.GetTags(), test.ShouldResemble, []string{UploadTag})
+
+		r.UploadDataFromPathFunc = func(
+			ctx context.Context,
+			path string,
+			uploadMetadata *datasyncpb.UploadMetadata,
+			extra map[string]interface{},
+		) (robot.UploadDataFromPathResult, error) {
+			return robot.UploadDataFromPathResult{}, errors.New("no data manager service configured")
+		}
+		_, err = Collect(ctx, r, Options{Upload: true})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no data manager")
+	})
+}
+
+func TestCreateBundle(t *testing.T) {
+	ctx := context.Background()
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	r := newTestRobot()
+	r.UploadDataFromPathFunc = func(
+		ctx context.Context,
+		path string,
+		uploadMetadata *datasyncpb.UploadMetadata,
+		extra map[string]interface{},
+	) (robot.UploadDataFromPathResult, error) {
+		return robot.UploadDataFromPathResult{FilesUploaded: 1, IDs: []string{"file-id"}}, nil
+	}
+	gServer := grpc.NewServer()
+	gServer.RegisterService(&ServiceDesc, NewServer(r))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+
+	bundle, err := CreateBundle(ctx, conn, Options{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bundle.Name, test.ShouldEndWith, ".tar.gz")
+	files, err := Extract(bundle.Data)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(files[FileLogs]), test.ShouldEqual, "first line\nsecond line\n")
+
+	bundle, err = CreateBundle(ctx, conn, Options{Upload: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, bundle.UploadIDs, test.ShouldResemble, []string{"file-id"})
+}
@@ -0,0 +1,120 @@
+package support
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/robot"
+)
+
+const (
+	serviceName        = "rdk.robot.support.v1.SupportService"
+	createBundleMethod = "/" + serviceName + "/CreateBundle"
+)
+
+// A Server serves support bundles. Requests hold the "network_checks" and "upload" options.
+// Responses hold the "name" of the bundle, its base64-encoded "data" and its "upload_ids".
+type Server interface {
+	CreateBundle(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// ServiceDesc describes the SupportService for registering a Server with an rpc.Server.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Server)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "CreateBundle", Handler: createBundleHandler},
+	},
+	Metadata: "rdk/robot/support",
+}
+
+//nolint:revive // the signature of gRPC method handlers puts the server before the context.
+func createBundleHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Server).CreateBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: createBundleMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Server).CreateBundle(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+type server struct {
+	r robot.Robot
+}
+
+// NewServer returns a Server of the support bundles of r.
+func NewServer(r robot.Robot) Server {
+	return &server{r: r}
+}
+
+func (s *server) CreateBundle(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	bundle, err := Collect(ctx, s.r, optionsFromProto(req))
+	if err != nil {
+		return nil, err
+	}
+	return bundleToProto(bundle), nil
+}
+
+// CreateBundle has the machine at the other end of conn collect a support bundle and returns it.
+func CreateBundle(ctx context.Context, conn grpc.ClientConnInterface, opts Options) (Bundle, error) {
+	resp := new(structpb.Struct)
+	if err := conn.Invoke(ctx, createBundleMethod, optionsToProto(opts), resp); err != nil {
+		return Bundle{}, err
+	}
+	return bundleFromProto(resp)
+}
+
+func optionsToProto(opts Options) *structpb.Struct {
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"network_checks": structpb.NewBoolValue(opts.NetworkChecks),
+		"upload":         structpb.NewBoolValue(opts.Upload),
+	}}
+}
+
+func optionsFromProto(req *structpb.Struct) Options {
+	fields := req.GetFields()
+	return Options{
+		NetworkChecks: fields["network_checks"].GetBoolValue(),
+		Upload:        fields["upload"].GetBoolValue(),
+	}
+}
+
+func bundleToProto(bundle Bundle) *structpb.Struct {
+	ids := make([]*structpb.Value, 0, len(bundle.UploadIDs))
+	for _, id := range bundle.UploadIDs {
+		ids = append(ids, structpb.NewStringValue(id))
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"name":       structpb.NewStringValue(bundle.Name),
+		"data":       structpb.NewStringValue(base64.StdEncoding.EncodeToString(bundle.Data)),
+		"upload_ids": structpb.NewListValue(&structpb.ListValue{Values: ids}),
+	}}
+}
+
+func bundleFromProto(resp *structpb.Struct) (Bundle, error) {
+	fields := resp.GetFields()
+	data, err := base64.StdEncoding.DecodeString(fields["data"].GetStringValue())
+	if err != nil {
+		return Bundle{}, errors.Wrap(err, "failed to decode support bundle")
+	}
+	bundle := Bundle{Name: fields["name"].GetStringValue(), Data: data}
+	for _, id := range fields["upload_ids"].GetListValue().GetValues() {
+		bundle.UploadIDs = append(bundle.UploadIDs, id.GetStringValue())
+	}
+	return bundle, nil
+}
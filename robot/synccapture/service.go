@@ -0,0 +1,227 @@
+package synccapture
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	"github.com/pkg/errors"
+	vprotoutils "go.viam.com/utils/protoutils"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+const (
+	serviceName   = "rdk.robot.synccapture.v1.SyncCaptureService"
+	captureMethod = "/" + serviceName + "/Capture"
+)
+
+// A Server serves synchronized captures. Requests hold the names of the "cameras", and optionally
+// the "trigger_board", "trigger_pin", "trigger_pulse_ms", "max_skew_ms" and "extra". Responses hold
+// the "frames" in the order of the cameras, each with its "camera", "captured_at",
+// "hardware_triggered" and "images", each image with its "source_name", "mime_type" and
+// base64-encoded "data", and the shared "timestamp" and "max_skew_ms" of the frames. Times are in
+// RFC 3339 format.
+type Server interface {
+	Capture(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// ServiceDesc describes the SyncCaptureService for registering a Server with an rpc.Server.
+var ServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Server)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Capture", Handler: captureHandler},
+	},
+	Metadata: "rdk/robot/synccapture",
+}
+
+//nolint:revive // the signature of gRPC method handlers puts the server before the context.
+func captureHandler(
+	srv interface{},
+	ctx context.Context,
+	dec func(interface{}) error,
+	interceptor grpc.UnaryServerInterceptor,
+) (interface{}, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Server).Capture(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: captureMethod}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Server).Capture(ctx, req.(*structpb.Struct))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+type server struct {
+	r robot.Robot
+}
+
+// NewServer returns a Server of synchronized captures from the cameras of r.
+func NewServer(r robot.Robot) Server {
+	return &server{r: r}
+}
+
+func (s *server) Capture(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	captureReq := requestFromProto(req)
+	for _, name := range captureReq.Cameras {
+		if err := resource.CheckAccess(ctx, camera.Named(name)); err != nil {
+			return nil, err
+		}
+	}
+	if captureReq.TriggerBoard != "" {
+		if err := resource.CheckAccess(ctx, board.Named(captureReq.TriggerBoard)); err != nil {
+			return nil, err
+		}
+	}
+	result, err := Capture(ctx, s.r, captureReq)
+	if err != nil {
+		return nil, err
+	}
+	return resultToProto(ctx, result)
+}
+
+// Query has the robot at the other end of conn capture a frame from each camera of req at once.
+func Query(ctx context.Context, conn grpc.ClientConnInterface, req Request) (Result, error) {
+	if err := req.Validate(); err != nil {
+		return Result{}, err
+	}
+	in, err := requestToProto(req)
+	if err != nil {
+		return Result{}, err
+	}
+	resp := new(structpb.Struct)
+	if err := conn.Invoke(ctx, captureMethod, in, resp); err != nil {
+		return Result{}, err
+	}
+	result, err := resultFromProto(resp)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(result.Frames) != len(req.Cameras) {
+		return Result{}, errors.Errorf("expected %d frames but got %d", len(req.Cameras), len(result.Frames))
+	}
+	return result, nil
+}
+
+func durationToMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func durationFromMS(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+func requestToProto(req Request) (*structpb.Struct, error) {
+	cameras := make([]*structpb.Value, 0, len(req.Cameras))
+	for _, name := range req.Cameras {
+		cameras = append(cameras, structpb.NewStringValue(name))
+	}
+	fields := map[string]*structpb.Value{
+		"cameras":          structpb.NewListValue(&structpb.ListValue{Values: cameras}),
+		"trigger_board":    structpb.NewStringValue(req.TriggerBoard),
+		"trigger_pin":      structpb.NewStringValue(req.TriggerPin),
+		"trigger_pulse_ms": structpb.NewNumberValue(durationToMS(req.TriggerPulse)),
+		"max_skew_ms":      structpb.NewNumberValue(durationToMS(req.MaxSkew)),
+	}
+	if req.Extra != nil {
+		extra, err := vprotoutils.StructToStructPb(req.Extra)
+		if err != nil {
+			return nil, err
+		}
+		fields["extra"] = structpb.NewStructValue(extra)
+	}
+	return &structpb.Struct{Fields: fields}, nil
+}
+
+func requestFromProto(in *structpb.Struct) Request {
+	fields := in.GetFields()
+	req := Request{
+		TriggerBoard: fields["trigger_board"].GetStringValue(),
+		TriggerPin:   fields["trigger_pin"].GetStringValue(),
+		TriggerPulse: durationFromMS(fields["trigger_pulse_ms"].GetNumberValue()),
+		MaxSkew:      durationFromMS(fields["max_skew_ms"].GetNumberValue()),
+	}
+	for _, v := range fields["cameras"].GetListValue().GetValues() {
+		req.Cameras = append(req.Cameras, v.GetStringValue())
+	}
+	if extra := fields["extra"].GetStructValue(); extra != nil {
+		req.Extra = extra.AsMap()
+	}
+	return req
+}
+
+func resultToProto(ctx context.Context, result Result) (*structpb.Struct, error) {
+	frames := make([]*structpb.Value, 0, len(result.Frames))
+	for _, f := range result.Frames {
+		images := make([]*structpb.Value, 0, len(f.Images))
+		for _, img := range f.Images {
+			imgData, err := img.Bytes(ctx)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+				"source_name": structpb.NewStringValue(img.SourceName),
+				"mime_type":   structpb.NewStringValue(img.MimeType()),
+				"data":        structpb.NewStringValue(base64.StdEncoding.EncodeToString(imgData)),
+			}}))
+		}
+		frames = append(frames, structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"camera":             structpb.NewStringValue(f.Camera),
+			"captured_at":        structpb.NewStringValue(f.CapturedAt.UTC().Format(time.RFC3339Nano)),
+			"hardware_triggered": structpb.NewBoolValue(f.HardwareTriggered),
+			"images":             structpb.NewListValue(&structpb.ListValue{Values: images}),
+		}}))
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		"frames":      structpb.NewListValue(&structpb.ListValue{Values: frames}),
+		"timestamp":   structpb.NewStringValue(result.Timestamp.UTC().Format(time.RFC3339Nano)),
+		"max_skew_ms": structpb.NewNumberValue(durationToMS(result.MaxSkew)),
+	}}, nil
+}
+
+func resultFromProto(resp *structpb.Struct) (Result, error) {
+	fields := resp.GetFields()
+	timestamp, err := time.Parse(time.RFC3339Nano, fields["timestamp"].GetStringValue())
+	if err != nil {
+		return Result{}, errors.Wrap(err, "invalid capture timestamp")
+	}
+	result := Result{Timestamp: timestamp, MaxSkew: durationFromMS(fields["max_skew_ms"].GetNumberValue())}
+	for _, v := range fields["frames"].GetListValue().GetValues() {
+		ff := v.GetStructValue().GetFields()
+		capturedAt, err := time.Parse(time.RFC3339Nano, ff["captured_at"].GetStringValue())
+		if err != nil {
+			return Result{}, errors.Wrapf(err, "invalid capture time of camera %s", ff["camera"].GetStringValue())
+		}
+		frame := Frame{
+			Camera:            ff["camera"].GetStringValue(),
+			CapturedAt:        capturedAt,
+			HardwareTriggered: ff["hardware_triggered"].GetBoolValue(),
+		}
+		for _, iv := range ff["images"].GetListValue().GetValues() {
+			imf := iv.GetStructValue().GetFields()
+			imgData, err := base64.StdEncoding.DecodeString(imf["data"].GetStringValue())
+			if err != nil {
+				return Result{}, errors.Wrapf(err, "invalid image of camera %s", frame.Camera)
+			}
+			img, err := camera.NamedImageFromBytes(
+				imgData, imf["source_name"].GetStringValue(), imf["mime_type"].GetStringValue(), data.Annotations{})
+			if err != nil {
+				return Result{}, err
+			}
+			frame.Images = append(frame.Images, img)
+		}
+		result.Frames = append(result.Frames, frame)
+	}
+	return result, nil
+}
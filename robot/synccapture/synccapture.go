@@ -0,0 +1,239 @@
+// Package synccapture captures frames from many cameras of a robot at once, for pipelines such as
+// stereo and multi-view reconstruction that need frames of the same instant.
+//
+// Cameras that support hardware triggering are armed and exposed together by a pulse on a board
+// GPIO pin wired to their trigger inputs. The other cameras are released together by a software
+// barrier at the moment of the pulse. Each capture reports a shared timestamp and how far apart the
+// frames were captured.
+//
+// The robot proto has no synchronized capture RPC, so the robot serves captures over a
+// SyncCaptureService of its own whose requests and responses are google.protobuf.Structs.
+package synccapture
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/robot"
+)
+
+// MaxCameras is the most cameras a capture may hold.
+const MaxCameras = 32
+
+// defaultTriggerPulse is how long the trigger pin is held high if the request does not say.
+const defaultTriggerPulse = time.Millisecond
+
+// A HardwareTriggerable camera can expose its next frame on a pulse of its trigger input, rather
+// than when its images are requested.
+type HardwareTriggerable interface {
+	// ArmTrigger makes the next call to Images return the frame exposed on the next trigger pulse,
+	// blocking until it has been.
+	ArmTrigger(ctx context.Context, extra map[string]interface{}) error
+}
+
+// A Request is a synchronized capture from many cameras.
+type Request struct {
+	// Cameras are the names of the cameras to capture from.
+	Cameras []string
+	// TriggerBoard and TriggerPin name the board GPIO pin wired to the trigger inputs of the cameras
+	// that support hardware triggering. If they are empty, every camera is captured by the software
+	// barrier.
+	TriggerBoard string
+	TriggerPin   string
+	// TriggerPulse is how long the trigger pin is held high, or 1ms if zero.
+	TriggerPulse time.Duration
+	// MaxSkew fails the capture if its frames were captured further apart. Zero means no limit.
+	MaxSkew time.Duration
+	Extra   map[string]interface{}
+}
+
+// Validate ensures the capture can be made.
+func (req Request) Validate() error {
+	if len(req.Cameras) == 0 {
+		return errors.New("synchronized captures need at least one camera")
+	}
+	if len(req.Cameras) > MaxCameras {
+		return errors.Errorf("synchronized captures may hold at most %d cameras but got %d", MaxCameras, len(req.Cameras))
+	}
+	seen := make(map[string]bool, len(req.Cameras))
+	for _, name := range req.Cameras {
+		if name == "" {
+			return errors.New("synchronized captures must name each camera")
+		}
+		if seen[name] {
+			return errors.Errorf("camera %q is in the synchronized capture more than once", name)
+		}
+		seen[name] = true
+	}
+	if (req.TriggerBoard == "") != (req.TriggerPin == "") {
+		return errors.New("a hardware trigger needs both a board and a pin")
+	}
+	if req.TriggerPulse < 0 || req.MaxSkew < 0 {
+		return errors.New("trigger pulse and max skew must not be negative")
+	}
+	return nil
+}
+
+// A Frame is what one camera captured.
+type Frame struct {
+	Camera string
+	Images []camera.NamedImage
+	// CapturedAt is when the camera says it captured the frame, or else the trigger pulse for
+	// hardware triggered frames and the middle of the request for others.
+	CapturedAt time.Time
+	// HardwareTriggered is whether the frame was exposed by the trigger pulse.
+	HardwareTriggered bool
+}
+
+// A Result is a synchronized capture, with a frame from each camera in the order they were requested.
+type Result struct {
+	Frames []Frame
+	// Timestamp is the shared time of the frames: the trigger pulse if every frame was hardware
+	// triggered, and otherwise the middle of the times they were captured.
+	Timestamp time.Time
+	// MaxSkew is how far apart the earliest and latest frames were captured.
+	MaxSkew time.Duration
+}
+
+// Capture captures a frame from each camera of req at once. It fails if any camera fails, as
+// frames missing a view are of no use to the pipelines it serves.
+func Capture(ctx context.Context, r robot.Robot, req Request) (Result, error) {
+	if err := req.Validate(); err != nil {
+		return Result{}, err
+	}
+	cams := make([]camera.Camera, len(req.Cameras))
+	for i, name := range req.Cameras {
+		cam, err := camera.FromProvider(r, name)
+		if err != nil {
+			return Result{}, err
+		}
+		cams[i] = cam
+	}
+
+	var trigger board.GPIOPin
+	triggered := make([]bool, len(cams))
+	if req.TriggerBoard != "" {
+		b, err := board.FromProvider(r, req.TriggerBoard)
+		if err != nil {
+			return Result{}, err
+		}
+		trigger, err = b.GPIOPinByName(req.TriggerPin)
+		if err != nil {
+			return Result{}, err
+		}
+		for i, cam := range cams {
+			triggerable, ok := cam.(HardwareTriggerable)
+			if !ok {
+				continue
+			}
+			if err := triggerable.ArmTrigger(ctx, req.Extra); err != nil {
+				return Result{}, errors.Wrapf(err, "failed to arm the trigger of camera %s", req.Cameras[i])
+			}
+			triggered[i] = true
+		}
+	}
+
+	// cameras armed for a trigger that never comes are stopped waiting if pulsing it fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	frames := make([]Frame, len(cams))
+	errs := make([]error, len(cams))
+	var ready, done sync.WaitGroup
+	release := make(chan struct{})
+	for i, cam := range cams {
+		ready.Add(1)
+		done.Add(1)
+		utils.PanicCapturingGo(func() {
+			defer done.Done()
+			ready.Done()
+			select {
+			case <-release:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			start := time.Now()
+			images, md, err := cam.Images(ctx, nil, req.Extra)
+			if err != nil {
+				errs[i] = errors.Wrapf(err, "failed to capture from camera %s", req.Cameras[i])
+				return
+			}
+			// hardware triggered frames the camera does not timestamp are given the time of the pulse.
+			capturedAt := md.CapturedAt
+			if capturedAt.IsZero() && !triggered[i] {
+				capturedAt = start.Add(time.Since(start) / 2)
+			}
+			frames[i] = Frame{Camera: req.Cameras[i], Images: images, CapturedAt: capturedAt, HardwareTriggered: triggered[i]}
+		})
+	}
+
+	// every camera is waiting at the barrier before any is released, so that none is held up by
+	// goroutines still starting.
+	ready.Wait()
+	close(release)
+	var pulsedAt time.Time
+	var pulseErr error
+	if trigger != nil {
+		pulsedAt, pulseErr = pulse(ctx, trigger, req.TriggerPulse, req.Extra)
+		if pulseErr != nil {
+			cancel()
+		}
+	}
+	done.Wait()
+	if pulseErr != nil {
+		return Result{}, errors.Wrap(pulseErr, "failed to pulse the trigger")
+	}
+	for _, err := range errs {
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	allTriggered := trigger != nil
+	for i := range frames {
+		if triggered[i] && frames[i].CapturedAt.IsZero() {
+			frames[i].CapturedAt = pulsedAt
+		}
+		allTriggered = allTriggered && triggered[i]
+	}
+	earliest, latest := frames[0].CapturedAt, frames[0].CapturedAt
+	for _, f := range frames[1:] {
+		if f.CapturedAt.Before(earliest) {
+			earliest = f.CapturedAt
+		}
+		if f.CapturedAt.After(latest) {
+			latest = f.CapturedAt
+		}
+	}
+	result := Result{Frames: frames, Timestamp: earliest.Add(latest.Sub(earliest) / 2), MaxSkew: latest.Sub(earliest)}
+	if allTriggered {
+		result.Timestamp = pulsedAt
+	}
+	if req.MaxSkew > 0 && result.MaxSkew > req.MaxSkew {
+		return Result{}, errors.Errorf("frames were captured %v apart, more than the max skew of %v", result.MaxSkew, req.MaxSkew)
+	}
+	return result, nil
+}
+
+// pulse holds the trigger pin high for width and returns when it went high.
+func pulse(ctx context.Context, pin board.GPIOPin, width time.Duration, extra map[string]interface{}) (time.Time, error) {
+	if width == 0 {
+		width = defaultTriggerPulse
+	}
+	pulsedAt := time.Now()
+	if err := pin.Set(ctx, true, extra); err != nil {
+		return time.Time{}, err
+	}
+	utils.SelectContextOrWait(ctx, width)
+	// the pin is lowered even if ctx is done, so that the trigger is not left high.
+	if err := pin.Set(context.Background(), false, extra); err != nil {
+		return time.Time{}, err
+	}
+	return pulsedAt, nil
+}
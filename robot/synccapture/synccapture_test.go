@@ -0,0 +1,271 @@
+package synccapture
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/components/board"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// triggeredCamera is a camera whose frames are exposed by pulses of its trigger input.
+type triggeredCamera struct {
+	*inject.Camera
+	armed   chan struct{}
+	exposed chan time.Time
+}
+
+func newTriggeredCamera(name string) *triggeredCamera {
+	cam := &triggeredCamera{Camera: inject.NewCamera(name), armed: make(chan struct{}, 1), exposed: make(chan time.Time, 1)}
+	cam.ImagesFunc = func(
+		ctx context.Context,
+		filterSourceNames []string,
+		extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		select {
+		case <-cam.armed:
+		default:
+			return nil, resource.ResponseMetadata{}, errors.New("not armed")
+		}
+		select {
+		case <-cam.exposed:
+		case <-ctx.Done():
+			return nil, resource.ResponseMetadata{}, ctx.Err()
+		}
+		img, err := camera.NamedImageFromBytes([]byte(name), "color", "image/jpeg", data.Annotations{})
+		return []camera.NamedImage{img}, resource.ResponseMetadata{}, err
+	}
+	return cam
+}
+
+func (c *triggeredCamera) ArmTrigger(ctx context.Context, extra map[string]interface{}) error {
+	c.armed <- struct{}{}
+	return nil
+}
+
+func newFreeCamera(name string, delay time.Duration) *inject.Camera {
+	cam := inject.NewCamera(name)
+	cam.ImagesFunc = func(
+		ctx context.Context,
+		filterSourceNames []string,
+		extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		time.Sleep(delay)
+		img, err := camera.NamedImageFromBytes([]byte(name), "color", "image/jpeg", data.Annotations{})
+		return []camera.NamedImage{img}, resource.ResponseMetadata{}, err
+	}
+	return cam
+}
+
+// newTestRobot returns a robot with a board whose trigger pin exposes the triggered cameras.
+func newTestRobot(cams ...resource.Resource) (*inject.Robot, *[]bool) {
+	var mu sync.Mutex
+	var levels []bool
+	pin := &inject.GPIOPin{}
+	pin.SetFunc = func(ctx context.Context, high bool, extra map[string]interface{}) error {
+		mu.Lock()
+		levels = append(levels, high)
+		mu.Unlock()
+		if high {
+			for _, res := range cams {
+				if tc, ok := res.(*triggeredCamera); ok {
+					tc.exposed <- time.Now()
+				}
+			}
+		}
+		return nil
+	}
+	b := inject.NewBoard("board")
+	b.GPIOPinByNameFunc = func(name string) (board.GPIOPin, error) {
+		if name != "trigger" {
+			return nil, errors.New("no such pin")
+		}
+		return pin, nil
+	}
+	resources := map[resource.Name]resource.Resource{board.Named("board"): b}
+	for _, cam := range cams {
+		resources[cam.Name()] = cam
+	}
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		res, ok := resources[name]
+		if !ok {
+			return nil, resource.NewNotFoundError(name)
+		}
+		return res, nil
+	}
+	return r, &levels
+}
+
+func checkFrames(t *testing.T, result Result, cameras []string) {
+	t.Helper()
+	test.That(t, result.Frames, test.ShouldHaveLength, len(cameras))
+	for i, f := range result.Frames {
+		test.That(t, f.Camera, test.ShouldEqual, cameras[i])
+		test.That(t, f.Images, test.ShouldHaveLength, 1)
+		imgData, err := f.Images[0].Bytes(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, string(imgData), test.ShouldEqual, cameras[i])
+		test.That(t, f.CapturedAt.IsZero(), test.ShouldBeFalse)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("software barrier", func(t *testing.T) {
+		r, levels := newTestRobot(newFreeCamera("left", 0), newFreeCamera("right", 20*time.Millisecond))
+		cameras := []string{"left", "right"}
+		result, err := Capture(ctx, r, Request{Cameras: cameras})
+		test.That(t, err, test.ShouldBeNil)
+		checkFrames(t, result, cameras)
+		test.That(t, result.Frames[0].HardwareTriggered, test.ShouldBeFalse)
+		test.That(t, result.MaxSkew, test.ShouldBeGreaterThan, 0)
+		test.That(t, result.Timestamp.After(result.Frames[0].CapturedAt), test.ShouldBeTrue)
+		test.That(t, result.Timestamp.Before(result.Frames[1].CapturedAt), test.ShouldBeTrue)
+		test.That(t, *levels, test.ShouldBeEmpty)
+
+		_, err = Capture(ctx, r, Request{Cameras: cameras, MaxSkew: time.Millisecond})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "max skew")
+	})
+
+	t.Run("hardware trigger", func(t *testing.T) {
+		r, levels := newTestRobot(newTriggeredCamera("left"), newTriggeredCamera("right"))
+		cameras := []string{"left", "right"}
+		result, err := Capture(ctx, r, Request{Cameras: cameras, TriggerBoard: "board", TriggerPin: "trigger"})
+		test.That(t, err, test.ShouldBeNil)
+		checkFrames(t, result, cameras)
+		for _, f := range result.Frames {
+			test.That(t, f.HardwareTriggered, test.ShouldBeTrue)
+			test.That(t, f.CapturedAt, test.ShouldEqual, result.Timestamp)
+		}
+		test.That(t, result.MaxSkew, test.ShouldEqual, 0)
+		test.That(t, *levels, test.ShouldResemble, []bool{true, false})
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		r, _ := newTestRobot(newTriggeredCamera("left"), newFreeCamera("right", 0))
+		cameras := []string{"left", "right"}
+		result, err := Capture(ctx, r, Request{Cameras: cameras, TriggerBoard: "board", TriggerPin: "trigger"})
+		test.That(t, err, test.ShouldBeNil)
+		checkFrames(t, result, cameras)
+		test.That(t, result.Frames[0].HardwareTriggered, test.ShouldBeTrue)
+		test.That(t, result.Frames[1].HardwareTriggered, test.ShouldBeFalse)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		r, _ := newTestRobot(newFreeCamera("left", 0))
+		for _, req := range []Request{
+			{},
+			{Cameras: []string{"left", "left"}},
+			{Cameras: []string{"left"}, TriggerBoard: "board"},
+			{Cameras: []string{"left"}, MaxSkew: -time.Second},
+		} {
+			_, err := Capture(ctx, r, req)
+			test.That(t, err, test.ShouldNotBeNil)
+		}
+		_, err := Capture(ctx, r, Request{Cameras: []string{"left", "missing"}})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = Capture(ctx, r, Request{Cameras: []string{"left"}, TriggerBoard: "board", TriggerPin: "missing"})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		broken := inject.NewCamera("broken")
+		broken.ImagesFunc = func(
+			ctx context.Context,
+			filterSourceNames []string,
+			extra map[string]interface{},
+		) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+			return nil, resource.ResponseMetadata{}, errors.New("unplugged")
+		}
+		r, _ = newTestRobot(newFreeCamera("left", 0), broken)
+		_, err = Capture(ctx, r, Request{Cameras: []string{"left", "broken"}})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "unplugged")
+	})
+}
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRobot(newTriggeredCamera("left"), newFreeCamera("right", 0))
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	gServer := grpc.NewServer()
+	gServer.RegisterService(&ServiceDesc, NewServer(r))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+
+	cameras := []string{"left", "right"}
+	result, err := Query(ctx, conn, Request{
+		Cameras:      cameras,
+		TriggerBoard: "board",
+		TriggerPin:   "trigger",
+		MaxSkew:      time.Minute,
+		Extra:        map[string]interface{}{"exposure": 10.},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	checkFrames(t, result, cameras)
+	test.That(t, result.Frames[0].HardwareTriggered, test.ShouldBeTrue)
+	test.That(t, result.Frames[1].HardwareTriggered, test.ShouldBeFalse)
+	test.That(t, result.Frames[0].Images[0].MimeType(), test.ShouldEqual, "image/jpeg")
+	test.That(t, result.Timestamp.IsZero(), test.ShouldBeFalse)
+
+	_, err = Query(ctx, conn, Request{Cameras: []string{"missing"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = Query(ctx, conn, Request{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestQueryAccessCheck(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newTestRobot(newFreeCamera("left", 0), newFreeCamera("right", 0))
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	// callers may only access the camera left.
+	gServer := grpc.NewServer(grpc.UnaryInterceptor(func(
+		ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (any, error) {
+		return handler(resource.ContextWithAccessCheck(ctx, func(name string) error {
+			if name != "left" {
+				return status.Errorf(codes.PermissionDenied, "not allowed to access resource %q", name)
+			}
+			return nil
+		}), req)
+	}))
+	gServer.RegisterService(&ServiceDesc, NewServer(r))
+	go gServer.Serve(listener)
+	defer gServer.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+
+	result, err := Query(ctx, conn, Request{Cameras: []string{"left"}, MaxSkew: time.Minute})
+	test.That(t, err, test.ShouldBeNil)
+	checkFrames(t, result, []string{"left"})
+
+	for _, req := range []Request{
+		{Cameras: []string{"left", "right"}, MaxSkew: time.Minute},
+		{Cameras: []string{"left"}, TriggerBoard: "board", TriggerPin: "trigger", MaxSkew: time.Minute},
+	} {
+		_, err = Query(ctx, conn, req)
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+	}
+}
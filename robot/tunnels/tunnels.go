@@ -0,0 +1,218 @@
+// Package tunnels manages the traffic tunnel endpoints of a robot at runtime, so that ports, such as
+// the ones of an RTSP server or a discovery protocol, can be tunneled on demand without changing the
+// robot's config. Endpoints opened at runtime are tunneled to in addition to the configured ones and
+// are forgotten when the robot shuts down.
+//
+// The manager is a resource named PublicServiceName on every local robot, and it is used through its
+// DoCommand with the keys below, which works against both local robots and robot clients. Open,
+// Close, and List wrap that contract. Like the Tunnel method, only the admin role may use it.
+package tunnels
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/generic"
+)
+
+// PublicServiceName is the generic service through which a robot's tunnel endpoints are managed.
+var PublicServiceName = resource.NewName(generic.API, "$tunnels")
+
+// export keys to be used with DoCommand on PublicServiceName so they can be referenced by clients.
+// Endpoints are given in the format of the traffic_tunnel_endpoints of a config.
+//
+//   - DoOpen opens an endpoint, replacing any opened at runtime on the same port and protocol. An
+//     endpoint without allowed entities is limited to the authenticated entity opening it, if any.
+//     required key: DoOpen
+//   - DoClose closes an endpoint opened at runtime; only its port and protocol are read
+//     required key: DoClose
+//   - DoList lists the endpoints opened at runtime
+//     required key: DoList
+//
+// Every command responds with the endpoints opened at runtime under the DoEndpoints key.
+const (
+	DoOpen      = "open"
+	DoClose     = "close"
+	DoList      = "list"
+	DoEndpoints = "endpoints"
+)
+
+// Open opens a tunnel endpoint on the given robot.
+func Open(ctx context.Context, r robot.Robot, tte config.TrafficTunnelEndpoint) error {
+	cmdTTE, err := endpointToCommand(tte)
+	if err != nil {
+		return err
+	}
+	_, err = doCommand(ctx, r, map[string]interface{}{DoOpen: cmdTTE})
+	return err
+}
+
+// Close closes the tunnel endpoint opened at runtime on the given robot on the given port and protocol.
+func Close(ctx context.Context, r robot.Robot, port int, protocol string) error {
+	cmdTTE, err := endpointToCommand(config.TrafficTunnelEndpoint{Port: port, Protocol: protocol})
+	if err != nil {
+		return err
+	}
+	_, err = doCommand(ctx, r, map[string]interface{}{DoClose: cmdTTE})
+	return err
+}
+
+// List lists the tunnel endpoints opened at runtime on the given robot.
+func List(ctx context.Context, r robot.Robot) ([]config.TrafficTunnelEndpoint, error) {
+	return doCommand(ctx, r, map[string]interface{}{DoList: true})
+}
+
+func doCommand(ctx context.Context, r robot.Robot, cmd map[string]interface{}) ([]config.TrafficTunnelEndpoint, error) {
+	res, err := r.ResourceByName(PublicServiceName)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := res.DoCommand(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	cmdTTEs, ok := resp[DoEndpoints].([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected %s to be a list but got %T", DoEndpoints, resp[DoEndpoints])
+	}
+	ttes := make([]config.TrafficTunnelEndpoint, 0, len(cmdTTEs))
+	for _, cmdTTE := range cmdTTEs {
+		tte, err := endpointFromCommand(cmdTTE)
+		if err != nil {
+			return nil, err
+		}
+		ttes = append(ttes, tte)
+	}
+	return ttes, nil
+}
+
+// endpointToCommand converts an endpoint to its config format, which DoCommand carries.
+func endpointToCommand(tte config.TrafficTunnelEndpoint) (map[string]interface{}, error) {
+	return resource.EncodeDoCommand(&tte)
+}
+
+func endpointFromCommand(cmdTTE interface{}) (config.TrafficTunnelEndpoint, error) {
+	m, ok := cmdTTE.(map[string]interface{})
+	if !ok {
+		return config.TrafficTunnelEndpoint{}, errors.Errorf("expected a tunnel endpoint to be a map but got %T", cmdTTE)
+	}
+	tte, err := resource.DecodeDoCommand[config.TrafficTunnelEndpoint](m)
+	if err != nil {
+		return tte, errors.Wrap(err, "invalid tunnel endpoint")
+	}
+	return tte, nil
+}
+
+// A Manager holds the tunnel endpoints opened at runtime.
+type Manager struct {
+	mu        sync.Mutex
+	endpoints []config.TrafficTunnelEndpoint
+}
+
+// NewManager returns a Manager with no endpoints opened.
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Open opens an endpoint, replacing any opened on the same port and protocol.
+func (m *Manager) Open(tte config.TrafficTunnelEndpoint) error {
+	if err := tte.Validate(DoOpen); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endpoints = slices.DeleteFunc(m.endpoints, func(opened config.TrafficTunnelEndpoint) bool {
+		return sameEndpoint(opened, tte)
+	})
+	m.endpoints = append(m.endpoints, tte)
+	return nil
+}
+
+// Close closes the endpoint opened on the given port and protocol.
+func (m *Manager) Close(port int, protocol string) error {
+	closing := config.TrafficTunnelEndpoint{Port: port, Protocol: protocol}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	idx := slices.IndexFunc(m.endpoints, func(opened config.TrafficTunnelEndpoint) bool {
+		return sameEndpoint(opened, closing)
+	})
+	if idx == -1 {
+		return fmt.Errorf("no %s tunnel endpoint is open at port %d", closing.Network(), port)
+	}
+	m.endpoints = slices.Delete(m.endpoints, idx, idx+1)
+	return nil
+}
+
+// Endpoints returns the endpoints opened.
+func (m *Manager) Endpoints() []config.TrafficTunnelEndpoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.endpoints)
+}
+
+func sameEndpoint(a, b config.TrafficTunnelEndpoint) bool {
+	return a.Port == b.Port && a.Network() == b.Network()
+}
+
+type service struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	manager *Manager
+}
+
+// NewService returns the resource through which the endpoints of manager are opened and closed,
+// which the robot serves as PublicServiceName.
+func NewService(manager *Manager) resource.Resource {
+	return &service{Named: PublicServiceName.AsNamed(), manager: manager}
+}
+
+func (svc *service) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	openTTE, open := cmd[DoOpen]
+	closeTTE, closing := cmd[DoClose]
+	_, list := cmd[DoList]
+	switch {
+	case open && closing:
+		return nil, fmt.Errorf("cannot both %s and %s an endpoint", DoOpen, DoClose)
+	case open:
+		tte, err := endpointFromCommand(openTTE)
+		if err != nil {
+			return nil, err
+		}
+		// endpoints are only opened to everyone when listed in the config
+		if entity, ok := rpc.ContextAuthEntity(ctx); ok && len(tte.AllowedEntities) == 0 {
+			tte.AllowedEntities = []string{entity.Entity}
+		}
+		if err := svc.manager.Open(tte); err != nil {
+			return nil, err
+		}
+	case closing:
+		tte, err := endpointFromCommand(closeTTE)
+		if err != nil {
+			return nil, err
+		}
+		if err := svc.manager.Close(tte.Port, tte.Protocol); err != nil {
+			return nil, err
+		}
+	case !list:
+		return nil, resource.ErrDoUnimplemented
+	}
+
+	endpoints := []interface{}{}
+	for _, tte := range svc.manager.Endpoints() {
+		cmdTTE, err := endpointToCommand(tte)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, cmdTTE)
+	}
+	return map[string]interface{}{DoEndpoints: endpoints}, nil
+}
@@ -0,0 +1,85 @@
+package tunnels_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/tunnels"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestTunnels(t *testing.T) {
+	ctx := context.Background()
+	manager := tunnels.NewManager()
+	svc := tunnels.NewService(manager)
+	test.That(t, svc.Name(), test.ShouldResemble, tunnels.PublicServiceName)
+
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		test.That(t, name, test.ShouldResemble, tunnels.PublicServiceName)
+		return svc, nil
+	}
+
+	ttes, err := tunnels.List(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ttes, test.ShouldBeEmpty)
+
+	tcp := config.TrafficTunnelEndpoint{Port: 8554, ConnectionTimeout: time.Second}
+	udp := config.TrafficTunnelEndpoint{
+		Port:            8554,
+		Protocol:        config.TunnelProtocolUDP,
+		AllowedEntities: []string{"some-entity"},
+	}
+	test.That(t, tunnels.Open(ctx, r, tcp), test.ShouldBeNil)
+	test.That(t, tunnels.Open(ctx, r, udp), test.ShouldBeNil)
+	ttes, err = tunnels.List(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ttes, test.ShouldResemble, []config.TrafficTunnelEndpoint{tcp, udp})
+	test.That(t, manager.Endpoints(), test.ShouldResemble, ttes)
+
+	t.Run("reopening replaces", func(t *testing.T) {
+		tcp.ConnectionTimeout = 2 * time.Second
+		test.That(t, tunnels.Open(ctx, r, tcp), test.ShouldBeNil)
+		ttes, err := tunnels.List(ctx, r)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ttes, test.ShouldResemble, []config.TrafficTunnelEndpoint{udp, tcp})
+	})
+
+	t.Run("invalid endpoint", func(t *testing.T) {
+		err := tunnels.Open(ctx, r, config.TrafficTunnelEndpoint{Port: 8554, Protocol: "sctp"})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "protocol")
+		test.That(t, manager.Endpoints(), test.ShouldHaveLength, 2)
+	})
+
+	t.Run("limited to the opening entity", func(t *testing.T) {
+		entityCtx := rpc.ContextWithAuthEntity(ctx, rpc.EntityInfo{Entity: "opener"})
+		ssh := config.TrafficTunnelEndpoint{Port: 22}
+		test.That(t, tunnels.Open(entityCtx, r, ssh), test.ShouldBeNil)
+		ttes, err := tunnels.List(ctx, r)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ttes, test.ShouldHaveLength, 3)
+		test.That(t, ttes[2].AllowedEntities, test.ShouldResemble, []string{"opener"})
+		test.That(t, ttes[2].Allows("someone-else"), test.ShouldBeFalse)
+		test.That(t, tunnels.Close(ctx, r, 22, config.TunnelProtocolTCP), test.ShouldBeNil)
+	})
+
+	t.Run("unknown command", func(t *testing.T) {
+		_, err := svc.DoCommand(ctx, map[string]interface{}{"foo": true})
+		test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+	})
+
+	test.That(t, tunnels.Close(ctx, r, 8554, config.TunnelProtocolTCP), test.ShouldBeNil)
+	err = tunnels.Close(ctx, r, 8554, config.TunnelProtocolTCP)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no tcp tunnel endpoint is open at port 8554")
+	ttes, err = tunnels.List(ctx, r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ttes, test.ShouldResemble, []config.TrafficTunnelEndpoint{udp})
+}
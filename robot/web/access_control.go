@@ -0,0 +1,218 @@
+package web
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"go.viam.com/utils/rpc"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/automation"
+	"go.viam.com/rdk/robot/tunnels"
+)
+
+// The methods of adminOnlyServices and the adminOnlyMethods may only be called by the admin role, and
+// the adminOnlyResources may only be accessed by it.
+var (
+	adminOnlyServices = []string{
+		"viam.service.shell.v1.ShellService",
+		// support bundles hold the logs and configs of the machine.
+		"rdk.robot.support.v1.SupportService",
+	}
+	adminOnlyMethods = []string{
+		"/viam.robot.v1.RobotService/Shutdown",
+		"/viam.robot.v1.RobotService/RestartModule",
+		"/viam.robot.v1.RobotService/Tunnel",
+		"/viam.robot.v1.RobotService/ListTunnels",
+	}
+	adminOnlyResources = []string{
+		// tunnel endpoints opened at runtime are as powerful as the Tunnel method.
+		tunnels.PublicServiceName.ShortName(),
+		// jobs run as the robot, so they can call anything, including the shell service.
+		automation.PublicServiceName.ShortName(),
+	}
+)
+
+// viewerMethodPrefixes are the prefixes of method names that only read state, which the viewer role
+// may call. Methods such as Stop are deliberately not included as they change what a resource is doing.
+var (
+	viewerMethodPrefixes = []string{"Get", "List", "Is", "Read", "Stream"}
+	viewerMethods        = []string{
+		"ResourceNames",
+		"ResourceRPCSubtypes",
+		"FrameSystemConfig",
+		"TransformPose",
+		"TransformPCD",
+		"StartSession",
+		"SendSessionHeartbeat",
+	}
+	// viewerServices may be called by the viewer role so that it can watch camera streams.
+	viewerServices = []string{
+		"proto.stream.v1.StreamService",
+	}
+)
+
+// methodAllowed returns whether role may call the gRPC method fullMethod.
+func methodAllowed(role config.AuthRole, fullMethod string) bool {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return false
+	}
+	// authentication and WebRTC signaling are needed to connect at all.
+	if strings.HasPrefix(service, "proto.rpc.") {
+		return true
+	}
+	switch role {
+	case config.AuthRoleAdmin:
+		return true
+	case config.AuthRoleOperator:
+		return !slices.Contains(adminOnlyServices, service) && !slices.Contains(adminOnlyMethods, fullMethod)
+	case config.AuthRoleViewer:
+		if slices.Contains(adminOnlyServices, service) || slices.Contains(adminOnlyMethods, fullMethod) {
+			return false
+		}
+		if slices.Contains(viewerServices, service) || slices.Contains(viewerMethods, method) {
+			return true
+		}
+		for _, prefix := range viewerMethodPrefixes {
+			if strings.HasPrefix(method, prefix) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// accessControl enforces the roles of an auth config on incoming RPCs. Entities without a role have
+// full access, except for calls over WebRTC connections that cannot be attributed to the entity that
+// signaled them, which get the viewer role.
+type accessControl struct {
+	roles map[string]config.AuthRoleConfig
+}
+
+func newAccessControl(roles []config.AuthRoleConfig) *accessControl {
+	ac := &accessControl{roles: make(map[string]config.AuthRoleConfig, len(roles))}
+	for _, role := range roles {
+		ac.roles[role.Entity] = role
+	}
+	return ac
+}
+
+// roleFor returns the role of the entity making the call, if it has one.
+func (ac *accessControl) roleFor(ctx context.Context) (config.AuthRoleConfig, bool) {
+	entity, ok := rpc.ContextAuthEntity(ctx)
+	if isUnmappedWebRTCCall(ctx) {
+		// the call is attributed to the robot itself, which would otherwise give it full access.
+		return config.AuthRoleConfig{Entity: entity.Entity, Role: config.AuthRoleViewer}, true
+	}
+	if !ok {
+		return config.AuthRoleConfig{}, false
+	}
+	role, ok := ac.roles[entity.Entity]
+	return role, ok
+}
+
+func (ac *accessControl) checkMethod(role config.AuthRoleConfig, fullMethod string) error {
+	if !methodAllowed(role.Role, fullMethod) {
+		return status.Errorf(codes.PermissionDenied, "the %s role may not call %s", role.Role, fullMethod)
+	}
+	return nil
+}
+
+func (ac *accessControl) checkResource(role config.AuthRoleConfig, fullMethod string, req any) error {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	name := resource.GetResourceNameFromRequest(service, method, req)
+	if name == "" {
+		return nil
+	}
+	if slices.Contains(adminOnlyResources, name) && role.Role != config.AuthRoleAdmin {
+		return status.Errorf(codes.PermissionDenied, "the %s role may not access resource %q", role.Role, name)
+	}
+	return checkResourceName(role, name)
+}
+
+// checkResourceName returns an error if role may not access the resource with the short name name.
+func checkResourceName(role config.AuthRoleConfig, name string) error {
+	if len(role.Resources) == 0 || slices.Contains(role.Resources, name) {
+		return nil
+	}
+	return status.Errorf(codes.PermissionDenied, "not allowed to access resource %q", name)
+}
+
+// withAccessCheck returns ctx with a check of the resources role may access, for RPCs naming
+// resources in their bodies.
+func withAccessCheck(ctx context.Context, role config.AuthRoleConfig) context.Context {
+	if len(role.Resources) == 0 {
+		return ctx
+	}
+	return resource.ContextWithAccessCheck(ctx, func(name string) error {
+		return checkResourceName(role, name)
+	})
+}
+
+// UnaryInterceptor rejects unary RPCs the calling entity's role does not allow.
+func (ac *accessControl) UnaryInterceptor(
+	ctx context.Context, req any, info *googlegrpc.UnaryServerInfo, handler googlegrpc.UnaryHandler,
+) (any, error) {
+	if role, ok := ac.roleFor(ctx); ok {
+		if err := ac.checkMethod(role, info.FullMethod); err != nil {
+			return nil, err
+		}
+		if err := ac.checkResource(role, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		ctx = withAccessCheck(ctx, role)
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor rejects streaming RPCs the calling entity's role does not allow. The resource
+// a stream is for is checked when its first message is received.
+func (ac *accessControl) StreamInterceptor(
+	srv any, ss googlegrpc.ServerStream, info *googlegrpc.StreamServerInfo, handler googlegrpc.StreamHandler,
+) error {
+	role, ok := ac.roleFor(ss.Context())
+	if !ok {
+		return handler(srv, ss)
+	}
+	if err := ac.checkMethod(role, info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, &accessCheckedStream{
+		ServerStream: ss,
+		ctx:          withAccessCheck(ss.Context(), role),
+		check: func(req any) error {
+			return ac.checkResource(role, info.FullMethod, req)
+		},
+	})
+}
+
+// accessCheckedStream checks the first message received on a stream.
+type accessCheckedStream struct {
+	googlegrpc.ServerStream
+	ctx     context.Context
+	check   func(req any) error
+	checked bool
+}
+
+func (s *accessCheckedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *accessCheckedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.checked {
+		return nil
+	}
+	s.checked = true
+	return s.check(m)
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"context"
+	"testing"
+
+	"github.com/viamrobotics/webrtc/v3"
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+)
+
+func TestUnmappedWebRTCCallsAreViewers(t *testing.T) {
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, pc.Close(), test.ShouldBeNil)
+	}()
+
+	mapper := newWebRTCEntityMapper()
+	ac := newAccessControl([]config.AuthRoleConfig{{Entity: "operator", Role: config.AuthRoleOperator}})
+	call := func(ctx context.Context, method string) error {
+		info := &googlegrpc.UnaryServerInfo{FullMethod: method}
+		_, err := mapper.UnaryInterceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			return ac.UnaryInterceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				return nil, nil
+			})
+		})
+		return err
+	}
+
+	// connections signaled through the cloud are attributed to the robot, which has no role.
+	ctx := rpc.ContextWithAuthEntity(context.Background(), rpc.EntityInfo{Entity: "robot.local"})
+	test.That(t, call(ctx, "/viam.component.base.v1.BaseService/SetPower"), test.ShouldBeNil)
+
+	ctx = rpc.ContextWithPeerConnection(ctx, pc)
+	test.That(t, call(ctx, "/viam.robot.v1.RobotService/ResourceNames"), test.ShouldBeNil)
+	err = call(ctx, "/viam.component.base.v1.BaseService/SetPower")
+	test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "the viewer role may not call")
+}
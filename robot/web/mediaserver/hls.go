@@ -0,0 +1,319 @@
+package mediaserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/pkg/formats/mpegts"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	// hlsSegmentDuration is the shortest a segment may be. Segments begin at IDRs, so they are
+	// often longer.
+	hlsSegmentDuration = time.Second
+	// hlsPlaylistSegments is how many segments are listed in playlists. A few more are kept for
+	// clients that are behind.
+	hlsPlaylistSegments = 5
+	hlsKeptSegments     = hlsPlaylistSegments + 3
+	// hlsIdleTimeout is how long a camera is segmented after its last request.
+	hlsIdleTimeout = 30 * time.Second
+	// hlsReadyTimeout is how long the first request for the playlist of a camera waits for its
+	// first segment.
+	hlsReadyTimeout = 20 * time.Second
+	// mpegtsTimeOffset is added to the timestamps of segments so that decode times before the
+	// first presentation time are not negative.
+	mpegtsTimeOffset = time.Second
+	hlsPlaylistFile  = "index.m3u8"
+	hlsRealm         = "viam"
+)
+
+// hlsServer serves each camera at http://<address>/<camera>/index.m3u8 as a live playlist of
+// MPEG-TS segments.
+type hlsServer struct {
+	s          *Server
+	listener   net.Listener
+	httpServer *http.Server
+
+	mu     sync.Mutex
+	muxers map[string]*hlsMuxer
+}
+
+func newHLSServer(s *Server, address string) (*hlsServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	h := &hlsServer{s: s, listener: listener, muxers: map[string]*hlsMuxer{}}
+	h.httpServer = &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+	s.workers.Add(1)
+	utils.ManagedGo(func() {
+		if err := h.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Errorw("error serving HLS", "error", err)
+		}
+	}, s.workers.Done)
+	return h, nil
+}
+
+func (h *hlsServer) close() error {
+	return h.httpServer.Close()
+}
+
+// ServeHTTP serves the playlists and segments of cameras.
+func (h *hlsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, pass, _ := r.BasicAuth()
+	if !h.s.authorized(user, pass) {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", hlsRealm))
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	// camera names of remotes have colons but no slashes, so the file is after the last slash.
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		http.NotFound(w, r)
+		return
+	}
+	name, file := path[:i], path[i+1:]
+	if file != hlsPlaylistFile && !strings.HasSuffix(file, ".ts") {
+		http.NotFound(w, r)
+		return
+	}
+
+	m, err := h.muxer(name)
+	if err != nil {
+		if resource.IsNotFoundError(err) {
+			http.NotFound(w, r)
+			return
+		}
+		h.s.logger.Warnw("failed to stream camera over HLS", "camera", name, "error", err)
+		http.Error(w, "camera unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	if file == hlsPlaylistFile {
+		ctx, cancel := context.WithTimeout(r.Context(), hlsReadyTimeout)
+		defer cancel()
+		select {
+		case <-m.ready:
+		case <-m.done:
+			http.Error(w, "camera unavailable", http.StatusServiceUnavailable)
+			return
+		case <-ctx.Done():
+			http.Error(w, "camera stream not ready", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+		w.Header().Set("Cache-Control", "no-cache")
+		_, err := w.Write(m.playlist())
+		utils.UncheckedError(err)
+		return
+	}
+
+	seq, err := strconv.Atoi(strings.TrimSuffix(file, ".ts"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	data, ok := m.segment(seq)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "video/MP2T")
+	_, err = w.Write(data)
+	utils.UncheckedError(err)
+}
+
+// muxer returns the muxer of the camera of the given name, starting it if needed.
+func (h *hlsServer) muxer(name string) (*hlsMuxer, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if m, ok := h.muxers[name]; ok {
+		m.touch()
+		return m, nil
+	}
+	sub, err := h.s.subscribe(name)
+	if err != nil {
+		return nil, err
+	}
+	m := &hlsMuxer{
+		name:        name,
+		lastRequest: time.Now(),
+		ready:       make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	h.muxers[name] = m
+	h.s.workers.Add(1)
+	utils.ManagedGo(func() {
+		defer func() {
+			h.mu.Lock()
+			if h.muxers[name] == m {
+				delete(h.muxers, name)
+			}
+			h.mu.Unlock()
+			close(m.done)
+			h.s.unsubscribe(sub)
+		}()
+		m.run(h.s.closedCtx, sub)
+	}, h.s.workers.Done)
+	return m, nil
+}
+
+// hlsSegment is an MPEG-TS segment of a camera stream.
+type hlsSegment struct {
+	seq      int
+	duration time.Duration
+	data     []byte
+}
+
+// hlsMuxer segments the stream of a camera while its playlist and segments are requested.
+type hlsMuxer struct {
+	name string
+	// ready is closed once the first segment is complete, and done once the muxer stops.
+	ready chan struct{}
+	done  chan struct{}
+
+	mu          sync.Mutex
+	segments    []*hlsSegment
+	lastRequest time.Time
+
+	// the segment being written, only used by run.
+	buf          *bytes.Buffer
+	writer       *mpegts.Writer
+	track        *mpegts.Track
+	segmentStart time.Duration
+	nextSeq      int
+	ptsOffset    time.Duration
+	dtsExtractor *h264.DTSExtractor
+}
+
+func (m *hlsMuxer) touch() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastRequest = time.Now()
+}
+
+// run segments the access units of sub until ctx is done, the camera can no longer be read, or
+// nothing has been requested of the muxer for a while.
+func (m *hlsMuxer) run(ctx context.Context, sub *subscription) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sub.done:
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			idle := time.Since(m.lastRequest) > hlsIdleTimeout
+			m.mu.Unlock()
+			if idle {
+				return
+			}
+		case au := <-sub.c:
+			m.write(au)
+		}
+	}
+}
+
+// write adds an access unit to the current segment, first completing it if the access unit is an
+// IDR that can begin the next one.
+func (m *hlsMuxer) write(au *accessUnit) {
+	if m.dtsExtractor == nil {
+		if !au.idr {
+			return
+		}
+		m.dtsExtractor = h264.NewDTSExtractor()
+		m.ptsOffset = au.pts
+	}
+	pts := au.pts - m.ptsOffset
+	if au.idr && m.buf != nil && pts-m.segmentStart >= hlsSegmentDuration {
+		m.completeSegment(pts)
+	}
+	if m.buf == nil {
+		m.buf = &bytes.Buffer{}
+		m.track = &mpegts.Track{Codec: &mpegts.CodecH264{}}
+		m.writer = mpegts.NewWriter(m.buf, []*mpegts.Track{m.track})
+		m.segmentStart = pts
+	}
+	dts, err := m.dtsExtractor.Extract(au.nalus, pts)
+	if err != nil {
+		// streams without B-frames decode in presentation order.
+		dts = pts
+	}
+	if err := m.writer.WriteH26x(m.track, mpegtsTicks(pts), mpegtsTicks(dts), au.idr, au.nalus); err != nil {
+		m.buf = nil
+	}
+}
+
+// completeSegment adds the current segment, which ends at the given time, to the playlist.
+func (m *hlsMuxer) completeSegment(end time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.segments = append(m.segments, &hlsSegment{seq: m.nextSeq, duration: end - m.segmentStart, data: m.buf.Bytes()})
+	if len(m.segments) > hlsKeptSegments {
+		m.segments = m.segments[len(m.segments)-hlsKeptSegments:]
+	}
+	if m.nextSeq == 0 {
+		close(m.ready)
+	}
+	m.nextSeq++
+	m.buf = nil
+}
+
+// mpegtsTicks converts a time of a stream to the 90kHz clock of MPEG-TS.
+func mpegtsTicks(d time.Duration) int64 {
+	d += mpegtsTimeOffset
+	return int64(d/time.Second)*h264ClockRate + int64(d%time.Second)*h264ClockRate/int64(time.Second)
+}
+
+// playlist returns the live playlist of the latest segments.
+func (m *hlsMuxer) playlist() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	listed := m.segments
+	if len(listed) > hlsPlaylistSegments {
+		listed = listed[len(listed)-hlsPlaylistSegments:]
+	}
+	targetDuration := 1
+	for _, seg := range listed {
+		targetDuration = max(targetDuration, int(math.Ceil(seg.duration.Seconds())))
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:%d\n#EXT-X-MEDIA-SEQUENCE:%d\n",
+		targetDuration, listed[0].seq)
+	for _, seg := range listed {
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%d.ts\n", seg.duration.Seconds(), seg.seq)
+	}
+	return b.Bytes()
+}
+
+// segment returns the data of the segment with the given sequence number, if it is still kept.
+func (m *hlsMuxer) segment(seq int) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, seg := range m.segments {
+		if seg.seq == seq {
+			return seg.data, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,305 @@
+// Package mediaserver re-publishes the streams of a robot's cameras over RTSP, RTMP and HLS, so
+// that they can be fed to NVRs and streaming infrastructure that have no WebRTC client. Cameras that
+// provide H264 over RTP passthrough are re-published as they are, while the images of other cameras
+// are encoded to H264 with the video encoder of the web service, when it has one.
+//
+// A camera is only read while it has viewers, and each camera is read once however many viewers it
+// has over however many protocols.
+package mediaserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/gostream/codec"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+const (
+	defaultFrameRate = 10
+	// subscriptionBufferSize is how many access units a viewer may fall behind before it misses
+	// frames and waits for the next IDR.
+	subscriptionBufferSize = 30
+)
+
+// Server serves the streams of cameras over the protocols configured.
+type Server struct {
+	robot    robot.Robot
+	conf     config.MediaServerConfig
+	allowed  map[string]bool
+	encoders codec.VideoEncoderFactory
+	logger   logging.Logger
+
+	closedCtx context.Context
+	closedFn  context.CancelFunc
+	workers   sync.WaitGroup
+
+	mu      sync.Mutex
+	streams map[string]*stream
+
+	rtsp *rtspServer
+	rtmp *rtmpServer
+	hls  *hlsServer
+}
+
+// NewServer starts serving the streams of the cameras of the robot over the protocols that conf has
+// addresses for. encoders, which may be nil, encodes the images of cameras that do not provide H264
+// over RTP passthrough.
+func NewServer(
+	r robot.Robot,
+	conf config.MediaServerConfig,
+	encoders codec.VideoEncoderFactory,
+	logger logging.Logger,
+) (*Server, error) {
+	closedCtx, closedFn := context.WithCancel(context.Background())
+	s := &Server{
+		robot:     r,
+		conf:      conf,
+		encoders:  encoders,
+		logger:    logger,
+		closedCtx: closedCtx,
+		closedFn:  closedFn,
+		streams:   map[string]*stream{},
+	}
+	if s.conf.FrameRate == 0 {
+		s.conf.FrameRate = defaultFrameRate
+	}
+	if len(conf.Cameras) != 0 {
+		s.allowed = map[string]bool{}
+		for _, name := range conf.Cameras {
+			s.allowed[name] = true
+		}
+	}
+	if conf.Username == "" && conf.AllowUnauthenticated {
+		logger.Warn("media server does not require authentication; any client that can reach it can view the cameras")
+	}
+
+	var err error
+	if conf.RTSPAddress != "" {
+		if s.rtsp, err = newRTSPServer(s, conf.RTSPAddress); err != nil {
+			return nil, multierr.Combine(errors.Wrap(err, "failed to serve RTSP"), s.Close())
+		}
+		logger.Infow("serving camera streams over RTSP", "address", conf.RTSPAddress)
+	}
+	if conf.RTMPAddress != "" {
+		if s.rtmp, err = newRTMPServer(s, conf.RTMPAddress); err != nil {
+			return nil, multierr.Combine(errors.Wrap(err, "failed to serve RTMP"), s.Close())
+		}
+		logger.Infow("serving camera streams over RTMP", "address", conf.RTMPAddress)
+	}
+	if conf.HLSAddress != "" {
+		if s.hls, err = newHLSServer(s, conf.HLSAddress); err != nil {
+			return nil, multierr.Combine(errors.Wrap(err, "failed to serve HLS"), s.Close())
+		}
+		logger.Infow("serving camera streams over HLS", "address", conf.HLSAddress)
+	}
+	return s, nil
+}
+
+// Close stops serving and reading the cameras.
+func (s *Server) Close() error {
+	s.closedFn()
+	var err error
+	if s.rtsp != nil {
+		s.rtsp.close()
+	}
+	if s.rtmp != nil {
+		err = multierr.Combine(err, s.rtmp.close())
+	}
+	if s.hls != nil {
+		err = multierr.Combine(err, s.hls.close())
+	}
+	s.workers.Wait()
+	return err
+}
+
+// authorized returns whether a client may view the cameras with the credentials given. Without a
+// username configured, no client is unless unauthenticated access was explicitly allowed.
+func (s *Server) authorized(user, pass string) bool {
+	if s.conf.Username == "" {
+		return s.conf.AllowUnauthenticated
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(s.conf.Username)) == 1
+	passOK := subtle.ConstantTimeCompare([]byte(pass), []byte(s.conf.Password)) == 1
+	return userOK && passOK
+}
+
+// accessUnit is an H264 access unit of a camera stream. It is shared by all the viewers of the
+// stream and must not be modified.
+type accessUnit struct {
+	nalus [][]byte
+	// pts is the presentation time of the access unit since the camera began to be read.
+	pts time.Duration
+	idr bool
+}
+
+// stream reads a camera for its subscriptions.
+type stream struct {
+	name   string
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	subs     map[*subscription]struct{}
+	sps, pps []byte
+	ended    bool
+}
+
+// subscription receives the access units of a stream from its next IDR on.
+type subscription struct {
+	stream *stream
+	c      chan *accessUnit
+	// done is closed when the camera can no longer be read.
+	done chan struct{}
+	// needIDR is whether the subscription has missed access units since its last IDR, guarded by
+	// the mutex of the stream.
+	needIDR bool
+}
+
+// subscribe begins reading the camera of the given name for a viewer, unless it is already being read.
+func (s *Server) subscribe(name string) (*subscription, error) {
+	if s.allowed != nil && !s.allowed[name] {
+		return nil, resource.NewNotFoundError(camera.Named(name))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closedCtx.Err() != nil {
+		return nil, errors.New("media server is closed")
+	}
+	st := s.streams[name]
+	if st != nil {
+		st.mu.Lock()
+		if st.ended {
+			st = nil
+		}
+		st.mu.Unlock()
+	}
+	if st == nil {
+		cam, err := camera.FromRobot(s.robot, name)
+		if err != nil {
+			return nil, err
+		}
+		ctx, cancel := context.WithCancel(s.closedCtx)
+		st = &stream{name: name, cancel: cancel, subs: map[*subscription]struct{}{}}
+		if err := s.startSource(ctx, st, cam); err != nil {
+			cancel()
+			return nil, err
+		}
+		s.streams[name] = st
+	}
+
+	sub := &subscription{stream: st, c: make(chan *accessUnit, subscriptionBufferSize), done: make(chan struct{}), needIDR: true}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.ended {
+		close(sub.done)
+	}
+	st.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// unsubscribe stops sending access units to sub, and stops reading its camera if it was the last
+// subscription to it.
+func (s *Server) unsubscribe(sub *subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := sub.stream
+	st.mu.Lock()
+	delete(st.subs, sub)
+	last := len(st.subs) == 0
+	st.mu.Unlock()
+	if last {
+		st.cancel()
+		if s.streams[st.name] == st {
+			delete(s.streams, st.name)
+		}
+	}
+}
+
+// publish sends an access unit to the subscriptions of the stream. Subscriptions that have fallen
+// behind miss it, and then only receive access units again from the next IDR on.
+func (st *stream) publish(au *accessUnit) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	hasParams := false
+	for _, nalu := range au.nalus {
+		switch naluType(nalu) {
+		case h264.NALUTypeSPS:
+			st.sps, hasParams = nalu, true
+		case h264.NALUTypePPS:
+			st.pps = nalu
+		default:
+		}
+	}
+	// some sources only send their parameter sets once, but viewers join at any IDR.
+	if au.idr && !hasParams && st.sps != nil && st.pps != nil {
+		au.nalus = append([][]byte{st.sps, st.pps}, au.nalus...)
+	}
+	for sub := range st.subs {
+		if sub.needIDR && !au.idr {
+			continue
+		}
+		select {
+		case sub.c <- au:
+			sub.needIDR = false
+		default:
+			sub.needIDR = true
+		}
+	}
+}
+
+// end marks the stream as no longer readable, ending its subscriptions.
+func (st *stream) end() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.ended {
+		return
+	}
+	st.ended = true
+	for sub := range st.subs {
+		close(sub.done)
+	}
+}
+
+// nextAccessUnit waits for the next access unit of sub, returning false if ctx is done or the camera
+// can no longer be read.
+func (sub *subscription) nextAccessUnit(ctx context.Context) (*accessUnit, bool) {
+	select {
+	case au := <-sub.c:
+		return au, true
+	case <-sub.done:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// parameterSets returns the SPS and PPS of an access unit, if it has them.
+func parameterSets(au *accessUnit) (sps, pps []byte) {
+	for _, nalu := range au.nalus {
+		switch naluType(nalu) {
+		case h264.NALUTypeSPS:
+			sps = nalu
+		case h264.NALUTypePPS:
+			pps = nalu
+		default:
+		}
+	}
+	return sps, pps
+}
+
+func naluType(nalu []byte) h264.NALUType {
+	if len(nalu) == 0 {
+		return 0
+	}
+	return h264.NALUType(nalu[0] & 0x1f)
+}
@@ -0,0 +1,279 @@
+package mediaserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bluenviron/gortmplib"
+	"github.com/bluenviron/gortmplib/pkg/codecs"
+	"github.com/bluenviron/gortsplib/v4"
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/pion/rtp"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/fake"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// newTestServer serves a fake camera named cam, which provides H264 over RTP passthrough, and a
+// fake camera named still, which does not, over the protocols that conf has addresses for.
+func newTestServer(t *testing.T, conf config.MediaServerConfig) *Server {
+	t.Helper()
+	logger := logging.NewTestLogger(t)
+	cams := map[resource.Name]resource.Resource{}
+	for name, passthrough := range map[string]bool{"cam": true, "still": false} {
+		cam, err := fake.NewCamera(context.Background(), nil, resource.Config{
+			Name:                name,
+			API:                 camera.API,
+			ConvertedAttributes: &fake.Config{RTPPassthrough: passthrough},
+		}, logger)
+		test.That(t, err, test.ShouldBeNil)
+		t.Cleanup(func() { test.That(t, cam.Close(context.Background()), test.ShouldBeNil) })
+		cams[camera.Named(name)] = cam
+	}
+	r := &inject.Robot{}
+	r.ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		if res, ok := cams[name]; ok {
+			return res, nil
+		}
+		return nil, resource.NewNotFoundError(name)
+	}
+
+	s, err := NewServer(r, conf, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { test.That(t, s.Close(), test.ShouldBeNil) })
+	return s
+}
+
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	port, err := utils.TryReserveRandomPort()
+	test.That(t, err, test.ShouldBeNil)
+	return fmt.Sprintf("localhost:%d", port)
+}
+
+// waitForNoStreams waits for the server to stop reading cameras.
+func waitForNoStreams(t *testing.T, s *Server) {
+	t.Helper()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		test.That(tb, s.streams, test.ShouldBeEmpty)
+	})
+}
+
+func TestSubscribe(t *testing.T) {
+	s := newTestServer(t, config.MediaServerConfig{Cameras: []string{"cam", "still"}})
+
+	sub, err := s.subscribe("cam")
+	test.That(t, err, test.ShouldBeNil)
+	au, ok := sub.nextAccessUnit(context.Background())
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, au.idr, test.ShouldBeTrue)
+	sps, pps := parameterSets(au)
+	test.That(t, sps, test.ShouldNotBeNil)
+	test.That(t, pps, test.ShouldNotBeNil)
+
+	// viewers of a camera share its stream.
+	other, err := s.subscribe("cam")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, other.stream, test.ShouldEqual, sub.stream)
+	s.unsubscribe(sub)
+	s.mu.Lock()
+	test.That(t, s.streams, test.ShouldContainKey, "cam")
+	s.mu.Unlock()
+	s.unsubscribe(other)
+	waitForNoStreams(t, s)
+
+	// a camera without H264 of its own needs an encoder.
+	_, err = s.subscribe("still")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no video encoder")
+
+	_, err = s.subscribe("missing")
+	test.That(t, resource.IsNotFoundError(err), test.ShouldBeTrue)
+	_, err = newTestServer(t, config.MediaServerConfig{Cameras: []string{"still"}}).subscribe("cam")
+	test.That(t, resource.IsNotFoundError(err), test.ShouldBeTrue)
+}
+
+func TestAuthorized(t *testing.T) {
+	s := &Server{conf: config.MediaServerConfig{Username: "viewer", Password: "secret"}}
+	test.That(t, s.authorized("viewer", "secret"), test.ShouldBeTrue)
+	test.That(t, s.authorized("viewer", "wrong"), test.ShouldBeFalse)
+	test.That(t, s.authorized("", ""), test.ShouldBeFalse)
+
+	// without credentials, clients are only let in when that was explicitly allowed.
+	s = &Server{conf: config.MediaServerConfig{}}
+	test.That(t, s.authorized("", ""), test.ShouldBeFalse)
+	s = &Server{conf: config.MediaServerConfig{AllowUnauthenticated: true}}
+	test.That(t, s.authorized("", ""), test.ShouldBeTrue)
+}
+
+func TestRTSP(t *testing.T) {
+	address := freeAddress(t)
+	s := newTestServer(t, config.MediaServerConfig{RTSPAddress: address, Username: "viewer", Password: "secret"})
+
+	transport := gortsplib.TransportTCP
+	describe := func(url string) (*gortsplib.Client, *description.Session, error) {
+		u, err := base.ParseURL(url)
+		test.That(t, err, test.ShouldBeNil)
+		c := &gortsplib.Client{Transport: &transport}
+		test.That(t, c.Start(u.Scheme, u.Host), test.ShouldBeNil)
+		desc, _, err := c.Describe(u)
+		if err != nil {
+			c.Close()
+			return nil, nil, err
+		}
+		return c, desc, nil
+	}
+
+	_, _, err := describe("rtsp://" + address + "/cam")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "401")
+	_, _, err = describe("rtsp://viewer:wrong@" + address + "/cam")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = describe("rtsp://viewer:secret@" + address + "/missing")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "404")
+
+	c, desc, err := describe("rtsp://viewer:secret@" + address + "/cam")
+	test.That(t, err, test.ShouldBeNil)
+	defer c.Close()
+	test.That(t, desc.Medias, test.ShouldHaveLength, 1)
+	var forma *format.H264
+	test.That(t, desc.FindFormat(&forma), test.ShouldNotBeNil)
+
+	test.That(t, c.SetupAll(desc.BaseURL, desc.Medias), test.ShouldBeNil)
+	received := make(chan struct{}, 1)
+	c.OnPacketRTPAny(func(_ *description.Media, _ format.Format, _ *rtp.Packet) {
+		select {
+		case received <- struct{}{}:
+		default:
+		}
+	})
+	_, err = c.Play(nil)
+	test.That(t, err, test.ShouldBeNil)
+	select {
+	case <-received:
+	case <-time.After(10 * time.Second):
+		t.Fatal("no RTP packets received")
+	}
+
+	// the camera is no longer read once its last session closes.
+	c.Close()
+	waitForNoStreams(t, s)
+}
+
+func TestHLS(t *testing.T) {
+	address := freeAddress(t)
+	s := newTestServer(t, config.MediaServerConfig{HLSAddress: address, Username: "viewer", Password: "secret"})
+
+	get := func(path string, authenticate bool) (int, []byte) {
+		req, err := http.NewRequest(http.MethodGet, "http://"+address+path, nil)
+		test.That(t, err, test.ShouldBeNil)
+		if authenticate {
+			req.SetBasicAuth("viewer", "secret")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		test.That(t, err, test.ShouldBeNil)
+		defer func() { test.That(t, resp.Body.Close(), test.ShouldBeNil) }()
+		body, err := io.ReadAll(resp.Body)
+		test.That(t, err, test.ShouldBeNil)
+		return resp.StatusCode, body
+	}
+
+	status, _ := get("/cam/index.m3u8", false)
+	test.That(t, status, test.ShouldEqual, http.StatusUnauthorized)
+	status, _ = get("/missing/index.m3u8", true)
+	test.That(t, status, test.ShouldEqual, http.StatusNotFound)
+
+	// the playlist is served once the first segment is complete.
+	status, playlist := get("/cam/index.m3u8", true)
+	test.That(t, status, test.ShouldEqual, http.StatusOK)
+	test.That(t, string(playlist), test.ShouldStartWith, "#EXTM3U\n")
+	test.That(t, string(playlist), test.ShouldContainSubstring, "#EXT-X-MEDIA-SEQUENCE:0\n")
+	test.That(t, string(playlist), test.ShouldContainSubstring, "\n0.ts\n")
+
+	status, segment := get("/cam/0.ts", true)
+	test.That(t, status, test.ShouldEqual, http.StatusOK)
+	test.That(t, len(segment), test.ShouldBeGreaterThan, 0)
+	// segments are whole MPEG-TS packets.
+	test.That(t, len(segment)%188, test.ShouldEqual, 0)
+	test.That(t, segment[0], test.ShouldEqual, 0x47)
+	status, _ = get("/cam/1000.ts", true)
+	test.That(t, status, test.ShouldEqual, http.StatusNotFound)
+
+	test.That(t, s.Close(), test.ShouldBeNil)
+	waitForNoStreams(t, s)
+}
+
+// rtmpRead connects to an RTMP server and plays a stream, returning a reader of it.
+func rtmpRead(t *testing.T, rawURL string) (*gortmplib.Client, *gortmplib.Reader, error) {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	test.That(t, err, test.ShouldBeNil)
+	c := &gortmplib.Client{URL: u}
+	test.That(t, c.Initialize(context.Background()), test.ShouldBeNil)
+	t.Cleanup(c.Close)
+	test.That(t, c.NetConn().SetReadDeadline(time.Now().Add(10*time.Second)), test.ShouldBeNil)
+	r := &gortmplib.Reader{Conn: c}
+	return c, r, r.Initialize()
+}
+
+func TestRTMP(t *testing.T) {
+	address := freeAddress(t)
+	s := newTestServer(t, config.MediaServerConfig{RTMPAddress: address, Username: "viewer", Password: "secret"})
+
+	// the connection is closed before anything is played.
+	_, _, err := rtmpRead(t, "rtmp://"+address+"/live/cam")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = rtmpRead(t, "rtmp://"+address+"/live/missing?user=viewer&pass=secret")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	for _, path := range []string{"/live/cam", "/cam"} {
+		c, r, err := rtmpRead(t, "rtmp://"+address+path+"?user=viewer&pass=secret")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, r.Tracks(), test.ShouldHaveLength, 1)
+		track := r.Tracks()[0]
+		h264Codec, ok := track.Codec.(*codecs.H264)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, h264Codec.SPS, test.ShouldNotBeNil)
+		test.That(t, h264Codec.PPS, test.ShouldNotBeNil)
+
+		// no frames are played before the first key frame.
+		var keyFrame, nonKeyFrame bool
+		r.OnDataH264(track, func(_, _ time.Duration, au [][]byte) {
+			if h264.IDRPresent(au) {
+				keyFrame = true
+			}
+			for _, nalu := range au {
+				if !keyFrame && h264.NALUType(nalu[0]&0x1f) == h264.NALUTypeNonIDR {
+					nonKeyFrame = true
+				}
+			}
+		})
+		for !keyFrame {
+			test.That(t, r.Read(), test.ShouldBeNil)
+		}
+		test.That(t, nonKeyFrame, test.ShouldBeFalse)
+
+		// the camera is no longer read once the client leaves.
+		c.Close()
+		waitForNoStreams(t, s)
+	}
+}
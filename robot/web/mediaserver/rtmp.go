@@ -0,0 +1,172 @@
+package mediaserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gortmplib"
+	"github.com/bluenviron/gortmplib/pkg/codecs"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	// rtmpSetupTimeout is how long a client has to connect and request a stream to play.
+	rtmpSetupTimeout = 10 * time.Second
+	rtmpWriteTimeout = 10 * time.Second
+)
+
+// rtmpServer serves each camera at rtmp://<address>/live/<camera> to clients that play it. Since
+// RTMP has no authentication of its own, credentials are given as the user and pass query
+// parameters of the stream name, as in rtmp://<address>/live/<camera>?user=<user>&pass=<pass>.
+type rtmpServer struct {
+	s        *Server
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func newRTMPServer(s *Server, address string) (*rtmpServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	r := &rtmpServer{s: s, listener: listener, conns: map[net.Conn]struct{}{}}
+	s.workers.Add(1)
+	utils.ManagedGo(r.accept, s.workers.Done)
+	return r, nil
+}
+
+func (r *rtmpServer) close() error {
+	err := r.listener.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for conn := range r.conns {
+		utils.UncheckedError(conn.Close())
+	}
+	return err
+}
+
+func (r *rtmpServer) accept() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				r.s.logger.Errorw("error accepting RTMP connection", "error", err)
+			}
+			return
+		}
+		r.mu.Lock()
+		r.conns[conn] = struct{}{}
+		r.mu.Unlock()
+		r.s.workers.Add(1)
+		utils.ManagedGo(func() {
+			defer func() {
+				r.mu.Lock()
+				delete(r.conns, conn)
+				r.mu.Unlock()
+				utils.UncheckedError(conn.Close())
+			}()
+			if err := r.serve(conn); err != nil {
+				r.s.logger.Debugw("RTMP connection ended", "remote", conn.RemoteAddr().String(), "error", err)
+			}
+		}, r.s.workers.Done)
+	}
+}
+
+// serve plays the camera a client asks for until the client leaves or the camera can no longer be read.
+func (r *rtmpServer) serve(conn net.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(rtmpSetupTimeout)); err != nil {
+		return err
+	}
+	sc := &gortmplib.ServerConn{RW: conn}
+	if err := sc.Initialize(); err != nil {
+		return errors.Wrap(err, "failed to set up connection")
+	}
+	if err := sc.Accept(); err != nil {
+		return errors.Wrap(err, "failed to set up connection")
+	}
+	if sc.Publish {
+		return errors.New("publishing is not supported")
+	}
+	query := sc.URL.Query()
+	if !r.s.authorized(query.Get("user"), query.Get("pass")) {
+		return errors.New("unauthorized")
+	}
+	// rtmp://<address>/<camera> has the camera as the app and no stream name, and camera names of
+	// remotes have colons but no slashes.
+	name := sc.URL.Path[strings.LastIndex(sc.URL.Path, "/")+1:]
+	sub, err := r.s.subscribe(name)
+	if err != nil {
+		if !resource.IsNotFoundError(err) {
+			r.s.logger.Warnw("failed to stream camera over RTMP", "camera", name, "error", err)
+		}
+		return err
+	}
+	defer r.s.unsubscribe(sub)
+
+	// the client sends acknowledgements and commands while it plays, which are read to notice it leaving.
+	ctx, cancel := context.WithCancel(r.s.closedCtx)
+	defer cancel()
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return err
+	}
+	r.s.workers.Add(1)
+	utils.ManagedGo(func() {
+		defer cancel()
+		_, err := io.Copy(io.Discard, conn)
+		utils.UncheckedError(err)
+	}, r.s.workers.Done)
+	return playVideo(ctx, conn, sc, sub)
+}
+
+// playVideo sends the access units of sub to the client of sc until ctx is done. FLV sends the
+// parameter sets ahead of the frames of a stream, so nothing is sent before the first IDR access
+// unit after they are known.
+func playVideo(ctx context.Context, conn net.Conn, sc *gortmplib.ServerConn, sub *subscription) error {
+	var w *gortmplib.Writer
+	var track *gortmplib.Track
+	var sps, pps []byte
+	var start time.Duration
+	for {
+		au, ok := sub.nextAccessUnit(ctx)
+		if !ok {
+			return nil
+		}
+		if w == nil {
+			auSPS, auPPS := parameterSets(au)
+			if auSPS != nil {
+				sps = auSPS
+			}
+			if auPPS != nil {
+				pps = auPPS
+			}
+			if !au.idr || sps == nil || pps == nil {
+				continue
+			}
+			track = &gortmplib.Track{Codec: &codecs.H264{SPS: sps, PPS: pps}}
+			w = &gortmplib.Writer{Conn: sc, Tracks: []*gortmplib.Track{track}}
+			if err := conn.SetWriteDeadline(time.Now().Add(rtmpWriteTimeout)); err != nil {
+				return err
+			}
+			if err := w.Initialize(); err != nil {
+				return err
+			}
+			start = au.pts
+		}
+		if err := conn.SetWriteDeadline(time.Now().Add(rtmpWriteTimeout)); err != nil {
+			return err
+		}
+		pts := au.pts - start
+		if err := w.WriteH264(track, pts, pts, au.nalus); err != nil {
+			return err
+		}
+	}
+}
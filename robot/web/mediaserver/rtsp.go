@@ -0,0 +1,241 @@
+package mediaserver
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4"
+	"github.com/bluenviron/gortsplib/v4/pkg/auth"
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtph264"
+	"github.com/bluenviron/gortsplib/v4/pkg/rtptime"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	rtspRealm       = "viam"
+	h264PayloadType = 96
+)
+
+// rtspServer serves each camera at rtsp://<address>/<camera> over TCP.
+type rtspServer struct {
+	s      *Server
+	server *gortsplib.Server
+	nonce  string
+
+	mu       sync.Mutex
+	paths    map[string]*rtspPath
+	sessions map[*gortsplib.ServerSession]*rtspPath
+}
+
+// rtspPath is the RTSP stream of a camera. The camera is read while the stream has sessions.
+type rtspPath struct {
+	name     string
+	stream   *gortsplib.ServerStream
+	media    *description.Media
+	sessions int
+	cancel   context.CancelFunc
+}
+
+func newRTSPServer(s *Server, address string) (*rtspServer, error) {
+	nonce, err := auth.GenerateNonce()
+	if err != nil {
+		return nil, err
+	}
+	r := &rtspServer{
+		s:        s,
+		nonce:    nonce,
+		paths:    map[string]*rtspPath{},
+		sessions: map[*gortsplib.ServerSession]*rtspPath{},
+	}
+	r.server = &gortsplib.Server{Handler: r, RTSPAddress: address}
+	if err := r.server.Start(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rtspServer) close() {
+	r.server.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.paths {
+		if p.cancel != nil {
+			p.cancel()
+		}
+		p.stream.Close()
+	}
+	r.paths = map[string]*rtspPath{}
+}
+
+// authenticate returns a response asking for credentials if the request does not have valid ones.
+func (r *rtspServer) authenticate(req *base.Request) *base.Response {
+	if r.s.conf.Username == "" {
+		if r.s.conf.AllowUnauthenticated {
+			return nil
+		}
+		return &base.Response{StatusCode: base.StatusUnauthorized}
+	}
+	if err := auth.Validate(req, r.s.conf.Username, r.s.conf.Password, nil, nil, rtspRealm, r.nonce); err != nil {
+		return &base.Response{
+			StatusCode: base.StatusUnauthorized,
+			Header: base.Header{
+				"WWW-Authenticate": auth.GenerateWWWAuthenticate(nil, rtspRealm, r.nonce),
+			},
+		}
+	}
+	return nil
+}
+
+// path returns the RTSP stream of the camera at the path of a request, creating it if needed.
+func (r *rtspServer) path(reqPath string) (*rtspPath, *base.Response) {
+	name := strings.Trim(reqPath, "/")
+	if r.s.allowed != nil && !r.s.allowed[name] {
+		return nil, &base.Response{StatusCode: base.StatusNotFound}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p, ok := r.paths[name]; ok {
+		return p, nil
+	}
+	if _, err := camera.FromRobot(r.s.robot, name); err != nil {
+		return nil, &base.Response{StatusCode: base.StatusNotFound}
+	}
+	media := &description.Media{
+		Type:    description.MediaTypeVideo,
+		Formats: []format.Format{&format.H264{PayloadTyp: h264PayloadType, PacketizationMode: 1}},
+	}
+	p := &rtspPath{
+		name:   name,
+		stream: gortsplib.NewServerStream(r.server, &description.Session{Medias: []*description.Media{media}}),
+		media:  media,
+	}
+	r.paths[name] = p
+	return p, nil
+}
+
+// OnDescribe describes the stream of a camera.
+func (r *rtspServer) OnDescribe(ctx *gortsplib.ServerHandlerOnDescribeCtx) (*base.Response, *gortsplib.ServerStream, error) {
+	if res := r.authenticate(ctx.Request); res != nil {
+		return res, nil, nil
+	}
+	p, res := r.path(ctx.Path)
+	if res != nil {
+		return res, nil, nil
+	}
+	return &base.Response{StatusCode: base.StatusOK}, p.stream, nil
+}
+
+// OnSetup adds a session to the stream of a camera, beginning to read the camera if it is the first.
+func (r *rtspServer) OnSetup(ctx *gortsplib.ServerHandlerOnSetupCtx) (*base.Response, *gortsplib.ServerStream, error) {
+	if res := r.authenticate(ctx.Request); res != nil {
+		return res, nil, nil
+	}
+	p, res := r.path(ctx.Path)
+	if res != nil {
+		return res, nil, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[ctx.Session]; ok {
+		return &base.Response{StatusCode: base.StatusOK}, p.stream, nil
+	}
+	if p.sessions == 0 {
+		sub, err := r.s.subscribe(p.name)
+		if err != nil {
+			if resource.IsNotFoundError(err) {
+				return &base.Response{StatusCode: base.StatusNotFound}, nil, nil
+			}
+			r.s.logger.Warnw("failed to stream camera over RTSP", "camera", p.name, "error", err)
+			return &base.Response{StatusCode: base.StatusServiceUnavailable}, nil, nil
+		}
+		var forwardCtx context.Context
+		forwardCtx, p.cancel = context.WithCancel(r.s.closedCtx)
+		r.s.workers.Add(1)
+		utils.ManagedGo(func() {
+			r.forward(forwardCtx, p, sub)
+		}, r.s.workers.Done)
+	}
+	p.sessions++
+	r.sessions[ctx.Session] = p
+	return &base.Response{StatusCode: base.StatusOK}, p.stream, nil
+}
+
+// OnPlay starts sending the stream set up to a session.
+func (r *rtspServer) OnPlay(ctx *gortsplib.ServerHandlerOnPlayCtx) (*base.Response, error) {
+	if res := r.authenticate(ctx.Request); res != nil {
+		return res, nil
+	}
+	return &base.Response{StatusCode: base.StatusOK}, nil
+}
+
+// OnSessionClose removes a session from its stream, no longer reading the camera if it was the last.
+func (r *rtspServer) OnSessionClose(ctx *gortsplib.ServerHandlerOnSessionCloseCtx) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.sessions[ctx.Session]
+	if !ok {
+		return
+	}
+	delete(r.sessions, ctx.Session)
+	p.sessions--
+	if p.sessions == 0 && p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
+// forward writes the access units of sub to the stream of p until ctx is done. If the camera can
+// no longer be read, the stream is closed, which ends its sessions.
+func (r *rtspServer) forward(ctx context.Context, p *rtspPath, sub *subscription) {
+	defer r.s.unsubscribe(sub)
+	encoder := &rtph264.Encoder{PayloadType: h264PayloadType}
+	if err := encoder.Init(); err != nil {
+		r.s.logger.Errorw("failed to create RTP encoder", "error", err)
+		return
+	}
+	clock := &rtptime.Encoder{ClockRate: h264ClockRate}
+	if err := clock.Initialize(); err != nil {
+		r.s.logger.Errorw("failed to create RTP clock", "error", err)
+		return
+	}
+	// start is the wall time at which the camera began to be read.
+	var start time.Time
+	for {
+		au, ok := sub.nextAccessUnit(ctx)
+		if !ok {
+			break
+		}
+		if start.IsZero() {
+			start = time.Now().Add(-au.pts)
+		}
+		pkts, err := encoder.Encode(au.nalus)
+		if err != nil {
+			r.s.logger.Debugw("failed to packetize access unit", "camera", p.name, "error", err)
+			continue
+		}
+		timestamp := clock.Encode(au.pts)
+		for _, pkt := range pkts {
+			pkt.Timestamp = timestamp
+			if err := p.stream.WritePacketRTPWithNTP(p.media, pkt, start.Add(au.pts)); err != nil {
+				r.s.logger.Debugw("failed to write RTP packet", "camera", p.name, "error", err)
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.paths[p.name] == p {
+		delete(r.paths, p.name)
+		p.stream.Close()
+	}
+}
@@ -0,0 +1,160 @@
+package mediaserver
+
+import (
+	"context"
+	"image"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtph264"
+	"github.com/bluenviron/mediacommon/pkg/codecs/h264"
+	"github.com/pion/rtp"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/rtppassthrough"
+	"go.viam.com/rdk/gostream/codec"
+)
+
+const (
+	rtpBufferSize = 512
+	h264ClockRate = 90000
+	// keyFrameSeconds is how often the encoded streams of cameras have an IDR, which is how long
+	// viewers may wait for their first frame.
+	keyFrameSeconds = 2
+)
+
+// startSource starts publishing the H264 stream of cam to st until ctx is done or the camera can no
+// longer be read. The RTP passthrough of the camera is preferred to encoding its images.
+func (s *Server) startSource(ctx context.Context, st *stream, cam camera.Camera) error {
+	if src, ok := cam.(rtppassthrough.Source); ok {
+		err := s.startPassthroughSource(ctx, st, src)
+		if err == nil {
+			return nil
+		}
+		s.logger.Debugw("camera RTP passthrough unavailable, encoding its images instead", "camera", st.name, "error", err)
+	}
+	if s.encoders == nil {
+		return errors.Errorf("camera %q provides no H264 stream and no video encoder is available to encode its images", st.name)
+	}
+	s.workers.Add(1)
+	utils.ManagedGo(func() {
+		defer st.end()
+		s.encodeImages(ctx, st, cam)
+	}, s.workers.Done)
+	return nil
+}
+
+// startPassthroughSource publishes the access units of the RTP packets of src.
+func (s *Server) startPassthroughSource(ctx context.Context, st *stream, src rtppassthrough.Source) error {
+	decoder := &rtph264.Decoder{PacketizationMode: 1}
+	if err := decoder.Init(); err != nil {
+		return err
+	}
+	var clock rtpClock
+	sub, err := src.SubscribeRTP(ctx, rtpBufferSize, func(pkts []*rtp.Packet) {
+		for _, pkt := range pkts {
+			nalus, err := decoder.Decode(pkt)
+			if err != nil {
+				// most often more packets are needed to complete the access unit.
+				continue
+			}
+			st.publish(&accessUnit{nalus: nalus, pts: clock.pts(pkt.Timestamp), idr: h264.IDRPresent(nalus)})
+		}
+	})
+	if err != nil {
+		return err
+	}
+	s.workers.Add(1)
+	utils.ManagedGo(func() {
+		defer st.end()
+		select {
+		case <-ctx.Done():
+		case <-sub.Terminated.Done():
+			return
+		}
+		if err := src.Unsubscribe(context.Background(), sub.ID); err != nil {
+			s.logger.Debugw("failed to unsubscribe from camera RTP passthrough", "camera", st.name, "error", err)
+		}
+	}, s.workers.Done)
+	return nil
+}
+
+// encodeImages publishes the images of cam encoded to H264 at the configured frame rate until ctx
+// is done.
+func (s *Server) encodeImages(ctx context.Context, st *stream, cam camera.Camera) {
+	var encoder codec.VideoEncoder
+	defer func() {
+		if encoder != nil {
+			utils.UncheckedError(encoder.Close())
+		}
+	}()
+	var bounds image.Rectangle
+	failing := false
+	start := time.Now()
+	ticker := time.NewTicker(time.Second / time.Duration(s.conf.FrameRate))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		img, err := camera.DecodeImageFromCamera(ctx, cam, nil, nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if !failing {
+				s.logger.Warnw("failed to read camera for media server", "camera", st.name, "error", err)
+				failing = true
+			}
+			continue
+		}
+		failing = false
+		if encoder == nil || img.Bounds() != bounds {
+			if encoder != nil {
+				utils.UncheckedError(encoder.Close())
+			}
+			bounds = img.Bounds()
+			encoder, err = s.encoders.New(bounds.Dx(), bounds.Dy(), s.conf.FrameRate*keyFrameSeconds, s.logger)
+			if err != nil {
+				encoder = nil
+				s.logger.Errorw("failed to create video encoder for media server", "camera", st.name, "error", err)
+				return
+			}
+		}
+		encoded, err := encoder.Encode(ctx, img)
+		if err != nil {
+			s.logger.Debugw("failed to encode camera image", "camera", st.name, "error", err)
+			continue
+		}
+		if len(encoded) == 0 {
+			continue
+		}
+		nalus, err := h264.AnnexBUnmarshal(encoded)
+		if err != nil {
+			s.logger.Debugw("failed to parse encoded camera image", "camera", st.name, "error", err)
+			continue
+		}
+		st.publish(&accessUnit{nalus: nalus, pts: time.Since(start), idr: h264.IDRPresent(nalus)})
+	}
+}
+
+// rtpClock unwraps the 32 bit timestamps of RTP packets into the time since the first packet.
+type rtpClock struct {
+	started bool
+	last    uint32
+	ticks   int64
+}
+
+func (c *rtpClock) pts(timestamp uint32) time.Duration {
+	if !c.started {
+		c.started = true
+		c.last = timestamp
+	}
+	c.ticks += int64(int32(timestamp - c.last))
+	c.last = timestamp
+	// split the ticks so that long streams do not overflow.
+	return time.Duration(c.ticks/h264ClockRate)*time.Second + time.Duration(c.ticks%h264ClockRate)*time.Second/h264ClockRate
+}
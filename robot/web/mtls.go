@@ -0,0 +1,55 @@
+package web
+
+import (
+	"context"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"go.viam.com/utils/rpc"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// mtlsEntityMapper replaces the entity of calls authenticated by a client certificate, which is
+// derived from the certificate's issuer and serial number, with the entity its DNS name is mapped
+// to so that roles can be assigned to it.
+type mtlsEntityMapper struct {
+	entities map[string]string
+}
+
+func (m *mtlsEntityMapper) mapEntity(ctx context.Context) context.Context {
+	if _, err := rpc.TokenFromContext(ctx); err == nil {
+		// authenticated by a token rather than the certificate.
+		return ctx
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return ctx
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ctx
+	}
+	for _, name := range tlsInfo.State.VerifiedChains[0][0].DNSNames {
+		if entity, ok := m.entities[name]; ok {
+			return rpc.ContextWithAuthEntity(ctx, rpc.EntityInfo{Entity: entity})
+		}
+	}
+	return ctx
+}
+
+// UnaryInterceptor maps the entity of unary calls authenticated by a client certificate.
+func (m *mtlsEntityMapper) UnaryInterceptor(
+	ctx context.Context, req any, info *googlegrpc.UnaryServerInfo, handler googlegrpc.UnaryHandler,
+) (any, error) {
+	return handler(m.mapEntity(ctx), req)
+}
+
+// StreamInterceptor maps the entity of streaming calls authenticated by a client certificate.
+func (m *mtlsEntityMapper) StreamInterceptor(
+	srv any, ss googlegrpc.ServerStream, info *googlegrpc.StreamServerInfo, handler googlegrpc.StreamHandler,
+) error {
+	wrapped := grpc_middleware.WrapServerStream(ss)
+	wrapped.WrappedContext = m.mapEntity(ss.Context())
+	return handler(srv, wrapped)
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+)
+
+// oidcSigningMethods are the signing methods accepted for access tokens.
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// oidcAuthHandler authenticates entities with access tokens issued by an OpenID Connect provider.
+// The provider is discovered on first use, so the web server can start while it is unreachable, and
+// its signing keys are refreshed in the background as well as whenever a token is signed by an
+// unknown key.
+type oidcAuthHandler struct {
+	conf   *config.OIDCAuthConfig
+	logger logging.Logger
+
+	cancelCtx context.Context
+	cancel    func()
+
+	mu          sync.Mutex
+	keys        *jwk.AutoRefresh
+	jwksURI     string
+	lastRefresh time.Time
+}
+
+func newOIDCAuthHandler(conf *config.OIDCAuthConfig, logger logging.Logger) *oidcAuthHandler {
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	return &oidcAuthHandler{conf: conf, logger: logger, cancelCtx: cancelCtx, cancel: cancel}
+}
+
+// discover looks up the JWKS URI of the provider and starts refreshing its keys if that has not
+// been done yet.
+func (h *oidcAuthHandler) discover(ctx context.Context) (*jwk.AutoRefresh, string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.keys != nil {
+		return h.keys, h.jwksURI, nil
+	}
+
+	wellKnown := strings.TrimSuffix(h.conf.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to discover OIDC provider")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", errors.Errorf("failed to discover OIDC provider: unexpected status %s", resp.Status)
+	}
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, "", errors.Wrap(err, "failed to decode OIDC discovery document")
+	}
+	if discovery.Issuer != h.conf.Issuer {
+		return nil, "", errors.Errorf("OIDC provider reports issuer %q rather than %q", discovery.Issuer, h.conf.Issuer)
+	}
+	if discovery.JWKSURI == "" {
+		return nil, "", errors.New("OIDC discovery document has no jwks_uri")
+	}
+
+	keys := jwk.NewAutoRefresh(h.cancelCtx)
+	keys.Configure(discovery.JWKSURI, jwk.WithMinRefreshInterval(h.conf.KeyRefreshInterval))
+	if _, err := keys.Refresh(ctx, discovery.JWKSURI); err != nil {
+		return nil, "", errors.Wrap(err, "failed to fetch OIDC signing keys")
+	}
+	h.keys, h.jwksURI, h.lastRefresh = keys, discovery.JWKSURI, time.Now()
+	return keys, discovery.JWKSURI, nil
+}
+
+// verificationKey returns the public key a token was signed with, refreshing the provider's keys
+// if the key is unknown, at most once a minute.
+func (h *oidcAuthHandler) verificationKey(ctx context.Context, token *jwt.Token) (interface{}, error) {
+	keyID, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, errors.New("kid header not in token header")
+	}
+	keys, jwksURI, err := h.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keySet, err := keys.Fetch(ctx, jwksURI)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := keySet.LookupKeyID(keyID)
+	if !ok {
+		h.mu.Lock()
+		refresh := time.Since(h.lastRefresh) > time.Minute
+		if refresh {
+			h.lastRefresh = time.Now()
+		}
+		h.mu.Unlock()
+		if refresh {
+			if keySet, err = keys.Refresh(ctx, jwksURI); err != nil {
+				return nil, err
+			}
+			key, ok = keySet.LookupKeyID(keyID)
+		}
+		if !ok {
+			return nil, errors.Errorf("no signing key with kid %q", keyID)
+		}
+	}
+	if alg := key.Algorithm(); alg != "" && alg != token.Method.Alg() {
+		return nil, errors.New("signing key has a different algorithm than the token")
+	}
+	var pubKey interface{}
+	if err := key.Raw(&pubKey); err != nil {
+		return nil, err
+	}
+	return pubKey, nil
+}
+
+// Authenticate verifies that payload is an access token for the configured audience issued to
+// entity.
+func (h *oidcAuthHandler) Authenticate(ctx context.Context, entity, payload string) (map[string]string, error) {
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(payload, claims, func(token *jwt.Token) (interface{}, error) {
+		return h.verificationKey(ctx, token)
+	}, jwt.WithValidMethods(oidcSigningMethods)); err != nil {
+		h.logger.CDebugw(ctx, "rejected OIDC access token", "error", err)
+		return nil, status.Errorf(codes.Unauthenticated, "invalid access token: %s", err)
+	}
+	// the parser only checks exp when it is present, and a token without it would never expire.
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, status.Error(codes.Unauthenticated, "access token has no expiry")
+	}
+	if !claims.VerifyIssuer(h.conf.Issuer, true) {
+		return nil, status.Error(codes.Unauthenticated, "access token has the wrong issuer")
+	}
+	if !claims.VerifyAudience(h.conf.Audience, true) {
+		return nil, status.Error(codes.Unauthenticated, "access token is not for this audience")
+	}
+	if tokenEntity, _ := claims[h.conf.EntityClaim].(string); tokenEntity == "" || tokenEntity != entity {
+		return nil, status.Errorf(codes.Unauthenticated, "access token %s claim does not match the entity", h.conf.EntityClaim)
+	}
+	return map[string]string{}, nil
+}
+
+// Close stops refreshing the provider's keys.
+func (h *oidcAuthHandler) Close() {
+	h.cancel()
+}
@@ -0,0 +1,180 @@
+package web
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.viam.com/utils/rpc"
+	"golang.org/x/time/rate"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+)
+
+// methodClass returns the class a gRPC method is rate limited as: the methods only the admin role
+// may call, the methods the viewer role may call, and everything else.
+func methodClass(fullMethod string) config.APIMethodClass {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	switch {
+	case slices.Contains(adminOnlyServices, service) || slices.Contains(adminOnlyMethods, fullMethod):
+		return config.APIMethodClassAdmin
+	case methodAllowed(config.AuthRoleViewer, fullMethod):
+		return config.APIMethodClassRead
+	default:
+		return config.APIMethodClassWrite
+	}
+}
+
+// rateLimiterSweepInterval is how often limiters that would behave like new ones are removed, so that
+// entities which stopped calling, such as those of expired certificates or OIDC subjects, are forgotten.
+const rateLimiterSweepInterval = time.Minute
+
+// entityLimiter tracks the calls of one entity to the methods one limit applies to.
+type entityLimiter struct {
+	requests *rate.Limiter
+	streams  int
+}
+
+// idle returns whether the limiter has no streams open and all of its requests available, in which
+// case removing it changes nothing.
+func (l *entityLimiter) idle(now time.Time) bool {
+	return l.streams == 0 && (l.requests == nil || l.requests.TokensAt(now) >= float64(l.requests.Burst()))
+}
+
+// rateLimiter enforces the rate limits of an auth config on incoming RPCs. Calls without an
+// authenticated entity are not limited.
+type rateLimiter struct {
+	limits map[config.RateLimitConfig]config.RateLimitConfig
+
+	mu        sync.Mutex
+	limiters  map[rateLimitKey]*entityLimiter
+	lastSwept time.Time
+}
+
+// rateLimitKey identifies the limiter of an entity for a matched limit.
+type rateLimitKey struct {
+	entity string
+	limit  config.RateLimitConfig
+}
+
+func newRateLimiter(limits []config.RateLimitConfig) *rateLimiter {
+	rl := &rateLimiter{
+		limits:   make(map[config.RateLimitConfig]config.RateLimitConfig, len(limits)),
+		limiters: map[rateLimitKey]*entityLimiter{},
+	}
+	for _, limit := range limits {
+		rl.limits[config.RateLimitConfig{Entity: limit.Entity, Class: limit.Class}] = limit
+	}
+	return rl
+}
+
+// limitFor returns the most specific limit that applies to entity calling a method of class.
+func (rl *rateLimiter) limitFor(entity string, class config.APIMethodClass) (config.RateLimitConfig, bool) {
+	for _, key := range []config.RateLimitConfig{
+		{Entity: entity, Class: class},
+		{Entity: entity},
+		{Class: class},
+		{},
+	} {
+		if limit, ok := rl.limits[key]; ok {
+			return limit, true
+		}
+	}
+	return config.RateLimitConfig{}, false
+}
+
+// acquire checks that the calling entity may make a call to fullMethod, returning a function
+// releasing the call once it is done if it is a stream.
+func (rl *rateLimiter) acquire(ctx context.Context, fullMethod string, stream bool) (func(), error) {
+	noop := func() {}
+	if strings.HasPrefix(fullMethod, "/proto.rpc.") {
+		// authentication and WebRTC signaling are needed to connect at all.
+		return noop, nil
+	}
+	entity, ok := rpc.ContextAuthEntity(ctx)
+	if !ok {
+		return noop, nil
+	}
+	class := methodClass(fullMethod)
+	limit, ok := rl.limitFor(entity.Entity, class)
+	if !ok {
+		return noop, nil
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	key := rateLimitKey{entity: entity.Entity, limit: limit}
+	limiter, ok := rl.limiters[key]
+	if !ok {
+		rl.sweep()
+		limiter = &entityLimiter{}
+		if limit.RequestsPerSec != 0 {
+			burst := limit.Burst
+			if burst == 0 {
+				burst = int(math.Ceil(limit.RequestsPerSec))
+			}
+			limiter.requests = rate.NewLimiter(rate.Limit(limit.RequestsPerSec), burst)
+		}
+		rl.limiters[key] = limiter
+	}
+	if stream && limit.MaxConcurrentStreams != 0 && limiter.streams >= limit.MaxConcurrentStreams {
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"%q exceeded its limit of %d concurrent %s streams", entity.Entity, limit.MaxConcurrentStreams, class)
+	}
+	if limiter.requests != nil && !limiter.requests.Allow() {
+		return nil, status.Errorf(codes.ResourceExhausted,
+			"%q exceeded its limit of %v %s requests per second", entity.Entity, limit.RequestsPerSec, class)
+	}
+	if !stream {
+		return noop, nil
+	}
+	limiter.streams++
+	return func() {
+		rl.mu.Lock()
+		limiter.streams--
+		rl.mu.Unlock()
+	}, nil
+}
+
+// sweep removes the idle limiters if they were not swept recently.
+func (rl *rateLimiter) sweep() {
+	now := time.Now()
+	if now.Sub(rl.lastSwept) < rateLimiterSweepInterval {
+		return
+	}
+	rl.lastSwept = now
+	for key, limiter := range rl.limiters {
+		if limiter.idle(now) {
+			delete(rl.limiters, key)
+		}
+	}
+}
+
+// UnaryInterceptor rejects unary RPCs that exceed the calling entity's rate limit.
+func (rl *rateLimiter) UnaryInterceptor(
+	ctx context.Context, req any, info *googlegrpc.UnaryServerInfo, handler googlegrpc.UnaryHandler,
+) (any, error) {
+	if _, err := rl.acquire(ctx, info.FullMethod, false); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor rejects streaming RPCs that exceed the calling entity's rate limit or number of
+// concurrent streams.
+func (rl *rateLimiter) StreamInterceptor(
+	srv any, ss googlegrpc.ServerStream, info *googlegrpc.StreamServerInfo, handler googlegrpc.StreamHandler,
+) error {
+	release, err := rl.acquire(ss.Context(), info.FullMethod, true)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return handler(srv, ss)
+}
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/config"
+)
+
+func TestRateLimiterForgetsIdleEntities(t *testing.T) {
+	rl := newRateLimiter([]config.RateLimitConfig{
+		{RequestsPerSec: 1000, Burst: 1},
+		{Entity: "slow", RequestsPerSec: 0.01},
+	})
+	call := func(entity string) error {
+		ctx := rpc.ContextWithAuthEntity(context.Background(), rpc.EntityInfo{Entity: entity})
+		_, err := rl.acquire(ctx, "/viam.robot.v1.RobotService/ResourceNames", false)
+		return err
+	}
+
+	test.That(t, call("slow"), test.ShouldBeNil)
+	for i := 0; i < 10; i++ {
+		test.That(t, call(fmt.Sprintf("entity-%d", i)), test.ShouldBeNil)
+	}
+	test.That(t, rl.limiters, test.ShouldHaveLength, 11)
+
+	// the fast limiters refill within milliseconds, but the slow one has used its only request.
+	time.Sleep(10 * time.Millisecond)
+	rl.lastSwept = time.Time{}
+	test.That(t, call("new-entity"), test.ShouldBeNil)
+	test.That(t, rl.limiters, test.ShouldHaveLength, 2)
+	test.That(t, call("slow"), test.ShouldNotBeNil)
+}
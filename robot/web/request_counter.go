@@ -1,10 +1,12 @@
 package web
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -18,6 +20,7 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
+	"go.viam.com/rdk/ftdc"
 	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/resource"
@@ -92,6 +95,10 @@ type RequestCounter struct {
 	// their own set of stats.
 	requestKeyToStats ssync.Map[string, *requestStats]
 
+	// callWindows maps resource names to their most recent unary API calls, from which rolling
+	// request counts, error rates, and latencies are reported in the machine status.
+	callWindows ssync.Map[string, *callWindow]
+
 	// inFlightRequests maps resource names to how many in-flight requests are
 	// currently targeting that resource name. There can only be `limit` API
 	// calls for any resource. E.g: `motor-foo` can have 50 `IsPowered`
@@ -190,6 +197,71 @@ func (rc *RequestCounter) Stats() any {
 	return ret
 }
 
+// WritePrometheus writes the request counts, errors, time spent, and data sent of every API call
+// for each resource, and the number of in-flight requests targeting each resource.
+func (rc *RequestCounter) WritePrometheus(pw *ftdc.PrometheusWriter) {
+	type sample struct {
+		resource, method string
+		stats            *requestStats
+	}
+	var samples []sample
+	for requestKey, requestStats := range rc.requestKeyToStats.Range {
+		s := sample{method: requestKey, stats: requestStats}
+		if idx := strings.LastIndexByte(requestKey, '.'); idx >= 0 {
+			s.resource, s.method = requestKey[:idx], requestKey[idx+1:]
+		}
+		samples = append(samples, s)
+	}
+	slices.SortFunc(samples, func(a, b sample) int {
+		return cmp.Or(cmp.Compare(a.resource, b.resource), cmp.Compare(a.method, b.method))
+	})
+
+	families := []struct {
+		name, metricType, help string
+		value                  func(*requestStats) int64
+	}{
+		{"viam_grpc_requests_total", "counter", "Number of API calls, by resource and method.", func(s *requestStats) int64 {
+			return s.count.Load()
+		}},
+		{"viam_grpc_request_errors_total", "counter", "Number of API calls that returned an error.", func(s *requestStats) int64 {
+			return s.errorCnt.Load()
+		}},
+		{"viam_grpc_request_duration_milliseconds_total", "counter", "Total time spent handling API calls.", func(s *requestStats) int64 {
+			return s.timeSpent.Load()
+		}},
+		{"viam_grpc_response_bytes_total", "counter", "Total size of the responses to API calls.", func(s *requestStats) int64 {
+			return s.dataSent.Load()
+		}},
+	}
+	for _, family := range families {
+		pw.Family(family.name, family.metricType, family.help)
+		for _, s := range samples {
+			pw.Sample(family.name, float64(family.value(s.stats)), "resource", s.resource, "method", s.method)
+		}
+	}
+
+	type inFlightSample struct {
+		resource, api string
+		count         int64
+	}
+	var inFlight []inFlightSample
+	for key, counter := range rc.inFlightRequests.Range {
+		// keys are the API of the request, prefixed with the resource it targets if any.
+		s := inFlightSample{api: key, count: counter.Load()}
+		if name, api, ok := strings.Cut(key, "."); ok && !strings.HasPrefix(key, "viam.") {
+			s.resource, s.api = name, api
+		}
+		inFlight = append(inFlight, s)
+	}
+	slices.SortFunc(inFlight, func(a, b inFlightSample) int {
+		return cmp.Or(cmp.Compare(a.resource, b.resource), cmp.Compare(a.api, b.api))
+	})
+	pw.Family("viam_grpc_requests_in_flight", "gauge", "Number of API calls currently in flight, by resource and API.")
+	for _, s := range inFlight {
+		pw.Sample("viam_grpc_requests_in_flight", float64(s.count), "resource", s.resource, "api", s.api)
+	}
+}
+
 // ClientInformation represents the metadata, connection information, and request counts
 // of a connected client. Useful for logging client information when request limits are
 // exceeded.
@@ -436,6 +508,7 @@ func (rc *RequestCounter) UnaryInterceptor(
 	// Storing in FTDC: `web.motor-name.MotorService/IsMoving: <count>`.
 	if apiMethod.shortPath != "" {
 		rc.preRequestIncrement(requestCounterKey)
+		resourceName := apiMethod.getResourceName(req)
 
 		start := time.Now()
 		defer func() {
@@ -456,11 +529,15 @@ func (rc *RequestCounter) UnaryInterceptor(
 			if protoMsg, ok := resp.(proto.Message); ok {
 				respSize = proto.Size(protoMsg)
 			}
+			timeSpent := time.Since(start)
 			rc.postRequestIncrement(
 				requestCounterKey,
-				time.Since(start),
+				timeSpent,
 				respSize,
 				err != nil)
+			if resourceName != "" {
+				rc.recordCall(resourceName, callSample{at: start, duration: timeSpent, failed: err != nil})
+			}
 		}()
 	}
 
@@ -0,0 +1,110 @@
+package web
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/robot"
+)
+
+const (
+	// requestStatsWindow is how far back the rolling request stats of each resource look.
+	requestStatsWindow = 5 * time.Minute
+	// requestStatsSamples bounds how many of the most recent calls to each resource are kept, so
+	// the stats of busy resources cover less than requestStatsWindow.
+	requestStatsSamples = 512
+)
+
+type callSample struct {
+	at       time.Time
+	duration time.Duration
+	failed   bool
+}
+
+// callWindow keeps the most recent unary calls to a resource in a ring.
+type callWindow struct {
+	mu      sync.Mutex
+	samples []callSample
+	next    int
+}
+
+func (w *callWindow) add(sample callSample) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.samples) < requestStatsSamples {
+		w.samples = append(w.samples, sample)
+		return
+	}
+	w.samples[w.next] = sample
+	w.next = (w.next + 1) % requestStatsSamples
+}
+
+// stats summarizes the calls made since requestStatsWindow before now. It returns false if there
+// were none.
+func (w *callWindow) stats(now time.Time) (robot.RequestStats, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var st robot.RequestStats
+	durations := make([]time.Duration, 0, len(w.samples))
+	oldest := now
+	for _, sample := range w.samples {
+		if now.Sub(sample.at) > requestStatsWindow {
+			continue
+		}
+		st.Requests++
+		if sample.failed {
+			st.Errors++
+		}
+		durations = append(durations, sample.duration)
+		if sample.at.Before(oldest) {
+			oldest = sample.at
+		}
+	}
+	if st.Requests == 0 {
+		return st, false
+	}
+	st.ErrorRate = float64(st.Errors) / float64(st.Requests)
+	st.Window = requestStatsWindow
+	if len(w.samples) == requestStatsSamples {
+		st.Window = min(requestStatsWindow, now.Sub(oldest))
+	}
+	slices.Sort(durations)
+	st.P50Latency = percentile(durations, 50)
+	st.P95Latency = percentile(durations, 95)
+	return st, true
+}
+
+// percentile returns the nearest-rank percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// recordCall adds a finished unary call to the rolling stats of the resource it targeted.
+func (rc *RequestCounter) recordCall(resourceName string, sample callSample) {
+	window, ok := rc.callWindows.Load(resourceName)
+	if !ok {
+		window, _ = rc.callWindows.LoadOrStore(resourceName, &callWindow{})
+	}
+	window.add(sample)
+}
+
+// ResourceRequestStats returns the rolling request counts, error rates, and handler latencies of the
+// unary API calls made to each resource recently, by the name the calls targeted it with.
+// Resources that have not been called within the window are left out.
+func (rc *RequestCounter) ResourceRequestStats() map[string]robot.RequestStats {
+	now := time.Now()
+	var ret map[string]robot.RequestStats
+	for name, window := range rc.callWindows.Range {
+		st, ok := window.stats(now)
+		if !ok {
+			continue
+		}
+		if ret == nil {
+			ret = make(map[string]robot.RequestStats)
+		}
+		ret[name] = st
+	}
+	return ret
+}
@@ -0,0 +1,63 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/robot"
+)
+
+func TestCallWindowStats(t *testing.T) {
+	now := time.Now()
+	var w callWindow
+	_, ok := w.stats(now)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	// too old to count.
+	w.add(callSample{at: now.Add(-2 * requestStatsWindow), duration: time.Hour, failed: true})
+	_, ok = w.stats(now)
+	test.That(t, ok, test.ShouldBeFalse)
+
+	for i := 1; i <= 20; i++ {
+		w.add(callSample{at: now.Add(-time.Second), duration: time.Duration(i) * time.Millisecond, failed: i%5 == 0})
+	}
+	st, ok := w.stats(now)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, st, test.ShouldResemble, robot.RequestStats{
+		Window:     requestStatsWindow,
+		Requests:   20,
+		Errors:     4,
+		ErrorRate:  0.2,
+		P50Latency: 10 * time.Millisecond,
+		P95Latency: 19 * time.Millisecond,
+	})
+
+	// once full, the oldest calls are replaced and the window shrinks to the calls kept.
+	for range requestStatsSamples {
+		w.add(callSample{at: now.Add(-time.Minute), duration: time.Millisecond})
+	}
+	st, ok = w.stats(now)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, st.Requests, test.ShouldEqual, requestStatsSamples)
+	test.That(t, st.Errors, test.ShouldEqual, 0)
+	test.That(t, st.Window, test.ShouldEqual, time.Minute)
+	test.That(t, st.P95Latency, test.ShouldEqual, time.Millisecond)
+}
+
+func TestResourceRequestStats(t *testing.T) {
+	var rc RequestCounter
+	test.That(t, rc.ResourceRequestStats(), test.ShouldBeNil)
+
+	rc.recordCall("arm", callSample{at: time.Now(), duration: time.Millisecond})
+	rc.recordCall("arm", callSample{at: time.Now(), duration: 3 * time.Millisecond, failed: true})
+	rc.recordCall("base", callSample{at: time.Now().Add(-2 * requestStatsWindow), duration: time.Millisecond})
+
+	stats := rc.ResourceRequestStats()
+	test.That(t, stats, test.ShouldHaveLength, 1)
+	test.That(t, stats["arm"].Requests, test.ShouldEqual, 2)
+	test.That(t, stats["arm"].ErrorRate, test.ShouldEqual, 0.5)
+	test.That(t, stats["arm"].P50Latency, test.ShouldEqual, time.Millisecond)
+	test.That(t, stats["arm"].P95Latency, test.ShouldEqual, 3*time.Millisecond)
+}
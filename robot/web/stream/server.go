@@ -72,6 +72,13 @@ type Server struct {
 	warnRepeatInterval time.Duration                // interval at which to log repeated warning messages
 }
 
+// A StreamFilter is implemented by robots that do not stream all of their cameras, such as robots
+// with remotes whose streams are not proxied.
+type StreamFilter interface {
+	// StreamsCamera returns whether the camera with the given short name is streamed.
+	StreamsCamera(name string) bool
+}
+
 // Resolution holds the width and height of a video stream.
 // We use int32 to match the resolution type in the proto.
 type Resolution struct {
@@ -585,7 +592,7 @@ func (server *Server) removeMissingStreams() {
 		shortName := resource.SDPTrackNameToShortName(camName)
 
 		_, err := camera.FromProvider(server.robot, shortName)
-		if !resource.IsNotFoundError(err) {
+		if !resource.IsNotFoundError(err) && server.streamsCamera(shortName) {
 			// Cameras can go through transient states during reconfigure that don't necessarily
 			// imply the camera is missing. E.g: *resource.notAvailableError. To double-check we
 			// have the right set of exceptions here, we log the error and ignore.
@@ -619,7 +626,7 @@ func (server *Server) removeMissingStreams() {
 
 		// Best effort close any active peer streams. We'll remove from the known streams
 		// first. Such that we only try closing/unsubscribing once.
-		server.logger.Infow("Camera doesn't exist. Closing its streams",
+		server.logger.Infow("Camera doesn't exist or is not streamed. Closing its streams",
 			"camera", camName, "err", err, "Type", fmt.Sprintf("%T", err))
 		delete(server.streamErrors, camName)
 		delete(server.nameToStreamState, key)
@@ -658,6 +665,11 @@ func (server *Server) refreshVideoSources(ctx context.Context) {
 		if err != nil {
 			continue
 		}
+		if !server.streamsCamera(name) {
+			// Its stream, if any, is closed by removeMissingStreams.
+			delete(server.videoSources, cam.Name().Name)
+			continue
+		}
 		src, err := camerautils.VideoSourceFromCamera(ctx, cam)
 		if err != nil {
 			server.logger.Errorf("error creating video source from camera: %v", err)
@@ -704,6 +716,12 @@ func (server *Server) refreshVideoSources(ctx context.Context) {
 	}
 }
 
+// streamsCamera returns whether the camera with the given short name is streamed.
+func (server *Server) streamsCamera(name string) bool {
+	filter, ok := server.robot.(StreamFilter)
+	return !ok || filter.StreamsCamera(name)
+}
+
 func (server *Server) createStream(config gostream.StreamConfig, name string) (gostream.Stream, bool, error) {
 	stream, err := server.NewStream(config)
 	// Skip if stream is already registered, otherwise raise any other errors
@@ -3,6 +3,7 @@ package web
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -30,6 +31,7 @@ import (
 	pb "go.viam.com/api/robot/v1"
 	"go.viam.com/utils"
 	echopb "go.viam.com/utils/proto/rpc/examples/echo/v1"
+	rpcpb "go.viam.com/utils/proto/rpc/v1"
 	"go.viam.com/utils/rpc"
 	echoserver "go.viam.com/utils/rpc/examples/echo/server"
 	"go.viam.com/utils/trace"
@@ -38,16 +40,24 @@ import (
 	googlegrpc "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/keepalive"
+	grpcmetadata "google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/ftdc"
 	"go.viam.com/rdk/grpc"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/module"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/robot/batch"
 	"go.viam.com/rdk/robot/client"
+	"go.viam.com/rdk/robot/estop"
+	"go.viam.com/rdk/robot/governor"
 	grpcserver "go.viam.com/rdk/robot/server"
+	"go.viam.com/rdk/robot/support"
+	"go.viam.com/rdk/robot/synccapture"
+	"go.viam.com/rdk/robot/web/mediaserver"
 	weboptions "go.viam.com/rdk/robot/web/options"
 	webstream "go.viam.com/rdk/robot/web/stream"
 	rutils "go.viam.com/rdk/utils"
@@ -101,7 +111,15 @@ type resourceGetterForAPI struct {
 }
 
 func (r resourceGetterForAPI) Resource(name string) (resource.Resource, error) {
-	return r.robot.FindBySimpleNameAndAPI(name, r.api)
+	res, err := r.robot.FindBySimpleNameAndAPI(name, r.api)
+	if err != nil {
+		return nil, err
+	}
+	// motion commanded over gRPC is subject to the robot's governor, if it has one.
+	if g, gErr := governor.FromProvider(r.robot, governor.InternalServiceName); gErr == nil {
+		return governor.Wrap(g, res.Name(), res), nil
+	}
+	return res, nil
 }
 
 type webService struct {
@@ -127,6 +145,10 @@ type webService struct {
 
 	requestCounter     RequestCounter
 	modPeerConnTracker *grpc.ModPeerConnTracker
+	// mtls is set when clients may authenticate with a client certificate.
+	mtls *config.MTLSAuthConfig
+	// oidcAuth is set when clients may authenticate with OIDC access tokens.
+	oidcAuth *oidcAuthHandler
 }
 
 // New returns a new web service for the given robot.
@@ -316,6 +338,12 @@ func (svc *webService) startProtocolModuleParentServer(ctx context.Context, tcpM
 			return status.Errorf(codes.Internal, "%v", p)
 		}))))
 
+	// modules must not be able to move actuators while the emergency stop is engaged either.
+	if p, ok := svc.r.(estop.Provider); ok {
+		unaryInterceptors = append(unaryInterceptors, p.EmergencyStop().UnaryServerInterceptor)
+		streamInterceptors = append(streamInterceptors, p.EmergencyStop().StreamServerInterceptor)
+	}
+
 	opManager := svc.r.OperationManager()
 	unaryInterceptors = append(unaryInterceptors,
 		opManager.UnaryServerInterceptor, logging.UnaryServerInterceptor)
@@ -473,6 +501,7 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 	if err != nil {
 		return err
 	}
+	oidcAuth := svc.oidcAuth
 
 	otelStatsHandler := otelgrpc.NewServerHandler(
 		otelgrpc.WithTracerProvider(trace.GetProvider()),
@@ -499,6 +528,18 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		return err
 	}
 
+	if err := svc.rpcServer.RegisterServiceServer(ctx, &batch.ServiceDesc, batch.NewServer(svc.r)); err != nil {
+		return err
+	}
+
+	if err := svc.rpcServer.RegisterServiceServer(ctx, &support.ServiceDesc, support.NewServer(svc.r)); err != nil {
+		return err
+	}
+
+	if err := svc.rpcServer.RegisterServiceServer(ctx, &synccapture.ServiceDesc, synccapture.NewServer(svc.r)); err != nil {
+		return err
+	}
+
 	if err := svc.initAPIResourceCollections(ctx, svc.rpcServer); err != nil {
 		return err
 	}
@@ -523,12 +564,47 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		return err
 	}
 
+	var unixServer *http.Server
+	var unixListener net.Listener
+	if options.Network.UnixSocket != "" {
+		unixServer, unixListener, err = svc.initUnixSocketServer(options)
+		if err != nil {
+			return err
+		}
+	}
+
+	var mediaServer *mediaserver.Server
+	if options.Network.MediaServer != nil {
+		mediaServer, err = mediaserver.NewServer(
+			svc.r, *options.Network.MediaServer, svc.videoEncoderFactory(), svc.logger.Sublogger("media_server"))
+		if err != nil {
+			return err
+		}
+	}
+
 	// Serve
 
 	svc.webWorkers.Add(1)
 	utils.PanicCapturingGo(func() {
 		defer svc.webWorkers.Done()
 		<-ctx.Done()
+		if mediaServer != nil {
+			defer func() {
+				if err := mediaServer.Close(); err != nil {
+					svc.logger.Errorw("error closing media server", "error", err)
+				}
+			}()
+		}
+		if oidcAuth != nil {
+			defer oidcAuth.Close()
+		}
+		if unixServer != nil {
+			defer func() {
+				if err := unixServer.Shutdown(context.Background()); err != nil {
+					svc.logger.Errorw("error shutting down unix socket server", "error", err)
+				}
+			}()
+		}
 		defer func() {
 			if err := httpServer.Shutdown(context.Background()); err != nil {
 				svc.logger.Errorw("error shutting down", "error", err)
@@ -581,6 +657,17 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		}
 	})
 
+	if unixServer != nil {
+		svc.logger.Infow("serving over unix socket", "address", rutils.UnixSocketAddressPrefix+options.Network.UnixSocket)
+		svc.webWorkers.Add(1)
+		utils.PanicCapturingGo(func() {
+			defer svc.webWorkers.Done()
+			if err := unixServer.Serve(unixListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				svc.logger.Errorw("error serving over unix socket", "error", err)
+			}
+		})
+	}
+
 	return err
 }
 
@@ -660,6 +747,34 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 	}
 	rpcOpts = append(rpcOpts, authOpts...)
 
+	// certificate and WebRTC entities must be mapped before roles and rate limits are checked.
+	if svc.mtls != nil {
+		mapper := &mtlsEntityMapper{entities: svc.mtls.Entities}
+		unaryInterceptors = append(unaryInterceptors, mapper.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, mapper.StreamInterceptor)
+	}
+	if len(options.Auth.RateLimits) != 0 || len(options.Auth.Roles) != 0 {
+		mapper := newWebRTCEntityMapper()
+		unaryInterceptors = append(unaryInterceptors, mapper.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, mapper.StreamInterceptor)
+	}
+	// rate limits are checked first so that calls a role does not allow count against them too.
+	if len(options.Auth.RateLimits) != 0 {
+		rl := newRateLimiter(options.Auth.RateLimits)
+		unaryInterceptors = append(unaryInterceptors, rl.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, rl.StreamInterceptor)
+	}
+	if len(options.Auth.Roles) != 0 {
+		ac := newAccessControl(options.Auth.Roles)
+		unaryInterceptors = append(unaryInterceptors, ac.UnaryInterceptor)
+		streamInterceptors = append(streamInterceptors, ac.StreamInterceptor)
+	}
+
+	if p, ok := svc.r.(estop.Provider); ok {
+		unaryInterceptors = append(unaryInterceptors, p.EmergencyStop().UnaryServerInterceptor)
+		streamInterceptors = append(streamInterceptors, p.EmergencyStop().StreamServerInterceptor)
+	}
+
 	opManager := svc.r.OperationManager()
 	sessManagerInts := svc.r.SessionManager().ServerInterceptors()
 	if sessManagerInts.UnaryServerInterceptor != nil {
@@ -695,6 +810,8 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 // Initialize authentication handler options.
 func (svc *webService) initAuthHandlers(listenerTCPAddr *net.TCPAddr, options weboptions.Options) ([]rpc.ServerOption, error) {
 	rpcOpts := []rpc.ServerOption{}
+	svc.mtls = nil
+	svc.oidcAuth = nil
 
 	if options.Managed && len(options.Auth.Handlers) == 1 {
 		if options.BakedAuthEntity == "" || options.BakedAuthCreds.Type == "" {
@@ -728,8 +845,9 @@ func (svc *webService) initAuthHandlers(listenerTCPAddr *net.TCPAddr, options we
 				authEntities = addIfNotFound(weboptions.LocalHostWithPort(listenerTCPAddr))
 			}
 		}
-		if options.Secure && len(options.Auth.TLSAuthEntities) != 0 {
-			rpcOpts = append(rpcOpts, rpc.WithTLSAuthHandler(options.Auth.TLSAuthEntities))
+		var tlsAuthEntities []string
+		if options.Secure {
+			tlsAuthEntities = append(tlsAuthEntities, options.Auth.TLSAuthEntities...)
 		}
 		for _, handler := range options.Auth.Handlers {
 			switch handler.Type {
@@ -755,11 +873,33 @@ func (svc *webService) initAuthHandlers(listenerTCPAddr *net.TCPAddr, options we
 					handler.Type,
 					rpc.MakeSimpleMultiAuthHandler(authEntities, locationSecrets),
 				))
+			case rutils.CredentialsTypeMTLS:
+				if !options.Secure {
+					return nil, errors.Errorf("%q handler requires the web server to use TLS", handler.Type)
+				}
+				mtls, err := config.ParseMTLSAuthConfig(handler)
+				if err != nil {
+					return nil, err
+				}
+				svc.mtls = mtls
+				for name := range mtls.Entities {
+					tlsAuthEntities = append(tlsAuthEntities, name)
+				}
+			case rutils.CredentialsTypeOIDC:
+				oidcConf, err := config.ParseOIDCAuthConfig(handler)
+				if err != nil {
+					return nil, err
+				}
+				svc.oidcAuth = newOIDCAuthHandler(oidcConf, svc.logger)
+				rpcOpts = append(rpcOpts, rpc.WithAuthHandler(handler.Type, svc.oidcAuth))
 			case rpc.CredentialsTypeExternal:
 			default:
 				return nil, errors.Errorf("do not know how to handle auth for %q", handler.Type)
 			}
 		}
+		if len(tlsAuthEntities) != 0 {
+			rpcOpts = append(rpcOpts, rpc.WithTLSAuthHandler(tlsAuthEntities))
+		}
 	}
 
 	if options.Auth.ExternalAuthConfig != nil {
@@ -797,10 +937,44 @@ func (svc *webService) initHTTPServer(listenerTCPAddr *net.TCPAddr, options webo
 		return httpServer, err
 	}
 	httpServer.TLSConfig = options.Network.TLSConfig.Clone()
+	if svc.mtls != nil {
+		if httpServer.TLSConfig == nil {
+			httpServer.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		// clients without a certificate may still authenticate in other ways.
+		httpServer.TLSConfig.ClientCAs = svc.mtls.ClientCAs
+		httpServer.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
 
 	return httpServer, nil
 }
 
+// initUnixSocketServer listens on the Unix domain socket of the network config and returns a server
+// for it with the same handlers as the web server. Connections over the socket never leave the host,
+// so they are not encrypted, even if the web server uses TLS.
+func (svc *webService) initUnixSocketServer(options weboptions.Options) (*http.Server, net.Listener, error) {
+	socketPath := options.Network.UnixSocket
+	// a socket left behind by a server that was not shut down cleanly would fail the listen, but
+	// anything else at the path is not ours to remove.
+	if info, err := os.Lstat(socketPath); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(socketPath); err != nil {
+			return nil, nil, errors.WithMessage(err, "could not remove stale unix socket")
+		}
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "failed to listen over UDS")
+	}
+	httpServer, err := utils.NewPossiblySecureHTTPServer(svc.initMux(options), utils.HTTPServerOptions{
+		MaxHeaderBytes: rpc.MaxMessageSize,
+	})
+	if err != nil {
+		utils.UncheckedError(listener.Close())
+		return nil, nil, err
+	}
+	return httpServer, listener, nil
+}
+
 // Initialize multiplexer between http handlers.
 func (svc *webService) initMux(options weboptions.Options) *goji.Mux {
 	mux := goji.NewMux()
@@ -820,10 +994,21 @@ func (svc *webService) initMux(options weboptions.Options) *goji.Mux {
 		mux.HandleFunc(pat.New("/debug/pprof/trace"), pprof.Trace)
 	}
 
-	// serve resource graph visualization
+	// the resource graph and prometheus metrics expose resource names, errors and usage, so they need
+	// authentication whenever the server requires it
+	requireAuth := func(h http.HandlerFunc) http.Handler {
+		if len(options.Auth.Handlers) == 0 {
+			return h
+		}
+		return svc.requireHTTPAuth(options, h)
+	}
+
+	// serve resource graph visualization and exports
 	// TODO: hide behind option
-	// TODO: accept params to display different formats
-	mux.HandleFunc(pat.New("/debug/graph"), svc.handleVisualizeResourceGraph)
+	mux.Handle(pat.New("/debug/graph"), requireAuth(svc.handleResourceGraph))
+
+	// serve prometheus metrics
+	mux.Handle(pat.New("/metrics"), requireAuth(svc.handleMetrics))
 
 	// serve restart status
 	mux.HandleFunc(pat.New("/restart_status"), svc.handleRestartStatus)
@@ -849,7 +1034,9 @@ func (svc *webService) initMux(options weboptions.Options) *goji.Mux {
 
 	// for urls with /api, add /viam to the path so that it matches with the paths defined in protobuf.
 	corsHandler := cors.AllowAll()
-	mux.Handle(pat.New("/api/*"), corsHandler.Handler(addPrefix(svc.rpcServer.GatewayHandler())))
+	if !options.Network.DisableHTTPAPI {
+		mux.Handle(pat.New("/api/*"), corsHandler.Handler(svc.apiKeyBasicAuth(addPrefix(svc.rpcServer.GatewayHandler()))))
+	}
 	mux.Handle(pat.New("/*"), corsHandler.Handler(svc.rpcServer.GRPCHandler()))
 
 	return mux
@@ -1073,6 +1260,117 @@ type RestartStatusResponse struct {
 	// ModuleServerTCPAddr is the TCP address of the module server.
 	// The module server can be used for unauthenticated local RPC calls.
 	ModuleServerTCPAddr string `json:"module_server_tcp_addr,omitempty"`
+	// ConfigRevisions reports how far each recently received config revision got toward being
+	// applied, from oldest to newest, so that agent can report it back to the cloud.
+	ConfigRevisions []robot.ConfigRevisionStatus `json:"config_revisions,omitempty"`
+}
+
+// Handles the `/debug/graph` endpoint. `format=json` and `format=dot` export the current resource
+// graph; otherwise its history is visualized.
+func (svc *webService) handleResourceGraph(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "json" && format != "dot" {
+		svc.handleVisualizeResourceGraph(w, r)
+		return
+	}
+	exporter, ok := svc.r.(robot.ResourceGraphExporter)
+	if !ok {
+		http.Error(w, "robot does not support exporting its resource graph", http.StatusNotImplemented)
+		return
+	}
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		utils.UncheckedError(json.NewEncoder(w).Encode(exporter.ExportResourceGraph()))
+		return
+	}
+	dot, err := exporter.ExportResourceGraphDot()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/vnd.graphviz")
+	_, err = w.Write([]byte(dot))
+	utils.UncheckedError(err)
+}
+
+// apiKeyBasicAuth lets HTTP/JSON callers authenticate with an API key passed as basic auth, with the
+// key ID as the username and the key as the password, by exchanging it for an access token.
+func (svc *webService) apiKeyBasicAuth(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID, key, ok := r.BasicAuth()
+		authServer, canAuth := svc.rpcServer.(rpcpb.AuthServiceServer)
+		if !ok || !canAuth {
+			h.ServeHTTP(w, r)
+			return
+		}
+		resp, err := authenticateHTTP(r, authServer, keyID, rpc.CredentialsTypeAPIKey, key)
+		if err != nil {
+			http.Error(w, status.Convert(err).Message(), http.StatusUnauthorized)
+			return
+		}
+		r2 := r.Clone(r.Context())
+		r2.Header.Set("Authorization", rpc.AuthorizationValuePrefixBearer+resp.GetAccessToken())
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// requireHTTPAuth only serves HTTP callers that authenticate with one of the auth handlers of
+// options: with a client certificate mapped to an entity, or with basic auth whose username is the
+// entity and whose password is its credential, such as an API key ID and key or an OIDC subject and
+// access token.
+func (svc *webService) requireHTTPAuth(options weboptions.Options, h http.Handler) http.Handler {
+	var credTypes []rpc.CredentialsType
+	for _, handler := range options.Auth.Handlers {
+		switch handler.Type {
+		case rutils.CredentialsTypeMTLS, rpc.CredentialsTypeExternal:
+		default:
+			credTypes = append(credTypes, handler.Type)
+		}
+	}
+	mtls := svc.mtls
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if mtls != nil && r.TLS != nil && len(r.TLS.VerifiedChains) != 0 && len(r.TLS.VerifiedChains[0]) != 0 {
+			for _, name := range r.TLS.VerifiedChains[0][0].DNSNames {
+				if _, ok := mtls.Entities[name]; ok {
+					h.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		entity, payload, ok := r.BasicAuth()
+		authServer, canAuth := svc.rpcServer.(rpcpb.AuthServiceServer)
+		if ok && canAuth {
+			for _, credType := range credTypes {
+				if _, err := authenticateHTTP(r, authServer, entity, credType, payload); err == nil {
+					h.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="viam-server"`)
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+	})
+}
+
+func authenticateHTTP(
+	r *http.Request, authServer rpcpb.AuthServiceServer, entity string, credType rpc.CredentialsType, payload string,
+) (*rpcpb.AuthenticateResponse, error) {
+	return authServer.Authenticate(grpcmetadata.NewIncomingContext(r.Context(), grpcmetadata.MD{}), &rpcpb.AuthenticateRequest{
+		Entity:      entity,
+		Credentials: &rpcpb.Credentials{Type: string(credType), Payload: payload},
+	})
+}
+
+// Handles the `/metrics` endpoint, which serves metrics in the Prometheus text format.
+func (svc *webService) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	pw := ftdc.NewPrometheusWriter(w)
+	svc.requestCounter.WritePrometheus(pw)
+	ftdc.WriteGoRuntimePrometheus(pw)
+	if exporter, ok := svc.r.(robot.MetricsExporter); ok {
+		exporter.WritePrometheusMetrics(pw)
+	}
+	utils.UncheckedError(pw.Err())
 }
 
 // Handles the `/restart_status` endpoint.
@@ -1083,6 +1381,9 @@ func (svc *webService) handleRestartStatus(w http.ResponseWriter, r *http.Reques
 		DoesNotHandleNeedsRestart: true,
 		ModuleServerTCPAddr:       modAddrs.TCPAddr,
 	}
+	if reporter, ok := svc.r.(robot.ConfigRevisionReporter); ok {
+		response.ConfigRevisions = reporter.ConfigRevisionStatuses()
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	// Only log errors from encoding here. A failure to encode should never
@@ -12,6 +12,7 @@ import (
 	"go.viam.com/utils/rpc"
 
 	"go.viam.com/rdk/gostream"
+	"go.viam.com/rdk/gostream/codec"
 	"go.viam.com/rdk/resource"
 	webstream "go.viam.com/rdk/robot/web/stream"
 )
@@ -66,6 +67,15 @@ func (svc *webService) initStreamServer(ctx context.Context, srv rpc.Server) err
 	)
 }
 
+// videoEncoderFactory returns the video encoder of the stream config, which the media server uses to
+// encode cameras without H264 streams of their own.
+func (svc *webService) videoEncoderFactory() codec.VideoEncoderFactory {
+	if svc.opts.streamConfig == nil {
+		return nil
+	}
+	return svc.opts.streamConfig.VideoEncoderFactory
+}
+
 type filterXML struct {
 	called bool
 	w      http.ResponseWriter
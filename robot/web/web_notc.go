@@ -6,6 +6,8 @@ import (
 	"context"
 
 	"go.viam.com/utils/rpc"
+
+	"go.viam.com/rdk/gostream/codec"
 )
 
 // stub implementation when gostream not available
@@ -16,5 +18,10 @@ func (svc *webService) initStreamServer(_ context.Context, _ rpc.Server) error {
 	return nil
 }
 
+// stub implementation when gostream not available, leaving the media server only cameras with H264 of their own
+func (svc *webService) videoEncoderFactory() codec.VideoEncoderFactory {
+	return nil
+}
+
 // stub for missing gostream
 type options struct{}
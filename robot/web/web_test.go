@@ -12,6 +12,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
@@ -179,6 +180,34 @@ func TestWebStartOptions(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 }
 
+func TestWebUnixSocket(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx, injectRobot := setupRobotCtx(t)
+
+	svc := New(injectRobot, logger)
+
+	options, _, _ := robottestutils.CreateBaseOptionsAndListener(t)
+	socketPath := filepath.Join(t.TempDir(), "web.sock")
+	options.Network.UnixSocket = socketPath
+
+	err := svc.Start(ctx, options)
+	test.That(t, err, test.ShouldBeNil)
+
+	robotClient, err := rclient.New(context.Background(), rutils.UnixSocketAddressPrefix+socketPath, logger,
+		rclient.WithDoNotWaitForRunning())
+	test.That(t, err, test.ShouldBeNil)
+	arm1, err := arm.FromProvider(robotClient, arm1String)
+	test.That(t, err, test.ShouldBeNil)
+
+	arm1Position, err := arm1.EndPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, arm1Position, test.ShouldResemble, pos)
+
+	test.That(t, robotClient.Close(context.Background()), test.ShouldBeNil)
+	err = svc.Close(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+}
+
 func TestWebWithAuth(t *testing.T) {
 	logger := logging.NewTestLogger(t)
 	ctx, injectRobot := setupRobotCtx(t)
@@ -0,0 +1,165 @@
+package web
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/viamrobotics/webrtc/v3"
+	webrtcpb "go.viam.com/utils/proto/rpc/webrtc/v1"
+	"go.viam.com/utils/rpc"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const signalingCallMethod = "/proto.rpc.webrtc.v1.SignalingService/Call"
+
+// webrtcEntityMapper replaces the entity of calls made over WebRTC connections that were signaled
+// through this robot with the entity that authenticated to signal them. Otherwise calls made over
+// WebRTC are attributed to the robot itself, and roles and rate limits would not apply to them.
+//
+// A connection is matched to its signaling call by the ICE username fragment of its offer. Calls
+// over connections signaled elsewhere, such as through the cloud, keep the robot as their entity
+// and are marked as unmapped so that access control can restrict them.
+type webrtcEntityMapper struct {
+	mu sync.Mutex
+	// signaled holds the entity of each offer signaled through this robot whose connection has not
+	// made a call yet, by the offer's ICE username fragment.
+	signaled map[string]rpc.EntityInfo
+	conns    map[*webrtc.PeerConnection]rpc.EntityInfo
+}
+
+func newWebRTCEntityMapper() *webrtcEntityMapper {
+	return &webrtcEntityMapper{
+		signaled: map[string]rpc.EntityInfo{},
+		conns:    map[*webrtc.PeerConnection]rpc.EntityInfo{},
+	}
+}
+
+// iceUfrag returns the ICE username fragment of an SDP.
+func iceUfrag(sdp string) string {
+	for _, line := range strings.Split(sdp, "\n") {
+		if ufrag, ok := strings.CutPrefix(strings.TrimSpace(line), "a=ice-ufrag:"); ok {
+			return ufrag
+		}
+	}
+	return ""
+}
+
+type unmappedWebRTCCallKey struct{}
+
+// isUnmappedWebRTCCall returns whether ctx is of a call made over a WebRTC connection whose
+// signaling entity is not known.
+func isUnmappedWebRTCCall(ctx context.Context) bool {
+	unmapped, _ := ctx.Value(unmappedWebRTCCallKey{}).(bool)
+	return unmapped
+}
+
+// mapEntity returns ctx with the entity that signaled the WebRTC connection a call is made over.
+func (m *webrtcEntityMapper) mapEntity(ctx context.Context) context.Context {
+	pc, ok := rpc.ContextPeerConnection(ctx)
+	if !ok {
+		return ctx
+	}
+	unmapped := context.WithValue(ctx, unmappedWebRTCCallKey{}, true)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entity, ok := m.conns[pc]
+	if !ok {
+		desc := pc.RemoteDescription()
+		if desc == nil {
+			return unmapped
+		}
+		ufrag := iceUfrag(desc.SDP)
+		if entity, ok = m.signaled[ufrag]; !ok {
+			return unmapped
+		}
+		delete(m.signaled, ufrag)
+		for conn := range m.conns {
+			if state := conn.ConnectionState(); state == webrtc.PeerConnectionStateClosed ||
+				state == webrtc.PeerConnectionStateFailed {
+				delete(m.conns, conn)
+			}
+		}
+		m.conns[pc] = entity
+	}
+	return rpc.ContextWithAuthEntity(ctx, entity)
+}
+
+// recordOffer records the entity signaling an offer, returning a function forgetting it.
+func (m *webrtcEntityMapper) recordOffer(ctx context.Context, req *webrtcpb.CallRequest) (func(), error) {
+	entity, ok := rpc.ContextAuthEntity(ctx)
+	if !ok {
+		return func() {}, nil
+	}
+	var offer webrtc.SessionDescription
+	if err := rpc.DecodeSDP(req.GetSdp(), &offer); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid offer: %v", err)
+	}
+	ufrag := iceUfrag(offer.SDP)
+	if ufrag == "" {
+		return nil, status.Error(codes.InvalidArgument, "offer has no ICE username fragment")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.signaled[ufrag]; ok {
+		return nil, status.Error(codes.AlreadyExists, "offer was already signaled")
+	}
+	m.signaled[ufrag] = entity
+	return func() {
+		m.mu.Lock()
+		delete(m.signaled, ufrag)
+		m.mu.Unlock()
+	}, nil
+}
+
+// UnaryInterceptor maps the entity of unary calls made over WebRTC.
+func (m *webrtcEntityMapper) UnaryInterceptor(
+	ctx context.Context, req any, info *googlegrpc.UnaryServerInfo, handler googlegrpc.UnaryHandler,
+) (any, error) {
+	return handler(m.mapEntity(ctx), req)
+}
+
+// StreamInterceptor maps the entity of streaming calls made over WebRTC and records the entities
+// signaling WebRTC connections.
+func (m *webrtcEntityMapper) StreamInterceptor(
+	srv any, ss googlegrpc.ServerStream, info *googlegrpc.StreamServerInfo, handler googlegrpc.StreamHandler,
+) error {
+	if info.FullMethod != signalingCallMethod {
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = m.mapEntity(ss.Context())
+		return handler(srv, wrapped)
+	}
+	stream := &signalingCallStream{ServerStream: ss, m: m}
+	err := handler(srv, stream)
+	if err != nil && stream.forget != nil {
+		// the connection was never established, so no calls will be made over it.
+		stream.forget()
+	}
+	return err
+}
+
+// signalingCallStream records the entity signaling the offer of a call.
+type signalingCallStream struct {
+	googlegrpc.ServerStream
+	m      *webrtcEntityMapper
+	forget func()
+}
+
+func (s *signalingCallStream) RecvMsg(msg any) error {
+	if err := s.ServerStream.RecvMsg(msg); err != nil {
+		return err
+	}
+	req, ok := msg.(*webrtcpb.CallRequest)
+	if !ok || s.forget != nil {
+		return nil
+	}
+	forget, err := s.m.recordOffer(s.Context(), req)
+	if err != nil {
+		return err
+	}
+	s.forget = forget
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package rosbridge is a client of rosbridge servers, which carry ROS 2 topics over a websocket as
+// JSON. Simulators such as Gazebo, through ros_gz_bridge, and Isaac Sim, through its ROS 2 bridge
+// extension, publish their sensors and take their commands on ROS 2 topics, so components bind to a
+// running simulation through a rosbridge server next to it.
+// Protocol: https://github.com/RobotWebTools/rosbridge_suite/blob/ros2/ROSBRIDGE_PROTOCOL.md
+package rosbridge
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"nhooyr.io/websocket"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+)
+
+// maxMessageBytes bounds the messages read from rosbridge, which may be uncompressed images.
+const maxMessageBytes = 64 << 20
+
+// reconnectInterval is how long to wait before reconnecting, which tests shorten.
+var reconnectInterval = 5 * time.Second
+
+// ErrNotConnected is returned when publishing while the client is not connected to rosbridge.
+var ErrNotConnected = errors.New("not connected to rosbridge")
+
+// Op is a message of the rosbridge protocol.
+type Op struct {
+	Op    string          `json:"op"`
+	Topic string          `json:"topic,omitempty"`
+	Type  string          `json:"type,omitempty"`
+	Msg   json.RawMessage `json:"msg,omitempty"`
+}
+
+// ValidateURL returns an error unless url is the websocket URL of a rosbridge server.
+func ValidateURL(path, url string) error {
+	if url == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "rosbridge_url")
+	}
+	if !strings.HasPrefix(url, "ws://") && !strings.HasPrefix(url, "wss://") {
+		return resource.NewConfigValidationError(path, errors.New("rosbridge_url must be a ws:// or wss:// URL"))
+	}
+	return nil
+}
+
+type subscription struct {
+	msgType string
+	handle  func(msg json.RawMessage)
+}
+
+// A Client stays connected to a rosbridge server until it is closed, reconnecting, subscribing and
+// advertising again whenever the connection is lost, such as when the simulation restarts.
+type Client struct {
+	url    string
+	logger logging.Logger
+
+	mu   sync.Mutex
+	conn *websocket.Conn
+	subs map[string]subscription
+	// advertised holds the type of each topic advertised on conn.
+	advertised map[string]string
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// NewClient returns a client that connects to the rosbridge server at url in the background.
+func NewClient(url string, logger logging.Logger) *Client {
+	c := &Client{url: url, logger: logger, subs: map[string]subscription{}}
+	c.cancelCtx, c.cancel = context.WithCancel(context.Background())
+	c.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(c.run, c.activeBackgroundWorkers.Done)
+	return c
+}
+
+// Subscribe calls handle with every message of msgType published on topic, from the goroutine
+// reading the connection, until the client is closed. A topic has at most one subscription.
+func (c *Client) Subscribe(topic, msgType string, handle func(msg json.RawMessage)) {
+	c.mu.Lock()
+	c.subs[topic] = subscription{msgType: msgType, handle: handle}
+	conn := c.conn
+	c.mu.Unlock()
+	if conn != nil {
+		// a failure here fails the connection, and the subscription is sent again on reconnecting.
+		utils.UncheckedError(send(c.cancelCtx, conn, Op{Op: "subscribe", Topic: topic, Type: msgType}, nil))
+	}
+}
+
+// Publish publishes msg, of msgType, on topic, advertising the topic first if need be.
+func (c *Client) Publish(ctx context.Context, topic, msgType string, msg any) error {
+	c.mu.Lock()
+	conn := c.conn
+	advertise := conn != nil && c.advertised[topic] != msgType
+	if advertise {
+		c.advertised[topic] = msgType
+	}
+	c.mu.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	if advertise {
+		if err := send(ctx, conn, Op{Op: "advertise", Topic: topic, Type: msgType}, nil); err != nil {
+			return err
+		}
+	}
+	return send(ctx, conn, Op{Op: "publish", Topic: topic}, msg)
+}
+
+// Connected returns whether the client is connected to rosbridge.
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil
+}
+
+// Close disconnects from rosbridge.
+func (c *Client) Close() {
+	c.cancel()
+	c.activeBackgroundWorkers.Wait()
+}
+
+// run keeps the client connected to rosbridge until it is closed.
+func (c *Client) run() {
+	for c.cancelCtx.Err() == nil {
+		dialCtx, cancel := context.WithTimeout(c.cancelCtx, reconnectInterval)
+		//nolint:bodyclose
+		conn, _, err := websocket.Dial(dialCtx, c.url, nil)
+		cancel()
+		if err == nil {
+			conn.SetReadLimit(maxMessageBytes)
+			err = c.serve(conn)
+		}
+		if c.cancelCtx.Err() != nil {
+			return
+		}
+		c.logger.CWarnw(c.cancelCtx, "rosbridge connection failed; reconnecting", "url", c.url, "error", err)
+		utils.SelectContextOrWait(c.cancelCtx, reconnectInterval)
+	}
+}
+
+func send(ctx context.Context, conn *websocket.Conn, op Op, msg any) error {
+	if msg != nil {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		op.Msg = data
+	}
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageText, data)
+}
+
+// serve subscribes over one connection and hands out the messages it reads until it fails or the
+// client is closed.
+func (c *Client) serve(conn *websocket.Conn) error {
+	c.mu.Lock()
+	c.conn = conn
+	c.advertised = map[string]string{}
+	subs := make(map[string]subscription, len(c.subs))
+	for topic, sub := range c.subs {
+		subs[topic] = sub
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+		utils.UncheckedError(conn.Close(websocket.StatusNormalClosure, ""))
+	}()
+
+	for topic, sub := range subs {
+		if err := send(c.cancelCtx, conn, Op{Op: "subscribe", Topic: topic, Type: sub.msgType}, nil); err != nil {
+			return err
+		}
+	}
+	for {
+		_, data, err := conn.Read(c.cancelCtx)
+		if err != nil {
+			return err
+		}
+		var op Op
+		if err := json.Unmarshal(data, &op); err != nil {
+			return err
+		}
+		switch op.Op {
+		case "publish":
+			c.mu.Lock()
+			sub, ok := c.subs[op.Topic]
+			c.mu.Unlock()
+			if ok {
+				sub.handle(op.Msg)
+			}
+		case "status":
+			c.logger.CDebugw(c.cancelCtx, "rosbridge status", "message", string(data))
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package rosbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/logging"
+)
+
+func TestValidateURL(t *testing.T) {
+	test.That(t, ValidateURL("path", "ws://localhost:9090"), test.ShouldBeNil)
+	test.That(t, ValidateURL("path", ""), test.ShouldNotBeNil)
+	test.That(t, ValidateURL("path", "localhost:9090").Error(), test.ShouldContainSubstring, "ws://")
+}
+
+func TestClient(t *testing.T) {
+	reconnectInterval = 50 * time.Millisecond
+	ctx := context.Background()
+	fs := NewFakeServer()
+	defer fs.Close()
+
+	c := NewClient(fs.URL(), logging.NewTestLogger(t))
+	defer c.Close()
+
+	var mu sync.Mutex
+	var states []JointState
+	c.Subscribe("/joint_states", TypeJointState, func(msg json.RawMessage) {
+		var state JointState
+		test.That(t, json.Unmarshal(msg, &state), test.ShouldBeNil)
+		mu.Lock()
+		states = append(states, state)
+		mu.Unlock()
+	})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, c.Connected(), test.ShouldBeTrue)
+		test.That(tb, fs.Ops("subscribe", "/joint_states"), test.ShouldHaveLength, 1)
+	})
+	test.That(t, fs.Ops("subscribe", "/joint_states")[0].Type, test.ShouldEqual, TypeJointState)
+
+	test.That(t, fs.Publish(ctx, "/joint_states", JointState{Name: []string{"a"}, Position: []float64{1}}), test.ShouldBeNil)
+	test.That(t, fs.Publish(ctx, "/other", JointState{}), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, states, test.ShouldHaveLength, 1)
+	})
+	test.That(t, states[0].Position, test.ShouldResemble, []float64{1})
+
+	// topics are advertised once per connection.
+	cmd := Twist{Linear: Vector3{X: .5}}
+	test.That(t, c.Publish(ctx, "/cmd_vel", TypeTwist, cmd), test.ShouldBeNil)
+	test.That(t, c.Publish(ctx, "/cmd_vel", TypeTwist, cmd), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, fs.Ops("publish", "/cmd_vel"), test.ShouldHaveLength, 2)
+	})
+	test.That(t, fs.Ops("advertise", "/cmd_vel"), test.ShouldHaveLength, 1)
+	var sent Twist
+	test.That(t, json.Unmarshal(fs.Ops("publish", "/cmd_vel")[0].Msg, &sent), test.ShouldBeNil)
+	test.That(t, sent, test.ShouldResemble, cmd)
+
+	// after the simulation restarts, the client subscribes and advertises again.
+	fs.Disconnect()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, fs.Ops("subscribe", "/joint_states"), test.ShouldHaveLength, 2)
+	})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, c.Publish(ctx, "/cmd_vel", TypeTwist, cmd), test.ShouldBeNil)
+	})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, fs.Ops("advertise", "/cmd_vel"), test.ShouldHaveLength, 2)
+	})
+
+	c.Close()
+	test.That(t, c.Connected(), test.ShouldBeFalse)
+	test.That(t, errors.Is(c.Publish(ctx, "/cmd_vel", TypeTwist, cmd), ErrNotConnected), test.ShouldBeTrue)
+}
@@ -0,0 +1,103 @@
+package rosbridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"go.viam.com/utils"
+	"nhooyr.io/websocket"
+)
+
+// FakeServer is a rosbridge server for tests that records the operations clients send it and
+// publishes messages to them, standing in for a simulation.
+type FakeServer struct {
+	server *httptest.Server
+
+	mu    sync.Mutex
+	ops   []Op
+	conns []*websocket.Conn
+}
+
+// NewFakeServer starts a fake rosbridge server.
+func NewFakeServer() *FakeServer {
+	fs := &FakeServer{}
+	fs.server = httptest.NewServer(fs)
+	return fs
+}
+
+// URL returns the websocket URL of the server.
+func (fs *FakeServer) URL() string {
+	return "ws" + strings.TrimPrefix(fs.server.URL, "http")
+}
+
+// Close stops the server.
+func (fs *FakeServer) Close() {
+	fs.Disconnect()
+	fs.server.Close()
+}
+
+// ServeHTTP accepts a client and records the operations it sends.
+func (fs *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(maxMessageBytes)
+	fs.mu.Lock()
+	fs.conns = append(fs.conns, conn)
+	fs.mu.Unlock()
+	for {
+		_, data, err := conn.Read(r.Context())
+		if err != nil {
+			return
+		}
+		var op Op
+		if err := json.Unmarshal(data, &op); err != nil {
+			return
+		}
+		fs.mu.Lock()
+		fs.ops = append(fs.ops, op)
+		fs.mu.Unlock()
+	}
+}
+
+// Ops returns the operations of kind op on topic that clients have sent, oldest first.
+func (fs *FakeServer) Ops(op, topic string) []Op {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	var found []Op
+	for _, o := range fs.ops {
+		if o.Op == op && o.Topic == topic {
+			found = append(found, o)
+		}
+	}
+	return found
+}
+
+// Publish sends msg on topic to every connected client.
+func (fs *FakeServer) Publish(ctx context.Context, topic string, msg any) error {
+	fs.mu.Lock()
+	conns := append([]*websocket.Conn(nil), fs.conns...)
+	fs.mu.Unlock()
+	for _, conn := range conns {
+		if err := send(ctx, conn, Op{Op: "publish", Topic: topic}, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Disconnect drops every client, as a simulation restarting does.
+func (fs *FakeServer) Disconnect() {
+	fs.mu.Lock()
+	conns := fs.conns
+	fs.conns = nil
+	fs.mu.Unlock()
+	for _, conn := range conns {
+		utils.UncheckedError(conn.Close(websocket.StatusGoingAway, ""))
+	}
+}
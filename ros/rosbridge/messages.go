@@ -0,0 +1,152 @@
+package rosbridge
+
+import (
+	"time"
+
+	"github.com/golang/geo/r3"
+	"gonum.org/v1/gonum/num/quat"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// The ROS 2 message types components send and receive. ROS uses meters, radians and a right handed
+// frame with x forward, y left and z up.
+const (
+	TypeCompressedImage   = "sensor_msgs/msg/CompressedImage"
+	TypeImage             = "sensor_msgs/msg/Image"
+	TypeImu               = "sensor_msgs/msg/Imu"
+	TypeJointState        = "sensor_msgs/msg/JointState"
+	TypeNavSatFix         = "sensor_msgs/msg/NavSatFix"
+	TypeOdometry          = "nav_msgs/msg/Odometry"
+	TypeTwist             = "geometry_msgs/msg/Twist"
+	TypeFloat64MultiArray = "std_msgs/msg/Float64MultiArray"
+)
+
+// Time is a builtin_interfaces/Time.
+type Time struct {
+	Sec     int32  `json:"sec"`
+	Nanosec uint32 `json:"nanosec"`
+}
+
+// Header is a std_msgs/Header.
+type Header struct {
+	Stamp   Time   `json:"stamp"`
+	FrameID string `json:"frame_id"`
+}
+
+// NewHeader returns a header stamped with t.
+func NewHeader(t time.Time, frameID string) Header {
+	return Header{
+		//nolint:gosec
+		Stamp:   Time{Sec: int32(t.Unix()), Nanosec: uint32(t.Nanosecond())},
+		FrameID: frameID,
+	}
+}
+
+// Vector3 is a geometry_msgs/Vector3, or a geometry_msgs/Point, which has the same fields.
+type Vector3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// R3 returns the vector scaled by scale, such as 1000 to convert meters to millimeters.
+func (v Vector3) R3(scale float64) r3.Vector {
+	return r3.Vector{X: v.X * scale, Y: v.Y * scale, Z: v.Z * scale}
+}
+
+// Quaternion is a geometry_msgs/Quaternion.
+type Quaternion struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	W float64 `json:"w"`
+}
+
+// Orientation returns the orientation the quaternion represents.
+func (q Quaternion) Orientation() spatialmath.Orientation {
+	return (*spatialmath.Quaternion)(&quat.Number{Real: q.W, Imag: q.X, Jmag: q.Y, Kmag: q.Z})
+}
+
+// Pose is a geometry_msgs/Pose.
+type Pose struct {
+	Position    Vector3    `json:"position"`
+	Orientation Quaternion `json:"orientation"`
+}
+
+// Twist is a geometry_msgs/Twist.
+type Twist struct {
+	Linear  Vector3 `json:"linear"`
+	Angular Vector3 `json:"angular"`
+}
+
+// PoseWithCovariance is a geometry_msgs/PoseWithCovariance.
+type PoseWithCovariance struct {
+	Pose       Pose      `json:"pose"`
+	Covariance []float64 `json:"covariance,omitempty"`
+}
+
+// TwistWithCovariance is a geometry_msgs/TwistWithCovariance.
+type TwistWithCovariance struct {
+	Twist      Twist     `json:"twist"`
+	Covariance []float64 `json:"covariance,omitempty"`
+}
+
+// Odometry is a nav_msgs/Odometry. Its pose is in the header's frame and its twist in the child
+// frame.
+type Odometry struct {
+	Header       Header              `json:"header"`
+	ChildFrameID string              `json:"child_frame_id"`
+	Pose         PoseWithCovariance  `json:"pose"`
+	Twist        TwistWithCovariance `json:"twist"`
+}
+
+// Imu is a sensor_msgs/Imu.
+type Imu struct {
+	Header             Header     `json:"header"`
+	Orientation        Quaternion `json:"orientation"`
+	AngularVelocity    Vector3    `json:"angular_velocity"`
+	LinearAcceleration Vector3    `json:"linear_acceleration"`
+}
+
+// NavSatFix is a sensor_msgs/NavSatFix, in degrees and meters above the WGS 84 ellipsoid.
+type NavSatFix struct {
+	Header    Header  `json:"header"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Altitude  float64 `json:"altitude"`
+}
+
+// JointState is a sensor_msgs/JointState, in radians for revolute joints and meters for prismatic
+// ones.
+type JointState struct {
+	Header   Header    `json:"header"`
+	Name     []string  `json:"name"`
+	Position []float64 `json:"position"`
+	Velocity []float64 `json:"velocity,omitempty"`
+	Effort   []float64 `json:"effort,omitempty"`
+}
+
+// Float64MultiArray is a std_msgs/Float64MultiArray, whose layout rosbridge fills in.
+type Float64MultiArray struct {
+	Data []float64 `json:"data"`
+}
+
+// Image is a sensor_msgs/Image.
+type Image struct {
+	Header      Header `json:"header"`
+	Height      int    `json:"height"`
+	Width       int    `json:"width"`
+	Encoding    string `json:"encoding"`
+	IsBigendian uint8  `json:"is_bigendian"`
+	Step        int    `json:"step"`
+	// Data is encoded as base64, which is how rosbridge encodes uint8 arrays.
+	Data []byte `json:"data"`
+}
+
+// CompressedImage is a sensor_msgs/CompressedImage.
+type CompressedImage struct {
+	Header Header `json:"header"`
+	Format string `json:"format"`
+	Data   []byte `json:"data"`
+}
@@ -1,6 +1,11 @@
 // Package baseremotecontrol implements a remote control for a base.
 // For more information, see the [base remote control service docs].
 //
+// The teleop service supersedes this service: it drives bases, arms, and grippers from configurable
+// control mappings with dead zones, response curves, and safety interlocks. New configurations should
+// use teleop; a joystickControl base remote control is a teleop service mapping AbsoluteY to
+// base_linear and AbsoluteX to base_angular.
+//
 // [base remote control service docs]: https://docs.viam.com/reference/services/base-rc/
 package baseremotecontrol
 
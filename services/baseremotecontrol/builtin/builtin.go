@@ -116,6 +116,8 @@ func NewBuiltIn(
 		cancel:    cancel,
 		events:    make(chan struct{}, 1),
 	}
+	logger.CWarnw(ctx, "the base remote control service is superseded by the teleop service; "+
+		"consider moving this configuration to a teleop service", "name", conf.Name)
 	remoteSvc.state.init()
 	if err := remoteSvc.reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -0,0 +1,379 @@
+// Package builtin implements a calibration service that collects pairs from an arm and pose trackers
+// and writes the frames it solves into a robot's JSON config file.
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/calibration"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func init() {
+	resource.RegisterService(calibration.API, resource.DefaultServiceModel, resource.Registration[calibration.Service, *Config]{
+		Constructor: NewBuiltIn,
+	})
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	Kind calibration.Kind `json:"kind"`
+	// Component is the camera or other sensor whose frame is calibrated.
+	Component string `json:"component"`
+	// Arm is the arm of a hand-eye calibration.
+	Arm string `json:"arm,omitempty"`
+	// PoseTracker reports the pose of the target as seen by the component, such as a pose tracker
+	// that finds fiducials in the component's images.
+	PoseTracker string `json:"pose_tracker,omitempty"`
+	// TargetBody is the body of the target the pose trackers report. It may be left out if they
+	// report only one.
+	TargetBody string `json:"target_body,omitempty"`
+	// ReferencePoseTracker reports the pose of the target in the reference frame, for collecting
+	// extrinsics pairs.
+	ReferencePoseTracker string `json:"reference_pose_tracker,omitempty"`
+	// ReferenceFrame is the frame the extrinsics of the component are relative to. It defaults to the
+	// world.
+	ReferenceFrame string `json:"reference_frame,omitempty"`
+	// ConfigPath is the JSON config file of the robot that solved frames are written into. The robot
+	// reconfigures with the new frames when it watches the file, as it does when started with it.
+	ConfigPath string `json:"config_path,omitempty"`
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if err := conf.Kind.Validate(); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	if conf.Component == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "component")
+	}
+	var deps []string
+	switch conf.Kind {
+	case calibration.KindEyeInHand, calibration.KindEyeToHand:
+		if conf.Arm == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "arm")
+		}
+		if conf.ReferencePoseTracker != "" || conf.ReferenceFrame != "" {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.New("reference_pose_tracker and reference_frame are only for extrinsics calibrations"))
+		}
+		deps = append(deps, conf.Arm)
+		if conf.Kind == calibration.KindEyeToHand {
+			// the camera is written relative to the parent of the arm, which the frame system knows.
+			deps = append(deps, framesystem.InternalServiceName.String())
+		}
+	case calibration.KindExtrinsics:
+		if conf.Arm != "" {
+			return nil, nil, resource.NewConfigValidationError(path, errors.New("arm is only for hand-eye calibrations"))
+		}
+		if conf.ReferencePoseTracker != "" {
+			deps = append(deps, conf.ReferencePoseTracker)
+		}
+	}
+	if conf.PoseTracker != "" {
+		deps = append(deps, conf.PoseTracker)
+	}
+	return deps, nil, nil
+}
+
+type builtIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	conf          *Config
+	arm           arm.Arm
+	tracker       posetracker.PoseTracker
+	referenceTrkr posetracker.PoseTracker
+	fs            framesystem.Service
+	logger        logging.Logger
+
+	mu    sync.Mutex
+	pairs []calibration.PosePair
+}
+
+// NewBuiltIn returns a new calibration service for the given robot.
+func NewBuiltIn(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (calibration.Service, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	svc := &builtIn{
+		Named:  conf.ResourceName().AsNamed(),
+		conf:   svcConfig,
+		logger: logger,
+	}
+	if svcConfig.Arm != "" {
+		if svc.arm, err = arm.FromProvider(deps, svcConfig.Arm); err != nil {
+			return nil, err
+		}
+	}
+	if svcConfig.PoseTracker != "" {
+		if svc.tracker, err = posetracker.FromProvider(deps, svcConfig.PoseTracker); err != nil {
+			return nil, err
+		}
+	}
+	if svcConfig.ReferencePoseTracker != "" {
+		if svc.referenceTrkr, err = posetracker.FromProvider(deps, svcConfig.ReferencePoseTracker); err != nil {
+			return nil, err
+		}
+	}
+	if svcConfig.Kind == calibration.KindEyeToHand {
+		if svc.fs, err = resource.FromProvider[framesystem.Service](deps, framesystem.InternalServiceName); err != nil {
+			return nil, err
+		}
+	}
+	return svc, nil
+}
+
+// Collect measures the pose of the arm's end, or of the target in the reference frame, along with
+// the pose of the target seen by the component.
+func (svc *builtIn) Collect(ctx context.Context, extra map[string]interface{}) (calibration.PosePair, error) {
+	if svc.tracker == nil {
+		return calibration.PosePair{}, errors.New("a pose_tracker must be configured to collect pairs")
+	}
+	var reference spatialmath.Pose
+	var err error
+	if svc.arm != nil {
+		reference, err = svc.arm.EndPosition(ctx, extra)
+	} else {
+		if svc.referenceTrkr == nil {
+			return calibration.PosePair{}, errors.New("a reference_pose_tracker must be configured to collect extrinsics pairs")
+		}
+		reference, err = svc.targetPose(ctx, svc.referenceTrkr, extra)
+	}
+	if err != nil {
+		return calibration.PosePair{}, err
+	}
+	seen, err := svc.targetPose(ctx, svc.tracker, extra)
+	if err != nil {
+		return calibration.PosePair{}, err
+	}
+	pair := calibration.PosePair{Reference: reference, Sensor: seen}
+	if err := svc.AddPair(ctx, pair, extra); err != nil {
+		return calibration.PosePair{}, err
+	}
+	return pair, nil
+}
+
+// targetPose returns the pose of the target body reported by tracker.
+func (svc *builtIn) targetPose(
+	ctx context.Context,
+	tracker posetracker.PoseTracker,
+	extra map[string]interface{},
+) (spatialmath.Pose, error) {
+	var bodies []string
+	if svc.conf.TargetBody != "" {
+		bodies = []string{svc.conf.TargetBody}
+	}
+	poses, err := tracker.Poses(ctx, bodies, extra)
+	if err != nil {
+		return nil, err
+	}
+	if svc.conf.TargetBody != "" {
+		pif, ok := poses[svc.conf.TargetBody]
+		if !ok {
+			return nil, errors.Errorf("%s does not see the target %q", tracker.Name(), svc.conf.TargetBody)
+		}
+		return pif.Pose(), nil
+	}
+	if len(poses) != 1 {
+		return nil, errors.Errorf("%s sees %d bodies; configure the target_body to use", tracker.Name(), len(poses))
+	}
+	for _, pif := range poses {
+		return pif.Pose(), nil
+	}
+	return nil, errors.Errorf("%s does not see the target", tracker.Name())
+}
+
+func (svc *builtIn) AddPair(ctx context.Context, pair calibration.PosePair, extra map[string]interface{}) error {
+	if pair.Reference == nil || pair.Sensor == nil {
+		return errors.New("pairs must have both poses")
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.pairs = append(svc.pairs, pair)
+	return nil
+}
+
+func (svc *builtIn) Pairs(ctx context.Context, extra map[string]interface{}) ([]calibration.PosePair, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return append([]calibration.PosePair(nil), svc.pairs...), nil
+}
+
+func (svc *builtIn) ClearPairs(ctx context.Context, extra map[string]interface{}) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.pairs = nil
+	return nil
+}
+
+// Solve calibrates the component from the pairs. Cameras on an arm are in the frame of the arm's end,
+// cameras fixed in the world beside the arm's base, and sensors in the reference frame.
+func (svc *builtIn) Solve(ctx context.Context, extra map[string]interface{}) (calibration.Solution, error) {
+	pairs, err := svc.Pairs(ctx, extra)
+	if err != nil {
+		return calibration.Solution{}, err
+	}
+	pose, err := calibration.SolvePose(svc.conf.Kind, pairs)
+	if err != nil {
+		return calibration.Solution{}, err
+	}
+	mm, degs := calibration.PairErrors(svc.conf.Kind, pose, pairs)
+
+	var parent string
+	switch svc.conf.Kind {
+	case calibration.KindEyeInHand:
+		parent = svc.conf.Arm
+	case calibration.KindEyeToHand:
+		// the solution is relative to the arm's base, which is where the arm's frame puts it in its
+		// parent.
+		armFrame, err := svc.armFrame(ctx)
+		if err != nil {
+			return calibration.Solution{}, err
+		}
+		parent = armFrame.Parent()
+		pose = spatialmath.Compose(armFrame.Pose(), pose)
+	default:
+		parent = svc.conf.ReferenceFrame
+		if parent == "" {
+			parent = referenceframe.World
+		}
+	}
+	orientation, err := spatialmath.NewOrientationConfig(pose.Orientation().OrientationVectorDegrees())
+	if err != nil {
+		return calibration.Solution{}, err
+	}
+	svc.logger.CInfof(ctx, "calibrated %s from %d pairs with errors of %.3fmm and %.3f degrees",
+		svc.conf.Component, len(pairs), mm, degs)
+	return calibration.Solution{
+		Frame: referenceframe.LinkConfig{
+			ID:          svc.conf.Component,
+			Parent:      parent,
+			Translation: pose.Point(),
+			Orientation: orientation,
+		},
+		TranslationErrorMM: mm,
+		RotationErrorDegs:  degs,
+	}, nil
+}
+
+func (svc *builtIn) armFrame(ctx context.Context) (*referenceframe.LinkInFrame, error) {
+	fsConf, err := svc.fs.FrameSystemConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range fsConf.Parts {
+		if part.FrameConfig != nil && part.FrameConfig.Name() == svc.conf.Arm {
+			return part.FrameConfig, nil
+		}
+	}
+	return nil, errors.Errorf("arm %q is not in the frame system", svc.conf.Arm)
+}
+
+// WriteFrame replaces the parent, translation and orientation of the frame of the component in the
+// config file, keeping its geometry unless the solution has one. The file is replaced at once so the
+// robot never reads it half written.
+func (svc *builtIn) WriteFrame(ctx context.Context, solution calibration.Solution, extra map[string]interface{}) error {
+	if svc.conf.ConfigPath == "" {
+		return errors.New("a config_path must be configured to write frames")
+	}
+	info, err := os.Stat(svc.conf.ConfigPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(svc.conf.ConfigPath)
+	if err != nil {
+		return err
+	}
+	var robotConf map[string]interface{}
+	if err := json.Unmarshal(data, &robotConf); err != nil {
+		return errors.Wrapf(err, "cannot read config %q", svc.conf.ConfigPath)
+	}
+	if err := setFrame(robotConf, solution.Frame); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(robotConf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(svc.conf.ConfigPath), filepath.Base(svc.conf.ConfigPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		//nolint:errcheck,gosec // the file is already renamed unless writing it failed.
+		os.Remove(tmp.Name())
+	}()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		//nolint:errcheck,gosec
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), svc.conf.ConfigPath); err != nil {
+		return err
+	}
+	svc.logger.CInfof(ctx, "wrote the frame of %s to %s", solution.Frame.ID, svc.conf.ConfigPath)
+	return nil
+}
+
+// setFrame sets the frame of the component named by frame.ID in robotConf.
+func setFrame(robotConf map[string]interface{}, frame referenceframe.LinkConfig) error {
+	components, _ := robotConf["components"].([]interface{})
+	for _, c := range components {
+		component, ok := c.(map[string]interface{})
+		if !ok || component["name"] != frame.ID {
+			continue
+		}
+		data, err := json.Marshal(frame)
+		if err != nil {
+			return err
+		}
+		var solved map[string]interface{}
+		if err := json.Unmarshal(data, &solved); err != nil {
+			return err
+		}
+		delete(solved, "id")
+		if existing, ok := component["frame"].(map[string]interface{}); ok && solved["geometry"] == nil {
+			if geometry, ok := existing["geometry"]; ok {
+				solved["geometry"] = geometry
+			}
+		}
+		component["frame"] = solved
+		return nil
+	}
+	return errors.Errorf("the config has no component named %q", frame.ID)
+}
+
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	resp, handled, err := calibration.HandleCalibrationCommand(ctx, svc, cmd)
+	if !handled {
+		return nil, resource.ErrDoUnimplemented
+	}
+	return resp, err
+}
@@ -0,0 +1,192 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/calibration"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+const testConfig = `{
+  "components": [
+    {"name": "arm", "api": "rdk:component:arm", "model": "fake"},
+    {
+      "name": "cam", "api": "rdk:component:camera", "model": "fake",
+      "frame": {"parent": "world", "geometry": {"type": "box", "x": 10, "y": 20, "z": 30}}
+    }
+  ]
+}`
+
+// armPoses are poses of the arm's end that rotate it about several axes.
+var armPoses = []spatialmath.Pose{
+	spatialmath.NewPose(r3.Vector{X: 300, Z: 400}, &spatialmath.OrientationVectorDegrees{OZ: -1}),
+	spatialmath.NewPose(r3.Vector{X: 250, Y: 50, Z: 420}, &spatialmath.OrientationVectorDegrees{OX: .2, OZ: -1, Theta: 20}),
+	spatialmath.NewPose(r3.Vector{X: 320, Y: -40, Z: 380}, &spatialmath.OrientationVectorDegrees{OY: .3, OZ: -1, Theta: -30}),
+	spatialmath.NewPose(r3.Vector{X: 280, Y: 20, Z: 450}, &spatialmath.OrientationVectorDegrees{OX: -.2, OY: .2, OZ: -1, Theta: 60}),
+}
+
+// newTestDeps returns an arm moving through armPoses and a pose tracker seeing the target from a
+// camera at camera, for the kind of calibration.
+func newTestDeps(kind calibration.Kind, camera, target spatialmath.Pose) resource.Dependencies {
+	next := 0
+	a := inject.NewArm("arm")
+	a.EndPositionFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+		return armPoses[next], nil
+	}
+	tracker := inject.NewPoseTracker("tracker")
+	tracker.PosesFunc = func(ctx context.Context, bodyNames []string, extra map[string]interface{}) (referenceframe.FrameSystemPoses, error) {
+		end := armPoses[next]
+		next = (next + 1) % len(armPoses)
+		if kind == calibration.KindEyeToHand {
+			end = spatialmath.PoseInverse(end)
+		}
+		seen := spatialmath.PoseBetween(spatialmath.Compose(end, camera), target)
+		return referenceframe.FrameSystemPoses{"target": referenceframe.NewPoseInFrame("cam", seen)}, nil
+	}
+	fs := inject.NewFrameSystemService(framesystem.InternalServiceName.Name)
+	fs.FrameSystemConfigFunc = func(ctx context.Context) (*framesystem.Config, error) {
+		armFrame := referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewPoseFromPoint(r3.Vector{Z: 100}), "arm", nil)
+		return &framesystem.Config{Parts: []*referenceframe.FrameSystemPart{{FrameConfig: armFrame}}}, nil
+	}
+	return resource.Dependencies{
+		arm.Named("arm"):                a,
+		posetracker.Named("tracker"):    tracker,
+		framesystem.InternalServiceName: fs,
+	}
+}
+
+func newService(t *testing.T, conf *Config, deps resource.Dependencies) calibration.Service {
+	t.Helper()
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	svc, err := NewBuiltIn(context.Background(), deps, resource.Config{
+		Name:                "calibration",
+		API:                 calibration.API,
+		Model:               resource.DefaultServiceModel,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return svc
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := (&Config{
+		Kind: calibration.KindEyeToHand, Component: "cam", Arm: "arm", PoseTracker: "tracker",
+	}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"arm", framesystem.InternalServiceName.String(), "tracker"})
+
+	_, _, err = (&Config{Kind: "stereo", Component: "cam"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{Kind: calibration.KindEyeInHand}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{Kind: calibration.KindEyeInHand, Component: "cam"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{Kind: calibration.KindExtrinsics, Component: "cam", Arm: "arm"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCalibrate(t *testing.T) {
+	ctx := context.Background()
+	camera := spatialmath.NewPose(r3.Vector{X: 40, Z: 60}, &spatialmath.OrientationVectorDegrees{OX: 1, Theta: 90})
+	target := spatialmath.NewPose(r3.Vector{X: 600, Y: -50}, &spatialmath.OrientationVectorDegrees{OZ: 1})
+
+	for _, tc := range []struct {
+		kind   calibration.Kind
+		parent string
+		pose   spatialmath.Pose
+	}{
+		{calibration.KindEyeInHand, "arm", camera},
+		// the camera is beside the arm, whose base is 100mm above the world.
+		{calibration.KindEyeToHand, referenceframe.World, spatialmath.Compose(spatialmath.NewPoseFromPoint(r3.Vector{Z: 100}), camera)},
+	} {
+		t.Run(string(tc.kind), func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "robot.json")
+			test.That(t, os.WriteFile(configPath, []byte(testConfig), 0o600), test.ShouldBeNil)
+			svc := newService(t, &Config{
+				Kind:        tc.kind,
+				Component:   "cam",
+				Arm:         "arm",
+				PoseTracker: "tracker",
+				TargetBody:  "target",
+				ConfigPath:  configPath,
+			}, newTestDeps(tc.kind, camera, target))
+
+			_, err := svc.Solve(ctx, nil)
+			test.That(t, err, test.ShouldNotBeNil)
+			for range armPoses {
+				_, err := svc.Collect(ctx, nil)
+				test.That(t, err, test.ShouldBeNil)
+			}
+			pairs, err := svc.Pairs(ctx, nil)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, pairs, test.ShouldHaveLength, len(armPoses))
+
+			sol, err := svc.Solve(ctx, nil)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, sol.Frame.ID, test.ShouldEqual, "cam")
+			test.That(t, sol.Frame.Parent, test.ShouldEqual, tc.parent)
+			test.That(t, sol.TranslationErrorMM, test.ShouldAlmostEqual, 0, 1e-6)
+			test.That(t, sol.RotationErrorDegs, test.ShouldAlmostEqual, 0, 1e-6)
+
+			// the frame is written into the config, keeping the camera's geometry.
+			test.That(t, svc.WriteFrame(ctx, sol, nil), test.ShouldBeNil)
+			data, err := os.ReadFile(configPath)
+			test.That(t, err, test.ShouldBeNil)
+			var conf config.Config
+			test.That(t, json.Unmarshal(data, &conf), test.ShouldBeNil)
+			test.That(t, conf.Components, test.ShouldHaveLength, 2)
+			frame := conf.Components[1].Frame
+			test.That(t, frame, test.ShouldNotBeNil)
+			test.That(t, frame.Parent, test.ShouldEqual, tc.parent)
+			test.That(t, frame.Geometry, test.ShouldNotBeNil)
+			test.That(t, frame.Geometry.Y, test.ShouldEqual, 20)
+			pose, err := frame.Pose()
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, spatialmath.PoseAlmostEqualEps(pose, tc.pose, 1e-6), test.ShouldBeTrue)
+
+			sol.Frame.ID = "missing"
+			test.That(t, svc.WriteFrame(ctx, sol, nil), test.ShouldNotBeNil)
+
+			test.That(t, svc.ClearPairs(ctx, nil), test.ShouldBeNil)
+			pairs, err = svc.Pairs(ctx, nil)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, pairs, test.ShouldBeEmpty)
+		})
+	}
+}
+
+func TestExtrinsics(t *testing.T) {
+	ctx := context.Background()
+	svc := newService(t, &Config{Kind: calibration.KindExtrinsics, Component: "cam", ReferenceFrame: "table"}, nil)
+
+	_, err := svc.Collect(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	sensor := spatialmath.NewPose(r3.Vector{X: 100, Y: 200, Z: 300}, &spatialmath.OrientationVectorDegrees{OY: -1, Theta: 45})
+	for _, target := range armPoses {
+		pair := calibration.PosePair{Reference: target, Sensor: spatialmath.PoseBetween(sensor, target)}
+		test.That(t, svc.AddPair(ctx, pair, nil), test.ShouldBeNil)
+	}
+	sol, err := svc.Solve(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sol.Frame.Parent, test.ShouldEqual, "table")
+	test.That(t, sol.Frame.Translation.Sub(sensor.Point()).Norm(), test.ShouldAlmostEqual, 0, 1e-6)
+
+	// without a config file the frame cannot be written.
+	test.That(t, svc.WriteFrame(ctx, sol, nil), test.ShouldNotBeNil)
+}
@@ -0,0 +1,334 @@
+// Package calibration defines a service that calibrates where cameras and other sensors are in the
+// frame system, by hand-eye calibration against an arm or by fitting the extrinsics between a
+// reference frame and a sensor, and writes the results back into the frames of their configs.
+package calibration
+
+import (
+	"context"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = "calibration"
+
+// API is a variable that identifies the calibration resource API.
+var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoCollect    = "collect"
+	DoAddPair    = "add_pair"
+	DoListPairs  = "list_pairs"
+	DoClearPairs = "clear_pairs"
+	DoSolve      = "solve"
+	DoWriteFrame = "write_frame"
+)
+
+// Named is a helper for getting the named calibration service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// Deprecated: FromRobot is a helper for getting the named calibration service from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named calibration service
+// from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Service, error) {
+	return resource.FromProvider[Service](provider, Named(name))
+}
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Service]{})
+}
+
+// A Kind is a kind of calibration.
+type Kind string
+
+// The set of known kinds of calibration.
+const (
+	// KindEyeInHand finds where a camera mounted on an arm is relative to the arm's end. Pairs hold
+	// the pose of the arm's end in the arm's base frame and the pose of a target, fixed in the world,
+	// as seen by the camera.
+	KindEyeInHand = Kind("eye_in_hand")
+	// KindEyeToHand finds where a camera fixed in the world is relative to the base of an arm. Pairs
+	// hold the pose of the arm's end in the arm's base frame and the pose of a target, held by the
+	// arm, as seen by the camera.
+	KindEyeToHand = Kind("eye_to_hand")
+	// KindExtrinsics finds where a sensor is relative to a reference frame. Pairs hold the pose of a
+	// target in the reference frame and its pose as seen by the sensor.
+	KindExtrinsics = Kind("extrinsics")
+)
+
+// Validate ensures the kind is known.
+func (k Kind) Validate() error {
+	switch k {
+	case KindEyeInHand, KindEyeToHand, KindExtrinsics:
+		return nil
+	default:
+		return errors.Errorf("unknown calibration kind %q", k)
+	}
+}
+
+// A PosePair is a pose of a target, or of an arm's end, in a reference frame along with the pose of
+// a target seen by the sensor being calibrated at the same time. What each pose is depends on the
+// Kind of calibration.
+type PosePair struct {
+	Reference spatialmath.Pose
+	Sensor    spatialmath.Pose
+}
+
+// A Solution is where a calibrated component is in the frame system.
+type Solution struct {
+	// Frame is the frame of the component, as it is written into the component's config.
+	Frame referenceframe.LinkConfig
+	// TranslationErrorMM and RotationErrorDegs are the root mean square disagreement of the pairs
+	// with the solution, which are small for good calibrations.
+	TranslationErrorMM float64
+	RotationErrorDegs  float64
+}
+
+// A Service calibrates the frame of a component from pairs of poses.
+type Service interface {
+	resource.Resource
+	// Collect measures a pair of poses, such as the pose of an arm's end and of a target seen by a
+	// camera, and adds it to the pairs the service solves with.
+	Collect(ctx context.Context, extra map[string]interface{}) (PosePair, error)
+	// AddPair adds a pair measured elsewhere to the pairs the service solves with.
+	AddPair(ctx context.Context, pair PosePair, extra map[string]interface{}) error
+	// Pairs returns the pairs the service solves with, in the order they were added.
+	Pairs(ctx context.Context, extra map[string]interface{}) ([]PosePair, error)
+	// ClearPairs removes every pair.
+	ClearPairs(ctx context.Context, extra map[string]interface{}) error
+	// Solve calibrates the component from the pairs.
+	Solve(ctx context.Context, extra map[string]interface{}) (Solution, error)
+	// WriteFrame writes the frame of a solution into the config of its component.
+	WriteFrame(ctx context.Context, solution Solution, extra map[string]interface{}) error
+}
+
+// poseMessage is the form a pose takes in DoCommand commands and responses, with its orientation as
+// an orientation vector in degrees.
+type poseMessage struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Z     float64 `json:"z"`
+	OX    float64 `json:"o_x"`
+	OY    float64 `json:"o_y"`
+	OZ    float64 `json:"o_z"`
+	Theta float64 `json:"theta"`
+}
+
+func poseToMessage(p spatialmath.Pose) poseMessage {
+	ov := p.Orientation().OrientationVectorDegrees()
+	pt := p.Point()
+	return poseMessage{X: pt.X, Y: pt.Y, Z: pt.Z, OX: ov.OX, OY: ov.OY, OZ: ov.OZ, Theta: ov.Theta}
+}
+
+func (m poseMessage) pose() spatialmath.Pose {
+	return spatialmath.NewPose(
+		r3.Vector{X: m.X, Y: m.Y, Z: m.Z},
+		&spatialmath.OrientationVectorDegrees{OX: m.OX, OY: m.OY, OZ: m.OZ, Theta: m.Theta},
+	)
+}
+
+type pairMessage struct {
+	Reference poseMessage `json:"reference"`
+	Sensor    poseMessage `json:"sensor"`
+}
+
+func pairToMessage(p PosePair) pairMessage {
+	return pairMessage{Reference: poseToMessage(p.Reference), Sensor: poseToMessage(p.Sensor)}
+}
+
+func (m pairMessage) pair() PosePair {
+	return PosePair{Reference: m.Reference.pose(), Sensor: m.Sensor.pose()}
+}
+
+type addPairCommand struct {
+	AddPair pairMessage            `json:"add_pair"`
+	Extra   map[string]interface{} `json:"extra,omitempty"`
+}
+
+type pairsResponse struct {
+	Pairs []pairMessage `json:"pairs"`
+}
+
+type solutionMessage struct {
+	Frame              referenceframe.LinkConfig `json:"frame"`
+	TranslationErrorMM float64                   `json:"translation_error_mm"`
+	RotationErrorDegs  float64                   `json:"rotation_error_degs"`
+}
+
+func (m *solutionMessage) Validate() error {
+	if m.Frame.ID == "" {
+		return errors.New("the frame of a solution must have an id")
+	}
+	if m.Frame.Parent == "" {
+		return errors.New("the frame of a solution must have a parent")
+	}
+	return nil
+}
+
+type writeFrameCommand struct {
+	WriteFrame solutionMessage        `json:"write_frame"`
+	Extra      map[string]interface{} `json:"extra,omitempty"`
+}
+
+func (c *writeFrameCommand) Validate() error {
+	return c.WriteFrame.Validate()
+}
+
+// HandleCalibrationCommand services the calibration DoCommand keys using the given Service, so that
+// components can be calibrated through DoCommand by callers that only have a generic resource
+// handle, such as the client of a calibration service provided by a module. It returns false if cmd
+// does not contain any of them so that it can be chained from a DoCommand implementation.
+//
+// DoCollect responds with the pair collected, DoListPairs with "pairs", and DoSolve with a solution
+// holding a "frame" in the form of a component's frame config. DoAddPair takes a pair with
+// "reference" and "sensor" poses, and DoWriteFrame takes a solution.
+func HandleCalibrationCommand(
+	ctx context.Context,
+	svc Service,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	extra, _ := cmd["extra"].(map[string]interface{})
+	switch {
+	case cmd[DoCollect] != nil:
+		pair, err := svc.Collect(ctx, extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(pairToMessage(pair))
+		return resp, true, err
+	case cmd[DoAddPair] != nil:
+		c, err := resource.DecodeDoCommand[addPairCommand](cmd)
+		if err != nil {
+			return nil, true, errors.Wrapf(err, "invalid %s command", DoAddPair)
+		}
+		return map[string]interface{}{}, true, svc.AddPair(ctx, c.AddPair.pair(), c.Extra)
+	case cmd[DoListPairs] != nil:
+		pairs, err := svc.Pairs(ctx, extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp := pairsResponse{Pairs: make([]pairMessage, 0, len(pairs))}
+		for _, p := range pairs {
+			resp.Pairs = append(resp.Pairs, pairToMessage(p))
+		}
+		encoded, err := resource.EncodeDoCommand(resp)
+		return encoded, true, err
+	case cmd[DoClearPairs] != nil:
+		return map[string]interface{}{}, true, svc.ClearPairs(ctx, extra)
+	case cmd[DoSolve] != nil:
+		sol, err := svc.Solve(ctx, extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(solutionMessage(sol))
+		return resp, true, err
+	case cmd[DoWriteFrame] != nil:
+		c, err := resource.DecodeDoCommand[writeFrameCommand](cmd)
+		if err != nil {
+			return nil, true, errors.Wrapf(err, "invalid %s command", DoWriteFrame)
+		}
+		return map[string]interface{}{}, true, svc.WriteFrame(ctx, Solution(c.WriteFrame), c.Extra)
+	default:
+		return nil, false, nil
+	}
+}
+
+// FromResource returns a Service that calibrates through the DoCommand of res, which must handle the
+// calibration DoCommand keys as HandleCalibrationCommand does. It lets calibration services be
+// provided by modules as generic resources.
+func FromResource(res resource.Resource) Service {
+	if svc, ok := res.(Service); ok {
+		return svc
+	}
+	return &doCommandService{Resource: res}
+}
+
+type doCommandService struct {
+	resource.Resource
+}
+
+type extraCommand struct {
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+func (s *doCommandService) do(ctx context.Context, key string, extra map[string]interface{}) (map[string]interface{}, error) {
+	cmd, err := resource.EncodeDoCommand(extraCommand{Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	cmd[key] = true
+	return s.DoCommand(ctx, cmd)
+}
+
+func (s *doCommandService) Collect(ctx context.Context, extra map[string]interface{}) (PosePair, error) {
+	resp, err := s.do(ctx, DoCollect, extra)
+	if err != nil {
+		return PosePair{}, err
+	}
+	msg, err := resource.DecodeDoCommand[pairMessage](resp)
+	if err != nil {
+		return PosePair{}, err
+	}
+	return msg.pair(), nil
+}
+
+func (s *doCommandService) AddPair(ctx context.Context, pair PosePair, extra map[string]interface{}) error {
+	_, err := resource.DoCommandAs[addPairCommand, map[string]interface{}](
+		ctx, s, addPairCommand{AddPair: pairToMessage(pair), Extra: extra})
+	return err
+}
+
+func (s *doCommandService) Pairs(ctx context.Context, extra map[string]interface{}) ([]PosePair, error) {
+	resp, err := s.do(ctx, DoListPairs, extra)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := resource.DecodeDoCommand[pairsResponse](resp)
+	if err != nil {
+		return nil, err
+	}
+	pairs := make([]PosePair, 0, len(msg.Pairs))
+	for _, p := range msg.Pairs {
+		pairs = append(pairs, p.pair())
+	}
+	return pairs, nil
+}
+
+func (s *doCommandService) ClearPairs(ctx context.Context, extra map[string]interface{}) error {
+	_, err := s.do(ctx, DoClearPairs, extra)
+	return err
+}
+
+func (s *doCommandService) Solve(ctx context.Context, extra map[string]interface{}) (Solution, error) {
+	resp, err := s.do(ctx, DoSolve, extra)
+	if err != nil {
+		return Solution{}, err
+	}
+	msg, err := resource.DecodeDoCommand[solutionMessage](resp)
+	if err != nil {
+		return Solution{}, err
+	}
+	return Solution(msg), nil
+}
+
+func (s *doCommandService) WriteFrame(ctx context.Context, solution Solution, extra map[string]interface{}) error {
+	_, err := resource.DoCommandAs[writeFrameCommand, map[string]interface{}](
+		ctx, s, writeFrameCommand{WriteFrame: solutionMessage(solution), Extra: extra})
+	return err
+}
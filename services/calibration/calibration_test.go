@@ -0,0 +1,185 @@
+package calibration
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func randomPose(rnd *rand.Rand) spatialmath.Pose {
+	return spatialmath.NewPose(
+		r3.Vector{X: rnd.Float64()*400 - 200, Y: rnd.Float64()*400 - 200, Z: rnd.Float64() * 400},
+		&spatialmath.OrientationVectorDegrees{
+			OX: rnd.Float64() - .5, OY: rnd.Float64() - .5, OZ: rnd.Float64() + .5, Theta: rnd.Float64()*180 - 90,
+		},
+	)
+}
+
+// makePairs returns pairs of the arm's end and the target seen by the camera, for a camera whose
+// pose is camera and a target whose pose is target for the kind of calibration.
+func makePairs(kind Kind, camera, target spatialmath.Pose, n int) []PosePair {
+	rnd := rand.New(rand.NewSource(1))
+	pairs := make([]PosePair, 0, n)
+	for i := 0; i < n; i++ {
+		end := randomPose(rnd)
+		var seen spatialmath.Pose
+		switch kind {
+		case KindEyeInHand:
+			// target = end * camera * seen
+			seen = spatialmath.PoseBetween(spatialmath.Compose(end, camera), target)
+		case KindEyeToHand:
+			// target = inv(end) * camera * seen
+			seen = spatialmath.PoseBetween(spatialmath.Compose(spatialmath.PoseInverse(end), camera), target)
+		default:
+			// end = camera * seen
+			seen = spatialmath.PoseBetween(camera, end)
+		}
+		pairs = append(pairs, PosePair{Reference: end, Sensor: seen})
+	}
+	return pairs
+}
+
+func TestSolvePose(t *testing.T) {
+	camera := spatialmath.NewPose(r3.Vector{X: 30, Y: -10, Z: 80}, &spatialmath.OrientationVectorDegrees{OY: 1, Theta: 30})
+	target := spatialmath.NewPose(r3.Vector{X: 500, Y: 100, Z: 0}, &spatialmath.OrientationVectorDegrees{OZ: -1, Theta: 90})
+
+	for _, kind := range []Kind{KindEyeInHand, KindEyeToHand, KindExtrinsics} {
+		t.Run(string(kind), func(t *testing.T) {
+			pairs := makePairs(kind, camera, target, 8)
+			pose, err := SolvePose(kind, pairs)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, spatialmath.PoseAlmostEqualEps(pose, camera, 1e-6), test.ShouldBeTrue)
+
+			mm, degs := PairErrors(kind, pose, pairs)
+			test.That(t, mm, test.ShouldAlmostEqual, 0, 1e-6)
+			test.That(t, degs, test.ShouldAlmostEqual, 0, 1e-6)
+
+			// a wrong pose disagrees with the pairs.
+			mm, _ = PairErrors(kind, spatialmath.NewPoseFromPoint(r3.Vector{X: 10}), pairs)
+			test.That(t, mm, test.ShouldBeGreaterThan, 1)
+		})
+	}
+
+	_, err := SolvePose(KindEyeInHand, makePairs(KindEyeInHand, camera, target, 2))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = SolvePose(KindExtrinsics, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = SolvePose("stereo", makePairs(KindExtrinsics, camera, target, 3))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// moving the arm about only one axis cannot determine where the camera is.
+	pairs := make([]PosePair, 0, 4)
+	for i := 0; i < 4; i++ {
+		end := spatialmath.NewPose(r3.Vector{X: float64(i) * 10}, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: float64(i) * 20})
+		seen := spatialmath.PoseBetween(spatialmath.Compose(end, camera), target)
+		pairs = append(pairs, PosePair{Reference: end, Sensor: seen})
+	}
+	_, err = SolvePose(KindEyeInHand, pairs)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "two different axes")
+}
+
+type fakeService struct {
+	resource.Named
+	resource.TriviallyCloseable
+	resource.TriviallyReconfigurable
+	pairs   []PosePair
+	written Solution
+}
+
+func (s *fakeService) Collect(ctx context.Context, extra map[string]interface{}) (PosePair, error) {
+	pair := PosePair{Reference: spatialmath.NewPoseFromPoint(r3.Vector{X: 1}), Sensor: spatialmath.NewZeroPose()}
+	s.pairs = append(s.pairs, pair)
+	return pair, nil
+}
+
+func (s *fakeService) AddPair(ctx context.Context, pair PosePair, extra map[string]interface{}) error {
+	s.pairs = append(s.pairs, pair)
+	return nil
+}
+
+func (s *fakeService) Pairs(ctx context.Context, extra map[string]interface{}) ([]PosePair, error) {
+	return s.pairs, nil
+}
+
+func (s *fakeService) ClearPairs(ctx context.Context, extra map[string]interface{}) error {
+	s.pairs = nil
+	return nil
+}
+
+func (s *fakeService) Solve(ctx context.Context, extra map[string]interface{}) (Solution, error) {
+	pose, err := SolvePose(KindExtrinsics, s.pairs)
+	if err != nil {
+		return Solution{}, err
+	}
+	o, err := spatialmath.NewOrientationConfig(pose.Orientation())
+	if err != nil {
+		return Solution{}, err
+	}
+	return Solution{Frame: referenceframe.LinkConfig{ID: "cam", Parent: "world", Translation: pose.Point(), Orientation: o}, TranslationErrorMM: 1}, nil
+}
+
+func (s *fakeService) WriteFrame(ctx context.Context, solution Solution, extra map[string]interface{}) error {
+	s.written = solution
+	return nil
+}
+
+func (s *fakeService) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	resp, _, err := HandleCalibrationCommand(ctx, s, cmd)
+	return resp, err
+}
+
+// genericResource hides that a resource is a Service, as the client of a modular service does.
+type genericResource struct {
+	resource.Resource
+}
+
+func TestFromResource(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeService{Named: Named("cal").AsNamed()}
+	svc := FromResource(genericResource{fake})
+	_, isFake := svc.(*fakeService)
+	test.That(t, isFake, test.ShouldBeFalse)
+
+	_, err := svc.Solve(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	pair, err := svc.Collect(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pair.Reference.Point(), test.ShouldResemble, r3.Vector{X: 1})
+
+	added := PosePair{
+		Reference: spatialmath.NewPose(r3.Vector{Y: 2}, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: 45}),
+		Sensor:    spatialmath.NewPoseFromPoint(r3.Vector{Z: 3}),
+	}
+	test.That(t, svc.AddPair(ctx, added, nil), test.ShouldBeNil)
+	pairs, err := svc.Pairs(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pairs, test.ShouldHaveLength, 2)
+	test.That(t, spatialmath.PoseAlmostEqual(pairs[1].Reference, added.Reference), test.ShouldBeTrue)
+	test.That(t, spatialmath.PoseAlmostEqual(pairs[1].Sensor, added.Sensor), test.ShouldBeTrue)
+
+	sol, err := svc.Solve(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, sol.Frame.ID, test.ShouldEqual, "cam")
+	test.That(t, sol.Frame.Parent, test.ShouldEqual, "world")
+	test.That(t, sol.TranslationErrorMM, test.ShouldEqual, 1)
+
+	test.That(t, svc.WriteFrame(ctx, sol, nil), test.ShouldBeNil)
+	test.That(t, fake.written.Frame.Translation, test.ShouldResemble, sol.Frame.Translation)
+	test.That(t, svc.WriteFrame(ctx, Solution{}, nil), test.ShouldNotBeNil)
+
+	test.That(t, svc.ClearPairs(ctx, nil), test.ShouldBeNil)
+	test.That(t, fake.pairs, test.ShouldBeEmpty)
+
+	_, handled, err := HandleCalibrationCommand(ctx, fake, map[string]interface{}{"other": true})
+	test.That(t, handled, test.ShouldBeFalse)
+	test.That(t, err, test.ShouldBeNil)
+}
@@ -0,0 +1,7 @@
+// Package register registers all relevant calibration models and also API specific functions
+package register
+
+import (
+	// for calibration models.
+	_ "go.viam.com/rdk/services/calibration/builtin"
+)
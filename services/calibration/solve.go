@@ -0,0 +1,223 @@
+package calibration
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// minSingularValueRatio is how small the second largest singular value of the rotation correlation
+// of a hand-eye calibration may be relative to the largest before the motions are considered to
+// rotate about too few axes to solve with.
+const minSingularValueRatio = 1e-3
+
+// SolvePose returns the pose that best explains the pairs for the kind of calibration: the pose of a
+// camera relative to the end of an arm for KindEyeInHand, relative to the base of an arm for
+// KindEyeToHand, or of a sensor relative to the reference frame for KindExtrinsics.
+//
+// Hand-eye calibrations solve AX=XB over the motions between every two pairs, rotation first and
+// then translation by least squares, so the arm must rotate about at least two different axes
+// across the pairs.
+func SolvePose(kind Kind, pairs []PosePair) (spatialmath.Pose, error) {
+	if err := kind.Validate(); err != nil {
+		return nil, err
+	}
+	for i, p := range pairs {
+		if p.Reference == nil || p.Sensor == nil {
+			return nil, errors.Errorf("pair %d is missing a pose", i)
+		}
+	}
+	if kind == KindExtrinsics {
+		return solveExtrinsics(pairs)
+	}
+	return solveHandEye(kind, pairs)
+}
+
+// PairErrors returns the root mean square disagreement, in millimeters and in degrees, of the pairs
+// with a pose solved for them. For extrinsics it is how far the target seen by the sensor is from
+// where the reference frame has it. For hand-eye calibrations each pair implies a pose of the target
+// that should not change, such as its pose in the arm's base when the camera is on the arm, and it
+// is how far those poses are from their mean.
+func PairErrors(kind Kind, pose spatialmath.Pose, pairs []PosePair) (float64, float64) {
+	if len(pairs) == 0 {
+		return 0, 0
+	}
+	deviations := make([]spatialmath.Pose, 0, len(pairs))
+	if kind == KindExtrinsics {
+		for _, p := range pairs {
+			deviations = append(deviations, spatialmath.PoseBetween(spatialmath.Compose(pose, p.Sensor), p.Reference))
+		}
+	} else {
+		fixed := make([]spatialmath.Pose, 0, len(pairs))
+		for _, p := range pairs {
+			reference := p.Reference
+			if kind == KindEyeToHand {
+				reference = spatialmath.PoseInverse(reference)
+			}
+			fixed = append(fixed, spatialmath.Compose(spatialmath.Compose(reference, pose), p.Sensor))
+		}
+		mean := meanPose(fixed)
+		for _, p := range fixed {
+			deviations = append(deviations, spatialmath.PoseBetween(mean, p))
+		}
+	}
+	var sqMM, sqRads float64
+	for _, d := range deviations {
+		sqMM += d.Point().Norm2()
+		theta := d.Orientation().AxisAngles().Theta
+		sqRads += theta * theta
+	}
+	n := float64(len(deviations))
+	return math.Sqrt(sqMM / n), utils.RadToDeg(math.Sqrt(sqRads / n))
+}
+
+func solveHandEye(kind Kind, pairs []PosePair) (spatialmath.Pose, error) {
+	if len(pairs) < 3 {
+		return nil, errors.Errorf("hand-eye calibration needs at least 3 pairs but got %d", len(pairs))
+	}
+	type motion struct{ a, b spatialmath.Pose }
+	motions := make([]motion, 0, len(pairs)*(len(pairs)-1)/2)
+	for i := range pairs {
+		for j := i + 1; j < len(pairs); j++ {
+			var a spatialmath.Pose
+			if kind == KindEyeInHand {
+				a = spatialmath.PoseBetween(pairs[j].Reference, pairs[i].Reference)
+			} else {
+				a = spatialmath.Compose(pairs[j].Reference, spatialmath.PoseInverse(pairs[i].Reference))
+			}
+			b := spatialmath.Compose(pairs[j].Sensor, spatialmath.PoseInverse(pairs[i].Sensor))
+			motions = append(motions, motion{a: a, b: b})
+		}
+	}
+
+	// the rotation axes of A and B are related by the rotation of X, so it is the rotation that best
+	// aligns them.
+	corr := mat.NewDense(3, 3, nil)
+	for _, m := range motions {
+		alpha := spatialmath.QuatToR3AA(m.a.Orientation().Quaternion())
+		beta := spatialmath.QuatToR3AA(m.b.Orientation().Quaternion())
+		corr.Add(corr, outer(alpha, beta))
+	}
+	var svd mat.SVD
+	if !svd.Factorize(corr, mat.SVDFull) {
+		return nil, errors.New("could not factorize the rotations of the pairs")
+	}
+	values := svd.Values(nil)
+	if values[0] == 0 || values[1] < minSingularValueRatio*values[0] {
+		return nil, errors.New("the arm must rotate about at least two different axes across the pairs")
+	}
+	rot, err := nearestRotation(corr)
+	if err != nil {
+		return nil, err
+	}
+
+	// with the rotation known, (R_A - I) t_X = R_X t_B - t_A is linear in the translation of X.
+	lhs := mat.NewDense(3*len(motions), 3, nil)
+	rhs := mat.NewDense(3*len(motions), 1, nil)
+	for k, m := range motions {
+		rotA := m.a.Orientation().RotationMatrix()
+		tA := m.a.Point()
+		rotTB := rot.Mul(m.b.Point())
+		for r := 0; r < 3; r++ {
+			for c := 0; c < 3; c++ {
+				v := rotA.At(r, c)
+				if r == c {
+					v--
+				}
+				lhs.Set(3*k+r, c, v)
+			}
+		}
+		rhs.Set(3*k, 0, rotTB.X-tA.X)
+		rhs.Set(3*k+1, 0, rotTB.Y-tA.Y)
+		rhs.Set(3*k+2, 0, rotTB.Z-tA.Z)
+	}
+	var t mat.Dense
+	if err := t.Solve(lhs, rhs); err != nil {
+		return nil, errors.Wrap(err, "could not solve for the translation")
+	}
+	return spatialmath.NewPose(r3.Vector{X: t.At(0, 0), Y: t.At(1, 0), Z: t.At(2, 0)}, rot), nil
+}
+
+func solveExtrinsics(pairs []PosePair) (spatialmath.Pose, error) {
+	if len(pairs) == 0 {
+		return nil, errors.New("extrinsics calibration needs at least 1 pair")
+	}
+	poses := make([]spatialmath.Pose, 0, len(pairs))
+	for _, p := range pairs {
+		poses = append(poses, spatialmath.Compose(p.Reference, spatialmath.PoseInverse(p.Sensor)))
+	}
+	rot, err := meanRotation(poses)
+	if err != nil {
+		return nil, err
+	}
+	// the translation that best fits the mean rotation is the mean of the translations it implies.
+	var sum r3.Vector
+	for _, p := range pairs {
+		sum = sum.Add(p.Reference.Point().Sub(rot.Mul(p.Sensor.Point())))
+	}
+	return spatialmath.NewPose(sum.Mul(1/float64(len(pairs))), rot), nil
+}
+
+// meanPose returns the mean of poses, with the chordal mean of their rotations.
+func meanPose(poses []spatialmath.Pose) spatialmath.Pose {
+	var sum r3.Vector
+	for _, p := range poses {
+		sum = sum.Add(p.Point())
+	}
+	rot, err := meanRotation(poses)
+	if err != nil {
+		return spatialmath.NewPoseFromPoint(sum.Mul(1 / float64(len(poses))))
+	}
+	return spatialmath.NewPose(sum.Mul(1/float64(len(poses))), rot)
+}
+
+// meanRotation returns the rotation nearest to the sum of the rotation matrices of poses.
+func meanRotation(poses []spatialmath.Pose) (*spatialmath.RotationMatrix, error) {
+	sum := mat.NewDense(3, 3, nil)
+	for _, p := range poses {
+		rm := p.Orientation().RotationMatrix()
+		for r := 0; r < 3; r++ {
+			for c := 0; c < 3; c++ {
+				sum.Set(r, c, sum.At(r, c)+rm.At(r, c))
+			}
+		}
+	}
+	return nearestRotation(sum)
+}
+
+// nearestRotation returns the rotation R maximizing trace(Rᵀ m).
+func nearestRotation(m mat.Matrix) (*spatialmath.RotationMatrix, error) {
+	var svd mat.SVD
+	if !svd.Factorize(m, mat.SVDFull) {
+		return nil, errors.New("could not factorize rotations")
+	}
+	var u, v, rot mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	rot.Mul(&u, v.T())
+	if mat.Det(&rot) < 0 {
+		// flip the axis of the smallest singular value so the result is a rotation, not a reflection.
+		u.Set(0, 2, -u.At(0, 2))
+		u.Set(1, 2, -u.At(1, 2))
+		u.Set(2, 2, -u.At(2, 2))
+		rot.Mul(&u, v.T())
+	}
+	data := make([]float64, 0, 9)
+	for r := 0; r < 3; r++ {
+		data = append(data, mat.Row(nil, r, &rot)...)
+	}
+	return spatialmath.NewRotationMatrix(data)
+}
+
+func outer(a, b r3.Vector) *mat.Dense {
+	return mat.NewDense(3, 3, []float64{
+		a.X * b.X, a.X * b.Y, a.X * b.Z,
+		a.Y * b.X, a.Y * b.Y, a.Y * b.Z,
+		a.Z * b.X, a.Z * b.Y, a.Z * b.Z,
+	})
+}
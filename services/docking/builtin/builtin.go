@@ -0,0 +1,535 @@
+// Package builtin implements a docking service that visually servos a base onto its charger.
+package builtin
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/docking"
+	"go.viam.com/rdk/services/vision"
+)
+
+const (
+	defaultMaxAttempts            = 3
+	defaultRetryBackoffMS         = 1000
+	defaultMaxStepsPerAttempt     = 50
+	defaultApproachStepMM         = 100
+	defaultContactDistanceMM      = 50
+	defaultUndockDistanceMM       = 300
+	defaultLinearMMPerSec         = 100.
+	defaultAngularDegsPerSec      = 30.
+	defaultHorizontalFOVDegs      = 60.
+	defaultCenterTolerance        = 0.05
+	defaultContactProximity       = 0.6
+	defaultChargingCurrentAmps    = 0.1
+	defaultContactTimeoutMS       = 2000
+	defaultMinConfidence          = 0.5
+	contactPollInterval           = 50 * time.Millisecond
+	irReadingOffset               = "offset"
+	irReadingProximity            = "proximity"
+	irReadingDetected             = "detected"
+	finalApproachSpeedScaleFactor = 0.5
+)
+
+var errDockNotFound = errors.New("could not find the dock")
+
+func init() {
+	resource.RegisterService(docking.API, resource.DefaultServiceModel, resource.Registration[docking.Service, *Config]{
+		Constructor: NewBuiltIn,
+	})
+}
+
+// Config describes how to configure the service. The dock is located either with a vision service
+// detecting a fiducial in a camera's images, or with an IR beacon sensor whose readings report the
+// beacon's normalized horizontal "offset" in [-0.5, 0.5], its "proximity" in [0, 1], and whether it is
+// "detected".
+type Config struct {
+	BaseName          string `json:"base"`
+	PowerSensorName   string `json:"power_sensor"`
+	VisionServiceName string `json:"vision_service,omitempty"`
+	CameraName        string `json:"camera,omitempty"`
+	DockLabel         string `json:"dock_label,omitempty"`
+	IRSensorName      string `json:"ir_sensor,omitempty"`
+
+	MinConfidence       float64 `json:"min_confidence,omitempty"`
+	ChargingCurrentAmps float64 `json:"charging_current_amps,omitempty"`
+	ContactTimeoutMS    int     `json:"contact_timeout_ms,omitempty"`
+
+	MaxAttempts        int `json:"max_attempts,omitempty"`
+	RetryBackoffMS     int `json:"retry_backoff_ms,omitempty"`
+	MaxStepsPerAttempt int `json:"max_steps_per_attempt,omitempty"`
+
+	ApproachStepMM    int     `json:"approach_step_mm,omitempty"`
+	ContactDistanceMM int     `json:"contact_distance_mm,omitempty"`
+	UndockDistanceMM  int     `json:"undock_distance_mm,omitempty"`
+	LinearMMPerSec    float64 `json:"linear_mm_per_sec,omitempty"`
+	AngularDegsPerSec float64 `json:"angular_degs_per_sec,omitempty"`
+	HorizontalFOVDegs float64 `json:"horizontal_fov_degs,omitempty"`
+	// CenterTolerance is how far from the center of view, as a fraction of its width, the dock may be
+	// before the base turns toward it.
+	CenterTolerance float64 `json:"center_tolerance,omitempty"`
+	// ContactProximity is the proximity at which the base makes its final approach onto the contacts.
+	// For vision detection it is the fraction of the image width spanned by the dock's bounding box.
+	ContactProximity float64 `json:"contact_proximity,omitempty"`
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.BaseName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "base")
+	}
+	if conf.PowerSensorName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "power_sensor")
+	}
+	deps := []string{conf.BaseName, conf.PowerSensorName}
+
+	switch {
+	case conf.IRSensorName != "" && conf.VisionServiceName != "":
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("only one of vision_service or ir_sensor may be used to locate the dock"))
+	case conf.IRSensorName != "":
+		deps = append(deps, conf.IRSensorName)
+	case conf.VisionServiceName != "":
+		if conf.CameraName == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "camera")
+		}
+		deps = append(deps, conf.VisionServiceName)
+	default:
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("one of vision_service or ir_sensor is required to locate the dock"))
+	}
+
+	if conf.MaxAttempts < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("max_attempts cannot be negative"))
+	}
+	if conf.CenterTolerance < 0 || conf.CenterTolerance >= 0.5 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("center_tolerance must be in [0, 0.5)"))
+	}
+	if conf.ContactProximity < 0 || conf.ContactProximity > 1 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("contact_proximity must be in [0, 1]"))
+	}
+	return deps, nil, nil
+}
+
+// observation is a sighting of the dock. offset is the horizontal position of the dock relative to the
+// center of view in [-0.5, 0.5], with positive values to the right, and proximity grows from 0 to 1 as
+// the base nears the dock.
+type observation struct {
+	offset    float64
+	proximity float64
+}
+
+type dockDetector interface {
+	// detect returns nil if the dock is not in view.
+	detect(ctx context.Context) (*observation, error)
+}
+
+type visionDetector struct {
+	vis        vision.Service
+	cameraName string
+	label      string
+	minScore   float64
+}
+
+func (d *visionDetector) detect(ctx context.Context) (*observation, error) {
+	dets, err := d.vis.DetectionsFromCamera(ctx, d.cameraName, nil)
+	if err != nil {
+		return nil, err
+	}
+	var best *observation
+	bestScore := 0.
+	for _, det := range dets {
+		if d.label != "" && det.Label() != d.label {
+			continue
+		}
+		if det.Score() < d.minScore || det.Score() <= bestScore {
+			continue
+		}
+		box := det.NormalizedBoundingBox()
+		if len(box) != 4 {
+			continue
+		}
+		bestScore = det.Score()
+		best = &observation{offset: (box[0]+box[2])/2 - 0.5, proximity: box[2] - box[0]}
+	}
+	return best, nil
+}
+
+type irDetector struct {
+	sensor sensor.Sensor
+}
+
+func (d *irDetector) detect(ctx context.Context) (*observation, error) {
+	readings, err := d.sensor.Readings(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if detected, ok := readings[irReadingDetected].(bool); ok && !detected {
+		return nil, nil
+	}
+	offset, ok := readings[irReadingOffset].(float64)
+	if !ok {
+		return nil, nil
+	}
+	proximity, ok := readings[irReadingProximity].(float64)
+	if !ok {
+		return nil, errors.Errorf("ir sensor readings are missing %q", irReadingProximity)
+	}
+	return &observation{offset: offset, proximity: proximity}, nil
+}
+
+type builtIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf     *Config
+	base     base.Base
+	power    powersensor.PowerSensor
+	detector dockDetector
+	logger   logging.Logger
+
+	// closeCtx is canceled on Close to stop any dock or undock operation that is running.
+	closeCtx   context.Context
+	cancelFunc context.CancelFunc
+	activeOps  sync.WaitGroup
+
+	mu     sync.Mutex
+	busy   bool
+	status docking.Status
+}
+
+// NewBuiltIn returns a new docking service.
+func NewBuiltIn(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (docking.Service, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b, err := base.FromProvider(deps, svcConfig.BaseName)
+	if err != nil {
+		return nil, err
+	}
+	ps, err := powersensor.FromProvider(deps, svcConfig.PowerSensorName)
+	if err != nil {
+		return nil, err
+	}
+
+	var detector dockDetector
+	if svcConfig.IRSensorName != "" {
+		s, err := sensor.FromProvider(deps, svcConfig.IRSensorName)
+		if err != nil {
+			return nil, err
+		}
+		detector = &irDetector{sensor: s}
+	} else {
+		vis, err := vision.FromProvider(deps, svcConfig.VisionServiceName)
+		if err != nil {
+			return nil, err
+		}
+		minScore := svcConfig.MinConfidence
+		if minScore == 0 {
+			minScore = defaultMinConfidence
+		}
+		detector = &visionDetector{
+			vis:        vis,
+			cameraName: svcConfig.CameraName,
+			label:      svcConfig.DockLabel,
+			minScore:   minScore,
+		}
+	}
+
+	closeCtx, cancelFunc := context.WithCancel(context.Background())
+	svc := &builtIn{
+		Named:      conf.ResourceName().AsNamed(),
+		conf:       withDefaults(*svcConfig),
+		base:       b,
+		power:      ps,
+		detector:   detector,
+		logger:     logger,
+		closeCtx:   closeCtx,
+		cancelFunc: cancelFunc,
+		status:     docking.Status{State: docking.StateUndocked},
+	}
+	// start out docked if the base was left on its charger
+	if charging, err := svc.charging(ctx); err == nil && charging {
+		svc.status.State = docking.StateDocked
+	}
+	return svc, nil
+}
+
+func withDefaults(conf Config) *Config {
+	setInt := func(v *int, def int) {
+		if *v == 0 {
+			*v = def
+		}
+	}
+	setFloat := func(v *float64, def float64) {
+		if *v == 0 {
+			*v = def
+		}
+	}
+	setInt(&conf.MaxAttempts, defaultMaxAttempts)
+	setInt(&conf.RetryBackoffMS, defaultRetryBackoffMS)
+	setInt(&conf.MaxStepsPerAttempt, defaultMaxStepsPerAttempt)
+	setInt(&conf.ApproachStepMM, defaultApproachStepMM)
+	setInt(&conf.ContactDistanceMM, defaultContactDistanceMM)
+	setInt(&conf.UndockDistanceMM, defaultUndockDistanceMM)
+	setInt(&conf.ContactTimeoutMS, defaultContactTimeoutMS)
+	setFloat(&conf.LinearMMPerSec, defaultLinearMMPerSec)
+	setFloat(&conf.AngularDegsPerSec, defaultAngularDegsPerSec)
+	setFloat(&conf.HorizontalFOVDegs, defaultHorizontalFOVDegs)
+	setFloat(&conf.CenterTolerance, defaultCenterTolerance)
+	setFloat(&conf.ContactProximity, defaultContactProximity)
+	setFloat(&conf.ChargingCurrentAmps, defaultChargingCurrentAmps)
+	return &conf
+}
+
+// begin marks the start of a dock or undock operation, failing if one is already running or the
+// service is closed. The returned context is canceled when the service is closed and must be
+// released by passing the returned function to end.
+func (svc *builtIn) begin(ctx context.Context, state docking.State) (context.Context, func(), error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.busy {
+		return nil, nil, errors.Errorf("cannot start while the docking service is %s", svc.status.State)
+	}
+	if err := svc.closeCtx.Err(); err != nil {
+		return nil, nil, errors.Wrap(err, "docking service is closed")
+	}
+	svc.busy = true
+	svc.status.State = state
+	svc.status.LastError = ""
+
+	svc.activeOps.Add(1)
+	opCtx, cancel := context.WithCancel(ctx)
+	stopOnClose := context.AfterFunc(svc.closeCtx, cancel)
+	return opCtx, func() {
+		stopOnClose()
+		cancel()
+		svc.activeOps.Done()
+	}, nil
+}
+
+func (svc *builtIn) end(release func(), state docking.State, err error) error {
+	svc.mu.Lock()
+	svc.busy = false
+	svc.status.State = state
+	if err != nil {
+		svc.status.LastError = err.Error()
+	}
+	svc.mu.Unlock()
+	release()
+	return err
+}
+
+// Dock approaches the dock and verifies electrical contact, backing off and retrying up to the
+// configured number of attempts.
+func (svc *builtIn) Dock(ctx context.Context, extra map[string]interface{}) error {
+	if charging, err := svc.charging(ctx); err == nil && charging {
+		svc.mu.Lock()
+		if !svc.busy {
+			svc.status.State = docking.StateDocked
+		}
+		svc.mu.Unlock()
+		return nil
+	}
+	ctx, release, err := svc.begin(ctx, docking.StateApproaching)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= svc.conf.MaxAttempts; attempt++ {
+		svc.mu.Lock()
+		svc.status.Attempts = attempt
+		svc.mu.Unlock()
+
+		lastErr = svc.approach(ctx)
+		if lastErr == nil {
+			return svc.end(release, docking.StateDocked, nil)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		svc.logger.CWarnf(ctx, "docking attempt %d of %d failed: %v", attempt, svc.conf.MaxAttempts, lastErr)
+		if attempt == svc.conf.MaxAttempts {
+			break
+		}
+		// back away from the dock to get a clear view of it before trying again
+		if err := svc.base.MoveStraight(ctx, -svc.conf.UndockDistanceMM, svc.conf.LinearMMPerSec, nil); err != nil {
+			lastErr = err
+			break
+		}
+		if !utils.SelectContextOrWait(ctx, time.Duration(svc.conf.RetryBackoffMS)*time.Millisecond) {
+			lastErr = ctx.Err()
+			break
+		}
+	}
+	if stopErr := svc.base.Stop(context.Background(), nil); stopErr != nil {
+		svc.logger.CWarnw(ctx, "failed to stop base after docking failed", "error", stopErr)
+	}
+	return svc.end(release, docking.StateFailed, errors.Wrap(lastErr, "failed to dock"))
+}
+
+// approach makes a single attempt at driving onto the dock.
+func (svc *builtIn) approach(ctx context.Context) error {
+	searchedDegs := 0.
+	searchStepDegs := svc.conf.HorizontalFOVDegs / 2
+	for step := 0; step < svc.conf.MaxStepsPerAttempt; step++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		obs, err := svc.detector.detect(ctx)
+		if err != nil {
+			return err
+		}
+		if obs == nil {
+			if searchedDegs >= 360 {
+				return errDockNotFound
+			}
+			searchedDegs += searchStepDegs
+			if err := svc.base.Spin(ctx, searchStepDegs, svc.conf.AngularDegsPerSec, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		searchedDegs = 0
+
+		if math.Abs(obs.offset) > svc.conf.CenterTolerance {
+			// positive spins are counterclockwise, so turn right toward docks to the right of center
+			if err := svc.base.Spin(ctx, -obs.offset*svc.conf.HorizontalFOVDegs, svc.conf.AngularDegsPerSec, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		if obs.proximity >= svc.conf.ContactProximity {
+			if err := svc.base.MoveStraight(
+				ctx, svc.conf.ContactDistanceMM, svc.conf.LinearMMPerSec*finalApproachSpeedScaleFactor, nil,
+			); err != nil {
+				return err
+			}
+			return svc.verifyContact(ctx)
+		}
+		if err := svc.base.MoveStraight(ctx, svc.conf.ApproachStepMM, svc.conf.LinearMMPerSec, nil); err != nil {
+			return err
+		}
+	}
+	return errors.Errorf("did not reach the dock within %d steps", svc.conf.MaxStepsPerAttempt)
+}
+
+// verifyContact waits for the power sensor to report charging current.
+func (svc *builtIn) verifyContact(ctx context.Context) error {
+	deadline := time.Now().Add(time.Duration(svc.conf.ContactTimeoutMS) * time.Millisecond)
+	for {
+		charging, err := svc.charging(ctx)
+		if err != nil {
+			return err
+		}
+		if charging {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.Errorf("no charging current detected within %dms of reaching the dock", svc.conf.ContactTimeoutMS)
+		}
+		if !utils.SelectContextOrWait(ctx, contactPollInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (svc *builtIn) charging(ctx context.Context) (bool, error) {
+	current, _, err := svc.power.Current(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	return current >= svc.conf.ChargingCurrentAmps, nil
+}
+
+// Undock backs the base off of the dock and checks that it is no longer charging. It does nothing
+// if the base is already undocked and fails if the base is anywhere other than on the dock, since
+// backing up blindly could drive it into something.
+func (svc *builtIn) Undock(ctx context.Context, extra map[string]interface{}) error {
+	charging, err := svc.charging(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to undock")
+	}
+	svc.mu.Lock()
+	state, busy := svc.status.State, svc.busy
+	svc.mu.Unlock()
+	if !charging && !busy && state != docking.StateDocked {
+		if state == docking.StateUndocked {
+			return nil
+		}
+		return errors.Errorf("cannot undock while the base is %s rather than docked", state)
+	}
+
+	ctx, release, err := svc.begin(ctx, docking.StateUndocking)
+	if err != nil {
+		return err
+	}
+	undockErr := svc.undock(ctx)
+	if undockErr == nil {
+		return svc.end(release, docking.StateUndocked, nil)
+	}
+	if stopErr := svc.base.Stop(context.Background(), nil); stopErr != nil {
+		svc.logger.CWarnw(ctx, "failed to stop base after undocking failed", "error", stopErr)
+	}
+	return svc.end(release, docking.StateFailed, undockErr)
+}
+
+// undock backs away from the dock and checks that charging has stopped.
+func (svc *builtIn) undock(ctx context.Context) error {
+	if err := svc.base.MoveStraight(ctx, -svc.conf.UndockDistanceMM, svc.conf.LinearMMPerSec, nil); err != nil {
+		return errors.Wrap(err, "failed to undock")
+	}
+	charging, err := svc.charging(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to undock")
+	}
+	if charging {
+		return errors.New("still charging after backing away from the dock")
+	}
+	return nil
+}
+
+func (svc *builtIn) Status(ctx context.Context) (map[string]interface{}, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return docking.StatusToMap(svc.status), nil
+}
+
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	resp, handled, err := docking.HandleDockingCommand(ctx, svc, cmd)
+	if !handled {
+		return nil, resource.ErrDoUnimplemented
+	}
+	return resp, err
+}
+
+// Close cancels any running dock or undock operation, waits for it to return, and stops the base
+// if one was running.
+func (svc *builtIn) Close(ctx context.Context) error {
+	svc.mu.Lock()
+	busy := svc.busy
+	svc.mu.Unlock()
+	svc.cancelFunc()
+	svc.activeOps.Wait()
+	if busy {
+		return svc.base.Stop(ctx, nil)
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package builtin
+
+import (
+	"context"
+	"image"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/powersensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/docking"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/objectdetection"
+)
+
+// simulatedDock tracks the distance and bearing from a base to its dock.
+type simulatedDock struct {
+	mu         sync.Mutex
+	distanceMM float64
+	// bearingDegs is the direction of the dock relative to the base's heading, positive to the right.
+	bearingDegs float64
+	// failedContacts is the number of times contact fails before it succeeds.
+	failedContacts int
+	charging       bool
+}
+
+func (s *simulatedDock) deps(t *testing.T, conf *Config) resource.Dependencies {
+	t.Helper()
+	b := inject.NewBase(conf.BaseName)
+	b.MoveStraightFunc = func(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.distanceMM -= float64(distanceMm)
+		// the slow final approach is what drives the base onto the contacts
+		if distanceMm > 0 && mmPerSec < defaultLinearMMPerSec {
+			if s.failedContacts > 0 {
+				s.failedContacts--
+			} else {
+				s.charging = true
+			}
+		}
+		if distanceMm < 0 {
+			s.charging = false
+		}
+		return nil
+	}
+	b.SpinFunc = func(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.bearingDegs = math.Remainder(s.bearingDegs+angleDeg, 360)
+		return nil
+	}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		return nil
+	}
+
+	ps := inject.NewPowerSensor(conf.PowerSensorName)
+	ps.CurrentFunc = func(ctx context.Context, extra map[string]interface{}) (float64, bool, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.charging {
+			return 1.5, false, nil
+		}
+		return 0, false, nil
+	}
+
+	vis := inject.NewVisionService(conf.VisionServiceName)
+	vis.DetectionsFromCameraFunc = func(
+		ctx context.Context, cameraName string, extra map[string]interface{},
+	) ([]objectdetection.Detection, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if math.Abs(s.bearingDegs) > defaultHorizontalFOVDegs/2 {
+			return nil, nil
+		}
+		// the dock appears wider as the base approaches and fills the image at 100mm
+		width := int(math.Min(100, 100*100/math.Max(s.distanceMM, 1)))
+		center := 50 + int(s.bearingDegs/defaultHorizontalFOVDegs*100)
+		bounds := image.Rect(0, 0, 100, 100)
+		return []objectdetection.Detection{
+			objectdetection.NewDetection(bounds, image.Rect(center-width/2, 40, center+width/2, 60), 0.9, "dock"),
+			objectdetection.NewDetection(bounds, image.Rect(0, 0, 10, 10), 0.95, "person"),
+		}, nil
+	}
+
+	return resource.Dependencies{
+		base.Named(conf.BaseName):               b,
+		powersensor.Named(conf.PowerSensorName): ps,
+		vision.Named(conf.VisionServiceName):    vis,
+	}
+}
+
+func newTestService(t *testing.T, conf *Config, deps resource.Dependencies) docking.Service {
+	t.Helper()
+	svc, err := NewBuiltIn(context.Background(), deps, resource.Config{
+		Name:                "dock",
+		API:                 docking.API,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return svc
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{BaseName: "base", PowerSensorName: "power", VisionServiceName: "vis", CameraName: "cam"}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	testutils.VerifySameElements(t, deps, []string{"base", "power", "vis"})
+
+	conf = &Config{BaseName: "base", PowerSensorName: "power", IRSensorName: "ir"}
+	deps, _, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	testutils.VerifySameElements(t, deps, []string{"base", "power", "ir"})
+
+	for _, bad := range []*Config{
+		{PowerSensorName: "power", IRSensorName: "ir"},
+		{BaseName: "base", IRSensorName: "ir"},
+		{BaseName: "base", PowerSensorName: "power"},
+		{BaseName: "base", PowerSensorName: "power", VisionServiceName: "vis"},
+		{BaseName: "base", PowerSensorName: "power", VisionServiceName: "vis", CameraName: "cam", IRSensorName: "ir"},
+		{BaseName: "base", PowerSensorName: "power", IRSensorName: "ir", CenterTolerance: 0.5},
+		{BaseName: "base", PowerSensorName: "power", IRSensorName: "ir", MaxAttempts: -1},
+	} {
+		_, _, err := bad.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestDockAndUndock(t *testing.T) {
+	ctx := context.Background()
+	conf := &Config{
+		BaseName:          "base",
+		PowerSensorName:   "power",
+		VisionServiceName: "vis",
+		CameraName:        "cam",
+		DockLabel:         "dock",
+		RetryBackoffMS:    1,
+		ContactTimeoutMS:  1,
+	}
+
+	t.Run("dock after turning toward it", func(t *testing.T) {
+		sim := &simulatedDock{distanceMM: 1000, bearingDegs: 20}
+		svc := newTestService(t, conf, sim.deps(t, conf))
+
+		st, err := docking.GetStatus(ctx, svc)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, st.State, test.ShouldEqual, docking.StateUndocked)
+
+		test.That(t, svc.Dock(ctx, nil), test.ShouldBeNil)
+		st, err = docking.GetStatus(ctx, svc)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, st.State, test.ShouldEqual, docking.StateDocked)
+		test.That(t, st.Attempts, test.ShouldEqual, 1)
+		test.That(t, math.Abs(sim.bearingDegs), test.ShouldBeLessThan, defaultCenterTolerance*defaultHorizontalFOVDegs)
+
+		// docking again is a no-op
+		test.That(t, svc.Dock(ctx, nil), test.ShouldBeNil)
+
+		test.That(t, svc.Undock(ctx, nil), test.ShouldBeNil)
+		st, err = docking.GetStatus(ctx, svc)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, st.State, test.ShouldEqual, docking.StateUndocked)
+		test.That(t, sim.charging, test.ShouldBeFalse)
+	})
+
+	t.Run("search for a dock out of view", func(t *testing.T) {
+		sim := &simulatedDock{distanceMM: 1000, bearingDegs: 90}
+		svc := newTestService(t, conf, sim.deps(t, conf))
+		test.That(t, svc.Dock(ctx, nil), test.ShouldBeNil)
+	})
+
+	t.Run("retry when contact fails", func(t *testing.T) {
+		sim := &simulatedDock{distanceMM: 1000, failedContacts: 1}
+		svc := newTestService(t, conf, sim.deps(t, conf))
+		test.That(t, svc.Dock(ctx, nil), test.ShouldBeNil)
+		st, err := docking.GetStatus(ctx, svc)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, st.Attempts, test.ShouldEqual, 2)
+	})
+
+	t.Run("fail after max attempts", func(t *testing.T) {
+		sim := &simulatedDock{distanceMM: 1000, failedContacts: 3}
+		svc := newTestService(t, conf, sim.deps(t, conf))
+		err := svc.Dock(ctx, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no charging current")
+		st, err := docking.GetStatus(ctx, svc)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, st.State, test.ShouldEqual, docking.StateFailed)
+		test.That(t, st.Attempts, test.ShouldEqual, defaultMaxAttempts)
+		test.That(t, st.LastError, test.ShouldContainSubstring, "no charging current")
+	})
+
+	t.Run("undock only from the dock", func(t *testing.T) {
+		sim := &simulatedDock{distanceMM: 1000, failedContacts: 3}
+		svc := newTestService(t, conf, sim.deps(t, conf))
+
+		// undocking an undocked base does not move it
+		test.That(t, svc.Undock(ctx, nil), test.ShouldBeNil)
+		test.That(t, sim.distanceMM, test.ShouldEqual, 1000)
+
+		test.That(t, svc.Dock(ctx, nil), test.ShouldNotBeNil)
+		sim.mu.Lock()
+		distanceMM := sim.distanceMM
+		sim.mu.Unlock()
+		err := svc.Undock(ctx, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "rather than docked")
+		test.That(t, sim.distanceMM, test.ShouldEqual, distanceMM)
+	})
+
+	t.Run("close cancels docking", func(t *testing.T) {
+		sim := &simulatedDock{distanceMM: 1000}
+		deps := sim.deps(t, conf)
+		moving := make(chan struct{})
+		var once sync.Once
+		deps[base.Named(conf.BaseName)].(*inject.Base).MoveStraightFunc = func(
+			ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{},
+		) error {
+			once.Do(func() { close(moving) })
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		svc := newTestService(t, conf, deps)
+
+		dockErr := make(chan error, 1)
+		go func() {
+			dockErr <- svc.Dock(ctx, nil)
+		}()
+		<-moving
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+		select {
+		case err := <-dockErr:
+			test.That(t, err, test.ShouldNotBeNil)
+		case <-time.After(time.Second):
+			t.Fatal("dock operation was not canceled by Close")
+		}
+		test.That(t, svc.Dock(ctx, nil), test.ShouldNotBeNil)
+	})
+
+	t.Run("cancelled undock stops the base", func(t *testing.T) {
+		sim := &simulatedDock{distanceMM: 1000}
+		deps := sim.deps(t, conf)
+		svc := newTestService(t, conf, deps)
+		test.That(t, svc.Dock(ctx, nil), test.ShouldBeNil)
+
+		b := deps[base.Named(conf.BaseName)].(*inject.Base)
+		moving := make(chan struct{})
+		b.MoveStraightFunc = func(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+			close(moving)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		var stopped atomic.Bool
+		b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+			stopped.Store(true)
+			return nil
+		}
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		undockErr := make(chan error, 1)
+		go func() {
+			undockErr <- svc.Undock(cancelCtx, nil)
+		}()
+		<-moving
+		cancel()
+		select {
+		case err := <-undockErr:
+			test.That(t, err, test.ShouldNotBeNil)
+		case <-time.After(time.Second):
+			t.Fatal("undock was not canceled")
+		}
+		test.That(t, stopped.Load(), test.ShouldBeTrue)
+		st, err := docking.GetStatus(ctx, svc)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, st.State, test.ShouldEqual, docking.StateFailed)
+	})
+
+	t.Run("dock through DoCommand", func(t *testing.T) {
+		sim := &simulatedDock{distanceMM: 500}
+		svc := newTestService(t, conf, sim.deps(t, conf))
+		resp, err := svc.DoCommand(ctx, map[string]interface{}{docking.DoDock: true, docking.DoStatus: true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp[docking.DoDock], test.ShouldEqual, true)
+		st, err := docking.StatusFromMap(resp[docking.DoStatus].(map[string]interface{}))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, st.State, test.ShouldEqual, docking.StateDocked)
+
+		_, err = svc.DoCommand(ctx, map[string]interface{}{"foo": true})
+		test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+	})
+}
+
+func TestIRDetector(t *testing.T) {
+	s := inject.NewSensor("ir")
+	readings := map[string]interface{}{irReadingDetected: false}
+	s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return readings, nil
+	}
+	d := &irDetector{sensor: s}
+
+	obs, err := d.detect(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, obs, test.ShouldBeNil)
+
+	readings = map[string]interface{}{irReadingDetected: true, irReadingOffset: 0.1, irReadingProximity: 0.3}
+	obs, err = d.detect(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, obs, test.ShouldResemble, &observation{offset: 0.1, proximity: 0.3})
+}
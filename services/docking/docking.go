@@ -0,0 +1,150 @@
+// Package docking defines a service that autonomously docks a base with its charger and undocks it again.
+package docking
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = "docking"
+
+// API is a variable that identifies the docking resource API.
+var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoDock   = "dock"
+	DoUndock = "undock"
+	DoStatus = "status"
+)
+
+// Named is a helper for getting the named docking service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// Deprecated: FromRobot is a helper for getting the named docking service from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named docking service
+// from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Service, error) {
+	return resource.FromProvider[Service](provider, Named(name))
+}
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Service]{})
+}
+
+// State describes where the base is in the docking process.
+type State string
+
+// The set of known docking states.
+const (
+	StateUndocked    = State("undocked")
+	StateApproaching = State("approaching")
+	StateDocked      = State("docked")
+	StateUndocking   = State("undocking")
+	StateFailed      = State("failed")
+)
+
+// Status reports the current state of a docking service.
+type Status struct {
+	State State
+	// Attempts is the number of approach attempts made by the most recent dock request.
+	Attempts int
+	// LastError is the error that ended the most recent dock or undock request, if any.
+	LastError string
+}
+
+// A Service drives a base onto its dock, verifies that it is charging, and drives it off again.
+// Its resource Status reports the docking Status in the form produced by StatusToMap.
+type Service interface {
+	resource.Resource
+	// Dock approaches the dock and verifies electrical contact, retrying as configured.
+	// It returns once the base is docked or all attempts have failed.
+	Dock(ctx context.Context, extra map[string]interface{}) error
+	// Undock drives the base off of the dock.
+	Undock(ctx context.Context, extra map[string]interface{}) error
+}
+
+// GetStatus returns the current docking state of the service.
+func GetStatus(ctx context.Context, svc Service) (Status, error) {
+	m, err := svc.Status(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	return StatusFromMap(m)
+}
+
+// HandleDockingCommand services the docking DoCommand keys using the given Service, so that docking
+// can be driven through DoCommand by callers that only have a generic resource handle. It returns false if
+// cmd does not contain any of them so that it can be chained from a DoCommand implementation.
+func HandleDockingCommand(
+	ctx context.Context,
+	svc Service,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	_, dock := cmd[DoDock]
+	_, undock := cmd[DoUndock]
+	_, status := cmd[DoStatus]
+	if dock && undock {
+		return nil, true, errors.Errorf("cannot %s and %s in the same command", DoDock, DoUndock)
+	}
+
+	resp := map[string]interface{}{}
+	switch {
+	case dock:
+		extra, _ := cmd[DoDock].(map[string]interface{})
+		if err := svc.Dock(ctx, extra); err != nil {
+			return nil, true, err
+		}
+		resp[DoDock] = true
+	case undock:
+		extra, _ := cmd[DoUndock].(map[string]interface{})
+		if err := svc.Undock(ctx, extra); err != nil {
+			return nil, true, err
+		}
+		resp[DoUndock] = true
+	case !status:
+		return nil, false, nil
+	}
+	if status {
+		st, err := svc.Status(ctx)
+		if err != nil {
+			return nil, true, err
+		}
+		resp[DoStatus] = st
+	}
+	return resp, true, nil
+}
+
+// StatusToMap converts a Status into a map suitable for a DoCommand response.
+func StatusToMap(st Status) map[string]interface{} {
+	return map[string]interface{}{
+		"state":      string(st.State),
+		"attempts":   float64(st.Attempts),
+		"last_error": st.LastError,
+	}
+}
+
+// StatusFromMap converts a DoCommand response produced by StatusToMap back into a Status.
+func StatusFromMap(m map[string]interface{}) (Status, error) {
+	state, ok := m["state"].(string)
+	if !ok {
+		return Status{}, errors.Errorf("expected docking status state to be a string but got %T", m["state"])
+	}
+	attempts, _ := m["attempts"].(float64)
+	lastErr, _ := m["last_error"].(string)
+	return Status{State: State(state), Attempts: int(attempts), LastError: lastErr}, nil
+}
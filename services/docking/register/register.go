@@ -0,0 +1,7 @@
+// Package register registers all relevant docking models and also API specific functions
+package register
+
+import (
+	// for docking models.
+	_ "go.viam.com/rdk/services/docking/builtin"
+)
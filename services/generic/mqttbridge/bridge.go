@@ -0,0 +1,410 @@
+// Package mqttbridge implements a generic service that bridges a robot to an MQTT broker. It
+// publishes readings of sensors and other resources to topics and passes messages received on
+// command topics to the DoCommand of resources.
+package mqttbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/protoutils"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+)
+
+// Model is the model of the MQTT bridge service.
+var Model = resource.DefaultModelFamily.WithModel("mqtt-bridge")
+
+const (
+	defaultIntervalMS   = 1000
+	defaultKeepAliveSec = 30
+	reconnectInterval   = 5 * time.Second
+	commandTimeout      = time.Minute
+)
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newBridge,
+	})
+}
+
+// PublishConfig publishes the readings of a resource to a topic.
+type PublishConfig struct {
+	Resource string `json:"resource"`
+	Topic    string `json:"topic"`
+	// IntervalMS is how often readings are taken; it defaults to one second.
+	IntervalMS int `json:"interval_ms,omitempty"`
+	// OnlyOnChange skips publishing readings that are the same as the last ones published.
+	OnlyOnChange bool `json:"only_on_change,omitempty"`
+}
+
+// CommandConfig passes the JSON object messages received on a topic to the DoCommand of a resource.
+type CommandConfig struct {
+	Resource string `json:"resource"`
+	Topic    string `json:"topic"`
+	// ResponseTopic, if set, is where the result or error of every command is published.
+	ResponseTopic string `json:"response_topic,omitempty"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	// Broker is the address of the MQTT broker, either host:port or a URL such as mqtts://host:8883
+	// to connect over TLS.
+	Broker string `json:"broker"`
+	// CACertPath is a PEM file of the certificate authorities the broker's certificate is verified
+	// against over mqtts; the system roots are used if it is not set.
+	CACertPath string `json:"ca_cert_path,omitempty"`
+	// CertPath and KeyPath are the PEM files of a client certificate presented to the broker over mqtts.
+	CertPath     string          `json:"cert_path,omitempty"`
+	KeyPath      string          `json:"key_path,omitempty"`
+	ClientID     string          `json:"client_id,omitempty"`
+	Username     string          `json:"username,omitempty"`
+	Password     string          `json:"password,omitempty"`
+	KeepAliveSec int             `json:"keep_alive_sec,omitempty"`
+	Publish      []PublishConfig `json:"publish,omitempty"`
+	Commands     []CommandConfig `json:"commands,omitempty"`
+}
+
+func validTopic(topic string) bool {
+	return topic != "" && !strings.ContainsAny(topic, "+#")
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.Broker == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "broker")
+	}
+	_, useTLS, err := brokerURL(conf.Broker)
+	if err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	if !useTLS && (conf.CACertPath != "" || conf.CertPath != "" || conf.KeyPath != "") {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("ca_cert_path, cert_path and key_path require an mqtts:// broker"))
+	}
+	if (conf.CertPath == "") != (conf.KeyPath == "") {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("cert_path and key_path must be set together"))
+	}
+	if len(conf.Publish) == 0 && len(conf.Commands) == 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("must set at least one of publish or commands"))
+	}
+	if conf.KeepAliveSec < 0 || conf.KeepAliveSec > 0xffff {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("keep_alive_sec must be in [0, 65535]"))
+	}
+	var deps []string
+	seen := map[string]bool{}
+	addDep := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			deps = append(deps, name)
+		}
+	}
+	for i, p := range conf.Publish {
+		pPath := fmt.Sprintf("%s.publish.%d", path, i)
+		if p.Resource == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(pPath, "resource")
+		}
+		if !validTopic(p.Topic) {
+			return nil, nil, resource.NewConfigValidationError(pPath, errors.New("topic must be set and cannot contain wildcards"))
+		}
+		if p.IntervalMS < 0 {
+			return nil, nil, resource.NewConfigValidationError(pPath, errors.New("interval_ms cannot be negative"))
+		}
+		addDep(p.Resource)
+	}
+	topics := map[string]bool{}
+	for i, c := range conf.Commands {
+		cPath := fmt.Sprintf("%s.commands.%d", path, i)
+		if c.Resource == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(cPath, "resource")
+		}
+		if !validTopic(c.Topic) {
+			return nil, nil, resource.NewConfigValidationError(cPath, errors.New("topic must be set and cannot contain wildcards"))
+		}
+		if topics[c.Topic] {
+			return nil, nil, resource.NewConfigValidationError(cPath, errors.Errorf("topic %q is used by another command", c.Topic))
+		}
+		if c.ResponseTopic != "" && !validTopic(c.ResponseTopic) {
+			return nil, nil, resource.NewConfigValidationError(cPath, errors.New("response_topic cannot contain wildcards"))
+		}
+		topics[c.Topic] = true
+		addDep(c.Resource)
+	}
+	return deps, nil, nil
+}
+
+type publisher struct {
+	conf    PublishConfig
+	sensor  resource.Sensor
+	last    []byte
+	lastErr string
+}
+
+type commander struct {
+	conf CommandConfig
+	res  resource.Resource
+}
+
+type bridge struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf       *Config
+	client     mqtt.Client
+	publishers []*publisher
+	commands   map[string]commander
+	logger     logging.Logger
+
+	connectionLost chan error
+	published      atomic.Int64
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func newBridge(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b := &bridge{
+		Named:          conf.ResourceName().AsNamed(),
+		conf:           svcConfig,
+		commands:       map[string]commander{},
+		logger:         logger,
+		connectionLost: make(chan error, 1),
+	}
+	opts, err := b.clientOptions(func(err error) {
+		select {
+		case b.connectionLost <- err:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.client = mqtt.NewClient(opts)
+	for _, p := range svcConfig.Publish {
+		res, err := deps.LookupByShortName(p.Resource)
+		if err != nil {
+			return nil, err
+		}
+		sensor, ok := res.(resource.Sensor)
+		if !ok {
+			return nil, errors.Errorf("cannot publish readings of %q as it has no readings", p.Resource)
+		}
+		b.publishers = append(b.publishers, &publisher{conf: p, sensor: sensor})
+	}
+	for _, c := range svcConfig.Commands {
+		res, err := deps.LookupByShortName(c.Resource)
+		if err != nil {
+			return nil, err
+		}
+		b.commands[c.Topic] = commander{conf: c, res: res}
+	}
+
+	b.cancelCtx, b.cancel = context.WithCancel(context.Background())
+	b.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(b.run, b.activeBackgroundWorkers.Done)
+	return b, nil
+}
+
+// run keeps the bridge connected to the broker until the service is closed.
+func (b *bridge) run() {
+	for b.cancelCtx.Err() == nil {
+		// drop a loss of the last connection that serve returned before seeing.
+		select {
+		case <-b.connectionLost:
+		default:
+		}
+		err := waitToken(b.cancelCtx, b.client.Connect())
+		if err == nil {
+			err = b.serve()
+		}
+		if b.cancelCtx.Err() != nil {
+			b.client.Disconnect(uint(disconnectQuiesce.Milliseconds()))
+			return
+		}
+		b.logger.CWarnw(b.cancelCtx, "MQTT connection failed; reconnecting", "broker", b.conf.Broker, "error", err)
+		utils.SelectContextOrWait(b.cancelCtx, reconnectInterval)
+	}
+}
+
+// serve runs the bridge over one connection until it is lost or the service is closed.
+func (b *bridge) serve() error {
+	ctx, cancel := context.WithCancel(b.cancelCtx)
+	var workers sync.WaitGroup
+	defer func() {
+		cancel()
+		workers.Wait()
+	}()
+
+	// messages are handed over from the client's goroutine so that commands are only started while serving.
+	messages := make(chan mqtt.Message)
+	if len(b.commands) > 0 {
+		filters := make(map[string]byte, len(b.commands))
+		for topic := range b.commands {
+			filters[topic] = 0
+		}
+		token := b.client.SubscribeMultiple(filters, func(_ mqtt.Client, msg mqtt.Message) {
+			select {
+			case messages <- msg:
+			case <-ctx.Done():
+			}
+		})
+		if err := waitToken(ctx, token); err != nil {
+			return err
+		}
+	}
+	for _, p := range b.publishers {
+		workers.Add(1)
+		utils.ManagedGo(func() {
+			b.publishLoop(ctx, p)
+		}, workers.Done)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-b.connectionLost:
+			return err
+		case msg := <-messages:
+			c, ok := b.commands[msg.Topic()]
+			if !ok {
+				continue
+			}
+			workers.Add(1)
+			utils.ManagedGo(func() {
+				b.handleCommand(ctx, c, msg.Payload())
+			}, workers.Done)
+		}
+	}
+}
+
+func (b *bridge) publishLoop(ctx context.Context, p *publisher) {
+	interval := time.Duration(p.conf.IntervalMS) * time.Millisecond
+	if interval == 0 {
+		interval = defaultIntervalMS * time.Millisecond
+	}
+	for {
+		if err := b.publishReadings(ctx, p); err != nil && ctx.Err() == nil {
+			// log each distinct error once rather than every interval.
+			if err.Error() != p.lastErr {
+				b.logger.CWarnw(ctx, "failed to publish readings", "resource", p.conf.Resource, "error", err)
+			}
+			p.lastErr = err.Error()
+		} else {
+			p.lastErr = ""
+		}
+		if !utils.SelectContextOrWait(ctx, interval) {
+			return
+		}
+	}
+}
+
+func (b *bridge) publishReadings(ctx context.Context, p *publisher) error {
+	readings, err := p.sensor.Readings(ctx, nil)
+	if err != nil {
+		return err
+	}
+	payload, err := marshalMap(readings)
+	if err != nil {
+		return err
+	}
+	if p.conf.OnlyOnChange && bytes.Equal(payload, p.last) {
+		return nil
+	}
+	msg, err := json.Marshal(map[string]any{
+		"time":     time.Now().UTC().Format(time.RFC3339Nano),
+		"readings": json.RawMessage(payload),
+	})
+	if err != nil {
+		return err
+	}
+	if err := b.publish(ctx, p.conf.Topic, msg); err != nil {
+		return err
+	}
+	p.last = payload
+	b.published.Add(1)
+	return nil
+}
+
+func (b *bridge) handleCommand(ctx context.Context, c commander, payload []byte) {
+	result, err := func() (map[string]any, error) {
+		var cmd map[string]any
+		if err := json.Unmarshal(payload, &cmd); err != nil {
+			return nil, errors.Wrap(err, "command must be a JSON object")
+		}
+		cmdCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+		return c.res.DoCommand(cmdCtx, cmd)
+	}()
+	if err != nil {
+		b.logger.CWarnw(ctx, "MQTT command failed", "resource", c.conf.Resource, "topic", c.conf.Topic, "error", err)
+	}
+	if c.conf.ResponseTopic == "" {
+		return
+	}
+	var response []byte
+	if err != nil {
+		response, err = json.Marshal(map[string]string{"error": err.Error()})
+	} else {
+		var resultJSON []byte
+		if resultJSON, err = marshalMap(result); err == nil {
+			response, err = json.Marshal(map[string]any{"result": json.RawMessage(resultJSON)})
+		}
+	}
+	if err == nil {
+		err = b.publish(ctx, c.conf.ResponseTopic, response)
+	}
+	if err != nil && ctx.Err() == nil {
+		b.logger.CWarnw(ctx, "failed to publish MQTT command response", "topic", c.conf.ResponseTopic, "error", err)
+	}
+}
+
+// publish sends a message to a topic at QoS 0.
+func (b *bridge) publish(ctx context.Context, topic string, payload []byte) error {
+	return waitToken(ctx, b.client.Publish(topic, 0, false, payload))
+}
+
+// marshalMap marshals readings or a DoCommand result to JSON the same way they are sent over the API.
+func marshalMap(m map[string]any) ([]byte, error) {
+	fields, err := protoutils.ReadingGoToProto(m)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal((&structpb.Struct{Fields: fields}).AsMap())
+}
+
+// DoCommand reports whether the bridge is connected to its broker and how many messages of readings
+// it has published.
+func (b *bridge) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"connected": b.client.IsConnectionOpen(),
+		"published": b.published.Load(),
+	}, nil
+}
+
+func (b *bridge) Close(ctx context.Context) error {
+	b.cancel()
+	b.activeBackgroundWorkers.Wait()
+	return nil
+}
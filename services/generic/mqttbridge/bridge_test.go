@@ -0,0 +1,393 @@
+package mqttbridge
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{Broker: "localhost:1883"}
+	_, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.Publish = []PublishConfig{{Resource: "s1", Topic: "robot/#"}}
+	_, _, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "wildcards")
+
+	conf.Publish[0].Topic = "robot/s1"
+	conf.Commands = []CommandConfig{{Resource: "s1", Topic: "robot/cmd"}, {Resource: "s2", Topic: "robot/cmd"}}
+	_, _, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "used by another command")
+
+	conf.Commands[1].Topic = "robot/cmd2"
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"s1", "s2"})
+
+	conf.CACertPath = "ca.pem"
+	_, _, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "mqtts://")
+	conf.Broker = "mqtts://localhost:8883"
+	conf.CertPath = "cert.pem"
+	_, _, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "set together")
+	conf.KeyPath = "key.pem"
+	_, _, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	conf.Broker = "ws://localhost:8080"
+	_, _, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unsupported broker scheme")
+
+	conf.Broker = ""
+	_, _, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "broker")
+}
+
+// The test broker implements just enough of MQTT 3.1.1 to check what the bridge sends.
+// Spec: https://docs.oasis-open.org/mqtt/mqtt/v3.1.1/os/mqtt-v3.1.1-os.html
+const (
+	packetConnect    = byte(1)
+	packetConnAck    = byte(2)
+	packetPublish    = byte(3)
+	packetSubscribe  = byte(8)
+	packetSubAck     = byte(9)
+	packetPingReq    = byte(12)
+	packetPingResp   = byte(13)
+	packetDisconnect = byte(14)
+	protocolLevel311 = byte(4)
+)
+
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+func writePacket(w io.Writer, kind, flags byte, body []byte) error {
+	header := []byte{kind<<4 | flags}
+	for length := len(body); ; {
+		digit := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			digit |= 0x80
+		}
+		header = append(header, digit)
+		if length == 0 {
+			break
+		}
+	}
+	_, err := w.Write(append(header, body...))
+	return err
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	length, multiplier := 0, 1
+	for {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		length += int(digit&0x7f) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{kind: first >> 4, flags: first & 0x0f, body: body}, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func readString(b []byte) (string, []byte, error) {
+	if len(b) < 2 || len(b) < 2+int(binary.BigEndian.Uint16(b)) {
+		return "", nil, errors.New("malformed mqtt string")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
+
+// parsePublish returns the topic and payload of a QoS 0 PUBLISH packet.
+func parsePublish(p packet) (string, []byte, error) {
+	return readString(p.body)
+}
+
+// brokerConn is the broker's end of a connection from the bridge.
+type brokerConn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (bc *brokerConn) read(kind byte) packet {
+	bc.t.Helper()
+	for {
+		p, err := readPacket(bc.r)
+		test.That(bc.t, err, test.ShouldBeNil)
+		if p.kind == packetPingReq {
+			test.That(bc.t, writePacket(bc.conn, packetPingResp, 0, nil), test.ShouldBeNil)
+			continue
+		}
+		test.That(bc.t, p.kind, test.ShouldEqual, kind)
+		return p
+	}
+}
+
+func (bc *brokerConn) readPublish() (string, map[string]any) {
+	bc.t.Helper()
+	topic, payload, err := parsePublish(bc.read(packetPublish))
+	test.That(bc.t, err, test.ShouldBeNil)
+	var msg map[string]any
+	test.That(bc.t, json.Unmarshal(payload, &msg), test.ShouldBeNil)
+	return topic, msg
+}
+
+func TestBridge(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	defer lis.Close()
+
+	s1 := inject.NewSensor("s1")
+	s1.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"temperature": 21.5}, nil
+	}
+	s1.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"echo": cmd["value"]}, nil
+	}
+	deps := resource.Dependencies{sensor.Named("s1"): s1}
+	conf := resource.Config{
+		Name:  "bridge",
+		API:   generic.API,
+		Model: Model,
+		ConvertedAttributes: &Config{
+			Broker:   lis.Addr().String(),
+			Username: "user",
+			Password: "pass",
+			Publish:  []PublishConfig{{Resource: "s1", Topic: "robot/s1", IntervalMS: 10, OnlyOnChange: true}},
+			Commands: []CommandConfig{{Resource: "s1", Topic: "robot/s1/cmd", ResponseTopic: "robot/s1/response"}},
+		},
+	}
+	svc, err := newBridge(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	conn, err := lis.Accept()
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+	bc := &brokerConn{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	connect := bc.read(packetConnect)
+	protocol, rest, err := readString(connect.body)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, protocol, test.ShouldEqual, "MQTT")
+	test.That(t, rest[0], test.ShouldEqual, protocolLevel311)
+	test.That(t, rest[1]&0xc0, test.ShouldEqual, byte(0xc0))
+	clientID, rest, err := readString(rest[4:])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, clientID, test.ShouldEqual, "viam-bridge")
+	username, rest, err := readString(rest)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, username, test.ShouldEqual, "user")
+	password, _, err := readString(rest)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, password, test.ShouldEqual, "pass")
+	test.That(t, writePacket(conn, packetConnAck, 0, []byte{0, 0}), test.ShouldBeNil)
+
+	// the subscription and the first readings may arrive in either order.
+	var readings map[string]any
+	for subscribed := false; !subscribed || readings == nil; {
+		p, err := readPacket(bc.r)
+		test.That(t, err, test.ShouldBeNil)
+		switch p.kind {
+		case packetSubscribe:
+			topic, rest, err := readString(p.body[2:])
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, topic, test.ShouldEqual, "robot/s1/cmd")
+			test.That(t, rest, test.ShouldResemble, []byte{0})
+			test.That(t, writePacket(conn, packetSubAck, 0, append(p.body[:2:2], 0)), test.ShouldBeNil)
+			subscribed = true
+		case packetPublish:
+			topic, payload, err := parsePublish(p)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, topic, test.ShouldEqual, "robot/s1")
+			test.That(t, json.Unmarshal(payload, &readings), test.ShouldBeNil)
+		default:
+			t.Fatalf("unexpected packet type %d", p.kind)
+		}
+	}
+	test.That(t, readings["readings"], test.ShouldResemble, map[string]any{"temperature": 21.5})
+	test.That(t, readings["time"], test.ShouldNotBeEmpty)
+
+	cmd := appendString(nil, "robot/s1/cmd")
+	cmd = append(cmd, []byte(`{"value": "hi"}`)...)
+	test.That(t, writePacket(conn, packetPublish, 0, cmd), test.ShouldBeNil)
+	// readings are unchanged, so the next message is the response.
+	topic, response := bc.readPublish()
+	test.That(t, topic, test.ShouldEqual, "robot/s1/response")
+	test.That(t, response, test.ShouldResemble, map[string]any{"result": map[string]any{"echo": "hi"}})
+
+	test.That(t, writePacket(conn, packetPublish, 0, append(appendString(nil, "robot/s1/cmd"), "nope"...)), test.ShouldBeNil)
+	topic, response = bc.readPublish()
+	test.That(t, topic, test.ShouldEqual, "robot/s1/response")
+	test.That(t, response["error"], test.ShouldContainSubstring, "JSON object")
+
+	status, err := svc.DoCommand(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["connected"], test.ShouldBeTrue)
+	test.That(t, status["published"], test.ShouldEqual, int64(1))
+
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	bc.read(packetDisconnect)
+}
+
+// writeTLSFiles writes a CA certificate and a certificate and key issued by it for localhost to dir,
+// returning their paths and the issued certificate.
+func writeTLSFiles(t *testing.T, dir, name string) (string, string, string, tls.Certificate) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.That(t, err, test.ShouldBeNil)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name + " ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	test.That(t, err, test.ShouldBeNil)
+	caCert, err := x509.ParseCertificate(caDER)
+	test.That(t, err, test.ShouldBeNil)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.That(t, err, test.ShouldBeNil)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	test.That(t, err, test.ShouldBeNil)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	test.That(t, err, test.ShouldBeNil)
+
+	write := func(file, blockType string, b []byte) string {
+		path := filepath.Join(dir, file)
+		test.That(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b}), 0o600), test.ShouldBeNil)
+		return path
+	}
+	caPath := write(name+"-ca.pem", "CERTIFICATE", caDER)
+	certPath := write(name+".pem", "CERTIFICATE", der)
+	keyPath := write(name+"-key.pem", "EC PRIVATE KEY", keyDER)
+	return caPath, certPath, keyPath, tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestBridgeTLS(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	brokerCAPath, _, _, brokerCert := writeTLSFiles(t, dir, "broker")
+	clientCAPath, clientCertPath, clientKeyPath, _ := writeTLSFiles(t, dir, "client")
+
+	clientCAPEM, err := os.ReadFile(clientCAPath)
+	test.That(t, err, test.ShouldBeNil)
+	clientCAs := x509.NewCertPool()
+	test.That(t, clientCAs.AppendCertsFromPEM(clientCAPEM), test.ShouldBeTrue)
+	lis, err := tls.Listen("tcp", "localhost:0", &tls.Config{
+		Certificates: []tls.Certificate{brokerCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	defer lis.Close()
+
+	s1 := inject.NewSensor("s1")
+	s1.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"temperature": 21.5}, nil
+	}
+	_, port, err := net.SplitHostPort(lis.Addr().String())
+	test.That(t, err, test.ShouldBeNil)
+	conf := resource.Config{
+		Name:  "bridge",
+		API:   generic.API,
+		Model: Model,
+		ConvertedAttributes: &Config{
+			Broker:     "mqtts://localhost:" + port,
+			CACertPath: brokerCAPath,
+			CertPath:   clientCertPath,
+			KeyPath:    clientKeyPath,
+			Publish:    []PublishConfig{{Resource: "s1", Topic: "robot/s1", OnlyOnChange: true}},
+		},
+	}
+	svc, err := newBridge(ctx, resource.Dependencies{sensor.Named("s1"): s1}, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	conn, err := lis.Accept()
+	test.That(t, err, test.ShouldBeNil)
+	defer conn.Close()
+	tlsConn := conn.(*tls.Conn)
+	test.That(t, tlsConn.Handshake(), test.ShouldBeNil)
+	peers := tlsConn.ConnectionState().PeerCertificates
+	test.That(t, len(peers), test.ShouldBeGreaterThan, 0)
+	test.That(t, peers[0].Subject.CommonName, test.ShouldEqual, "client")
+
+	bc := &brokerConn{t: t, conn: conn, r: bufio.NewReader(conn)}
+	bc.read(packetConnect)
+	test.That(t, writePacket(conn, packetConnAck, 0, []byte{0, 0}), test.ShouldBeNil)
+	topic, readings := bc.readPublish()
+	test.That(t, topic, test.ShouldEqual, "robot/s1")
+	test.That(t, readings["readings"], test.ShouldResemble, map[string]any{"temperature": 21.5})
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		status, err := svc.DoCommand(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, status["connected"], test.ShouldBeTrue)
+	})
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	bc.read(packetDisconnect)
+}
@@ -0,0 +1,112 @@
+package mqttbridge
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/pkg/errors"
+)
+
+// disconnectQuiesce is how long closing the connection waits for outgoing messages to be sent.
+const disconnectQuiesce = 250 * time.Millisecond
+
+// brokerURL returns the URL of the broker and whether it is connected to over TLS. A broker given as
+// host:port is connected to over plain TCP.
+func brokerURL(broker string) (*url.URL, bool, error) {
+	if !strings.Contains(broker, "://") {
+		broker = "tcp://" + broker
+	}
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, false, err
+	}
+	if u.Host == "" {
+		return nil, false, errors.Errorf("broker %q has no host", broker)
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		return u, false, nil
+	case "mqtts", "ssl", "tls":
+		return u, true, nil
+	default:
+		return nil, false, errors.Errorf("unsupported broker scheme %q; use mqtt or mqtts", u.Scheme)
+	}
+}
+
+// tlsConfig returns the TLS config used to connect to a broker over mqtts. The broker's certificate
+// is verified against the system roots unless a CA certificate is configured.
+func (conf *Config) tlsConfig() (*tls.Config, error) {
+	tlsConf := &tls.Config{MinVersion: tls.VersionTLS12}
+	if conf.CACertPath != "" {
+		caPEM, err := os.ReadFile(conf.CACertPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read ca_cert_path")
+		}
+		tlsConf.RootCAs = x509.NewCertPool()
+		if !tlsConf.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.Errorf("no PEM certificates found in %q", conf.CACertPath)
+		}
+	}
+	if conf.CertPath != "" {
+		cert, err := tls.LoadX509KeyPair(conf.CertPath, conf.KeyPath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load client certificate")
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConf, nil
+}
+
+// clientOptions returns the options of the bridge's MQTT client. Reconnecting is left to the bridge
+// so that every failed attempt is logged.
+func (b *bridge) clientOptions(onConnectionLost func(err error)) (*mqtt.ClientOptions, error) {
+	u, useTLS, err := brokerURL(b.conf.Broker)
+	if err != nil {
+		return nil, err
+	}
+	clientID := b.conf.ClientID
+	if clientID == "" {
+		clientID = "viam-" + b.Name().ShortName()
+	}
+	keepAlive := time.Duration(b.conf.KeepAliveSec) * time.Second
+	if keepAlive == 0 {
+		keepAlive = defaultKeepAliveSec * time.Second
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(u.String()).
+		SetClientID(clientID).
+		SetUsername(b.conf.Username).
+		SetPassword(b.conf.Password).
+		SetProtocolVersion(4).
+		SetCleanSession(true).
+		SetKeepAlive(keepAlive).
+		SetConnectTimeout(reconnectInterval).
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			onConnectionLost(err)
+		})
+	if useTLS {
+		tlsConf, err := b.conf.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConf)
+	}
+	return opts, nil
+}
+
+// waitToken waits for an MQTT operation to complete or ctx to be done.
+func waitToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
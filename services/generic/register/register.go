@@ -5,4 +5,6 @@ import (
 	// register generic.
 	_ "go.viam.com/rdk/services/generic"
 	_ "go.viam.com/rdk/services/generic/fake"
+	_ "go.viam.com/rdk/services/generic/mqttbridge"
+	_ "go.viam.com/rdk/services/generic/ros2bridge"
 )
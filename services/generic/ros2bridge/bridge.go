@@ -0,0 +1,478 @@
+// Package ros2bridge implements a generic service that bridges a robot to ROS 2 through a rosbridge
+// server. It publishes camera images, sensor readings and the frame system as TF, and drives a base
+// with the Twist messages published on cmd_vel, so tools such as RViz and Nav2 can work with the
+// robot.
+package ros2bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+	"google.golang.org/protobuf/types/known/structpb"
+	"nhooyr.io/websocket"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/protoutils"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/generic"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// Model is the model of the ROS 2 bridge service.
+var Model = resource.DefaultModelFamily.WithModel("ros2-bridge")
+
+const (
+	defaultCameraIntervalMS = 100
+	defaultSensorIntervalMS = 1000
+	defaultTFIntervalMS     = 100
+	defaultCmdVelTopic      = "/cmd_vel"
+	defaultCmdVelTimeoutMS  = 500
+	reconnectInterval       = 5 * time.Second
+	// maxMessageBytes bounds the messages read from rosbridge, which are only ever Twists.
+	maxMessageBytes = 1 << 16
+)
+
+func init() {
+	resource.RegisterService(generic.API, Model, resource.Registration[resource.Resource, *Config]{
+		Constructor: newBridge,
+	})
+}
+
+// TopicConfig publishes a resource to a ROS 2 topic.
+type TopicConfig struct {
+	Resource string `json:"resource"`
+	// Topic defaults to /<resource>/image/compressed for cameras and /<resource>/readings for sensors.
+	Topic      string `json:"topic,omitempty"`
+	IntervalMS int    `json:"interval_ms,omitempty"`
+}
+
+// CmdVelConfig drives a base with the Twist messages published to a topic. Linear x is the forward
+// speed in meters per second and angular z is the turning speed in radians per second.
+type CmdVelConfig struct {
+	Base string `json:"base"`
+	// Topic defaults to /cmd_vel.
+	Topic string `json:"topic,omitempty"`
+	// TimeoutMS stops the base if no message arrives for this long; it defaults to 500.
+	TimeoutMS int `json:"timeout_ms,omitempty"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	// RosbridgeURL is the websocket URL of the rosbridge server, such as ws://localhost:9090.
+	RosbridgeURL string `json:"rosbridge_url"`
+	// Cameras are published as sensor_msgs/CompressedImage.
+	Cameras []TopicConfig `json:"cameras,omitempty"`
+	// Sensors are published as std_msgs/String holding their readings as JSON.
+	Sensors []TopicConfig `json:"sensors,omitempty"`
+	// PublishTF publishes the transform from every frame in the frame system to its parent on /tf.
+	PublishTF    bool          `json:"publish_tf,omitempty"`
+	TFIntervalMS int           `json:"tf_interval_ms,omitempty"`
+	CmdVel       *CmdVelConfig `json:"cmd_vel,omitempty"`
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.RosbridgeURL == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "rosbridge_url")
+	}
+	if !strings.HasPrefix(conf.RosbridgeURL, "ws://") && !strings.HasPrefix(conf.RosbridgeURL, "wss://") {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("rosbridge_url must be a ws:// or wss:// URL"))
+	}
+	if conf.TFIntervalMS < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("tf_interval_ms cannot be negative"))
+	}
+	var deps []string
+	for _, field := range []struct {
+		name   string
+		topics []TopicConfig
+	}{{"cameras", conf.Cameras}, {"sensors", conf.Sensors}} {
+		for i, tc := range field.topics {
+			tPath := fmt.Sprintf("%s.%s.%d", path, field.name, i)
+			if tc.Resource == "" {
+				return nil, nil, resource.NewConfigValidationFieldRequiredError(tPath, "resource")
+			}
+			if tc.IntervalMS < 0 {
+				return nil, nil, resource.NewConfigValidationError(tPath, errors.New("interval_ms cannot be negative"))
+			}
+			deps = append(deps, tc.Resource)
+		}
+	}
+	if conf.CmdVel != nil {
+		if conf.CmdVel.Base == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(path+".cmd_vel", "base")
+		}
+		if conf.CmdVel.TimeoutMS < 0 {
+			return nil, nil, resource.NewConfigValidationError(path+".cmd_vel", errors.New("timeout_ms cannot be negative"))
+		}
+		deps = append(deps, conf.CmdVel.Base)
+	}
+	if len(deps) == 0 && !conf.PublishTF {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("nothing to bridge"))
+	}
+	if conf.PublishTF {
+		deps = append(deps, framesystem.InternalServiceName.String())
+	}
+	return deps, nil, nil
+}
+
+func interval(ms, defaultMS int) time.Duration {
+	if ms == 0 {
+		ms = defaultMS
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// topic is a ROS 2 topic the bridge publishes to.
+type topic struct {
+	name     string
+	msgType  string
+	interval time.Duration
+	// message returns the next message to publish.
+	message func(ctx context.Context) (any, error)
+}
+
+type bridge struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf    *Config
+	topics  []topic
+	base    base.Base
+	logger  logging.Logger
+	cmdVel  CmdVelConfig
+	lastCmd atomic.Int64 // unix nanoseconds of the last cmd_vel message, or zero when stopped
+
+	connected atomic.Bool
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func newBridge(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (resource.Resource, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	b := &bridge{
+		Named:  conf.ResourceName().AsNamed(),
+		conf:   svcConfig,
+		logger: logger,
+	}
+	for _, tc := range svcConfig.Cameras {
+		cam, err := camera.FromProvider(deps, tc.Resource)
+		if err != nil {
+			return nil, err
+		}
+		b.topics = append(b.topics, cameraTopic(tc, cam))
+	}
+	for _, tc := range svcConfig.Sensors {
+		res, err := deps.LookupByShortName(tc.Resource)
+		if err != nil {
+			return nil, err
+		}
+		sensor, ok := res.(resource.Sensor)
+		if !ok {
+			return nil, errors.Errorf("cannot publish readings of %q as it has no readings", tc.Resource)
+		}
+		b.topics = append(b.topics, sensorTopic(tc, sensor))
+	}
+	if svcConfig.PublishTF {
+		fs, err := resource.FromProvider[framesystem.Service](deps, framesystem.InternalServiceName)
+		if err != nil {
+			return nil, err
+		}
+		b.topics = append(b.topics, tfTopic(svcConfig.TFIntervalMS, fs))
+	}
+	if svcConfig.CmdVel != nil {
+		b.cmdVel = *svcConfig.CmdVel
+		if b.cmdVel.Topic == "" {
+			b.cmdVel.Topic = defaultCmdVelTopic
+		}
+		if b.cmdVel.TimeoutMS == 0 {
+			b.cmdVel.TimeoutMS = defaultCmdVelTimeoutMS
+		}
+		if b.base, err = base.FromProvider(deps, b.cmdVel.Base); err != nil {
+			return nil, err
+		}
+	}
+
+	b.cancelCtx, b.cancel = context.WithCancel(context.Background())
+	b.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(b.run, b.activeBackgroundWorkers.Done)
+	if b.base != nil {
+		b.activeBackgroundWorkers.Add(1)
+		utils.ManagedGo(b.cmdVelWatchdog, b.activeBackgroundWorkers.Done)
+	}
+	return b, nil
+}
+
+func cameraTopic(tc TopicConfig, cam camera.Camera) topic {
+	name := tc.Topic
+	if name == "" {
+		name = "/" + tc.Resource + "/image/compressed"
+	}
+	return topic{
+		name:     name,
+		msgType:  typeCompressedImage,
+		interval: interval(tc.IntervalMS, defaultCameraIntervalMS),
+		message: func(ctx context.Context) (any, error) {
+			images, _, err := cam.Images(ctx, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			if len(images) == 0 {
+				return nil, errors.New("camera returned no images")
+			}
+			img := images[0]
+			msg := compressedImage{Header: newHeader(time.Now(), tc.Resource)}
+			switch img.MimeType() {
+			case rutils.MimeTypeJPEG:
+				msg.Format = "jpeg"
+				msg.Data, err = img.Bytes(ctx)
+			case rutils.MimeTypePNG:
+				msg.Format = "png"
+				msg.Data, err = img.Bytes(ctx)
+			default:
+				decoded, decodeErr := img.Image(ctx)
+				if decodeErr != nil {
+					return nil, decodeErr
+				}
+				msg.Format = "jpeg"
+				msg.Data, err = rimage.EncodeImage(ctx, decoded, rutils.MimeTypeJPEG)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return msg, nil
+		},
+	}
+}
+
+func sensorTopic(tc TopicConfig, sensor resource.Sensor) topic {
+	name := tc.Topic
+	if name == "" {
+		name = "/" + tc.Resource + "/readings"
+	}
+	return topic{
+		name:     name,
+		msgType:  typeString,
+		interval: interval(tc.IntervalMS, defaultSensorIntervalMS),
+		message: func(ctx context.Context) (any, error) {
+			readings, err := sensor.Readings(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			fields, err := protoutils.ReadingGoToProto(readings)
+			if err != nil {
+				return nil, err
+			}
+			data, err := json.Marshal((&structpb.Struct{Fields: fields}).AsMap())
+			if err != nil {
+				return nil, err
+			}
+			return stringMsg{Data: string(data)}, nil
+		},
+	}
+}
+
+func tfTopic(intervalMS int, fs framesystem.Service) topic {
+	return topic{
+		name:     "/tf",
+		msgType:  typeTFMessage,
+		interval: interval(intervalMS, defaultTFIntervalMS),
+		message: func(ctx context.Context) (any, error) {
+			fsConfig, err := fs.FrameSystemConfig(ctx)
+			if err != nil {
+				return nil, err
+			}
+			now := time.Now()
+			msg := tfMessage{Transforms: []transformStamped{}}
+			for _, part := range fsConfig.Parts {
+				name, parent := part.FrameConfig.Name(), part.FrameConfig.Parent()
+				pose, err := fs.GetPose(ctx, name, parent, nil, nil)
+				if err != nil {
+					return nil, err
+				}
+				msg.Transforms = append(msg.Transforms, transformStamped{
+					Header:       newHeader(now, parent),
+					ChildFrameID: name,
+					Transform:    transformFromPose(pose.Pose()),
+				})
+			}
+			return msg, nil
+		},
+	}
+}
+
+// run keeps the bridge connected to rosbridge until the service is closed.
+func (b *bridge) run() {
+	for b.cancelCtx.Err() == nil {
+		dialCtx, cancel := context.WithTimeout(b.cancelCtx, reconnectInterval)
+		//nolint:bodyclose
+		conn, _, err := websocket.Dial(dialCtx, b.conf.RosbridgeURL, nil)
+		cancel()
+		if err == nil {
+			conn.SetReadLimit(maxMessageBytes)
+			b.connected.Store(true)
+			err = b.serve(conn)
+			b.connected.Store(false)
+		}
+		if b.cancelCtx.Err() != nil {
+			return
+		}
+		b.logger.CWarnw(b.cancelCtx, "rosbridge connection failed; reconnecting", "url", b.conf.RosbridgeURL, "error", err)
+		utils.SelectContextOrWait(b.cancelCtx, reconnectInterval)
+	}
+}
+
+func send(ctx context.Context, conn *websocket.Conn, op rosbridgeOp, msg any) error {
+	if msg != nil {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		op.Msg = data
+	}
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageText, data)
+}
+
+// serve runs the bridge over one connection until it fails or the service is closed.
+func (b *bridge) serve(conn *websocket.Conn) error {
+	ctx, cancel := context.WithCancel(b.cancelCtx)
+	var workers sync.WaitGroup
+	defer func() {
+		cancel()
+		workers.Wait()
+		utils.UncheckedError(conn.Close(websocket.StatusNormalClosure, ""))
+	}()
+
+	for _, t := range b.topics {
+		if err := send(ctx, conn, rosbridgeOp{Op: "advertise", Topic: t.name, Type: t.msgType}, nil); err != nil {
+			return err
+		}
+	}
+	if b.base != nil {
+		if err := send(ctx, conn, rosbridgeOp{Op: "subscribe", Topic: b.cmdVel.Topic, Type: typeTwist}, nil); err != nil {
+			return err
+		}
+	}
+	for _, t := range b.topics {
+		workers.Add(1)
+		utils.ManagedGo(func() {
+			b.publishLoop(ctx, conn, t)
+		}, workers.Done)
+	}
+
+	for {
+		_, data, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+		var op rosbridgeOp
+		if err := json.Unmarshal(data, &op); err != nil {
+			return err
+		}
+		switch {
+		case op.Op == "publish" && b.base != nil && op.Topic == b.cmdVel.Topic:
+			var cmd twist
+			if err := json.Unmarshal(op.Msg, &cmd); err != nil {
+				b.logger.CWarnw(ctx, "invalid cmd_vel message", "error", err)
+				continue
+			}
+			b.handleTwist(ctx, cmd)
+		case op.Op == "status":
+			b.logger.CDebugw(ctx, "rosbridge status", "message", string(data))
+		}
+	}
+}
+
+func (b *bridge) publishLoop(ctx context.Context, conn *websocket.Conn, t topic) {
+	var lastErr string
+	for {
+		msg, err := t.message(ctx)
+		if err == nil {
+			err = send(ctx, conn, rosbridgeOp{Op: "publish", Topic: t.name}, msg)
+		}
+		if err != nil && ctx.Err() == nil {
+			// log each distinct error once rather than every interval.
+			if err.Error() != lastErr {
+				b.logger.CWarnw(ctx, "failed to publish to ROS", "topic", t.name, "error", err)
+			}
+			lastErr = err.Error()
+		} else {
+			lastErr = ""
+		}
+		if !utils.SelectContextOrWait(ctx, t.interval) {
+			return
+		}
+	}
+}
+
+func (b *bridge) handleTwist(ctx context.Context, cmd twist) {
+	linear := r3.Vector{Y: cmd.Linear.X * 1000}
+	angular := r3.Vector{Z: cmd.Angular.Z * 180 / math.Pi}
+	if err := b.base.SetVelocity(ctx, linear, angular, nil); err != nil {
+		b.logger.CWarnw(ctx, "failed to drive base from cmd_vel", "base", b.cmdVel.Base, "error", err)
+		return
+	}
+	if linear.Y == 0 && angular.Z == 0 {
+		b.lastCmd.Store(0)
+	} else {
+		b.lastCmd.Store(time.Now().UnixNano())
+	}
+}
+
+// cmdVelWatchdog stops the base if cmd_vel messages stop arriving while it is moving, such as when
+// the connection to rosbridge or the node publishing them is lost.
+func (b *bridge) cmdVelWatchdog() {
+	timeout := time.Duration(b.cmdVel.TimeoutMS) * time.Millisecond
+	for utils.SelectContextOrWait(b.cancelCtx, timeout/4) {
+		last := b.lastCmd.Load()
+		if last == 0 || time.Since(time.Unix(0, last)) < timeout {
+			continue
+		}
+		if !b.lastCmd.CompareAndSwap(last, 0) {
+			continue
+		}
+		if err := b.base.Stop(b.cancelCtx, nil); err != nil {
+			b.logger.CWarnw(b.cancelCtx, "failed to stop base after cmd_vel timeout", "base", b.cmdVel.Base, "error", err)
+		}
+	}
+}
+
+// DoCommand reports whether the bridge is connected to rosbridge.
+func (b *bridge) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return map[string]interface{}{"connected": b.connected.Load()}, nil
+}
+
+func (b *bridge) Close(ctx context.Context) error {
+	b.cancel()
+	b.activeBackgroundWorkers.Wait()
+	if b.base != nil && b.lastCmd.Load() != 0 {
+		return b.base.Stop(ctx, nil)
+	}
+	return nil
+}
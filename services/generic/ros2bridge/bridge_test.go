@@ -0,0 +1,247 @@
+package ros2bridge
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+	"nhooyr.io/websocket"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/data"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	rutils "go.viam.com/rdk/utils"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{RosbridgeURL: "ws://localhost:9090"}
+	_, _, err := conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "nothing to bridge")
+
+	conf.PublishTF = true
+	conf.Sensors = []TopicConfig{{Resource: "s1"}}
+	conf.CmdVel = &CmdVelConfig{Base: "base1"}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"s1", "base1", framesystem.InternalServiceName.String()})
+
+	conf.CmdVel.Base = ""
+	_, _, err = conf.Validate("path")
+	test.That(t, resource.GetFieldFromFieldRequiredError(err), test.ShouldEqual, "base")
+
+	conf.RosbridgeURL = "localhost:9090"
+	_, _, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "ws://")
+}
+
+// fakeRosbridge records the operations sent by the bridge and lets the test send it messages.
+type fakeRosbridge struct {
+	mu   sync.Mutex
+	ops  []rosbridgeOp
+	conn *websocket.Conn
+}
+
+func (fr *fakeRosbridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(1 << 20)
+	fr.mu.Lock()
+	fr.conn = conn
+	fr.mu.Unlock()
+	for {
+		_, data, err := conn.Read(r.Context())
+		if err != nil {
+			return
+		}
+		var op rosbridgeOp
+		if err := json.Unmarshal(data, &op); err != nil {
+			return
+		}
+		fr.mu.Lock()
+		fr.ops = append(fr.ops, op)
+		fr.mu.Unlock()
+	}
+}
+
+// lastOp returns the latest operation of kind op on topic.
+func (fr *fakeRosbridge) lastOp(op, topic string) (rosbridgeOp, bool) {
+	fr.mu.Lock()
+	defer fr.mu.Unlock()
+	for i := len(fr.ops) - 1; i >= 0; i-- {
+		if fr.ops[i].Op == op && fr.ops[i].Topic == topic {
+			return fr.ops[i], true
+		}
+	}
+	return rosbridgeOp{}, false
+}
+
+func (fr *fakeRosbridge) publish(t *testing.T, topic string, msg any) {
+	t.Helper()
+	data, err := json.Marshal(msg)
+	test.That(t, err, test.ShouldBeNil)
+	data, err = json.Marshal(rosbridgeOp{Op: "publish", Topic: topic, Msg: data})
+	test.That(t, err, test.ShouldBeNil)
+	fr.mu.Lock()
+	conn := fr.conn
+	fr.mu.Unlock()
+	test.That(t, conn.Write(context.Background(), websocket.MessageText, data), test.ShouldBeNil)
+}
+
+func TestBridge(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	ctx := context.Background()
+
+	fr := &fakeRosbridge{}
+	server := httptest.NewServer(fr)
+	defer server.Close()
+
+	cam := inject.NewCamera("cam1")
+	cam.ImagesFunc = func(
+		ctx context.Context, filterSourceNames []string, extra map[string]interface{},
+	) ([]camera.NamedImage, resource.ResponseMetadata, error) {
+		img, err := camera.NamedImageFromImage(image.NewRGBA(image.Rect(0, 0, 4, 4)), "color", rutils.MimeTypeRawRGBA, data.Annotations{})
+		return []camera.NamedImage{img}, resource.ResponseMetadata{}, err
+	}
+	s1 := inject.NewSensor("s1")
+	s1.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		return map[string]interface{}{"temperature": 21.5}, nil
+	}
+	fs := inject.NewFrameSystemService("fs")
+	fs.FrameSystemConfigFunc = func(ctx context.Context) (*framesystem.Config, error) {
+		return &framesystem.Config{Parts: []*referenceframe.FrameSystemPart{
+			{FrameConfig: referenceframe.NewLinkInFrame(referenceframe.World, spatialmath.NewZeroPose(), "base1", nil)},
+		}}, nil
+	}
+	fs.GetPoseFunc = func(
+		ctx context.Context,
+		componentName, destinationFrame string,
+		supplementalTransforms []*referenceframe.LinkInFrame,
+		extra map[string]interface{},
+	) (*referenceframe.PoseInFrame, error) {
+		pose := spatialmath.NewPose(r3.Vector{X: 1000, Y: 2000}, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: 90})
+		return referenceframe.NewPoseInFrame(destinationFrame, pose), nil
+	}
+
+	var mu sync.Mutex
+	var velocities []r3.Vector
+	stopped := 0
+	b := inject.NewBase("base1")
+	b.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		velocities = append(velocities, linear, angular)
+		return nil
+	}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped++
+		return nil
+	}
+
+	deps := resource.Dependencies{
+		camera.Named("cam1"):            cam,
+		sensor.Named("s1"):              s1,
+		base.Named("base1"):             b,
+		framesystem.InternalServiceName: fs,
+	}
+	conf := resource.Config{
+		Name:  "ros",
+		API:   generic.API,
+		Model: Model,
+		ConvertedAttributes: &Config{
+			RosbridgeURL: "ws" + strings.TrimPrefix(server.URL, "http"),
+			Cameras:      []TopicConfig{{Resource: "cam1", IntervalMS: 10}},
+			Sensors:      []TopicConfig{{Resource: "s1", Topic: "/temperature", IntervalMS: 10}},
+			PublishTF:    true,
+			TFIntervalMS: 10,
+			CmdVel:       &CmdVelConfig{Base: "base1", TimeoutMS: 100},
+		},
+	}
+	svc, err := newBridge(ctx, deps, conf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		for _, topic := range []string{"/cam1/image/compressed", "/temperature", "/tf"} {
+			_, ok := fr.lastOp("publish", topic)
+			test.That(tb, ok, test.ShouldBeTrue)
+		}
+	})
+	advertise, ok := fr.lastOp("advertise", "/cam1/image/compressed")
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, advertise.Type, test.ShouldEqual, typeCompressedImage)
+	subscribe, ok := fr.lastOp("subscribe", "/cmd_vel")
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, subscribe.Type, test.ShouldEqual, typeTwist)
+
+	op, _ := fr.lastOp("publish", "/cam1/image/compressed")
+	var img compressedImage
+	test.That(t, json.Unmarshal(op.Msg, &img), test.ShouldBeNil)
+	test.That(t, img.Format, test.ShouldEqual, "jpeg")
+	test.That(t, img.Header.FrameID, test.ShouldEqual, "cam1")
+	test.That(t, img.Data, test.ShouldNotBeEmpty)
+
+	op, _ = fr.lastOp("publish", "/temperature")
+	var readings stringMsg
+	test.That(t, json.Unmarshal(op.Msg, &readings), test.ShouldBeNil)
+	test.That(t, readings.Data, test.ShouldEqual, `{"temperature":21.5}`)
+
+	op, _ = fr.lastOp("publish", "/tf")
+	var tf tfMessage
+	test.That(t, json.Unmarshal(op.Msg, &tf), test.ShouldBeNil)
+	test.That(t, tf.Transforms, test.ShouldHaveLength, 1)
+	test.That(t, tf.Transforms[0].Header.FrameID, test.ShouldEqual, referenceframe.World)
+	test.That(t, tf.Transforms[0].ChildFrameID, test.ShouldEqual, "base1")
+	test.That(t, tf.Transforms[0].Transform.Translation, test.ShouldResemble, vector3{X: 1, Y: 2})
+	test.That(t, tf.Transforms[0].Transform.Rotation.Z, test.ShouldAlmostEqual, math.Sqrt2/2)
+	test.That(t, tf.Transforms[0].Transform.Rotation.W, test.ShouldAlmostEqual, math.Sqrt2/2)
+
+	fr.publish(t, "/cmd_vel", twist{Linear: vector3{X: 0.5}, Angular: vector3{Z: math.Pi / 2}})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, velocities, test.ShouldHaveLength, 2)
+		if len(velocities) != 2 {
+			return
+		}
+		test.That(tb, velocities[0], test.ShouldResemble, r3.Vector{Y: 500})
+		test.That(tb, velocities[1].Z, test.ShouldAlmostEqual, 90)
+	})
+	// the base is stopped once cmd_vel messages stop arriving.
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, stopped, test.ShouldEqual, 1)
+	})
+	time.Sleep(200 * time.Millisecond)
+	mu.Lock()
+	test.That(t, stopped, test.ShouldEqual, 1)
+	mu.Unlock()
+
+	status, err := svc.DoCommand(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["connected"], test.ShouldBeTrue)
+}
@@ -0,0 +1,97 @@
+package ros2bridge
+
+import (
+	"encoding/json"
+	"time"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// The ROS 2 message types the bridge sends and receives, as encoded by the rosbridge protocol.
+// Protocol: https://github.com/RobotWebTools/rosbridge_suite/blob/ros2/ROSBRIDGE_PROTOCOL.md
+const (
+	typeCompressedImage = "sensor_msgs/msg/CompressedImage"
+	typeString          = "std_msgs/msg/String"
+	typeTFMessage       = "tf2_msgs/msg/TFMessage"
+	typeTwist           = "geometry_msgs/msg/Twist"
+)
+
+// rosbridgeOp is a message of the rosbridge protocol.
+type rosbridgeOp struct {
+	Op    string          `json:"op"`
+	Topic string          `json:"topic,omitempty"`
+	Type  string          `json:"type,omitempty"`
+	Msg   json.RawMessage `json:"msg,omitempty"`
+}
+
+type rosTime struct {
+	Sec     int32  `json:"sec"`
+	Nanosec uint32 `json:"nanosec"`
+}
+
+type rosHeader struct {
+	Stamp   rosTime `json:"stamp"`
+	FrameID string  `json:"frame_id"`
+}
+
+func newHeader(t time.Time, frameID string) rosHeader {
+	return rosHeader{
+		//nolint:gosec
+		Stamp:   rosTime{Sec: int32(t.Unix()), Nanosec: uint32(t.Nanosecond())},
+		FrameID: frameID,
+	}
+}
+
+type compressedImage struct {
+	Header rosHeader `json:"header"`
+	Format string    `json:"format"`
+	// Data is encoded as base64, which is how rosbridge encodes uint8 arrays.
+	Data []byte `json:"data"`
+}
+
+type stringMsg struct {
+	Data string `json:"data"`
+}
+
+type vector3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+type rosQuaternion struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	W float64 `json:"w"`
+}
+
+type transform struct {
+	Translation vector3       `json:"translation"`
+	Rotation    rosQuaternion `json:"rotation"`
+}
+
+type transformStamped struct {
+	Header       rosHeader `json:"header"`
+	ChildFrameID string    `json:"child_frame_id"`
+	Transform    transform `json:"transform"`
+}
+
+type tfMessage struct {
+	Transforms []transformStamped `json:"transforms"`
+}
+
+type twist struct {
+	Linear  vector3 `json:"linear"`
+	Angular vector3 `json:"angular"`
+}
+
+// transformFromPose converts a pose in millimeters to a ROS transform in meters.
+func transformFromPose(pose spatialmath.Pose) transform {
+	point := pose.Point()
+	q := pose.Orientation().Quaternion()
+	return transform{
+		Translation: vector3{X: point.X / 1000, Y: point.Y / 1000, Z: point.Z / 1000},
+		Rotation:    rosQuaternion{X: q.Imag, Y: q.Jmag, Z: q.Kmag, W: q.Real},
+	}
+}
@@ -0,0 +1,294 @@
+package builtin
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r3"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/services/grasp"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	normalNeighbors = 10
+	// contactClearanceMM is how much wider than the object the fingers open before closing on it.
+	contactClearanceMM = 10.
+	// surfaceToleranceMM is how far points may be from a surface and still be on it.
+	surfaceToleranceMM = 1.
+	// duplicateDistanceMM and duplicateAngleDegs are how close two grasps are for only the better one
+	// to be kept.
+	duplicateDistanceMM = 5.
+	duplicateAngleDegs  = 15.
+)
+
+// approachRotations are the rotations about the closing axis of a grasp, away from the preferred
+// approach, that candidate grasps approach from.
+var approachRotations = []float64{0, math.Pi / 6, -math.Pi / 6, math.Pi / 3, -math.Pi / 3}
+
+// antipodalPlanner finds grasps whose two contacts face each other across the object, so that the
+// forces of the fingers oppose each other within the friction cones of the contacts.
+type antipodalPlanner struct {
+	gripper grasp.GripperGeometry
+	// approach is the unit direction the gripper prefers to approach the object in.
+	approach          r3.Vector
+	approachDistMM    float64
+	frictionCos       float64
+	maxGrasps         int
+	points, normals   []r3.Vector
+	candidateContacts []int
+}
+
+type candidateGrasp struct {
+	center, closing, approach r3.Vector
+	width, score              float64
+}
+
+func newAntipodalPlanner(
+	cloud pointcloud.PointCloud,
+	gripper grasp.GripperGeometry,
+	approach r3.Vector,
+	approachDistMM, frictionAngleDegs float64,
+	maxCandidates, maxGrasps int,
+) *antipodalPlanner {
+	p := &antipodalPlanner{
+		gripper:        gripper,
+		approach:       approach.Normalize(),
+		approachDistMM: approachDistMM,
+		frictionCos:    math.Cos(frictionAngleDegs * math.Pi / 180),
+		maxGrasps:      maxGrasps,
+	}
+	cloud.Iterate(0, 0, func(pt r3.Vector, d pointcloud.Data) bool {
+		p.points = append(p.points, pt)
+		return true
+	})
+	p.normals = estimateNormals(cloud, p.points)
+
+	// contacts are sampled evenly across the cloud so that every side of the object is tried.
+	stride := 1
+	if len(p.points) > maxCandidates {
+		stride = len(p.points) / maxCandidates
+	}
+	for i := 0; i < len(p.points); i += stride {
+		p.candidateContacts = append(p.candidateContacts, i)
+	}
+	return p
+}
+
+// estimateNormals returns the normal of the surface at each of points, pointing away from the centroid
+// of the cloud, from the plane through its nearest neighbors.
+func estimateNormals(cloud pointcloud.PointCloud, points []r3.Vector) []r3.Vector {
+	var centroid r3.Vector
+	for _, pt := range points {
+		centroid = centroid.Add(pt)
+	}
+	centroid = centroid.Mul(1 / float64(len(points)))
+
+	tree := pointcloud.ToKDTree(cloud)
+	normals := make([]r3.Vector, len(points))
+	for i, pt := range points {
+		neighbors := tree.KNearestNeighbors(pt, normalNeighbors, true)
+		normal := planeNormal(neighbors)
+		if normal.Dot(pt.Sub(centroid)) < 0 {
+			normal = normal.Mul(-1)
+		}
+		normals[i] = normal
+	}
+	return normals
+}
+
+// planeNormal returns the normal of the plane that best fits points, which is the direction they vary
+// least in.
+func planeNormal(points []*pointcloud.PointAndData) r3.Vector {
+	var mean r3.Vector
+	for _, pd := range points {
+		mean = mean.Add(pd.P)
+	}
+	mean = mean.Mul(1 / float64(len(points)))
+	cov := mat.NewSymDense(3, nil)
+	for _, pd := range points {
+		d := pd.P.Sub(mean)
+		v := []float64{d.X, d.Y, d.Z}
+		for r := 0; r < 3; r++ {
+			for c := r; c < 3; c++ {
+				cov.SetSym(r, c, cov.At(r, c)+v[r]*v[c])
+			}
+		}
+	}
+	var eig mat.EigenSym
+	if !eig.Factorize(cov, true) {
+		return r3.Vector{}
+	}
+	var vecs mat.Dense
+	eig.VectorsTo(&vecs)
+	// eigenvalues are in ascending order.
+	return r3.Vector{X: vecs.At(0, 0), Y: vecs.At(1, 0), Z: vecs.At(2, 0)}.Normalize()
+}
+
+// plan returns the best grasps found, best first.
+func (p *antipodalPlanner) plan() []candidateGrasp {
+	var candidates []candidateGrasp
+	for _, i := range p.candidateContacts {
+		q, ok := p.opposingContact(i)
+		if !ok {
+			continue
+		}
+		closing := p.points[q].Sub(p.points[i])
+		width := closing.Norm()
+		closing = closing.Mul(1 / width)
+		antipodality := math.Min(-p.normals[i].Dot(closing), p.normals[q].Dot(closing))
+		center := p.points[i].Add(p.points[q]).Mul(0.5)
+
+		preferred := p.approach.Sub(closing.Mul(p.approach.Dot(closing)))
+		if preferred.Norm() < 1e-3 {
+			// the preferred approach is along the closing axis, so any approach around it is as good.
+			preferred = closing.Ortho()
+		}
+		preferred = preferred.Normalize()
+		for _, angle := range approachRotations {
+			approach := rotateAbout(preferred, closing, angle)
+			c := candidateGrasp{center: center, closing: closing, approach: approach, width: width}
+			fill, collides := p.checkFingers(c)
+			if collides {
+				continue
+			}
+			margin := 1 - width/p.gripper.MaxOpeningMM
+			c.score = 0.4*antipodality + 0.3*(1+approach.Dot(p.approach))/2 + 0.2*fill + 0.1*margin
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	var grasps []candidateGrasp
+	minAngleCos := math.Cos(duplicateAngleDegs * math.Pi / 180)
+	for _, c := range candidates {
+		duplicate := false
+		for _, g := range grasps {
+			if c.center.Distance(g.center) < duplicateDistanceMM &&
+				math.Abs(c.closing.Dot(g.closing)) > minAngleCos && c.approach.Dot(g.approach) > minAngleCos {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		grasps = append(grasps, c)
+		if len(grasps) == p.maxGrasps {
+			break
+		}
+	}
+	return grasps
+}
+
+// opposingContact returns the point on the far side of the object from contact i, along the inverse
+// of its normal, whose normal is also within the friction cone of the line between them.
+func (p *antipodalPlanner) opposingContact(i int) (int, bool) {
+	contact, normal := p.points[i], p.normals[i]
+	if normal.Norm() == 0 {
+		return 0, false
+	}
+	closing := normal.Mul(-1)
+	// the fingers touch the object on a patch about as wide as they are.
+	radius := p.gripper.FingerWidthMM / 4
+	// the far side of the object is the farthest of the points near the line through the contact,
+	// and of those the opposing contact is the nearest to the line.
+	var onLine []int
+	farthest := 0.
+	for q, pt := range p.points {
+		d := pt.Sub(contact)
+		along := d.Dot(closing)
+		if along < p.gripper.MinOpeningMM || along > p.gripper.MaxOpeningMM-contactClearanceMM {
+			continue
+		}
+		if d.Sub(closing.Mul(along)).Norm() > radius {
+			continue
+		}
+		onLine = append(onLine, q)
+		farthest = math.Max(farthest, along)
+	}
+	best, bestOffset := -1, math.Inf(1)
+	for _, q := range onLine {
+		d := p.points[q].Sub(contact)
+		along := d.Dot(closing)
+		if along < farthest-surfaceToleranceMM {
+			continue
+		}
+		if offset := d.Sub(closing.Mul(along)).Norm(); offset < bestOffset {
+			best, bestOffset = q, offset
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	line := p.points[best].Sub(contact).Normalize()
+	if -normal.Dot(line) < p.frictionCos || p.normals[best].Dot(line) < p.frictionCos {
+		return 0, false
+	}
+	return best, true
+}
+
+// checkFingers returns the fraction of the finger pads the object fills, and whether the fingers hit
+// the object as they approach it, open, from the approach distance.
+func (p *antipodalPlanner) checkFingers(c candidateGrasp) (float64, bool) {
+	cross := c.approach.Cross(c.closing)
+	open := math.Min(c.width+contactClearanceMM, p.gripper.MaxOpeningMM)
+	halfDepth := p.gripper.FingerDepthMM / 2
+	halfWidth := p.gripper.FingerWidthMM / 2
+	var between, filledDepth float64
+	minFilled, maxFilled := math.Inf(1), math.Inf(-1)
+	for _, pt := range p.points {
+		d := pt.Sub(c.center)
+		x, y, z := d.Dot(c.closing), d.Dot(cross), d.Dot(c.approach)
+		if math.Abs(y) > halfWidth || z > halfDepth || z < -halfDepth-p.approachDistMM {
+			continue
+		}
+		// the fingers sweep from the approach distance to the grasp, on either side of the object.
+		if ax := math.Abs(x); ax > open/2 && ax < open/2+p.gripper.FingerThicknessMM {
+			return 0, true
+		}
+		if z >= -halfDepth && math.Abs(x) <= c.width/2+1 {
+			between++
+			minFilled, maxFilled = math.Min(minFilled, z), math.Max(maxFilled, z)
+		}
+	}
+	if between > 0 {
+		filledDepth = (maxFilled - minFilled) / p.gripper.FingerDepthMM
+	}
+	return math.Min(filledDepth, 1), false
+}
+
+// rotateAbout rotates v by angle radians about the unit axis.
+func rotateAbout(v, axis r3.Vector, angle float64) r3.Vector {
+	if angle == 0 {
+		return v
+	}
+	// Rodrigues' rotation formula.
+	sin, cos := math.Sincos(angle)
+	return v.Mul(cos).Add(axis.Cross(v).Mul(sin)).Add(axis.Mul(axis.Dot(v) * (1 - cos)))
+}
+
+// toGrasp returns the grasp of c with the gripper's x axis along its closing axis and its z axis along
+// its approach.
+func (p *antipodalPlanner) toGrasp(c candidateGrasp, frame string) (grasp.Grasp, error) {
+	x, z := c.closing, c.approach
+	y := z.Cross(x)
+	// the columns of the rotation are the axes of the gripper.
+	rm, err := spatialmath.NewRotationMatrix([]float64{
+		x.X, y.X, z.X,
+		x.Y, y.Y, z.Y,
+		x.Z, y.Z, z.Z,
+	})
+	if err != nil {
+		return grasp.Grasp{}, err
+	}
+	return grasp.Grasp{
+		Pose:     spatialmath.NewPose(c.center, rm),
+		Approach: spatialmath.NewPose(c.center.Sub(z.Mul(p.approachDistMM)), rm),
+		Frame:    frame,
+		WidthMM:  c.width,
+		Score:    c.score,
+	}, nil
+}
@@ -0,0 +1,221 @@
+// Package builtin implements a grasp service that plans antipodal grasps of objects in pointclouds.
+package builtin
+
+import (
+	"context"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/grasp"
+	"go.viam.com/rdk/services/vision"
+)
+
+const (
+	defaultApproachDistanceMM = 100.
+	defaultFrictionAngleDegs  = 15.
+	defaultMaxCandidates      = 300
+	defaultMaxGrasps          = 10
+	minObjectPoints           = 20
+)
+
+func init() {
+	resource.RegisterService(grasp.API, resource.DefaultServiceModel, resource.Registration[grasp.Service, *Config]{
+		Constructor: NewBuiltIn,
+	})
+}
+
+// Config describes how to configure the service. Objects are segmented from camera pointclouds by the
+// vision service, if one is configured.
+type Config struct {
+	Gripper           grasp.GripperGeometry `json:"gripper"`
+	VisionServiceName string                `json:"vision_service,omitempty"`
+	// CameraName is the camera to segment objects from when a request names none.
+	CameraName string `json:"camera,omitempty"`
+
+	// ApproachDistanceMM is how far from a grasp the gripper starts its approach.
+	ApproachDistanceMM float64 `json:"approach_distance_mm,omitempty"`
+	// FrictionAngleDegs is the half angle of the friction cone of contacts, which is how far from the
+	// line between the fingers the surface normals at both contacts may be.
+	FrictionAngleDegs float64 `json:"friction_angle_degs,omitempty"`
+	// MaxCandidates is the most contacts tried, sampled evenly across the object.
+	MaxCandidates int `json:"max_candidates,omitempty"`
+	// MaxGrasps is the most grasps returned when a request does not say.
+	MaxGrasps int `json:"max_grasps,omitempty"`
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	gripper := conf.Gripper
+	if err := gripper.Validate(); err != nil {
+		return nil, nil, resource.NewConfigValidationError(path, err)
+	}
+	if conf.ApproachDistanceMM < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("approach_distance_mm cannot be negative"))
+	}
+	if conf.FrictionAngleDegs < 0 || conf.FrictionAngleDegs >= 90 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("friction_angle_degs must be in [0, 90)"))
+	}
+	if conf.MaxCandidates < 0 || conf.MaxGrasps < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("max_candidates and max_grasps cannot be negative"))
+	}
+	if conf.CameraName != "" && conf.VisionServiceName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "vision_service")
+	}
+	if conf.VisionServiceName != "" {
+		return []string{conf.VisionServiceName}, nil, nil
+	}
+	return nil, nil, nil
+}
+
+type builtIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	conf   *Config
+	vis    vision.Service
+	logger logging.Logger
+}
+
+// NewBuiltIn returns a new grasp service for the given robot.
+func NewBuiltIn(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (grasp.Service, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	newConf := *svcConfig
+	// validating the gripper fills in its defaults.
+	if err := newConf.Gripper.Validate(); err != nil {
+		return nil, err
+	}
+	svc := &builtIn{
+		Named:  conf.ResourceName().AsNamed(),
+		conf:   withDefaults(newConf),
+		logger: logger,
+	}
+	if svcConfig.VisionServiceName != "" {
+		if svc.vis, err = vision.FromProvider(deps, svcConfig.VisionServiceName); err != nil {
+			return nil, err
+		}
+	}
+	return svc, nil
+}
+
+func withDefaults(conf Config) *Config {
+	if conf.ApproachDistanceMM == 0 {
+		conf.ApproachDistanceMM = defaultApproachDistanceMM
+	}
+	if conf.FrictionAngleDegs == 0 {
+		conf.FrictionAngleDegs = defaultFrictionAngleDegs
+	}
+	if conf.MaxCandidates == 0 {
+		conf.MaxCandidates = defaultMaxCandidates
+	}
+	if conf.MaxGrasps == 0 {
+		conf.MaxGrasps = defaultMaxGrasps
+	}
+	return &conf
+}
+
+// PlanGrasps returns antipodal grasps of the object of req, best first.
+func (svc *builtIn) PlanGrasps(ctx context.Context, req grasp.PlanReq) ([]grasp.Grasp, error) {
+	object, frame := req.Object, req.Frame
+	if object == nil {
+		var err error
+		if object, frame, err = svc.segment(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	if object.Size() < minObjectPoints {
+		return nil, errors.Errorf("object has %d points but at least %d are needed to plan grasps", object.Size(), minObjectPoints)
+	}
+
+	gripper := svc.conf.Gripper
+	if req.Gripper != nil {
+		gripper = *req.Gripper
+		if err := gripper.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	approach := r3.Vector{Z: 1}
+	if req.ApproachDirection != nil {
+		if req.ApproachDirection.Norm() == 0 {
+			return nil, errors.New("approach direction cannot be zero")
+		}
+		approach = *req.ApproachDirection
+	}
+	maxGrasps := svc.conf.MaxGrasps
+	if req.MaxGrasps > 0 {
+		maxGrasps = req.MaxGrasps
+	}
+
+	planner := newAntipodalPlanner(
+		object, gripper, approach, svc.conf.ApproachDistanceMM, svc.conf.FrictionAngleDegs, svc.conf.MaxCandidates, maxGrasps)
+	candidates := planner.plan()
+	if len(candidates) == 0 {
+		return nil, errors.New("no grasps of the object fit the gripper")
+	}
+	grasps := make([]grasp.Grasp, 0, len(candidates))
+	for _, c := range candidates {
+		g, err := planner.toGrasp(c, frame)
+		if err != nil {
+			return nil, err
+		}
+		grasps = append(grasps, g)
+	}
+	return grasps, nil
+}
+
+// segment returns the object of req segmented from its camera's pointcloud, and the frame it is in.
+func (svc *builtIn) segment(ctx context.Context, req grasp.PlanReq) (pointcloud.PointCloud, string, error) {
+	if svc.vis == nil {
+		return nil, "", errors.New("an object pointcloud is required when no vision_service is configured")
+	}
+	cameraName := req.CameraName
+	if cameraName == "" {
+		cameraName = svc.conf.CameraName
+	}
+	if cameraName == "" {
+		return nil, "", errors.New("an object pointcloud or a camera is required")
+	}
+	objects, err := svc.vis.GetObjectPointClouds(ctx, cameraName, req.Extra)
+	if err != nil {
+		return nil, "", err
+	}
+	var best pointcloud.PointCloud
+	for _, obj := range objects {
+		if obj.PointCloud == nil {
+			continue
+		}
+		if req.Label != "" && (obj.Geometry == nil || obj.Geometry.Label() != req.Label) {
+			continue
+		}
+		if best == nil || obj.Size() > best.Size() {
+			best = obj.PointCloud
+		}
+	}
+	if best == nil {
+		if req.Label != "" {
+			return nil, "", errors.Errorf("camera %q sees no object labeled %q", cameraName, req.Label)
+		}
+		return nil, "", errors.Errorf("camera %q sees no objects", cameraName)
+	}
+	return best, cameraName, nil
+}
+
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	resp, handled, err := grasp.HandleGraspCommand(ctx, svc, cmd)
+	if !handled {
+		return nil, resource.ErrDoUnimplemented
+	}
+	return resp, err
+}
@@ -0,0 +1,181 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/grasp"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	viz "go.viam.com/rdk/vision"
+)
+
+var testGripper = grasp.GripperGeometry{MaxOpeningMM: 80}
+
+// boxCloud returns points on the surface of a box of the given size centered at center, spaced 2mm
+// apart.
+func boxCloud(t *testing.T, center, size r3.Vector) pointcloud.PointCloud {
+	t.Helper()
+	cloud := pointcloud.NewBasicEmpty()
+	half := size.Mul(0.5)
+	onSurface := func(v, h float64) bool { return math.Abs(math.Abs(v)-h) < 1e-9 }
+	for x := -half.X; x <= half.X+1e-9; x += 2 {
+		for y := -half.Y; y <= half.Y+1e-9; y += 2 {
+			for z := -half.Z; z <= half.Z+1e-9; z += 2 {
+				if !onSurface(x, half.X) && !onSurface(y, half.Y) && !onSurface(z, half.Z) {
+					continue
+				}
+				test.That(t, cloud.Set(center.Add(r3.Vector{X: x, Y: y, Z: z}), nil), test.ShouldBeNil)
+			}
+		}
+	}
+	return cloud
+}
+
+func newService(t *testing.T, conf *Config, deps resource.Dependencies) grasp.Service {
+	t.Helper()
+	svc, err := NewBuiltIn(context.Background(), deps, resource.Config{
+		Name:                "grasp",
+		API:                 grasp.API,
+		Model:               resource.DefaultServiceModel,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return svc
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := (&Config{Gripper: testGripper, VisionServiceName: "vis", CameraName: "cam"}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"vis"})
+
+	_, _, err = (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_opening_mm")
+
+	_, _, err = (&Config{Gripper: testGripper, CameraName: "cam"}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{Gripper: testGripper, FrictionAngleDegs: 90}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestPlanGrasps(t *testing.T) {
+	ctx := context.Background()
+	svc := newService(t, &Config{Gripper: testGripper}, nil)
+	center := r3.Vector{Z: 500}
+	// a box 40mm across and 100mm deep seen by a camera, which only fits the gripper across x and y.
+	object := boxCloud(t, center, r3.Vector{X: 40, Y: 40, Z: 100})
+
+	grasps, err := svc.PlanGrasps(ctx, grasp.PlanReq{Object: object, Frame: "cam", MaxGrasps: 5})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(grasps), test.ShouldBeBetweenOrEqual, 1, 5)
+	for i, g := range grasps {
+		test.That(t, g.Frame, test.ShouldEqual, "cam")
+		test.That(t, g.WidthMM, test.ShouldAlmostEqual, 40, 1)
+		if i > 0 {
+			test.That(t, g.Score, test.ShouldBeLessThanOrEqualTo, grasps[i-1].Score)
+		}
+
+		// the fingers close across the box, and the gripper approaches it roughly from the camera.
+		pose := g.Pose.Point()
+		test.That(t, math.Abs(pose.X-center.X), test.ShouldBeLessThanOrEqualTo, 20)
+		test.That(t, math.Abs(pose.Y-center.Y), test.ShouldBeLessThanOrEqualTo, 20)
+		test.That(t, math.Abs(pose.Z-center.Z), test.ShouldBeLessThanOrEqualTo, 50)
+		rm := g.Pose.Orientation().RotationMatrix()
+		closing, approach := rm.Col(0), rm.Col(2)
+		test.That(t, math.Abs(closing.Z), test.ShouldBeLessThan, 1e-6)
+		test.That(t, approach.Z, test.ShouldBeGreaterThan, 0.4)
+
+		// the approach backs away from the grasp along the approach axis.
+		back := g.Pose.Point().Sub(g.Approach.Point())
+		test.That(t, back.Sub(approach.Mul(defaultApproachDistanceMM)).Norm(), test.ShouldBeLessThan, 1e-6)
+	}
+	// the best grasp approaches straight from the camera.
+	test.That(t, grasps[0].Pose.Orientation().RotationMatrix().Col(2).Z, test.ShouldAlmostEqual, 1, 1e-6)
+
+	moveReq := grasps[0].MoveReq("gripper")
+	test.That(t, moveReq.ComponentName, test.ShouldEqual, "gripper")
+	test.That(t, moveReq.Destination.Parent(), test.ShouldEqual, "cam")
+	test.That(t, moveReq.Destination.Pose(), test.ShouldEqual, grasps[0].Pose)
+
+	// a box too wide for the gripper cannot be grasped.
+	_, err = svc.PlanGrasps(ctx, grasp.PlanReq{Object: boxCloud(t, center, r3.Vector{X: 100, Y: 100, Z: 100}), Frame: "cam"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no grasps")
+
+	// unless the request is for a wider gripper.
+	grasps, err = svc.PlanGrasps(ctx, grasp.PlanReq{
+		Object:  boxCloud(t, center, r3.Vector{X: 100, Y: 100, Z: 100}),
+		Frame:   "cam",
+		Gripper: &grasp.GripperGeometry{MaxOpeningMM: 150},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, grasps[0].WidthMM, test.ShouldAlmostEqual, 100, 1)
+}
+
+func TestPlanGraspsFromCamera(t *testing.T) {
+	ctx := context.Background()
+	small := boxCloud(t, r3.Vector{X: -100, Z: 500}, r3.Vector{X: 20, Y: 20, Z: 20})
+	large := boxCloud(t, r3.Vector{X: 100, Z: 500}, r3.Vector{X: 40, Y: 40, Z: 40})
+	vis := inject.NewVisionService("vis")
+	vis.GetObjectPointCloudsFunc = func(ctx context.Context, cameraName string, extra map[string]interface{}) ([]*viz.Object, error) {
+		test.That(t, cameraName, test.ShouldEqual, "cam")
+		smallObj, err := viz.NewObjectWithLabel(small, "cube", nil)
+		test.That(t, err, test.ShouldBeNil)
+		largeObj, err := viz.NewObjectWithLabel(large, "box", nil)
+		test.That(t, err, test.ShouldBeNil)
+		return []*viz.Object{smallObj, largeObj}, nil
+	}
+	svc := newService(t, &Config{Gripper: testGripper, VisionServiceName: "vis", CameraName: "cam"},
+		resource.Dependencies{vision.Named("vis"): vis})
+
+	// the largest object is grasped unless a label is given.
+	grasps, err := svc.PlanGrasps(ctx, grasp.PlanReq{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, grasps[0].Frame, test.ShouldEqual, "cam")
+	test.That(t, grasps[0].Pose.Point().X, test.ShouldAlmostEqual, 100, 1)
+
+	grasps, err = svc.PlanGrasps(ctx, grasp.PlanReq{Label: "cube"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, grasps[0].Pose.Point().X, test.ShouldAlmostEqual, -100, 1)
+
+	_, err = svc.PlanGrasps(ctx, grasp.PlanReq{Label: "sphere"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = newService(t, &Config{Gripper: testGripper}, nil).PlanGrasps(ctx, grasp.PlanReq{CameraName: "cam"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestDoCommand(t *testing.T) {
+	ctx := context.Background()
+	svc := newService(t, &Config{Gripper: testGripper}, nil)
+	object := boxCloud(t, r3.Vector{Z: 500}, r3.Vector{X: 40, Y: 40, Z: 100})
+	want, err := svc.PlanGrasps(ctx, grasp.PlanReq{Object: object, Frame: "cam"})
+	test.That(t, err, test.ShouldBeNil)
+
+	// a module provides the service as a generic service that handles the grasp commands.
+	res := inject.NewGenericComponent("grasp")
+	res.DoFunc = svc.DoCommand
+	got, err := grasp.FromResource(res).PlanGrasps(ctx, grasp.PlanReq{Object: object, Frame: "cam"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(got), test.ShouldEqual, len(want))
+	for i := range got {
+		test.That(t, got[i].Frame, test.ShouldEqual, "cam")
+		test.That(t, got[i].Score, test.ShouldAlmostEqual, want[i].Score)
+		test.That(t, got[i].WidthMM, test.ShouldAlmostEqual, want[i].WidthMM)
+		test.That(t, got[i].Pose.Point().Distance(want[i].Pose.Point()), test.ShouldBeLessThan, 1e-3)
+		test.That(t, got[i].Approach.Point().Distance(want[i].Approach.Point()), test.ShouldBeLessThan, 1e-3)
+	}
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{grasp.DoPlanGrasps: map[string]interface{}{}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+}
@@ -0,0 +1,337 @@
+// Package grasp defines a service that plans how a gripper can grasp an object seen in a pointcloud,
+// so that objects can be picked up without an external grasp planning library.
+package grasp
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = "grasp"
+
+// API is a variable that identifies the grasp resource API.
+var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoPlanGrasps = "plan_grasps"
+)
+
+// Named is a helper for getting the named grasp service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// Deprecated: FromRobot is a helper for getting the named grasp service from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named grasp service
+// from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Service, error) {
+	return resource.FromProvider[Service](provider, Named(name))
+}
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Service]{})
+}
+
+const (
+	defaultFingerDepthMM     = 20.
+	defaultFingerThicknessMM = 10.
+	defaultFingerWidthMM     = 20.
+)
+
+// GripperGeometry describes the fingers of a parallel jaw gripper. The gripper's frame has its origin
+// midway between the pads of its fingers, its fingers close along its x axis, and it approaches
+// objects along its z axis.
+type GripperGeometry struct {
+	// MaxOpeningMM is the widest the fingers open.
+	MaxOpeningMM float64 `json:"max_opening_mm"`
+	// MinOpeningMM is the narrowest object the fingers can hold.
+	MinOpeningMM float64 `json:"min_opening_mm,omitempty"`
+	// FingerDepthMM is the length of the finger pads along the approach. FingerDepthMM defaults to 20
+	// if unspecified.
+	FingerDepthMM float64 `json:"finger_depth_mm,omitempty"`
+	// FingerThicknessMM is the size of the fingers along the direction they close in.
+	// FingerThicknessMM defaults to 10 if unspecified.
+	FingerThicknessMM float64 `json:"finger_thickness_mm,omitempty"`
+	// FingerWidthMM is the size of the fingers across the direction they close in.
+	// FingerWidthMM defaults to 20 if unspecified.
+	FingerWidthMM float64 `json:"finger_width_mm,omitempty"`
+}
+
+// Validate ensures the geometry is complete and fills in its defaults.
+func (g *GripperGeometry) Validate() error {
+	switch {
+	case g.MaxOpeningMM <= 0:
+		return errors.New("gripper max_opening_mm must be positive")
+	case g.MinOpeningMM < 0 || g.MinOpeningMM >= g.MaxOpeningMM:
+		return errors.New("gripper min_opening_mm must be in [0, max_opening_mm)")
+	case g.FingerDepthMM < 0, g.FingerThicknessMM < 0, g.FingerWidthMM < 0:
+		return errors.New("gripper finger sizes cannot be negative")
+	}
+	if g.FingerDepthMM == 0 {
+		g.FingerDepthMM = defaultFingerDepthMM
+	}
+	if g.FingerThicknessMM == 0 {
+		g.FingerThicknessMM = defaultFingerThicknessMM
+	}
+	if g.FingerWidthMM == 0 {
+		g.FingerWidthMM = defaultFingerWidthMM
+	}
+	return nil
+}
+
+// A PlanReq asks for grasps of an object. The object is either given as a pointcloud that holds only
+// it, or segmented from the pointcloud of a camera by the service.
+type PlanReq struct {
+	// Object is the pointcloud of the object, in the frame named by Frame.
+	Object pointcloud.PointCloud
+	// Frame is the name of the frame Object is in, usually the camera that saw it.
+	Frame string
+	// CameraName is the camera to segment the object from if Object is nil. The segmented object is in
+	// the frame of the camera.
+	CameraName string
+	// Label picks which segmented object to grasp. The object with the most points is grasped if Label
+	// is empty.
+	Label string
+
+	// Gripper overrides the gripper geometry the service is configured with.
+	Gripper *GripperGeometry
+	// ApproachDirection is the direction, in Frame, that the gripper prefers to approach the object in.
+	// It defaults to the z axis of Frame, which for a camera approaches the object from the camera.
+	ApproachDirection *r3.Vector
+	// MaxGrasps is the most grasps to return, or a number chosen by the service if zero.
+	MaxGrasps int
+
+	Extra map[string]interface{}
+}
+
+// A Grasp is a pose of a gripper that holds an object, with the pose to approach it from.
+type Grasp struct {
+	// Pose is the pose of the gripper frame when holding the object.
+	Pose spatialmath.Pose
+	// Approach is the pose the gripper moves from, straight along its z axis, to reach Pose.
+	Approach spatialmath.Pose
+	// Frame is the name of the frame Pose and Approach are in.
+	Frame string
+	// WidthMM is the width of the object between the fingers.
+	WidthMM float64
+	// Score ranks grasps, from 0 to 1, with higher scores being more likely to hold.
+	Score float64
+}
+
+// MoveReq returns a request for the motion service to move the gripper to the grasp.
+func (g Grasp) MoveReq(gripperName string) motion.MoveReq {
+	return motion.MoveReq{ComponentName: gripperName, Destination: referenceframe.NewPoseInFrame(g.Frame, g.Pose)}
+}
+
+// ApproachMoveReq returns a request for the motion service to move the gripper to the approach of the
+// grasp.
+func (g Grasp) ApproachMoveReq(gripperName string) motion.MoveReq {
+	return motion.MoveReq{ComponentName: gripperName, Destination: referenceframe.NewPoseInFrame(g.Frame, g.Approach)}
+}
+
+// A Service plans grasps of objects.
+type Service interface {
+	resource.Resource
+	// PlanGrasps returns grasps of the object of req, best first.
+	PlanGrasps(ctx context.Context, req PlanReq) ([]Grasp, error)
+}
+
+// poseMessage is the form a pose takes in DoCommand commands and responses, with its orientation as
+// an orientation vector in degrees.
+type poseMessage struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Z     float64 `json:"z"`
+	OX    float64 `json:"o_x"`
+	OY    float64 `json:"o_y"`
+	OZ    float64 `json:"o_z"`
+	Theta float64 `json:"theta"`
+}
+
+func poseToMessage(p spatialmath.Pose) poseMessage {
+	ov := p.Orientation().OrientationVectorDegrees()
+	pt := p.Point()
+	return poseMessage{X: pt.X, Y: pt.Y, Z: pt.Z, OX: ov.OX, OY: ov.OY, OZ: ov.OZ, Theta: ov.Theta}
+}
+
+func (m poseMessage) pose() spatialmath.Pose {
+	return spatialmath.NewPose(
+		r3.Vector{X: m.X, Y: m.Y, Z: m.Z},
+		&spatialmath.OrientationVectorDegrees{OX: m.OX, OY: m.OY, OZ: m.OZ, Theta: m.Theta},
+	)
+}
+
+type planReqMessage struct {
+	// ObjectPCD is the object as a binary PCD file, encoded as base64.
+	ObjectPCD         []byte           `json:"object_pcd,omitempty"`
+	Frame             string           `json:"frame,omitempty"`
+	CameraName        string           `json:"camera,omitempty"`
+	Label             string           `json:"label,omitempty"`
+	Gripper           *GripperGeometry `json:"gripper,omitempty"`
+	ApproachDirection *r3.Vector       `json:"approach_direction,omitempty"`
+	MaxGrasps         int              `json:"max_grasps,omitempty"`
+}
+
+type planGraspsCommand struct {
+	PlanGrasps planReqMessage         `json:"plan_grasps"`
+	Extra      map[string]interface{} `json:"extra,omitempty"`
+}
+
+func (c *planGraspsCommand) Validate() error {
+	if len(c.PlanGrasps.ObjectPCD) == 0 && c.PlanGrasps.CameraName == "" {
+		return errors.Errorf("%s requires an object_pcd or a camera", DoPlanGrasps)
+	}
+	if c.PlanGrasps.Gripper != nil {
+		return c.PlanGrasps.Gripper.Validate()
+	}
+	return nil
+}
+
+type graspMessage struct {
+	Pose     poseMessage `json:"pose"`
+	Approach poseMessage `json:"approach"`
+	Frame    string      `json:"frame"`
+	WidthMM  float64     `json:"width_mm"`
+	Score    float64     `json:"score"`
+}
+
+type planGraspsResponse struct {
+	Grasps []graspMessage `json:"grasps"`
+}
+
+func planReqToCommand(req PlanReq) (planGraspsCommand, error) {
+	msg := planReqMessage{
+		Frame:             req.Frame,
+		CameraName:        req.CameraName,
+		Label:             req.Label,
+		ApproachDirection: req.ApproachDirection,
+		MaxGrasps:         req.MaxGrasps,
+	}
+	if req.Gripper != nil {
+		// validating the command fills in the defaults of the gripper, which should not change req.
+		gripper := *req.Gripper
+		msg.Gripper = &gripper
+	}
+	if req.Object != nil {
+		var buf bytes.Buffer
+		if err := pointcloud.ToPCD(req.Object, &buf, pointcloud.PCDBinary); err != nil {
+			return planGraspsCommand{}, err
+		}
+		msg.ObjectPCD = buf.Bytes()
+	}
+	return planGraspsCommand{PlanGrasps: msg, Extra: req.Extra}, nil
+}
+
+func (c planGraspsCommand) planReq() (PlanReq, error) {
+	msg := c.PlanGrasps
+	req := PlanReq{
+		Frame:             msg.Frame,
+		CameraName:        msg.CameraName,
+		Label:             msg.Label,
+		Gripper:           msg.Gripper,
+		ApproachDirection: msg.ApproachDirection,
+		MaxGrasps:         msg.MaxGrasps,
+		Extra:             c.Extra,
+	}
+	if len(msg.ObjectPCD) != 0 {
+		object, err := pointcloud.ReadPCD(bytes.NewReader(msg.ObjectPCD), pointcloud.BasicType)
+		if err != nil {
+			return PlanReq{}, err
+		}
+		req.Object = object
+	}
+	return req, nil
+}
+
+// HandleGraspCommand services the grasp DoCommand keys using the given Service, so that grasps can be
+// planned through DoCommand by callers that only have a generic resource handle, such as the client
+// of a grasp service provided by a module. It returns false if cmd does not contain any of them so
+// that it can be chained from a DoCommand implementation.
+func HandleGraspCommand(
+	ctx context.Context,
+	svc Service,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	if _, ok := cmd[DoPlanGrasps]; !ok {
+		return nil, false, nil
+	}
+	c, err := resource.DecodeDoCommand[planGraspsCommand](cmd)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "invalid %s command", DoPlanGrasps)
+	}
+	req, err := c.planReq()
+	if err != nil {
+		return nil, true, err
+	}
+	grasps, err := svc.PlanGrasps(ctx, req)
+	if err != nil {
+		return nil, true, err
+	}
+	resp := planGraspsResponse{Grasps: make([]graspMessage, 0, len(grasps))}
+	for _, g := range grasps {
+		resp.Grasps = append(resp.Grasps, graspMessage{
+			Pose:     poseToMessage(g.Pose),
+			Approach: poseToMessage(g.Approach),
+			Frame:    g.Frame,
+			WidthMM:  g.WidthMM,
+			Score:    g.Score,
+		})
+	}
+	encoded, err := resource.EncodeDoCommand(resp)
+	return encoded, true, err
+}
+
+// FromResource returns a Service that plans grasps through the DoCommand of res, which must handle the
+// grasp DoCommand keys as HandleGraspCommand does. It lets grasp planners be provided by modules as
+// generic resources.
+func FromResource(res resource.Resource) Service {
+	if svc, ok := res.(Service); ok {
+		return svc
+	}
+	return &doCommandService{Resource: res}
+}
+
+type doCommandService struct {
+	resource.Resource
+}
+
+func (s *doCommandService) PlanGrasps(ctx context.Context, req PlanReq) ([]Grasp, error) {
+	cmd, err := planReqToCommand(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := resource.DoCommandAs[planGraspsCommand, planGraspsResponse](ctx, s, cmd)
+	if err != nil {
+		return nil, err
+	}
+	grasps := make([]Grasp, 0, len(resp.Grasps))
+	for _, msg := range resp.Grasps {
+		grasps = append(grasps, Grasp{
+			Pose:     msg.Pose.pose(),
+			Approach: msg.Approach.pose(),
+			Frame:    msg.Frame,
+			WidthMM:  msg.WidthMM,
+			Score:    msg.Score,
+		})
+	}
+	return grasps, nil
+}
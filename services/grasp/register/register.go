@@ -0,0 +1,7 @@
+// Package register registers all relevant grasp models and also API specific functions
+package register
+
+import (
+	// for grasp models.
+	_ "go.viam.com/rdk/services/grasp/builtin"
+)
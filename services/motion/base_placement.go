@@ -0,0 +1,114 @@
+package motion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+)
+
+// DoMoveWithBasePlacement is the DoCommand key of a mobile manipulation move, whose value is a
+// BasePlacementReq and whose response holds a BasePlacementResult under the same key. Use
+// MoveWithBasePlacement to make one.
+const DoMoveWithBasePlacement = "move_with_base_placement"
+
+const defaultParkingHeadings = 16
+
+var defaultParkingDistancesMM = []float64{400, 600, 800}
+
+// A BasePlacementReq moves a component carried by an arm on a mobile base to a destination the arm
+// cannot reach from where the base is. The base is first parked where the arm can best reach the
+// destination, without its geometries colliding with any obstacle, and the component is then moved
+// to the destination from there.
+type BasePlacementReq struct {
+	// ComponentName is the name of the component to move to the destination, an arm or a component
+	// attached to the end of one.
+	ComponentName string `json:"component_name"`
+	// BaseName is the name of the mobile base the arm is mounted on.
+	BaseName string `json:"base_name"`
+	// SlamName is the name of the SLAM service whose map the base moves on. The world frame must be
+	// the frame of the map.
+	SlamName string `json:"slam_name"`
+	// Destination is where the component should be moved to.
+	Destination *referenceframe.PoseInFrame `json:"destination"`
+	// WorldState holds the obstacles the base must park clear of, and the arm must avoid.
+	WorldState *referenceframe.WorldState `json:"world_state,omitempty"`
+
+	// ReachabilityMapPath is where the reachability map of the arm is cached. It is generated if it is
+	// not there, or was generated for different kinematics, which can take a while. If it is empty the
+	// map is generated for every request.
+	ReachabilityMapPath string `json:"reachability_map_path,omitempty"`
+	// ParkingDistancesMM are the distances from the destination, along the floor, that the base is
+	// considered being parked at. ParkingDistancesMM defaults to 400, 600 and 800 if unspecified.
+	ParkingDistancesMM []float64 `json:"parking_distances_mm,omitempty"`
+	// ParkingHeadings is the number of evenly spaced directions from the destination, and headings of
+	// the base at each, that the base is considered being parked at. ParkingHeadings defaults to 16 if
+	// unspecified.
+	ParkingHeadings int `json:"parking_headings,omitempty"`
+
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Validate ensures the request is complete and fills in its defaults.
+func (req *BasePlacementReq) Validate() error {
+	switch {
+	case req.ComponentName == "":
+		return errors.New("base placement move requires a component_name")
+	case req.BaseName == "":
+		return errors.New("base placement move requires a base_name")
+	case req.SlamName == "":
+		return errors.New("base placement move requires a slam_name")
+	case req.Destination == nil:
+		return errors.New("base placement move requires a destination")
+	case req.ParkingHeadings < 0:
+		return fmt.Errorf("base placement move parking_headings cannot be negative but got %d", req.ParkingHeadings)
+	}
+	for _, d := range req.ParkingDistancesMM {
+		if d <= 0 {
+			return fmt.Errorf("base placement move parking_distances_mm must be positive but got %v", d)
+		}
+	}
+	if len(req.ParkingDistancesMM) == 0 {
+		req.ParkingDistancesMM = defaultParkingDistancesMM
+	}
+	if req.ParkingHeadings == 0 {
+		req.ParkingHeadings = defaultParkingHeadings
+	}
+	return nil
+}
+
+// A BasePlacementResult is the outcome of a mobile manipulation move.
+type BasePlacementResult struct {
+	// Parking is where the base was parked, in the world frame.
+	Parking *referenceframe.PoseInFrame `json:"parking"`
+	// Reachability is the fraction of approach directions the arm can reach the destination from,
+	// with the base parked, according to its reachability map.
+	Reachability float64 `json:"reachability"`
+	// BaseExecutionID identifies the execution that moved the base.
+	BaseExecutionID ExecutionID `json:"base_execution_id"`
+}
+
+type basePlacementCommand struct {
+	MoveWithBasePlacement BasePlacementReq `json:"move_with_base_placement"`
+}
+
+func (c *basePlacementCommand) Validate() error {
+	return c.MoveWithBasePlacement.Validate()
+}
+
+type basePlacementResponse struct {
+	MoveWithBasePlacement BasePlacementResult `json:"move_with_base_placement"`
+}
+
+// MoveWithBasePlacement makes a mobile manipulation move with a motion service that supports
+// DoMoveWithBasePlacement.
+func MoveWithBasePlacement(ctx context.Context, svc Service, req BasePlacementReq) (BasePlacementResult, error) {
+	resp, err := resource.DoCommandAs[basePlacementCommand, basePlacementResponse](
+		ctx, svc, basePlacementCommand{MoveWithBasePlacement: req})
+	if err != nil {
+		return BasePlacementResult{}, err
+	}
+	return resp.MoveWithBasePlacement, nil
+}
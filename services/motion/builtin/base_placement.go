@@ -0,0 +1,268 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// basePlacementPollInterval is how often the base motion service is polled while the base parks.
+const basePlacementPollInterval = 100 * time.Millisecond
+
+// handleBasePlacementCommand makes the mobile manipulation move of cmd, if it has one.
+func (ms *builtIn) handleBasePlacementCommand(
+	ctx context.Context,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	raw, ok := cmd[motion.DoMoveWithBasePlacement]
+	if !ok {
+		return nil, false, nil
+	}
+	m, err := utils.AssertType[map[string]interface{}](raw)
+	if err != nil {
+		return nil, true, err
+	}
+	req, err := resource.DecodeDoCommand[motion.BasePlacementReq](m)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "invalid %s command", motion.DoMoveWithBasePlacement)
+	}
+	result, err := ms.moveWithBasePlacement(ctx, req)
+	if err != nil {
+		return nil, true, err
+	}
+	encoded, err := resource.EncodeDoCommand(result)
+	if err != nil {
+		return nil, true, err
+	}
+	return map[string]interface{}{motion.DoMoveWithBasePlacement: encoded}, true, nil
+}
+
+// moveWithBasePlacement parks the base of req where its arm can best reach the destination, then moves
+// the component of req to the destination.
+func (ms *builtIn) moveWithBasePlacement(ctx context.Context, req motion.BasePlacementReq) (motion.BasePlacementResult, error) {
+	ms.mu.RLock()
+	baseMotion := ms.baseMotion
+	placement, err := ms.placeBase(ctx, req)
+	ms.mu.RUnlock()
+	if err != nil {
+		return motion.BasePlacementResult{}, err
+	}
+
+	executionID, err := baseMotion.MoveOnMap(ctx, motion.MoveOnMapReq{
+		ComponentName: req.BaseName,
+		Destination:   placement.parking,
+		SlamName:      req.SlamName,
+		Extra:         req.Extra,
+	})
+	if err != nil {
+		return motion.BasePlacementResult{}, errors.Wrap(err, "failed to park the base")
+	}
+	result := motion.BasePlacementResult{
+		Parking:         referenceframe.NewPoseInFrame(referenceframe.World, placement.parking),
+		Reachability:    placement.reachability,
+		BaseExecutionID: executionID,
+	}
+	if err := motion.PollHistoryUntilSuccessOrError(ctx, baseMotion, basePlacementPollInterval, motion.PlanHistoryReq{
+		ComponentName: req.BaseName,
+		ExecutionID:   executionID,
+	}); err != nil {
+		return result, errors.Wrap(err, "failed to park the base")
+	}
+
+	// The destination is given relative to the parked base, as the frame system may not know where
+	// the base is on the map.
+	destination := spatialmath.Compose(spatialmath.PoseInverse(placement.parking), placement.destination)
+	if _, err := ms.Move(ctx, motion.MoveReq{
+		ComponentName: req.ComponentName,
+		Destination:   referenceframe.NewPoseInFrame(req.BaseName, destination),
+		WorldState:    req.WorldState,
+		Extra:         req.Extra,
+	}); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// basePlacement is where a base is to be parked for a mobile manipulation move, in the world frame.
+type basePlacement struct {
+	parking      spatialmath.Pose
+	destination  spatialmath.Pose
+	reachability float64
+}
+
+// placeBase chooses where to park the base of req, among the candidates around the destination whose
+// base geometries are clear of the obstacles of req, as the one the arm can best reach the destination
+// from according to its reachability map.
+func (ms *builtIn) placeBase(ctx context.Context, req motion.BasePlacementReq) (basePlacement, error) {
+	if ms.baseMotion == nil {
+		return basePlacement{}, errors.New("moves with base placement need a base_motion_service to be configured")
+	}
+	fs, err := ms.getFrameSystem(ctx, req.WorldState.Transforms())
+	if err != nil {
+		return basePlacement{}, err
+	}
+	inputs, err := ms.fsService.CurrentInputs(ctx)
+	if err != nil {
+		return basePlacement{}, err
+	}
+	linearInputs := inputs.ToLinearInputs()
+	poseIn := func(object *referenceframe.PoseInFrame, frame string) (spatialmath.Pose, error) {
+		tf, err := fs.Transform(linearInputs, object, frame)
+		if err != nil {
+			return nil, err
+		}
+		return tf.(*referenceframe.PoseInFrame).Pose(), nil
+	}
+	originOf := func(frame string) *referenceframe.PoseInFrame {
+		return referenceframe.NewPoseInFrame(frame, spatialmath.NewZeroPose())
+	}
+
+	armName, err := carryingArm(fs, req.ComponentName)
+	if err != nil {
+		return basePlacement{}, err
+	}
+	var rm *motionplan.ReachabilityMap
+	if req.ReachabilityMapPath != "" {
+		rm, err = motionplan.LoadOrNewReachabilityMap(ctx, fs, armName, nil, req.ReachabilityMapPath)
+	} else {
+		rm, err = motionplan.NewReachabilityMap(ctx, fs, armName, nil)
+	}
+	if err != nil {
+		return basePlacement{}, err
+	}
+
+	destination, err := poseIn(req.Destination, referenceframe.World)
+	if err != nil {
+		return basePlacement{}, err
+	}
+	// the map is of where the end of the arm reaches, which the component may be offset from
+	componentInArm, err := poseIn(originOf(req.ComponentName), armName)
+	if err != nil {
+		return basePlacement{}, err
+	}
+	target := spatialmath.Compose(destination, spatialmath.PoseInverse(componentInArm))
+	base, err := poseIn(originOf(req.BaseName), referenceframe.World)
+	if err != nil {
+		return basePlacement{}, err
+	}
+	referenceInBase, err := poseIn(originOf(rm.Reference), req.BaseName)
+	if err != nil {
+		return basePlacement{}, err
+	}
+
+	baseGeometries, err := referenceframe.FrameSystemGeometriesForFrames(fs, linearInputs, map[string]bool{
+		req.BaseName:             true,
+		req.BaseName + "_origin": true,
+	})
+	if err != nil {
+		return basePlacement{}, err
+	}
+	obstacles, err := req.WorldState.ObstaclesInWorldFrame(fs, inputs)
+	if err != nil {
+		return basePlacement{}, err
+	}
+
+	var candidates, references []spatialmath.Pose
+	for _, candidate := range parkingCandidates(destination.Point(), base.Point().Z, req.ParkingDistancesMM, req.ParkingHeadings) {
+		// base geometries are moved from where the base is to the candidate
+		moved := spatialmath.Compose(candidate, spatialmath.PoseInverse(base))
+		collides, err := geometriesCollide(baseGeometries, moved, obstacles.Geometries())
+		if err != nil {
+			return basePlacement{}, err
+		}
+		if collides {
+			continue
+		}
+		candidates = append(candidates, candidate)
+		references = append(references, spatialmath.Compose(candidate, referenceInBase))
+	}
+	if len(candidates) == 0 {
+		return basePlacement{}, errors.New("every place the base could park near the destination collides with an obstacle")
+	}
+	best, fraction, err := rm.BestBasePlacement([]spatialmath.Pose{target}, references)
+	if err != nil {
+		return basePlacement{}, err
+	}
+	if fraction == 0 {
+		return basePlacement{}, fmt.Errorf("arm %s cannot reach the destination from anywhere the base could park", armName)
+	}
+	score := rm.Score(spatialmath.Compose(spatialmath.PoseInverse(references[best]), target))
+	return basePlacement{parking: candidates[best], destination: destination, reachability: score.Reachability}, nil
+}
+
+// carryingArm returns the name of the frame that moves the component named name, which is the component
+// itself if it moves, and otherwise the nearest frame it is attached to that does.
+func carryingArm(fs *referenceframe.FrameSystem, name string) (string, error) {
+	frame := fs.Frame(name)
+	if frame == nil {
+		return "", referenceframe.NewFrameMissingError(name)
+	}
+	for frame != fs.World() {
+		if len(frame.DoF()) > 0 {
+			return frame.Name(), nil
+		}
+		parent, err := fs.Parent(frame)
+		if err != nil {
+			return "", err
+		}
+		frame = parent
+	}
+	return "", fmt.Errorf("component %s is not attached to an arm", name)
+}
+
+// parkingCandidates returns the poses of the base at each distance from the destination, in each of
+// headings evenly spaced directions from it, and facing each of headings evenly spaced headings.
+func parkingCandidates(destination r3.Vector, z float64, distances []float64, headings int) []spatialmath.Pose {
+	candidates := make([]spatialmath.Pose, 0, len(distances)*headings*headings)
+	step := 2 * math.Pi / float64(headings)
+	for _, d := range distances {
+		for i := 0; i < headings; i++ {
+			pt := r3.Vector{
+				X: destination.X + d*math.Cos(float64(i)*step),
+				Y: destination.Y + d*math.Sin(float64(i)*step),
+				Z: z,
+			}
+			for j := 0; j < headings; j++ {
+				candidates = append(candidates, spatialmath.NewPose(pt, &spatialmath.OrientationVectorDegrees{
+					OZ:    1,
+					Theta: utils.RadToDeg(float64(j) * step),
+				}))
+			}
+		}
+	}
+	return candidates
+}
+
+// geometriesCollide returns whether any of the geometries, moved by pose, collides with an obstacle.
+func geometriesCollide(
+	geometries map[string]*referenceframe.GeometriesInFrame,
+	pose spatialmath.Pose,
+	obstacles []spatialmath.Geometry,
+) (bool, error) {
+	for _, gif := range geometries {
+		for _, g := range gif.Geometries() {
+			moved := g.Transform(pose)
+			for _, obstacle := range obstacles {
+				collides, _, err := moved.CollidesWith(obstacle, defaultCollisionBuffer)
+				if err != nil {
+					return false, err
+				}
+				if collides {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,119 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// parkingBase is a motion service which parks bases immediately, recording where.
+type parkingBase struct {
+	motion.Service
+	executionID motion.ExecutionID
+	parked      []motion.MoveOnMapReq
+}
+
+func (pb *parkingBase) MoveOnMap(ctx context.Context, req motion.MoveOnMapReq) (motion.ExecutionID, error) {
+	pb.parked = append(pb.parked, req)
+	return pb.executionID, nil
+}
+
+func (pb *parkingBase) PlanHistory(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+	if req.ExecutionID != pb.executionID {
+		return nil, nil
+	}
+	return []motion.PlanWithStatus{{StatusHistory: []motion.PlanStatus{{State: motion.PlanStateSucceeded}}}}, nil
+}
+
+func TestMoveWithBasePlacement(t *testing.T) {
+	ctx := context.Background()
+	mapPath := filepath.Join(t.TempDir(), "mobileArm.json")
+	destination := referenceframe.NewPoseInFrame(referenceframe.World, spatialmath.NewPose(
+		r3.Vector{X: 2000, Y: 0, Z: 400},
+		&spatialmath.OrientationVectorDegrees{OZ: -1},
+	))
+
+	setup := func(t *testing.T) (*builtIn, *parkingBase) {
+		t.Helper()
+		svc, teardown := setupMotionServiceFromConfig(t, "../data/mobile_arm.json")
+		t.Cleanup(teardown)
+		ms := svc.(*builtIn)
+		base := &parkingBase{executionID: uuid.New()}
+		ms.baseMotion = base
+		return ms, base
+	}
+	req := motion.BasePlacementReq{
+		ComponentName:       "mobileGripper",
+		BaseName:            "mobileBase",
+		SlamName:            "slam",
+		Destination:         destination,
+		ReachabilityMapPath: mapPath,
+	}
+
+	t.Run("parks within reach and clear of obstacles", func(t *testing.T) {
+		ms, base := setup(t)
+		// the obstacle covers the places the base could park on the far side of the destination
+		obstacle, err := spatialmath.NewBox(
+			spatialmath.NewPoseFromPoint(r3.Vector{X: 2600, Y: 0, Z: 100}),
+			r3.Vector{X: 1200, Y: 2000, Z: 200},
+			"wall",
+		)
+		test.That(t, err, test.ShouldBeNil)
+		worldState, err := referenceframe.NewWorldState(
+			[]*referenceframe.GeometriesInFrame{referenceframe.NewGeometriesInFrame(referenceframe.World, []spatialmath.Geometry{obstacle})},
+			nil,
+		)
+		test.That(t, err, test.ShouldBeNil)
+		withObstacle := req
+		withObstacle.WorldState = worldState
+
+		result, err := motion.MoveWithBasePlacement(ctx, ms, withObstacle)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, result.Reachability, test.ShouldBeGreaterThan, 0)
+		test.That(t, base.parked, test.ShouldHaveLength, 1)
+		test.That(t, base.parked[0].ComponentName, test.ShouldEqual, "mobileBase")
+		test.That(t, base.parked[0].SlamName, test.ShouldEqual, "slam")
+		test.That(t, result.BaseExecutionID, test.ShouldEqual, base.executionID)
+
+		parking := result.Parking.Pose().Point()
+		test.That(t, spatialmath.PoseAlmostCoincident(result.Parking.Pose(), base.parked[0].Destination), test.ShouldBeTrue)
+		test.That(t, parking.X, test.ShouldBeLessThan, 2000)
+		distance := math.Hypot(parking.X-2000, parking.Y)
+		test.That(t, math.Round(distance), test.ShouldBeIn, 400., 600., 800.)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		ms, base := setup(t)
+		unreachable := req
+		unreachable.Destination = referenceframe.NewPoseInFrame(referenceframe.World, spatialmath.NewPoseFromPoint(r3.Vector{X: 2000, Z: 5000}))
+		_, err := motion.MoveWithBasePlacement(ctx, ms, unreachable)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "cannot reach")
+		test.That(t, base.parked, test.ShouldBeEmpty)
+
+		ms.baseMotion = nil
+		_, err = motion.MoveWithBasePlacement(ctx, ms, req)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "base_motion_service")
+
+		incomplete := req
+		incomplete.BaseName = ""
+		_, err = motion.MoveWithBasePlacement(ctx, ms, incomplete)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		deps, _, err := (&Config{BaseMotionService: "base"}).Validate("")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, deps, test.ShouldContain, motion.Named("base").String())
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,11 +24,12 @@ import (
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/gantry"
 	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/motionplan"
 	"go.viam.com/rdk/motionplan/armplanning"
-	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot/framesystem"
@@ -55,6 +57,7 @@ func init() {
 // export keys to be used with DoCommand so they can be referenced by clients.
 const (
 	DoPlan              = "plan"
+	DoPlanRequest       = "plan_request"
 	DoExecute           = "execute"
 	DoExecuteCheckStart = "executeCheckStart"
 
@@ -62,10 +65,14 @@ const (
 	DoTeleopMove   = "teleop_move"
 	DoTeleopStop   = "teleop_stop"
 	DoTeleopStatus = "teleop_status"
+
+	DoExecutePriority   = "executePriority"
+	DoExecutePreemption = "executePreemption"
+	DoExecuteSpeedScale = "executeSpeedScale"
+	DoListExecutions    = "list_executions"
 )
 
 const (
-	builtinOpLabel                     = "motion-service"
 	maxTravelDistanceMM                = 5e6 // this is equivalent to 5km
 	lookAheadDistanceMM        float64 = 5e6
 	defaultSmoothIter                  = 30
@@ -75,6 +82,7 @@ const (
 	defaultGlobePlanDeviationM         = 2.6
 	defaultCollisionBuffer             = 150. // mm
 	defaultExecuteEpsilon              = 0.01 // rad or mm
+	maxMoveStartAttempts               = 3
 )
 
 // inputEnabledActuator is an actuator that interacts with the frame system.
@@ -111,6 +119,36 @@ type Config struct {
 	// more latency); higher alpha is more responsive and closer to the raw planned motion. The
 	// valid range is (0, 1]: 1 disables smoothing, and 0 (the zero value) selects the default of 0.5.
 	TeleopSmoothAlpha float64 `json:"teleop_smooth_alpha"`
+
+	// ArmSpeedLimits holds the joint velocity and acceleration limits of arms, by name. A plan
+	// execution's speed scale is applied to these limits, so only the arms listed here can be slowed
+	// down; executions moving any other component always run at full speed.
+	ArmSpeedLimits map[string]ArmSpeedLimits `json:"arm_speed_limits,omitempty"`
+
+	// RemotePlanner names a motion service which Move requests are planned by instead of this one,
+	// for machines too slow to plan quickly themselves. It is typically the builtin motion service
+	// of a remote on a more powerful machine, named like "remote:builtin", or another service
+	// supporting DoPlanRequest. The plans it returns are checked against this robot's frame system
+	// and obstacles before they are executed.
+	RemotePlanner string `json:"remote_planner,omitempty"`
+	// RemotePlannerFallback plans locally if the remote planner fails, or returns a plan which fails
+	// the checks.
+	RemotePlannerFallback bool `json:"remote_planner_fallback,omitempty"`
+
+	// BaseMotionService names a motion service which moves mobile bases with MoveOnMap, for
+	// DoMoveWithBasePlacement moves.
+	BaseMotionService string `json:"base_motion_service,omitempty"`
+
+	// TrajectoryFilters holds the limits the positions commanded to arms and gantries while executing
+	// plans are filtered to, by component name, so that they move smoothly between the waypoints of
+	// a plan rather than jerking to each in turn.
+	TrajectoryFilters map[string]TrajectoryFilterConfig `json:"trajectory_filters,omitempty"`
+}
+
+// ArmSpeedLimits are the joint velocity and acceleration limits an arm moves at when unscaled.
+type ArmSpeedLimits struct {
+	MaxVelDegsPerSec  float64 `json:"max_vel_degs_per_sec"`
+	MaxAccDegsPerSec2 float64 `json:"max_acc_degs_per_sec2"`
 }
 
 func (c *Config) shouldWritePlan(start time.Time, err error) bool {
@@ -145,7 +183,31 @@ func (c *Config) Validate(path string) ([]string, []string, error) {
 		return nil, nil, fmt.Errorf("teleop_smooth_alpha must be in [0, 1] (0 selects the default), got %v", c.TeleopSmoothAlpha)
 	}
 
-	return []string{framesystem.InternalServiceName.String()}, nil, nil
+	for name, limits := range c.ArmSpeedLimits {
+		if limits.MaxVelDegsPerSec <= 0 || limits.MaxAccDegsPerSec2 <= 0 {
+			return nil, nil, resource.NewConfigValidationError(fmt.Sprintf("%s.arm_speed_limits.%s", path, name),
+				errors.New("max_vel_degs_per_sec and max_acc_degs_per_sec2 must be positive"))
+		}
+	}
+
+	for name, filter := range c.TrajectoryFilters {
+		if err := filter.validate(fmt.Sprintf("%s.trajectory_filters.%s", path, name)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if c.RemotePlannerFallback && c.RemotePlanner == "" {
+		return nil, nil, errors.New("need a remote_planner if you set remote_planner_fallback")
+	}
+
+	deps := []string{framesystem.InternalServiceName.String()}
+	if c.RemotePlanner != "" {
+		deps = append(deps, motion.Named(c.RemotePlanner).String())
+	}
+	if c.BaseMotionService != "" {
+		deps = append(deps, motion.Named(c.BaseMotionService).String())
+	}
+	return deps, nil, nil
 }
 
 type builtIn struct {
@@ -157,8 +219,11 @@ type builtIn struct {
 	slamServices            map[string]slam.Service
 	visionServices          map[string]vision.Service
 	components              map[string]resource.Resource
+	remotePlanner           motion.Service
+	baseMotion              motion.Service
 	logger                  logging.Logger
 	configuredDefaultExtras map[string]any
+	executions              *executionManager
 
 	// Teleop pipeline. Protected by teleopMu (separate from mu to simplify lock ordering).
 	teleopMu       sync.RWMutex
@@ -173,6 +238,7 @@ func NewBuiltIn(
 		Named:                   conf.ResourceName().AsNamed(),
 		logger:                  logger,
 		configuredDefaultExtras: make(map[string]any),
+		executions:              newExecutionManager(),
 	}
 
 	if err := ms.BuiltInReconfigure(ctx, deps, conf); err != nil {
@@ -197,6 +263,8 @@ func (ms *builtIn) BuiltInReconfigure(
 
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
+	// executions run without holding ms.mu, so stop any using the components about to be replaced
+	ms.executions.stopAll()
 	config, err := resource.NativeConfig[*Config](conf)
 	if err != nil {
 		return err
@@ -211,6 +279,19 @@ func (ms *builtIn) BuiltInReconfigure(
 		ms.configuredDefaultExtras["num_threads"] = config.NumThreads
 	}
 
+	ms.remotePlanner = nil
+	if config.RemotePlanner != "" {
+		if ms.remotePlanner, err = motion.FromProvider(deps, config.RemotePlanner); err != nil {
+			return err
+		}
+	}
+	ms.baseMotion = nil
+	if config.BaseMotionService != "" {
+		if ms.baseMotion, err = motion.FromProvider(deps, config.BaseMotionService); err != nil {
+			return err
+		}
+	}
+
 	movementSensors := make(map[string]movementsensor.MovementSensor)
 	slamServices := make(map[string]slam.Service)
 	visionServices := make(map[string]vision.Service)
@@ -225,6 +306,8 @@ func (ms *builtIn) BuiltInReconfigure(
 			slamServices[name.Name] = dep
 		case vision.Service:
 			visionServices[name.Name] = dep
+		case motion.Service:
+			// the remote planner and base motion service, which are looked up by name above
 		default:
 			componentMap[name.Name] = dep
 		}
@@ -244,22 +327,47 @@ func (ms *builtIn) Close(ctx context.Context) error {
 		ms.teleopPipeline = nil
 	}
 	ms.teleopMu.Unlock()
+	ms.executions.stopAll()
 
 	return nil
 }
 
 func (ms *builtIn) Move(ctx context.Context, req motion.MoveReq) (bool, error) {
+	for attempt := 1; ; attempt++ {
+		execution, err := ms.prepareMove(ctx, req)
+		if err != nil {
+			return false, err
+		}
+		// The plan starts from the inputs read while planning, but an execution preempted by this one may
+		// have kept the components moving until it stopped. Execution therefore checks that the components
+		// are still at the start of the plan once they are ours if it preempted another execution, and the
+		// move is replanned if they are not.
+		err = ms.executeManaged(ctx, execution)
+		var notAtStart *notAtStartError
+		if errors.As(err, &notAtStart) && attempt < maxMoveStartAttempts {
+			ms.logger.CDebugf(ctx, "replanning move since the components moved while planning: %v", err)
+			continue
+		}
+		return err == nil, err
+	}
+}
+
+// prepareMove plans req and prepares the execution of the plan.
+func (ms *builtIn) prepareMove(ctx context.Context, req motion.MoveReq) (preparedExecution, error) {
 	ms.mu.RLock()
 	defer ms.mu.RUnlock()
-	operation.CancelOtherWithLabel(ctx, builtinOpLabel)
 
 	ms.applyDefaultExtras(req.Extra)
+	execReq, err := executionRequestFromExtra(req.Extra)
+	if err != nil {
+		return preparedExecution{}, err
+	}
 	plan, err := ms.plan(ctx, req, ms.logger)
 	if err != nil {
-		return false, err
+		return preparedExecution{}, err
 	}
-	err = ms.execute(ctx, plan.Trajectory(), math.MaxFloat64)
-	return err == nil, err
+	execReq.preemptedEpsilon = defaultExecuteEpsilon
+	return ms.prepareExecution(plan.Trajectory(), math.MaxFloat64, execReq), nil
 }
 
 func (ms *builtIn) MoveOnMap(ctx context.Context, req motion.MoveOnMapReq) (motion.ExecutionID, error) {
@@ -310,34 +418,101 @@ func (ms *builtIn) PlanHistory(
 //     required key: DoPlan
 //     input value: a motionpb.MoveRequest which will be used to create a Trajectory
 //     output value: a motionplan.Trajectory specified as a map (the mapstructure.Decode function is useful for decoding this)
+//   - DoPlanRequest plans an armplanning.PlanRequest without executing it, for robots which offload
+//     planning to this one with their remote_planner attribute
+//     required key: DoPlanRequest
+//     input value: an armplanning.PlanRequest
+//     output value: the plan, as serialized by motionplan.MarshalPlan
 //   - DoExecute takes a Trajectory and executes it
 //     required key: DoExecute
 //     input value: a motionplan.Trajectory
+//     optional keys: DoExecutePriority and DoExecutePreemption, which behave like the ExtraPriority and
+//     ExtraPreemption keys of a Move request's extra
+//     output value: a bool
+//   - DoListExecutions returns the plan executions currently in progress
+//     required key: DoListExecutions
+//     output value: a list of maps with the id, components, priority, start time, pause state, and speed scale
+//     of each execution
+//   - motion.DoPauseExecution, motion.DoResumeExecution, and motion.DoScaleExecution pause, resume, or change
+//     the speed scale of in-progress executions without replanning them
+//     required key: one of motion.DoPauseExecution, motion.DoResumeExecution, or motion.DoScaleExecution
+//     (whose value is the new speed scale in (0, 1])
+//     optional key: motion.DoExecutionID, the id of the execution to control; all executions are controlled if omitted
 //     output value: a bool
+//   - motion.DoGuardedMove moves an arm in a straight line until a force torque sensor senses contact
+//     required key: motion.DoGuardedMove
+//     input value: a motion.GuardedMoveReq
+//     output value: a motion.GuardedMoveResult
+//   - motion.DoMoveWithBasePlacement parks a mobile base where the arm on it can reach a destination, with the
+//     configured base_motion_service, then moves a component to the destination
+//     required key: motion.DoMoveWithBasePlacement
+//     input value: a motion.BasePlacementReq
+//     output value: a motion.BasePlacementResult
 func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
 	// Handle teleop commands first (they manage their own locking).
 	if resp, handled, err := ms.handleTeleopCommand(ctx, cmd); handled {
 		return resp, err
 	}
+	// Execution control does not need ms.mu since it only acts on in-progress executions.
+	if resp, handled, err := ms.handleExecutionControlCommand(cmd); handled {
+		return resp, err
+	}
+	// Guarded moves only hold ms.mu while looking up their components, as they may take a while.
+	if resp, handled, err := ms.handleGuardedMoveCommand(ctx, cmd); handled {
+		return resp, err
+	}
+	// Plan requests carry their own frame system and obstacles, so do not need ms.mu either.
+	if resp, handled, err := ms.handlePlanRequestCommand(ctx, cmd); handled {
+		return resp, err
+	}
+	// Base placement moves hold ms.mu only while planning, as moving the base may take a while.
+	if resp, handled, err := ms.handleBasePlacementCommand(ctx, cmd); handled {
+		return resp, err
+	}
 
+	// Executions run without holding ms.mu, so that a paused or slowed execution does not hold up
+	// reconfiguration.
 	ms.mu.RLock()
-	defer ms.mu.RUnlock()
+	resp, execution, err := ms.doLockedCommand(ctx, cmd)
+	ms.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if execution != nil {
+		if err := ms.executeManaged(ctx, *execution); err != nil {
+			return nil, err
+		}
+		resp[DoExecute] = true
+	}
+	if _, ok := cmd[DoListExecutions]; ok {
+		resp[DoListExecutions] = ms.executions.list()
+	}
+	return resp, nil
+}
+
+// doLockedCommand handles the DoPlan and DoExecute commands of DoCommand, which need ms.mu to be
+// read-locked. It returns the execution DoExecute asks for, if any, for the caller to execute once
+// ms.mu is released.
+func (ms *builtIn) doLockedCommand(
+	ctx context.Context,
+	cmd map[string]interface{},
+) (map[string]interface{}, *preparedExecution, error) {
 	resp := make(map[string]interface{}, 0)
 	if req, ok := cmd[DoPlan]; ok {
 		s, err := utils.AssertType[string](req)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		var moveReqProto pb.MoveRequest
 		err = protojson.Unmarshal([]byte(s), &moveReqProto)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		fields := moveReqProto.Extra.AsMap()
 		if extra, err := utils.AssertType[map[string]interface{}](fields["fields"]); err == nil {
 			v, err := structpb.NewStruct(extra)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			moveReqProto.Extra = v
 		}
@@ -348,11 +523,11 @@ func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (m
 
 		moveReq, err := motion.MoveReqFromProto(&moveReqProto)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		plan, err := ms.plan(ctx, moveReq, obsLogger)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		partialLogString := "returning partial plan up to waypoint"
@@ -381,7 +556,7 @@ func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (m
 	if req, ok := cmd[DoExecute]; ok {
 		var trajectory motionplan.Trajectory
 		if err := mapstructure.Decode(req, &trajectory); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		// if included and set to true
 		epsilon := math.MaxFloat64
@@ -396,12 +571,18 @@ func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (m
 
 			resp[DoExecuteCheckStart] = "resource at starting location"
 		}
-		if err := ms.execute(ctx, trajectory, epsilon); err != nil {
-			return nil, err
+		execReq, err := executionRequestFromExtra(map[string]interface{}{
+			ExtraPriority:   cmd[DoExecutePriority],
+			ExtraPreemption: cmd[DoExecutePreemption],
+			ExtraSpeedScale: cmd[DoExecuteSpeedScale],
+		})
+		if err != nil {
+			return nil, nil, err
 		}
-		resp[DoExecute] = true
+		execution := ms.prepareExecution(trajectory, epsilon, execReq)
+		return resp, &execution, nil
 	}
-	return resp, nil
+	return resp, nil, nil
 }
 
 func (ms *builtIn) getFrameSystem(ctx context.Context, transforms []*referenceframe.LinkInFrame) (*referenceframe.FrameSystem, error) {
@@ -533,7 +714,7 @@ func (ms *builtIn) plan(ctx context.Context, req motion.MoveReq, logger logging.
 	}
 
 	start := time.Now()
-	plan, meta, err := armplanning.PlanMotion(ctx, logger, planRequest)
+	plan, meta, err := ms.planMotion(ctx, logger, planRequest)
 	if ms.conf.shouldWritePlan(start, err) {
 		var traceID string
 		if span := trace.FromContext(ctx); span != nil {
@@ -607,7 +788,90 @@ func (ms *builtIn) planTeleopMulti(
 	return plan, err
 }
 
-func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory, epsilon float64) error {
+// notAtStartError is returned when a component is not where the trajectory being executed starts.
+type notAtStartError struct {
+	name              string
+	epsilon           float64
+	expected, current []referenceframe.Input
+}
+
+func (e *notAtStartError) Error() string {
+	return fmt.Sprintf("component %v is not within %v of the current position. Expected inputs %v current inputs %v",
+		e.name, e.epsilon, e.expected, e.current)
+}
+
+// preparedExecution is a trajectory to execute along with the state of the motion service it is executed with,
+// so that it can be executed without holding ms.mu.
+type preparedExecution struct {
+	trajectory motionplan.Trajectory
+	components map[string]resource.Resource
+	filters    map[string]*TrajectoryFilterConfig
+	epsilon    float64
+	req        executionRequest
+}
+
+// prepareExecution prepares the trajectory to be executed by executeManaged. It must be called with ms.mu
+// read-locked.
+func (ms *builtIn) prepareExecution(
+	trajectory motionplan.Trajectory,
+	epsilon float64,
+	req executionRequest,
+) preparedExecution {
+	// only the claimed components are commanded or checked, since the rest may belong to other executions
+	trajectory = onlyComponentsMoved(trajectory)
+	components := ms.components
+	filters := map[string]*TrajectoryFilterConfig{}
+	for name := range componentsMoved(trajectory) {
+		filter, ok := ms.conf.TrajectoryFilters[name]
+		if !ok {
+			continue
+		}
+		switch components[name].(type) {
+		case arm.Arm, gantry.Gantry:
+			filters[name] = &filter
+		}
+	}
+	req.generation = ms.executions.currentGeneration()
+	req.armLimits = map[string]ArmSpeedLimits{}
+	for name := range componentsMoved(trajectory) {
+		limits, ok := ms.conf.ArmSpeedLimits[name]
+		if _, isArm := components[name].(arm.Arm); ok && isArm {
+			req.armLimits[name] = limits
+		} else {
+			req.unscalable = append(req.unscalable, name)
+		}
+	}
+	sort.Strings(req.unscalable)
+	return preparedExecution{trajectory: trajectory, components: components, filters: filters, epsilon: epsilon, req: req}
+}
+
+// executeManaged executes a prepared trajectory once the execution manager has granted it the components it
+// moves. It must be called without holding ms.mu, so that a paused or slowed execution does not hold up
+// reconfiguration. Reconfiguring stops the execution instead.
+func (ms *builtIn) executeManaged(ctx context.Context, execution preparedExecution) error {
+	execCtx, ctrl, preempted, finish, err := ms.executions.begin(ctx, execution.trajectory, execution.req)
+	if err != nil {
+		return err
+	}
+	defer finish()
+	epsilon := execution.epsilon
+	if preempted && execution.req.preemptedEpsilon > 0 && execution.req.preemptedEpsilon < epsilon {
+		epsilon = execution.req.preemptedEpsilon
+	}
+	return executionError(execCtx, execute(execCtx, execution.components, execution.trajectory, epsilon, execution.filters, ctrl))
+}
+
+// execute moves components through the trajectory. The positions commanded to components with a filter in
+// filters are shaped by it first. If ctrl is non-nil, the execution can be paused, resumed, and scaled through
+// it while in progress.
+func execute(
+	ctx context.Context,
+	components map[string]resource.Resource,
+	trajectory motionplan.Trajectory,
+	epsilon float64,
+	filters map[string]*TrajectoryFilterConfig,
+	ctrl *executionControl,
+) error {
 	// Batch GoToInputs calls if possible; components may want to blend between inputs
 	combinedSteps := []map[string][][]referenceframe.Input{}
 	currStep := map[string][][]referenceframe.Input{}
@@ -618,7 +882,7 @@ func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory
 					continue
 				}
 
-				r, ok := ms.components[name]
+				r, ok := components[name]
 				if !ok {
 					return fmt.Errorf("plan had step for resource %s but the motion service is not aware of a component of that name", name)
 				}
@@ -631,8 +895,7 @@ func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory
 					return err
 				}
 				if referenceframe.InputsLinfDistance(curr, inputs) > epsilon {
-					return fmt.Errorf("component %v is not within %v of the current position. Expected inputs %v current inputs %v",
-						name, epsilon, inputs, curr)
+					return &notAtStartError{name: name, epsilon: epsilon, expected: inputs, current: curr}
 				}
 				currStep[name] = append(currStep[name], inputs)
 			}
@@ -682,12 +945,22 @@ func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory
 	}
 	combinedSteps = append(combinedSteps, currStep)
 
+	// where each filtered component was last commanded to, which its next batch is shaped from
+	last := map[string][]referenceframe.Input{}
 	for _, step := range combinedSteps {
 		for name, inputs := range step {
 			if len(inputs) == 0 {
 				continue
 			}
-			r, ok := ms.components[name]
+			if filter, ok := filters[name]; ok {
+				from, ok := last[name]
+				if !ok {
+					from = inputs[0]
+				}
+				last[name] = inputs[len(inputs)-1]
+				inputs = filter.shape(from, inputs)
+			}
+			r, ok := components[name]
 			if !ok {
 				return fmt.Errorf("plan had step for resource %s but it was not found in the motion", name)
 			}
@@ -695,7 +968,12 @@ func (ms *builtIn) execute(ctx context.Context, trajectory motionplan.Trajectory
 			if err != nil {
 				return err
 			}
-			if err := ie.GoToInputs(ctx, inputs...); err != nil {
+			if ctrl != nil {
+				err = goToInputsControlled(ctx, ctrl, name, r, ie, inputs)
+			} else {
+				err = ie.GoToInputs(ctx, inputs...)
+			}
+			if err != nil {
 				// If there is an error on GoToInputs, stop the component if possible before returning the error
 				if actuator, ok := r.(inputEnabledActuator); ok {
 					//nolint:errcheck
@@ -0,0 +1,242 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/robot/framesystem"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/utils"
+)
+
+// executionControl lets an operator pause, resume, and scale the speed of an in-progress plan execution.
+// Changes take effect by interrupting the component's current move and continuing through the remaining
+// waypoints of the existing plan from wherever the component stopped, so nothing is replanned.
+//
+// Only arms with configured speed limits can be slowed down, by scaling those limits. Other components have
+// no way to be commanded at a lower speed, so the speed of executions moving them cannot be scaled.
+type executionControl struct {
+	// armLimits holds the speed limits of the arms moved by the execution that can be slowed down.
+	armLimits map[string]ArmSpeedLimits
+	// unscalable lists the components moved by the execution that cannot be slowed down.
+	unscalable []string
+
+	mu     sync.Mutex
+	paused bool
+	scale  float64
+	// changed is closed and replaced whenever paused or scale changes.
+	changed chan struct{}
+}
+
+func newExecutionControl(req executionRequest) (*executionControl, error) {
+	c := &executionControl{
+		armLimits:  req.armLimits,
+		unscalable: req.unscalable,
+		scale:      1,
+		changed:    make(chan struct{}),
+	}
+	if req.speedScale != 0 {
+		if err := c.setScale(req.speedScale); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func validateSpeedScale(scale float64) error {
+	if scale <= 0 || scale > 1 {
+		return fmt.Errorf("speed scale must be in (0, 1] but got %v", scale)
+	}
+	return nil
+}
+
+func (c *executionControl) state() (bool, float64, <-chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused, c.scale, c.changed
+}
+
+func (c *executionControl) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *executionControl) setPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused != paused {
+		c.paused = paused
+		c.notifyLocked()
+	}
+}
+
+// checkScale returns an error if the execution cannot be run at the given speed scale.
+func (c *executionControl) checkScale(scale float64) error {
+	if err := validateSpeedScale(scale); err != nil {
+		return err
+	}
+	if scale < 1 && len(c.unscalable) > 0 {
+		return fmt.Errorf("cannot scale the speed of %v: only arms with configured arm_speed_limits can be slowed down",
+			c.unscalable)
+	}
+	return nil
+}
+
+func (c *executionControl) setScale(scale float64) error {
+	if err := c.checkScale(scale); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.scale != scale {
+		c.scale = scale
+		c.notifyLocked()
+	}
+	return nil
+}
+
+// goToInputsControlled moves the named component through waypoints, honoring pause and speed scale changes
+// made through ctrl while the move is in progress.
+func goToInputsControlled(
+	ctx context.Context,
+	ctrl *executionControl,
+	name string,
+	r interface{},
+	ie framesystem.InputEnabled,
+	waypoints [][]referenceframe.Input,
+) error {
+	remaining := waypoints
+	for len(remaining) > 0 {
+		paused, scale, changed := ctrl.state()
+		if paused {
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		moveCtx, cancel := context.WithCancel(ctx)
+		errCh := make(chan error, 1)
+		toMove := remaining
+		goutils.PanicCapturingGo(func() {
+			errCh <- ctrl.moveThroughScaled(moveCtx, name, r, ie, toMove, scale)
+		})
+		select {
+		case err := <-errCh:
+			cancel()
+			return err
+		case <-changed:
+			cancel()
+			<-errCh
+		}
+
+		// the move was interrupted; stop where we are and work out how far along the waypoints we got
+		if actuator, ok := r.(inputEnabledActuator); ok {
+			if err := actuator.Stop(ctx, nil); err != nil {
+				return err
+			}
+		}
+		curr, err := ie.CurrentInputs(ctx)
+		if err != nil {
+			return err
+		}
+		remaining = remaining[resumeIndex(remaining, curr):]
+	}
+	return nil
+}
+
+// moveThroughScaled moves through waypoints at the given fraction of full speed by lowering the joint velocity
+// and acceleration limits of an arm. Components that cannot be slowed down always move at full speed, which
+// setScale ensures is the only speed requested of them.
+func (c *executionControl) moveThroughScaled(
+	ctx context.Context,
+	name string,
+	r interface{},
+	ie framesystem.InputEnabled,
+	waypoints [][]referenceframe.Input,
+	scale float64,
+) error {
+	limits, ok := c.armLimits[name]
+	a, isArm := r.(arm.Arm)
+	if scale >= 1 || !ok || !isArm {
+		return ie.GoToInputs(ctx, waypoints...)
+	}
+	// scaling time by 1/scale scales velocity by scale and acceleration by scale squared, which never
+	// exceeds the arm's limits since scale is at most 1
+	return a.MoveThroughJointPositions(ctx, waypoints, &arm.MoveOptions{
+		MaxVelRads: utils.DegToRad(limits.MaxVelDegsPerSec * scale),
+		MaxAccRads: utils.DegToRad(limits.MaxAccDegsPerSec2 * scale * scale),
+	}, nil)
+}
+
+// resumeIndex returns the index of the first waypoint that a component at curr has not yet passed.
+func resumeIndex(waypoints [][]referenceframe.Input, curr []referenceframe.Input) int {
+	nearest := 0
+	nearestDist := referenceframe.InputsL2Distance(waypoints[0], curr)
+	for i := 1; i < len(waypoints); i++ {
+		if d := referenceframe.InputsL2Distance(waypoints[i], curr); d < nearestDist {
+			nearest, nearestDist = i, d
+		}
+	}
+	// if we are closer to the next waypoint than the nearest one is, we have already passed the nearest one
+	if nearest+1 < len(waypoints) &&
+		referenceframe.InputsL2Distance(curr, waypoints[nearest+1]) <
+			referenceframe.InputsL2Distance(waypoints[nearest], waypoints[nearest+1]) {
+		return nearest + 1
+	}
+	return nearest
+}
+
+// handleExecutionControlCommand services motion.DoPauseExecution, motion.DoResumeExecution, and motion.DoScaleExecution.
+func (ms *builtIn) handleExecutionControlCommand(cmd map[string]interface{}) (map[string]interface{}, bool, error) {
+	_, pause := cmd[motion.DoPauseExecution]
+	_, resume := cmd[motion.DoResumeExecution]
+	rawScale, scale := cmd[motion.DoScaleExecution]
+	if !pause && !resume && !scale {
+		return nil, false, nil
+	}
+	if pause && resume {
+		return nil, true, fmt.Errorf("cannot both %s and %s", motion.DoPauseExecution, motion.DoResumeExecution)
+	}
+
+	id, _ := cmd[motion.DoExecutionID].(string)
+	controls, err := ms.executions.controls(id)
+	if err != nil {
+		return nil, true, err
+	}
+	resp := map[string]interface{}{}
+	if scale {
+		s, err := utils.AssertType[float64](rawScale)
+		if err != nil {
+			return nil, true, err
+		}
+		for _, c := range controls {
+			if err := c.checkScale(s); err != nil {
+				return nil, true, err
+			}
+		}
+		for _, c := range controls {
+			//nolint:errcheck // the scale was checked above
+			_ = c.setScale(s)
+		}
+		resp[motion.DoScaleExecution] = true
+	}
+	if pause || resume {
+		for _, c := range controls {
+			c.setPaused(pause)
+		}
+		if pause {
+			resp[motion.DoPauseExecution] = true
+		} else {
+			resp[motion.DoResumeExecution] = true
+		}
+	}
+	return resp, true, nil
+}
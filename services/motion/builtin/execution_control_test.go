@@ -0,0 +1,164 @@
+package builtin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+// steppingComponent moves one unit of its single input per tick toward each waypoint in turn.
+type steppingComponent struct {
+	mu   sync.Mutex
+	pos  float64
+	tick time.Duration
+}
+
+func (s *steppingComponent) Kinematics(ctx context.Context) (referenceframe.Model, error) {
+	return nil, nil
+}
+
+func (s *steppingComponent) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return []referenceframe.Input{s.pos}, nil
+}
+
+func (s *steppingComponent) GoToInputs(ctx context.Context, waypoints ...[]referenceframe.Input) error {
+	for _, wp := range waypoints {
+		for {
+			s.mu.Lock()
+			switch {
+			case s.pos < wp[0]:
+				s.pos++
+			case s.pos > wp[0]:
+				s.pos--
+			}
+			done := s.pos == wp[0]
+			s.mu.Unlock()
+			if done {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.tick):
+			}
+		}
+	}
+	return nil
+}
+
+func (s *steppingComponent) position() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pos
+}
+
+func TestExecutionControl(t *testing.T) {
+	ctx := context.Background()
+	waypoints := [][]referenceframe.Input{{10}, {20}, {30}}
+
+	t.Run("pause and resume without replanning", func(t *testing.T) {
+		comp := &steppingComponent{tick: 5 * time.Millisecond}
+		ctrl, err := newExecutionControl(executionRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- goToInputsControlled(ctx, ctrl, "comp", comp, comp, waypoints)
+		}()
+
+		testutils.WaitForAssertion(t, func(tb testing.TB) {
+			test.That(tb, comp.position(), test.ShouldBeGreaterThan, 12)
+		})
+		ctrl.setPaused(true)
+		time.Sleep(20 * time.Millisecond)
+		paused := comp.position()
+		time.Sleep(50 * time.Millisecond)
+		test.That(t, comp.position(), test.ShouldEqual, paused)
+		test.That(t, paused, test.ShouldBeLessThan, 30)
+
+		ctrl.setPaused(false)
+		test.That(t, <-errCh, test.ShouldBeNil)
+		test.That(t, comp.position(), test.ShouldEqual, 30)
+	})
+
+	t.Run("arms are slowed down by scaling their configured limits", func(t *testing.T) {
+		injectArm := inject.NewArm("arm1")
+		var options []*arm.MoveOptions
+		injectArm.MoveThroughJointPositionsFunc = func(
+			ctx context.Context, positions [][]referenceframe.Input, opts *arm.MoveOptions, extra map[string]any,
+		) error {
+			options = append(options, opts)
+			return nil
+		}
+		ctrl, err := newExecutionControl(executionRequest{
+			speedScale: 0.5,
+			armLimits:  map[string]ArmSpeedLimits{"arm1": {MaxVelDegsPerSec: 100, MaxAccDegsPerSec2: 400}},
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, goToInputsControlled(ctx, ctrl, "arm1", injectArm, injectArm, waypoints), test.ShouldBeNil)
+		test.That(t, len(options), test.ShouldEqual, 1)
+		test.That(t, options[0].MaxVelRads, test.ShouldAlmostEqual, utils.DegToRad(50))
+		test.That(t, options[0].MaxAccRads, test.ShouldAlmostEqual, utils.DegToRad(100))
+
+		test.That(t, ctrl.setScale(0), test.ShouldNotBeNil)
+		test.That(t, ctrl.setScale(1.5), test.ShouldNotBeNil)
+	})
+
+	t.Run("components that cannot be slowed down reject speed scales", func(t *testing.T) {
+		_, err := newExecutionControl(executionRequest{speedScale: 0.5, unscalable: []string{"gantry1"}})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "gantry1")
+
+		ctrl, err := newExecutionControl(executionRequest{speedScale: 1, unscalable: []string{"gantry1"}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, ctrl.setScale(0.5), test.ShouldNotBeNil)
+		test.That(t, ctrl.setScale(1), test.ShouldBeNil)
+	})
+
+	t.Run("resume from the first waypoint not yet passed", func(t *testing.T) {
+		test.That(t, resumeIndex(waypoints, []referenceframe.Input{0}), test.ShouldEqual, 0)
+		test.That(t, resumeIndex(waypoints, []referenceframe.Input{12}), test.ShouldEqual, 1)
+		test.That(t, resumeIndex(waypoints, []referenceframe.Input{18}), test.ShouldEqual, 1)
+		test.That(t, resumeIndex(waypoints, []referenceframe.Input{30}), test.ShouldEqual, 2)
+	})
+
+	t.Run("control executions through DoCommand", func(t *testing.T) {
+		ms := &builtIn{conf: &Config{}, executions: newExecutionManager()}
+		_, ctrl, _, finish, err := ms.executions.begin(ctx, trajectoryMoving([]string{"arm1"}), executionRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		defer finish()
+
+		resp, handled, err := ms.handleExecutionControlCommand(map[string]interface{}{motion.DoPauseExecution: true})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, handled, test.ShouldBeTrue)
+		test.That(t, resp[motion.DoPauseExecution], test.ShouldEqual, true)
+		paused, _, _ := ctrl.state()
+		test.That(t, paused, test.ShouldBeTrue)
+
+		id := ms.executions.list()[0].(map[string]interface{})["id"]
+		_, _, err = ms.handleExecutionControlCommand(map[string]interface{}{
+			motion.DoResumeExecution: true, motion.DoScaleExecution: 0.5, motion.DoExecutionID: id,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		paused, scale, _ := ctrl.state()
+		test.That(t, paused, test.ShouldBeFalse)
+		test.That(t, scale, test.ShouldEqual, 0.5)
+
+		_, _, err = ms.handleExecutionControlCommand(map[string]interface{}{motion.DoPauseExecution: true, motion.DoExecutionID: "nope"})
+		test.That(t, err, test.ShouldNotBeNil)
+
+		_, handled, _ = ms.handleExecutionControlCommand(map[string]interface{}{DoListExecutions: true})
+		test.That(t, handled, test.ShouldBeFalse)
+	})
+}
@@ -0,0 +1,349 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+)
+
+// Keys that may be set in the extra of a Move request, or alongside DoExecute, to control how its execution
+// interacts with executions already in progress.
+const (
+	// ExtraPriority is a number; executions with a higher priority preempt executions with a lower one.
+	ExtraPriority = "priority"
+	// ExtraPreemption selects what happens when the execution needs components that are in use; see the
+	// Preemption constants.
+	ExtraPreemption = "preemption"
+	// ExtraSpeedScale is a number in (0, 1] that the execution speed starts out scaled by.
+	ExtraSpeedScale = "speed_scale"
+)
+
+// Preemption rules for an execution that needs components already used by another execution.
+const (
+	// PreemptionPreempt cancels in-progress executions of equal or lower priority that use any of the same
+	// components, and fails if one of them has a higher priority. This is the default.
+	PreemptionPreempt = "preempt"
+	// PreemptionReject fails if any in-progress execution uses any of the same components.
+	PreemptionReject = "reject"
+)
+
+// errPreempted is the cancellation cause of an execution that was preempted by another one.
+var errPreempted = errors.New("plan execution was preempted by a higher or equal priority request")
+
+// errExecutionsReset is the cancellation cause of executions stopped by reconfiguring or closing the service.
+var errExecutionsReset = errors.New("plan execution was canceled because the motion service was reconfigured or closed")
+
+// executionRequest describes how a new execution should be scheduled relative to running ones.
+type executionRequest struct {
+	priority   int
+	preemption string
+	speedScale float64
+	// preemptedEpsilon, if positive, is the furthest the components may be from the start of the
+	// trajectory when the execution preempted another one on them, which may have kept them moving
+	// after the trajectory was planned.
+	preemptedEpsilon float64
+
+	// The fields below are filled in from the motion service's state when the execution is prepared.
+
+	// generation is the executionManager generation the execution was prepared in.
+	generation uint64
+	// armLimits holds the speed limits of the arms moved by the execution that can be slowed down.
+	armLimits map[string]ArmSpeedLimits
+	// unscalable lists the components moved by the execution that cannot be slowed down.
+	unscalable []string
+}
+
+// executionRequestFromExtra reads the priority, preemption rule, and speed scale from a request's extra.
+func executionRequestFromExtra(extra map[string]interface{}) (executionRequest, error) {
+	req := executionRequest{preemption: PreemptionPreempt, speedScale: 1}
+	if raw, ok := extra[ExtraSpeedScale]; ok && raw != nil {
+		scale, ok := raw.(float64)
+		if !ok {
+			return executionRequest{}, fmt.Errorf("expected %q to be a number but got %T", ExtraSpeedScale, raw)
+		}
+		if err := validateSpeedScale(scale); err != nil {
+			return executionRequest{}, err
+		}
+		req.speedScale = scale
+	}
+	if raw, ok := extra[ExtraPriority]; ok && raw != nil {
+		switch v := raw.(type) {
+		case float64:
+			req.priority = int(v)
+		case int:
+			req.priority = v
+		default:
+			return executionRequest{}, fmt.Errorf("expected %q to be a number but got %T", ExtraPriority, raw)
+		}
+	}
+	if raw, ok := extra[ExtraPreemption]; ok && raw != nil {
+		rule, ok := raw.(string)
+		if !ok {
+			return executionRequest{}, fmt.Errorf("expected %q to be a string but got %T", ExtraPreemption, raw)
+		}
+		switch rule {
+		case PreemptionPreempt, PreemptionReject:
+		default:
+			return executionRequest{}, fmt.Errorf("unknown %s rule %q", ExtraPreemption, rule)
+		}
+		req.preemption = rule
+	}
+	return req, nil
+}
+
+// activeExecution is a plan execution currently moving a set of components.
+type activeExecution struct {
+	id         uuid.UUID
+	components map[string]struct{}
+	priority   int
+	started    time.Time
+	control    *executionControl
+	cancel     context.CancelCauseFunc
+	done       chan struct{}
+}
+
+func (ae *activeExecution) conflicts(components map[string]struct{}) bool {
+	for name := range components {
+		if _, ok := ae.components[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// executionManager lets plans that move disjoint sets of components execute at the same time, while
+// arbitrating between plans that need the same components according to their priorities.
+type executionManager struct {
+	mu     sync.Mutex
+	active map[uuid.UUID]*activeExecution
+	// generation is incremented by stopAll so that executions prepared against the previous
+	// configuration of the motion service are not started.
+	generation uint64
+}
+
+func newExecutionManager() *executionManager {
+	return &executionManager{active: map[uuid.UUID]*activeExecution{}}
+}
+
+// begin claims the components moved by trajectory, preempting conflicting executions if allowed.
+// It returns a context to execute under, which is canceled if the execution is itself preempted,
+// the control used to pause and scale the execution, whether it preempted other executions, and a
+// function that must be called once execution finishes to release the components.
+func (em *executionManager) begin(
+	ctx context.Context,
+	trajectory motionplan.Trajectory,
+	req executionRequest,
+) (context.Context, *executionControl, bool, func(), error) {
+	components := componentsMoved(trajectory)
+	control, err := newExecutionControl(req)
+	if err != nil {
+		return nil, nil, false, nil, err
+	}
+
+	em.mu.Lock()
+	if req.generation != em.generation {
+		em.mu.Unlock()
+		return nil, nil, false, nil, errExecutionsReset
+	}
+	var preempted []*activeExecution
+	for _, ae := range em.active {
+		if !ae.conflicts(components) {
+			continue
+		}
+		if req.preemption == PreemptionReject || ae.priority > req.priority {
+			em.mu.Unlock()
+			return nil, nil, false, nil, fmt.Errorf(
+				"components %v are in use by plan execution %s with priority %d",
+				sortedNames(ae.components), ae.id, ae.priority,
+			)
+		}
+		preempted = append(preempted, ae)
+	}
+	for _, ae := range preempted {
+		ae.cancel(errPreempted)
+		delete(em.active, ae.id)
+	}
+
+	execCtx, cancel := context.WithCancelCause(ctx)
+	ae := &activeExecution{
+		id:         uuid.New(),
+		components: components,
+		priority:   req.priority,
+		started:    time.Now(),
+		control:    control,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	em.active[ae.id] = ae
+	em.mu.Unlock()
+
+	finish := func() {
+		em.mu.Lock()
+		if em.active[ae.id] == ae {
+			delete(em.active, ae.id)
+		}
+		em.mu.Unlock()
+		cancel(nil)
+		close(ae.done)
+	}
+
+	// preempted executions must have stopped commanding their components before this one starts
+	for _, p := range preempted {
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			finish()
+			return nil, nil, false, nil, ctx.Err()
+		}
+	}
+	return execCtx, ae.control, len(preempted) != 0, finish, nil
+}
+
+// currentGeneration returns the generation that executions prepared now must be begun in.
+func (em *executionManager) currentGeneration() uint64 {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	return em.generation
+}
+
+// stopAll cancels every in-progress execution and waits for them to stop commanding their components.
+// Executions prepared before the call are refused by begin.
+func (em *executionManager) stopAll() {
+	em.mu.Lock()
+	em.generation++
+	stopped := make([]*activeExecution, 0, len(em.active))
+	for id, ae := range em.active {
+		ae.cancel(errExecutionsReset)
+		delete(em.active, id)
+		stopped = append(stopped, ae)
+	}
+	em.mu.Unlock()
+	for _, ae := range stopped {
+		<-ae.done
+	}
+}
+
+// controls returns the controls of the execution with the given id, or of all executions if id is empty.
+func (em *executionManager) controls(id string) ([]*executionControl, error) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	if id == "" {
+		controls := make([]*executionControl, 0, len(em.active))
+		for _, ae := range em.active {
+			controls = append(controls, ae.control)
+		}
+		return controls, nil
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+	ae, ok := em.active[parsed]
+	if !ok {
+		return nil, fmt.Errorf("no plan execution with id %s is in progress", id)
+	}
+	return []*executionControl{ae.control}, nil
+}
+
+// list returns a description of every in-progress execution, oldest first.
+func (em *executionManager) list() []interface{} {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	active := make([]*activeExecution, 0, len(em.active))
+	for _, ae := range em.active {
+		active = append(active, ae)
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].started.Before(active[j].started) })
+
+	out := make([]interface{}, 0, len(active))
+	for _, ae := range active {
+		components := []interface{}{}
+		for _, name := range sortedNames(ae.components) {
+			components = append(components, name)
+		}
+		paused, scale, _ := ae.control.state()
+		out = append(out, map[string]interface{}{
+			"id":          ae.id.String(),
+			"components":  components,
+			"priority":    float64(ae.priority),
+			"started":     ae.started.Format(time.RFC3339Nano),
+			"paused":      paused,
+			"speed_scale": scale,
+		})
+	}
+	return out
+}
+
+// executionError reports why an execution was canceled by the execution manager rather than a bare context
+// cancellation.
+func executionError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if cause := context.Cause(ctx); errors.Is(cause, errPreempted) || errors.Is(cause, errExecutionsReset) {
+		return cause
+	}
+	return err
+}
+
+// componentsMoved returns the components whose inputs change over the trajectory. Plans include inputs for
+// every frame in the frame system, so components that merely hold still are not considered in use.
+func componentsMoved(trajectory motionplan.Trajectory) map[string]struct{} {
+	components := map[string]struct{}{}
+	if len(trajectory) == 0 {
+		return components
+	}
+	first := trajectory[0]
+	for _, step := range trajectory {
+		for name, inputs := range step {
+			if len(inputs) == 0 {
+				continue
+			}
+			start, ok := first[name]
+			if !ok || len(start) != len(inputs) {
+				components[name] = struct{}{}
+				continue
+			}
+			for i := range inputs {
+				if inputs[i] != start[i] {
+					components[name] = struct{}{}
+					break
+				}
+			}
+		}
+	}
+	return components
+}
+
+// onlyComponentsMoved returns the trajectory restricted to the components returned by componentsMoved.
+func onlyComponentsMoved(trajectory motionplan.Trajectory) motionplan.Trajectory {
+	moved := componentsMoved(trajectory)
+	out := make(motionplan.Trajectory, 0, len(trajectory))
+	for _, step := range trajectory {
+		filtered := make(referenceframe.FrameSystemInputs, len(moved))
+		for name := range moved {
+			if inputs, ok := step[name]; ok {
+				filtered[name] = inputs
+			}
+		}
+		out = append(out, filtered)
+	}
+	return out
+}
+
+func sortedNames(set map[string]struct{}) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,165 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func trajectoryMoving(moving []string, still ...string) motionplan.Trajectory {
+	start := referenceframe.FrameSystemInputs{}
+	end := referenceframe.FrameSystemInputs{}
+	for _, name := range moving {
+		start[name] = []referenceframe.Input{0}
+		end[name] = []referenceframe.Input{1}
+	}
+	for _, name := range still {
+		start[name] = []referenceframe.Input{0}
+		end[name] = []referenceframe.Input{0}
+	}
+	return motionplan.Trajectory{start, end}
+}
+
+func TestExecutionManager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stationary components are not claimed", func(t *testing.T) {
+		trajectory := trajectoryMoving([]string{"arm1"}, "arm2")
+		moved := componentsMoved(trajectory)
+		test.That(t, sortedNames(moved), test.ShouldResemble, []string{"arm1"})
+
+		// nor commanded, since they may be moving under another execution
+		filtered := onlyComponentsMoved(trajectory)
+		test.That(t, len(filtered), test.ShouldEqual, len(trajectory))
+		for _, step := range filtered {
+			test.That(t, len(step), test.ShouldEqual, 1)
+			test.That(t, step["arm1"], test.ShouldNotBeNil)
+		}
+	})
+
+	t.Run("disjoint executions run concurrently", func(t *testing.T) {
+		em := newExecutionManager()
+		ctx1, _, _, finish1, err := em.begin(ctx, trajectoryMoving([]string{"arm1"}, "arm2"), executionRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		ctx2, _, preempted, finish2, err := em.begin(ctx, trajectoryMoving([]string{"arm2"}, "arm1"), executionRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, preempted, test.ShouldBeFalse)
+		test.That(t, len(em.list()), test.ShouldEqual, 2)
+		test.That(t, ctx1.Err(), test.ShouldBeNil)
+		test.That(t, ctx2.Err(), test.ShouldBeNil)
+		finish1()
+		finish2()
+		test.That(t, em.list(), test.ShouldBeEmpty)
+	})
+
+	t.Run("higher or equal priority preempts", func(t *testing.T) {
+		em := newExecutionManager()
+		ctx1, _, _, finish1, err := em.begin(ctx, trajectoryMoving([]string{"arm1"}), executionRequest{priority: 1})
+		test.That(t, err, test.ShouldBeNil)
+
+		// the preempted execution must finish before the new one is allowed to start
+		go func() {
+			<-ctx1.Done()
+			test.That(t, executionError(ctx1, ctx1.Err()), test.ShouldBeError, errPreempted)
+			finish1()
+		}()
+		_, _, preempted, finish2, err := em.begin(ctx, trajectoryMoving([]string{"arm1"}), executionRequest{priority: 1})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, preempted, test.ShouldBeTrue)
+		list := em.list()
+		test.That(t, len(list), test.ShouldEqual, 1)
+		test.That(t, list[0].(map[string]interface{})["priority"], test.ShouldEqual, 1.)
+		finish2()
+	})
+
+	t.Run("lower priority and reject rule fail", func(t *testing.T) {
+		em := newExecutionManager()
+		ctx1, _, _, finish1, err := em.begin(ctx, trajectoryMoving([]string{"base", "arm1"}), executionRequest{priority: 5})
+		test.That(t, err, test.ShouldBeNil)
+		defer finish1()
+
+		_, _, _, _, err = em.begin(ctx, trajectoryMoving([]string{"arm1"}), executionRequest{priority: 4})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "priority 5")
+
+		_, _, _, _, err = em.begin(ctx, trajectoryMoving([]string{"base"}), executionRequest{priority: 9, preemption: PreemptionReject})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, ctx1.Err(), test.ShouldBeNil)
+	})
+
+	t.Run("stopping all executions refuses ones prepared before", func(t *testing.T) {
+		em := newExecutionManager()
+		prepared := executionRequest{generation: em.currentGeneration()}
+		ctx1, _, _, finish1, err := em.begin(ctx, trajectoryMoving([]string{"arm1"}), prepared)
+		test.That(t, err, test.ShouldBeNil)
+		go func() {
+			<-ctx1.Done()
+			finish1()
+		}()
+		em.stopAll()
+		test.That(t, executionError(ctx1, ctx1.Err()), test.ShouldBeError, errExecutionsReset)
+		test.That(t, em.list(), test.ShouldBeEmpty)
+
+		_, _, _, _, err = em.begin(ctx, trajectoryMoving([]string{"arm2"}), prepared)
+		test.That(t, err, test.ShouldBeError, errExecutionsReset)
+		_, _, _, finish2, err := em.begin(ctx, trajectoryMoving([]string{"arm2"}), executionRequest{generation: em.currentGeneration()})
+		test.That(t, err, test.ShouldBeNil)
+		finish2()
+	})
+
+	t.Run("parse request from extra", func(t *testing.T) {
+		req, err := executionRequestFromExtra(nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, req, test.ShouldResemble, executionRequest{preemption: PreemptionPreempt, speedScale: 1})
+
+		req, err = executionRequestFromExtra(map[string]interface{}{ExtraPriority: 3., ExtraPreemption: PreemptionReject})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, req, test.ShouldResemble, executionRequest{priority: 3, preemption: PreemptionReject, speedScale: 1})
+
+		_, err = executionRequestFromExtra(map[string]interface{}{ExtraPreemption: "queue"})
+		test.That(t, err, test.ShouldNotBeNil)
+		_, err = executionRequestFromExtra(map[string]interface{}{ExtraPriority: "high"})
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+
+	t.Run("start is only checked after preempting", func(t *testing.T) {
+		// the arm has drifted from where the trajectory starts since it was planned.
+		a := inject.NewArm("arm1")
+		a.CurrentInputsFunc = func(ctx context.Context) ([]referenceframe.Input, error) {
+			return []referenceframe.Input{0.5}, nil
+		}
+		a.GoToInputsFunc = func(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+			return nil
+		}
+		ms := &builtIn{
+			conf:       &Config{},
+			logger:     logging.NewTestLogger(t),
+			executions: newExecutionManager(),
+			components: map[string]resource.Resource{"arm1": a},
+		}
+		req := executionRequest{preemption: PreemptionPreempt, speedScale: 1, preemptedEpsilon: defaultExecuteEpsilon}
+		execute := func() error {
+			return ms.executeManaged(ctx, ms.prepareExecution(trajectoryMoving([]string{"arm1"}), math.MaxFloat64, req))
+		}
+		test.That(t, execute(), test.ShouldBeNil)
+
+		// the preempted execution may have kept the arm moving.
+		ctx1, _, _, finish1, err := ms.executions.begin(ctx, trajectoryMoving([]string{"arm1"}), executionRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		go func() {
+			<-ctx1.Done()
+			finish1()
+		}()
+		var notAtStart *notAtStartError
+		test.That(t, errors.As(execute(), &notAtStart), test.ShouldBeTrue)
+	})
+}
@@ -0,0 +1,219 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/forcetorque"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/motionplan/armplanning"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// guardedLineConstraint keeps the end of the arm on the line of a guarded move, and its orientation
+// unchanged, so that contact is approached the way it was asked to be.
+var guardedLineConstraint = &motionplan.Constraints{
+	LinearConstraint: []motionplan.LinearConstraint{{LineToleranceMm: 1, OrientationToleranceDegs: 1}},
+}
+
+// handleGuardedMoveCommand makes the guarded move of cmd, if it has one.
+func (ms *builtIn) handleGuardedMoveCommand(
+	ctx context.Context,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	raw, ok := cmd[motion.DoGuardedMove]
+	if !ok {
+		return nil, false, nil
+	}
+	m, err := utils.AssertType[map[string]interface{}](raw)
+	if err != nil {
+		return nil, true, err
+	}
+	req, err := resource.DecodeDoCommand[motion.GuardedMoveReq](m)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "invalid %s command", motion.DoGuardedMove)
+	}
+	result, err := ms.guardedMove(ctx, req)
+	if err != nil {
+		return nil, true, err
+	}
+	encoded, err := resource.EncodeDoCommand(result)
+	if err != nil {
+		return nil, true, err
+	}
+	return map[string]interface{}{motion.DoGuardedMove: encoded}, true, nil
+}
+
+// guardedMove moves the end of the arm of req along a straight line while reading its force torque
+// sensor, stopping the arm as soon as the sensor reads contact.
+func (ms *builtIn) guardedMove(ctx context.Context, req motion.GuardedMoveReq) (motion.GuardedMoveResult, error) {
+	ms.mu.RLock()
+	armRes, armOK := ms.components[req.ComponentName]
+	sensorRes, sensorOK := ms.components[req.SensorName]
+	ms.mu.RUnlock()
+	if !armOK {
+		return motion.GuardedMoveResult{}, resource.DependencyNotFoundError(arm.Named(req.ComponentName))
+	}
+	if !sensorOK {
+		return motion.GuardedMoveResult{}, resource.DependencyNotFoundError(forcetorque.Named(req.SensorName))
+	}
+	a, ok := armRes.(arm.Arm)
+	if !ok {
+		return motion.GuardedMoveResult{}, fmt.Errorf("guarded moves can only move arms, but %q is a %T", req.ComponentName, armRes)
+	}
+	// sensors provided by modules may only be reachable through DoCommand.
+	sensor := forcetorque.FromResource(sensorRes)
+
+	if req.Tare {
+		if err := sensor.Tare(ctx, req.Extra); err != nil {
+			return motion.GuardedMoveResult{}, errors.Wrap(err, "failed to tare force torque sensor")
+		}
+	}
+	direction := req.Direction.Normalize()
+	start, err := a.EndPosition(ctx, nil)
+	if err != nil {
+		return motion.GuardedMoveResult{}, err
+	}
+	// the sensor is checked before moving, as it may already be in contact.
+	wrench, err := sensor.Wrench(ctx, req.Extra)
+	if err != nil {
+		return motion.GuardedMoveResult{}, err
+	}
+	result := motion.GuardedMoveResult{Contact: req.Contact(wrench), Wrench: wrench}
+	if !result.Contact {
+		if result, err = ms.moveUntilContact(ctx, a, sensor, req, start, direction); err != nil {
+			return result, err
+		}
+	}
+
+	if !result.Contact {
+		if req.RequireContact {
+			return result, fmt.Errorf("no contact was made within %vmm", req.DistanceMM)
+		}
+		return result, nil
+	}
+	if req.OnContact == motion.ContactRetract {
+		if err := ms.moveLine(ctx, a, direction.Mul(-req.RetractMM), req.SpeedMMPerSec, func(context.Context) error {
+			return nil
+		}); err != nil {
+			return result, errors.Wrap(err, "failed to retract from contact")
+		}
+	}
+	return result, nil
+}
+
+// moveUntilContact moves the end of the arm DistanceMM along direction from start, stopping it once
+// the sensor reads contact.
+func (ms *builtIn) moveUntilContact(
+	ctx context.Context,
+	a arm.Arm,
+	sensor forcetorque.ForceTorqueSensor,
+	req motion.GuardedMoveReq,
+	start spatialmath.Pose,
+	direction r3.Vector,
+) (motion.GuardedMoveResult, error) {
+	var result motion.GuardedMoveResult
+	interval := time.Duration(req.PollIntervalMS) * time.Millisecond
+	guard := func(moveCtx context.Context) error {
+		for goutils.SelectContextOrWait(moveCtx, interval) {
+			wrench, err := sensor.Wrench(moveCtx, req.Extra)
+			if err != nil {
+				if moveCtx.Err() != nil {
+					return nil
+				}
+				return errors.Wrap(err, "failed to read force torque sensor")
+			}
+			result.Wrench = wrench
+			if req.Contact(wrench) {
+				result.Contact = true
+				return errGuardTripped
+			}
+		}
+		return nil
+	}
+	err := ms.moveLine(ctx, a, direction.Mul(req.DistanceMM), req.SpeedMMPerSec, guard)
+	if err != nil && !errors.Is(err, errGuardTripped) {
+		return result, err
+	}
+
+	end, err := a.EndPosition(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	result.TravelMM = end.Point().Sub(start.Point()).Dot(direction)
+	if !result.Contact {
+		// the reading at the end of the line is reported when contact was never made.
+		if result.Wrench, err = sensor.Wrench(ctx, req.Extra); err != nil {
+			return result, err
+		}
+		result.Contact = req.Contact(result.Wrench)
+	}
+	return result, nil
+}
+
+var errGuardTripped = errors.New("guard tripped")
+
+// moveLine moves the end of the arm by offset, in the frame of its base, along a straight line at
+// speedMMPerSec. guard runs while the arm moves; if it returns an error the arm is stopped and the
+// error returned. guard must return once its context is done.
+func (ms *builtIn) moveLine(
+	ctx context.Context, a arm.Arm, offset r3.Vector, speedMMPerSec float64, guard func(context.Context) error,
+) error {
+	inputs, err := a.CurrentInputs(ctx)
+	if err != nil {
+		return err
+	}
+	model, err := a.Kinematics(ctx)
+	if err != nil {
+		return err
+	}
+	from, err := model.Transform(inputs)
+	if err != nil {
+		return err
+	}
+	dst := spatialmath.NewPose(from.Point().Add(offset), from.Orientation())
+	plan, err := armplanning.PlanFrameMotion(ctx, ms.logger, dst, model, inputs, guardedLineConstraint, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to plan a straight line")
+	}
+
+	moveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	speedMPerSec := speedMMPerSec / 1000
+	moveErr := make(chan error, 1)
+	goutils.PanicCapturingGo(func() {
+		moveErr <- a.MoveThroughJointPositions(moveCtx, plan, &arm.MoveOptions{MaxTCPSpeedMPerSec: &speedMPerSec}, nil)
+	})
+	guardErr := make(chan error, 1)
+	goutils.PanicCapturingGo(func() {
+		guardErr <- guard(moveCtx)
+	})
+
+	select {
+	case err := <-moveErr:
+		cancel()
+		<-guardErr
+		return err
+	case err := <-guardErr:
+		if err == nil {
+			// the guard only returns without an error once the move is over.
+			return <-moveErr
+		}
+		cancel()
+		stopErr := a.Stop(ctx, nil)
+		<-moveErr
+		if stopErr != nil {
+			return errors.Wrapf(stopErr, "failed to stop arm after %v", err)
+		}
+		return err
+	}
+}
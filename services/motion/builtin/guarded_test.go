@@ -0,0 +1,166 @@
+package builtin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/arm/sim"
+	"go.viam.com/rdk/components/forcetorque"
+	"go.viam.com/rdk/components/forcetorque/fake"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+)
+
+// wallSensor is a force torque sensor on the end of an arm that pushes back once the arm passes below
+// a wall.
+type wallSensor struct {
+	*fake.ForceTorqueSensor
+	arm   arm.Arm
+	wallZ float64
+}
+
+func (s *wallSensor) Wrench(ctx context.Context, extra map[string]interface{}) (forcetorque.Wrench, error) {
+	pose, err := s.arm.EndPosition(ctx, nil)
+	if err != nil {
+		return forcetorque.Wrench{}, err
+	}
+	var raw forcetorque.Wrench
+	if depth := s.wallZ - pose.Point().Z; depth > 0 {
+		raw.Force = r3.Vector{Z: 5 + depth}
+	}
+	s.SetWrench(raw)
+	return s.ForceTorqueSensor.Wrench(ctx, extra)
+}
+
+func newGuardedMoveTest(t *testing.T, wallBelowMM float64) (*builtIn, arm.Arm, r3.Vector) {
+	t.Helper()
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	a, err := sim.NewArm(ctx, nil, resource.Config{
+		Name:                "arm",
+		API:                 arm.API,
+		Model:               sim.Model,
+		ConvertedAttributes: &sim.Config{Model: "lite6", Speed: 2, SimulateTime: true},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { test.That(t, a.Close(context.Background()), test.ShouldBeNil) })
+	test.That(t, a.MoveToJointPositions(ctx, []float64{0, 0.3, 1.2, 0, 0.9, 0}, nil), test.ShouldBeNil)
+	start, err := a.EndPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	ft, err := fake.NewForceTorqueSensor(ctx, nil, resource.Config{
+		Name:                "ft",
+		API:                 forcetorque.API,
+		ConvertedAttributes: &fake.Config{},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	sensor := &wallSensor{ForceTorqueSensor: ft.(*fake.ForceTorqueSensor), arm: a, wallZ: start.Point().Z - wallBelowMM}
+
+	ms := &builtIn{
+		conf:       &Config{},
+		logger:     logger,
+		executions: newExecutionManager(),
+		components: map[string]resource.Resource{"arm": a, "ft": sensor},
+	}
+	return ms, a, start.Point()
+}
+
+func guardedMoveCmd(req map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{motion.DoGuardedMove: req}
+}
+
+func TestGuardedMove(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stop on contact", func(t *testing.T) {
+		ms, a, start := newGuardedMoveTest(t, 10)
+		res, err := motion.GuardedMove(ctx, ms, motion.GuardedMoveReq{
+			ComponentName: "arm",
+			SensorName:    "ft",
+			Direction:     r3.Vector{Z: -1},
+			DistanceMM:    40,
+			SpeedMMPerSec: 50,
+			MaxForceN:     5,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, res.Contact, test.ShouldBeTrue)
+		test.That(t, res.Wrench.Force.Z, test.ShouldBeGreaterThanOrEqualTo, 5)
+		// the arm stops at the wall rather than the end of the line.
+		test.That(t, res.TravelMM, test.ShouldBeBetween, 9, 30)
+		end, err := a.EndPosition(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, start.Z-end.Point().Z, test.ShouldAlmostEqual, res.TravelMM, 1)
+		moving, err := a.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeFalse)
+	})
+
+	t.Run("retract from contact", func(t *testing.T) {
+		ms, a, start := newGuardedMoveTest(t, 10)
+		res, err := motion.GuardedMove(ctx, ms, motion.GuardedMoveReq{
+			ComponentName: "arm",
+			SensorName:    "ft",
+			Direction:     r3.Vector{Z: -1},
+			DistanceMM:    40,
+			SpeedMMPerSec: 50,
+			MaxForceN:     5,
+			OnContact:     motion.ContactRetract,
+			RetractMM:     15,
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, res.Contact, test.ShouldBeTrue)
+		end, err := a.EndPosition(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, start.Z-end.Point().Z, test.ShouldAlmostEqual, res.TravelMM-15, 1)
+	})
+
+	t.Run("no contact", func(t *testing.T) {
+		ms, _, _ := newGuardedMoveTest(t, 100)
+		req := map[string]interface{}{
+			"component_name": "arm",
+			"sensor_name":    "ft",
+			"direction":      map[string]interface{}{"X": 0, "Y": 0, "Z": -1},
+			"distance_mm":    20,
+			"max_force_n":    5,
+		}
+		resp, err := ms.DoCommand(ctx, guardedMoveCmd(req))
+		test.That(t, err, test.ShouldBeNil)
+		res, err := resource.DecodeDoCommand[motion.GuardedMoveResult](resp[motion.DoGuardedMove].(map[string]interface{}))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, res.Contact, test.ShouldBeFalse)
+		test.That(t, res.TravelMM, test.ShouldAlmostEqual, 20, 1)
+
+		// pushing until contact fails without it.
+		req["require_contact"] = true
+		_, err = ms.DoCommand(ctx, guardedMoveCmd(req))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no contact")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		ms, _, _ := newGuardedMoveTest(t, 10)
+		_, err := ms.DoCommand(ctx, guardedMoveCmd(map[string]interface{}{
+			"component_name": "arm",
+			"sensor_name":    "ft",
+			"direction":      map[string]interface{}{"Z": -1},
+			"distance_mm":    20,
+		}))
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "max_force_n")
+
+		_, err = ms.DoCommand(ctx, guardedMoveCmd(map[string]interface{}{
+			"component_name": "gripper",
+			"sensor_name":    "ft",
+			"direction":      map[string]interface{}{"Z": -1},
+			"distance_mm":    20,
+			"max_force_n":    5,
+		}))
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
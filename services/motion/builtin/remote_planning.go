@@ -0,0 +1,100 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/motionplan/armplanning"
+	"go.viam.com/rdk/resource"
+)
+
+type planRequestCommand struct {
+	PlanRequest *armplanning.PlanRequest `json:"plan_request"`
+}
+
+func (c *planRequestCommand) Validate() error {
+	if c.PlanRequest == nil {
+		return errors.Errorf("%s must not be empty", DoPlanRequest)
+	}
+	return nil
+}
+
+type planRequestResponse struct {
+	Plan json.RawMessage `json:"plan_request"`
+}
+
+// handlePlanRequestCommand plans the plan request of cmd, if it has one.
+func (ms *builtIn) handlePlanRequestCommand(
+	ctx context.Context,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	if _, ok := cmd[DoPlanRequest]; !ok {
+		return nil, false, nil
+	}
+	req, err := resource.DecodeDoCommand[planRequestCommand](cmd)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "invalid %s command", DoPlanRequest)
+	}
+	plan, _, err := armplanning.PlanMotion(ctx, ms.logger, req.PlanRequest)
+	if err != nil {
+		return nil, true, err
+	}
+	data, err := motionplan.MarshalPlan(plan)
+	if err != nil {
+		return nil, true, err
+	}
+	resp, err := resource.EncodeDoCommand(planRequestResponse{Plan: data})
+	return resp, true, err
+}
+
+// planMotion plans req with the remote planner if one is configured, and locally otherwise or if the
+// remote planner fails and falling back is configured.
+func (ms *builtIn) planMotion(
+	ctx context.Context,
+	logger logging.Logger,
+	req *armplanning.PlanRequest,
+) (motionplan.Plan, *armplanning.PlanMeta, error) {
+	if ms.remotePlanner == nil {
+		return armplanning.PlanMotion(ctx, logger, req)
+	}
+
+	start := time.Now()
+	plan, err := ms.planRemotely(ctx, logger, req)
+	// The remote planner's meta data is not returned, so only the duration is known.
+	meta := &armplanning.PlanMeta{Duration: time.Since(start)}
+	if err == nil || !ms.conf.RemotePlannerFallback {
+		return plan, meta, err
+	}
+	logger.CWarnf(ctx, "planning locally instead: %v", err)
+	return armplanning.PlanMotion(ctx, logger, req)
+}
+
+// planRemotely plans req with the remote planner. The plan returned is checked against the frame
+// system and obstacles of req, as the remote planner may have planned from different kinematics or
+// been sent a plan other than its own.
+func (ms *builtIn) planRemotely(
+	ctx context.Context,
+	logger logging.Logger,
+	req *armplanning.PlanRequest,
+) (motionplan.Plan, error) {
+	resp, err := resource.DoCommandAs[planRequestCommand, planRequestResponse](
+		ctx, ms.remotePlanner, planRequestCommand{PlanRequest: req})
+	if err != nil {
+		return nil, errors.Wrapf(err, "remote planner %q failed", ms.conf.RemotePlanner)
+	}
+	plan, err := motionplan.UnmarshalPlan(resp.Plan)
+	if err != nil {
+		return nil, errors.Wrapf(err, "remote planner %q returned an invalid plan", ms.conf.RemotePlanner)
+	}
+	err = armplanning.CheckPlan(ctx, logger, req.FrameSystem, req.StartState.Configuration(), plan,
+		req.ObstaclesInWorldFrame, req.PlannerOptions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "plan from remote planner %q failed checks", ms.conf.RemotePlanner)
+	}
+	return plan, nil
+}
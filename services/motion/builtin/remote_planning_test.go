@@ -0,0 +1,104 @@
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/protoutils"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// wirePlanner passes commands to a motion service as if over the network, counting them, and may
+// replace the responses.
+type wirePlanner struct {
+	motion.Service
+	t        *testing.T
+	commands int
+	replace  map[string]interface{}
+}
+
+func (wp *wirePlanner) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	wp.commands++
+	cmdProto, err := protoutils.StructToStructPb(cmd)
+	test.That(wp.t, err, test.ShouldBeNil)
+	resp, err := wp.Service.DoCommand(ctx, cmdProto.AsMap())
+	if err != nil || wp.replace != nil {
+		return wp.replace, err
+	}
+	respProto, err := protoutils.StructToStructPb(resp)
+	test.That(wp.t, err, test.ShouldBeNil)
+	return respProto.AsMap(), nil
+}
+
+func TestRemotePlanning(t *testing.T) {
+	ctx := context.Background()
+	destination := referenceframe.NewPoseInFrame("c", spatialmath.NewPoseFromPoint(r3.Vector{X: 0, Y: -30, Z: -50}))
+
+	setup := func(t *testing.T, fallback bool) (*builtIn, *wirePlanner) {
+		t.Helper()
+		local, teardownLocal := setupMotionServiceFromConfig(t, "../data/moving_arm.json")
+		t.Cleanup(teardownLocal)
+		remote, teardownRemote := setupMotionServiceFromConfig(t, "../data/moving_arm.json")
+		t.Cleanup(teardownRemote)
+
+		ms := local.(*builtIn)
+		planner := &wirePlanner{Service: remote, t: t}
+		ms.remotePlanner = planner
+		ms.conf.RemotePlanner = "remote:builtin"
+		ms.conf.RemotePlannerFallback = fallback
+		return ms, planner
+	}
+
+	// A plan which starts elsewhere than the arm is.
+	wrongPlan := func(t *testing.T, ms *builtIn) map[string]interface{} {
+		t.Helper()
+		inputs, err := ms.fsService.CurrentInputs(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		moved := append([]referenceframe.Input{}, inputs["pieceArm"]...)
+		moved[0] += 0.5
+		data, err := motionplan.MarshalPlan(motionplan.NewSimplePlan(nil, motionplan.Trajectory{{"pieceArm": moved}}))
+		test.That(t, err, test.ShouldBeNil)
+		var plan map[string]interface{}
+		test.That(t, json.Unmarshal(data, &plan), test.ShouldBeNil)
+		return map[string]interface{}{DoPlanRequest: plan}
+	}
+
+	t.Run("plans with the remote planner", func(t *testing.T) {
+		ms, planner := setup(t, false)
+		_, err := ms.Move(ctx, motion.MoveReq{ComponentName: "pieceGripper", Destination: destination})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, planner.commands, test.ShouldEqual, 1)
+	})
+
+	t.Run("rejects plans which fail checks", func(t *testing.T) {
+		ms, planner := setup(t, false)
+		planner.replace = wrongPlan(t, ms)
+		_, err := ms.Move(ctx, motion.MoveReq{ComponentName: "pieceGripper", Destination: destination})
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "failed checks")
+	})
+
+	t.Run("falls back to planning locally", func(t *testing.T) {
+		ms, planner := setup(t, true)
+		planner.replace = wrongPlan(t, ms)
+		_, err := ms.Move(ctx, motion.MoveReq{ComponentName: "pieceGripper", Destination: destination})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, planner.commands, test.ShouldEqual, 1)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		deps, _, err := (&Config{RemotePlanner: "remote:builtin"}).Validate("")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, deps, test.ShouldContain, motion.Named("remote:builtin").String())
+
+		_, _, err = (&Config{RemotePlannerFallback: true}).Validate("")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,145 @@
+package builtin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+)
+
+const (
+	defaultFilterSamplePeriodMS = 20
+	// filterSettleTolerance is how close, in the units of a component's inputs, filtered positions
+	// must come to the end of a move before the last of them is snapped to it.
+	filterSettleTolerance = 1e-3
+	// maxFilterSamples bounds the positions a filter returns for one move, should it fail to settle.
+	maxFilterSamples = 100000
+)
+
+// TrajectoryFilterConfig holds the limits the positions commanded to a component are shaped to, in
+// the units of its inputs, such as radians for arm joints and mm for gantry axes. Each applies to
+// every input of the component.
+type TrajectoryFilterConfig struct {
+	MaxVel  float64 `json:"max_vel"`  // per second
+	MaxAcc  float64 `json:"max_acc"`  // per second squared
+	MaxJerk float64 `json:"max_jerk"` // per second cubed
+	// SamplePeriodMS is the time between the positions commanded, which defaults to 20ms.
+	SamplePeriodMS int `json:"sample_period_ms,omitempty"`
+}
+
+func (c *TrajectoryFilterConfig) validate(path string) error {
+	if c.MaxVel <= 0 || c.MaxAcc <= 0 || c.MaxJerk <= 0 {
+		return resource.NewConfigValidationError(path, errors.New("max_vel, max_acc and max_jerk must be positive"))
+	}
+	if c.SamplePeriodMS < 0 {
+		return resource.NewConfigValidationError(path, fmt.Errorf("sample_period_ms must not be negative, got %d", c.SamplePeriodMS))
+	}
+	return nil
+}
+
+func (c *TrajectoryFilterConfig) samplePeriod() float64 {
+	if c.SamplePeriodMS == 0 {
+		return defaultFilterSamplePeriodMS / 1000.
+	}
+	return float64(c.SamplePeriodMS) / 1000.
+}
+
+// shape returns the positions to command to a component moving from start through waypoints,
+// sampled every sample period from a jerkLimitedFilter tracking the waypoints at the velocity
+// limit. The filter's lag rounds the corners of the path between waypoints, but the last position
+// is exactly the last waypoint.
+func (c *TrajectoryFilterConfig) shape(start []referenceframe.Input, waypoints [][]referenceframe.Input) [][]referenceframe.Input {
+	if len(waypoints) == 0 {
+		return waypoints
+	}
+	f := newJerkLimitedFilter(c, start)
+	shaped := [][]referenceframe.Input{}
+	from := start
+	for _, to := range waypoints {
+		duration := referenceframe.InputsLinfDistance(from, to) / c.MaxVel
+		steps := int(math.Ceil(duration / f.dt))
+		ref := make([]referenceframe.Input, len(to))
+		for i := 1; i <= steps && len(shaped) < maxFilterSamples; i++ {
+			by := float64(i) / float64(steps)
+			for j := range ref {
+				ref[j] = from[j] + (to[j]-from[j])*by
+			}
+			shaped = append(shaped, f.step(ref))
+		}
+		from = to
+	}
+	for !f.settled(from) && len(shaped) < maxFilterSamples {
+		shaped = append(shaped, f.step(from))
+	}
+	if len(shaped) > 0 {
+		shaped = shaped[:len(shaped)-1]
+	}
+	return append(shaped, from)
+}
+
+// jerkLimitedFilter is an online low-pass filter of the positions commanded to a component. Each
+// step moves it one sample period toward a reference position, changing its acceleration by no more
+// than the jerk limit allows, keeping it within the acceleration limit, and slowing down in time to
+// stop at the reference without overshooting it.
+type jerkLimitedFilter struct {
+	conf *TrajectoryFilterConfig
+	dt   float64
+	// tauAcc is the time the filter takes to ramp up to full acceleration, or one sample period if
+	// that is longer.
+	tauAcc float64
+
+	ref, pos, vel, acc []float64
+}
+
+func newJerkLimitedFilter(conf *TrajectoryFilterConfig, start []referenceframe.Input) *jerkLimitedFilter {
+	dt := conf.samplePeriod()
+	return &jerkLimitedFilter{
+		conf:   conf,
+		dt:     dt,
+		tauAcc: math.Max(conf.MaxAcc/conf.MaxJerk, dt),
+		ref:    append([]float64{}, start...),
+		pos:    append([]float64{}, start...),
+		vel:    make([]float64, len(start)),
+		acc:    make([]float64, len(start)),
+	}
+}
+
+func clampMagnitude(x, limit float64) float64 {
+	return math.Max(-limit, math.Min(limit, x))
+}
+
+// step moves the filter one sample period toward ref and returns its new position.
+func (f *jerkLimitedFilter) step(ref []referenceframe.Input) []referenceframe.Input {
+	out := make([]referenceframe.Input, len(f.pos))
+	for i := range f.pos {
+		refVel := (ref[i] - f.ref[i]) / f.dt
+		e := ref[i] - f.pos[i]
+		// The velocity toward the reference is one from which the filter can still stop at it once its
+		// acceleration has ramped down, and falls off linearly close to it so that it settles rather
+		// than oscillating about it.
+		brake := math.Max(0, math.Abs(e)-math.Abs(f.vel[i]-refVel)*f.tauAcc)
+		correction := math.Copysign(math.Min(math.Sqrt(2*f.conf.MaxAcc*brake), math.Abs(e)/(4*f.tauAcc)), e)
+		velDes := clampMagnitude(refVel+correction, f.conf.MaxVel)
+		accDes := clampMagnitude((velDes-f.vel[i])/f.tauAcc, f.conf.MaxAcc)
+		jerk := clampMagnitude((accDes-f.acc[i])/f.dt, f.conf.MaxJerk)
+
+		f.acc[i] += jerk * f.dt
+		f.vel[i] += f.acc[i] * f.dt
+		f.pos[i] += f.vel[i] * f.dt
+		out[i] = f.pos[i]
+	}
+	copy(f.ref, ref)
+	return out
+}
+
+// settled returns whether the filter has come to rest within filterSettleTolerance of target.
+func (f *jerkLimitedFilter) settled(target []referenceframe.Input) bool {
+	for i := range f.pos {
+		if math.Abs(target[i]-f.pos[i]) > filterSettleTolerance || math.Abs(f.vel[i])*f.dt > filterSettleTolerance {
+			return false
+		}
+	}
+	return true
+}
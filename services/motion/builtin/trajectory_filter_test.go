@@ -0,0 +1,90 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestTrajectoryFilter(t *testing.T) {
+	conf := &TrajectoryFilterConfig{MaxVel: 1, MaxAcc: 2, MaxJerk: 10}
+	start := []referenceframe.Input{0, 0}
+	waypoints := [][]referenceframe.Input{{0.5, 0}, {0.5, 1}, {-0.25, 1}}
+
+	t.Run("shaped positions respect the limits", func(t *testing.T) {
+		shaped := conf.shape(start, waypoints)
+		test.That(t, shaped[len(shaped)-1], test.ShouldResemble, waypoints[len(waypoints)-1])
+
+		// The filter starts at rest, and the last position is snapped to the goal, so the derivatives
+		// are taken over the start repeated and every position but the last.
+		positions := append([][]referenceframe.Input{start, start, start}, shaped[:len(shaped)-1]...)
+		dt := conf.samplePeriod()
+		const tolerance = 1e-9
+		for i := 3; i < len(positions); i++ {
+			for j := range start {
+				p0, p1, p2, p3 := positions[i-3][j], positions[i-2][j], positions[i-1][j], positions[i][j]
+				vel := (p3 - p2) / dt
+				acc := (p3 - 2*p2 + p1) / (dt * dt)
+				jerk := (p3 - 3*p2 + 3*p1 - p0) / (dt * dt * dt)
+				test.That(t, math.Abs(vel), test.ShouldBeLessThanOrEqualTo, conf.MaxVel+tolerance)
+				test.That(t, math.Abs(acc), test.ShouldBeLessThanOrEqualTo, conf.MaxAcc+tolerance)
+				test.That(t, math.Abs(jerk), test.ShouldBeLessThanOrEqualTo, conf.MaxJerk+tolerance)
+			}
+		}
+
+		// A straight move does not overshoot its goal.
+		for _, pos := range conf.shape([]referenceframe.Input{0}, [][]referenceframe.Input{{1}}) {
+			test.That(t, pos[0], test.ShouldBeLessThanOrEqualTo, 1+filterSettleTolerance)
+		}
+	})
+
+	t.Run("execution commands shaped positions", func(t *testing.T) {
+		ctx := context.Background()
+		injectArm := inject.NewArm("arm1")
+		injectArm.CurrentInputsFunc = func(ctx context.Context) ([]referenceframe.Input, error) {
+			return start, nil
+		}
+		var commanded [][]referenceframe.Input
+		injectArm.GoToInputsFunc = func(ctx context.Context, inputSteps ...[]referenceframe.Input) error {
+			commanded = append(commanded, inputSteps...)
+			return nil
+		}
+		components := map[string]resource.Resource{"arm1": injectArm}
+		trajectory := motionplan.Trajectory{{"arm1": start}}
+		for _, wp := range waypoints {
+			trajectory = append(trajectory, referenceframe.FrameSystemInputs{"arm1": wp})
+		}
+
+		test.That(t, execute(ctx, components, trajectory, 1e-3, nil, nil), test.ShouldBeNil)
+		test.That(t, len(commanded), test.ShouldEqual, len(trajectory))
+
+		commanded = nil
+		filters := map[string]*TrajectoryFilterConfig{"arm1": conf}
+		test.That(t, execute(ctx, components, trajectory, 1e-3, filters, nil), test.ShouldBeNil)
+		test.That(t, len(commanded), test.ShouldBeGreaterThan, len(trajectory))
+		test.That(t, commanded[len(commanded)-1], test.ShouldResemble, waypoints[len(waypoints)-1])
+	})
+
+	t.Run("config", func(t *testing.T) {
+		_, _, err := (&Config{TrajectoryFilters: map[string]TrajectoryFilterConfig{"arm1": *conf}}).Validate("")
+		test.That(t, err, test.ShouldBeNil)
+
+		_, _, err = (&Config{TrajectoryFilters: map[string]TrajectoryFilterConfig{
+			"arm1": {MaxVel: 1, MaxAcc: 2},
+		}}).Validate("")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "trajectory_filters.arm1")
+
+		_, _, err = (&Config{TrajectoryFilters: map[string]TrajectoryFilterConfig{
+			"arm1": {MaxVel: 1, MaxAcc: 2, MaxJerk: 10, SamplePeriodMS: -1},
+		}}).Validate("")
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,144 @@
+package motion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/components/forcetorque"
+	"go.viam.com/rdk/resource"
+)
+
+// DoGuardedMove is the DoCommand key of a guarded move, whose value is a GuardedMoveReq and whose
+// response holds a GuardedMoveResult under the same key. Use GuardedMove to make one.
+const DoGuardedMove = "guarded_move"
+
+// A ContactAction is what a guarded move does once it makes contact.
+type ContactAction string
+
+// The set of known contact actions.
+const (
+	// ContactStop stops the arm where contact was made.
+	ContactStop = ContactAction("stop")
+	// ContactRetract stops the arm and then backs it away from the contact.
+	ContactRetract = ContactAction("retract")
+)
+
+const (
+	defaultGuardedSpeedMMPerSec  = 20.
+	defaultGuardedRetractMM      = 5.
+	defaultGuardedPollIntervalMS = 5
+)
+
+// A GuardedMoveReq moves the end of an arm in a straight line until a force torque sensor senses
+// contact or the end of the line is reached. It serves both for guarded approaches, which need not
+// make contact, and for pushing until contact, which must.
+type GuardedMoveReq struct {
+	// ComponentName is the name of the arm to move.
+	ComponentName string `json:"component_name"`
+	// SensorName is the name of the force torque sensor that senses contact.
+	SensorName string `json:"sensor_name"`
+
+	// Direction is the direction to move the end of the arm in, in the frame of the arm's base.
+	Direction r3.Vector `json:"direction"`
+	// DistanceMM is the farthest the end of the arm moves.
+	DistanceMM float64 `json:"distance_mm"`
+	// SpeedMMPerSec is how fast the end of the arm moves. SpeedMMPerSec defaults to 20 if unspecified.
+	SpeedMMPerSec float64 `json:"speed_mm_per_sec,omitempty"`
+
+	// MaxForceN and MaxTorqueNm are the magnitudes of force and torque beyond which contact is made.
+	// Either may be zero to not sense contact by it, but not both.
+	MaxForceN   float64 `json:"max_force_n,omitempty"`
+	MaxTorqueNm float64 `json:"max_torque_nm,omitempty"`
+	// Tare is whether to tare the sensor before moving, so that only forces from contact count.
+	Tare bool `json:"tare,omitempty"`
+	// PollIntervalMS is how often the sensor is read. PollIntervalMS defaults to 5 if unspecified.
+	PollIntervalMS int `json:"poll_interval_ms,omitempty"`
+
+	// OnContact is what to do once contact is made. OnContact defaults to ContactStop if unspecified.
+	OnContact ContactAction `json:"on_contact,omitempty"`
+	// RetractMM is how far the arm backs away from contact for ContactRetract. RetractMM defaults to 5
+	// if unspecified.
+	RetractMM float64 `json:"retract_mm,omitempty"`
+	// RequireContact is whether reaching the end of the line without making contact is an error.
+	RequireContact bool `json:"require_contact,omitempty"`
+
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Validate ensures the request is complete and fills in its defaults.
+func (req *GuardedMoveReq) Validate() error {
+	switch {
+	case req.ComponentName == "":
+		return errors.New("guarded move requires a component_name")
+	case req.SensorName == "":
+		return errors.New("guarded move requires a sensor_name")
+	case req.Direction.Norm() == 0:
+		return errors.New("guarded move direction cannot be zero")
+	case req.DistanceMM <= 0:
+		return fmt.Errorf("guarded move distance_mm must be positive but got %v", req.DistanceMM)
+	case req.SpeedMMPerSec < 0, req.RetractMM < 0, req.PollIntervalMS < 0:
+		return errors.New("guarded move speed_mm_per_sec, retract_mm and poll_interval_ms cannot be negative")
+	case req.MaxForceN < 0, req.MaxTorqueNm < 0:
+		return errors.New("guarded move thresholds cannot be negative")
+	case req.MaxForceN == 0 && req.MaxTorqueNm == 0:
+		return errors.New("guarded move requires a max_force_n or max_torque_nm to sense contact with")
+	}
+	switch req.OnContact {
+	case "":
+		req.OnContact = ContactStop
+	case ContactStop, ContactRetract:
+	default:
+		return fmt.Errorf("unknown guarded move contact action %q", req.OnContact)
+	}
+	if req.SpeedMMPerSec == 0 {
+		req.SpeedMMPerSec = defaultGuardedSpeedMMPerSec
+	}
+	if req.RetractMM == 0 {
+		req.RetractMM = defaultGuardedRetractMM
+	}
+	if req.PollIntervalMS == 0 {
+		req.PollIntervalMS = defaultGuardedPollIntervalMS
+	}
+	return nil
+}
+
+// Contact returns whether wrench exceeds the thresholds of the request.
+func (req *GuardedMoveReq) Contact(wrench forcetorque.Wrench) bool {
+	force, torque := wrench.Magnitudes()
+	return (req.MaxForceN > 0 && force >= req.MaxForceN) || (req.MaxTorqueNm > 0 && torque >= req.MaxTorqueNm)
+}
+
+// A GuardedMoveResult is the outcome of a guarded move.
+type GuardedMoveResult struct {
+	// Contact is whether contact was made.
+	Contact bool `json:"contact"`
+	// TravelMM is how far the end of the arm moved along the direction before it stopped, before any
+	// retraction.
+	TravelMM float64 `json:"travel_mm"`
+	// Wrench is the reading of the sensor when the arm stopped, which made contact if Contact is true.
+	Wrench forcetorque.Wrench `json:"wrench"`
+}
+
+type guardedMoveCommand struct {
+	GuardedMove GuardedMoveReq `json:"guarded_move"`
+}
+
+func (c *guardedMoveCommand) Validate() error {
+	return c.GuardedMove.Validate()
+}
+
+type guardedMoveResponse struct {
+	GuardedMove GuardedMoveResult `json:"guarded_move"`
+}
+
+// GuardedMove makes a guarded move with a motion service that supports DoGuardedMove.
+func GuardedMove(ctx context.Context, svc Service, req GuardedMoveReq) (GuardedMoveResult, error) {
+	resp, err := resource.DoCommandAs[guardedMoveCommand, guardedMoveResponse](ctx, svc, guardedMoveCommand{GuardedMove: req})
+	if err != nil {
+		return GuardedMoveResult{}, err
+	}
+	return resp.GuardedMove, nil
+}
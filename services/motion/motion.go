@@ -417,6 +417,18 @@ type MotionConfiguration struct {
 	AngularDegsPerSec     float64
 }
 
+// export keys to be used with DoCommand to control a motion service's in-progress plan executions so
+// they can be referenced by clients and other services.
+const (
+	// DoPauseExecution, DoResumeExecution, and DoScaleExecution pause, resume, or change the speed scale of
+	// plan executions. The value of DoScaleExecution is the new scale, in (0, 1].
+	DoPauseExecution  = "pause_execution"
+	DoResumeExecution = "resume_execution"
+	DoScaleExecution  = "scale_execution"
+	// DoExecutionID is the id of the execution to control; all executions are controlled if it is omitted.
+	DoExecutionID = "execution_id"
+)
+
 // SubtypeName is the name of the type of service.
 const SubtypeName = "motion"
 
@@ -29,6 +29,9 @@ func PollHistoryUntilSuccessOrError(
 			return err
 		}
 
+		if len(ph) == 0 || len(ph[0].StatusHistory) == 0 {
+			return errors.New("plan history is empty")
+		}
 		status := ph[0].StatusHistory[0]
 
 		switch status.State {
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/golang/geo/r3"
+	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -75,6 +76,9 @@ const (
 
 	// frequency in milliseconds.
 	planHistoryPollFrequency = time.Millisecond * 50
+
+	// number of progress events retained for introspection.
+	eventLogCapacity = 256
 )
 
 func init() {
@@ -109,6 +113,10 @@ type Config struct {
 	PlanDeviationM             float64                          `json:"plan_deviation_m,omitempty"`
 	ReplanCostFactor           float64                          `json:"replan_cost_factor,omitempty"`
 	LogFilePath                string                           `json:"log_file_path"`
+
+	// Geofences are keep-out zones added to the planning obstacles and keep-in zones added to the
+	// planning bounding regions. Both are enforced in every mode by stopping the base on breach.
+	Geofences []*navigation.GeofenceConfig `json:"geofences,omitempty"`
 }
 
 type executionWaypoint struct {
@@ -200,6 +208,12 @@ func (conf *Config) Validate(path string) ([]string, []string, error) {
 		}
 	}
 
+	for i, fence := range conf.Geofences {
+		if err := fence.Validate(fmt.Sprintf("%s.geofences.%d", path, i)); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// add framesystem service as dependency to be used by builtin and explore motion service
 	deps = append(deps, framesystem.InternalServiceName.String())
 
@@ -213,6 +227,7 @@ func NewBuiltIn(
 	navSvc := &builtIn{
 		Named:  conf.ResourceName().AsNamed(),
 		logger: logger,
+		events: navigation.NewEventLog(eventLogCapacity),
 	}
 	if err := navSvc.reconfigure(ctx, deps, conf); err != nil {
 		return nil, err
@@ -238,6 +253,7 @@ type builtIn struct {
 	motionService        motion.Service
 	obstacles            []*spatialmath.GeoGeometry
 	boundingRegions      []*spatialmath.GeoGeometry
+	geofences            []*navigation.Geofence
 
 	motionCfg        *motion.MotionConfiguration
 	replanCostFactor float64
@@ -247,6 +263,10 @@ type builtIn struct {
 	currentWaypointCancelFunc func()
 	waypointInProgress        *navigation.Waypoint
 	activeBackgroundWorkers   sync.WaitGroup
+	events                    *navigation.EventLog
+
+	geofenceCancelFunc func()
+	geofenceWorkers    sync.WaitGroup
 }
 
 func (svc *builtIn) reconfigure(ctx context.Context, deps resource.Dependencies, conf resource.Config) error {
@@ -254,6 +274,7 @@ func (svc *builtIn) reconfigure(ctx context.Context, deps resource.Dependencies,
 	defer svc.actionMu.Unlock()
 
 	svc.stopActiveMode()
+	svc.stopGeofenceMonitor()
 
 	// Set framesystem service
 	for name, dep := range deps {
@@ -387,12 +408,32 @@ func (svc *builtIn) reconfigure(ctx context.Context, deps resource.Dependencies,
 		return errors.Wrap(errBoundingRegionsGeomParse, err.Error())
 	}
 
+	// Parse geofences from the configuration and represent them to the planner
+	geofences := make([]*navigation.Geofence, 0, len(svcConfig.Geofences))
+	for _, fenceCfg := range svcConfig.Geofences {
+		fence, err := navigation.NewGeofence(fenceCfg)
+		if err != nil {
+			return err
+		}
+		geoGeom, err := fence.GeoGeometry()
+		if err != nil {
+			return err
+		}
+		if fence.Type == navigation.GeofenceKeepOut {
+			newObstacles = append(newObstacles, geoGeom)
+		} else {
+			newBoundingRegions = append(newBoundingRegions, geoGeom)
+		}
+		geofences = append(geofences, fence)
+	}
+
 	svc.mode = navigation.ModeManual
 	svc.base = baseComponent
 	svc.mapType = mapType
 	svc.motionService = motionSvc
 	svc.obstacles = newObstacles
 	svc.boundingRegions = newBoundingRegions
+	svc.geofences = geofences
 	svc.replanCostFactor = replanCostFactor
 	svc.visionServicesByName = visionServicesByName
 	svc.motionCfg = &motion.MotionConfiguration{
@@ -403,6 +444,7 @@ func (svc *builtIn) reconfigure(ctx context.Context, deps resource.Dependencies,
 		PositionPollingFreqHz: &positionPollingFrequencyHz,
 		ObstaclePollingFreqHz: &obstaclePollingFrequencyHz,
 	}
+	svc.startGeofenceMonitor()
 
 	return nil
 }
@@ -449,6 +491,86 @@ func (svc *builtIn) SetMode(ctx context.Context, mode navigation.Mode, extra map
 	return nil
 }
 
+// startGeofenceMonitor polls the position of the machine in every mode for as long as the current
+// configuration is in effect. On a breach it stops the base and any active plan; if the service is
+// navigating on its own it also drops back into manual mode. In manual mode the base is stopped once
+// on entering a breach so that it can then be driven back out.
+func (svc *builtIn) startGeofenceMonitor() {
+	if len(svc.geofences) == 0 {
+		return
+	}
+	if svc.movementSensor == nil {
+		svc.logger.Warn("geofences are configured but cannot be enforced without a movement sensor")
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	svc.geofenceCancelFunc = cancel
+
+	interval := time.Duration(float64(time.Second) / *svc.motionCfg.PositionPollingFreqHz)
+	geofences := svc.geofences
+	movementSensor := svc.movementSensor
+	baseComponent := svc.base
+	motionSvc := svc.motionService
+
+	svc.geofenceWorkers.Add(1)
+	utils.ManagedGo(func() {
+		var breached *navigation.Geofence
+		for utils.SelectContextOrWait(ctx, interval) {
+			loc, _, err := movementSensor.Position(ctx, nil)
+			if err != nil {
+				svc.logger.CDebugf(ctx, "unable to check geofences due to error getting position: %v", err)
+				continue
+			}
+			fence := navigation.BreachedGeofence(loc, geofences)
+			wasBreached := breached != nil
+			breached = fence
+			if fence == nil {
+				continue
+			}
+
+			svc.mu.Lock()
+			mode := svc.mode
+			cancelMode := svc.wholeServiceCancelFunc
+			if mode != navigation.ModeManual {
+				svc.mode = navigation.ModeManual
+			}
+			svc.mu.Unlock()
+			if mode == navigation.ModeManual && wasBreached {
+				continue
+			}
+
+			msg := fmt.Sprintf("%s geofence %q breached at %v, %v", fence.Type, fence.Name, loc.Lat(), loc.Lng())
+			if mode == navigation.ModeManual {
+				svc.logger.CError(ctx, msg+"; stopping the base")
+			} else {
+				svc.logger.CErrorf(ctx, "%s; stopping the base and switching from %s to manual mode", msg, mode)
+				if cancelMode != nil {
+					cancelMode()
+				}
+			}
+			svc.events.Publish(navigation.EventGeofenceBreach, primitive.NilObjectID, msg)
+
+			stopCtx, stopCancel := context.WithTimeout(ctx, 5*time.Second)
+			if err := baseComponent.Stop(stopCtx, nil); err != nil {
+				svc.logger.CErrorf(ctx, "failed to stop base after geofence breach: %v", err)
+			}
+			if err := motionSvc.StopPlan(stopCtx, motion.StopPlanReq{ComponentName: baseComponent.Name().Name}); err != nil {
+				svc.logger.CDebugf(ctx, "failed to stop plan after geofence breach: %v", err)
+			}
+			stopCancel()
+		}
+	}, svc.geofenceWorkers.Done)
+}
+
+// stopGeofenceMonitor stops the monitor started by startGeofenceMonitor and waits for it to exit.
+func (svc *builtIn) stopGeofenceMonitor() {
+	if svc.geofenceCancelFunc != nil {
+		svc.geofenceCancelFunc()
+		svc.geofenceCancelFunc = nil
+	}
+	svc.geofenceWorkers.Wait()
+}
+
 func (svc *builtIn) Location(ctx context.Context, extra map[string]interface{}) (*spatialmath.GeoPose, error) {
 	svc.mu.RLock()
 	defer svc.mu.RUnlock()
@@ -510,12 +632,7 @@ func (svc *builtIn) RemoveWaypoint(ctx context.Context, id primitive.ObjectID, e
 	svc.mu.Lock()
 	defer svc.mu.Unlock()
 	svc.logger.CInfof(ctx, "RemoveWaypoint called with waypointID: %s", id)
-	if svc.waypointInProgress != nil && svc.waypointInProgress.ID == id {
-		if svc.currentWaypointCancelFunc != nil {
-			svc.currentWaypointCancelFunc()
-		}
-		svc.waypointInProgress = nil
-	}
+	svc.cancelWaypointInProgressLocked(id)
 	return svc.store.RemoveWaypoint(ctx, id)
 }
 
@@ -539,6 +656,7 @@ func (svc *builtIn) Close(ctx context.Context) error {
 	defer svc.actionMu.Unlock()
 
 	svc.stopActiveMode()
+	svc.stopGeofenceMonitor()
 	return svc.store.Close(ctx)
 }
 
@@ -585,13 +703,11 @@ func (svc *builtIn) moveToWaypoint(ctx context.Context, wp navigation.Waypoint,
 		}
 	}()
 
-	err = motion.PollHistoryUntilSuccessOrError(cancelCtx, svc.motionService, planHistoryPollFrequency,
-		motion.PlanHistoryReq{
-			ComponentName: req.ComponentName,
-			ExecutionID:   executionID,
-			LastPlanOnly:  true,
-		},
-	)
+	err = svc.pollExecutionUntilDone(cancelCtx, wp, motion.PlanHistoryReq{
+		ComponentName: req.ComponentName,
+		ExecutionID:   executionID,
+		LastPlanOnly:  true,
+	})
 	if err != nil {
 		return err
 	}
@@ -599,6 +715,45 @@ func (svc *builtIn) moveToWaypoint(ctx context.Context, wp navigation.Waypoint,
 	return svc.waypointReached(cancelCtx)
 }
 
+// pollExecutionUntilDone polls the execution with motion.PollHistoryUntilSuccessOrError while publishing
+// an EventReplanned whenever the motion service replaces the plan of the execution.
+func (svc *builtIn) pollExecutionUntilDone(ctx context.Context, wp navigation.Waypoint, req motion.PlanHistoryReq) error {
+	observer := &replanObserver{
+		Service: svc.motionService,
+		onReplan: func(reason string) {
+			svc.events.Publish(navigation.EventReplanned, wp.ID, reason)
+		},
+	}
+	return motion.PollHistoryUntilSuccessOrError(ctx, observer, planHistoryPollFrequency, req)
+}
+
+// replanObserver wraps a motion service to notice when the plan returned by PlanHistory changes.
+type replanObserver struct {
+	motion.Service
+	onReplan   func(reason string)
+	lastPlanID motion.PlanID
+}
+
+func (o *replanObserver) PlanHistory(ctx context.Context, req motion.PlanHistoryReq) ([]motion.PlanWithStatus, error) {
+	ph, err := o.Service.PlanHistory(ctx, req)
+	if err != nil || len(ph) == 0 {
+		return ph, err
+	}
+	planID := ph[0].Plan.ID
+	if planID == o.lastPlanID {
+		return ph, nil
+	}
+	if o.lastPlanID != uuid.Nil {
+		reason := "replanned"
+		if statuses := ph[0].StatusHistory; len(statuses) > 1 && statuses[len(statuses)-1].Reason != nil {
+			reason = *statuses[len(statuses)-1].Reason
+		}
+		o.onReplan(reason)
+	}
+	o.lastPlanID = planID
+	return ph, nil
+}
+
 func (svc *builtIn) startWaypointMode(ctx context.Context, extra map[string]interface{}) {
 	if extra == nil {
 		extra = map[string]interface{}{}
@@ -626,15 +781,18 @@ func (svc *builtIn) startWaypointMode(ctx context.Context, extra map[string]inte
 			svc.mu.Unlock()
 
 			svc.logger.CInfof(ctx, "navigating to waypoint: %+v", wp)
+			svc.events.Publish(navigation.EventWaypointStarted, wp.ID, "")
 			if err := svc.moveToWaypoint(cancelCtx, wp, extra); err != nil {
 				if svc.waypointIsDeleted() {
 					svc.logger.CInfof(ctx, "skipping waypoint %+v since it was deleted", wp)
 					continue
 				}
 				svc.logger.CWarnf(ctx, "retrying navigation to waypoint %+v since it errored out: %s", wp, err)
+				svc.events.Publish(navigation.EventWaypointFailed, wp.ID, err.Error())
 				continue
 			}
 			svc.logger.CInfof(ctx, "reached waypoint: %+v", wp)
+			svc.events.Publish(navigation.EventWaypointReached, wp.ID, "")
 		}
 	}, svc.activeBackgroundWorkers.Done)
 }
@@ -846,6 +1004,121 @@ func (svc *builtIn) Paths(ctx context.Context, extra map[string]interface{}) ([]
 	return []*navigation.Path{navPath}, nil
 }
 
+// Route returns the unvisited waypoints in the order they will be navigated to, with the estimated
+// time of arrival at each computed from the configured linear speed.
+func (svc *builtIn) Route(ctx context.Context) (navigation.Route, error) {
+	wps, err := svc.store.Waypoints(ctx)
+	if err != nil {
+		return navigation.Route{}, err
+	}
+
+	svc.mu.RLock()
+	movementSensor := svc.movementSensor
+	metersPerSec := svc.motionCfg.LinearMPerSec
+	var inProgress *primitive.ObjectID
+	if svc.waypointInProgress != nil {
+		id := svc.waypointInProgress.ID
+		inProgress = &id
+	}
+	svc.mu.RUnlock()
+
+	var prev *geo.Point
+	if movementSensor != nil {
+		loc, _, err := movementSensor.Position(ctx, nil)
+		if err != nil {
+			svc.logger.CDebugf(ctx, "not including distance to first waypoint in route due to error getting position: %v", err)
+		} else {
+			prev = loc
+		}
+	}
+
+	route := navigation.Route{InProgress: inProgress, Legs: make([]navigation.RouteLeg, 0, len(wps))}
+	var totalM float64
+	for _, wp := range wps {
+		pt := wp.ToPoint()
+		var distM float64
+		if prev != nil {
+			// GreatCircleDistance returns kilometers
+			distM = 1e3 * prev.GreatCircleDistance(pt)
+		}
+		totalM += distM
+		var eta time.Duration
+		if metersPerSec > 0 {
+			eta = time.Duration(totalM / metersPerSec * float64(time.Second))
+		}
+		route.Legs = append(route.Legs, navigation.RouteLeg{Waypoint: wp, DistanceM: distM, ETA: eta})
+		prev = pt
+	}
+	return route, nil
+}
+
+// Events returns the retained progress events with a sequence number greater than since.
+func (svc *builtIn) Events(ctx context.Context, since uint64) ([]navigation.Event, error) {
+	return svc.events.Since(since), nil
+}
+
+// SubscribeEvents returns a channel notified whenever new progress events are emitted.
+func (svc *builtIn) SubscribeEvents() (<-chan struct{}, func()) {
+	return svc.events.Subscribe()
+}
+
+// SkipWaypoint marks the waypoint as visited, canceling navigation to it if it is in progress.
+func (svc *builtIn) SkipWaypoint(ctx context.Context, id primitive.ObjectID) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.logger.CInfof(ctx, "SkipWaypoint called with waypointID: %s", id)
+	if err := svc.store.WaypointVisited(ctx, id); err != nil {
+		return err
+	}
+	svc.cancelWaypointInProgressLocked(id)
+	svc.events.Publish(navigation.EventWaypointSkipped, id, "")
+	return nil
+}
+
+// ReorderWaypoints moves the given waypoints to the front of the route. If the waypoint in progress is
+// no longer first, navigation to it is canceled so the new first waypoint is navigated to instead.
+func (svc *builtIn) ReorderWaypoints(ctx context.Context, ids []primitive.ObjectID) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.logger.CInfof(ctx, "ReorderWaypoints called with waypointIDs: %v", ids)
+	reorderer, ok := svc.store.(navigation.WaypointReorderer)
+	if !ok {
+		return errors.Errorf("navigation store %T does not support reordering waypoints", svc.store)
+	}
+	if err := reorderer.ReorderWaypoints(ctx, ids); err != nil {
+		return err
+	}
+	next, err := svc.store.NextWaypoint(ctx)
+	if err == nil && svc.waypointInProgress != nil && svc.waypointInProgress.ID != next.ID {
+		svc.cancelWaypointInProgressLocked(svc.waypointInProgress.ID)
+	}
+	var first primitive.ObjectID
+	if len(ids) > 0 {
+		first = ids[0]
+	}
+	svc.events.Publish(navigation.EventRouteChanged, first, "")
+	return nil
+}
+
+// cancelWaypointInProgressLocked cancels navigation to the waypoint with the given id if it is in
+// progress. It must be called with svc.mu held.
+func (svc *builtIn) cancelWaypointInProgressLocked(id primitive.ObjectID) {
+	if svc.waypointInProgress != nil && svc.waypointInProgress.ID == id {
+		if svc.currentWaypointCancelFunc != nil {
+			svc.currentWaypointCancelFunc()
+		}
+		svc.waypointInProgress = nil
+	}
+}
+
+// DoCommand supports the route introspection commands defined in the navigation package.
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, handled, err := navigation.HandleRouteCommand(ctx, svc, cmd); handled {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
 func (svc *builtIn) Properties(ctx context.Context) (navigation.Properties, error) {
 	svc.mu.RLock()
 	defer svc.mu.RUnlock()
@@ -11,9 +11,11 @@ import (
 	"github.com/golang/geo/r3"
 	"github.com/google/uuid"
 	geo "github.com/kellydunn/golang-geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/atomic"
 	"go.viam.com/test"
 	"go.viam.com/utils"
+	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/base"
 	baseFake "go.viam.com/rdk/components/base/fake"
@@ -1910,3 +1912,147 @@ func createFrameSystemService(
 
 	return fsSvc, nil
 }
+
+func TestRoute(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	s := setupStartWaypoint(ctx, t, logger)
+	defer s.closeFunc()
+
+	s.movementSensor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return geo.NewPoint(0, 0), 0, nil
+	}
+
+	test.That(t, s.ns.AddWaypoint(ctx, geo.NewPoint(0, 0.001), nil), test.ShouldBeNil)
+	test.That(t, s.ns.AddWaypoint(ctx, geo.NewPoint(0, 0.002), nil), test.ShouldBeNil)
+	wps, err := s.ns.Waypoints(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(wps), test.ShouldEqual, 2)
+
+	route, err := navigation.GetRoute(ctx, s.ns)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, route.InProgress, test.ShouldBeNil)
+	test.That(t, len(route.Legs), test.ShouldEqual, 2)
+	test.That(t, route.Legs[0].Waypoint.ID, test.ShouldEqual, wps[0].ID)
+	// 0.001 degrees of longitude at the equator is roughly 111 meters
+	test.That(t, route.Legs[0].DistanceM, test.ShouldAlmostEqual, 111.2, 0.5)
+	test.That(t, route.Legs[1].DistanceM, test.ShouldAlmostEqual, 111.2, 0.5)
+	// the service is configured to travel at 1 meter per second
+	test.That(t, route.Legs[1].ETA.Seconds(), test.ShouldAlmostEqual, 222.4, 1)
+
+	test.That(t, navigation.ReorderWaypoints(ctx, s.ns, []primitive.ObjectID{wps[1].ID}), test.ShouldBeNil)
+	route, err = navigation.GetRoute(ctx, s.ns)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, route.Legs[0].Waypoint.ID, test.ShouldEqual, wps[1].ID)
+	test.That(t, route.Legs[0].DistanceM, test.ShouldAlmostEqual, 222.4, 1)
+
+	test.That(t, navigation.SkipWaypoint(ctx, s.ns, wps[1].ID), test.ShouldBeNil)
+	route, err = navigation.GetRoute(ctx, s.ns)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(route.Legs), test.ShouldEqual, 1)
+	test.That(t, route.Legs[0].Waypoint.ID, test.ShouldEqual, wps[0].ID)
+
+	events, err := navigation.GetEvents(ctx, s.ns, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(events), test.ShouldEqual, 2)
+	test.That(t, events[0].Type, test.ShouldEqual, navigation.EventRouteChanged)
+	test.That(t, events[1].Type, test.ShouldEqual, navigation.EventWaypointSkipped)
+	test.That(t, events[1].WaypointID, test.ShouldEqual, wps[1].ID)
+
+	// the same operations are available through DoCommand for clients
+	resp, err := s.ns.DoCommand(ctx, map[string]interface{}{navigation.DoEvents: float64(events[0].Seq)})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(resp[navigation.DoEvents].([]interface{})), test.ShouldEqual, 1)
+}
+
+func TestGeofenceBreach(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+
+	fakeBase, err := baseFake.NewBase(ctx, nil, resource.Config{
+		Name:  "test_base",
+		API:   base.API,
+		Frame: &referenceframe.LinkConfig{Geometry: &spatialmath.GeometryConfig{R: 100}},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	injectMovementSensor := inject.NewMovementSensor("test_movement")
+	injectMovementSensor.PositionFunc = func(ctx context.Context, extra map[string]interface{}) (*geo.Point, float64, error) {
+		return geo.NewPoint(0, 0), 0, nil
+	}
+	injectMS := injectmotion.NewMotionService("test_motion")
+	var stopPlanCalled atomic.Bool
+	injectMS.StopPlanFunc = func(ctx context.Context, req motion.StopPlanReq) error {
+		stopPlanCalled.Store(true)
+		return nil
+	}
+
+	pollingHz := 100.
+	cfg := &Config{
+		BaseName:                   "test_base",
+		MovementSensorName:         "test_movement",
+		MotionServiceName:          "test_motion",
+		PositionPollingFrequencyHz: pollingHz,
+		Geofences: []*navigation.GeofenceConfig{
+			{
+				Name: "pond",
+				Type: navigation.GeofenceKeepOut,
+				GeoJSON: []byte(`{"type": "Polygon", "coordinates": ` +
+					`[[[-0.001, -0.001], [0.001, -0.001], [0.001, 0.001], [-0.001, 0.001], [-0.001, -0.001]]]}`),
+			},
+		},
+	}
+	_, _, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	deps := resource.Dependencies{
+		injectMS.Name():             injectMS,
+		fakeBase.Name():             fakeBase,
+		injectMovementSensor.Name(): injectMovementSensor,
+	}
+	ns, err := NewBuiltIn(ctx, deps, resource.Config{ConvertedAttributes: cfg}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() { test.That(t, ns.Close(context.Background()), test.ShouldBeNil) }()
+
+	// the keep-out zone is passed to the planner as an obstacle
+	obstacles, err := ns.Obstacles(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(obstacles), test.ShouldEqual, 1)
+	test.That(t, obstacles[0].Geometries()[0].Label(), test.ShouldEqual, "pond")
+
+	// geofences are enforced in manual mode too, by stopping the base once on entering the breach
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, stopPlanCalled.Load(), test.ShouldBeTrue)
+	})
+	events, err := navigation.GetEvents(ctx, ns, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(events), test.ShouldEqual, 1)
+	test.That(t, events[0].Type, test.ShouldEqual, navigation.EventGeofenceBreach)
+	test.That(t, events[0].Message, test.ShouldContainSubstring, "pond")
+	time.Sleep(10 * time.Second / time.Duration(pollingHz))
+	events, err = navigation.GetEvents(ctx, ns, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(events), test.ShouldEqual, 1)
+
+	// navigating autonomously inside the breach drops back into manual mode
+	stopPlanCalled.Store(false)
+	test.That(t, ns.SetMode(ctx, navigation.ModeWaypoint, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		mode, err := ns.Mode(ctx, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, mode, test.ShouldEqual, navigation.ModeManual)
+	})
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, stopPlanCalled.Load(), test.ShouldBeTrue)
+	})
+	events, err = navigation.GetEvents(ctx, ns, events[0].Seq)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(events), test.ShouldEqual, 1)
+	test.That(t, events[0].Type, test.ShouldEqual, navigation.EventGeofenceBreach)
+
+	t.Run("invalid geofence config fails validation", func(t *testing.T) {
+		cfg.Geofences[0].Type = "keep_near"
+		_, _, err := cfg.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "path.geofences.0")
+	})
+}
@@ -0,0 +1,306 @@
+package navigation
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/golang/geo/r3"
+	geo "github.com/kellydunn/golang-geo"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// GeofenceType describes whether a geofence must be avoided or must not be left.
+type GeofenceType string
+
+// The set of known geofence types.
+const (
+	GeofenceKeepOut = GeofenceType("keep_out")
+	GeofenceKeepIn  = GeofenceType("keep_in")
+)
+
+const (
+	// geofenceHeightMM is the height of the geometries used to represent geofences during planning.
+	geofenceHeightMM = 1e4
+	// geofenceCellsPerSide is how many cells the longer side of a geofence's bounding rectangle is
+	// divided into when the polygon is decomposed into boxes for planning.
+	geofenceCellsPerSide = 64
+)
+
+// GeofenceConfig describes a polygonal zone given as a GeoJSON Polygon geometry or a Feature wrapping one.
+type GeofenceConfig struct {
+	Name    string          `json:"name"`
+	Type    GeofenceType    `json:"type"`
+	GeoJSON json.RawMessage `json:"geojson"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *GeofenceConfig) Validate(path string) error {
+	if conf.Name == "" {
+		return resource.NewConfigValidationFieldRequiredError(path, "name")
+	}
+	switch conf.Type {
+	case GeofenceKeepOut, GeofenceKeepIn:
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown geofence type %q", conf.Type))
+	}
+	if _, err := NewGeofence(conf); err != nil {
+		return resource.NewConfigValidationError(path, err)
+	}
+	return nil
+}
+
+// Geofence is a polygonal zone, possibly with holes, that navigation must stay out of or inside of.
+type Geofence struct {
+	Name string
+	Type GeofenceType
+	// rings holds the outer ring of the polygon followed by any holes.
+	rings [][]*geo.Point
+}
+
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Coordinates [][][2]float64  `json:"coordinates"`
+	Properties  json.RawMessage `json:"properties"`
+}
+
+// NewGeofence parses the GeoJSON in the config into a Geofence.
+func NewGeofence(conf *GeofenceConfig) (*Geofence, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(conf.GeoJSON, &obj); err != nil {
+		return nil, errors.Wrapf(err, "geofence %q has invalid geojson", conf.Name)
+	}
+	if obj.Type == "Feature" {
+		if obj.Geometry == nil {
+			return nil, errors.Errorf("geofence %q geojson feature has no geometry", conf.Name)
+		}
+		obj = *obj.Geometry
+	}
+	if obj.Type != "Polygon" {
+		return nil, errors.Errorf("geofence %q geojson must be a Polygon but got %q", conf.Name, obj.Type)
+	}
+	if len(obj.Coordinates) == 0 {
+		return nil, errors.Errorf("geofence %q polygon has no rings", conf.Name)
+	}
+
+	fence := &Geofence{Name: conf.Name, Type: conf.Type}
+	for _, ring := range obj.Coordinates {
+		// GeoJSON rings are closed so the last position repeats the first
+		if len(ring) < 4 {
+			return nil, errors.Errorf("geofence %q polygon rings must have at least 4 positions", conf.Name)
+		}
+		pts := make([]*geo.Point, 0, len(ring))
+		for _, pos := range ring {
+			// GeoJSON positions are [longitude, latitude]
+			pts = append(pts, geo.NewPoint(pos[1], pos[0]))
+		}
+		fence.rings = append(fence.rings, pts)
+	}
+	return fence, nil
+}
+
+// Contains returns whether the point lies inside the polygon and outside all of its holes.
+func (g *Geofence) Contains(pt *geo.Point) bool {
+	if !ringContains(g.rings[0], pt) {
+		return false
+	}
+	for _, hole := range g.rings[1:] {
+		if ringContains(hole, pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// GeoGeometry returns the geofence decomposed into boxes on a grid, which is how it is represented to
+// the motion planner. Keep-out zones are covered by every cell that overlaps the polygon, so planning
+// around them is conservative. Keep-in zones are made up of only the cells lying entirely inside the
+// polygon, so planning within them never leaves the zone. Holes and concave edges are respected either
+// way, to within one cell.
+func (g *Geofence) GeoGeometry() (*spatialmath.GeoGeometry, error) {
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	minLng, maxLng := math.Inf(1), math.Inf(-1)
+	for _, pt := range g.rings[0] {
+		minLat, maxLat = math.Min(minLat, pt.Lat()), math.Max(maxLat, pt.Lat())
+		minLng, maxLng = math.Min(minLng, pt.Lng()), math.Max(maxLng, pt.Lng())
+	}
+	center := geo.NewPoint((minLat+maxLat)/2, (minLng+maxLng)/2)
+
+	// project the rings into the plane around the center the same way the planner projects GeoGeometries
+	rings := make([][]r3.Vector, 0, len(g.rings))
+	minPt, maxPt := r3.Vector{X: math.Inf(1), Y: math.Inf(1)}, r3.Vector{X: math.Inf(-1), Y: math.Inf(-1)}
+	for i, ring := range g.rings {
+		planar := make([]r3.Vector, 0, len(ring))
+		for _, pt := range ring {
+			v := spatialmath.GeoPointToPoint(pt, center)
+			if i == 0 {
+				minPt = r3.Vector{X: math.Min(minPt.X, v.X), Y: math.Min(minPt.Y, v.Y)}
+				maxPt = r3.Vector{X: math.Max(maxPt.X, v.X), Y: math.Max(maxPt.Y, v.Y)}
+			}
+			planar = append(planar, v)
+		}
+		rings = append(rings, planar)
+	}
+	cellSize := math.Max(maxPt.X-minPt.X, maxPt.Y-minPt.Y) / geofenceCellsPerSide
+	if cellSize <= 0 {
+		return nil, errors.Errorf("geofence %q has no area", g.Name)
+	}
+
+	keepOut := g.Type == GeofenceKeepOut
+	var geoms []spatialmath.Geometry
+	addRun := func(y float64, fromX, toX float64) error {
+		box, err := spatialmath.NewBox(
+			spatialmath.NewPoseFromPoint(r3.Vector{X: (fromX + toX) / 2, Y: y}),
+			r3.Vector{X: toX - fromX, Y: cellSize, Z: geofenceHeightMM},
+			g.Name,
+		)
+		if err != nil {
+			return err
+		}
+		geoms = append(geoms, box)
+		return nil
+	}
+	for y0 := minPt.Y; y0 < maxPt.Y; y0 += cellSize {
+		y1 := y0 + cellSize
+		// adjacent cells in a row are merged into a single box
+		runStart := math.NaN()
+		for x0 := minPt.X; x0 < maxPt.X; x0 += cellSize {
+			x1 := x0 + cellSize
+			centerInside := planarPolygonContains(rings, r3.Vector{X: (x0 + x1) / 2, Y: (y0 + y1) / 2})
+			onBoundary := planarPolygonCrossesRect(rings, x0, y0, x1, y1)
+			included := centerInside && !onBoundary
+			if keepOut {
+				included = centerInside || onBoundary
+			}
+			switch {
+			case included && math.IsNaN(runStart):
+				runStart = x0
+			case !included && !math.IsNaN(runStart):
+				if err := addRun((y0+y1)/2, runStart, x0); err != nil {
+					return nil, err
+				}
+				runStart = math.NaN()
+			}
+		}
+		if !math.IsNaN(runStart) {
+			if err := addRun((y0+y1)/2, runStart, minPt.X+cellSize*math.Ceil((maxPt.X-minPt.X)/cellSize)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(geoms) == 0 {
+		return nil, errors.Errorf("geofence %q is too narrow to be represented to the planner", g.Name)
+	}
+	return spatialmath.NewGeoGeometry(center, geoms), nil
+}
+
+// BreachedGeofence returns the first geofence violated by the given location: a keep-out zone containing
+// it, or, if any keep-in zones exist, a keep-in zone when the location is outside of all of them.
+// It returns nil if no geofence is violated.
+func BreachedGeofence(pt *geo.Point, fences []*Geofence) *Geofence {
+	var firstKeepIn *Geofence
+	insideKeepIn := false
+	for _, fence := range fences {
+		switch fence.Type {
+		case GeofenceKeepOut:
+			if fence.Contains(pt) {
+				return fence
+			}
+		case GeofenceKeepIn:
+			if firstKeepIn == nil {
+				firstKeepIn = fence
+			}
+			if fence.Contains(pt) {
+				insideKeepIn = true
+			}
+		}
+	}
+	if firstKeepIn != nil && !insideKeepIn {
+		return firstKeepIn
+	}
+	return nil
+}
+
+// ringContains uses ray casting over longitude/latitude, which is accurate for zones that are small
+// relative to the curvature of the earth.
+func ringContains(ring []*geo.Point, pt *geo.Point) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat() > pt.Lat()) != (b.Lat() > pt.Lat()) &&
+			pt.Lng() < (b.Lng()-a.Lng())*(pt.Lat()-a.Lat())/(b.Lat()-a.Lat())+a.Lng() {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// planarPolygonContains is the planar counterpart of Contains for rings projected by GeoGeometry.
+func planarPolygonContains(rings [][]r3.Vector, pt r3.Vector) bool {
+	if !planarRingContains(rings[0], pt) {
+		return false
+	}
+	for _, hole := range rings[1:] {
+		if planarRingContains(hole, pt) {
+			return false
+		}
+	}
+	return true
+}
+
+func planarRingContains(ring []r3.Vector, pt r3.Vector) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Y > pt.Y) != (b.Y > pt.Y) && pt.X < (b.X-a.X)*(pt.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// planarPolygonCrossesRect returns whether any edge of the rings passes through the given rectangle.
+func planarPolygonCrossesRect(rings [][]r3.Vector, x0, y0, x1, y1 float64) bool {
+	for _, ring := range rings {
+		for i := 1; i < len(ring); i++ {
+			if segmentCrossesRect(ring[i-1], ring[i], x0, y0, x1, y1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// segmentCrossesRect clips the segment from a to b against the rectangle (Liang-Barsky) and reports
+// whether any part of it remains.
+func segmentCrossesRect(a, b r3.Vector, x0, y0, x1, y1 float64) bool {
+	d := b.Sub(a)
+	tMin, tMax := 0., 1.
+	for _, edge := range [][2]float64{
+		{-d.X, a.X - x0},
+		{d.X, x1 - a.X},
+		{-d.Y, a.Y - y0},
+		{d.Y, y1 - a.Y},
+	} {
+		p, q := edge[0], edge[1]
+		if p == 0 {
+			if q < 0 {
+				return false
+			}
+			continue
+		}
+		t := q / p
+		if p < 0 {
+			tMin = math.Max(tMin, t)
+		} else {
+			tMax = math.Min(tMax, t)
+		}
+		if tMin > tMax {
+			return false
+		}
+	}
+	return true
+}
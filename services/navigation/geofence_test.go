@@ -0,0 +1,125 @@
+package navigation_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/services/navigation"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// square of side 0.002 degrees around the origin with a hole of side 0.001 degrees.
+const squareWithHole = `{
+	"type": "Polygon",
+	"coordinates": [
+		[[-0.001, -0.001], [0.001, -0.001], [0.001, 0.001], [-0.001, 0.001], [-0.001, -0.001]],
+		[[-0.0005, -0.0005], [0.0005, -0.0005], [0.0005, 0.0005], [-0.0005, 0.0005], [-0.0005, -0.0005]]
+	]
+}`
+
+func TestGeofence(t *testing.T) {
+	t.Run("parse polygon and feature", func(t *testing.T) {
+		fence, err := navigation.NewGeofence(&navigation.GeofenceConfig{
+			Name: "square", Type: navigation.GeofenceKeepOut, GeoJSON: json.RawMessage(squareWithHole),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fence.Contains(geo.NewPoint(0.0008, 0)), test.ShouldBeTrue)
+		test.That(t, fence.Contains(geo.NewPoint(0, 0)), test.ShouldBeFalse)
+		test.That(t, fence.Contains(geo.NewPoint(0.002, 0)), test.ShouldBeFalse)
+
+		feature := `{"type": "Feature", "properties": {}, "geometry": ` + squareWithHole + `}`
+		fence, err = navigation.NewGeofence(&navigation.GeofenceConfig{
+			Name: "feature", Type: navigation.GeofenceKeepIn, GeoJSON: json.RawMessage(feature),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fence.Contains(geo.NewPoint(0, 0.0008)), test.ShouldBeTrue)
+	})
+
+	t.Run("invalid configs", func(t *testing.T) {
+		for _, tc := range []navigation.GeofenceConfig{
+			{Type: navigation.GeofenceKeepOut, GeoJSON: json.RawMessage(squareWithHole)},
+			{Name: "a", Type: "keep_around", GeoJSON: json.RawMessage(squareWithHole)},
+			{Name: "a", Type: navigation.GeofenceKeepOut, GeoJSON: json.RawMessage(`{"type": "Point", "coordinates": [0, 0]}`)},
+			{Name: "a", Type: navigation.GeofenceKeepOut, GeoJSON: json.RawMessage(`{"type": "Polygon", "coordinates": [[[0, 0], [1, 1]]]}`)},
+			{Name: "a", Type: navigation.GeofenceKeepOut, GeoJSON: json.RawMessage(`not json`)},
+		} {
+			test.That(t, tc.Validate("path"), test.ShouldNotBeNil)
+		}
+	})
+
+	// covered reports whether the geofence's planning geometry covers the given location.
+	covered := func(t *testing.T, geoGeom *spatialmath.GeoGeometry, pt *geo.Point) bool {
+		t.Helper()
+		probe := spatialmath.NewPoint(spatialmath.GeoPointToPoint(pt, geoGeom.Location()), "")
+		for _, geom := range spatialmath.GeoGeometriesToGeometries([]*spatialmath.GeoGeometry{geoGeom}, geoGeom.Location()) {
+			collides, _, err := geom.CollidesWith(probe, 0)
+			test.That(t, err, test.ShouldBeNil)
+			if collides {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("keep-out planning geometry covers the polygon but not its hole", func(t *testing.T) {
+		fence, err := navigation.NewGeofence(&navigation.GeofenceConfig{
+			Name: "square", Type: navigation.GeofenceKeepOut, GeoJSON: json.RawMessage(squareWithHole),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		geoGeom, err := fence.GeoGeometry()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, geoGeom.Location().Lat(), test.ShouldAlmostEqual, 0)
+		test.That(t, geoGeom.Location().Lng(), test.ShouldAlmostEqual, 0)
+		for _, geom := range geoGeom.Geometries() {
+			test.That(t, geom.Label(), test.ShouldEqual, "square")
+		}
+
+		test.That(t, covered(t, geoGeom, geo.NewPoint(0.0008, 0)), test.ShouldBeTrue)
+		test.That(t, covered(t, geoGeom, geo.NewPoint(-0.00099, -0.00099)), test.ShouldBeTrue)
+		test.That(t, covered(t, geoGeom, geo.NewPoint(0, 0)), test.ShouldBeFalse)
+		test.That(t, covered(t, geoGeom, geo.NewPoint(0.002, 0)), test.ShouldBeFalse)
+	})
+
+	t.Run("keep-in planning geometry stays within a concave polygon", func(t *testing.T) {
+		// an L shape missing its north-east quadrant
+		fence, err := navigation.NewGeofence(&navigation.GeofenceConfig{
+			Name: "yard", Type: navigation.GeofenceKeepIn, GeoJSON: json.RawMessage(`{
+				"type": "Polygon",
+				"coordinates": [[[-0.001, -0.001], [0.001, -0.001], [0.001, 0], [0, 0], [0, 0.001], [-0.001, 0.001], [-0.001, -0.001]]]
+			}`),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		geoGeom, err := fence.GeoGeometry()
+		test.That(t, err, test.ShouldBeNil)
+
+		test.That(t, covered(t, geoGeom, geo.NewPoint(-0.0005, -0.0005)), test.ShouldBeTrue)
+		test.That(t, covered(t, geoGeom, geo.NewPoint(0.0005, -0.0005)), test.ShouldBeTrue)
+		test.That(t, covered(t, geoGeom, geo.NewPoint(0.0005, 0.0005)), test.ShouldBeFalse)
+		test.That(t, covered(t, geoGeom, geo.NewPoint(0.00001, 0.00001)), test.ShouldBeFalse)
+		test.That(t, covered(t, geoGeom, geo.NewPoint(-0.0011, 0)), test.ShouldBeFalse)
+	})
+
+	t.Run("breaches", func(t *testing.T) {
+		keepOut, err := navigation.NewGeofence(&navigation.GeofenceConfig{
+			Name: "out", Type: navigation.GeofenceKeepOut, GeoJSON: json.RawMessage(squareWithHole),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		keepIn, err := navigation.NewGeofence(&navigation.GeofenceConfig{
+			Name: "in", Type: navigation.GeofenceKeepIn, GeoJSON: json.RawMessage(`{
+				"type": "Polygon",
+				"coordinates": [[[-0.01, -0.01], [0.01, -0.01], [0.01, 0.01], [-0.01, 0.01], [-0.01, -0.01]]]
+			}`),
+		})
+		test.That(t, err, test.ShouldBeNil)
+		fences := []*navigation.Geofence{keepOut, keepIn}
+
+		test.That(t, navigation.BreachedGeofence(geo.NewPoint(0, 0), fences), test.ShouldBeNil)
+		test.That(t, navigation.BreachedGeofence(geo.NewPoint(0.005, 0.005), fences), test.ShouldBeNil)
+		test.That(t, navigation.BreachedGeofence(geo.NewPoint(0.0008, 0), fences), test.ShouldEqual, keepOut)
+		test.That(t, navigation.BreachedGeofence(geo.NewPoint(0.02, 0), fences), test.ShouldEqual, keepIn)
+		test.That(t, navigation.BreachedGeofence(geo.NewPoint(0.02, 0), fences[:1]), test.ShouldBeNil)
+	})
+}
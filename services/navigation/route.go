@@ -0,0 +1,438 @@
+package navigation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoRoute            = "route"
+	DoEvents           = "events"
+	DoSkipWaypoint     = "skip_waypoint"
+	DoReorderWaypoints = "reorder_waypoints"
+)
+
+// ErrRouteUnsupported is returned when a navigation service does not support route introspection.
+var ErrRouteUnsupported = errors.New("navigation service does not support route introspection")
+
+// RouteLeg is a single leg of the planned route ending at Waypoint.
+type RouteLeg struct {
+	Waypoint Waypoint
+	// DistanceM is the great circle length of this leg in meters.
+	DistanceM float64
+	// ETA is the estimated time from now until Waypoint is reached, including all previous legs.
+	ETA time.Duration
+}
+
+// Route is the ordered list of legs the navigation service intends to travel.
+type Route struct {
+	Legs []RouteLeg
+	// InProgress is the ID of the waypoint currently being navigated to, if any.
+	InProgress *primitive.ObjectID
+}
+
+// EventType describes what happened in an Event.
+type EventType string
+
+// The set of known event types.
+const (
+	EventWaypointStarted EventType = "waypoint_started"
+	EventWaypointReached EventType = "waypoint_reached"
+	EventWaypointSkipped EventType = "waypoint_skipped"
+	EventWaypointFailed  EventType = "waypoint_failed"
+	EventReplanned       EventType = "replanned"
+	EventRouteChanged    EventType = "route_changed"
+	EventGeofenceBreach  EventType = "geofence_breach"
+)
+
+// Event is a progress event emitted by the navigation service while navigating.
+type Event struct {
+	// Seq increases monotonically for each event emitted by a service.
+	Seq        uint64
+	Type       EventType
+	WaypointID primitive.ObjectID
+	Time       time.Time
+	Message    string
+}
+
+// RouteIntrospector is an optional interface implemented by navigation services that expose their
+// planned route and progress events, and allow the route to be altered at runtime.
+type RouteIntrospector interface {
+	// Route returns the currently planned route.
+	Route(ctx context.Context) (Route, error)
+	// Events returns all retained events with a sequence number greater than since.
+	Events(ctx context.Context, since uint64) ([]Event, error)
+	// SkipWaypoint marks the waypoint visited without navigating to it, canceling navigation if it is in progress.
+	SkipWaypoint(ctx context.Context, id primitive.ObjectID) error
+	// ReorderWaypoints moves the given waypoints to the front of the route in the given order.
+	ReorderWaypoints(ctx context.Context, ids []primitive.ObjectID) error
+}
+
+// GetRoute returns the navigation service's planned route.
+func GetRoute(ctx context.Context, svc Service) (Route, error) {
+	if r, ok := svc.(RouteIntrospector); ok {
+		return r.Route(ctx)
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{DoRoute: true})
+	if err != nil {
+		return Route{}, err
+	}
+	raw, ok := resp[DoRoute].(map[string]interface{})
+	if !ok {
+		return Route{}, ErrRouteUnsupported
+	}
+	return routeFromMap(raw)
+}
+
+// GetEvents returns the navigation service's events with a sequence number greater than since.
+func GetEvents(ctx context.Context, svc Service, since uint64) ([]Event, error) {
+	if r, ok := svc.(RouteIntrospector); ok {
+		return r.Events(ctx, since)
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{DoEvents: float64(since)})
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := resp[DoEvents].([]interface{})
+	if !ok {
+		return nil, ErrRouteUnsupported
+	}
+	return eventsFromList(raw)
+}
+
+// SkipWaypoint asks the navigation service to skip the given waypoint.
+func SkipWaypoint(ctx context.Context, svc Service, id primitive.ObjectID) error {
+	if r, ok := svc.(RouteIntrospector); ok {
+		return r.SkipWaypoint(ctx, id)
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{DoSkipWaypoint: id.Hex()})
+	if err != nil {
+		return err
+	}
+	if _, ok := resp[DoSkipWaypoint]; !ok {
+		return ErrRouteUnsupported
+	}
+	return nil
+}
+
+// ReorderWaypoints asks the navigation service to navigate to the given waypoints first, in order.
+func ReorderWaypoints(ctx context.Context, svc Service, ids []primitive.ObjectID) error {
+	if r, ok := svc.(RouteIntrospector); ok {
+		return r.ReorderWaypoints(ctx, ids)
+	}
+	hexIDs := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		hexIDs = append(hexIDs, id.Hex())
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{DoReorderWaypoints: hexIDs})
+	if err != nil {
+		return err
+	}
+	if _, ok := resp[DoReorderWaypoints]; !ok {
+		return ErrRouteUnsupported
+	}
+	return nil
+}
+
+// EventSubscriber is an optional interface implemented by navigation services that notify subscribers
+// as soon as new events are emitted.
+type EventSubscriber interface {
+	// SubscribeEvents returns a channel that receives a value whenever new events have been emitted,
+	// and a function that ends the subscription. Notifications are coalesced, so one value may stand
+	// for several events; the events themselves are read with Events.
+	SubscribeEvents() (<-chan struct{}, func())
+}
+
+// SubscribeEvents delivers the navigation service's events on the returned channel, in order, until ctx
+// is done, at which point the channel is closed. Services implementing EventSubscriber are read as soon
+// as they announce new events. Other services, including navigation clients since there is no
+// streaming RPC for events, are polled at the given interval.
+func SubscribeEvents(ctx context.Context, svc Service, interval time.Duration, logger logging.Logger) <-chan Event {
+	ch := make(chan Event, 64)
+	var since uint64
+	catchUp := func() bool {
+		events, err := GetEvents(ctx, svc, since)
+		if err != nil {
+			logger.CDebugf(ctx, "error getting navigation events: %v", err)
+			return ctx.Err() == nil
+		}
+		for _, ev := range events {
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return false
+			}
+			since = ev.Seq
+		}
+		return true
+	}
+
+	if sub, ok := svc.(EventSubscriber); ok {
+		notify, unsubscribe := sub.SubscribeEvents()
+		utils.PanicCapturingGo(func() {
+			defer close(ch)
+			defer unsubscribe()
+			// subscribe before reading the retained events so that none are missed in between.
+			for catchUp() {
+				select {
+				case <-ctx.Done():
+					return
+				case _, ok := <-notify:
+					if !ok {
+						return
+					}
+				}
+			}
+		})
+		return ch
+	}
+
+	utils.PanicCapturingGo(func() {
+		defer close(ch)
+		for utils.SelectContextOrWait(ctx, interval) {
+			if !catchUp() {
+				return
+			}
+		}
+	})
+	return ch
+}
+
+// HandleRouteCommand services the route introspection DoCommand keys using the given RouteIntrospector.
+// It returns false if cmd does not contain any of them so that it can be chained from a DoCommand
+// implementation.
+func HandleRouteCommand(
+	ctx context.Context,
+	r RouteIntrospector,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	resp := map[string]interface{}{}
+	handled := false
+
+	if rawID, ok := cmd[DoSkipWaypoint]; ok {
+		handled = true
+		id, err := objectIDFromInterface(rawID)
+		if err != nil {
+			return nil, true, err
+		}
+		if err := r.SkipWaypoint(ctx, id); err != nil {
+			return nil, true, err
+		}
+		resp[DoSkipWaypoint] = true
+	}
+	if rawIDs, ok := cmd[DoReorderWaypoints]; ok {
+		handled = true
+		list, ok := rawIDs.([]interface{})
+		if !ok {
+			return nil, true, errors.Errorf("expected %q to be a list of waypoint ids but got %T", DoReorderWaypoints, rawIDs)
+		}
+		ids := make([]primitive.ObjectID, 0, len(list))
+		for _, rawID := range list {
+			id, err := objectIDFromInterface(rawID)
+			if err != nil {
+				return nil, true, err
+			}
+			ids = append(ids, id)
+		}
+		if err := r.ReorderWaypoints(ctx, ids); err != nil {
+			return nil, true, err
+		}
+		resp[DoReorderWaypoints] = true
+	}
+	if _, ok := cmd[DoRoute]; ok {
+		handled = true
+		route, err := r.Route(ctx)
+		if err != nil {
+			return nil, true, err
+		}
+		resp[DoRoute] = routeToMap(route)
+	}
+	if rawSince, ok := cmd[DoEvents]; ok {
+		handled = true
+		since, _ := rawSince.(float64)
+		events, err := r.Events(ctx, uint64(since))
+		if err != nil {
+			return nil, true, err
+		}
+		resp[DoEvents] = eventsToList(events)
+	}
+	if !handled {
+		return nil, false, nil
+	}
+	return resp, true, nil
+}
+
+// EventLog retains the most recent navigation events for introspection and notifies subscribers of
+// new events.
+type EventLog struct {
+	mu          sync.Mutex
+	capacity    int
+	events      []Event
+	lastSeq     uint64
+	nextSubID   int
+	subscribers map[int]chan struct{}
+}
+
+// NewEventLog returns an EventLog retaining up to capacity events.
+func NewEventLog(capacity int) *EventLog {
+	return &EventLog{capacity: capacity, subscribers: map[int]chan struct{}{}}
+}
+
+// Publish records a new event.
+func (l *EventLog) Publish(typ EventType, id primitive.ObjectID, msg string) Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastSeq++
+	ev := Event{Seq: l.lastSeq, Type: typ, WaypointID: id, Time: time.Now(), Message: msg}
+	l.events = append(l.events, ev)
+	if len(l.events) > l.capacity {
+		l.events = l.events[len(l.events)-l.capacity:]
+	}
+	for _, sub := range l.subscribers {
+		select {
+		case sub <- struct{}{}:
+		default:
+			// a notification is already pending
+		}
+	}
+	return ev
+}
+
+// Subscribe returns a channel that receives a value whenever events are published after the call, and
+// a function that ends the subscription. Publishing never blocks on a subscriber: a notification that
+// is still pending covers any events published after it.
+func (l *EventLog) Subscribe() (<-chan struct{}, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := l.nextSubID
+	l.nextSubID++
+	sub := make(chan struct{}, 1)
+	l.subscribers[id] = sub
+
+	var once sync.Once
+	return sub, func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			delete(l.subscribers, id)
+			close(sub)
+		})
+	}
+}
+
+// Since returns all retained events with a sequence number greater than since.
+func (l *EventLog) Since(since uint64) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []Event
+	for _, ev := range l.events {
+		if ev.Seq > since {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
+
+func objectIDFromInterface(raw interface{}) (primitive.ObjectID, error) {
+	hex, ok := raw.(string)
+	if !ok {
+		return primitive.NilObjectID, errors.Errorf("expected waypoint id to be a string but got %T", raw)
+	}
+	return primitive.ObjectIDFromHex(hex)
+}
+
+func routeToMap(route Route) map[string]interface{} {
+	legs := make([]interface{}, 0, len(route.Legs))
+	for _, leg := range route.Legs {
+		legs = append(legs, map[string]interface{}{
+			"id":         leg.Waypoint.ID.Hex(),
+			"latitude":   leg.Waypoint.Lat,
+			"longitude":  leg.Waypoint.Long,
+			"distance_m": leg.DistanceM,
+			"eta_sec":    leg.ETA.Seconds(),
+		})
+	}
+	out := map[string]interface{}{"legs": legs}
+	if route.InProgress != nil {
+		out["in_progress"] = route.InProgress.Hex()
+	}
+	return out
+}
+
+func routeFromMap(m map[string]interface{}) (Route, error) {
+	var route Route
+	if raw, ok := m["in_progress"]; ok {
+		id, err := objectIDFromInterface(raw)
+		if err != nil {
+			return Route{}, err
+		}
+		route.InProgress = &id
+	}
+	legs, _ := m["legs"].([]interface{})
+	for _, rawLeg := range legs {
+		leg, ok := rawLeg.(map[string]interface{})
+		if !ok {
+			return Route{}, errors.Errorf("expected route leg to be a map but got %T", rawLeg)
+		}
+		id, err := objectIDFromInterface(leg["id"])
+		if err != nil {
+			return Route{}, err
+		}
+		lat, _ := leg["latitude"].(float64)
+		lng, _ := leg["longitude"].(float64)
+		dist, _ := leg["distance_m"].(float64)
+		eta, _ := leg["eta_sec"].(float64)
+		route.Legs = append(route.Legs, RouteLeg{
+			Waypoint:  Waypoint{ID: id, Lat: lat, Long: lng},
+			DistanceM: dist,
+			ETA:       time.Duration(eta * float64(time.Second)),
+		})
+	}
+	return route, nil
+}
+
+func eventsToList(events []Event) []interface{} {
+	out := make([]interface{}, 0, len(events))
+	for _, ev := range events {
+		out = append(out, map[string]interface{}{
+			"seq":         float64(ev.Seq),
+			"type":        string(ev.Type),
+			"waypoint_id": ev.WaypointID.Hex(),
+			"time":        ev.Time.Format(time.RFC3339Nano),
+			"message":     ev.Message,
+		})
+	}
+	return out
+}
+
+func eventsFromList(list []interface{}) ([]Event, error) {
+	out := make([]Event, 0, len(list))
+	for _, rawEv := range list {
+		ev, ok := rawEv.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("expected event to be a map but got %T", rawEv)
+		}
+		id, err := objectIDFromInterface(ev["waypoint_id"])
+		if err != nil {
+			return nil, err
+		}
+		rawTime, _ := ev["time"].(string)
+		ts, err := time.Parse(time.RFC3339Nano, rawTime)
+		if err != nil {
+			return nil, err
+		}
+		seq, _ := ev["seq"].(float64)
+		typ, _ := ev["type"].(string)
+		msg, _ := ev["message"].(string)
+		out = append(out, Event{Seq: uint64(seq), Type: EventType(typ), WaypointID: id, Time: ts, Message: msg})
+	}
+	return out, nil
+}
@@ -0,0 +1,236 @@
+package navigation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	geo "github.com/kellydunn/golang-geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/navigation"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+type fakeRouteIntrospector struct {
+	route    navigation.Route
+	events   *navigation.EventLog
+	skipped  []primitive.ObjectID
+	reorders [][]primitive.ObjectID
+}
+
+func (f *fakeRouteIntrospector) Route(ctx context.Context) (navigation.Route, error) {
+	return f.route, nil
+}
+
+func (f *fakeRouteIntrospector) Events(ctx context.Context, since uint64) ([]navigation.Event, error) {
+	return f.events.Since(since), nil
+}
+
+func (f *fakeRouteIntrospector) SkipWaypoint(ctx context.Context, id primitive.ObjectID) error {
+	f.skipped = append(f.skipped, id)
+	return nil
+}
+
+func (f *fakeRouteIntrospector) ReorderWaypoints(ctx context.Context, ids []primitive.ObjectID) error {
+	f.reorders = append(f.reorders, ids)
+	return nil
+}
+
+func TestRouteThroughDoCommand(t *testing.T) {
+	ctx := context.Background()
+	wp1 := navigation.Waypoint{ID: primitive.NewObjectID(), Lat: 1, Long: 2}
+	wp2 := navigation.Waypoint{ID: primitive.NewObjectID(), Lat: 3, Long: 4}
+	fake := &fakeRouteIntrospector{
+		route: navigation.Route{
+			InProgress: &wp1.ID,
+			Legs: []navigation.RouteLeg{
+				{Waypoint: wp1, DistanceM: 10, ETA: 2 * time.Second},
+				{Waypoint: wp2, DistanceM: 20, ETA: 6 * time.Second},
+			},
+		},
+		events: navigation.NewEventLog(2),
+	}
+	svc := inject.NewNavigationService("nav")
+	svc.DoCommandFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		if resp, handled, err := navigation.HandleRouteCommand(ctx, fake, cmd); handled {
+			return resp, err
+		}
+		return nil, resource.ErrDoUnimplemented
+	}
+
+	route, err := navigation.GetRoute(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, route, test.ShouldResemble, fake.route)
+
+	test.That(t, navigation.SkipWaypoint(ctx, svc, wp2.ID), test.ShouldBeNil)
+	test.That(t, fake.skipped, test.ShouldResemble, []primitive.ObjectID{wp2.ID})
+
+	test.That(t, navigation.ReorderWaypoints(ctx, svc, []primitive.ObjectID{wp2.ID, wp1.ID}), test.ShouldBeNil)
+	test.That(t, fake.reorders, test.ShouldResemble, [][]primitive.ObjectID{{wp2.ID, wp1.ID}})
+
+	fake.events.Publish(navigation.EventWaypointStarted, wp1.ID, "")
+	fake.events.Publish(navigation.EventReplanned, wp1.ID, "obstacle detected")
+	fake.events.Publish(navigation.EventWaypointReached, wp1.ID, "")
+
+	// the oldest event has been evicted from the log
+	events, err := navigation.GetEvents(ctx, svc, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(events), test.ShouldEqual, 2)
+	test.That(t, events[0].Seq, test.ShouldEqual, 2)
+	test.That(t, events[0].Type, test.ShouldEqual, navigation.EventReplanned)
+	test.That(t, events[0].Message, test.ShouldEqual, "obstacle detected")
+	test.That(t, events[1].Type, test.ShouldEqual, navigation.EventWaypointReached)
+	test.That(t, events[1].WaypointID, test.ShouldEqual, wp1.ID)
+
+	events, err = navigation.GetEvents(ctx, svc, 3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, events, test.ShouldBeEmpty)
+
+	t.Run("subscribe", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		ch := navigation.SubscribeEvents(cancelCtx, svc, time.Millisecond, logging.NewTestLogger(t))
+		ev := <-ch
+		test.That(t, ev.Seq, test.ShouldEqual, 2)
+		ev = <-ch
+		test.That(t, ev.Seq, test.ShouldEqual, 3)
+		fake.events.Publish(navigation.EventWaypointSkipped, wp2.ID, "")
+		ev = <-ch
+		test.That(t, ev.Type, test.ShouldEqual, navigation.EventWaypointSkipped)
+		cancel()
+		for range ch {
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		svc.DoCommandFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+			return map[string]interface{}{}, nil
+		}
+		_, err := navigation.GetRoute(ctx, svc)
+		test.That(t, err, test.ShouldBeError, navigation.ErrRouteUnsupported)
+		_, err = navigation.GetEvents(ctx, svc, 0)
+		test.That(t, err, test.ShouldBeError, navigation.ErrRouteUnsupported)
+	})
+}
+
+type pushingNavigationService struct {
+	*inject.NavigationService
+	*fakeRouteIntrospector
+}
+
+func (s pushingNavigationService) SubscribeEvents() (<-chan struct{}, func()) {
+	return s.events.Subscribe()
+}
+
+func TestSubscribeEventsPushed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	id := primitive.NewObjectID()
+	svc := pushingNavigationService{
+		NavigationService:     inject.NewNavigationService("nav"),
+		fakeRouteIntrospector: &fakeRouteIntrospector{events: navigation.NewEventLog(1000)},
+	}
+	svc.events.Publish(navigation.EventWaypointStarted, id, "")
+
+	// the polling interval is long enough that events can only arrive through notifications
+	ch := navigation.SubscribeEvents(ctx, svc, time.Hour, logging.NewTestLogger(t))
+	ev := <-ch
+	test.That(t, ev.Seq, test.ShouldEqual, 1)
+
+	// notifications coalesce without losing any events
+	for i := 0; i < 500; i++ {
+		svc.events.Publish(navigation.EventReplanned, id, "")
+	}
+	for seq := uint64(2); seq <= 501; seq++ {
+		select {
+		case ev = <-ch:
+			test.That(t, ev.Seq, test.ShouldEqual, seq)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for event %d", seq)
+		}
+	}
+	cancel()
+	for range ch {
+	}
+}
+
+func TestMemoryStoreReorderWaypoints(t *testing.T) {
+	ctx := context.Background()
+	store := navigation.NewMemoryNavigationStore()
+	wp1, err := store.AddWaypoint(ctx, geo.NewPoint(0, 0))
+	test.That(t, err, test.ShouldBeNil)
+	wp2, err := store.AddWaypoint(ctx, geo.NewPoint(1, 1))
+	test.That(t, err, test.ShouldBeNil)
+	wp3, err := store.AddWaypoint(ctx, geo.NewPoint(2, 2))
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, store.ReorderWaypoints(ctx, []primitive.ObjectID{wp3.ID}), test.ShouldBeNil)
+	wps, err := store.Waypoints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wps, test.ShouldResemble, []navigation.Waypoint{wp3, wp1, wp2})
+
+	next, err := store.NextWaypoint(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, next, test.ShouldResemble, wp3)
+
+	err = store.ReorderWaypoints(ctx, []primitive.ObjectID{primitive.NewObjectID()})
+	test.That(t, err, test.ShouldNotBeNil)
+	err = store.ReorderWaypoints(ctx, []primitive.ObjectID{wp1.ID, wp1.ID})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestMongoDBStoreReorderWaypoints(t *testing.T) {
+	ctx := context.Background()
+	dbName := navigation.MongoDBNavStoreDBName
+	navigation.MongoDBNavStoreDBName = "navigation_test"
+	defer func() {
+		navigation.MongoDBNavStoreDBName = dbName
+	}()
+	store, err := navigation.NewMongoDBNavigationStore(ctx, nil)
+	if err != nil {
+		t.Skipf("cannot run TestMongoDBStoreReorderWaypoints because no mongo: %s", err)
+		return
+	}
+	defer func() {
+		test.That(t, store.Close(context.Background()), test.ShouldBeNil)
+	}()
+	existing, err := store.Waypoints(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	for _, wp := range existing {
+		test.That(t, store.RemoveWaypoint(ctx, wp.ID), test.ShouldBeNil)
+	}
+
+	var added []navigation.Waypoint
+	for i := 0; i < 4; i++ {
+		wp, err := store.AddWaypoint(ctx, geo.NewPoint(float64(i), float64(i)))
+		test.That(t, err, test.ShouldBeNil)
+		added = append(added, wp)
+		defer func() {
+			test.That(t, store.RemoveWaypoint(context.Background(), wp.ID), test.ShouldBeNil)
+		}()
+	}
+	ids := func() []primitive.ObjectID {
+		wps, err := store.Waypoints(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		out := make([]primitive.ObjectID, 0, len(wps))
+		for _, wp := range wps {
+			out = append(out, wp.ID)
+		}
+		return out
+	}
+
+	test.That(t, store.ReorderWaypoints(ctx, []primitive.ObjectID{added[2].ID, added[1].ID}), test.ShouldBeNil)
+	test.That(t, ids(), test.ShouldResemble, []primitive.ObjectID{added[2].ID, added[1].ID, added[0].ID, added[3].ID})
+
+	// waypoints moved by the first reorder keep their place behind the ones moved by the second
+	test.That(t, store.ReorderWaypoints(ctx, []primitive.ObjectID{added[3].ID}), test.ShouldBeNil)
+	test.That(t, ids(), test.ShouldResemble, []primitive.ObjectID{added[3].ID, added[2].ID, added[1].ID, added[0].ID})
+
+	next, err := store.NextWaypoint(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, next.ID, test.ShouldEqual, added[3].ID)
+}
@@ -29,6 +29,14 @@ type NavStore interface {
 	Close(ctx context.Context) error
 }
 
+// WaypointReorderer is an optional interface implemented by a NavStore that can change the order in
+// which its waypoints are navigated to.
+type WaypointReorderer interface {
+	// ReorderWaypoints moves the given waypoints to the front of the store in the given order,
+	// leaving the relative order of all other waypoints unchanged.
+	ReorderWaypoints(ctx context.Context, ids []primitive.ObjectID) error
+}
+
 type storeType string
 
 const (
@@ -185,6 +193,40 @@ func (store *MemoryNavigationStore) WaypointVisited(ctx context.Context, id prim
 	return nil
 }
 
+// ReorderWaypoints moves the given waypoints to the front of the MemoryNavigationStore in the given
+// order, leaving the relative order of all other waypoints unchanged.
+func (store *MemoryNavigationStore) ReorderWaypoints(ctx context.Context, ids []primitive.ObjectID) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	byID := make(map[primitive.ObjectID]*Waypoint, len(store.waypoints))
+	for _, wp := range store.waypoints {
+		byID[wp.ID] = wp
+	}
+	newWps := make([]*Waypoint, 0, len(store.waypoints))
+	moved := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		wp, ok := byID[id]
+		if !ok {
+			return errors.Errorf("waypoint %s not found", id.Hex())
+		}
+		if moved[id] {
+			return errors.Errorf("waypoint %s listed more than once", id.Hex())
+		}
+		moved[id] = true
+		newWps = append(newWps, wp)
+	}
+	for _, wp := range store.waypoints {
+		if !moved[wp.ID] {
+			newWps = append(newWps, wp)
+		}
+	}
+	store.waypoints = newWps
+	return nil
+}
+
 // Close does nothing.
 func (store *MemoryNavigationStore) Close(ctx context.Context) error {
 	return nil
@@ -307,3 +349,45 @@ func (store *MongoDBNavigationStore) WaypointVisited(ctx context.Context, id pri
 	_, err := store.waypointsColl.UpdateOne(ctx, bson.D{{"_id", id}}, bson.D{{"$set", bson.D{{"visited", true}}}})
 	return err
 }
+
+// ReorderWaypoints moves the given waypoints to the front of the MongoDBNavigationStore in the given
+// order by assigning them order values above the current maximum, leaving the relative order of all
+// other waypoints unchanged. All updates are sent as a single ordered bulk write.
+func (store *MongoDBNavigationStore) ReorderWaypoints(ctx context.Context, ids []primitive.ObjectID) error {
+	if ids == nil {
+		// a nil slice is encoded as null, which $in rejects
+		ids = []primitive.ObjectID{}
+	}
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return errors.Errorf("waypoint %s listed more than once", id.Hex())
+		}
+		seen[id] = true
+	}
+	count, err := store.waypointsColl.CountDocuments(ctx, bson.D{{"_id", bson.D{{"$in", ids}}}})
+	if err != nil {
+		return err
+	}
+	if int(count) != len(ids) {
+		return errors.Errorf("%d of the waypoints to reorder were not found", len(ids)-int(count))
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var highest Waypoint
+	err = store.waypointsColl.FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{"order", -1}})).Decode(&highest)
+	if err != nil {
+		return err
+	}
+
+	models := make([]mongo.WriteModel, 0, len(ids))
+	for i, id := range ids {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{"_id", id}}).
+			SetUpdate(bson.D{{"$set", bson.D{{"order", highest.Order + len(ids) - i}}}}))
+	}
+	_, err = store.waypointsColl.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(true))
+	return err
+}
@@ -0,0 +1,447 @@
+// Package builtin implements an orchestration service that runs behavior trees and state machines
+// defined in its config.
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/orchestration"
+)
+
+const defaultLoopIntervalSec = 0.1
+
+func init() {
+	resource.RegisterService(orchestration.API, resource.DefaultServiceModel, resource.Registration[orchestration.Service, *Config]{
+		Constructor: NewBuiltIn,
+	})
+}
+
+// TreeConfig describes a behavior tree.
+type TreeConfig struct {
+	Name string     `json:"name"`
+	Root NodeConfig `json:"root"`
+	// RunOnStart starts the tree when the service is configured.
+	RunOnStart bool `json:"run_on_start,omitempty"`
+	// Loop runs the tree again, LoopIntervalSec after it finishes, until it is stopped.
+	Loop            bool    `json:"loop,omitempty"`
+	LoopIntervalSec float64 `json:"loop_interval_sec,omitempty"`
+}
+
+// StateConfig describes a state of a state machine, which runs a node and then moves to another state
+// depending on whether the node succeeded. A state machine finishes in a state with nowhere to move.
+type StateConfig struct {
+	Name      string     `json:"name"`
+	Node      NodeConfig `json:"node"`
+	OnSuccess string     `json:"on_success,omitempty"`
+	OnFailure string     `json:"on_failure,omitempty"`
+}
+
+// StateMachineConfig describes a state machine.
+type StateMachineConfig struct {
+	Name string `json:"name"`
+	// Initial is the state the machine starts in; it defaults to the first state.
+	Initial string        `json:"initial,omitempty"`
+	States  []StateConfig `json:"states"`
+	// RunOnStart starts the state machine when the service is configured.
+	RunOnStart bool `json:"run_on_start,omitempty"`
+	// LoopIntervalSec is how long a state waits before moving to itself, so that states that poll do
+	// not spin.
+	LoopIntervalSec float64 `json:"loop_interval_sec,omitempty"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	Trees         []TreeConfig         `json:"trees,omitempty"`
+	StateMachines []StateMachineConfig `json:"state_machines,omitempty"`
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if len(conf.Trees) == 0 && len(conf.StateMachines) == 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("at least one tree or state machine is required"))
+	}
+	deps := map[string]bool{}
+	names := map[string]bool{}
+	checkName := func(pPath, name string) error {
+		if name == "" {
+			return resource.NewConfigValidationFieldRequiredError(pPath, "name")
+		}
+		if names[name] {
+			return resource.NewConfigValidationError(pPath, errors.Errorf("more than one program is named %q", name))
+		}
+		names[name] = true
+		return nil
+	}
+	for i := range conf.Trees {
+		tree := &conf.Trees[i]
+		tPath := fmt.Sprintf("%s.trees.%d", path, i)
+		if err := checkName(tPath, tree.Name); err != nil {
+			return nil, nil, err
+		}
+		if tree.LoopIntervalSec < 0 {
+			return nil, nil, resource.NewConfigValidationError(tPath, errors.New("loop_interval_sec cannot be negative"))
+		}
+		if err := tree.Root.validate(tPath+".root", deps); err != nil {
+			return nil, nil, err
+		}
+	}
+	for i := range conf.StateMachines {
+		sm := &conf.StateMachines[i]
+		smPath := fmt.Sprintf("%s.state_machines.%d", path, i)
+		if err := checkName(smPath, sm.Name); err != nil {
+			return nil, nil, err
+		}
+		if len(sm.States) == 0 {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(smPath, "states")
+		}
+		if sm.LoopIntervalSec < 0 {
+			return nil, nil, resource.NewConfigValidationError(smPath, errors.New("loop_interval_sec cannot be negative"))
+		}
+		states := map[string]bool{}
+		for j, st := range sm.States {
+			if st.Name == "" {
+				return nil, nil, resource.NewConfigValidationFieldRequiredError(fmt.Sprintf("%s.states.%d", smPath, j), "name")
+			}
+			if states[st.Name] {
+				return nil, nil, resource.NewConfigValidationError(smPath, errors.Errorf("more than one state is named %q", st.Name))
+			}
+			states[st.Name] = true
+		}
+		if sm.Initial != "" && !states[sm.Initial] {
+			return nil, nil, resource.NewConfigValidationError(smPath, errors.Errorf("initial state %q does not exist", sm.Initial))
+		}
+		for j := range sm.States {
+			st := &sm.States[j]
+			stPath := fmt.Sprintf("%s.states.%d", smPath, j)
+			for _, next := range []string{st.OnSuccess, st.OnFailure} {
+				if next != "" && !states[next] {
+					return nil, nil, resource.NewConfigValidationError(stPath, errors.Errorf("state %q does not exist", next))
+				}
+			}
+			if err := st.Node.validate(stPath+".node", deps); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	depList := make([]string, 0, len(deps))
+	for name := range deps {
+		depList = append(depList, name)
+	}
+	sort.Strings(depList)
+	return depList, nil, nil
+}
+
+// A program is a behavior tree or state machine of the service.
+type program struct {
+	name string
+	// tree is set for behavior trees, and states for state machines.
+	tree       *TreeConfig
+	root       *node
+	machine    *StateMachineConfig
+	states     map[string]*node
+	runOnStart bool
+
+	mu      sync.Mutex
+	state   orchestration.ProgramState
+	nodes   []orchestration.NodeState
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+func (p *program) setNode(i int, update func(*orchestration.NodeState)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	update(&p.nodes[i])
+}
+
+type builtIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	programs map[string]*program
+	logger   logging.Logger
+
+	// closeCtx is canceled on Close to stop every program.
+	closeCtx   context.Context
+	cancelFunc context.CancelFunc
+}
+
+// NewBuiltIn returns a new orchestration service for the given robot.
+func NewBuiltIn(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (orchestration.Service, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	closeCtx, cancelFunc := context.WithCancel(context.Background())
+	svc := &builtIn{
+		Named:      conf.ResourceName().AsNamed(),
+		programs:   map[string]*program{},
+		logger:     logger,
+		closeCtx:   closeCtx,
+		cancelFunc: cancelFunc,
+	}
+	for i := range svcConfig.Trees {
+		tree := &svcConfig.Trees[i]
+		p := &program{name: tree.Name, tree: tree, runOnStart: tree.RunOnStart}
+		rootName := tree.Root.Name
+		if rootName == "" {
+			rootName = "root"
+		}
+		if p.root, err = newNode(&tree.Root, rootName, deps, p); err != nil {
+			cancelFunc()
+			return nil, err
+		}
+		svc.programs[p.name] = p
+	}
+	for i := range svcConfig.StateMachines {
+		sm := &svcConfig.StateMachines[i]
+		p := &program{name: sm.Name, machine: sm, states: map[string]*node{}, runOnStart: sm.RunOnStart}
+		for j := range sm.States {
+			st := &sm.States[j]
+			if p.states[st.Name], err = newNode(&st.Node, st.Name, deps, p); err != nil {
+				cancelFunc()
+				return nil, err
+			}
+		}
+		svc.programs[p.name] = p
+	}
+	for _, p := range svc.programs {
+		p.state = orchestration.ProgramState{Name: p.name}
+		if p.runOnStart {
+			if err := svc.start(p); err != nil {
+				cancelFunc()
+				return nil, err
+			}
+		}
+	}
+	return svc, nil
+}
+
+func (svc *builtIn) program(name string) (*program, error) {
+	p, ok := svc.programs[name]
+	if !ok {
+		return nil, errors.Errorf("no program named %q", name)
+	}
+	return p, nil
+}
+
+// Start starts a program in the background if it is not already running.
+func (svc *builtIn) Start(ctx context.Context, name string, extra map[string]interface{}) error {
+	p, err := svc.program(name)
+	if err != nil {
+		return err
+	}
+	if err := svc.closeCtx.Err(); err != nil {
+		return errors.Wrap(err, "orchestration service is closed")
+	}
+	return svc.start(p)
+}
+
+func (svc *builtIn) start(p *program) error {
+	p.mu.Lock()
+	if p.state.Running {
+		p.mu.Unlock()
+		return errors.Errorf("program %q is already running", p.name)
+	}
+	ctx, cancel := context.WithCancel(svc.closeCtx)
+	stopped := make(chan struct{})
+	p.state = orchestration.ProgramState{Name: p.name, Running: true, Started: time.Now()}
+	for i := range p.nodes {
+		p.nodes[i] = orchestration.NodeState{Path: p.nodes[i].Path, Type: p.nodes[i].Type, Status: orchestration.NodeIdle}
+	}
+	p.cancel = cancel
+	p.stopped = stopped
+	p.mu.Unlock()
+
+	goutils.PanicCapturingGo(func() {
+		defer close(stopped)
+		var err error
+		if p.tree != nil {
+			err = svc.runTree(ctx, p)
+		} else {
+			err = svc.runMachine(ctx, p)
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.finish(ctx, err)
+		p.state.Running = false
+		p.state.Finished = time.Now()
+		if err != nil && ctx.Err() == nil {
+			svc.logger.CWarnw(ctx, "program failed", "program", p.name, "error", err)
+		}
+	})
+	return nil
+}
+
+// finish records how a program, or a run of a looping tree, finished. It must be called with p.mu
+// held.
+func (p *program) finish(ctx context.Context, err error) {
+	switch {
+	case ctx.Err() != nil:
+		p.state.Result = orchestration.ResultStopped
+		p.state.Error = ""
+	case err != nil:
+		p.state.Result = orchestration.ResultFailed
+		p.state.Error = err.Error()
+	default:
+		p.state.Result = orchestration.ResultSucceeded
+		p.state.Error = ""
+	}
+}
+
+// runTree runs a behavior tree once, or until it is stopped if it loops.
+func (svc *builtIn) runTree(ctx context.Context, p *program) error {
+	interval := p.tree.LoopIntervalSec
+	if interval == 0 {
+		interval = defaultLoopIntervalSec
+	}
+	for {
+		p.mu.Lock()
+		p.state.Runs++
+		p.mu.Unlock()
+		err := p.root.run(ctx)
+		if !p.tree.Loop || ctx.Err() != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.finish(ctx, err)
+		p.mu.Unlock()
+		if !goutils.SelectContextOrWait(ctx, seconds(interval)) {
+			return ctx.Err()
+		}
+	}
+}
+
+// runMachine runs a state machine until it reaches a state with nowhere to move, or is stopped.
+func (svc *builtIn) runMachine(ctx context.Context, p *program) error {
+	interval := p.machine.LoopIntervalSec
+	if interval == 0 {
+		interval = defaultLoopIntervalSec
+	}
+	current := p.machine.Initial
+	if current == "" {
+		current = p.machine.States[0].Name
+	}
+	for {
+		p.mu.Lock()
+		p.state.State = current
+		p.mu.Unlock()
+		err := p.states[current].run(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var next string
+		for _, st := range p.machine.States {
+			if st.Name != current {
+				continue
+			}
+			next = st.OnSuccess
+			if err != nil {
+				next = st.OnFailure
+			}
+		}
+		if next == "" {
+			if err != nil {
+				return errors.Wrapf(err, "state %q failed", current)
+			}
+			return nil
+		}
+		if next == current && !goutils.SelectContextOrWait(ctx, seconds(interval)) {
+			return ctx.Err()
+		}
+		current = next
+	}
+}
+
+// Stop stops a program and waits for it to return.
+func (svc *builtIn) Stop(ctx context.Context, name string, extra map[string]interface{}) error {
+	p, err := svc.program(name)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	cancel, stopped := p.cancel, p.stopped
+	p.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetState returns the state of a program and of its nodes.
+func (svc *builtIn) GetState(ctx context.Context, name string, extra map[string]interface{}) (*orchestration.ProgramState, error) {
+	p, err := svc.program(name)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := p.state
+	st.Nodes = append([]orchestration.NodeState{}, p.nodes...)
+	return &st, nil
+}
+
+// ListPrograms returns the names of the configured trees and state machines.
+func (svc *builtIn) ListPrograms(ctx context.Context, extra map[string]interface{}) ([]string, error) {
+	names := make([]string, 0, len(svc.programs))
+	for name := range svc.programs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (svc *builtIn) Status(ctx context.Context) (map[string]interface{}, error) {
+	var running []string
+	for name, p := range svc.programs {
+		p.mu.Lock()
+		if p.state.Running {
+			running = append(running, name)
+		}
+		p.mu.Unlock()
+	}
+	return orchestration.StatusToMap(running), nil
+}
+
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	resp, handled, err := orchestration.HandleOrchestrationCommand(ctx, svc, cmd)
+	if !handled {
+		return nil, resource.ErrDoUnimplemented
+	}
+	return resp, err
+}
+
+// Close stops every program and waits for them to return.
+func (svc *builtIn) Close(ctx context.Context) error {
+	svc.cancelFunc()
+	for _, p := range svc.programs {
+		p.mu.Lock()
+		stopped := p.stopped
+		p.mu.Unlock()
+		if stopped != nil {
+			<-stopped
+		}
+	}
+	return nil
+}
@@ -0,0 +1,349 @@
+package builtin
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/generic"
+	"go.viam.com/rdk/services/orchestration"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// testRobot has a sensor whose level the test sets, a base, and a generic service that records the
+// commands it is sent and fails those with "fail" set.
+type testRobot struct {
+	mu       sync.Mutex
+	level    float64
+	commands []string
+	failures map[string]int
+	stops    int
+
+	deps resource.Dependencies
+}
+
+func newTestRobot() *testRobot {
+	r := &testRobot{failures: map[string]int{}}
+	s := inject.NewSensor("tank")
+	s.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return map[string]interface{}{"level": r.level, "pump": map[string]interface{}{"state": "on"}}, nil
+	}
+	b := inject.NewBase("base")
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.stops++
+		return nil
+	}
+	g := inject.NewGenericComponent("worker")
+	g.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		name, _ := cmd["name"].(string)
+		if _, block := cmd["block"]; block {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.commands = append(r.commands, name)
+		if r.failures[name] > 0 {
+			r.failures[name]--
+			return nil, errors.Errorf("%s failed", name)
+		}
+		return map[string]interface{}{}, nil
+	}
+	r.deps = resource.Dependencies{
+		sensor.Named("tank"):    s,
+		base.Named("base"):      b,
+		generic.Named("worker"): g,
+	}
+	return r
+}
+
+func (r *testRobot) sent() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.commands...)
+}
+
+func do(name string) NodeConfig {
+	return NodeConfig{Name: name, Type: nodeAction, Resource: "worker", Command: map[string]interface{}{"name": name}}
+}
+
+func newService(t *testing.T, conf *Config, deps resource.Dependencies) orchestration.Service {
+	t.Helper()
+	svc, err := NewBuiltIn(context.Background(), deps, resource.Config{
+		Name:                "orchestration",
+		API:                 orchestration.API,
+		Model:               resource.DefaultServiceModel,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { test.That(t, svc.Close(context.Background()), test.ShouldBeNil) })
+	return svc
+}
+
+// run starts a program and waits for it to finish.
+func run(t *testing.T, svc orchestration.Service, name string) *orchestration.ProgramState {
+	t.Helper()
+	ctx := context.Background()
+	test.That(t, svc.Start(ctx, name, nil), test.ShouldBeNil)
+	var st *orchestration.ProgramState
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		var err error
+		st, err = svc.GetState(ctx, name, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, st.Running, test.ShouldBeFalse)
+	})
+	return st
+}
+
+func nodeState(t *testing.T, st *orchestration.ProgramState, path string) orchestration.NodeState {
+	t.Helper()
+	for _, n := range st.Nodes {
+		if n.Path == path {
+			return n
+		}
+	}
+	t.Fatalf("no node %q", path)
+	return orchestration.NodeState{}
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{
+		Trees: []TreeConfig{{Name: "tree", Root: NodeConfig{Type: nodeSequence, Children: []NodeConfig{
+			{Type: nodeCondition, Resource: "tank", Reading: "level", Operator: ">", Value: 5},
+			do("a"),
+		}}}},
+		StateMachines: []StateMachineConfig{{Name: "machine", States: []StateConfig{
+			{Name: "start", Node: NodeConfig{Type: nodeAction, Resource: "base", Method: methodStop}, OnSuccess: "end"},
+			{Name: "end", Node: NodeConfig{Type: nodeWait, DurationSec: 1}},
+		}}},
+	}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"base", "tank", "worker"})
+
+	for _, bad := range []*Config{
+		{},
+		{Trees: []TreeConfig{{Name: "tree", Root: NodeConfig{Type: "loop"}}}},
+		{Trees: []TreeConfig{{Name: "tree", Root: NodeConfig{Type: nodeSequence}}}},
+		{Trees: []TreeConfig{{Name: "tree", Root: NodeConfig{Type: nodeAction, Resource: "worker"}}}},
+		{Trees: []TreeConfig{{Name: "tree", Root: NodeConfig{Type: nodeCondition, Resource: "tank", Reading: "level", Operator: "<", Value: "x"}}}},
+		{Trees: []TreeConfig{{Name: "tree", Root: do("a")}, {Name: "tree", Root: do("b")}}},
+		{Trees: []TreeConfig{{Name: "tree", Root: NodeConfig{Type: nodeSequence, Children: []NodeConfig{do("a"), do("a")}}}}},
+		{StateMachines: []StateMachineConfig{{Name: "machine", States: []StateConfig{{Name: "start", Node: do("a"), OnSuccess: "end"}}}}},
+	} {
+		_, _, err := bad.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	for _, tc := range []struct {
+		a    interface{}
+		op   string
+		b    interface{}
+		want bool
+	}{
+		{3, "==", 3.0, true},
+		{int64(3), "<", 3.5, true},
+		{3.5, ">=", 4, false},
+		{"on", "==", "on", true},
+		{true, "!=", false, true},
+	} {
+		got, err := compare(tc.a, tc.op, tc.b)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, got, test.ShouldEqual, tc.want)
+	}
+	_, err := compare("on", "<", 3)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestBehaviorTree(t *testing.T) {
+	r := newTestRobot()
+	svc := newService(t, &Config{Trees: []TreeConfig{{Name: "fill", Root: NodeConfig{Type: nodeSequence, Children: []NodeConfig{
+		{Name: "full", Type: nodeCondition, Resource: "tank", Reading: "level", Operator: ">=", Value: 5},
+		{Name: "pump_on", Type: nodeCondition, Resource: "tank", Reading: "pump.state", Operator: "==", Value: "on"},
+		{Name: "move", Type: nodeFallback, Children: []NodeConfig{do("primary"), do("backup")}},
+		{Type: nodeWait, DurationSec: 0.01},
+	}}}}}, r.deps)
+
+	// the tree stops at the first condition while the tank is not full.
+	st := run(t, svc, "fill")
+	test.That(t, st.Result, test.ShouldEqual, orchestration.ResultFailed)
+	test.That(t, st.Error, test.ShouldContainSubstring, `reading "level" is 0`)
+	test.That(t, nodeState(t, st, "root/full").Status, test.ShouldEqual, orchestration.NodeFailed)
+	test.That(t, nodeState(t, st, "root/move").Status, test.ShouldEqual, orchestration.NodeIdle)
+	test.That(t, r.sent(), test.ShouldBeEmpty)
+
+	// once it is, the fallback tries its backup when the primary fails.
+	r.mu.Lock()
+	r.level = 5
+	r.failures["primary"] = 1
+	r.mu.Unlock()
+	st = run(t, svc, "fill")
+	test.That(t, st.Result, test.ShouldEqual, orchestration.ResultSucceeded)
+	test.That(t, st.Error, test.ShouldBeEmpty)
+	test.That(t, r.sent(), test.ShouldResemble, []string{"primary", "backup"})
+	test.That(t, nodeState(t, st, "root/move/primary").Status, test.ShouldEqual, orchestration.NodeFailed)
+	test.That(t, nodeState(t, st, "root/move/primary").Error, test.ShouldContainSubstring, "primary failed")
+	test.That(t, nodeState(t, st, "root/move/backup").Status, test.ShouldEqual, orchestration.NodeSucceeded)
+	test.That(t, nodeState(t, st, "root/3").Status, test.ShouldEqual, orchestration.NodeSucceeded)
+	test.That(t, st.Finished.After(st.Started), test.ShouldBeTrue)
+
+	_, err := svc.GetState(context.Background(), "drain", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestRetriesAndTimeouts(t *testing.T) {
+	r := newTestRobot()
+	r.failures["flaky"] = 2
+	flaky := do("flaky")
+	flaky.Retries = 2
+	slow := NodeConfig{Name: "slow", Type: nodeAction, Resource: "worker", Command: map[string]interface{}{"block": true}, TimeoutSec: 0.05}
+	svc := newService(t, &Config{Trees: []TreeConfig{
+		{Name: "retry", Root: flaky},
+		{Name: "timeout", Root: slow},
+		{Name: "inverted", Root: NodeConfig{Type: nodeSequence, Children: []NodeConfig{
+			{Type: nodeAction, Resource: "worker", Command: map[string]interface{}{"block": true}, TimeoutSec: 0.01, Invert: true},
+			{Type: nodeAction, Resource: "base", Method: methodStop},
+		}}},
+		{Name: "parallel", Root: NodeConfig{Type: nodeParallel, SuccessThreshold: 1, Children: []NodeConfig{
+			{Name: "forever", Type: nodeWait, DurationSec: 60},
+			do("quick"),
+		}}},
+	}}, r.deps)
+
+	st := run(t, svc, "retry")
+	test.That(t, st.Result, test.ShouldEqual, orchestration.ResultSucceeded)
+	test.That(t, nodeState(t, st, "flaky").Attempts, test.ShouldEqual, 3)
+
+	st = run(t, svc, "timeout")
+	test.That(t, st.Result, test.ShouldEqual, orchestration.ResultFailed)
+	test.That(t, st.Error, test.ShouldContainSubstring, "timed out")
+
+	st = run(t, svc, "inverted")
+	test.That(t, st.Result, test.ShouldEqual, orchestration.ResultSucceeded)
+	test.That(t, r.stops, test.ShouldEqual, 1)
+
+	// the parallel succeeds once one child does, and stops the other.
+	st = run(t, svc, "parallel")
+	test.That(t, st.Result, test.ShouldEqual, orchestration.ResultSucceeded)
+	test.That(t, nodeState(t, st, "root/forever").Status, test.ShouldEqual, orchestration.NodeIdle)
+	test.That(t, nodeState(t, st, "root/quick").Status, test.ShouldEqual, orchestration.NodeSucceeded)
+}
+
+func TestLoopAndStop(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRobot()
+	svc := newService(t, &Config{Trees: []TreeConfig{
+		{Name: "patrol", Root: do("step"), RunOnStart: true, Loop: true, LoopIntervalSec: 0.01},
+	}}, r.deps)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		test.That(tb, len(r.sent()), test.ShouldBeGreaterThan, 2)
+	})
+	running, err := orchestration.GetRunning(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, running, test.ShouldResemble, []string{"patrol"})
+	test.That(t, svc.Start(ctx, "patrol", nil), test.ShouldNotBeNil)
+
+	test.That(t, svc.Stop(ctx, "patrol", nil), test.ShouldBeNil)
+	st, err := svc.GetState(ctx, "patrol", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Running, test.ShouldBeFalse)
+	test.That(t, st.Result, test.ShouldEqual, orchestration.ResultStopped)
+	test.That(t, st.Runs, test.ShouldBeGreaterThan, 2)
+	running, err = orchestration.GetRunning(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, running, test.ShouldBeEmpty)
+}
+
+func TestStateMachine(t *testing.T) {
+	r := newTestRobot()
+	svc := newService(t, &Config{StateMachines: []StateMachineConfig{{
+		Name:            "refill",
+		LoopIntervalSec: 0.01,
+		States: []StateConfig{
+			{
+				Name:      "waiting",
+				Node:      NodeConfig{Type: nodeCondition, Resource: "tank", Reading: "level", Operator: "<", Value: 1},
+				OnSuccess: "filling",
+				OnFailure: "waiting",
+			},
+			{Name: "filling", Node: do("fill"), OnSuccess: "done", OnFailure: "stopping"},
+			{Name: "stopping", Node: NodeConfig{Type: nodeAction, Resource: "base", Method: methodStop}},
+			{Name: "done", Node: do("report")},
+		},
+	}}}, r.deps)
+
+	// the machine polls while the tank is full.
+	r.level = 3
+	test.That(t, svc.Start(context.Background(), "refill", nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		st, err := svc.GetState(context.Background(), "refill", nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, st.State, test.ShouldEqual, "waiting")
+		test.That(tb, nodeState(t, st, "waiting").Attempts, test.ShouldBeGreaterThan, 2)
+	})
+	r.mu.Lock()
+	r.level = 0
+	r.mu.Unlock()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		st, err := svc.GetState(context.Background(), "refill", nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, st.Running, test.ShouldBeFalse)
+		test.That(tb, st.State, test.ShouldEqual, "done")
+		test.That(tb, st.Result, test.ShouldEqual, orchestration.ResultSucceeded)
+	})
+	test.That(t, r.sent(), test.ShouldResemble, []string{"fill", "report"})
+
+	// a failed fill stops the base, and ends the machine where it has nowhere to go.
+	r.failures["fill"] = 1
+	st := run(t, svc, "refill")
+	test.That(t, st.State, test.ShouldEqual, "stopping")
+	test.That(t, st.Result, test.ShouldEqual, orchestration.ResultSucceeded)
+	test.That(t, r.stops, test.ShouldEqual, 1)
+}
+
+func TestDoCommand(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRobot()
+	r.failures["a"] = 1
+	svc := newService(t, &Config{Trees: []TreeConfig{{Name: "a", Root: do("a")}, {Name: "b", Root: do("b")}}}, r.deps)
+
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{orchestration.DoListPrograms: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[orchestration.DoListPrograms], test.ShouldResemble, []interface{}{"a", "b"})
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{orchestration.DoStart: "a"})
+	test.That(t, err, test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		resp, err := svc.DoCommand(ctx, map[string]interface{}{orchestration.DoGetState: "a"})
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, resp["running"], test.ShouldBeFalse)
+		test.That(tb, resp["result"], test.ShouldEqual, "failed")
+		test.That(tb, resp["nodes"], test.ShouldHaveLength, 1)
+	})
+	_, err = svc.DoCommand(ctx, map[string]interface{}{orchestration.DoStop: "a"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{orchestration.DoStart: "c"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+}
@@ -0,0 +1,410 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/orchestration"
+)
+
+// The set of known node types.
+const (
+	// A sequence runs its children in order, and fails as soon as one fails.
+	nodeSequence = "sequence"
+	// A fallback runs its children in order until one succeeds, and fails if none do.
+	nodeFallback = "fallback"
+	// A parallel runs its children at once, and succeeds once success_threshold of them succeed.
+	nodeParallel = "parallel"
+	// An action calls a method of a resource.
+	nodeAction = "action"
+	// A condition succeeds if a reading of a resource compares to a value.
+	nodeCondition = "condition"
+	// A wait succeeds after duration_sec.
+	nodeWait = "wait"
+)
+
+// The set of known action methods.
+const (
+	methodDoCommand = "do_command"
+	methodStop      = "stop"
+)
+
+// NodeConfig describes a node of a program, and its children.
+type NodeConfig struct {
+	// Name identifies the node in the state of its program; it defaults to the index of the node
+	// among its parent's children.
+	Name     string       `json:"name,omitempty"`
+	Type     string       `json:"type"`
+	Children []NodeConfig `json:"children,omitempty"`
+	// SuccessThreshold is how many children of a parallel must succeed for it to succeed. It defaults
+	// to all of them.
+	SuccessThreshold int `json:"success_threshold,omitempty"`
+
+	// Resource is the resource an action or condition calls.
+	Resource string `json:"resource,omitempty"`
+	// Method is the method an action calls, which is do_command, with Command, if unspecified.
+	Method  string                 `json:"method,omitempty"`
+	Command map[string]interface{} `json:"command,omitempty"`
+	// Reading is the key of the reading a condition compares, with '.' separating the keys of nested
+	// readings. Operator is one of ==, !=, <, <=, >, or >=.
+	Reading  string      `json:"reading,omitempty"`
+	Operator string      `json:"operator,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+	// DurationSec is how long a wait waits.
+	DurationSec float64 `json:"duration_sec,omitempty"`
+
+	// TimeoutSec, if set, fails any node that runs for longer.
+	TimeoutSec float64 `json:"timeout_sec,omitempty"`
+	// Retries is how many more times any node is run when it fails, RetryDelaySec apart.
+	Retries       int     `json:"retries,omitempty"`
+	RetryDelaySec float64 `json:"retry_delay_sec,omitempty"`
+	// Invert makes any node succeed when it would fail, and fail when it would succeed.
+	Invert bool `json:"invert,omitempty"`
+}
+
+// validate checks the node and its children, and adds the resources they call to deps.
+func (conf *NodeConfig) validate(path string, deps map[string]bool) error {
+	if conf.TimeoutSec < 0 || conf.Retries < 0 || conf.RetryDelaySec < 0 {
+		return resource.NewConfigValidationError(path, errors.New("timeout_sec, retries, and retry_delay_sec cannot be negative"))
+	}
+	switch conf.Type {
+	case nodeSequence, nodeFallback, nodeParallel:
+		if len(conf.Children) == 0 {
+			return resource.NewConfigValidationError(path, errors.Errorf("a %s needs children", conf.Type))
+		}
+		if conf.SuccessThreshold < 0 || conf.SuccessThreshold > len(conf.Children) {
+			return resource.NewConfigValidationError(path, errors.New("success_threshold must be between 0 and the number of children"))
+		}
+		names := map[string]bool{}
+		for i := range conf.Children {
+			child := &conf.Children[i]
+			if child.Name != "" && names[child.Name] {
+				return resource.NewConfigValidationError(path, errors.Errorf("more than one child is named %q", child.Name))
+			}
+			names[child.Name] = true
+			if err := child.validate(fmt.Sprintf("%s.children.%d", path, i), deps); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nodeAction:
+		if conf.Resource == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "resource")
+		}
+		switch conf.Method {
+		case "", methodDoCommand:
+			if conf.Command == nil {
+				return resource.NewConfigValidationFieldRequiredError(path, "command")
+			}
+		case methodStop:
+		default:
+			return resource.NewConfigValidationError(path, errors.Errorf("unknown method %q", conf.Method))
+		}
+		deps[conf.Resource] = true
+		return nil
+	case nodeCondition:
+		if conf.Resource == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "resource")
+		}
+		if conf.Reading == "" {
+			return resource.NewConfigValidationFieldRequiredError(path, "reading")
+		}
+		switch conf.Operator {
+		case "==", "!=":
+		case "<", "<=", ">", ">=":
+			if _, ok := toFloat(conf.Value); !ok {
+				return resource.NewConfigValidationError(path, errors.Errorf("operator %s needs a number value", conf.Operator))
+			}
+		default:
+			return resource.NewConfigValidationError(path, errors.Errorf("unknown operator %q", conf.Operator))
+		}
+		deps[conf.Resource] = true
+		return nil
+	case nodeWait:
+		if conf.DurationSec <= 0 {
+			return resource.NewConfigValidationError(path, errors.New("duration_sec must be positive"))
+		}
+		return nil
+	case "":
+		return resource.NewConfigValidationFieldRequiredError(path, "type")
+	default:
+		return resource.NewConfigValidationError(path, errors.Errorf("unknown node type %q", conf.Type))
+	}
+}
+
+// A node is a node of a running program.
+type node struct {
+	conf     *NodeConfig
+	prog     *program
+	index    int
+	children []*node
+	res      resource.Resource
+}
+
+// newNode builds a node and its children, adding them to the nodes of prog in depth first order.
+func newNode(conf *NodeConfig, path string, deps resource.Dependencies, prog *program) (*node, error) {
+	n := &node{conf: conf, prog: prog, index: len(prog.nodes)}
+	prog.nodes = append(prog.nodes, orchestration.NodeState{Path: path, Type: conf.Type, Status: orchestration.NodeIdle})
+	if conf.Resource != "" {
+		res, err := deps.LookupByShortName(conf.Resource)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case conf.Type == nodeCondition:
+			if _, ok := res.(resource.Sensor); !ok {
+				return nil, errors.Errorf("condition %q needs readings, which %q does not have", path, conf.Resource)
+			}
+		case conf.Method == methodStop:
+			if _, ok := res.(resource.Actuator); !ok {
+				return nil, errors.Errorf("action %q stops %q, which cannot move", path, conf.Resource)
+			}
+		}
+		n.res = res
+	}
+	for i := range conf.Children {
+		name := conf.Children[i].Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		child, err := newNode(&conf.Children[i], path+"/"+name, deps, prog)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, child)
+	}
+	return n, nil
+}
+
+// run runs the node, retrying it if it fails, and returns why it failed, if it did.
+func (n *node) run(ctx context.Context) error {
+	var err error
+	for i := 0; i <= n.conf.Retries; i++ {
+		if i > 0 && !goutils.SelectContextOrWait(ctx, seconds(n.conf.RetryDelaySec)) {
+			return ctx.Err()
+		}
+		if err = n.attempt(ctx); err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (n *node) attempt(ctx context.Context) error {
+	n.prog.setNode(n.index, func(st *orchestration.NodeState) {
+		st.Status = orchestration.NodeRunning
+		st.Error = ""
+		st.Attempts++
+	})
+
+	runCtx := ctx
+	if n.conf.TimeoutSec > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, seconds(n.conf.TimeoutSec))
+		defer cancel()
+	}
+	err := n.exec(runCtx)
+	if err != nil && ctx.Err() == nil && runCtx.Err() != nil {
+		err = errors.Errorf("timed out after %vs", n.conf.TimeoutSec)
+	}
+	if n.conf.Invert && ctx.Err() == nil {
+		if err == nil {
+			err = errors.New("succeeded but is inverted")
+		} else {
+			err = nil
+		}
+	}
+
+	n.prog.setNode(n.index, func(st *orchestration.NodeState) {
+		switch {
+		case ctx.Err() != nil:
+			// the program was stopped, so the node did not finish.
+			st.Status = orchestration.NodeIdle
+		case err != nil:
+			st.Status = orchestration.NodeFailed
+			st.Error = err.Error()
+		default:
+			st.Status = orchestration.NodeSucceeded
+		}
+	})
+	return err
+}
+
+func (n *node) exec(ctx context.Context) error {
+	switch n.conf.Type {
+	case nodeSequence:
+		for _, child := range n.children {
+			if err := child.run(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nodeFallback:
+		var err error
+		for _, child := range n.children {
+			if err = child.run(ctx); err == nil || ctx.Err() != nil {
+				return err
+			}
+		}
+		return errors.Wrap(err, "every child failed, the last")
+	case nodeParallel:
+		return n.parallel(ctx)
+	case nodeAction:
+		if n.conf.Method == methodStop {
+			return n.res.(resource.Actuator).Stop(ctx, nil)
+		}
+		_, err := n.res.DoCommand(ctx, n.conf.Command)
+		return err
+	case nodeCondition:
+		return n.condition(ctx)
+	case nodeWait:
+		if !goutils.SelectContextOrWait(ctx, seconds(n.conf.DurationSec)) {
+			return ctx.Err()
+		}
+		return nil
+	default:
+		return errors.Errorf("unknown node type %q", n.conf.Type)
+	}
+}
+
+// parallel runs every child at once, and stops those still running once enough have succeeded, or
+// too many have failed, for the parallel to succeed.
+func (n *node) parallel(ctx context.Context) error {
+	threshold := n.conf.SuccessThreshold
+	if threshold == 0 {
+		threshold = len(n.children)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan error, len(n.children))
+	for _, child := range n.children {
+		goutils.PanicCapturingGo(func() {
+			results <- child.run(ctx)
+		})
+	}
+
+	var succeeded, failed int
+	var firstErr error
+	var decided error
+	done := false
+	for range n.children {
+		err := <-results
+		if done {
+			continue
+		}
+		if err == nil {
+			succeeded++
+		} else {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		switch {
+		case succeeded >= threshold:
+			done = true
+		case failed > len(n.children)-threshold:
+			done = true
+			decided = errors.Wrapf(firstErr, "%d of %d children failed, the first", failed, len(n.children))
+		default:
+			continue
+		}
+		// the children still running no longer matter, and are waited for so they do not outlive the node.
+		cancel()
+	}
+	if !done {
+		return ctx.Err()
+	}
+	return decided
+}
+
+func (n *node) condition(ctx context.Context) error {
+	readings, err := n.res.(resource.Sensor).Readings(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var v interface{} = readings
+	for _, key := range strings.Split(n.conf.Reading, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("%q has no reading %q", n.conf.Resource, n.conf.Reading)
+		}
+		if v, ok = m[key]; !ok {
+			return errors.Errorf("%q has no reading %q", n.conf.Resource, n.conf.Reading)
+		}
+	}
+	holds, err := compare(v, n.conf.Operator, n.conf.Value)
+	if err != nil {
+		return errors.Wrapf(err, "cannot compare reading %q", n.conf.Reading)
+	}
+	if !holds {
+		return errors.Errorf("reading %q is %v, which is not %s %v", n.conf.Reading, v, n.conf.Operator, n.conf.Value)
+	}
+	return nil
+}
+
+// compare returns whether a op b, comparing numbers of any type by value.
+func compare(a interface{}, op string, b interface{}) (bool, error) {
+	af, aNum := toFloat(a)
+	bf, bNum := toFloat(b)
+	switch op {
+	case "==", "!=":
+		var equal bool
+		if aNum && bNum {
+			equal = af == bf
+		} else {
+			equal = fmt.Sprint(a) == fmt.Sprint(b)
+		}
+		return equal == (op == "=="), nil
+	}
+	if !aNum || !bNum {
+		return false, errors.Errorf("%v is not a number", a)
+	}
+	switch op {
+	case "<":
+		return af < bf, nil
+	case "<=":
+		return af <= bf, nil
+	case ">":
+		return af > bf, nil
+	case ">=":
+		return af >= bf, nil
+	default:
+		return false, errors.Errorf("unknown operator %q", op)
+	}
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return math.NaN(), false
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,201 @@
+// Package orchestration defines a service that runs behavior trees and state machines, configured as
+// programs of nodes that call the APIs of resources, on the robot.
+package orchestration
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = "orchestration"
+
+// API is a variable that identifies the orchestration resource API.
+var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoStart        = "start"
+	DoStop         = "stop"
+	DoGetState     = "get_state"
+	DoListPrograms = "list_programs"
+)
+
+// Named is a helper for getting the named orchestration service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// Deprecated: FromRobot is a helper for getting the named orchestration service from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named orchestration service
+// from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Service, error) {
+	return resource.FromProvider[Service](provider, Named(name))
+}
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Service]{})
+}
+
+// A NodeStatus is the state of a node of a program.
+type NodeStatus string
+
+// The set of known node statuses.
+const (
+	// NodeIdle nodes have not run since their program started, or were stopped before finishing.
+	NodeIdle = NodeStatus("idle")
+	// NodeRunning nodes are running.
+	NodeRunning = NodeStatus("running")
+	// NodeSucceeded and NodeFailed nodes finished the last time they ran.
+	NodeSucceeded = NodeStatus("succeeded")
+	NodeFailed    = NodeStatus("failed")
+)
+
+// NodeState is the state of one node of a program.
+type NodeState struct {
+	// Path identifies the node by the names, or indices, of the nodes from the root of its program
+	// to it, separated by '/'. The nodes of a state machine are under the names of their states.
+	Path   string     `json:"path"`
+	Type   string     `json:"type"`
+	Status NodeStatus `json:"status"`
+	// Error is why the node last failed, if it did.
+	Error string `json:"error,omitempty"`
+	// Attempts is how many times the node has run since its program started.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// A Result is how a program finished.
+type Result string
+
+// The set of known results.
+const (
+	ResultSucceeded = Result("succeeded")
+	ResultFailed    = Result("failed")
+	ResultStopped   = Result("stopped")
+)
+
+// ProgramState is the state of a program, so that what a program is doing can be inspected while
+// it runs.
+type ProgramState struct {
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+	// Result is how the program last finished, if it has finished since it was last started.
+	Result Result `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// State is the current, or last, state of a state machine.
+	State string `json:"state,omitempty"`
+	// Runs is how many times a looping program has run since it was started.
+	Runs     int         `json:"runs,omitempty"`
+	Started  time.Time   `json:"started,omitzero"`
+	Finished time.Time   `json:"finished,omitzero"`
+	Nodes    []NodeState `json:"nodes"`
+}
+
+// A Service runs programs of nodes that call the APIs of resources.
+// Its resource Status reports the names of its running programs in the form produced by
+// StatusToMap.
+type Service interface {
+	resource.Resource
+	// Start starts running the named program in the background.
+	Start(ctx context.Context, program string, extra map[string]interface{}) error
+	// Stop stops the named program, returning once it has stopped.
+	Stop(ctx context.Context, program string, extra map[string]interface{}) error
+	// GetState returns the state of the named program.
+	GetState(ctx context.Context, program string, extra map[string]interface{}) (*ProgramState, error)
+	// ListPrograms returns the names of the configured programs.
+	ListPrograms(ctx context.Context, extra map[string]interface{}) ([]string, error)
+}
+
+// StatusToMap converts the names of the running programs into the map reported by an orchestration
+// service's resource Status.
+func StatusToMap(running []string) map[string]interface{} {
+	sorted := append([]string{}, running...)
+	sort.Strings(sorted)
+	list := make([]interface{}, 0, len(sorted))
+	for _, name := range sorted {
+		list = append(list, name)
+	}
+	return map[string]interface{}{"running": list}
+}
+
+// StatusFromMap converts a map produced by StatusToMap back into the names of the running programs.
+func StatusFromMap(m map[string]interface{}) ([]string, error) {
+	list, ok := m["running"].([]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected orchestration status running to be a list but got %T", m["running"])
+	}
+	running := make([]string, 0, len(list))
+	for _, v := range list {
+		name, ok := v.(string)
+		if !ok {
+			return nil, errors.Errorf("expected orchestration status running to be a list of strings but got %T", v)
+		}
+		running = append(running, name)
+	}
+	return running, nil
+}
+
+// GetRunning returns the names of the programs the service is running.
+func GetRunning(ctx context.Context, svc Service) ([]string, error) {
+	m, err := svc.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return StatusFromMap(m)
+}
+
+// HandleOrchestrationCommand services the orchestration DoCommand keys using the given Service, so
+// that programs can be run and inspected through DoCommand by callers that only have a generic
+// resource handle. It returns false if cmd does not contain any of them so that it can be chained
+// from a DoCommand implementation.
+//
+// DoStart, DoStop, and DoGetState take the name of a program, and DoGetState responds with a
+// ProgramState. DoListPrograms responds with a list of names.
+func HandleOrchestrationCommand(
+	ctx context.Context,
+	svc Service,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	extra, _ := cmd["extra"].(map[string]interface{})
+	switch {
+	case cmd[DoStart] != nil:
+		name, _ := cmd[DoStart].(string)
+		return map[string]interface{}{}, true, svc.Start(ctx, name, extra)
+	case cmd[DoStop] != nil:
+		name, _ := cmd[DoStop].(string)
+		return map[string]interface{}{}, true, svc.Stop(ctx, name, extra)
+	case cmd[DoGetState] != nil:
+		name, _ := cmd[DoGetState].(string)
+		st, err := svc.GetState(ctx, name, extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(st)
+		return resp, true, err
+	case cmd[DoListPrograms] != nil:
+		names, err := svc.ListPrograms(ctx, extra)
+		if err != nil {
+			return nil, true, err
+		}
+		list := make([]interface{}, 0, len(names))
+		for _, n := range names {
+			list = append(list, n)
+		}
+		return map[string]interface{}{DoListPrograms: list}, true, nil
+	default:
+		return nil, false, nil
+	}
+}
@@ -0,0 +1,7 @@
+// Package register registers all relevant orchestration models and also API specific functions
+package register
+
+import (
+	// for orchestration models.
+	_ "go.viam.com/rdk/services/orchestration/builtin"
+)
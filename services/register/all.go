@@ -4,11 +4,18 @@ package register
 import (
 	// register services.
 	_ "go.viam.com/rdk/services/baseremotecontrol/register"
+	_ "go.viam.com/rdk/services/calibration/register"
 	_ "go.viam.com/rdk/services/datamanager/register"
 	_ "go.viam.com/rdk/services/discovery/register"
+	_ "go.viam.com/rdk/services/docking/register"
 	_ "go.viam.com/rdk/services/generic/register"
+	_ "go.viam.com/rdk/services/grasp/register"
+	_ "go.viam.com/rdk/services/orchestration/register"
 	_ "go.viam.com/rdk/services/shell/register"
 	_ "go.viam.com/rdk/services/slam/register"
+	_ "go.viam.com/rdk/services/speech/register"
+	_ "go.viam.com/rdk/services/teaching/register"
+	_ "go.viam.com/rdk/services/teleop/register"
 	_ "go.viam.com/rdk/services/video/register"
 	_ "go.viam.com/rdk/services/vision/register"
 	_ "go.viam.com/rdk/services/worldstatestore/register"
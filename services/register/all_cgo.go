@@ -5,4 +5,5 @@ package register
 import (
 	// blank import registration pattern.
 	_ "go.viam.com/rdk/services/navigation/register"
+	_ "go.viam.com/rdk/services/safetyzone/register"
 )
@@ -0,0 +1,605 @@
+// Package builtin implements a safety zone service that checks point clouds, proximity sensor
+// readings and ranging array detections against zones and pauses, slows, or stops the robot's motion
+// while a zone is violated.
+package builtin
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/rangingarray"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/governor"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/safetyzone"
+)
+
+const (
+	defaultRateHz      = 10.
+	defaultSpeedScale  = 0.5
+	defaultClearDelay  = 500 * time.Millisecond
+	defaultMaxEvents   = 50
+	defaultDistanceKey = "distance"
+	defaultMinPoints   = 1
+)
+
+func init() {
+	resource.RegisterService(safetyzone.API, resource.DefaultServiceModel, resource.Registration[safetyzone.Service, *Config]{
+		Constructor: NewBuiltIn,
+	})
+}
+
+// BoxConfig is an axis aligned box in the frame of the camera observing it.
+type BoxConfig struct {
+	MinMM r3.Vector `json:"min_mm"`
+	MaxMM r3.Vector `json:"max_mm"`
+}
+
+func (b *BoxConfig) contains(p r3.Vector) bool {
+	return p.X >= b.MinMM.X && p.X <= b.MaxMM.X &&
+		p.Y >= b.MinMM.Y && p.Y <= b.MaxMM.Y &&
+		p.Z >= b.MinMM.Z && p.Z <= b.MaxMM.Z
+}
+
+// ZoneConfig describes a single zone. A zone is violated when enough points from one of its cameras
+// fall within RadiusMM of the camera or inside Box, when a detection of one of its ranging arrays
+// falls within RadiusMM of the array or inside Box, or when one of its proximity sensors reports a
+// distance within RadiusMM.
+type ZoneConfig struct {
+	Name   string            `json:"name"`
+	Action safetyzone.Action `json:"action"`
+	// SpeedScale is the fraction of normal speed permitted while a slow zone is violated.
+	SpeedScale float64    `json:"speed_scale,omitempty"`
+	RadiusMM   float64    `json:"radius_mm,omitempty"`
+	Box        *BoxConfig `json:"box,omitempty"`
+	Cameras    []string   `json:"cameras,omitempty"`
+	Sensors    []string   `json:"sensors,omitempty"`
+	// RangingArrays are watched in their own frames, as cameras are, with each detection taken to be
+	// along the center of its beam unless the array resolves its direction.
+	RangingArrays []string `json:"ranging_arrays,omitempty"`
+	// MinPoints is the number of points that must be inside the zone for it to be violated, which
+	// keeps a few noisy points from stopping the robot.
+	MinPoints int `json:"min_points,omitempty"`
+}
+
+func (z *ZoneConfig) speedScale() float64 {
+	switch z.Action {
+	case safetyzone.ActionStop:
+		return 0
+	case safetyzone.ActionSlow:
+		if z.SpeedScale == 0 {
+			return defaultSpeedScale
+		}
+		return z.SpeedScale
+	default:
+		return 1
+	}
+}
+
+// ArmSpeedLimits are the full speed joint limits of an arm, which a slow zone scales down.
+type ArmSpeedLimits struct {
+	MaxVelDegsPerSec  float64 `json:"max_vel_degs_per_sec"`
+	MaxAccDegsPerSec2 float64 `json:"max_acc_degs_per_sec2"`
+}
+
+// Config describes how to configure the service. While a stop zone is violated every configured base
+// and arm is stopped, the robot rejects motion commands to them from any caller, and the motion
+// service's plan executions are paused. While a slow zone is violated the robot scales down the speed
+// of every motion command sent to the configured bases and arms, including those sent by the motion
+// service. Full speed is restored and paused executions are resumed once every zone has been clear for
+// ClearDelayMS.
+type Config struct {
+	Zones []ZoneConfig `json:"zones"`
+	Bases []string     `json:"bases,omitempty"`
+	Arms  []string     `json:"arms,omitempty"`
+	// ArmSpeedLimits holds the full speed limits of arms by name. An arm can only be slowed when it has
+	// limits here or when the command moving it carries its own.
+	ArmSpeedLimits    map[string]ArmSpeedLimits `json:"arm_speed_limits,omitempty"`
+	MotionServiceName string                    `json:"motion_service,omitempty"`
+	// DistanceKey is the proximity sensor reading holding the distance to the nearest obstacle in meters.
+	DistanceKey  string  `json:"distance_key,omitempty"`
+	RateHz       float64 `json:"rate_hz,omitempty"`
+	ClearDelayMS int     `json:"clear_delay_ms,omitempty"`
+	MaxEvents    int     `json:"max_events,omitempty"`
+	// IgnoreSensorErrors keeps a zone from being treated as violated when one of its sources cannot be read.
+	IgnoreSensorErrors bool `json:"ignore_sensor_errors,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if len(conf.Zones) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "zones")
+	}
+	var deps []string
+	names := map[string]bool{}
+	for _, z := range conf.Zones {
+		if z.Name == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "zones.name")
+		}
+		if names[z.Name] {
+			return nil, nil, resource.NewConfigValidationError(path, errors.Errorf("duplicate zone %q", z.Name))
+		}
+		names[z.Name] = true
+		switch z.Action {
+		case safetyzone.ActionStop, safetyzone.ActionSlow:
+		default:
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("zone %q has unknown action %q", z.Name, z.Action))
+		}
+		if z.SpeedScale < 0 || z.SpeedScale >= 1 {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("zone %q speed_scale must be in [0, 1) but got %v", z.Name, z.SpeedScale))
+		}
+		if len(z.Cameras) == 0 && len(z.Sensors) == 0 && len(z.RangingArrays) == 0 {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("zone %q must watch at least one camera, sensor, or ranging array", z.Name))
+		}
+		if z.RadiusMM < 0 {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("zone %q radius_mm cannot be negative", z.Name))
+		}
+		if z.RadiusMM == 0 && (z.Box == nil || len(z.Sensors) != 0) {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("zone %q needs a radius_mm for proximity sensors or a radius_mm or box for cameras and ranging arrays",
+					z.Name))
+		}
+		if z.Box != nil && (z.Box.MinMM.X > z.Box.MaxMM.X || z.Box.MinMM.Y > z.Box.MaxMM.Y || z.Box.MinMM.Z > z.Box.MaxMM.Z) {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("zone %q box min_mm must not exceed max_mm", z.Name))
+		}
+		deps = append(deps, z.Cameras...)
+		deps = append(deps, z.Sensors...)
+		deps = append(deps, z.RangingArrays...)
+	}
+	if len(conf.Bases) == 0 && len(conf.Arms) == 0 && conf.MotionServiceName == "" {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("at least one base, arm, or motion service must be configured"))
+	}
+	if conf.RateHz < 0 || conf.ClearDelayMS < 0 || conf.MaxEvents < 0 {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("rate_hz, clear_delay_ms, and max_events cannot be negative"))
+	}
+	arms := map[string]bool{}
+	for _, name := range conf.Arms {
+		arms[name] = true
+	}
+	for name, limits := range conf.ArmSpeedLimits {
+		if !arms[name] {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("arm_speed_limits names arm %q which is not in arms", name))
+		}
+		if limits.MaxVelDegsPerSec <= 0 || limits.MaxAccDegsPerSec2 <= 0 {
+			return nil, nil, resource.NewConfigValidationError(path,
+				errors.Errorf("arm_speed_limits for arm %q must be positive", name))
+		}
+	}
+	deps = append(deps, conf.Bases...)
+	deps = append(deps, conf.Arms...)
+	if len(conf.Bases) != 0 || len(conf.Arms) != 0 {
+		deps = append(deps, governor.InternalServiceName.String())
+	}
+	if conf.MotionServiceName != "" {
+		deps = append(deps, resource.NewName(motion.API, conf.MotionServiceName).String())
+	}
+	return deps, nil, nil
+}
+
+// zoneState tracks whether a zone is violated, with hysteresis on clearing.
+type zoneState struct {
+	violated bool
+	lastSeen time.Time
+}
+
+// observation is the outcome of checking one zone against its sources.
+type observation struct {
+	violated bool
+	source   string
+	distance float64
+	err      error
+}
+
+type builtIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf          *Config
+	logger        logging.Logger
+	cameras       map[string]camera.Camera
+	sensors       map[string]sensor.Sensor
+	rangingArrays map[string]rangingarray.RangingArray
+	bases         map[string]base.Base
+	arms          map[string]arm.Arm
+	governor      governor.Service
+	motion        motion.Service
+	rate          float64
+	clearDelay    time.Duration
+	maxEvents     int
+	distanceKey   string
+
+	mu     sync.Mutex
+	zones  map[string]*zoneState
+	action safetyzone.Action
+	scale  float64
+	events []safetyzone.Event
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// NewBuiltIn returns a new safety zone service for the given robot.
+func NewBuiltIn(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (safetyzone.Service, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	svc := &builtIn{
+		Named:         conf.ResourceName().AsNamed(),
+		conf:          svcConfig,
+		logger:        logger,
+		cameras:       map[string]camera.Camera{},
+		sensors:       map[string]sensor.Sensor{},
+		rangingArrays: map[string]rangingarray.RangingArray{},
+		bases:         map[string]base.Base{},
+		arms:          map[string]arm.Arm{},
+		rate:          svcConfig.RateHz,
+		clearDelay:    time.Duration(svcConfig.ClearDelayMS) * time.Millisecond,
+		maxEvents:     svcConfig.MaxEvents,
+		distanceKey:   svcConfig.DistanceKey,
+		zones:         map[string]*zoneState{},
+		scale:         1,
+	}
+	if svc.rate == 0 {
+		svc.rate = defaultRateHz
+	}
+	if svc.clearDelay == 0 {
+		svc.clearDelay = defaultClearDelay
+	}
+	if svc.maxEvents == 0 {
+		svc.maxEvents = defaultMaxEvents
+	}
+	if svc.distanceKey == "" {
+		svc.distanceKey = defaultDistanceKey
+	}
+
+	for _, z := range svcConfig.Zones {
+		svc.zones[z.Name] = &zoneState{}
+		for _, name := range z.Cameras {
+			if svc.cameras[name], err = camera.FromProvider(deps, name); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range z.Sensors {
+			if svc.sensors[name], err = sensor.FromProvider(deps, name); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range z.RangingArrays {
+			if svc.rangingArrays[name], err = rangingarray.FromProvider(deps, name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, name := range svcConfig.Bases {
+		if svc.bases[name], err = base.FromProvider(deps, name); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range svcConfig.Arms {
+		if svc.arms[name], err = arm.FromProvider(deps, name); err != nil {
+			return nil, err
+		}
+	}
+	if len(svc.bases) != 0 || len(svc.arms) != 0 {
+		if svc.governor, err = governor.FromProvider(deps, governor.InternalServiceName); err != nil {
+			return nil, err
+		}
+	}
+	if svcConfig.MotionServiceName != "" {
+		if svc.motion, err = motion.FromProvider(deps, svcConfig.MotionServiceName); err != nil {
+			return nil, err
+		}
+	}
+
+	svc.cancelCtx, svc.cancel = context.WithCancel(context.Background())
+	svc.startMonitor()
+	return svc, nil
+}
+
+func (svc *builtIn) startMonitor() {
+	period := time.Duration(float64(time.Second) / svc.rate)
+	svc.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		for utils.SelectContextOrWait(svc.cancelCtx, period) {
+			if err := svc.tick(svc.cancelCtx, time.Now()); err != nil && svc.cancelCtx.Err() == nil {
+				svc.logger.CWarnw(svc.cancelCtx, "failed to enforce safety zones", "error", err)
+			}
+		}
+	}, svc.activeBackgroundWorkers.Done)
+}
+
+// tick checks every zone and enforces the most restrictive action among those violated.
+func (svc *builtIn) tick(ctx context.Context, now time.Time) error {
+	observations := make([]observation, len(svc.conf.Zones))
+	for i := range svc.conf.Zones {
+		observations[i] = svc.check(ctx, &svc.conf.Zones[i])
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	svc.mu.Lock()
+	action, scale := safetyzone.ActionNone, 1.
+	for i := range svc.conf.Zones {
+		z := &svc.conf.Zones[i]
+		svc.updateZoneLocked(ctx, z, observations[i], now)
+		if !svc.zones[z.Name].violated {
+			continue
+		}
+		if z.Action == safetyzone.ActionStop {
+			action = safetyzone.ActionStop
+		} else if action == safetyzone.ActionNone {
+			action = safetyzone.ActionSlow
+		}
+		scale = math.Min(scale, z.speedScale())
+	}
+	prevAction, prevScale := svc.action, svc.scale
+	svc.action, svc.scale = action, scale
+	svc.mu.Unlock()
+
+	// the governor keeps limits in force between changes, so the components and the motion service are
+	// only commanded when the state changes.
+	if action == prevAction && scale == prevScale {
+		return nil
+	}
+	svc.limit(action, scale)
+	var errs error
+	if action == safetyzone.ActionStop {
+		errs = svc.stopAll(ctx)
+	} else if prevAction == safetyzone.ActionStop {
+		// only resume what this service paused
+		errs = svc.controlMotion(ctx, map[string]interface{}{motion.DoResumeExecution: true})
+	}
+	return errs
+}
+
+// limit has the robot's governor apply the speed scale to every configured base and arm, or lifts the
+// limits when no zone is violated.
+func (svc *builtIn) limit(action safetyzone.Action, scale float64) {
+	if svc.governor == nil {
+		return
+	}
+	owner := svc.Name().String()
+	if action == safetyzone.ActionNone {
+		svc.governor.SetLimits(owner, nil)
+		return
+	}
+	reason := "safety zone " + string(action)
+	limits := map[resource.Name]governor.Limit{}
+	for name := range svc.bases {
+		limits[base.Named(name)] = governor.Limit{SpeedScale: scale, Reason: reason}
+	}
+	for name := range svc.arms {
+		armLimits := svc.conf.ArmSpeedLimits[name]
+		limits[arm.Named(name)] = governor.Limit{
+			SpeedScale:        scale,
+			Reason:            reason,
+			MaxVelDegsPerSec:  armLimits.MaxVelDegsPerSec,
+			MaxAccDegsPerSec2: armLimits.MaxAccDegsPerSec2,
+		}
+	}
+	svc.governor.SetLimits(owner, limits)
+}
+
+// updateZoneLocked applies an observation to a zone, recording an event if it becomes violated or clear.
+// A zone only clears once it has gone unobserved for the clear delay.
+func (svc *builtIn) updateZoneLocked(ctx context.Context, z *ZoneConfig, obs observation, now time.Time) {
+	st := svc.zones[z.Name]
+	if obs.violated {
+		st.lastSeen = now
+		if st.violated {
+			return
+		}
+		st.violated = true
+		if obs.err != nil {
+			svc.logger.CWarnw(ctx, "safety zone source could not be read; treating zone as violated",
+				"zone", z.Name, "source", obs.source, "action", z.Action, "error", obs.err)
+		} else {
+			svc.logger.CWarnw(ctx, "safety zone violated",
+				"zone", z.Name, "source", obs.source, "action", z.Action, "distance_mm", obs.distance)
+		}
+		svc.recordLocked(safetyzone.Event{
+			Time: now, Zone: z.Name, Source: obs.source, Action: z.Action, DistanceMM: obs.distance,
+		})
+		return
+	}
+	if !st.violated || now.Sub(st.lastSeen) < svc.clearDelay {
+		return
+	}
+	st.violated = false
+	svc.logger.CInfow(ctx, "safety zone clear", "zone", z.Name)
+	svc.recordLocked(safetyzone.Event{Time: now, Zone: z.Name, Action: z.Action, Cleared: true})
+}
+
+func (svc *builtIn) recordLocked(e safetyzone.Event) {
+	svc.events = append(svc.events, e)
+	if over := len(svc.events) - svc.maxEvents; over > 0 {
+		svc.events = append([]safetyzone.Event{}, svc.events[over:]...)
+	}
+}
+
+// check reads each of a zone's sources, returning the first violation found.
+func (svc *builtIn) check(ctx context.Context, z *ZoneConfig) observation {
+	for _, name := range z.Sensors {
+		readings, err := svc.sensors[name].Readings(ctx, nil)
+		if err == nil {
+			var meters float64
+			meters, err = distanceReading(readings, svc.distanceKey)
+			if err == nil {
+				if mm := meters * 1000; mm <= z.RadiusMM {
+					return observation{violated: true, source: name, distance: mm}
+				}
+				continue
+			}
+		}
+		if !svc.conf.IgnoreSensorErrors {
+			return observation{violated: true, source: name, err: err}
+		}
+	}
+	for _, name := range z.RangingArrays {
+		closest, found, err := checkRangingArray(ctx, svc.rangingArrays[name], z)
+		if err != nil {
+			if !svc.conf.IgnoreSensorErrors {
+				return observation{violated: true, source: name, err: err}
+			}
+			continue
+		}
+		if found {
+			return observation{violated: true, source: name, distance: closest}
+		}
+	}
+	minPoints := z.MinPoints
+	if minPoints == 0 {
+		minPoints = defaultMinPoints
+	}
+	for _, name := range z.Cameras {
+		pc, err := svc.cameras[name].NextPointCloud(ctx, nil)
+		if err != nil {
+			if !svc.conf.IgnoreSensorErrors {
+				return observation{violated: true, source: name, err: err}
+			}
+			continue
+		}
+		if count, closest := pointsInZone(pc, z); count >= minPoints {
+			return observation{violated: true, source: name, distance: closest}
+		}
+	}
+	return observation{}
+}
+
+// distanceReading returns the distance in meters reported under key in a proximity sensor's readings.
+func distanceReading(readings map[string]interface{}, key string) (float64, error) {
+	raw, ok := readings[key]
+	if !ok {
+		return 0, errors.Errorf("readings do not contain %q", key)
+	}
+	d, ok := raw.(float64)
+	if !ok {
+		return 0, errors.Errorf("expected reading %q to be a float64 but got %T", key, raw)
+	}
+	return d, nil
+}
+
+// checkRangingArray reads a ranging array, returning the distance to the closest of its detections
+// within the zone and whether there are any.
+func checkRangingArray(ctx context.Context, a rangingarray.RangingArray, z *ZoneConfig) (float64, bool, error) {
+	props, err := a.Properties(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	ranges, err := a.Ranges(ctx, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	closest, found := detectionsInZone(props, ranges, z)
+	return closest, found, nil
+}
+
+// detectionsInZone returns the distance from the array to the closest of its detections within the
+// zone, and false if there are none.
+func detectionsInZone(props rangingarray.Properties, ranges *rangingarray.Ranges, z *ZoneConfig) (float64, bool) {
+	closest, found := math.Inf(1), false
+	for _, det := range ranges.Detections {
+		if det.Beam < 0 || det.Beam >= len(props.Beams) {
+			continue
+		}
+		p := det.Point(props.Beams[det.Beam])
+		d := p.Norm()
+		if (z.RadiusMM > 0 && d <= z.RadiusMM) || (z.Box != nil && z.Box.contains(p)) {
+			closest, found = math.Min(closest, d), true
+		}
+	}
+	return closest, found
+}
+
+// pointsInZone returns how many points of the cloud lie within the zone and the distance to the closest of them.
+func pointsInZone(pc pointcloud.PointCloud, z *ZoneConfig) (int, float64) {
+	count, closest := 0, math.Inf(1)
+	pc.Iterate(0, 0, func(p r3.Vector, _ pointcloud.Data) bool {
+		d := p.Norm()
+		if (z.RadiusMM > 0 && d <= z.RadiusMM) || (z.Box != nil && z.Box.contains(p)) {
+			count++
+			closest = math.Min(closest, d)
+		}
+		return true
+	})
+	return count, closest
+}
+
+// stopAll stops every configured actuator and pauses the motion service's executions. The governor
+// rejects any motion commanded while the stop zone stays violated.
+func (svc *builtIn) stopAll(ctx context.Context) error {
+	errs := svc.controlMotion(ctx, map[string]interface{}{motion.DoPauseExecution: true})
+	for _, b := range svc.bases {
+		errs = multierr.Combine(errs, b.Stop(ctx, nil))
+	}
+	for _, a := range svc.arms {
+		errs = multierr.Combine(errs, a.Stop(ctx, nil))
+	}
+	return errs
+}
+
+func (svc *builtIn) controlMotion(ctx context.Context, cmd map[string]interface{}) error {
+	if svc.motion == nil {
+		return nil
+	}
+	_, err := svc.motion.DoCommand(ctx, cmd)
+	return err
+}
+
+func (svc *builtIn) Status(ctx context.Context) (map[string]interface{}, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	var violated []string
+	for name, st := range svc.zones {
+		if st.violated {
+			violated = append(violated, name)
+		}
+	}
+	sort.Strings(violated)
+	return safetyzone.StatusToMap(safetyzone.Status{
+		Action:     svc.action,
+		SpeedScale: svc.scale,
+		Violated:   violated,
+		Events:     append([]safetyzone.Event{}, svc.events...),
+	}), nil
+}
+
+func (svc *builtIn) Close(ctx context.Context) error {
+	svc.cancel()
+	svc.activeBackgroundWorkers.Wait()
+	svc.limit(safetyzone.ActionNone, 1)
+	if svc.action == safetyzone.ActionStop {
+		return svc.controlMotion(ctx, map[string]interface{}{motion.DoResumeExecution: true})
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/rangingarray"
+	fakerangingarray "go.viam.com/rdk/components/rangingarray/fake"
+	"go.viam.com/rdk/components/sensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/governor"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/safetyzone"
+	rtestutils "go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/testutils/inject"
+	injectmotion "go.viam.com/rdk/testutils/inject/motion"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{
+		Zones: []ZoneConfig{
+			{Name: "near", Action: safetyzone.ActionStop, RadiusMM: 300, Sensors: []string{"prox"}},
+			{
+				Name:    "front",
+				Action:  safetyzone.ActionSlow,
+				Box:     &BoxConfig{MinMM: r3.Vector{X: -500, Y: -500}, MaxMM: r3.Vector{X: 500, Y: 500, Z: 2000}},
+				Cameras: []string{"lidar"},
+			},
+			{Name: "ring", Action: safetyzone.ActionStop, RadiusMM: 500, RangingArrays: []string{"sonar"}},
+		},
+		Bases:             []string{"base"},
+		MotionServiceName: "builtin",
+	}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	rtestutils.VerifySameElements(t, deps, []string{
+		"prox", "lidar", "sonar", "base", motion.Named("builtin").String(), governor.InternalServiceName.String(),
+	})
+
+	for _, z := range []ZoneConfig{
+		{Action: safetyzone.ActionStop, RadiusMM: 1, Sensors: []string{"prox"}},
+		{Name: "z", Action: "swerve", RadiusMM: 1, Sensors: []string{"prox"}},
+		{Name: "z", Action: safetyzone.ActionSlow, SpeedScale: 1, RadiusMM: 1, Sensors: []string{"prox"}},
+		{Name: "z", Action: safetyzone.ActionStop, RadiusMM: 1},
+		{Name: "z", Action: safetyzone.ActionStop, Sensors: []string{"prox"}, Box: &BoxConfig{}},
+		{Name: "z", Action: safetyzone.ActionStop, Cameras: []string{"lidar"}, Box: &BoxConfig{MinMM: r3.Vector{X: 1}}},
+		{Name: "z", Action: safetyzone.ActionStop, RangingArrays: []string{"sonar"}},
+	} {
+		_, _, err := (&Config{Zones: []ZoneConfig{z}, Bases: []string{"base"}}).Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+	_, _, err = (&Config{Zones: conf.Zones}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{
+		Zones:          conf.Zones,
+		Bases:          []string{"base"},
+		ArmSpeedLimits: map[string]ArmSpeedLimits{"arm": {MaxVelDegsPerSec: 10, MaxAccDegsPerSec2: 10}},
+	}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{Zones: []ZoneConfig{conf.Zones[0], conf.Zones[0]}, Bases: []string{"base"}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestPointsInZone(t *testing.T) {
+	pc := pointcloud.NewBasicEmpty()
+	for _, p := range []r3.Vector{{X: 100}, {Y: 250}, {Z: 1500}, {X: 900, Z: 900}} {
+		test.That(t, pc.Set(p, nil), test.ShouldBeNil)
+	}
+	count, closest := pointsInZone(pc, &ZoneConfig{RadiusMM: 300})
+	test.That(t, count, test.ShouldEqual, 2)
+	test.That(t, closest, test.ShouldAlmostEqual, 100)
+
+	box := &BoxConfig{MinMM: r3.Vector{X: -10, Y: -10, Z: 1000}, MaxMM: r3.Vector{X: 10, Y: 10, Z: 2000}}
+	count, closest = pointsInZone(pc, &ZoneConfig{Box: box})
+	test.That(t, count, test.ShouldEqual, 1)
+	test.That(t, closest, test.ShouldAlmostEqual, 1500)
+}
+
+func TestDetectionsInZone(t *testing.T) {
+	props := rangingarray.Properties{Beams: []rangingarray.Beam{
+		{PositionMM: r3.Vector{X: 100}},
+		{PositionMM: r3.Vector{Y: 100}, Azimuth: math.Pi / 2},
+	}}
+	ranges := &rangingarray.Ranges{Detections: []rangingarray.Detection{
+		{Beam: 0, DistanceMM: 1400},
+		{Beam: 1, DistanceMM: 200},
+		// detections of unknown beams are ignored.
+		{Beam: 2, DistanceMM: 10},
+	}}
+	closest, found := detectionsInZone(props, ranges, &ZoneConfig{RadiusMM: 500})
+	test.That(t, found, test.ShouldBeTrue)
+	test.That(t, closest, test.ShouldAlmostEqual, 300)
+
+	box := &BoxConfig{MinMM: r3.Vector{X: 1000, Y: -100, Z: -100}, MaxMM: r3.Vector{X: 2000, Y: 100, Z: 100}}
+	closest, found = detectionsInZone(props, ranges, &ZoneConfig{Box: box})
+	test.That(t, found, test.ShouldBeTrue)
+	test.That(t, closest, test.ShouldAlmostEqual, 1500)
+
+	_, found = detectionsInZone(props, ranges, &ZoneConfig{RadiusMM: 250})
+	test.That(t, found, test.ShouldBeFalse)
+}
+
+func TestRangingArrayZone(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	// the walls are 400mm from the side beams, which are 100mm from the center of the ring.
+	sonar, err := fakerangingarray.NewRangingArray(ctx, nil, resource.Config{
+		Name:                "sonar",
+		API:                 rangingarray.API,
+		ConvertedAttributes: &fakerangingarray.Config{RoomLengthMM: 2000, RoomWidthMM: 1000, NumBeams: 4, RingRadiusMM: 100},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	rr := newRecordingRobot()
+	rr.deps[rangingarray.Named("sonar")] = sonar
+
+	res, err := NewBuiltIn(ctx, rr.deps, resource.Config{
+		Name: "safety",
+		API:  safetyzone.API,
+		ConvertedAttributes: &Config{
+			Zones: []ZoneConfig{
+				{Name: "far", Action: safetyzone.ActionSlow, RadiusMM: 450, RangingArrays: []string{"sonar"}},
+				{Name: "near", Action: safetyzone.ActionStop, RadiusMM: 600, RangingArrays: []string{"sonar"}},
+			},
+			Bases:  []string{"base"},
+			RateHz: 1e-6,
+		},
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, res.Close(ctx), test.ShouldBeNil)
+	}()
+	svc := res.(*builtIn)
+	test.That(t, svc.tick(ctx, time.Now()), test.ShouldBeNil)
+	st, err := safetyzone.GetStatus(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Violated, test.ShouldResemble, []string{"near"})
+	test.That(t, st.Events, test.ShouldHaveLength, 1)
+	test.That(t, st.Events[0].Source, test.ShouldEqual, "sonar")
+	test.That(t, st.Events[0].DistanceMM, test.ShouldAlmostEqual, 500)
+}
+
+type recordingRobot struct {
+	mu         sync.Mutex
+	distance   float64
+	readErr    error
+	points     []r3.Vector
+	baseStops  int
+	armStops   int
+	velocity   r3.Vector
+	motionCmds []map[string]interface{}
+	governor   governor.Service
+	deps       resource.Dependencies
+}
+
+func newRecordingRobot() *recordingRobot {
+	rr := &recordingRobot{distance: 5, governor: governor.New()}
+
+	prox := inject.NewSensor("prox")
+	prox.ReadingsFunc = func(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		return map[string]interface{}{"distance": rr.distance}, rr.readErr
+	}
+	lidar := inject.NewCamera("lidar")
+	lidar.NextPointCloudFunc = func(ctx context.Context, extra map[string]interface{}) (pointcloud.PointCloud, error) {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		pc := pointcloud.NewBasicEmpty()
+		for _, p := range rr.points {
+			if err := pc.Set(p, nil); err != nil {
+				return nil, err
+			}
+		}
+		return pc, nil
+	}
+	b := inject.NewBase("base")
+	b.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		rr.velocity = linear
+		return nil
+	}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		rr.baseStops++
+		return nil
+	}
+	a := inject.NewArm("arm")
+	a.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		rr.armStops++
+		return nil
+	}
+	ms := injectmotion.NewMotionService("builtin")
+	ms.DoCommandFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		rr.motionCmds = append(rr.motionCmds, cmd)
+		return map[string]interface{}{}, nil
+	}
+
+	rr.deps = resource.Dependencies{
+		sensor.Named("prox"):         prox,
+		camera.Named("lidar"):        lidar,
+		base.Named("base"):           b,
+		arm.Named("arm"):             a,
+		motion.Named("builtin"):      ms,
+		governor.InternalServiceName: rr.governor,
+	}
+	return rr
+}
+
+// lastMotionCmd returns and forgets the most recent command sent to the motion service.
+func (rr *recordingRobot) lastMotionCmd() map[string]interface{} {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if len(rr.motionCmds) == 0 {
+		return nil
+	}
+	cmd := rr.motionCmds[len(rr.motionCmds)-1]
+	rr.motionCmds = nil
+	return cmd
+}
+
+func TestSafetyZone(t *testing.T) {
+	ctx := context.Background()
+	rr := newRecordingRobot()
+	conf := &Config{
+		Zones: []ZoneConfig{
+			{Name: "slow", Action: safetyzone.ActionSlow, SpeedScale: 0.25, RadiusMM: 1000, Sensors: []string{"prox"}},
+			{
+				Name:      "stop",
+				Action:    safetyzone.ActionStop,
+				Box:       &BoxConfig{MinMM: r3.Vector{X: -200, Y: -200}, MaxMM: r3.Vector{X: 200, Y: 200, Z: 500}},
+				Cameras:   []string{"lidar"},
+				MinPoints: 2,
+			},
+		},
+		Bases:             []string{"base"},
+		Arms:              []string{"arm"},
+		MotionServiceName: "builtin",
+		// keep the background monitor from running so that the test drives every tick
+		RateHz: 1e-6,
+	}
+	res, err := NewBuiltIn(ctx, rr.deps, resource.Config{
+		Name:                "safety",
+		API:                 safetyzone.API,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, res.Close(ctx), test.ShouldBeNil)
+	}()
+	svc := res.(*builtIn)
+	status := func() safetyzone.Status {
+		st, err := safetyzone.GetStatus(ctx, svc)
+		test.That(t, err, test.ShouldBeNil)
+		return st
+	}
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// the base as any caller on the robot sees it
+	governed := governor.Wrap(rr.governor, base.Named("base"), rr.deps[base.Named("base")]).(base.Base)
+	drive := func() (r3.Vector, error) {
+		err := governed.SetVelocity(ctx, r3.Vector{Y: 400}, r3.Vector{}, nil)
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		v := rr.velocity
+		rr.velocity = r3.Vector{}
+		return v, err
+	}
+
+	// nothing nearby
+	test.That(t, svc.tick(ctx, at(0)), test.ShouldBeNil)
+	test.That(t, status().Action, test.ShouldEqual, safetyzone.ActionNone)
+	test.That(t, rr.lastMotionCmd(), test.ShouldBeNil)
+
+	// an obstacle within the slow zone scales motion
+	rr.mu.Lock()
+	rr.distance = 0.5
+	rr.mu.Unlock()
+	test.That(t, svc.tick(ctx, at(100)), test.ShouldBeNil)
+	st := status()
+	test.That(t, st.Action, test.ShouldEqual, safetyzone.ActionSlow)
+	test.That(t, st.SpeedScale, test.ShouldEqual, 0.25)
+	test.That(t, st.Violated, test.ShouldResemble, []string{"slow"})
+	test.That(t, st.Events, test.ShouldHaveLength, 1)
+	test.That(t, st.Events[0].Source, test.ShouldEqual, "prox")
+	test.That(t, st.Events[0].DistanceMM, test.ShouldEqual, 500)
+	// slowing is enforced by the governor, so the motion service is left alone
+	test.That(t, rr.lastMotionCmd(), test.ShouldBeNil)
+	v, err := drive()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v.Y, test.ShouldEqual, 100)
+
+	// a single noisy point is not enough to stop
+	rr.mu.Lock()
+	rr.points = []r3.Vector{{Z: 300}}
+	rr.mu.Unlock()
+	test.That(t, svc.tick(ctx, at(200)), test.ShouldBeNil)
+	test.That(t, status().Action, test.ShouldEqual, safetyzone.ActionSlow)
+
+	// the stop zone stops everything and pauses executions
+	rr.mu.Lock()
+	rr.points = append(rr.points, r3.Vector{X: 50, Z: 200})
+	rr.mu.Unlock()
+	test.That(t, svc.tick(ctx, at(300)), test.ShouldBeNil)
+	st = status()
+	test.That(t, st.Action, test.ShouldEqual, safetyzone.ActionStop)
+	test.That(t, st.SpeedScale, test.ShouldEqual, 0)
+	test.That(t, st.Violated, test.ShouldResemble, []string{"slow", "stop"})
+	test.That(t, rr.lastMotionCmd(), test.ShouldResemble, map[string]interface{}{motion.DoPauseExecution: true})
+	_, err = drive()
+	test.That(t, err, test.ShouldWrap, governor.ErrBlocked)
+	// commands are only sent when the state changes
+	test.That(t, svc.tick(ctx, at(400)), test.ShouldBeNil)
+	test.That(t, rr.lastMotionCmd(), test.ShouldBeNil)
+	rr.mu.Lock()
+	test.That(t, rr.baseStops, test.ShouldEqual, 1)
+	test.That(t, rr.armStops, test.ShouldEqual, 1)
+	rr.points = nil
+	rr.mu.Unlock()
+
+	// the zone must stay clear for the clear delay before motion resumes
+	test.That(t, svc.tick(ctx, at(500)), test.ShouldBeNil)
+	test.That(t, status().Action, test.ShouldEqual, safetyzone.ActionStop)
+	test.That(t, svc.tick(ctx, at(900)), test.ShouldBeNil)
+	test.That(t, status().Action, test.ShouldEqual, safetyzone.ActionSlow)
+	test.That(t, rr.lastMotionCmd(), test.ShouldResemble, map[string]interface{}{motion.DoResumeExecution: true})
+
+	rr.mu.Lock()
+	rr.distance = 5
+	rr.mu.Unlock()
+	test.That(t, svc.tick(ctx, at(1500)), test.ShouldBeNil)
+	st = status()
+	test.That(t, st.Action, test.ShouldEqual, safetyzone.ActionNone)
+	test.That(t, st.SpeedScale, test.ShouldEqual, 1)
+	test.That(t, st.Violated, test.ShouldBeEmpty)
+	test.That(t, rr.lastMotionCmd(), test.ShouldBeNil)
+	v, err = drive()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, v.Y, test.ShouldEqual, 400)
+
+	test.That(t, st.Events, test.ShouldHaveLength, 4)
+	test.That(t, st.Events[1].Zone, test.ShouldEqual, "stop")
+	test.That(t, st.Events[2].Zone, test.ShouldEqual, "stop")
+	test.That(t, st.Events[2].Cleared, test.ShouldBeTrue)
+	test.That(t, st.Events[3].Zone, test.ShouldEqual, "slow")
+	test.That(t, st.Events[3].Cleared, test.ShouldBeTrue)
+
+	// an unreadable sensor is treated as a violation
+	rr.mu.Lock()
+	rr.readErr = errors.New("disconnected")
+	rr.mu.Unlock()
+	test.That(t, svc.tick(ctx, at(1700)), test.ShouldBeNil)
+	test.That(t, status().Violated, test.ShouldResemble, []string{"slow"})
+}
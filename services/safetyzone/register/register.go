@@ -0,0 +1,7 @@
+// Package register registers all relevant safety zone models and also API specific functions
+package register
+
+import (
+	// for safety zone models.
+	_ "go.viam.com/rdk/services/safetyzone/builtin"
+)
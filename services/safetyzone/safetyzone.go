@@ -0,0 +1,156 @@
+// Package safetyzone defines a service that watches obstacle sensors against configured zones and
+// slows or stops motion while a zone is violated.
+package safetyzone
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = "safety_zone"
+
+// API is a variable that identifies the safety zone resource API.
+var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
+
+// Named is a helper for getting the named safety zone service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// Deprecated: FromRobot is a helper for getting the named safety zone service from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named safety zone service
+// from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Service, error) {
+	return resource.FromProvider[Service](provider, Named(name))
+}
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Service]{})
+}
+
+// Action is what the service does while a zone is violated.
+type Action string
+
+// The set of known zone actions, from least to most restrictive.
+const (
+	ActionNone = Action("")
+	ActionSlow = Action("slow")
+	ActionStop = Action("stop")
+)
+
+// Event records a zone becoming violated or clear.
+type Event struct {
+	Time time.Time
+	Zone string
+	// Source is the sensor or camera that observed the violation.
+	Source string
+	Action Action
+	// DistanceMM is the distance from the source to the closest violating obstacle.
+	DistanceMM float64
+	// Cleared is true if the zone stopped being violated.
+	Cleared bool
+}
+
+// Status reports the current state of a safety zone service.
+type Status struct {
+	// Action is the most restrictive action of all violated zones.
+	Action Action
+	// SpeedScale is the fraction of normal speed currently permitted.
+	SpeedScale float64
+	// Violated holds the names of the zones currently violated.
+	Violated []string
+	// Events holds the most recent zone events, oldest first.
+	Events []Event
+}
+
+// A Service monitors zones and limits motion while they are violated.
+// Its resource Status reports the safety zone Status in the form produced by StatusToMap.
+type Service interface {
+	resource.Resource
+}
+
+// GetStatus returns the current state of the service.
+func GetStatus(ctx context.Context, svc Service) (Status, error) {
+	m, err := svc.Status(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	return StatusFromMap(m)
+}
+
+// StatusToMap converts a Status into the map reported by a safety zone service's resource Status.
+func StatusToMap(st Status) map[string]interface{} {
+	violated := make([]interface{}, 0, len(st.Violated))
+	for _, z := range st.Violated {
+		violated = append(violated, z)
+	}
+	events := make([]interface{}, 0, len(st.Events))
+	for _, e := range st.Events {
+		events = append(events, map[string]interface{}{
+			"time":        e.Time.UTC().Format(time.RFC3339Nano),
+			"zone":        e.Zone,
+			"source":      e.Source,
+			"action":      string(e.Action),
+			"distance_mm": e.DistanceMM,
+			"cleared":     e.Cleared,
+		})
+	}
+	return map[string]interface{}{
+		"action":      string(st.Action),
+		"speed_scale": st.SpeedScale,
+		"violated":    violated,
+		"events":      events,
+	}
+}
+
+// StatusFromMap converts a map produced by StatusToMap back into a Status.
+func StatusFromMap(m map[string]interface{}) (Status, error) {
+	action, ok := m["action"].(string)
+	if !ok {
+		return Status{}, errors.Errorf("expected safety zone status action to be a string but got %T", m["action"])
+	}
+	st := Status{Action: Action(action)}
+	st.SpeedScale, _ = m["speed_scale"].(float64)
+	rawViolated, _ := m["violated"].([]interface{})
+	for _, z := range rawViolated {
+		if name, ok := z.(string); ok {
+			st.Violated = append(st.Violated, name)
+		}
+	}
+	rawEvents, _ := m["events"].([]interface{})
+	for _, raw := range rawEvents {
+		em, ok := raw.(map[string]interface{})
+		if !ok {
+			return Status{}, errors.Errorf("expected safety zone event to be a map but got %T", raw)
+		}
+		var e Event
+		if ts, ok := em["time"].(string); ok {
+			t, err := time.Parse(time.RFC3339Nano, ts)
+			if err != nil {
+				return Status{}, err
+			}
+			e.Time = t
+		}
+		e.Zone, _ = em["zone"].(string)
+		e.Source, _ = em["source"].(string)
+		a, _ := em["action"].(string)
+		e.Action = Action(a)
+		e.DistanceMM, _ = em["distance_mm"].(float64)
+		e.Cleared, _ = em["cleared"].(bool)
+		st.Events = append(st.Events, e)
+	}
+	return st, nil
+}
@@ -4,10 +4,12 @@ package builtin
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -68,6 +70,27 @@ func (svc *builtIn) Shell(ctx context.Context, extra map[string]interface{}) (
 	//nolint:gosec
 	cmd := exec.Command(defaultShellPath, shellArgs...)
 	cmd.Env = shellEnv
+	if env, ok := extra[shell.ShellEnvKey].(map[string]interface{}); ok && len(env) != 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		keys := make([]string, 0, len(env))
+		for k := range env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%v", k, env[k]))
+		}
+	}
+	if dir, ok := extra[shell.ShellDirKey].(string); ok && dir != "" {
+		shellDir, err := execDir(dir)
+		if err != nil {
+			cancel()
+			return nil, nil, nil, err
+		}
+		cmd.Dir = shellDir
+	}
 	// xpty gives a unix pty or a Windows ConPTY behind one interface.
 	f, err := xpty.NewPty(80, 24)
 	if err != nil {
@@ -89,15 +112,15 @@ func (svc *builtIn) Shell(ctx context.Context, extra map[string]interface{}) (
 			return
 		}
 		switch data["message"] {
-		case "window-change":
-			cols, ok := data["cols"].(float64)
+		case shell.WindowChangeMessage:
+			cols, ok := windowDimension(data["cols"])
 			if !ok {
-				svc.logger.CErrorw(ctx, "invalid window-change message; expected cols to be float64", "value", data["cols"])
+				svc.logger.CErrorw(ctx, "invalid window-change message; expected cols to be a positive number", "value", data["cols"])
 				return
 			}
-			rows, ok := data["rows"].(float64)
+			rows, ok := windowDimension(data["rows"])
 			if !ok {
-				svc.logger.CErrorw(ctx, "invalid window-change message; expected rows to be float64", "value", data["rows"])
+				svc.logger.CErrorw(ctx, "invalid window-change message; expected rows to be a positive number", "value", data["rows"])
 				return
 			}
 			sizeLock.Lock()
@@ -107,7 +130,7 @@ func (svc *builtIn) Shell(ctx context.Context, extra map[string]interface{}) (
 				return
 			}
 			lastSet = time.Now()
-			if err := f.Resize(int(cols), int(rows)); err != nil {
+			if err := f.Resize(cols, rows); err != nil {
 				svc.logger.CErrorw(ctx, "error setting pty window size", "error", err)
 			}
 		default:
@@ -219,6 +242,21 @@ func (svc *builtIn) Shell(ctx context.Context, extra map[string]interface{}) (
 	return input, oobInput, output, nil
 }
 
+// windowDimension returns the number of columns or rows in a window-change message, which is a
+// float64 when the message came over the network.
+func windowDimension(v interface{}) (int, bool) {
+	var dim int
+	switch n := v.(type) {
+	case float64:
+		dim = int(n)
+	case int:
+		dim = n
+	default:
+		return 0, false
+	}
+	return dim, dim > 0
+}
+
 // CopyFilesToMachine places files from the returned FileCopier
 // into the given local destination.
 func (svc *builtIn) CopyFilesToMachine(
@@ -228,9 +266,13 @@ func (svc *builtIn) CopyFilesToMachine(
 	preserve bool,
 	extra map[string]interface{},
 ) (shell.FileCopier, error) {
+	opts, err := shell.CopyOptionsFromExtra(extra)
+	if err != nil {
+		return nil, err
+	}
 	// we make a temporary factory here just because its reusing some code used
 	// elsewhere (like the CLI) that keeps the factory around longer.
-	factory, err := shell.NewLocalFileCopyFactory(destination, preserve, true)
+	factory, err := shell.NewLocalFileCopyFactoryWithOptions(destination, preserve, true, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +292,11 @@ func (svc *builtIn) CopyFilesFromMachine(
 	copyFactory shell.FileCopyFactory,
 	extra map[string]interface{},
 ) error {
-	reader, err := shell.NewLocalFileReadCopier(paths, allowRecursion, false, copyFactory)
+	opts, err := shell.CopyOptionsFromExtra(extra)
+	if err != nil {
+		return err
+	}
+	reader, err := shell.NewLocalFileReadCopierWithOptions(paths, allowRecursion, false, copyFactory, opts)
 	if err != nil {
 		return err
 	}
@@ -260,6 +306,44 @@ func (svc *builtIn) CopyFilesFromMachine(
 	return reader.ReadAll(ctx)
 }
 
+// DoCommand runs commands without a terminal under shell.DoExec, and describes what a destination
+// on the machine already has for copies that sync or resume under shell.DoCopyManifest.
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if execReq, ok, err := shell.ExecRequestFromCommand(cmd); ok {
+		if err != nil {
+			return nil, err
+		}
+		result, err := svc.runExec(ctx, execReq)
+		if err != nil {
+			return nil, err
+		}
+		return shell.ExecResultToResponse(result)
+	}
+	req, ok := cmd[shell.DoCopyManifest].(map[string]interface{})
+	if !ok {
+		return nil, resource.ErrDoUnimplemented
+	}
+	destination, _ := req["destination"].(string)
+	rawNames, _ := req["names"].([]interface{})
+	names := make([]string, 0, len(rawNames))
+	for _, name := range rawNames {
+		nameStr, ok := name.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected names to be strings but got %v", name)
+		}
+		names = append(names, nameStr)
+	}
+	manifest, err := shell.BuildCopyManifest(destination, names, true)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := resource.EncodeDoCommand(manifest)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{shell.DoCopyManifest: encoded}, nil
+}
+
 func (svc *builtIn) Close(ctx context.Context) error {
 	svc.activeBackgroundWorkers.Wait()
 	return nil
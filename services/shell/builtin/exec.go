@@ -0,0 +1,104 @@
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.viam.com/rdk/services/shell"
+)
+
+// execWaitDelay is how long an executed command's output is waited for after it exits or is
+// killed, in case something it started still holds its stdout or stderr open.
+const execWaitDelay = time.Second
+
+// runExec runs the command req describes without a terminal.
+func (svc *builtIn) runExec(ctx context.Context, req shell.ExecRequest) (shell.ExecResult, error) {
+	runCtx := ctx
+	if req.TimeoutMs > 0 {
+		var cancel func()
+		runCtx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	//nolint:gosec // this is from an authenticated/authorized connection
+	cmd := exec.CommandContext(runCtx, req.Args[0], req.Args[1:]...)
+	cmd.WaitDelay = execWaitDelay
+	dir, err := execDir(req.Dir)
+	if err != nil {
+		return shell.ExecResult{}, err
+	}
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	keys := make([]string, 0, len(req.Env))
+	for k := range req.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+req.Env[k])
+	}
+	cmd.Stdin = strings.NewReader(req.Stdin)
+	stdout := &cappedBuffer{limit: shell.MaxExecOutput}
+	stderr := &cappedBuffer{limit: shell.MaxExecOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return shell.ExecResult{}, err
+	}
+	waitErr := cmd.Wait()
+	if ctx.Err() != nil {
+		return shell.ExecResult{}, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if waitErr != nil && !errors.As(waitErr, &exitErr) && !errors.Is(waitErr, exec.ErrWaitDelay) {
+		return shell.ExecResult{}, waitErr
+	}
+	svc.logger.CDebugw(ctx, "executed command", "args", req.Args, "exit_code", cmd.ProcessState.ExitCode())
+	return shell.ExecResult{
+		ExitCode:  cmd.ProcessState.ExitCode(),
+		Stdout:    stdout.buf.String(),
+		Stderr:    stderr.buf.String(),
+		Truncated: stdout.truncated || stderr.truncated,
+		TimedOut:  errors.Is(runCtx.Err(), context.DeadlineExceeded),
+	}, nil
+}
+
+// execDir returns the directory a command runs in, which is relative to the home directory.
+func execDir(dir string) (string, error) {
+	if filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if dir == "~" {
+		return home, nil
+	}
+	dir, _ = strings.CutPrefix(dir, "~/")
+	return filepath.Join(home, dir), nil
+}
+
+// A cappedBuffer keeps what is written to it up to its limit and drops the rest.
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); len(p) > remaining {
+		b.truncated = true
+		b.buf.Write(p[:max(remaining, 0)])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
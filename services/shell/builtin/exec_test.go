@@ -0,0 +1,67 @@
+package builtin_test
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/shell"
+	"go.viam.com/rdk/services/shell/builtin"
+)
+
+func TestExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	ctx := context.Background()
+	svc, err := builtin.NewBuiltIn(shell.Named("shell"), logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	result, err := shell.Exec(ctx, svc, shell.ExecRequest{
+		Args:  []string{"sh", "-c", `echo "$GREETING" from "$(pwd)"; cat; echo oops >&2; exit 3`},
+		Env:   map[string]string{"GREETING": "hello"},
+		Dir:   os.TempDir(),
+		Stdin: "input\n",
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result.ExitCode, test.ShouldEqual, 3)
+	test.That(t, result.Stdout, test.ShouldStartWith, "hello from /")
+	test.That(t, result.Stdout, test.ShouldEndWith, "\ninput\n")
+	test.That(t, result.Stderr, test.ShouldEqual, "oops\n")
+	test.That(t, result.TimedOut, test.ShouldBeFalse)
+
+	home, err := os.UserHomeDir()
+	test.That(t, err, test.ShouldBeNil)
+	result, err = shell.Exec(ctx, svc, shell.ExecRequest{Args: []string{"pwd"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result.ExitCode, test.ShouldEqual, 0)
+	test.That(t, strings.TrimSpace(result.Stdout), test.ShouldEqual, home)
+
+	result, err = shell.Exec(ctx, svc, shell.ExecRequest{Args: []string{"sleep", "10"}, TimeoutMs: 100})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result.TimedOut, test.ShouldBeTrue)
+	test.That(t, result.ExitCode, test.ShouldEqual, -1)
+
+	result, err = shell.Exec(ctx, svc, shell.ExecRequest{Args: []string{"head", "-c", "2000000", "/dev/zero"}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result.Truncated, test.ShouldBeTrue)
+	test.That(t, result.Stdout, test.ShouldHaveLength, shell.MaxExecOutput)
+
+	_, err = shell.Exec(ctx, svc, shell.ExecRequest{Args: []string{"no-such-program-anywhere"}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = shell.Exec(ctx, svc, shell.ExecRequest{})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{shell.DoExec: map[string]interface{}{"args": []interface{}{}}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldBeError, resource.ErrDoUnimplemented)
+}
@@ -30,17 +30,32 @@ func NewLocalFileCopyFactory(
 	preserve bool,
 	relativeToHome bool,
 ) (FileCopyFactory, error) {
+	return NewLocalFileCopyFactoryWithOptions(destination, preserve, relativeToHome, CopyOptions{})
+}
+
+// NewLocalFileCopyFactoryWithOptions is NewLocalFileCopyFactory for copies made with the given
+// options, which must match those of the side sending the files.
+func NewLocalFileCopyFactoryWithOptions(
+	destination string,
+	preserve bool,
+	relativeToHome bool,
+	opts CopyOptions,
+) (FileCopyFactory, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
 	// fixup destination to something we can work with
 	destination, err := fixPeerPath(destination, true, relativeToHome)
 	if err != nil {
 		return nil, err
 	}
-	return &localFileCopyFactory{destination: destination, preserve: preserve}, nil
+	return &localFileCopyFactory{destination: destination, preserve: preserve, opts: opts}, nil
 }
 
 type localFileCopyFactory struct {
 	destination string
 	preserve    bool
+	opts        CopyOptions
 }
 
 // MakeFileCopier makes a new FileCopier that is ready to copy files into the factory's
@@ -133,6 +148,7 @@ func (f *localFileCopyFactory) MakeFileCopier(ctx context.Context, sourceType Co
 		dst:          finalDestination,
 		overrideName: overrideName,
 		preserve:     f.preserve,
+		opts:         f.opts,
 	}, nil
 }
 
@@ -147,6 +163,7 @@ type localFileCopier struct {
 	dst          string
 	overrideName string
 	preserve     bool
+	opts         CopyOptions
 }
 
 func (copier *localFileCopier) Copy(ctx context.Context, file File) error {
@@ -229,21 +246,19 @@ func (copier *localFileCopier) Copy(ctx context.Context, file File) error {
 		// Since file may be streamed (see copyFile in copy_rpc.go), write to a temp file (filename.download) and rename after the download
 		// has completed. This way a temporary network blip won't leave a corrupted partial file in the expected location.
 		// Technically the temp file can be deleted upon any Copy error, but it will also be clobbered next time we retry.
-		fullPathTmp := fullPath + ".download"
+		fullPathTmp := fullPath + partialSuffix
 
-		// always delete a potential stale temp. if the temp is read-only, it can
-		// cause the following OpenFile call to fail on windows
-		if err := os.Remove(fullPathTmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
-			return err
-		}
-		//nolint:gosec // this is from an authenticated/authorized connection
-		localFile, err := os.OpenFile(fullPathTmp, os.O_CREATE|os.O_WRONLY, fileMode)
+		localFile, err := copier.openPartial(file.RelativeName, fullPathTmp, fileInfo.Size(), fileMode)
 		if err != nil {
 			return err
 		}
 		_, copyErr := io.Copy(localFile, file.Data)
 		closeErr := localFile.Close()
 		if copyErr != nil {
+			if copier.opts.Resume {
+				// keep what was received so that the copy can resume from it.
+				return multierr.Combine(copyErr, closeErr)
+			}
 			// Remove partially downloaded file if possible. Don't error if it does not exist.
 			cleanupErr := os.Remove(fullPathTmp)
 			if errors.Is(cleanupErr, fs.ErrNotExist) {
@@ -279,6 +294,27 @@ func (copier *localFileCopier) Copy(ctx context.Context, file File) error {
 	return nil
 }
 
+// openPartial opens the temp file a file is received into, which is appended to if the copy of the
+// file resumes.
+func (copier *localFileCopier) openPartial(relName, fullPathTmp string, size int64, fileMode fs.FileMode) (*os.File, error) {
+	if offset, _ := copier.opts.resumeOffset(relName, size); offset > 0 {
+		info, err := os.Stat(fullPathTmp)
+		if err != nil || info.Size() != offset {
+			return nil, status.Newf(codes.FailedPrecondition,
+				"cannot resume copying %q since its partial copy changed; try again", relName).Err()
+		}
+		//nolint:gosec // this is from an authenticated/authorized connection
+		return os.OpenFile(fullPathTmp, os.O_WRONLY|os.O_APPEND, fileMode)
+	}
+	// always delete a potential stale temp. if the temp is read-only, it can
+	// cause the following OpenFile call to fail on windows
+	if err := os.Remove(fullPathTmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	//nolint:gosec // this is from an authenticated/authorized connection
+	return os.OpenFile(fullPathTmp, os.O_CREATE|os.O_WRONLY, fileMode)
+}
+
 // Close does nothing.
 func (copier *localFileCopier) Close(ctx context.Context) error {
 	return nil
@@ -287,6 +323,7 @@ func (copier *localFileCopier) Close(ctx context.Context) error {
 type localFileReadCopier struct {
 	filesToCopy []*os.File
 	copyFactory FileCopyFactory
+	opts        CopyOptions
 }
 
 // NewLocalFileReadCopier returns a FileReadCopier that will have its ReadAll
@@ -299,6 +336,21 @@ func NewLocalFileReadCopier(
 	relativeToHome bool,
 	copyFactory FileCopyFactory,
 ) (FileReadCopier, error) {
+	return NewLocalFileReadCopierWithOptions(paths, allowRecursive, relativeToHome, copyFactory, CopyOptions{})
+}
+
+// NewLocalFileReadCopierWithOptions is NewLocalFileReadCopier for copies made with the given
+// options, which must match those of the side receiving the files.
+func NewLocalFileReadCopierWithOptions(
+	paths []string,
+	allowRecursive bool,
+	relativeToHome bool,
+	copyFactory FileCopyFactory,
+	opts CopyOptions,
+) (FileReadCopier, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
 	var filesToCopy []*os.File
 
 	for _, p := range paths {
@@ -334,7 +386,7 @@ func NewLocalFileReadCopier(
 	if len(filesToCopy) == 0 {
 		return nil, errors.New("no files provided to copy")
 	}
-	return &localFileReadCopier{filesToCopy: filesToCopy, copyFactory: copyFactory}, nil
+	return &localFileReadCopier{filesToCopy: filesToCopy, copyFactory: copyFactory, opts: opts}, nil
 }
 
 // ErrMsgDirectoryCopyRequestNoRecursion should be returned when a file is included in a path for a copy request
@@ -373,6 +425,8 @@ func (reader *localFileReadCopier) ReadAll(ctx context.Context) error {
 
 	// Note: okay with recursion for now. may want to check depth later...
 
+	limiter := reader.opts.newCopyLimiter()
+
 	makeRelName := func(relDir string, file *os.File) string {
 		return path.Join(relDir, filepath.Base(file.Name()))
 	}
@@ -387,6 +441,12 @@ func (reader *localFileReadCopier) ReadAll(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
+			relName := makeRelName(relDir, f)
+			if !reader.opts.copied(relName, fileInfo.IsDir()) || reader.opts.upToDate(relName, fileInfo) {
+				utils.UncheckedError(f.Close())
+				continue
+			}
+			var data fs.File = f
 			if fileInfo.IsDir() {
 				filesEntriesInDir, err := f.ReadDir(0)
 				if err != nil {
@@ -406,14 +466,22 @@ func (reader *localFileReadCopier) ReadAll(ctx context.Context) error {
 					filesInDir = append(filesInDir, entryFile)
 				}
 
-				if err := copyFiles(makeRelName(relDir, f), filesInDir); err != nil {
+				if err := copyFiles(relName, filesInDir); err != nil {
 					return err
 				}
+			} else {
+				if err := reader.opts.seekToResume(f, relName, fileInfo.Size()); err != nil {
+					utils.UncheckedError(f.Close())
+					return err
+				}
+				if limiter != nil {
+					data = &throttledFile{File: f, ctx: ctx, limiter: limiter}
+				}
 			}
 
 			if err := copier.Copy(ctx, File{
-				RelativeName: makeRelName(relDir, f),
-				Data:         f,
+				RelativeName: relName,
+				Data:         data,
 			}); err != nil {
 				return err
 			}
@@ -0,0 +1,342 @@
+package shell
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.viam.com/utils"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/resource"
+)
+
+// copy_options extends copies beyond what the copy RPCs carry: filtering which files are copied,
+// skipping files the destination already has, resuming interrupted copies, and limiting bandwidth.
+// Both sides of a copy are configured with the same CopyOptions, which travel in the extra of the
+// copy request, so that the side sending files and the side receiving them agree on what is sent.
+
+// CopyOptionsKey is the key in the extra of CopyFilesToMachine and CopyFilesFromMachine under
+// which CopyOptions are passed.
+const CopyOptionsKey = "copy_options"
+
+// DoCopyManifest is the DoCommand key with which a shell service is asked for the CopyManifest of a
+// destination on its machine. Its value holds the "destination" and the "names" to describe, and
+// the response holds the manifest under the same key.
+const DoCopyManifest = "copy_manifest"
+
+// partialSuffix is appended to the name of a file while it is being received.
+const partialSuffix = ".download"
+
+// CopyOptions are options for copying files that both sides of a copy must agree on.
+type CopyOptions struct {
+	// Include and Exclude are glob patterns, as in path.Match, matched against both the
+	// slash-separated relative name of each file and its base name. Only files that match an
+	// Include pattern, if there are any, and no Exclude pattern are copied. Directories matching an
+	// Exclude pattern are skipped entirely.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	// Sync skips files the destination already has with the same size and modification time,
+	// which requires preserving modification times.
+	Sync bool `json:"sync,omitempty"`
+	// Resume continues copies of files the destination received part of before being interrupted,
+	// and keeps what was received of files whose copy is interrupted.
+	Resume bool `json:"resume,omitempty"`
+	// BytesPerSec limits how fast file data is sent. Zero means no limit.
+	BytesPerSec int64 `json:"bytes_per_sec,omitempty"`
+	// Manifest describes what the destination already has, which Sync and Resume need. It is
+	// filled by BuildCopyManifest or FetchCopyManifest.
+	Manifest CopyManifest `json:"manifest,omitempty"`
+}
+
+// A CopyManifest describes the files a destination already has by the slash-separated relative
+// names they are copied with.
+type CopyManifest map[string]CopyManifestEntry
+
+// A CopyManifestEntry describes a file a destination already has, either completely or partially.
+type CopyManifestEntry struct {
+	// Size and ModTime describe the complete file, if there is one.
+	Size    int64     `json:"size,omitempty"`
+	ModTime time.Time `json:"mod_time,omitempty"`
+	// PartialSize and PartialSHA256 describe what an interrupted copy of the file left behind, if
+	// anything.
+	PartialSize   int64  `json:"partial_size,omitempty"`
+	PartialSHA256 string `json:"partial_sha256,omitempty"`
+}
+
+// Validate ensures the options are valid.
+func (opts CopyOptions) Validate() error {
+	for _, pattern := range append(append([]string(nil), opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	if opts.BytesPerSec < 0 {
+		return errors.New("bytes_per_sec cannot be negative")
+	}
+	return nil
+}
+
+// NeedsManifest returns whether the options need the Manifest of the destination.
+func (opts CopyOptions) NeedsManifest() bool {
+	return opts.Sync || opts.Resume
+}
+
+// ToExtra returns extra with the options added under CopyOptionsKey.
+func (opts CopyOptions) ToExtra(extra map[string]interface{}) (map[string]interface{}, error) {
+	encoded, err := resource.EncodeDoCommand(opts)
+	if err != nil {
+		return nil, err
+	}
+	withOpts := make(map[string]interface{}, len(extra)+1)
+	for k, v := range extra {
+		withOpts[k] = v
+	}
+	withOpts[CopyOptionsKey] = encoded
+	return withOpts, nil
+}
+
+// CopyOptionsFromExtra returns the options under CopyOptionsKey in extra, which are the zero
+// CopyOptions if there are none.
+func CopyOptionsFromExtra(extra map[string]interface{}) (CopyOptions, error) {
+	encoded, ok := extra[CopyOptionsKey].(map[string]interface{})
+	if !ok {
+		if _, present := extra[CopyOptionsKey]; present {
+			return CopyOptions{}, fmt.Errorf("expected %s to be an object", CopyOptionsKey)
+		}
+		return CopyOptions{}, nil
+	}
+	opts, err := resource.DecodeDoCommand[CopyOptions](encoded)
+	if err != nil {
+		return CopyOptions{}, err
+	}
+	return opts, opts.Validate()
+}
+
+// copied returns whether the file with the given relative name is copied under the options.
+func (opts CopyOptions) copied(relName string, isDir bool) bool {
+	if matchesAny(opts.Exclude, relName) {
+		return false
+	}
+	return isDir || len(opts.Include) == 0 || matchesAny(opts.Include, relName)
+}
+
+func matchesAny(patterns []string, relName string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, relName); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(relName)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// upToDate returns whether the destination already has the given file under Sync.
+func (opts CopyOptions) upToDate(relName string, info fs.FileInfo) bool {
+	if !opts.Sync || info.IsDir() {
+		return false
+	}
+	entry, ok := opts.Manifest[relName]
+	return ok && entry.Size == info.Size() && entry.ModTime.Unix() == info.ModTime().Unix()
+}
+
+// resumeOffset returns the offset at which the copy of the given file resumes under Resume, which
+// is zero if it starts over. Both sides of a copy use it to agree on what is sent.
+func (opts CopyOptions) resumeOffset(relName string, size int64) (int64, string) {
+	if !opts.Resume {
+		return 0, ""
+	}
+	entry := opts.Manifest[relName]
+	if entry.PartialSize <= 0 || entry.PartialSize >= size {
+		return 0, ""
+	}
+	return entry.PartialSize, entry.PartialSHA256
+}
+
+// BuildCopyManifest describes the files a local destination already has under each of the given
+// top-level names, which are the base names of the files being copied. A destination that is not an
+// existing directory has nothing to describe, since files copied to it start over.
+func BuildCopyManifest(destination string, names []string, relativeToHome bool) (CopyManifest, error) {
+	destination, err := fixPeerPath(destination, true, relativeToHome)
+	if err != nil {
+		return nil, err
+	}
+	manifest := CopyManifest{}
+	if info, err := os.Stat(destination); err != nil || !info.IsDir() {
+		//nolint:nilerr // a missing destination has nothing in it
+		return manifest, nil
+	}
+	for _, name := range names {
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return nil, fmt.Errorf("invalid name %q", name)
+		}
+		// a single file interrupted while being received only left its partial copy behind.
+		for _, root := range []string{name, name + partialSuffix} {
+			if err := addToCopyManifest(manifest, destination, root); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// addToCopyManifest adds the files under root in destination to manifest.
+func addToCopyManifest(manifest CopyManifest, destination, root string) error {
+	return filepath.WalkDir(filepath.Join(destination, root), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(destination, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if trimmed, ok := strings.CutSuffix(rel, partialSuffix); ok {
+			sum, err := sha256File(p, info.Size())
+			if err != nil {
+				return err
+			}
+			entry := manifest[trimmed]
+			entry.PartialSize, entry.PartialSHA256 = info.Size(), sum
+			manifest[trimmed] = entry
+			return nil
+		}
+		entry := manifest[rel]
+		entry.Size, entry.ModTime = info.Size(), info.ModTime()
+		manifest[rel] = entry
+		return nil
+	})
+}
+
+// FetchCopyManifest asks the given shell service for the CopyManifest of a destination on its
+// machine, as BuildCopyManifest describes it relative to the home directory.
+func FetchCopyManifest(ctx context.Context, svc Service, destination string, names []string) (CopyManifest, error) {
+	namesList := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		namesList = append(namesList, name)
+	}
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{
+		DoCopyManifest: map[string]interface{}{"destination": destination, "names": namesList},
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded, ok := resp[DoCopyManifest].(map[string]interface{})
+	if !ok {
+		return CopyManifest{}, nil
+	}
+	return resource.DecodeDoCommand[CopyManifest](encoded)
+}
+
+// CopyManifestNames returns the top-level names the files at the given paths are copied with.
+// Paths whose name is not known ahead of time, such as the home directory, are left out, so that
+// nothing copied under them is synced or resumed.
+func CopyManifestNames(paths []string) []string {
+	var names []string
+	for _, p := range paths {
+		if p == "" || p == "~" || p == "~/" {
+			continue
+		}
+		name := filepath.Base(filepath.Clean(p))
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// sha256File returns the hex encoded SHA-256 of the first n bytes of the file at p.
+func sha256File(p string, n int64) (string, error) {
+	//nolint:gosec // this is from an authenticated/authorized connection
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer utils.UncheckedErrorFunc(f.Close)
+	return sha256Prefix(f, n)
+}
+
+func sha256Prefix(r io.Reader, n int64) (string, error) {
+	h := sha256.New()
+	if _, err := io.CopyN(h, r, n); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// seekToResume positions f, which is sent under the given relative name, where its copy resumes.
+// It fails if f no longer starts with what the destination received of it, in which case the
+// partial copy has to be removed before copying again.
+func (opts CopyOptions) seekToResume(f *os.File, relName string, size int64) error {
+	offset, sum := opts.resumeOffset(relName, size)
+	if offset == 0 {
+		return nil
+	}
+	actual, err := sha256Prefix(f, offset)
+	if err != nil {
+		return err
+	}
+	if actual != sum {
+		return status.Newf(codes.FailedPrecondition,
+			"cannot resume copying %q since it changed after the copy was interrupted; remove the partial copy and try again",
+			relName).Err()
+	}
+	_, err = f.Seek(offset, io.SeekStart)
+	return err
+}
+
+// maxThrottledRead is the most file data read at once while bandwidth is limited, which keeps the
+// data sent smooth rather than bursty.
+const maxThrottledRead = 32 * 1024
+
+// newCopyLimiter returns a limiter for the bandwidth of the options, or nil if there is no limit.
+func (opts CopyOptions) newCopyLimiter() *rate.Limiter {
+	if opts.BytesPerSec == 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(opts.BytesPerSec), int(min(opts.BytesPerSec, maxThrottledRead)))
+}
+
+// A throttledFile reads from a file no faster than its limiter allows.
+type throttledFile struct {
+	fs.File
+	ctx     context.Context
+	limiter *rate.Limiter
+}
+
+func (f *throttledFile) Read(buf []byte) (int, error) {
+	if len(buf) > f.limiter.Burst() {
+		buf = buf[:f.limiter.Burst()]
+	}
+	n, err := f.File.Read(buf)
+	if n > 0 {
+		if waitErr := f.limiter.WaitN(f.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,189 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func writeTestFile(t *testing.T, p string, data []byte) {
+	t.Helper()
+	test.That(t, os.MkdirAll(filepath.Dir(p), 0o750), test.ShouldBeNil)
+	test.That(t, os.WriteFile(p, data, 0o640), test.ShouldBeNil)
+}
+
+func copyWithOptions(t *testing.T, src, dst string, opts CopyOptions) error {
+	t.Helper()
+	ctx := context.Background()
+	factory, err := NewLocalFileCopyFactoryWithOptions(dst, true, false, opts)
+	test.That(t, err, test.ShouldBeNil)
+	readCopier, err := NewLocalFileReadCopierWithOptions([]string{src}, true, false, factory, opts)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, readCopier.Close(ctx), test.ShouldBeNil)
+	}()
+	return readCopier.ReadAll(ctx)
+}
+
+func TestCopyOptionsExtra(t *testing.T) {
+	opts, err := CopyOptionsFromExtra(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, opts, test.ShouldResemble, CopyOptions{})
+
+	modTime := time.Date(2024, time.January, 3, 12, 0, 0, 0, time.UTC)
+	expected := CopyOptions{
+		Include:     []string{"*.log"},
+		Sync:        true,
+		BytesPerSec: 1024,
+		Manifest:    CopyManifest{"logs/a.log": {Size: 5, ModTime: modTime, PartialSize: 2, PartialSHA256: "ab"}},
+	}
+	extra, err := expected.ToExtra(map[string]interface{}{"other": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, extra["other"], test.ShouldEqual, true)
+	opts, err = CopyOptionsFromExtra(extra)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, opts, test.ShouldResemble, expected)
+
+	_, err = CopyOptionsFromExtra(map[string]interface{}{CopyOptionsKey: "sync"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, CopyOptions{Exclude: []string{"["}}.Validate(), test.ShouldNotBeNil)
+	test.That(t, CopyOptions{BytesPerSec: -1}.Validate(), test.ShouldNotBeNil)
+}
+
+func TestCopyIncludeExclude(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "logs")
+	for _, name := range []string{"a.log", "b.txt", "sub/c.log", "skip/d.log"} {
+		writeTestFile(t, filepath.Join(src, name), []byte(name))
+	}
+	dst := t.TempDir()
+	err := copyWithOptions(t, src, dst, CopyOptions{Include: []string{"*.log"}, Exclude: []string{"logs/skip"}})
+	test.That(t, err, test.ShouldBeNil)
+
+	var copied []string
+	test.That(t, filepath.WalkDir(dst, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dst, p)
+			copied = append(copied, filepath.ToSlash(rel))
+		}
+		return err
+	}), test.ShouldBeNil)
+	test.That(t, copied, test.ShouldResemble, []string{"logs/a.log", "logs/sub/c.log"})
+}
+
+func TestCopySync(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "logs")
+	writeTestFile(t, filepath.Join(src, "a.log"), []byte("a"))
+	writeTestFile(t, filepath.Join(src, "b.log"), []byte("b"))
+	dst := t.TempDir()
+	test.That(t, copyWithOptions(t, src, dst, CopyOptions{}), test.ShouldBeNil)
+
+	manifest, err := BuildCopyManifest(dst, CopyManifestNames([]string{src}), false)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, manifest, test.ShouldHaveLength, 2)
+	test.That(t, manifest["logs/a.log"].Size, test.ShouldEqual, 1)
+
+	// an up-to-date file is skipped even if its contents differ, as with rsync.
+	writeTestFile(t, filepath.Join(dst, "logs", "a.log"), []byte("x"))
+	aInfo, err := os.Stat(filepath.Join(src, "a.log"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.Chtimes(filepath.Join(dst, "logs", "a.log"), time.Now(), aInfo.ModTime()), test.ShouldBeNil)
+	writeTestFile(t, filepath.Join(src, "b.log"), []byte("bb"))
+	manifest, err = BuildCopyManifest(dst, []string{"logs"}, false)
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, copyWithOptions(t, src, dst, CopyOptions{Sync: true, Manifest: manifest}), test.ShouldBeNil)
+	data, err := os.ReadFile(filepath.Join(dst, "logs", "a.log"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(data), test.ShouldEqual, "x")
+	data, err = os.ReadFile(filepath.Join(dst, "logs", "b.log"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(data), test.ShouldEqual, "bb")
+
+	// a destination that is not a directory has nothing to sync.
+	manifest, err = BuildCopyManifest(filepath.Join(dst, "missing"), []string{"logs"}, false)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, manifest, test.ShouldBeEmpty)
+	_, err = BuildCopyManifest(dst, []string{"../logs"}, false)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+// failingFile fails after its data is read, like a copy whose connection drops.
+type failingFile struct {
+	fs.File
+	remaining int
+}
+
+func (f *failingFile) Read(buf []byte) (int, error) {
+	if f.remaining == 0 {
+		return 0, errors.New("connection lost")
+	}
+	if len(buf) > f.remaining {
+		buf = buf[:f.remaining]
+	}
+	n, err := f.File.Read(buf)
+	f.remaining -= n
+	return n, err
+}
+
+func TestCopyResume(t *testing.T) {
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	src := filepath.Join(srcDir, "big.bin")
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	writeTestFile(t, src, data)
+	dst := t.TempDir()
+
+	// an interrupted copy keeps what was received.
+	factory, err := NewLocalFileCopyFactoryWithOptions(dst, false, false, CopyOptions{Resume: true})
+	test.That(t, err, test.ShouldBeNil)
+	copier, err := factory.MakeFileCopier(ctx, CopyFilesSourceTypeMultipleFiles)
+	test.That(t, err, test.ShouldBeNil)
+	f, err := os.Open(src)
+	test.That(t, err, test.ShouldBeNil)
+	err = copier.Copy(ctx, File{RelativeName: "big.bin", Data: &failingFile{File: f, remaining: 40000}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = os.Stat(filepath.Join(dst, "big.bin"))
+	test.That(t, errors.Is(err, fs.ErrNotExist), test.ShouldBeTrue)
+
+	manifest, err := BuildCopyManifest(dst, CopyManifestNames([]string{src}), false)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, manifest["big.bin"].PartialSize, test.ShouldEqual, 40000)
+
+	// the copy continues where it stopped.
+	opts := CopyOptions{Resume: true, Manifest: manifest}
+	offset, _ := opts.resumeOffset("big.bin", int64(len(data)))
+	test.That(t, offset, test.ShouldEqual, 40000)
+	test.That(t, copyWithOptions(t, src, dst, opts), test.ShouldBeNil)
+	received, err := os.ReadFile(filepath.Join(dst, "big.bin"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, received, test.ShouldResemble, data)
+	_, err = os.Stat(filepath.Join(dst, "big.bin.download"))
+	test.That(t, errors.Is(err, fs.ErrNotExist), test.ShouldBeTrue)
+
+	// a source that changed since the copy was interrupted cannot be resumed.
+	writeTestFile(t, filepath.Join(dst, "big.bin.download"), []byte("changed"))
+	manifest, err = BuildCopyManifest(dst, []string{"big.bin"}, false)
+	test.That(t, err, test.ShouldBeNil)
+	err = copyWithOptions(t, src, dst, CopyOptions{Resume: true, Manifest: manifest})
+	test.That(t, status.Code(err), test.ShouldEqual, codes.FailedPrecondition)
+}
+
+func TestCopyBandwidthLimit(t *testing.T) {
+	srcDir := t.TempDir()
+	src := filepath.Join(srcDir, "file.bin")
+	writeTestFile(t, src, make([]byte, 96*1024))
+
+	start := time.Now()
+	test.That(t, copyWithOptions(t, src, t.TempDir(), CopyOptions{BytesPerSec: 128 * 1024}), test.ShouldBeNil)
+	// the first 32KiB are a burst and the rest are sent at 128KiB/s.
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 400*time.Millisecond)
+}
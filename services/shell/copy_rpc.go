@@ -70,14 +70,35 @@ func NewCopyFileToMachineFactory(
 	}
 }
 
+// NewCopyFileToMachineFactoryWithOptions is NewCopyFileToMachineFactory for copies made with the
+// given options, which are passed to the service in the extra of CopyFilesToMachine.
+func NewCopyFileToMachineFactoryWithOptions(
+	destination string,
+	preserve bool,
+	shellSvc Service,
+	opts CopyOptions,
+) (FileCopyFactory, error) {
+	extra, err := opts.ToExtra(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &copyFileToMachineFactory{
+		destination: destination,
+		preserve:    preserve,
+		svc:         shellSvc,
+		extra:       extra,
+	}, nil
+}
+
 type copyFileToMachineFactory struct {
 	destination string
 	preserve    bool
 	svc         Service
+	extra       map[string]interface{}
 }
 
 func (f *copyFileToMachineFactory) MakeFileCopier(ctx context.Context, sourceType CopyFilesSourceType) (FileCopier, error) {
-	return f.svc.CopyFilesToMachine(ctx, sourceType, f.destination, f.preserve, nil)
+	return f.svc.CopyFilesToMachine(ctx, sourceType, f.destination, f.preserve, f.extra)
 }
 
 // A shellRPCCopyReader is a light abstraction around the different directions of
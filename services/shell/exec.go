@@ -0,0 +1,127 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.viam.com/rdk/resource"
+)
+
+// DoExec is the DoCommand key with which a shell service is asked to run a single command without a
+// terminal, as described by an ExecRequest, and respond with an ExecResult under the same key.
+// Unlike Shell, the command is not interpreted by a shell and its output is not mixed with anything
+// a terminal would add, so tooling can rely on its stdout, stderr, and exit code.
+const DoExec = "exec"
+
+// MaxExecOutput is how much of each of the stdout and stderr of an executed command is kept.
+const MaxExecOutput = 1 << 20
+
+// WindowChangeMessage is the out-of-band message, with "cols" and "rows", that resizes the
+// terminal of a Shell.
+const WindowChangeMessage = "window-change"
+
+// ShellEnvKey and ShellDirKey are keys in the extra of Shell. ShellEnvKey holds an object of
+// variables added to the environment of the shell and ShellDirKey the directory the shell starts in,
+// which is relative to the home directory if it is relative.
+const (
+	ShellEnvKey = "env"
+	ShellDirKey = "dir"
+)
+
+// NewWindowChange returns an out-of-band message that resizes the terminal of a Shell, to be sent
+// over its oobInput or passed in its extra under "messages".
+func NewWindowChange(cols, rows int) map[string]interface{} {
+	return map[string]interface{}{
+		"message": WindowChangeMessage,
+		"cols":    cols,
+		"rows":    rows,
+	}
+}
+
+// An ExecRequest describes a command for a shell service to run.
+type ExecRequest struct {
+	// Args holds the program to run followed by its arguments. The program is looked up in the PATH
+	// of the machine if it contains no path separator.
+	Args []string `json:"args"`
+	// Env holds variables added to the environment of the machine the command runs in.
+	Env map[string]string `json:"env,omitempty"`
+	// Dir is the directory the command runs in, relative to the home directory if it is relative.
+	// The home directory is used if it is empty.
+	Dir string `json:"dir,omitempty"`
+	// Stdin is written to the standard input of the command, which is otherwise empty.
+	Stdin string `json:"stdin,omitempty"`
+	// TimeoutMs is how long the command may run before it is killed. Zero means it runs until the
+	// request is canceled.
+	TimeoutMs int64 `json:"timeout_ms,omitempty"`
+}
+
+// Validate ensures the request is valid.
+func (req ExecRequest) Validate() error {
+	if len(req.Args) == 0 || req.Args[0] == "" {
+		return errors.New("args must name a program to run")
+	}
+	for k := range req.Env {
+		if k == "" || strings.ContainsAny(k, "=\x00") {
+			return fmt.Errorf("invalid environment variable name %q", k)
+		}
+	}
+	if req.TimeoutMs < 0 {
+		return errors.New("timeout_ms cannot be negative")
+	}
+	return nil
+}
+
+// An ExecResult describes how a command run by a shell service finished.
+type ExecResult struct {
+	// ExitCode is the exit code of the command, which is -1 if it was killed by a signal, including
+	// when it timed out.
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	// Truncated is whether more than MaxExecOutput was written to stdout or stderr, in which case
+	// only the first MaxExecOutput bytes of it are kept.
+	Truncated bool `json:"truncated,omitempty"`
+	TimedOut  bool `json:"timed_out,omitempty"`
+}
+
+// execCommand is the DoCommand through which an ExecRequest is sent.
+type execCommand struct {
+	Exec ExecRequest `json:"exec"`
+}
+
+func (cmd execCommand) Validate() error {
+	return cmd.Exec.Validate()
+}
+
+type execResponse struct {
+	Exec ExecResult `json:"exec"`
+}
+
+// Exec runs a command with the given shell service and returns how it finished. An error is only
+// returned if the command could not be run; a command that fails is described by its ExecResult.
+func Exec(ctx context.Context, svc Service, req ExecRequest) (ExecResult, error) {
+	resp, err := resource.DoCommandAs[execCommand, execResponse](ctx, svc, execCommand{Exec: req})
+	if err != nil {
+		return ExecResult{}, err
+	}
+	return resp.Exec, nil
+}
+
+// ExecRequestFromCommand returns the ExecRequest of a DoCommand sent by Exec, if it is one.
+func ExecRequestFromCommand(cmd map[string]interface{}) (ExecRequest, bool, error) {
+	if _, ok := cmd[DoExec]; !ok {
+		return ExecRequest{}, false, nil
+	}
+	decoded, err := resource.DecodeDoCommand[execCommand](cmd)
+	if err != nil {
+		return ExecRequest{}, true, err
+	}
+	return decoded.Exec, true, nil
+}
+
+// ExecResultToResponse returns the DoCommand response of an ExecResult.
+func ExecResultToResponse(result ExecResult) (map[string]interface{}, error) {
+	return resource.EncodeDoCommand(execResponse{Exec: result})
+}
@@ -125,6 +125,23 @@ func (c *client) Properties(ctx context.Context) (Properties, error) {
 	return prop, err
 }
 
+// Relocalize asks the remote SLAM service to relocalize against its prior map.
+func (c *client) Relocalize(ctx context.Context, initialGuess spatialmath.Pose) (LocalizationResult, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::client::Relocalize")
+	defer span.End()
+
+	return relocalizeThroughDoCommand(ctx, c.DoCommand, initialGuess)
+}
+
+// OccupancyGrid asks the remote SLAM service for an occupancy grid of its map. The grid is computed
+// on the server so the full pointcloud map does not have to be downloaded.
+func (c *client) OccupancyGrid(ctx context.Context, resolutionMM float64) (*OccupancyGrid, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::client::OccupancyGrid")
+	defer span.End()
+
+	return occupancyGridThroughDoCommand(ctx, c.DoCommand, resolutionMM)
+}
+
 func (c *client) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
 	ctx, span := trace.StartSpan(ctx, "slam::client::DoCommand")
 	defer span.End()
@@ -18,25 +18,54 @@ import (
 
 var model = resource.DefaultModelFamily.WithModel("fake")
 
-const datasetDirectory = "slam/example_cartographer_outputs/viam-office-02-22-3"
+const (
+	datasetDirectory = "slam/example_cartographer_outputs/viam-office-02-22-3"
+	// relocalizeConfidenceScaleMM is the guess error at which the fake reports a confidence of 0.5.
+	relocalizeConfidenceScaleMM = 1000.
+)
 
 func init() {
 	resource.RegisterService(
 		slam.API,
 		model,
-		resource.Registration[slam.Service, resource.NoNativeConfig]{
+		resource.Registration[slam.Service, *Config]{
 			Constructor: func(
 				ctx context.Context,
 				_ resource.Dependencies,
 				conf resource.Config,
 				logger logging.Logger,
 			) (slam.Service, error) {
-				return NewSLAM(conf.ResourceName(), logger), nil
+				slamSvc := NewSLAM(conf.ResourceName(), logger)
+				if conf.ConvertedAttributes == nil {
+					return slamSvc, nil
+				}
+				svcConf, err := resource.NativeConfig[*Config](conf)
+				if err != nil {
+					return nil, err
+				}
+				if svcConf.ExistingMap != "" || svcConf.EnableMapping != nil {
+					enableMapping := svcConf.EnableMapping != nil && *svcConf.EnableMapping
+					slamSvc.mappingMode = slam.MappingModeFromConfig(svcConf.ExistingMap, enableMapping)
+				}
+				return slamSvc, nil
 			},
 		},
 	)
 }
 
+// Config describes how the fake SLAM service pretends to have been started. When neither field is
+// set it acts as if it were localizing against the bundled dataset's map.
+type Config struct {
+	// ExistingMap is the path to the prior map, typically inside a packaged map.
+	ExistingMap   string `json:"existing_map,omitempty"`
+	EnableMapping *bool  `json:"enable_mapping,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	return nil, nil, nil
+}
+
 // SLAM is a fake slam that returns generic data.
 type SLAM struct {
 	resource.Named
@@ -45,6 +74,7 @@ type SLAM struct {
 	dataCount    int
 	logger       logging.Logger
 	mapTimestamp time.Time
+	mappingMode  slam.MappingMode
 }
 
 // NewSLAM is a constructor for a fake slam service.
@@ -54,6 +84,9 @@ func NewSLAM(name resource.Name, logger logging.Logger) *SLAM {
 		logger:       logger,
 		dataCount:    -1,
 		mapTimestamp: time.Now().UTC(),
+		// MappingModeLocalizationOnly may cause the frontend to not refresh, but it allows motion to work with
+		// fakeslam. Can make changes in motion to only restrict for cartographer if this becomes a problem.
+		mappingMode: slam.MappingModeLocalizationOnly,
 	}
 }
 
@@ -90,16 +123,14 @@ func (slamSvc *SLAM) InternalState(ctx context.Context) (func() ([]byte, error),
 
 // Properties returns the mapping mode of the slam service as well as a boolean indicating if it is running
 // in the cloud or locally. In the case of fake slam, it will return that the service is being run locally
-// and is creating a new map.
+// in the mapping mode derived from its config.
 func (slamSvc *SLAM) Properties(ctx context.Context) (slam.Properties, error) {
 	_, span := trace.StartSpan(ctx, "slam::fake::Properties")
 	defer span.End()
 
-	// MappingModeLocalizationOnly may cause the frontend to not refresh, but it allows motion to work with
-	// fakeslam. Can make changes in motion to only restrict for cartographer if this becomes a problem.
 	prop := slam.Properties{
 		CloudSlam:             false,
-		MappingMode:           slam.MappingModeLocalizationOnly,
+		MappingMode:           slamSvc.mappingMode,
 		InternalStateFileType: ".pbstream",
 		SensorInfo: []slam.SensorInfo{
 			{Name: "my-camera", Type: slam.SensorTypeCamera},
@@ -109,6 +140,37 @@ func (slamSvc *SLAM) Properties(ctx context.Context) (slam.Properties, error) {
 	return prop, nil
 }
 
+// Relocalize reports the current dataset position as the relocalized pose without advancing the
+// dataset. When an initial guess is given, the reported confidence decays with the distance between
+// the guess and that position. A fake building a new map has nothing to relocalize against.
+func (slamSvc *SLAM) Relocalize(ctx context.Context, initialGuess spatialmath.Pose) (slam.LocalizationResult, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::fake::Relocalize")
+	defer span.End()
+
+	if slamSvc.mappingMode == slam.MappingModeNewMap {
+		return slam.LocalizationResult{}, slam.ErrRelocalizeUnsupported
+	}
+
+	p, err := fakePosition(ctx, datasetDirectory, slamSvc)
+	if err != nil {
+		return slam.LocalizationResult{}, err
+	}
+	confidence := 1.
+	if initialGuess != nil {
+		dist := initialGuess.Point().Distance(p.Point())
+		confidence = 1 / (1 + dist/relocalizeConfidenceScaleMM)
+	}
+	return slam.LocalizationResult{Pose: p, Confidence: confidence}, nil
+}
+
+// DoCommand supports the slam.DoRelocalize command for callers in the same process.
+func (slamSvc *SLAM) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, handled, err := slam.HandleRelocalizeCommand(ctx, slamSvc, cmd); handled {
+		return resp, err
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
 // incrementDataCount is not thread safe but that is ok as we only intend a single user to be interacting
 // with it at a time.
 func (slamSvc *SLAM) incrementDataCount() {
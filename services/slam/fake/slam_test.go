@@ -16,6 +16,7 @@ import (
 
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/slam"
 	"go.viam.com/rdk/spatialmath"
 )
@@ -39,6 +40,68 @@ func TestFakeSLAMPosition(t *testing.T) {
 	test.That(t, p, test.ShouldResemble, p2)
 }
 
+func TestFakeSLAMRelocalize(t *testing.T) {
+	slamSvc := NewSLAM(slam.Named("test"), logging.NewTestLogger(t))
+
+	p, err := slamSvc.Position(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	count := slamSvc.getCount()
+
+	res, err := slamSvc.Relocalize(context.Background(), nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqual(res.Pose, p), test.ShouldBeTrue)
+	test.That(t, res.Confidence, test.ShouldEqual, 1.)
+
+	guess := spatialmath.NewPoseFromPoint(p.Point().Add(r3.Vector{X: relocalizeConfidenceScaleMM}))
+	res, err = slamSvc.Relocalize(context.Background(), guess)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, res.Confidence, test.ShouldAlmostEqual, 0.5)
+	test.That(t, slamSvc.getCount(), test.ShouldEqual, count)
+
+	resp, err := slamSvc.DoCommand(context.Background(), map[string]interface{}{slam.DoRelocalize: map[string]interface{}{}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[slam.DoRelocalizeConfidence], test.ShouldEqual, 1.)
+}
+
+func TestFakeSLAMMappingMode(t *testing.T) {
+	logger := logging.NewTestLogger(t)
+	build := func(attrs *Config) slam.Service {
+		t.Helper()
+		reg, ok := resource.LookupRegistration(slam.API, model)
+		test.That(t, ok, test.ShouldBeTrue)
+		conf := resource.Config{Name: "test", API: slam.API, Model: model}
+		if attrs != nil {
+			conf.ConvertedAttributes = attrs
+		}
+		res, err := reg.Constructor(context.Background(), nil, conf, logger)
+		test.That(t, err, test.ShouldBeNil)
+		return res.(slam.Service)
+	}
+	enabled := true
+
+	for _, tc := range []struct {
+		conf *Config
+		mode slam.MappingMode
+	}{
+		{nil, slam.MappingModeLocalizationOnly},
+		{&Config{ExistingMap: "${packages.my-map}/map.pbstream"}, slam.MappingModeLocalizationOnly},
+		{&Config{ExistingMap: "${packages.my-map}/map.pbstream", EnableMapping: &enabled}, slam.MappingModeUpdateExistingMap},
+		{&Config{EnableMapping: &enabled}, slam.MappingModeNewMap},
+	} {
+		svc := build(tc.conf)
+		prop, err := svc.Properties(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, prop.MappingMode, test.ShouldEqual, tc.mode)
+
+		_, err = svc.(slam.Relocalizer).Relocalize(context.Background(), nil)
+		if tc.mode == slam.MappingModeNewMap {
+			test.That(t, err, test.ShouldBeError, slam.ErrRelocalizeUnsupported)
+		} else {
+			test.That(t, err, test.ShouldBeNil)
+		}
+	}
+}
+
 func TestFakeProperties(t *testing.T) {
 	slamSvc := NewSLAM(slam.Named("test"), logging.NewTestLogger(t))
 
@@ -93,6 +93,9 @@ func sensorTypeToProtobuf(sensorType SensorType) pb.SensorType {
 	switch sensorType {
 	case SensorTypeCamera:
 		return pb.SensorType_SENSOR_TYPE_CAMERA
+	case SensorTypeLidar:
+		// the proto has no lidars, which are sent as the cameras they were configured as before.
+		return pb.SensorType_SENSOR_TYPE_CAMERA
 	case SensorTypeMovementSensor:
 		return pb.SensorType_SENSOR_TYPE_MOVEMENT_SENSOR
 	default:
@@ -0,0 +1,268 @@
+package slam
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/utils/trace"
+
+	"go.viam.com/rdk/pointcloud"
+)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoOccupancyGrid             = "occupancy_grid"
+	DoOccupancyGridResolutionMM = "resolution_mm"
+	DoOccupancyGridOrigin       = "origin"
+	DoOccupancyGridWidth        = "width"
+	DoOccupancyGridHeight       = "height"
+	DoOccupancyGridCells        = "cells"
+)
+
+const (
+	// MaxOccupancyGridCells bounds the number of cells in an occupancy grid so that a fine resolution
+	// over a large map fails instead of exhausting memory.
+	MaxOccupancyGridCells = 1 << 24
+	// OccupancyUnknown marks a grid cell for which the map holds no information.
+	OccupancyUnknown = int8(-1)
+	// DefaultOccupiedThreshold is the probability (0-100) at or above which a cell is considered occupied.
+	DefaultOccupiedThreshold = 50
+	// defaultPointProbability is assigned to map points carrying neither a color nor a value, matching
+	// how the pointcloud octree treats such points as collision candidates.
+	defaultPointProbability = 50
+)
+
+// OccupancyGrid is a 2D rasterization of a SLAM map in the map's XY plane. Cells are stored row-major
+// with row index increasing along +Y and column index increasing along +X.
+type OccupancyGrid struct {
+	// ResolutionMM is the side length of a single square cell in millimeters.
+	ResolutionMM float64
+	// Origin is the map-frame position of the minimum corner of cell (0, 0).
+	Origin r3.Vector
+	Width  int
+	Height int
+	// Cells holds an occupancy probability in [0, 100] per cell, or OccupancyUnknown.
+	Cells []int8
+}
+
+// OccupancyGridder is an optional interface implemented by SLAM services that can produce an
+// occupancy grid natively instead of having one derived from their pointcloud map.
+type OccupancyGridder interface {
+	OccupancyGrid(ctx context.Context, resolutionMM float64) (*OccupancyGrid, error)
+}
+
+// OccupancyGridMap returns a 2D occupancy grid of the SLAM service's current map at the given
+// resolution. Services implementing OccupancyGridder are asked directly, which includes SLAM clients
+// whose server rasterizes the map; otherwise the grid is derived from the pointcloud map.
+func OccupancyGridMap(ctx context.Context, svc Service, resolutionMM float64) (*OccupancyGrid, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::OccupancyGridMap")
+	defer span.End()
+
+	if g, ok := svc.(OccupancyGridder); ok {
+		return g.OccupancyGrid(ctx, resolutionMM)
+	}
+
+	data, err := PointCloudMapFull(ctx, svc, false)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := pointcloud.ReadPCD(bytes.NewReader(data), "")
+	if err != nil {
+		return nil, err
+	}
+	return NewOccupancyGridFromPointCloud(pc, resolutionMM)
+}
+
+// NewOccupancyGridFromPointCloud rasterizes a pointcloud map into an occupancy grid. Each cell takes the
+// highest probability of the points projected into it; cells without points are OccupancyUnknown.
+func NewOccupancyGridFromPointCloud(pc pointcloud.PointCloud, resolutionMM float64) (*OccupancyGrid, error) {
+	if resolutionMM <= 0 {
+		return nil, errors.Errorf("occupancy grid resolution must be positive, got %v", resolutionMM)
+	}
+	if pc.Size() == 0 {
+		return &OccupancyGrid{ResolutionMM: resolutionMM}, nil
+	}
+
+	md := pc.MetaData()
+	width := math.Floor((md.MaxX-md.MinX)/resolutionMM) + 1
+	height := math.Floor((md.MaxY-md.MinY)/resolutionMM) + 1
+	if width*height > MaxOccupancyGridCells {
+		return nil, errors.Errorf(
+			"an occupancy grid of the map at %vmm resolution would have %vx%v cells, more than the maximum of %d; use a coarser resolution",
+			resolutionMM, width, height, MaxOccupancyGridCells)
+	}
+	grid := &OccupancyGrid{
+		ResolutionMM: resolutionMM,
+		Origin:       r3.Vector{X: md.MinX, Y: md.MinY},
+		Width:        int(width),
+		Height:       int(height),
+	}
+	grid.Cells = make([]int8, grid.Width*grid.Height)
+	for i := range grid.Cells {
+		grid.Cells[i] = OccupancyUnknown
+	}
+
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		idx, ok := grid.index(p)
+		if !ok {
+			return true
+		}
+		if prob := pointProbability(d); prob > grid.Cells[idx] {
+			grid.Cells[idx] = prob
+		}
+		return true
+	})
+	return grid, nil
+}
+
+// occupancyGridThroughDoCommand asks a SLAM service reached over gRPC for an occupancy grid. There is
+// no dedicated RPC, so the request travels as a DoOccupancyGrid command and the server rasterizes
+// the map next to the service.
+func occupancyGridThroughDoCommand(
+	ctx context.Context,
+	doCommand func(context.Context, map[string]interface{}) (map[string]interface{}, error),
+	resolutionMM float64,
+) (*OccupancyGrid, error) {
+	resp, err := doCommand(ctx, map[string]interface{}{
+		DoOccupancyGrid: map[string]interface{}{DoOccupancyGridResolutionMM: resolutionMM},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return occupancyGridFromMap(resp, resolutionMM)
+}
+
+// HandleOccupancyGridCommand services a DoOccupancyGrid command by rasterizing the service's map
+// with OccupancyGridMap. It returns false if cmd is not an occupancy grid command so that it can be
+// chained from a DoCommand implementation.
+func HandleOccupancyGridCommand(
+	ctx context.Context,
+	svc Service,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	rawArgs, ok := cmd[DoOccupancyGrid]
+	if !ok {
+		return nil, false, nil
+	}
+	args, ok := rawArgs.(map[string]interface{})
+	if !ok {
+		return nil, true, errors.Errorf("expected %q to be a map but got %T", DoOccupancyGrid, rawArgs)
+	}
+	resolutionMM, ok := args[DoOccupancyGridResolutionMM].(float64)
+	if !ok {
+		return nil, true, errors.Errorf("expected %q to be a number but got %T",
+			DoOccupancyGridResolutionMM, args[DoOccupancyGridResolutionMM])
+	}
+
+	grid, err := OccupancyGridMap(ctx, svc, resolutionMM)
+	if err != nil {
+		return nil, true, err
+	}
+	cells := make([]byte, len(grid.Cells))
+	for i, v := range grid.Cells {
+		cells[i] = byte(v)
+	}
+	return map[string]interface{}{
+		DoOccupancyGridOrigin: map[string]interface{}{"x": grid.Origin.X, "y": grid.Origin.Y, "z": grid.Origin.Z},
+		DoOccupancyGridWidth:  float64(grid.Width),
+		DoOccupancyGridHeight: float64(grid.Height),
+		DoOccupancyGridCells:  base64.StdEncoding.EncodeToString(cells),
+	}, true, nil
+}
+
+func occupancyGridFromMap(resp map[string]interface{}, resolutionMM float64) (*OccupancyGrid, error) {
+	origin, ok := resp[DoOccupancyGridOrigin].(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected %q to be a map but got %T", DoOccupancyGridOrigin, resp[DoOccupancyGridOrigin])
+	}
+	width, ok := resp[DoOccupancyGridWidth].(float64)
+	if !ok {
+		return nil, errors.Errorf("expected %q to be a number but got %T", DoOccupancyGridWidth, resp[DoOccupancyGridWidth])
+	}
+	height, ok := resp[DoOccupancyGridHeight].(float64)
+	if !ok {
+		return nil, errors.Errorf("expected %q to be a number but got %T", DoOccupancyGridHeight, resp[DoOccupancyGridHeight])
+	}
+	encoded, ok := resp[DoOccupancyGridCells].(string)
+	if !ok {
+		return nil, errors.Errorf("expected %q to be a string but got %T", DoOccupancyGridCells, resp[DoOccupancyGridCells])
+	}
+	cells, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(cells) != int(width)*int(height) {
+		return nil, errors.Errorf("occupancy grid of %vx%v cells holds %d values", width, height, len(cells))
+	}
+
+	grid := &OccupancyGrid{
+		ResolutionMM: resolutionMM,
+		Width:        int(width),
+		Height:       int(height),
+		Cells:        make([]int8, len(cells)),
+	}
+	grid.Origin.X, _ = origin["x"].(float64)
+	grid.Origin.Y, _ = origin["y"].(float64)
+	grid.Origin.Z, _ = origin["z"].(float64)
+	for i, v := range cells {
+		grid.Cells[i] = int8(v)
+	}
+	return grid, nil
+}
+
+// At returns the value of the cell containing the given map-frame point. The second return is false
+// if the point lies outside of the grid.
+func (g *OccupancyGrid) At(p r3.Vector) (int8, bool) {
+	idx, ok := g.index(p)
+	if !ok {
+		return OccupancyUnknown, false
+	}
+	return g.Cells[idx], true
+}
+
+// Traversable reports whether the cell containing the given map-frame point is known and has an
+// occupancy probability below occupiedThreshold. Points outside of the grid are not traversable.
+func (g *OccupancyGrid) Traversable(p r3.Vector, occupiedThreshold int) bool {
+	v, ok := g.At(p)
+	return ok && v != OccupancyUnknown && int(v) < occupiedThreshold
+}
+
+// TraversabilityMap returns a row-major mask, laid out like Cells, that is true for every traversable cell.
+func (g *OccupancyGrid) TraversabilityMap(occupiedThreshold int) []bool {
+	mask := make([]bool, len(g.Cells))
+	for i, v := range g.Cells {
+		mask[i] = v != OccupancyUnknown && int(v) < occupiedThreshold
+	}
+	return mask
+}
+
+func (g *OccupancyGrid) index(p r3.Vector) (int, bool) {
+	if g.ResolutionMM <= 0 {
+		return 0, false
+	}
+	col := int(math.Floor((p.X - g.Origin.X) / g.ResolutionMM))
+	row := int(math.Floor((p.Y - g.Origin.Y) / g.ResolutionMM))
+	if col < 0 || row < 0 || col >= g.Width || row >= g.Height {
+		return 0, false
+	}
+	return row*g.Width + col, true
+}
+
+// pointProbability extracts the occupancy probability SLAM encodes in a map point, which is either
+// the blue channel of its color or its value.
+func pointProbability(d pointcloud.Data) int8 {
+	prob := defaultPointProbability
+	switch {
+	case d == nil:
+	case d.HasColor():
+		_, _, b := d.RGB255()
+		prob = int(b)
+	case d.HasValue():
+		prob = d.Value()
+	}
+	return int8(math.Max(0, math.Min(100, float64(prob))))
+}
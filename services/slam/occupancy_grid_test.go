@@ -0,0 +1,113 @@
+package slam_test
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"io"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestNewOccupancyGridFromPointCloud(t *testing.T) {
+	pc := pointcloud.NewBasicEmpty()
+	test.That(t, pc.Set(r3.Vector{X: 0, Y: 0}, pointcloud.NewValueData(10)), test.ShouldBeNil)
+	test.That(t, pc.Set(r3.Vector{X: 40, Y: 5}, pointcloud.NewValueData(90)), test.ShouldBeNil)
+	test.That(t, pc.Set(r3.Vector{X: 45, Y: 8, Z: 100}, pointcloud.NewValueData(20)), test.ShouldBeNil)
+	test.That(t, pc.Set(r3.Vector{X: 0, Y: 30}, pointcloud.NewColoredData(color.NRGBA{B: 70, A: 255})), test.ShouldBeNil)
+
+	_, err := slam.NewOccupancyGridFromPointCloud(pc, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	grid, err := slam.NewOccupancyGridFromPointCloud(pc, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, grid.Origin, test.ShouldResemble, r3.Vector{})
+	test.That(t, grid.Width, test.ShouldEqual, 5)
+	test.That(t, grid.Height, test.ShouldEqual, 4)
+	test.That(t, len(grid.Cells), test.ShouldEqual, 20)
+
+	v, ok := grid.At(r3.Vector{X: 1, Y: 1})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, v, test.ShouldEqual, 10)
+
+	// the highest probability in a cell wins regardless of height
+	v, ok = grid.At(r3.Vector{X: 42, Y: 2})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, v, test.ShouldEqual, 90)
+
+	v, ok = grid.At(r3.Vector{X: 5, Y: 35})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, v, test.ShouldEqual, 70)
+
+	v, ok = grid.At(r3.Vector{X: 25, Y: 15})
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, v, test.ShouldEqual, slam.OccupancyUnknown)
+
+	_, ok = grid.At(r3.Vector{X: -5, Y: 0})
+	test.That(t, ok, test.ShouldBeFalse)
+
+	test.That(t, grid.Traversable(r3.Vector{X: 1, Y: 1}, slam.DefaultOccupiedThreshold), test.ShouldBeTrue)
+	test.That(t, grid.Traversable(r3.Vector{X: 42, Y: 2}, slam.DefaultOccupiedThreshold), test.ShouldBeFalse)
+	test.That(t, grid.Traversable(r3.Vector{X: 25, Y: 15}, slam.DefaultOccupiedThreshold), test.ShouldBeFalse)
+	test.That(t, grid.Traversable(r3.Vector{X: 500, Y: 500}, slam.DefaultOccupiedThreshold), test.ShouldBeFalse)
+
+	mask := grid.TraversabilityMap(slam.DefaultOccupiedThreshold)
+	traversable := 0
+	for _, ok := range mask {
+		if ok {
+			traversable++
+		}
+	}
+	test.That(t, traversable, test.ShouldEqual, 1)
+}
+
+func TestOccupancyGridMap(t *testing.T) {
+	pc := pointcloud.NewBasicEmpty()
+	// PCD keeps colors but not values, so probabilities are carried in the blue channel as SLAM does
+	test.That(t, pc.Set(r3.Vector{X: 100, Y: 200}, pointcloud.NewColoredData(color.NRGBA{B: 80, A: 255})), test.ShouldBeNil)
+	test.That(t, pc.Set(r3.Vector{X: 300, Y: 200}, pointcloud.NewColoredData(color.NRGBA{B: 5, A: 255})), test.ShouldBeNil)
+	var buf bytes.Buffer
+	test.That(t, pointcloud.ToPCD(pc, &buf, pointcloud.PCDBinary), test.ShouldBeNil)
+
+	svc := inject.NewSLAMService("test")
+	svc.PointCloudMapFunc = func(ctx context.Context, returnEditedMap bool) (func() ([]byte, error), error) {
+		sent := false
+		return func() ([]byte, error) {
+			if sent {
+				return nil, io.EOF
+			}
+			sent = true
+			return buf.Bytes(), nil
+		}, nil
+	}
+
+	for _, tc := range []struct {
+		name string
+		svc  slam.Service
+	}{
+		{"local", svc},
+		{"over grpc", newSLAMClient(t, svc)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			grid, err := slam.OccupancyGridMap(context.Background(), tc.svc, 50)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, grid.ResolutionMM, test.ShouldEqual, 50)
+			test.That(t, grid.Origin, test.ShouldResemble, r3.Vector{X: 100, Y: 200})
+			test.That(t, grid.Width, test.ShouldEqual, 5)
+			test.That(t, grid.Height, test.ShouldEqual, 1)
+			test.That(t, grid.Cells, test.ShouldResemble, []int8{80, -1, -1, -1, 5})
+		})
+	}
+
+	t.Run("the server reports grids that are too large", func(t *testing.T) {
+		_, err := slam.OccupancyGridMap(context.Background(), newSLAMClient(t, svc), 1e-5)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "coarser resolution")
+	})
+}
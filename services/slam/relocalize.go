@@ -0,0 +1,161 @@
+package slam
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	commonpb "go.viam.com/api/common/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoRelocalize            = "relocalize"
+	DoRelocalizeInitialPose = "initial_pose"
+	DoRelocalizePose        = "pose"
+	DoRelocalizeConfidence  = "confidence"
+)
+
+// ErrRelocalizeUnsupported is returned when a SLAM service cannot relocalize against its map,
+// for example because it is building a new map rather than running against a prior one.
+var ErrRelocalizeUnsupported = errors.New("slam service does not support relocalization")
+
+// Relocalizer is an optional interface implemented by SLAM services that can relocalize against a
+// prior map. SLAM clients implement it, and the server returns codes.Unimplemented when the service
+// behind them does not.
+//
+// Relocalize example:
+//
+//	// Drop the current pose estimate and search the whole prior map for the robot.
+//	if r, ok := mySLAMService.(slam.Relocalizer); ok {
+//	    result, err := r.Relocalize(context.Background(), nil)
+//	    fmt.Printf("at %v with confidence %.2f\n", result.Pose, result.Confidence)
+//	}
+type Relocalizer interface {
+	// Relocalize drops the current pose estimate and localizes against the prior map, starting
+	// from initialGuess if it is not nil and searching the whole map otherwise.
+	Relocalize(ctx context.Context, initialGuess spatialmath.Pose) (LocalizationResult, error)
+}
+
+// LocalizationResult is the outcome of relocalizing against a prior map.
+type LocalizationResult struct {
+	// Pose is the estimated pose of the robot in the map frame.
+	Pose spatialmath.Pose
+	// Confidence is a value in [0, 1] describing how well the sensor data matched the map.
+	Confidence float64
+}
+
+// relocalizeThroughDoCommand asks a SLAM service reached over gRPC to relocalize. There is no
+// dedicated RPC, so the request travels as a DoRelocalize command that the server hands to the
+// service's Relocalize method.
+func relocalizeThroughDoCommand(
+	ctx context.Context,
+	doCommand func(context.Context, map[string]interface{}) (map[string]interface{}, error),
+	initialGuess spatialmath.Pose,
+) (LocalizationResult, error) {
+	args := map[string]interface{}{}
+	if initialGuess != nil {
+		poseMap, err := poseToMap(initialGuess)
+		if err != nil {
+			return LocalizationResult{}, err
+		}
+		args[DoRelocalizeInitialPose] = poseMap
+	}
+	resp, err := doCommand(ctx, map[string]interface{}{DoRelocalize: args})
+	if err != nil {
+		return LocalizationResult{}, err
+	}
+	return relocalizeResultFromMap(resp)
+}
+
+// HandleRelocalizeCommand services a DoRelocalize command by calling the service's Relocalize
+// method, returning codes.Unimplemented if it is not a Relocalizer. It returns false if cmd is not
+// a relocalize command so that it can be chained from a DoCommand implementation.
+func HandleRelocalizeCommand(
+	ctx context.Context,
+	svc Service,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	rawArgs, ok := cmd[DoRelocalize]
+	if !ok {
+		return nil, false, nil
+	}
+	r, ok := svc.(Relocalizer)
+	if !ok {
+		return nil, true, status.Error(codes.Unimplemented, ErrRelocalizeUnsupported.Error())
+	}
+	var initialGuess spatialmath.Pose
+	if args, ok := rawArgs.(map[string]interface{}); ok {
+		if rawPose, ok := args[DoRelocalizeInitialPose]; ok {
+			poseMap, ok := rawPose.(map[string]interface{})
+			if !ok {
+				return nil, true, errors.Errorf("expected %q to be a map but got %T", DoRelocalizeInitialPose, rawPose)
+			}
+			p, err := poseFromMap(poseMap)
+			if err != nil {
+				return nil, true, err
+			}
+			initialGuess = p
+		}
+	}
+
+	res, err := r.Relocalize(ctx, initialGuess)
+	if err != nil {
+		return nil, true, err
+	}
+	poseMap, err := poseToMap(res.Pose)
+	if err != nil {
+		return nil, true, err
+	}
+	return map[string]interface{}{
+		DoRelocalizePose:       poseMap,
+		DoRelocalizeConfidence: res.Confidence,
+	}, true, nil
+}
+
+func relocalizeResultFromMap(resp map[string]interface{}) (LocalizationResult, error) {
+	rawPose, ok := resp[DoRelocalizePose].(map[string]interface{})
+	if !ok {
+		return LocalizationResult{}, ErrRelocalizeUnsupported
+	}
+	p, err := poseFromMap(rawPose)
+	if err != nil {
+		return LocalizationResult{}, err
+	}
+	confidence, ok := resp[DoRelocalizeConfidence].(float64)
+	if !ok {
+		return LocalizationResult{}, errors.Errorf("expected %q to be a number but got %T", DoRelocalizeConfidence, resp[DoRelocalizeConfidence])
+	}
+	return LocalizationResult{Pose: p, Confidence: confidence}, nil
+}
+
+// poseToMap encodes a pose in the JSON form of its protobuf representation so it survives
+// the structpb conversion DoCommand goes through over the wire.
+func poseToMap(p spatialmath.Pose) (map[string]interface{}, error) {
+	b, err := protojson.Marshal(spatialmath.PoseToProtobuf(p))
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func poseFromMap(m map[string]interface{}) (spatialmath.Pose, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var p commonpb.Pose
+	if err := protojson.Unmarshal(b, &p); err != nil {
+		return nil, err
+	}
+	return spatialmath.NewPoseFromProtobuf(&p), nil
+}
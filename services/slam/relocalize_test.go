@@ -0,0 +1,106 @@
+package slam_test
+
+import (
+	"context"
+	"math"
+	"net"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	viamgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/slam"
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// newSLAMClient serves svc over gRPC and returns a client connected to it.
+func newSLAMClient(t *testing.T, svc slam.Service) slam.Service {
+	t.Helper()
+	logger := logging.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	rpcServer, err := rpc.NewServer(logger, rpc.WithUnauthenticated())
+	test.That(t, err, test.ShouldBeNil)
+
+	svcs, err := resource.NewAPIResourceCollection(slam.API, map[resource.Name]slam.Service{svc.Name(): svc})
+	test.That(t, err, test.ShouldBeNil)
+	resourceAPI, ok, err := resource.LookupAPIRegistration[slam.Service](slam.API)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	resourceAPI.RegisterRPCService(context.Background(), rpcServer, svcs, logger)
+	go rpcServer.Serve(listener)
+	t.Cleanup(func() { test.That(t, rpcServer.Stop(), test.ShouldBeNil) })
+
+	conn, err := viamgrpc.Dial(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { test.That(t, conn.Close(), test.ShouldBeNil) })
+	client, err := slam.NewClientFromConn(context.Background(), conn, "", svc.Name(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	return client
+}
+
+func TestRelocalizeOverGRPC(t *testing.T) {
+	expected := spatial.NewPose(r3.Vector{X: 1, Y: 2, Z: 3}, &spatial.OrientationVector{Theta: math.Pi / 2, OZ: 1})
+	var receivedGuess spatial.Pose
+	relocalizeErr := error(nil)
+	injectSvc := inject.NewSLAMService("test")
+	injectSvc.RelocalizeFunc = func(ctx context.Context, initialGuess spatial.Pose) (slam.LocalizationResult, error) {
+		receivedGuess = initialGuess
+		if relocalizeErr != nil {
+			return slam.LocalizationResult{}, relocalizeErr
+		}
+		return slam.LocalizationResult{Pose: expected, Confidence: 0.75}, nil
+	}
+	// the server hands relocalize commands to Relocalize without going through DoCommand
+	injectSvc.DoCommandFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		return nil, resource.ErrDoUnimplemented
+	}
+
+	client := newSLAMClient(t, injectSvc)
+
+	t.Run("without an initial guess", func(t *testing.T) {
+		res, err := client.(slam.Relocalizer).Relocalize(context.Background(), nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, receivedGuess, test.ShouldBeNil)
+		test.That(t, spatial.PoseAlmostEqual(res.Pose, expected), test.ShouldBeTrue)
+		test.That(t, res.Confidence, test.ShouldEqual, 0.75)
+	})
+
+	t.Run("with an initial guess", func(t *testing.T) {
+		guess := spatial.NewPoseFromPoint(r3.Vector{X: 10, Y: 20})
+		res, err := client.(slam.Relocalizer).Relocalize(context.Background(), guess)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, spatial.PoseAlmostEqual(receivedGuess, guess), test.ShouldBeTrue)
+		test.That(t, spatial.PoseAlmostEqual(res.Pose, expected), test.ShouldBeTrue)
+	})
+
+	t.Run("relocalize errors are returned", func(t *testing.T) {
+		relocalizeErr = errors.New("no match in map")
+		_, err := client.(slam.Relocalizer).Relocalize(context.Background(), nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "no match in map")
+	})
+}
+
+func TestRelocalizeUnimplemented(t *testing.T) {
+	// embedding the Service interface hides the Relocalize method of the injected service.
+	svc := struct{ slam.Service }{inject.NewSLAMService("test")}
+	client := newSLAMClient(t, svc)
+	_, err := client.(slam.Relocalizer).Relocalize(context.Background(), nil)
+	test.That(t, status.Code(err), test.ShouldEqual, codes.Unimplemented)
+}
+
+func TestMappingModeFromConfig(t *testing.T) {
+	test.That(t, slam.MappingModeFromConfig("", true), test.ShouldEqual, slam.MappingModeNewMap)
+	test.That(t, slam.MappingModeFromConfig("", false), test.ShouldEqual, slam.MappingModeNewMap)
+	test.That(t, slam.MappingModeFromConfig("map.pbstream", false), test.ShouldEqual, slam.MappingModeLocalizationOnly)
+	test.That(t, slam.MappingModeFromConfig("map.pbstream", true), test.ShouldEqual, slam.MappingModeUpdateExistingMap)
+}
@@ -0,0 +1,44 @@
+package slam
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/lidar"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+)
+
+// NextPointCloud returns the next point cloud of a sensor of a SLAM service, in the sensor's frame,
+// and when it was captured, so that SLAM services can map with lidars and depth cameras alike. The
+// point cloud of a lidar is its latest scan, captured when the scan ended, and that of a camera is
+// taken to be captured now.
+func NextPointCloud(ctx context.Context, provider resource.Provider, sensor SensorInfo) (pointcloud.PointCloud, time.Time, error) {
+	switch sensor.Type {
+	case SensorTypeLidar:
+		l, err := lidar.FromProvider(provider, sensor.Name)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		scan, err := l.Scan(ctx, nil)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		pc, err := scan.PointCloud()
+		return pc, scan.EndedAt, err
+	case SensorTypeCamera:
+		cam, err := camera.FromProvider(provider, sensor.Name)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		pc, err := cam.NextPointCloud(ctx, nil)
+		return pc, time.Now(), err
+	case SensorTypeMovementSensor:
+		fallthrough
+	default:
+		return nil, time.Time{}, errors.Errorf("%s %s does not capture point clouds", sensor.Type, sensor.Name)
+	}
+}
@@ -0,0 +1,79 @@
+package slam_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/lidar"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+type testLidar struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	scan *lidar.Scan
+}
+
+func (l *testLidar) Scan(ctx context.Context, extra map[string]interface{}) (*lidar.Scan, error) {
+	return l.scan, nil
+}
+
+func (l *testLidar) Properties(ctx context.Context) (lidar.Properties, error) {
+	return lidar.Properties{Kind: lidar.KindSpinning, Channels: 1}, nil
+}
+
+func (l *testLidar) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return lidar.ScanToReadings(l.scan)
+}
+
+func (l *testLidar) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	return nil, resource.ErrDoUnimplemented
+}
+
+func TestNextPointCloud(t *testing.T) {
+	ctx := context.Background()
+	ended := time.Now().Add(-time.Second)
+	l := &testLidar{
+		Named: lidar.Named("lidar").AsNamed(),
+		scan: &lidar.Scan{
+			Measurements: []lidar.Measurement{{RangeMM: 1000}, {}},
+			StartedAt:    ended.Add(-100 * time.Millisecond),
+			EndedAt:      ended,
+		},
+	}
+	cam := inject.NewCamera("cam")
+	cam.NextPointCloudFunc = func(ctx context.Context, extra map[string]interface{}) (pointcloud.PointCloud, error) {
+		pc := pointcloud.NewBasicEmpty()
+		return pc, pc.Set(r3.Vector{Z: 500}, nil)
+	}
+	deps := resource.Dependencies{
+		lidar.Named("lidar"):        l,
+		camera.Named("cam"):         cam,
+		movementsensor.Named("imu"): inject.NewMovementSensor("imu"),
+	}
+
+	pc, capturedAt, err := slam.NextPointCloud(ctx, deps, slam.SensorInfo{Name: "lidar", Type: slam.SensorTypeLidar})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 1)
+	test.That(t, capturedAt, test.ShouldEqual, ended)
+
+	pc, capturedAt, err = slam.NextPointCloud(ctx, deps, slam.SensorInfo{Name: "cam", Type: slam.SensorTypeCamera})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 1)
+	test.That(t, capturedAt.After(ended), test.ShouldBeTrue)
+
+	_, _, err = slam.NextPointCloud(ctx, deps, slam.SensorInfo{Name: "imu", Type: slam.SensorTypeMovementSensor})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = slam.NextPointCloud(ctx, deps, slam.SensorInfo{Name: "cam", Type: slam.SensorTypeLidar})
+	test.That(t, err, test.ShouldNotBeNil)
+}
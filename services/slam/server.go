@@ -8,6 +8,7 @@ import (
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/service/slam/v1"
 	"go.viam.com/utils/trace"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/logging"
 	"go.viam.com/rdk/protoutils"
@@ -171,6 +172,24 @@ func (server *serviceServer) DoCommand(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
+	var handle func(context.Context, Service, map[string]interface{}) (map[string]interface{}, bool, error)
+	fields := req.GetCommand().GetFields()
+	if _, ok := fields[DoRelocalize]; ok {
+		handle = HandleRelocalizeCommand
+	} else if _, ok := fields[DoOccupancyGrid]; ok {
+		handle = HandleOccupancyGridCommand
+	}
+	if handle != nil {
+		resp, _, err := handle(ctx, svc, req.GetCommand().AsMap())
+		if err != nil {
+			return nil, err
+		}
+		res, err := structpb.NewStruct(resp)
+		if err != nil {
+			return nil, err
+		}
+		return &commonpb.DoCommandResponse{Result: res}, nil
+	}
 	return protoutils.DoFromResourceServer(ctx, svc, req)
 }
 
@@ -69,6 +69,7 @@ func (t MappingMode) String() string {
 const (
 	SensorTypeCamera = SensorType(iota)
 	SensorTypeMovementSensor
+	SensorTypeLidar
 )
 
 func (t SensorType) String() string {
@@ -77,6 +78,8 @@ func (t SensorType) String() string {
 		return "camera"
 	case SensorTypeMovementSensor:
 		return "movement sensor"
+	case SensorTypeLidar:
+		return "lidar"
 	default:
 		return "unsupported sensor type"
 	}
@@ -90,7 +93,7 @@ var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
 type MappingMode uint8
 
 // SensorType describes what sensor type the sensor is, including
-// camera or movement sensor.
+// camera, movement sensor or lidar.
 type SensorType uint8
 
 // SensorInfo holds information about the sensor name and sensor type.
@@ -108,6 +111,21 @@ type Properties struct {
 	SensorInfo            []SensorInfo
 }
 
+// MappingModeFromConfig derives the mapping mode a SLAM wrapper should run in from the common
+// existing_map/enable_mapping attributes: no prior map creates a new map, a prior map with
+// mapping disabled localizes purely against it, and a prior map with mapping enabled updates it.
+// existingMap is typically a path inside a packaged map, e.g. "${packages.my-map}/map.pbstream".
+func MappingModeFromConfig(existingMap string, enableMapping bool) MappingMode {
+	switch {
+	case existingMap == "":
+		return MappingModeNewMap
+	case enableMapping:
+		return MappingModeUpdateExistingMap
+	default:
+		return MappingModeLocalizationOnly
+	}
+}
+
 // Named is a helper for getting the named service's typed resource name.
 func Named(name string) resource.Name {
 	return resource.NewName(API, name)
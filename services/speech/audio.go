@@ -0,0 +1,138 @@
+package speech
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/audioin"
+	"go.viam.com/rdk/components/audioout"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/utils"
+)
+
+// Say synthesizes text with svc and plays it through out, returning once it has been played.
+func Say(ctx context.Context, svc Service, out audioout.AudioOut, text string, extra map[string]interface{}) error {
+	audio, err := svc.Synthesize(ctx, text, extra)
+	if err != nil {
+		return err
+	}
+	return out.Play(ctx, audio.Data, audio.Info, nil)
+}
+
+// Listen records durationSeconds of pcm16 audio from in and returns the text svc recognizes in it.
+func Listen(
+	ctx context.Context, svc Service, in audioin.AudioIn, durationSeconds float32, extra map[string]interface{},
+) (string, error) {
+	if durationSeconds <= 0 {
+		return "", errors.New("duration must be greater than 0")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	audioChunks, err := in.GetAudio(ctx, utils.CodecPCM16, durationSeconds, 0, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// the audio info of the stream is only known from its first chunk.
+	first, ok := <-audioChunks
+	if !ok {
+		return "", errors.New("audio in did not return any audio")
+	}
+	chunks := make(chan []byte)
+	goutils.PanicCapturingGo(func() {
+		defer close(chunks)
+		for chunk := first; chunk != nil; chunk = <-audioChunks {
+			select {
+			case chunks <- chunk.AudioData:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	transcripts, err := svc.StreamRecognize(ctx, first.AudioInfo, chunks, extra)
+	if err != nil {
+		return "", err
+	}
+	var texts []string
+	for transcript := range transcripts {
+		if transcript.Final && transcript.Text != "" {
+			texts = append(texts, transcript.Text)
+		}
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return strings.Join(texts, " "), nil
+}
+
+// RecognizeSegments implements StreamRecognize with an engine that can only recognize whole clips
+// of audio. It buffers the chunks received, and recognizes each segment of segmentDuration with
+// recognize, sending its final transcript, and then the rest of the audio once chunks is closed.
+// All of the audio is recognized at once if segmentDuration is 0 or the audio is not pcm16. Errors
+// recognizing a segment are logged and end the stream.
+func RecognizeSegments(
+	ctx context.Context,
+	info *utils.AudioInfo,
+	chunks <-chan []byte,
+	segmentDuration time.Duration,
+	recognize func(context.Context, *Audio) (*Transcript, error),
+	logger logging.Logger,
+) <-chan *Transcript {
+	segmentSize := 0
+	if info != nil && info.Codec == utils.CodecPCM16 && segmentDuration > 0 {
+		bytesPerSecond := int64(info.SampleRateHz) * int64(info.NumChannels) * 2
+		segmentSize = int(bytesPerSecond * int64(segmentDuration) / int64(time.Second))
+		// segments never split a sample.
+		segmentSize -= segmentSize % max(int(info.NumChannels)*2, 1)
+	}
+
+	transcripts := make(chan *Transcript)
+	goutils.PanicCapturingGo(func() {
+		defer close(transcripts)
+		send := func(data []byte) bool {
+			transcript, err := recognize(ctx, &Audio{Data: data, Info: info})
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.CWarnw(ctx, "failed to recognize speech", "error", err)
+				}
+				return false
+			}
+			transcript.Final = true
+			select {
+			case transcripts <- transcript:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var buffered []byte
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case chunk, ok := <-chunks:
+				if !ok {
+					if len(buffered) > 0 {
+						send(buffered)
+					}
+					return
+				}
+				buffered = append(buffered, chunk...)
+				for segmentSize > 0 && len(buffered) >= segmentSize {
+					segment := buffered[:segmentSize:segmentSize]
+					buffered = buffered[segmentSize:]
+					if !send(segment) {
+						return
+					}
+				}
+			}
+		}
+	})
+	return transcripts
+}
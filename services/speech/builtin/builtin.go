@@ -0,0 +1,271 @@
+// Package builtin implements a speech service that synthesizes and recognizes speech with local
+// engines run as programs, such as espeak-ng and whisper.cpp, so that no network connection is needed.
+package builtin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/audioin"
+	"go.viam.com/rdk/components/audioout"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/speech"
+	"go.viam.com/rdk/utils"
+)
+
+// The placeholders replaced in the arguments of the commands run.
+const (
+	textPlaceholder  = "{text}"
+	audioPlaceholder = "{audio}"
+)
+
+const defaultSegmentSeconds = 5.
+
+// defaultSynthesizeCommand speaks with espeak-ng, which writes a WAV file to stdout.
+var defaultSynthesizeCommand = []string{"espeak-ng", "--stdout", textPlaceholder}
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	// DoSay synthesizes the text it is given and plays it through the audio out of the service.
+	DoSay = "say"
+	// DoListen records from the audio in of the service for the number of seconds it is given and
+	// returns the text recognized in it.
+	DoListen = "listen"
+)
+
+func init() {
+	resource.RegisterService(speech.API, resource.DefaultServiceModel, resource.Registration[speech.Service, *Config]{
+		Constructor: NewBuiltIn,
+	})
+}
+
+// Config describes how to configure the service. Commands are programs and their arguments, in
+// which "{text}" is replaced with the text to synthesize and "{audio}" with the path of a WAV file
+// of the audio to recognize.
+type Config struct {
+	// SynthesizeCommand writes a WAV file of the text being spoken to stdout. SynthesizeCommand
+	// defaults to espeak-ng if unspecified.
+	SynthesizeCommand []string `json:"synthesize_command,omitempty"`
+	// RecognizeCommand writes the text spoken in the audio to stdout, such as
+	// ["whisper-cli", "-m", "ggml-base.en.bin", "-nt", "-np", "-f", "{audio}"]. The WAV file is
+	// written to stdin if no argument is "{audio}". Speech is not recognized if unspecified.
+	RecognizeCommand []string `json:"recognize_command,omitempty"`
+	// SegmentSeconds is the length of the segments streamed audio is recognized in. SegmentSeconds
+	// defaults to 5 if unspecified.
+	SegmentSeconds float64 `json:"segment_seconds,omitempty"`
+	// AudioIn and AudioOut are the audio components to listen and speak through with DoCommand.
+	AudioIn  string `json:"audio_in,omitempty"`
+	AudioOut string `json:"audio_out,omitempty"`
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.SegmentSeconds < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("segment_seconds cannot be negative"))
+	}
+	var deps []string
+	if conf.AudioIn != "" {
+		deps = append(deps, audioin.Named(conf.AudioIn).String())
+	}
+	if conf.AudioOut != "" {
+		deps = append(deps, audioout.Named(conf.AudioOut).String())
+	}
+	return deps, nil, nil
+}
+
+type builtIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	synthesizeCommand []string
+	recognizeCommand  []string
+	segmentDuration   time.Duration
+	audioIn           audioin.AudioIn
+	audioOut          audioout.AudioOut
+	logger            logging.Logger
+}
+
+// NewBuiltIn returns a new speech service for the given robot.
+func NewBuiltIn(
+	ctx context.Context, deps resource.Dependencies, conf resource.Config, logger logging.Logger,
+) (speech.Service, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	svc := &builtIn{
+		Named:             conf.ResourceName().AsNamed(),
+		synthesizeCommand: svcConfig.SynthesizeCommand,
+		recognizeCommand:  svcConfig.RecognizeCommand,
+		segmentDuration:   time.Duration(svcConfig.SegmentSeconds * float64(time.Second)),
+		logger:            logger,
+	}
+	if len(svc.synthesizeCommand) == 0 {
+		svc.synthesizeCommand = defaultSynthesizeCommand
+	}
+	if svc.segmentDuration == 0 {
+		svc.segmentDuration = time.Duration(defaultSegmentSeconds * float64(time.Second))
+	}
+	if _, err := exec.LookPath(svc.synthesizeCommand[0]); err != nil {
+		// recognition may still work, so the service is usable without synthesis.
+		logger.CWarnw(ctx, "speech will not be synthesized, as its engine is not installed", "error", err)
+	}
+	if svcConfig.AudioIn != "" {
+		if svc.audioIn, err = audioin.FromProvider(deps, svcConfig.AudioIn); err != nil {
+			return nil, err
+		}
+	}
+	if svcConfig.AudioOut != "" {
+		if svc.audioOut, err = audioout.FromProvider(deps, svcConfig.AudioOut); err != nil {
+			return nil, err
+		}
+	}
+	return svc, nil
+}
+
+// Synthesize runs the synthesize command and returns the audio of the WAV file it writes.
+func (svc *builtIn) Synthesize(ctx context.Context, text string, extra map[string]interface{}) (*speech.Audio, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("no text to synthesize")
+	}
+	out, err := runCommand(ctx, svc.synthesizeCommand, map[string]string{textPlaceholder: text}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "synthesizing speech")
+	}
+	return parseWAV(out)
+}
+
+// Recognize runs the recognize command on a WAV file of audio and returns the text it writes.
+func (svc *builtIn) Recognize(ctx context.Context, audio *speech.Audio, extra map[string]interface{}) (*speech.Transcript, error) {
+	if len(svc.recognizeCommand) == 0 {
+		return nil, errors.New("speech cannot be recognized without a recognize_command")
+	}
+	if audio == nil || audio.Info == nil {
+		return nil, errors.New("audio info is required")
+	}
+	wav, err := audioin.CreateWAVFile(audio.Data, audio.Info.SampleRateHz, audio.Info.NumChannels, audio.Info.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []byte
+	if containsArg(svc.recognizeCommand, audioPlaceholder) {
+		dir, err := os.MkdirTemp("", "speech")
+		if err != nil {
+			return nil, err
+		}
+		defer goutils.UncheckedErrorFunc(func() error { return os.RemoveAll(dir) })
+		path := filepath.Join(dir, "audio.wav")
+		if err := os.WriteFile(path, wav, 0o600); err != nil {
+			return nil, err
+		}
+		out, err = runCommand(ctx, svc.recognizeCommand, map[string]string{audioPlaceholder: path}, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "recognizing speech")
+		}
+	} else {
+		if out, err = runCommand(ctx, svc.recognizeCommand, nil, wav); err != nil {
+			return nil, errors.Wrap(err, "recognizing speech")
+		}
+	}
+	// engines may write the text across several lines.
+	return &speech.Transcript{Text: strings.Join(strings.Fields(string(out)), " "), Final: true}, nil
+}
+
+// StreamRecognize recognizes the streamed audio in segments of SegmentSeconds.
+func (svc *builtIn) StreamRecognize(
+	ctx context.Context, info *utils.AudioInfo, chunks <-chan []byte, extra map[string]interface{},
+) (<-chan *speech.Transcript, error) {
+	if len(svc.recognizeCommand) == 0 {
+		return nil, errors.New("speech cannot be recognized without a recognize_command")
+	}
+	if info == nil {
+		return nil, errors.New("audio info is required")
+	}
+	return speech.RecognizeSegments(ctx, info, chunks, svc.segmentDuration,
+		func(ctx context.Context, audio *speech.Audio) (*speech.Transcript, error) {
+			return svc.Recognize(ctx, audio, extra)
+		}, svc.logger), nil
+}
+
+// DoCommand handles the speech DoCommand keys, and DoSay and DoListen with the audio components
+// of the service.
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	if resp, ok, err := speech.HandleSpeechCommand(ctx, svc, cmd); ok {
+		return resp, err
+	}
+	if text, ok := cmd[DoSay]; ok {
+		if svc.audioOut == nil {
+			return nil, errors.Errorf("cannot %s without an audio_out", DoSay)
+		}
+		text, ok := text.(string)
+		if !ok {
+			return nil, errors.Errorf("expected %s to be a string but got %T", DoSay, cmd[DoSay])
+		}
+		if err := speech.Say(ctx, svc, svc.audioOut, text, nil); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{DoSay: true}, nil
+	}
+	if seconds, ok := cmd[DoListen]; ok {
+		if svc.audioIn == nil {
+			return nil, errors.Errorf("cannot %s without an audio_in", DoListen)
+		}
+		seconds, ok := seconds.(float64)
+		if !ok {
+			return nil, errors.Errorf("expected %s to be a number of seconds but got %T", DoListen, cmd[DoListen])
+		}
+		text, err := speech.Listen(ctx, svc, svc.audioIn, float32(seconds), nil)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"text": text}, nil
+	}
+	return nil, resource.ErrDoUnimplemented
+}
+
+func containsArg(command []string, arg string) bool {
+	for _, a := range command[1:] {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// runCommand runs command with its placeholder arguments replaced, writing stdin to it, and returns
+// what it writes to stdout.
+func runCommand(ctx context.Context, command []string, replacements map[string]string, stdin []byte) ([]byte, error) {
+	args := make([]string, 0, len(command)-1)
+	for _, arg := range command[1:] {
+		if replacement, ok := replacements[arg]; ok {
+			arg = replacement
+		}
+		args = append(args, arg)
+	}
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, command[0], args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.Wrap(err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,171 @@
+package builtin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/audioin"
+	"go.viam.com/rdk/components/audioout"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/speech"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+var testInfo = &utils.AudioInfo{Codec: utils.CodecPCM16, SampleRateHz: 8000, NumChannels: 1}
+
+// writeScript writes an executable shell script to dir and returns its path.
+func writeScript(t *testing.T, dir, name, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	test.That(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o700), test.ShouldBeNil)
+	return path
+}
+
+func newService(t *testing.T, conf *Config, deps resource.Dependencies) speech.Service {
+	t.Helper()
+	svc, err := NewBuiltIn(context.Background(), deps, resource.Config{
+		Name:                "speech",
+		API:                 speech.API,
+		Model:               resource.DefaultServiceModel,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return svc
+}
+
+func TestSynthesize(t *testing.T) {
+	dir := t.TempDir()
+	wav, err := audioin.CreateWAVFile([]byte{1, 2, 3, 4}, 8000, 1, utils.CodecPCM16)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.WriteFile(filepath.Join(dir, "speech.wav"), wav, 0o600), test.ShouldBeNil)
+	synthesize := writeScript(t, dir, "synthesize", `echo "$2" > `+dir+`/text; cat `+dir+`/speech.wav`)
+
+	svc := newService(t, &Config{SynthesizeCommand: []string{synthesize, "-v", textPlaceholder}}, nil)
+	audio, err := svc.Synthesize(context.Background(), "hello robot", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, audio, test.ShouldResemble, &speech.Audio{Data: []byte{1, 2, 3, 4}, Info: testInfo})
+	text, err := os.ReadFile(filepath.Join(dir, "text"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(text), test.ShouldEqual, "hello robot\n")
+
+	_, err = svc.Synthesize(context.Background(), " ", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestRecognize(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	// the file recognizers print the size of the WAV file they are given.
+	fileRecognizer := writeScript(t, dir, "recognize_file", `for f; do :; done; echo "heard"; wc -c < "$f" | tr -d ' '`)
+	stdinRecognizer := writeScript(t, dir, "recognize_stdin", `echo "heard $(wc -c | tr -d ' ')"`)
+
+	svc := newService(t, &Config{RecognizeCommand: []string{fileRecognizer, "-f", audioPlaceholder}}, nil)
+	transcript, err := svc.Recognize(ctx, &speech.Audio{Data: make([]byte, 100), Info: testInfo}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	// WAV files have a 44 byte header.
+	test.That(t, transcript, test.ShouldResemble, &speech.Transcript{Text: "heard 144", Final: true})
+
+	t.Run("stdin", func(t *testing.T) {
+		svc := newService(t, &Config{RecognizeCommand: []string{stdinRecognizer}}, nil)
+		transcript, err := svc.Recognize(ctx, &speech.Audio{Data: make([]byte, 10), Info: testInfo}, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, transcript.Text, test.ShouldEqual, "heard 54")
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		// 0.1s of mono pcm16 at 8000Hz is 1600 bytes.
+		svc := newService(t, &Config{RecognizeCommand: []string{fileRecognizer, audioPlaceholder}, SegmentSeconds: 0.1}, nil)
+		chunks := make(chan []byte, 2)
+		chunks <- make([]byte, 2000)
+		chunks <- make([]byte, 2000)
+		close(chunks)
+		transcripts, err := svc.StreamRecognize(ctx, testInfo, chunks, nil)
+		test.That(t, err, test.ShouldBeNil)
+		var texts []string
+		for transcript := range transcripts {
+			texts = append(texts, transcript.Text)
+		}
+		test.That(t, texts, test.ShouldResemble, []string{"heard 1644", "heard 1644", "heard 844"})
+	})
+
+	t.Run("without a command", func(t *testing.T) {
+		svc := newService(t, &Config{}, nil)
+		_, err := svc.Recognize(ctx, &speech.Audio{Data: make([]byte, 10), Info: testInfo}, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+	})
+}
+
+func TestSayAndListen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	wav, err := audioin.CreateWAVFile([]byte{1, 2}, 8000, 1, utils.CodecPCM16)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.WriteFile(filepath.Join(dir, "speech.wav"), wav, 0o600), test.ShouldBeNil)
+	synthesize := writeScript(t, dir, "synthesize", "cat "+dir+"/speech.wav")
+	recognize := writeScript(t, dir, "recognize", "echo hello; echo robot")
+
+	out := inject.NewAudioOut("speaker")
+	var played []byte
+	out.PlayFunc = func(ctx context.Context, data []byte, info *utils.AudioInfo, extra map[string]interface{}) error {
+		played = data
+		return nil
+	}
+	in := inject.NewAudioIn("mic")
+	in.GetAudioFunc = func(ctx context.Context, codec string, durationSeconds float32, previousTimestampNs int64,
+		extra map[string]interface{},
+	) (chan *audioin.AudioChunk, error) {
+		chunks := make(chan *audioin.AudioChunk, 1)
+		chunks <- &audioin.AudioChunk{AudioData: make([]byte, 1600), AudioInfo: testInfo}
+		close(chunks)
+		return chunks, nil
+	}
+	conf := &Config{
+		SynthesizeCommand: []string{synthesize},
+		RecognizeCommand:  []string{recognize},
+		AudioIn:           "mic",
+		AudioOut:          "speaker",
+	}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{audioin.Named("mic").String(), audioout.Named("speaker").String()})
+	svc := newService(t, conf, resource.Dependencies{in.Name(): in, out.Name(): out})
+
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{DoSay: "hello"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{DoSay: true})
+	test.That(t, played, test.ShouldResemble, []byte{1, 2})
+
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{DoListen: 1.0})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp, test.ShouldResemble, map[string]interface{}{"text": "hello robot"})
+
+	// the speech commands are handled too.
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{speech.DoSynthesize: "hello"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[speech.DoSynthesize], test.ShouldBeNil)
+	test.That(t, resp["codec"], test.ShouldEqual, utils.CodecPCM16)
+}
+
+func TestParseWAV(t *testing.T) {
+	wav, err := audioin.CreateWAVFile([]byte{1, 2, 3, 4, 5, 6, 7, 8}, 16000, 2, utils.CodecPCM32Float)
+	test.That(t, err, test.ShouldBeNil)
+	audio, err := parseWAV(wav)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, audio.Info, test.ShouldResemble, &utils.AudioInfo{Codec: utils.CodecPCM32Float, SampleRateHz: 16000, NumChannels: 2})
+	test.That(t, audio.Data, test.ShouldResemble, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+
+	// files written to pipes have an unknown data length.
+	copy(wav[40:44], []byte{0xff, 0xff, 0xff, 0xff})
+	audio, err = parseWAV(wav)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, audio.Data, test.ShouldResemble, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+
+	_, err = parseWAV([]byte("not a wav file"))
+	test.That(t, err, test.ShouldNotBeNil)
+}
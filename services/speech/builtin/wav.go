@@ -0,0 +1,61 @@
+package builtin
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/services/speech"
+	"go.viam.com/rdk/utils"
+)
+
+// parseWAV returns the audio of a WAV file of PCM samples. The length of the data may be unknown,
+// as it is in files written to a pipe, in which case the data runs to the end of the file.
+func parseWAV(wav []byte) (*speech.Audio, error) {
+	if len(wav) < 12 || string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		return nil, errors.New("audio is not a WAV file")
+	}
+	var info *utils.AudioInfo
+	for rest := wav[12:]; len(rest) >= 8; {
+		id := string(rest[0:4])
+		size := int(binary.LittleEndian.Uint32(rest[4:8]))
+		rest = rest[8:]
+		switch id {
+		case "fmt ":
+			if size < 16 || len(rest) < 16 {
+				return nil, errors.New("WAV file has an invalid format chunk")
+			}
+			format := binary.LittleEndian.Uint16(rest[0:2])
+			bitsPerSample := binary.LittleEndian.Uint16(rest[14:16])
+			info = &utils.AudioInfo{
+				NumChannels:  int32(binary.LittleEndian.Uint16(rest[2:4])),
+				SampleRateHz: int32(binary.LittleEndian.Uint32(rest[4:8])),
+			}
+			switch {
+			case format == 1 && bitsPerSample == 16:
+				info.Codec = utils.CodecPCM16
+			case format == 1 && bitsPerSample == 32:
+				info.Codec = utils.CodecPCM32
+			case format == 3 && bitsPerSample == 32:
+				info.Codec = utils.CodecPCM32Float
+			default:
+				return nil, errors.Errorf("WAV files of format %d with %d bits per sample are not supported", format, bitsPerSample)
+			}
+		case "data":
+			if info == nil {
+				return nil, errors.New("WAV file has no format chunk before its data")
+			}
+			if size == 0 || size > len(rest) {
+				size = len(rest)
+			}
+			return &speech.Audio{Data: rest[:size], Info: info}, nil
+		}
+		// chunks are padded to an even size.
+		size += size % 2
+		if size > len(rest) {
+			break
+		}
+		rest = rest[size:]
+	}
+	return nil, errors.New("WAV file has no data")
+}
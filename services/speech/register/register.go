@@ -0,0 +1,7 @@
+// Package register registers all relevant speech models and also API specific functions
+package register
+
+import (
+	// for speech models.
+	_ "go.viam.com/rdk/services/speech/builtin"
+)
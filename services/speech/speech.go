@@ -0,0 +1,204 @@
+// Package speech defines a service that synthesizes speech from text and recognizes text in speech,
+// so that robots can talk and listen through their audio components.
+package speech
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+	"go.viam.com/rdk/utils"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = "speech"
+
+// API is a variable that identifies the speech resource API.
+var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoSynthesize = "synthesize"
+	DoRecognize  = "recognize"
+)
+
+// Named is a helper for getting the named speech service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// Deprecated: FromRobot is a helper for getting the named speech service from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named speech service
+// from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Service, error) {
+	return resource.FromProvider[Service](provider, Named(name))
+}
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Service]{})
+}
+
+// Audio is a clip of audio data.
+type Audio struct {
+	Data []byte
+	Info *utils.AudioInfo
+}
+
+// A Transcript is text recognized in speech.
+type Transcript struct {
+	Text string
+	// Confidence is how likely the text is to be correct, from 0 to 1, or 0 if the engine does not
+	// report it.
+	Confidence float64
+	// Final is false for interim transcripts of speech that is still being recognized, which later
+	// transcripts of the same speech replace.
+	Final bool
+}
+
+// A Service synthesizes and recognizes speech.
+type Service interface {
+	resource.Resource
+	// Synthesize returns audio of text being spoken.
+	Synthesize(ctx context.Context, text string, extra map[string]interface{}) (*Audio, error)
+	// Recognize returns the text spoken in audio.
+	Recognize(ctx context.Context, audio *Audio, extra map[string]interface{}) (*Transcript, error)
+	// StreamRecognize recognizes the text spoken in the chunks of audio received until chunks is
+	// closed, sending transcripts as speech is recognized. The transcripts channel is closed once all
+	// of the audio has been recognized or ctx is done.
+	StreamRecognize(
+		ctx context.Context, info *utils.AudioInfo, chunks <-chan []byte, extra map[string]interface{},
+	) (<-chan *Transcript, error)
+}
+
+// audioMessage is the form audio takes in DoCommand commands and responses. Data is encoded as
+// base64.
+type audioMessage struct {
+	Data         []byte `json:"audio"`
+	Codec        string `json:"codec"`
+	SampleRateHz int32  `json:"sample_rate_hz"`
+	NumChannels  int32  `json:"num_channels"`
+}
+
+func audioToMessage(audio *Audio) audioMessage {
+	msg := audioMessage{Data: audio.Data}
+	if audio.Info != nil {
+		msg.Codec, msg.SampleRateHz, msg.NumChannels = audio.Info.Codec, audio.Info.SampleRateHz, audio.Info.NumChannels
+	}
+	return msg
+}
+
+func (msg audioMessage) audio() *Audio {
+	return &Audio{
+		Data: msg.Data,
+		Info: &utils.AudioInfo{Codec: msg.Codec, SampleRateHz: msg.SampleRateHz, NumChannels: msg.NumChannels},
+	}
+}
+
+type synthesizeCommand struct {
+	Text  string                 `json:"synthesize"`
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type recognizeCommand struct {
+	Audio audioMessage           `json:"recognize"`
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+type transcriptMessage struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence,omitempty"`
+}
+
+// HandleSpeechCommand services the speech DoCommand keys using the given Service, so that speech can
+// be synthesized and recognized through DoCommand by callers that only have a generic resource
+// handle, such as the client of a speech service provided by a module. It returns false if cmd does
+// not contain any of them so that it can be chained from a DoCommand implementation.
+func HandleSpeechCommand(
+	ctx context.Context,
+	svc Service,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	_, synthesize := cmd[DoSynthesize]
+	_, recognize := cmd[DoRecognize]
+	switch {
+	case synthesize && recognize:
+		return nil, true, errors.Errorf("cannot %s and %s in the same command", DoSynthesize, DoRecognize)
+	case synthesize:
+		req, err := resource.DecodeDoCommand[synthesizeCommand](cmd)
+		if err != nil {
+			return nil, true, errors.Wrapf(err, "invalid %s command", DoSynthesize)
+		}
+		audio, err := svc.Synthesize(ctx, req.Text, req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(audioToMessage(audio))
+		return resp, true, err
+	case recognize:
+		req, err := resource.DecodeDoCommand[recognizeCommand](cmd)
+		if err != nil {
+			return nil, true, errors.Wrapf(err, "invalid %s command", DoRecognize)
+		}
+		transcript, err := svc.Recognize(ctx, req.Audio.audio(), req.Extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(transcriptMessage{Text: transcript.Text, Confidence: transcript.Confidence})
+		return resp, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// FromResource returns a Service that synthesizes and recognizes speech through the DoCommand of
+// res, which must handle the speech DoCommand keys as HandleSpeechCommand does. It lets speech
+// engines be provided by modules as generic resources, and speech services be used through
+// connections that only carry DoCommand.
+func FromResource(res resource.Resource, logger logging.Logger) Service {
+	if svc, ok := res.(Service); ok {
+		return svc
+	}
+	return &doCommandService{Resource: res, logger: logger}
+}
+
+type doCommandService struct {
+	resource.Resource
+	logger logging.Logger
+}
+
+func (s *doCommandService) Synthesize(ctx context.Context, text string, extra map[string]interface{}) (*Audio, error) {
+	msg, err := resource.DoCommandAs[synthesizeCommand, audioMessage](ctx, s, synthesizeCommand{Text: text, Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	return msg.audio(), nil
+}
+
+func (s *doCommandService) Recognize(ctx context.Context, audio *Audio, extra map[string]interface{}) (*Transcript, error) {
+	msg, err := resource.DoCommandAs[recognizeCommand, transcriptMessage](
+		ctx, s, recognizeCommand{Audio: audioToMessage(audio), Extra: extra})
+	if err != nil {
+		return nil, err
+	}
+	return &Transcript{Text: msg.Text, Confidence: msg.Confidence, Final: true}, nil
+}
+
+// StreamRecognize recognizes all of the audio at once after chunks is closed, since DoCommand
+// cannot stream.
+func (s *doCommandService) StreamRecognize(
+	ctx context.Context, info *utils.AudioInfo, chunks <-chan []byte, extra map[string]interface{},
+) (<-chan *Transcript, error) {
+	return RecognizeSegments(ctx, info, chunks, 0, func(ctx context.Context, audio *Audio) (*Transcript, error) {
+		return s.Recognize(ctx, audio, extra)
+	}, s.logger), nil
+}
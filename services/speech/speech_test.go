@@ -0,0 +1,146 @@
+package speech_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/audioin"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/speech"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+var textInfo = &utils.AudioInfo{Codec: utils.CodecPCM16, SampleRateHz: 16000, NumChannels: 1}
+
+// echoService speaks text as audio of its bytes, and recognizes audio as the text of its bytes.
+type echoService struct {
+	resource.Named
+	resource.TriviallyReconfigurable
+	resource.TriviallyCloseable
+	logger logging.Logger
+}
+
+func (s *echoService) Synthesize(ctx context.Context, text string, extra map[string]interface{}) (*speech.Audio, error) {
+	return &speech.Audio{Data: []byte(text), Info: textInfo}, nil
+}
+
+func (s *echoService) Recognize(ctx context.Context, audio *speech.Audio, extra map[string]interface{}) (*speech.Transcript, error) {
+	return &speech.Transcript{Text: string(audio.Data), Confidence: 0.5, Final: true}, nil
+}
+
+func (s *echoService) StreamRecognize(
+	ctx context.Context, info *utils.AudioInfo, chunks <-chan []byte, extra map[string]interface{},
+) (<-chan *speech.Transcript, error) {
+	return speech.RecognizeSegments(ctx, info, chunks, 0, func(ctx context.Context, audio *speech.Audio) (*speech.Transcript, error) {
+		return s.Recognize(ctx, audio, extra)
+	}, s.logger), nil
+}
+
+func newEchoService(t *testing.T) *echoService {
+	t.Helper()
+	return &echoService{Named: speech.Named("echo").AsNamed(), logger: logging.NewTestLogger(t)}
+}
+
+func TestFromResource(t *testing.T) {
+	ctx := context.Background()
+	logger := logging.NewTestLogger(t)
+	echo := newEchoService(t)
+	test.That(t, speech.FromResource(echo, logger), test.ShouldEqual, echo)
+
+	// a module provides the engine as a generic service that handles the speech commands.
+	res := inject.NewGenericService("engine")
+	res.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		resp, ok, err := speech.HandleSpeechCommand(ctx, echo, cmd)
+		if !ok {
+			return nil, resource.ErrDoUnimplemented
+		}
+		return resp, err
+	}
+	svc := speech.FromResource(res, logger)
+
+	audio, err := svc.Synthesize(ctx, "hello", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, audio, test.ShouldResemble, &speech.Audio{Data: []byte("hello"), Info: textInfo})
+
+	transcript, err := svc.Recognize(ctx, audio, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, transcript, test.ShouldResemble, &speech.Transcript{Text: "hello", Confidence: 0.5, Final: true})
+
+	chunks := make(chan []byte, 2)
+	chunks <- []byte("hello ")
+	chunks <- []byte("robot")
+	close(chunks)
+	transcripts, err := svc.StreamRecognize(ctx, textInfo, chunks, nil)
+	test.That(t, err, test.ShouldBeNil)
+	var texts []string
+	for transcript := range transcripts {
+		texts = append(texts, transcript.Text)
+	}
+	test.That(t, texts, test.ShouldResemble, []string{"hello robot"})
+
+	_, _, err = speech.HandleSpeechCommand(ctx, echo, map[string]interface{}{
+		speech.DoSynthesize: "hello",
+		speech.DoRecognize:  map[string]interface{}{},
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, ok, err := speech.HandleSpeechCommand(ctx, echo, map[string]interface{}{"other": true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestRecognizeSegments(t *testing.T) {
+	// 1s of mono pcm16 at 4Hz is 8 bytes.
+	info := &utils.AudioInfo{Codec: utils.CodecPCM16, SampleRateHz: 4, NumChannels: 1}
+	chunks := make(chan []byte, 3)
+	chunks <- []byte("abcde")
+	chunks <- []byte("fghijkl")
+	chunks <- []byte("mnopqrs")
+	close(chunks)
+	transcripts := speech.RecognizeSegments(context.Background(), info, chunks, 1500*time.Millisecond,
+		func(ctx context.Context, audio *speech.Audio) (*speech.Transcript, error) {
+			return &speech.Transcript{Text: string(audio.Data)}, nil
+		}, logging.NewTestLogger(t))
+	var texts []string
+	for transcript := range transcripts {
+		test.That(t, transcript.Final, test.ShouldBeTrue)
+		texts = append(texts, transcript.Text)
+	}
+	// 1.5s is 12 bytes, which is 6 whole samples.
+	test.That(t, texts, test.ShouldResemble, []string{"abcdefghijkl", "mnopqrs"})
+}
+
+func TestSayAndListen(t *testing.T) {
+	ctx := context.Background()
+	echo := newEchoService(t)
+
+	out := inject.NewAudioOut("speaker")
+	var played []byte
+	out.PlayFunc = func(ctx context.Context, data []byte, info *utils.AudioInfo, extra map[string]interface{}) error {
+		test.That(t, info, test.ShouldResemble, textInfo)
+		played = data
+		return nil
+	}
+	test.That(t, speech.Say(ctx, echo, out, "hello", nil), test.ShouldBeNil)
+	test.That(t, played, test.ShouldResemble, []byte("hello"))
+
+	in := inject.NewAudioIn("mic")
+	in.GetAudioFunc = func(ctx context.Context, codec string, durationSeconds float32, previousTimestampNs int64,
+		extra map[string]interface{},
+	) (chan *audioin.AudioChunk, error) {
+		test.That(t, codec, test.ShouldEqual, utils.CodecPCM16)
+		test.That(t, durationSeconds, test.ShouldEqual, 2)
+		chunks := make(chan *audioin.AudioChunk, 2)
+		chunks <- &audioin.AudioChunk{AudioData: []byte("hello "), AudioInfo: textInfo}
+		chunks <- &audioin.AudioChunk{AudioData: []byte("robot"), AudioInfo: textInfo, Sequence: 1}
+		close(chunks)
+		return chunks, nil
+	}
+	text, err := speech.Listen(ctx, echo, in, 2, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, text, test.ShouldEqual, "hello robot")
+}
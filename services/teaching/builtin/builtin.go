@@ -0,0 +1,443 @@
+// Package builtin implements a teaching service that records the joint positions of arms and the
+// velocities of bases, and replays them.
+package builtin
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/teaching"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	defaultSampleRateHz = 20.
+	skillFileExt        = ".json"
+	// jointEpsilonRads and velocityEpsilon are the smallest changes between samples that are recorded.
+	jointEpsilonRads = 1e-4
+	velocityEpsilon  = 1e-3
+)
+
+func init() {
+	resource.RegisterService(teaching.API, resource.DefaultServiceModel, resource.Registration[teaching.Service, *Config]{
+		Constructor: NewBuiltIn,
+	})
+}
+
+// BaseConfig names a base to record and the movement sensor that measures its velocities.
+type BaseConfig struct {
+	Name           string `json:"name"`
+	MovementSensor string `json:"movement_sensor"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	Arms  []string     `json:"arms,omitempty"`
+	Bases []BaseConfig `json:"bases,omitempty"`
+	// Grippers are the grippers that markers may open or grab with.
+	Grippers []string `json:"grippers,omitempty"`
+	// MotionService plans the motion of arms when replaying with replanning.
+	MotionService string `json:"motion_service,omitempty"`
+	// SkillsDir is the directory skills are stored in, which may be the path of a package to replay
+	// skills taught on another machine. SkillsDir defaults to a directory named after the service in
+	// ~/.viam/skills if unspecified.
+	SkillsDir    string  `json:"skills_dir,omitempty"`
+	SampleRateHz float64 `json:"sample_rate_hz,omitempty"`
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if len(conf.Arms) == 0 && len(conf.Bases) == 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("at least one arm or base is required"))
+	}
+	deps := append([]string{}, conf.Arms...)
+	for _, b := range conf.Bases {
+		if b.Name == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "bases.name")
+		}
+		if b.MovementSensor == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "bases.movement_sensor")
+		}
+		deps = append(deps, b.Name, b.MovementSensor)
+	}
+	deps = append(deps, conf.Grippers...)
+	if conf.MotionService != "" {
+		deps = append(deps, conf.MotionService)
+	}
+	if conf.SampleRateHz < 0 {
+		return nil, nil, resource.NewConfigValidationError(path, errors.New("sample_rate_hz cannot be negative"))
+	}
+	return deps, nil, nil
+}
+
+type recordedBase struct {
+	base   base.Base
+	sensor movementsensor.MovementSensor
+}
+
+// A recording is a skill being recorded.
+type recording struct {
+	mu      sync.Mutex
+	skill   *teaching.Skill
+	start   time.Time
+	workers *goutils.StoppableWorkers
+}
+
+type builtIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf      *Config
+	skillsDir string
+	arms      map[string]arm.Arm
+	bases     map[string]recordedBase
+	grippers  map[string]gripper.Gripper
+	motion    motion.Service
+	logger    logging.Logger
+
+	// closeCtx is canceled on Close to stop any replay that is running.
+	closeCtx   context.Context
+	cancelFunc context.CancelFunc
+	activeOps  sync.WaitGroup
+
+	mu        sync.Mutex
+	recording *recording
+	status    teaching.Status
+}
+
+// NewBuiltIn returns a new teaching service for the given robot.
+func NewBuiltIn(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (teaching.Service, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	closeCtx, cancelFunc := context.WithCancel(context.Background())
+	svc := &builtIn{
+		Named:      conf.ResourceName().AsNamed(),
+		conf:       svcConfig,
+		skillsDir:  svcConfig.SkillsDir,
+		arms:       map[string]arm.Arm{},
+		bases:      map[string]recordedBase{},
+		grippers:   map[string]gripper.Gripper{},
+		logger:     logger,
+		closeCtx:   closeCtx,
+		cancelFunc: cancelFunc,
+	}
+	if svc.skillsDir == "" {
+		svc.skillsDir = filepath.Join(utils.ViamDotDir, "skills", conf.Name)
+	}
+	for _, name := range svcConfig.Arms {
+		if svc.arms[name], err = arm.FromProvider(deps, name); err != nil {
+			return nil, err
+		}
+	}
+	for _, b := range svcConfig.Bases {
+		rb := recordedBase{}
+		if rb.base, err = base.FromProvider(deps, b.Name); err != nil {
+			return nil, err
+		}
+		if rb.sensor, err = movementsensor.FromProvider(deps, b.MovementSensor); err != nil {
+			return nil, err
+		}
+		svc.bases[b.Name] = rb
+	}
+	for _, name := range svcConfig.Grippers {
+		if svc.grippers[name], err = gripper.FromProvider(deps, name); err != nil {
+			return nil, err
+		}
+	}
+	if svcConfig.MotionService != "" {
+		if svc.motion, err = motion.FromProvider(deps, svcConfig.MotionService); err != nil {
+			return nil, err
+		}
+	}
+	return svc, nil
+}
+
+func (svc *builtIn) skillPath(name string) string {
+	return filepath.Join(svc.skillsDir, name+skillFileExt)
+}
+
+// StartRecording starts sampling the components of the service at the configured rate.
+func (svc *builtIn) StartRecording(ctx context.Context, name string, extra map[string]interface{}) error {
+	if err := teaching.ValidateSkillName(name); err != nil {
+		return err
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.recording != nil {
+		return errors.Errorf("already recording skill %q", svc.recording.skill.Name)
+	}
+	if svc.status.Replaying != "" {
+		return errors.Errorf("cannot record while replaying skill %q", svc.status.Replaying)
+	}
+
+	rec := &recording{skill: &teaching.Skill{Name: name}, start: time.Now()}
+	for _, name := range svc.conf.Arms {
+		rec.skill.Tracks = append(rec.skill.Tracks, teaching.Track{Component: name, Kind: teaching.TrackArm})
+	}
+	for _, b := range svc.conf.Bases {
+		rec.skill.Tracks = append(rec.skill.Tracks, teaching.Track{Component: b.Name, Kind: teaching.TrackBase})
+	}
+	// the first sample is taken before returning so that every skill starts where it was recorded.
+	if err := svc.sample(ctx, rec); err != nil {
+		return err
+	}
+	rate := svc.conf.SampleRateHz
+	if rate == 0 {
+		rate = defaultSampleRateHz
+	}
+	rec.workers = goutils.NewStoppableWorkerWithTicker(time.Duration(float64(time.Second)/rate), func(ctx context.Context) {
+		if err := svc.sample(ctx, rec); err != nil && ctx.Err() == nil {
+			svc.logger.CWarnw(ctx, "failed to record sample", "skill", name, "error", err)
+		}
+	})
+	svc.recording = rec
+	svc.status = teaching.Status{Recording: name, Since: rec.start}
+	return nil
+}
+
+// sample appends the current state of every component to the tracks of rec.
+func (svc *builtIn) sample(ctx context.Context, rec *recording) error {
+	samples := make([]teaching.Sample, len(rec.skill.Tracks))
+	t := time.Since(rec.start).Seconds()
+	for i, track := range rec.skill.Tracks {
+		samples[i].Time = t
+		switch track.Kind {
+		case teaching.TrackArm:
+			joints, err := svc.arms[track.Component].JointPositions(ctx, nil)
+			if err != nil {
+				return err
+			}
+			samples[i].Joints = joints
+		case teaching.TrackBase:
+			b := svc.bases[track.Component]
+			linear, err := b.sensor.LinearVelocity(ctx, nil)
+			if err != nil {
+				return err
+			}
+			angular, err := b.sensor.AngularVelocity(ctx, nil)
+			if err != nil {
+				return err
+			}
+			// movement sensors measure linear velocity in meters per second.
+			samples[i].LinearMMPerSec = linear.Mul(1000)
+			samples[i].AngularDegsPerSec = r3.Vector(angular)
+		}
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for i := range samples {
+		rec.skill.Tracks[i].Samples = append(rec.skill.Tracks[i].Samples, samples[i])
+	}
+	return nil
+}
+
+// Mark adds a marker to the recording, opening or grabbing with its gripper first if it has one.
+func (svc *builtIn) Mark(ctx context.Context, marker teaching.Marker, extra map[string]interface{}) error {
+	if err := marker.Validate(); err != nil {
+		return err
+	}
+	svc.mu.Lock()
+	rec := svc.recording
+	svc.mu.Unlock()
+	if rec == nil {
+		return errors.New("cannot mark without recording")
+	}
+	if err := svc.performMarker(ctx, marker); err != nil {
+		return err
+	}
+	rec.mu.Lock()
+	marker.Time = time.Since(rec.start).Seconds()
+	rec.skill.Markers = append(rec.skill.Markers, marker)
+	rec.mu.Unlock()
+
+	svc.mu.Lock()
+	svc.status.LastMarker = marker.Label
+	svc.mu.Unlock()
+	return nil
+}
+
+func (svc *builtIn) performMarker(ctx context.Context, marker teaching.Marker) error {
+	if marker.Gripper == "" {
+		return nil
+	}
+	g, ok := svc.grippers[marker.Gripper]
+	if !ok {
+		return errors.Errorf("gripper %q is not one of the grippers of the teaching service", marker.Gripper)
+	}
+	switch marker.Action {
+	case teaching.ActionOpen:
+		return g.Open(ctx, nil)
+	case teaching.ActionGrab:
+		_, err := g.Grab(ctx, nil)
+		return err
+	default:
+		return errors.Errorf("unknown marker action %q", marker.Action)
+	}
+}
+
+// StopRecording stops sampling and stores the skill, keeping only the samples where components change.
+func (svc *builtIn) StopRecording(ctx context.Context, extra map[string]interface{}) (*teaching.Skill, error) {
+	svc.mu.Lock()
+	rec := svc.recording
+	svc.recording = nil
+	if rec != nil {
+		svc.status.Recording = ""
+	}
+	svc.mu.Unlock()
+	if rec == nil {
+		return nil, errors.New("not recording")
+	}
+	rec.workers.Stop()
+	// a last sample is taken so that the skill ends where it was stopped.
+	if err := svc.sample(ctx, rec); err != nil {
+		return nil, err
+	}
+
+	skill := rec.skill
+	skill.Duration = time.Since(rec.start).Seconds()
+	for i := range skill.Tracks {
+		skill.Tracks[i].Samples = compressSamples(skill.Tracks[i].Samples)
+	}
+	if err := os.MkdirAll(svc.skillsDir, 0o750); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(skill)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(svc.skillPath(skill.Name), data, 0o600); err != nil {
+		return nil, err
+	}
+	return skill, nil
+}
+
+// compressSamples drops samples that do not change, except the last before each change so that pauses
+// last as long when replayed.
+func compressSamples(samples []teaching.Sample) []teaching.Sample {
+	if len(samples) <= 2 {
+		return samples
+	}
+	kept := []teaching.Sample{samples[0]}
+	for i := 1; i < len(samples)-1; i++ {
+		last := kept[len(kept)-1]
+		if samplesDiffer(last, samples[i]) || samplesDiffer(samples[i], samples[i+1]) {
+			kept = append(kept, samples[i])
+		}
+	}
+	return append(kept, samples[len(samples)-1])
+}
+
+func samplesDiffer(a, b teaching.Sample) bool {
+	for i := range a.Joints {
+		if i >= len(b.Joints) || math.Abs(a.Joints[i]-b.Joints[i]) > jointEpsilonRads {
+			return true
+		}
+	}
+	return a.LinearMMPerSec.Sub(b.LinearMMPerSec).Norm() > velocityEpsilon ||
+		a.AngularDegsPerSec.Sub(b.AngularDegsPerSec).Norm() > velocityEpsilon
+}
+
+// ListSkills returns the names of the skills in the skills directory.
+func (svc *builtIn) ListSkills(ctx context.Context, extra map[string]interface{}) ([]string, error) {
+	entries, err := os.ReadDir(svc.skillsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	names := []string{}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), skillFileExt) {
+			names = append(names, strings.TrimSuffix(e.Name(), skillFileExt))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetSkill reads a skill from the skills directory.
+func (svc *builtIn) GetSkill(ctx context.Context, name string, extra map[string]interface{}) (*teaching.Skill, error) {
+	if err := teaching.ValidateSkillName(name); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(svc.skillPath(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.Errorf("no skill named %q", name)
+		}
+		return nil, err
+	}
+	var skill teaching.Skill
+	if err := json.Unmarshal(data, &skill); err != nil {
+		return nil, errors.Wrapf(err, "invalid skill %q", name)
+	}
+	return &skill, nil
+}
+
+// DeleteSkill removes a skill from the skills directory.
+func (svc *builtIn) DeleteSkill(ctx context.Context, name string, extra map[string]interface{}) error {
+	if err := teaching.ValidateSkillName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(svc.skillPath(name)); err != nil {
+		if os.IsNotExist(err) {
+			return errors.Errorf("no skill named %q", name)
+		}
+		return err
+	}
+	return nil
+}
+
+func (svc *builtIn) Status(ctx context.Context) (map[string]interface{}, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return teaching.StatusToMap(svc.status), nil
+}
+
+func (svc *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	resp, handled, err := teaching.HandleTeachingCommand(ctx, svc, cmd)
+	if !handled {
+		return nil, resource.ErrDoUnimplemented
+	}
+	return resp, err
+}
+
+// Close discards any recording, and stops any replay and waits for it to return.
+func (svc *builtIn) Close(ctx context.Context) error {
+	svc.mu.Lock()
+	rec := svc.recording
+	svc.recording = nil
+	svc.mu.Unlock()
+	if rec != nil {
+		rec.workers.Stop()
+	}
+	svc.cancelFunc()
+	svc.activeOps.Wait()
+	return nil
+}
@@ -0,0 +1,388 @@
+package builtin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot/governor"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/teaching"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+// testRobot is a robot whose arm and base are driven by the test, as an operator would drive them.
+type testRobot struct {
+	mu       sync.Mutex
+	joints   []referenceframe.Input
+	linear   r3.Vector
+	grabs    int
+	moves    [][]referenceframe.Input
+	through  []*arm.MoveOptions
+	velocity []r3.Vector
+	stops    int
+	servoed  [][]referenceframe.Input
+
+	deps resource.Dependencies
+}
+
+func newTestRobot(t *testing.T) *testRobot {
+	t.Helper()
+	r := &testRobot{joints: make([]referenceframe.Input, 6)}
+
+	a := inject.NewArm("arm")
+	a.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) ([]referenceframe.Input, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return append([]referenceframe.Input{}, r.joints...), nil
+	}
+	a.MoveToJointPositionsFunc = func(ctx context.Context, positions []referenceframe.Input, extra map[string]interface{}) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.moves = append(r.moves, positions)
+		return nil
+	}
+	a.MoveThroughJointPositionsFunc = func(
+		ctx context.Context,
+		positions [][]referenceframe.Input,
+		options *arm.MoveOptions,
+		extra map[string]interface{},
+	) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.moves = append(r.moves, positions[len(positions)-1])
+		r.through = append(r.through, options)
+		return nil
+	}
+	a.StopFunc = func(ctx context.Context, extra map[string]interface{}) error { return nil }
+	a.KinematicsFunc = func(ctx context.Context) (referenceframe.Model, error) {
+		return referenceframe.ParseModelJSONFile(utils.ResolveFile("referenceframe/testfiles/ur5e.json"), "arm")
+	}
+
+	b := inject.NewBase("base")
+	b.SetVelocityFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.velocity = append(r.velocity, linear)
+		return nil
+	}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.stops++
+		return nil
+	}
+
+	ms := inject.NewMovementSensor("odometry")
+	ms.LinearVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.linear, nil
+	}
+	ms.AngularVelocityFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+		return spatialmath.AngularVelocity{}, nil
+	}
+
+	g := inject.NewGripper("gripper")
+	g.GrabFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.grabs++
+		return true, nil
+	}
+
+	r.deps = resource.Dependencies{
+		arm.Named("arm"):                 a,
+		base.Named("base"):               b,
+		movementsensor.Named("odometry"): ms,
+		gripper.Named("gripper"):         g,
+	}
+	return r
+}
+
+func (r *testRobot) set(joint float64, linear r3.Vector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.joints {
+		r.joints[i] = joint
+	}
+	r.linear = linear
+}
+
+func newService(t *testing.T, conf *Config, deps resource.Dependencies) teaching.Service {
+	t.Helper()
+	svc, err := NewBuiltIn(context.Background(), deps, resource.Config{
+		Name:                "teaching",
+		API:                 teaching.API,
+		Model:               resource.DefaultServiceModel,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	t.Cleanup(func() { test.That(t, svc.Close(context.Background()), test.ShouldBeNil) })
+	return svc
+}
+
+func testConfig(t *testing.T) *Config {
+	t.Helper()
+	return &Config{
+		Arms:         []string{"arm"},
+		Bases:        []BaseConfig{{Name: "base", MovementSensor: "odometry"}},
+		Grippers:     []string{"gripper"},
+		SkillsDir:    t.TempDir(),
+		SampleRateHz: 100,
+	}
+}
+
+// teach records a skill in which the arm moves, the gripper grabs, the base drives, and the arm moves
+// again.
+func teach(t *testing.T, svc teaching.Service, r *testRobot, name string) *teaching.Skill {
+	t.Helper()
+	ctx := context.Background()
+	r.set(0, r3.Vector{})
+	test.That(t, svc.StartRecording(ctx, name, nil), test.ShouldBeNil)
+	time.Sleep(50 * time.Millisecond)
+	r.set(0.1, r3.Vector{})
+	time.Sleep(50 * time.Millisecond)
+	test.That(t, svc.Mark(ctx, teaching.Marker{Label: "grab", Gripper: "gripper", Action: teaching.ActionGrab}, nil), test.ShouldBeNil)
+	r.set(0.1, r3.Vector{Y: 0.1})
+	time.Sleep(50 * time.Millisecond)
+	r.set(0.2, r3.Vector{})
+	time.Sleep(50 * time.Millisecond)
+	skill, err := svc.StopRecording(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	return skill
+}
+
+func TestValidate(t *testing.T) {
+	deps, _, err := testConfig(t).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"arm", "base", "odometry", "gripper"})
+
+	_, _, err = (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = (&Config{Bases: []BaseConfig{{Name: "base"}}}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "movement_sensor")
+}
+
+func TestRecord(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRobot(t)
+	conf := testConfig(t)
+	svc := newService(t, conf, r.deps)
+
+	test.That(t, svc.StartRecording(ctx, "../pick", nil), test.ShouldNotBeNil)
+	test.That(t, svc.Mark(ctx, teaching.Marker{Label: "grab"}, nil), test.ShouldNotBeNil)
+	_, err := svc.StopRecording(ctx, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	skill := teach(t, svc, r, "pick")
+	test.That(t, skill.Name, test.ShouldEqual, "pick")
+	test.That(t, skill.Duration, test.ShouldBeGreaterThanOrEqualTo, 0.2)
+	test.That(t, skill.Markers, test.ShouldHaveLength, 1)
+	test.That(t, skill.Markers[0].Label, test.ShouldEqual, "grab")
+	test.That(t, skill.Markers[0].Time, test.ShouldBeBetween, 0.1, skill.Duration)
+	test.That(t, r.grabs, test.ShouldEqual, 1)
+
+	test.That(t, skill.Tracks, test.ShouldHaveLength, 2)
+	armTrack, baseTrack := skill.Tracks[0], skill.Tracks[1]
+	test.That(t, armTrack.Kind, test.ShouldEqual, teaching.TrackArm)
+	test.That(t, armTrack.Samples[0].Joints[0], test.ShouldEqual, 0)
+	test.That(t, armTrack.Samples[len(armTrack.Samples)-1].Joints[0], test.ShouldEqual, 0.2)
+	// samples where nothing changes are dropped.
+	test.That(t, len(armTrack.Samples), test.ShouldBeLessThanOrEqualTo, 6)
+	test.That(t, baseTrack.Kind, test.ShouldEqual, teaching.TrackBase)
+	driving := false
+	for _, s := range baseTrack.Samples {
+		driving = driving || s.LinearMMPerSec.Y == 100
+	}
+	test.That(t, driving, test.ShouldBeTrue)
+
+	_, err = os.Stat(filepath.Join(conf.SkillsDir, "pick.json"))
+	test.That(t, err, test.ShouldBeNil)
+	names, err := svc.ListSkills(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, names, test.ShouldResemble, []string{"pick"})
+	got, err := svc.GetSkill(ctx, "pick", nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, got, test.ShouldResemble, skill)
+
+	test.That(t, svc.DeleteSkill(ctx, "pick", nil), test.ShouldBeNil)
+	test.That(t, svc.DeleteSkill(ctx, "pick", nil), test.ShouldNotBeNil)
+	_, err = svc.GetSkill(ctx, "pick", nil)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRobot(t)
+	svc := newService(t, testConfig(t), r.deps)
+	skill := teach(t, svc, r, "pick")
+	r.set(0.5, r3.Vector{})
+
+	start := time.Now()
+	test.That(t, svc.Replay(ctx, teaching.ReplayReq{Skill: "pick", SpeedScale: 2}), test.ShouldBeNil)
+	// the skill replays in half the time it was recorded in.
+	test.That(t, time.Since(start).Seconds(), test.ShouldBeBetween, skill.Duration/2-0.01, skill.Duration)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// the arm moves to where the skill starts, to the marker, and to where the skill ends.
+	test.That(t, r.moves, test.ShouldHaveLength, 3)
+	test.That(t, r.moves[0][0], test.ShouldEqual, 0)
+	test.That(t, r.moves[1][0], test.ShouldEqual, 0.1)
+	test.That(t, r.moves[2][0], test.ShouldEqual, 0.2)
+	for _, opts := range r.through {
+		test.That(t, opts.MaxVelRads, test.ShouldBeGreaterThan, 0)
+	}
+	test.That(t, r.grabs, test.ShouldEqual, 2)
+	// the base drives twice as fast, and stops at the marker and the end.
+	test.That(t, r.velocity, test.ShouldContain, r3.Vector{Y: 200})
+	test.That(t, r.stops, test.ShouldEqual, 2)
+
+	st, err := teaching.GetStatus(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Replaying, test.ShouldEqual, "")
+	test.That(t, st.LastMarker, test.ShouldEqual, "grab")
+
+	test.That(t, svc.Replay(ctx, teaching.ReplayReq{Skill: "place"}), test.ShouldNotBeNil)
+	test.That(t, svc.Replay(ctx, teaching.ReplayReq{Skill: "pick", SpeedScale: -1}), test.ShouldNotBeNil)
+	err = svc.Replay(ctx, teaching.ReplayReq{Skill: "pick", Replan: true})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "motion_service")
+}
+
+// servoingArm is an arm that can be servoed, as UR arms can.
+type servoingArm struct {
+	arm.Arm
+	r *testRobot
+}
+
+func (a *servoingArm) ServoJoints(ctx context.Context, positions []referenceframe.Input) error {
+	a.r.mu.Lock()
+	defer a.r.mu.Unlock()
+	a.r.servoed = append(a.r.servoed, positions)
+	return nil
+}
+
+func TestReplayServo(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRobot(t)
+	// the robot applies its governor to the arms that services depend on.
+	g := governor.New()
+	r.deps[arm.Named("arm")] = governor.Wrap(g, arm.Named("arm"), &servoingArm{Arm: r.deps[arm.Named("arm")].(arm.Arm), r: r})
+	svc := newService(t, testConfig(t), r.deps)
+	teach(t, svc, r, "pick")
+
+	test.That(t, svc.Replay(ctx, teaching.ReplayReq{Skill: "pick"}), test.ShouldBeNil)
+	r.mu.Lock()
+	// the arm only moves to where the skill starts, and is servoed through the samples after.
+	test.That(t, r.moves, test.ShouldHaveLength, 1)
+	test.That(t, r.through, test.ShouldBeEmpty)
+	test.That(t, len(r.servoed), test.ShouldBeGreaterThan, 2)
+	test.That(t, r.servoed[len(r.servoed)-1][0], test.ShouldEqual, 0.2)
+	r.mu.Unlock()
+
+	g.SetLimits("zone", map[resource.Name]governor.Limit{arm.Named("arm"): {SpeedScale: 0}})
+	test.That(t, svc.Replay(ctx, teaching.ReplayReq{Skill: "pick"}), test.ShouldNotBeNil)
+}
+
+// moveRecorder is a motion service that only moves.
+type moveRecorder struct {
+	motion.Service
+	name resource.Name
+	move func(ctx context.Context, req motion.MoveReq) (bool, error)
+}
+
+func (m *moveRecorder) Name() resource.Name {
+	return m.name
+}
+
+func (m *moveRecorder) Move(ctx context.Context, req motion.MoveReq) (bool, error) {
+	return m.move(ctx, req)
+}
+
+func TestReplayReplan(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRobot(t)
+	var destinations []*referenceframe.PoseInFrame
+	ms := &moveRecorder{name: motion.Named("motion")}
+	ms.move = func(ctx context.Context, req motion.MoveReq) (bool, error) {
+		test.That(t, req.ComponentName, test.ShouldEqual, "arm")
+		test.That(t, req.WorldState, test.ShouldNotBeNil)
+		destinations = append(destinations, req.Destination)
+		return true, nil
+	}
+	r.deps[motion.Named("motion")] = ms
+	conf := testConfig(t)
+	conf.MotionService = "motion"
+	svc := newService(t, conf, r.deps)
+	teach(t, svc, r, "pick")
+
+	ws, err := referenceframe.NewWorldState(nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, svc.Replay(ctx, teaching.ReplayReq{Skill: "pick", Replan: true, WorldState: ws}), test.ShouldBeNil)
+	// the arm is planned to where the skill starts, to the marker, and to where it ends, rather than
+	// replaying its joint positions.
+	test.That(t, destinations, test.ShouldHaveLength, 3)
+	test.That(t, destinations[0].Parent(), test.ShouldEqual, "arm_origin")
+	test.That(t, r.moves, test.ShouldBeEmpty)
+	test.That(t, r.grabs, test.ShouldEqual, 2)
+}
+
+func TestDoCommand(t *testing.T) {
+	ctx := context.Background()
+	r := newTestRobot(t)
+	svc := newService(t, testConfig(t), r.deps)
+
+	_, err := svc.DoCommand(ctx, map[string]interface{}{teaching.DoStartRecording: "pick"})
+	test.That(t, err, test.ShouldBeNil)
+	st, err := teaching.GetStatus(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Recording, test.ShouldEqual, "pick")
+	test.That(t, st.Since.IsZero(), test.ShouldBeFalse)
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{
+		teaching.DoMark: map[string]interface{}{"label": "grab", "gripper": "gripper", "action": "grab"},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{teaching.DoMark: map[string]interface{}{"label": "grab", "gripper": "gripper"}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{teaching.DoStopRecording: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["name"], test.ShouldEqual, "pick")
+
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{teaching.DoListSkills: true})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[teaching.DoListSkills], test.ShouldResemble, []interface{}{"pick"})
+
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{teaching.DoGetSkill: "pick"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp["markers"], test.ShouldHaveLength, 1)
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{teaching.DoReplay: map[string]interface{}{"skill": "pick", "speed_scale": 10}})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{teaching.DoReplay: "pick"})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{teaching.DoDeleteSkill: "pick"})
+	test.That(t, err, test.ShouldBeNil)
+	_, err = svc.DoCommand(ctx, map[string]interface{}{"unknown": true})
+	test.That(t, err, test.ShouldEqual, resource.ErrDoUnimplemented)
+}
@@ -0,0 +1,306 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/services/teaching"
+)
+
+// A segment is the part of a skill between two markers, or a marker and an end of the skill.
+type segment struct {
+	start, end float64
+	// marker is the marker the segment ends at, if any.
+	marker *teaching.Marker
+}
+
+// segments splits a skill at its markers.
+func segments(skill *teaching.Skill) []segment {
+	var segs []segment
+	start := 0.
+	for i := range skill.Markers {
+		m := &skill.Markers[i]
+		segs = append(segs, segment{start: start, end: m.Time, marker: m})
+		start = m.Time
+	}
+	return append(segs, segment{start: start, end: math.Max(start, skill.Duration)})
+}
+
+// samplesIn returns the samples of a track in (seg.start, seg.end], and the last sample before them.
+func samplesIn(samples []teaching.Sample, seg segment) (teaching.Sample, []teaching.Sample) {
+	var before teaching.Sample
+	var in []teaching.Sample
+	for _, s := range samples {
+		switch {
+		case s.Time <= seg.start:
+			before = s
+		case s.Time <= seg.end:
+			in = append(in, s)
+		}
+	}
+	return before, in
+}
+
+// Replay moves each component to where the skill starts, then replays the skill one segment at a time,
+// stopping every component at each marker to perform it.
+func (svc *builtIn) Replay(ctx context.Context, req teaching.ReplayReq) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	if req.Replan && svc.motion == nil {
+		return errors.New("replanning requires a motion_service")
+	}
+	skill, err := svc.GetSkill(ctx, req.Skill, req.Extra)
+	if err != nil {
+		return err
+	}
+	for _, track := range skill.Tracks {
+		if err := svc.checkTrack(track); err != nil {
+			return err
+		}
+	}
+	for _, m := range skill.Markers {
+		if _, ok := svc.grippers[m.Gripper]; m.Gripper != "" && !ok {
+			return errors.Errorf("skill %q uses gripper %q which is not one of the grippers of the teaching service", skill.Name, m.Gripper)
+		}
+	}
+
+	ctx, release, err := svc.begin(ctx, skill.Name)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	err = svc.replay(ctx, skill, req)
+	if err != nil {
+		// components are stopped with a fresh context since ctx may be why the replay failed.
+		err = multierr.Combine(err, svc.stopAll(context.Background(), skill))
+	}
+	return err
+}
+
+func (svc *builtIn) checkTrack(track teaching.Track) error {
+	switch track.Kind {
+	case teaching.TrackArm:
+		if _, ok := svc.arms[track.Component]; !ok {
+			return errors.Errorf("arm %q is not one of the arms of the teaching service", track.Component)
+		}
+	case teaching.TrackBase:
+		if _, ok := svc.bases[track.Component]; !ok {
+			return errors.Errorf("base %q is not one of the bases of the teaching service", track.Component)
+		}
+	default:
+		return errors.Errorf("unknown track kind %q", track.Kind)
+	}
+	return nil
+}
+
+// begin marks the start of a replay, failing if the service is recording or replaying or is closed.
+// The returned context is canceled when the service is closed and must be released by calling the
+// returned function.
+func (svc *builtIn) begin(ctx context.Context, skill string) (context.Context, func(), error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.recording != nil {
+		return nil, nil, errors.Errorf("cannot replay while recording skill %q", svc.recording.skill.Name)
+	}
+	if svc.status.Replaying != "" {
+		return nil, nil, errors.Errorf("already replaying skill %q", svc.status.Replaying)
+	}
+	if err := svc.closeCtx.Err(); err != nil {
+		return nil, nil, errors.Wrap(err, "teaching service is closed")
+	}
+	svc.status = teaching.Status{Replaying: skill, Since: time.Now()}
+
+	svc.activeOps.Add(1)
+	opCtx, cancel := context.WithCancel(ctx)
+	stopOnClose := context.AfterFunc(svc.closeCtx, cancel)
+	return opCtx, func() {
+		stopOnClose()
+		cancel()
+		svc.mu.Lock()
+		svc.status.Replaying = ""
+		svc.mu.Unlock()
+		svc.activeOps.Done()
+	}, nil
+}
+
+func (svc *builtIn) replay(ctx context.Context, skill *teaching.Skill, req teaching.ReplayReq) error {
+	// arms first move to where the skill starts, which it may not have been replayed from.
+	for _, track := range skill.Tracks {
+		if track.Kind != teaching.TrackArm || len(track.Samples) == 0 {
+			continue
+		}
+		if err := svc.moveArmTo(ctx, track.Component, track.Samples[0].Joints, req); err != nil {
+			return err
+		}
+	}
+
+	for _, seg := range segments(skill) {
+		// the tracks of a segment are replayed together, and all stop once any fails.
+		segCtx, cancel := context.WithCancel(ctx)
+		errs := make([]error, len(skill.Tracks))
+		var wg sync.WaitGroup
+		for i, track := range skill.Tracks {
+			wg.Add(1)
+			goutils.PanicCapturingGo(func() {
+				defer wg.Done()
+				if errs[i] = svc.replayTrack(segCtx, track, seg, req); errs[i] != nil {
+					cancel()
+				}
+			})
+		}
+		wg.Wait()
+		cancel()
+		if err := multierr.Combine(errs...); err != nil {
+			return err
+		}
+		if seg.marker == nil {
+			continue
+		}
+		if err := svc.performMarker(ctx, *seg.marker); err != nil {
+			return errors.Wrapf(err, "failed to perform marker %q", seg.marker.Label)
+		}
+		svc.mu.Lock()
+		svc.status.LastMarker = seg.marker.Label
+		svc.mu.Unlock()
+	}
+	return nil
+}
+
+// replayTrack replays the samples of a track in a segment, at the speed scale of req, and returns once
+// the component has finished the segment and the segment's time has passed.
+func (svc *builtIn) replayTrack(ctx context.Context, track teaching.Track, seg segment, req teaching.ReplayReq) error {
+	start := time.Now()
+	before, samples := samplesIn(track.Samples, seg)
+	at := func(t float64) time.Time {
+		return start.Add(time.Duration((t - seg.start) / req.SpeedScale * float64(time.Second)))
+	}
+	waitUntil := func(t time.Time) bool {
+		return goutils.SelectContextOrWait(ctx, time.Until(t))
+	}
+
+	switch track.Kind {
+	case teaching.TrackArm:
+		if len(samples) > 0 {
+			if err := svc.replayArm(ctx, track.Component, before, samples, at, req); err != nil {
+				return err
+			}
+		}
+	case teaching.TrackBase:
+		b := svc.bases[track.Component].base
+		for _, s := range samples {
+			if !waitUntil(at(s.Time)) {
+				return ctx.Err()
+			}
+			if err := b.SetVelocity(ctx, s.LinearMMPerSec.Mul(req.SpeedScale), s.AngularDegsPerSec.Mul(req.SpeedScale), nil); err != nil {
+				return err
+			}
+		}
+		if !waitUntil(at(seg.end)) {
+			return ctx.Err()
+		}
+		// bases stop at markers as arms do.
+		return b.Stop(ctx, nil)
+	}
+	if !waitUntil(at(seg.end)) {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// replayArm moves an arm through the samples of a segment. Arms that can be servoed follow the samples
+// in time; others move through them as fast as the fastest joint moved when recorded.
+func (svc *builtIn) replayArm(
+	ctx context.Context,
+	name string,
+	before teaching.Sample,
+	samples []teaching.Sample,
+	at func(float64) time.Time,
+	req teaching.ReplayReq,
+) error {
+	last := samples[len(samples)-1].Joints
+	if req.Replan {
+		return svc.moveArmTo(ctx, name, last, req)
+	}
+	a := svc.arms[name]
+	if servoer, ok := a.(arm.JointServoer); ok {
+		for _, s := range samples {
+			if !goutils.SelectContextOrWait(ctx, time.Until(at(s.Time))) {
+				return ctx.Err()
+			}
+			if err := servoer.ServoJoints(ctx, s.Joints); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	waypoints := make([][]referenceframe.Input, 0, len(samples))
+	maxVel := 0.
+	prev := before
+	for _, s := range samples {
+		if dt := s.Time - prev.Time; dt > 0 && len(prev.Joints) == len(s.Joints) {
+			for j := range s.Joints {
+				maxVel = math.Max(maxVel, math.Abs(s.Joints[j]-prev.Joints[j])/dt)
+			}
+		}
+		waypoints = append(waypoints, s.Joints)
+		prev = s
+	}
+	if maxVel == 0 {
+		// the arm held still through the segment.
+		return nil
+	}
+	maxVel *= req.SpeedScale
+	return a.MoveThroughJointPositions(ctx, waypoints, &arm.MoveOptions{MaxVelRads: maxVel}, req.Extra)
+}
+
+// moveArmTo moves an arm to joint positions, planning a move around the obstacles of req with the
+// motion service if req replans.
+func (svc *builtIn) moveArmTo(ctx context.Context, name string, joints []referenceframe.Input, req teaching.ReplayReq) error {
+	a := svc.arms[name]
+	if !req.Replan {
+		return a.MoveToJointPositions(ctx, joints, req.Extra)
+	}
+	model, err := a.Kinematics(ctx)
+	if err != nil {
+		return err
+	}
+	pose, err := model.Transform(joints)
+	if err != nil {
+		return err
+	}
+	// the pose of an arm's end is relative to its origin frame, which places the arm in the frame
+	// system.
+	_, err = svc.motion.Move(ctx, motion.MoveReq{
+		ComponentName: name,
+		Destination:   referenceframe.NewPoseInFrame(name+"_origin", pose),
+		WorldState:    req.WorldState,
+		Extra:         req.Extra,
+	})
+	return err
+}
+
+// stopAll stops every component of a skill.
+func (svc *builtIn) stopAll(ctx context.Context, skill *teaching.Skill) error {
+	var err error
+	for _, track := range skill.Tracks {
+		switch track.Kind {
+		case teaching.TrackArm:
+			err = multierr.Combine(err, svc.arms[track.Component].Stop(ctx, nil))
+		case teaching.TrackBase:
+			err = multierr.Combine(err, svc.bases[track.Component].base.Stop(ctx, nil))
+		}
+	}
+	return err
+}
@@ -0,0 +1,7 @@
+// Package register registers all relevant teaching models and also API specific functions
+package register
+
+import (
+	// for teaching models.
+	_ "go.viam.com/rdk/services/teaching/builtin"
+)
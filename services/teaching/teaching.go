@@ -0,0 +1,314 @@
+// Package teaching defines a service that records trajectories driven by an operator as named skills,
+// and replays them, so that robots can be taught tasks by demonstration.
+package teaching
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = "teaching"
+
+// API is a variable that identifies the teaching resource API.
+var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
+
+// export keys to be used with DoCommand so they can be referenced by clients.
+const (
+	DoStartRecording = "start_recording"
+	DoMark           = "mark"
+	DoStopRecording  = "stop_recording"
+	DoReplay         = "replay"
+	DoListSkills     = "list_skills"
+	DoGetSkill       = "get_skill"
+	DoDeleteSkill    = "delete_skill"
+)
+
+// Named is a helper for getting the named teaching service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// Deprecated: FromRobot is a helper for getting the named teaching service from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named teaching service
+// from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Service, error) {
+	return resource.FromProvider[Service](provider, Named(name))
+}
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Service]{})
+}
+
+// A TrackKind is the kind of component a track records.
+type TrackKind string
+
+// The set of known track kinds.
+const (
+	// TrackArm records the joint positions of an arm.
+	TrackArm = TrackKind("arm")
+	// TrackBase records the velocities of a base, as measured by a movement sensor.
+	TrackBase = TrackKind("base")
+)
+
+// A Sample is the state of a component at a time in a skill.
+type Sample struct {
+	// Time is the number of seconds since the skill started.
+	Time float64 `json:"t"`
+	// Joints are the joint positions of an arm.
+	Joints []referenceframe.Input `json:"joints,omitempty"`
+	// LinearMMPerSec and AngularDegsPerSec are the velocities of a base.
+	LinearMMPerSec    r3.Vector `json:"linear_mm_per_sec,omitzero"`
+	AngularDegsPerSec r3.Vector `json:"angular_degs_per_sec,omitzero"`
+}
+
+// A Track is the samples of one component in a skill, in order of time.
+type Track struct {
+	Component string    `json:"component"`
+	Kind      TrackKind `json:"kind"`
+	Samples   []Sample  `json:"samples"`
+}
+
+// A MarkerAction is what a gripper does at a marker.
+type MarkerAction string
+
+// The set of known marker actions.
+const (
+	ActionOpen = MarkerAction("open")
+	ActionGrab = MarkerAction("grab")
+)
+
+// A Marker is an event in a skill, such as a gripper grabbing an object. Arms stop at markers during
+// replay, so that what happens at a marker happens where it did when the skill was taught.
+type Marker struct {
+	// Time is the number of seconds since the skill started.
+	Time  float64 `json:"t"`
+	Label string  `json:"label"`
+	// Gripper is the gripper that performs Action at the marker, if any.
+	Gripper string       `json:"gripper,omitempty"`
+	Action  MarkerAction `json:"action,omitempty"`
+}
+
+// Validate ensures the marker's gripper and action go together.
+func (m *Marker) Validate() error {
+	if (m.Gripper == "") != (m.Action == "") {
+		return errors.New("a marker needs both a gripper and an action, or neither")
+	}
+	switch m.Action {
+	case "", ActionOpen, ActionGrab:
+		return nil
+	default:
+		return errors.Errorf("unknown marker action %q", m.Action)
+	}
+}
+
+// A Skill is a recorded demonstration of a task.
+type Skill struct {
+	Name    string   `json:"name"`
+	Tracks  []Track  `json:"tracks"`
+	Markers []Marker `json:"markers,omitempty"`
+	// Duration is the number of seconds the skill lasts.
+	Duration float64 `json:"duration"`
+}
+
+var skillNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ValidateSkillName ensures a skill name can be stored as a file name.
+func ValidateSkillName(name string) error {
+	if !skillNameRegexp.MatchString(name) {
+		return errors.Errorf("skill name %q must start with a letter or digit and contain only letters, digits, '_', '.', and '-'", name)
+	}
+	return nil
+}
+
+// A ReplayReq replays a skill.
+type ReplayReq struct {
+	Skill string `json:"skill"`
+	// SpeedScale is how much faster than it was recorded to replay the skill. SpeedScale defaults to 1
+	// if unspecified.
+	SpeedScale float64 `json:"speed_scale,omitempty"`
+	// Replan is whether to plan the motion of arms between markers with the motion service, around the
+	// obstacles of WorldState, rather than replaying their recorded joint positions.
+	Replan     bool                       `json:"replan,omitempty"`
+	WorldState *referenceframe.WorldState `json:"-"`
+
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+// Validate ensures the request is complete and fills in its defaults.
+func (req *ReplayReq) Validate() error {
+	if req.Skill == "" {
+		return errors.New("replay requires a skill")
+	}
+	if req.SpeedScale < 0 {
+		return errors.Errorf("replay speed_scale must be positive but got %v", req.SpeedScale)
+	}
+	if req.SpeedScale == 0 {
+		req.SpeedScale = 1
+	}
+	return nil
+}
+
+// Status reports the state of a teaching service.
+type Status struct {
+	// Recording is the name of the skill being recorded, if any.
+	Recording string
+	// Replaying is the name of the skill being replayed, if any.
+	Replaying string
+	// LastMarker is the label of the marker most recently recorded or replayed.
+	LastMarker string
+	// Since is when the current recording or replay started.
+	Since time.Time
+}
+
+// A Service records and replays skills.
+// Its resource Status reports the teaching Status in the form produced by StatusToMap.
+type Service interface {
+	resource.Resource
+	// StartRecording starts recording the components of the service as the named skill.
+	StartRecording(ctx context.Context, skill string, extra map[string]interface{}) error
+	// Mark adds a marker at the current time of the recording, performing its gripper action, if any.
+	Mark(ctx context.Context, marker Marker, extra map[string]interface{}) error
+	// StopRecording stops recording, and stores and returns the recorded skill.
+	StopRecording(ctx context.Context, extra map[string]interface{}) (*Skill, error)
+	// Replay replays a stored skill, returning once it is done.
+	Replay(ctx context.Context, req ReplayReq) error
+	// ListSkills returns the names of the stored skills.
+	ListSkills(ctx context.Context, extra map[string]interface{}) ([]string, error)
+	// GetSkill returns a stored skill.
+	GetSkill(ctx context.Context, name string, extra map[string]interface{}) (*Skill, error)
+	// DeleteSkill deletes a stored skill.
+	DeleteSkill(ctx context.Context, name string, extra map[string]interface{}) error
+}
+
+// GetStatus returns what the service is recording or replaying.
+func GetStatus(ctx context.Context, svc Service) (Status, error) {
+	m, err := svc.Status(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	return StatusFromMap(m)
+}
+
+// StatusToMap converts a Status into the map reported by a teaching service's resource Status.
+func StatusToMap(st Status) map[string]interface{} {
+	m := map[string]interface{}{
+		"recording":   st.Recording,
+		"replaying":   st.Replaying,
+		"last_marker": st.LastMarker,
+	}
+	if !st.Since.IsZero() {
+		m["since"] = st.Since.UTC().Format(time.RFC3339Nano)
+	}
+	return m
+}
+
+// StatusFromMap converts a map produced by StatusToMap back into a Status.
+func StatusFromMap(m map[string]interface{}) (Status, error) {
+	var st Status
+	var ok bool
+	if st.Recording, ok = m["recording"].(string); !ok {
+		return Status{}, errors.Errorf("expected teaching status recording to be a string but got %T", m["recording"])
+	}
+	st.Replaying, _ = m["replaying"].(string)
+	st.LastMarker, _ = m["last_marker"].(string)
+	if since, ok := m["since"].(string); ok {
+		t, err := time.Parse(time.RFC3339Nano, since)
+		if err != nil {
+			return Status{}, err
+		}
+		st.Since = t
+	}
+	return st, nil
+}
+
+type markCommand struct {
+	Mark  Marker                 `json:"mark"`
+	Extra map[string]interface{} `json:"extra,omitempty"`
+}
+
+func (c *markCommand) Validate() error {
+	return c.Mark.Validate()
+}
+
+// HandleTeachingCommand services the teaching DoCommand keys using the given Service, so that skills
+// can be recorded and replayed through DoCommand by callers that only have a generic resource
+// handle. It returns false if cmd does not contain any of them so that it can be chained from a
+// DoCommand implementation.
+//
+// DoStartRecording, DoGetSkill, and DoDeleteSkill take the name of a skill, DoMark takes a Marker,
+// and DoReplay takes a ReplayReq. DoStopRecording and DoGetSkill respond with a Skill, and
+// DoListSkills with a list of names.
+func HandleTeachingCommand(
+	ctx context.Context,
+	svc Service,
+	cmd map[string]interface{},
+) (map[string]interface{}, bool, error) {
+	extra, _ := cmd["extra"].(map[string]interface{})
+	switch {
+	case cmd[DoStartRecording] != nil:
+		name, _ := cmd[DoStartRecording].(string)
+		return map[string]interface{}{}, true, svc.StartRecording(ctx, name, extra)
+	case cmd[DoMark] != nil:
+		req, err := resource.DecodeDoCommand[markCommand](cmd)
+		if err != nil {
+			return nil, true, errors.Wrapf(err, "invalid %s command", DoMark)
+		}
+		return map[string]interface{}{}, true, svc.Mark(ctx, req.Mark, req.Extra)
+	case cmd[DoStopRecording] != nil:
+		skill, err := svc.StopRecording(ctx, extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(skill)
+		return resp, true, err
+	case cmd[DoReplay] != nil:
+		raw, ok := cmd[DoReplay].(map[string]interface{})
+		if !ok {
+			return nil, true, errors.Errorf("expected %s to be a map but got %T", DoReplay, cmd[DoReplay])
+		}
+		req, err := resource.DecodeDoCommand[ReplayReq](raw)
+		if err != nil {
+			return nil, true, errors.Wrapf(err, "invalid %s command", DoReplay)
+		}
+		return map[string]interface{}{}, true, svc.Replay(ctx, req)
+	case cmd[DoListSkills] != nil:
+		names, err := svc.ListSkills(ctx, extra)
+		if err != nil {
+			return nil, true, err
+		}
+		list := make([]interface{}, 0, len(names))
+		for _, n := range names {
+			list = append(list, n)
+		}
+		return map[string]interface{}{DoListSkills: list}, true, nil
+	case cmd[DoGetSkill] != nil:
+		name, _ := cmd[DoGetSkill].(string)
+		skill, err := svc.GetSkill(ctx, name, extra)
+		if err != nil {
+			return nil, true, err
+		}
+		resp, err := resource.EncodeDoCommand(skill)
+		return resp, true, err
+	case cmd[DoDeleteSkill] != nil:
+		name, _ := cmd[DoDeleteSkill].(string)
+		return map[string]interface{}{}, true, svc.DeleteSkill(ctx, name, extra)
+	default:
+		return nil, false, nil
+	}
+}
@@ -0,0 +1,539 @@
+// Package builtin implements a teleop service that maps input controller events onto component commands.
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/teleop"
+	rutils "go.viam.com/rdk/utils"
+)
+
+const (
+	defaultRateHz             = 10.
+	defaultMaxJointDegsPerSec = 30.
+)
+
+func init() {
+	resource.RegisterService(teleop.API, resource.DefaultServiceModel, resource.Registration[teleop.Service, *Config]{
+		Constructor: NewBuiltIn,
+	})
+}
+
+// Action is the component command that a control is mapped to.
+type Action string
+
+// The set of supported actions. Axis actions scale the control's value into a command; button actions
+// fire when the control is pressed.
+const (
+	// ActionBaseLinear drives a base forward and backward with the control's value as linear power.
+	ActionBaseLinear = Action("base_linear")
+	// ActionBaseAngular turns a base with the control's value as angular power.
+	ActionBaseAngular = Action("base_angular")
+	// ActionArmJoint jogs one arm joint at a velocity proportional to the control's value.
+	ActionArmJoint = Action("arm_joint")
+	// ActionGripperOpen opens a gripper.
+	ActionGripperOpen = Action("gripper_open")
+	// ActionGripperGrab closes a gripper.
+	ActionGripperGrab = Action("gripper_grab")
+	// ActionStop stops every mapped component.
+	ActionStop = Action("stop")
+)
+
+func (a Action) isAxis() bool {
+	return a == ActionBaseLinear || a == ActionBaseAngular || a == ActionArmJoint
+}
+
+// MappingConfig maps one input control onto a component command.
+type MappingConfig struct {
+	Control   input.Control `json:"control"`
+	Action    Action        `json:"action"`
+	Component string        `json:"component,omitempty"`
+	// Joint is the index of the joint moved by ActionArmJoint.
+	Joint int `json:"joint,omitempty"`
+	// Scale multiplies the shaped axis value; it defaults to 1.
+	Scale  float64 `json:"scale,omitempty"`
+	Invert bool    `json:"invert,omitempty"`
+	// DeadZone is the magnitude in [0, 1) below which axis values are treated as zero. Values outside the
+	// dead zone are rescaled so the output still spans the full range.
+	DeadZone float64 `json:"dead_zone,omitempty"`
+	// Expo in [0, 1] blends a cubic curve into the response for finer control near center.
+	Expo float64 `json:"expo,omitempty"`
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	InputControllerName string          `json:"input_controller"`
+	Mappings            []MappingConfig `json:"mappings"`
+	// EnableControl is a deadman button that must be held for any command other than stop to be sent.
+	EnableControl input.Control `json:"enable_control,omitempty"`
+	// TimeoutMS stops all components if the input controller cannot be reached for this long. The
+	// controller's current state is polled every control loop tick, so a stick held steady keeps
+	// components moving. Zero disables it.
+	TimeoutMS          int     `json:"timeout_ms,omitempty"`
+	RateHz             float64 `json:"rate_hz,omitempty"`
+	MaxJointDegsPerSec float64 `json:"max_joint_degs_per_sec,omitempty"`
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, []string, error) {
+	if conf.InputControllerName == "" {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "input_controller")
+	}
+	if len(conf.Mappings) == 0 {
+		return nil, nil, resource.NewConfigValidationFieldRequiredError(path, "mappings")
+	}
+	if conf.TimeoutMS < 0 || conf.RateHz < 0 || conf.MaxJointDegsPerSec < 0 {
+		return nil, nil, resource.NewConfigValidationError(path,
+			errors.New("timeout_ms, rate_hz, and max_joint_degs_per_sec cannot be negative"))
+	}
+	deps := []string{conf.InputControllerName}
+	seen := map[string]bool{}
+	for i, m := range conf.Mappings {
+		mPath := fmt.Sprintf("%s.mappings.%d", path, i)
+		if m.Control == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(mPath, "control")
+		}
+		switch m.Action {
+		case ActionStop:
+			continue
+		case ActionBaseLinear, ActionBaseAngular, ActionArmJoint, ActionGripperOpen, ActionGripperGrab:
+		default:
+			return nil, nil, resource.NewConfigValidationError(mPath, errors.Errorf("unknown action %q", m.Action))
+		}
+		if m.Component == "" {
+			return nil, nil, resource.NewConfigValidationFieldRequiredError(mPath, "component")
+		}
+		if m.DeadZone < 0 || m.DeadZone >= 1 {
+			return nil, nil, resource.NewConfigValidationError(mPath, errors.New("dead_zone must be in [0, 1)"))
+		}
+		if m.Expo < 0 || m.Expo > 1 {
+			return nil, nil, resource.NewConfigValidationError(mPath, errors.New("expo must be in [0, 1]"))
+		}
+		if m.Joint < 0 {
+			return nil, nil, resource.NewConfigValidationError(mPath, errors.New("joint cannot be negative"))
+		}
+		if !seen[m.Component] {
+			seen[m.Component] = true
+			deps = append(deps, m.Component)
+		}
+	}
+	return deps, nil, nil
+}
+
+// shape applies the mapping's dead zone, expo curve, inversion, and scale to a raw axis value in [-1, 1].
+func (m *MappingConfig) shape(v float64) float64 {
+	v = math.Max(-1, math.Min(1, v))
+	mag := math.Abs(v)
+	if mag <= m.DeadZone {
+		return 0
+	}
+	mag = (mag - m.DeadZone) / (1 - m.DeadZone)
+	mag = (1-m.Expo)*mag + m.Expo*mag*mag*mag
+	out := math.Copysign(mag, v)
+	if m.Invert {
+		out = -out
+	}
+	scale := m.Scale
+	if scale == 0 {
+		scale = 1
+	}
+	return out * scale
+}
+
+type baseCommand struct {
+	linear, angular float64
+}
+
+type builtIn struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	conf       *Config
+	controller input.Controller
+	bases      map[string]base.Base
+	arms       map[string]arm.Arm
+	grippers   map[string]gripper.Gripper
+	logger     logging.Logger
+
+	mu         sync.Mutex
+	axes       []float64
+	enabled    bool
+	connected  bool
+	lastEvent  time.Time
+	lastPoll   time.Time
+	lastBase   map[string]baseCommand
+	wasEngaged bool
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// NewBuiltIn returns a new teleop service.
+func NewBuiltIn(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger logging.Logger,
+) (teleop.Service, error) {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	controller, err := input.FromProvider(deps, svcConfig.InputControllerName)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	svc := &builtIn{
+		Named:      conf.ResourceName().AsNamed(),
+		conf:       svcConfig,
+		controller: controller,
+		bases:      map[string]base.Base{},
+		arms:       map[string]arm.Arm{},
+		grippers:   map[string]gripper.Gripper{},
+		logger:     logger,
+		axes:       make([]float64, len(svcConfig.Mappings)),
+		enabled:    svcConfig.EnableControl == "",
+		connected:  true,
+		lastEvent:  time.Now(),
+		lastPoll:   time.Now(),
+		lastBase:   map[string]baseCommand{},
+		cancelCtx:  cancelCtx,
+		cancel:     cancel,
+	}
+	for _, m := range svcConfig.Mappings {
+		var err error
+		switch m.Action {
+		case ActionBaseLinear, ActionBaseAngular:
+			svc.bases[m.Component], err = base.FromProvider(deps, m.Component)
+		case ActionArmJoint:
+			svc.arms[m.Component], err = arm.FromProvider(deps, m.Component)
+		case ActionGripperOpen, ActionGripperGrab:
+			svc.grippers[m.Component], err = gripper.FromProvider(deps, m.Component)
+		case ActionStop:
+		}
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+
+	if err := svc.registerCallbacks(ctx); err != nil {
+		cancel()
+		return nil, err
+	}
+	svc.startControlLoop()
+	return svc, nil
+}
+
+func (svc *builtIn) registerCallbacks(ctx context.Context) error {
+	controls := map[input.Control]bool{}
+	for _, m := range svc.conf.Mappings {
+		controls[m.Control] = true
+	}
+	if svc.conf.EnableControl != "" {
+		controls[svc.conf.EnableControl] = true
+	}
+	for control := range controls {
+		if err := svc.controller.RegisterControlCallback(
+			ctx,
+			control,
+			[]input.EventType{
+				input.ButtonPress, input.ButtonRelease, input.PositionChangeAbs, input.Connect, input.Disconnect,
+			},
+			svc.handleEvent,
+			map[string]interface{}{},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (svc *builtIn) handleEvent(ctx context.Context, ev input.Event) {
+	if svc.cancelCtx.Err() != nil {
+		return
+	}
+	var pressed []MappingConfig
+
+	svc.mu.Lock()
+	svc.lastEvent = time.Now()
+	switch ev.Event {
+	case input.Connect:
+		svc.connected = true
+	case input.Disconnect:
+		// lose the controller and we lose the operator's ability to stop, so stop everything now
+		svc.connected = false
+		for i := range svc.axes {
+			svc.axes[i] = 0
+		}
+	case input.ButtonPress, input.ButtonRelease:
+		if ev.Control == svc.conf.EnableControl {
+			svc.enabled = ev.Event == input.ButtonPress
+		}
+		for i, m := range svc.conf.Mappings {
+			if m.Control != ev.Control {
+				continue
+			}
+			if m.Action.isAxis() {
+				svc.axes[i] = m.shape(ev.Value)
+			} else if ev.Event == input.ButtonPress {
+				pressed = append(pressed, m)
+			}
+		}
+	case input.PositionChangeAbs:
+		for i, m := range svc.conf.Mappings {
+			if m.Control == ev.Control && m.Action.isAxis() {
+				svc.axes[i] = m.shape(ev.Value)
+			}
+		}
+	default:
+	}
+	engaged := svc.engagedLocked()
+	svc.mu.Unlock()
+
+	for _, m := range pressed {
+		if m.Action != ActionStop && !engaged {
+			continue
+		}
+		m := m
+		svc.activeBackgroundWorkers.Add(1)
+		utils.PanicCapturingGo(func() {
+			defer svc.activeBackgroundWorkers.Done()
+			if err := svc.press(svc.cancelCtx, m); err != nil {
+				svc.logger.CWarnw(svc.cancelCtx, "teleop command failed", "action", m.Action, "component", m.Component, "error", err)
+			}
+		})
+	}
+}
+
+func (svc *builtIn) press(ctx context.Context, m MappingConfig) error {
+	switch m.Action {
+	case ActionGripperOpen:
+		return svc.grippers[m.Component].Open(ctx, nil)
+	case ActionGripperGrab:
+		_, err := svc.grippers[m.Component].Grab(ctx, nil)
+		return err
+	case ActionStop:
+		svc.mu.Lock()
+		for i := range svc.axes {
+			svc.axes[i] = 0
+		}
+		svc.mu.Unlock()
+		return svc.stopAll(ctx)
+	case ActionBaseLinear, ActionBaseAngular, ActionArmJoint:
+	}
+	return nil
+}
+
+// engagedLocked returns whether the interlocks currently allow motion commands.
+func (svc *builtIn) engagedLocked() bool {
+	if !svc.connected || !svc.enabled {
+		return false
+	}
+	if svc.conf.TimeoutMS > 0 && time.Since(svc.lastPoll) > time.Duration(svc.conf.TimeoutMS)*time.Millisecond {
+		return false
+	}
+	return true
+}
+
+func (svc *builtIn) startControlLoop() {
+	rate := svc.conf.RateHz
+	if rate == 0 {
+		rate = defaultRateHz
+	}
+	period := time.Duration(float64(time.Second) / rate)
+	svc.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		for utils.SelectContextOrWait(svc.cancelCtx, period) {
+			if err := svc.tick(svc.cancelCtx, period); err != nil && svc.cancelCtx.Err() == nil {
+				svc.logger.CWarnw(svc.cancelCtx, "teleop control loop error", "error", err)
+			}
+		}
+	}, svc.activeBackgroundWorkers.Done)
+}
+
+// poll refreshes the held state of every mapped control from the controller. Callbacks only fire when
+// a control changes, so polling is what tells a stick held still apart from a controller that stopped
+// responding.
+func (svc *builtIn) poll(ctx context.Context) error {
+	events, err := svc.controller.Events(ctx, nil)
+	if err != nil {
+		return err
+	}
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	svc.lastPoll = time.Now()
+	if ev, ok := events[svc.conf.EnableControl]; ok && svc.conf.EnableControl != "" {
+		switch ev.Event {
+		case input.ButtonPress, input.ButtonRelease:
+			svc.enabled = ev.Event == input.ButtonPress
+		default:
+		}
+	}
+	for i, m := range svc.conf.Mappings {
+		if !m.Action.isAxis() {
+			continue
+		}
+		ev, ok := events[m.Control]
+		if !ok {
+			continue
+		}
+		switch ev.Event {
+		case input.ButtonPress, input.ButtonRelease, input.PositionChangeAbs:
+			svc.axes[i] = m.shape(ev.Value)
+		default:
+		}
+	}
+	return nil
+}
+
+// tick sends the commands implied by the current axis values.
+func (svc *builtIn) tick(ctx context.Context, dt time.Duration) error {
+	var errs error
+	if err := svc.poll(ctx); err != nil {
+		errs = errors.Wrap(err, "failed to poll input controller")
+	}
+
+	svc.mu.Lock()
+	engaged := svc.engagedLocked()
+	disengaged := svc.wasEngaged && !engaged
+	svc.wasEngaged = engaged
+	baseCmds := map[string]baseCommand{}
+	jointVels := map[string]map[int]float64{}
+	if engaged {
+		for i, m := range svc.conf.Mappings {
+			switch m.Action {
+			case ActionBaseLinear:
+				cmd := baseCmds[m.Component]
+				cmd.linear += svc.axes[i]
+				baseCmds[m.Component] = cmd
+			case ActionBaseAngular:
+				cmd := baseCmds[m.Component]
+				cmd.angular += svc.axes[i]
+				baseCmds[m.Component] = cmd
+			case ActionArmJoint:
+				if svc.axes[i] == 0 {
+					continue
+				}
+				if jointVels[m.Component] == nil {
+					jointVels[m.Component] = map[int]float64{}
+				}
+				jointVels[m.Component][m.Joint] += svc.axes[i]
+			case ActionGripperOpen, ActionGripperGrab, ActionStop:
+			}
+		}
+	}
+	svc.mu.Unlock()
+
+	if disengaged {
+		svc.logger.CInfo(ctx, "teleop interlock disengaged; stopping components")
+		return multierr.Combine(errs, svc.stopAll(ctx))
+	}
+	if !engaged {
+		return errs
+	}
+
+	for name, b := range svc.bases {
+		cmd := baseCmds[name]
+		cmd.linear = math.Max(-1, math.Min(1, cmd.linear))
+		cmd.angular = math.Max(-1, math.Min(1, cmd.angular))
+		svc.mu.Lock()
+		last, ok := svc.lastBase[name]
+		svc.mu.Unlock()
+		if ok && last == cmd {
+			continue
+		}
+		if err := b.SetPower(ctx, r3.Vector{Y: cmd.linear}, r3.Vector{Z: cmd.angular}, nil); err != nil {
+			errs = errors.Wrapf(err, "failed to set power of base %q", name)
+			continue
+		}
+		svc.mu.Lock()
+		svc.lastBase[name] = cmd
+		svc.mu.Unlock()
+	}
+
+	maxJointVel := svc.conf.MaxJointDegsPerSec
+	if maxJointVel == 0 {
+		maxJointVel = defaultMaxJointDegsPerSec
+	}
+	for name, vels := range jointVels {
+		a := svc.arms[name]
+		positions, err := a.JointPositions(ctx, nil)
+		if err != nil {
+			errs = err
+			continue
+		}
+		target := append([]referenceframe.Input{}, positions...)
+		var badJoint error
+		for joint, vel := range vels {
+			if joint >= len(target) {
+				badJoint = errors.Errorf("arm %q has no joint %d", name, joint)
+				break
+			}
+			target[joint] += rutils.DegToRad(vel * maxJointVel * dt.Seconds())
+		}
+		if badJoint != nil {
+			errs = badJoint
+			continue
+		}
+		if err := a.MoveToJointPositions(ctx, target, nil); err != nil {
+			errs = err
+		}
+	}
+	return errs
+}
+
+func (svc *builtIn) stopAll(ctx context.Context) error {
+	svc.mu.Lock()
+	svc.lastBase = map[string]baseCommand{}
+	svc.mu.Unlock()
+
+	var errs error
+	for name, b := range svc.bases {
+		if err := b.Stop(ctx, nil); err != nil {
+			errs = errors.Wrapf(err, "failed to stop base %q", name)
+		}
+	}
+	for name, a := range svc.arms {
+		if err := a.Stop(ctx, nil); err != nil {
+			errs = errors.Wrapf(err, "failed to stop arm %q", name)
+		}
+	}
+	for name, g := range svc.grippers {
+		if err := g.Stop(ctx, nil); err != nil {
+			errs = errors.Wrapf(err, "failed to stop gripper %q", name)
+		}
+	}
+	return errs
+}
+
+func (svc *builtIn) Status(ctx context.Context) (map[string]interface{}, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	return teleop.StatusToMap(teleop.Status{Engaged: svc.engagedLocked(), LastEvent: svc.lastEvent}), nil
+}
+
+func (svc *builtIn) Close(ctx context.Context) error {
+	svc.cancel()
+	svc.activeBackgroundWorkers.Wait()
+	return svc.stopAll(ctx)
+}
@@ -0,0 +1,285 @@
+package builtin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/arm"
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/logging"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/teleop"
+	rtestutils "go.viam.com/rdk/testutils"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestShape(t *testing.T) {
+	m := MappingConfig{DeadZone: 0.1}
+	test.That(t, m.shape(0.05), test.ShouldEqual, 0)
+	test.That(t, m.shape(-0.1), test.ShouldEqual, 0)
+	test.That(t, m.shape(1), test.ShouldAlmostEqual, 1)
+	test.That(t, m.shape(-0.55), test.ShouldAlmostEqual, -0.5)
+	test.That(t, m.shape(2), test.ShouldAlmostEqual, 1)
+
+	m = MappingConfig{Expo: 1, Invert: true, Scale: 0.5}
+	test.That(t, m.shape(0.5), test.ShouldAlmostEqual, -0.0625)
+	test.That(t, m.shape(-1), test.ShouldAlmostEqual, 0.5)
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{
+		InputControllerName: "pad",
+		Mappings: []MappingConfig{
+			{Control: input.AbsoluteY, Action: ActionBaseLinear, Component: "base"},
+			{Control: input.AbsoluteX, Action: ActionBaseAngular, Component: "base"},
+			{Control: input.ButtonSouth, Action: ActionGripperGrab, Component: "gripper"},
+			{Control: input.ButtonStart, Action: ActionStop},
+		},
+	}
+	deps, _, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	rtestutils.VerifySameElements(t, deps, []string{"pad", "base", "gripper"})
+
+	for _, m := range []MappingConfig{
+		{Action: ActionStop},
+		{Control: input.AbsoluteY, Action: "fly"},
+		{Control: input.AbsoluteY, Action: ActionBaseLinear},
+		{Control: input.AbsoluteY, Action: ActionBaseLinear, Component: "base", DeadZone: 1},
+		{Control: input.AbsoluteY, Action: ActionBaseLinear, Component: "base", Expo: 2},
+	} {
+		_, _, err := (&Config{InputControllerName: "pad", Mappings: []MappingConfig{m}}).Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+	_, _, err = (&Config{Mappings: conf.Mappings}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+type recordingComponents struct {
+	mu        sync.Mutex
+	callbacks map[input.Control]input.ControlFunction
+	state     map[input.Control]input.Event
+	pollErr   error
+	moves     int
+	power     r3.Vector
+	angular   r3.Vector
+	baseStops int
+	grabs     int
+	joints    []referenceframe.Input
+	deps      resource.Dependencies
+}
+
+func newRecordingComponents() *recordingComponents {
+	rc := &recordingComponents{
+		callbacks: map[input.Control]input.ControlFunction{},
+		state:     map[input.Control]input.Event{},
+		joints:    []referenceframe.Input{0, 0},
+	}
+	pad := &inject.InputController{}
+	pad.RegisterControlCallbackFunc = func(
+		ctx context.Context,
+		control input.Control,
+		triggers []input.EventType,
+		ctrlFunc input.ControlFunction,
+		extra map[string]interface{},
+	) error {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		rc.callbacks[control] = ctrlFunc
+		return nil
+	}
+
+	pad.EventsFunc = func(ctx context.Context, extra map[string]interface{}) (map[input.Control]input.Event, error) {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		if rc.pollErr != nil {
+			return nil, rc.pollErr
+		}
+		state := map[input.Control]input.Event{}
+		for control, ev := range rc.state {
+			state[control] = ev
+		}
+		return state, nil
+	}
+
+	b := inject.NewBase("base")
+	b.SetPowerFunc = func(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		rc.power, rc.angular = linear, angular
+		return nil
+	}
+	b.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		rc.baseStops++
+		rc.power, rc.angular = r3.Vector{}, r3.Vector{}
+		return nil
+	}
+
+	a := inject.NewArm("arm")
+	a.JointPositionsFunc = func(ctx context.Context, extra map[string]interface{}) ([]referenceframe.Input, error) {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		return append([]referenceframe.Input{}, rc.joints...), nil
+	}
+	a.MoveToJointPositionsFunc = func(ctx context.Context, positions []referenceframe.Input, extra map[string]interface{}) error {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		rc.joints = positions
+		rc.moves++
+		return nil
+	}
+	a.StopFunc = func(ctx context.Context, extra map[string]interface{}) error { return nil }
+
+	g := inject.NewGripper("gripper")
+	g.GrabFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		rc.grabs++
+		return true, nil
+	}
+	g.StopFunc = func(ctx context.Context, extra map[string]interface{}) error { return nil }
+
+	rc.deps = resource.Dependencies{
+		input.Named("pad"):       pad,
+		base.Named("base"):       b,
+		arm.Named("arm"):         a,
+		gripper.Named("gripper"): g,
+	}
+	return rc
+}
+
+func (rc *recordingComponents) send(ctx context.Context, control input.Control, event input.EventType, value float64) {
+	ev := input.Event{Time: time.Now(), Event: event, Control: control, Value: value}
+	rc.mu.Lock()
+	cb := rc.callbacks[control]
+	rc.state[control] = ev
+	rc.mu.Unlock()
+	cb(ctx, ev)
+}
+
+func TestTeleop(t *testing.T) {
+	ctx := context.Background()
+	rc := newRecordingComponents()
+	conf := &Config{
+		InputControllerName: "pad",
+		EnableControl:       input.ButtonLT,
+		RateHz:              100,
+		Mappings: []MappingConfig{
+			{Control: input.AbsoluteY, Action: ActionBaseLinear, Component: "base", DeadZone: 0.1, Invert: true},
+			{Control: input.AbsoluteX, Action: ActionBaseAngular, Component: "base", Scale: 0.5},
+			{Control: input.AbsoluteRX, Action: ActionArmJoint, Component: "arm", Joint: 1},
+			{Control: input.ButtonSouth, Action: ActionGripperGrab, Component: "gripper"},
+			{Control: input.ButtonStart, Action: ActionStop},
+		},
+	}
+	svc, err := NewBuiltIn(ctx, rc.deps, resource.Config{
+		Name:                "teleop",
+		API:                 teleop.API,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// nothing moves until the deadman button is held
+	st, err := teleop.GetStatus(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Engaged, test.ShouldBeFalse)
+	rc.send(ctx, input.AbsoluteY, input.PositionChangeAbs, -1)
+	rc.send(ctx, input.ButtonSouth, input.ButtonPress, 1)
+	time.Sleep(50 * time.Millisecond)
+	rc.mu.Lock()
+	test.That(t, rc.power, test.ShouldResemble, r3.Vector{})
+	test.That(t, rc.grabs, test.ShouldEqual, 0)
+	rc.mu.Unlock()
+
+	rc.send(ctx, input.ButtonLT, input.ButtonPress, 1)
+	rc.send(ctx, input.AbsoluteX, input.PositionChangeAbs, 1)
+	rc.send(ctx, input.AbsoluteRX, input.PositionChangeAbs, 1)
+	rc.send(ctx, input.ButtonSouth, input.ButtonPress, 1)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		test.That(tb, rc.power.Y, test.ShouldAlmostEqual, 1)
+		test.That(tb, rc.angular.Z, test.ShouldAlmostEqual, 0.5)
+		test.That(tb, rc.grabs, test.ShouldEqual, 1)
+		test.That(tb, rc.joints[0], test.ShouldEqual, 0)
+		test.That(tb, rc.joints[1], test.ShouldBeGreaterThan, 0)
+	})
+	st, err = teleop.GetStatus(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Engaged, test.ShouldBeTrue)
+
+	// releasing the deadman button stops everything
+	rc.send(ctx, input.ButtonLT, input.ButtonRelease, 0)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		test.That(tb, rc.baseStops, test.ShouldBeGreaterThan, 0)
+		test.That(tb, rc.power, test.ShouldResemble, r3.Vector{})
+	})
+
+	// a controller disconnect disengages even while the deadman is held
+	rc.send(ctx, input.ButtonLT, input.ButtonPress, 1)
+	rc.send(ctx, input.AbsoluteY, input.Disconnect, 0)
+	st, err = teleop.GetStatus(ctx, svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, st.Engaged, test.ShouldBeFalse)
+}
+
+func TestTeleopHeldInput(t *testing.T) {
+	ctx := context.Background()
+	rc := newRecordingComponents()
+	conf := &Config{
+		InputControllerName: "pad",
+		RateHz:              100,
+		TimeoutMS:           50,
+		Mappings: []MappingConfig{
+			{Control: input.AbsoluteY, Action: ActionBaseLinear, Component: "base"},
+			{Control: input.AbsoluteRX, Action: ActionArmJoint, Component: "arm", Joint: 5},
+		},
+	}
+	svc, err := NewBuiltIn(ctx, rc.deps, resource.Config{
+		Name:                "teleop",
+		API:                 teleop.API,
+		ConvertedAttributes: conf,
+	}, logging.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// a stick held still sends no events, but the base keeps driving while the controller responds
+	rc.send(ctx, input.AbsoluteY, input.PositionChangeAbs, 0.5)
+	rc.send(ctx, input.AbsoluteRX, input.PositionChangeAbs, 1)
+	time.Sleep(4 * time.Duration(conf.TimeoutMS) * time.Millisecond)
+	rc.mu.Lock()
+	test.That(t, rc.power.Y, test.ShouldAlmostEqual, 0.5)
+	test.That(t, rc.baseStops, test.ShouldEqual, 0)
+	// the arm has no joint 5, so it is never commanded
+	test.That(t, rc.moves, test.ShouldEqual, 0)
+	rc.pollErr = errors.New("controller unreachable")
+	rc.mu.Unlock()
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		test.That(tb, rc.baseStops, test.ShouldBeGreaterThan, 0)
+		test.That(tb, rc.power, test.ShouldResemble, r3.Vector{})
+	})
+}
@@ -0,0 +1,7 @@
+// Package register registers all relevant teleop models and also API specific functions
+package register
+
+import (
+	// for teleop models.
+	_ "go.viam.com/rdk/services/teleop/builtin"
+)
@@ -0,0 +1,91 @@
+// Package teleop defines a service that drives bases, arms, and grippers from input controller events
+// according to a configurable mapping.
+package teleop
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/robot"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = "teleop"
+
+// API is a variable that identifies the teleop resource API.
+var API = resource.APINamespaceRDK.WithServiceType(SubtypeName)
+
+// Named is a helper for getting the named teleop service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NewName(API, name)
+}
+
+// Deprecated: FromRobot is a helper for getting the named teleop service from the given Robot.
+// Use FromProvider instead.
+//
+//nolint:revive // ignore exported comment check
+func FromRobot(r robot.Robot, name string) (Service, error) {
+	return robot.ResourceFromRobot[Service](r, Named(name))
+}
+
+// FromProvider is a helper for getting the named teleop service
+// from a resource Provider (collection of Dependencies or a Robot).
+func FromProvider(provider resource.Provider, name string) (Service, error) {
+	return resource.FromProvider[Service](provider, Named(name))
+}
+
+func init() {
+	resource.RegisterAPI(API, resource.APIRegistration[Service]{})
+}
+
+// Status reports the state of a teleop service.
+type Status struct {
+	// Engaged is true while the safety interlocks permit commands to be sent to components.
+	Engaged bool
+	// LastEvent is when the most recent input controller event was received.
+	LastEvent time.Time
+}
+
+// A Service maps input controller events onto component commands.
+// Its resource Status reports the teleop Status in the form produced by StatusToMap.
+type Service interface {
+	resource.Resource
+}
+
+// GetStatus returns whether the service is currently sending commands to components.
+func GetStatus(ctx context.Context, svc Service) (Status, error) {
+	m, err := svc.Status(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+	return StatusFromMap(m)
+}
+
+// StatusToMap converts a Status into the map reported by a teleop service's resource Status.
+func StatusToMap(st Status) map[string]interface{} {
+	m := map[string]interface{}{"engaged": st.Engaged}
+	if !st.LastEvent.IsZero() {
+		m["last_event"] = st.LastEvent.UTC().Format(time.RFC3339Nano)
+	}
+	return m
+}
+
+// StatusFromMap converts a map produced by StatusToMap back into a Status.
+func StatusFromMap(m map[string]interface{}) (Status, error) {
+	engaged, ok := m["engaged"].(bool)
+	if !ok {
+		return Status{}, errors.Errorf("expected teleop status engaged to be a bool but got %T", m["engaged"])
+	}
+	st := Status{Engaged: engaged}
+	if raw, ok := m["last_event"].(string); ok {
+		lastEvent, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return Status{}, err
+		}
+		st.LastEvent = lastEvent
+	}
+	return st, nil
+}
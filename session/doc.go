@@ -149,6 +149,13 @@ accessed last (if no one else accessed them) will be stopped. If the robot disco
 then the remote robot will have the remote session be expired and also terminate all resources that the connecting
 robot had accessed last in the same vein.
 
+# Exclusive Control
+
+When several clients operate one robot, a session may acquire exclusive control of resources through the
+arbiter (see the robot/arbiter package). Safety monitored methods of those resources are then rejected with
+code "FailedPrecondition" unless they are called within that session, until it releases control, expires,
+or another session takes control over, which stops the resources.
+
 # Security Considerations
 
   - Since the loss of a session can result in stopping moves to components, which we would consider an authorized
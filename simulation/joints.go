@@ -0,0 +1,122 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// Joints simulates the inputs of a kinematic model, such as the joints of an arm or the axes of a
+// gantry, moving together so that they all arrive at once.
+type Joints struct {
+	conf      *Config
+	model     referenceframe.Model
+	maxSpeed  float64
+	obstacles []spatialmath.Geometry
+	motions   motions
+
+	mu     sync.Mutex
+	inputs []referenceframe.Input
+}
+
+// NewJoints returns a simulation of the model starting at start, whose inputs move at up to maxSpeed
+// of their units, radians or millimeters, per second.
+func NewJoints(conf *Config, model referenceframe.Model, maxSpeed float64, start []referenceframe.Input) (*Joints, error) {
+	if len(start) != len(model.DoF()) {
+		return nil, referenceframe.NewIncorrectDoFError(len(start), len(model.DoF()))
+	}
+	obstacles, err := conf.world()
+	if err != nil {
+		return nil, err
+	}
+	return &Joints{
+		conf:      conf,
+		model:     model,
+		maxSpeed:  maxSpeed,
+		obstacles: obstacles,
+		inputs:    append([]referenceframe.Input(nil), start...),
+	}, nil
+}
+
+// Inputs returns where the inputs are now.
+func (j *Joints) Inputs() []referenceframe.Input {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]referenceframe.Input(nil), j.inputs...)
+}
+
+// MoveTo moves the inputs to goal at up to speed, or their maximum speed if it is 0, and returns once
+// they are there. It fails without moving if goal is beyond the model's limits, and stops short if
+// the model would collide with an obstacle or the motion is stopped.
+func (j *Joints) MoveTo(ctx context.Context, goal []referenceframe.Input, speed float64) error {
+	limits := j.model.DoF()
+	if len(goal) != len(limits) {
+		return referenceframe.NewIncorrectDoFError(len(goal), len(limits))
+	}
+	for i, g := range goal {
+		if g < limits[i].Min || g > limits[i].Max {
+			return errors.Errorf("input %d goal %.4f is outside its limits [%.4f, %.4f]", i, g, limits[i].Min, limits[i].Max)
+		}
+	}
+	if speed <= 0 || speed > j.maxSpeed {
+		speed = j.maxSpeed
+	}
+
+	m := j.motions.begin()
+	defer j.motions.end(m)
+	geometries, err := j.model.Geometries(j.Inputs())
+	if err != nil {
+		return err
+	}
+	allowed, err := contacts(geometries.Geometries(), j.obstacles)
+	if err != nil {
+		return err
+	}
+	return j.conf.run(ctx, m, func(dt float64) (bool, error) {
+		current := j.Inputs()
+		// the input with the farthest to go moves at speed, and the rest in proportion.
+		var farthest float64
+		for i := range goal {
+			farthest = math.Max(farthest, math.Abs(goal[i]-current[i]))
+		}
+		moved, arrived := approach(farthest, speed, dt)
+		next := make([]referenceframe.Input, len(current))
+		for i := range current {
+			if arrived {
+				next[i] = goal[i]
+			} else {
+				next[i] = current[i] + (goal[i]-current[i])*moved/farthest
+			}
+		}
+		geometries, err := j.model.Geometries(next)
+		if err != nil {
+			return false, err
+		}
+		obstacle, err := newContact(geometries.Geometries(), j.obstacles, allowed)
+		if err != nil {
+			return false, err
+		}
+		if obstacle != "" {
+			return false, errors.Errorf("%s stopped before colliding with %s", j.model.Name(), obstacle)
+		}
+		j.mu.Lock()
+		j.inputs = next
+		j.mu.Unlock()
+		return arrived, nil
+	})
+}
+
+// Stop stops the inputs where they are.
+func (j *Joints) Stop() {
+	j.motions.stop()
+}
+
+// IsMoving returns whether the inputs are moving.
+func (j *Joints) IsMoving() bool {
+	return j.motions.moving()
+}
@@ -0,0 +1,179 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// Planar simulates a base driving over the floor of the frame it starts in, forward along its y axis
+// and spinning counterclockwise about its z axis.
+type Planar struct {
+	name       string
+	conf       *Config
+	geometries []spatialmath.Geometry
+	obstacles  []spatialmath.Geometry
+	motions    motions
+
+	mu          sync.Mutex
+	x, y        float64
+	headingDegs float64
+}
+
+// NewPlanar returns a simulation of a base named name with the given geometries, starting at the
+// origin.
+func NewPlanar(name string, conf *Config, geometries []spatialmath.Geometry) (*Planar, error) {
+	obstacles, err := conf.world()
+	if err != nil {
+		return nil, err
+	}
+	if conf.Gravity {
+		// bases drive over the floor rather than through it, so it is not an obstacle of theirs.
+		obstacles = obstacles[:len(obstacles)-1]
+	}
+	return &Planar{name: name, conf: conf, geometries: geometries, obstacles: obstacles}, nil
+}
+
+// Pose returns where the base is in the frame it started in.
+func (p *Planar) Pose() spatialmath.Pose {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pose(p.x, p.y, p.headingDegs)
+}
+
+func (p *Planar) pose(x, y, headingDegs float64) spatialmath.Pose {
+	return spatialmath.NewPose(r3.Vector{X: x, Y: y}, &spatialmath.OrientationVectorDegrees{OZ: 1, Theta: headingDegs})
+}
+
+// MoveStraight drives distanceMM forward, or backward if it or mmPerSec is negative, and returns
+// once the base is there.
+func (p *Planar) MoveStraight(ctx context.Context, distanceMM, mmPerSec float64) error {
+	if distanceMM == 0 {
+		return nil
+	}
+	if mmPerSec == 0 {
+		return errors.New("cannot move straight at a speed of 0")
+	}
+	speed := math.Min(math.Abs(mmPerSec), p.conf.MaxLinearSpeed())
+	left := math.Abs(distanceMM)
+	if (distanceMM < 0) != (mmPerSec < 0) {
+		speed = -speed
+	}
+	m := p.motions.begin()
+	defer p.motions.end(m)
+	allowed, err := p.contacts()
+	if err != nil {
+		return err
+	}
+	return p.conf.run(ctx, m, func(dt float64) (bool, error) {
+		moved, arrived := approach(left, math.Abs(speed), dt)
+		left -= moved
+		return arrived, p.step(math.Copysign(moved, speed), 0, allowed)
+	})
+}
+
+// Spin turns angleDegs counterclockwise, or clockwise if it or degsPerSec is negative, and returns
+// once the base has turned.
+func (p *Planar) Spin(ctx context.Context, angleDegs, degsPerSec float64) error {
+	if angleDegs == 0 {
+		return nil
+	}
+	if degsPerSec == 0 {
+		return errors.New("cannot spin at a speed of 0")
+	}
+	speed := math.Min(math.Abs(degsPerSec), utils.RadToDeg(p.conf.MaxAngularSpeed()))
+	left := math.Abs(angleDegs)
+	if (angleDegs < 0) != (degsPerSec < 0) {
+		speed = -speed
+	}
+	m := p.motions.begin()
+	defer p.motions.end(m)
+	allowed, err := p.contacts()
+	if err != nil {
+		return err
+	}
+	return p.conf.run(ctx, m, func(dt float64) (bool, error) {
+		turned, arrived := approach(left, math.Abs(speed), dt)
+		left -= turned
+		return arrived, p.step(0, math.Copysign(turned, speed), allowed)
+	})
+}
+
+// SetVelocity drives forward at linearMMPerSec and spins at angularDegsPerSec, limited to the
+// maximum speeds, until the base is stopped, given another command, or would collide.
+func (p *Planar) SetVelocity(linearMMPerSec, angularDegsPerSec float64) {
+	if linearMMPerSec == 0 && angularDegsPerSec == 0 {
+		p.Stop()
+		return
+	}
+	maxLinear, maxAngular := p.conf.MaxLinearSpeed(), utils.RadToDeg(p.conf.MaxAngularSpeed())
+	linear := math.Max(-maxLinear, math.Min(maxLinear, linearMMPerSec))
+	angular := math.Max(-maxAngular, math.Min(maxAngular, angularDegsPerSec))
+	m := p.motions.begin()
+	goutils.PanicCapturingGo(func() {
+		defer p.motions.end(m)
+		allowed, err := p.contacts()
+		if err != nil {
+			return
+		}
+		//nolint:errcheck // a velocity stopped by a collision just stops.
+		p.conf.run(context.Background(), m, func(dt float64) (bool, error) {
+			return false, p.step(linear*dt, angular*dt, allowed)
+		})
+	})
+}
+
+// SetPower drives at fractions, from -1 to 1, of the maximum speeds.
+func (p *Planar) SetPower(linear, angular float64) {
+	p.SetVelocity(linear*p.conf.MaxLinearSpeed(), angular*utils.RadToDeg(p.conf.MaxAngularSpeed()))
+}
+
+// placed returns the geometries of the base at pose.
+func (p *Planar) placed(pose spatialmath.Pose) []spatialmath.Geometry {
+	moved := make([]spatialmath.Geometry, 0, len(p.geometries))
+	for _, g := range p.geometries {
+		moved = append(moved, g.Transform(pose))
+	}
+	return moved
+}
+
+// contacts returns the collisions of the base where it is now.
+func (p *Planar) contacts() (map[[2]string]bool, error) {
+	return contacts(p.placed(p.Pose()), p.obstacles)
+}
+
+// step drives forward and turns by the given amounts unless the base would collide.
+func (p *Planar) step(forwardMM, turnDegs float64, allowed map[[2]string]bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	heading := p.headingDegs + turnDegs
+	rads := utils.DegToRad(heading)
+	x := p.x - forwardMM*math.Sin(rads)
+	y := p.y + forwardMM*math.Cos(rads)
+	obstacle, err := newContact(p.placed(p.pose(x, y, heading)), p.obstacles, allowed)
+	if err != nil {
+		return err
+	}
+	if obstacle != "" {
+		return errors.Errorf("%s stopped before colliding with %s", p.name, obstacle)
+	}
+	p.x, p.y, p.headingDegs = x, y, heading
+	return nil
+}
+
+// Stop stops the base where it is.
+func (p *Planar) Stop() {
+	p.motions.stop()
+}
+
+// IsMoving returns whether the base is moving.
+func (p *Planar) IsMoving() bool {
+	return p.motions.moving()
+}
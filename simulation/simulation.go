@@ -0,0 +1,237 @@
+// Package simulation simulates the motion of fake components, so that they take time to move, respect
+// their joint limits and stop short of obstacles instead of teleporting wherever they are sent. It
+// lets tests and CI catch planning and execution bugs, such as not waiting for a motion to finish or
+// planning through the world, that teleporting fakes hide.
+//
+// The simulation is kinematic: components move at up to their maximum speeds with no dynamics, and
+// gravity is modeled only as the floor they rest on.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+const (
+	defaultMaxAngularSpeedDegsPerSec = 90.
+	defaultMaxLinearSpeedMMPerSec    = 200.
+	// stepInterval is how often, in real time, simulations advance.
+	stepInterval = 10 * time.Millisecond
+	// floorSizeMM is how far across and how thick the floor is.
+	floorSizeMM = 1e6
+	// FloorLabel is the label of the floor obstacle added under gravity.
+	FloorLabel = "floor"
+)
+
+// ErrStopped is returned by motions that were stopped, or replaced by another motion, before they
+// reached their goal.
+var ErrStopped = errors.New("stopped before reaching the goal")
+
+// Config turns on the simulation of a fake component.
+type Config struct {
+	// MaxAngularSpeedDegsPerSec is how fast rotational joints turn, and bases spin, at most.
+	MaxAngularSpeedDegsPerSec float64 `json:"max_angular_speed_degs_per_sec,omitempty"`
+	// MaxLinearSpeedMMPerSec is how fast linear joints slide, and bases drive, at most.
+	MaxLinearSpeedMMPerSec float64 `json:"max_linear_speed_mm_per_sec,omitempty"`
+	// TimeScale is how many times faster than real time the simulation runs, so tests need not wait
+	// out long motions. It defaults to 1.
+	TimeScale float64 `json:"time_scale,omitempty"`
+	// Gravity adds the floor, everything below z = 0 of the frame obstacles are in, as an obstacle
+	// that the component may not move into.
+	Gravity bool `json:"gravity,omitempty"`
+	// Obstacles are fixed in the frame the component is attached to: the base of an arm or gantry,
+	// or the frame a base starts driving in.
+	Obstacles []spatialmath.GeometryConfig `json:"obstacles,omitempty"`
+}
+
+// Validate ensures the simulation can be run.
+func (conf *Config) Validate(path string) error {
+	if conf.MaxAngularSpeedDegsPerSec < 0 || conf.MaxLinearSpeedMMPerSec < 0 {
+		return errors.New("simulated speeds cannot be negative")
+	}
+	if conf.TimeScale < 0 {
+		return errors.New("simulated time_scale cannot be negative")
+	}
+	if _, err := conf.world(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MaxAngularSpeed returns how fast rotational joints turn at most, in radians per second.
+func (conf *Config) MaxAngularSpeed() float64 {
+	if conf.MaxAngularSpeedDegsPerSec == 0 {
+		return utils.DegToRad(defaultMaxAngularSpeedDegsPerSec)
+	}
+	return utils.DegToRad(conf.MaxAngularSpeedDegsPerSec)
+}
+
+// MaxLinearSpeed returns how fast linear joints slide at most, in millimeters per second.
+func (conf *Config) MaxLinearSpeed() float64 {
+	if conf.MaxLinearSpeedMMPerSec == 0 {
+		return defaultMaxLinearSpeedMMPerSec
+	}
+	return conf.MaxLinearSpeedMMPerSec
+}
+
+// step returns how much simulated time passes in each step, in seconds.
+func (conf *Config) step() float64 {
+	if conf.TimeScale == 0 {
+		return stepInterval.Seconds()
+	}
+	return stepInterval.Seconds() * conf.TimeScale
+}
+
+// world returns the obstacles, with the floor under gravity.
+func (conf *Config) world() ([]spatialmath.Geometry, error) {
+	obstacles := make([]spatialmath.Geometry, 0, len(conf.Obstacles)+1)
+	for i, gc := range conf.Obstacles {
+		g, err := gc.ParseConfig()
+		if err != nil {
+			return nil, errors.Wrapf(err, "obstacle %d", i)
+		}
+		if g.Label() == "" {
+			g.SetLabel(fmt.Sprintf("obstacle %d", i))
+		}
+		obstacles = append(obstacles, g)
+	}
+	if conf.Gravity {
+		floor, err := spatialmath.NewBox(
+			spatialmath.NewPoseFromPoint(r3.Vector{Z: -floorSizeMM / 2}),
+			r3.Vector{X: floorSizeMM, Y: floorSizeMM, Z: floorSizeMM},
+			FloorLabel,
+		)
+		if err != nil {
+			return nil, err
+		}
+		obstacles = append(obstacles, floor)
+	}
+	return obstacles, nil
+}
+
+// contacts returns the labels of the pairs of geometries and obstacles in collision.
+func contacts(geometries, obstacles []spatialmath.Geometry) (map[[2]string]bool, error) {
+	found := map[[2]string]bool{}
+	for _, g := range geometries {
+		for _, o := range obstacles {
+			collides, _, err := g.CollidesWith(o, 0)
+			if err != nil {
+				return nil, err
+			}
+			if collides {
+				found[[2]string{g.Label(), o.Label()}] = true
+			}
+		}
+	}
+	return found, nil
+}
+
+// newContact returns the label of an obstacle geometries are in a collision with that is not in
+// allowed, or the empty string if there is none. As in motion planning, the collisions present when
+// a motion starts, such as the base of an arm resting on the floor, are allowed so that components
+// can move away from them.
+func newContact(geometries, obstacles []spatialmath.Geometry, allowed map[[2]string]bool) (string, error) {
+	found, err := contacts(geometries, obstacles)
+	if err != nil {
+		return "", err
+	}
+	for pair := range found {
+		if !allowed[pair] {
+			return pair[1], nil
+		}
+	}
+	return "", nil
+}
+
+// A motion is one command moving a simulated component.
+type motion struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func (m *motion) halt() {
+	m.stopOnce.Do(func() { close(m.stop) })
+	<-m.done
+}
+
+// motions runs one motion of a component at a time, stopping the last when another begins.
+type motions struct {
+	mu      sync.Mutex
+	current *motion
+}
+
+func (ms *motions) begin() *motion {
+	m := &motion{stop: make(chan struct{}), done: make(chan struct{})}
+	ms.mu.Lock()
+	prev := ms.current
+	ms.current = m
+	ms.mu.Unlock()
+	if prev != nil {
+		prev.halt()
+	}
+	return m
+}
+
+func (ms *motions) end(m *motion) {
+	ms.mu.Lock()
+	if ms.current == m {
+		ms.current = nil
+	}
+	ms.mu.Unlock()
+	close(m.done)
+}
+
+func (ms *motions) stop() {
+	ms.mu.Lock()
+	m := ms.current
+	ms.mu.Unlock()
+	if m != nil {
+		m.halt()
+	}
+}
+
+func (ms *motions) moving() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.current != nil
+}
+
+// run advances m by calling step with the simulated time passed every stepInterval, until step is
+// done or fails, m is stopped, or ctx is done.
+func (conf *Config) run(ctx context.Context, m *motion, step func(dt float64) (bool, error)) error {
+	ticker := time.NewTicker(stepInterval)
+	defer ticker.Stop()
+	dt := conf.step()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-m.stop:
+			return ErrStopped
+		case <-ticker.C:
+		}
+		done, err := step(dt)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// approach returns how far along a distance left to go a motion at speed gets in dt, and whether it
+// gets there.
+func approach(left, speed, dt float64) (float64, bool) {
+	if math.Abs(left) <= speed*dt {
+		return left, true
+	}
+	return math.Copysign(speed*dt, left), false
+}
@@ -0,0 +1,139 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// newGantryModel returns a gantry whose 50mm wide carriage, resting on the floor, slides along x
+// from -50mm to 50mm.
+func newGantryModel(t *testing.T) referenceframe.Model {
+	t.Helper()
+	model, err := referenceframe.KinematicModelFromFile(utils.ResolveFile("components/gantry/fake/test_gantry_model.json"), "gantry")
+	test.That(t, err, test.ShouldBeNil)
+	return model
+}
+
+func TestValidate(t *testing.T) {
+	test.That(t, (&Config{}).Validate("path"), test.ShouldBeNil)
+	test.That(t, (&Config{TimeScale: -1}).Validate("path"), test.ShouldNotBeNil)
+	test.That(t, (&Config{MaxLinearSpeedMMPerSec: -1}).Validate("path"), test.ShouldNotBeNil)
+	test.That(t, (&Config{Obstacles: []spatialmath.GeometryConfig{{Type: "cylinder"}}}).Validate("path"), test.ShouldNotBeNil)
+}
+
+func TestJoints(t *testing.T) {
+	ctx := context.Background()
+	model := newGantryModel(t)
+	// a box in the way of the carriage at 80mm.
+	conf := &Config{
+		TimeScale: 10,
+		Gravity:   true,
+		Obstacles: []spatialmath.GeometryConfig{{
+			Type: "box", X: 10, Y: 100, Z: 100, TranslationOffset: r3.Vector{X: 60, Z: 50}, Label: "wall",
+		}},
+	}
+	sim, err := NewJoints(conf, model, 100, []referenceframe.Input{50})
+	test.That(t, err, test.ShouldBeNil)
+
+	// the carriage takes time to get there, even though it rests on the floor the whole way.
+	start := time.Now()
+	test.That(t, sim.MoveTo(ctx, []referenceframe.Input{0}, 0), test.ShouldBeNil)
+	test.That(t, sim.Inputs(), test.ShouldResemble, []referenceframe.Input{0})
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 40*time.Millisecond)
+	test.That(t, sim.IsMoving(), test.ShouldBeFalse)
+
+	err = sim.MoveTo(ctx, []referenceframe.Input{101}, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "outside its limits")
+	test.That(t, sim.Inputs(), test.ShouldResemble, []referenceframe.Input{0})
+	test.That(t, sim.MoveTo(ctx, []referenceframe.Input{1, 2}, 0), test.ShouldNotBeNil)
+
+	// the carriage stops short of the wall.
+	err = sim.MoveTo(ctx, []referenceframe.Input{100}, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "wall")
+	stoppedAt := sim.Inputs()[0]
+	test.That(t, stoppedAt, test.ShouldBeBetween, 50, 80)
+
+	// stopping a motion leaves the carriage partway.
+	done := make(chan error, 1)
+	go func() { done <- sim.MoveTo(ctx, []referenceframe.Input{0}, 10) }()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, sim.IsMoving(), test.ShouldBeTrue)
+	})
+	sim.Stop()
+	test.That(t, errors.Is(<-done, ErrStopped), test.ShouldBeTrue)
+	test.That(t, sim.IsMoving(), test.ShouldBeFalse)
+	test.That(t, sim.Inputs()[0], test.ShouldBeBetween, 0, stoppedAt)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	test.That(t, sim.MoveTo(cancelCtx, []referenceframe.Input{0}, 0), test.ShouldBeError, context.Canceled)
+}
+
+func TestPlanar(t *testing.T) {
+	ctx := context.Background()
+	body, err := spatialmath.NewBox(spatialmath.NewZeroPose(), r3.Vector{X: 100, Y: 100, Z: 100}, "body")
+	test.That(t, err, test.ShouldBeNil)
+	conf := &Config{
+		TimeScale: 20,
+		Gravity:   true,
+		Obstacles: []spatialmath.GeometryConfig{{
+			Type: "box", X: 1000, Y: 10, Z: 100, TranslationOffset: r3.Vector{Y: 500}, Label: "wall",
+		}},
+	}
+	sim, err := NewPlanar("base", conf, []spatialmath.Geometry{body})
+	test.That(t, err, test.ShouldBeNil)
+
+	test.That(t, sim.MoveStraight(ctx, 200, 100), test.ShouldBeNil)
+	test.That(t, sim.Pose().Point().Sub(r3.Vector{Y: 200}).Norm(), test.ShouldBeLessThan, 1e-6)
+	test.That(t, sim.MoveStraight(ctx, 100, -100), test.ShouldBeNil)
+	test.That(t, sim.Pose().Point().Sub(r3.Vector{Y: 100}).Norm(), test.ShouldBeLessThan, 1e-6)
+
+	// a quarter turn counterclockwise faces -x.
+	test.That(t, sim.Spin(ctx, 90, 90), test.ShouldBeNil)
+	test.That(t, sim.MoveStraight(ctx, 100, 100), test.ShouldBeNil)
+	test.That(t, sim.Pose().Point().Sub(r3.Vector{X: -100, Y: 100}).Norm(), test.ShouldBeLessThan, 1e-6)
+	test.That(t, sim.Spin(ctx, -90, 90), test.ShouldBeNil)
+	test.That(t, sim.Pose().Orientation().OrientationVectorDegrees().Theta, test.ShouldAlmostEqual, 0, 1e-6)
+
+	// driving forward stops short of the wall.
+	err = sim.MoveStraight(ctx, 1000, 200)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "wall")
+	test.That(t, sim.Pose().Point().Y, test.ShouldBeBetween, 400, 445)
+
+	// velocities drive until stopped.
+	sim.SetVelocity(-100, 0)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, sim.Pose().Point().Y, test.ShouldBeLessThan, 300)
+	})
+	test.That(t, sim.IsMoving(), test.ShouldBeTrue)
+	sim.Stop()
+	test.That(t, sim.IsMoving(), test.ShouldBeFalse)
+	y := sim.Pose().Point().Y
+	time.Sleep(3 * stepInterval)
+	test.That(t, sim.Pose().Point().Y, test.ShouldEqual, y)
+
+	// power spins at a fraction of the maximum speed, which is as fast as it goes.
+	sim.SetPower(0, 2)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, math.Abs(sim.Pose().Orientation().OrientationVectorDegrees().Theta), test.ShouldBeGreaterThan, 10)
+	})
+	sim.SetVelocity(0, 0)
+	test.That(t, sim.IsMoving(), test.ShouldBeFalse)
+
+	test.That(t, sim.MoveStraight(ctx, 100, 0), test.ShouldNotBeNil)
+	test.That(t, sim.Spin(ctx, 90, 0), test.ShouldNotBeNil)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/golang/geo/r3"
 	commonpb "go.viam.com/api/common/v1"
+	"gonum.org/v1/gonum/num/quat"
 
 	"go.viam.com/rdk/utils"
 )
@@ -78,7 +79,7 @@ type box struct {
 	// what b.center.Orientation().RotationMatrix() returns (which is
 	// local→world). SAT and projection routines in this file read its rows
 	// as the box's local axes in world.
-	rotMatrix *RotationMatrix
+	rotMatrix RotationMatrix
 	once      sync.Once
 }
 
@@ -341,11 +342,12 @@ func (b *box) toMesh() *Mesh {
 }
 
 func (b *box) rotationMatrix() *RotationMatrix {
-	// Orientation().RotationMatrix() returns local→world; we cache its transpose.
+	// Orientation().RotationMatrix() returns local→world; we cache its transpose, which is the
+	// rotation matrix of the conjugate quaternion.
 	b.once.Do(func() {
-		b.rotMatrix = b.center.Orientation().RotationMatrix().Transpose()
+		b.rotMatrix = *QuatToRotationMatrix(quat.Conj(b.center.Orientation().Quaternion()))
 	})
-	return b.rotMatrix
+	return &b.rotMatrix
 }
 
 // boxVsBoxCollision takes two boxes as arguments and returns a bool describing if they are in collision,
@@ -9,6 +9,7 @@ import (
 
 	"github.com/golang/geo/r3"
 	commonpb "go.viam.com/api/common/v1"
+	"gonum.org/v1/gonum/num/quat"
 
 	"go.viam.com/rdk/utils"
 )
@@ -40,7 +41,7 @@ type capsule struct {
 	// what c.pose.Orientation().RotationMatrix() returns (which is
 	// local→world). SAT code in sat_generic.go reads its rows as the
 	// capsule's local axes in world.
-	rotMatrix *RotationMatrix
+	rotMatrix RotationMatrix
 	once      sync.Once
 }
 
@@ -254,11 +255,12 @@ func (c *capsule) Hash() int {
 }
 
 func (c *capsule) rotationMatrix() *RotationMatrix {
-	// Orientation().RotationMatrix() returns local→world; we cache its transpose.
+	// Orientation().RotationMatrix() returns local→world; we cache its transpose, which is the
+	// rotation matrix of the conjugate quaternion.
 	c.once.Do(func() {
-		c.rotMatrix = c.pose.Orientation().RotationMatrix().Transpose()
+		c.rotMatrix = *QuatToRotationMatrix(quat.Conj(c.pose.Orientation().Quaternion()))
 	})
-	return c.rotMatrix
+	return &c.rotMatrix
 }
 
 func capsuleVsPointDistance(c *capsule, other r3.Vector) float64 {
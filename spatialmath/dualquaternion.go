@@ -90,6 +90,15 @@ func DualQuaternionFromPose(p Pose) *DualQuaternion {
 	return q
 }
 
+// dualQuaternionNumber returns the dual quaternion of any pose as DualQuaternionFromPose does, but
+// without copying a *DualQuaternion to the heap.
+func dualQuaternionNumber(p Pose) dualquat.Number {
+	if q, ok := p.(*DualQuaternion); ok {
+		return q.Number
+	}
+	return DualQuaternionFromPose(p).Number
+}
+
 // ToProtobuf converts a dualQuaternion to a protobuf pose.
 func (q *DualQuaternion) ToProtobuf() *commonpb.Pose {
 	final := &commonpb.Pose{}
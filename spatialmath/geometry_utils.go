@@ -148,19 +148,28 @@ func PlaneNormal(p0, p1, p2 r3.Vector) r3.Vector {
 // BoundingSphere returns a spherical geometry centered on the point (0, 0, 0) that will encompass the given geometry
 // if it were to be rotated 360 degrees about the Z axis.  The label of the new geometry is inherited from the given one.
 func BoundingSphere(geometry Geometry) (Geometry, error) {
-	r := geometry.Pose().Point().Norm()
+	r, err := BoundingRadius(geometry)
+	if err != nil {
+		return nil, err
+	}
+	return NewSphere(NewZeroPose(), geometry.Pose().Point().Norm()+r, geometry.Label())
+}
+
+// BoundingRadius returns the radius of the smallest sphere centered on the geometry's pose which
+// encloses it. Boxes, spheres, capsules and points are supported.
+func BoundingRadius(geometry Geometry) (float64, error) {
 	switch g := geometry.(type) {
 	case *box:
-		r += r3.Vector{X: g.halfSize[0], Y: g.halfSize[1], Z: g.halfSize[2]}.Norm()
+		return g.boundingSphereR, nil
 	case *sphere:
-		r += g.radius
+		return g.radius, nil
 	case *capsule:
-		r += g.length / 2
+		return g.length / 2, nil
 	case *point:
+		return 0, nil
 	default:
-		return nil, errGeometryTypeUnsupported
+		return 0, errGeometryTypeUnsupported
 	}
-	return NewSphere(NewZeroPose(), r, geometry.Label())
 }
 
 // ClosestPointsSegmentTriangle takes a line segment and a triangle, and returns the point on each closest to the other.
@@ -87,7 +87,15 @@ func NewPoseFromDH(a, d, alpha float64) Pose {
 // It converts the poses to dual quaternions and multiplies them together, normalizes the transform and returns a new Pose.
 // Composition does not commute in general, i.e. you cannot guarantee ABx == BAx.
 func Compose(a, b Pose) Pose {
-	return &DualQuaternion{DualQuaternionFromPose(a).Transformation(DualQuaternionFromPose(b).Number)}
+	dq := ComposeDQ(a, b)
+	return &dq
+}
+
+// ComposeDQ is like Compose except it returns a DualQuaternion value, which callers on hot paths
+// such as collision checking can store in place rather than allocating a new Pose.
+func ComposeDQ(a, b Pose) DualQuaternion {
+	aDQ := DualQuaternion{dualQuaternionNumber(a)}
+	return DualQuaternion{aDQ.Transformation(dualQuaternionNumber(b))}
 }
 
 // TransformPointByPose returns pt transformed by pose. Equivalent to
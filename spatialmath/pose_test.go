@@ -88,6 +88,19 @@ func TestDualQuatTransform(t *testing.T) {
 	test.That(t, transformedPoint.Z, test.ShouldAlmostEqual, expectedPoint.Z)
 }
 
+func TestComposeDQ(t *testing.T) {
+	a := NewPose(r3.Vector{1, 2, 3}, &OrientationVectorDegrees{OX: 1, Theta: 30})
+	b := NewPose(r3.Vector{4, 5, 6}, &R4AA{Theta: 1, RZ: 1})
+	dq := ComposeDQ(a, b)
+	test.That(t, PoseAlmostEqual(&dq, Compose(a, b)), test.ShouldBeTrue)
+
+	// Composing into a value must not allocate, as collision checking does so for every geometry.
+	allocs := testing.AllocsPerRun(100, func() {
+		dq = ComposeDQ(a, b)
+	})
+	test.That(t, allocs, test.ShouldEqual, 0)
+}
+
 func TestPoseInterpolation(t *testing.T) {
 	p1 := NewPoseFromPoint(r3.Vector{1, 2, 3})
 	p2 := NewPoseFromPoint(r3.Vector{3, 6, 9})
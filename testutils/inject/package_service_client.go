@@ -0,0 +1,37 @@
+package inject
+
+import (
+	"context"
+
+	packagespb "go.viam.com/api/app/packages/v1"
+	"google.golang.org/grpc"
+)
+
+// PackageServiceClient represents a fake instance of a package service client.
+type PackageServiceClient struct {
+	packagespb.PackageServiceClient
+	GetPackageFunc func(ctx context.Context, in *packagespb.GetPackageRequest,
+		opts ...grpc.CallOption) (*packagespb.GetPackageResponse, error)
+	ListPackagesFunc func(ctx context.Context, in *packagespb.ListPackagesRequest,
+		opts ...grpc.CallOption) (*packagespb.ListPackagesResponse, error)
+}
+
+// GetPackage calls the injected GetPackageFunc or the real version.
+func (psc *PackageServiceClient) GetPackage(ctx context.Context, in *packagespb.GetPackageRequest,
+	opts ...grpc.CallOption,
+) (*packagespb.GetPackageResponse, error) {
+	if psc.GetPackageFunc == nil {
+		return psc.PackageServiceClient.GetPackage(ctx, in, opts...)
+	}
+	return psc.GetPackageFunc(ctx, in, opts...)
+}
+
+// ListPackages calls the injected ListPackagesFunc or the real version.
+func (psc *PackageServiceClient) ListPackages(ctx context.Context, in *packagespb.ListPackagesRequest,
+	opts ...grpc.CallOption,
+) (*packagespb.ListPackagesResponse, error) {
+	if psc.ListPackagesFunc == nil {
+		return psc.PackageServiceClient.ListPackages(ctx, in, opts...)
+	}
+	return psc.ListPackagesFunc(ctx, in, opts...)
+}
@@ -16,6 +16,7 @@ type SLAMService struct {
 	PointCloudMapFunc func(ctx context.Context, returnEditedMap bool) (func() ([]byte, error), error)
 	InternalStateFunc func(ctx context.Context) (func() ([]byte, error), error)
 	PropertiesFunc    func(ctx context.Context) (slam.Properties, error)
+	RelocalizeFunc    func(ctx context.Context, initialGuess spatialmath.Pose) (slam.LocalizationResult, error)
 	DoCommandFunc     func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error)
 	StatusFunc        func(ctx context.Context) (map[string]interface{}, error)
 	CloseFunc         func(ctx context.Context) error
@@ -63,6 +64,18 @@ func (slamSvc *SLAMService) Properties(ctx context.Context) (slam.Properties, er
 	return slamSvc.PropertiesFunc(ctx)
 }
 
+// Relocalize calls the injected RelocalizeFunc or the real version.
+func (slamSvc *SLAMService) Relocalize(ctx context.Context, initialGuess spatialmath.Pose) (slam.LocalizationResult, error) {
+	if slamSvc.RelocalizeFunc == nil {
+		r, ok := slamSvc.Service.(slam.Relocalizer)
+		if !ok {
+			return slam.LocalizationResult{}, slam.ErrRelocalizeUnsupported
+		}
+		return r.Relocalize(ctx, initialGuess)
+	}
+	return slamSvc.RelocalizeFunc(ctx, initialGuess)
+}
+
 // DoCommand calls the injected DoCommand or the real variant.
 func (slamSvc *SLAMService) DoCommand(ctx context.Context,
 	cmd map[string]interface{},
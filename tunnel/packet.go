@@ -0,0 +1,55 @@
+package tunnel
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+// ProtocolMetadataKey is the gRPC metadata key through which a client opening a tunnel names the
+// protocol of the destination port, "tcp" or "udp". The port is assumed to be TCP without it.
+const ProtocolMetadataKey = "viam-tunnel-protocol"
+
+var errNoPeer = errors.New("no datagram has been received yet, so there is no peer to send to")
+
+// PacketConn adapts a listening net.PacketConn, such as a UDP socket, to the io.ReadWriteCloser
+// tunnels copy between. Each read returns one datagram and each write sends one. Writes go to the
+// peer that sent the most recent datagram, so it suits protocols with a single local peer.
+type PacketConn struct {
+	conn net.PacketConn
+
+	mu   sync.Mutex
+	peer net.Addr
+}
+
+// NewPacketConn returns a PacketConn reading from and writing to conn.
+func NewPacketConn(conn net.PacketConn) *PacketConn {
+	return &PacketConn{conn: conn}
+}
+
+// Read reads a datagram into buf, remembering its sender as the peer to write to.
+func (pc *PacketConn) Read(buf []byte) (int, error) {
+	n, addr, err := pc.conn.ReadFrom(buf)
+	if addr != nil {
+		pc.mu.Lock()
+		pc.peer = addr
+		pc.mu.Unlock()
+	}
+	return n, err
+}
+
+// Write sends buf as a datagram to the peer that sent the most recent datagram.
+func (pc *PacketConn) Write(buf []byte) (int, error) {
+	pc.mu.Lock()
+	peer := pc.peer
+	pc.mu.Unlock()
+	if peer == nil {
+		return 0, errNoPeer
+	}
+	return pc.conn.WriteTo(buf, peer)
+}
+
+// Close closes the underlying connection.
+func (pc *PacketConn) Close() error {
+	return pc.conn.Close()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"testing"
 
 	"go.viam.com/test"
@@ -277,3 +278,36 @@ func TestRecvWriterLoop(t *testing.T) {
 		test.That(t, writeCt, test.ShouldEqual, 2)
 	})
 }
+
+func TestPacketConn(t *testing.T) {
+	local, err := net.ListenPacket("udp", "127.0.0.1:0")
+	test.That(t, err, test.ShouldBeNil)
+	pc := tunnel.NewPacketConn(local)
+	defer func() {
+		test.That(t, pc.Close(), test.ShouldBeNil)
+	}()
+
+	// nothing can be written before a peer has sent a datagram.
+	_, err = pc.Write([]byte{1})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	peer, err := net.Dial("udp", local.LocalAddr().String())
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, peer.Close(), test.ShouldBeNil)
+	}()
+
+	_, err = peer.Write([]byte{1, 2, 3})
+	test.That(t, err, test.ShouldBeNil)
+	buf := make([]byte, 16)
+	n, err := pc.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, buf[:n], test.ShouldResemble, []byte{1, 2, 3})
+
+	n, err = pc.Write([]byte{4, 5})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, n, test.ShouldEqual, 2)
+	n, err = peer.Read(buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, buf[:n], test.ShouldResemble, []byte{4, 5})
+}
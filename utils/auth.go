@@ -22,6 +22,14 @@ const (
 	// external authentication endpoint (see ExternalAuthService#AuthenticateTo) intended
 	// for another, different consumer at a different endpoint.
 	CredentialsTypeExternal = goutils.CredentialsTypeExternal
+
+	// CredentialsTypeMTLS is for clients authenticating with a TLS client certificate issued by a
+	// configured certificate authority.
+	CredentialsTypeMTLS = "mtls"
+
+	// CredentialsTypeOIDC is for clients authenticating with an access token issued by a configured
+	// OpenID Connect provider, such as an organization's SSO.
+	CredentialsTypeOIDC = "oidc"
 )
 
 // Credentials packages up both a type of credential along with its payload which
@@ -31,6 +31,22 @@ const (
 	// to the time right after the point cloud was captured.
 	TimeReceivedMetadataKey = "viam-time-received"
 
+	// ServerTimeMetadataKey is optional metadata in the gRPC response header holding the time of the
+	// server when it handled the request, which clients use to estimate the offset between their clocks.
+	ServerTimeMetadataKey = "viam-server-time"
+
+	// RequestStatsMetadataKey is optional metadata in the gRPC response header of GetMachineStatus
+	// holding the JSON encoded request stats of the machine's resources.
+	RequestStatsMetadataKey = "viam-request-stats"
+
+	// AlertsMetadataKey is optional metadata in the gRPC response header of GetMachineStatus holding
+	// the JSON encoded active alerts of the machine.
+	AlertsMetadataKey = "viam-alerts"
+
+	// ConfigRevisionsMetadataKey is optional metadata in the gRPC response header of GetMachineStatus
+	// holding the JSON encoded statuses of the machine's recently received config revisions.
+	ConfigRevisionsMetadataKey = "viam-config-revisions"
+
 	// Timeout values to use when reading a config either from App behind a proxy, or from App with a local (cached) file.
 	// The timeout is far shorter when a cached config exists because the machine can always fall back to the cached config.
 	readConfigFromCloudBehindProxyTimeout = time.Minute
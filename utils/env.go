@@ -23,6 +23,15 @@ const (
 	// that resources are allowed to (re)configure.
 	ResourceConfigurationTimeoutEnvVar = "VIAM_RESOURCE_CONFIGURATION_TIMEOUT"
 
+	// DefaultResourceConfigurationConcurrency is the default number of resources
+	// that may be (re)configured at the same time.
+	DefaultResourceConfigurationConcurrency = 10
+
+	// ResourceConfigurationConcurrencyEnvVar is the environment variable that can
+	// be set to override DefaultResourceConfigurationConcurrency as the number of
+	// resources that are allowed to (re)configure at the same time.
+	ResourceConfigurationConcurrencyEnvVar = "VIAM_RESOURCE_CONFIGURATION_CONCURRENCY"
+
 	// DefaultModuleStartupTimeout is the default module startup timeout.
 	DefaultModuleStartupTimeout = 5 * time.Minute
 
@@ -116,6 +125,15 @@ var EnvTrueValues = []string{"true", "yes", "1", "TRUE", "YES"}
 // TCPRegex tests whether a module address is TCP (vs unix sockets). See also OnlyUseViamTCPSockets().
 var TCPRegex = regexp.MustCompile(`:\d+$`)
 
+// UnixSocketAddressPrefix prefixes the addresses of machines served over a Unix domain socket, such
+// as "unix:/run/viam/companion.sock".
+const UnixSocketAddressPrefix = "unix:"
+
+// IsUnixSocketAddress returns whether a machine address is of a Unix domain socket rather than of a host.
+func IsUnixSocketAddress(address string) bool {
+	return strings.HasPrefix(address, UnixSocketAddressPrefix)
+}
+
 // ViamDotDir is the directory for Viam's cached files.
 var ViamDotDir = viamDotDir()
 
@@ -136,6 +154,22 @@ func GetModuleStartupTimeout(logger logging.Logger) time.Duration {
 	return timeout
 }
 
+// GetResourceConfigurationConcurrency returns the number of resources that may be
+// (re)configured at the same time (env variable value if set to a positive integer,
+// DefaultResourceConfigurationConcurrency otherwise).
+func GetResourceConfigurationConcurrency(logger logging.Logger) int {
+	if val := os.Getenv(ResourceConfigurationConcurrencyEnvVar); val != "" {
+		concurrency, err := strconv.Atoi(val)
+		if err != nil || concurrency < 1 {
+			logger.Warnf("Failed to parse %s env var as a positive integer, falling back to default concurrency of %d",
+				ResourceConfigurationConcurrencyEnvVar, DefaultResourceConfigurationConcurrency)
+			return DefaultResourceConfigurationConcurrency
+		}
+		return concurrency
+	}
+	return DefaultResourceConfigurationConcurrency
+}
+
 // GetConfigReadTimeout returns the config read timeout set by the env variable value,
 // DefaultConfigReadTimeout otherwise.
 func GetConfigReadTimeout(logger logging.Logger) (time.Duration, bool) {
@@ -53,6 +53,7 @@ type Arguments struct {
 	OutputLogFile              string `flag:"log-file,usage=write logs to a file with log rotation"`
 	NoTLS                      bool   `flag:"no-tls,usage=starts an insecure http server without TLS certificates even if one exists"`
 	NetworkCheckOnly           bool   `flag:"network-check,usage=only runs normal network checks, logs results, and exits"`
+	ModuleDevMode              bool   `flag:"module-dev-mode,usage=restart local modules when their executables change"`
 }
 
 type robotServer struct {
@@ -61,6 +62,10 @@ type robotServer struct {
 	registry                                   *logging.Registry
 	conn                                       rpc.ClientConn
 	signalingConn                              rpc.ClientConn
+	// netAppender sends logs to the cloud, and is nil if the robot is not configured for it.
+	netAppender *logging.NetAppender
+	// recentLogs keeps the most recent logs for support bundles.
+	recentLogs *logging.RecentLogsAppender
 }
 
 func logViamEnvVariables(logger logging.Logger) {
@@ -142,6 +147,10 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 		registry.AddAppenderToAll(logging.NewStdoutAppender())
 	}
 
+	// The most recent logs are kept in memory for support bundles.
+	recentLogs := logging.NewRecentLogsAppender(logging.DefaultRecentLogsCapacity)
+	registry.AddAppenderToAll(recentLogs)
+
 	if os.Getenv(rutils.ViamNoWindowsEventLoggerEnvVar) == "" {
 		etwCloser, etwErr := logging.RegisterETWLogger(rootLogger,
 			filepath.Join(rutils.ViamDotDir, "logs"), logging.ServerETW)
@@ -193,6 +202,7 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 	}
 
 	var appConn, signalingConn rpc.ClientConn
+	var netAppender *logging.NetAppender
 
 	// Ensure VIAM_HOME is set in the environment so that config placeholder substitution
 	// (e.g. ${environment.VIAM_HOME}) and spawned modules see the same home directory we
@@ -251,7 +261,7 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 		// Start remote logging with config from disk.
 		// This is to ensure we make our best effort to write logs for failures loading the remote config.
 		if cloud.AppAddress != "" {
-			netAppender, err := logging.NewNetAppender(
+			netAppender, err = logging.NewNetAppender(
 				&logging.CloudConfig{
 					AppAddress: cloud.AppAddress,
 					ID:         cloud.ID,
@@ -263,6 +273,7 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 				return err
 			}
 			defer netAppender.Close()
+			netAppender.SetConfig(cfgFromDisk.NetAppender)
 
 			registry.AddAppenderToAll(netAppender)
 		}
@@ -288,6 +299,8 @@ func RunServer(ctx context.Context, args []string, _ logging.Logger) (err error)
 		registry:         registry,
 		conn:             appConn,
 		signalingConn:    signalingConn,
+		netAppender:      netAppender,
+		recentLogs:       recentLogs,
 	}
 
 	// Run the server with remote logging enabled.
@@ -467,6 +480,9 @@ func (s *robotServer) configWatcher(ctx context.Context, currCfg *config.Config,
 				}
 				config.UpdateLoggerRegistryFromConfig(s.registry, processedConfig, s.rootLogger)
 			}
+			if s.netAppender != nil {
+				s.netAppender.SetConfig(processedConfig.NetAppender)
+			}
 
 			r.Reconfigure(ctx, processedConfig)
 			currCfg = processedConfig
@@ -574,6 +590,9 @@ func (s *robotServer) serveWeb(ctx context.Context, cfg *config.Config) (err err
 	//
 	// This functionality is tested in `TestLogPropagation` in `local_robot_test.go`.
 	config.UpdateLoggerRegistryFromConfig(s.registry, fullProcessedConfig, s.rootLogger)
+	if s.netAppender != nil {
+		s.netAppender.SetConfig(fullProcessedConfig.NetAppender)
+	}
 
 	// Only start cloud restart checker if cloud config is non-nil, and viam-agent is not
 	// handling restart checking for us (relevant environment variable is unset).
@@ -615,8 +634,19 @@ func (s *robotServer) serveWeb(ctx context.Context, cfg *config.Config) (err err
 	})
 	robotOptions = append(robotOptions, shutdownCallbackOpt)
 
+	if s.recentLogs != nil {
+		robotOptions = append(robotOptions, robotimpl.WithRecentLogs(s.recentLogs))
+	}
+
 	if s.args.EnableFTDC {
 		robotOptions = append(robotOptions, robotimpl.WithFTDC())
+		if s.netAppender != nil {
+			robotOptions = append(robotOptions, robotimpl.WithFTDCStatser("net_appender", s.netAppender))
+		}
+	}
+
+	if s.args.ModuleDevMode {
+		robotOptions = append(robotOptions, robotimpl.WithModuleDevMode())
 	}
 
 	// Create `minimalProcessedConfig`, a copy of `fullProcessedConfig`. Remove